@@ -10,24 +10,31 @@ import (
 )
 
 type Config struct {
-	Port                string   `mapstructure:"PORT"`
-	Env                 string   `mapstructure:"ENV"`
-	AuthMode            string   `mapstructure:"AUTH_MODE"`
-	DatabaseURL         string   `mapstructure:"DATABASE_URL"`
-	DBMaxConns          int32    `mapstructure:"DB_MAX_CONNS"`
-	DBMinConns          int32    `mapstructure:"DB_MIN_CONNS"`
-	RedisURL            string   `mapstructure:"REDIS_URL"`
-	AuthIssuer          string   `mapstructure:"AUTH_ISSUER"`
-	AuthJWKSURL         string   `mapstructure:"AUTH_JWKS_URL"`
-	AuthAudience        string   `mapstructure:"AUTH_AUDIENCE"`
-	DefaultTenant       string   `mapstructure:"DEFAULT_TENANT"`
-	CORSOrigins         []string `mapstructure:"CORS_ORIGINS"`
-	HIPAAEncryptionKey  string   `mapstructure:"HIPAA_ENCRYPTION_KEY"`
-	RateLimitRPS        float64  `mapstructure:"RATE_LIMIT_RPS"`
-	RateLimitBurst      int      `mapstructure:"RATE_LIMIT_BURST"`
-	TLSEnabled          bool     `mapstructure:"TLS_ENABLED"`
-	TLSCertFile         string   `mapstructure:"TLS_CERT_FILE"`
-	TLSKeyFile          string   `mapstructure:"TLS_KEY_FILE"`
+	Port                  string   `mapstructure:"PORT"`
+	Env                   string   `mapstructure:"ENV"`
+	AuthMode              string   `mapstructure:"AUTH_MODE"`
+	DatabaseURL           string   `mapstructure:"DATABASE_URL"`
+	DBMaxConns            int32    `mapstructure:"DB_MAX_CONNS"`
+	DBMinConns            int32    `mapstructure:"DB_MIN_CONNS"`
+	RedisURL              string   `mapstructure:"REDIS_URL"`
+	AuthIssuer            string   `mapstructure:"AUTH_ISSUER"`
+	AuthJWKSURL           string   `mapstructure:"AUTH_JWKS_URL"`
+	AuthAudience          string   `mapstructure:"AUTH_AUDIENCE"`
+	DefaultTenant         string   `mapstructure:"DEFAULT_TENANT"`
+	CORSOrigins           []string `mapstructure:"CORS_ORIGINS"`
+	HIPAAEncryptionKey    string   `mapstructure:"HIPAA_ENCRYPTION_KEY"`
+	RateLimitRPS          float64  `mapstructure:"RATE_LIMIT_RPS"`
+	RateLimitBurst        int      `mapstructure:"RATE_LIMIT_BURST"`
+	TLSEnabled            bool     `mapstructure:"TLS_ENABLED"`
+	TLSCertFile           string   `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile            string   `mapstructure:"TLS_KEY_FILE"`
+	ImportDir             string   `mapstructure:"IMPORT_DIR"`
+	ExportSigningKey      string   `mapstructure:"EXPORT_SIGNING_KEY"`
+	IncludeMaxDepth       int      `mapstructure:"INCLUDE_MAX_DEPTH"`
+	IncludeMaxResources   int      `mapstructure:"INCLUDE_MAX_RESOURCES"`
+	GraphQLMaxCount       int      `mapstructure:"GRAPHQL_MAX_COUNT"`
+	GraphQLMaxDepth       int      `mapstructure:"GRAPHQL_MAX_DEPTH"`
+	GraphQLMaxResolutions int      `mapstructure:"GRAPHQL_MAX_RESOLUTIONS"`
 }
 
 func Load() (*Config, error) {
@@ -65,6 +72,13 @@ func Load() (*Config, error) {
 	v.BindEnv("TLS_ENABLED")
 	v.BindEnv("TLS_CERT_FILE")
 	v.BindEnv("TLS_KEY_FILE")
+	v.BindEnv("IMPORT_DIR")
+	v.BindEnv("EXPORT_SIGNING_KEY")
+	v.BindEnv("INCLUDE_MAX_DEPTH")
+	v.BindEnv("INCLUDE_MAX_RESOURCES")
+	v.BindEnv("GRAPHQL_MAX_COUNT")
+	v.BindEnv("GRAPHQL_MAX_DEPTH")
+	v.BindEnv("GRAPHQL_MAX_RESOLUTIONS")
 
 	// Try reading .env file, but don't fail if missing
 	_ = v.ReadInConfig()
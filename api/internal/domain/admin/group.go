@@ -126,83 +126,47 @@ func (g *Group) ToFHIR() map[string]interface{} {
 // GroupFromFHIR parses a FHIR R4 Group resource map into the domain model.
 func GroupFromFHIR(data map[string]interface{}) (*Group, error) {
 	g := &Group{}
-
-	if v, ok := data["type"].(string); ok {
-		if !IsValidGroupType(v) {
-			return nil, fmt.Errorf("invalid group type: %s", v)
-		}
-		g.Type = GroupType(v)
-	}
-
-	if v, ok := data["actual"].(bool); ok {
-		g.Actual = v
-	}
-
-	if v, ok := data["active"].(bool); ok {
-		g.Active = v
-	}
-
-	if v, ok := data["name"].(string); ok {
-		g.Name = v
-	}
-
-	if v, ok := data["quantity"].(float64); ok {
-		g.Quantity = int(v)
-	}
-
-	if v, ok := data["code"].(map[string]interface{}); ok {
-		if codings, ok := v["coding"].([]interface{}); ok && len(codings) > 0 {
-			if coding, ok := codings[0].(map[string]interface{}); ok {
-				if code, ok := coding["code"].(string); ok {
-					g.Code = &code
-				}
-			}
-		}
+	if err := g.FromFHIR(data); err != nil {
+		return nil, err
 	}
+	return g, nil
+}
 
-	if v, ok := data["managingEntity"].(map[string]interface{}); ok {
-		if ref, ok := v["reference"].(string); ok {
-			g.ManagingEntity = &ref
+// FromFHIR populates the group from a FHIR Group resource, the inverse of
+// ToFHIR. Member entities may reference any resource type.
+func (g *Group) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Group", data)
+	g.Type = GroupType(r.RequiredCode("type", "person", "animal", "practitioner", "device", "medication", "substance"))
+	g.Actual = r.BoolValue("actual")
+	g.Active = true
+	if v := r.Bool("active"); v != nil {
+		g.Active = *v
+	}
+	g.Name = r.RequiredString("name")
+	if v := r.Int("quantity"); v != nil {
+		g.Quantity = *v
+	}
+	g.Code = r.ConceptCode("code")
+	g.ManagingEntity = r.Reference("managingEntity")
+
+	g.Members = nil
+	for i := 0; i < r.Len("member"); i++ {
+		p := fmt.Sprintf("member[%d]", i)
+		ref := r.Reference(p + ".entity")
+		if ref == nil {
+			r.Invalid(p+".entity", fmt.Sprintf("Group.%s.entity.reference is required", p))
+			continue
 		}
-	}
-
-	if members, ok := data["member"].([]interface{}); ok {
-		for _, mi := range members {
-			m, ok := mi.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			member := GroupMember{}
-			if entity, ok := m["entity"].(map[string]interface{}); ok {
-				if ref, ok := entity["reference"].(string); ok {
-					parts := strings.SplitN(ref, "/", 2)
-					if len(parts) == 2 {
-						member.EntityType = parts[0]
-						member.EntityID = parts[1]
-					}
-				}
-			}
-			if inactive, ok := m["inactive"].(bool); ok {
-				member.Inactive = inactive
-			}
-			if period, ok := m["period"].(map[string]interface{}); ok {
-				if start, ok := period["start"].(string); ok {
-					if t, err := time.Parse(time.RFC3339, start); err == nil {
-						member.PeriodStart = &t
-					}
-				}
-				if end, ok := period["end"].(string); ok {
-					if t, err := time.Parse(time.RFC3339, end); err == nil {
-						member.PeriodEnd = &t
-					}
-				}
-			}
-			member.ID = uuid.New()
-			g.Members = append(g.Members, member)
+		rt, id, ok := fhir.SplitReference(*ref)
+		if !ok {
+			r.Invalid(p+".entity.reference", fmt.Sprintf("Group.%s.entity.reference %q is not a literal Type/id reference", p, *ref))
+			continue
 		}
+		member := GroupMember{ID: uuid.New(), EntityType: rt, EntityID: id, Inactive: r.BoolValue(p + ".inactive")}
+		member.PeriodStart, member.PeriodEnd = r.Period(p + ".period")
+		g.Members = append(g.Members, member)
 	}
-
-	return g, nil
+	return r.Err()
 }
 
 // --------------------------------------------------------------------------
@@ -534,6 +498,10 @@ func (h *GroupHandler) RegisterGroupRoutes(api *echo.Group, fhirGroup *echo.Grou
 	fhirRead.GET("/Group/:id/_history", h.HistoryGroupFHIR)
 }
 
+func (h *GroupHandler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Group", fhir.ReadOnlyInteractions()...)
+}
+
 // -- Operational Handlers --
 
 func (h *GroupHandler) CreateGroup(c echo.Context) error {
@@ -680,13 +648,9 @@ func (h *GroupHandler) GetGroupFHIR(c echo.Context) error {
 }
 
 func (h *GroupHandler) CreateGroupFHIR(c echo.Context) error {
-	var data map[string]interface{}
-	if err := c.Bind(&data); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
-	group, err := GroupFromFHIR(data)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	group := &Group{}
+	if err := fhir.BindResource(c, "Group", "", group); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateGroup(c.Request().Context(), group); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -700,18 +664,11 @@ func (h *GroupHandler) UpdateGroupFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Group", c.Param("id")))
 	}
-	var data map[string]interface{}
-	if err := c.Bind(&data); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
-	group, err := GroupFromFHIR(data)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	group := *existing
+	if err := fhir.BindResource(c, "Group", existing.FHIRID, &group); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
-	group.ID = existing.ID
-	group.FHIRID = existing.FHIRID
-	group.CreatedAt = existing.CreatedAt
-	if err := h.svc.UpdateGroup(c.Request().Context(), group); err != nil {
+	if err := h.svc.UpdateGroup(c.Request().Context(), &group); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
 	return c.JSON(http.StatusOK, group.ToFHIR())
@@ -739,7 +696,7 @@ func (h *GroupHandler) PatchGroupFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Group", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -765,17 +722,8 @@ func (h *GroupHandler) PatchGroupFHIR(c echo.Context) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["name"].(string); ok {
-		existing.Name = v
-	}
-	if v, ok := patched["active"].(bool); ok {
-		existing.Active = v
-	}
-	if v, ok := patched["type"].(string); ok {
-		existing.Type = GroupType(v)
-	}
-	if v, ok := patched["actual"].(bool); ok {
-		existing.Actual = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateGroup(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
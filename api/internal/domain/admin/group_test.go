@@ -310,6 +310,24 @@ func TestGroupFromFHIR_InvalidType(t *testing.T) {
 	}
 }
 
+func TestGroupFromFHIR_InvalidMember(t *testing.T) {
+	data := map[string]interface{}{
+		"type": "person",
+		"name": "Cohort",
+		"member": []interface{}{
+			map[string]interface{}{"entity": map[string]interface{}{"reference": "Patient/p1"}},
+			map[string]interface{}{"entity": map[string]interface{}{"reference": "#contained"}},
+		},
+	}
+	_, err := GroupFromFHIR(data)
+	if err == nil {
+		t.Fatal("expected error for non-literal member reference")
+	}
+	if !strings.Contains(err.Error(), "Group.member[1].entity.reference") {
+		t.Errorf("error %q does not locate the bad member", err.Error())
+	}
+}
+
 func TestGroupFromFHIR_MinimalResource(t *testing.T) {
 	data := map[string]interface{}{
 		"type":   "device",
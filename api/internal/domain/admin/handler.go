@@ -83,6 +83,11 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Location/:id/_history", h.HistoryLocationFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Organization", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("Location", fhir.ReadOnlyInteractions()...)
+}
+
 // -- Organization Handlers (Operational) --
 
 func (h *Handler) CreateOrganization(c echo.Context) error {
@@ -412,8 +417,8 @@ func (h *Handler) GetOrganizationFHIR(c echo.Context) error {
 
 func (h *Handler) CreateOrganizationFHIR(c echo.Context) error {
 	var org Organization
-	if err := c.Bind(&org); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Organization", "", &org); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateOrganization(c.Request().Context(), &org); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -427,12 +432,10 @@ func (h *Handler) UpdateOrganizationFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Organization", c.Param("id")))
 	}
-	var org Organization
-	if err := c.Bind(&org); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	org := *existing
+	if err := fhir.BindResource(c, "Organization", existing.FHIRID, &org); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
-	org.ID = existing.ID
-	org.FHIRID = existing.FHIRID
 	if err := h.svc.UpdateOrganization(c.Request().Context(), &org); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -485,8 +488,8 @@ func (h *Handler) GetLocationFHIR(c echo.Context) error {
 
 func (h *Handler) CreateLocationFHIR(c echo.Context) error {
 	var loc Location
-	if err := c.Bind(&loc); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Location", "", &loc); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateLocation(c.Request().Context(), &loc); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -500,12 +503,10 @@ func (h *Handler) UpdateLocationFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Location", c.Param("id")))
 	}
-	var loc Location
-	if err := c.Bind(&loc); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	loc := *existing
+	if err := fhir.BindResource(c, "Location", existing.FHIRID, &loc); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
-	loc.ID = existing.ID
-	loc.FHIRID = existing.FHIRID
 	if err := h.svc.UpdateLocation(c.Request().Context(), &loc); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -536,7 +537,7 @@ func (h *Handler) PatchOrganizationFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Organization", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -562,11 +563,8 @@ func (h *Handler) PatchOrganizationFHIR(c echo.Context) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["name"].(string); ok {
-		existing.Name = v
-	}
-	if v, ok := patched["active"].(bool); ok {
-		existing.Active = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateOrganization(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -611,7 +609,7 @@ func (h *Handler) PatchLocationFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Location", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -637,11 +635,8 @@ func (h *Handler) PatchLocationFHIR(c echo.Context) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
-	}
-	if v, ok := patched["name"].(string); ok {
-		existing.Name = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateLocation(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
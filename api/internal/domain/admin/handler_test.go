@@ -532,7 +532,7 @@ func TestHandler_GetOrganizationFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateOrganizationFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"name":"FHIR Org"}`
+	body := `{"resourceType":"Organization","name":"FHIR Org"}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -552,7 +552,7 @@ func TestHandler_UpdateOrganizationFHIR(t *testing.T) {
 	h, e := newTestHandler()
 	org := &Organization{Name: "Test"}
 	h.svc.CreateOrganization(nil, org)
-	body := `{"name":"Updated"}`
+	body := `{"resourceType":"Organization","name":"Updated"}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -687,7 +687,7 @@ func TestHandler_GetLocationFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateLocationFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"name":"FHIR Loc"}`
+	body := `{"resourceType":"Location","name":"FHIR Loc"}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -704,7 +704,7 @@ func TestHandler_UpdateLocationFHIR(t *testing.T) {
 	h, e := newTestHandler()
 	loc := &Location{Name: "L1"}
 	h.svc.CreateLocation(nil, loc)
-	body := `{"name":"L2"}`
+	body := `{"resourceType":"Location","name":"L2"}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -108,6 +108,28 @@ func (o *Organization) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the organization from a FHIR Organization resource, the
+// inverse of ToFHIR.
+func (o *Organization) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Organization", data)
+	o.Active = true
+	if v := r.Bool("active"); v != nil {
+		o.Active = *v
+	}
+	o.Name = r.RequiredString("name")
+	o.TypeCode = r.StringValue("type.coding.code")
+	o.Phone = r.ContactValue("telecom", "phone", "")
+	o.Email = r.ContactValue("telecom", "email", "")
+	o.AddressLine1 = r.String("address.line[0]")
+	o.AddressLine2 = r.String("address.line[1]")
+	o.City = r.String("address.city")
+	o.State = r.String("address.state")
+	o.PostalCode = r.String("address.postalCode")
+	o.Country = r.String("address.country")
+	o.ParentOrgID = r.ReferenceID("partOf", "Organization")
+	return r.Err()
+}
+
 // Department maps to the department table.
 type Department struct {
 	ID                 uuid.UUID  `db:"id" json:"id"`
@@ -193,6 +215,20 @@ func (l *Location) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the location from a FHIR Location resource, the inverse
+// of ToFHIR.
+func (l *Location) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Location", data)
+	l.Status = r.CodeValue("status", "active", "suspended", "inactive")
+	l.Name = r.RequiredString("name")
+	locType := r.CodeableConcept("type")
+	l.TypeCode, l.TypeDisplay = locType.Code, locType.Display
+	l.PhysicalTypeCode = r.CodeableConcept("physicalType").Code
+	l.OrganizationID = r.ReferenceID("managingOrganization", "Organization")
+	l.PartOfLocationID = r.ReferenceID("partOf", "Location")
+	return r.Err()
+}
+
 // SystemUser maps to the system_user table.
 type SystemUser struct {
 	ID                    uuid.UUID  `db:"id" json:"id"`
@@ -448,3 +448,79 @@ func TestUserRoleAssignment_JSONRoundTrip(t *testing.T) {
 		t.Error("EndDate is nil, want non-nil")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestOrganization_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &Organization{
+		FHIRID:       "org-rt",
+		Name:         "General Hospital",
+		TypeCode:     "prov",
+		Active:       true,
+		ParentOrgID:  ptrUUID(uuid.New()),
+		NPINumber:    ptrStr("1234567890"),
+		AddressLine1: ptrStr("1 Main St"),
+		AddressLine2: ptrStr("Suite 2"),
+		City:         ptrStr("Springfield"),
+		PostalCode:   ptrStr("12345"),
+		Phone:        ptrStr("555-0100"),
+		Email:        ptrStr("info@example.org"),
+	}
+
+	got := Organization{NPINumber: orig.NPINumber}
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Name != orig.Name || got.TypeCode != "prov" || !got.Active || *got.ParentOrgID != *orig.ParentOrgID {
+		t.Errorf("core fields mismatch: %+v", got)
+	}
+	if *got.AddressLine1 != "1 Main St" || *got.AddressLine2 != "Suite 2" || *got.City != "Springfield" || *got.PostalCode != "12345" {
+		t.Errorf("address mismatch: %+v", got)
+	}
+	if *got.Phone != "555-0100" || *got.Email != "info@example.org" {
+		t.Errorf("telecom mismatch: %+v", got)
+	}
+	if got.NPINumber == nil || *got.NPINumber != "1234567890" {
+		t.Error("expected NPI (not represented in FHIR) to be preserved")
+	}
+}
+
+func TestLocation_FromFHIR_InvalidElements(t *testing.T) {
+	var loc Location
+	err := loc.FromFHIR(map[string]interface{}{
+		"resourceType":         "Location",
+		"status":               "closed",
+		"managingOrganization": map[string]interface{}{"reference": "Location/" + uuid.New().String()},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	want := map[string]bool{
+		"Location.status":                         true,
+		"Location.name":                           true,
+		"Location.managingOrganization.reference": true,
+	}
+	for _, issue := range pe.Outcome.Issue {
+		delete(want, issue.Expression[0])
+	}
+	if len(want) != 0 {
+		t.Errorf("missing issues for %v in %+v", want, pe.Outcome.Issue)
+	}
+}
+
@@ -78,7 +78,7 @@ func (r *orgRepoPG) Update(ctx context.Context, org *Organization) error {
 			rohini_id = $9, abdm_facility_id = $10, nabh_accreditation = $11,
 			address_line1 = $12, address_line2 = $13, city = $14, district = $15,
 			state = $16, postal_code = $17, country = $18,
-			phone = $19, email = $20, website = $21, updated_at = NOW()
+			phone = $19, email = $20, website = $21, version_id = version_id + 1, updated_at = NOW()
 		WHERE id = $1`,
 		org.ID, org.Name, org.TypeCode, org.Active, org.ParentOrgID,
 		org.NPINumber, org.TINNumber, org.CLIANumber,
@@ -130,7 +130,7 @@ var organizationSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *orgRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Organization, int, error) {
 	qb := fhir.NewSearchQuery("organization", orgColumns)
-	qb.ApplyParams(params, organizationSearchParams)
+	qb.ApplyParams(ctx, params, organizationSearchParams)
 	qb.OrderBy("name")
 
 	var total int
@@ -138,6 +138,9 @@ func (r *orgRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	if err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -245,7 +248,8 @@ func (r *deptRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*Department, er
 func (r *deptRepoPG) Update(ctx context.Context, dept *Department) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE department SET
-			name = $2, code = $3, description = $4, head_practitioner_id = $5, active = $6
+			name = $2, code = $3, description = $4, head_practitioner_id = $5, active = $6,
+			version_id = version_id + 1
 		WHERE id = $1`,
 		dept.ID, dept.Name, dept.Code, dept.Description, dept.HeadPractitionerID, dept.Active,
 	)
@@ -346,7 +350,7 @@ func (r *locRepoPG) Update(ctx context.Context, loc *Location) error {
 			type_code=$7, type_display=$8, physical_type_code=$9,
 			organization_id=$10, part_of_location_id=$11,
 			address_line1=$12, city=$13, state=$14, postal_code=$15, country=$16,
-			latitude=$17, longitude=$18, phone=$19, email=$20
+			latitude=$17, longitude=$18, phone=$19, email=$20, version_id=version_id+1
 		WHERE id = $1`,
 		loc.ID, loc.Status, loc.OperationalStatus, loc.Name, loc.Description, loc.Mode,
 		loc.TypeCode, loc.TypeDisplay, loc.PhysicalTypeCode,
@@ -639,6 +643,9 @@ func (r *groupRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*Group, error)
 	if err != nil {
 		return nil, err
 	}
+	if !fhir.ProjectionFromContext(ctx).Includes("Group", "member") {
+		return g, nil
+	}
 	members, err := r.ListMembers(ctx, g.ID)
 	if err != nil {
 		return nil, err
@@ -652,6 +659,11 @@ func (r *groupRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Group, e
 	if err != nil {
 		return nil, err
 	}
+	// A read projected to elements without member skips the member table;
+	// quantity is kept on the group row.
+	if !fhir.ProjectionFromContext(ctx).Includes("Group", "member") {
+		return g, nil
+	}
 	members, err := r.ListMembers(ctx, g.ID)
 	if err != nil {
 		return nil, err
@@ -723,7 +735,7 @@ var groupSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *groupRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Group, int, error) {
 	qb := fhir.NewSearchQuery("fhir_group", grpColumns)
-	qb.ApplyParams(params, groupSearchParams)
+	qb.ApplyParams(ctx, params, groupSearchParams)
 	qb.OrderBy("name")
 
 	var total int
@@ -731,6 +743,9 @@ func (r *groupRepoPG) Search(ctx context.Context, params map[string]string, limi
 	if err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -832,3 +847,10 @@ func (r *groupRepoPG) scanGroupRow(rows pgx.Rows) (*Group, error) {
 	g.Type = GroupType(groupType)
 	return &g, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("organization", organizationSearchParams)
+	r.RegisterTable("fhir_group", groupSearchParams)
+}
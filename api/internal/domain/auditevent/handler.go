@@ -35,6 +35,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/AuditEvent/:id/_history", h.HistoryAuditEventFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("AuditEvent", fhir.ReadOnlyInteractions()...)
+}
+
 func (h *Handler) GetAuditEvent(c echo.Context) error {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -11,6 +11,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/ehr/ehr/internal/platform/db"
+	"github.com/ehr/ehr/internal/platform/fhir"
 )
 
 type queryable interface {
@@ -107,6 +108,16 @@ func (r *AuditEventRepoPG) Search(ctx context.Context, params map[string]string,
 		args = append(args, v)
 		idx++
 	}
+	if v, ok := params["patient"]; ok {
+		where = append(where, fmt.Sprintf("entity_what_type = 'Patient' AND entity_what_id = (SELECT id FROM patient WHERE fhir_id = $%d LIMIT 1)", idx))
+		args = append(args, strings.TrimPrefix(v, "Patient/"))
+		idx++
+	}
+
+	metaWhere, metaArgs := fhir.MetaSearchSQL("AuditEvent", params, idx)
+	where = append(where, metaWhere...)
+	args = append(args, metaArgs...)
+	idx += len(metaArgs)
 
 	whereClause := ""
 	if len(where) > 0 {
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Basic/:id/_history", h.HistoryBasicFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Basic", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateBasic(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetBasicFHIR(c echo.Context) error {
 
 func (h *Handler) CreateBasicFHIR(c echo.Context) error {
 	var b Basic
-	if err := c.Bind(&b); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Basic", "", &b); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateBasic(c.Request().Context(), &b); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateBasicFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateBasicFHIR(c echo.Context) error {
-	var b Basic
-	if err := c.Bind(&b); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetBasicByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Basic", c.Param("id")))
 	}
-	b.ID = existing.ID
-	b.FHIRID = existing.FHIRID
+	b := *existing
+	if err := fhir.BindResource(c, "Basic", existing.FHIRID, &b); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateBasic(c.Request().Context(), &b); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Basic", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,14 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if code, ok := patched["code"].(map[string]interface{}); ok {
-		if coding, ok := code["coding"].([]interface{}); ok && len(coding) > 0 {
-			if c0, ok := coding[0].(map[string]interface{}); ok {
-				if v, ok := c0["code"].(string); ok {
-					existing.CodeCode = v
-				}
-			}
-		}
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateBasic(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
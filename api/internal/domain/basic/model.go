@@ -56,6 +56,19 @@ func (b *Basic) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the Basic from a FHIR Basic resource, the inverse of
+// ToFHIR.
+func (b *Basic) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Basic", data)
+	code := r.CodeableConcept("code")
+	b.CodeCode = r.RequiredString("code.coding.code")
+	b.CodeSystem, b.CodeDisplay = code.System, code.Display
+	b.SubjectType, b.SubjectReference = r.ReferenceTarget("subject")
+	b.AuthorID = r.ReferenceID("author", "Practitioner")
+	b.AuthorDate = r.DateTime("created")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -72,7 +72,7 @@ func (r *basicRepoPG) Update(ctx context.Context, b *Basic) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE basic SET code_code=$2, code_system=$3, code_display=$4,
 			subject_type=$5, subject_reference=$6, author_id=$7, author_date=$8,
-			updated_at=NOW()
+			version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		b.ID, b.CodeCode, b.CodeSystem, b.CodeDisplay,
 		b.SubjectType, b.SubjectReference, b.AuthorID, b.AuthorDate)
@@ -113,13 +113,16 @@ var basicSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *basicRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Basic, int, error) {
 	qb := fhir.NewSearchQuery("basic", bscCols)
-	qb.ApplyParams(params, basicSearchParams)
+	qb.ApplyParams(ctx, params, basicSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -136,3 +139,9 @@ func (r *basicRepoPG) Search(ctx context.Context, params map[string]string, limi
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("basic", basicSearchParams)
+}
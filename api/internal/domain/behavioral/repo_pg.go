@@ -86,7 +86,7 @@ func (r *psychAssessmentRepoPG) Update(ctx context.Context, a *PsychiatricAssess
 		UPDATE psychiatric_assessment SET chief_complaint=$2, mental_status_exam=$3,
 			risk_assessment=$4, suicide_risk_level=$5, homicide_risk_level=$6,
 			diagnosis_code=$7, diagnosis_display=$8, formulation=$9, treatment_plan=$10,
-			disposition=$11, note=$12, updated_at=NOW()
+			disposition=$11, note=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		a.ID, a.ChiefComplaint, a.MentalStatusExam,
 		a.RiskAssessment, a.SuicideRiskLevel, a.HomicideRiskLevel,
@@ -128,13 +128,16 @@ var psychAssessmentSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *psychAssessmentRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*PsychiatricAssessment, int, error) {
 	qb := fhir.NewSearchQuery("psychiatric_assessment", psychAssessCols)
-	qb.ApplyParams(params, psychAssessmentSearchParams)
+	qb.ApplyParams(ctx, params, psychAssessmentSearchParams)
 	qb.OrderBy("assessment_date DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -208,7 +211,7 @@ func (r *safetyPlanRepoPG) Update(ctx context.Context, s *SafetyPlan) error {
 		UPDATE safety_plan SET status=$2, warning_signs=$3, coping_strategies=$4,
 			social_distractions=$5, people_to_contact=$6, professionals_to_contact=$7,
 			emergency_contacts=$8, means_restriction=$9, reasons_for_living=$10,
-			review_date=$11, note=$12, updated_at=NOW()
+			review_date=$11, note=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		s.ID, s.Status, s.WarningSigns, s.CopingStrategies,
 		s.SocialDistractions, s.PeopleToContact, s.ProfessionalsToContact,
@@ -250,13 +253,16 @@ var safetyPlanSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *safetyPlanRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*SafetyPlan, int, error) {
 	qb := fhir.NewSearchQuery("safety_plan", safetyPlanCols)
-	qb.ApplyParams(params, safetyPlanSearchParams)
+	qb.ApplyParams(ctx, params, safetyPlanSearchParams)
 	qb.OrderBy("plan_date DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -331,7 +337,7 @@ func (r *legalHoldRepoPG) Update(ctx context.Context, h *LegalHold) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE legal_hold SET status=$2, end_datetime=$3, release_reason=$4,
 			release_authorized_by_id=$5, court_hearing_date=$6, court_order_number=$7,
-			legal_counsel_notified=$8, patient_rights_given=$9, note=$10, updated_at=NOW()
+			legal_counsel_notified=$8, patient_rights_given=$9, note=$10, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		h.ID, h.Status, h.EndDatetime, h.ReleaseReason,
 		h.ReleaseAuthorizedByID, h.CourtHearingDate, h.CourtOrderNumber,
@@ -372,13 +378,16 @@ var legalHoldSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *legalHoldRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*LegalHold, int, error) {
 	qb := fhir.NewSearchQuery("legal_hold", legalHoldCols)
-	qb.ApplyParams(params, legalHoldSearchParams)
+	qb.ApplyParams(ctx, params, legalHoldSearchParams)
 	qb.OrderBy("start_datetime DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -494,13 +503,16 @@ var seclusionRestraintSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *seclusionRestraintRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*SeclusionRestraintEvent, int, error) {
 	qb := fhir.NewSearchQuery("seclusion_restraint_event", seclusionCols)
-	qb.ApplyParams(params, seclusionRestraintSearchParams)
+	qb.ApplyParams(ctx, params, seclusionRestraintSearchParams)
 	qb.OrderBy("start_datetime DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -608,13 +620,16 @@ var groupTherapySearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *groupTherapyRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*GroupTherapySession, int, error) {
 	qb := fhir.NewSearchQuery("group_therapy_session", groupSessionCols)
-	qb.ApplyParams(params, groupTherapySearchParams)
+	qb.ApplyParams(ctx, params, groupTherapySearchParams)
 	qb.OrderBy("scheduled_datetime DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -663,3 +678,13 @@ func (r *groupTherapyRepoPG) GetAttendance(ctx context.Context, sessionID uuid.U
 	}
 	return items, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("psychiatric_assessment", psychAssessmentSearchParams)
+	r.RegisterTable("safety_plan", safetyPlanSearchParams)
+	r.RegisterTable("legal_hold", legalHoldSearchParams)
+	r.RegisterTable("seclusion_restraint_event", seclusionRestraintSearchParams)
+	r.RegisterTable("group_therapy_session", groupTherapySearchParams)
+}
@@ -111,6 +111,14 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/ExplanationOfBenefit/:id/_history", h.HistoryEOBFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Coverage", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("Claim", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("ClaimResponse", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("Invoice", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("ExplanationOfBenefit", fhir.ReadOnlyInteractions()...)
+}
+
 // -- Coverage Handlers --
 
 func (h *Handler) CreateCoverage(c echo.Context) error {
@@ -511,8 +519,8 @@ func (h *Handler) GetCoverageFHIR(c echo.Context) error {
 
 func (h *Handler) CreateCoverageFHIR(c echo.Context) error {
 	var cov Coverage
-	if err := c.Bind(&cov); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Coverage", "", &cov); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateCoverage(c.Request().Context(), &cov); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -546,8 +554,8 @@ func (h *Handler) GetClaimFHIR(c echo.Context) error {
 
 func (h *Handler) CreateClaimFHIR(c echo.Context) error {
 	var cl Claim
-	if err := c.Bind(&cl); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Claim", "", &cl); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateClaim(c.Request().Context(), &cl); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -581,8 +589,8 @@ func (h *Handler) GetClaimResponseFHIR(c echo.Context) error {
 
 func (h *Handler) CreateClaimResponseFHIR(c echo.Context) error {
 	var cr ClaimResponse
-	if err := c.Bind(&cr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ClaimResponse", "", &cr); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateClaimResponse(c.Request().Context(), &cr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -616,8 +624,8 @@ func (h *Handler) GetEOBFHIR(c echo.Context) error {
 
 func (h *Handler) CreateEOBFHIR(c echo.Context) error {
 	var eob ExplanationOfBenefit
-	if err := c.Bind(&eob); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ExplanationOfBenefit", "", &eob); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateExplanationOfBenefit(c.Request().Context(), &eob); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -627,16 +635,14 @@ func (h *Handler) CreateEOBFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateEOBFHIR(c echo.Context) error {
-	var eob ExplanationOfBenefit
-	if err := c.Bind(&eob); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetExplanationOfBenefitByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ExplanationOfBenefit", c.Param("id")))
 	}
-	eob.ID = existing.ID
-	eob.FHIRID = existing.FHIRID
+	eob := *existing
+	if err := fhir.BindResource(c, "ExplanationOfBenefit", existing.FHIRID, &eob); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateExplanationOfBenefit(c.Request().Context(), &eob); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -660,8 +666,8 @@ func (h *Handler) PatchEOBFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ExplanationOfBenefit", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateExplanationOfBenefit(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -697,16 +703,14 @@ func (h *Handler) HistoryEOBFHIR(c echo.Context) error {
 // -- FHIR Update Endpoints --
 
 func (h *Handler) UpdateCoverageFHIR(c echo.Context) error {
-	var cov Coverage
-	if err := c.Bind(&cov); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetCoverageByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Coverage", c.Param("id")))
 	}
-	cov.ID = existing.ID
-	cov.FHIRID = existing.FHIRID
+	cov := *existing
+	if err := fhir.BindResource(c, "Coverage", existing.FHIRID, &cov); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateCoverage(c.Request().Context(), &cov); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -714,16 +718,14 @@ func (h *Handler) UpdateCoverageFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateClaimResponseFHIR(c echo.Context) error {
-	var cr ClaimResponse
-	if err := c.Bind(&cr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetClaimResponseByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ClaimResponse", c.Param("id")))
 	}
-	cr.ID = existing.ID
-	cr.FHIRID = existing.FHIRID
+	cr := *existing
+	if err := fhir.BindResource(c, "ClaimResponse", existing.FHIRID, &cr); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateClaimResponse(c.Request().Context(), &cr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -731,16 +733,14 @@ func (h *Handler) UpdateClaimResponseFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateClaimFHIR(c echo.Context) error {
-	var cl Claim
-	if err := c.Bind(&cl); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetClaimByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Claim", c.Param("id")))
 	}
-	cl.ID = existing.ID
-	cl.FHIRID = existing.FHIRID
+	cl := *existing
+	if err := fhir.BindResource(c, "Claim", existing.FHIRID, &cl); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateClaim(c.Request().Context(), &cl); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -790,8 +790,8 @@ func (h *Handler) PatchCoverageFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Coverage", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateCoverage(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -806,8 +806,8 @@ func (h *Handler) PatchClaimResponseFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ClaimResponse", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateClaimResponse(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -822,8 +822,8 @@ func (h *Handler) PatchClaimFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Claim", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateClaim(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -848,31 +848,31 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string, apply
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "Claim":
 		existing, err := h.svc.GetClaimByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "ClaimResponse":
 		existing, err := h.svc.GetClaimResponseByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "Invoice":
 		existing, err := h.svc.GetInvoiceByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "ExplanationOfBenefit":
 		existing, err := h.svc.GetExplanationOfBenefitByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	default:
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("unsupported resource type for PATCH"))
 	}
@@ -1005,8 +1005,8 @@ func (h *Handler) GetInvoiceFHIR(c echo.Context) error {
 
 func (h *Handler) CreateInvoiceFHIR(c echo.Context) error {
 	var inv Invoice
-	if err := c.Bind(&inv); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Invoice", "", &inv); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateInvoice(c.Request().Context(), &inv); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1016,16 +1016,14 @@ func (h *Handler) CreateInvoiceFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateInvoiceFHIR(c echo.Context) error {
-	var inv Invoice
-	if err := c.Bind(&inv); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetInvoiceByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Invoice", c.Param("id")))
 	}
-	inv.ID = existing.ID
-	inv.FHIRID = existing.FHIRID
+	inv := *existing
+	if err := fhir.BindResource(c, "Invoice", existing.FHIRID, &inv); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateInvoice(c.Request().Context(), &inv); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -1049,8 +1047,8 @@ func (h *Handler) PatchInvoiceFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Invoice", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateInvoice(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
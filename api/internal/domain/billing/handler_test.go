@@ -651,7 +651,7 @@ func TestHandler_GetCoverageFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateCoverageFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","payor_name":"Aetna"}`
+	body := `{"resourceType":"Coverage","beneficiary":{"reference":"Patient/` + uuid.New().String() + `"},"payor":[{"display":"Aetna"}]}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -674,7 +674,7 @@ func TestHandler_UpdateCoverageFHIR(t *testing.T) {
 	payorName := "Aetna"
 	cov := &Coverage{PatientID: uuid.New(), PayorName: &payorName}
 	h.svc.CreateCoverage(nil, cov)
-	body := `{"patient_id":"` + cov.PatientID.String() + `"}`
+	body := `{"resourceType":"Coverage","beneficiary":{"reference":"Patient/` + cov.PatientID.String() + `"},"payor":[{"display":"Aetna"}]}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -804,7 +804,7 @@ func TestHandler_GetClaimFHIR(t *testing.T) {
 
 func TestHandler_CreateClaimFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"Claim","patient":{"reference":"Patient/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -826,7 +826,7 @@ func TestHandler_UpdateClaimFHIR(t *testing.T) {
 	h, e := newTestHandler()
 	cl := &Claim{PatientID: uuid.New()}
 	h.svc.CreateClaim(nil, cl)
-	body := `{"patient_id":"` + cl.PatientID.String() + `"}`
+	body := `{"resourceType":"Claim","patient":{"reference":"Patient/` + cl.PatientID.String() + `"}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -952,7 +952,7 @@ func TestHandler_GetClaimResponseFHIR(t *testing.T) {
 
 func TestHandler_CreateClaimResponseFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"claim_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"ClaimResponse","request":{"reference":"Claim/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
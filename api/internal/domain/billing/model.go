@@ -110,6 +110,27 @@ func (c *Coverage) ToFHIR() map[string]interface{} {
 	return result
 }
 
+
+// FromFHIR populates the Coverage from a FHIR Coverage resource, the inverse
+// of ToFHIR. Only the first payor is read.
+func (c *Coverage) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Coverage", data)
+	c.Status = r.CodeValue("status", "active", "cancelled", "draft", "entered-in-error")
+	c.TypeCode = r.ConceptCode("type")
+	c.PatientID = r.RequiredReferenceID("beneficiary", "Patient")
+	c.SubscriberID = r.String("subscriberId")
+	c.Relationship = r.ConceptCode("relationship")
+	c.PayorOrgID = r.ReferenceID("payor", "Organization")
+	c.PayorName = nil
+	if c.PayorOrgID == nil {
+		c.PayorName = r.String("payor.display")
+	}
+	c.PeriodStart, c.PeriodEnd = r.Period("period")
+	c.CoverageOrder = r.Int("order")
+	c.PolicyNumber = r.String("identifier.value")
+	return r.Err()
+}
+
 // Claim maps to the claim table (FHIR Claim resource).
 type Claim struct {
 	ID                    uuid.UUID  `db:"id" json:"id"`
@@ -215,6 +236,35 @@ func (cl *Claim) ToFHIR() map[string]interface{} {
 	return result
 }
 
+
+// FromFHIR populates the Claim from a FHIR Claim resource, the inverse of
+// ToFHIR. Diagnoses, procedures and items are managed through their own
+// endpoints.
+func (cl *Claim) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Claim", data)
+	cl.Status = r.CodeValue("status", "active", "cancelled", "draft", "entered-in-error")
+	cl.PatientID = r.RequiredReferenceID("patient", "Patient")
+	cl.TypeCode = r.ConceptCode("type")
+	cl.UseCode = r.Code("use", "claim", "preauthorization", "predetermination")
+	cl.ProviderID, cl.ProviderOrgID = nil, nil
+	if rt, _ := r.ReferenceTarget("provider", "Practitioner", "Organization"); rt != nil {
+		if *rt == "Practitioner" {
+			cl.ProviderID = r.ReferenceID("provider", "Practitioner")
+		} else {
+			cl.ProviderOrgID = r.ReferenceID("provider", "Organization")
+		}
+	}
+	cl.InsurerOrgID = r.ReferenceID("insurer", "Organization")
+	cl.CoverageID = r.ReferenceID("insurance.coverage", "Coverage")
+	cl.PriorityCode = r.ConceptCode("priority")
+	cl.BillablePeriodStart, cl.BillablePeriodEnd = r.Period("billablePeriod")
+	cl.TotalAmount = r.Decimal("total.value")
+	cl.Currency = r.String("total.currency")
+	cl.EncounterID = r.ReferenceID("encounter", "Encounter")
+	cl.FacilityID = r.ReferenceID("facility", "Location")
+	return r.Err()
+}
+
 // ClaimDiagnosis maps to the claim_diagnosis junction table.
 type ClaimDiagnosis struct {
 	ID                   uuid.UUID `db:"id" json:"id"`
@@ -357,6 +407,26 @@ func (cr *ClaimResponse) ToFHIR() map[string]interface{} {
 	return result
 }
 
+
+// FromFHIR populates the ClaimResponse from a FHIR ClaimResponse resource,
+// the inverse of ToFHIR. Only the first preAuthRef and processNote are read.
+func (cr *ClaimResponse) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ClaimResponse", data)
+	cr.Status = r.CodeValue("status", "active", "cancelled", "draft", "entered-in-error")
+	cr.ClaimID = r.RequiredReferenceID("request", "Claim")
+	cr.TypeCode = r.ConceptCode("type")
+	cr.UseCode = r.Code("use", "claim", "preauthorization", "predetermination")
+	cr.Outcome = r.Code("outcome", "queued", "complete", "error", "partial")
+	cr.Disposition = r.String("disposition")
+	cr.PreAuthRef = r.String("preAuthRef")
+	cr.PaymentAmount = r.Decimal("payment.amount.value")
+	cr.PaymentDate = r.DateTime("payment.date")
+	cr.PaymentTypeCode = r.ConceptCode("payment.type")
+	cr.TotalAmount = r.Decimal("total.amount.value")
+	cr.ProcessNote = r.String("processNote.text")
+	return r.Err()
+}
+
 // ClaimResponseItem maps to the claim_response_item table.
 type ClaimResponseItem struct {
 	ID                    uuid.UUID `db:"id" json:"id"`
@@ -480,6 +550,43 @@ func (eob *ExplanationOfBenefit) ToFHIR() map[string]interface{} {
 	return result
 }
 
+
+// FromFHIR populates the ExplanationOfBenefit from a FHIR
+// ExplanationOfBenefit resource, the inverse of ToFHIR. Only the submitted
+// and benefit totals are read.
+func (eob *ExplanationOfBenefit) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ExplanationOfBenefit", data)
+	eob.Status = r.CodeValue("status", "active", "cancelled", "draft", "entered-in-error")
+	eob.PatientID = r.RequiredReferenceID("patient", "Patient")
+	eob.TypeCode = r.ConceptCode("type")
+	eob.UseCode = r.Code("use", "claim", "preauthorization", "predetermination")
+	eob.Outcome = r.Code("outcome", "queued", "complete", "error", "partial")
+	eob.Disposition = r.String("disposition")
+	eob.InsurerOrgID = r.ReferenceID("insurer", "Organization")
+	eob.ProviderID = r.ReferenceID("provider", "Practitioner")
+	eob.ClaimID = r.ReferenceID("claim", "Claim")
+	eob.ClaimResponseID = r.ReferenceID("claimResponse", "ClaimResponse")
+	eob.CoverageID = r.ReferenceID("insurance.coverage", "Coverage")
+	eob.BillablePeriodStart, eob.BillablePeriodEnd = r.Period("billablePeriod")
+	eob.TotalSubmitted, eob.TotalBenefit, eob.Currency = nil, nil, nil
+	if item, ok := r.Find("total", func(item string) bool { return r.StringValue(item+".category.coding.code") == "submitted" }); ok {
+		eob.TotalSubmitted = r.Decimal(item + ".amount.value")
+		eob.Currency = r.String(item + ".amount.currency")
+	}
+	if item, ok := r.Find("total", func(item string) bool { return r.StringValue(item+".category.coding.code") == "benefit" }); ok {
+		eob.TotalBenefit = r.Decimal(item + ".amount.value")
+		if eob.Currency == nil {
+			eob.Currency = r.String(item + ".amount.currency")
+		}
+	}
+	eob.TotalPayment = r.Decimal("payment.amount.value")
+	eob.PaymentDate = r.DateTime("payment.date")
+	if eob.Currency == nil {
+		eob.Currency = r.String("payment.amount.currency")
+	}
+	return r.Err()
+}
+
 // Invoice maps to the invoice table (FHIR Invoice resource).
 type Invoice struct {
 	ID             uuid.UUID  `db:"id" json:"id"`
@@ -572,6 +679,29 @@ func (inv *Invoice) ToFHIR() map[string]interface{} {
 	return result
 }
 
+
+// FromFHIR populates the Invoice from a FHIR Invoice resource, the inverse of
+// ToFHIR. Line items are managed through their own endpoints.
+func (inv *Invoice) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Invoice", data)
+	inv.Status = r.CodeValue("status", "draft", "issued", "balanced", "cancelled", "entered-in-error")
+	inv.PatientID = r.RequiredReferenceID("subject", "Patient")
+	inv.TypeCode = r.ConceptCode("type")
+	inv.EncounterID = r.ReferenceID("encounter", "Encounter")
+	inv.IssuerOrgID = r.ReferenceID("issuer", "Organization")
+	inv.Date = r.DateTime("date")
+	inv.ParticipantID = r.ReferenceID("participant.actor", "Practitioner")
+	inv.TotalNet = r.Decimal("totalNet.value")
+	inv.TotalGross = r.Decimal("totalGross.value")
+	inv.Currency = r.String("totalNet.currency")
+	if inv.Currency == nil {
+		inv.Currency = r.String("totalGross.currency")
+	}
+	inv.PaymentTerms = r.String("paymentTerms")
+	inv.Note = r.Annotation("note")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -1,9 +1,11 @@
 package billing
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/ehr/ehr/internal/platform/fhir"
 	"github.com/google/uuid"
 )
 
@@ -662,3 +664,108 @@ func TestExplanationOfBenefitToFHIR_PaymentWithoutDate(t *testing.T) {
 		t.Error("expected payment.date to be absent when PaymentDate is nil")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestExplanationOfBenefit_FromFHIR_RoundTrip(t *testing.T) {
+	claimID := uuid.New()
+	providerID := uuid.New()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	orig := &ExplanationOfBenefit{
+		FHIRID:              "eob-rt",
+		Status:              "active",
+		PatientID:           uuid.New(),
+		TypeCode:            ptrStr("professional"),
+		UseCode:             ptrStr("claim"),
+		Outcome:             ptrStr("complete"),
+		ClaimID:             &claimID,
+		ProviderID:          &providerID,
+		BillablePeriodStart: &start,
+		TotalSubmitted:      ptrFloat(500),
+		TotalBenefit:        ptrFloat(400),
+		TotalPayment:        ptrFloat(350),
+		Currency:            ptrStr("USD"),
+	}
+
+	var got ExplanationOfBenefit
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Status != "active" || got.PatientID != orig.PatientID || *got.TypeCode != "professional" {
+		t.Errorf("status/patient/type mismatch: %+v", got)
+	}
+	if *got.UseCode != "claim" || *got.Outcome != "complete" {
+		t.Errorf("use/outcome mismatch: %+v", got)
+	}
+	if got.ClaimID == nil || *got.ClaimID != claimID || got.ProviderID == nil || *got.ProviderID != providerID {
+		t.Errorf("references mismatch: %+v", got)
+	}
+	if got.BillablePeriodStart == nil || !got.BillablePeriodStart.Equal(start) {
+		t.Errorf("billablePeriod mismatch: %v", got.BillablePeriodStart)
+	}
+	if *got.TotalSubmitted != 500 || *got.TotalBenefit != 400 || *got.TotalPayment != 350 || *got.Currency != "USD" {
+		t.Errorf("totals mismatch: %+v", got)
+	}
+}
+
+func TestClaim_FromFHIR_ProviderOrganization(t *testing.T) {
+	orgID := uuid.New()
+	orig := &Claim{FHIRID: "claim-rt", Status: "active", PatientID: uuid.New(), ProviderOrgID: &orgID, TotalAmount: ptrFloat(125.5), Currency: ptrStr("INR")}
+
+	var got Claim
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.ProviderID != nil || got.ProviderOrgID == nil || *got.ProviderOrgID != orgID {
+		t.Errorf("expected organization provider, got %+v", got)
+	}
+	if *got.TotalAmount != 125.5 || *got.Currency != "INR" {
+		t.Errorf("total mismatch: %+v", got)
+	}
+}
+
+func TestCoverage_FromFHIR_PayorName(t *testing.T) {
+	orig := &Coverage{FHIRID: "cov-rt", Status: "active", PatientID: uuid.New(), PayorName: ptrStr("Aetna"), PolicyNumber: ptrStr("POL-1")}
+
+	var got Coverage
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.PatientID != orig.PatientID || got.PayorOrgID != nil || *got.PayorName != "Aetna" || *got.PolicyNumber != "POL-1" {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+}
+
+func TestInvoice_FromFHIR_InvalidElements(t *testing.T) {
+	var inv Invoice
+	err := inv.FromFHIR(map[string]interface{}{
+		"resourceType": "Invoice",
+		"status":       "paid",
+		"subject":      map[string]interface{}{"reference": "Group/" + uuid.New().String()},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	if len(pe.Outcome.Issue) != 2 {
+		t.Fatalf("expected 2 issues, got %+v", pe.Outcome.Issue)
+	}
+	if pe.Outcome.Issue[0].Expression[0] != "Invoice.status" || pe.Outcome.Issue[1].Expression[0] != "Invoice.subject.reference" {
+		t.Errorf("unexpected issues: %+v", pe.Outcome.Issue)
+	}
+}
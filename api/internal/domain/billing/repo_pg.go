@@ -101,7 +101,7 @@ func (r *coverageRepoPG) Update(ctx context.Context, c *Coverage) error {
 		UPDATE coverage SET status=$2, type_code=$3, payor_org_id=$4, payor_name=$5,
 			policy_number=$6, group_number=$7, plan_name=$8,
 			period_start=$9, period_end=$10, network=$11,
-			copay_amount=$12, deductible_amount=$13, note=$14, updated_at=NOW()
+			copay_amount=$12, deductible_amount=$13, note=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		c.ID, c.Status, c.TypeCode, c.PayorOrgID, c.PayorName,
 		c.PolicyNumber, c.GroupNumber, c.PlanName,
@@ -144,13 +144,16 @@ var coverageSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *coverageRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Coverage, int, error) {
 	qb := fhir.NewSearchQuery("coverage", covCols)
-	qb.ApplyParams(params, coverageSearchParams)
+	qb.ApplyParams(ctx, params, coverageSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -241,7 +244,7 @@ func (r *claimRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Claim, e
 func (r *claimRepoPG) Update(ctx context.Context, c *Claim) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE claim SET status=$2, type_code=$3, use_code=$4,
-			coverage_id=$5, total_amount=$6, currency=$7, updated_at=NOW()
+			coverage_id=$5, total_amount=$6, currency=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		c.ID, c.Status, c.TypeCode, c.UseCode,
 		c.CoverageID, c.TotalAmount, c.Currency)
@@ -282,13 +285,16 @@ var claimSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *claimRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Claim, int, error) {
 	qb := fhir.NewSearchQuery("claim", claimCols)
-	qb.ApplyParams(params, claimSearchParams)
+	qb.ApplyParams(ctx, params, claimSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -479,7 +485,7 @@ func (r *claimResponseRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*
 func (r *claimResponseRepoPG) Update(ctx context.Context, cr *ClaimResponse) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE claim_response SET status=$2, outcome=$3, disposition=$4,
-			payment_amount=$5, total_amount=$6, process_note=$7
+			payment_amount=$5, total_amount=$6, process_note=$7, version_id=version_id+1
 		WHERE id = $1`,
 		cr.ID, cr.Status, cr.Outcome, cr.Disposition,
 		cr.PaymentAmount, cr.TotalAmount, cr.ProcessNote)
@@ -520,13 +526,16 @@ var claimResponseSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *claimResponseRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ClaimResponse, int, error) {
 	qb := fhir.NewSearchQuery("claim_response", crCols)
-	qb.ApplyParams(params, claimResponseSearchParams)
+	qb.ApplyParams(ctx, params, claimResponseSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -657,13 +666,16 @@ var eobSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *eobRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ExplanationOfBenefit, int, error) {
 	qb := fhir.NewSearchQuery("explanation_of_benefit", eobCols)
-	qb.ApplyParams(params, eobSearchParams)
+	qb.ApplyParams(ctx, params, eobSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -740,7 +752,7 @@ func (r *invoiceRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Invoic
 func (r *invoiceRepoPG) Update(ctx context.Context, inv *Invoice) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE invoice SET status=$2, total_net=$3, total_gross=$4, total_tax=$5,
-			payment_terms=$6, note=$7
+			payment_terms=$6, note=$7, version_id=version_id+1
 		WHERE id = $1`,
 		inv.ID, inv.Status, inv.TotalNet, inv.TotalGross, inv.TotalTax,
 		inv.PaymentTerms, inv.Note)
@@ -780,13 +792,16 @@ var invoiceSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *invoiceRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Invoice, int, error) {
 	qb := fhir.NewSearchQuery("invoice", invCols)
-	qb.ApplyParams(params, invoiceSearchParams)
+	qb.ApplyParams(ctx, params, invoiceSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -839,3 +854,13 @@ func (r *invoiceRepoPG) GetLineItems(ctx context.Context, invoiceID uuid.UUID) (
 	}
 	return items, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("coverage", coverageSearchParams)
+	r.RegisterTable("claim", claimSearchParams)
+	r.RegisterTable("claim_response", claimResponseSearchParams)
+	r.RegisterTable("explanation_of_benefit", eobSearchParams)
+	r.RegisterTable("invoice", invoiceSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/BiologicallyDerivedProduct/:id/_history", h.HistoryBiologicallyDerivedProductFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("BiologicallyDerivedProduct", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateBiologicallyDerivedProduct(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetBiologicallyDerivedProductFHIR(c echo.Context) error {
 
 func (h *Handler) CreateBiologicallyDerivedProductFHIR(c echo.Context) error {
 	var b BiologicallyDerivedProduct
-	if err := c.Bind(&b); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "BiologicallyDerivedProduct", "", &b); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateBiologicallyDerivedProduct(c.Request().Context(), &b); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateBiologicallyDerivedProductFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateBiologicallyDerivedProductFHIR(c echo.Context) error {
-	var b BiologicallyDerivedProduct
-	if err := c.Bind(&b); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetBiologicallyDerivedProductByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("BiologicallyDerivedProduct", c.Param("id")))
 	}
-	b.ID = existing.ID
-	b.FHIRID = existing.FHIRID
+	b := *existing
+	if err := fhir.BindResource(c, "BiologicallyDerivedProduct", existing.FHIRID, &b); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateBiologicallyDerivedProduct(c.Request().Context(), &b); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("BiologicallyDerivedProduct", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = &v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateBiologicallyDerivedProduct(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
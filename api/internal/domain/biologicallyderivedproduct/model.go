@@ -91,6 +91,25 @@ func (b *BiologicallyDerivedProduct) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the product from a FHIR BiologicallyDerivedProduct
+// resource, the inverse of ToFHIR.
+func (b *BiologicallyDerivedProduct) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("BiologicallyDerivedProduct", data)
+	b.ProductCategory = r.Code("productCategory", "organ", "tissue", "fluid", "cells", "biologicalAgent")
+	productCode := r.CodeableConcept("productCode")
+	b.ProductCodeCode, b.ProductCodeDisplay = productCode.Code, productCode.Display
+	b.Status = r.Code("status", "available", "unavailable")
+	b.RequestID = r.ReferenceID("request", "ServiceRequest")
+	b.Quantity = r.Int("quantity")
+	b.ParentID = r.ReferenceID("parent", "BiologicallyDerivedProduct")
+	b.CollectionSourceType, b.CollectionSourceRef = r.ReferenceTarget("collection.source", "Patient", "Organization")
+	b.CollectionCollectedDate = r.DateTime("collection.collectedDateTime")
+	b.ProcessingDescription = r.String("processing.description")
+	b.StorageTemperatureCode = r.String("storage.temperature")
+	b.StorageDuration = r.String("storage.duration")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
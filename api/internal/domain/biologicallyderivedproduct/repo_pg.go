@@ -81,7 +81,7 @@ func (r *bdpRepoPG) Update(ctx context.Context, b *BiologicallyDerivedProduct) e
 		UPDATE biologically_derived_product SET product_category=$2, product_code_code=$3, product_code_display=$4,
 			status=$5, request_id=$6, quantity=$7, parent_id=$8,
 			collection_source_type=$9, collection_source_reference=$10, collection_collected_date=$11,
-			processing_description=$12, storage_temperature_code=$13, storage_duration=$14, updated_at=NOW()
+			processing_description=$12, storage_temperature_code=$13, storage_duration=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		b.ID, b.ProductCategory, b.ProductCodeCode, b.ProductCodeDisplay,
 		b.Status, b.RequestID, b.Quantity, b.ParentID,
@@ -123,13 +123,16 @@ var bdpSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *bdpRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*BiologicallyDerivedProduct, int, error) {
 	qb := fhir.NewSearchQuery("biologically_derived_product", bdpCols)
-	qb.ApplyParams(params, bdpSearchParams)
+	qb.ApplyParams(ctx, params, bdpSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -146,3 +149,9 @@ func (r *bdpRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("biologically_derived_product", bdpSearchParams)
+}
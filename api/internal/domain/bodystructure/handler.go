@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/BodyStructure/:id/_history", h.HistoryBodyStructureFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("BodyStructure", fhir.ReadOnlyInteractions()...)
+}
+
 func (h *Handler) CreateBodyStructure(c echo.Context) error {
 	var b BodyStructure
 	if err := c.Bind(&b); err != nil {
@@ -133,8 +137,8 @@ func (h *Handler) GetBodyStructureFHIR(c echo.Context) error {
 
 func (h *Handler) CreateBodyStructureFHIR(c echo.Context) error {
 	var b BodyStructure
-	if err := c.Bind(&b); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "BodyStructure", "", &b); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateBodyStructure(c.Request().Context(), &b); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -144,16 +148,14 @@ func (h *Handler) CreateBodyStructureFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateBodyStructureFHIR(c echo.Context) error {
-	var b BodyStructure
-	if err := c.Bind(&b); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetBodyStructureByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("BodyStructure", c.Param("id")))
 	}
-	b.ID = existing.ID
-	b.FHIRID = existing.FHIRID
+	b := *existing
+	if err := fhir.BindResource(c, "BodyStructure", existing.FHIRID, &b); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateBodyStructure(c.Request().Context(), &b); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -209,7 +211,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("BodyStructure", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -233,7 +235,9 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	_ = patched
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
+	}
 	if err := h.svc.UpdateBodyStructure(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
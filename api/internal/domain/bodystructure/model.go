@@ -58,6 +58,25 @@ func (b *BodyStructure) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the body structure from a FHIR BodyStructure resource,
+// the inverse of ToFHIR.
+func (b *BodyStructure) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("BodyStructure", data)
+	b.Active = true
+	if active := r.Bool("active"); active != nil {
+		b.Active = *active
+	}
+	b.PatientID = r.RequiredReferenceID("patient", "Patient")
+	morphology := r.CodeableConcept("morphology")
+	b.MorphologyCode, b.MorphologyDisplay, b.MorphologySystem = morphology.Code, morphology.Display, morphology.System
+	location := r.CodeableConcept("location")
+	b.LocationCode, b.LocationDisplay, b.LocationSystem = location.Code, location.Display, location.System
+	qualifier := r.CodeableConcept("locationQualifier")
+	b.LocationQualifierCode, b.LocationQualifierDisplay = qualifier.Code, qualifier.Display
+	b.Description = r.String("description")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
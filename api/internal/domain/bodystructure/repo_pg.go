@@ -81,7 +81,7 @@ func (r *bodyStructureRepoPG) Update(ctx context.Context, b *BodyStructure) erro
 		UPDATE body_structure SET active=$2, morphology_code=$3, morphology_display=$4, morphology_system=$5,
 			location_code=$6, location_display=$7, location_system=$8,
 			location_qualifier_code=$9, location_qualifier_display=$10,
-			description=$11, updated_at=NOW()
+			description=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		b.ID, b.Active, b.MorphologyCode, b.MorphologyDisplay, b.MorphologySystem,
 		b.LocationCode, b.LocationDisplay, b.LocationSystem,
@@ -122,13 +122,16 @@ var bodyStructureSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *bodyStructureRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*BodyStructure, int, error) {
 	qb := fhir.NewSearchQuery("body_structure", bsCols)
-	qb.ApplyParams(params, bodyStructureSearchParams)
+	qb.ApplyParams(ctx, params, bodyStructureSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -145,3 +148,9 @@ func (r *bodyStructureRepoPG) Search(ctx context.Context, params map[string]stri
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("body_structure", bodyStructureSearchParams)
+}
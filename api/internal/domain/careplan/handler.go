@@ -68,6 +68,11 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Goal/:id/_history", h.HistoryGoalFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("CarePlan", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("Goal", fhir.ReadOnlyInteractions()...)
+}
+
 // -- CarePlan REST --
 
 func (h *Handler) CreateCarePlan(c echo.Context) error {
@@ -276,8 +281,8 @@ func (h *Handler) GetCarePlanFHIR(c echo.Context) error {
 
 func (h *Handler) CreateCarePlanFHIR(c echo.Context) error {
 	var cp CarePlan
-	if err := c.Bind(&cp); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "CarePlan", "", &cp); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateCarePlan(c.Request().Context(), &cp); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -287,16 +292,14 @@ func (h *Handler) CreateCarePlanFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateCarePlanFHIR(c echo.Context) error {
-	var cp CarePlan
-	if err := c.Bind(&cp); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetCarePlanByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CarePlan", c.Param("id")))
 	}
-	cp.ID = existing.ID
-	cp.FHIRID = existing.FHIRID
+	cp := *existing
+	if err := fhir.BindResource(c, "CarePlan", existing.FHIRID, &cp); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateCarePlan(c.Request().Context(), &cp); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -320,8 +323,8 @@ func (h *Handler) PatchCarePlanFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CarePlan", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateCarePlan(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -390,8 +393,8 @@ func (h *Handler) GetGoalFHIR(c echo.Context) error {
 
 func (h *Handler) CreateGoalFHIR(c echo.Context) error {
 	var g Goal
-	if err := c.Bind(&g); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Goal", "", &g); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateGoal(c.Request().Context(), &g); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -401,16 +404,14 @@ func (h *Handler) CreateGoalFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateGoalFHIR(c echo.Context) error {
-	var g Goal
-	if err := c.Bind(&g); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetGoalByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Goal", c.Param("id")))
 	}
-	g.ID = existing.ID
-	g.FHIRID = existing.FHIRID
+	g := *existing
+	if err := fhir.BindResource(c, "Goal", existing.FHIRID, &g); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateGoal(c.Request().Context(), &g); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -434,8 +435,8 @@ func (h *Handler) PatchGoalFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Goal", ctx.Param("id")))
 		}
-		if v, ok := resource["lifecycleStatus"].(string); ok {
-			existing.LifecycleStatus = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateGoal(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -482,13 +483,13 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string, apply
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "Goal":
 		existing, err := h.svc.GetGoalByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	default:
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("unsupported resource type for PATCH"))
 	}
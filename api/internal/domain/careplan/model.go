@@ -75,6 +75,24 @@ func (cp *CarePlan) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the care plan from a FHIR CarePlan resource, the inverse
+// of ToFHIR. Activities are managed through their own endpoints.
+func (cp *CarePlan) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("CarePlan", data)
+	cp.Status = r.CodeValue("status", "draft", "active", "on-hold", "completed", "revoked", "entered-in-error")
+	cp.Intent = r.RequiredCode("intent", "proposal", "plan", "order", "option")
+	cp.PatientID = r.RequiredReferenceID("subject", "Patient")
+	category := r.CodeableConcept("category")
+	cp.CategoryCode, cp.CategoryDisplay = category.Code, category.Display
+	cp.Title = r.String("title")
+	cp.Description = r.String("description")
+	cp.EncounterID = r.ReferenceID("encounter", "Encounter")
+	cp.PeriodStart, cp.PeriodEnd = r.Period("period")
+	cp.AuthorID = r.ReferenceID("author", "Practitioner")
+	cp.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // CarePlanActivity maps to the care_plan_activity table.
 type CarePlanActivity struct {
 	ID             uuid.UUID  `db:"id" json:"id"`
@@ -162,6 +180,25 @@ func (g *Goal) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the goal from a FHIR Goal resource, the inverse of
+// ToFHIR. Only the first target is read.
+func (g *Goal) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Goal", data)
+	g.LifecycleStatus = r.CodeValue("lifecycleStatus", "proposed", "planned", "accepted", "active",
+		"on-hold", "completed", "cancelled", "entered-in-error", "rejected")
+	g.Description = r.RequiredString("description.text")
+	g.PatientID = r.RequiredReferenceID("subject", "Patient")
+	g.AchievementStatus = r.ConceptCode("achievementStatus")
+	category := r.CodeableConcept("category")
+	g.CategoryCode, g.CategoryDisplay = category.Code, category.Display
+	g.TargetMeasure = r.ConceptCode("target.measure")
+	g.TargetDetailString = r.String("target.detailString")
+	g.TargetDueDate = r.DateTime("target.dueDate")
+	g.ExpressedByID = r.ReferenceID("expressedBy", "Practitioner")
+	g.Note = r.Annotation("note")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -1,6 +1,7 @@
 package careplan
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -154,3 +155,69 @@ func TestGoal_ToFHIR_OptionalFieldsNil(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestGoal_FromFHIR_RoundTrip(t *testing.T) {
+	due := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	orig := &Goal{
+		FHIRID:             "goal-rt",
+		LifecycleStatus:    "active",
+		AchievementStatus:  ptrStr("in-progress"),
+		Description:        "Reduce HbA1c below 7%",
+		PatientID:          uuid.New(),
+		TargetMeasure:      ptrStr("4548-4"),
+		TargetDetailString: ptrStr("<7%"),
+		TargetDueDate:      &due,
+		ExpressedByID:      ptrUUID(uuid.New()),
+	}
+
+	var got Goal
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.LifecycleStatus != "active" || got.Description != orig.Description || got.PatientID != orig.PatientID {
+		t.Errorf("status/description/subject mismatch: %+v", got)
+	}
+	if *got.AchievementStatus != "in-progress" || *got.TargetMeasure != "4548-4" || *got.TargetDetailString != "<7%" {
+		t.Errorf("target mismatch: %+v", got)
+	}
+	if got.TargetDueDate == nil || !got.TargetDueDate.Equal(due) || *got.ExpressedByID != *orig.ExpressedByID {
+		t.Errorf("due date/expressedBy mismatch: %+v", got)
+	}
+}
+
+func TestCarePlan_FromFHIR_InvalidElements(t *testing.T) {
+	var cp CarePlan
+	err := cp.FromFHIR(map[string]interface{}{
+		"resourceType": "CarePlan",
+		"status":       "active",
+		"subject":      map[string]interface{}{"reference": "Patient/" + uuid.New().String()},
+		"period":       map[string]interface{}{"start": "2024-13-01"},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	if len(pe.Outcome.Issue) != 2 {
+		t.Fatalf("expected 2 issues, got %+v", pe.Outcome.Issue)
+	}
+	if pe.Outcome.Issue[0].Expression[0] != "CarePlan.intent" || pe.Outcome.Issue[1].Expression[0] != "CarePlan.period.start" {
+		t.Errorf("unexpected issues: %+v", pe.Outcome.Issue)
+	}
+}
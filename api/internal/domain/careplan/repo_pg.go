@@ -139,13 +139,16 @@ var carePlanSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *carePlanRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*CarePlan, int, error) {
 	qb := fhir.NewSearchQuery("care_plan", cpCols)
-	qb.ApplyParams(params, carePlanSearchParams)
+	qb.ApplyParams(ctx, params, carePlanSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -319,13 +322,16 @@ var goalSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *goalRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Goal, int, error) {
 	qb := fhir.NewSearchQuery("goal", goalCols)
-	qb.ApplyParams(params, goalSearchParams)
+	qb.ApplyParams(ctx, params, goalSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -342,3 +348,10 @@ func (r *goalRepoPG) Search(ctx context.Context, params map[string]string, limit
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("care_plan", carePlanSearchParams)
+	r.RegisterTable("goal", goalSearchParams)
+}
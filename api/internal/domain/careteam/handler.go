@@ -55,6 +55,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/CareTeam/:id/_history", h.HistoryCareTeamFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("CareTeam", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST handlers --
 
 func (h *Handler) CreateCareTeam(c echo.Context) error {
@@ -205,8 +209,8 @@ func (h *Handler) GetCareTeamFHIR(c echo.Context) error {
 
 func (h *Handler) CreateCareTeamFHIR(c echo.Context) error {
 	var ct CareTeam
-	if err := c.Bind(&ct); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "CareTeam", "", &ct); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateCareTeam(c.Request().Context(), &ct); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -216,16 +220,14 @@ func (h *Handler) CreateCareTeamFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateCareTeamFHIR(c echo.Context) error {
-	var ct CareTeam
-	if err := c.Bind(&ct); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetCareTeamByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CareTeam", c.Param("id")))
 	}
-	ct.ID = existing.ID
-	ct.FHIRID = existing.FHIRID
+	ct := *existing
+	if err := fhir.BindResource(c, "CareTeam", existing.FHIRID, &ct); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateCareTeam(c.Request().Context(), &ct); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -249,8 +251,8 @@ func (h *Handler) PatchCareTeamFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CareTeam", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateCareTeam(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -294,7 +296,7 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string, apply
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -57,7 +57,7 @@ func TestGetCareTeamFHIR_NotFound(t *testing.T) {
 
 func TestCreateCareTeamFHIR_Success(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","status":"active"}`
+	body := `{"resourceType":"CareTeam","subject":{"reference":"Patient/` + uuid.New().String() + `"},"status":"active"}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -86,6 +86,24 @@ func (ct *CareTeam) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the care team from a FHIR CareTeam resource, the inverse
+// of ToFHIR. Participants are managed through their own endpoints.
+func (ct *CareTeam) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("CareTeam", data)
+	ct.Status = r.RequiredCode("status", "proposed", "active", "suspended", "inactive", "entered-in-error")
+	ct.PatientID = r.RequiredReferenceID("subject", "Patient")
+	ct.Name = r.String("name")
+	category := r.CodeableConcept("category")
+	ct.CategoryCode, ct.CategoryDisplay = category.Code, category.Display
+	ct.EncounterID = r.ReferenceID("encounter", "Encounter")
+	ct.PeriodStart, ct.PeriodEnd = r.Period("period")
+	ct.ManagingOrganizationID = r.ReferenceID("managingOrganization", "Organization")
+	reason := r.CodeableConcept("reasonCode")
+	ct.ReasonCode, ct.ReasonDisplay = reason.Code, reason.Display
+	ct.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // CareTeamParticipant maps to the care_team_participant table.
 type CareTeamParticipant struct {
 	ID           uuid.UUID  `db:"id" json:"id"`
@@ -1,9 +1,11 @@
 package careteam
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/ehr/ehr/internal/platform/fhir"
 	"github.com/google/uuid"
 )
 
@@ -161,3 +163,64 @@ func TestCareTeamParticipant_ToFHIR(t *testing.T) {
 }
 
 func strPtr(s string) *string { return &s }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestCareTeam_FromFHIR_RoundTrip(t *testing.T) {
+	managingOrgID := uuid.New()
+	orig := &CareTeam{
+		FHIRID:                 "ct-rt",
+		Status:                 "active",
+		Name:                   strPtr("Oncology Team"),
+		PatientID:              uuid.New(),
+		CategoryCode:           strPtr("longitudinal"),
+		CategoryDisplay:        strPtr("Longitudinal Care Coordination"),
+		ManagingOrganizationID: &managingOrgID,
+		Note:                   strPtr("Weekly review"),
+	}
+
+	var got CareTeam
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Status != "active" || got.PatientID != orig.PatientID || *got.Name != "Oncology Team" {
+		t.Errorf("status/subject/name mismatch: %+v", got)
+	}
+	if *got.CategoryCode != "longitudinal" || *got.CategoryDisplay != "Longitudinal Care Coordination" {
+		t.Errorf("category mismatch: %+v", got)
+	}
+	if got.ManagingOrganizationID == nil || *got.ManagingOrganizationID != managingOrgID || *got.Note != "Weekly review" {
+		t.Errorf("organization/note mismatch: %+v", got)
+	}
+}
+
+func TestCareTeam_FromFHIR_InvalidStatus(t *testing.T) {
+	var ct CareTeam
+	err := ct.FromFHIR(map[string]interface{}{
+		"resourceType": "CareTeam",
+		"status":       "disbanded",
+		"subject":      map[string]interface{}{"reference": "Patient/" + uuid.New().String()},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	if len(pe.Outcome.Issue) != 1 || pe.Outcome.Issue[0].Expression[0] != "CareTeam.status" {
+		t.Errorf("unexpected issues: %+v", pe.Outcome.Issue)
+	}
+}
@@ -76,7 +76,7 @@ func (r *careTeamRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*CareT
 
 func (r *careTeamRepoPG) Update(ctx context.Context, ct *CareTeam) error {
 	_, err := r.conn(ctx).Exec(ctx, `
-		UPDATE care_team SET status=$2, name=$3, note=$4, updated_at=NOW()
+		UPDATE care_team SET status=$2, name=$3, note=$4, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ct.ID, ct.Status, ct.Name, ct.Note)
 	return err
@@ -116,13 +116,16 @@ var careTeamSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *careTeamRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*CareTeam, int, error) {
 	qb := fhir.NewSearchQuery("care_team", ctCols)
-	qb.ApplyParams(params, careTeamSearchParams)
+	qb.ApplyParams(ctx, params, careTeamSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -177,3 +180,9 @@ func (r *careTeamRepoPG) GetParticipants(ctx context.Context, careTeamID uuid.UU
 	}
 	return items, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("care_team", careTeamSearchParams)
+}
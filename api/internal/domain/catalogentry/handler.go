@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/CatalogEntry/:id/_history", h.HistoryCatalogEntryFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("CatalogEntry", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateCatalogEntry(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetCatalogEntryFHIR(c echo.Context) error {
 
 func (h *Handler) CreateCatalogEntryFHIR(c echo.Context) error {
 	var ce CatalogEntry
-	if err := c.Bind(&ce); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "CatalogEntry", "", &ce); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateCatalogEntry(c.Request().Context(), &ce); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateCatalogEntryFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateCatalogEntryFHIR(c echo.Context) error {
-	var ce CatalogEntry
-	if err := c.Bind(&ce); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetCatalogEntryByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CatalogEntry", c.Param("id")))
 	}
-	ce.ID = existing.ID
-	ce.FHIRID = existing.FHIRID
+	ce := *existing
+	if err := fhir.BindResource(c, "CatalogEntry", existing.FHIRID, &ce); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateCatalogEntry(c.Request().Context(), &ce); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CatalogEntry", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateCatalogEntry(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
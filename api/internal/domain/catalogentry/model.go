@@ -67,6 +67,22 @@ func (ce *CatalogEntry) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the catalog entry from a FHIR CatalogEntry resource, the
+// inverse of ToFHIR.
+func (ce *CatalogEntry) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("CatalogEntry", data)
+	ce.Type = r.String("type")
+	ce.Orderable = r.BoolValue("orderable")
+	ce.ReferencedItemType, ce.ReferencedItemReference = r.RequiredReferenceTarget("referencedItem")
+	ce.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	ce.EffectivePeriodStart, ce.EffectivePeriodEnd = r.Period("effectivePeriod")
+	ce.AdditionalIdentifier = r.IdentifierValue("additionalIdentifier", "")
+	classification := r.CodeableConcept("classification")
+	ce.ClassificationCode, ce.ClassificationDisplay = classification.Code, classification.Display
+	ce.ValidityPeriodStart, ce.ValidityPeriodEnd = r.Period("validityPeriod")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
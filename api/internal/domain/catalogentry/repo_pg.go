@@ -81,7 +81,7 @@ func (r *catalogEntryRepoPG) Update(ctx context.Context, ce *CatalogEntry) error
 		UPDATE catalog_entry SET type=$2, orderable=$3, referenced_item_type=$4, referenced_item_reference=$5,
 			status=$6, effective_period_start=$7, effective_period_end=$8,
 			additional_identifier=$9, classification_code=$10, classification_display=$11,
-			validity_period_start=$12, validity_period_end=$13, last_updated_ts=$14, updated_at=NOW()
+			validity_period_start=$12, validity_period_end=$13, last_updated_ts=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ce.ID, ce.Type, ce.Orderable, ce.ReferencedItemType, ce.ReferencedItemReference,
 		ce.Status, ce.EffectivePeriodStart, ce.EffectivePeriodEnd,
@@ -124,13 +124,16 @@ var catalogEntrySearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *catalogEntryRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*CatalogEntry, int, error) {
 	qb := fhir.NewSearchQuery("catalog_entry", ceCols)
-	qb.ApplyParams(params, catalogEntrySearchParams)
+	qb.ApplyParams(ctx, params, catalogEntrySearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -147,3 +150,9 @@ func (r *catalogEntryRepoPG) Search(ctx context.Context, params map[string]strin
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("catalog_entry", catalogEntrySearchParams)
+}
@@ -66,7 +66,7 @@ func (r *cdsRuleRepoPG) Update(ctx context.Context, rule *CDSRule) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE cds_rule SET rule_name=$2, rule_type=$3, description=$4, severity=$5, category=$6,
 			trigger_event=$7, condition_expr=$8, action_type=$9, action_detail=$10,
-			evidence_source=$11, evidence_url=$12, active=$13, version=$14, updated_at=NOW()
+			evidence_source=$11, evidence_url=$12, active=$13, version=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		rule.ID, rule.RuleName, rule.RuleType, rule.Description, rule.Severity, rule.Category,
 		rule.TriggerEvent, rule.ConditionExpr, rule.ActionType, rule.ActionDetail,
@@ -143,7 +143,7 @@ func (r *cdsAlertRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*CDSAlert,
 
 func (r *cdsAlertRepoPG) Update(ctx context.Context, a *CDSAlert) error {
 	_, err := r.conn(ctx).Exec(ctx, `
-		UPDATE cds_alert SET status=$2, resolved_at=$3, updated_at=NOW()
+		UPDATE cds_alert SET status=$2, resolved_at=$3, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		a.ID, a.Status, a.ResolvedAt)
 	return err
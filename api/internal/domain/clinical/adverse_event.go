@@ -103,6 +103,29 @@ func (a *AdverseEvent) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the event from a FHIR AdverseEvent resource, the inverse
+// of ToFHIR.
+func (a *AdverseEvent) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("AdverseEvent", data)
+	a.Actuality = r.CodeValue("actuality", "actual", "potential")
+	category := r.CodeableConcept("category")
+	a.CategoryCode, a.CategoryDisplay = category.Code, category.Display
+	event := r.CodeableConcept("event")
+	a.EventCode, a.EventDisplay, a.EventSystem = event.Code, event.Display, event.System
+	a.SubjectPatientID = r.RequiredReferenceID("subject", "Patient")
+	a.EncounterID = r.ReferenceID("encounter", "Encounter")
+	a.Date = r.DateTime("date")
+	a.Detected = r.DateTime("detected")
+	a.RecordedDate = r.DateTime("recordedDate")
+	a.RecorderID = r.ReferenceID("recorder", "Practitioner")
+	a.SeriousnessCode = r.CodeableConcept("seriousness").Code
+	a.SeverityCode = r.CodeableConcept("severity").Code
+	a.OutcomeCode = r.CodeableConcept("outcome").Code
+	a.LocationID = r.ReferenceID("location", "Location")
+	a.Description = r.String("description")
+	return r.Err()
+}
+
 // AdverseEventRepository defines the repository interface.
 type AdverseEventRepository interface {
 	Create(ctx context.Context, a *AdverseEvent) error
@@ -176,7 +199,7 @@ func (r *adverseEventRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*A
 func (r *adverseEventRepoPG) Update(ctx context.Context, a *AdverseEvent) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE adverse_event SET actuality=$2, seriousness_code=$3, severity_code=$4,
-			outcome_code=$5, description=$6, updated_at=NOW()
+			outcome_code=$5, description=$6, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		a.ID, a.Actuality, a.SeriousnessCode, a.SeverityCode,
 		a.OutcomeCode, a.Description)
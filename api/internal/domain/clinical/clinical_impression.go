@@ -89,6 +89,27 @@ func (ci *ClinicalImpression) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the impression from a FHIR ClinicalImpression resource,
+// the inverse of ToFHIR.
+func (ci *ClinicalImpression) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ClinicalImpression", data)
+	ci.Status = r.CodeValue("status", "in-progress", "completed", "entered-in-error")
+	ci.StatusReason = r.CodeableConcept("statusReason").Display
+	code := r.CodeableConcept("code")
+	ci.CodeCode, ci.CodeDisplay = code.Code, code.Display
+	ci.Description = r.String("description")
+	ci.SubjectPatientID = r.RequiredReferenceID("subject", "Patient")
+	ci.EncounterID = r.ReferenceID("encounter", "Encounter")
+	ci.EffectiveDate = r.DateTime("effectiveDateTime")
+	ci.Date = r.DateTime("date")
+	ci.AssessorID = r.ReferenceID("assessor", "Practitioner")
+	ci.Summary = r.String("summary")
+	prognosis := r.CodeableConcept("prognosisCodeableConcept")
+	ci.PrognosisCode, ci.PrognosisDisplay = prognosis.Code, prognosis.Display
+	ci.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // ClinicalImpressionRepository defines the repository interface.
 type ClinicalImpressionRepository interface {
 	Create(ctx context.Context, ci *ClinicalImpression) error
@@ -158,7 +179,7 @@ func (r *clinicalImpressionRepoPG) GetByFHIRID(ctx context.Context, fhirID strin
 func (r *clinicalImpressionRepoPG) Update(ctx context.Context, ci *ClinicalImpression) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE clinical_impression SET status=$2, status_reason=$3, summary=$4,
-			prognosis_code=$5, prognosis_display=$6, note=$7, updated_at=NOW()
+			prognosis_code=$5, prognosis_display=$6, note=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ci.ID, ci.Status, ci.StatusReason, ci.Summary,
 		ci.PrognosisCode, ci.PrognosisDisplay, ci.Note)
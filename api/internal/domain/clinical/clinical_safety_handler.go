@@ -110,6 +110,14 @@ func (h *ClinicalSafetyHandler) RegisterRoutes(api *echo.Group, fhirGroup *echo.
 	fr.GET("/RiskAssessment/:id/_history", h.HistoryRiskAssessmentFHIR)
 }
 
+func (h *ClinicalSafetyHandler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Flag", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("DetectedIssue", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("AdverseEvent", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("ClinicalImpression", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("RiskAssessment", fhir.ReadOnlyInteractions()...)
+}
+
 // ============ REST Handlers ============
 
 // -- Flag REST --
@@ -480,8 +488,8 @@ func (h *ClinicalSafetyHandler) GetFlagFHIR(c echo.Context) error {
 
 func (h *ClinicalSafetyHandler) CreateFlagFHIR(c echo.Context) error {
 	var f Flag
-	if err := c.Bind(&f); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Flag", "", &f); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateFlag(c.Request().Context(), &f); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -491,16 +499,14 @@ func (h *ClinicalSafetyHandler) CreateFlagFHIR(c echo.Context) error {
 }
 
 func (h *ClinicalSafetyHandler) UpdateFlagFHIR(c echo.Context) error {
-	var f Flag
-	if err := c.Bind(&f); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetFlagByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Flag", c.Param("id")))
 	}
-	f.ID = existing.ID
-	f.FHIRID = existing.FHIRID
+	f := *existing
+	if err := fhir.BindResource(c, "Flag", existing.FHIRID, &f); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateFlag(c.Request().Context(), &f); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -549,8 +555,8 @@ func (h *ClinicalSafetyHandler) GetDetectedIssueFHIR(c echo.Context) error {
 
 func (h *ClinicalSafetyHandler) CreateDetectedIssueFHIR(c echo.Context) error {
 	var d DetectedIssue
-	if err := c.Bind(&d); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "DetectedIssue", "", &d); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateDetectedIssue(c.Request().Context(), &d); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -560,16 +566,14 @@ func (h *ClinicalSafetyHandler) CreateDetectedIssueFHIR(c echo.Context) error {
 }
 
 func (h *ClinicalSafetyHandler) UpdateDetectedIssueFHIR(c echo.Context) error {
-	var d DetectedIssue
-	if err := c.Bind(&d); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetDetectedIssueByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DetectedIssue", c.Param("id")))
 	}
-	d.ID = existing.ID
-	d.FHIRID = existing.FHIRID
+	d := *existing
+	if err := fhir.BindResource(c, "DetectedIssue", existing.FHIRID, &d); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateDetectedIssue(c.Request().Context(), &d); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -618,8 +622,8 @@ func (h *ClinicalSafetyHandler) GetAdverseEventFHIR(c echo.Context) error {
 
 func (h *ClinicalSafetyHandler) CreateAdverseEventFHIR(c echo.Context) error {
 	var a AdverseEvent
-	if err := c.Bind(&a); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "AdverseEvent", "", &a); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateAdverseEvent(c.Request().Context(), &a); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -629,16 +633,14 @@ func (h *ClinicalSafetyHandler) CreateAdverseEventFHIR(c echo.Context) error {
 }
 
 func (h *ClinicalSafetyHandler) UpdateAdverseEventFHIR(c echo.Context) error {
-	var a AdverseEvent
-	if err := c.Bind(&a); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetAdverseEventByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("AdverseEvent", c.Param("id")))
 	}
-	a.ID = existing.ID
-	a.FHIRID = existing.FHIRID
+	a := *existing
+	if err := fhir.BindResource(c, "AdverseEvent", existing.FHIRID, &a); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateAdverseEvent(c.Request().Context(), &a); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -687,8 +689,8 @@ func (h *ClinicalSafetyHandler) GetClinicalImpressionFHIR(c echo.Context) error
 
 func (h *ClinicalSafetyHandler) CreateClinicalImpressionFHIR(c echo.Context) error {
 	var ci ClinicalImpression
-	if err := c.Bind(&ci); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ClinicalImpression", "", &ci); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateClinicalImpression(c.Request().Context(), &ci); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -698,16 +700,14 @@ func (h *ClinicalSafetyHandler) CreateClinicalImpressionFHIR(c echo.Context) err
 }
 
 func (h *ClinicalSafetyHandler) UpdateClinicalImpressionFHIR(c echo.Context) error {
-	var ci ClinicalImpression
-	if err := c.Bind(&ci); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetClinicalImpressionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ClinicalImpression", c.Param("id")))
 	}
-	ci.ID = existing.ID
-	ci.FHIRID = existing.FHIRID
+	ci := *existing
+	if err := fhir.BindResource(c, "ClinicalImpression", existing.FHIRID, &ci); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateClinicalImpression(c.Request().Context(), &ci); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -756,8 +756,8 @@ func (h *ClinicalSafetyHandler) GetRiskAssessmentFHIR(c echo.Context) error {
 
 func (h *ClinicalSafetyHandler) CreateRiskAssessmentFHIR(c echo.Context) error {
 	var ra RiskAssessment
-	if err := c.Bind(&ra); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "RiskAssessment", "", &ra); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateRiskAssessment(c.Request().Context(), &ra); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -767,16 +767,14 @@ func (h *ClinicalSafetyHandler) CreateRiskAssessmentFHIR(c echo.Context) error {
 }
 
 func (h *ClinicalSafetyHandler) UpdateRiskAssessmentFHIR(c echo.Context) error {
-	var ra RiskAssessment
-	if err := c.Bind(&ra); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetRiskAssessmentByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("RiskAssessment", c.Param("id")))
 	}
-	ra.ID = existing.ID
-	ra.FHIRID = existing.FHIRID
+	ra := *existing
+	if err := fhir.BindResource(c, "RiskAssessment", existing.FHIRID, &ra); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateRiskAssessment(c.Request().Context(), &ra); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -802,8 +800,8 @@ func (h *ClinicalSafetyHandler) PatchFlagFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Flag", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateFlag(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -818,8 +816,8 @@ func (h *ClinicalSafetyHandler) PatchDetectedIssueFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DetectedIssue", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateDetectedIssue(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -834,8 +832,8 @@ func (h *ClinicalSafetyHandler) PatchAdverseEventFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("AdverseEvent", ctx.Param("id")))
 		}
-		if v, ok := resource["actuality"].(string); ok {
-			existing.Actuality = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateAdverseEvent(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -850,8 +848,8 @@ func (h *ClinicalSafetyHandler) PatchClinicalImpressionFHIR(c echo.Context) erro
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ClinicalImpression", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateClinicalImpression(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -866,8 +864,8 @@ func (h *ClinicalSafetyHandler) PatchRiskAssessmentFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("RiskAssessment", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateRiskAssessment(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -890,31 +888,31 @@ func (h *ClinicalSafetyHandler) handleSafetyPatch(c echo.Context, resourceType,
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "DetectedIssue":
 		existing, err := h.svc.GetDetectedIssueByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "AdverseEvent":
 		existing, err := h.svc.GetAdverseEventByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "ClinicalImpression":
 		existing, err := h.svc.GetClinicalImpressionByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "RiskAssessment":
 		existing, err := h.svc.GetRiskAssessmentByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	default:
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("unsupported resource type for PATCH"))
 	}
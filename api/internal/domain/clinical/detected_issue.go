@@ -89,6 +89,26 @@ func (d *DetectedIssue) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the issue from a FHIR DetectedIssue resource, the
+// inverse of ToFHIR.
+func (d *DetectedIssue) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("DetectedIssue", data)
+	d.Status = r.CodeValue("status", "registered", "preliminary", "final", "amended",
+		"corrected", "cancelled", "entered-in-error", "unknown")
+	code := r.CodeableConcept("code")
+	d.CodeCode, d.CodeDisplay, d.CodeSystem = code.Code, code.Display, code.System
+	d.Severity = r.Code("severity", "high", "moderate", "low")
+	d.PatientID = r.ReferenceID("patient", "Patient")
+	d.IdentifiedDate = r.DateTime("identifiedDateTime")
+	d.AuthorPractitionerID = r.ReferenceID("author", "Practitioner")
+	d.Detail = r.String("detail")
+	d.ReferenceURL = r.String("reference")
+	d.MitigationAction = r.CodeableConcept("mitigation.action").Display
+	d.MitigationDate = r.DateTime("mitigation.date")
+	d.MitigationAuthorID = r.ReferenceID("mitigation.author", "Practitioner")
+	return r.Err()
+}
+
 // DetectedIssueRepository defines the repository interface.
 type DetectedIssueRepository interface {
 	Create(ctx context.Context, d *DetectedIssue) error
@@ -158,7 +178,7 @@ func (r *detectedIssueRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*
 func (r *detectedIssueRepoPG) Update(ctx context.Context, d *DetectedIssue) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE detected_issue SET status=$2, severity=$3, detail=$4,
-			mitigation_action=$5, mitigation_date=$6, mitigation_author_id=$7, updated_at=NOW()
+			mitigation_action=$5, mitigation_date=$6, mitigation_author_id=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		d.ID, d.Status, d.Severity, d.Detail,
 		d.MitigationAction, d.MitigationDate, d.MitigationAuthorID)
@@ -70,6 +70,23 @@ func (f *Flag) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the flag from a FHIR Flag resource, the inverse of
+// ToFHIR.
+func (f *Flag) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Flag", data)
+	f.Status = r.CodeValue("status", "active", "inactive", "entered-in-error")
+	code := r.CodeableConcept("code")
+	f.CodeSystem, f.CodeDisplay = code.System, code.Display
+	f.CodeCode = r.RequiredString("code.coding.code")
+	category := r.CodeableConcept("category")
+	f.CategoryCode, f.CategoryDisplay = category.Code, category.Display
+	f.SubjectPatientID = r.ReferenceID("subject", "Patient")
+	f.PeriodStart, f.PeriodEnd = r.Period("period")
+	f.EncounterID = r.ReferenceID("encounter", "Encounter")
+	f.AuthorPractitionerID = r.ReferenceID("author", "Practitioner")
+	return r.Err()
+}
+
 // FlagRepository defines the repository interface.
 type FlagRepository interface {
 	Create(ctx context.Context, f *Flag) error
@@ -136,7 +153,7 @@ func (r *flagRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Flag, err
 
 func (r *flagRepoPG) Update(ctx context.Context, f *Flag) error {
 	_, err := r.conn(ctx).Exec(ctx, `
-		UPDATE flag SET status=$2, code_code=$3, code_display=$4, period_start=$5, period_end=$6, updated_at=NOW()
+		UPDATE flag SET status=$2, code_code=$3, code_display=$4, period_start=$5, period_end=$6, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		f.ID, f.Status, f.CodeCode, f.CodeDisplay, f.PeriodStart, f.PeriodEnd)
 	return err
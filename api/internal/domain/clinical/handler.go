@@ -104,6 +104,13 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Procedure/:id/_history", h.HistoryProcedureFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Condition", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("Observation", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("AllergyIntolerance", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("Procedure", fhir.ReadOnlyInteractions()...)
+}
+
 // -- Condition Handlers --
 
 func (h *Handler) CreateCondition(c echo.Context) error {
@@ -512,8 +519,8 @@ func (h *Handler) GetConditionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateConditionFHIR(c echo.Context) error {
 	var cond Condition
-	if err := c.Bind(&cond); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Condition", "", &cond); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateCondition(c.Request().Context(), &cond); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -556,8 +563,8 @@ func (h *Handler) GetObservationFHIR(c echo.Context) error {
 
 func (h *Handler) CreateObservationFHIR(c echo.Context) error {
 	var obs Observation
-	if err := c.Bind(&obs); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Observation", "", &obs); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateObservation(c.Request().Context(), &obs); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -600,8 +607,8 @@ func (h *Handler) GetAllergyFHIR(c echo.Context) error {
 
 func (h *Handler) CreateAllergyFHIR(c echo.Context) error {
 	var a AllergyIntolerance
-	if err := c.Bind(&a); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "AllergyIntolerance", "", &a); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateAllergy(c.Request().Context(), &a); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -644,8 +651,8 @@ func (h *Handler) GetProcedureFHIR(c echo.Context) error {
 
 func (h *Handler) CreateProcedureFHIR(c echo.Context) error {
 	var p ProcedureRecord
-	if err := c.Bind(&p); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Procedure", "", &p); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateProcedure(c.Request().Context(), &p); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -657,16 +664,14 @@ func (h *Handler) CreateProcedureFHIR(c echo.Context) error {
 // -- FHIR Update Endpoints --
 
 func (h *Handler) UpdateConditionFHIR(c echo.Context) error {
-	var cond Condition
-	if err := c.Bind(&cond); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetConditionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Condition", c.Param("id")))
 	}
-	cond.ID = existing.ID
-	cond.FHIRID = existing.FHIRID
+	cond := *existing
+	if err := fhir.BindResource(c, "Condition", existing.FHIRID, &cond); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateCondition(c.Request().Context(), &cond); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -674,16 +679,14 @@ func (h *Handler) UpdateConditionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateObservationFHIR(c echo.Context) error {
-	var obs Observation
-	if err := c.Bind(&obs); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetObservationByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Observation", c.Param("id")))
 	}
-	obs.ID = existing.ID
-	obs.FHIRID = existing.FHIRID
+	obs := *existing
+	if err := fhir.BindResource(c, "Observation", existing.FHIRID, &obs); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateObservation(c.Request().Context(), &obs); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -691,16 +694,14 @@ func (h *Handler) UpdateObservationFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateAllergyFHIR(c echo.Context) error {
-	var a AllergyIntolerance
-	if err := c.Bind(&a); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetAllergyByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("AllergyIntolerance", c.Param("id")))
 	}
-	a.ID = existing.ID
-	a.FHIRID = existing.FHIRID
+	a := *existing
+	if err := fhir.BindResource(c, "AllergyIntolerance", existing.FHIRID, &a); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateAllergy(c.Request().Context(), &a); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -708,16 +709,14 @@ func (h *Handler) UpdateAllergyFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateProcedureFHIR(c echo.Context) error {
-	var p ProcedureRecord
-	if err := c.Bind(&p); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetProcedureByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Procedure", c.Param("id")))
 	}
-	p.ID = existing.ID
-	p.FHIRID = existing.FHIRID
+	p := *existing
+	if err := fhir.BindResource(c, "Procedure", existing.FHIRID, &p); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateProcedure(c.Request().Context(), &p); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -778,22 +777,8 @@ func (h *Handler) PatchConditionFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Condition", ctx.Param("id")))
 		}
-		// Apply patch result back to the model (simplified: update key fields)
-		if v, ok := resource["clinicalStatus"].(map[string]interface{}); ok {
-			if coding, ok := v["coding"].([]interface{}); ok && len(coding) > 0 {
-				if c, ok := coding[0].(map[string]interface{}); ok {
-					if code, ok := c["code"].(string); ok {
-						existing.ClinicalStatus = code
-					}
-				}
-			}
-		}
-		if v, ok := resource["note"].([]interface{}); ok && len(v) > 0 {
-			if n, ok := v[0].(map[string]interface{}); ok {
-				if text, ok := n["text"].(string); ok {
-					existing.Note = &text
-				}
-			}
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateCondition(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -808,8 +793,8 @@ func (h *Handler) PatchObservationFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Observation", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateObservation(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -824,6 +809,9 @@ func (h *Handler) PatchAllergyFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("AllergyIntolerance", ctx.Param("id")))
 		}
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
+		}
 		if err := h.svc.UpdateAllergy(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 		}
@@ -837,8 +825,8 @@ func (h *Handler) PatchProcedureFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Procedure", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateProcedure(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -863,25 +851,25 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string, apply
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "Observation":
 		existing, err := h.svc.GetObservationByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "AllergyIntolerance":
 		existing, err := h.svc.GetAllergyByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "Procedure":
 		existing, err := h.svc.GetProcedureByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	default:
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("unsupported resource type for PATCH"))
 	}
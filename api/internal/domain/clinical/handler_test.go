@@ -710,7 +710,7 @@ func TestHandler_GetConditionFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateConditionFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","code_value":"J06.9","code_display":"URI"}`
+	body := `{"resourceType":"Condition","subject":{"reference":"Patient/` + uuid.New().String() + `"},"code":{"coding":[{"code":"J06.9","display":"URI"}]}}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/Condition", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -733,7 +733,7 @@ func TestHandler_UpdateConditionFHIR(t *testing.T) {
 	cond := &Condition{PatientID: uuid.New(), CodeValue: "J06.9", CodeDisplay: "URI"}
 	h.svc.CreateCondition(nil, cond)
 
-	body := `{"clinical_status":"resolved","code_value":"J06.9","code_display":"URI"}`
+	body := `{"resourceType":"Condition","clinicalStatus":{"coding":[{"code":"resolved"}]},"subject":{"reference":"Patient/` + cond.PatientID.String() + `"},"code":{"coding":[{"code":"J06.9","display":"URI"}]}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -913,7 +913,7 @@ func TestHandler_GetObservationFHIR(t *testing.T) {
 
 func TestHandler_CreateObservationFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","code_value":"8310-5","code_display":"Body temp"}`
+	body := `{"resourceType":"Observation","status":"final","subject":{"reference":"Patient/` + uuid.New().String() + `"},"code":{"coding":[{"code":"8310-5","display":"Body temp"}]}}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/Observation", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -932,7 +932,7 @@ func TestHandler_UpdateObservationFHIR(t *testing.T) {
 	obs := &Observation{PatientID: uuid.New(), CodeValue: "8310-5", CodeDisplay: "Body temp"}
 	h.svc.CreateObservation(nil, obs)
 
-	body := `{"status":"amended"}`
+	body := `{"resourceType":"Observation","status":"amended","subject":{"reference":"Patient/` + obs.PatientID.String() + `"},"code":{"coding":[{"code":"8310-5","display":"Body temp"}]}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -1065,7 +1065,7 @@ func TestHandler_GetAllergyFHIR(t *testing.T) {
 
 func TestHandler_CreateAllergyFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"AllergyIntolerance","patient":{"reference":"Patient/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/AllergyIntolerance", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -1084,7 +1084,7 @@ func TestHandler_UpdateAllergyFHIR(t *testing.T) {
 	a := &AllergyIntolerance{PatientID: uuid.New()}
 	h.svc.CreateAllergy(nil, a)
 
-	body := `{"patient_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"AllergyIntolerance","patient":{"reference":"Patient/` + a.PatientID.String() + `"}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -1217,7 +1217,7 @@ func TestHandler_GetProcedureFHIR(t *testing.T) {
 
 func TestHandler_CreateProcedureFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","code_value":"80146002","code_display":"Appendectomy"}`
+	body := `{"resourceType":"Procedure","status":"completed","subject":{"reference":"Patient/` + uuid.New().String() + `"},"code":{"coding":[{"code":"80146002","display":"Appendectomy"}]}}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/Procedure", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -1236,7 +1236,7 @@ func TestHandler_UpdateProcedureFHIR(t *testing.T) {
 	p := &ProcedureRecord{PatientID: uuid.New(), CodeValue: "80146002", CodeDisplay: "Appendectomy"}
 	h.svc.CreateProcedure(nil, p)
 
-	body := `{"status":"completed"}`
+	body := `{"resourceType":"Procedure","status":"completed","subject":{"reference":"Patient/` + p.PatientID.String() + `"},"code":{"coding":[{"code":"80146002","display":"Appendectomy"}]}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -134,6 +134,34 @@ func (c *Condition) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the condition from a FHIR Condition resource, the
+// inverse of ToFHIR.
+func (c *Condition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Condition", data)
+	c.ClinicalStatus = strVal(r.ConceptCode("clinicalStatus",
+		"active", "recurrence", "relapse", "inactive", "remission", "resolved"))
+	c.VerificationStatus = r.ConceptCode("verificationStatus",
+		"unconfirmed", "provisional", "differential", "confirmed", "refuted", "entered-in-error")
+	c.CategoryCode = r.ConceptCode("category")
+	severity := r.CodeableConcept("severity")
+	c.SeverityCode, c.SeverityDisplay = severity.Code, severity.Display
+
+	code := r.CodeableConcept("code")
+	c.CodeSystem = code.System
+	c.CodeValue = r.RequiredString("code.coding.code")
+	c.CodeDisplay = strVal(code.Display)
+
+	c.PatientID = r.RequiredReferenceID("subject", "Patient")
+	c.EncounterID = r.ReferenceID("encounter", "Encounter")
+	c.OnsetDatetime = r.DateTime("onsetDateTime")
+	c.AbatementDatetime = r.DateTime("abatementDateTime")
+	bodySite := r.CodeableConcept("bodySite")
+	c.BodySiteCode, c.BodySiteDisplay = bodySite.Code, bodySite.Display
+	c.RecordedDate = r.DateTime("recordedDate")
+	c.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // Observation maps to the observation table (FHIR Observation resource).
 type Observation struct {
 	ID                    uuid.UUID  `db:"id" json:"id"`
@@ -272,6 +300,45 @@ func (o *Observation) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the observation from a FHIR Observation resource, the
+// inverse of ToFHIR. Only the value[x] types stored by the schema are
+// accepted.
+func (o *Observation) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Observation", data)
+	o.Status = r.CodeValue("status", "registered", "preliminary", "final", "amended",
+		"corrected", "cancelled", "entered-in-error", "unknown")
+	category := r.CodeableConcept("category")
+	o.CategoryCode, o.CategoryDisplay = category.Code, category.Display
+
+	code := r.CodeableConcept("code")
+	o.CodeSystem = code.System
+	o.CodeValue = r.RequiredString("code.coding.code")
+	o.CodeDisplay = strVal(code.Display)
+
+	o.PatientID = r.RequiredReferenceID("subject", "Patient")
+	o.EncounterID = r.ReferenceID("encounter", "Encounter")
+	o.EffectiveDatetime = r.DateTime("effectiveDateTime")
+
+	value := r.Quantity("valueQuantity")
+	o.ValueQuantity, o.ValueUnit, o.ValueSystem, o.ValueCode = value.Value, value.Unit, value.System, value.Code
+	o.ValueString = r.String("valueString")
+	o.ValueBoolean = r.Bool("valueBoolean")
+	o.ValueInteger = r.Int("valueInteger")
+	valueConcept := r.CodeableConcept("valueCodeableConcept")
+	o.ValueCodeableCode, o.ValueCodeableDisplay = valueConcept.Code, valueConcept.Display
+
+	o.ReferenceRangeLow = r.Decimal("referenceRange.low.value")
+	o.ReferenceRangeHigh = r.Decimal("referenceRange.high.value")
+	o.ReferenceRangeUnit = r.String("referenceRange.low.unit")
+	if o.ReferenceRangeUnit == nil {
+		o.ReferenceRangeUnit = r.String("referenceRange.high.unit")
+	}
+	interpretation := r.CodeableConcept("interpretation")
+	o.InterpretationCode, o.InterpretationDisplay = interpretation.Code, interpretation.Display
+	o.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // ObservationComponent maps to observation_component table.
 type ObservationComponent struct {
 	ID                    uuid.UUID `db:"id" json:"id"`
@@ -374,6 +441,29 @@ func (a *AllergyIntolerance) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the allergy from a FHIR AllergyIntolerance resource, the
+// inverse of ToFHIR.
+func (a *AllergyIntolerance) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("AllergyIntolerance", data)
+	a.PatientID = r.RequiredReferenceID("patient", "Patient")
+	a.ClinicalStatus = r.ConceptCode("clinicalStatus", "active", "inactive", "resolved")
+	a.VerificationStatus = r.ConceptCode("verificationStatus",
+		"unconfirmed", "confirmed", "refuted", "entered-in-error")
+	a.Type = r.Code("type", "allergy", "intolerance")
+	a.Category = nil
+	for i := 0; i < r.Len("category"); i++ {
+		if cat := r.Code(fmt.Sprintf("category[%d]", i), "food", "medication", "environment", "biologic"); cat != nil {
+			a.Category = append(a.Category, *cat)
+		}
+	}
+	a.Criticality = r.Code("criticality", "low", "high", "unable-to-assess")
+	code := r.CodeableConcept("code")
+	a.CodeSystem, a.CodeValue, a.CodeDisplay = code.System, code.Code, code.Display
+	a.OnsetDatetime = r.DateTime("onsetDateTime")
+	a.RecordedDate = r.DateTime("recordedDate")
+	return r.Err()
+}
+
 // AllergyReaction maps to allergy_reaction table.
 type AllergyReaction struct {
 	ID                   uuid.UUID  `db:"id" json:"id"`
@@ -487,6 +577,34 @@ func (p *ProcedureRecord) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the procedure from a FHIR Procedure resource, the
+// inverse of ToFHIR.
+func (p *ProcedureRecord) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Procedure", data)
+	p.Status = r.CodeValue("status", "preparation", "in-progress", "not-done", "on-hold",
+		"stopped", "completed", "entered-in-error", "unknown")
+	code := r.CodeableConcept("code")
+	p.CodeSystem = code.System
+	p.CodeValue = r.RequiredString("code.coding.code")
+	p.CodeDisplay = strVal(code.Display)
+	p.PatientID = r.RequiredReferenceID("subject", "Patient")
+
+	category := r.CodeableConcept("category")
+	p.CategoryCode, p.CategoryDisplay = category.Code, category.Display
+	p.EncounterID = r.ReferenceID("encounter", "Encounter")
+	p.PerformedDatetime = r.DateTime("performedDateTime")
+	p.PerformedStart, p.PerformedEnd = r.Period("performedPeriod")
+	bodySite := r.CodeableConcept("bodySite")
+	p.BodySiteCode, p.BodySiteDisplay = bodySite.Code, bodySite.Display
+	outcome := r.CodeableConcept("outcome")
+	p.OutcomeCode, p.OutcomeDisplay = outcome.Code, outcome.Display
+	reason := r.CodeableConcept("reasonCode")
+	p.ReasonCode, p.ReasonDisplay = reason.Code, reason.Display
+	p.LocationID = r.ReferenceID("location", "Location")
+	p.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // ProcedurePerformer maps to procedure_performer table.
 type ProcedurePerformer struct {
 	ID             uuid.UUID  `db:"id" json:"id"`
@@ -1,6 +1,7 @@
 package clinical
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -728,3 +729,151 @@ func TestProcedureRecord_ToFHIR_OptionalFieldsNil(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestCondition_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &Condition{
+		FHIRID:             "cond-rt",
+		PatientID:          uuid.New(),
+		EncounterID:        ptrUUID(uuid.New()),
+		ClinicalStatus:     "active",
+		VerificationStatus: ptrStr("confirmed"),
+		CategoryCode:       ptrStr("problem-list-item"),
+		SeverityCode:       ptrStr("24484000"),
+		SeverityDisplay:    ptrStr("Severe"),
+		CodeSystem:         ptrStr("http://hl7.org/fhir/sid/icd-10"),
+		CodeValue:          "J06.9",
+		CodeDisplay:        "URI",
+		OnsetDatetime:      ptrTime(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+		Note:               ptrStr("Follow up"),
+	}
+
+	var got Condition
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.PatientID != orig.PatientID || *got.EncounterID != *orig.EncounterID {
+		t.Errorf("references mismatch: %+v", got)
+	}
+	if got.ClinicalStatus != "active" || *got.VerificationStatus != "confirmed" || *got.CategoryCode != "problem-list-item" {
+		t.Errorf("status mismatch: %+v", got)
+	}
+	if got.CodeValue != "J06.9" || got.CodeDisplay != "URI" || *got.CodeSystem != *orig.CodeSystem {
+		t.Errorf("code mismatch: %+v", got)
+	}
+	if *got.SeverityCode != "24484000" || !got.OnsetDatetime.Equal(*orig.OnsetDatetime) || *got.Note != "Follow up" {
+		t.Errorf("optional fields mismatch: %+v", got)
+	}
+}
+
+func TestObservation_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &Observation{
+		FHIRID:             "obs-rt",
+		Status:             "final",
+		PatientID:          uuid.New(),
+		CodeValue:          "8310-5",
+		CodeDisplay:        "Body temp",
+		ValueQuantity:      ptrFloat(37.2),
+		ValueUnit:          ptrStr("Cel"),
+		ReferenceRangeLow:  ptrFloat(36.1),
+		ReferenceRangeHigh: ptrFloat(37.8),
+		ReferenceRangeUnit: ptrStr("Cel"),
+	}
+
+	var got Observation
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Status != "final" || got.PatientID != orig.PatientID || got.CodeValue != "8310-5" {
+		t.Errorf("required fields mismatch: %+v", got)
+	}
+	if got.ValueQuantity == nil || *got.ValueQuantity != 37.2 || *got.ValueUnit != "Cel" {
+		t.Errorf("valueQuantity mismatch: %+v", got)
+	}
+	if *got.ReferenceRangeLow != 36.1 || *got.ReferenceRangeHigh != 37.8 || *got.ReferenceRangeUnit != "Cel" {
+		t.Errorf("referenceRange mismatch: %+v", got)
+	}
+}
+
+func TestObservation_FromFHIR_InvalidElements(t *testing.T) {
+	var obs Observation
+	err := obs.FromFHIR(map[string]interface{}{
+		"resourceType": "Observation",
+		"status":       "done",
+		"subject":      map[string]interface{}{"reference": "Patient/not-a-uuid"},
+		"valueBoolean": "yes",
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	want := map[string]bool{
+		"Observation.status":            true,
+		"Observation.code.coding.code":  true,
+		"Observation.subject.reference": true,
+		"Observation.valueBoolean":      true,
+	}
+	for _, issue := range pe.Outcome.Issue {
+		delete(want, issue.Expression[0])
+	}
+	if len(want) != 0 {
+		t.Errorf("missing issues for %v in %+v", want, pe.Outcome.Issue)
+	}
+}
+
+func TestAllergyIntolerance_FromFHIR_Category(t *testing.T) {
+	var a AllergyIntolerance
+	err := a.FromFHIR(map[string]interface{}{
+		"resourceType": "AllergyIntolerance",
+		"patient":      map[string]interface{}{"reference": "Patient/" + uuid.New().String()},
+		"category":     []interface{}{"food", "medicine"},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	if len(pe.Outcome.Issue) != 1 || pe.Outcome.Issue[0].Expression[0] != "AllergyIntolerance.category[1]" {
+		t.Errorf("unexpected issues: %+v", pe.Outcome.Issue)
+	}
+	if len(a.Category) != 1 || a.Category[0] != "food" {
+		t.Errorf("Category = %v, want [food]", a.Category)
+	}
+}
+
+func TestProcedureRecord_FromFHIR_PerformedPeriod(t *testing.T) {
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	orig := &ProcedureRecord{
+		FHIRID:         "proc-rt",
+		Status:         "completed",
+		PatientID:      uuid.New(),
+		CodeValue:      "80146002",
+		CodeDisplay:    "Appendectomy",
+		PerformedStart: &start,
+		PerformedEnd:   &end,
+	}
+
+	var got ProcedureRecord
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.PerformedStart == nil || !got.PerformedStart.Equal(start) || !got.PerformedEnd.Equal(end) {
+		t.Errorf("performedPeriod mismatch: %v - %v", got.PerformedStart, got.PerformedEnd)
+	}
+}
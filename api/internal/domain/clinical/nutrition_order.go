@@ -293,6 +293,42 @@ func (n *NutritionOrder) ToFHIR() map[string]interface{} {
 // FHIR Mapping — fromFHIR
 // ---------------------------------------------------------------------------
 
+// FromFHIR populates the order from a FHIR NutritionOrder resource, the
+// inverse of ToFHIR. The header elements are validated strictly; the diet,
+// supplement and enteral formula components are read by NutritionOrderFromFHIR.
+func (n *NutritionOrder) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("NutritionOrder", data)
+	n.Status = r.CodeValue("status", "draft", "active", "on-hold", "revoked", "completed", "entered-in-error")
+	n.Intent = r.RequiredCode("intent", "proposal", "plan", "directive", "order",
+		"original-order", "reflex-order", "filler-order", "instance-order")
+	if dt := r.DateTime("dateTime"); dt != nil {
+		n.DateTime = *dt
+	}
+	n.PatientID = r.RequiredReferenceID("patient", "Patient")
+	n.EncounterID = r.ReferenceID("encounter", "Encounter")
+	n.Orderer = r.ReferenceID("orderer", "Practitioner")
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	parsed, err := NutritionOrderFromFHIR(body)
+	if err != nil {
+		return err
+	}
+	n.OralDiet = parsed.OralDiet
+	n.Supplement = parsed.Supplement
+	n.EnteralFormula = parsed.EnteralFormula
+	n.AllergyIntolerances = parsed.AllergyIntolerances
+	n.FoodPreferenceModifiers = parsed.FoodPreferenceModifiers
+	n.ExcludeFoodModifiers = parsed.ExcludeFoodModifiers
+	n.Note = parsed.Note
+	return nil
+}
+
 // NutritionOrderFromFHIR parses a FHIR NutritionOrder JSON into the domain model.
 func NutritionOrderFromFHIR(data []byte) (*NutritionOrder, error) {
 	var raw map[string]json.RawMessage
@@ -1303,6 +1339,10 @@ func (h *NutritionOrderHandler) RegisterNutritionOrderRoutes(api *echo.Group, fh
 	fw.PATCH("/NutritionOrder/:id", h.PatchNutritionOrderFHIR)
 }
 
+func (h *NutritionOrderHandler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("NutritionOrder", fhir.ReadOnlyInteractions()...)
+}
+
 func (h *NutritionOrderHandler) CreateNutritionOrder(c echo.Context) error {
 	var order NutritionOrder
 	if err := c.Bind(&order); err != nil {
@@ -1419,13 +1459,9 @@ func (h *NutritionOrderHandler) GetNutritionOrderFHIR(c echo.Context) error {
 }
 
 func (h *NutritionOrderHandler) CreateNutritionOrderFHIR(c echo.Context) error {
-	body, err := io.ReadAll(c.Request().Body)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("failed to read request body"))
-	}
-	order, err := NutritionOrderFromFHIR(body)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	order := &NutritionOrder{}
+	if err := fhir.BindResource(c, "NutritionOrder", "", order); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.Create(c.Request().Context(), order); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1435,21 +1471,15 @@ func (h *NutritionOrderHandler) CreateNutritionOrderFHIR(c echo.Context) error {
 }
 
 func (h *NutritionOrderHandler) UpdateNutritionOrderFHIR(c echo.Context) error {
-	body, err := io.ReadAll(c.Request().Body)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("failed to read request body"))
-	}
-	order, err := NutritionOrderFromFHIR(body)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("NutritionOrder", c.Param("id")))
 	}
-	order.ID = existing.ID
-	order.FHIRID = existing.FHIRID
-	if err := h.svc.Update(c.Request().Context(), order); err != nil {
+	order := *existing
+	if err := fhir.BindResource(c, "NutritionOrder", existing.FHIRID, &order); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
+	if err := h.svc.Update(c.Request().Context(), &order); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
 	return c.JSON(http.StatusOK, order.ToFHIR())
@@ -1477,7 +1507,7 @@ func (h *NutritionOrderHandler) PatchNutritionOrderFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("NutritionOrder", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -1503,12 +1533,8 @@ func (h *NutritionOrderHandler) PatchNutritionOrderFHIR(c echo.Context) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	// Apply patched fields back to model
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
-	}
-	if v, ok := patched["intent"].(string); ok {
-		existing.Intent = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.Update(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
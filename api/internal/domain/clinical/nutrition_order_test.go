@@ -930,6 +930,45 @@ func TestNutritionOrder_FromFHIR(t *testing.T) {
 	}
 }
 
+func TestNutritionOrder_FromFHIRMap_InvalidHeader(t *testing.T) {
+	order := &NutritionOrder{}
+	err := order.FromFHIR(map[string]interface{}{
+		"resourceType": "NutritionOrder",
+		"status":       "active",
+		"intent":       "suggestion",
+		"patient":      map[string]interface{}{"reference": "Encounter/" + uuid.New().String()},
+	})
+	if err == nil {
+		t.Fatal("expected error for invalid intent and patient reference")
+	}
+	for _, want := range []string{"NutritionOrder.intent", "NutritionOrder.patient.reference"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err.Error(), want)
+		}
+	}
+}
+
+func TestNutritionOrder_FromFHIRMap_RoundTrip(t *testing.T) {
+	orig := makeNutritionOrder(uuid.New())
+	orig.Note = "Low salt"
+	orig.FoodPreferenceModifiers = []string{"kosher"}
+
+	raw, _ := json.Marshal(orig.ToFHIR())
+	var data map[string]interface{}
+	json.Unmarshal(raw, &data)
+
+	got := &NutritionOrder{}
+	if err := got.FromFHIR(data); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.PatientID != orig.PatientID || got.Intent != orig.Intent || got.Status != orig.Status {
+		t.Errorf("header mismatch: %+v", got)
+	}
+	if got.Note != "Low salt" || len(got.FoodPreferenceModifiers) != 1 {
+		t.Errorf("note/modifiers mismatch: %+v", got)
+	}
+}
+
 // ── Handler Tests ──
 
 func TestNutritionOrderHandler_Create(t *testing.T) {
@@ -0,0 +1,154 @@
+package clinical
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ehr/ehr/internal/platform/fhir"
+)
+
+// NewLastNExecutor returns a fhir.LastNExecutor that serves Observation
+// $lastn from repo.
+func NewLastNExecutor(repo ObservationAggregateRepository) fhir.LastNExecutor {
+	return func(ctx context.Context, params fhir.LastNParams) ([]map[string]interface{}, error) {
+		obs, err := repo.LastN(ctx, ObservationLastNQuery{
+			Patient:    params.Patient,
+			Categories: splitTokens(params.Category),
+			Codes:      splitTokens(params.Code),
+			Max:        params.Max,
+		})
+		if err != nil {
+			return nil, err
+		}
+		resources := make([]map[string]interface{}, 0, len(obs))
+		for _, o := range obs {
+			resources = append(resources, o.ToFHIR())
+		}
+		return resources, nil
+	}
+}
+
+// NewStatsExecutor returns a fhir.StatsExecutor that serves Observation
+// $stats from repo. The period is either a list of date search values
+// ("ge2024-01-01,lt2025-01-01") or a "start/end" range. Values recorded in
+// commensurable UCUM units (mg/dL and g/L) are combined and reported in the
+// unit most of them were recorded in. When values remain in units that
+// cannot be combined, the statistics of the most used one are returned and
+// the observations of the others are counted in Excluded, which $stats
+// reports as warnings.
+func NewStatsExecutor(repo ObservationAggregateRepository) fhir.StatsExecutor {
+	return func(ctx context.Context, params fhir.StatsParams) (*fhir.StatsResult, error) {
+		code := params.Code
+		if params.System != "" && !strings.Contains(code, "|") {
+			code = params.System + "|" + code
+		}
+		rows, err := repo.Stats(ctx, ObservationStatsQuery{
+			Patient: params.Patient,
+			Codes:   []string{code},
+			Period:  periodSearchValues(params.Period),
+		})
+		if err != nil {
+			return nil, err
+		}
+		result := &fhir.StatsResult{Code: code, Subject: params.Patient, Period: params.Period}
+		var best *ObservationStats
+		for _, r := range rows {
+			if best == nil || r.Count > best.Count {
+				best = r
+			}
+		}
+		if best == nil {
+			return result, nil
+		}
+		for _, r := range rows {
+			if r != best {
+				result.Excluded = append(result.Excluded, fhir.StatsExcludedUnit{Unit: statsUnit(r), Count: r.Count})
+			}
+		}
+		best = statsInRecordedUnit(best)
+		result.Unit = best.Unit
+		result.Count = best.Count
+		result.Min = best.Min
+		result.Max = best.Max
+		result.Mean = best.Mean
+		result.Median = best.Median
+		result.StdDev = best.StdDev
+		result.Sum = best.Sum
+		result.P20 = best.P20
+		result.P80 = best.P80
+		return result, nil
+	}
+}
+
+// statsUnit returns the unit a group of statistics is reported in: the
+// unit its values were mostly recorded in, or the canonical UCUM unit that
+// groups them.
+func statsUnit(s *ObservationStats) string {
+	if s.Unit == "" {
+		return s.CanonicalUnit
+	}
+	return s.Unit
+}
+
+// statsInRecordedUnit converts statistics summarized in their canonical
+// UCUM unit to the unit the values were mostly recorded in. The spread
+// (standard deviation) is a difference of values, so a unit's offset, as
+// for Celsius, does not apply to it. When the conversion fails the
+// statistics stay in the canonical unit.
+func statsInRecordedUnit(s *ObservationStats) *ObservationStats {
+	if s.CanonicalUnit == "" || s.Unit == s.CanonicalUnit {
+		return s
+	}
+	origin, err := fhir.ConvertUCUM(0, s.CanonicalUnit, s.Unit)
+	if err != nil {
+		out := *s
+		out.Unit = s.CanonicalUnit
+		return &out
+	}
+	convert := func(v *float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		c, _ := fhir.ConvertUCUM(*v, s.CanonicalUnit, s.Unit)
+		return &c
+	}
+	out := *s
+	out.Min, out.Max, out.Mean = convert(s.Min), convert(s.Max), convert(s.Mean)
+	out.Median, out.P20, out.P80 = convert(s.Median), convert(s.P20), convert(s.P80)
+	if s.StdDev != nil {
+		sd := *convert(s.StdDev) - origin
+		out.StdDev = &sd
+	}
+	if out.Mean != nil {
+		sum := *out.Mean * float64(s.Count)
+		out.Sum = &sum
+	}
+	return &out
+}
+
+// splitTokens splits a comma-separated token parameter, dropping empty values.
+func splitTokens(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// periodSearchValues converts a $stats period into date search values on
+// the effective time.
+func periodSearchValues(period string) []string {
+	if start, end, ok := strings.Cut(period, "/"); ok {
+		var out []string
+		if start = strings.TrimSpace(start); start != "" {
+			out = append(out, "ge"+start)
+		}
+		if end = strings.TrimSpace(end); end != "" {
+			out = append(out, "le"+end)
+		}
+		return out
+	}
+	return splitTokens(period)
+}
@@ -0,0 +1,150 @@
+package clinical
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/ehr/ehr/internal/platform/fhir"
+	"github.com/google/uuid"
+)
+
+type fakeObservationAggregateRepo struct {
+	lastNQuery ObservationLastNQuery
+	statsQuery ObservationStatsQuery
+	obs        []*Observation
+	stats      []*ObservationStats
+}
+
+func (f *fakeObservationAggregateRepo) LastN(_ context.Context, q ObservationLastNQuery) ([]*Observation, error) {
+	f.lastNQuery = q
+	return f.obs, nil
+}
+
+func (f *fakeObservationAggregateRepo) Stats(_ context.Context, q ObservationStatsQuery) ([]*ObservationStats, error) {
+	f.statsQuery = q
+	return f.stats, nil
+}
+
+func (f *fakeObservationAggregateRepo) BackfillCanonical(context.Context) (int, error) {
+	return 0, nil
+}
+
+func TestLastNExecutor(t *testing.T) {
+	repo := &fakeObservationAggregateRepo{obs: []*Observation{
+		{ID: uuid.New(), FHIRID: "obs-1", Status: "final", CodeValue: "8480-6", PatientID: uuid.New()},
+	}}
+	exec := NewLastNExecutor(repo)
+
+	resources, err := exec(context.Background(), fhir.LastNParams{
+		Patient:  "Patient/123",
+		Category: "vital-signs, laboratory",
+		Code:     "http://loinc.org|8480-6",
+		Max:      3,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q := repo.lastNQuery
+	if q.Patient != "Patient/123" || q.Max != 3 {
+		t.Errorf("query = %+v", q)
+	}
+	if len(q.Categories) != 2 || q.Categories[1] != "laboratory" {
+		t.Errorf("categories = %v", q.Categories)
+	}
+	if len(q.Codes) != 1 || q.Codes[0] != "http://loinc.org|8480-6" {
+		t.Errorf("codes = %v", q.Codes)
+	}
+	if len(resources) != 1 || resources[0]["resourceType"] != "Observation" || resources[0]["id"] != "obs-1" {
+		t.Errorf("resources = %v", resources)
+	}
+}
+
+func TestStatsExecutor_MostUsedUnit(t *testing.T) {
+	mean := 120.0
+	repo := &fakeObservationAggregateRepo{stats: []*ObservationStats{
+		{CodeValue: "8480-6", Unit: "kPa", Count: 2},
+		{CodeValue: "8480-6", Unit: "mm[Hg]", Count: 8, Mean: &mean},
+	}}
+	exec := NewStatsExecutor(repo)
+
+	result, err := exec(context.Background(), fhir.StatsParams{
+		Patient: "Patient/123",
+		Code:    "8480-6",
+		System:  "http://loinc.org",
+		Period:  "2024-01-01/2024-12-31",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q := repo.statsQuery
+	if len(q.Codes) != 1 || q.Codes[0] != "http://loinc.org|8480-6" {
+		t.Errorf("codes = %v", q.Codes)
+	}
+	if len(q.Period) != 2 || q.Period[0] != "ge2024-01-01" || q.Period[1] != "le2024-12-31" {
+		t.Errorf("period = %v", q.Period)
+	}
+	if result.Unit != "mm[Hg]" || result.Count != 8 || result.Mean == nil || *result.Mean != 120 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Excluded) != 1 || result.Excluded[0] != (fhir.StatsExcludedUnit{Unit: "kPa", Count: 2}) {
+		t.Errorf("excluded = %+v", result.Excluded)
+	}
+}
+
+func TestStatsExecutor_MixedUnitSeries(t *testing.T) {
+	// 90 mg/dL, 1.1 g/L and 100 mg/dL of glucose, summarized by the
+	// repository in the canonical unit g.m-3 (900, 1100 and 1000).
+	f := func(v float64) *float64 { return &v }
+	repo := &fakeObservationAggregateRepo{stats: []*ObservationStats{
+		{CodeValue: "2345-7", Unit: "mg/dL", CanonicalUnit: "g.m-3", Count: 3,
+			Min: f(900), Max: f(1100), Mean: f(1000), Median: f(1000),
+			StdDev: f(100), Sum: f(3000), P20: f(940), P80: f(1060)},
+	}}
+	result, err := NewStatsExecutor(repo)(context.Background(), fhir.StatsParams{
+		Patient: "Patient/123",
+		Code:    "2345-7",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Unit != "mg/dL" || result.Count != 3 {
+		t.Fatalf("result = %+v", result)
+	}
+	for name, got := range map[string]*float64{
+		"min": result.Min, "max": result.Max, "mean": result.Mean, "median": result.Median,
+		"stddev": result.StdDev, "sum": result.Sum, "p20": result.P20, "p80": result.P80,
+	} {
+		want := map[string]float64{"min": 90, "max": 110, "mean": 100, "median": 100,
+			"stddev": 10, "sum": 300, "p20": 94, "p80": 106}[name]
+		if got == nil || math.Abs(*got-want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestStatsExecutor_TemperatureSpread(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	repo := &fakeObservationAggregateRepo{stats: []*ObservationStats{
+		{CodeValue: "8310-5", Unit: "Cel", CanonicalUnit: "K", Count: 2,
+			Mean: f(310.15), StdDev: f(0.5)},
+	}}
+	result, err := NewStatsExecutor(repo)(context.Background(), fhir.StatsParams{Code: "8310-5"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Unit != "Cel" || math.Abs(*result.Mean-37) > 1e-9 || math.Abs(*result.StdDev-0.5) > 1e-9 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestStatsExecutor_NoValues(t *testing.T) {
+	exec := NewStatsExecutor(&fakeObservationAggregateRepo{})
+	result, err := exec(context.Background(), fhir.StatsParams{Patient: "Patient/123", Code: "8480-6", Period: "ge2024-01-01"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Count != 0 || result.Unit != "" || result.Code != "8480-6" {
+		t.Errorf("result = %+v", result)
+	}
+}
@@ -31,6 +31,56 @@ type ObservationRepository interface {
 	GetComponents(ctx context.Context, observationID uuid.UUID) ([]*ObservationComponent, error)
 }
 
+// ObservationAggregateRepository answers the Observation $lastn and $stats
+// operations in the database, without loading every matching Observation.
+type ObservationAggregateRepository interface {
+	// LastN returns the most recent observations of each code.
+	LastN(ctx context.Context, q ObservationLastNQuery) ([]*Observation, error)
+	// Stats summarizes the quantity values of each code, per unit.
+	Stats(ctx context.Context, q ObservationStatsQuery) ([]*ObservationStats, error)
+	// BackfillCanonical stores the canonical UCUM value of observations
+	// written before it was recorded, and returns how many it updated.
+	BackfillCanonical(ctx context.Context) (int, error)
+}
+
+// ObservationLastNQuery selects the observations returned by $lastn. Each
+// list of tokens is OR'ed; empty lists do not filter.
+type ObservationLastNQuery struct {
+	Patient    string // patient reference
+	Categories []string
+	Codes      []string // system|code or code
+	Max        int      // observations per code
+}
+
+// ObservationStatsQuery selects the observations summarized by $stats.
+type ObservationStatsQuery struct {
+	Patient string   // patient reference
+	Codes   []string // system|code or code, OR'ed
+	Period  []string // date search values on the effective time, AND'ed
+}
+
+// ObservationStats summarizes the quantity values of one code in one unit.
+// Values in commensurable UCUM units are summarized together in their
+// canonical unit, named by CanonicalUnit; Unit is then the unit most of them
+// were recorded in. Other values are summarized per unit, with Unit the UCUM
+// code or the human-readable unit and CanonicalUnit empty.
+type ObservationStats struct {
+	CodeSystem    *string
+	CodeValue     string
+	CodeDisplay   string
+	Unit          string
+	CanonicalUnit string
+	Count         int
+	Min           *float64
+	Max           *float64
+	Mean          *float64
+	Median        *float64
+	StdDev        *float64
+	Sum           *float64
+	P20           *float64
+	P80           *float64
+}
+
 type AllergyRepository interface {
 	Create(ctx context.Context, a *AllergyIntolerance) error
 	GetByID(ctx context.Context, id uuid.UUID) (*AllergyIntolerance, error)
@@ -2,6 +2,9 @@ package clinical
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -84,7 +87,7 @@ func (r *conditionRepoPG) Update(ctx context.Context, c *Condition) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE condition SET clinical_status=$2, verification_status=$3, category_code=$4,
 			severity_code=$5, severity_display=$6, code_system=$7, code_value=$8, code_display=$9,
-			onset_datetime=$10, abatement_datetime=$11, note=$12, updated_at=NOW()
+			onset_datetime=$10, abatement_datetime=$11, note=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		c.ID, c.ClinicalStatus, c.VerificationStatus, c.CategoryCode,
 		c.SeverityCode, c.SeverityDisplay, c.CodeSystem, c.CodeValue, c.CodeDisplay,
@@ -150,13 +153,16 @@ var conditionSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *conditionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Condition, int, error) {
 	qb := fhir.NewSearchQuery("condition", condCols)
-	qb.ApplyParams(params, conditionSearchParams)
+	qb.ApplyParams(ctx, params, conditionSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -219,23 +225,44 @@ func (r *observationRepoPG) Create(ctx context.Context, o *Observation) error {
 	if o.FHIRID == "" {
 		o.FHIRID = o.ID.String()
 	}
+	canonicalValue, canonicalUnit := canonicalObsValue(o)
 	_, err := r.conn(ctx).Exec(ctx, `
 		INSERT INTO observation (id, fhir_id, status, category_code, category_display,
 			code_system, code_value, code_display, patient_id, encounter_id, performer_id,
 			effective_datetime, value_quantity, value_unit, value_system, value_code,
 			value_string, value_boolean, value_integer,
 			value_codeable_code, value_codeable_display,
-			interpretation_code, interpretation_display, note)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24)`,
+			interpretation_code, interpretation_display, note,
+			value_quantity_canonical, value_unit_canonical)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26)`,
 		o.ID, o.FHIRID, o.Status, o.CategoryCode, o.CategoryDisplay,
 		o.CodeSystem, o.CodeValue, o.CodeDisplay, o.PatientID, o.EncounterID, o.PerformerID,
 		o.EffectiveDatetime, o.ValueQuantity, o.ValueUnit, o.ValueSystem, o.ValueCode,
 		o.ValueString, o.ValueBoolean, o.ValueInteger,
 		o.ValueCodeableCode, o.ValueCodeableDisplay,
-		o.InterpretationCode, o.InterpretationDisplay, o.Note)
+		o.InterpretationCode, o.InterpretationDisplay, o.Note,
+		canonicalValue, canonicalUnit)
 	return err
 }
 
+// canonicalObsValue returns the observation's value quantity in its
+// canonical UCUM unit, or nils when it has none or its unit is not UCUM.
+// The unit text stands in for a missing unit code.
+func canonicalObsValue(o *Observation) (*float64, *string) {
+	if o.ValueQuantity == nil {
+		return nil, nil
+	}
+	code := strVal(o.ValueCode)
+	if code == "" {
+		code = strVal(o.ValueUnit)
+	}
+	value, unit, ok := fhir.CanonicalQuantity(*o.ValueQuantity, strVal(o.ValueSystem), code)
+	if !ok {
+		return nil, nil
+	}
+	return &value, &unit
+}
+
 func (r *observationRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*Observation, error) {
 	return r.scanObs(r.conn(ctx).QueryRow(ctx, `SELECT `+obsCols+` FROM observation WHERE id = $1`, id))
 }
@@ -245,12 +272,16 @@ func (r *observationRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Ob
 }
 
 func (r *observationRepoPG) Update(ctx context.Context, o *Observation) error {
+	canonicalValue, canonicalUnit := canonicalObsValue(o)
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE observation SET status=$2, value_quantity=$3, value_unit=$4, value_string=$5,
-			interpretation_code=$6, interpretation_display=$7, note=$8, updated_at=NOW()
+			interpretation_code=$6, interpretation_display=$7, note=$8,
+			value_system=$9, value_code=$10, value_quantity_canonical=$11, value_unit_canonical=$12,
+			version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		o.ID, o.Status, o.ValueQuantity, o.ValueUnit, o.ValueString,
-		o.InterpretationCode, o.InterpretationDisplay, o.Note)
+		o.InterpretationCode, o.InterpretationDisplay, o.Note,
+		o.ValueSystem, o.ValueCode, canonicalValue, canonicalUnit)
 	return err
 }
 
@@ -308,17 +339,23 @@ var observationSearchParams = map[string]fhir.SearchParamConfig{
 	"status":   {Type: fhir.SearchParamToken, Column: "status"},
 	"date":     {Type: fhir.SearchParamDate, Column: "effective_datetime"},
 	"_id":      {Type: fhir.SearchParamToken, Column: "fhir_id"},
+	"value-quantity": {Type: fhir.SearchParamQuantity, Column: "value_quantity", SysColumn: "value_system",
+		UnitColumn: "value_code", CanonicalColumn: "value_quantity_canonical", CanonicalUnitColumn: "value_unit_canonical",
+		AnalyteColumn: "code_value"},
 }
 
 func (r *observationRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Observation, int, error) {
 	qb := fhir.NewSearchQuery("observation", obsCols)
-	qb.ApplyParams(params, observationSearchParams)
+	qb.ApplyParams(ctx, params, observationSearchParams)
 	qb.OrderBy("effective_datetime DESC NULLS LAST")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -376,6 +413,185 @@ func (r *observationRepoPG) GetComponents(ctx context.Context, observationID uui
 	return items, nil
 }
 
+// NewObservationAggregateRepoPG creates the PostgreSQL repository backing
+// Observation $lastn and $stats.
+func NewObservationAggregateRepoPG(pool *pgxpool.Pool) ObservationAggregateRepository {
+	return &observationRepoPG{pool: pool}
+}
+
+// excludedObservationStatuses are never returned by $lastn or counted by $stats.
+const excludedObservationStatuses = `status NOT IN ('entered-in-error', 'cancelled')`
+
+// observationUnitExpr is the unit an observation was recorded in: the UCUM
+// code when the value is UCUM coded, otherwise the display unit.
+const observationUnitExpr = `COALESCE(CASE WHEN value_system = 'http://unitsofmeasure.org' THEN value_code END, value_unit, '')`
+
+// observationStatsValueExpr is the value $stats aggregates: the canonical
+// UCUM value when there is one, so that values recorded in commensurable
+// units combine, otherwise the value as recorded.
+const observationStatsValueExpr = `COALESCE(value_quantity_canonical, value_quantity)`
+
+// observationFilter builds the WHERE clause shared by $lastn and $stats,
+// starting at argument index 1.
+func observationFilter(patient string, categories, codes []string) (string, []interface{}, int) {
+	where := " AND " + excludedObservationStatuses
+	var args []interface{}
+	idx := 1
+	if patient != "" {
+		clause, a, next := fhir.ReferenceSearchClause("patient_id", patient, idx)
+		where += " AND " + clause
+		args, idx = append(args, a...), next
+	}
+	if len(categories) > 0 {
+		var ors []string
+		for _, cat := range categories {
+			if i := strings.LastIndex(cat, "|"); i >= 0 {
+				cat = cat[i+1:]
+			}
+			ors = append(ors, fmt.Sprintf("category_code = $%d", idx))
+			args = append(args, cat)
+			idx++
+		}
+		where += " AND (" + strings.Join(ors, " OR ") + ")"
+	}
+	if len(codes) > 0 {
+		var ors []string
+		for _, code := range codes {
+			clause, a, next := fhir.TokenSearchClause("code_system", "code_value", code, idx)
+			ors = append(ors, clause)
+			args, idx = append(args, a...), next
+		}
+		where += " AND (" + strings.Join(ors, " OR ") + ")"
+	}
+	return where, args, idx
+}
+
+// LastN ranks the matching observations of each code by effective time and
+// keeps the first q.Max.
+func (r *observationRepoPG) LastN(ctx context.Context, q ObservationLastNQuery) ([]*Observation, error) {
+	where, args, idx := observationFilter(q.Patient, q.Categories, q.Codes)
+	max := q.Max
+	if max < 1 {
+		max = 1
+	}
+	rows, err := r.conn(ctx).Query(ctx, `
+		SELECT `+obsCols+` FROM (
+			SELECT `+obsCols+`,
+				ROW_NUMBER() OVER (
+					PARTITION BY code_system, code_value
+					ORDER BY effective_datetime DESC NULLS LAST, created_at DESC
+				) AS rn
+			FROM observation WHERE 1=1`+where+`
+		) ranked
+		WHERE rn <= $`+strconv.Itoa(idx)+`
+		ORDER BY code_value, effective_datetime DESC NULLS LAST`,
+		append(args, max)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Observation
+	for rows.Next() {
+		o, err := r.scanObs(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, o)
+	}
+	return items, rows.Err()
+}
+
+// Stats aggregates the quantity values of the matching observations per code
+// and unit. Groups are ordered by code, the largest first.
+func (r *observationRepoPG) Stats(ctx context.Context, q ObservationStatsQuery) ([]*ObservationStats, error) {
+	where, args, idx := observationFilter(q.Patient, nil, q.Codes)
+	for _, period := range q.Period {
+		clause, a, next := fhir.DateSearchClause("effective_datetime", period, idx)
+		where += " AND " + clause
+		args, idx = append(args, a...), next
+	}
+	// Values with a canonical unit are grouped by it alone; the rest by the
+	// unit they were recorded in.
+	const v = observationStatsValueExpr
+	rows, err := r.conn(ctx).Query(ctx, `
+		SELECT code_system, code_value, MAX(code_display),
+			MODE() WITHIN GROUP (ORDER BY `+observationUnitExpr+`),
+			COALESCE(value_unit_canonical, ''),
+			COUNT(*),
+			MIN(`+v+`)::float8, MAX(`+v+`)::float8, AVG(`+v+`)::float8,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY `+v+`),
+			STDDEV_SAMP(`+v+`)::float8, SUM(`+v+`)::float8,
+			PERCENTILE_CONT(0.2) WITHIN GROUP (ORDER BY `+v+`),
+			PERCENTILE_CONT(0.8) WITHIN GROUP (ORDER BY `+v+`)
+		FROM observation
+		WHERE value_quantity IS NOT NULL`+where+`
+		GROUP BY code_system, code_value, value_unit_canonical,
+			CASE WHEN value_unit_canonical IS NULL THEN `+observationUnitExpr+` END
+		ORDER BY code_value, COUNT(*) DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ObservationStats
+	for rows.Next() {
+		var s ObservationStats
+		if err := rows.Scan(&s.CodeSystem, &s.CodeValue, &s.CodeDisplay, &s.Unit, &s.CanonicalUnit, &s.Count,
+			&s.Min, &s.Max, &s.Mean, &s.Median, &s.StdDev, &s.Sum, &s.P20, &s.P80); err != nil {
+			return nil, err
+		}
+		items = append(items, &s)
+	}
+	return items, rows.Err()
+}
+
+// canonicalBackfillBatch is the number of observations read per batch by
+// BackfillCanonical.
+const canonicalBackfillBatch = 500
+
+func (r *observationRepoPG) BackfillCanonical(ctx context.Context) (int, error) {
+	updated := 0
+	after := uuid.Nil
+	for {
+		rows, err := r.conn(ctx).Query(ctx, `
+			SELECT id, value_quantity::float8, value_unit, value_system, value_code
+			FROM observation
+			WHERE value_quantity IS NOT NULL AND value_unit_canonical IS NULL AND id > $1
+			ORDER BY id LIMIT $2`, after, canonicalBackfillBatch)
+		if err != nil {
+			return updated, err
+		}
+		var batch []*Observation
+		for rows.Next() {
+			var o Observation
+			if err := rows.Scan(&o.ID, &o.ValueQuantity, &o.ValueUnit, &o.ValueSystem, &o.ValueCode); err != nil {
+				rows.Close()
+				return updated, err
+			}
+			batch = append(batch, &o)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return updated, err
+		}
+		for _, o := range batch {
+			value, unit := canonicalObsValue(o)
+			if unit == nil {
+				continue
+			}
+			if _, err := r.conn(ctx).Exec(ctx, `
+				UPDATE observation SET value_quantity_canonical = $2, value_unit_canonical = $3
+				WHERE id = $1`, o.ID, value, unit); err != nil {
+				return updated, err
+			}
+			updated++
+		}
+		if len(batch) < canonicalBackfillBatch {
+			return updated, nil
+		}
+		after = batch[len(batch)-1].ID
+	}
+}
+
 // =========== Allergy Repository ===========
 
 type allergyRepoPG struct{ pool *pgxpool.Pool }
@@ -435,7 +651,7 @@ func (r *allergyRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Allerg
 func (r *allergyRepoPG) Update(ctx context.Context, a *AllergyIntolerance) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE allergy_intolerance SET clinical_status=$2, verification_status=$3, type=$4,
-			category=$5, criticality=$6, code_value=$7, code_display=$8, note=$9, updated_at=NOW()
+			category=$5, criticality=$6, code_value=$7, code_display=$8, note=$9, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		a.ID, a.ClinicalStatus, a.VerificationStatus, a.Type,
 		a.Category, a.Criticality, a.CodeValue, a.CodeDisplay, a.Note)
@@ -477,13 +693,16 @@ var allergySearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *allergyRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*AllergyIntolerance, int, error) {
 	qb := fhir.NewSearchQuery("allergy_intolerance", allergyCols)
-	qb.ApplyParams(params, allergySearchParams)
+	qb.ApplyParams(ctx, params, allergySearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -611,7 +830,7 @@ func (r *procedureRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Proc
 func (r *procedureRepoPG) Update(ctx context.Context, p *ProcedureRecord) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE procedure_record SET status=$2, outcome_code=$3, outcome_display=$4,
-			complication_code=$5, complication_display=$6, note=$7, updated_at=NOW()
+			complication_code=$5, complication_display=$6, note=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		p.ID, p.Status, p.OutcomeCode, p.OutcomeDisplay,
 		p.ComplicationCode, p.ComplicationDisp, p.Note)
@@ -654,13 +873,16 @@ var procedureSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *procedureRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ProcedureRecord, int, error) {
 	qb := fhir.NewSearchQuery("procedure_record", procCols)
-	qb.ApplyParams(params, procedureSearchParams)
+	qb.ApplyParams(ctx, params, procedureSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -710,3 +932,12 @@ func (r *procedureRepoPG) RemovePerformer(ctx context.Context, id uuid.UUID) err
 	_, err := r.conn(ctx).Exec(ctx, `DELETE FROM procedure_performer WHERE id = $1`, id)
 	return err
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("condition", conditionSearchParams)
+	r.RegisterTable("observation", observationSearchParams)
+	r.RegisterTable("allergy_intolerance", allergySearchParams)
+	r.RegisterTable("procedure_record", procedureSearchParams)
+}
@@ -91,6 +91,28 @@ func (ra *RiskAssessment) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the assessment from a FHIR RiskAssessment resource, the
+// inverse of ToFHIR.
+func (ra *RiskAssessment) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("RiskAssessment", data)
+	ra.Status = r.CodeValue("status", "registered", "preliminary", "final", "amended",
+		"corrected", "cancelled", "entered-in-error", "unknown")
+	method := r.CodeableConcept("method")
+	ra.MethodCode, ra.MethodDisplay = method.Code, method.Display
+	code := r.CodeableConcept("code")
+	ra.CodeCode, ra.CodeDisplay = code.Code, code.Display
+	ra.SubjectPatientID = r.RequiredReferenceID("subject", "Patient")
+	ra.EncounterID = r.ReferenceID("encounter", "Encounter")
+	ra.OccurrenceDate = r.DateTime("occurrenceDateTime")
+	ra.PerformerID = r.ReferenceID("performer", "Practitioner")
+	ra.PredictionOutcome = r.CodeableConcept("prediction.outcome").Display
+	ra.PredictionProbability = r.Decimal("prediction.probabilityDecimal")
+	ra.PredictionQualitative = r.CodeableConcept("prediction.qualitativeRisk").Display
+	ra.Mitigation = r.String("mitigation")
+	ra.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // RiskAssessmentRepository defines the repository interface.
 type RiskAssessmentRepository interface {
 	Create(ctx context.Context, ra *RiskAssessment) error
@@ -164,7 +186,7 @@ func (r *riskAssessmentRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (
 func (r *riskAssessmentRepoPG) Update(ctx context.Context, ra *RiskAssessment) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE risk_assessment SET status=$2, prediction_outcome=$3, prediction_probability=$4,
-			prediction_qualitative=$5, mitigation=$6, note=$7, updated_at=NOW()
+			prediction_qualitative=$5, mitigation=$6, note=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ra.ID, ra.Status, ra.PredictionOutcome, ra.PredictionProbability,
 		ra.PredictionQualitative, ra.Mitigation, ra.Note)
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/CodeSystem/:id/_history", h.HistoryCodeSystemFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("CodeSystem", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateCodeSystem(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetCodeSystemFHIR(c echo.Context) error {
 
 func (h *Handler) CreateCodeSystemFHIR(c echo.Context) error {
 	var cs CodeSystem
-	if err := c.Bind(&cs); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "CodeSystem", "", &cs); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateCodeSystem(c.Request().Context(), &cs); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateCodeSystemFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateCodeSystemFHIR(c echo.Context) error {
-	var cs CodeSystem
-	if err := c.Bind(&cs); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetCodeSystemByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CodeSystem", c.Param("id")))
 	}
-	cs.ID = existing.ID
-	cs.FHIRID = existing.FHIRID
+	cs := *existing
+	if err := fhir.BindResource(c, "CodeSystem", existing.FHIRID, &cs); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateCodeSystem(c.Request().Context(), &cs); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CodeSystem", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateCodeSystem(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
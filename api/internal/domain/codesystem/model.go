@@ -80,3 +80,23 @@ func (cs *CodeSystem) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the code system from a FHIR CodeSystem resource, the
+// inverse of ToFHIR.
+func (cs *CodeSystem) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("CodeSystem", data)
+	cs.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	cs.Content = r.RequiredCode("content", "not-present", "example", "fragment", "complete", "supplement")
+	cs.URL = r.String("url")
+	cs.Name = r.String("name")
+	cs.Title = r.String("title")
+	cs.Description = r.String("description")
+	cs.Publisher = r.String("publisher")
+	cs.Date = r.DateTime("date")
+	cs.ValueSetURI = r.String("valueSet")
+	cs.HierarchyMeaning = r.Code("hierarchyMeaning", "grouped-by", "is-a", "part-of", "classified-with")
+	cs.Compositional = r.BoolValue("compositional")
+	cs.VersionNeeded = r.BoolValue("versionNeeded")
+	cs.Count = r.Int("count")
+	return r.Err()
+}
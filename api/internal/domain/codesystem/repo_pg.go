@@ -76,7 +76,7 @@ func (r *codeSystemRepoPG) Update(ctx context.Context, cs *CodeSystem) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE code_system SET status=$2, url=$3, name=$4, title=$5, description=$6,
 			publisher=$7, date=$8, content=$9, value_set_uri=$10, hierarchy_meaning=$11,
-			compositional=$12, version_needed=$13, count=$14, updated_at=NOW()
+			compositional=$12, version_needed=$13, count=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		cs.ID, cs.Status, cs.URL, cs.Name, cs.Title, cs.Description,
 		cs.Publisher, cs.Date, cs.Content, cs.ValueSetURI, cs.HierarchyMeaning,
@@ -119,13 +119,16 @@ var codeSystemSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *codeSystemRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*CodeSystem, int, error) {
 	qb := fhir.NewSearchQuery("code_system", csCols)
-	qb.ApplyParams(params, codeSystemSearchParams)
+	qb.ApplyParams(ctx, params, codeSystemSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -142,3 +145,9 @@ func (r *codeSystemRepoPG) Search(ctx context.Context, params map[string]string,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("code_system", codeSystemSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/CommunicationRequest/:id/_history", h.HistoryCommunicationRequestFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("CommunicationRequest", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateCommunicationRequest(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetCommunicationRequestFHIR(c echo.Context) error {
 
 func (h *Handler) CreateCommunicationRequestFHIR(c echo.Context) error {
 	var cr CommunicationRequest
-	if err := c.Bind(&cr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "CommunicationRequest", "", &cr); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateCommunicationRequest(c.Request().Context(), &cr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateCommunicationRequestFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateCommunicationRequestFHIR(c echo.Context) error {
-	var cr CommunicationRequest
-	if err := c.Bind(&cr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetCommunicationRequestByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CommunicationRequest", c.Param("id")))
 	}
-	cr.ID = existing.ID
-	cr.FHIRID = existing.FHIRID
+	cr := *existing
+	if err := fhir.BindResource(c, "CommunicationRequest", existing.FHIRID, &cr); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateCommunicationRequest(c.Request().Context(), &cr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CommunicationRequest", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateCommunicationRequest(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
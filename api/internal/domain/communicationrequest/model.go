@@ -90,6 +90,31 @@ func (cr *CommunicationRequest) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the request from a FHIR CommunicationRequest resource,
+// the inverse of ToFHIR.
+func (cr *CommunicationRequest) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("CommunicationRequest", data)
+	cr.Status = r.CodeValue("status", "draft", "active", "on-hold", "revoked",
+		"completed", "entered-in-error", "unknown")
+	cr.PatientID = r.ReferenceID("subject", "Patient")
+	cr.EncounterID = r.ReferenceID("encounter", "Encounter")
+	cr.RequesterID = r.ReferenceID("requester", "Practitioner")
+	cr.RecipientID = r.ReferenceID("recipient", "Practitioner")
+	cr.SenderID = r.ReferenceID("sender", "Practitioner")
+	category := r.CodeableConcept("category")
+	cr.CategoryCode, cr.CategoryDisplay = category.Code, category.Display
+	cr.Priority = r.Code("priority", "routine", "urgent", "asap", "stat")
+	medium := r.CodeableConcept("medium")
+	cr.MediumCode, cr.MediumDisplay = medium.Code, medium.Display
+	cr.PayloadText = r.String("payload.contentString")
+	cr.OccurrenceDate = r.DateTime("occurrenceDateTime")
+	cr.AuthoredOn = r.DateTime("authoredOn")
+	reason := r.CodeableConcept("reasonCode")
+	cr.ReasonCode, cr.ReasonDisplay = reason.Code, reason.Display
+	cr.Note = r.Annotation("note")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -91,7 +91,7 @@ func (r *communicationRequestRepoPG) Update(ctx context.Context, cr *Communicati
 			category_code=$8, category_display=$9, priority=$10,
 			medium_code=$11, medium_display=$12, payload_text=$13,
 			occurrence_date=$14, authored_on=$15,
-			reason_code=$16, reason_display=$17, note=$18, updated_at=NOW()
+			reason_code=$16, reason_display=$17, note=$18, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		cr.ID, cr.Status, cr.PatientID, cr.EncounterID,
 		cr.RequesterID, cr.RecipientID, cr.SenderID,
@@ -137,13 +137,16 @@ var communicationRequestSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *communicationRequestRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*CommunicationRequest, int, error) {
 	qb := fhir.NewSearchQuery("communication_request", crCols)
-	qb.ApplyParams(params, communicationRequestSearchParams)
+	qb.ApplyParams(ctx, params, communicationRequestSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -160,3 +163,9 @@ func (r *communicationRequestRepoPG) Search(ctx context.Context, params map[stri
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("communication_request", communicationRequestSearchParams)
+}
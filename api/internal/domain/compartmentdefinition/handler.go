@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/CompartmentDefinition/:id/_history", h.HistoryCompartmentDefinitionFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("CompartmentDefinition", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateCompartmentDefinition(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetCompartmentDefinitionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateCompartmentDefinitionFHIR(c echo.Context) error {
 	var cd CompartmentDefinition
-	if err := c.Bind(&cd); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "CompartmentDefinition", "", &cd); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateCompartmentDefinition(c.Request().Context(), &cd); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateCompartmentDefinitionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateCompartmentDefinitionFHIR(c echo.Context) error {
-	var cd CompartmentDefinition
-	if err := c.Bind(&cd); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetCompartmentDefinitionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CompartmentDefinition", c.Param("id")))
 	}
-	cd.ID = existing.ID
-	cd.FHIRID = existing.FHIRID
+	cd := *existing
+	if err := fhir.BindResource(c, "CompartmentDefinition", existing.FHIRID, &cd); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateCompartmentDefinition(c.Request().Context(), &cd); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CompartmentDefinition", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateCompartmentDefinition(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -66,6 +66,23 @@ func (cd *CompartmentDefinition) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the compartment definition from a FHIR
+// CompartmentDefinition resource, the inverse of ToFHIR.
+func (cd *CompartmentDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("CompartmentDefinition", data)
+	cd.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	cd.URL = r.RequiredString("url")
+	cd.Name = r.RequiredString("name")
+	cd.Code = r.RequiredCode("code", "Patient", "Encounter", "RelatedPerson", "Practitioner", "Device")
+	cd.Search = r.BoolValue("search")
+	cd.Description = r.String("description")
+	cd.Publisher = r.String("publisher")
+	cd.Date = r.DateTime("date")
+	cd.ResourceType = r.String("resource.code")
+	cd.ResourceParam = r.String("resource.param.name")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -71,7 +71,7 @@ func (r *compartmentDefinitionRepoPG) GetByFHIRID(ctx context.Context, fhirID st
 func (r *compartmentDefinitionRepoPG) Update(ctx context.Context, cd *CompartmentDefinition) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE compartment_definition SET status=$2, url=$3, name=$4, description=$5, publisher=$6, date=$7,
-			code=$8, search=$9, resource_type=$10, resource_param=$11, updated_at=NOW()
+			code=$8, search=$9, resource_type=$10, resource_param=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		cd.ID, cd.Status, cd.URL, cd.Name, cd.Description, cd.Publisher, cd.Date,
 		cd.Code, cd.Search, cd.ResourceType, cd.ResourceParam)
@@ -113,13 +113,16 @@ var compartmentDefinitionSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *compartmentDefinitionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*CompartmentDefinition, int, error) {
 	qb := fhir.NewSearchQuery("compartment_definition", cdCols)
-	qb.ApplyParams(params, compartmentDefinitionSearchParams)
+	qb.ApplyParams(ctx, params, compartmentDefinitionSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -136,3 +139,9 @@ func (r *compartmentDefinitionRepoPG) Search(ctx context.Context, params map[str
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("compartment_definition", compartmentDefinitionSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/ConceptMap/:id/_history", h.HistoryConceptMapFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("ConceptMap", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateConceptMap(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetConceptMapFHIR(c echo.Context) error {
 
 func (h *Handler) CreateConceptMapFHIR(c echo.Context) error {
 	var cm ConceptMap
-	if err := c.Bind(&cm); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ConceptMap", "", &cm); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateConceptMap(c.Request().Context(), &cm); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateConceptMapFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateConceptMapFHIR(c echo.Context) error {
-	var cm ConceptMap
-	if err := c.Bind(&cm); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetConceptMapByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ConceptMap", c.Param("id")))
 	}
-	cm.ID = existing.ID
-	cm.FHIRID = existing.FHIRID
+	cm := *existing
+	if err := fhir.BindResource(c, "ConceptMap", existing.FHIRID, &cm); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateConceptMap(c.Request().Context(), &cm); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ConceptMap", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateConceptMap(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -70,3 +70,20 @@ func (cm *ConceptMap) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the concept map from a FHIR ConceptMap resource, the
+// inverse of ToFHIR.
+func (cm *ConceptMap) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ConceptMap", data)
+	cm.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	cm.URL = r.String("url")
+	cm.Name = r.String("name")
+	cm.Title = r.String("title")
+	cm.Description = r.String("description")
+	cm.Publisher = r.String("publisher")
+	cm.Date = r.DateTime("date")
+	cm.SourceURI = r.String("sourceUri")
+	cm.TargetURI = r.String("targetUri")
+	cm.Purpose = r.String("purpose")
+	return r.Err()
+}
@@ -73,7 +73,7 @@ func (r *conceptMapRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Con
 func (r *conceptMapRepoPG) Update(ctx context.Context, cm *ConceptMap) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE concept_map SET status=$2, url=$3, name=$4, title=$5, description=$6,
-			publisher=$7, date=$8, source_uri=$9, target_uri=$10, purpose=$11, updated_at=NOW()
+			publisher=$7, date=$8, source_uri=$9, target_uri=$10, purpose=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		cm.ID, cm.Status, cm.URL, cm.Name, cm.Title, cm.Description,
 		cm.Publisher, cm.Date, cm.SourceURI, cm.TargetURI, cm.Purpose)
@@ -116,13 +116,16 @@ var cmSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *conceptMapRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ConceptMap, int, error) {
 	qb := fhir.NewSearchQuery("concept_map", cmCols)
-	qb.ApplyParams(params, cmSearchParams)
+	qb.ApplyParams(ctx, params, cmSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -139,3 +142,9 @@ func (r *conceptMapRepoPG) Search(ctx context.Context, params map[string]string,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("concept_map", cmSearchParams)
+}
@@ -101,6 +101,13 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/MessageHeader/:id/_history", h.HistoryMessageHeaderFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("NamingSystem", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("OperationDefinition", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("MessageDefinition", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("MessageHeader", fhir.ReadOnlyInteractions()...)
+}
+
 // ==================== NamingSystem REST ====================
 
 func (h *Handler) CreateNamingSystem(c echo.Context) error {
@@ -428,8 +435,8 @@ func (h *Handler) GetNamingSystemFHIR(c echo.Context) error {
 
 func (h *Handler) CreateNamingSystemFHIR(c echo.Context) error {
 	var ns NamingSystem
-	if err := c.Bind(&ns); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "NamingSystem", "", &ns); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateNamingSystem(c.Request().Context(), &ns); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -439,16 +446,14 @@ func (h *Handler) CreateNamingSystemFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateNamingSystemFHIR(c echo.Context) error {
-	var ns NamingSystem
-	if err := c.Bind(&ns); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetNamingSystemByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("NamingSystem", c.Param("id")))
 	}
-	ns.ID = existing.ID
-	ns.FHIRID = existing.FHIRID
+	ns := *existing
+	if err := fhir.BindResource(c, "NamingSystem", existing.FHIRID, &ns); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateNamingSystem(c.Request().Context(), &ns); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -472,11 +477,8 @@ func (h *Handler) PatchNamingSystemFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("NamingSystem", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
-		}
-		if v, ok := resource["name"].(string); ok {
-			existing.Name = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateNamingSystem(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -536,8 +538,8 @@ func (h *Handler) GetOperationDefinitionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateOperationDefinitionFHIR(c echo.Context) error {
 	var od OperationDefinition
-	if err := c.Bind(&od); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "OperationDefinition", "", &od); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateOperationDefinition(c.Request().Context(), &od); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -547,16 +549,14 @@ func (h *Handler) CreateOperationDefinitionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateOperationDefinitionFHIR(c echo.Context) error {
-	var od OperationDefinition
-	if err := c.Bind(&od); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetOperationDefinitionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("OperationDefinition", c.Param("id")))
 	}
-	od.ID = existing.ID
-	od.FHIRID = existing.FHIRID
+	od := *existing
+	if err := fhir.BindResource(c, "OperationDefinition", existing.FHIRID, &od); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateOperationDefinition(c.Request().Context(), &od); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -580,11 +580,8 @@ func (h *Handler) PatchOperationDefinitionFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("OperationDefinition", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
-		}
-		if v, ok := resource["name"].(string); ok {
-			existing.Name = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateOperationDefinition(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -644,8 +641,8 @@ func (h *Handler) GetMessageDefinitionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateMessageDefinitionFHIR(c echo.Context) error {
 	var md MessageDefinition
-	if err := c.Bind(&md); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "MessageDefinition", "", &md); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateMessageDefinition(c.Request().Context(), &md); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -655,16 +652,14 @@ func (h *Handler) CreateMessageDefinitionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateMessageDefinitionFHIR(c echo.Context) error {
-	var md MessageDefinition
-	if err := c.Bind(&md); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetMessageDefinitionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("MessageDefinition", c.Param("id")))
 	}
-	md.ID = existing.ID
-	md.FHIRID = existing.FHIRID
+	md := *existing
+	if err := fhir.BindResource(c, "MessageDefinition", existing.FHIRID, &md); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateMessageDefinition(c.Request().Context(), &md); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -688,8 +683,8 @@ func (h *Handler) PatchMessageDefinitionFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("MessageDefinition", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateMessageDefinition(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -749,8 +744,8 @@ func (h *Handler) GetMessageHeaderFHIR(c echo.Context) error {
 
 func (h *Handler) CreateMessageHeaderFHIR(c echo.Context) error {
 	var mh MessageHeader
-	if err := c.Bind(&mh); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "MessageHeader", "", &mh); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateMessageHeader(c.Request().Context(), &mh); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -760,16 +755,14 @@ func (h *Handler) CreateMessageHeaderFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateMessageHeaderFHIR(c echo.Context) error {
-	var mh MessageHeader
-	if err := c.Bind(&mh); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetMessageHeaderByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("MessageHeader", c.Param("id")))
 	}
-	mh.ID = existing.ID
-	mh.FHIRID = existing.FHIRID
+	mh := *existing
+	if err := fhir.BindResource(c, "MessageHeader", existing.FHIRID, &mh); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateMessageHeader(c.Request().Context(), &mh); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -793,10 +786,8 @@ func (h *Handler) PatchMessageHeaderFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("MessageHeader", ctx.Param("id")))
 		}
-		if v, ok := resource["eventCoding"].(map[string]interface{}); ok {
-			if code, ok := v["code"].(string); ok {
-				existing.EventCodingCode = code
-			}
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateMessageHeader(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -845,25 +836,25 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string, apply
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "OperationDefinition":
 		existing, err := h.svc.GetOperationDefinitionByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "MessageDefinition":
 		existing, err := h.svc.GetMessageDefinitionByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "MessageHeader":
 		existing, err := h.svc.GetMessageHeaderByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	default:
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("unsupported resource type for PATCH"))
 	}
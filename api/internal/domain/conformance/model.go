@@ -78,6 +78,24 @@ func (ns *NamingSystem) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the naming system from a FHIR NamingSystem resource, the
+// inverse of ToFHIR.
+func (ns *NamingSystem) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("NamingSystem", data)
+	ns.Name = r.RequiredString("name")
+	ns.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	ns.Kind = r.CodeValue("kind", "codesystem", "identifier", "root")
+	ns.Date = r.String("date")
+	ns.Publisher = r.String("publisher")
+	ns.Responsible = r.String("responsible")
+	nsType := r.CodeableConcept("type")
+	ns.TypeCode, ns.TypeDisplay = nsType.Code, nsType.Display
+	ns.Description = r.String("description")
+	ns.UsageNote = r.String("usage")
+	ns.Jurisdiction = r.ConceptCode("jurisdiction")
+	return r.Err()
+}
+
 // NamingSystemUniqueID maps to the naming_system_unique_id table.
 type NamingSystemUniqueID struct {
 	ID             uuid.UUID `db:"id" json:"id"`
@@ -162,6 +180,26 @@ func (od *OperationDefinition) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the operation definition from a FHIR OperationDefinition
+// resource, the inverse of ToFHIR.
+func (od *OperationDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("OperationDefinition", data)
+	od.Name = r.RequiredString("name")
+	od.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	od.Kind = r.CodeValue("kind", "operation", "query")
+	od.Code = r.RequiredString("code")
+	od.URL = r.String("url")
+	od.Title = r.String("title")
+	od.Description = r.String("description")
+	od.System = r.Bool("system")
+	od.Type = r.Bool("type")
+	od.Instance = r.Bool("instance")
+	od.InputProfile = r.String("inputProfile")
+	od.OutputProfile = r.String("outputProfile")
+	od.Publisher = r.String("publisher")
+	return r.Err()
+}
+
 // OperationDefinitionParameter maps to the operation_definition_parameter table.
 type OperationDefinitionParameter struct {
 	ID                    uuid.UUID `db:"id" json:"id"`
@@ -249,6 +287,26 @@ func (md *MessageDefinition) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the message definition from a FHIR MessageDefinition
+// resource, the inverse of ToFHIR.
+func (md *MessageDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MessageDefinition", data)
+	md.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	event := r.Coding("eventCoding")
+	md.EventCodingCode = r.RequiredString("eventCoding.code")
+	md.EventCodingSystem, md.EventCodingDisplay = event.System, event.Display
+	md.URL = r.String("url")
+	md.Name = r.String("name")
+	md.Title = r.String("title")
+	md.Date = r.String("date")
+	md.Publisher = r.String("publisher")
+	md.Description = r.String("description")
+	md.Purpose = r.String("purpose")
+	md.Category = r.Code("category", "consequence", "currency", "notification")
+	md.ResponseRequired = r.Code("responseRequired", "always", "on-error", "never", "on-success")
+	return r.Err()
+}
+
 // MessageHeader maps to the message_header table (FHIR MessageHeader resource).
 type MessageHeader struct {
 	ID                  uuid.UUID `db:"id" json:"id"`
@@ -344,6 +402,29 @@ func (mh *MessageHeader) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the message header from a FHIR MessageHeader resource,
+// the inverse of ToFHIR.
+func (mh *MessageHeader) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MessageHeader", data)
+	event := r.Coding("eventCoding")
+	mh.EventCodingCode = r.RequiredString("eventCoding.code")
+	mh.EventCodingSystem, mh.EventCodingDisplay = event.System, event.Display
+	mh.SourceEndpoint = r.RequiredString("source.endpoint")
+	mh.SourceName = r.String("source.name")
+	mh.SourceSoftware = r.String("source.software")
+	mh.SourceVersion = r.String("source.version")
+	mh.DestinationName = r.String("destination.name")
+	mh.DestinationEndpoint = r.String("destination.endpoint")
+	mh.SenderOrgID = r.ReferenceID("sender", "Organization")
+	reason := r.CodeableConcept("reason")
+	mh.ReasonCode, mh.ReasonDisplay = reason.Code, reason.Display
+	mh.ResponseIdentifier = r.String("response.identifier")
+	mh.ResponseCode = r.Code("response.code", "ok", "transient-error", "fatal-error")
+	mh.FocusReference = r.Reference("focus")
+	mh.DefinitionURL = r.String("definition")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -74,7 +74,7 @@ func (r *namingSystemRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*N
 func (r *namingSystemRepoPG) Update(ctx context.Context, ns *NamingSystem) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE naming_system SET name=$2, status=$3, kind=$4, publisher=$5,
-			description=$6, usage_note=$7, updated_at=NOW()
+			description=$6, usage_note=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ns.ID, ns.Name, ns.Status, ns.Kind, ns.Publisher,
 		ns.Description, ns.UsageNote)
@@ -115,13 +115,16 @@ var nsSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *namingSystemRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*NamingSystem, int, error) {
 	qb := fhir.NewSearchQuery("naming_system", nsCols)
-	qb.ApplyParams(params, nsSearchParams)
+	qb.ApplyParams(ctx, params, nsSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -227,7 +230,7 @@ func (r *opDefRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Operatio
 func (r *opDefRepoPG) Update(ctx context.Context, od *OperationDefinition) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE operation_definition SET name=$2, status=$3, kind=$4, description=$5,
-			code=$6, system=$7, type=$8, instance=$9, publisher=$10, updated_at=NOW()
+			code=$6, system=$7, type=$8, instance=$9, publisher=$10, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		od.ID, od.Name, od.Status, od.Kind, od.Description,
 		od.Code, od.System, od.Type, od.Instance, od.Publisher)
@@ -268,13 +271,16 @@ var odSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *opDefRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*OperationDefinition, int, error) {
 	qb := fhir.NewSearchQuery("operation_definition", odCols)
-	qb.ApplyParams(params, odSearchParams)
+	qb.ApplyParams(ctx, params, odSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -382,7 +388,7 @@ func (r *msgDefRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Message
 func (r *msgDefRepoPG) Update(ctx context.Context, md *MessageDefinition) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE message_definition SET name=$2, status=$3, description=$4, purpose=$5,
-			event_coding_code=$6, category=$7, response_required=$8, updated_at=NOW()
+			event_coding_code=$6, category=$7, response_required=$8, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		md.ID, md.Name, md.Status, md.Description, md.Purpose,
 		md.EventCodingCode, md.Category, md.ResponseRequired)
@@ -422,13 +428,16 @@ var mdSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *msgDefRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MessageDefinition, int, error) {
 	qb := fhir.NewSearchQuery("message_definition", mdCols)
-	qb.ApplyParams(params, mdSearchParams)
+	qb.ApplyParams(ctx, params, mdSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -512,7 +521,7 @@ func (r *msgHeaderRepoPG) Update(ctx context.Context, mh *MessageHeader) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE message_header SET event_coding_code=$2, event_coding_system=$3,
 			destination_name=$4, destination_endpoint=$5, source_endpoint=$6,
-			reason_code=$7, response_code=$8, updated_at=NOW()
+			reason_code=$7, response_code=$8, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		mh.ID, mh.EventCodingCode, mh.EventCodingSystem,
 		mh.DestinationName, mh.DestinationEndpoint, mh.SourceEndpoint,
@@ -552,13 +561,16 @@ var mhSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *msgHeaderRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MessageHeader, int, error) {
 	qb := fhir.NewSearchQuery("message_header", mhCols)
-	qb.ApplyParams(params, mhSearchParams)
+	qb.ApplyParams(ctx, params, mhSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -575,3 +587,12 @@ func (r *msgHeaderRepoPG) Search(ctx context.Context, params map[string]string,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("naming_system", nsSearchParams)
+	r.RegisterTable("operation_definition", odSearchParams)
+	r.RegisterTable("message_definition", mdSearchParams)
+	r.RegisterTable("message_header", mhSearchParams)
+}
@@ -72,6 +72,11 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/CoverageEligibilityResponse/:id/_history", h.HistoryResponseFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("CoverageEligibilityRequest", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("CoverageEligibilityResponse", fhir.ReadOnlyInteractions()...)
+}
+
 // ============================================================
 // REST: CoverageEligibilityRequest
 // ============================================================
@@ -227,8 +232,8 @@ func (h *Handler) GetRequestFHIR(c echo.Context) error {
 
 func (h *Handler) CreateRequestFHIR(c echo.Context) error {
 	var r CoverageEligibilityRequest
-	if err := c.Bind(&r); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "CoverageEligibilityRequest", "", &r); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateRequest(c.Request().Context(), &r); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -238,16 +243,14 @@ func (h *Handler) CreateRequestFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateRequestFHIR(c echo.Context) error {
-	var r CoverageEligibilityRequest
-	if err := c.Bind(&r); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetRequestByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CoverageEligibilityRequest", c.Param("id")))
 	}
-	r.ID = existing.ID
-	r.FHIRID = existing.FHIRID
+	r := *existing
+	if err := fhir.BindResource(c, "CoverageEligibilityRequest", existing.FHIRID, &r); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateRequest(c.Request().Context(), &r); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -303,7 +306,7 @@ func (h *Handler) handlePatchRequest(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CoverageEligibilityRequest", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -327,8 +330,8 @@ func (h *Handler) handlePatchRequest(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateRequest(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -365,8 +368,8 @@ func (h *Handler) GetResponseFHIR(c echo.Context) error {
 
 func (h *Handler) CreateResponseFHIR(c echo.Context) error {
 	var r CoverageEligibilityResponse
-	if err := c.Bind(&r); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "CoverageEligibilityResponse", "", &r); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateResponse(c.Request().Context(), &r); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -376,16 +379,14 @@ func (h *Handler) CreateResponseFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateResponseFHIR(c echo.Context) error {
-	var r CoverageEligibilityResponse
-	if err := c.Bind(&r); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetResponseByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CoverageEligibilityResponse", c.Param("id")))
 	}
-	r.ID = existing.ID
-	r.FHIRID = existing.FHIRID
+	r := *existing
+	if err := fhir.BindResource(c, "CoverageEligibilityResponse", existing.FHIRID, &r); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateResponse(c.Request().Context(), &r); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -441,7 +442,7 @@ func (h *Handler) handlePatchResponse(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("CoverageEligibilityResponse", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -465,11 +466,8 @@ func (h *Handler) handlePatchResponse(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
-	}
-	if v, ok := patched["outcome"].(string); ok {
-		existing.Outcome = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateResponse(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -55,6 +55,20 @@ func (r *CoverageEligibilityRequest) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the request from a FHIR CoverageEligibilityRequest
+// resource, the inverse of ToFHIR.
+func (r *CoverageEligibilityRequest) FromFHIR(data map[string]interface{}) error {
+	rd := fhir.NewResourceReader("CoverageEligibilityRequest", data)
+	r.Status = rd.CodeValue("status", "active", "cancelled", "draft", "entered-in-error")
+	r.Purpose = rd.CodeValue("purpose", "auth-requirements", "benefits", "discovery", "validation")
+	r.PatientID = rd.RequiredReferenceID("patient", "Patient")
+	r.ProviderID = rd.ReferenceID("provider", "Practitioner")
+	r.InsurerID = rd.ReferenceID("insurer", "Organization")
+	r.ServicedDate = rd.DateTime("servicedDate")
+	r.Created = rd.DateTime("created")
+	return rd.Err()
+}
+
 // CoverageEligibilityResponse maps to the coverage_eligibility_response table (FHIR CoverageEligibilityResponse resource).
 type CoverageEligibilityResponse struct {
 	ID          uuid.UUID  `db:"id" json:"id"`
@@ -101,3 +115,17 @@ func (r *CoverageEligibilityResponse) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the response from a FHIR CoverageEligibilityResponse
+// resource, the inverse of ToFHIR.
+func (r *CoverageEligibilityResponse) FromFHIR(data map[string]interface{}) error {
+	rd := fhir.NewResourceReader("CoverageEligibilityResponse", data)
+	r.Status = rd.CodeValue("status", "active", "cancelled", "draft", "entered-in-error")
+	r.Outcome = rd.CodeValue("outcome", "queued", "complete", "error", "partial")
+	r.PatientID = rd.RequiredReferenceID("patient", "Patient")
+	r.RequestID = rd.ReferenceID("request", "CoverageEligibilityRequest")
+	r.InsurerID = rd.ReferenceID("insurer", "Organization")
+	r.Disposition = rd.String("disposition")
+	r.Created = rd.DateTime("created")
+	return rd.Err()
+}
@@ -73,7 +73,7 @@ func (r *coverageEligibilityRequestRepoPG) GetByFHIRID(ctx context.Context, fhir
 func (r *coverageEligibilityRequestRepoPG) Update(ctx context.Context, e *CoverageEligibilityRequest) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE coverage_eligibility_request SET status=$2, patient_id=$3, provider_id=$4, insurer_id=$5,
-			purpose=$6, serviced_date=$7, created=$8, updated_at=NOW()
+			purpose=$6, serviced_date=$7, created=$8, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.PatientID, e.ProviderID, e.InsurerID,
 		e.Purpose, e.ServicedDate, e.Created)
@@ -114,13 +114,16 @@ var cerSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *coverageEligibilityRequestRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*CoverageEligibilityRequest, int, error) {
 	qb := fhir.NewSearchQuery("coverage_eligibility_request", reqCols)
-	qb.ApplyParams(params, cerSearchParams)
+	qb.ApplyParams(ctx, params, cerSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -193,7 +196,7 @@ func (r *coverageEligibilityResponseRepoPG) GetByFHIRID(ctx context.Context, fhi
 func (r *coverageEligibilityResponseRepoPG) Update(ctx context.Context, e *CoverageEligibilityResponse) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE coverage_eligibility_response SET status=$2, patient_id=$3, request_id=$4, insurer_id=$5,
-			outcome=$6, disposition=$7, created=$8, updated_at=NOW()
+			outcome=$6, disposition=$7, created=$8, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.PatientID, e.RequestID, e.InsurerID,
 		e.Outcome, e.Disposition, e.Created)
@@ -235,13 +238,16 @@ var cerspSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *coverageEligibilityResponseRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*CoverageEligibilityResponse, int, error) {
 	qb := fhir.NewSearchQuery("coverage_eligibility_response", respCols)
-	qb.ApplyParams(params, cerspSearchParams)
+	qb.ApplyParams(ctx, params, cerspSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -258,3 +264,10 @@ func (r *coverageEligibilityResponseRepoPG) Search(ctx context.Context, params m
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("coverage_eligibility_request", cerSearchParams)
+	r.RegisterTable("coverage_eligibility_response", cerspSearchParams)
+}
@@ -52,6 +52,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Device/:id/_history", h.HistoryDeviceFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Device", fhir.ReadOnlyInteractions()...)
+}
+
 // -- Device REST --
 
 func (h *Handler) CreateDevice(c echo.Context) error {
@@ -160,8 +164,8 @@ func (h *Handler) GetDeviceFHIR(c echo.Context) error {
 
 func (h *Handler) CreateDeviceFHIR(c echo.Context) error {
 	var d Device
-	if err := c.Bind(&d); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Device", "", &d); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateDevice(c.Request().Context(), &d); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -171,16 +175,14 @@ func (h *Handler) CreateDeviceFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateDeviceFHIR(c echo.Context) error {
-	var d Device
-	if err := c.Bind(&d); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetDeviceByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Device", c.Param("id")))
 	}
-	d.ID = existing.ID
-	d.FHIRID = existing.FHIRID
+	d := *existing
+	if err := fhir.BindResource(c, "Device", existing.FHIRID, &d); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateDevice(c.Request().Context(), &d); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -237,7 +239,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Device", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -263,8 +265,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateDevice(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
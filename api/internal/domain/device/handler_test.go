@@ -57,7 +57,7 @@ func TestGetDeviceFHIR_NotFound(t *testing.T) {
 
 func TestCreateDeviceFHIR_Success(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"device_name":"New Device","status":"active"}`
+	body := `{"resourceType":"Device","status":"active","deviceName":[{"name":"New Device","type":"user-friendly-name"}]}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -82,7 +82,7 @@ func TestCreateDeviceFHIR_Success(t *testing.T) {
 
 func TestCreateDeviceFHIR_InvalidBody(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"status":"active"}`
+	body := `{"resourceType":"Device","status":"active"}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -142,6 +142,39 @@ func (d *Device) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the device from a FHIR Device resource, the inverse of
+// ToFHIR.
+func (d *Device) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Device", data)
+	d.Status = r.CodeValue("status", "active", "inactive", "entered-in-error", "unknown")
+	d.DeviceName = r.RequiredString("deviceName.name")
+	d.DeviceNameType = r.CodeValue("deviceName.type", "udi-label-name", "user-friendly-name",
+		"patient-reported-name", "manufacturer-name", "model-name", "other")
+	d.StatusReason = r.ConceptCode("statusReason")
+	d.DistinctIdentifier = r.String("distinctIdentifier")
+	d.ManufacturerName = r.String("manufacturer")
+	d.ManufactureDate = r.DateTime("manufactureDate")
+	d.ExpirationDate = r.DateTime("expirationDate")
+	d.LotNumber = r.String("lotNumber")
+	d.SerialNumber = r.String("serialNumber")
+	d.ModelNumber = r.String("modelNumber")
+	deviceType := r.CodeableConcept("type")
+	d.TypeCode, d.TypeDisplay, d.TypeSystem = deviceType.Code, deviceType.Display, deviceType.System
+	d.VersionValue = r.String("version.value")
+	d.PatientID = r.ReferenceID("patient", "Patient")
+	d.OwnerID = r.ReferenceID("owner", "Organization")
+	d.LocationID = r.ReferenceID("location", "Location")
+	d.ContactPhone = r.ContactValue("contact", "phone", "")
+	d.ContactEmail = r.ContactValue("contact", "email", "")
+	d.URL = r.String("url")
+	d.Note = r.Annotation("note")
+	safety := r.CodeableConcept("safety")
+	d.SafetyCode, d.SafetyDisplay = safety.Code, safety.Display
+	d.UDICarrier = r.String("udiCarrier.carrierHRF")
+	d.UDIEntryType = r.Code("udiCarrier.entryType", "barcode", "rfid", "manual", "card", "self-reported", "unknown")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
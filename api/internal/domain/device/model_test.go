@@ -1,6 +1,7 @@
 package device
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -273,3 +274,86 @@ func TestDevice_ToFHIR_MinimalFields(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestDevice_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &Device{
+		FHIRID:         "dev-rt",
+		Status:         "inactive",
+		DeviceName:     "Infusion Pump",
+		DeviceNameType: "user-friendly-name",
+		TypeCode:       ptrStr("69805005"),
+		TypeSystem:     ptrStr("http://snomed.info/sct"),
+		ExpirationDate: ptrTime(time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)),
+		PatientID:      ptrUUID(uuid.New()),
+		OwnerID:        ptrUUID(uuid.New()),
+		ContactPhone:   ptrStr("555-0000"),
+		ContactEmail:   ptrStr("biomed@example.org"),
+		Note:           ptrStr("Check battery"),
+		UDICarrier:     ptrStr("(01)00844588003288"),
+		UDIEntryType:   ptrStr("rfid"),
+	}
+
+	var got Device
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Status != "inactive" || got.DeviceName != "Infusion Pump" || got.DeviceNameType != "user-friendly-name" {
+		t.Errorf("status/name mismatch: %+v", got)
+	}
+	if *got.TypeCode != "69805005" || *got.TypeSystem != "http://snomed.info/sct" {
+		t.Errorf("type mismatch: %+v", got)
+	}
+	if !got.ExpirationDate.Equal(*orig.ExpirationDate) {
+		t.Errorf("expirationDate = %v", got.ExpirationDate)
+	}
+	if *got.PatientID != *orig.PatientID || *got.OwnerID != *orig.OwnerID {
+		t.Errorf("references mismatch: %+v", got)
+	}
+	if *got.ContactPhone != "555-0000" || *got.ContactEmail != "biomed@example.org" || *got.Note != "Check battery" {
+		t.Errorf("contact/note mismatch: %+v", got)
+	}
+	if *got.UDICarrier != "(01)00844588003288" || *got.UDIEntryType != "rfid" {
+		t.Errorf("udiCarrier mismatch: %+v", got)
+	}
+}
+
+func TestDevice_FromFHIR_InvalidElements(t *testing.T) {
+	var d Device
+	err := d.FromFHIR(map[string]interface{}{
+		"resourceType": "Device",
+		"status":       "broken",
+		"patient":      map[string]interface{}{"reference": "Patient/not-a-uuid"},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	want := map[string]bool{
+		"Device.status":            true,
+		"Device.deviceName.name":   true,
+		"Device.patient.reference": true,
+	}
+	for _, issue := range pe.Outcome.Issue {
+		delete(want, issue.Expression[0])
+	}
+	if len(want) != 0 {
+		t.Errorf("missing issues for %v in %+v", want, pe.Outcome.Issue)
+	}
+}
@@ -88,7 +88,7 @@ func (r *deviceRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Device,
 func (r *deviceRepoPG) Update(ctx context.Context, d *Device) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE device SET status=$2, status_reason=$3, manufacturer_name=$4,
-			device_name=$5, serial_number=$6, model_number=$7, note=$8, updated_at=NOW()
+			device_name=$5, serial_number=$6, model_number=$7, note=$8, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		d.ID, d.Status, d.StatusReason, d.ManufacturerName,
 		d.DeviceName, d.SerialNumber, d.ModelNumber, d.Note)
@@ -151,13 +151,16 @@ var deviceSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *deviceRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Device, int, error) {
 	qb := fhir.NewSearchQuery("device", deviceCols)
-	qb.ApplyParams(params, deviceSearchParams)
+	qb.ApplyParams(ctx, params, deviceSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -174,3 +177,9 @@ func (r *deviceRepoPG) Search(ctx context.Context, params map[string]string, lim
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("device", deviceSearchParams)
+}
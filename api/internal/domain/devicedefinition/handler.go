@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/DeviceDefinition/:id/_history", h.HistoryDeviceDefinitionFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("DeviceDefinition", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateDeviceDefinition(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetDeviceDefinitionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateDeviceDefinitionFHIR(c echo.Context) error {
 	var d DeviceDefinition
-	if err := c.Bind(&d); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "DeviceDefinition", "", &d); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateDeviceDefinition(c.Request().Context(), &d); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateDeviceDefinitionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateDeviceDefinitionFHIR(c echo.Context) error {
-	var d DeviceDefinition
-	if err := c.Bind(&d); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetDeviceDefinitionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DeviceDefinition", c.Param("id")))
 	}
-	d.ID = existing.ID
-	d.FHIRID = existing.FHIRID
+	d := *existing
+	if err := fhir.BindResource(c, "DeviceDefinition", existing.FHIRID, &d); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateDeviceDefinition(c.Request().Context(), &d); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DeviceDefinition", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["description"].(string); ok {
-		existing.Description = &v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateDeviceDefinition(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -76,6 +76,25 @@ func (d *DeviceDefinition) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the device definition from a FHIR DeviceDefinition
+// resource, the inverse of ToFHIR.
+func (d *DeviceDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("DeviceDefinition", data)
+	d.ManufacturerString = r.String("manufacturerString")
+	d.ModelNumber = r.String("modelNumber")
+	d.DeviceName = r.String("deviceName.name")
+	d.DeviceNameType = r.String("deviceName.type")
+	deviceType := r.CodeableConcept("type")
+	d.TypeCode, d.TypeDisplay = deviceType.Code, deviceType.Display
+	d.Specialization = r.String("specialization")
+	safety := r.CodeableConcept("safety")
+	d.SafetyCode, d.SafetyDisplay = safety.Code, safety.Display
+	d.OwnerID = r.ReferenceID("owner", "Organization")
+	d.ParentDeviceID = r.ReferenceID("parentDevice", "DeviceDefinition")
+	d.Description = r.String("description")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
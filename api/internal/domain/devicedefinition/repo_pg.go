@@ -81,7 +81,7 @@ func (r *deviceDefinitionRepoPG) Update(ctx context.Context, d *DeviceDefinition
 		UPDATE device_definition SET manufacturer_string=$2, model_number=$3,
 			device_name=$4, device_name_type=$5, type_code=$6, type_display=$7,
 			specialization=$8, safety_code=$9, safety_display=$10,
-			owner_id=$11, parent_device_id=$12, description=$13, updated_at=NOW()
+			owner_id=$11, parent_device_id=$12, description=$13, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		d.ID, d.ManufacturerString, d.ModelNumber,
 		d.DeviceName, d.DeviceNameType, d.TypeCode, d.TypeDisplay,
@@ -124,13 +124,16 @@ var ddSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *deviceDefinitionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*DeviceDefinition, int, error) {
 	qb := fhir.NewSearchQuery("device_definition", ddCols)
-	qb.ApplyParams(params, ddSearchParams)
+	qb.ApplyParams(ctx, params, ddSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -147,3 +150,9 @@ func (r *deviceDefinitionRepoPG) Search(ctx context.Context, params map[string]s
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("device_definition", ddSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/DeviceMetric/:id/_history", h.HistoryDeviceMetricFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("DeviceMetric", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateDeviceMetric(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetDeviceMetricFHIR(c echo.Context) error {
 
 func (h *Handler) CreateDeviceMetricFHIR(c echo.Context) error {
 	var m DeviceMetric
-	if err := c.Bind(&m); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "DeviceMetric", "", &m); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateDeviceMetric(c.Request().Context(), &m); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateDeviceMetricFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateDeviceMetricFHIR(c echo.Context) error {
-	var m DeviceMetric
-	if err := c.Bind(&m); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetDeviceMetricByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DeviceMetric", c.Param("id")))
 	}
-	m.ID = existing.ID
-	m.FHIRID = existing.FHIRID
+	m := *existing
+	if err := fhir.BindResource(c, "DeviceMetric", existing.FHIRID, &m); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateDeviceMetric(c.Request().Context(), &m); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DeviceMetric", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["category"].(string); ok {
-		existing.Category = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateDeviceMetric(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
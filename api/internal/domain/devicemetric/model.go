@@ -87,6 +87,28 @@ func (m *DeviceMetric) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the metric from a FHIR DeviceMetric resource, the inverse
+// of ToFHIR.
+func (m *DeviceMetric) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("DeviceMetric", data)
+	metricType := r.CodeableConcept("type")
+	m.TypeCode = r.RequiredString("type.coding.code")
+	m.TypeDisplay = metricType.Display
+	m.Category = r.CodeValue("category", "measurement", "setting", "calculation", "unspecified")
+	m.SourceID = r.ReferenceID("source", "Device")
+	m.ParentID = r.ReferenceID("parent", "Device")
+	unit := r.CodeableConcept("unit")
+	m.UnitCode, m.UnitDisplay = unit.Code, unit.Display
+	m.OperationalStatus = r.Code("operationalStatus", "on", "off", "standby", "entered-in-error")
+	m.Color = r.Code("color", "black", "red", "green", "yellow", "blue", "magenta", "cyan", "white")
+	m.CalibrationType = r.Code("calibration.type", "unspecified", "offset", "gain", "two-point")
+	m.CalibrationState = r.Code("calibration.state", "not-calibrated", "calibration-required", "calibrated", "unspecified")
+	m.CalibrationTime = r.DateTime("calibration.time")
+	m.MeasurementPeriodValue = r.Decimal("measurementPeriod.repeat.period")
+	m.MeasurementPeriodUnit = r.String("measurementPeriod.repeat.periodUnit")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -86,7 +86,7 @@ func (r *deviceMetricRepoPG) Update(ctx context.Context, m *DeviceMetric) error
 			source_id=$4, parent_id=$5, unit_code=$6, unit_display=$7,
 			operational_status=$8, color=$9, category=$10,
 			calibration_type=$11, calibration_state=$12, calibration_time=$13,
-			measurement_period_value=$14, measurement_period_unit=$15, updated_at=NOW()
+			measurement_period_value=$14, measurement_period_unit=$15, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.TypeCode, m.TypeDisplay,
 		m.SourceID, m.ParentID, m.UnitCode, m.UnitDisplay,
@@ -130,13 +130,16 @@ var dmSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *deviceMetricRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*DeviceMetric, int, error) {
 	qb := fhir.NewSearchQuery("device_metric", dmCols)
-	qb.ApplyParams(params, dmSearchParams)
+	qb.ApplyParams(ctx, params, dmSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -153,3 +156,9 @@ func (r *deviceMetricRepoPG) Search(ctx context.Context, params map[string]strin
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("device_metric", dmSearchParams)
+}
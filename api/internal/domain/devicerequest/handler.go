@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/DeviceRequest/:id/_history", h.HistoryDeviceRequestFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("DeviceRequest", fhir.ReadOnlyInteractions()...)
+}
+
 func (h *Handler) CreateDeviceRequest(c echo.Context) error {
 	var d DeviceRequest
 	if err := c.Bind(&d); err != nil {
@@ -133,8 +137,8 @@ func (h *Handler) GetDeviceRequestFHIR(c echo.Context) error {
 
 func (h *Handler) CreateDeviceRequestFHIR(c echo.Context) error {
 	var d DeviceRequest
-	if err := c.Bind(&d); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "DeviceRequest", "", &d); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateDeviceRequest(c.Request().Context(), &d); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -144,16 +148,14 @@ func (h *Handler) CreateDeviceRequestFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateDeviceRequestFHIR(c echo.Context) error {
-	var d DeviceRequest
-	if err := c.Bind(&d); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetDeviceRequestByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DeviceRequest", c.Param("id")))
 	}
-	d.ID = existing.ID
-	d.FHIRID = existing.FHIRID
+	d := *existing
+	if err := fhir.BindResource(c, "DeviceRequest", existing.FHIRID, &d); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateDeviceRequest(c.Request().Context(), &d); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -209,7 +211,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DeviceRequest", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -233,8 +235,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateDeviceRequest(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -74,6 +74,28 @@ func (d *DeviceRequest) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the request from a FHIR DeviceRequest resource, the
+// inverse of ToFHIR.
+func (d *DeviceRequest) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("DeviceRequest", data)
+	d.Status = r.CodeValue("status", "draft", "active", "on-hold", "revoked",
+		"completed", "entered-in-error", "unknown")
+	d.Intent = r.CodeValue("intent", "proposal", "plan", "directive", "order",
+		"original-order", "reflex-order", "filler-order", "instance-order", "option")
+	d.SubjectPatientID = r.RequiredReferenceID("subject", "Patient")
+	d.Priority = r.Code("priority", "routine", "urgent", "asap", "stat")
+	code := r.CodeableConcept("codeCodeableConcept")
+	d.CodeCode, d.CodeDisplay, d.CodeSystem = code.Code, code.Display, code.System
+	d.EncounterID = r.ReferenceID("encounter", "Encounter")
+	d.AuthoredOn = r.DateTime("authoredOn")
+	d.RequesterID = r.ReferenceID("requester", "Practitioner")
+	d.PerformerID = r.ReferenceID("performer", "Practitioner")
+	reason := r.CodeableConcept("reasonCode")
+	d.ReasonCode, d.ReasonDisplay = reason.Code, reason.Display
+	d.Note = r.Annotation("note")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -86,7 +86,7 @@ func (r *deviceRequestRepoPG) Update(ctx context.Context, d *DeviceRequest) erro
 			code_code=$5, code_display=$6, code_system=$7,
 			encounter_id=$8, authored_on=$9,
 			requester_id=$10, performer_id=$11,
-			reason_code=$12, reason_display=$13, note=$14, updated_at=NOW()
+			reason_code=$12, reason_display=$13, note=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		d.ID, d.Status, d.Intent, d.Priority,
 		d.CodeCode, d.CodeDisplay, d.CodeSystem,
@@ -130,13 +130,16 @@ var drSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *deviceRequestRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*DeviceRequest, int, error) {
 	qb := fhir.NewSearchQuery("device_request", drCols)
-	qb.ApplyParams(params, drSearchParams)
+	qb.ApplyParams(ctx, params, drSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -153,3 +156,9 @@ func (r *deviceRequestRepoPG) Search(ctx context.Context, params map[string]stri
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("device_request", drSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/DeviceUseStatement/:id/_history", h.HistoryDeviceUseStatementFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("DeviceUseStatement", fhir.ReadOnlyInteractions()...)
+}
+
 func (h *Handler) CreateDeviceUseStatement(c echo.Context) error {
 	var d DeviceUseStatement
 	if err := c.Bind(&d); err != nil {
@@ -133,8 +137,8 @@ func (h *Handler) GetDeviceUseStatementFHIR(c echo.Context) error {
 
 func (h *Handler) CreateDeviceUseStatementFHIR(c echo.Context) error {
 	var d DeviceUseStatement
-	if err := c.Bind(&d); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "DeviceUseStatement", "", &d); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateDeviceUseStatement(c.Request().Context(), &d); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -144,16 +148,14 @@ func (h *Handler) CreateDeviceUseStatementFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateDeviceUseStatementFHIR(c echo.Context) error {
-	var d DeviceUseStatement
-	if err := c.Bind(&d); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetDeviceUseStatementByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DeviceUseStatement", c.Param("id")))
 	}
-	d.ID = existing.ID
-	d.FHIRID = existing.FHIRID
+	d := *existing
+	if err := fhir.BindResource(c, "DeviceUseStatement", existing.FHIRID, &d); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateDeviceUseStatement(c.Request().Context(), &d); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -209,7 +211,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DeviceUseStatement", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -233,8 +235,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateDeviceUseStatement(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -72,6 +72,25 @@ func (d *DeviceUseStatement) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the statement from a FHIR DeviceUseStatement resource,
+// the inverse of ToFHIR.
+func (d *DeviceUseStatement) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("DeviceUseStatement", data)
+	d.Status = r.CodeValue("status", "active", "completed", "entered-in-error", "intended", "stopped", "on-hold")
+	d.SubjectPatientID = r.RequiredReferenceID("subject", "Patient")
+	d.DeviceID = r.ReferenceID("device", "Device")
+	d.TimingDate = r.DateTime("timingDateTime")
+	d.TimingPeriodStart, d.TimingPeriodEnd = r.Period("timingPeriod")
+	d.RecordedOn = r.DateTime("recordedOn")
+	d.SourceID = r.ReferenceID("source", "Practitioner")
+	reason := r.CodeableConcept("reasonCode")
+	d.ReasonCode, d.ReasonDisplay = reason.Code, reason.Display
+	bodySite := r.CodeableConcept("bodySite")
+	d.BodySiteCode, d.BodySiteDisplay = bodySite.Code, bodySite.Display
+	d.Note = r.Annotation("note")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
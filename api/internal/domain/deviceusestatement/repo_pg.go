@@ -86,7 +86,7 @@ func (r *deviceUseStatementRepoPG) Update(ctx context.Context, d *DeviceUseState
 			timing_date=$4, timing_period_start=$5, timing_period_end=$6,
 			recorded_on=$7, source_id=$8,
 			reason_code=$9, reason_display=$10,
-			body_site_code=$11, body_site_display=$12, note=$13, updated_at=NOW()
+			body_site_code=$11, body_site_display=$12, note=$13, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		d.ID, d.Status, d.DeviceID,
 		d.TimingDate, d.TimingPeriodStart, d.TimingPeriodEnd,
@@ -129,13 +129,16 @@ var dusSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *deviceUseStatementRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*DeviceUseStatement, int, error) {
 	qb := fhir.NewSearchQuery("device_use_statement", dusCols)
-	qb.ApplyParams(params, dusSearchParams)
+	qb.ApplyParams(ctx, params, dusSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -152,3 +155,9 @@ func (r *deviceUseStatementRepoPG) Search(ctx context.Context, params map[string
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("device_use_statement", dusSearchParams)
+}
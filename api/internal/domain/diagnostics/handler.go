@@ -5,7 +5,6 @@ import (
 	"io"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -103,6 +102,13 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Specimen/:id/_history", h.HistorySpecimenFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("ServiceRequest", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("DiagnosticReport", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("Specimen", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("ImagingStudy", fhir.ReadOnlyInteractions()...)
+}
+
 // -- ServiceRequest Handlers --
 
 func (h *Handler) CreateServiceRequest(c echo.Context) error {
@@ -486,8 +492,8 @@ func (h *Handler) GetServiceRequestFHIR(c echo.Context) error {
 
 func (h *Handler) CreateServiceRequestFHIR(c echo.Context) error {
 	var sr ServiceRequest
-	if err := c.Bind(&sr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ServiceRequest", "", &sr); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateServiceRequest(c.Request().Context(), &sr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -530,8 +536,8 @@ func (h *Handler) GetDiagnosticReportFHIR(c echo.Context) error {
 
 func (h *Handler) CreateDiagnosticReportFHIR(c echo.Context) error {
 	var dr DiagnosticReport
-	if err := c.Bind(&dr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "DiagnosticReport", "", &dr); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateDiagnosticReport(c.Request().Context(), &dr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -572,8 +578,8 @@ func (h *Handler) GetSpecimenFHIR(c echo.Context) error {
 
 func (h *Handler) CreateSpecimenFHIR(c echo.Context) error {
 	var sp Specimen
-	if err := c.Bind(&sp); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Specimen", "", &sp); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateSpecimen(c.Request().Context(), &sp); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -614,8 +620,8 @@ func (h *Handler) GetImagingStudyFHIR(c echo.Context) error {
 
 func (h *Handler) CreateImagingStudyFHIR(c echo.Context) error {
 	var is ImagingStudy
-	if err := c.Bind(&is); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ImagingStudy", "", &is); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateImagingStudy(c.Request().Context(), &is); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -627,16 +633,14 @@ func (h *Handler) CreateImagingStudyFHIR(c echo.Context) error {
 // -- FHIR Update Endpoints --
 
 func (h *Handler) UpdateServiceRequestFHIR(c echo.Context) error {
-	var sr ServiceRequest
-	if err := c.Bind(&sr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetServiceRequestByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ServiceRequest", c.Param("id")))
 	}
-	sr.ID = existing.ID
-	sr.FHIRID = existing.FHIRID
+	sr := *existing
+	if err := fhir.BindResource(c, "ServiceRequest", existing.FHIRID, &sr); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateServiceRequest(c.Request().Context(), &sr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -644,16 +648,14 @@ func (h *Handler) UpdateServiceRequestFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateDiagnosticReportFHIR(c echo.Context) error {
-	var dr DiagnosticReport
-	if err := c.Bind(&dr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetDiagnosticReportByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DiagnosticReport", c.Param("id")))
 	}
-	dr.ID = existing.ID
-	dr.FHIRID = existing.FHIRID
+	dr := *existing
+	if err := fhir.BindResource(c, "DiagnosticReport", existing.FHIRID, &dr); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateDiagnosticReport(c.Request().Context(), &dr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -661,16 +663,14 @@ func (h *Handler) UpdateDiagnosticReportFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateSpecimenFHIR(c echo.Context) error {
-	var sp Specimen
-	if err := c.Bind(&sp); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetSpecimenByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Specimen", c.Param("id")))
 	}
-	sp.ID = existing.ID
-	sp.FHIRID = existing.FHIRID
+	sp := *existing
+	if err := fhir.BindResource(c, "Specimen", existing.FHIRID, &sp); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateSpecimen(c.Request().Context(), &sp); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -678,16 +678,14 @@ func (h *Handler) UpdateSpecimenFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateImagingStudyFHIR(c echo.Context) error {
-	var is ImagingStudy
-	if err := c.Bind(&is); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetImagingStudyByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ImagingStudy", c.Param("id")))
 	}
-	is.ID = existing.ID
-	is.FHIRID = existing.FHIRID
+	is := *existing
+	if err := fhir.BindResource(c, "ImagingStudy", existing.FHIRID, &is); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateImagingStudy(c.Request().Context(), &is); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -752,7 +750,7 @@ func (h *Handler) PatchServiceRequestFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ServiceRequest", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -776,7 +774,9 @@ func (h *Handler) PatchServiceRequestFHIR(c echo.Context) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	applyServiceRequestPatch(existing, patched)
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
+	}
 	if err := h.svc.UpdateServiceRequest(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -793,7 +793,7 @@ func (h *Handler) PatchDiagnosticReportFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DiagnosticReport", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -817,7 +817,9 @@ func (h *Handler) PatchDiagnosticReportFHIR(c echo.Context) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	applyDiagnosticReportPatch(existing, patched)
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
+	}
 	if err := h.svc.UpdateDiagnosticReport(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -834,7 +836,7 @@ func (h *Handler) PatchSpecimenFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Specimen", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -858,7 +860,9 @@ func (h *Handler) PatchSpecimenFHIR(c echo.Context) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	applySpecimenPatch(existing, patched)
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
+	}
 	if err := h.svc.UpdateSpecimen(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -875,7 +879,7 @@ func (h *Handler) PatchImagingStudyFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ImagingStudy", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -899,7 +903,9 @@ func (h *Handler) PatchImagingStudyFHIR(c echo.Context) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	applyImagingStudyPatch(existing, patched)
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
+	}
 	if err := h.svc.UpdateImagingStudy(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -1003,384 +1009,3 @@ func (h *Handler) HistorySpecimenFHIR(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, fhir.NewHistoryBundle([]*fhir.HistoryEntry{entry}, 1, "/fhir"))
 }
-
-// -- FHIR PATCH helpers --
-
-func diagPatchCodeableConcept(data map[string]interface{}, key string) (code, display string, ok bool) {
-	v, exists := data[key]
-	if !exists {
-		return "", "", false
-	}
-	if cc, ok := v.(map[string]interface{}); ok {
-		if coding, ok := cc["coding"].([]interface{}); ok && len(coding) > 0 {
-			if c, ok := coding[0].(map[string]interface{}); ok {
-				code, _ = c["code"].(string)
-				display, _ = c["display"].(string)
-				return code, display, true
-			}
-		}
-	}
-	return "", "", false
-}
-
-func diagPatchCodeableConceptArray(data map[string]interface{}, key string) (code, display string, ok bool) {
-	v, exists := data[key]
-	if !exists {
-		return "", "", false
-	}
-	if arr, ok := v.([]interface{}); ok && len(arr) > 0 {
-		if cc, ok := arr[0].(map[string]interface{}); ok {
-			if coding, ok := cc["coding"].([]interface{}); ok && len(coding) > 0 {
-				if c, ok := coding[0].(map[string]interface{}); ok {
-					code, _ = c["code"].(string)
-					display, _ = c["display"].(string)
-					return code, display, true
-				}
-			}
-		}
-	}
-	return "", "", false
-}
-
-func diagPatchStringPtr(data map[string]interface{}, key string, target **string) {
-	if v, ok := data[key].(string); ok {
-		*target = &v
-	}
-}
-
-func diagPatchFloat64Ptr(data map[string]interface{}, key string, target **float64) {
-	if v, ok := data[key].(float64); ok {
-		*target = &v
-	}
-}
-
-func diagPatchIntPtr(data map[string]interface{}, key string, target **int) {
-	if v, ok := data[key].(float64); ok {
-		iv := int(v)
-		*target = &iv
-	}
-}
-
-func diagPatchTimePtr(data map[string]interface{}, key string, target **time.Time) {
-	if v, ok := data[key].(string); ok {
-		if t, err := time.Parse(time.RFC3339, v); err == nil {
-			*target = &t
-		}
-	}
-}
-
-func applyServiceRequestPatch(sr *ServiceRequest, patched map[string]interface{}) {
-	if v, ok := patched["status"].(string); ok {
-		sr.Status = v
-	}
-	if v, ok := patched["intent"].(string); ok {
-		sr.Intent = v
-	}
-	diagPatchStringPtr(patched, "priority", &sr.Priority)
-	// category
-	if code, display, ok := diagPatchCodeableConceptArray(patched, "category"); ok {
-		sr.CategoryCode = &code
-		sr.CategoryDisplay = &display
-	}
-	// code
-	if v, ok := patched["code"]; ok {
-		if cc, ok := v.(map[string]interface{}); ok {
-			if coding, ok := cc["coding"].([]interface{}); ok && len(coding) > 0 {
-				if c, ok := coding[0].(map[string]interface{}); ok {
-					if code, ok := c["code"].(string); ok {
-						sr.CodeValue = code
-					}
-					if display, ok := c["display"].(string); ok {
-						sr.CodeDisplay = display
-					}
-					if system, ok := c["system"].(string); ok {
-						sr.CodeSystem = &system
-					}
-				}
-			}
-			if text, ok := cc["text"].(string); ok {
-				sr.CodeDisplay = text
-			}
-		}
-	}
-	// orderDetail
-	if code, display, ok := diagPatchCodeableConceptArray(patched, "orderDetail"); ok {
-		sr.OrderDetailCode = &code
-		sr.OrderDetailDisplay = &display
-	}
-	// occurrenceDateTime
-	diagPatchTimePtr(patched, "occurrenceDateTime", &sr.OccurrenceDatetime)
-	// occurrencePeriod
-	if v, ok := patched["occurrencePeriod"].(map[string]interface{}); ok {
-		diagPatchTimePtr(v, "start", &sr.OccurrenceStart)
-		diagPatchTimePtr(v, "end", &sr.OccurrenceEnd)
-	}
-	// authoredOn
-	diagPatchTimePtr(patched, "authoredOn", &sr.AuthoredOn)
-	// reasonCode
-	if code, display, ok := diagPatchCodeableConceptArray(patched, "reasonCode"); ok {
-		sr.ReasonCode = &code
-		sr.ReasonDisplay = &display
-	}
-	// bodySite
-	if code, display, ok := diagPatchCodeableConceptArray(patched, "bodySite"); ok {
-		sr.BodySiteCode = &code
-		sr.BodySiteDisplay = &display
-	}
-	// note
-	if v, ok := patched["note"]; ok {
-		if notes, ok := v.([]interface{}); ok && len(notes) > 0 {
-			if note, ok := notes[0].(map[string]interface{}); ok {
-				diagPatchStringPtr(note, "text", &sr.Note)
-			}
-		}
-	}
-	// patientInstruction
-	diagPatchStringPtr(patched, "patientInstruction", &sr.PatientInstruction)
-	// quantityQuantity
-	if v, ok := patched["quantityQuantity"].(map[string]interface{}); ok {
-		diagPatchFloat64Ptr(v, "value", &sr.QuantityValue)
-		diagPatchStringPtr(v, "unit", &sr.QuantityUnit)
-	}
-}
-
-func applyDiagnosticReportPatch(dr *DiagnosticReport, patched map[string]interface{}) {
-	if v, ok := patched["status"].(string); ok {
-		dr.Status = v
-	}
-	// category
-	if code, display, ok := diagPatchCodeableConceptArray(patched, "category"); ok {
-		dr.CategoryCode = &code
-		dr.CategoryDisplay = &display
-	}
-	// code
-	if v, ok := patched["code"]; ok {
-		if cc, ok := v.(map[string]interface{}); ok {
-			if coding, ok := cc["coding"].([]interface{}); ok && len(coding) > 0 {
-				if c, ok := coding[0].(map[string]interface{}); ok {
-					if code, ok := c["code"].(string); ok {
-						dr.CodeValue = code
-					}
-					if display, ok := c["display"].(string); ok {
-						dr.CodeDisplay = display
-					}
-					if system, ok := c["system"].(string); ok {
-						dr.CodeSystem = &system
-					}
-				}
-			}
-			if text, ok := cc["text"].(string); ok {
-				dr.CodeDisplay = text
-			}
-		}
-	}
-	// effectiveDateTime
-	diagPatchTimePtr(patched, "effectiveDateTime", &dr.EffectiveDatetime)
-	// effectivePeriod
-	if v, ok := patched["effectivePeriod"].(map[string]interface{}); ok {
-		diagPatchTimePtr(v, "start", &dr.EffectiveStart)
-		diagPatchTimePtr(v, "end", &dr.EffectiveEnd)
-	}
-	// issued
-	diagPatchTimePtr(patched, "issued", &dr.Issued)
-	// conclusion
-	diagPatchStringPtr(patched, "conclusion", &dr.Conclusion)
-	// conclusionCode
-	if code, display, ok := diagPatchCodeableConceptArray(patched, "conclusionCode"); ok {
-		dr.ConclusionCode = &code
-		dr.ConclusionDisplay = &display
-	}
-	// presentedForm
-	if v, ok := patched["presentedForm"]; ok {
-		if forms, ok := v.([]interface{}); ok && len(forms) > 0 {
-			if form, ok := forms[0].(map[string]interface{}); ok {
-				diagPatchStringPtr(form, "url", &dr.PresentedFormURL)
-				diagPatchStringPtr(form, "contentType", &dr.PresentedFormType)
-			}
-		}
-	}
-	// performer
-	if v, ok := patched["performer"]; ok {
-		if performers, ok := v.([]interface{}); ok && len(performers) > 0 {
-			if perf, ok := performers[0].(map[string]interface{}); ok {
-				if ref, ok := perf["reference"].(string); ok {
-					parts := strings.Split(ref, "/")
-					if len(parts) >= 2 {
-						if id, err := uuid.Parse(parts[len(parts)-1]); err == nil {
-							dr.PerformerID = &id
-						}
-					}
-				}
-			}
-		}
-	}
-}
-
-func applySpecimenPatch(sp *Specimen, patched map[string]interface{}) {
-	if v, ok := patched["status"].(string); ok {
-		sp.Status = v
-	}
-	// type
-	if code, display, ok := diagPatchCodeableConcept(patched, "type"); ok {
-		sp.TypeCode = &code
-		sp.TypeDisplay = &display
-	}
-	// accessionIdentifier
-	if v, ok := patched["accessionIdentifier"].(map[string]interface{}); ok {
-		diagPatchStringPtr(v, "value", &sp.AccessionID)
-	}
-	// receivedTime
-	diagPatchTimePtr(patched, "receivedTime", &sp.ReceivedTime)
-	// collection
-	if v, ok := patched["collection"].(map[string]interface{}); ok {
-		// collectedDateTime
-		diagPatchTimePtr(v, "collectedDateTime", &sp.CollectionDatetime)
-		// quantity
-		if qty, ok := v["quantity"].(map[string]interface{}); ok {
-			diagPatchFloat64Ptr(qty, "value", &sp.CollectionQuantity)
-			diagPatchStringPtr(qty, "unit", &sp.CollectionUnit)
-		}
-		// method
-		if method, ok := v["method"].(map[string]interface{}); ok {
-			if coding, ok := method["coding"].([]interface{}); ok && len(coding) > 0 {
-				if c, ok := coding[0].(map[string]interface{}); ok {
-					if code, ok := c["code"].(string); ok {
-						sp.CollectionMethod = &code
-					}
-				}
-			}
-		}
-		// bodySite
-		if bodySite, ok := v["bodySite"].(map[string]interface{}); ok {
-			if coding, ok := bodySite["coding"].([]interface{}); ok && len(coding) > 0 {
-				if c, ok := coding[0].(map[string]interface{}); ok {
-					if code, ok := c["code"].(string); ok {
-						sp.CollectionBodySite = &code
-					}
-				}
-			}
-		}
-		// collector
-		if collector, ok := v["collector"].(map[string]interface{}); ok {
-			if ref, ok := collector["reference"].(string); ok {
-				parts := strings.Split(ref, "/")
-				if len(parts) >= 2 {
-					if id, err := uuid.Parse(parts[len(parts)-1]); err == nil {
-						sp.CollectionCollector = &id
-					}
-				}
-			}
-		}
-	}
-	// condition
-	if code, display, ok := diagPatchCodeableConceptArray(patched, "condition"); ok {
-		sp.ConditionCode = &code
-		sp.ConditionDisplay = &display
-	}
-	// note
-	if v, ok := patched["note"]; ok {
-		if notes, ok := v.([]interface{}); ok && len(notes) > 0 {
-			if note, ok := notes[0].(map[string]interface{}); ok {
-				diagPatchStringPtr(note, "text", &sp.Note)
-			}
-		}
-	}
-	// container
-	if v, ok := patched["container"]; ok {
-		if containers, ok := v.([]interface{}); ok && len(containers) > 0 {
-			if container, ok := containers[0].(map[string]interface{}); ok {
-				diagPatchStringPtr(container, "description", &sp.ContainerDesc)
-				if code, _, ok := diagPatchCodeableConcept(container, "type"); ok {
-					sp.ContainerType = &code
-				}
-			}
-		}
-	}
-	// processing
-	if v, ok := patched["processing"]; ok {
-		if procs, ok := v.([]interface{}); ok && len(procs) > 0 {
-			if proc, ok := procs[0].(map[string]interface{}); ok {
-				diagPatchStringPtr(proc, "description", &sp.ProcessingDesc)
-				diagPatchTimePtr(proc, "timeDateTime", &sp.ProcessingDatetime)
-				if procedure, ok := proc["procedure"].(map[string]interface{}); ok {
-					if coding, ok := procedure["coding"].([]interface{}); ok && len(coding) > 0 {
-						if c, ok := coding[0].(map[string]interface{}); ok {
-							if code, ok := c["code"].(string); ok {
-								sp.ProcessingProcedure = &code
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-}
-
-func applyImagingStudyPatch(is *ImagingStudy, patched map[string]interface{}) {
-	if v, ok := patched["status"].(string); ok {
-		is.Status = v
-	}
-	// modality
-	if v, ok := patched["modality"]; ok {
-		if modalities, ok := v.([]interface{}); ok && len(modalities) > 0 {
-			if mod, ok := modalities[0].(map[string]interface{}); ok {
-				if code, ok := mod["code"].(string); ok {
-					is.ModalityCode = &code
-				}
-				if display, ok := mod["display"].(string); ok {
-					is.ModalityDisplay = &display
-				}
-			}
-		}
-	}
-	// description
-	diagPatchStringPtr(patched, "description", &is.Description)
-	// started
-	diagPatchTimePtr(patched, "started", &is.Started)
-	// numberOfSeries
-	diagPatchIntPtr(patched, "numberOfSeries", &is.NumberOfSeries)
-	// numberOfInstances
-	diagPatchIntPtr(patched, "numberOfInstances", &is.NumberOfInstances)
-	// reasonCode
-	if code, display, ok := diagPatchCodeableConceptArray(patched, "reasonCode"); ok {
-		is.ReasonCode = &code
-		is.ReasonDisplay = &display
-	}
-	// endpoint
-	if v, ok := patched["endpoint"]; ok {
-		if endpoints, ok := v.([]interface{}); ok && len(endpoints) > 0 {
-			if ep, ok := endpoints[0].(map[string]interface{}); ok {
-				if ref, ok := ep["reference"].(string); ok {
-					is.Endpoint = &ref
-				}
-			}
-		}
-	}
-	// identifier (studyUID)
-	if v, ok := patched["identifier"]; ok {
-		if idents, ok := v.([]interface{}); ok && len(idents) > 0 {
-			if ident, ok := idents[0].(map[string]interface{}); ok {
-				diagPatchStringPtr(ident, "value", &is.StudyUID)
-			}
-		}
-	}
-	// note
-	if v, ok := patched["note"]; ok {
-		if notes, ok := v.([]interface{}); ok && len(notes) > 0 {
-			if note, ok := notes[0].(map[string]interface{}); ok {
-				diagPatchStringPtr(note, "text", &is.Note)
-			}
-		}
-	}
-	// referrer
-	if v, ok := patched["referrer"].(map[string]interface{}); ok {
-		if ref, ok := v["reference"].(string); ok {
-			parts := strings.Split(ref, "/")
-			if len(parts) >= 2 {
-				if id, err := uuid.Parse(parts[len(parts)-1]); err == nil {
-					is.ReferrerID = &id
-				}
-			}
-		}
-	}
-}
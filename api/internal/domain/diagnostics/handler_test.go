@@ -571,7 +571,7 @@ func TestHandler_GetServiceRequestFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateServiceRequestFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","requester_id":"` + uuid.New().String() + `","code_value":"CBC","code_display":"Complete Blood Count"}`
+	body := `{"resourceType":"ServiceRequest","subject":{"reference":"Patient/` + uuid.New().String() + `"},"requester":{"reference":"Practitioner/` + uuid.New().String() + `"},"code":{"coding":[{"code":"CBC","display":"Complete Blood Count"}]}}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -595,7 +595,7 @@ func TestHandler_UpdateServiceRequestFHIR(t *testing.T) {
 	sr := &ServiceRequest{PatientID: uuid.New(), RequesterID: uuid.New(), CodeValue: "CBC"}
 	h.svc.CreateServiceRequest(nil, sr)
 
-	body := `{"patient_id":"` + sr.PatientID.String() + `","requester_id":"` + sr.RequesterID.String() + `","code_value":"CMP"}`
+	body := `{"resourceType":"ServiceRequest","subject":{"reference":"Patient/` + sr.PatientID.String() + `"},"requester":{"reference":"Practitioner/` + sr.RequesterID.String() + `"},"code":{"coding":[{"code":"CMP"}]}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -733,7 +733,7 @@ func TestHandler_GetDiagnosticReportFHIR(t *testing.T) {
 
 func TestHandler_CreateDiagnosticReportFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","code_value":"CBC","code_display":"Complete Blood Count"}`
+	body := `{"resourceType":"DiagnosticReport","subject":{"reference":"Patient/` + uuid.New().String() + `"},"code":{"coding":[{"code":"CBC","display":"Complete Blood Count"}]}}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -757,7 +757,7 @@ func TestHandler_UpdateDiagnosticReportFHIR(t *testing.T) {
 	dr := &DiagnosticReport{PatientID: uuid.New(), CodeValue: "CBC"}
 	h.svc.CreateDiagnosticReport(nil, dr)
 
-	body := `{"patient_id":"` + dr.PatientID.String() + `","code_value":"CMP"}`
+	body := `{"resourceType":"DiagnosticReport","subject":{"reference":"Patient/` + dr.PatientID.String() + `"},"code":{"coding":[{"code":"CMP"}]}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -895,7 +895,7 @@ func TestHandler_GetSpecimenFHIR(t *testing.T) {
 
 func TestHandler_CreateSpecimenFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"Specimen","subject":{"reference":"Patient/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -919,7 +919,7 @@ func TestHandler_UpdateSpecimenFHIR(t *testing.T) {
 	sp := &Specimen{PatientID: uuid.New()}
 	h.svc.CreateSpecimen(nil, sp)
 
-	body := `{"patient_id":"` + sp.PatientID.String() + `"}`
+	body := `{"resourceType":"Specimen","subject":{"reference":"Patient/` + sp.PatientID.String() + `"}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -1057,7 +1057,7 @@ func TestHandler_GetImagingStudyFHIR(t *testing.T) {
 
 func TestHandler_CreateImagingStudyFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"ImagingStudy","subject":{"reference":"Patient/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -1081,7 +1081,7 @@ func TestHandler_UpdateImagingStudyFHIR(t *testing.T) {
 	is := &ImagingStudy{PatientID: uuid.New()}
 	h.svc.CreateImagingStudy(nil, is)
 
-	body := `{"patient_id":"` + is.PatientID.String() + `"}`
+	body := `{"resourceType":"ImagingStudy","subject":{"reference":"Patient/` + is.PatientID.String() + `"}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -1208,9 +1208,9 @@ func TestHandler_RegisterRoutes(t *testing.T) {
 	}
 }
 
-// -- applyServiceRequestPatch unit tests --
+// -- ServiceRequest.FromFHIR (PATCH) tests --
 
-func TestApplyServiceRequestPatch_Status(t *testing.T) {
+func TestServiceRequest_FromFHIR_PatchStatus(t *testing.T) {
 	sr := &ServiceRequest{
 		Status:      "draft",
 		Intent:      "order",
@@ -1225,7 +1225,9 @@ func TestApplyServiceRequestPatch_Status(t *testing.T) {
 		"intent":   "plan",
 		"priority": "urgent",
 	}
-	applyServiceRequestPatch(sr, patched)
+	if err := sr.FromFHIR(applyPatch(t, sr.ToFHIR(), patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
 
 	if sr.Status != "active" {
 		t.Errorf("expected status 'active', got %q", sr.Status)
@@ -1241,7 +1243,7 @@ func TestApplyServiceRequestPatch_Status(t *testing.T) {
 	}
 }
 
-func TestApplyServiceRequestPatch_Code(t *testing.T) {
+func TestServiceRequest_FromFHIR_PatchCode(t *testing.T) {
 	sr := &ServiceRequest{
 		Status:      "draft",
 		Intent:      "order",
@@ -1278,7 +1280,9 @@ func TestApplyServiceRequestPatch_Code(t *testing.T) {
 		},
 		"patientInstruction": "Do not eat for 12 hours before test",
 	}
-	applyServiceRequestPatch(sr, patched)
+	if err := sr.FromFHIR(applyPatch(t, sr.ToFHIR(), patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
 
 	if sr.CodeValue != "CMP" {
 		t.Errorf("expected CodeValue 'CMP', got %q", sr.CodeValue)
@@ -1306,9 +1310,9 @@ func TestApplyServiceRequestPatch_Code(t *testing.T) {
 	}
 }
 
-// -- applyDiagnosticReportPatch unit tests --
+// -- DiagnosticReport.FromFHIR (PATCH) tests --
 
-func TestApplyDiagnosticReportPatch_StatusConclusion(t *testing.T) {
+func TestDiagnosticReport_FromFHIR_PatchStatusConclusion(t *testing.T) {
 	dr := &DiagnosticReport{
 		Status:      "preliminary",
 		CodeValue:   "CBC",
@@ -1337,7 +1341,9 @@ func TestApplyDiagnosticReportPatch_StatusConclusion(t *testing.T) {
 			},
 		},
 	}
-	applyDiagnosticReportPatch(dr, patched)
+	if err := dr.FromFHIR(applyPatch(t, dr.ToFHIR(), patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
 
 	if dr.Status != "final" {
 		t.Errorf("expected status 'final', got %q", dr.Status)
@@ -1365,9 +1371,9 @@ func TestApplyDiagnosticReportPatch_StatusConclusion(t *testing.T) {
 	}
 }
 
-// -- applySpecimenPatch unit tests --
+// -- Specimen.FromFHIR (PATCH) tests --
 
-func TestApplySpecimenPatch_Status(t *testing.T) {
+func TestSpecimen_FromFHIR_PatchStatus(t *testing.T) {
 	sp := &Specimen{
 		Status:    "available",
 		PatientID: uuid.New(),
@@ -1424,7 +1430,9 @@ func TestApplySpecimenPatch_Status(t *testing.T) {
 			},
 		},
 	}
-	applySpecimenPatch(sp, patched)
+	if err := sp.FromFHIR(applyPatch(t, sp.ToFHIR(), patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
 
 	if sp.Status != "unavailable" {
 		t.Errorf("expected status 'unavailable', got %q", sp.Status)
@@ -1464,9 +1472,9 @@ func TestApplySpecimenPatch_Status(t *testing.T) {
 	}
 }
 
-// -- applyImagingStudyPatch unit tests --
+// -- ImagingStudy.FromFHIR (PATCH) tests --
 
-func TestApplyImagingStudyPatch_StatusModality(t *testing.T) {
+func TestImagingStudy_FromFHIR_PatchStatusModality(t *testing.T) {
 	is := &ImagingStudy{
 		Status:    "registered",
 		PatientID: uuid.New(),
@@ -1510,7 +1518,9 @@ func TestApplyImagingStudyPatch_StatusModality(t *testing.T) {
 			},
 		},
 	}
-	applyImagingStudyPatch(is, patched)
+	if err := is.FromFHIR(applyPatch(t, is.ToFHIR(), patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
 
 	if is.Status != "available" {
 		t.Errorf("expected status 'available', got %q", is.Status)
@@ -1549,3 +1559,21 @@ func TestApplyImagingStudyPatch_StatusModality(t *testing.T) {
 		t.Errorf("expected Note 'Contrast administered', got %v", is.Note)
 	}
 }
+
+// applyPatch overlays the top-level elements of patch onto a JSON round trip
+// of current, mirroring what a merge patch produces in the PATCH handlers.
+func applyPatch(t *testing.T, current, patch map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(current)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var resource map[string]interface{}
+	if err := json.Unmarshal(raw, &resource); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	for k, v := range patch {
+		resource[k] = v
+	}
+	return resource
+}
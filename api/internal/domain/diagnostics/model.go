@@ -115,6 +115,37 @@ func (sr *ServiceRequest) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the request from a FHIR ServiceRequest resource, the
+// inverse of ToFHIR.
+func (sr *ServiceRequest) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ServiceRequest", data)
+	sr.Status = r.CodeValue("status", "draft", "active", "on-hold", "revoked",
+		"completed", "entered-in-error", "unknown")
+	sr.Intent = r.CodeValue("intent", "proposal", "plan", "directive", "order",
+		"original-order", "reflex-order", "filler-order", "instance-order", "option")
+	code := r.CodeableConcept("code")
+	sr.CodeSystem = code.System
+	sr.CodeValue = r.RequiredString("code.coding.code")
+	sr.CodeDisplay = strVal(code.Display)
+	sr.PatientID = r.RequiredReferenceID("subject", "Patient")
+	sr.RequesterID = r.RequiredReferenceID("requester", "Practitioner")
+	sr.Priority = r.Code("priority", "routine", "urgent", "asap", "stat")
+	category := r.CodeableConcept("category")
+	sr.CategoryCode, sr.CategoryDisplay = category.Code, category.Display
+	sr.EncounterID = r.ReferenceID("encounter", "Encounter")
+	sr.PerformerID = r.ReferenceID("performer", "Practitioner")
+	sr.OccurrenceDatetime = r.DateTime("occurrenceDateTime")
+	sr.OccurrenceStart, sr.OccurrenceEnd = r.Period("occurrencePeriod")
+	sr.AuthoredOn = r.DateTime("authoredOn")
+	reason := r.CodeableConcept("reasonCode")
+	sr.ReasonCode, sr.ReasonDisplay = reason.Code, reason.Display
+	bodySite := r.CodeableConcept("bodySite")
+	sr.BodySiteCode, sr.BodySiteDisplay = bodySite.Code, bodySite.Display
+	sr.Note = r.Annotation("note")
+	sr.PatientInstruction = r.String("patientInstruction")
+	return r.Err()
+}
+
 // Specimen maps to the specimen table (FHIR Specimen resource).
 type Specimen struct {
 	ID                 uuid.UUID  `db:"id" json:"id"`
@@ -210,6 +241,28 @@ func (sp *Specimen) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the specimen from a FHIR Specimen resource, the inverse
+// of ToFHIR.
+func (sp *Specimen) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Specimen", data)
+	sp.Status = r.CodeValue("status", "available", "unavailable", "unsatisfactory", "entered-in-error")
+	sp.PatientID = r.RequiredReferenceID("subject", "Patient")
+	sp.AccessionID = r.String("accessionIdentifier.value")
+	specimenType := r.CodeableConcept("type")
+	sp.TypeCode, sp.TypeDisplay = specimenType.Code, specimenType.Display
+	sp.ReceivedTime = r.DateTime("receivedTime")
+	sp.CollectionCollector = r.ReferenceID("collection.collector", "Practitioner")
+	sp.CollectionDatetime = r.DateTime("collection.collectedDateTime")
+	quantity := r.Quantity("collection.quantity")
+	sp.CollectionQuantity, sp.CollectionUnit = quantity.Value, quantity.Unit
+	sp.CollectionMethod = r.CodeableConcept("collection.method").Code
+	sp.CollectionBodySite = r.CodeableConcept("collection.bodySite").Code
+	condition := r.CodeableConcept("condition")
+	sp.ConditionCode, sp.ConditionDisplay = condition.Code, condition.Display
+	sp.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // DiagnosticReport maps to the diagnostic_report table (FHIR DiagnosticReport resource).
 type DiagnosticReport struct {
 	ID                uuid.UUID  `db:"id" json:"id"`
@@ -304,6 +357,33 @@ func (dr *DiagnosticReport) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the report from a FHIR DiagnosticReport resource, the
+// inverse of ToFHIR.
+func (dr *DiagnosticReport) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("DiagnosticReport", data)
+	dr.Status = r.CodeValue("status", "registered", "partial", "preliminary", "final",
+		"amended", "corrected", "appended", "cancelled", "entered-in-error", "unknown")
+	code := r.CodeableConcept("code")
+	dr.CodeSystem = code.System
+	dr.CodeValue = r.RequiredString("code.coding.code")
+	dr.CodeDisplay = strVal(code.Display)
+	dr.PatientID = r.RequiredReferenceID("subject", "Patient")
+	category := r.CodeableConcept("category")
+	dr.CategoryCode, dr.CategoryDisplay = category.Code, category.Display
+	dr.EncounterID = r.ReferenceID("encounter", "Encounter")
+	dr.PerformerID = r.ReferenceID("performer", "Practitioner")
+	dr.EffectiveDatetime = r.DateTime("effectiveDateTime")
+	dr.EffectiveStart, dr.EffectiveEnd = r.Period("effectivePeriod")
+	dr.Issued = r.DateTime("issued")
+	dr.SpecimenID = r.ReferenceID("specimen", "Specimen")
+	dr.Conclusion = r.String("conclusion")
+	conclusion := r.CodeableConcept("conclusionCode")
+	dr.ConclusionCode, dr.ConclusionDisplay = conclusion.Code, conclusion.Display
+	dr.PresentedFormURL = r.String("presentedForm.url")
+	dr.PresentedFormType = r.String("presentedForm.contentType")
+	return r.Err()
+}
+
 // DiagnosticReportResult maps to the diagnostic_report_result junction table.
 type DiagnosticReportResult struct {
 	DiagnosticReportID uuid.UUID `db:"diagnostic_report_id" json:"diagnostic_report_id"`
@@ -393,6 +473,31 @@ func (is *ImagingStudy) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the study from a FHIR ImagingStudy resource, the inverse
+// of ToFHIR.
+func (is *ImagingStudy) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ImagingStudy", data)
+	is.Status = r.CodeValue("status", "registered", "available", "cancelled", "entered-in-error", "unknown")
+	is.PatientID = r.RequiredReferenceID("subject", "Patient")
+	modality := r.Coding("modality")
+	is.ModalityCode, is.ModalityDisplay = modality.Code, modality.Display
+	is.EncounterID = r.ReferenceID("encounter", "Encounter")
+	is.ReferrerID = r.ReferenceID("referrer", "Practitioner")
+	is.StudyUID = r.IdentifierValue("identifier", "urn:dicom:uid")
+	if is.StudyUID == nil {
+		is.StudyUID = r.IdentifierValue("identifier", "")
+	}
+	is.NumberOfSeries = r.Int("numberOfSeries")
+	is.NumberOfInstances = r.Int("numberOfInstances")
+	is.Description = r.String("description")
+	is.Started = r.DateTime("started")
+	is.Endpoint = r.Reference("endpoint")
+	reason := r.CodeableConcept("reasonCode")
+	is.ReasonCode, is.ReasonDisplay = reason.Code, reason.Display
+	is.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // OrderStatusHistory records a status transition for service requests or medication requests.
 type OrderStatusHistory struct {
 	ID           uuid.UUID `db:"id" json:"id"`
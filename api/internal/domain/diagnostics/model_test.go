@@ -1,9 +1,11 @@
 package diagnostics
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/ehr/ehr/internal/platform/fhir"
 	"github.com/google/uuid"
 )
 
@@ -562,3 +564,84 @@ func TestImagingStudyToFHIR_WithOptionalFields(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestServiceRequest_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &ServiceRequest{
+		FHIRID:          "sr-rt",
+		Status:          "active",
+		Intent:          "order",
+		Priority:        ptrStr("stat"),
+		CodeSystem:      ptrStr("http://loinc.org"),
+		CodeValue:       "58410-2",
+		CodeDisplay:     "CBC panel",
+		PatientID:       uuid.New(),
+		RequesterID:     uuid.New(),
+		EncounterID:     ptrUUID(uuid.New()),
+		OccurrenceStart: ptrTime(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)),
+		OccurrenceEnd:   ptrTime(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)),
+		Note:            ptrStr("fasting"),
+	}
+
+	var got ServiceRequest
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Status != "active" || got.Intent != "order" || *got.Priority != "stat" {
+		t.Errorf("status/intent/priority mismatch: %+v", got)
+	}
+	if *got.CodeSystem != "http://loinc.org" || got.CodeValue != "58410-2" || got.CodeDisplay != "CBC panel" {
+		t.Errorf("code mismatch: %+v", got)
+	}
+	if got.PatientID != orig.PatientID || got.RequesterID != orig.RequesterID || *got.EncounterID != *orig.EncounterID {
+		t.Errorf("references mismatch: %+v", got)
+	}
+	if !got.OccurrenceStart.Equal(*orig.OccurrenceStart) || !got.OccurrenceEnd.Equal(*orig.OccurrenceEnd) {
+		t.Errorf("occurrencePeriod mismatch: %v - %v", got.OccurrenceStart, got.OccurrenceEnd)
+	}
+	if got.Note == nil || *got.Note != "fasting" {
+		t.Errorf("note mismatch: %v", got.Note)
+	}
+}
+
+func TestDiagnosticReport_FromFHIR_InvalidElements(t *testing.T) {
+	var dr DiagnosticReport
+	err := dr.FromFHIR(map[string]interface{}{
+		"resourceType": "DiagnosticReport",
+		"status":       "done",
+		"subject":      map[string]interface{}{"reference": "Group/" + uuid.New().String()},
+		"issued":       "yesterday",
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	want := map[string]bool{
+		"DiagnosticReport.status":            true,
+		"DiagnosticReport.subject.reference": true,
+		"DiagnosticReport.code.coding.code":  true,
+		"DiagnosticReport.issued":            true,
+	}
+	for _, issue := range pe.Outcome.Issue {
+		delete(want, issue.Expression[0])
+	}
+	if len(want) != 0 {
+		t.Errorf("missing issues for %v in %+v", want, pe.Outcome.Issue)
+	}
+}
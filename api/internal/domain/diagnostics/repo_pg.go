@@ -95,7 +95,7 @@ func (r *serviceRequestRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (
 func (r *serviceRequestRepoPG) Update(ctx context.Context, sr *ServiceRequest) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE service_request SET status=$2, intent=$3, priority=$4,
-			performer_id=$5, occurrence_datetime=$6, note=$7, updated_at=NOW()
+			performer_id=$5, occurrence_datetime=$6, note=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		sr.ID, sr.Status, sr.Intent, sr.Priority,
 		sr.PerformerID, sr.OccurrenceDatetime, sr.Note)
@@ -138,13 +138,16 @@ var srSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *serviceRequestRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ServiceRequest, int, error) {
 	qb := fhir.NewSearchQuery("service_request", srCols)
-	qb.ApplyParams(params, srSearchParams)
+	qb.ApplyParams(ctx, params, srSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -281,7 +284,7 @@ func (r *specimenRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Speci
 func (r *specimenRepoPG) Update(ctx context.Context, sp *Specimen) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE specimen SET status=$2, type_code=$3, type_display=$4,
-			condition_code=$5, condition_display=$6, note=$7, updated_at=NOW()
+			condition_code=$5, condition_display=$6, note=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		sp.ID, sp.Status, sp.TypeCode, sp.TypeDisplay,
 		sp.ConditionCode, sp.ConditionDisplay, sp.Note)
@@ -322,13 +325,16 @@ var spSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *specimenRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Specimen, int, error) {
 	qb := fhir.NewSearchQuery("specimen", spCols)
-	qb.ApplyParams(params, spSearchParams)
+	qb.ApplyParams(ctx, params, spSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -415,7 +421,7 @@ func (r *diagnosticReportRepoPG) GetByFHIRID(ctx context.Context, fhirID string)
 func (r *diagnosticReportRepoPG) Update(ctx context.Context, dr *DiagnosticReport) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE diagnostic_report SET status=$2, conclusion=$3, conclusion_code=$4,
-			conclusion_display=$5, note=$6, updated_at=NOW()
+			conclusion_display=$5, note=$6, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		dr.ID, dr.Status, dr.Conclusion, dr.ConclusionCode,
 		dr.ConclusionDisplay, dr.Note)
@@ -459,13 +465,16 @@ var drSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *diagnosticReportRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*DiagnosticReport, int, error) {
 	qb := fhir.NewSearchQuery("diagnostic_report", drCols)
-	qb.ApplyParams(params, drSearchParams)
+	qb.ApplyParams(ctx, params, drSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -576,7 +585,7 @@ func (r *imagingStudyRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*I
 func (r *imagingStudyRepoPG) Update(ctx context.Context, is *ImagingStudy) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE imaging_study SET status=$2, number_of_series=$3, number_of_instances=$4,
-			description=$5, note=$6, updated_at=NOW()
+			description=$5, note=$6, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		is.ID, is.Status, is.NumberOfSeries, is.NumberOfInstances,
 		is.Description, is.Note)
@@ -617,13 +626,16 @@ var isSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *imagingStudyRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ImagingStudy, int, error) {
 	qb := fhir.NewSearchQuery("imaging_study", isCols)
-	qb.ApplyParams(params, isSearchParams)
+	qb.ApplyParams(ctx, params, isSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -640,3 +652,12 @@ func (r *imagingStudyRepoPG) Search(ctx context.Context, params map[string]strin
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("service_request", srSearchParams)
+	r.RegisterTable("specimen", spSearchParams)
+	r.RegisterTable("diagnostic_report", drSearchParams)
+	r.RegisterTable("imaging_study", isSearchParams)
+}
@@ -64,6 +64,22 @@ func (d *DocumentManifest) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the manifest from a FHIR DocumentManifest resource, the
+// inverse of ToFHIR.
+func (d *DocumentManifest) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("DocumentManifest", data)
+	d.Status = r.CodeValue("status", "current", "superseded", "entered-in-error")
+	docType := r.CodeableConcept("type")
+	d.TypeCode, d.TypeDisplay = docType.Code, docType.Display
+	d.SubjectReference = r.Reference("subject")
+	d.Created = r.DateTime("created")
+	d.AuthorReference = r.Reference("author")
+	d.RecipientReference = r.Reference("recipient")
+	d.SourceURL = r.String("source")
+	d.Description = r.String("description")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
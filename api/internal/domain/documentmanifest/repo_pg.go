@@ -71,7 +71,7 @@ func (r *documentManifestRepoPG) GetByFHIRID(ctx context.Context, fhirID string)
 func (r *documentManifestRepoPG) Update(ctx context.Context, d *DocumentManifest) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE document_manifest SET status=$2, type_code=$3, type_display=$4, subject_reference=$5,
-			created=$6, author_reference=$7, recipient_reference=$8, source_url=$9, description=$10, updated_at=NOW()
+			created=$6, author_reference=$7, recipient_reference=$8, source_url=$9, description=$10, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		d.ID, d.Status, d.TypeCode, d.TypeDisplay, d.SubjectReference,
 		d.Created, d.AuthorReference, d.RecipientReference, d.SourceURL, d.Description)
@@ -112,13 +112,16 @@ var dmSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *documentManifestRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*DocumentManifest, int, error) {
 	qb := fhir.NewSearchQuery("document_manifest", dmCols)
-	qb.ApplyParams(params, dmSearchParams)
+	qb.ApplyParams(ctx, params, dmSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -135,3 +138,9 @@ func (r *documentManifestRepoPG) Search(ctx context.Context, params map[string]s
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("document_manifest", dmSearchParams)
+}
@@ -100,6 +100,12 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Composition/:id/_history", h.HistoryCompositionFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Consent", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("DocumentReference", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("Composition", fhir.ReadOnlyInteractions()...)
+}
+
 // -- Consent Handlers --
 
 func (h *Handler) CreateConsent(c echo.Context) error {
@@ -532,8 +538,8 @@ func (h *Handler) GetConsentFHIR(c echo.Context) error {
 
 func (h *Handler) CreateConsentFHIR(c echo.Context) error {
 	var consent Consent
-	if err := c.Bind(&consent); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Consent", "", &consent); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateConsent(c.Request().Context(), &consent); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -576,8 +582,8 @@ func (h *Handler) GetDocumentReferenceFHIR(c echo.Context) error {
 
 func (h *Handler) CreateDocumentReferenceFHIR(c echo.Context) error {
 	var doc DocumentReference
-	if err := c.Bind(&doc); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "DocumentReference", "", &doc); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateDocumentReference(c.Request().Context(), &doc); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -588,35 +594,22 @@ func (h *Handler) CreateDocumentReferenceFHIR(c echo.Context) error {
 
 func (h *Handler) SearchCompositionsFHIR(c echo.Context) error {
 	pg := pagination.FromContext(c)
-	if patientID := c.QueryParam("patient"); patientID != "" {
-		pid, err := uuid.Parse(patientID)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("invalid patient parameter"))
-		}
-		items, total, err := h.svc.ListCompositionsByPatient(c.Request().Context(), pid, pg.Limit, pg.Offset)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, fhir.ErrorOutcome(err.Error()))
-		}
-		resources := make([]interface{}, len(items))
-		for i, item := range items {
-			resources[i] = item.ToFHIR()
-		}
-		return c.JSON(http.StatusOK, fhir.NewSearchBundleWithLinks(resources, fhir.SearchBundleParams{
-			ServerBaseURL: fhir.ServerBaseURLFromRequest(c),
-			BaseURL:  "/fhir/Composition",
-			QueryStr: c.QueryString(),
-			Count:    pg.Limit,
-			Offset:   pg.Offset,
-			Total:    total,
-		}))
-	}
-	return c.JSON(http.StatusOK, fhir.NewSearchBundleWithLinks(nil, fhir.SearchBundleParams{
+	params := fhir.ExtractSearchParams(c)
+	items, total, err := h.svc.SearchCompositions(c.Request().Context(), params, pg.Limit, pg.Offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, fhir.ErrorOutcome(err.Error()))
+	}
+	resources := make([]interface{}, len(items))
+	for i, item := range items {
+		resources[i] = item.ToFHIR()
+	}
+	return c.JSON(http.StatusOK, fhir.NewSearchBundleWithLinks(resources, fhir.SearchBundleParams{
 		ServerBaseURL: fhir.ServerBaseURLFromRequest(c),
 		BaseURL:  "/fhir/Composition",
 		QueryStr: c.QueryString(),
 		Count:    pg.Limit,
 		Offset:   pg.Offset,
-		Total:    0,
+		Total:    total,
 	}))
 }
 
@@ -631,8 +624,8 @@ func (h *Handler) GetCompositionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateCompositionFHIR(c echo.Context) error {
 	var comp Composition
-	if err := c.Bind(&comp); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Composition", "", &comp); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateComposition(c.Request().Context(), &comp); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -644,16 +637,14 @@ func (h *Handler) CreateCompositionFHIR(c echo.Context) error {
 // -- FHIR Update Endpoints --
 
 func (h *Handler) UpdateConsentFHIR(c echo.Context) error {
-	var consent Consent
-	if err := c.Bind(&consent); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetConsentByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Consent", c.Param("id")))
 	}
-	consent.ID = existing.ID
-	consent.FHIRID = existing.FHIRID
+	consent := *existing
+	if err := fhir.BindResource(c, "Consent", existing.FHIRID, &consent); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateConsent(c.Request().Context(), &consent); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -661,16 +652,14 @@ func (h *Handler) UpdateConsentFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateDocumentReferenceFHIR(c echo.Context) error {
-	var doc DocumentReference
-	if err := c.Bind(&doc); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetDocumentReferenceByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DocumentReference", c.Param("id")))
 	}
-	doc.ID = existing.ID
-	doc.FHIRID = existing.FHIRID
+	doc := *existing
+	if err := fhir.BindResource(c, "DocumentReference", existing.FHIRID, &doc); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateDocumentReference(c.Request().Context(), &doc); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -678,16 +667,14 @@ func (h *Handler) UpdateDocumentReferenceFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateCompositionFHIR(c echo.Context) error {
-	var comp Composition
-	if err := c.Bind(&comp); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetCompositionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Composition", c.Param("id")))
 	}
-	comp.ID = existing.ID
-	comp.FHIRID = existing.FHIRID
+	comp := *existing
+	if err := fhir.BindResource(c, "Composition", existing.FHIRID, &comp); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateComposition(c.Request().Context(), &comp); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -737,8 +724,8 @@ func (h *Handler) PatchConsentFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Consent", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateConsent(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -753,8 +740,8 @@ func (h *Handler) PatchDocumentReferenceFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("DocumentReference", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateDocumentReference(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -769,8 +756,8 @@ func (h *Handler) PatchCompositionFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Composition", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateComposition(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -795,19 +782,19 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string, apply
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "DocumentReference":
 		existing, err := h.svc.GetDocumentReferenceByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "Composition":
 		existing, err := h.svc.GetCompositionByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	default:
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("unsupported resource type for PATCH"))
 	}
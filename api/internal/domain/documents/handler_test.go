@@ -566,7 +566,7 @@ func TestHandler_GetConsentFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateConsentFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"Consent","patient":{"reference":"Patient/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -589,7 +589,7 @@ func TestHandler_UpdateConsentFHIR(t *testing.T) {
 	consent := &Consent{PatientID: uuid.New()}
 	h.svc.CreateConsent(nil, consent)
 
-	body := `{"status":"active","patient_id":"` + consent.PatientID.String() + `"}`
+	body := `{"resourceType":"Consent","status":"active","patient":{"reference":"Patient/` + consent.PatientID.String() + `"}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -842,7 +842,7 @@ func TestHandler_GetDocumentReferenceFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateDocumentReferenceFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"DocumentReference","subject":{"reference":"Patient/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -865,7 +865,7 @@ func TestHandler_UpdateDocumentReferenceFHIR(t *testing.T) {
 	doc := &DocumentReference{PatientID: uuid.New()}
 	h.svc.CreateDocumentReference(nil, doc)
 
-	body := `{"status":"superseded","patient_id":"` + doc.PatientID.String() + `"}`
+	body := `{"resourceType":"DocumentReference","status":"superseded","subject":{"reference":"Patient/` + doc.PatientID.String() + `"}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -1039,7 +1039,7 @@ func TestHandler_GetCompositionFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateCompositionFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"Composition","subject":{"reference":"Patient/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -1062,7 +1062,7 @@ func TestHandler_UpdateCompositionFHIR(t *testing.T) {
 	comp := &Composition{PatientID: uuid.New()}
 	h.svc.CreateComposition(nil, comp)
 
-	body := `{"status":"final","patient_id":"` + comp.PatientID.String() + `"}`
+	body := `{"resourceType":"Composition","status":"final","subject":{"reference":"Patient/` + comp.PatientID.String() + `"}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -96,6 +96,25 @@ func (c *Consent) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the consent from a FHIR Consent resource, the inverse of
+// ToFHIR.
+func (c *Consent) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Consent", data)
+	c.Status = r.CodeValue("status", "draft", "proposed", "active", "rejected", "inactive", "entered-in-error")
+	c.PatientID = r.RequiredReferenceID("patient", "Patient")
+	c.Scope = r.ConceptCode("scope")
+	category := r.CodeableConcept("category")
+	c.CategoryCode, c.CategoryDisplay = category.Code, category.Display
+	c.PerformerID = r.ReferenceID("performer", "Practitioner")
+	c.OrganizationID = r.ReferenceID("organization", "Organization")
+	c.PolicyURI = r.String("policy.uri")
+	c.ProvisionType = r.Code("provision.type", "deny", "permit")
+	c.ProvisionStart, c.ProvisionEnd = r.Period("provision.period")
+	c.ProvisionAction = r.ConceptCode("provision.action")
+	c.DateTime = r.DateTime("dateTime")
+	return r.Err()
+}
+
 // DocumentReference maps to the document_reference table (FHIR DocumentReference resource).
 type DocumentReference struct {
 	ID              uuid.UUID  `db:"id" json:"id"`
@@ -211,6 +230,33 @@ func (d *DocumentReference) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the document reference from a FHIR DocumentReference
+// resource, the inverse of ToFHIR.
+func (d *DocumentReference) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("DocumentReference", data)
+	d.Status = r.CodeValue("status", "current", "superseded", "entered-in-error")
+	d.PatientID = r.RequiredReferenceID("subject", "Patient")
+	d.DocStatus = r.Code("docStatus", "preliminary", "final", "amended", "entered-in-error")
+	docType := r.CodeableConcept("type")
+	d.TypeCode, d.TypeDisplay = docType.Code, docType.Display
+	category := r.CodeableConcept("category")
+	d.CategoryCode, d.CategoryDisplay = category.Code, category.Display
+	d.AuthorID = r.ReferenceID("author", "Practitioner")
+	d.CustodianID = r.ReferenceID("custodian", "Organization")
+	d.EncounterID = r.ReferenceID("context.encounter", "Encounter")
+	d.Date = r.DateTime("date")
+	d.Description = r.String("description")
+	d.SecurityLabel = r.ConceptCode("securityLabel")
+	d.ContentType = r.String("content.attachment.contentType")
+	d.ContentURL = r.String("content.attachment.url")
+	d.ContentSize = r.Int("content.attachment.size")
+	d.ContentHash = r.String("content.attachment.hash")
+	d.ContentTitle = r.String("content.attachment.title")
+	format := r.Coding("content.format")
+	d.FormatCode, d.FormatDisplay = format.Code, format.Display
+	return r.Err()
+}
+
 // ClinicalNote maps to the clinical_note table (structured clinical notes).
 type ClinicalNote struct {
 	ID            uuid.UUID  `db:"id" json:"id"`
@@ -307,6 +353,25 @@ func (comp *Composition) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the composition from a FHIR Composition resource, the
+// inverse of ToFHIR.
+func (comp *Composition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Composition", data)
+	comp.Status = r.CodeValue("status", "preliminary", "final", "amended", "entered-in-error")
+	comp.PatientID = r.RequiredReferenceID("subject", "Patient")
+	compType := r.CodeableConcept("type")
+	comp.TypeCode, comp.TypeDisplay = compType.Code, compType.Display
+	category := r.CodeableConcept("category")
+	comp.CategoryCode, comp.CategoryDisplay = category.Code, category.Display
+	comp.EncounterID = r.ReferenceID("encounter", "Encounter")
+	comp.Date = r.DateTime("date")
+	comp.AuthorID = r.ReferenceID("author", "Practitioner")
+	comp.Title = r.String("title")
+	comp.Confidentiality = r.String("confidentiality")
+	comp.CustodianID = r.ReferenceID("custodian", "Organization")
+	return r.Err()
+}
+
 // CompositionSection maps to the composition_section table.
 type CompositionSection struct {
 	ID              uuid.UUID  `db:"id" json:"id"`
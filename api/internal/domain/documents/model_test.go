@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ehr/ehr/internal/platform/fhir"
 	"github.com/google/uuid"
 )
 
@@ -581,3 +582,81 @@ func TestClinicalNote_JSONOmitsNilOptionalFields(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestDocumentReference_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &DocumentReference{
+		FHIRID:       "doc-rt",
+		Status:       "current",
+		DocStatus:    ptrStr("final"),
+		TypeCode:     ptrStr("34133-9"),
+		TypeDisplay:  ptrStr("Summary of episode note"),
+		PatientID:    uuid.New(),
+		AuthorID:     ptrUUID(uuid.New()),
+		EncounterID:  ptrUUID(uuid.New()),
+		Date:         ptrTime(time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)),
+		ContentType:  ptrStr("application/pdf"),
+		ContentURL:   ptrStr("https://example.org/doc.pdf"),
+		ContentSize:  ptrInt(2048),
+		ContentTitle: ptrStr("Discharge summary"),
+		FormatCode:   ptrStr("urn:ihe:pcc:xds-ms:2007"),
+	}
+
+	var got DocumentReference
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Status != "current" || *got.DocStatus != "final" || *got.TypeCode != "34133-9" {
+		t.Errorf("status/type mismatch: %+v", got)
+	}
+	if got.PatientID != orig.PatientID || *got.AuthorID != *orig.AuthorID || *got.EncounterID != *orig.EncounterID {
+		t.Errorf("references mismatch: %+v", got)
+	}
+	if !got.Date.Equal(*orig.Date) {
+		t.Errorf("date = %v", got.Date)
+	}
+	if *got.ContentType != "application/pdf" || *got.ContentURL != "https://example.org/doc.pdf" ||
+		*got.ContentSize != 2048 || *got.ContentTitle != "Discharge summary" || *got.FormatCode != "urn:ihe:pcc:xds-ms:2007" {
+		t.Errorf("content mismatch: %+v", got)
+	}
+}
+
+func TestConsent_FromFHIR_InvalidElements(t *testing.T) {
+	var c Consent
+	err := c.FromFHIR(map[string]interface{}{
+		"resourceType": "Consent",
+		"status":       "signed",
+		"provision":    map[string]interface{}{"type": "allow"},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	want := map[string]bool{
+		"Consent.status":         true,
+		"Consent.patient":        true,
+		"Consent.provision.type": true,
+	}
+	for _, issue := range pe.Outcome.Issue {
+		delete(want, issue.Expression[0])
+	}
+	if len(want) != 0 {
+		t.Errorf("missing issues for %v in %+v", want, pe.Outcome.Issue)
+	}
+}
@@ -53,6 +53,7 @@ type CompositionRepository interface {
 	Update(ctx context.Context, c *Composition) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	ListByPatient(ctx context.Context, patientID uuid.UUID, limit, offset int) ([]*Composition, int, error)
+	Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Composition, int, error)
 	// Sections
 	AddSection(ctx context.Context, s *CompositionSection) error
 	GetSections(ctx context.Context, compositionID uuid.UUID) ([]*CompositionSection, error)
@@ -128,13 +128,16 @@ var consentSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *consentRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Consent, int, error) {
 	qb := fhir.NewSearchQuery("consent", consentCols)
-	qb.ApplyParams(params, consentSearchParams)
+	qb.ApplyParams(ctx, params, consentSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -265,13 +268,16 @@ var docRefSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *docRefRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*DocumentReference, int, error) {
 	qb := fhir.NewSearchQuery("document_reference", docRefCols)
-	qb.ApplyParams(params, docRefSearchParams)
+	qb.ApplyParams(ctx, params, docRefSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -341,7 +347,7 @@ func (r *noteRepoPG) Update(ctx context.Context, n *ClinicalNote) error {
 		UPDATE clinical_note SET status=$2, title=$3, subjective=$4, objective=$5,
 			assessment=$6, plan=$7, note_text=$8,
 			signed_by=$9, signed_at=$10, cosigned_by=$11, cosigned_at=$12,
-			amended_by=$13, amended_at=$14, amended_reason=$15, updated_at=NOW()
+			amended_by=$13, amended_at=$14, amended_reason=$15, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		n.ID, n.Status, n.Title, n.Subjective, n.Objective,
 		n.Assessment, n.Plan, n.NoteText,
@@ -487,6 +493,45 @@ func (r *compRepoPG) ListByPatient(ctx context.Context, patientID uuid.UUID, lim
 	return items, total, nil
 }
 
+var compositionSearchParams = map[string]fhir.SearchParamConfig{
+	"patient":  {Type: fhir.SearchParamReference, Column: "patient_id"},
+	"status":   {Type: fhir.SearchParamToken, Column: "status"},
+	"type":     {Type: fhir.SearchParamToken, Column: "type_code"},
+	"category": {Type: fhir.SearchParamToken, Column: "category_code"},
+	"date":     {Type: fhir.SearchParamDate, Column: "date"},
+	"title":    {Type: fhir.SearchParamString, Column: "title"},
+	"_id":      {Type: fhir.SearchParamToken, Column: "fhir_id"},
+}
+
+func (r *compRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Composition, int, error) {
+	qb := fhir.NewSearchQuery("composition", compCols)
+	qb.ApplyParams(ctx, params, compositionSearchParams)
+	qb.OrderBy("created_at DESC")
+
+	var total int
+	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var items []*Composition
+	for rows.Next() {
+		c, err := r.scanComp(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, c)
+	}
+	return items, total, nil
+}
+
 func (r *compRepoPG) AddSection(ctx context.Context, s *CompositionSection) error {
 	s.ID = uuid.New()
 	_, err := r.conn(ctx).Exec(ctx, `
@@ -620,3 +665,11 @@ func (r *documentTemplateRepoPG) GetSections(ctx context.Context, templateID uui
 	}
 	return items, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("consent", consentSearchParams)
+	r.RegisterTable("document_reference", docRefSearchParams)
+	r.RegisterTable("composition", compositionSearchParams)
+}
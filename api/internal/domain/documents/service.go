@@ -277,6 +277,10 @@ func (s *Service) ListCompositionsByPatient(ctx context.Context, patientID uuid.
 	return s.comps.ListByPatient(ctx, patientID, limit, offset)
 }
 
+func (s *Service) SearchCompositions(ctx context.Context, params map[string]string, limit, offset int) ([]*Composition, int, error) {
+	return s.comps.Search(ctx, params, limit, offset)
+}
+
 func (s *Service) AddCompositionSection(ctx context.Context, sec *CompositionSection) error {
 	if sec.CompositionID == uuid.Nil {
 		return fmt.Errorf("composition_id is required")
@@ -254,6 +254,14 @@ func (m *mockCompositionRepo) ListByPatient(_ context.Context, patientID uuid.UU
 	return result, len(result), nil
 }
 
+func (m *mockCompositionRepo) Search(_ context.Context, _ map[string]string, limit, offset int) ([]*Composition, int, error) {
+	var result []*Composition
+	for _, c := range m.items {
+		result = append(result, c)
+	}
+	return result, len(result), nil
+}
+
 func (m *mockCompositionRepo) AddSection(_ context.Context, s *CompositionSection) error {
 	s.ID = uuid.New()
 	m.sections[s.ID] = s
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/EffectEvidenceSynthesis/:id/_history", h.HistoryEffectEvidenceSynthesisFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("EffectEvidenceSynthesis", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateEffectEvidenceSynthesis(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetEffectEvidenceSynthesisFHIR(c echo.Context) error {
 
 func (h *Handler) CreateEffectEvidenceSynthesisFHIR(c echo.Context) error {
 	var e EffectEvidenceSynthesis
-	if err := c.Bind(&e); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "EffectEvidenceSynthesis", "", &e); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateEffectEvidenceSynthesis(c.Request().Context(), &e); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateEffectEvidenceSynthesisFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateEffectEvidenceSynthesisFHIR(c echo.Context) error {
-	var e EffectEvidenceSynthesis
-	if err := c.Bind(&e); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetEffectEvidenceSynthesisByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("EffectEvidenceSynthesis", c.Param("id")))
 	}
-	e.ID = existing.ID
-	e.FHIRID = existing.FHIRID
+	e := *existing
+	if err := fhir.BindResource(c, "EffectEvidenceSynthesis", existing.FHIRID, &e); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateEffectEvidenceSynthesis(c.Request().Context(), &e); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("EffectEvidenceSynthesis", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateEffectEvidenceSynthesis(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
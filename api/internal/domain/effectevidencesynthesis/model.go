@@ -84,3 +84,22 @@ func (e *EffectEvidenceSynthesis) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the synthesis from a FHIR EffectEvidenceSynthesis
+// resource, the inverse of ToFHIR.
+func (e *EffectEvidenceSynthesis) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("EffectEvidenceSynthesis", data)
+	e.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	e.URL = r.String("url")
+	e.Name = r.String("name")
+	e.Title = r.String("title")
+	e.Description = r.String("description")
+	e.Publisher = r.String("publisher")
+	e.Date = r.DateTime("date")
+	e.PopulationReference = r.Reference("population")
+	e.ExposureReference = r.Reference("exposure")
+	e.OutcomeReference = r.Reference("outcome")
+	e.SampleSizeDescription = r.String("sampleSize.description")
+	e.ResultByExposureDescription = r.String("resultsByExposure.description")
+	return r.Err()
+}
@@ -76,7 +76,7 @@ func (r *effectEvidenceSynthesisRepoPG) Update(ctx context.Context, e *EffectEvi
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE effect_evidence_synthesis SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
 			population_reference=$9, exposure_reference=$10, outcome_reference=$11,
-			sample_size_description=$12, result_by_exposure_description=$13, updated_at=NOW()
+			sample_size_description=$12, result_by_exposure_description=$13, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.URL, e.Name, e.Title, e.Description, e.Publisher, e.Date,
 		e.PopulationReference, e.ExposureReference, e.OutcomeReference,
@@ -118,13 +118,16 @@ var eesSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *effectEvidenceSynthesisRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*EffectEvidenceSynthesis, int, error) {
 	qb := fhir.NewSearchQuery("effect_evidence_synthesis", eesCols)
-	qb.ApplyParams(params, eesSearchParams)
+	qb.ApplyParams(ctx, params, eesSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -141,3 +144,9 @@ func (r *effectEvidenceSynthesisRepoPG) Search(ctx context.Context, params map[s
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("effect_evidence_synthesis", eesSearchParams)
+}
@@ -76,7 +76,7 @@ func (r *triageRepoPG) Update(ctx context.Context, t *TriageRecord) error {
 		UPDATE triage_record SET chief_complaint=$2, acuity_level=$3, acuity_system=$4,
 			pain_scale=$5, heart_rate=$6, blood_pressure_sys=$7, blood_pressure_dia=$8,
 			temperature=$9, respiratory_rate=$10, oxygen_saturation=$11,
-			glasgow_coma_score=$12, injury_description=$13, note=$14, updated_at=NOW()
+			glasgow_coma_score=$12, injury_description=$13, note=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		t.ID, t.ChiefComplaint, t.AcuityLevel, t.AcuitySystem,
 		t.PainScale, t.HeartRate, t.BloodPressureSys, t.BloodPressureDia,
@@ -140,13 +140,16 @@ var triageSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *triageRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*TriageRecord, int, error) {
 	qb := fhir.NewSearchQuery("triage_record", triageCols)
-	qb.ApplyParams(params, triageSearchParams)
+	qb.ApplyParams(ctx, params, triageSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -213,7 +216,7 @@ func (r *edTrackingRepoPG) Update(ctx context.Context, t *EDTracking) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE ed_tracking SET current_status=$2, bed_assignment=$3, attending_id=$4, nurse_id=$5,
 			discharge_time=$6, disposition=$7, disposition_dest=$8, length_of_stay_mins=$9,
-			note=$10, updated_at=NOW()
+			note=$10, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		t.ID, t.CurrentStatus, t.BedAssignment, t.AttendingID, t.NurseID,
 		t.DischargeTime, t.Disposition, t.DispositionDest, t.LengthOfStayMins, t.Note)
@@ -275,13 +278,16 @@ var edTrackSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *edTrackingRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*EDTracking, int, error) {
 	qb := fhir.NewSearchQuery("ed_tracking", edTrackCols)
-	qb.ApplyParams(params, edTrackSearchParams)
+	qb.ApplyParams(ctx, params, edTrackSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -375,7 +381,7 @@ func (r *traumaRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*TraumaActiva
 func (r *traumaRepoPG) Update(ctx context.Context, t *TraumaActivation) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE trauma_activation SET activation_level=$2, deactivation_time=$3,
-			mechanism_of_injury=$4, team_lead_id=$5, outcome=$6, note=$7, updated_at=NOW()
+			mechanism_of_injury=$4, team_lead_id=$5, outcome=$6, note=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		t.ID, t.ActivationLevel, t.DeactivationTime,
 		t.MechanismOfInjury, t.TeamLeadID, t.Outcome, t.Note)
@@ -436,13 +442,16 @@ var traumaSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *traumaRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*TraumaActivation, int, error) {
 	qb := fhir.NewSearchQuery("trauma_activation", traumaCols)
-	qb.ApplyParams(params, traumaSearchParams)
+	qb.ApplyParams(ctx, params, traumaSearchParams)
 	qb.OrderBy("activation_time DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -459,3 +468,11 @@ func (r *traumaRepoPG) Search(ctx context.Context, params map[string]string, lim
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("triage_record", triageSearchParams)
+	r.RegisterTable("ed_tracking", edTrackSearchParams)
+	r.RegisterTable("trauma_activation", traumaSearchParams)
+}
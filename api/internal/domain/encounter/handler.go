@@ -5,7 +5,6 @@ import (
 	"io"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -63,6 +62,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Encounter/:id/_history", h.HistoryEncounterFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Encounter", fhir.ReadOnlyInteractions()...)
+}
+
 // -- Operational Handlers --
 
 func (h *Handler) CreateEncounter(c echo.Context) error {
@@ -260,8 +263,8 @@ func (h *Handler) GetEncounterFHIR(c echo.Context) error {
 
 func (h *Handler) CreateEncounterFHIR(c echo.Context) error {
 	var enc Encounter
-	if err := c.Bind(&enc); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Encounter", "", &enc); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateEncounter(c.Request().Context(), &enc); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -275,12 +278,10 @@ func (h *Handler) UpdateEncounterFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Encounter", c.Param("id")))
 	}
-	var enc Encounter
-	if err := c.Bind(&enc); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	enc := *existing
+	if err := fhir.BindResource(c, "Encounter", existing.FHIRID, &enc); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
-	enc.ID = existing.ID
-	enc.FHIRID = existing.FHIRID
 	enc.PatientID = existing.PatientID
 	if err := h.svc.UpdateEncounter(c.Request().Context(), &enc); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -312,7 +313,7 @@ func (h *Handler) PatchEncounterFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Encounter", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -335,7 +336,9 @@ func (h *Handler) PatchEncounterFHIR(c echo.Context) error {
 	} else {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome("PATCH requires application/json-patch+json or application/merge-patch+json"))
 	}
-	applyEncounterPatch(existing, patched)
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
+	}
 	if err := h.svc.UpdateEncounter(ctx, existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -367,91 +370,3 @@ func (h *Handler) HistoryEncounterFHIR(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, fhir.NewHistoryBundle([]*fhir.HistoryEntry{entry}, 1, "/fhir"))
 }
-
-// -- FHIR PATCH helpers --
-
-func applyEncounterPatch(e *Encounter, patched map[string]interface{}) {
-	if v, ok := patched["status"].(string); ok {
-		e.Status = v
-	}
-	// class
-	if v, ok := patched["class"]; ok {
-		if cls, ok := v.(map[string]interface{}); ok {
-			if code, ok := cls["code"].(string); ok {
-				e.ClassCode = code
-			}
-			if display, ok := cls["display"].(string); ok {
-				e.ClassDisplay = &display
-			}
-		}
-	}
-	// type
-	if v, ok := patched["type"]; ok {
-		if types, ok := v.([]interface{}); ok && len(types) > 0 {
-			if t, ok := types[0].(map[string]interface{}); ok {
-				if coding, ok := t["coding"].([]interface{}); ok && len(coding) > 0 {
-					if c, ok := coding[0].(map[string]interface{}); ok {
-						if code, ok := c["code"].(string); ok {
-							e.TypeCode = &code
-						}
-						if display, ok := c["display"].(string); ok {
-							e.TypeDisplay = &display
-						}
-					}
-				}
-			}
-		}
-	}
-	// serviceType
-	if v, ok := patched["serviceType"]; ok {
-		if st, ok := v.(map[string]interface{}); ok {
-			if coding, ok := st["coding"].([]interface{}); ok && len(coding) > 0 {
-				if c, ok := coding[0].(map[string]interface{}); ok {
-					if code, ok := c["code"].(string); ok {
-						e.ServiceTypeCode = &code
-					}
-					if display, ok := c["display"].(string); ok {
-						e.ServiceTypeDisplay = &display
-					}
-				}
-			}
-		}
-	}
-	// priority
-	if v, ok := patched["priority"]; ok {
-		if pri, ok := v.(map[string]interface{}); ok {
-			if coding, ok := pri["coding"].([]interface{}); ok && len(coding) > 0 {
-				if c, ok := coding[0].(map[string]interface{}); ok {
-					if code, ok := c["code"].(string); ok {
-						e.PriorityCode = &code
-					}
-				}
-			}
-		}
-	}
-	// period
-	if v, ok := patched["period"]; ok {
-		if period, ok := v.(map[string]interface{}); ok {
-			if start, ok := period["start"].(string); ok {
-				if t, err := time.Parse(time.RFC3339, start); err == nil {
-					e.PeriodStart = t
-				}
-			}
-			if end, ok := period["end"].(string); ok {
-				if t, err := time.Parse(time.RFC3339, end); err == nil {
-					e.PeriodEnd = &t
-				}
-			}
-		}
-	}
-	// reasonCode
-	if v, ok := patched["reasonCode"]; ok {
-		if reasons, ok := v.([]interface{}); ok && len(reasons) > 0 {
-			if reason, ok := reasons[0].(map[string]interface{}); ok {
-				if text, ok := reason["text"].(string); ok {
-					e.ReasonText = &text
-				}
-			}
-		}
-	}
-}
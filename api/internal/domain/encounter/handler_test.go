@@ -439,7 +439,7 @@ func TestHandler_GetEncounterFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateEncounterFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","class_code":"AMB"}`
+	body := `{"resourceType":"Encounter","status":"planned","class":{"code":"AMB"},"subject":{"reference":"Patient/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/Encounter", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -465,7 +465,7 @@ func TestHandler_CreateEncounterFHIR(t *testing.T) {
 
 func TestHandler_CreateEncounterFHIR_BadRequest(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"class_code":"AMB"}`
+	body := `{"resourceType":"Encounter","class":{"code":"AMB"}}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/Encounter", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -485,7 +485,7 @@ func TestHandler_UpdateEncounterFHIR(t *testing.T) {
 	enc := &Encounter{PatientID: uuid.New(), ClassCode: "AMB"}
 	h.svc.CreateEncounter(nil, enc)
 
-	body := `{"class_code":"IMP"}`
+	body := `{"resourceType":"Encounter","status":"arrived","class":{"code":"IMP"},"subject":{"reference":"Patient/` + enc.PatientID.String() + `"}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -500,11 +500,40 @@ func TestHandler_UpdateEncounterFHIR(t *testing.T) {
 	if rec.Code != http.StatusOK {
 		t.Errorf("expected 200, got %d", rec.Code)
 	}
+	var result map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	if cls, _ := result["class"].(map[string]interface{}); cls["code"] != "IMP" || result["status"] != "arrived" {
+		t.Errorf("expected updated class and status, got %v %v", result["class"], result["status"])
+	}
+}
+
+func TestHandler_UpdateEncounterFHIR_InvalidElement(t *testing.T) {
+	h, e := newTestHandler()
+	enc := &Encounter{PatientID: uuid.New(), ClassCode: "AMB"}
+	h.svc.CreateEncounter(nil, enc)
+
+	body := `{"resourceType":"Encounter","status":"done","class":"IMP","subject":{"reference":"Patient/` + enc.PatientID.String() + `"}}`
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, "application/fhir+json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(enc.FHIRID)
+
+	if err := h.UpdateEncounterFHIR(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Encounter.status") || !strings.Contains(rec.Body.String(), "Encounter.class") {
+		t.Errorf("expected issues for status and class, got %s", rec.Body.String())
+	}
 }
 
 func TestHandler_UpdateEncounterFHIR_NotFound(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"class_code":"IMP"}`
+	body := `{"resourceType":"Encounter","class":{"code":"IMP"}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -623,6 +652,27 @@ func TestHandler_PatchEncounterFHIR_UnsupportedMediaType(t *testing.T) {
 	}
 }
 
+func TestHandler_PatchEncounterFHIR_InvalidResult(t *testing.T) {
+	h, e := newTestHandler()
+	enc := &Encounter{PatientID: uuid.New(), ClassCode: "AMB"}
+	h.svc.CreateEncounter(nil, enc)
+
+	body := `{"period":{"start":"not-a-date"}}`
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(enc.FHIRID)
+
+	if err := h.PatchEncounterFHIR(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", rec.Code)
+	}
+}
+
 func TestHandler_PatchEncounterFHIR_NotFound(t *testing.T) {
 	h, e := newTestHandler()
 	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{}`))
@@ -726,100 +776,3 @@ func TestHandler_HistoryEncounterFHIR_NotFound(t *testing.T) {
 		t.Errorf("expected 404, got %d", rec.Code)
 	}
 }
-
-// -- applyEncounterPatch unit tests --
-
-func TestApplyEncounterPatch_Status(t *testing.T) {
-	enc := &Encounter{
-		Status:    "planned",
-		ClassCode: "AMB",
-		PatientID: uuid.New(),
-	}
-
-	patched := map[string]interface{}{
-		"status": "in-progress",
-	}
-	applyEncounterPatch(enc, patched)
-
-	if enc.Status != "in-progress" {
-		t.Errorf("expected status 'in-progress', got %q", enc.Status)
-	}
-	if enc.ClassCode != "AMB" {
-		t.Errorf("expected ClassCode unchanged 'AMB', got %q", enc.ClassCode)
-	}
-}
-
-func TestApplyEncounterPatch_Class(t *testing.T) {
-	enc := &Encounter{
-		Status:    "planned",
-		ClassCode: "AMB",
-	}
-
-	patched := map[string]interface{}{
-		"class": map[string]interface{}{
-			"code":    "IMP",
-			"display": "inpatient encounter",
-		},
-	}
-	applyEncounterPatch(enc, patched)
-
-	if enc.ClassCode != "IMP" {
-		t.Errorf("expected ClassCode 'IMP', got %q", enc.ClassCode)
-	}
-	if enc.ClassDisplay == nil || *enc.ClassDisplay != "inpatient encounter" {
-		t.Errorf("expected ClassDisplay 'inpatient encounter', got %v", enc.ClassDisplay)
-	}
-	if enc.Status != "planned" {
-		t.Errorf("expected Status unchanged 'planned', got %q", enc.Status)
-	}
-}
-
-func TestApplyEncounterPatch_Period(t *testing.T) {
-	enc := &Encounter{
-		Status:    "in-progress",
-		ClassCode: "AMB",
-	}
-
-	patched := map[string]interface{}{
-		"period": map[string]interface{}{
-			"start": "2025-06-01T09:00:00Z",
-			"end":   "2025-06-01T10:30:00Z",
-		},
-	}
-	applyEncounterPatch(enc, patched)
-
-	if enc.PeriodStart.IsZero() {
-		t.Fatal("expected PeriodStart to be set")
-	}
-	if enc.PeriodStart.Year() != 2025 || enc.PeriodStart.Month() != 6 || enc.PeriodStart.Day() != 1 {
-		t.Errorf("unexpected PeriodStart: %v", enc.PeriodStart)
-	}
-	if enc.PeriodEnd == nil {
-		t.Fatal("expected PeriodEnd to be set")
-	}
-	if enc.PeriodEnd.Hour() != 10 || enc.PeriodEnd.Minute() != 30 {
-		t.Errorf("unexpected PeriodEnd: %v", *enc.PeriodEnd)
-	}
-}
-
-func TestApplyEncounterPatch_EmptyMap(t *testing.T) {
-	orig := "AMB"
-	enc := &Encounter{
-		Status:    "planned",
-		ClassCode: "AMB",
-		ClassDisplay: &orig,
-	}
-
-	patched := map[string]interface{}{}
-	applyEncounterPatch(enc, patched)
-
-	if enc.Status != "planned" {
-		t.Errorf("expected Status unchanged 'planned', got %q", enc.Status)
-	}
-	if enc.ClassCode != "AMB" {
-		t.Errorf("expected ClassCode unchanged 'AMB', got %q", enc.ClassCode)
-	}
-	if enc.ClassDisplay == nil || *enc.ClassDisplay != "AMB" {
-		t.Errorf("expected ClassDisplay unchanged")
-	}
-}
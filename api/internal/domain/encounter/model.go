@@ -155,6 +155,44 @@ func (e *Encounter) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the encounter from a FHIR Encounter resource, the
+// inverse of ToFHIR. Columns with no FHIR representation (department, bed,
+// telehealth and DRG details) are left unchanged.
+func (e *Encounter) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Encounter", data)
+	e.Status = r.CodeValue("status", "planned", "arrived", "triaged", "in-progress",
+		"onleave", "finished", "cancelled", "entered-in-error")
+
+	class := r.Coding("class")
+	e.ClassCode = r.RequiredString("class.code")
+	e.ClassDisplay = class.Display
+
+	typ := r.CodeableConcept("type")
+	e.TypeCode, e.TypeDisplay = typ.Code, typ.Display
+	serviceType := r.CodeableConcept("serviceType")
+	e.ServiceTypeCode, e.ServiceTypeDisplay = serviceType.Code, serviceType.Display
+	e.PriorityCode = r.CodeableConcept("priority").Code
+
+	e.PatientID = r.RequiredReferenceID("subject", "Patient")
+	start, end := r.Period("period")
+	if start != nil {
+		e.PeriodStart = *start
+	}
+	e.PeriodEnd = end
+
+	e.PrimaryPractitionerID = r.ReferenceID("participant.individual", "Practitioner")
+	e.ServiceProviderID = r.ReferenceID("serviceProvider", "Organization")
+	e.LocationID = r.ReferenceID("location.location", "Location")
+	e.ReasonText = r.String("reasonCode.text")
+
+	admit := r.CodeableConcept("hospitalization.admitSource")
+	e.AdmitSourceCode, e.AdmitSourceDisplay = admit.Code, admit.Display
+	discharge := r.CodeableConcept("hospitalization.dischargeDisposition")
+	e.DischargeDispositionCode, e.DischargeDispositionDisp = discharge.Code, discharge.Display
+	e.ReAdmission = r.Has("hospitalization.reAdmission")
+	return r.Err()
+}
+
 // EncounterParticipant maps to the encounter_participant table.
 type EncounterParticipant struct {
 	ID             uuid.UUID  `db:"id" json:"id"`
@@ -1,6 +1,7 @@
 package encounter
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -232,3 +233,110 @@ func TestEncounter_ToFHIR_OptionalFieldsNil(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Encounter.FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestEncounter_FromFHIR_RoundTrip(t *testing.T) {
+	end := time.Date(2024, 7, 1, 9, 30, 0, 0, time.UTC)
+	orig := &Encounter{
+		FHIRID:                   "enc-rt",
+		Status:                   "finished",
+		ClassCode:                "IMP",
+		ClassDisplay:             ptrStr("inpatient encounter"),
+		TypeCode:                 ptrStr("99213"),
+		TypeDisplay:              ptrStr("Office visit"),
+		ServiceTypeCode:          ptrStr("57"),
+		PriorityCode:             ptrStr("EM"),
+		PatientID:                uuid.New(),
+		PrimaryPractitionerID:    ptrUUID(uuid.New()),
+		ServiceProviderID:        ptrUUID(uuid.New()),
+		LocationID:               ptrUUID(uuid.New()),
+		PeriodStart:              time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC),
+		PeriodEnd:                &end,
+		ReasonText:               ptrStr("Chest pain"),
+		AdmitSourceCode:          ptrStr("emd"),
+		DischargeDispositionCode: ptrStr("home"),
+		ReAdmission:              true,
+	}
+
+	var got Encounter
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Status != orig.Status || got.ClassCode != orig.ClassCode || *got.ClassDisplay != *orig.ClassDisplay {
+		t.Errorf("status/class mismatch: %+v", got)
+	}
+	if *got.TypeCode != "99213" || *got.TypeDisplay != "Office visit" || *got.ServiceTypeCode != "57" || *got.PriorityCode != "EM" {
+		t.Errorf("coded fields mismatch: %+v", got)
+	}
+	if got.PatientID != orig.PatientID || *got.PrimaryPractitionerID != *orig.PrimaryPractitionerID ||
+		*got.ServiceProviderID != *orig.ServiceProviderID || *got.LocationID != *orig.LocationID {
+		t.Errorf("references mismatch: %+v", got)
+	}
+	if !got.PeriodStart.Equal(orig.PeriodStart) || !got.PeriodEnd.Equal(end) {
+		t.Errorf("period mismatch: %v - %v", got.PeriodStart, got.PeriodEnd)
+	}
+	if *got.ReasonText != "Chest pain" || *got.AdmitSourceCode != "emd" || *got.DischargeDispositionCode != "home" || !got.ReAdmission {
+		t.Errorf("hospitalization mismatch: %+v", got)
+	}
+}
+
+func TestEncounter_FromFHIR_ClearsAbsentElements(t *testing.T) {
+	enc := &Encounter{ClassCode: "AMB", TypeCode: ptrStr("x"), ReasonText: ptrStr("old"), DRGCode: ptrStr("470")}
+	err := enc.FromFHIR(map[string]interface{}{
+		"resourceType": "Encounter",
+		"status":       "planned",
+		"class":        map[string]interface{}{"code": "AMB"},
+		"subject":      map[string]interface{}{"reference": "Patient/" + uuid.New().String()},
+	})
+	if err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if enc.TypeCode != nil || enc.ReasonText != nil {
+		t.Errorf("expected absent elements to be cleared, got %+v", enc)
+	}
+	if enc.DRGCode == nil {
+		t.Error("expected DRG code (not represented in FHIR) to be preserved")
+	}
+}
+
+func TestEncounter_FromFHIR_InvalidElements(t *testing.T) {
+	var enc Encounter
+	err := enc.FromFHIR(map[string]interface{}{
+		"resourceType": "Encounter",
+		"status":       "bogus",
+		"subject":      map[string]interface{}{"reference": "Group/1"},
+		"period":       map[string]interface{}{"start": "2024-07-01T10:00:00Z", "end": "2024-07-01T09:00:00Z"},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	want := map[string]bool{
+		"Encounter.status":            true,
+		"Encounter.class.code":        true,
+		"Encounter.subject.reference": true,
+		"Encounter.period":            true,
+	}
+	for _, issue := range pe.Outcome.Issue {
+		delete(want, issue.Expression[0])
+	}
+	if len(want) != 0 {
+		t.Errorf("missing issues for %v in %+v", want, pe.Outcome.Issue)
+	}
+}
@@ -99,7 +99,7 @@ func (r *repoPG) Update(ctx context.Context, enc *Encounter) error {
 			admit_source_code=$18, admit_source_display=$19,
 			discharge_disposition_code=$20, discharge_disposition_display=$21,
 			re_admission=$22, is_telehealth=$23, telehealth_platform=$24, reason_text=$25,
-			drg_code=$26, drg_type=$27, updated_at=NOW()
+			drg_code=$26, drg_type=$27, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		enc.ID, enc.Status, enc.ClassCode, enc.ClassDisplay, enc.TypeCode, enc.TypeDisplay,
 		enc.ServiceTypeCode, enc.ServiceTypeDisplay, enc.PriorityCode,
@@ -158,13 +158,16 @@ var encounterSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *repoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Encounter, int, error) {
 	qb := fhir.NewSearchQuery("encounter", encCols)
-	qb.ApplyParams(params, encounterSearchParams)
+	qb.ApplyParams(ctx, params, encounterSearchParams)
 	qb.OrderBy("period_start DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -318,3 +321,9 @@ func collectEncs(rows pgx.Rows, total int) ([]*Encounter, int, error) {
 	}
 	return encs, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("encounter", encounterSearchParams)
+}
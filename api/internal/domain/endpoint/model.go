@@ -70,6 +70,24 @@ func (e *Endpoint) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the endpoint from a FHIR Endpoint resource, the inverse
+// of ToFHIR.
+func (e *Endpoint) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Endpoint", data)
+	e.Status = r.CodeValue("status", "active", "suspended", "error", "off", "entered-in-error", "test")
+	e.Address = r.RequiredString("address")
+	connectionType := r.Coding("connectionType")
+	e.ConnectionTypeCode, e.ConnectionTypeDisplay = connectionType.Code, connectionType.Display
+	e.Name = r.String("name")
+	e.ManagingOrgID = r.ReferenceID("managingOrganization", "Organization")
+	e.PeriodStart, e.PeriodEnd = r.Period("period")
+	payloadType := r.CodeableConcept("payloadType")
+	e.PayloadTypeCode, e.PayloadTypeDisplay = payloadType.Code, payloadType.Display
+	e.PayloadMimeType = r.String("payloadMimeType")
+	e.Header = r.String("header")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -81,7 +81,7 @@ func (r *endpointRepoPG) Update(ctx context.Context, e *Endpoint) error {
 		UPDATE endpoint SET status=$2, connection_type_code=$3, connection_type_display=$4,
 			name=$5, managing_org_id=$6, contact_phone=$7, contact_email=$8,
 			period_start=$9, period_end=$10, payload_type_code=$11, payload_type_display=$12,
-			payload_mime_type=$13, address=$14, header=$15, updated_at=NOW()
+			payload_mime_type=$13, address=$14, header=$15, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.ConnectionTypeCode, e.ConnectionTypeDisplay,
 		e.Name, e.ManagingOrgID, e.ContactPhone, e.ContactEmail,
@@ -124,13 +124,16 @@ var endpointSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *endpointRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Endpoint, int, error) {
 	qb := fhir.NewSearchQuery("endpoint", epCols)
-	qb.ApplyParams(params, endpointSearchParams)
+	qb.ApplyParams(ctx, params, endpointSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -147,3 +150,9 @@ func (r *endpointRepoPG) Search(ctx context.Context, params map[string]string, l
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("endpoint", endpointSearchParams)
+}
@@ -50,6 +50,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/EpisodeOfCare/:id/_history", h.HistoryEpisodeOfCareFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("EpisodeOfCare", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateEpisodeOfCare(c echo.Context) error {
@@ -149,8 +153,8 @@ func (h *Handler) GetEpisodeOfCareFHIR(c echo.Context) error {
 
 func (h *Handler) CreateEpisodeOfCareFHIR(c echo.Context) error {
 	var e EpisodeOfCare
-	if err := c.Bind(&e); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "EpisodeOfCare", "", &e); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateEpisodeOfCare(c.Request().Context(), &e); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -160,16 +164,14 @@ func (h *Handler) CreateEpisodeOfCareFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateEpisodeOfCareFHIR(c echo.Context) error {
-	var e EpisodeOfCare
-	if err := c.Bind(&e); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetEpisodeOfCareByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("EpisodeOfCare", c.Param("id")))
 	}
-	e.ID = existing.ID
-	e.FHIRID = existing.FHIRID
+	e := *existing
+	if err := fhir.BindResource(c, "EpisodeOfCare", existing.FHIRID, &e); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateEpisodeOfCare(c.Request().Context(), &e); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -226,7 +228,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("EpisodeOfCare", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -252,8 +254,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateEpisodeOfCare(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -73,6 +73,24 @@ func (e *EpisodeOfCare) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the episode from a FHIR EpisodeOfCare resource, the
+// inverse of ToFHIR.
+func (e *EpisodeOfCare) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("EpisodeOfCare", data)
+	e.Status = r.CodeValue("status", "planned", "waitlist", "active", "onhold",
+		"finished", "cancelled", "entered-in-error")
+	e.PatientID = r.RequiredReferenceID("patient", "Patient")
+	episodeType := r.CodeableConcept("type")
+	e.TypeCode, e.TypeDisplay = episodeType.Code, episodeType.Display
+	e.DiagnosisConditionID = r.ReferenceID("diagnosis.condition", "Condition")
+	e.DiagnosisRole = r.ConceptCode("diagnosis.role")
+	e.PeriodStart, e.PeriodEnd = r.Period("period")
+	e.ManagingOrgID = r.ReferenceID("managingOrganization", "Organization")
+	e.CareManagerID = r.ReferenceID("careManager", "Practitioner")
+	e.ReferralRequestID = r.ReferenceID("referralRequest", "ServiceRequest")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -76,7 +76,7 @@ func (r *episodeOfCareRepoPG) Update(ctx context.Context, e *EpisodeOfCare) erro
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE episode_of_care SET status=$2, type_code=$3, type_display=$4,
 			diagnosis_condition_id=$5, diagnosis_role=$6, managing_org_id=$7,
-			period_start=$8, period_end=$9, care_manager_id=$10, updated_at=NOW()
+			period_start=$8, period_end=$9, care_manager_id=$10, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.TypeCode, e.TypeDisplay,
 		e.DiagnosisConditionID, e.DiagnosisRole, e.ManagingOrgID,
@@ -138,13 +138,16 @@ var episodeOfCareSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *episodeOfCareRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*EpisodeOfCare, int, error) {
 	qb := fhir.NewSearchQuery("episode_of_care", eocCols)
-	qb.ApplyParams(params, episodeOfCareSearchParams)
+	qb.ApplyParams(ctx, params, episodeOfCareSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -161,3 +164,9 @@ func (r *episodeOfCareRepoPG) Search(ctx context.Context, params map[string]stri
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("episode_of_care", episodeOfCareSearchParams)
+}
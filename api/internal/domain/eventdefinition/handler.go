@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/EventDefinition/:id/_history", h.HistoryEventDefinitionFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("EventDefinition", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateEventDefinition(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetEventDefinitionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateEventDefinitionFHIR(c echo.Context) error {
 	var e EventDefinition
-	if err := c.Bind(&e); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "EventDefinition", "", &e); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateEventDefinition(c.Request().Context(), &e); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateEventDefinitionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateEventDefinitionFHIR(c echo.Context) error {
-	var e EventDefinition
-	if err := c.Bind(&e); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetEventDefinitionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("EventDefinition", c.Param("id")))
 	}
-	e.ID = existing.ID
-	e.FHIRID = existing.FHIRID
+	e := *existing
+	if err := fhir.BindResource(c, "EventDefinition", existing.FHIRID, &e); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateEventDefinition(c.Request().Context(), &e); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("EventDefinition", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateEventDefinition(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -76,6 +76,25 @@ func (e *EventDefinition) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the event definition from a FHIR EventDefinition
+// resource, the inverse of ToFHIR.
+func (e *EventDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("EventDefinition", data)
+	e.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	e.URL = r.String("url")
+	e.Name = r.String("name")
+	e.Title = r.String("title")
+	e.Description = r.String("description")
+	e.Publisher = r.String("publisher")
+	e.Date = r.DateTime("date")
+	e.Purpose = r.String("purpose")
+	e.TriggerType = r.RequiredCode("trigger.type", "named-event", "periodic", "data-changed", "data-added",
+		"data-modified", "data-removed", "data-accessed", "data-access-ended")
+	e.TriggerName = r.String("trigger.name")
+	e.TriggerCondition = r.String("trigger.condition")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -71,7 +71,7 @@ func (r *eventDefinitionRepoPG) GetByFHIRID(ctx context.Context, fhirID string)
 func (r *eventDefinitionRepoPG) Update(ctx context.Context, e *EventDefinition) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE event_definition SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8, purpose=$9,
-			trigger_type=$10, trigger_name=$11, trigger_condition=$12, updated_at=NOW()
+			trigger_type=$10, trigger_name=$11, trigger_condition=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.URL, e.Name, e.Title, e.Description, e.Publisher, e.Date, e.Purpose,
 		e.TriggerType, e.TriggerName, e.TriggerCondition)
@@ -112,13 +112,16 @@ var eventDefinitionSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *eventDefinitionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*EventDefinition, int, error) {
 	qb := fhir.NewSearchQuery("event_definition", edCols)
-	qb.ApplyParams(params, eventDefinitionSearchParams)
+	qb.ApplyParams(ctx, params, eventDefinitionSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -135,3 +138,9 @@ func (r *eventDefinitionRepoPG) Search(ctx context.Context, params map[string]st
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("event_definition", eventDefinitionSearchParams)
+}
@@ -66,3 +66,19 @@ func (e *Evidence) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the evidence from a FHIR Evidence resource, the inverse
+// of ToFHIR.
+func (e *Evidence) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Evidence", data)
+	e.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	e.URL = r.String("url")
+	e.Name = r.String("name")
+	e.Title = r.String("title")
+	e.Description = r.String("description")
+	e.Publisher = r.String("publisher")
+	e.Date = r.DateTime("date")
+	e.OutcomeReference = r.Reference("outcome")
+	e.ExposureBackgroundReference = r.Reference("exposureBackground")
+	return r.Err()
+}
@@ -71,7 +71,7 @@ func (r *evidenceRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Evide
 func (r *evidenceRepoPG) Update(ctx context.Context, e *Evidence) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE evidence SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
-			outcome_reference=$9, exposure_background_reference=$10, updated_at=NOW()
+			outcome_reference=$9, exposure_background_reference=$10, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.URL, e.Name, e.Title, e.Description, e.Publisher, e.Date,
 		e.OutcomeReference, e.ExposureBackgroundReference)
@@ -112,13 +112,16 @@ var evidenceSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *evidenceRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Evidence, int, error) {
 	qb := fhir.NewSearchQuery("evidence", evCols)
-	qb.ApplyParams(params, evidenceSearchParams)
+	qb.ApplyParams(ctx, params, evidenceSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -135,3 +138,9 @@ func (r *evidenceRepoPG) Search(ctx context.Context, params map[string]string, l
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("evidence", evidenceSearchParams)
+}
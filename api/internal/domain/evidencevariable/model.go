@@ -72,3 +72,20 @@ func (e *EvidenceVariable) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the evidence variable from a FHIR EvidenceVariable
+// resource, the inverse of ToFHIR.
+func (e *EvidenceVariable) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("EvidenceVariable", data)
+	e.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	e.Type = r.CodeValue("type", "dichotomous", "continuous", "descriptive")
+	e.URL = r.String("url")
+	e.Name = r.String("name")
+	e.Title = r.String("title")
+	e.Description = r.String("description")
+	e.Publisher = r.String("publisher")
+	e.Date = r.DateTime("date")
+	e.CharacteristicDescription = r.String("characteristic.description")
+	e.CharacteristicDefinitionRef = r.Reference("characteristic.definitionReference")
+	return r.Err()
+}
@@ -71,7 +71,7 @@ func (r *evidenceVariableRepoPG) GetByFHIRID(ctx context.Context, fhirID string)
 func (r *evidenceVariableRepoPG) Update(ctx context.Context, e *EvidenceVariable) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE evidence_variable SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
-			type=$9, characteristic_description=$10, characteristic_definition_reference=$11, updated_at=NOW()
+			type=$9, characteristic_description=$10, characteristic_definition_reference=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.URL, e.Name, e.Title, e.Description, e.Publisher, e.Date,
 		e.Type, e.CharacteristicDescription, e.CharacteristicDefinitionRef)
@@ -112,13 +112,16 @@ var evidenceVariableSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *evidenceVariableRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*EvidenceVariable, int, error) {
 	qb := fhir.NewSearchQuery("evidence_variable", evCols)
-	qb.ApplyParams(params, evidenceVariableSearchParams)
+	qb.ApplyParams(ctx, params, evidenceVariableSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -135,3 +138,9 @@ func (r *evidenceVariableRepoPG) Search(ctx context.Context, params map[string]s
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("evidence_variable", evidenceVariableSearchParams)
+}
@@ -66,3 +66,19 @@ func (e *ExampleScenario) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the scenario from a FHIR ExampleScenario resource, the
+// inverse of ToFHIR.
+func (e *ExampleScenario) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ExampleScenario", data)
+	e.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	e.URL = r.String("url")
+	e.Name = r.String("name")
+	e.Title = r.String("title")
+	e.Description = r.String("description")
+	e.Publisher = r.String("publisher")
+	e.Date = r.DateTime("date")
+	e.Purpose = r.String("purpose")
+	e.Copyright = r.String("copyright")
+	return r.Err()
+}
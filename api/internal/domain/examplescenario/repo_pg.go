@@ -71,7 +71,7 @@ func (r *exampleScenarioRepoPG) GetByFHIRID(ctx context.Context, fhirID string)
 func (r *exampleScenarioRepoPG) Update(ctx context.Context, e *ExampleScenario) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE example_scenario SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
-			purpose=$9, copyright=$10, updated_at=NOW()
+			purpose=$9, copyright=$10, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.URL, e.Name, e.Title, e.Description, e.Publisher, e.Date,
 		e.Purpose, e.Copyright)
@@ -112,13 +112,16 @@ var exampleScenarioSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *exampleScenarioRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ExampleScenario, int, error) {
 	qb := fhir.NewSearchQuery("example_scenario", esCols)
-	qb.ApplyParams(params, exampleScenarioSearchParams)
+	qb.ApplyParams(ctx, params, exampleScenarioSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -135,3 +138,9 @@ func (r *exampleScenarioRepoPG) Search(ctx context.Context, params map[string]st
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("example_scenario", exampleScenarioSearchParams)
+}
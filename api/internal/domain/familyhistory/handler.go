@@ -48,6 +48,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirWrite.DELETE("/FamilyMemberHistory/:id", h.DeleteFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("FamilyMemberHistory", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Handlers --
 
 func (h *Handler) CreateFamilyMemberHistory(c echo.Context) error {
@@ -146,8 +150,8 @@ func (h *Handler) GetFHIR(c echo.Context) error {
 
 func (h *Handler) CreateFHIR(c echo.Context) error {
 	var f FamilyMemberHistory
-	if err := c.Bind(&f); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "FamilyMemberHistory", "", &f); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateFamilyMemberHistory(c.Request().Context(), &f); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -157,16 +161,14 @@ func (h *Handler) CreateFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateFHIR(c echo.Context) error {
-	var f FamilyMemberHistory
-	if err := c.Bind(&f); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetFamilyMemberHistoryByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("FamilyMemberHistory", c.Param("id")))
 	}
-	f.ID = existing.ID
-	f.FHIRID = existing.FHIRID
+	f := *existing
+	if err := fhir.BindResource(c, "FamilyMemberHistory", existing.FHIRID, &f); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateFamilyMemberHistory(c.Request().Context(), &f); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
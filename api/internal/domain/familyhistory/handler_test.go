@@ -210,7 +210,7 @@ func TestHandler_GetFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","relationship_code":"FTH","relationship_display":"Father"}`
+	body := `{"resourceType":"FamilyMemberHistory","patient":{"reference":"Patient/` + uuid.New().String() + `"},"relationship":{"coding":[{"code":"FTH","display":"Father"}]}}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/FamilyMemberHistory", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -233,7 +233,7 @@ func TestHandler_UpdateFHIR(t *testing.T) {
 	f := &FamilyMemberHistory{PatientID: uuid.New(), RelationshipCode: "FTH", RelationshipDisplay: "Father"}
 	h.svc.CreateFamilyMemberHistory(nil, f)
 
-	body := `{"status":"partial","relationship_code":"FTH","relationship_display":"Father"}`
+	body := `{"resourceType":"FamilyMemberHistory","status":"partial","patient":{"reference":"Patient/` + f.PatientID.String() + `"},"relationship":{"coding":[{"code":"FTH","display":"Father"}]}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -75,6 +75,23 @@ func (f *FamilyMemberHistory) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the history from a FHIR FamilyMemberHistory resource, the
+// inverse of ToFHIR.
+func (f *FamilyMemberHistory) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("FamilyMemberHistory", data)
+	f.Status = r.CodeValue("status", "partial", "completed", "entered-in-error", "health-unknown")
+	f.PatientID = r.RequiredReferenceID("patient", "Patient")
+	f.RelationshipCode = r.RequiredString("relationship.coding.code")
+	f.RelationshipDisplay = r.RequiredString("relationship.coding.display")
+	f.Name = r.String("name")
+	f.Date = r.DateTime("date")
+	f.Sex = r.ConceptCode("sex")
+	f.DeceasedBoolean = r.Bool("deceasedBoolean")
+	f.DeceasedAge = r.Int("deceasedAge.value")
+	f.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // FamilyMemberCondition maps to the family_member_condition table.
 type FamilyMemberCondition struct {
 	ID                uuid.UUID `db:"id" json:"id"`
@@ -1,6 +1,7 @@
 package familyhistory
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -157,3 +158,63 @@ func TestFamilyMemberHistory_ToFHIR_OptionalFieldsNil(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestFamilyMemberHistory_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &FamilyMemberHistory{
+		FHIRID:              "fmh-rt",
+		Status:              "partial",
+		PatientID:           uuid.New(),
+		RelationshipCode:    "MTH",
+		RelationshipDisplay: "Mother",
+		Name:                ptrStr("Jane"),
+		Sex:                 ptrStr("female"),
+		DeceasedAge:         ptrInt(72),
+		Note:                ptrStr("Breast cancer at 60"),
+	}
+
+	var got FamilyMemberHistory
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Status != "partial" || got.PatientID != orig.PatientID {
+		t.Errorf("status/patient mismatch: %+v", got)
+	}
+	if got.RelationshipCode != "MTH" || got.RelationshipDisplay != "Mother" {
+		t.Errorf("relationship mismatch: %+v", got)
+	}
+	if *got.Name != "Jane" || *got.Sex != "female" || *got.DeceasedAge != 72 || *got.Note != "Breast cancer at 60" {
+		t.Errorf("optional fields mismatch: %+v", got)
+	}
+}
+
+func TestFamilyMemberHistory_FromFHIR_MissingRelationship(t *testing.T) {
+	var f FamilyMemberHistory
+	err := f.FromFHIR(map[string]interface{}{
+		"resourceType": "FamilyMemberHistory",
+		"patient":      map[string]interface{}{"reference": "Patient/" + uuid.New().String()},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	if len(pe.Outcome.Issue) != 2 || pe.Outcome.Issue[0].Expression[0] != "FamilyMemberHistory.relationship.coding.code" {
+		t.Errorf("unexpected issues: %+v", pe.Outcome.Issue)
+	}
+}
@@ -136,13 +136,16 @@ var familyMemberHistorySearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *familyMemberHistoryRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*FamilyMemberHistory, int, error) {
 	qb := fhir.NewSearchQuery("family_member_history", fmhCols)
-	qb.ApplyParams(params, familyMemberHistorySearchParams)
+	qb.ApplyParams(ctx, params, familyMemberHistorySearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -191,3 +194,9 @@ func (r *familyMemberHistoryRepoPG) GetConditions(ctx context.Context, familyMem
 	}
 	return items, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("family_member_history", familyMemberHistorySearchParams)
+}
@@ -52,6 +52,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/List/:id/_history", h.HistoryFHIRListFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("List", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateFHIRList(c echo.Context) error {
@@ -164,14 +168,16 @@ func (h *Handler) GetFHIRListFHIR(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("List", c.Param("id")))
 	}
 	result := l.ToFHIR()
-	// Include entries in the FHIR response
-	entries, _ := h.svc.GetEntries(c.Request().Context(), l.ID)
-	if len(entries) > 0 {
-		fhirEntries := make([]map[string]interface{}, len(entries))
-		for i, e := range entries {
-			fhirEntries[i] = e.ToFHIR()
+	// Include entries in the FHIR response, unless _summary or _elements drops them
+	if fhir.ProjectionFromContext(c.Request().Context()).Includes("List", "entry") {
+		entries, _ := h.svc.GetEntries(c.Request().Context(), l.ID)
+		if len(entries) > 0 {
+			fhirEntries := make([]map[string]interface{}, len(entries))
+			for i, e := range entries {
+				fhirEntries[i] = e.ToFHIR()
+			}
+			result["entry"] = fhirEntries
 		}
-		result["entry"] = fhirEntries
 	}
 	fhir.SetVersionHeaders(c, 1, l.UpdatedAt.Format("2006-01-02T15:04:05Z"))
 	return c.JSON(http.StatusOK, result)
@@ -179,8 +185,8 @@ func (h *Handler) GetFHIRListFHIR(c echo.Context) error {
 
 func (h *Handler) CreateFHIRListFHIR(c echo.Context) error {
 	var l FHIRList
-	if err := c.Bind(&l); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "List", "", &l); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateFHIRList(c.Request().Context(), &l); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -190,16 +196,14 @@ func (h *Handler) CreateFHIRListFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateFHIRListFHIR(c echo.Context) error {
-	var l FHIRList
-	if err := c.Bind(&l); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetFHIRListByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("List", c.Param("id")))
 	}
-	l.ID = existing.ID
-	l.FHIRID = existing.FHIRID
+	l := *existing
+	if err := fhir.BindResource(c, "List", existing.FHIRID, &l); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateFHIRList(c.Request().Context(), &l); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -256,7 +260,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("List", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -282,14 +286,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
-	}
-	if v, ok := patched["mode"].(string); ok {
-		existing.Mode = v
-	}
-	if v, ok := patched["title"].(string); ok {
-		existing.Title = &v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateFHIRList(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -74,6 +74,24 @@ func (l *FHIRList) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the list from a FHIR List resource, the inverse of
+// ToFHIR. Entries are managed separately and are not read here.
+func (l *FHIRList) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("List", data)
+	l.Status = r.CodeValue("status", "current", "retired", "entered-in-error")
+	l.Mode = r.CodeValue("mode", "working", "snapshot", "changes")
+	l.Title = r.String("title")
+	code := r.CodeableConcept("code")
+	l.CodeCode, l.CodeDisplay = code.Code, code.Display
+	l.SubjectPatientID = r.ReferenceID("subject", "Patient")
+	l.EncounterID = r.ReferenceID("encounter", "Encounter")
+	l.Date = r.DateTime("date")
+	l.SourcePractitionerID = r.ReferenceID("source", "Practitioner")
+	l.OrderedBy = r.ConceptCode("orderedBy")
+	l.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // FHIRListEntry maps to the fhir_list_entry table.
 type FHIRListEntry struct {
 	ID            uuid.UUID  `db:"id" json:"id"`
@@ -75,7 +75,7 @@ func (r *fhirListRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*FHIRL
 func (r *fhirListRepoPG) Update(ctx context.Context, l *FHIRList) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE fhir_list SET status=$2, mode=$3, title=$4,
-			code_code=$5, code_display=$6, ordered_by=$7, note=$8, updated_at=NOW()
+			code_code=$5, code_display=$6, ordered_by=$7, note=$8, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		l.ID, l.Status, l.Mode, l.Title,
 		l.CodeCode, l.CodeDisplay, l.OrderedBy, l.Note)
@@ -115,13 +115,16 @@ var fhirListSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *fhirListRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*FHIRList, int, error) {
 	qb := fhir.NewSearchQuery("fhir_list", listCols)
-	qb.ApplyParams(params, fhirListSearchParams)
+	qb.ApplyParams(ctx, params, fhirListSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -170,3 +173,9 @@ func (r *fhirListRepoPG) GetEntries(ctx context.Context, listID uuid.UUID) ([]*F
 	}
 	return items, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("fhir_list", fhirListSearchParams)
+}
@@ -166,6 +166,18 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/EnrollmentResponse/:id/_history", h.HistoryEnrollmentResponseFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Account", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("InsurancePlan", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("PaymentNotice", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("PaymentReconciliation", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("ChargeItem", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("ChargeItemDefinition", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("Contract", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("EnrollmentRequest", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("EnrollmentResponse", fhir.ReadOnlyInteractions()...)
+}
+
 // ===== REST Account Handlers =====
 
 func (h *Handler) CreateAccount(c echo.Context) error {
@@ -742,8 +754,8 @@ func (h *Handler) GetAccountFHIR(c echo.Context) error {
 
 func (h *Handler) CreateAccountFHIR(c echo.Context) error {
 	var a Account
-	if err := c.Bind(&a); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Account", "", &a); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateAccount(c.Request().Context(), &a); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -753,16 +765,14 @@ func (h *Handler) CreateAccountFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateAccountFHIR(c echo.Context) error {
-	var a Account
-	if err := c.Bind(&a); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetAccountByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Account", c.Param("id")))
 	}
-	a.ID = existing.ID
-	a.FHIRID = existing.FHIRID
+	a := *existing
+	if err := fhir.BindResource(c, "Account", existing.FHIRID, &a); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateAccount(c.Request().Context(), &a); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -786,8 +796,8 @@ func (h *Handler) PatchAccountFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Account", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateAccount(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -847,8 +857,8 @@ func (h *Handler) GetInsurancePlanFHIR(c echo.Context) error {
 
 func (h *Handler) CreateInsurancePlanFHIR(c echo.Context) error {
 	var ip InsurancePlan
-	if err := c.Bind(&ip); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "InsurancePlan", "", &ip); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateInsurancePlan(c.Request().Context(), &ip); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -858,16 +868,14 @@ func (h *Handler) CreateInsurancePlanFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateInsurancePlanFHIR(c echo.Context) error {
-	var ip InsurancePlan
-	if err := c.Bind(&ip); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetInsurancePlanByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("InsurancePlan", c.Param("id")))
 	}
-	ip.ID = existing.ID
-	ip.FHIRID = existing.FHIRID
+	ip := *existing
+	if err := fhir.BindResource(c, "InsurancePlan", existing.FHIRID, &ip); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateInsurancePlan(c.Request().Context(), &ip); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -891,8 +899,8 @@ func (h *Handler) PatchInsurancePlanFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("InsurancePlan", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateInsurancePlan(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -952,8 +960,8 @@ func (h *Handler) GetPaymentNoticeFHIR(c echo.Context) error {
 
 func (h *Handler) CreatePaymentNoticeFHIR(c echo.Context) error {
 	var pn PaymentNotice
-	if err := c.Bind(&pn); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "PaymentNotice", "", &pn); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreatePaymentNotice(c.Request().Context(), &pn); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -963,16 +971,14 @@ func (h *Handler) CreatePaymentNoticeFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdatePaymentNoticeFHIR(c echo.Context) error {
-	var pn PaymentNotice
-	if err := c.Bind(&pn); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetPaymentNoticeByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("PaymentNotice", c.Param("id")))
 	}
-	pn.ID = existing.ID
-	pn.FHIRID = existing.FHIRID
+	pn := *existing
+	if err := fhir.BindResource(c, "PaymentNotice", existing.FHIRID, &pn); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdatePaymentNotice(c.Request().Context(), &pn); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -996,8 +1002,8 @@ func (h *Handler) PatchPaymentNoticeFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("PaymentNotice", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdatePaymentNotice(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1057,8 +1063,8 @@ func (h *Handler) GetPaymentReconciliationFHIR(c echo.Context) error {
 
 func (h *Handler) CreatePaymentReconciliationFHIR(c echo.Context) error {
 	var pr PaymentReconciliation
-	if err := c.Bind(&pr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "PaymentReconciliation", "", &pr); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreatePaymentReconciliation(c.Request().Context(), &pr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1068,16 +1074,14 @@ func (h *Handler) CreatePaymentReconciliationFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdatePaymentReconciliationFHIR(c echo.Context) error {
-	var pr PaymentReconciliation
-	if err := c.Bind(&pr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetPaymentReconciliationByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("PaymentReconciliation", c.Param("id")))
 	}
-	pr.ID = existing.ID
-	pr.FHIRID = existing.FHIRID
+	pr := *existing
+	if err := fhir.BindResource(c, "PaymentReconciliation", existing.FHIRID, &pr); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdatePaymentReconciliation(c.Request().Context(), &pr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -1101,8 +1105,8 @@ func (h *Handler) PatchPaymentReconciliationFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("PaymentReconciliation", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdatePaymentReconciliation(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1162,8 +1166,8 @@ func (h *Handler) GetChargeItemFHIR(c echo.Context) error {
 
 func (h *Handler) CreateChargeItemFHIR(c echo.Context) error {
 	var ci ChargeItem
-	if err := c.Bind(&ci); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ChargeItem", "", &ci); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateChargeItem(c.Request().Context(), &ci); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1173,16 +1177,14 @@ func (h *Handler) CreateChargeItemFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateChargeItemFHIR(c echo.Context) error {
-	var ci ChargeItem
-	if err := c.Bind(&ci); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetChargeItemByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ChargeItem", c.Param("id")))
 	}
-	ci.ID = existing.ID
-	ci.FHIRID = existing.FHIRID
+	ci := *existing
+	if err := fhir.BindResource(c, "ChargeItem", existing.FHIRID, &ci); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateChargeItem(c.Request().Context(), &ci); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -1206,8 +1208,8 @@ func (h *Handler) PatchChargeItemFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ChargeItem", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateChargeItem(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1267,8 +1269,8 @@ func (h *Handler) GetChargeItemDefinitionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateChargeItemDefinitionFHIR(c echo.Context) error {
 	var cd ChargeItemDefinition
-	if err := c.Bind(&cd); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ChargeItemDefinition", "", &cd); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateChargeItemDefinition(c.Request().Context(), &cd); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1278,16 +1280,14 @@ func (h *Handler) CreateChargeItemDefinitionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateChargeItemDefinitionFHIR(c echo.Context) error {
-	var cd ChargeItemDefinition
-	if err := c.Bind(&cd); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetChargeItemDefinitionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ChargeItemDefinition", c.Param("id")))
 	}
-	cd.ID = existing.ID
-	cd.FHIRID = existing.FHIRID
+	cd := *existing
+	if err := fhir.BindResource(c, "ChargeItemDefinition", existing.FHIRID, &cd); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateChargeItemDefinition(c.Request().Context(), &cd); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -1311,8 +1311,8 @@ func (h *Handler) PatchChargeItemDefinitionFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ChargeItemDefinition", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateChargeItemDefinition(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1372,8 +1372,8 @@ func (h *Handler) GetContractFHIR(c echo.Context) error {
 
 func (h *Handler) CreateContractFHIR(c echo.Context) error {
 	var ct Contract
-	if err := c.Bind(&ct); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Contract", "", &ct); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateContract(c.Request().Context(), &ct); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1383,16 +1383,14 @@ func (h *Handler) CreateContractFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateContractFHIR(c echo.Context) error {
-	var ct Contract
-	if err := c.Bind(&ct); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetContractByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Contract", c.Param("id")))
 	}
-	ct.ID = existing.ID
-	ct.FHIRID = existing.FHIRID
+	ct := *existing
+	if err := fhir.BindResource(c, "Contract", existing.FHIRID, &ct); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateContract(c.Request().Context(), &ct); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -1416,8 +1414,8 @@ func (h *Handler) PatchContractFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Contract", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateContract(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1477,8 +1475,8 @@ func (h *Handler) GetEnrollmentRequestFHIR(c echo.Context) error {
 
 func (h *Handler) CreateEnrollmentRequestFHIR(c echo.Context) error {
 	var er EnrollmentRequest
-	if err := c.Bind(&er); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "EnrollmentRequest", "", &er); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateEnrollmentRequest(c.Request().Context(), &er); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1488,16 +1486,14 @@ func (h *Handler) CreateEnrollmentRequestFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateEnrollmentRequestFHIR(c echo.Context) error {
-	var er EnrollmentRequest
-	if err := c.Bind(&er); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetEnrollmentRequestByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("EnrollmentRequest", c.Param("id")))
 	}
-	er.ID = existing.ID
-	er.FHIRID = existing.FHIRID
+	er := *existing
+	if err := fhir.BindResource(c, "EnrollmentRequest", existing.FHIRID, &er); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateEnrollmentRequest(c.Request().Context(), &er); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -1521,8 +1517,8 @@ func (h *Handler) PatchEnrollmentRequestFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("EnrollmentRequest", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateEnrollmentRequest(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1582,8 +1578,8 @@ func (h *Handler) GetEnrollmentResponseFHIR(c echo.Context) error {
 
 func (h *Handler) CreateEnrollmentResponseFHIR(c echo.Context) error {
 	var er EnrollmentResponse
-	if err := c.Bind(&er); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "EnrollmentResponse", "", &er); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateEnrollmentResponse(c.Request().Context(), &er); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1593,16 +1589,14 @@ func (h *Handler) CreateEnrollmentResponseFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateEnrollmentResponseFHIR(c echo.Context) error {
-	var er EnrollmentResponse
-	if err := c.Bind(&er); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetEnrollmentResponseByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("EnrollmentResponse", c.Param("id")))
 	}
-	er.ID = existing.ID
-	er.FHIRID = existing.FHIRID
+	er := *existing
+	if err := fhir.BindResource(c, "EnrollmentResponse", existing.FHIRID, &er); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateEnrollmentResponse(c.Request().Context(), &er); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -1626,8 +1620,8 @@ func (h *Handler) PatchEnrollmentResponseFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("EnrollmentResponse", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateEnrollmentResponse(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -1676,55 +1670,55 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string, apply
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "InsurancePlan":
 		existing, err := h.svc.GetInsurancePlanByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "PaymentNotice":
 		existing, err := h.svc.GetPaymentNoticeByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "PaymentReconciliation":
 		existing, err := h.svc.GetPaymentReconciliationByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "ChargeItem":
 		existing, err := h.svc.GetChargeItemByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "ChargeItemDefinition":
 		existing, err := h.svc.GetChargeItemDefinitionByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "Contract":
 		existing, err := h.svc.GetContractByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "EnrollmentRequest":
 		existing, err := h.svc.GetEnrollmentRequestByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "EnrollmentResponse":
 		existing, err := h.svc.GetEnrollmentResponseByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	default:
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("unsupported resource type for PATCH"))
 	}
@@ -67,6 +67,21 @@ func (a *Account) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the account from a FHIR Account resource, the inverse of
+// ToFHIR.
+func (a *Account) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Account", data)
+	a.Status = r.CodeValue("status", "active", "inactive", "entered-in-error", "on-hold", "unknown")
+	accountType := r.CodeableConcept("type")
+	a.TypeCode, a.TypeDisplay = accountType.Code, accountType.Display
+	a.Name = r.String("name")
+	a.SubjectPatientID = r.ReferenceID("subject", "Patient")
+	a.ServicePeriodStart, a.ServicePeriodEnd = r.Period("servicePeriod")
+	a.OwnerOrgID = r.ReferenceID("owner", "Organization")
+	a.Description = r.String("description")
+	return r.Err()
+}
+
 // InsurancePlan maps to the insurance_plan table (FHIR InsurancePlan resource).
 type InsurancePlan struct {
 	ID                 uuid.UUID  `db:"id" json:"id"`
@@ -134,6 +149,23 @@ func (ip *InsurancePlan) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the plan from a FHIR InsurancePlan resource, the inverse
+// of ToFHIR.
+func (ip *InsurancePlan) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("InsurancePlan", data)
+	ip.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	planType := r.CodeableConcept("type")
+	ip.TypeCode, ip.TypeDisplay = planType.Code, planType.Display
+	ip.Name = r.String("name")
+	ip.Alias = r.String("alias")
+	ip.PeriodStart, ip.PeriodEnd = r.Period("period")
+	ip.OwnedByOrgID = r.ReferenceID("ownedBy", "Organization")
+	ip.AdministeredByOrgID = r.ReferenceID("administeredBy", "Organization")
+	ip.CoverageArea = r.String("coverageArea.display")
+	ip.NetworkName = r.String("network.name")
+	return r.Err()
+}
+
 // PaymentNotice maps to the payment_notice table (FHIR PaymentNotice resource).
 type PaymentNotice struct {
 	ID                uuid.UUID  `db:"id" json:"id"`
@@ -206,6 +238,27 @@ func (pn *PaymentNotice) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the notice from a FHIR PaymentNotice resource, the
+// inverse of ToFHIR.
+func (pn *PaymentNotice) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("PaymentNotice", data)
+	pn.Status = r.CodeValue("status", "active", "cancelled", "draft", "entered-in-error")
+	if created := r.DateTime("created"); created != nil {
+		pn.Created = *created
+	}
+	pn.RequestReference = r.Reference("request")
+	pn.ResponseReference = r.Reference("response")
+	pn.ProviderID = r.ReferenceID("provider", "Practitioner")
+	pn.PaymentReference = r.Reference("payment")
+	pn.PaymentDate = r.DateTime("paymentDate")
+	pn.PayeeOrgID = r.ReferenceID("payee", "Organization")
+	pn.RecipientOrgID = r.ReferenceID("recipient", "Organization")
+	pn.AmountValue = r.Decimal("amount.value")
+	pn.AmountCurrency = r.String("amount.currency")
+	pn.PaymentStatusCode = r.ConceptCode("paymentStatus")
+	return r.Err()
+}
+
 // PaymentReconciliation maps to the payment_reconciliation table (FHIR PaymentReconciliation resource).
 type PaymentReconciliation struct {
 	ID                uuid.UUID  `db:"id" json:"id"`
@@ -286,6 +339,33 @@ func (pr *PaymentReconciliation) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the reconciliation from a FHIR PaymentReconciliation
+// resource, the inverse of ToFHIR.
+func (pr *PaymentReconciliation) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("PaymentReconciliation", data)
+	pr.Status = r.CodeValue("status", "active", "cancelled", "draft", "entered-in-error")
+	if created := r.DateTime("created"); created != nil {
+		pr.Created = *created
+	}
+	if paymentDate := r.DateTime("paymentDate"); paymentDate != nil {
+		pr.PaymentDate = *paymentDate
+	}
+	if amount := r.Decimal("paymentAmount.value"); amount != nil {
+		pr.PaymentAmount = *amount
+	}
+	pr.PaymentCurrency = r.String("paymentAmount.currency")
+	pr.PeriodStart, pr.PeriodEnd = r.Period("period")
+	pr.PaymentIssuerOrgID = r.ReferenceID("paymentIssuer", "Organization")
+	pr.RequestReference = r.Reference("request")
+	pr.RequestorID = r.ReferenceID("requestor", "Practitioner")
+	pr.Outcome = r.Code("outcome", "queued", "complete", "error", "partial")
+	pr.Disposition = r.String("disposition")
+	pr.PaymentIdentifier = r.String("paymentIdentifier.value")
+	pr.FormCode = r.ConceptCode("formCode")
+	pr.ProcessNote = r.String("processNote.text")
+	return r.Err()
+}
+
 // ChargeItem maps to the charge_item table (FHIR ChargeItem resource).
 type ChargeItem struct {
 	ID                    uuid.UUID  `db:"id" json:"id"`
@@ -383,6 +463,31 @@ func (ci *ChargeItem) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the charge item from a FHIR ChargeItem resource, the
+// inverse of ToFHIR.
+func (ci *ChargeItem) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ChargeItem", data)
+	ci.Status = r.CodeValue("status", "planned", "billable", "not-billable", "aborted",
+		"billed", "entered-in-error", "unknown")
+	ci.SubjectPatientID = r.RequiredReferenceID("subject", "Patient")
+	code := r.CodeableConcept("code")
+	ci.CodeCode, ci.CodeDisplay, ci.CodeSystem = code.Code, code.Display, code.System
+	ci.ContextEncounterID = r.ReferenceID("context", "Encounter")
+	ci.OccurrenceDate = r.DateTime("occurrenceDateTime")
+	ci.PerformerID = r.ReferenceID("performer.actor", "Practitioner")
+	ci.PerformingOrgID = r.ReferenceID("performingOrganization", "Organization")
+	ci.QuantityValue = r.Decimal("quantity.value")
+	ci.FactorOverride = r.Decimal("factorOverride")
+	ci.PriceOverrideValue = r.Decimal("priceOverride.value")
+	ci.PriceOverrideCurrency = r.String("priceOverride.currency")
+	ci.OverrideReason = r.String("overrideReason")
+	ci.EntererID = r.ReferenceID("enterer", "Practitioner")
+	ci.EnteredDate = r.DateTime("enteredDate")
+	ci.AccountID = r.ReferenceID("account", "Account")
+	ci.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // ChargeItemDefinition maps to the charge_item_definition table (FHIR ChargeItemDefinition resource).
 type ChargeItemDefinition struct {
 	ID             uuid.UUID  `db:"id" json:"id"`
@@ -452,6 +557,23 @@ func (cd *ChargeItemDefinition) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the definition from a FHIR ChargeItemDefinition resource,
+// the inverse of ToFHIR.
+func (cd *ChargeItemDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ChargeItemDefinition", data)
+	cd.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	cd.URL = r.String("url")
+	cd.Title = r.String("title")
+	cd.Description = r.String("description")
+	code := r.CodeableConcept("code")
+	cd.CodeCode, cd.CodeDisplay, cd.CodeSystem = code.Code, code.Display, code.System
+	cd.EffectiveStart, cd.EffectiveEnd = r.Period("effectivePeriod")
+	cd.Publisher = r.String("publisher")
+	cd.ApprovalDate = r.DateTime("approvalDate")
+	cd.LastReviewDate = r.DateTime("lastReviewDate")
+	return r.Err()
+}
+
 // Contract maps to the contract table (FHIR Contract resource).
 type Contract struct {
 	ID               uuid.UUID  `db:"id" json:"id"`
@@ -528,6 +650,26 @@ func (ct *Contract) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the contract from a FHIR Contract resource, the inverse
+// of ToFHIR.
+func (ct *Contract) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Contract", data)
+	ct.Status = r.CodeValue("status", "amended", "appended", "cancelled", "disputed",
+		"entered-in-error", "executable", "executed", "negotiable", "offered", "policy",
+		"rejected", "renewed", "revoked", "resolved", "terminated")
+	contractType := r.CodeableConcept("type")
+	ct.TypeCode, ct.TypeDisplay = contractType.Code, contractType.Display
+	ct.SubTypeCode = r.ConceptCode("subType")
+	ct.Title = r.String("title")
+	ct.Issued = r.DateTime("issued")
+	ct.AppliesStart, ct.AppliesEnd = r.Period("applies")
+	ct.SubjectPatientID = r.ReferenceID("subject", "Patient")
+	ct.AuthorityOrgID = r.ReferenceID("authority", "Organization")
+	scope := r.CodeableConcept("scope")
+	ct.ScopeCode, ct.ScopeDisplay = scope.Code, scope.Display
+	return r.Err()
+}
+
 // EnrollmentRequest maps to the enrollment_request table (FHIR EnrollmentRequest resource).
 type EnrollmentRequest struct {
 	ID                 uuid.UUID  `db:"id" json:"id"`
@@ -573,6 +715,21 @@ func (er *EnrollmentRequest) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the request from a FHIR EnrollmentRequest resource, the
+// inverse of ToFHIR.
+func (er *EnrollmentRequest) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("EnrollmentRequest", data)
+	er.Status = r.CodeValue("status", "active", "cancelled", "draft", "entered-in-error")
+	if created := r.DateTime("created"); created != nil {
+		er.Created = *created
+	}
+	er.InsurerOrgID = r.ReferenceID("insurer", "Organization")
+	er.ProviderID = r.ReferenceID("provider", "Practitioner")
+	er.CandidatePatientID = r.ReferenceID("candidate", "Patient")
+	er.CoverageID = r.ReferenceID("coverage", "Coverage")
+	return r.Err()
+}
+
 // EnrollmentResponse maps to the enrollment_response table (FHIR EnrollmentResponse resource).
 type EnrollmentResponse struct {
 	ID             uuid.UUID  `db:"id" json:"id"`
@@ -617,3 +774,18 @@ func (er *EnrollmentResponse) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the response from a FHIR EnrollmentResponse resource, the
+// inverse of ToFHIR.
+func (er *EnrollmentResponse) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("EnrollmentResponse", data)
+	er.Status = r.CodeValue("status", "active", "cancelled", "draft", "entered-in-error")
+	if created := r.DateTime("created"); created != nil {
+		er.Created = *created
+	}
+	er.RequestID = r.ReferenceID("request", "EnrollmentRequest")
+	er.Outcome = r.Code("outcome", "queued", "complete", "error", "partial")
+	er.Disposition = r.String("disposition")
+	er.OrganizationID = r.ReferenceID("organization", "Organization")
+	return r.Err()
+}
@@ -74,7 +74,7 @@ func (r *accountRepoPG) Update(ctx context.Context, a *Account) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE account SET status=$2, type_code=$3, type_display=$4, name=$5,
 			subject_patient_id=$6, service_period_start=$7, service_period_end=$8,
-			owner_org_id=$9, description=$10, updated_at=NOW()
+			owner_org_id=$9, description=$10, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		a.ID, a.Status, a.TypeCode, a.TypeDisplay, a.Name,
 		a.SubjectPatientID, a.ServicePeriodStart, a.ServicePeriodEnd,
@@ -116,13 +116,16 @@ var accountSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *accountRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Account, int, error) {
 	qb := fhir.NewSearchQuery("account", acctCols)
-	qb.ApplyParams(params, accountSearchParams)
+	qb.ApplyParams(ctx, params, accountSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -198,7 +201,7 @@ func (r *insurancePlanRepoPG) Update(ctx context.Context, ip *InsurancePlan) err
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE insurance_plan SET status=$2, type_code=$3, type_display=$4, name=$5, alias=$6,
 			period_start=$7, period_end=$8, owned_by_org_id=$9, administered_by_org_id=$10,
-			coverage_area=$11, network_name=$12, updated_at=NOW()
+			coverage_area=$11, network_name=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ip.ID, ip.Status, ip.TypeCode, ip.TypeDisplay, ip.Name, ip.Alias,
 		ip.PeriodStart, ip.PeriodEnd, ip.OwnedByOrgID, ip.AdministeredByOrgID,
@@ -240,13 +243,16 @@ var insurancePlanSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *insurancePlanRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*InsurancePlan, int, error) {
 	qb := fhir.NewSearchQuery("insurance_plan", ipCols)
-	qb.ApplyParams(params, insurancePlanSearchParams)
+	qb.ApplyParams(ctx, params, insurancePlanSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -323,7 +329,7 @@ func (r *paymentNoticeRepoPG) Update(ctx context.Context, pn *PaymentNotice) err
 		UPDATE payment_notice SET status=$2, request_reference=$3, response_reference=$4,
 			provider_id=$5, payment_reference=$6, payment_date=$7,
 			payee_org_id=$8, recipient_org_id=$9,
-			amount_value=$10, amount_currency=$11, payment_status_code=$12, updated_at=NOW()
+			amount_value=$10, amount_currency=$11, payment_status_code=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		pn.ID, pn.Status, pn.RequestReference, pn.ResponseReference,
 		pn.ProviderID, pn.PaymentReference, pn.PaymentDate,
@@ -365,13 +371,16 @@ var paymentNoticeSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *paymentNoticeRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*PaymentNotice, int, error) {
 	qb := fhir.NewSearchQuery("payment_notice", pnCols)
-	qb.ApplyParams(params, paymentNoticeSearchParams)
+	qb.ApplyParams(ctx, params, paymentNoticeSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -452,7 +461,7 @@ func (r *paymentReconciliationRepoPG) Update(ctx context.Context, pr *PaymentRec
 		UPDATE payment_reconciliation SET status=$2, period_start=$3, period_end=$4,
 			payment_issuer_org_id=$5, request_reference=$6, requestor_id=$7,
 			outcome=$8, disposition=$9, payment_date=$10, payment_amount=$11, payment_currency=$12,
-			payment_identifier=$13, form_code=$14, process_note=$15, updated_at=NOW()
+			payment_identifier=$13, form_code=$14, process_note=$15, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		pr.ID, pr.Status, pr.PeriodStart, pr.PeriodEnd,
 		pr.PaymentIssuerOrgID, pr.RequestReference, pr.RequestorID,
@@ -494,13 +503,16 @@ var paymentReconciliationSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *paymentReconciliationRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*PaymentReconciliation, int, error) {
 	qb := fhir.NewSearchQuery("payment_reconciliation", prCols)
-	qb.ApplyParams(params, paymentReconciliationSearchParams)
+	qb.ApplyParams(ctx, params, paymentReconciliationSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -586,7 +598,7 @@ func (r *chargeItemRepoPG) Update(ctx context.Context, ci *ChargeItem) error {
 			context_encounter_id=$6, occurrence_date=$7,
 			performer_id=$8, performing_org_id=$9, quantity_value=$10, factor_override=$11,
 			price_override_value=$12, price_override_currency=$13, override_reason=$14,
-			enterer_id=$15, entered_date=$16, account_id=$17, note=$18, updated_at=NOW()
+			enterer_id=$15, entered_date=$16, account_id=$17, note=$18, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ci.ID, ci.Status, ci.CodeCode, ci.CodeDisplay, ci.CodeSystem,
 		ci.ContextEncounterID, ci.OccurrenceDate,
@@ -630,13 +642,16 @@ var chargeItemSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *chargeItemRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ChargeItem, int, error) {
 	qb := fhir.NewSearchQuery("charge_item", ciCols)
-	qb.ApplyParams(params, chargeItemSearchParams)
+	qb.ApplyParams(ctx, params, chargeItemSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -717,7 +732,7 @@ func (r *chargeItemDefinitionRepoPG) Update(ctx context.Context, cd *ChargeItemD
 		UPDATE charge_item_definition SET url=$2, status=$3, title=$4, description=$5,
 			code_code=$6, code_display=$7, code_system=$8,
 			effective_start=$9, effective_end=$10, publisher=$11,
-			approval_date=$12, last_review_date=$13, updated_at=NOW()
+			approval_date=$12, last_review_date=$13, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		cd.ID, cd.URL, cd.Status, cd.Title, cd.Description,
 		cd.CodeCode, cd.CodeDisplay, cd.CodeSystem,
@@ -760,13 +775,16 @@ var chargeItemDefinitionSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *chargeItemDefinitionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ChargeItemDefinition, int, error) {
 	qb := fhir.NewSearchQuery("charge_item_definition", cdCols)
-	qb.ApplyParams(params, chargeItemDefinitionSearchParams)
+	qb.ApplyParams(ctx, params, chargeItemDefinitionSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -842,7 +860,7 @@ func (r *contractRepoPG) Update(ctx context.Context, ct *Contract) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE contract SET status=$2, type_code=$3, type_display=$4, sub_type_code=$5,
 			title=$6, issued=$7, applies_start=$8, applies_end=$9,
-			subject_patient_id=$10, authority_org_id=$11, scope_code=$12, scope_display=$13, updated_at=NOW()
+			subject_patient_id=$10, authority_org_id=$11, scope_code=$12, scope_display=$13, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ct.ID, ct.Status, ct.TypeCode, ct.TypeDisplay, ct.SubTypeCode,
 		ct.Title, ct.Issued, ct.AppliesStart, ct.AppliesEnd,
@@ -883,13 +901,16 @@ var contractSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *contractRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Contract, int, error) {
 	qb := fhir.NewSearchQuery("contract", ctCols)
-	qb.ApplyParams(params, contractSearchParams)
+	qb.ApplyParams(ctx, params, contractSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -960,7 +981,7 @@ func (r *enrollmentRequestRepoPG) GetByFHIRID(ctx context.Context, fhirID string
 func (r *enrollmentRequestRepoPG) Update(ctx context.Context, er *EnrollmentRequest) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE enrollment_request SET status=$2, insurer_org_id=$3, provider_id=$4,
-			candidate_patient_id=$5, coverage_id=$6, updated_at=NOW()
+			candidate_patient_id=$5, coverage_id=$6, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		er.ID, er.Status, er.InsurerOrgID, er.ProviderID,
 		er.CandidatePatientID, er.CoverageID)
@@ -1000,13 +1021,16 @@ var enrollmentRequestSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *enrollmentRequestRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*EnrollmentRequest, int, error) {
 	qb := fhir.NewSearchQuery("enrollment_request", erCols)
-	qb.ApplyParams(params, enrollmentRequestSearchParams)
+	qb.ApplyParams(ctx, params, enrollmentRequestSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -1077,7 +1101,7 @@ func (r *enrollmentResponseRepoPG) GetByFHIRID(ctx context.Context, fhirID strin
 func (r *enrollmentResponseRepoPG) Update(ctx context.Context, er *EnrollmentResponse) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE enrollment_response SET status=$2, request_id=$3, outcome=$4, disposition=$5,
-			organization_id=$6, updated_at=NOW()
+			organization_id=$6, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		er.ID, er.Status, er.RequestID, er.Outcome, er.Disposition,
 		er.OrganizationID)
@@ -1117,13 +1141,16 @@ var enrollmentResponseSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *enrollmentResponseRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*EnrollmentResponse, int, error) {
 	qb := fhir.NewSearchQuery("enrollment_response", erspCols)
-	qb.ApplyParams(params, enrollmentResponseSearchParams)
+	qb.ApplyParams(ctx, params, enrollmentResponseSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -1140,3 +1167,17 @@ func (r *enrollmentResponseRepoPG) Search(ctx context.Context, params map[string
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("account", accountSearchParams)
+	r.RegisterTable("insurance_plan", insurancePlanSearchParams)
+	r.RegisterTable("payment_notice", paymentNoticeSearchParams)
+	r.RegisterTable("payment_reconciliation", paymentReconciliationSearchParams)
+	r.RegisterTable("charge_item", chargeItemSearchParams)
+	r.RegisterTable("charge_item_definition", chargeItemDefinitionSearchParams)
+	r.RegisterTable("contract", contractSearchParams)
+	r.RegisterTable("enrollment_request", enrollmentRequestSearchParams)
+	r.RegisterTable("enrollment_response", enrollmentResponseSearchParams)
+}
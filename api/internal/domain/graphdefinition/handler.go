@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/GraphDefinition/:id/_history", h.HistoryGraphDefinitionFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("GraphDefinition", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateGraphDefinition(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetGraphDefinitionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateGraphDefinitionFHIR(c echo.Context) error {
 	var g GraphDefinition
-	if err := c.Bind(&g); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "GraphDefinition", "", &g); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateGraphDefinition(c.Request().Context(), &g); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateGraphDefinitionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateGraphDefinitionFHIR(c echo.Context) error {
-	var g GraphDefinition
-	if err := c.Bind(&g); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetGraphDefinitionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("GraphDefinition", c.Param("id")))
 	}
-	g.ID = existing.ID
-	g.FHIRID = existing.FHIRID
+	g := *existing
+	if err := fhir.BindResource(c, "GraphDefinition", existing.FHIRID, &g); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateGraphDefinition(c.Request().Context(), &g); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("GraphDefinition", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateGraphDefinition(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
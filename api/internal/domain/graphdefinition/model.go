@@ -59,6 +59,21 @@ func (g *GraphDefinition) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the graph definition from a FHIR GraphDefinition
+// resource, the inverse of ToFHIR.
+func (g *GraphDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("GraphDefinition", data)
+	g.Name = r.RequiredString("name")
+	g.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	g.StartType = r.RequiredString("start")
+	g.URL = r.String("url")
+	g.Description = r.String("description")
+	g.Publisher = r.String("publisher")
+	g.Date = r.DateTime("date")
+	g.Profile = r.String("profile")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
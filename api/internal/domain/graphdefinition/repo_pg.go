@@ -71,7 +71,7 @@ func (r *graphDefinitionRepoPG) GetByFHIRID(ctx context.Context, fhirID string)
 func (r *graphDefinitionRepoPG) Update(ctx context.Context, g *GraphDefinition) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE graph_definition SET status=$2, url=$3, name=$4, description=$5, publisher=$6, date=$7,
-			start_type=$8, profile=$9, updated_at=NOW()
+			start_type=$8, profile=$9, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		g.ID, g.Status, g.URL, g.Name, g.Description, g.Publisher, g.Date,
 		g.StartType, g.Profile)
@@ -113,13 +113,16 @@ var graphDefinitionSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *graphDefinitionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*GraphDefinition, int, error) {
 	qb := fhir.NewSearchQuery("graph_definition", gdCols)
-	qb.ApplyParams(params, graphDefinitionSearchParams)
+	qb.ApplyParams(ctx, params, graphDefinitionSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -136,3 +139,9 @@ func (r *graphDefinitionRepoPG) Search(ctx context.Context, params map[string]st
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("graph_definition", graphDefinitionSearchParams)
+}
@@ -50,6 +50,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/HealthcareService/:id/_history", h.HistoryHealthcareServiceFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("HealthcareService", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateHealthcareService(c echo.Context) error {
@@ -139,8 +143,8 @@ func (h *Handler) GetHealthcareServiceFHIR(c echo.Context) error {
 
 func (h *Handler) CreateHealthcareServiceFHIR(c echo.Context) error {
 	var hs HealthcareService
-	if err := c.Bind(&hs); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "HealthcareService", "", &hs); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateHealthcareService(c.Request().Context(), &hs); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -150,16 +154,14 @@ func (h *Handler) CreateHealthcareServiceFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateHealthcareServiceFHIR(c echo.Context) error {
-	var hs HealthcareService
-	if err := c.Bind(&hs); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetHealthcareServiceByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("HealthcareService", c.Param("id")))
 	}
-	hs.ID = existing.ID
-	hs.FHIRID = existing.FHIRID
+	hs := *existing
+	if err := fhir.BindResource(c, "HealthcareService", existing.FHIRID, &hs); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateHealthcareService(c.Request().Context(), &hs); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -216,7 +218,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("HealthcareService", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -242,11 +244,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["active"].(bool); ok {
-		existing.Active = v
-	}
-	if v, ok := patched["name"].(string); ok {
-		existing.Name = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateHealthcareService(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
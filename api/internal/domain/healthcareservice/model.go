@@ -90,6 +90,31 @@ func (hs *HealthcareService) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the service from a FHIR HealthcareService resource, the
+// inverse of ToFHIR.
+func (hs *HealthcareService) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("HealthcareService", data)
+	hs.Active = true
+	if active := r.Bool("active"); active != nil {
+		hs.Active = *active
+	}
+	hs.Name = r.RequiredString("name")
+	hs.AppointmentRequired = r.BoolValue("appointmentRequired")
+	hs.ProvidedByOrgID = r.ReferenceID("providedBy", "Organization")
+	category := r.CodeableConcept("category")
+	hs.CategoryCode, hs.CategoryDisplay = category.Code, category.Display
+	serviceType := r.CodeableConcept("type")
+	hs.TypeCode, hs.TypeDisplay = serviceType.Code, serviceType.Display
+	hs.Comment = r.String("comment")
+	hs.TelecomPhone = r.ContactValue("telecom", "phone", "")
+	hs.TelecomEmail = r.ContactValue("telecom", "email", "")
+	hs.ServiceProvisionCode = r.ConceptCode("serviceProvisionCode")
+	hs.ProgramName = r.String("program.text")
+	hs.LocationID = r.ReferenceID("location", "Location")
+	hs.AvailabilityExceptions = r.String("availabilityExceptions")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -86,7 +86,7 @@ func (r *healthcareServiceRepoPG) Update(ctx context.Context, hs *HealthcareServ
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE healthcare_service SET active=$2, name=$3, comment=$4,
 			telecom_phone=$5, telecom_email=$6, service_provision_code=$7,
-			appointment_required=$8, availability_exceptions=$9, updated_at=NOW()
+			appointment_required=$8, availability_exceptions=$9, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		hs.ID, hs.Active, hs.Name, hs.Comment,
 		hs.TelecomPhone, hs.TelecomEmail, hs.ServiceProvisionCode,
@@ -128,13 +128,16 @@ var healthcareServiceSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *healthcareServiceRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*HealthcareService, int, error) {
 	qb := fhir.NewSearchQuery("healthcare_service", hsCols)
-	qb.ApplyParams(params, healthcareServiceSearchParams)
+	qb.ApplyParams(ctx, params, healthcareServiceSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -151,3 +154,9 @@ func (r *healthcareServiceRepoPG) Search(ctx context.Context, params map[string]
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("healthcare_service", healthcareServiceSearchParams)
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -96,6 +95,12 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/PractitionerRole/:id/_history", h.HistoryPractitionerRoleFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Patient", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("Practitioner", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("PractitionerRole", fhir.ReadOnlyInteractions()...)
+}
+
 // -- Patient Operational Handlers --
 
 func (h *Handler) CreatePatient(c echo.Context) error {
@@ -404,8 +409,8 @@ func (h *Handler) GetPatientFHIR(c echo.Context) error {
 
 func (h *Handler) CreatePatientFHIR(c echo.Context) error {
 	var p Patient
-	if err := c.Bind(&p); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Patient", "", &p); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreatePatient(c.Request().Context(), &p); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -419,12 +424,10 @@ func (h *Handler) UpdatePatientFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Patient", c.Param("id")))
 	}
-	var p Patient
-	if err := c.Bind(&p); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	p := *existing
+	if err := fhir.BindResource(c, "Patient", existing.FHIRID, &p); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
-	p.ID = existing.ID
-	p.FHIRID = existing.FHIRID
 	if err := h.svc.UpdatePatient(c.Request().Context(), &p); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -480,8 +483,8 @@ func (h *Handler) GetPractitionerFHIR(c echo.Context) error {
 
 func (h *Handler) CreatePractitionerFHIR(c echo.Context) error {
 	var p Practitioner
-	if err := c.Bind(&p); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Practitioner", "", &p); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreatePractitioner(c.Request().Context(), &p); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -495,12 +498,10 @@ func (h *Handler) UpdatePractitionerFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Practitioner", c.Param("id")))
 	}
-	var p Practitioner
-	if err := c.Bind(&p); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	p := *existing
+	if err := fhir.BindResource(c, "Practitioner", existing.FHIRID, &p); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
-	p.ID = existing.ID
-	p.FHIRID = existing.FHIRID
 	if err := h.svc.UpdatePractitioner(c.Request().Context(), &p); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -556,8 +557,8 @@ func (h *Handler) GetPractitionerRoleFHIR(c echo.Context) error {
 
 func (h *Handler) CreatePractitionerRoleFHIR(c echo.Context) error {
 	var role PractitionerRole
-	if err := c.Bind(&role); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "PractitionerRole", "", &role); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreatePractitionerRole(c.Request().Context(), &role); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -571,12 +572,10 @@ func (h *Handler) UpdatePractitionerRoleFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("PractitionerRole", c.Param("id")))
 	}
-	var role PractitionerRole
-	if err := c.Bind(&role); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	role := *existing
+	if err := fhir.BindResource(c, "PractitionerRole", existing.FHIRID, &role); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
-	role.ID = existing.ID
-	role.FHIRID = existing.FHIRID
 	role.PractitionerID = existing.PractitionerID
 	if err := h.svc.UpdatePractitionerRole(c.Request().Context(), &role); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -606,7 +605,7 @@ func (h *Handler) PatchPractitionerRoleFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("PractitionerRole", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -629,7 +628,9 @@ func (h *Handler) PatchPractitionerRoleFHIR(c echo.Context) error {
 	} else {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome("PATCH requires application/json-patch+json or application/merge-patch+json"))
 	}
-	applyPractitionerRolePatch(existing, patched)
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
+	}
 	if err := h.svc.UpdatePractitionerRole(ctx, existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -717,7 +718,7 @@ func (h *Handler) PatchPatientFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Patient", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -740,7 +741,9 @@ func (h *Handler) PatchPatientFHIR(c echo.Context) error {
 	} else {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome("PATCH requires application/json-patch+json or application/merge-patch+json"))
 	}
-	applyPatientPatch(existing, patched)
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
+	}
 	if err := h.svc.UpdatePatient(ctx, existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -758,7 +761,7 @@ func (h *Handler) PatchPractitionerFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Practitioner", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -781,7 +784,9 @@ func (h *Handler) PatchPractitionerFHIR(c echo.Context) error {
 	} else {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome("PATCH requires application/json-patch+json or application/merge-patch+json"))
 	}
-	applyPractitionerPatch(existing, patched)
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
+	}
 	if err := h.svc.UpdatePractitioner(ctx, existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -926,296 +931,3 @@ func (h *Handler) HistoryPractitionerFHIR(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, fhir.NewHistoryBundle([]*fhir.HistoryEntry{entry}, 1, "/fhir"))
 }
-
-// -- FHIR PATCH helpers --
-
-func applyPatientPatch(p *Patient, patched map[string]interface{}) {
-	if v, ok := patched["active"].(bool); ok {
-		p.Active = v
-	}
-	if v, ok := patched["gender"].(string); ok {
-		p.Gender = &v
-	}
-	if v, ok := patched["birthDate"].(string); ok {
-		if t, err := time.Parse("2006-01-02", v); err == nil {
-			p.BirthDate = &t
-		}
-	}
-	if v, ok := patched["deceasedBoolean"].(bool); ok {
-		p.DeceasedBoolean = v
-	}
-	if v, ok := patched["deceasedDateTime"].(string); ok {
-		if t, err := time.Parse(time.RFC3339, v); err == nil {
-			p.DeceasedDatetime = &t
-		}
-	}
-	// name array
-	if v, ok := patched["name"]; ok {
-		if names, ok := v.([]interface{}); ok && len(names) > 0 {
-			if name, ok := names[0].(map[string]interface{}); ok {
-				if family, ok := name["family"].(string); ok {
-					p.LastName = family
-				}
-				if given, ok := name["given"].([]interface{}); ok {
-					if len(given) > 0 {
-						if first, ok := given[0].(string); ok {
-							p.FirstName = first
-						}
-					}
-					if len(given) > 1 {
-						if middle, ok := given[1].(string); ok {
-							p.MiddleName = &middle
-						}
-					}
-				}
-				if prefix, ok := name["prefix"].([]interface{}); ok && len(prefix) > 0 {
-					if pv, ok := prefix[0].(string); ok {
-						p.Prefix = &pv
-					}
-				}
-				if suffix, ok := name["suffix"].([]interface{}); ok && len(suffix) > 0 {
-					if sv, ok := suffix[0].(string); ok {
-						p.Suffix = &sv
-					}
-				}
-			}
-		}
-	}
-	// telecom array
-	if v, ok := patched["telecom"]; ok {
-		if telecoms, ok := v.([]interface{}); ok {
-			for _, tc := range telecoms {
-				if cp, ok := tc.(map[string]interface{}); ok {
-					system, _ := cp["system"].(string)
-					value, _ := cp["value"].(string)
-					use, _ := cp["use"].(string)
-					if system == "phone" {
-						switch use {
-						case "mobile":
-							p.PhoneMobile = &value
-						case "home":
-							p.PhoneHome = &value
-						case "work":
-							p.PhoneWork = &value
-						default:
-							p.PhoneMobile = &value
-						}
-					} else if system == "email" {
-						p.Email = &value
-					}
-				}
-			}
-		}
-	}
-	// address array
-	if v, ok := patched["address"]; ok {
-		if addrs, ok := v.([]interface{}); ok && len(addrs) > 0 {
-			if addr, ok := addrs[0].(map[string]interface{}); ok {
-				if use, ok := addr["use"].(string); ok {
-					p.AddressUse = &use
-				}
-				if lines, ok := addr["line"].([]interface{}); ok {
-					if len(lines) > 0 {
-						if l1, ok := lines[0].(string); ok {
-							p.AddressLine1 = &l1
-						}
-					}
-					if len(lines) > 1 {
-						if l2, ok := lines[1].(string); ok {
-							p.AddressLine2 = &l2
-						}
-					}
-				}
-				if city, ok := addr["city"].(string); ok {
-					p.City = &city
-				}
-				if district, ok := addr["district"].(string); ok {
-					p.District = &district
-				}
-				if state, ok := addr["state"].(string); ok {
-					p.State = &state
-				}
-				if postalCode, ok := addr["postalCode"].(string); ok {
-					p.PostalCode = &postalCode
-				}
-				if country, ok := addr["country"].(string); ok {
-					p.Country = &country
-				}
-			}
-		}
-	}
-	// maritalStatus
-	if v, ok := patched["maritalStatus"]; ok {
-		if ms, ok := v.(map[string]interface{}); ok {
-			if coding, ok := ms["coding"].([]interface{}); ok && len(coding) > 0 {
-				if c, ok := coding[0].(map[string]interface{}); ok {
-					if code, ok := c["code"].(string); ok {
-						p.MaritalStatus = &code
-					}
-				}
-			}
-		}
-	}
-	// communication / preferredLanguage
-	if v, ok := patched["communication"]; ok {
-		if comms, ok := v.([]interface{}); ok && len(comms) > 0 {
-			if comm, ok := comms[0].(map[string]interface{}); ok {
-				if lang, ok := comm["language"].(map[string]interface{}); ok {
-					if coding, ok := lang["coding"].([]interface{}); ok && len(coding) > 0 {
-						if c, ok := coding[0].(map[string]interface{}); ok {
-							if code, ok := c["code"].(string); ok {
-								p.PreferredLanguage = &code
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-	// photo
-	if v, ok := patched["photo"]; ok {
-		if photos, ok := v.([]interface{}); ok && len(photos) > 0 {
-			if photo, ok := photos[0].(map[string]interface{}); ok {
-				if url, ok := photo["url"].(string); ok {
-					p.PhotoURL = &url
-				}
-			}
-		}
-	}
-}
-
-func applyPractitionerPatch(p *Practitioner, patched map[string]interface{}) {
-	if v, ok := patched["active"].(bool); ok {
-		p.Active = v
-	}
-	if v, ok := patched["gender"].(string); ok {
-		p.Gender = &v
-	}
-	if v, ok := patched["birthDate"].(string); ok {
-		if t, err := time.Parse("2006-01-02", v); err == nil {
-			p.BirthDate = &t
-		}
-	}
-	// name array
-	if v, ok := patched["name"]; ok {
-		if names, ok := v.([]interface{}); ok && len(names) > 0 {
-			if name, ok := names[0].(map[string]interface{}); ok {
-				if family, ok := name["family"].(string); ok {
-					p.LastName = family
-				}
-				if given, ok := name["given"].([]interface{}); ok {
-					if len(given) > 0 {
-						if first, ok := given[0].(string); ok {
-							p.FirstName = first
-						}
-					}
-					if len(given) > 1 {
-						if middle, ok := given[1].(string); ok {
-							p.MiddleName = &middle
-						}
-					}
-				}
-				if prefix, ok := name["prefix"].([]interface{}); ok && len(prefix) > 0 {
-					if pv, ok := prefix[0].(string); ok {
-						p.Prefix = &pv
-					}
-				}
-				if suffix, ok := name["suffix"].([]interface{}); ok && len(suffix) > 0 {
-					if sv, ok := suffix[0].(string); ok {
-						p.Suffix = &sv
-					}
-				}
-			}
-		}
-	}
-	// telecom array
-	if v, ok := patched["telecom"]; ok {
-		if telecoms, ok := v.([]interface{}); ok {
-			for _, tc := range telecoms {
-				if cp, ok := tc.(map[string]interface{}); ok {
-					system, _ := cp["system"].(string)
-					value, _ := cp["value"].(string)
-					if system == "phone" {
-						p.Phone = &value
-					} else if system == "email" {
-						p.Email = &value
-					}
-				}
-			}
-		}
-	}
-	// address array
-	if v, ok := patched["address"]; ok {
-		if addrs, ok := v.([]interface{}); ok && len(addrs) > 0 {
-			if addr, ok := addrs[0].(map[string]interface{}); ok {
-				if lines, ok := addr["line"].([]interface{}); ok && len(lines) > 0 {
-					if l1, ok := lines[0].(string); ok {
-						p.AddressLine1 = &l1
-					}
-				}
-				if city, ok := addr["city"].(string); ok {
-					p.City = &city
-				}
-				if state, ok := addr["state"].(string); ok {
-					p.State = &state
-				}
-				if postalCode, ok := addr["postalCode"].(string); ok {
-					p.PostalCode = &postalCode
-				}
-				if country, ok := addr["country"].(string); ok {
-					p.Country = &country
-				}
-			}
-		}
-	}
-	// qualification
-	if v, ok := patched["qualification"]; ok {
-		if quals, ok := v.([]interface{}); ok && len(quals) > 0 {
-			if qual, ok := quals[0].(map[string]interface{}); ok {
-				if code, ok := qual["code"].(map[string]interface{}); ok {
-					if text, ok := code["text"].(string); ok {
-						p.QualificationSummary = &text
-					}
-				}
-			}
-		}
-	}
-}
-
-func applyPractitionerRolePatch(r *PractitionerRole, patched map[string]interface{}) {
-	if v, ok := patched["active"].(bool); ok {
-		r.Active = v
-	}
-	// code array
-	if v, ok := patched["code"]; ok {
-		if codes, ok := v.([]interface{}); ok && len(codes) > 0 {
-			if cc, ok := codes[0].(map[string]interface{}); ok {
-				if coding, ok := cc["coding"].([]interface{}); ok && len(coding) > 0 {
-					if c, ok := coding[0].(map[string]interface{}); ok {
-						if code, ok := c["code"].(string); ok {
-							r.RoleCode = code
-						}
-						if display, ok := c["display"].(string); ok {
-							r.RoleDisplay = &display
-						}
-					}
-				}
-			}
-		}
-	}
-	// period
-	if v, ok := patched["period"]; ok {
-		if period, ok := v.(map[string]interface{}); ok {
-			if start, ok := period["start"].(string); ok {
-				if t, err := time.Parse(time.RFC3339, start); err == nil {
-					r.PeriodStart = &t
-				}
-			}
-			if end, ok := period["end"].(string); ok {
-				if t, err := time.Parse(time.RFC3339, end); err == nil {
-					r.PeriodEnd = &t
-				}
-			}
-		}
-	}
-}
@@ -253,6 +253,94 @@ func (p *Patient) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the patient from a FHIR Patient resource, the inverse
+// of ToFHIR. Hashed identifiers and other columns with no FHIR representation
+// are left unchanged.
+func (p *Patient) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Patient", data)
+	p.Active = true
+	if active := r.Bool("active"); active != nil {
+		p.Active = *active
+	}
+
+	name := officialName(r)
+	p.LastName = r.RequiredString(name + ".family")
+	p.FirstName = r.RequiredString(name + ".given[0]")
+	p.MiddleName = r.String(name + ".given[1]")
+	p.Prefix = r.String(name + ".prefix")
+	p.Suffix = r.String(name + ".suffix")
+
+	mrn, ok := r.Find("identifier", func(item string) bool {
+		return r.StringValue(item+".type.coding.code") == "MR"
+	})
+	if !ok {
+		mrn = "identifier"
+	}
+	p.MRN = r.RequiredString(mrn + ".value")
+	p.AbhaID = r.IdentifierValue("identifier", "https://healthid.ndhm.gov.in")
+
+	p.Gender = r.Code("gender", "male", "female", "other", "unknown")
+	p.BirthDate = r.DateTime("birthDate")
+	p.DeceasedBoolean = r.BoolValue("deceasedBoolean")
+	p.DeceasedDatetime = r.DateTime("deceasedDateTime")
+
+	p.PhoneMobile = r.ContactValue("telecom", "phone", "mobile")
+	p.PhoneHome = r.ContactValue("telecom", "phone", "home")
+	if p.PhoneMobile == nil && p.PhoneHome == nil {
+		p.PhoneMobile = r.ContactValue("telecom", "phone", "")
+	}
+	p.Email = r.ContactValue("telecom", "email", "")
+
+	p.AddressUse = r.Code("address.use", "home", "work", "temp", "old", "billing")
+	p.AddressLine1 = r.String("address.line[0]")
+	p.AddressLine2 = r.String("address.line[1]")
+	p.City = r.String("address.city")
+	p.District = r.String("address.district")
+	p.State = r.String("address.state")
+	p.PostalCode = r.String("address.postalCode")
+	p.Country = r.String("address.country")
+
+	p.ManagingOrgID = r.ReferenceID("managingOrganization", "Organization")
+	p.PrimaryCareProviderID = r.ReferenceID("generalPractitioner", "Practitioner")
+	p.PreferredLanguage = r.CodeableConcept("communication.language").Code
+
+	p.RaceCode, p.RaceDisplay, p.RaceText = nil, nil, nil
+	if ext, ok := r.Extension("", "http://hl7.org/fhir/us/core/StructureDefinition/us-core-race"); ok {
+		p.RaceCode, p.RaceDisplay, p.RaceText = ombCategory(r, ext)
+	}
+	p.EthnicityCode, p.EthnicityDisplay, p.EthnicityText = nil, nil, nil
+	if ext, ok := r.Extension("", "http://hl7.org/fhir/us/core/StructureDefinition/us-core-ethnicity"); ok {
+		p.EthnicityCode, p.EthnicityDisplay, p.EthnicityText = ombCategory(r, ext)
+	}
+	p.BirthSex = nil
+	if ext, ok := r.Extension("", "http://hl7.org/fhir/us/core/StructureDefinition/us-core-birthsex"); ok {
+		p.BirthSex = r.Code(ext + ".valueCode")
+	}
+	return r.Err()
+}
+
+// officialName returns the path of the official HumanName, falling back to
+// the first name.
+func officialName(r *fhir.ResourceReader) string {
+	if name, ok := r.Find("name", func(item string) bool { return r.StringValue(item+".use") == "official" }); ok {
+		return name
+	}
+	return "name[0]"
+}
+
+// ombCategory reads the ombCategory coding and text of a US Core race or
+// ethnicity extension.
+func ombCategory(r *fhir.ResourceReader, ext string) (code, display, text *string) {
+	if cat, ok := r.Extension(ext, "ombCategory"); ok {
+		coding := r.Coding(cat + ".valueCoding")
+		code, display = coding.Code, coding.Display
+	}
+	if txt, ok := r.Extension(ext, "text"); ok {
+		text = r.String(txt + ".valueString")
+	}
+	return code, display, text
+}
+
 // ToFHIRWithLinks returns FHIR representation including link[] from PatientLink data.
 func (p *Patient) ToFHIRWithLinks(links []*PatientLink) map[string]interface{} {
 	result := p.ToFHIR()
@@ -427,6 +515,37 @@ func (p *Practitioner) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the practitioner from a FHIR Practitioner resource, the
+// inverse of ToFHIR. Licence, DEA and qualification columns are left unchanged.
+func (p *Practitioner) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Practitioner", data)
+	p.Active = true
+	if active := r.Bool("active"); active != nil {
+		p.Active = *active
+	}
+
+	name := officialName(r)
+	p.LastName = r.RequiredString(name + ".family")
+	p.FirstName = r.RequiredString(name + ".given[0]")
+	p.MiddleName = r.String(name + ".given[1]")
+	p.Prefix = r.String(name + ".prefix")
+	p.Suffix = r.String(name + ".suffix")
+
+	p.NPINumber = r.IdentifierValue("identifier", "http://hl7.org/fhir/sid/us-npi")
+	p.HPRID = r.IdentifierValue("identifier", "https://hpr.ndhm.gov.in")
+	p.Gender = r.Code("gender", "male", "female", "other", "unknown")
+	p.BirthDate = r.DateTime("birthDate")
+
+	p.Phone = r.ContactValue("telecom", "phone", "")
+	p.Email = r.ContactValue("telecom", "email", "")
+	p.AddressLine1 = r.String("address.line[0]")
+	p.City = r.String("address.city")
+	p.State = r.String("address.state")
+	p.PostalCode = r.String("address.postalCode")
+	p.Country = r.String("address.country")
+	return r.Err()
+}
+
 // PatientLink maps to the patient_link table (FHIR Patient.link).
 type PatientLink struct {
 	ID              uuid.UUID `db:"id" json:"id"`
@@ -521,6 +640,23 @@ func (r *PractitionerRole) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the role from a FHIR PractitionerRole resource, the
+// inverse of ToFHIR.
+func (r *PractitionerRole) FromFHIR(data map[string]interface{}) error {
+	rd := fhir.NewResourceReader("PractitionerRole", data)
+	r.Active = true
+	if active := rd.Bool("active"); active != nil {
+		r.Active = *active
+	}
+	r.PractitionerID = rd.RequiredReferenceID("practitioner", "Practitioner")
+	r.OrganizationID = rd.ReferenceID("organization", "Organization")
+	rd.CodeableConcept("code")
+	r.RoleCode = rd.RequiredString("code.coding.code")
+	r.RoleDisplay = rd.String("code.coding.display")
+	r.PeriodStart, r.PeriodEnd = rd.Period("period")
+	return rd.Err()
+}
+
 // strVal safely dereferences a string pointer, returning empty string for nil.
 func strVal(s *string) string {
 	if s == nil {
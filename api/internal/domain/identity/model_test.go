@@ -1,6 +1,7 @@
 package identity
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -415,3 +416,336 @@ func TestPractitioner_ToFHIR_OptionalFieldsNil(t *testing.T) {
 		}
 	}
 }
+
+// -- Patient.FromFHIR Tests --
+
+// fhirPatient returns a minimal valid FHIR Patient with elements added.
+func fhirPatient(elements map[string]interface{}) map[string]interface{} {
+	data := map[string]interface{}{
+		"resourceType": "Patient",
+		"name":         []interface{}{map[string]interface{}{"family": "Doe", "given": []interface{}{"John"}}},
+		"identifier":   []interface{}{map[string]interface{}{"value": "MRN001"}},
+	}
+	for k, v := range elements {
+		data[k] = v
+	}
+	return data
+}
+
+func TestPatientFromFHIR_BasicFields(t *testing.T) {
+	p := &Patient{FirstName: "John", LastName: "Doe", Active: false}
+
+	patched := map[string]interface{}{
+		"active":    true,
+		"gender":    "male",
+		"birthDate": "1990-05-15",
+	}
+	if err := p.FromFHIR(fhirPatient(patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+
+	if p.Active != true {
+		t.Errorf("expected Active=true, got %v", p.Active)
+	}
+	if p.Gender == nil || *p.Gender != "male" {
+		t.Errorf("expected Gender=male, got %v", p.Gender)
+	}
+	if p.BirthDate == nil {
+		t.Fatal("expected BirthDate to be set")
+	}
+	if p.BirthDate.Format("2006-01-02") != "1990-05-15" {
+		t.Errorf("expected BirthDate=1990-05-15, got %s", p.BirthDate.Format("2006-01-02"))
+	}
+	// Unchanged fields
+	if p.FirstName != "John" {
+		t.Errorf("expected FirstName=John, got %s", p.FirstName)
+	}
+	if p.LastName != "Doe" {
+		t.Errorf("expected LastName=Doe, got %s", p.LastName)
+	}
+}
+
+func TestPatientFromFHIR_Name(t *testing.T) {
+	p := &Patient{FirstName: "Old", LastName: "Name"}
+
+	patched := map[string]interface{}{
+		"name": []interface{}{
+			map[string]interface{}{
+				"family": "Smith",
+				"given":  []interface{}{"Jane", "Marie"},
+				"prefix": []interface{}{"Dr."},
+				"suffix": []interface{}{"Jr."},
+			},
+		},
+	}
+	if err := p.FromFHIR(fhirPatient(patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+
+	if p.LastName != "Smith" {
+		t.Errorf("expected LastName=Smith, got %s", p.LastName)
+	}
+	if p.FirstName != "Jane" {
+		t.Errorf("expected FirstName=Jane, got %s", p.FirstName)
+	}
+	if p.MiddleName == nil || *p.MiddleName != "Marie" {
+		t.Errorf("expected MiddleName=Marie, got %v", p.MiddleName)
+	}
+	if p.Prefix == nil || *p.Prefix != "Dr." {
+		t.Errorf("expected Prefix=Dr., got %v", p.Prefix)
+	}
+	if p.Suffix == nil || *p.Suffix != "Jr." {
+		t.Errorf("expected Suffix=Jr., got %v", p.Suffix)
+	}
+}
+
+func TestPatientFromFHIR_Telecom(t *testing.T) {
+	p := &Patient{FirstName: "John", LastName: "Doe"}
+
+	patched := map[string]interface{}{
+		"telecom": []interface{}{
+			map[string]interface{}{
+				"system": "phone",
+				"value":  "555-0100",
+				"use":    "mobile",
+			},
+			map[string]interface{}{
+				"system": "phone",
+				"value":  "555-0101",
+				"use":    "home",
+			},
+			map[string]interface{}{
+				"system": "email",
+				"value":  "john@example.com",
+			},
+		},
+	}
+	if err := p.FromFHIR(fhirPatient(patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+
+	if p.PhoneMobile == nil || *p.PhoneMobile != "555-0100" {
+		t.Errorf("expected PhoneMobile=555-0100, got %v", p.PhoneMobile)
+	}
+	if p.PhoneHome == nil || *p.PhoneHome != "555-0101" {
+		t.Errorf("expected PhoneHome=555-0101, got %v", p.PhoneHome)
+	}
+	if p.Email == nil || *p.Email != "john@example.com" {
+		t.Errorf("expected Email=john@example.com, got %v", p.Email)
+	}
+}
+
+func TestPatientFromFHIR_Address(t *testing.T) {
+	p := &Patient{FirstName: "John", LastName: "Doe"}
+
+	patched := map[string]interface{}{
+		"address": []interface{}{
+			map[string]interface{}{
+				"use":        "home",
+				"line":       []interface{}{"123 Main St", "Apt 4B"},
+				"city":       "Springfield",
+				"district":   "Clark",
+				"state":      "IL",
+				"postalCode": "62701",
+				"country":    "US",
+			},
+		},
+	}
+	if err := p.FromFHIR(fhirPatient(patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+
+	if p.AddressUse == nil || *p.AddressUse != "home" {
+		t.Errorf("expected AddressUse=home, got %v", p.AddressUse)
+	}
+	if p.AddressLine1 == nil || *p.AddressLine1 != "123 Main St" {
+		t.Errorf("expected AddressLine1=123 Main St, got %v", p.AddressLine1)
+	}
+	if p.AddressLine2 == nil || *p.AddressLine2 != "Apt 4B" {
+		t.Errorf("expected AddressLine2=Apt 4B, got %v", p.AddressLine2)
+	}
+	if p.City == nil || *p.City != "Springfield" {
+		t.Errorf("expected City=Springfield, got %v", p.City)
+	}
+	if p.District == nil || *p.District != "Clark" {
+		t.Errorf("expected District=Clark, got %v", p.District)
+	}
+	if p.State == nil || *p.State != "IL" {
+		t.Errorf("expected State=IL, got %v", p.State)
+	}
+	if p.PostalCode == nil || *p.PostalCode != "62701" {
+		t.Errorf("expected PostalCode=62701, got %v", p.PostalCode)
+	}
+	if p.Country == nil || *p.Country != "US" {
+		t.Errorf("expected Country=US, got %v", p.Country)
+	}
+}
+
+func TestPatientFromFHIR_Minimal(t *testing.T) {
+	p := &Patient{FirstName: "John", LastName: "Doe", Active: true}
+
+	patched := map[string]interface{}{}
+	if err := p.FromFHIR(fhirPatient(patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+
+	if p.FirstName != "John" {
+		t.Errorf("expected FirstName=John, got %s", p.FirstName)
+	}
+	if p.LastName != "Doe" {
+		t.Errorf("expected LastName=Doe, got %s", p.LastName)
+	}
+	if p.Active != true {
+		t.Errorf("expected Active=true, got %v", p.Active)
+	}
+	if p.Gender != nil {
+		t.Errorf("expected Gender=nil, got %v", p.Gender)
+	}
+}
+
+// -- Practitioner.FromFHIR Tests --
+
+// fhirPractitioner returns a minimal valid FHIR Practitioner with elements added.
+func fhirPractitioner(elements map[string]interface{}) map[string]interface{} {
+	data := map[string]interface{}{
+		"resourceType": "Practitioner",
+		"name":         []interface{}{map[string]interface{}{"family": "Smith", "given": []interface{}{"Dr"}}},
+	}
+	for k, v := range elements {
+		data[k] = v
+	}
+	return data
+}
+
+func TestPractitionerFromFHIR_BasicFields(t *testing.T) {
+	pr := &Practitioner{FirstName: "Dr", LastName: "Smith", Active: false}
+
+	patched := map[string]interface{}{
+		"active": true,
+		"gender": "female",
+		"name": []interface{}{
+			map[string]interface{}{
+				"family": "Johnson",
+				"given":  []interface{}{"Sarah", "Ann"},
+				"prefix": []interface{}{"Dr."},
+				"suffix": []interface{}{"MD"},
+			},
+		},
+		"telecom": []interface{}{
+			map[string]interface{}{
+				"system": "phone",
+				"value":  "555-9999",
+			},
+			map[string]interface{}{
+				"system": "email",
+				"value":  "sarah@hospital.com",
+			},
+		},
+	}
+	if err := pr.FromFHIR(fhirPractitioner(patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+
+	if pr.Active != true {
+		t.Errorf("expected Active=true, got %v", pr.Active)
+	}
+	if pr.Gender == nil || *pr.Gender != "female" {
+		t.Errorf("expected Gender=female, got %v", pr.Gender)
+	}
+	if pr.LastName != "Johnson" {
+		t.Errorf("expected LastName=Johnson, got %s", pr.LastName)
+	}
+	if pr.FirstName != "Sarah" {
+		t.Errorf("expected FirstName=Sarah, got %s", pr.FirstName)
+	}
+	if pr.MiddleName == nil || *pr.MiddleName != "Ann" {
+		t.Errorf("expected MiddleName=Ann, got %v", pr.MiddleName)
+	}
+	if pr.Prefix == nil || *pr.Prefix != "Dr." {
+		t.Errorf("expected Prefix=Dr., got %v", pr.Prefix)
+	}
+	if pr.Suffix == nil || *pr.Suffix != "MD" {
+		t.Errorf("expected Suffix=MD, got %v", pr.Suffix)
+	}
+	if pr.Phone == nil || *pr.Phone != "555-9999" {
+		t.Errorf("expected Phone=555-9999, got %v", pr.Phone)
+	}
+	if pr.Email == nil || *pr.Email != "sarah@hospital.com" {
+		t.Errorf("expected Email=sarah@hospital.com, got %v", pr.Email)
+	}
+}
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestPatientFromFHIR_RoundTrip(t *testing.T) {
+	orig := &Patient{
+		Active: true, MRN: "MRN-RT", FirstName: "Ana", MiddleName: ptrStr("Maria"), LastName: "Silva",
+		BirthDate: ptrTime(time.Date(1985, 3, 4, 0, 0, 0, 0, time.UTC)), Gender: ptrStr("female"),
+		AbhaID: ptrStr("12-3456"), PhoneMobile: ptrStr("555-1"), Email: ptrStr("ana@example.com"),
+		AddressLine1: ptrStr("1 Main St"), City: ptrStr("Pune"), ManagingOrgID: ptrUUID(uuid.New()),
+		PreferredLanguage: ptrStr("pt"), RaceCode: ptrStr("2106-3"), RaceDisplay: ptrStr("White"),
+		EthnicityCode: ptrStr("2186-5"), BirthSex: ptrStr("F"),
+	}
+	var got Patient
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.MRN != "MRN-RT" || got.FirstName != "Ana" || *got.MiddleName != "Maria" || got.LastName != "Silva" {
+		t.Errorf("name/identifier mismatch: %+v", got)
+	}
+	if *got.AbhaID != "12-3456" || *got.PhoneMobile != "555-1" || *got.Email != "ana@example.com" {
+		t.Errorf("identifier/telecom mismatch: %+v", got)
+	}
+	if !got.BirthDate.Equal(*orig.BirthDate) || *got.Gender != "female" || *got.City != "Pune" {
+		t.Errorf("demographics mismatch: %+v", got)
+	}
+	if *got.ManagingOrgID != *orig.ManagingOrgID || *got.PreferredLanguage != "pt" {
+		t.Errorf("references mismatch: %+v", got)
+	}
+	if *got.RaceCode != "2106-3" || *got.RaceDisplay != "White" || *got.EthnicityCode != "2186-5" || *got.BirthSex != "F" {
+		t.Errorf("extensions mismatch: %+v", got)
+	}
+}
+
+func TestPatientFromFHIR_InvalidElements(t *testing.T) {
+	var p Patient
+	err := p.FromFHIR(map[string]interface{}{
+		"resourceType": "Patient",
+		"name":         []interface{}{map[string]interface{}{"given": []interface{}{"A"}}},
+		"gender":       "x",
+		"birthDate":    "03/04/1985",
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	want := map[string]bool{"Patient.name[0].family": true, "Patient.identifier.value": true, "Patient.gender": true, "Patient.birthDate": true}
+	for _, issue := range pe.Outcome.Issue {
+		delete(want, issue.Expression[0])
+	}
+	if len(want) != 0 {
+		t.Errorf("missing issues for %v in %+v", want, pe.Outcome.Issue)
+	}
+}
+
+func TestPractitionerRoleFromFHIR(t *testing.T) {
+	orig := &PractitionerRole{PractitionerID: uuid.New(), OrganizationID: ptrUUID(uuid.New()), RoleCode: "doctor", RoleDisplay: ptrStr("Doctor"), Active: true}
+	var got PractitionerRole
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.PractitionerID != orig.PractitionerID || *got.OrganizationID != *orig.OrganizationID || got.RoleCode != "doctor" || *got.RoleDisplay != "Doctor" {
+		t.Errorf("unexpected role %+v", got)
+	}
+}
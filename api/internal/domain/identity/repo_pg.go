@@ -110,6 +110,12 @@ func (r *patientRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Patien
 	if err != nil {
 		return nil, err
 	}
+	// As in Search, a read projected to elements without telecom and
+	// address leaves the encrypted contact columns undecrypted.
+	if !fhir.ProjectionFromContext(ctx).IncludesAny("Patient", "telecom", "address") {
+		dropPatientPHI(p)
+		return p, nil
+	}
 	if err := r.decryptPatientPHI(p); err != nil {
 		return nil, fmt.Errorf("patient get by fhir id: %w", err)
 	}
@@ -201,13 +207,16 @@ func (r *patientRepoPG) Search(ctx context.Context, params map[string]string, li
 	if name, ok := params["name"]; ok {
 		qb.Add(fmt.Sprintf("(first_name ILIKE $%d OR last_name ILIKE $%d)", qb.Idx(), qb.Idx()), "%"+name+"%")
 	}
-	qb.ApplyParams(params, patientSearchParams)
+	qb.ApplyParams(ctx, params, patientSearchParams)
 	qb.OrderBy("last_name, first_name")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -215,13 +224,19 @@ func (r *patientRepoPG) Search(ctx context.Context, params map[string]string, li
 	}
 	defer rows.Close()
 
+	// List screens project to a few elements, e.g. _elements=name,birthDate;
+	// the encrypted contact columns are only decrypted if they are returned.
+	decrypt := fhir.ProjectionFromContext(ctx).IncludesAny("Patient", "telecom", "address")
+
 	var patients []*Patient
 	for rows.Next() {
 		p, err := scanPatientRows(rows)
 		if err != nil {
 			return nil, 0, err
 		}
-		if err := r.decryptPatientPHI(p); err != nil {
+		if !decrypt {
+			dropPatientPHI(p)
+		} else if err := r.decryptPatientPHI(p); err != nil {
 			return nil, 0, fmt.Errorf("patient search: %w", err)
 		}
 		patients = append(patients, p)
@@ -375,6 +390,14 @@ func (r *patientRepoPG) encryptPatientPHI(p *Patient) error {
 	return nil
 }
 
+// dropPatientPHI clears the encrypted PHI fields of a Patient that will not
+// be returned, so that ciphertext never reaches a response.
+func dropPatientPHI(p *Patient) {
+	p.SSNHash, p.AadhaarHash = nil, nil
+	p.PhoneHome, p.PhoneMobile, p.PhoneWork, p.Email = nil, nil, nil, nil
+	p.AddressLine1, p.AddressLine2, p.City, p.District, p.State, p.PostalCode = nil, nil, nil, nil, nil, nil
+}
+
 // decryptPatientPHI decrypts all PHI fields on a Patient in place after database retrieval.
 func (r *patientRepoPG) decryptPatientPHI(p *Patient) error {
 	var err error
@@ -678,13 +701,16 @@ func (r *practRepoPG) Search(ctx context.Context, params map[string]string, limi
 	if name, ok := params["name"]; ok {
 		qb.Add(fmt.Sprintf("(first_name ILIKE $%d OR last_name ILIKE $%d)", qb.Idx(), qb.Idx()), "%"+name+"%")
 	}
-	qb.ApplyParams(params, practitionerSearchParams)
+	qb.ApplyParams(ctx, params, practitionerSearchParams)
 	qb.OrderBy("last_name, first_name")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -1014,13 +1040,16 @@ var practitionerRoleSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *practRoleRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*PractitionerRole, int, error) {
 	qb := fhir.NewSearchQuery("practitioner_role", practRoleCols)
-	qb.ApplyParams(params, practitionerRoleSearchParams)
+	qb.ApplyParams(ctx, params, practitionerRoleSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -1044,3 +1073,11 @@ type querier interface {
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("patient", patientSearchParams)
+	r.RegisterTable("practitioner", practitionerSearchParams)
+	r.RegisterTable("practitioner_role", practitionerRoleSearchParams)
+}
@@ -71,6 +71,11 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/ImmunizationRecommendation/:id/_history", h.HistoryRecommendationFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Immunization", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("ImmunizationRecommendation", fhir.ReadOnlyInteractions()...)
+}
+
 // -- Immunization REST Handlers --
 
 func (h *Handler) CreateImmunization(c echo.Context) error {
@@ -251,8 +256,8 @@ func (h *Handler) GetImmunizationFHIR(c echo.Context) error {
 
 func (h *Handler) CreateImmunizationFHIR(c echo.Context) error {
 	var im Immunization
-	if err := c.Bind(&im); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Immunization", "", &im); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateImmunization(c.Request().Context(), &im); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -262,16 +267,14 @@ func (h *Handler) CreateImmunizationFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateImmunizationFHIR(c echo.Context) error {
-	var im Immunization
-	if err := c.Bind(&im); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetImmunizationByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Immunization", c.Param("id")))
 	}
-	im.ID = existing.ID
-	im.FHIRID = existing.FHIRID
+	im := *existing
+	if err := fhir.BindResource(c, "Immunization", existing.FHIRID, &im); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateImmunization(c.Request().Context(), &im); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -295,8 +298,8 @@ func (h *Handler) PatchImmunizationFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Immunization", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateImmunization(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -363,8 +366,8 @@ func (h *Handler) GetRecommendationFHIR(c echo.Context) error {
 
 func (h *Handler) CreateRecommendationFHIR(c echo.Context) error {
 	var r ImmunizationRecommendation
-	if err := c.Bind(&r); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ImmunizationRecommendation", "", &r); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateRecommendation(c.Request().Context(), &r); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -374,16 +377,14 @@ func (h *Handler) CreateRecommendationFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateRecommendationFHIR(c echo.Context) error {
-	var r ImmunizationRecommendation
-	if err := c.Bind(&r); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetRecommendationByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ImmunizationRecommendation", c.Param("id")))
 	}
-	r.ID = existing.ID
-	r.FHIRID = existing.FHIRID
+	r := *existing
+	if err := fhir.BindResource(c, "ImmunizationRecommendation", existing.FHIRID, &r); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateRecommendation(c.Request().Context(), &r); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -407,6 +408,9 @@ func (h *Handler) PatchRecommendationFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ImmunizationRecommendation", ctx.Param("id")))
 		}
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
+		}
 		if err := h.svc.UpdateRecommendation(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 		}
@@ -453,13 +457,13 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string, apply
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "ImmunizationRecommendation":
 		existing, err := h.svc.GetRecommendationByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	default:
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("unsupported resource type for PATCH"))
 	}
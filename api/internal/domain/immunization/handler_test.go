@@ -267,7 +267,7 @@ func TestHandler_GetImmunizationFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateImmunizationFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","vaccine_code":"08","vaccine_display":"Hep B"}`
+	body := `{"resourceType":"Immunization","status":"completed","patient":{"reference":"Patient/` + uuid.New().String() + `"},"vaccineCode":{"coding":[{"system":"http://hl7.org/fhir/sid/cvx","code":"08","display":"Hep B"}]}}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/Immunization", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -105,6 +105,36 @@ func (im *Immunization) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the immunization from a FHIR Immunization resource, the
+// inverse of ToFHIR. The vaccine display falls back to vaccineCode.text.
+func (im *Immunization) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Immunization", data)
+	im.Status = r.CodeValue("status", "completed", "entered-in-error", "not-done")
+	im.PatientID = r.RequiredReferenceID("patient", "Patient")
+	vaccine := r.CodeableConcept("vaccineCode")
+	im.VaccineCodeSystem = vaccine.System
+	im.VaccineCode = r.RequiredString("vaccineCode.coding.code")
+	im.VaccineDisplay = ptrVal(vaccine.Display)
+	if im.VaccineDisplay == "" && r.Has("vaccineCode") {
+		r.Invalid("vaccineCode", "Immunization.vaccineCode requires a coding display or text")
+	}
+	im.PrimarySource = r.BoolValue("primarySource")
+	im.EncounterID = r.ReferenceID("encounter", "Encounter")
+	im.OccurrenceDateTime = r.DateTime("occurrenceDateTime")
+	im.OccurrenceString = r.String("occurrenceString")
+	site := r.CodeableConcept("site")
+	im.SiteCode, im.SiteDisplay = site.Code, site.Display
+	route := r.CodeableConcept("route")
+	im.RouteCode, im.RouteDisplay = route.Code, route.Display
+	dose := r.Quantity("doseQuantity")
+	im.DoseQuantity, im.DoseUnit = dose.Value, dose.Unit
+	im.PerformerID = r.ReferenceID("performer.actor", "Practitioner")
+	im.LotNumber = r.String("lotNumber")
+	im.ExpirationDate = r.DateTime("expirationDate")
+	im.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // ImmunizationRecommendation maps to the immunization_recommendation table.
 type ImmunizationRecommendation struct {
 	ID              uuid.UUID  `db:"id" json:"id"`
@@ -173,6 +203,28 @@ func (r *ImmunizationRecommendation) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the recommendation from a FHIR
+// ImmunizationRecommendation resource, the inverse of ToFHIR. Only the first
+// recommendation is read.
+func (r *ImmunizationRecommendation) FromFHIR(data map[string]interface{}) error {
+	rd := fhir.NewResourceReader("ImmunizationRecommendation", data)
+	r.PatientID = rd.RequiredReferenceID("patient", "Patient")
+	if date := rd.DateTime("date"); date != nil {
+		r.Date = *date
+	}
+	vaccine := rd.CodeableConcept("recommendation.vaccineCode")
+	r.VaccineCode = rd.RequiredString("recommendation.vaccineCode.coding.code")
+	r.VaccineDisplay = ptrVal(vaccine.Display)
+	forecast := rd.CodeableConcept("recommendation.forecastStatus")
+	r.ForecastStatus = rd.RequiredString("recommendation.forecastStatus.coding.code")
+	r.ForecastDisplay = forecast.Display
+	r.DateCriterion = rd.DateTime("recommendation.dateCriterion.value")
+	r.SeriesDoses = rd.Int("recommendation.seriesDosesPositiveInt")
+	r.DoseNumber = rd.Int("recommendation.doseNumberPositiveInt")
+	r.Description = rd.String("recommendation.description")
+	return rd.Err()
+}
+
 func strVal(s *string, defaultVal string) string {
 	if s == nil {
 		return defaultVal
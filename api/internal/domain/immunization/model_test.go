@@ -1,6 +1,7 @@
 package immunization
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -325,3 +326,101 @@ func TestImmunizationRecommendation_ToFHIR_OptionalFieldsNil(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestImmunization_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &Immunization{
+		FHIRID:             "imm-rt",
+		Status:             "completed",
+		PatientID:          uuid.New(),
+		VaccineCode:        "140",
+		VaccineDisplay:     "Influenza, seasonal",
+		OccurrenceDateTime: ptrTime(time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC)),
+		PrimarySource:      true,
+		SiteCode:           ptrStr("LA"),
+		DoseQuantity:       ptrFloat(0.5),
+		DoseUnit:           ptrStr("mL"),
+		PerformerID:        ptrUUID(uuid.New()),
+		LotNumber:          ptrStr("LOT-1"),
+	}
+
+	var got Immunization
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.VaccineCode != "140" || got.VaccineDisplay != "Influenza, seasonal" ||
+		got.VaccineCodeSystem == nil || *got.VaccineCodeSystem != "http://hl7.org/fhir/sid/cvx" {
+		t.Errorf("vaccineCode mismatch: %+v", got)
+	}
+	if got.PatientID != orig.PatientID || *got.PerformerID != *orig.PerformerID || !got.PrimarySource {
+		t.Errorf("patient/performer/primarySource mismatch: %+v", got)
+	}
+	if !got.OccurrenceDateTime.Equal(*orig.OccurrenceDateTime) {
+		t.Errorf("occurrenceDateTime = %v", got.OccurrenceDateTime)
+	}
+	if *got.SiteCode != "LA" || *got.DoseQuantity != 0.5 || *got.DoseUnit != "mL" || *got.LotNumber != "LOT-1" {
+		t.Errorf("site/dose/lot mismatch: %+v", got)
+	}
+}
+
+func TestImmunizationRecommendation_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &ImmunizationRecommendation{
+		PatientID:      uuid.New(),
+		Date:           time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		VaccineCode:    "08",
+		VaccineDisplay: "Hep B",
+		ForecastStatus: "due",
+		DoseNumber:     ptrInt(2),
+	}
+
+	var got ImmunizationRecommendation
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.PatientID != orig.PatientID || !got.Date.Equal(orig.Date) {
+		t.Errorf("patient/date mismatch: %+v", got)
+	}
+	if got.VaccineCode != "08" || got.ForecastStatus != "due" || *got.DoseNumber != 2 {
+		t.Errorf("recommendation mismatch: %+v", got)
+	}
+}
+
+func TestImmunization_FromFHIR_InvalidElements(t *testing.T) {
+	var im Immunization
+	err := im.FromFHIR(map[string]interface{}{
+		"resourceType": "Immunization",
+		"status":       "given",
+		"patient":      map[string]interface{}{"reference": "Patient/" + uuid.New().String()},
+		"vaccineCode":  map[string]interface{}{"coding": []interface{}{map[string]interface{}{"code": "08"}}},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	want := map[string]bool{
+		"Immunization.status":      true,
+		"Immunization.vaccineCode": true,
+	}
+	for _, issue := range pe.Outcome.Issue {
+		delete(want, issue.Expression[0])
+	}
+	if len(want) != 0 {
+		t.Errorf("missing issues for %v in %+v", want, pe.Outcome.Issue)
+	}
+}
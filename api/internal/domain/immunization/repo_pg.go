@@ -151,13 +151,16 @@ var immunizationSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *immunizationRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Immunization, int, error) {
 	qb := fhir.NewSearchQuery("immunization", immCols)
-	qb.ApplyParams(params, immunizationSearchParams)
+	qb.ApplyParams(ctx, params, immunizationSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -295,13 +298,16 @@ var recommendationSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *recommendationRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ImmunizationRecommendation, int, error) {
 	qb := fhir.NewSearchQuery("immunization_recommendation", recCols)
-	qb.ApplyParams(params, recommendationSearchParams)
+	qb.ApplyParams(ctx, params, recommendationSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -318,3 +324,10 @@ func (r *recommendationRepoPG) Search(ctx context.Context, params map[string]str
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("immunization", immunizationSearchParams)
+	r.RegisterTable("immunization_recommendation", recommendationSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/ImmunizationEvaluation/:id/_history", h.HistoryImmunizationEvaluationFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("ImmunizationEvaluation", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST --
 
 func (h *Handler) CreateImmunizationEvaluation(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetImmunizationEvaluationFHIR(c echo.Context) error {
 
 func (h *Handler) CreateImmunizationEvaluationFHIR(c echo.Context) error {
 	var ie ImmunizationEvaluation
-	if err := c.Bind(&ie); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ImmunizationEvaluation", "", &ie); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateImmunizationEvaluation(c.Request().Context(), &ie); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateImmunizationEvaluationFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateImmunizationEvaluationFHIR(c echo.Context) error {
-	var ie ImmunizationEvaluation
-	if err := c.Bind(&ie); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetImmunizationEvaluationByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ImmunizationEvaluation", c.Param("id")))
 	}
-	ie.ID = existing.ID
-	ie.FHIRID = existing.FHIRID
+	ie := *existing
+	if err := fhir.BindResource(c, "ImmunizationEvaluation", existing.FHIRID, &ie); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateImmunizationEvaluation(c.Request().Context(), &ie); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ImmunizationEvaluation", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateImmunizationEvaluation(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
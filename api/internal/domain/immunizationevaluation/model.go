@@ -73,6 +73,28 @@ func (ie *ImmunizationEvaluation) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the evaluation from a FHIR ImmunizationEvaluation
+// resource, the inverse of ToFHIR.
+func (ie *ImmunizationEvaluation) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ImmunizationEvaluation", data)
+	ie.Status = r.CodeValue("status", "completed", "entered-in-error")
+	ie.PatientID = r.RequiredReferenceID("patient", "Patient")
+	ie.TargetDiseaseCode = r.RequiredString("targetDisease.coding.code")
+	ie.TargetDiseaseDisplay = r.CodeableConcept("targetDisease").Display
+	ie.ImmunizationEventRef = r.RequiredString("immunizationEvent.reference")
+	ie.DoseStatusCode = r.RequiredString("doseStatus.coding.code")
+	ie.DoseStatusDisplay = r.CodeableConcept("doseStatus").Display
+	ie.Date = r.DateTime("date")
+	ie.AuthorityReference = r.Reference("authority")
+	reason := r.CodeableConcept("doseStatusReason")
+	ie.DoseStatusReasonCode, ie.DoseStatusReasonDisplay = reason.Code, reason.Display
+	ie.Series = r.String("series")
+	ie.DoseNumber = r.String("doseNumberString")
+	ie.SeriesDoses = r.String("seriesDosesString")
+	ie.Description = r.String("description")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
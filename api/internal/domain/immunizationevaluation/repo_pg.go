@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/ehr/ehr/internal/platform/db"
+	"github.com/ehr/ehr/internal/platform/fhir"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -129,6 +130,11 @@ func (r *ImmunizationEvaluationRepoPG) Search(ctx context.Context, params map[st
 		idx++
 	}
 
+	metaWhere, metaArgs := fhir.MetaSearchSQL("ImmunizationEvaluation", params, idx)
+	where = append(where, metaWhere...)
+	args = append(args, metaArgs...)
+	idx += len(metaArgs)
+
 	whereClause := ""
 	if len(where) > 0 {
 		whereClause = "WHERE " + strings.Join(where, " AND ")
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/ImplementationGuide/:id/_history", h.HistoryImplementationGuideFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("ImplementationGuide", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateImplementationGuide(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetImplementationGuideFHIR(c echo.Context) error {
 
 func (h *Handler) CreateImplementationGuideFHIR(c echo.Context) error {
 	var ig ImplementationGuide
-	if err := c.Bind(&ig); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ImplementationGuide", "", &ig); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateImplementationGuide(c.Request().Context(), &ig); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateImplementationGuideFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateImplementationGuideFHIR(c echo.Context) error {
-	var ig ImplementationGuide
-	if err := c.Bind(&ig); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetImplementationGuideByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ImplementationGuide", c.Param("id")))
 	}
-	ig.ID = existing.ID
-	ig.FHIRID = existing.FHIRID
+	ig := *existing
+	if err := fhir.BindResource(c, "ImplementationGuide", existing.FHIRID, &ig); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateImplementationGuide(c.Request().Context(), &ig); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ImplementationGuide", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateImplementationGuide(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
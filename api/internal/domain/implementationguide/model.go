@@ -79,3 +79,23 @@ func (ig *ImplementationGuide) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the guide from a FHIR ImplementationGuide resource, the
+// inverse of ToFHIR.
+func (ig *ImplementationGuide) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ImplementationGuide", data)
+	ig.URL = r.RequiredString("url")
+	ig.Name = r.RequiredString("name")
+	ig.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	ig.Title = r.String("title")
+	ig.Description = r.String("description")
+	ig.Publisher = r.String("publisher")
+	ig.Date = r.DateTime("date")
+	ig.PackageID = r.String("packageId")
+	ig.FHIRVersion = r.String("fhirVersion")
+	ig.License = r.String("license")
+	ig.DependsOnURI = r.String("dependsOn.uri")
+	ig.GlobalType = r.String("global.type")
+	ig.GlobalProfile = r.String("global.profile")
+	return r.Err()
+}
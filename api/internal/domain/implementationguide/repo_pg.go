@@ -71,7 +71,7 @@ func (r *implementationGuideRepoPG) GetByFHIRID(ctx context.Context, fhirID stri
 func (r *implementationGuideRepoPG) Update(ctx context.Context, ig *ImplementationGuide) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE implementation_guide SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
-			package_id=$9, fhir_version=$10, license=$11, depends_on_uri=$12, global_type=$13, global_profile=$14, updated_at=NOW()
+			package_id=$9, fhir_version=$10, license=$11, depends_on_uri=$12, global_type=$13, global_profile=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ig.ID, ig.Status, ig.URL, ig.Name, ig.Title, ig.Description, ig.Publisher, ig.Date,
 		ig.PackageID, ig.FHIRVersion, ig.License, ig.DependsOnURI, ig.GlobalType, ig.GlobalProfile)
@@ -112,13 +112,16 @@ var implementationGuideSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *implementationGuideRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ImplementationGuide, int, error) {
 	qb := fhir.NewSearchQuery("implementation_guide", igCols)
-	qb.ApplyParams(params, implementationGuideSearchParams)
+	qb.ApplyParams(ctx, params, implementationGuideSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -135,3 +138,9 @@ func (r *implementationGuideRepoPG) Search(ctx context.Context, params map[strin
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("implementation_guide", implementationGuideSearchParams)
+}
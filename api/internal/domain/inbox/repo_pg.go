@@ -64,7 +64,7 @@ func (r *messagePoolRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*Message
 func (r *messagePoolRepoPG) Update(ctx context.Context, p *MessagePool) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE message_pool SET pool_name=$2, pool_type=$3, organization_id=$4, department_id=$5,
-			description=$6, is_active=$7, updated_at=NOW()
+			description=$6, is_active=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		p.ID, p.PoolName, p.PoolType, p.OrganizationID, p.DepartmentID,
 		p.Description, p.IsActive)
@@ -151,7 +151,7 @@ func (r *inboxMessageRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*InboxM
 func (r *inboxMessageRepoPG) Update(ctx context.Context, m *InboxMessage) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE inbox_message SET status=$2, priority=$3, is_urgent=$4, due_date=$5,
-			read_at=$6, completed_at=$7, updated_at=NOW()
+			read_at=$6, completed_at=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.Status, m.Priority, m.IsUrgent, m.DueDate,
 		m.ReadAt, m.CompletedAt)
@@ -215,13 +215,16 @@ var inboxMessageSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *inboxMessageRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*InboxMessage, int, error) {
 	qb := fhir.NewSearchQuery("inbox_message", inboxMsgCols)
-	qb.ApplyParams(params, inboxMessageSearchParams)
+	qb.ApplyParams(ctx, params, inboxMessageSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -594,3 +597,9 @@ func (r *handoffRepoPG) ListByProvider(ctx context.Context, providerID uuid.UUID
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("inbox_message", inboxMessageSearchParams)
+}
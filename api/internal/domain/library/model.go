@@ -73,6 +73,32 @@ func (l *Library) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the library from a FHIR Library resource, the inverse of
+// ToFHIR. Inline content data is decoded from base64.
+func (l *Library) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Library", data)
+	l.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	libType := r.CodeableConcept("type")
+	l.TypeCode = r.RequiredString("type.coding.code")
+	l.TypeDisplay = libType.Display
+	l.URL = r.String("url")
+	l.Name = r.String("name")
+	l.Title = r.String("title")
+	l.Description = r.String("description")
+	l.Publisher = r.String("publisher")
+	l.Date = r.DateTime("date")
+	l.ContentType = r.String("content.contentType")
+	l.ContentData = nil
+	if encoded := r.String("content.data"); encoded != nil {
+		decoded, err := base64.StdEncoding.DecodeString(*encoded)
+		if err != nil {
+			r.Invalid("content.data", "Library.content.data must be base64 encoded")
+		}
+		l.ContentData = decoded
+	}
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -76,7 +76,7 @@ func (r *libraryRepoPG) Update(ctx context.Context, l *Library) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE library SET status=$2, url=$3, name=$4, title=$5,
 			type_code=$6, type_display=$7, description=$8, publisher=$9, date=$10,
-			content_type=$11, content_data=$12, updated_at=NOW()
+			content_type=$11, content_data=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		l.ID, l.Status, l.URL, l.Name, l.Title,
 		l.TypeCode, l.TypeDisplay, l.Description, l.Publisher, l.Date,
@@ -120,13 +120,16 @@ var librarySearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *libraryRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Library, int, error) {
 	qb := fhir.NewSearchQuery("library", libCols)
-	qb.ApplyParams(params, librarySearchParams)
+	qb.ApplyParams(ctx, params, librarySearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -143,3 +146,9 @@ func (r *libraryRepoPG) Search(ctx context.Context, params map[string]string, li
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("library", librarySearchParams)
+}
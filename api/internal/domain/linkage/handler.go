@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Linkage/:id/_history", h.HistoryLinkageFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Linkage", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateLinkage(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetLinkageFHIR(c echo.Context) error {
 
 func (h *Handler) CreateLinkageFHIR(c echo.Context) error {
 	var l Linkage
-	if err := c.Bind(&l); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Linkage", "", &l); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateLinkage(c.Request().Context(), &l); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateLinkageFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateLinkageFHIR(c echo.Context) error {
-	var l Linkage
-	if err := c.Bind(&l); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetLinkageByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Linkage", c.Param("id")))
 	}
-	l.ID = existing.ID
-	l.FHIRID = existing.FHIRID
+	l := *existing
+	if err := fhir.BindResource(c, "Linkage", existing.FHIRID, &l); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateLinkage(c.Request().Context(), &l); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Linkage", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["active"].(bool); ok {
-		existing.Active = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateLinkage(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -55,3 +55,27 @@ func (l *Linkage) ToFHIR() map[string]interface{} {
 	result["item"] = items
 	return result
 }
+
+// FromFHIR populates the linkage from a FHIR Linkage resource, the inverse of
+// ToFHIR. The first source and alternate items are read.
+func (l *Linkage) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Linkage", data)
+	l.Active = true
+	if active := r.Bool("active"); active != nil {
+		l.Active = *active
+	}
+	l.AuthorID = r.ReferenceID("author", "Practitioner")
+	isType := func(t string) func(string) bool {
+		return func(item string) bool { return r.StringValue(item+".type") == t }
+	}
+	if src, ok := r.Find("item", isType("source")); ok {
+		l.SourceType, l.SourceReference = r.RequiredReferenceTarget(src + ".resource")
+	} else {
+		r.Invalid("item", "Linkage.item must contain an item of type source")
+	}
+	l.AlternateType, l.AlternateReference = nil, nil
+	if alt, ok := r.Find("item", isType("alternate")); ok {
+		l.AlternateType, l.AlternateReference = r.ReferenceTarget(alt + ".resource")
+	}
+	return r.Err()
+}
@@ -71,7 +71,7 @@ func (r *linkageRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Linkag
 func (r *linkageRepoPG) Update(ctx context.Context, l *Linkage) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE linkage SET active=$2, author_id=$3, source_type=$4, source_reference=$5,
-			alternate_type=$6, alternate_reference=$7, updated_at=NOW()
+			alternate_type=$6, alternate_reference=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		l.ID, l.Active, l.AuthorID, l.SourceType, l.SourceReference,
 		l.AlternateType, l.AlternateReference)
@@ -111,13 +111,16 @@ var linkageSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *linkageRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Linkage, int, error) {
 	qb := fhir.NewSearchQuery("linkage", lnkCols)
-	qb.ApplyParams(params, linkageSearchParams)
+	qb.ApplyParams(ctx, params, linkageSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -134,3 +137,9 @@ func (r *linkageRepoPG) Search(ctx context.Context, params map[string]string, li
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("linkage", linkageSearchParams)
+}
@@ -82,6 +82,27 @@ func (m *Measure) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the measure from a FHIR Measure resource, the inverse of
+// ToFHIR.
+func (m *Measure) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Measure", data)
+	m.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	m.URL = r.String("url")
+	m.Name = r.String("name")
+	m.Title = r.String("title")
+	m.Description = r.String("description")
+	m.Publisher = r.String("publisher")
+	m.Date = r.DateTime("date")
+	m.EffectivePeriodStart, m.EffectivePeriodEnd = r.Period("effectivePeriod")
+	scoring := r.CodeableConcept("scoring")
+	m.ScoringCode, m.ScoringDisplay = scoring.Code, scoring.Display
+	subject := r.CodeableConcept("subject")
+	m.SubjectCode, m.SubjectDisplay = subject.Code, subject.Display
+	m.ApprovalDate = r.DateTime("approvalDate")
+	m.LastReviewDate = r.DateTime("lastReviewDate")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -81,7 +81,7 @@ func (r *measureRepoPG) Update(ctx context.Context, m *Measure) error {
 		UPDATE measure SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
 			effective_period_start=$9, effective_period_end=$10,
 			scoring_code=$11, scoring_display=$12, subject_code=$13, subject_display=$14,
-			approval_date=$15, last_review_date=$16, updated_at=NOW()
+			approval_date=$15, last_review_date=$16, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.Status, m.URL, m.Name, m.Title, m.Description, m.Publisher, m.Date,
 		m.EffectivePeriodStart, m.EffectivePeriodEnd,
@@ -125,13 +125,16 @@ var measureSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *measureRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Measure, int, error) {
 	qb := fhir.NewSearchQuery("measure", measureCols)
-	qb.ApplyParams(params, measureSearchParams)
+	qb.ApplyParams(ctx, params, measureSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -148,3 +151,9 @@ func (r *measureRepoPG) Search(ctx context.Context, params map[string]string, li
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("measure", measureSearchParams)
+}
@@ -50,6 +50,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/MeasureReport/:id/_history", h.HistoryMeasureReportFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("MeasureReport", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateMeasureReport(c echo.Context) error {
@@ -139,8 +143,8 @@ func (h *Handler) GetMeasureReportFHIR(c echo.Context) error {
 
 func (h *Handler) CreateMeasureReportFHIR(c echo.Context) error {
 	var mr MeasureReport
-	if err := c.Bind(&mr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "MeasureReport", "", &mr); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateMeasureReport(c.Request().Context(), &mr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -150,16 +154,14 @@ func (h *Handler) CreateMeasureReportFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateMeasureReportFHIR(c echo.Context) error {
-	var mr MeasureReport
-	if err := c.Bind(&mr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetMeasureReportByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("MeasureReport", c.Param("id")))
 	}
-	mr.ID = existing.ID
-	mr.FHIRID = existing.FHIRID
+	mr := *existing
+	if err := fhir.BindResource(c, "MeasureReport", existing.FHIRID, &mr); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateMeasureReport(c.Request().Context(), &mr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -216,7 +218,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("MeasureReport", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -242,8 +244,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateMeasureReport(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -87,3 +87,23 @@ func (mr *MeasureReport) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the report from a FHIR MeasureReport resource, the
+// inverse of ToFHIR. Only the first group and population are read.
+func (mr *MeasureReport) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MeasureReport", data)
+	mr.Status = r.CodeValue("status", "complete", "pending", "error")
+	mr.Type = r.CodeValue("type", "individual", "subject-list", "summary", "data-collection")
+	mr.PeriodStart = r.RequiredDateTime("period.start")
+	mr.PeriodEnd = r.RequiredDateTime("period.end")
+	mr.MeasureURL = r.String("measure")
+	mr.SubjectPatientID = r.ReferenceID("subject", "Patient")
+	mr.Date = r.DateTime("date")
+	mr.ReporterOrgID = r.ReferenceID("reporter", "Organization")
+	mr.ImprovementNotation = r.ConceptCode("improvementNotation")
+	mr.GroupCode = r.ConceptCode("group.code")
+	mr.GroupPopulationCode = r.ConceptCode("group.population.code")
+	mr.GroupPopulationCount = r.Int("group.population.count")
+	mr.GroupMeasureScore = r.Decimal("group.measureScore.value")
+	return r.Err()
+}
@@ -83,7 +83,7 @@ func (r *measureReportRepoPG) Update(ctx context.Context, mr *MeasureReport) err
 		UPDATE measure_report SET status=$2, type=$3, measure_url=$4,
 			period_start=$5, period_end=$6, improvement_notation=$7,
 			group_code=$8, group_population_code=$9, group_population_count=$10,
-			group_measure_score=$11, updated_at=NOW()
+			group_measure_score=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		mr.ID, mr.Status, mr.Type, mr.MeasureURL,
 		mr.PeriodStart, mr.PeriodEnd, mr.ImprovementNotation,
@@ -125,13 +125,16 @@ var measureReportSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *measureReportRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MeasureReport, int, error) {
 	qb := fhir.NewSearchQuery("measure_report", mrCols)
-	qb.ApplyParams(params, measureReportSearchParams)
+	qb.ApplyParams(ctx, params, measureReportSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -148,3 +151,9 @@ func (r *measureReportRepoPG) Search(ctx context.Context, params map[string]stri
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("measure_report", measureReportSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Media/:id/_history", h.HistoryMediaFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Media", fhir.ReadOnlyInteractions()...)
+}
+
 func (h *Handler) CreateMedia(c echo.Context) error {
 	var m Media
 	if err := c.Bind(&m); err != nil {
@@ -133,8 +137,8 @@ func (h *Handler) GetMediaFHIR(c echo.Context) error {
 
 func (h *Handler) CreateMediaFHIR(c echo.Context) error {
 	var m Media
-	if err := c.Bind(&m); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Media", "", &m); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateMedia(c.Request().Context(), &m); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -144,16 +148,14 @@ func (h *Handler) CreateMediaFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateMediaFHIR(c echo.Context) error {
-	var m Media
-	if err := c.Bind(&m); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetMediaByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Media", c.Param("id")))
 	}
-	m.ID = existing.ID
-	m.FHIRID = existing.FHIRID
+	m := *existing
+	if err := fhir.BindResource(c, "Media", existing.FHIRID, &m); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateMedia(c.Request().Context(), &m); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -209,7 +211,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Media", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -233,8 +235,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateMedia(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -114,6 +114,36 @@ func (m *Media) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the media from a FHIR Media resource, the inverse of
+// ToFHIR.
+func (m *Media) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Media", data)
+	m.Status = r.CodeValue("status", "preparation", "in-progress", "not-done", "on-hold",
+		"stopped", "completed", "entered-in-error", "unknown")
+	mediaType := r.CodeableConcept("type")
+	m.TypeCode, m.TypeDisplay = mediaType.Code, mediaType.Display
+	modality := r.CodeableConcept("modality")
+	m.ModalityCode, m.ModalityDisplay = modality.Code, modality.Display
+	m.SubjectPatientID = r.ReferenceID("subject", "Patient")
+	m.EncounterID = r.ReferenceID("encounter", "Encounter")
+	m.CreatedDate = r.DateTime("createdDateTime")
+	m.OperatorID = r.ReferenceID("operator", "Practitioner")
+	m.ReasonCode = r.ConceptCode("reasonCode")
+	bodySite := r.CodeableConcept("bodySite")
+	m.BodySiteCode, m.BodySiteDisplay = bodySite.Code, bodySite.Display
+	m.DeviceName = r.String("deviceName")
+	m.Height = r.Int("height")
+	m.Width = r.Int("width")
+	m.Frames = r.Int("frames")
+	m.Duration = r.Decimal("duration")
+	m.ContentType = r.String("content.contentType")
+	m.ContentURL = r.String("content.url")
+	m.ContentSize = r.Int("content.size")
+	m.ContentTitle = r.String("content.title")
+	m.Note = r.Annotation("note")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
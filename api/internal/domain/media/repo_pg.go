@@ -92,7 +92,7 @@ func (r *mediaRepoPG) Update(ctx context.Context, m *Media) error {
 			reason_code=$11, body_site_code=$12, body_site_display=$13, device_name=$14,
 			height=$15, width=$16, frames=$17, duration=$18,
 			content_type=$19, content_url=$20, content_size=$21, content_title=$22,
-			note=$23, updated_at=NOW()
+			note=$23, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.Status, m.TypeCode, m.TypeDisplay,
 		m.ModalityCode, m.ModalityDisplay,
@@ -137,13 +137,16 @@ var mediaSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *mediaRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Media, int, error) {
 	qb := fhir.NewSearchQuery("media", mediaCols)
-	qb.ApplyParams(params, mediaSearchParams)
+	qb.ApplyParams(ctx, params, mediaSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -160,3 +163,9 @@ func (r *mediaRepoPG) Search(ctx context.Context, params map[string]string, limi
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("media", mediaSearchParams)
+}
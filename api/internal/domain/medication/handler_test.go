@@ -682,7 +682,7 @@ func TestHandler_GetMedicationFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateMedicationFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"code_value":"12345","code_display":"Aspirin"}`
+	body := `{"resourceType":"Medication","code":{"coding":[{"code":"12345","display":"Aspirin"}]}}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/Medication", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -704,7 +704,7 @@ func TestHandler_UpdateMedicationFHIR(t *testing.T) {
 	m := &Medication{CodeValue: "12345", CodeDisplay: "Aspirin"}
 	h.svc.CreateMedication(nil, m)
 
-	body := `{"code_value":"12345","code_display":"Aspirin 500mg"}`
+	body := `{"resourceType":"Medication","code":{"coding":[{"code":"12345","display":"Aspirin 500mg"}]}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -837,7 +837,7 @@ func TestHandler_GetMedicationRequestFHIR(t *testing.T) {
 
 func TestHandler_CreateMedicationRequestFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","medication_id":"` + uuid.New().String() + `","requester_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"MedicationRequest","subject":{"reference":"Patient/` + uuid.New().String() + `"},"medicationReference":{"reference":"Medication/` + uuid.New().String() + `"},"requester":{"reference":"Practitioner/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/MedicationRequest", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -856,7 +856,7 @@ func TestHandler_UpdateMedicationRequestFHIR(t *testing.T) {
 	mr := &MedicationRequest{PatientID: uuid.New(), MedicationID: uuid.New(), RequesterID: uuid.New()}
 	h.svc.CreateMedicationRequest(nil, mr)
 
-	body := `{"status":"active"}`
+	body := `{"resourceType":"MedicationRequest","status":"active","subject":{"reference":"Patient/` + mr.PatientID.String() + `"},"medicationReference":{"reference":"Medication/` + mr.MedicationID.String() + `"},"requester":{"reference":"Practitioner/` + mr.RequesterID.String() + `"}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -968,7 +968,7 @@ func TestHandler_GetMedicationAdministrationFHIR(t *testing.T) {
 
 func TestHandler_CreateMedicationAdministrationFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","medication_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"MedicationAdministration","subject":{"reference":"Patient/` + uuid.New().String() + `"},"medicationReference":{"reference":"Medication/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/MedicationAdministration", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -1078,7 +1078,7 @@ func TestHandler_GetMedicationDispenseFHIR(t *testing.T) {
 
 func TestHandler_CreateMedicationDispenseFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","medication_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"MedicationDispense","subject":{"reference":"Patient/` + uuid.New().String() + `"},"medicationReference":{"reference":"Medication/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/MedicationDispense", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -1180,9 +1180,9 @@ func TestHandler_RegisterRoutes(t *testing.T) {
 	}
 }
 
-// -- applyMedicationPatch unit tests --
+// -- Medication.FromFHIR (PATCH) tests --
 
-func TestApplyMedicationPatch_Status(t *testing.T) {
+func TestMedication_FromFHIR_PatchStatus(t *testing.T) {
 	m := &Medication{
 		Status:      "active",
 		CodeValue:   "12345",
@@ -1192,7 +1192,9 @@ func TestApplyMedicationPatch_Status(t *testing.T) {
 	patched := map[string]interface{}{
 		"status": "inactive",
 	}
-	applyMedicationPatch(m, patched)
+	if err := m.FromFHIR(applyPatch(t, medicationToFHIR(m), patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
 
 	if m.Status != "inactive" {
 		t.Errorf("expected status 'inactive', got %q", m.Status)
@@ -1205,7 +1207,7 @@ func TestApplyMedicationPatch_Status(t *testing.T) {
 	}
 }
 
-func TestApplyMedicationPatch_Code(t *testing.T) {
+func TestMedication_FromFHIR_PatchCode(t *testing.T) {
 	m := &Medication{
 		Status:      "active",
 		CodeValue:   "12345",
@@ -1223,7 +1225,9 @@ func TestApplyMedicationPatch_Code(t *testing.T) {
 			},
 		},
 	}
-	applyMedicationPatch(m, patched)
+	if err := m.FromFHIR(applyPatch(t, medicationToFHIR(m), patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
 
 	if m.CodeValue != "67890" {
 		t.Errorf("expected CodeValue '67890', got %q", m.CodeValue)
@@ -1239,9 +1243,9 @@ func TestApplyMedicationPatch_Code(t *testing.T) {
 	}
 }
 
-// -- applyMedicationRequestPatch unit tests --
+// -- MedicationRequest.FromFHIR (PATCH) tests --
 
-func TestApplyMedicationRequestPatch_StatusIntent(t *testing.T) {
+func TestMedicationRequest_FromFHIR_PatchStatusIntent(t *testing.T) {
 	mr := &MedicationRequest{
 		Status:       "draft",
 		Intent:       "proposal",
@@ -1255,7 +1259,9 @@ func TestApplyMedicationRequestPatch_StatusIntent(t *testing.T) {
 		"intent":   "order",
 		"priority": "urgent",
 	}
-	applyMedicationRequestPatch(mr, patched)
+	if err := mr.FromFHIR(applyPatch(t, mr.ToFHIR(), patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
 
 	if mr.Status != "active" {
 		t.Errorf("expected status 'active', got %q", mr.Status)
@@ -1268,7 +1274,7 @@ func TestApplyMedicationRequestPatch_StatusIntent(t *testing.T) {
 	}
 }
 
-func TestApplyMedicationRequestPatch_DosageInstruction(t *testing.T) {
+func TestMedicationRequest_FromFHIR_PatchDosageInstruction(t *testing.T) {
 	mr := &MedicationRequest{
 		Status:       "active",
 		Intent:       "order",
@@ -1301,7 +1307,9 @@ func TestApplyMedicationRequestPatch_DosageInstruction(t *testing.T) {
 			},
 		},
 	}
-	applyMedicationRequestPatch(mr, patched)
+	if err := mr.FromFHIR(applyPatch(t, mr.ToFHIR(), patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
 
 	if mr.DosageText == nil || *mr.DosageText != "Take 1 tablet daily" {
 		t.Errorf("expected DosageText 'Take 1 tablet daily', got %v", mr.DosageText)
@@ -1326,9 +1334,9 @@ func TestApplyMedicationRequestPatch_DosageInstruction(t *testing.T) {
 	}
 }
 
-// -- applyMedicationAdministrationPatch unit tests --
+// -- MedicationAdministration.FromFHIR (PATCH) tests --
 
-func TestApplyMedicationAdministrationPatch_Status(t *testing.T) {
+func TestMedicationAdministration_FromFHIR_PatchStatus(t *testing.T) {
 	ma := &MedicationAdministration{
 		Status:       "in-progress",
 		PatientID:    uuid.New(),
@@ -1354,7 +1362,9 @@ func TestApplyMedicationAdministrationPatch_Status(t *testing.T) {
 			},
 		},
 	}
-	applyMedicationAdministrationPatch(ma, patched)
+	if err := ma.FromFHIR(applyPatch(t, ma.ToFHIR(), patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
 
 	if ma.Status != "completed" {
 		t.Errorf("expected status 'completed', got %q", ma.Status)
@@ -1379,9 +1389,9 @@ func TestApplyMedicationAdministrationPatch_Status(t *testing.T) {
 	}
 }
 
-// -- applyMedicationDispensePatch unit tests --
+// -- MedicationDispense.FromFHIR (PATCH) tests --
 
-func TestApplyMedicationDispensePatch_Status(t *testing.T) {
+func TestMedicationDispense_FromFHIR_PatchStatus(t *testing.T) {
 	md := &MedicationDispense{
 		Status:       "preparation",
 		PatientID:    uuid.New(),
@@ -1420,7 +1430,9 @@ func TestApplyMedicationDispensePatch_Status(t *testing.T) {
 			},
 		},
 	}
-	applyMedicationDispensePatch(md, patched)
+	if err := md.FromFHIR(applyPatch(t, md.ToFHIR(), patched)); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
 
 	if md.Status != "completed" {
 		t.Errorf("expected status 'completed', got %q", md.Status)
@@ -1453,3 +1465,21 @@ func TestApplyMedicationDispensePatch_Status(t *testing.T) {
 		t.Errorf("expected Note 'Dispensed at pharmacy', got %v", md.Note)
 	}
 }
+
+// applyPatch overlays the top-level elements of patch onto a JSON round trip
+// of current, mirroring what a merge patch produces in the PATCH handlers.
+func applyPatch(t *testing.T, current, patch map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(current)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var resource map[string]interface{}
+	if err := json.Unmarshal(raw, &resource); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	for k, v := range patch {
+		resource[k] = v
+	}
+	return resource
+}
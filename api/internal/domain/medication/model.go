@@ -43,6 +43,28 @@ type Medication struct {
 	UpdatedAt               time.Time  `db:"updated_at" json:"updated_at"`
 }
 
+// FromFHIR populates the medication from a FHIR Medication resource, the
+// inverse of medicationToFHIR. The manufacturer display and batch are also
+// accepted, but left unchanged when absent since they are not rendered.
+func (m *Medication) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Medication", data)
+	m.Status = r.CodeValue("status", "active", "inactive", "entered-in-error")
+	code := r.CodeableConcept("code")
+	m.CodeSystem = code.System
+	m.CodeValue = r.RequiredString("code.coding.code")
+	m.CodeDisplay = strVal(code.Display)
+	form := r.CodeableConcept("form")
+	m.FormCode, m.FormDisplay = form.Code, form.Display
+	if r.Has("manufacturer") {
+		m.ManufacturerName = r.String("manufacturer.display")
+	}
+	if r.Has("batch") {
+		m.LotNumber = r.String("batch.lotNumber")
+		m.ExpirationDate = r.DateTime("batch.expirationDate")
+	}
+	return r.Err()
+}
+
 // MedicationIngredient maps to the medication_ingredient table.
 type MedicationIngredient struct {
 	ID                      uuid.UUID `db:"id" json:"id"`
@@ -237,6 +259,44 @@ func (mr *MedicationRequest) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the request from a FHIR MedicationRequest resource, the
+// inverse of ToFHIR.
+func (mr *MedicationRequest) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicationRequest", data)
+	mr.Status = r.CodeValue("status", "active", "on-hold", "cancelled", "completed",
+		"entered-in-error", "stopped", "draft", "unknown")
+	mr.Intent = r.CodeValue("intent", "proposal", "plan", "order", "original-order",
+		"reflex-order", "filler-order", "instance-order", "option")
+	mr.MedicationID = r.RequiredReferenceID("medicationReference", "Medication")
+	mr.PatientID = r.RequiredReferenceID("subject", "Patient")
+	mr.RequesterID = r.RequiredReferenceID("requester", "Practitioner")
+	category := r.CodeableConcept("category")
+	mr.CategoryCode, mr.CategoryDisplay = category.Code, category.Display
+	mr.Priority = r.Code("priority", "routine", "urgent", "asap", "stat")
+	mr.EncounterID = r.ReferenceID("encounter", "Encounter")
+	mr.AuthoredOn = r.DateTime("authoredOn")
+	reason := r.CodeableConcept("reasonCode")
+	mr.ReasonCode, mr.ReasonDisplay = reason.Code, reason.Display
+
+	mr.DosageText = r.String("dosageInstruction.text")
+	timing := r.CodeableConcept("dosageInstruction.timing.code")
+	mr.DosageTimingCode, mr.DosageTimingDisplay = timing.Code, timing.Display
+	route := r.CodeableConcept("dosageInstruction.route")
+	mr.DosageRouteCode, mr.DosageRouteDisplay = route.Code, route.Display
+	dose := r.Quantity("dosageInstruction.doseAndRate.doseQuantity")
+	mr.DoseQuantity, mr.DoseUnit = dose.Value, dose.Unit
+	mr.AsNeeded = r.Bool("dosageInstruction.asNeededBoolean")
+
+	quantity := r.Quantity("dispenseRequest.quantity")
+	mr.QuantityValue, mr.QuantityUnit = quantity.Value, quantity.Unit
+	mr.DaysSupply = r.Int("dispenseRequest.expectedSupplyDuration.value")
+	mr.RefillsAllowed = r.Int("dispenseRequest.numberOfRepeatsAllowed")
+	mr.ValidityStart, mr.ValidityEnd = r.Period("dispenseRequest.validityPeriod")
+	mr.SubstitutionAllowed = r.Bool("substitution.allowedBoolean")
+	mr.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // MedicationAdministration maps to the medication_administration table (FHIR MedicationAdministration).
 type MedicationAdministration struct {
 	ID                    uuid.UUID  `db:"id" json:"id"`
@@ -371,6 +431,39 @@ func (ma *MedicationAdministration) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the administration from a FHIR MedicationAdministration
+// resource, the inverse of ToFHIR.
+func (ma *MedicationAdministration) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicationAdministration", data)
+	ma.Status = r.CodeValue("status", "in-progress", "not-done", "on-hold", "completed",
+		"entered-in-error", "stopped", "unknown")
+	ma.MedicationID = r.RequiredReferenceID("medicationReference", "Medication")
+	ma.PatientID = r.RequiredReferenceID("subject", "Patient")
+	category := r.CodeableConcept("category")
+	ma.CategoryCode, ma.CategoryDisplay = category.Code, category.Display
+	ma.EncounterID = r.ReferenceID("context", "Encounter")
+	ma.MedicationRequestID = r.ReferenceID("request", "MedicationRequest")
+	ma.PerformerID = r.ReferenceID("performer.actor", "Practitioner")
+	role := r.CodeableConcept("performer.function")
+	ma.PerformerRoleCode, ma.PerformerRoleDisplay = role.Code, role.Display
+	ma.EffectiveDatetime = r.DateTime("effectiveDateTime")
+	ma.EffectiveStart, ma.EffectiveEnd = r.Period("effectivePeriod")
+	reason := r.CodeableConcept("reasonCode")
+	ma.ReasonCode, ma.ReasonDisplay = reason.Code, reason.Display
+
+	ma.DosageText = r.String("dosage.text")
+	route := r.CodeableConcept("dosage.route")
+	ma.DosageRouteCode, ma.DosageRouteDisplay = route.Code, route.Display
+	site := r.CodeableConcept("dosage.site")
+	ma.DosageSiteCode, ma.DosageSiteDisplay = site.Code, site.Display
+	dose := r.Quantity("dosage.dose")
+	ma.DoseQuantity, ma.DoseUnit = dose.Value, dose.Unit
+	rate := r.Quantity("dosage.rateQuantity")
+	ma.RateQuantity, ma.RateUnit = rate.Value, rate.Unit
+	ma.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // MedicationDispense maps to the medication_dispense table (FHIR MedicationDispense).
 type MedicationDispense struct {
 	ID                    uuid.UUID  `db:"id" json:"id"`
@@ -489,6 +582,33 @@ func (md *MedicationDispense) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the dispense from a FHIR MedicationDispense resource, the
+// inverse of ToFHIR.
+func (md *MedicationDispense) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicationDispense", data)
+	md.Status = r.CodeValue("status", "preparation", "in-progress", "cancelled", "on-hold",
+		"completed", "entered-in-error", "stopped", "declined", "unknown")
+	md.MedicationID = r.RequiredReferenceID("medicationReference", "Medication")
+	md.PatientID = r.RequiredReferenceID("subject", "Patient")
+	category := r.CodeableConcept("category")
+	md.CategoryCode, md.CategoryDisplay = category.Code, category.Display
+	md.EncounterID = r.ReferenceID("context", "Encounter")
+	md.MedicationRequestID = r.ReferenceID("authorizingPrescription", "MedicationRequest")
+	md.PerformerID = r.ReferenceID("performer.actor", "Practitioner")
+	md.LocationID = r.ReferenceID("location", "Location")
+	quantity := r.Quantity("quantity")
+	md.QuantityValue, md.QuantityUnit = quantity.Value, quantity.Unit
+	md.DaysSupply = r.Int("daysSupply.value")
+	md.WhenPrepared = r.DateTime("whenPrepared")
+	md.WhenHandedOver = r.DateTime("whenHandedOver")
+	md.DestinationID = r.ReferenceID("destination", "Location")
+	md.WasSubstituted = r.Bool("substitution.wasSubstituted")
+	md.SubstitutionTypeCode = r.CodeableConcept("substitution.type").Code
+	md.SubstitutionReason = r.String("substitution.reason.text")
+	md.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // MedicationStatement maps to the medication_statement table (FHIR MedicationStatement).
 type MedicationStatement struct {
 	ID                    uuid.UUID  `db:"id" json:"id"`
@@ -610,6 +730,39 @@ func (ms *MedicationStatement) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the statement from a FHIR MedicationStatement resource,
+// the inverse of ToFHIR.
+func (ms *MedicationStatement) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicationStatement", data)
+	ms.Status = r.CodeValue("status", "active", "completed", "entered-in-error", "intended",
+		"stopped", "on-hold", "unknown", "not-taken")
+	ms.PatientID = r.RequiredReferenceID("subject", "Patient")
+	ms.MedicationID = r.ReferenceID("medicationReference", "Medication")
+	medication := r.CodeableConcept("medicationCodeableConcept")
+	ms.MedicationCode, ms.MedicationDisplay = medication.Code, medication.Display
+	category := r.CodeableConcept("category")
+	ms.CategoryCode, ms.CategoryDisplay = category.Code, category.Display
+	ms.EncounterID = r.ReferenceID("context", "Encounter")
+	ms.InformationSourceID = r.ReferenceID("informationSource", "Patient")
+	ms.EffectiveDatetime = r.DateTime("effectiveDateTime")
+	ms.EffectiveStart, ms.EffectiveEnd = r.Period("effectivePeriod")
+	ms.DateAsserted = r.DateTime("dateAsserted")
+	reason := r.CodeableConcept("reasonCode")
+	ms.ReasonCode, ms.ReasonDisplay = reason.Code, reason.Display
+	statusReason := r.CodeableConcept("statusReason")
+	ms.StatusReasonCode, ms.StatusReasonDisplay = statusReason.Code, statusReason.Display
+
+	ms.DosageText = r.String("dosage.text")
+	route := r.CodeableConcept("dosage.route")
+	ms.DosageRouteCode, ms.DosageRouteDisplay = route.Code, route.Display
+	dose := r.Quantity("dosage.doseAndRate.doseQuantity")
+	ms.DoseQuantity, ms.DoseUnit = dose.Value, dose.Unit
+	timing := r.CodeableConcept("dosage.timing.code")
+	ms.DosageTimingCode, ms.DosageTimingDisplay = timing.Code, timing.Display
+	ms.Note = r.Annotation("note")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
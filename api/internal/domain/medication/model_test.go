@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ehr/ehr/internal/platform/fhir"
 	"github.com/google/uuid"
 )
 
@@ -628,3 +629,116 @@ func TestMedicationJSONMarshal_OmitsNilOptionalFields(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestMedicationRequest_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &MedicationRequest{
+		FHIRID:              "mr-rt",
+		Status:              "active",
+		Intent:              "order",
+		Priority:            ptrStr("urgent"),
+		MedicationID:        uuid.New(),
+		PatientID:           uuid.New(),
+		RequesterID:         uuid.New(),
+		EncounterID:         ptrUUID(uuid.New()),
+		CategoryCode:        ptrStr("outpatient"),
+		DosageText:          ptrStr("1 tab daily"),
+		DosageTimingCode:    ptrStr("QD"),
+		DosageRouteCode:     ptrStr("26643006"),
+		DoseQuantity:        ptrFloat(500),
+		DoseUnit:            ptrStr("mg"),
+		AsNeeded:            ptrBool(false),
+		QuantityValue:       ptrFloat(30),
+		QuantityUnit:        ptrStr("tablets"),
+		DaysSupply:          ptrInt(30),
+		RefillsAllowed:      ptrInt(2),
+		SubstitutionAllowed: ptrBool(true),
+		AuthoredOn:          ptrTime(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+		PriorAuthNumber:     ptrStr("PA-1"),
+	}
+
+	got := MedicationRequest{PriorAuthNumber: orig.PriorAuthNumber}
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Status != "active" || got.Intent != "order" || *got.Priority != "urgent" {
+		t.Errorf("status/intent/priority mismatch: %+v", got)
+	}
+	if got.MedicationID != orig.MedicationID || got.PatientID != orig.PatientID ||
+		got.RequesterID != orig.RequesterID || *got.EncounterID != *orig.EncounterID {
+		t.Errorf("references mismatch: %+v", got)
+	}
+	if *got.DosageText != "1 tab daily" || *got.DosageTimingCode != "QD" || *got.DosageRouteCode != "26643006" ||
+		*got.DoseQuantity != 500 || *got.DoseUnit != "mg" || *got.AsNeeded {
+		t.Errorf("dosage mismatch: %+v", got)
+	}
+	if *got.QuantityValue != 30 || *got.DaysSupply != 30 || *got.RefillsAllowed != 2 || !*got.SubstitutionAllowed {
+		t.Errorf("dispense request mismatch: %+v", got)
+	}
+	if !got.AuthoredOn.Equal(*orig.AuthoredOn) || got.PriorAuthNumber == nil {
+		t.Errorf("authoredOn/prior auth mismatch: %+v", got)
+	}
+}
+
+func TestMedicationStatement_FromFHIR_MedicationCodeableConcept(t *testing.T) {
+	orig := &MedicationStatement{
+		Status:         "active",
+		PatientID:      uuid.New(),
+		MedicationCode: ptrStr("1191"),
+		EffectiveStart: ptrTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+
+	var got MedicationStatement
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.MedicationID != nil || got.MedicationCode == nil || *got.MedicationCode != "1191" {
+		t.Errorf("medication[x] mismatch: %+v", got)
+	}
+	if got.EffectiveStart == nil || !got.EffectiveStart.Equal(*orig.EffectiveStart) {
+		t.Errorf("effectivePeriod mismatch: %v", got.EffectiveStart)
+	}
+}
+
+func TestMedicationDispense_FromFHIR_InvalidElements(t *testing.T) {
+	var md MedicationDispense
+	err := md.FromFHIR(map[string]interface{}{
+		"resourceType":        "MedicationDispense",
+		"status":              "dispensed",
+		"medicationReference": map[string]interface{}{"reference": "Substance/" + uuid.New().String()},
+		"daysSupply":          map[string]interface{}{"value": 2.5},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	want := map[string]bool{
+		"MedicationDispense.status":                        true,
+		"MedicationDispense.medicationReference.reference": true,
+		"MedicationDispense.subject":                       true,
+		"MedicationDispense.daysSupply.value":              true,
+	}
+	for _, issue := range pe.Outcome.Issue {
+		delete(want, issue.Expression[0])
+	}
+	if len(want) != 0 {
+		t.Errorf("missing issues for %v in %+v", want, pe.Outcome.Issue)
+	}
+}
+
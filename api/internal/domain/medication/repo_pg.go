@@ -96,7 +96,7 @@ func (r *medicationRepoPG) Update(ctx context.Context, m *Medication) error {
 			form_code=$6, form_display=$7, schedule=$8,
 			is_brand=$9, is_over_the_counter=$10,
 			is_narcotic=$11, is_antibiotic=$12, is_high_alert=$13,
-			description=$14, note=$15, updated_at=NOW()
+			description=$14, note=$15, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.CodeSystem, m.CodeValue, m.CodeDisplay, m.Status,
 		m.FormCode, m.FormDisplay, m.Schedule,
@@ -119,13 +119,16 @@ var medicationSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *medicationRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Medication, int, error) {
 	qb := fhir.NewSearchQuery("medication", medCols)
-	qb.ApplyParams(params, medicationSearchParams)
+	qb.ApplyParams(ctx, params, medicationSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -293,7 +296,7 @@ func (r *medRequestRepoPG) Update(ctx context.Context, mr *MedicationRequest) er
 		UPDATE medication_request SET status=$2, status_reason_code=$3, status_reason_display=$4,
 			priority=$5, dosage_text=$6, dose_quantity=$7, dose_unit=$8,
 			quantity_value=$9, quantity_unit=$10, days_supply=$11, refills_allowed=$12,
-			substitution_allowed=$13, note=$14, updated_at=NOW()
+			substitution_allowed=$13, note=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		mr.ID, mr.Status, mr.StatusReasonCode, mr.StatusReasonDisplay,
 		mr.Priority, mr.DosageText, mr.DoseQuantity, mr.DoseUnit,
@@ -339,13 +342,16 @@ var medRequestSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *medRequestRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicationRequest, int, error) {
 	qb := fhir.NewSearchQuery("medication_request", medReqCols)
-	qb.ApplyParams(params, medRequestSearchParams)
+	qb.ApplyParams(ctx, params, medRequestSearchParams)
 	qb.OrderBy("authored_on DESC NULLS LAST")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -448,7 +454,7 @@ func (r *medAdminRepoPG) Update(ctx context.Context, ma *MedicationAdministratio
 		UPDATE medication_administration SET status=$2, status_reason_code=$3, status_reason_display=$4,
 			effective_datetime=$5, effective_start=$6, effective_end=$7,
 			dose_quantity=$8, dose_unit=$9, rate_quantity=$10, rate_unit=$11,
-			note=$12, updated_at=NOW()
+			note=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ma.ID, ma.Status, ma.StatusReasonCode, ma.StatusReasonDisplay,
 		ma.EffectiveDatetime, ma.EffectiveStart, ma.EffectiveEnd,
@@ -491,13 +497,16 @@ var medAdminSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *medAdminRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicationAdministration, int, error) {
 	qb := fhir.NewSearchQuery("medication_administration", medAdminCols)
-	qb.ApplyParams(params, medAdminSearchParams)
+	qb.ApplyParams(ctx, params, medAdminSearchParams)
 	qb.OrderBy("effective_datetime DESC NULLS LAST")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -593,7 +602,7 @@ func (r *medDispenseRepoPG) Update(ctx context.Context, md *MedicationDispense)
 			quantity_value=$5, quantity_unit=$6, days_supply=$7,
 			when_prepared=$8, when_handed_over=$9,
 			was_substituted=$10, substitution_type_code=$11, substitution_reason=$12,
-			note=$13, updated_at=NOW()
+			note=$13, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		md.ID, md.Status, md.StatusReasonCode, md.StatusReasonDisplay,
 		md.QuantityValue, md.QuantityUnit, md.DaysSupply,
@@ -637,13 +646,16 @@ var medDispenseSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *medDispenseRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicationDispense, int, error) {
 	qb := fhir.NewSearchQuery("medication_dispense", medDispCols)
-	qb.ApplyParams(params, medDispenseSearchParams)
+	qb.ApplyParams(ctx, params, medDispenseSearchParams)
 	qb.OrderBy("when_handed_over DESC NULLS LAST")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -743,7 +755,7 @@ func (r *medStatementRepoPG) Update(ctx context.Context, ms *MedicationStatement
 			medication_code=$5, medication_display=$6,
 			effective_datetime=$7, effective_start=$8, effective_end=$9,
 			dosage_text=$10, dose_quantity=$11, dose_unit=$12,
-			note=$13, updated_at=NOW()
+			note=$13, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ms.ID, ms.Status, ms.StatusReasonCode, ms.StatusReasonDisplay,
 		ms.MedicationCode, ms.MedicationDisplay,
@@ -786,13 +798,16 @@ var medStatementSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *medStatementRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicationStatement, int, error) {
 	qb := fhir.NewSearchQuery("medication_statement", medStmtCols)
-	qb.ApplyParams(params, medStatementSearchParams)
+	qb.ApplyParams(ctx, params, medStatementSearchParams)
 	qb.OrderBy("date_asserted DESC NULLS LAST")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -809,3 +824,13 @@ func (r *medStatementRepoPG) Search(ctx context.Context, params map[string]strin
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("medication", medicationSearchParams)
+	r.RegisterTable("medication_request", medRequestSearchParams)
+	r.RegisterTable("medication_administration", medAdminSearchParams)
+	r.RegisterTable("medication_dispense", medDispenseSearchParams)
+	r.RegisterTable("medication_statement", medStatementSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/MedicationKnowledge/:id/_history", h.HistoryMedicationKnowledgeFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("MedicationKnowledge", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateMedicationKnowledge(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetMedicationKnowledgeFHIR(c echo.Context) error {
 
 func (h *Handler) CreateMedicationKnowledgeFHIR(c echo.Context) error {
 	var m MedicationKnowledge
-	if err := c.Bind(&m); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "MedicationKnowledge", "", &m); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateMedicationKnowledge(c.Request().Context(), &m); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateMedicationKnowledgeFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateMedicationKnowledgeFHIR(c echo.Context) error {
-	var m MedicationKnowledge
-	if err := c.Bind(&m); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetMedicationKnowledgeByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("MedicationKnowledge", c.Param("id")))
 	}
-	m.ID = existing.ID
-	m.FHIRID = existing.FHIRID
+	m := *existing
+	if err := fhir.BindResource(c, "MedicationKnowledge", existing.FHIRID, &m); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateMedicationKnowledge(c.Request().Context(), &m); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("MedicationKnowledge", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateMedicationKnowledge(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
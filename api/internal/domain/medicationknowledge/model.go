@@ -74,6 +74,23 @@ func (m *MedicationKnowledge) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the medication knowledge from a FHIR MedicationKnowledge
+// resource, the inverse of ToFHIR.
+func (m *MedicationKnowledge) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicationKnowledge", data)
+	m.Status = r.CodeValue("status", "active", "inactive", "entered-in-error")
+	code := r.CodeableConcept("code")
+	m.CodeCode, m.CodeSystem, m.CodeDisplay = code.Code, code.System, code.Display
+	m.ManufacturerID = r.ReferenceID("manufacturer", "Organization")
+	doseForm := r.CodeableConcept("doseForm")
+	m.DoseFormCode, m.DoseFormDisplay = doseForm.Code, doseForm.Display
+	amount := r.Quantity("amount")
+	m.AmountValue, m.AmountUnit = amount.Value, amount.Unit
+	m.Synonym = r.String("synonym")
+	m.Description = r.String("description")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
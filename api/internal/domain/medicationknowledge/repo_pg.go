@@ -76,7 +76,7 @@ func (r *medicationKnowledgeRepoPG) Update(ctx context.Context, m *MedicationKno
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE medication_knowledge SET status=$2, code_code=$3, code_system=$4, code_display=$5,
 			manufacturer_id=$6, dose_form_code=$7, dose_form_display=$8,
-			amount_value=$9, amount_unit=$10, synonym=$11, description=$12, updated_at=NOW()
+			amount_value=$9, amount_unit=$10, synonym=$11, description=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.Status, m.CodeCode, m.CodeSystem, m.CodeDisplay,
 		m.ManufacturerID, m.DoseFormCode, m.DoseFormDisplay,
@@ -117,13 +117,16 @@ var medicationKnowledgeSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *medicationKnowledgeRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicationKnowledge, int, error) {
 	qb := fhir.NewSearchQuery("medication_knowledge", mkCols)
-	qb.ApplyParams(params, medicationKnowledgeSearchParams)
+	qb.ApplyParams(ctx, params, medicationKnowledgeSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -140,3 +143,9 @@ func (r *medicationKnowledgeRepoPG) Search(ctx context.Context, params map[strin
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("medication_knowledge", medicationKnowledgeSearchParams)
+}
@@ -60,6 +60,26 @@ func (m *MedicinalProduct) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the medicinal product from a FHIR MedicinalProduct
+// resource, the inverse of ToFHIR.
+func (m *MedicinalProduct) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicinalProduct", data)
+	productType := r.CodeableConcept("type")
+	m.TypeCode, m.TypeDisplay = productType.Code, productType.Display
+	domain := r.Coding("domain")
+	m.DomainCode, m.DomainDisplay = domain.Code, domain.Display
+	m.Description = r.String("description")
+	doseForm := r.CodeableConcept("combinedPharmaceuticalDoseForm")
+	m.CombinedPharmaceuticalDoseFormCode, m.CombinedPharmaceuticalDoseFormDisplay = doseForm.Code, doseForm.Display
+	m.LegalStatusOfSupplyCode = r.ConceptCode("legalStatusOfSupply")
+	m.AdditionalMonitoring = nil
+	if code := r.ConceptCode("additionalMonitoringIndicator", "Y", "N"); code != nil {
+		monitored := *code == "Y"
+		m.AdditionalMonitoring = &monitored
+	}
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
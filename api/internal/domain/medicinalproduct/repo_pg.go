@@ -76,7 +76,7 @@ func (r *medicinalProductRepoPG) Update(ctx context.Context, m *MedicinalProduct
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE medicinal_product SET status=$2, type_code=$3, type_display=$4, domain_code=$5, domain_display=$6,
 			description=$7, combined_pharmaceutical_dose_form_code=$8, combined_pharmaceutical_dose_form_display=$9,
-			legal_status_of_supply_code=$10, additional_monitoring=$11, updated_at=NOW()
+			legal_status_of_supply_code=$10, additional_monitoring=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.Status, m.TypeCode, m.TypeDisplay, m.DomainCode, m.DomainDisplay,
 		m.Description, m.CombinedPharmaceuticalDoseFormCode, m.CombinedPharmaceuticalDoseFormDisplay,
@@ -118,13 +118,16 @@ var medicinalProductSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *medicinalProductRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicinalProduct, int, error) {
 	qb := fhir.NewSearchQuery("medicinal_product", mpCols)
-	qb.ApplyParams(params, medicinalProductSearchParams)
+	qb.ApplyParams(ctx, params, medicinalProductSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -141,3 +144,9 @@ func (r *medicinalProductRepoPG) Search(ctx context.Context, params map[string]s
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("medicinal_product", medicinalProductSearchParams)
+}
@@ -40,6 +40,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fr.GET("/MedicinalProductAuthorization/:id/_history", h.HistoryFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("MedicinalProductAuthorization", fhir.ReadOnlyInteractions()...)
+}
+
 func (h *Handler) Create(c echo.Context) error {
 	var m MedicinalProductAuthorization
 	if err := c.Bind(&m); err != nil { return echo.NewHTTPError(http.StatusBadRequest, err.Error()) }
@@ -91,17 +95,16 @@ func (h *Handler) GetFHIR(c echo.Context) error {
 }
 func (h *Handler) CreateFHIR(c echo.Context) error {
 	var m MedicinalProductAuthorization
-	if err := c.Bind(&m); err != nil { return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error())) }
+	if err := fhir.BindResource(c, "MedicinalProductAuthorization", "", &m); err != nil { return fhir.WriteParseError(c, err) }
 	if err := h.svc.Create(c.Request().Context(), &m); err != nil { return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error())) }
 	c.Response().Header().Set("Location", "/fhir/MedicinalProductAuthorization/"+m.FHIRID)
 	return c.JSON(http.StatusCreated, m.ToFHIR())
 }
 func (h *Handler) UpdateFHIR(c echo.Context) error {
-	var m MedicinalProductAuthorization
-	if err := c.Bind(&m); err != nil { return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error())) }
 	existing, err := h.svc.GetByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil { return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("MedicinalProductAuthorization", c.Param("id"))) }
-	m.ID = existing.ID; m.FHIRID = existing.FHIRID
+	m := *existing
+	if err := fhir.BindResource(c, "MedicinalProductAuthorization", existing.FHIRID, &m); err != nil { return fhir.WriteParseError(c, err) }
 	if err := h.svc.Update(c.Request().Context(), &m); err != nil { return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error())) }
 	return c.JSON(http.StatusOK, m.ToFHIR())
 }
@@ -117,7 +120,7 @@ func (h *Handler) PatchFHIR(c echo.Context) error {
 	if err != nil { return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("failed to read request body")) }
 	existing, err := h.svc.GetByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil { return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("MedicinalProductAuthorization", c.Param("id"))) }
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -132,7 +135,7 @@ func (h *Handler) PatchFHIR(c echo.Context) error {
 	} else {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome("PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	_ = patched
+	if err := fhir.BindPatched(c, patched, existing); err != nil { return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err)) }
 	if err := h.svc.Update(c.Request().Context(), existing); err != nil { return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error())) }
 	return c.JSON(http.StatusOK, existing.ToFHIR())
 }
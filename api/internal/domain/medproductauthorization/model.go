@@ -76,6 +76,25 @@ func (m *MedicinalProductAuthorization) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the authorization from a FHIR
+// MedicinalProductAuthorization resource, the inverse of ToFHIR.
+func (m *MedicinalProductAuthorization) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicinalProductAuthorization", data)
+	m.Status = r.ConceptCode("status")
+	m.StatusDate = r.DateTime("statusDate")
+	m.SubjectReference = r.Reference("subject")
+	country := r.CodeableConcept("country")
+	m.CountryCode, m.CountryDisplay = country.Code, country.Display
+	jurisdiction := r.CodeableConcept("jurisdiction")
+	m.JurisdictionCode, m.JurisdictionDisplay = jurisdiction.Code, jurisdiction.Display
+	m.ValidityPeriodStart, m.ValidityPeriodEnd = r.Period("validityPeriod")
+	m.DateOfFirstAuthorization = r.DateTime("dateOfFirstAuthorization")
+	m.InternationalBirthDate = r.DateTime("internationalBirthDate")
+	m.HolderReference = r.Reference("holder")
+	m.RegulatorReference = r.Reference("regulator")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil { return "" }
 	return *s
@@ -80,7 +80,7 @@ func (r *mpaRepoPG) Update(ctx context.Context, m *MedicinalProductAuthorization
 			country_code=$5, country_display=$6, jurisdiction_code=$7, jurisdiction_display=$8,
 			validity_period_start=$9, validity_period_end=$10,
 			date_of_first_authorization=$11, international_birth_date=$12,
-			holder_reference=$13, regulator_reference=$14, updated_at=NOW()
+			holder_reference=$13, regulator_reference=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.Status, m.StatusDate, m.SubjectReference,
 		m.CountryCode, m.CountryDisplay, m.JurisdictionCode, m.JurisdictionDisplay,
@@ -118,13 +118,16 @@ var mpaSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *mpaRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicinalProductAuthorization, int, error) {
 	qb := fhir.NewSearchQuery("medicinal_product_authorization", mpaCols)
-	qb.ApplyParams(params, mpaSearchParams)
+	qb.ApplyParams(ctx, params, mpaSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -141,3 +144,9 @@ func (r *mpaRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("medicinal_product_authorization", mpaSearchParams)
+}
@@ -72,6 +72,24 @@ func (m *MedicinalProductContraindication) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the contraindication from a FHIR
+// MedicinalProductContraindication resource, the inverse of ToFHIR.
+func (m *MedicinalProductContraindication) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicinalProductContraindication", data)
+	m.SubjectReference = r.Reference("subject")
+	disease := r.CodeableConcept("disease")
+	m.DiseaseCode, m.DiseaseDisplay = disease.Code, disease.Display
+	diseaseStatus := r.CodeableConcept("diseaseStatus")
+	m.DiseaseStatusCode, m.DiseaseStatusDisplay = diseaseStatus.Code, diseaseStatus.Display
+	comorbidity := r.CodeableConcept("comorbidity")
+	m.ComorbidityCode, m.ComorbidityDisplay = comorbidity.Code, comorbidity.Display
+	m.TherapeuticIndicationRef = r.Reference("therapeuticIndication")
+	m.PopulationAgeLow = r.Decimal("population.ageRange.low.value")
+	m.PopulationAgeHigh = r.Decimal("population.ageRange.high.value")
+	m.PopulationGenderCode = r.ConceptCode("population.gender")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil { return "" }
 	return *s
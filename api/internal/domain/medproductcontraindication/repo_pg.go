@@ -69,7 +69,7 @@ func (r *mpcRepoPG) Update(ctx context.Context, m *MedicinalProductContraindicat
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE medicinal_product_contraindication SET subject_reference=$2, disease_code=$3, disease_display=$4,
 			disease_status_code=$5, disease_status_display=$6, comorbidity_code=$7, comorbidity_display=$8,
-			therapeutic_indication_reference=$9, population_age_low=$10, population_age_high=$11, population_gender_code=$12, updated_at=NOW()
+			therapeutic_indication_reference=$9, population_age_low=$10, population_age_high=$11, population_gender_code=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.SubjectReference, m.DiseaseCode, m.DiseaseDisplay,
 		m.DiseaseStatusCode, m.DiseaseStatusDisplay, m.ComorbidityCode, m.ComorbidityDisplay,
@@ -100,13 +100,16 @@ var mpcSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *mpcRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicinalProductContraindication, int, error) {
 	qb := fhir.NewSearchQuery("medicinal_product_contraindication", mpcCols)
-	qb.ApplyParams(params, mpcSearchParams)
+	qb.ApplyParams(ctx, params, mpcSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -123,3 +126,9 @@ func (r *mpcRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("medicinal_product_contraindication", mpcSearchParams)
+}
@@ -64,4 +64,26 @@ func (m *MedicinalProductIndication) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the indication from a FHIR MedicinalProductIndication
+// resource, the inverse of ToFHIR.
+func (m *MedicinalProductIndication) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicinalProductIndication", data)
+	m.SubjectReference = r.Reference("subject")
+	disease := r.CodeableConcept("diseaseSymptomProcedure")
+	m.DiseaseSymptomProcedureCode, m.DiseaseSymptomProcedureDisplay = disease.Code, disease.Display
+	diseaseStatus := r.CodeableConcept("diseaseStatus")
+	m.DiseaseStatusCode, m.DiseaseStatusDisplay = diseaseStatus.Code, diseaseStatus.Display
+	comorbidity := r.CodeableConcept("comorbidity")
+	m.ComorbidityCode, m.ComorbidityDisplay = comorbidity.Code, comorbidity.Display
+	effect := r.CodeableConcept("intendedEffect")
+	m.IntendedEffectCode, m.IntendedEffectDisplay = effect.Code, effect.Display
+	duration := r.Quantity("duration")
+	m.DurationValue, m.DurationUnit = duration.Value, duration.Unit
+	m.UndesirableEffectReference = r.Reference("undesirableEffect")
+	m.PopulationAgeLow = r.Decimal("population.ageRange.low.value")
+	m.PopulationAgeHigh = r.Decimal("population.ageRange.high.value")
+	m.PopulationGenderCode = r.ConceptCode("population.gender")
+	return r.Err()
+}
+
 func strVal(s *string) string { if s == nil { return "" }; return *s }
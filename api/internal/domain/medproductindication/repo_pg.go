@@ -69,7 +69,7 @@ func (r *mpiRepoPG) Update(ctx context.Context, m *MedicinalProductIndication) e
 		UPDATE medicinal_product_indication SET subject_reference=$2, disease_symptom_procedure_code=$3, disease_symptom_procedure_display=$4,
 			disease_status_code=$5, disease_status_display=$6, comorbidity_code=$7, comorbidity_display=$8,
 			intended_effect_code=$9, intended_effect_display=$10, duration_value=$11, duration_unit=$12,
-			undesirable_effect_reference=$13, population_age_low=$14, population_age_high=$15, population_gender_code=$16, updated_at=NOW()
+			undesirable_effect_reference=$13, population_age_low=$14, population_age_high=$15, population_gender_code=$16, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.SubjectReference, m.DiseaseSymptomProcedureCode, m.DiseaseSymptomProcedureDisplay,
 		m.DiseaseStatusCode, m.DiseaseStatusDisplay, m.ComorbidityCode, m.ComorbidityDisplay,
@@ -97,11 +97,12 @@ var mpiSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *mpiRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicinalProductIndication, int, error) {
 	qb := fhir.NewSearchQuery("medicinal_product_indication", mpiCols)
-	qb.ApplyParams(params, mpiSearchParams)
+	qb.ApplyParams(ctx, params, mpiSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil { return nil, 0, err }
+	if qb.CountOnly() { return nil, total, nil }
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil { return nil, 0, err }; defer rows.Close()
@@ -109,3 +110,9 @@ func (r *mpiRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	for rows.Next() { m, err := r.scanRow(rows); if err != nil { return nil, 0, err }; items = append(items, m) }
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("medicinal_product_indication", mpiSearchParams)
+}
@@ -68,6 +68,25 @@ func (m *MedicinalProductIngredient) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the ingredient from a FHIR MedicinalProductIngredient
+// resource, the inverse of ToFHIR. Only the first specified substance and
+// strength are read.
+func (m *MedicinalProductIngredient) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicinalProductIngredient", data)
+	role := r.CodeableConcept("role")
+	m.RoleCode = r.RequiredString("role.coding.code")
+	m.RoleDisplay = role.Display
+	m.AllergenicIndicator = r.Bool("allergenicIndicator")
+	substance := r.CodeableConcept("specifiedSubstance.code")
+	m.SubstanceCode, m.SubstanceDisplay = substance.Code, substance.Display
+	numerator := r.Quantity("specifiedSubstance.strength.presentation.numerator")
+	m.StrengthNumeratorValue, m.StrengthNumeratorUnit = numerator.Value, numerator.Unit
+	denominator := r.Quantity("specifiedSubstance.strength.presentation.denominator")
+	m.StrengthDenominatorValue, m.StrengthDenominatorUnit = denominator.Value, denominator.Unit
+	m.ManufacturerReference = r.Reference("manufacturer")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -76,7 +76,7 @@ func (r *mpiRepoPG) Update(ctx context.Context, m *MedicinalProductIngredient) e
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE medicinal_product_ingredient SET role_code=$2, role_display=$3, allergenic_indicator=$4,
 			substance_code=$5, substance_display=$6, strength_numerator_value=$7, strength_numerator_unit=$8,
-			strength_denominator_value=$9, strength_denominator_unit=$10, manufacturer_reference=$11, updated_at=NOW()
+			strength_denominator_value=$9, strength_denominator_unit=$10, manufacturer_reference=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.RoleCode, m.RoleDisplay, m.AllergenicIndicator,
 		m.SubstanceCode, m.SubstanceDisplay, m.StrengthNumeratorValue, m.StrengthNumeratorUnit,
@@ -117,13 +117,16 @@ var mpiSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *mpiRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicinalProductIngredient, int, error) {
 	qb := fhir.NewSearchQuery("medicinal_product_ingredient", mpiCols)
-	qb.ApplyParams(params, mpiSearchParams)
+	qb.ApplyParams(ctx, params, mpiSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -140,3 +143,9 @@ func (r *mpiRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("medicinal_product_ingredient", mpiSearchParams)
+}
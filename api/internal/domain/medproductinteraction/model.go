@@ -48,4 +48,21 @@ func (m *MedicinalProductInteraction) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the interaction from a FHIR MedicinalProductInteraction
+// resource, the inverse of ToFHIR.
+func (m *MedicinalProductInteraction) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicinalProductInteraction", data)
+	m.SubjectReference = r.Reference("subject")
+	m.Description = r.String("description")
+	interactionType := r.CodeableConcept("type")
+	m.TypeCode, m.TypeDisplay = interactionType.Code, interactionType.Display
+	effect := r.CodeableConcept("effect")
+	m.EffectCode, m.EffectDisplay = effect.Code, effect.Display
+	incidence := r.CodeableConcept("incidence")
+	m.IncidenceCode, m.IncidenceDisplay = incidence.Code, incidence.Display
+	management := r.CodeableConcept("management")
+	m.ManagementCode, m.ManagementDisplay = management.Code, management.Display
+	return r.Err()
+}
+
 func strVal(s *string) string { if s == nil { return "" }; return *s }
@@ -64,7 +64,7 @@ func (r *mpiRepoPG) Update(ctx context.Context, m *MedicinalProductInteraction)
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE medicinal_product_interaction SET subject_reference=$2, description=$3, type_code=$4, type_display=$5,
 			effect_code=$6, effect_display=$7, incidence_code=$8, incidence_display=$9,
-			management_code=$10, management_display=$11, updated_at=NOW()
+			management_code=$10, management_display=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.SubjectReference, m.Description, m.TypeCode, m.TypeDisplay,
 		m.EffectCode, m.EffectDisplay, m.IncidenceCode, m.IncidenceDisplay,
@@ -91,11 +91,12 @@ var mpiSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *mpiRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicinalProductInteraction, int, error) {
 	qb := fhir.NewSearchQuery("medicinal_product_interaction", mpiCols)
-	qb.ApplyParams(params, mpiSearchParams)
+	qb.ApplyParams(ctx, params, mpiSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil { return nil, 0, err }
+	if qb.CountOnly() { return nil, total, nil }
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil { return nil, 0, err }; defer rows.Close()
@@ -103,3 +104,9 @@ func (r *mpiRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	for rows.Next() { m, err := r.scanRow(rows); if err != nil { return nil, 0, err }; items = append(items, m) }
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("medicinal_product_interaction", mpiSearchParams)
+}
@@ -57,6 +57,22 @@ func (m *MedicinalProductManufactured) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the manufactured item from a FHIR
+// MedicinalProductManufactured resource, the inverse of ToFHIR.
+func (m *MedicinalProductManufactured) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicinalProductManufactured", data)
+	doseForm := r.CodeableConcept("manufacturedDoseForm")
+	m.ManufacturedDoseFormCode = r.RequiredString("manufacturedDoseForm.coding.code")
+	m.ManufacturedDoseFormDisplay = doseForm.Display
+	unit := r.CodeableConcept("unitOfPresentation")
+	m.UnitOfPresentationCode, m.UnitOfPresentationDisplay = unit.Code, unit.Display
+	quantity := r.Quantity("quantity")
+	m.QuantityValue, m.QuantityUnit = quantity.Value, quantity.Unit
+	m.ManufacturerReference = r.Reference("manufacturer")
+	m.IngredientReference = r.Reference("ingredient")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil { return "" }
 	return *s
@@ -70,7 +70,7 @@ func (r *mpmRepoPG) Update(ctx context.Context, m *MedicinalProductManufactured)
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE medicinal_product_manufactured SET manufactured_dose_form_code=$2, manufactured_dose_form_display=$3,
 			unit_of_presentation_code=$4, unit_of_presentation_display=$5,
-			quantity_value=$6, quantity_unit=$7, manufacturer_reference=$8, ingredient_reference=$9, updated_at=NOW()
+			quantity_value=$6, quantity_unit=$7, manufacturer_reference=$8, ingredient_reference=$9, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.ManufacturedDoseFormCode, m.ManufacturedDoseFormDisplay,
 		m.UnitOfPresentationCode, m.UnitOfPresentationDisplay,
@@ -104,11 +104,12 @@ var mpmSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *mpmRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicinalProductManufactured, int, error) {
 	qb := fhir.NewSearchQuery("medicinal_product_manufactured", mpmCols)
-	qb.ApplyParams(params, mpmSearchParams)
+	qb.ApplyParams(ctx, params, mpmSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil { return nil, 0, err }
+	if qb.CountOnly() { return nil, total, nil }
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil { return nil, 0, err }
@@ -121,3 +122,9 @@ func (r *mpmRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("medicinal_product_manufactured", mpmSearchParams)
+}
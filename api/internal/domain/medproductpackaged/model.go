@@ -58,6 +58,21 @@ func (m *MedicinalProductPackaged) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the packaged product from a FHIR MedicinalProductPackaged
+// resource, the inverse of ToFHIR.
+func (m *MedicinalProductPackaged) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicinalProductPackaged", data)
+	m.SubjectReference = r.Reference("subject")
+	m.Description = r.String("description")
+	legalStatus := r.CodeableConcept("legalStatusOfSupply")
+	m.LegalStatusOfSupplyCode, m.LegalStatusOfSupplyDisplay = legalStatus.Code, legalStatus.Display
+	marketingStatus := r.CodeableConcept("marketingStatus.status")
+	m.MarketingStatusCode, m.MarketingStatusDisplay = marketingStatus.Code, marketingStatus.Display
+	m.MarketingAuthorizationReference = r.Reference("marketingAuthorization")
+	m.ManufacturerReference = r.Reference("manufacturer")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil { return "" }
 	return *s
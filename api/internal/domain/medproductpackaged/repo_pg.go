@@ -75,7 +75,7 @@ func (r *mppRepoPG) Update(ctx context.Context, m *MedicinalProductPackaged) err
 		UPDATE medicinal_product_packaged SET subject_reference=$2, description=$3,
 			legal_status_of_supply_code=$4, legal_status_of_supply_display=$5,
 			marketing_status_code=$6, marketing_status_display=$7,
-			marketing_authorization_reference=$8, manufacturer_reference=$9, updated_at=NOW()
+			marketing_authorization_reference=$8, manufacturer_reference=$9, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.SubjectReference, m.Description,
 		m.LegalStatusOfSupplyCode, m.LegalStatusOfSupplyDisplay,
@@ -110,11 +110,12 @@ var mppSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *mppRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicinalProductPackaged, int, error) {
 	qb := fhir.NewSearchQuery("medicinal_product_packaged", mppCols)
-	qb.ApplyParams(params, mppSearchParams)
+	qb.ApplyParams(ctx, params, mppSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil { return nil, 0, err }
+	if qb.CountOnly() { return nil, total, nil }
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil { return nil, 0, err }
@@ -127,3 +128,9 @@ func (r *mppRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("medicinal_product_packaged", mppSearchParams)
+}
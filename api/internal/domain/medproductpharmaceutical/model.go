@@ -42,4 +42,18 @@ func (m *MedicinalProductPharmaceutical) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the pharmaceutical product from a FHIR
+// MedicinalProductPharmaceutical resource, the inverse of ToFHIR.
+func (m *MedicinalProductPharmaceutical) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicinalProductPharmaceutical", data)
+	doseForm := r.CodeableConcept("administrableDoseForm")
+	m.AdministrableDoseFormCode = r.RequiredString("administrableDoseForm.coding.code")
+	m.AdministrableDoseFormDisplay = doseForm.Display
+	unit := r.CodeableConcept("unitOfPresentation")
+	m.UnitOfPresentationCode, m.UnitOfPresentationDisplay = unit.Code, unit.Display
+	m.IngredientReference = r.Reference("ingredient")
+	m.DeviceReference = r.Reference("device")
+	return r.Err()
+}
+
 func strVal(s *string) string { if s == nil { return "" }; return *s }
@@ -64,7 +64,7 @@ func (r *mppRepoPG) Update(ctx context.Context, m *MedicinalProductPharmaceutica
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE medicinal_product_pharmaceutical SET administrable_dose_form_code=$2, administrable_dose_form_display=$3,
 			unit_of_presentation_code=$4, unit_of_presentation_display=$5,
-			ingredient_reference=$6, device_reference=$7, updated_at=NOW()
+			ingredient_reference=$6, device_reference=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.AdministrableDoseFormCode, m.AdministrableDoseFormDisplay,
 		m.UnitOfPresentationCode, m.UnitOfPresentationDisplay,
@@ -90,11 +90,12 @@ var mppSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *mppRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicinalProductPharmaceutical, int, error) {
 	qb := fhir.NewSearchQuery("medicinal_product_pharmaceutical", mppCols)
-	qb.ApplyParams(params, mppSearchParams)
+	qb.ApplyParams(ctx, params, mppSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil { return nil, 0, err }
+	if qb.CountOnly() { return nil, total, nil }
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil { return nil, 0, err }; defer rows.Close()
@@ -102,3 +103,9 @@ func (r *mppRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	for rows.Next() { m, err := r.scanRow(rows); if err != nil { return nil, 0, err }; items = append(items, m) }
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("medicinal_product_pharmaceutical", mppSearchParams)
+}
@@ -52,4 +52,21 @@ func (m *MedicinalProductUndesirableEffect) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the undesirable effect from a FHIR
+// MedicinalProductUndesirableEffect resource, the inverse of ToFHIR.
+func (m *MedicinalProductUndesirableEffect) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MedicinalProductUndesirableEffect", data)
+	m.SubjectReference = r.Reference("subject")
+	symptom := r.CodeableConcept("symptomConditionEffect")
+	m.SymptomConditionEffectCode, m.SymptomConditionEffectDisplay = symptom.Code, symptom.Display
+	classification := r.CodeableConcept("classification")
+	m.ClassificationCode, m.ClassificationDisplay = classification.Code, classification.Display
+	frequency := r.CodeableConcept("frequencyOfOccurrence")
+	m.FrequencyOfOccurrenceCode, m.FrequencyOfOccurrenceDisplay = frequency.Code, frequency.Display
+	m.PopulationAgeLow = r.Decimal("population.ageRange.low.value")
+	m.PopulationAgeHigh = r.Decimal("population.ageRange.high.value")
+	m.PopulationGenderCode = r.ConceptCode("population.gender")
+	return r.Err()
+}
+
 func strVal(s *string) string { if s == nil { return "" }; return *s }
@@ -64,7 +64,7 @@ func (r *mpueRepoPG) Update(ctx context.Context, m *MedicinalProductUndesirableE
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE medicinal_product_undesirable_effect SET subject_reference=$2, symptom_condition_effect_code=$3, symptom_condition_effect_display=$4,
 			classification_code=$5, classification_display=$6, frequency_of_occurrence_code=$7, frequency_of_occurrence_display=$8,
-			population_age_low=$9, population_age_high=$10, population_gender_code=$11, updated_at=NOW()
+			population_age_low=$9, population_age_high=$10, population_gender_code=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.SubjectReference, m.SymptomConditionEffectCode, m.SymptomConditionEffectDisplay,
 		m.ClassificationCode, m.ClassificationDisplay, m.FrequencyOfOccurrenceCode, m.FrequencyOfOccurrenceDisplay,
@@ -90,11 +90,12 @@ var mpueSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *mpueRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MedicinalProductUndesirableEffect, int, error) {
 	qb := fhir.NewSearchQuery("medicinal_product_undesirable_effect", mpueCols)
-	qb.ApplyParams(params, mpueSearchParams)
+	qb.ApplyParams(ctx, params, mpueSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil { return nil, 0, err }
+	if qb.CountOnly() { return nil, total, nil }
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil { return nil, 0, err }; defer rows.Close()
@@ -102,3 +103,9 @@ func (r *mpueRepoPG) Search(ctx context.Context, params map[string]string, limit
 	for rows.Next() { m, err := r.scanRow(rows); if err != nil { return nil, 0, err }; items = append(items, m) }
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("medicinal_product_undesirable_effect", mpueSearchParams)
+}
@@ -83,6 +83,26 @@ func (m *MolecularSequence) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the sequence from a FHIR MolecularSequence resource, the
+// inverse of ToFHIR. Only the first variant window is read.
+func (m *MolecularSequence) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("MolecularSequence", data)
+	m.Type = r.RequiredCode("type", "aa", "dna", "rna")
+	if cs := r.Int("coordinateSystem"); cs != nil {
+		m.CoordinateSystem = *cs
+	}
+	m.PatientID = r.ReferenceID("patient", "Patient")
+	m.SpecimenID = r.ReferenceID("specimen", "Specimen")
+	m.DeviceID = r.ReferenceID("device", "Device")
+	m.PerformerID = r.ReferenceID("performer", "Organization")
+	m.ObservedSeq = r.String("observedSeq")
+	m.ReferenceSeqID = r.String("referenceSeq.referenceSeqId.text")
+	m.ReferenceSeqStrand = r.String("referenceSeq.strand")
+	m.WindowStart = r.Int("variant.start")
+	m.WindowEnd = r.Int("variant.end")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
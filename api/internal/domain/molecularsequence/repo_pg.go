@@ -76,7 +76,7 @@ func (r *molecularSequenceRepoPG) Update(ctx context.Context, m *MolecularSequen
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE molecular_sequence SET type=$2, patient_id=$3, specimen_id=$4, device_id=$5, performer_id=$6,
 			coordinate_system=$7, observed_seq=$8, reference_seq_id=$9, reference_seq_strand=$10,
-			window_start=$11, window_end=$12, updated_at=NOW()
+			window_start=$11, window_end=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.Type, m.PatientID, m.SpecimenID, m.DeviceID, m.PerformerID,
 		m.CoordinateSystem, m.ObservedSeq, m.ReferenceSeqID, m.ReferenceSeqStrand,
@@ -117,13 +117,16 @@ var msSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *molecularSequenceRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*MolecularSequence, int, error) {
 	qb := fhir.NewSearchQuery("molecular_sequence", msCols)
-	qb.ApplyParams(params, msSearchParams)
+	qb.ApplyParams(ctx, params, msSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -140,3 +143,9 @@ func (r *molecularSequenceRepoPG) Search(ctx context.Context, params map[string]
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("molecular_sequence", msSearchParams)
+}
@@ -59,7 +59,7 @@ func (r *flowsheetTemplateRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*F
 
 func (r *flowsheetTemplateRepoPG) Update(ctx context.Context, t *FlowsheetTemplate) error {
 	_, err := r.conn(ctx).Exec(ctx, `
-		UPDATE flowsheet_template SET name=$2, description=$3, category=$4, is_active=$5, updated_at=NOW()
+		UPDATE flowsheet_template SET name=$2, description=$3, category=$4, is_active=$5, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		t.ID, t.Name, t.Description, t.Category, t.IsActive)
 	return err
@@ -216,13 +216,16 @@ var entrySearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *flowsheetEntryRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*FlowsheetEntry, int, error) {
 	qb := fhir.NewSearchQuery("flowsheet_entry", entryCols)
-	qb.ApplyParams(params, entrySearchParams)
+	qb.ApplyParams(ctx, params, entrySearchParams)
 	qb.OrderBy("recorded_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -284,7 +287,7 @@ func (r *nursingAssessmentRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*N
 
 func (r *nursingAssessmentRepoPG) Update(ctx context.Context, a *NursingAssessment) error {
 	_, err := r.conn(ctx).Exec(ctx, `
-		UPDATE nursing_assessment SET assessment_data=$2, status=$3, completed_at=$4, note=$5, updated_at=NOW()
+		UPDATE nursing_assessment SET assessment_data=$2, status=$3, completed_at=$4, note=$5, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		a.ID, a.AssessmentData, a.Status, a.CompletedAt, a.Note)
 	return err
@@ -799,3 +802,9 @@ func (r *intakeOutputRepoPG) ListByEncounter(ctx context.Context, encounterID uu
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("flowsheet_entry", entrySearchParams)
+}
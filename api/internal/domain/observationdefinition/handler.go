@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/ObservationDefinition/:id/_history", h.HistoryObservationDefinitionFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("ObservationDefinition", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateObservationDefinition(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetObservationDefinitionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateObservationDefinitionFHIR(c echo.Context) error {
 	var od ObservationDefinition
-	if err := c.Bind(&od); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ObservationDefinition", "", &od); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateObservationDefinition(c.Request().Context(), &od); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateObservationDefinitionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateObservationDefinitionFHIR(c echo.Context) error {
-	var od ObservationDefinition
-	if err := c.Bind(&od); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetObservationDefinitionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ObservationDefinition", c.Param("id")))
 	}
-	od.ID = existing.ID
-	od.FHIRID = existing.FHIRID
+	od := *existing
+	if err := fhir.BindResource(c, "ObservationDefinition", existing.FHIRID, &od); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateObservationDefinition(c.Request().Context(), &od); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ObservationDefinition", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateObservationDefinition(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -84,6 +84,29 @@ func (od *ObservationDefinition) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the definition from a FHIR ObservationDefinition
+// resource, the inverse of ToFHIR.
+func (od *ObservationDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ObservationDefinition", data)
+	od.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	code := r.CodeableConcept("code")
+	od.CodeCode = r.RequiredString("code.coding.code")
+	od.CodeSystem, od.CodeDisplay = code.System, code.Display
+	category := r.CodeableConcept("category")
+	od.CategoryCode, od.CategoryDisplay = category.Code, category.Display
+	od.PermittedDataType = r.Code("permittedDataType", "Quantity", "CodeableConcept", "string",
+		"boolean", "integer", "Range", "Ratio", "SampledData", "time", "dateTime", "Period")
+	od.MultipleResultsAllowed = r.BoolValue("multipleResultsAllowed")
+	method := r.CodeableConcept("method")
+	od.MethodCode, od.MethodDisplay = method.Code, method.Display
+	od.PreferredReportName = r.String("preferredReportName")
+	unit := r.Coding("quantitativeDetails.unit")
+	od.UnitCode, od.UnitDisplay = unit.Code, unit.Display
+	od.NormalValueLow = r.Decimal("qualifiedInterval.range.low.value")
+	od.NormalValueHigh = r.Decimal("qualifiedInterval.range.high.value")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -91,7 +91,7 @@ func (r *observationDefinitionRepoPG) Update(ctx context.Context, od *Observatio
 			permitted_data_type=$8, multiple_results_allowed=$9,
 			method_code=$10, method_display=$11, preferred_report_name=$12,
 			unit_code=$13, unit_display=$14,
-			normal_value_low=$15, normal_value_high=$16, updated_at=NOW()
+			normal_value_low=$15, normal_value_high=$16, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		od.ID, od.Status, od.CategoryCode, od.CategoryDisplay,
 		od.CodeCode, od.CodeSystem, od.CodeDisplay,
@@ -136,13 +136,16 @@ var odSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *observationDefinitionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ObservationDefinition, int, error) {
 	qb := fhir.NewSearchQuery("observation_definition", odCols)
-	qb.ApplyParams(params, odSearchParams)
+	qb.ApplyParams(ctx, params, odSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -159,3 +162,9 @@ func (r *observationDefinitionRepoPG) Search(ctx context.Context, params map[str
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("observation_definition", odSearchParams)
+}
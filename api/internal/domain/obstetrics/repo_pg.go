@@ -77,7 +77,7 @@ func (r *pregnancyRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*Pregnancy
 func (r *pregnancyRepoPG) Update(ctx context.Context, p *Pregnancy) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE pregnancy SET status=$2, estimated_due_date=$3, risk_level=$4, risk_factors=$5,
-			note=$6, outcome_date=$7, outcome_summary=$8, updated_at=NOW()
+			note=$6, outcome_date=$7, outcome_summary=$8, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		p.ID, p.Status, p.EstimatedDueDate, p.RiskLevel, p.RiskFactors,
 		p.Note, p.OutcomeDate, p.OutcomeSummary)
@@ -199,7 +199,7 @@ func (r *prenatalVisitRepoPG) Update(ctx context.Context, v *PrenatalVisit) erro
 		UPDATE prenatal_visit SET weight=$2, blood_pressure_systolic=$3, blood_pressure_diastolic=$4,
 			fundal_height=$5, fetal_heart_rate=$6, fetal_presentation=$7,
 			urine_protein=$8, urine_glucose=$9, edema=$10,
-			note=$11, next_visit_date=$12, updated_at=NOW()
+			note=$11, next_visit_date=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		v.ID, v.Weight, v.BloodPressureSystolic, v.BloodPressureDiastolic,
 		v.FundalHeight, v.FetalHeartRate, v.FetalPresentation,
@@ -285,7 +285,7 @@ func (r *laborRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*LaborRecord,
 
 func (r *laborRepoPG) Update(ctx context.Context, l *LaborRecord) error {
 	_, err := r.conn(ctx).Exec(ctx, `
-		UPDATE labor_record SET status=$2, anesthesia_type=$3, anesthesia_start=$4, note=$5, updated_at=NOW()
+		UPDATE labor_record SET status=$2, anesthesia_type=$3, anesthesia_start=$4, note=$5, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		l.ID, l.Status, l.AnesthesiaType, l.AnesthesiaStart, l.Note)
 	return err
@@ -458,7 +458,7 @@ func (r *deliveryRepoPG) Update(ctx context.Context, d *DeliveryRecord) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE delivery_record SET delivery_method=$2, delivery_type=$3,
 			placenta_delivery=$4, placenta_intact=$5,
-			blood_loss_ml=$6, complications=$7, note=$8, updated_at=NOW()
+			blood_loss_ml=$6, complications=$7, note=$8, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		d.ID, d.DeliveryMethod, d.DeliveryType,
 		d.PlacentaDelivery, d.PlacentaIntact,
@@ -556,7 +556,7 @@ func (r *newbornRepoPG) Update(ctx context.Context, n *NewbornRecord) error {
 		UPDATE newborn_record SET sex=$2, birth_weight_grams=$3, birth_length_cm=$4,
 			apgar_1min=$5, apgar_5min=$6, apgar_10min=$7,
 			birth_status=$8, nicu_admission=$9, nicu_reason=$10,
-			feeding_method=$11, note=$12, updated_at=NOW()
+			feeding_method=$11, note=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		n.ID, n.Sex, n.BirthWeightGrams, n.BirthLengthCM,
 		n.Apgar1Min, n.Apgar5Min, n.Apgar10Min,
@@ -660,7 +660,7 @@ func (r *postpartumRepoPG) Update(ctx context.Context, p *PostpartumRecord) erro
 			perineum_status=$5, incision_status=$6, breast_status=$7, breastfeeding_status=$8,
 			contraception_plan=$9, mood_screening_score=$10, mood_screening_tool=$11,
 			depression_risk=$12, blood_pressure_systolic=$13, blood_pressure_diastolic=$14,
-			weight=$15, note=$16, updated_at=NOW()
+			weight=$15, note=$16, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		p.ID, p.UterineInvolution, p.LochiaType, p.LochiaAmount,
 		p.PerineumStatus, p.IncisionStatus, p.BreastStatus, p.BreastfeedingStatus,
@@ -81,7 +81,7 @@ func (r *cancerDiagnosisRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*Can
 func (r *cancerDiagnosisRepoPG) Update(ctx context.Context, d *CancerDiagnosis) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE cancer_diagnosis SET current_status=$2, stage_group=$3, t_stage=$4, n_stage=$5, m_stage=$6,
-			grade=$7, managing_provider_id=$8, note=$9, updated_at=NOW()
+			grade=$7, managing_provider_id=$8, note=$9, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		d.ID, d.CurrentStatus, d.StageGroup, d.TStage, d.NStage, d.MStage,
 		d.Grade, d.ManagingProviderID, d.Note)
@@ -185,7 +185,7 @@ func (r *treatmentProtocolRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*T
 func (r *treatmentProtocolRepoPG) Update(ctx context.Context, p *TreatmentProtocol) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE treatment_protocol SET protocol_name=$2, status=$3, number_of_cycles=$4,
-			start_date=$5, end_date=$6, note=$7, updated_at=NOW()
+			start_date=$5, end_date=$6, note=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		p.ID, p.ProtocolName, p.Status, p.NumberOfCycles,
 		p.StartDate, p.EndDate, p.Note)
@@ -305,7 +305,7 @@ func (r *chemoCycleRepoPG) Update(ctx context.Context, c *ChemoCycle) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE chemotherapy_cycle SET status=$2, actual_start_date=$3, actual_end_date=$4,
 			dose_reduction_pct=$5, dose_reduction_reason=$6,
-			delay_days=$7, delay_reason=$8, note=$9, updated_at=NOW()
+			delay_days=$7, delay_reason=$8, note=$9, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		c.ID, c.Status, c.ActualStartDate, c.ActualEndDate,
 		c.DoseReductionPct, c.DoseReductionReason,
@@ -440,7 +440,7 @@ func (r *radiationRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*Radiation
 
 func (r *radiationRepoPG) Update(ctx context.Context, rt *RadiationTherapy) error {
 	_, err := r.conn(ctx).Exec(ctx, `
-		UPDATE radiation_therapy SET status=$2, completed_fractions=$3, end_date=$4, note=$5, updated_at=NOW()
+		UPDATE radiation_therapy SET status=$2, completed_fractions=$3, end_date=$4, note=$5, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		rt.ID, rt.Status, rt.CompletedFractions, rt.EndDate, rt.Note)
 	return err
@@ -570,7 +570,7 @@ func (r *tumorMarkerRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*TumorMa
 func (r *tumorMarkerRepoPG) Update(ctx context.Context, m *TumorMarker) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE tumor_marker SET value_quantity=$2, value_unit=$3, value_string=$4,
-			value_interpretation=$5, result_datetime=$6, note=$7, updated_at=NOW()
+			value_interpretation=$5, result_datetime=$6, note=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.ValueQuantity, m.ValueUnit, m.ValueString,
 		m.ValueInterpretation, m.ResultDatetime, m.Note)
@@ -660,7 +660,7 @@ func (r *tumorBoardRepoPG) Update(ctx context.Context, b *TumorBoardReview) erro
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE tumor_board_review SET recommendations=$2, treatment_decision=$3,
 			clinical_trial_discussed=$4, clinical_trial_id=$5,
-			next_review_date=$6, note=$7, updated_at=NOW()
+			next_review_date=$6, note=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		b.ID, b.Recommendations, b.TreatmentDecision,
 		b.ClinicalTrialDiscussed, b.ClinicalTrialID,
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/OrganizationAffiliation/:id/_history", h.HistoryOrganizationAffiliationFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("OrganizationAffiliation", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateOrganizationAffiliation(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetOrganizationAffiliationFHIR(c echo.Context) error {
 
 func (h *Handler) CreateOrganizationAffiliationFHIR(c echo.Context) error {
 	var o OrganizationAffiliation
-	if err := c.Bind(&o); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "OrganizationAffiliation", "", &o); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateOrganizationAffiliation(c.Request().Context(), &o); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateOrganizationAffiliationFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateOrganizationAffiliationFHIR(c echo.Context) error {
-	var o OrganizationAffiliation
-	if err := c.Bind(&o); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetOrganizationAffiliationByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("OrganizationAffiliation", c.Param("id")))
 	}
-	o.ID = existing.ID
-	o.FHIRID = existing.FHIRID
+	o := *existing
+	if err := fhir.BindResource(c, "OrganizationAffiliation", existing.FHIRID, &o); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateOrganizationAffiliation(c.Request().Context(), &o); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("OrganizationAffiliation", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["active"].(bool); ok {
-		existing.Active = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateOrganizationAffiliation(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -74,6 +74,27 @@ func (o *OrganizationAffiliation) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the affiliation from a FHIR OrganizationAffiliation
+// resource, the inverse of ToFHIR.
+func (o *OrganizationAffiliation) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("OrganizationAffiliation", data)
+	o.Active = true
+	if active := r.Bool("active"); active != nil {
+		o.Active = *active
+	}
+	o.OrganizationID = r.ReferenceID("organization", "Organization")
+	o.ParticipatingOrgID = r.ReferenceID("participatingOrganization", "Organization")
+	o.PeriodStart, o.PeriodEnd = r.Period("period")
+	code := r.CodeableConcept("code")
+	o.CodeCode, o.CodeDisplay = code.Code, code.Display
+	specialty := r.CodeableConcept("specialty")
+	o.SpecialtyCode, o.SpecialtyDisplay = specialty.Code, specialty.Display
+	o.LocationID = r.ReferenceID("location", "Location")
+	o.TelecomPhone = r.ContactValue("telecom", "phone", "")
+	o.TelecomEmail = r.ContactValue("telecom", "email", "")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -81,7 +81,7 @@ func (r *organizationAffiliationRepoPG) Update(ctx context.Context, o *Organizat
 		UPDATE organization_affiliation SET active=$2, organization_id=$3, participating_org_id=$4,
 			period_start=$5, period_end=$6, code_code=$7, code_display=$8,
 			specialty_code=$9, specialty_display=$10, location_id=$11,
-			telecom_phone=$12, telecom_email=$13, updated_at=NOW()
+			telecom_phone=$12, telecom_email=$13, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		o.ID, o.Active, o.OrganizationID, o.ParticipatingOrgID,
 		o.PeriodStart, o.PeriodEnd, o.CodeCode, o.CodeDisplay,
@@ -125,13 +125,16 @@ var oaSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *organizationAffiliationRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*OrganizationAffiliation, int, error) {
 	qb := fhir.NewSearchQuery("organization_affiliation", oaCols)
-	qb.ApplyParams(params, oaSearchParams)
+	qb.ApplyParams(ctx, params, oaSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -148,3 +151,9 @@ func (r *organizationAffiliationRepoPG) Search(ctx context.Context, params map[s
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("organization_affiliation", oaSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Person/:id/_history", h.HistoryPersonFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Person", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreatePerson(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetPersonFHIR(c echo.Context) error {
 
 func (h *Handler) CreatePersonFHIR(c echo.Context) error {
 	var p Person
-	if err := c.Bind(&p); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Person", "", &p); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreatePerson(c.Request().Context(), &p); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreatePersonFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdatePersonFHIR(c echo.Context) error {
-	var p Person
-	if err := c.Bind(&p); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetPersonByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Person", c.Param("id")))
 	}
-	p.ID = existing.ID
-	p.FHIRID = existing.FHIRID
+	p := *existing
+	if err := fhir.BindResource(c, "Person", existing.FHIRID, &p); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdatePerson(c.Request().Context(), &p); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Person", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["active"].(bool); ok {
-		existing.Active = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdatePerson(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -91,6 +91,28 @@ func (p *Person) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the person from a FHIR Person resource, the inverse of
+// ToFHIR.
+func (p *Person) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Person", data)
+	p.Active = true
+	if active := r.Bool("active"); active != nil {
+		p.Active = *active
+	}
+	p.NameFamily = r.String("name.family")
+	p.NameGiven = r.String("name.given")
+	p.Gender = r.Code("gender", "male", "female", "other", "unknown")
+	p.BirthDate = r.DateTime("birthDate")
+	p.AddressLine = r.String("address.line")
+	p.AddressCity = r.String("address.city")
+	p.AddressState = r.String("address.state")
+	p.AddressPostalCode = r.String("address.postalCode")
+	p.TelecomPhone = r.ContactValue("telecom", "phone", "")
+	p.TelecomEmail = r.ContactValue("telecom", "email", "")
+	p.ManagingOrgID = r.ReferenceID("managingOrganization", "Organization")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
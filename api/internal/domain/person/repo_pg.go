@@ -76,7 +76,7 @@ func (r *personRepoPG) Update(ctx context.Context, p *Person) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE person SET active=$2, name_family=$3, name_given=$4, gender=$5, birth_date=$6,
 			address_line=$7, address_city=$8, address_state=$9, address_postal_code=$10,
-			telecom_phone=$11, telecom_email=$12, managing_org_id=$13, updated_at=NOW()
+			telecom_phone=$11, telecom_email=$12, managing_org_id=$13, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		p.ID, p.Active, p.NameFamily, p.NameGiven, p.Gender, p.BirthDate,
 		p.AddressLine, p.AddressCity, p.AddressState, p.AddressPostalCode,
@@ -118,13 +118,16 @@ var personSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *personRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Person, int, error) {
 	qb := fhir.NewSearchQuery("person", personCols)
-	qb.ApplyParams(params, personSearchParams)
+	qb.ApplyParams(ctx, params, personSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -141,3 +144,9 @@ func (r *personRepoPG) Search(ctx context.Context, params map[string]string, lim
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("person", personSearchParams)
+}
@@ -87,6 +87,11 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/QuestionnaireResponse/:id/_history", h.HistoryQuestionnaireResponseFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Questionnaire", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("QuestionnaireResponse", fhir.ReadOnlyInteractions()...)
+}
+
 // -- Portal Account Handlers --
 
 func (h *Handler) CreatePortalAccount(c echo.Context) error {
@@ -519,8 +524,8 @@ func (h *Handler) GetQuestionnaireFHIR(c echo.Context) error {
 
 func (h *Handler) CreateQuestionnaireFHIR(c echo.Context) error {
 	var q Questionnaire
-	if err := c.Bind(&q); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Questionnaire", "", &q); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateQuestionnaire(c.Request().Context(), &q); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -554,8 +559,8 @@ func (h *Handler) GetQuestionnaireResponseFHIR(c echo.Context) error {
 
 func (h *Handler) CreateQuestionnaireResponseFHIR(c echo.Context) error {
 	var qr QuestionnaireResponse
-	if err := c.Bind(&qr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "QuestionnaireResponse", "", &qr); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateQuestionnaireResponse(c.Request().Context(), &qr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -571,12 +576,10 @@ func (h *Handler) UpdateQuestionnaireFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Questionnaire", c.Param("id")))
 	}
-	var q Questionnaire
-	if err := c.Bind(&q); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	q := *existing
+	if err := fhir.BindResource(c, "Questionnaire", existing.FHIRID, &q); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
-	q.ID = existing.ID
-	q.FHIRID = existing.FHIRID
 	if err := h.svc.UpdateQuestionnaire(c.Request().Context(), &q); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -605,7 +608,7 @@ func (h *Handler) PatchQuestionnaireFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Questionnaire", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -631,11 +634,8 @@ func (h *Handler) PatchQuestionnaireFHIR(c echo.Context) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
-	}
-	if v, ok := patched["name"].(string); ok {
-		existing.Name = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateQuestionnaire(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -674,12 +674,10 @@ func (h *Handler) UpdateQuestionnaireResponseFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("QuestionnaireResponse", c.Param("id")))
 	}
-	var qr QuestionnaireResponse
-	if err := c.Bind(&qr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	qr := *existing
+	if err := fhir.BindResource(c, "QuestionnaireResponse", existing.FHIRID, &qr); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
-	qr.ID = existing.ID
-	qr.FHIRID = existing.FHIRID
 	if err := h.svc.UpdateQuestionnaireResponse(c.Request().Context(), &qr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -708,7 +706,7 @@ func (h *Handler) PatchQuestionnaireResponseFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("QuestionnaireResponse", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -734,8 +732,8 @@ func (h *Handler) PatchQuestionnaireResponseFHIR(c echo.Context) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateQuestionnaireResponse(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
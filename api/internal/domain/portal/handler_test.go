@@ -730,7 +730,7 @@ func TestHandler_GetQuestionnaireFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateQuestionnaireFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"name":"GAD-7"}`
+	body := `{"resourceType":"Questionnaire","name":"GAD-7"}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -752,7 +752,7 @@ func TestHandler_UpdateQuestionnaireFHIR(t *testing.T) {
 	h, e := newTestHandler()
 	q := &Questionnaire{Name: "PHQ-9"}
 	h.svc.CreateQuestionnaire(nil, q)
-	body := `{"name":"GAD-7"}`
+	body := `{"resourceType":"Questionnaire","name":"GAD-7"}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -896,7 +896,7 @@ func TestHandler_GetQuestionnaireResponseFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateQuestionnaireResponseFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"questionnaire_id":"` + uuid.New().String() + `","patient_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"QuestionnaireResponse","questionnaire":"` + uuid.New().String() + `","subject":{"reference":"Patient/` + uuid.New().String() + `"}}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -918,7 +918,7 @@ func TestHandler_UpdateQuestionnaireResponseFHIR(t *testing.T) {
 	h, e := newTestHandler()
 	qr := &QuestionnaireResponse{QuestionnaireID: uuid.New(), PatientID: uuid.New()}
 	h.svc.CreateQuestionnaireResponse(nil, qr)
-	body := `{"questionnaire_id":"` + qr.QuestionnaireID.String() + `","patient_id":"` + qr.PatientID.String() + `"}`
+	body := `{"resourceType":"QuestionnaireResponse","questionnaire":"` + qr.QuestionnaireID.String() + `","subject":{"reference":"Patient/` + qr.PatientID.String() + `"}}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
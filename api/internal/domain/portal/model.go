@@ -126,6 +126,24 @@ func (q *Questionnaire) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the questionnaire from a FHIR Questionnaire resource, the
+// inverse of ToFHIR. Items are managed through their own endpoints.
+func (q *Questionnaire) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Questionnaire", data)
+	q.Name = r.RequiredString("name")
+	q.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	q.Title = r.String("title")
+	q.Version = r.String("version")
+	q.Description = r.String("description")
+	q.Purpose = r.String("purpose")
+	q.SubjectType = r.String("subjectType")
+	q.Date = r.DateTime("date")
+	q.Publisher = r.String("publisher")
+	q.ApprovalDate = r.DateTime("approvalDate")
+	q.LastReviewDate = r.DateTime("lastReviewDate")
+	return r.Err()
+}
+
 // QuestionnaireItem maps to the questionnaire_item table.
 type QuestionnaireItem struct {
 	ID              uuid.UUID       `db:"id" json:"id"`
@@ -191,6 +209,29 @@ func (qr *QuestionnaireResponse) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the response from a FHIR QuestionnaireResponse resource,
+// the inverse of ToFHIR. The questionnaire canonical may be a bare id or a
+// Questionnaire/<id> reference.
+func (qr *QuestionnaireResponse) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("QuestionnaireResponse", data)
+	qr.Status = r.CodeValue("status", "in-progress", "completed", "amended", "entered-in-error", "stopped")
+	if questionnaire := r.RequiredString("questionnaire"); questionnaire != "" {
+		if _, id, ok := fhir.SplitReference(questionnaire); ok {
+			questionnaire = id
+		}
+		if id, err := uuid.Parse(questionnaire); err != nil {
+			r.Invalid("questionnaire", "QuestionnaireResponse.questionnaire must identify a Questionnaire by id")
+		} else {
+			qr.QuestionnaireID = id
+		}
+	}
+	qr.PatientID = r.RequiredReferenceID("subject", "Patient")
+	qr.EncounterID = r.ReferenceID("encounter", "Encounter")
+	qr.AuthorID = r.ReferenceID("author", "Practitioner")
+	qr.Authored = r.DateTime("authored")
+	return r.Err()
+}
+
 // QuestionnaireResponseItem maps to the questionnaire_response_item table.
 type QuestionnaireResponseItem struct {
 	ID         uuid.UUID  `db:"id" json:"id"`
@@ -1,9 +1,11 @@
 package portal
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/ehr/ehr/internal/platform/fhir"
 	"github.com/google/uuid"
 )
 
@@ -395,3 +397,62 @@ func TestPatientCheckin_StructCreation(t *testing.T) {
 		t.Errorf("Note = %v, want 'Arrived on time'", checkin.Note)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestQuestionnaireResponse_FromFHIR_RoundTrip(t *testing.T) {
+	authored := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	orig := &QuestionnaireResponse{
+		FHIRID:          "qr-rt",
+		QuestionnaireID: uuid.New(),
+		PatientID:       uuid.New(),
+		EncounterID:     ptrUUID(uuid.New()),
+		Status:          "completed",
+		Authored:        &authored,
+	}
+
+	var got QuestionnaireResponse
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.QuestionnaireID != orig.QuestionnaireID || got.PatientID != orig.PatientID {
+		t.Errorf("questionnaire/patient mismatch: %+v", got)
+	}
+	if got.EncounterID == nil || *got.EncounterID != *orig.EncounterID {
+		t.Errorf("encounter mismatch: %+v", got.EncounterID)
+	}
+	if got.Status != "completed" || got.Authored == nil || !got.Authored.Equal(authored) {
+		t.Errorf("status/authored mismatch: %+v", got)
+	}
+}
+
+func TestQuestionnaireResponse_FromFHIR_InvalidQuestionnaire(t *testing.T) {
+	var qr QuestionnaireResponse
+	err := qr.FromFHIR(map[string]interface{}{
+		"resourceType":  "QuestionnaireResponse",
+		"questionnaire": "http://example.org/Questionnaire/phq-9",
+		"subject":       map[string]interface{}{"reference": "Patient/" + uuid.New().String()},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	if len(pe.Outcome.Issue) != 1 || pe.Outcome.Issue[0].Expression[0] != "QuestionnaireResponse.questionnaire" {
+		t.Errorf("unexpected issues: %+v", pe.Outcome.Issue)
+	}
+}
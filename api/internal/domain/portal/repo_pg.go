@@ -271,7 +271,7 @@ func (r *questionnaireRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*
 func (r *questionnaireRepoPG) Update(ctx context.Context, q *Questionnaire) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE questionnaire SET name=$2, title=$3, status=$4, version=$5, description=$6,
-			purpose=$7, publisher=$8, updated_at=NOW()
+			purpose=$7, publisher=$8, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		q.ID, q.Name, q.Title, q.Status, q.Version, q.Description,
 		q.Purpose, q.Publisher)
@@ -312,13 +312,16 @@ var questSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *questionnaireRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Questionnaire, int, error) {
 	qb := fhir.NewSearchQuery("questionnaire", questCols)
-	qb.ApplyParams(params, questSearchParams)
+	qb.ApplyParams(ctx, params, questSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -425,7 +428,7 @@ func (r *questionnaireResponseRepoPG) GetByFHIRID(ctx context.Context, fhirID st
 
 func (r *questionnaireResponseRepoPG) Update(ctx context.Context, qr *QuestionnaireResponse) error {
 	_, err := r.conn(ctx).Exec(ctx, `
-		UPDATE questionnaire_response SET status=$2, authored=$3, updated_at=NOW()
+		UPDATE questionnaire_response SET status=$2, authored=$3, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		qr.ID, qr.Status, qr.Authored)
 	return err
@@ -486,13 +489,16 @@ var qrSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *questionnaireResponseRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*QuestionnaireResponse, int, error) {
 	qb := fhir.NewSearchQuery("questionnaire_response", qrCols)
-	qb.ApplyParams(params, qrSearchParams)
+	qb.ApplyParams(ctx, params, qrSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -641,3 +647,10 @@ func (r *patientCheckinRepoPG) ListByPatient(ctx context.Context, patientID uuid
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("questionnaire", questSearchParams)
+	r.RegisterTable("questionnaire_response", qrSearchParams)
+}
@@ -48,6 +48,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirWrite.DELETE("/Provenance/:id", h.DeleteFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Provenance", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Handlers --
 
 func (h *Handler) CreateProvenance(c echo.Context) error {
@@ -143,8 +147,8 @@ func (h *Handler) GetFHIR(c echo.Context) error {
 
 func (h *Handler) CreateFHIR(c echo.Context) error {
 	var p Provenance
-	if err := c.Bind(&p); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Provenance", "", &p); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateProvenance(c.Request().Context(), &p); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -154,16 +158,14 @@ func (h *Handler) CreateFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateFHIR(c echo.Context) error {
-	var p Provenance
-	if err := c.Bind(&p); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetProvenanceByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Provenance", c.Param("id")))
 	}
-	p.ID = existing.ID
-	p.FHIRID = existing.FHIRID
+	p := *existing
+	if err := fhir.BindResource(c, "Provenance", existing.FHIRID, &p); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateProvenance(c.Request().Context(), &p); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -211,7 +211,7 @@ func TestHandler_GetFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"target_type":"Patient","target_id":"pat-123","recorded":"2024-06-01T10:30:00Z"}`
+	body := `{"resourceType":"Provenance","target":[{"reference":"Patient/pat-123"}],"recorded":"2024-06-01T10:30:00Z"}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/Provenance", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -234,7 +234,7 @@ func TestHandler_UpdateFHIR(t *testing.T) {
 	p := &Provenance{TargetType: "Patient", TargetID: "pat-123", Recorded: time.Now()}
 	h.svc.CreateProvenance(nil, p)
 
-	body := `{"target_type":"Patient","target_id":"pat-123"}`
+	body := `{"resourceType":"Provenance","target":[{"reference":"Patient/pat-123"}],"recorded":"2024-06-01T10:30:00Z"}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
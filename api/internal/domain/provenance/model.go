@@ -57,6 +57,20 @@ func (p *Provenance) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the provenance from a FHIR Provenance resource, the
+// inverse of ToFHIR. Only the first target is read; agents and entities are
+// managed through their own endpoints.
+func (p *Provenance) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Provenance", data)
+	p.TargetType, p.TargetID = r.RequiredReferenceTarget("target")
+	p.Recorded = r.RequiredDateTime("recorded")
+	activity := r.CodeableConcept("activity")
+	p.ActivityCode, p.ActivityDisplay = activity.Code, activity.Display
+	reason := r.CodeableConcept("reason")
+	p.ReasonCode, p.ReasonDisplay = reason.Code, reason.Display
+	return r.Err()
+}
+
 // ProvenanceAgent maps to the provenance_agent table.
 type ProvenanceAgent struct {
 	ID              uuid.UUID `db:"id" json:"id"`
@@ -1,6 +1,7 @@
 package provenance
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -144,3 +145,58 @@ func TestStrVal_NonNil(t *testing.T) {
 		t.Errorf("strVal(&hello) = %q, want hello", v)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestProvenance_FromFHIR_RoundTrip(t *testing.T) {
+	recorded := time.Date(2024, 6, 1, 10, 30, 0, 0, time.UTC)
+	orig := &Provenance{
+		FHIRID:          "prov-rt",
+		TargetType:      "Observation",
+		TargetID:        "obs-1",
+		Recorded:        recorded,
+		ActivityCode:    ptrStr("CREATE"),
+		ActivityDisplay: ptrStr("create"),
+	}
+
+	var got Provenance
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.TargetType != "Observation" || got.TargetID != "obs-1" || !got.Recorded.Equal(recorded) {
+		t.Errorf("target/recorded mismatch: %+v", got)
+	}
+	if got.ActivityCode == nil || *got.ActivityCode != "CREATE" || *got.ActivityDisplay != "create" {
+		t.Errorf("activity mismatch: %+v", got)
+	}
+}
+
+func TestProvenance_FromFHIR_MissingRecorded(t *testing.T) {
+	var p Provenance
+	err := p.FromFHIR(map[string]interface{}{
+		"resourceType": "Provenance",
+		"target":       []interface{}{map[string]interface{}{"reference": "Patient/pat-1"}},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	if len(pe.Outcome.Issue) != 1 || pe.Outcome.Issue[0].Expression[0] != "Provenance.recorded" {
+		t.Errorf("unexpected issues: %+v", pe.Outcome.Issue)
+	}
+}
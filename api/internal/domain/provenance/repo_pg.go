@@ -71,7 +71,7 @@ func (r *provenanceRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Pro
 func (r *provenanceRepoPG) Update(ctx context.Context, p *Provenance) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE provenance SET target_type=$2, target_id=$3, activity_code=$4,
-			activity_display=$5, reason_code=$6, reason_display=$7, updated_at=NOW()
+			activity_display=$5, reason_code=$6, reason_display=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		p.ID, p.TargetType, p.TargetID, p.ActivityCode,
 		p.ActivityDisplay, p.ReasonCode, p.ReasonDisplay)
@@ -110,13 +110,16 @@ var provenanceSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *provenanceRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Provenance, int, error) {
 	qb := fhir.NewSearchQuery("provenance", provCols)
-	qb.ApplyParams(params, provenanceSearchParams)
+	qb.ApplyParams(ctx, params, provenanceSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -193,3 +196,9 @@ func (r *provenanceRepoPG) GetEntities(ctx context.Context, provenanceID uuid.UU
 	}
 	return items, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("provenance", provenanceSearchParams)
+}
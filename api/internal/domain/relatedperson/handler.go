@@ -48,6 +48,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirWrite.DELETE("/RelatedPerson/:id", h.DeleteFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("RelatedPerson", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Handlers --
 
 func (h *Handler) CreateRelatedPerson(c echo.Context) error {
@@ -146,8 +150,8 @@ func (h *Handler) GetFHIR(c echo.Context) error {
 
 func (h *Handler) CreateFHIR(c echo.Context) error {
 	var rp RelatedPerson
-	if err := c.Bind(&rp); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "RelatedPerson", "", &rp); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateRelatedPerson(c.Request().Context(), &rp); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -157,16 +161,14 @@ func (h *Handler) CreateFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateFHIR(c echo.Context) error {
-	var rp RelatedPerson
-	if err := c.Bind(&rp); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetRelatedPersonByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("RelatedPerson", c.Param("id")))
 	}
-	rp.ID = existing.ID
-	rp.FHIRID = existing.FHIRID
+	rp := *existing
+	if err := fhir.BindResource(c, "RelatedPerson", existing.FHIRID, &rp); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateRelatedPerson(c.Request().Context(), &rp); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -210,7 +210,7 @@ func TestHandler_GetFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"patient_id":"` + uuid.New().String() + `","relationship_code":"WIFE","relationship_display":"Wife"}`
+	body := `{"resourceType":"RelatedPerson","patient":{"reference":"Patient/` + uuid.New().String() + `"},"relationship":[{"coding":[{"code":"WIFE","display":"Wife"}]}]}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/RelatedPerson", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -233,7 +233,7 @@ func TestHandler_UpdateFHIR(t *testing.T) {
 	rp := &RelatedPerson{PatientID: uuid.New(), RelationshipCode: "WIFE", RelationshipDisplay: "Wife"}
 	h.svc.CreateRelatedPerson(nil, rp)
 
-	body := `{"active":true,"relationship_code":"WIFE","relationship_display":"Wife"}`
+	body := `{"resourceType":"RelatedPerson","active":true,"patient":{"reference":"Patient/` + rp.PatientID.String() + `"},"relationship":[{"coding":[{"code":"WIFE","display":"Wife"}]}]}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
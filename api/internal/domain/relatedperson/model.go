@@ -83,6 +83,26 @@ func (rp *RelatedPerson) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the related person from a FHIR RelatedPerson resource,
+// the inverse of ToFHIR.
+func (rp *RelatedPerson) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("RelatedPerson", data)
+	rp.Active = true
+	if active := r.Bool("active"); active != nil {
+		rp.Active = *active
+	}
+	rp.PatientID = r.RequiredReferenceID("patient", "Patient")
+	rp.RelationshipCode = r.RequiredString("relationship.coding.code")
+	rp.RelationshipDisplay = r.RequiredString("relationship.coding.display")
+	rp.FamilyName = r.String("name.family")
+	rp.GivenName = r.String("name.given")
+	rp.Gender = r.Code("gender", "male", "female", "other", "unknown")
+	rp.BirthDate = r.DateTime("birthDate")
+	rp.Phone = r.ContactValue("telecom", "phone", "")
+	rp.Email = r.ContactValue("telecom", "email", "")
+	return r.Err()
+}
+
 // RelatedPersonCommunication maps to the related_person_communication table.
 type RelatedPersonCommunication struct {
 	ID              uuid.UUID `db:"id" json:"id"`
@@ -1,6 +1,7 @@
 package relatedperson
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -166,3 +167,66 @@ func TestRelatedPerson_ToFHIR_OptionalFieldsNil(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestRelatedPerson_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &RelatedPerson{
+		FHIRID:              "rp-rt",
+		Active:              false,
+		PatientID:           uuid.New(),
+		RelationshipCode:    "WIFE",
+		RelationshipDisplay: "Wife",
+		FamilyName:          ptrStr("Smith"),
+		GivenName:           ptrStr("Anna"),
+		Phone:               ptrStr("555-0100"),
+		Email:               ptrStr("anna@example.com"),
+	}
+
+	var got RelatedPerson
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Active || got.PatientID != orig.PatientID {
+		t.Errorf("active/patient mismatch: %+v", got)
+	}
+	if got.RelationshipCode != "WIFE" || got.RelationshipDisplay != "Wife" {
+		t.Errorf("relationship mismatch: %+v", got)
+	}
+	if *got.FamilyName != "Smith" || *got.GivenName != "Anna" || *got.Phone != "555-0100" || *got.Email != "anna@example.com" {
+		t.Errorf("name/telecom mismatch: %+v", got)
+	}
+}
+
+func TestRelatedPerson_FromFHIR_InvalidPatient(t *testing.T) {
+	var rp RelatedPerson
+	err := rp.FromFHIR(map[string]interface{}{
+		"resourceType": "RelatedPerson",
+		"patient":      map[string]interface{}{"reference": "Practitioner/" + uuid.New().String()},
+		"relationship": []interface{}{map[string]interface{}{
+			"coding": []interface{}{map[string]interface{}{"code": "WIFE", "display": "Wife"}},
+		}},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	if len(pe.Outcome.Issue) != 1 || pe.Outcome.Issue[0].Expression[0] != "RelatedPerson.patient.reference" {
+		t.Errorf("unexpected issues: %+v", pe.Outcome.Issue)
+	}
+}
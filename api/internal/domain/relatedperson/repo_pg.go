@@ -140,13 +140,16 @@ var relatedPersonSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *relatedPersonRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*RelatedPerson, int, error) {
 	qb := fhir.NewSearchQuery("related_person", rpCols)
-	qb.ApplyParams(params, relatedPersonSearchParams)
+	qb.ApplyParams(ctx, params, relatedPersonSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -191,3 +194,9 @@ func (r *relatedPersonRepoPG) GetCommunications(ctx context.Context, relatedPers
 	}
 	return items, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("related_person", relatedPersonSearchParams)
+}
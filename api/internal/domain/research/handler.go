@@ -71,6 +71,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/ResearchStudy/:id/_history", h.HistoryStudyFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("ResearchStudy", fhir.ReadOnlyInteractions()...)
+}
+
 // -- Research Study Handlers --
 
 func (h *Handler) CreateStudy(c echo.Context) error {
@@ -404,8 +408,8 @@ func (h *Handler) GetStudyFHIR(c echo.Context) error {
 
 func (h *Handler) CreateStudyFHIR(c echo.Context) error {
 	var s ResearchStudy
-	if err := c.Bind(&s); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ResearchStudy", "", &s); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateStudy(c.Request().Context(), &s); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -419,12 +423,10 @@ func (h *Handler) UpdateStudyFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ResearchStudy", c.Param("id")))
 	}
-	var s ResearchStudy
-	if err := c.Bind(&s); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	s := *existing
+	if err := fhir.BindResource(c, "ResearchStudy", existing.FHIRID, &s); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
-	s.ID = existing.ID
-	s.FHIRID = existing.FHIRID
 	if err := h.svc.UpdateStudy(c.Request().Context(), &s); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -453,7 +455,7 @@ func (h *Handler) PatchStudyFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ResearchStudy", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -479,11 +481,8 @@ func (h *Handler) PatchStudyFHIR(c echo.Context) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
-	}
-	if v, ok := patched["title"].(string); ok {
-		existing.Title = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateStudy(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
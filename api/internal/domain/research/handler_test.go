@@ -540,7 +540,7 @@ func TestHandler_GetStudyFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateStudyFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"protocol_number":"P-1","title":"T"}`
+	body := `{"resourceType":"ResearchStudy","identifier":[{"system":"urn:ehr:research:protocol","value":"P-1"}],"title":"T"}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -560,7 +560,7 @@ func TestHandler_UpdateStudyFHIR(t *testing.T) {
 	h, e := newTestHandler()
 	s := &ResearchStudy{ProtocolNumber: "P-1", Title: "T"}
 	h.svc.CreateStudy(nil, s)
-	body := `{"protocol_number":"P-1","title":"Updated","status":"active-recruiting"}`
+	body := `{"resourceType":"ResearchStudy","identifier":[{"system":"urn:ehr:research:protocol","value":"P-1"}],"title":"Updated","status":"active"}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -108,6 +108,40 @@ func (s *ResearchStudy) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the study from a FHIR ResearchStudy resource, the inverse
+// of ToFHIR. A FHIR status that already describes the current internal status
+// leaves it unchanged, so "active" does not overwrite the recruiting state.
+func (s *ResearchStudy) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ResearchStudy", data)
+	s.Title = r.RequiredString("title")
+	if status := r.Code("status", "in-review", "approved", "active", "temporarily-closed-to-accrual",
+		"closed-to-accrual", "completed", "withdrawn", "administratively-completed"); status == nil {
+		s.Status = ""
+	} else if mapStudyStatusToFHIR(s.Status) != *status {
+		s.Status = mapStudyStatusFromFHIR(*status)
+	}
+	if protocol := r.IdentifierValue("identifier", "urn:ehr:research:protocol"); protocol != nil {
+		s.ProtocolNumber = *protocol
+	} else {
+		r.Invalid("identifier", "ResearchStudy.identifier must include the urn:ehr:research:protocol identifier")
+	}
+	s.Phase = r.ConceptCode("phase")
+	s.Category = r.String("category.text")
+	s.Description = r.String("description")
+	s.SponsorName = r.String("sponsor.display")
+	s.PrincipalInvestigatorID = r.ReferenceID("principalInvestigator", "Practitioner")
+	s.StartDate, s.EndDate = r.Period("period")
+	s.EnrollmentTarget = nil
+	if display := r.StringValue("enrollment.display"); display != "" {
+		var target int
+		if _, err := fmt.Sscanf(display, "%d", &target); err == nil {
+			s.EnrollmentTarget = &target
+		}
+	}
+	s.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // ResearchArm maps to the research_arm table.
 type ResearchArm struct {
 	ID               uuid.UUID `db:"id" json:"id"`
@@ -207,6 +241,19 @@ func mapStudyStatusToFHIR(status string) string {
 	return status
 }
 
+func mapStudyStatusFromFHIR(status string) string {
+	mapping := map[string]string{
+		"active":                        "active-recruiting",
+		"temporarily-closed-to-accrual": "temporarily-closed",
+		"closed-to-accrual":             "closed",
+		"administratively-completed":    "suspended",
+	}
+	if mapped, ok := mapping[status]; ok {
+		return mapped
+	}
+	return status
+}
+
 func strVal(s string) string {
 	return s
 }
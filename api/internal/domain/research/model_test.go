@@ -1,9 +1,11 @@
 package research
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/ehr/ehr/internal/platform/fhir"
 	"github.com/google/uuid"
 )
 
@@ -404,3 +406,75 @@ func TestResearchEnrollment_NilOptionalFields(t *testing.T) {
 		t.Errorf("Note should be nil, got %v", enrollment.Note)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestResearchStudy_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &ResearchStudy{
+		FHIRID:           "rs-rt",
+		Title:            "Hypertension Trial",
+		ProtocolNumber:   "P-100",
+		Status:           "active-not-recruiting",
+		Phase:            ptrStr("phase-3"),
+		SponsorName:      ptrStr("Acme Pharma"),
+		EnrollmentTarget: ptrInt(250),
+	}
+
+	var got ResearchStudy
+	got.Status = orig.Status
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Title != "Hypertension Trial" || got.ProtocolNumber != "P-100" {
+		t.Errorf("title/protocol mismatch: %+v", got)
+	}
+	if got.Status != "active-not-recruiting" {
+		t.Errorf("expected status to be preserved, got %q", got.Status)
+	}
+	if *got.Phase != "phase-3" || *got.SponsorName != "Acme Pharma" || *got.EnrollmentTarget != 250 {
+		t.Errorf("optional fields mismatch: %+v", got)
+	}
+}
+
+func TestResearchStudy_FromFHIR_MapsStatus(t *testing.T) {
+	s := ResearchStudy{Status: "in-review"}
+	err := s.FromFHIR(map[string]interface{}{
+		"resourceType": "ResearchStudy",
+		"title":        "T",
+		"status":       "closed-to-accrual",
+		"identifier":   []interface{}{map[string]interface{}{"system": "urn:ehr:research:protocol", "value": "P-1"}},
+	})
+	if err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if s.Status != "closed" {
+		t.Errorf("expected status closed, got %q", s.Status)
+	}
+}
+
+func TestResearchStudy_FromFHIR_MissingProtocol(t *testing.T) {
+	var s ResearchStudy
+	err := s.FromFHIR(map[string]interface{}{"resourceType": "ResearchStudy", "title": "T"})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	if len(pe.Outcome.Issue) != 1 || pe.Outcome.Issue[0].Expression[0] != "ResearchStudy.identifier" {
+		t.Errorf("unexpected issues: %+v", pe.Outcome.Issue)
+	}
+}
@@ -86,7 +86,7 @@ func (r *studyRepoPG) Update(ctx context.Context, s *ResearchStudy) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE research_study SET status=$2, title=$3, description=$4,
 			start_date=$5, end_date=$6, enrollment_target=$7,
-			primary_endpoint=$8, secondary_endpoints=$9, note=$10, updated_at=NOW()
+			primary_endpoint=$8, secondary_endpoints=$9, note=$10, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		s.ID, s.Status, s.Title, s.Description,
 		s.StartDate, s.EndDate, s.EnrollmentTarget,
@@ -128,13 +128,16 @@ var studySearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *studyRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ResearchStudy, int, error) {
 	qb := fhir.NewSearchQuery("research_study", studyCols)
-	qb.ApplyParams(params, studySearchParams)
+	qb.ApplyParams(ctx, params, studySearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -233,7 +236,7 @@ func (r *enrollmentRepoPG) Update(ctx context.Context, e *ResearchEnrollment) er
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE research_enrollment SET status=$2, arm_id=$3,
 			enrolled_date=$4, completion_date=$5, withdrawal_date=$6,
-			withdrawal_reason=$7, subject_number=$8, note=$9, updated_at=NOW()
+			withdrawal_reason=$7, subject_number=$8, note=$9, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.ArmID,
 		e.EnrolledDate, e.CompletionDate, e.WithdrawalDate,
@@ -465,3 +468,9 @@ func (r *deviationRepoPG) ListByEnrollment(ctx context.Context, enrollmentID uui
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("research_study", studySearchParams)
+}
@@ -70,3 +70,20 @@ func (e *ResearchDefinition) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the definition from a FHIR ResearchDefinition resource,
+// the inverse of ToFHIR.
+func (e *ResearchDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ResearchDefinition", data)
+	e.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	e.URL = r.String("url")
+	e.Name = r.String("name")
+	e.Title = r.String("title")
+	e.Description = r.String("description")
+	e.Publisher = r.String("publisher")
+	e.Date = r.DateTime("date")
+	e.PopulationReference = r.Reference("population")
+	e.ExposureReference = r.Reference("exposure")
+	e.OutcomeReference = r.Reference("outcome")
+	return r.Err()
+}
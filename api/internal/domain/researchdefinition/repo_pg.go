@@ -71,7 +71,7 @@ func (r *researchDefinitionRepoPG) GetByFHIRID(ctx context.Context, fhirID strin
 func (r *researchDefinitionRepoPG) Update(ctx context.Context, e *ResearchDefinition) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE research_definition SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
-			population_reference=$9, exposure_reference=$10, outcome_reference=$11, updated_at=NOW()
+			population_reference=$9, exposure_reference=$10, outcome_reference=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.URL, e.Name, e.Title, e.Description, e.Publisher, e.Date,
 		e.PopulationReference, e.ExposureReference, e.OutcomeReference)
@@ -112,13 +112,16 @@ var researchDefinitionSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *researchDefinitionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ResearchDefinition, int, error) {
 	qb := fhir.NewSearchQuery("research_definition", rdCols)
-	qb.ApplyParams(params, researchDefinitionSearchParams)
+	qb.ApplyParams(ctx, params, researchDefinitionSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -135,3 +138,9 @@ func (r *researchDefinitionRepoPG) Search(ctx context.Context, params map[string
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("research_definition", researchDefinitionSearchParams)
+}
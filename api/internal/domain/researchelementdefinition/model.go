@@ -67,3 +67,19 @@ func (e *ResearchElementDefinition) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the definition from a FHIR ResearchElementDefinition
+// resource, the inverse of ToFHIR. Only the first characteristic is read.
+func (e *ResearchElementDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ResearchElementDefinition", data)
+	e.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	e.Type = r.RequiredCode("type", "population", "exposure", "outcome")
+	e.URL = r.String("url")
+	e.Name = r.String("name")
+	e.Title = r.String("title")
+	e.Description = r.String("description")
+	e.Publisher = r.String("publisher")
+	e.Date = r.DateTime("date")
+	e.CharacteristicDefinitionRef = r.Reference("characteristic.definitionReference")
+	return r.Err()
+}
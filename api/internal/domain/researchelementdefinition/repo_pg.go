@@ -71,7 +71,7 @@ func (r *researchElementDefinitionRepoPG) GetByFHIRID(ctx context.Context, fhirI
 func (r *researchElementDefinitionRepoPG) Update(ctx context.Context, e *ResearchElementDefinition) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE research_element_definition SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
-			type=$9, characteristic_definition_reference=$10, updated_at=NOW()
+			type=$9, characteristic_definition_reference=$10, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.URL, e.Name, e.Title, e.Description, e.Publisher, e.Date,
 		e.Type, e.CharacteristicDefinitionRef)
@@ -112,13 +112,16 @@ var researchElementDefinitionSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *researchElementDefinitionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ResearchElementDefinition, int, error) {
 	qb := fhir.NewSearchQuery("research_element_definition", redCols)
-	qb.ApplyParams(params, researchElementDefinitionSearchParams)
+	qb.ApplyParams(ctx, params, researchElementDefinitionSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -135,3 +138,9 @@ func (r *researchElementDefinitionRepoPG) Search(ctx context.Context, params map
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("research_element_definition", researchElementDefinitionSearchParams)
+}
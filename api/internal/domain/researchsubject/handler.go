@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/ResearchSubject/:id/_history", h.HistoryResearchSubjectFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("ResearchSubject", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateResearchSubject(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetResearchSubjectFHIR(c echo.Context) error {
 
 func (h *Handler) CreateResearchSubjectFHIR(c echo.Context) error {
 	var r ResearchSubject
-	if err := c.Bind(&r); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ResearchSubject", "", &r); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateResearchSubject(c.Request().Context(), &r); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateResearchSubjectFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateResearchSubjectFHIR(c echo.Context) error {
-	var r ResearchSubject
-	if err := c.Bind(&r); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetResearchSubjectByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ResearchSubject", c.Param("id")))
 	}
-	r.ID = existing.ID
-	r.FHIRID = existing.FHIRID
+	r := *existing
+	if err := fhir.BindResource(c, "ResearchSubject", existing.FHIRID, &r); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateResearchSubject(c.Request().Context(), &r); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ResearchSubject", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateResearchSubject(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -59,3 +59,19 @@ func (r *ResearchSubject) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the subject from a FHIR ResearchSubject resource, the
+// inverse of ToFHIR.
+func (r *ResearchSubject) FromFHIR(data map[string]interface{}) error {
+	rd := fhir.NewResourceReader("ResearchSubject", data)
+	r.Status = rd.CodeValue("status", "candidate", "eligible", "follow-up", "ineligible",
+		"not-registered", "off-study", "on-study", "on-study-intervention", "on-study-observation",
+		"pending-on-study", "potential-candidate", "screening", "withdrawn")
+	r.StudyReference = rd.Reference("study")
+	r.IndividualReference = rd.Reference("individual")
+	r.ConsentReference = rd.Reference("consent")
+	r.PeriodStart, r.PeriodEnd = rd.Period("period")
+	r.AssignedArm = rd.String("assignedArm")
+	r.ActualArm = rd.String("actualArm")
+	return rd.Err()
+}
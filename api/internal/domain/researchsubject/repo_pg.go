@@ -71,7 +71,7 @@ func (r *researchSubjectRepoPG) GetByFHIRID(ctx context.Context, fhirID string)
 func (r *researchSubjectRepoPG) Update(ctx context.Context, rs *ResearchSubject) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE research_subject SET status=$2, study_reference=$3, individual_reference=$4,
-			consent_reference=$5, period_start=$6, period_end=$7, assigned_arm=$8, actual_arm=$9, updated_at=NOW()
+			consent_reference=$5, period_start=$6, period_end=$7, assigned_arm=$8, actual_arm=$9, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		rs.ID, rs.Status, rs.StudyReference, rs.IndividualReference,
 		rs.ConsentReference, rs.PeriodStart, rs.PeriodEnd, rs.AssignedArm, rs.ActualArm)
@@ -112,13 +112,16 @@ var researchSubjectSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *researchSubjectRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ResearchSubject, int, error) {
 	qb := fhir.NewSearchQuery("research_subject", rsCols)
-	qb.ApplyParams(params, researchSubjectSearchParams)
+	qb.ApplyParams(ctx, params, researchSubjectSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -135,3 +138,9 @@ func (r *researchSubjectRepoPG) Search(ctx context.Context, params map[string]st
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("research_subject", researchSubjectSearchParams)
+}
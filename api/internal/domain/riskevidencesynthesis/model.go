@@ -84,3 +84,22 @@ func (e *RiskEvidenceSynthesis) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the synthesis from a FHIR RiskEvidenceSynthesis resource,
+// the inverse of ToFHIR.
+func (e *RiskEvidenceSynthesis) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("RiskEvidenceSynthesis", data)
+	e.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	e.URL = r.String("url")
+	e.Name = r.String("name")
+	e.Title = r.String("title")
+	e.Description = r.String("description")
+	e.Publisher = r.String("publisher")
+	e.Date = r.DateTime("date")
+	e.PopulationReference = r.Reference("population")
+	e.OutcomeReference = r.Reference("outcome")
+	e.SampleSizeDescription = r.String("sampleSize.description")
+	e.RiskEstimateDescription = r.String("riskEstimate.description")
+	e.RiskEstimateValue = r.Decimal("riskEstimate.value")
+	return r.Err()
+}
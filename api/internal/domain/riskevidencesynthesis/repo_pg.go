@@ -76,7 +76,7 @@ func (r *riskEvidenceSynthesisRepoPG) Update(ctx context.Context, e *RiskEvidenc
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE risk_evidence_synthesis SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
 			population_reference=$9, outcome_reference=$10,
-			sample_size_description=$11, risk_estimate_description=$12, risk_estimate_value=$13, updated_at=NOW()
+			sample_size_description=$11, risk_estimate_description=$12, risk_estimate_value=$13, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.URL, e.Name, e.Title, e.Description, e.Publisher, e.Date,
 		e.PopulationReference, e.OutcomeReference,
@@ -118,13 +118,16 @@ var riskEvidenceSynthesisSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *riskEvidenceSynthesisRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*RiskEvidenceSynthesis, int, error) {
 	qb := fhir.NewSearchQuery("risk_evidence_synthesis", resCols)
-	qb.ApplyParams(params, riskEvidenceSynthesisSearchParams)
+	qb.ApplyParams(ctx, params, riskEvidenceSynthesisSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -141,3 +144,9 @@ func (r *riskEvidenceSynthesisRepoPG) Search(ctx context.Context, params map[str
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("risk_evidence_synthesis", riskEvidenceSynthesisSearchParams)
+}
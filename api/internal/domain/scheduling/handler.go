@@ -98,6 +98,13 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/AppointmentResponse/:id/_history", h.HistoryAppointmentResponseFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Schedule", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("Slot", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("Appointment", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("AppointmentResponse", fhir.ReadOnlyInteractions()...)
+}
+
 // -- Schedule Handlers --
 
 func (h *Handler) CreateSchedule(c echo.Context) error {
@@ -455,8 +462,8 @@ func (h *Handler) GetScheduleFHIR(c echo.Context) error {
 
 func (h *Handler) CreateScheduleFHIR(c echo.Context) error {
 	var sched Schedule
-	if err := c.Bind(&sched); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Schedule", "", &sched); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateSchedule(c.Request().Context(), &sched); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -490,8 +497,8 @@ func (h *Handler) GetSlotFHIR(c echo.Context) error {
 
 func (h *Handler) CreateSlotFHIR(c echo.Context) error {
 	var sl Slot
-	if err := c.Bind(&sl); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Slot", "", &sl); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateSlot(c.Request().Context(), &sl); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -525,8 +532,8 @@ func (h *Handler) GetAppointmentFHIR(c echo.Context) error {
 
 func (h *Handler) CreateAppointmentFHIR(c echo.Context) error {
 	var a Appointment
-	if err := c.Bind(&a); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Appointment", "", &a); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateAppointment(c.Request().Context(), &a); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -562,8 +569,8 @@ func (h *Handler) GetAppointmentResponseFHIR(c echo.Context) error {
 
 func (h *Handler) CreateAppointmentResponseFHIR(c echo.Context) error {
 	var ar AppointmentResponse
-	if err := c.Bind(&ar); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "AppointmentResponse", "", &ar); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateAppointmentResponse(c.Request().Context(), &ar); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -573,16 +580,14 @@ func (h *Handler) CreateAppointmentResponseFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateAppointmentResponseFHIR(c echo.Context) error {
-	var ar AppointmentResponse
-	if err := c.Bind(&ar); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetAppointmentResponseByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("AppointmentResponse", c.Param("id")))
 	}
-	ar.ID = existing.ID
-	ar.FHIRID = existing.FHIRID
+	ar := *existing
+	if err := fhir.BindResource(c, "AppointmentResponse", existing.FHIRID, &ar); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateAppointmentResponse(c.Request().Context(), &ar); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -606,11 +611,8 @@ func (h *Handler) PatchAppointmentResponseFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("AppointmentResponse", ctx.Param("id")))
 		}
-		if v, ok := resource["participantStatus"].(string); ok {
-			existing.ParticipantStatus = v
-		}
-		if v, ok := resource["comment"].(string); ok {
-			existing.Comment = &v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateAppointmentResponse(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -646,16 +648,14 @@ func (h *Handler) HistoryAppointmentResponseFHIR(c echo.Context) error {
 // -- FHIR Update Endpoints --
 
 func (h *Handler) UpdateScheduleFHIR(c echo.Context) error {
-	var sched Schedule
-	if err := c.Bind(&sched); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetScheduleByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Schedule", c.Param("id")))
 	}
-	sched.ID = existing.ID
-	sched.FHIRID = existing.FHIRID
+	sched := *existing
+	if err := fhir.BindResource(c, "Schedule", existing.FHIRID, &sched); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateSchedule(c.Request().Context(), &sched); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -663,16 +663,14 @@ func (h *Handler) UpdateScheduleFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateSlotFHIR(c echo.Context) error {
-	var sl Slot
-	if err := c.Bind(&sl); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetSlotByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Slot", c.Param("id")))
 	}
-	sl.ID = existing.ID
-	sl.FHIRID = existing.FHIRID
+	sl := *existing
+	if err := fhir.BindResource(c, "Slot", existing.FHIRID, &sl); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateSlot(c.Request().Context(), &sl); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -680,16 +678,14 @@ func (h *Handler) UpdateSlotFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateAppointmentFHIR(c echo.Context) error {
-	var a Appointment
-	if err := c.Bind(&a); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetAppointmentByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Appointment", c.Param("id")))
 	}
-	a.ID = existing.ID
-	a.FHIRID = existing.FHIRID
+	a := *existing
+	if err := fhir.BindResource(c, "Appointment", existing.FHIRID, &a); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateAppointment(c.Request().Context(), &a); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -739,8 +735,8 @@ func (h *Handler) PatchScheduleFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Schedule", ctx.Param("id")))
 		}
-		if v, ok := resource["comment"].(string); ok {
-			existing.Comment = &v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateSchedule(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -755,8 +751,8 @@ func (h *Handler) PatchSlotFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Slot", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateSlot(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -771,8 +767,8 @@ func (h *Handler) PatchAppointmentFHIR(c echo.Context) error {
 		if err != nil {
 			return ctx.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Appointment", ctx.Param("id")))
 		}
-		if v, ok := resource["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(ctx, resource, existing); err != nil {
+			return ctx.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateAppointment(ctx.Request().Context(), existing); err != nil {
 			return ctx.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -797,25 +793,25 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string, apply
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "Slot":
 		existing, err := h.svc.GetSlotByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "Appointment":
 		existing, err := h.svc.GetAppointmentByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "AppointmentResponse":
 		existing, err := h.svc.GetAppointmentResponseByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	default:
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("unsupported resource type for PATCH"))
 	}
@@ -560,7 +560,7 @@ func TestHandler_GetScheduleFHIR_NotFound(t *testing.T) {
 
 func TestHandler_CreateScheduleFHIR(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"practitioner_id":"` + uuid.New().String() + `"}`
+	body := `{"resourceType":"Schedule","actor":[{"reference":"Practitioner/` + uuid.New().String() + `"}]}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -582,7 +582,7 @@ func TestHandler_UpdateScheduleFHIR(t *testing.T) {
 	h, e := newTestHandler()
 	s := &Schedule{PractitionerID: uuid.New()}
 	h.svc.CreateSchedule(nil, s)
-	body := `{"practitioner_id":"` + s.PractitionerID.String() + `"}`
+	body := `{"resourceType":"Schedule","actor":[{"reference":"Practitioner/` + s.PractitionerID.String() + `"}]}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -712,7 +712,7 @@ func TestHandler_CreateSlotFHIR(t *testing.T) {
 	h, e := newTestHandler()
 	start := time.Now().Format(time.RFC3339)
 	end := time.Now().Add(30 * time.Minute).Format(time.RFC3339)
-	body := `{"schedule_id":"` + uuid.New().String() + `","start_time":"` + start + `","end_time":"` + end + `"}`
+	body := `{"resourceType":"Slot","schedule":{"reference":"Schedule/` + uuid.New().String() + `"},"status":"free","start":"` + start + `","end":"` + end + `"}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -732,7 +732,7 @@ func TestHandler_UpdateSlotFHIR(t *testing.T) {
 	h.svc.CreateSlot(nil, sl)
 	start := time.Now().Format(time.RFC3339)
 	end := time.Now().Add(60 * time.Minute).Format(time.RFC3339)
-	body := `{"schedule_id":"` + sl.ScheduleID.String() + `","start_time":"` + start + `","end_time":"` + end + `"}`
+	body := `{"resourceType":"Slot","schedule":{"reference":"Schedule/` + sl.ScheduleID.String() + `"},"status":"free","start":"` + start + `","end":"` + end + `"}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -876,7 +876,7 @@ func TestHandler_GetAppointmentFHIR_NotFound(t *testing.T) {
 func TestHandler_CreateAppointmentFHIR(t *testing.T) {
 	h, e := newTestHandler()
 	start := time.Now().Format(time.RFC3339)
-	body := `{"patient_id":"` + uuid.New().String() + `","start_time":"` + start + `"}`
+	body := `{"resourceType":"Appointment","start":"` + start + `","participant":[{"actor":{"reference":"Patient/` + uuid.New().String() + `"},"status":"accepted"}]}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -899,7 +899,7 @@ func TestHandler_UpdateAppointmentFHIR(t *testing.T) {
 	start := time.Now()
 	a := &Appointment{PatientID: uuid.New(), StartTime: &start}
 	h.svc.CreateAppointment(nil, a)
-	body := `{"patient_id":"` + a.PatientID.String() + `","start_time":"` + start.Format(time.RFC3339) + `"}`
+	body := `{"resourceType":"Appointment","start":"` + start.Format(time.RFC3339) + `","participant":[{"actor":{"reference":"Patient/` + a.PatientID.String() + `"},"status":"accepted"}]}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -73,6 +73,25 @@ func (s *Schedule) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the schedule from a FHIR Schedule resource, the inverse
+// of ToFHIR.
+func (s *Schedule) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Schedule", data)
+	s.Active = r.Bool("active")
+	s.PractitionerID = r.RequiredReferenceID(r.FindReference("actor", "", "Practitioner"), "Practitioner")
+	s.LocationID = nil
+	if p := r.FindReference("actor", "", "Location"); p != "actor" {
+		s.LocationID = r.ReferenceID(p, "Location")
+	}
+	serviceType := r.CodeableConcept("serviceType")
+	s.ServiceTypeCode, s.ServiceTypeDisplay = serviceType.Code, serviceType.Display
+	specialty := r.CodeableConcept("specialty")
+	s.SpecialtyCode, s.SpecialtyDisplay = specialty.Code, specialty.Display
+	s.PlanningHorizonStart, s.PlanningHorizonEnd = r.Period("planningHorizon")
+	s.Comment = r.String("comment")
+	return r.Err()
+}
+
 // Slot maps to the slot table (FHIR Slot resource).
 type Slot struct {
 	ID                     uuid.UUID  `db:"id" json:"id"`
@@ -138,6 +157,25 @@ func (sl *Slot) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the slot from a FHIR Slot resource, the inverse of
+// ToFHIR.
+func (sl *Slot) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Slot", data)
+	sl.ScheduleID = r.RequiredReferenceID("schedule", "Schedule")
+	sl.Status = r.CodeValue("status", "busy", "free", "busy-unavailable", "busy-tentative", "entered-in-error")
+	sl.StartTime = r.RequiredDateTime("start")
+	sl.EndTime = r.RequiredDateTime("end")
+	sl.Overbooked = r.Bool("overbooked")
+	serviceType := r.CodeableConcept("serviceType")
+	sl.ServiceTypeCode, sl.ServiceTypeDisplay = serviceType.Code, serviceType.Display
+	specialty := r.CodeableConcept("specialty")
+	sl.SpecialtyCode, sl.SpecialtyDisplay = specialty.Code, specialty.Display
+	appointmentType := r.CodeableConcept("appointmentType")
+	sl.AppointmentTypeCode, sl.AppointmentTypeDisplay = appointmentType.Code, appointmentType.Display
+	sl.Comment = r.String("comment")
+	return r.Err()
+}
+
 // Appointment maps to the appointment table (FHIR Appointment resource).
 type Appointment struct {
 	ID                     uuid.UUID  `db:"id" json:"id"`
@@ -261,6 +299,42 @@ func (a *Appointment) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the appointment from a FHIR Appointment resource, the
+// inverse of ToFHIR. The patient, practitioner and location are taken from
+// the participant actors.
+func (a *Appointment) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Appointment", data)
+	a.Status = r.CodeValue("status", "proposed", "pending", "booked", "arrived", "fulfilled",
+		"cancelled", "noshow", "entered-in-error", "checked-in", "waitlist")
+	serviceType := r.CodeableConcept("serviceType")
+	a.ServiceTypeCode, a.ServiceTypeDisplay = serviceType.Code, serviceType.Display
+	specialty := r.CodeableConcept("specialty")
+	a.SpecialtyCode, a.SpecialtyDisplay = specialty.Code, specialty.Display
+	appointmentType := r.CodeableConcept("appointmentType")
+	a.AppointmentTypeCode, a.AppointmentTypeDisplay = appointmentType.Code, appointmentType.Display
+	a.Priority = r.Int("priority")
+	a.Description = r.String("description")
+	start := r.RequiredDateTime("start")
+	a.StartTime = &start
+	a.EndTime = r.DateTime("end")
+	a.MinutesDuration = r.Int("minutesDuration")
+	a.SlotID = r.ReferenceID("slot", "Slot")
+	reason := r.CodeableConcept("reasonCode")
+	a.ReasonCode, a.ReasonDisplay = reason.Code, reason.Display
+	a.CancellationReason = r.CodeableConcept("cancelationReason").Text
+	a.PatientID = r.RequiredReferenceID(r.FindReference("participant", "actor", "Patient"), "Patient")
+	a.PractitionerID, a.LocationID = nil, nil
+	if p := r.FindReference("participant", "actor", "Practitioner"); p != "participant.actor" {
+		a.PractitionerID = r.ReferenceID(p, "Practitioner")
+	}
+	if p := r.FindReference("participant", "actor", "Location"); p != "participant.actor" {
+		a.LocationID = r.ReferenceID(p, "Location")
+	}
+	a.Note = r.String("comment")
+	a.PatientInstruction = r.String("patientInstruction")
+	return r.Err()
+}
+
 // AppointmentParticipant maps to the appointment_participant table.
 type AppointmentParticipant struct {
 	ID            uuid.UUID  `db:"id" json:"id"`
@@ -322,6 +396,23 @@ func (ar *AppointmentResponse) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the response from a FHIR AppointmentResponse resource,
+// the inverse of ToFHIR. The actor type is taken from the actor reference.
+func (ar *AppointmentResponse) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("AppointmentResponse", data)
+	ar.AppointmentID = r.RequiredReferenceID("appointment", "Appointment")
+	ar.ActorID = r.RequiredReferenceID("actor", "Patient", "Practitioner", "PractitionerRole",
+		"RelatedPerson", "Device", "HealthcareService", "Location")
+	if ref := r.Reference("actor"); ref != nil {
+		ar.ActorType, _, _ = fhir.SplitReference(*ref)
+	}
+	ar.ParticipantStatus = r.RequiredCode("participantStatus", "accepted", "declined", "tentative", "needs-action")
+	ar.Comment = r.String("comment")
+	ar.StartTime = r.DateTime("start")
+	ar.EndTime = r.DateTime("end")
+	return r.Err()
+}
+
 // Waitlist maps to the waitlist table.
 type Waitlist struct {
 	ID                 uuid.UUID  `db:"id" json:"id"`
@@ -1,9 +1,11 @@
 package scheduling
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/ehr/ehr/internal/platform/fhir"
 	"github.com/google/uuid"
 )
 
@@ -440,3 +442,123 @@ func TestAppointmentToFHIR_ParticipantWithPractitioner(t *testing.T) {
 		t.Errorf("participant count = %d, want 2 (patient + practitioner)", len(participants))
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestAppointment_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &Appointment{
+		FHIRID:             "appt-rt",
+		Status:             "booked",
+		ServiceTypeCode:    ptrStr("124"),
+		ServiceTypeDisplay: ptrStr("General Practice"),
+		Priority:           ptrInt(5),
+		StartTime:          ptrTime(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)),
+		EndTime:            ptrTime(time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)),
+		MinutesDuration:    ptrInt(30),
+		SlotID:             ptrUUID(uuid.New()),
+		PatientID:          uuid.New(),
+		PractitionerID:     ptrUUID(uuid.New()),
+		LocationID:         ptrUUID(uuid.New()),
+		CancellationReason: ptrStr("patient request"),
+		Note:               ptrStr("bring referral"),
+	}
+
+	var got Appointment
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Status != "booked" || *got.ServiceTypeCode != "124" || *got.Priority != 5 || *got.MinutesDuration != 30 {
+		t.Errorf("scalar mismatch: %+v", got)
+	}
+	if !got.StartTime.Equal(*orig.StartTime) || !got.EndTime.Equal(*orig.EndTime) {
+		t.Errorf("start/end mismatch: %v - %v", got.StartTime, got.EndTime)
+	}
+	if got.PatientID != orig.PatientID || *got.PractitionerID != *orig.PractitionerID ||
+		*got.LocationID != *orig.LocationID || *got.SlotID != *orig.SlotID {
+		t.Errorf("participant/slot mismatch: %+v", got)
+	}
+	if *got.CancellationReason != "patient request" || *got.Note != "bring referral" {
+		t.Errorf("cancelation/comment mismatch: %+v", got)
+	}
+}
+
+func TestSchedule_FromFHIR_Actors(t *testing.T) {
+	orig := &Schedule{
+		PractitionerID: uuid.New(),
+		LocationID:     ptrUUID(uuid.New()),
+		Active:         ptrBool(true),
+	}
+
+	got := Schedule{LocationID: ptrUUID(uuid.New())}
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.PractitionerID != orig.PractitionerID || got.LocationID == nil || *got.LocationID != *orig.LocationID {
+		t.Errorf("actor mismatch: %+v", got)
+	}
+
+	orig.LocationID = nil
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.LocationID != nil {
+		t.Errorf("expected location to be cleared, got %v", got.LocationID)
+	}
+}
+
+func TestAppointmentResponse_FromFHIR_ActorType(t *testing.T) {
+	actorID := uuid.New()
+	var ar AppointmentResponse
+	err := ar.FromFHIR(map[string]interface{}{
+		"resourceType":      "AppointmentResponse",
+		"appointment":       map[string]interface{}{"reference": "Appointment/" + uuid.New().String()},
+		"actor":             map[string]interface{}{"reference": "Practitioner/" + actorID.String()},
+		"participantStatus": "tentative",
+	})
+	if err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if ar.ActorType != "Practitioner" || ar.ActorID != actorID || ar.ParticipantStatus != "tentative" {
+		t.Errorf("unexpected response: %+v", ar)
+	}
+}
+
+func TestSlot_FromFHIR_InvalidElements(t *testing.T) {
+	var sl Slot
+	err := sl.FromFHIR(map[string]interface{}{
+		"resourceType": "Slot",
+		"status":       "open",
+		"start":        "not-a-date",
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	want := map[string]bool{
+		"Slot.status":   true,
+		"Slot.schedule": true,
+		"Slot.start":    true,
+		"Slot.end":      true,
+	}
+	for _, issue := range pe.Outcome.Issue {
+		delete(want, issue.Expression[0])
+	}
+	if len(want) != 0 {
+		t.Errorf("missing issues for %v in %+v", want, pe.Outcome.Issue)
+	}
+}
@@ -74,7 +74,7 @@ func (r *scheduleRepoPG) Update(ctx context.Context, s *Schedule) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE schedule SET active=$2, location_id=$3, service_type_code=$4, service_type_display=$5,
 			specialty_code=$6, specialty_display=$7, planning_horizon_start=$8, planning_horizon_end=$9,
-			comment=$10, updated_at=NOW()
+			comment=$10, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		s.ID, s.Active, s.LocationID, s.ServiceTypeCode, s.ServiceTypeDisplay,
 		s.SpecialtyCode, s.SpecialtyDisplay, s.PlanningHorizonStart, s.PlanningHorizonEnd, s.Comment)
@@ -115,13 +115,16 @@ var scheduleSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *scheduleRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Schedule, int, error) {
 	qb := fhir.NewSearchQuery("schedule", schedCols)
-	qb.ApplyParams(params, scheduleSearchParams)
+	qb.ApplyParams(ctx, params, scheduleSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -195,7 +198,7 @@ func (r *slotRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Slot, err
 
 func (r *slotRepoPG) Update(ctx context.Context, sl *Slot) error {
 	_, err := r.conn(ctx).Exec(ctx, `
-		UPDATE slot SET status=$2, overbooked=$3, comment=$4, updated_at=NOW()
+		UPDATE slot SET status=$2, overbooked=$3, comment=$4, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		sl.ID, sl.Status, sl.Overbooked, sl.Comment)
 	return err
@@ -236,13 +239,16 @@ var slotSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *slotRepoPG) SearchAvailable(ctx context.Context, params map[string]string, limit, offset int) ([]*Slot, int, error) {
 	qb := fhir.NewSearchQuery("slot", slotCols)
-	qb.ApplyParams(params, slotSearchParams)
+	qb.ApplyParams(ctx, params, slotSearchParams)
 	qb.OrderBy("start_time ASC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -337,7 +343,7 @@ func (r *appointmentRepoPG) Update(ctx context.Context, a *Appointment) error {
 		UPDATE appointment SET status=$2, cancellation_reason=$3, start_time=$4, end_time=$5,
 			minutes_duration=$6, practitioner_id=$7, location_id=$8,
 			reason_code=$9, reason_display=$10, note=$11, patient_instruction=$12,
-			is_telehealth=$13, telehealth_url=$14, updated_at=NOW()
+			is_telehealth=$13, telehealth_url=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		a.ID, a.Status, a.CancellationReason, a.StartTime, a.EndTime,
 		a.MinutesDuration, a.PractitionerID, a.LocationID,
@@ -403,13 +409,16 @@ var appointmentSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *appointmentRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Appointment, int, error) {
 	qb := fhir.NewSearchQuery("appointment", apptCols)
-	qb.ApplyParams(params, appointmentSearchParams)
+	qb.ApplyParams(ctx, params, appointmentSearchParams)
 	qb.OrderBy("start_time DESC NULLS LAST")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -623,7 +632,7 @@ func (r *appointmentResponseRepoPG) GetByFHIRID(ctx context.Context, fhirID stri
 func (r *appointmentResponseRepoPG) Update(ctx context.Context, ar *AppointmentResponse) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE appointment_response SET participant_status=$2, comment=$3,
-			start_time=$4, end_time=$5, updated_at=NOW()
+			start_time=$4, end_time=$5, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ar.ID, ar.ParticipantStatus, ar.Comment, ar.StartTime, ar.EndTime)
 	return err
@@ -662,13 +671,16 @@ var appointmentResponseSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *appointmentResponseRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*AppointmentResponse, int, error) {
 	qb := fhir.NewSearchQuery("appointment_response", apptRespCols)
-	qb.ApplyParams(params, appointmentResponseSearchParams)
+	qb.ApplyParams(ctx, params, appointmentResponseSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -685,3 +697,12 @@ func (r *appointmentResponseRepoPG) Search(ctx context.Context, params map[strin
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("schedule", scheduleSearchParams)
+	r.RegisterTable("slot", slotSearchParams)
+	r.RegisterTable("appointment", appointmentSearchParams)
+	r.RegisterTable("appointment_response", appointmentResponseSearchParams)
+}
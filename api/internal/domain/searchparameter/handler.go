@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/SearchParameter/:id/_history", h.HistorySearchParameterFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("SearchParameter", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateSearchParameter(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetSearchParameterFHIR(c echo.Context) error {
 
 func (h *Handler) CreateSearchParameterFHIR(c echo.Context) error {
 	var sp SearchParameter
-	if err := c.Bind(&sp); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "SearchParameter", "", &sp); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateSearchParameter(c.Request().Context(), &sp); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateSearchParameterFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateSearchParameterFHIR(c echo.Context) error {
-	var sp SearchParameter
-	if err := c.Bind(&sp); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetSearchParameterByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("SearchParameter", c.Param("id")))
 	}
-	sp.ID = existing.ID
-	sp.FHIRID = existing.FHIRID
+	sp := *existing
+	if err := fhir.BindResource(c, "SearchParameter", existing.FHIRID, &sp); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateSearchParameter(c.Request().Context(), &sp); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("SearchParameter", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateSearchParameter(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -62,6 +62,32 @@ func (s *SearchParameter) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the search parameter from a FHIR SearchParameter
+// resource, the inverse of ToFHIR. The base and target lists are stored
+// comma-separated.
+func (s *SearchParameter) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("SearchParameter", data)
+	s.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	s.URL = r.RequiredString("url")
+	s.Name = r.RequiredString("name")
+	s.Description = r.RequiredString("description")
+	s.Code = r.RequiredString("code")
+	s.Base = strings.Join(r.Strings("base"), ",")
+	if s.Base == "" {
+		r.Invalid("base", "SearchParameter.base is required")
+	}
+	s.Type = r.RequiredCode("type", "number", "date", "string", "token", "reference",
+		"composite", "quantity", "uri", "special")
+	s.Expression = r.String("expression")
+	s.XPath = r.String("xpath")
+	s.Target = nil
+	if targets := r.Strings("target"); len(targets) > 0 {
+		target := strings.Join(targets, ",")
+		s.Target = &target
+	}
+	return r.Err()
+}
+
 func splitComma(s string) []string {
 	parts := strings.Split(s, ",")
 	var result []string
@@ -76,7 +76,7 @@ func (r *searchParameterRepoPG) Update(ctx context.Context, s *SearchParameter)
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE search_parameter SET status=$2, url=$3, name=$4, description=$5, code=$6,
 			base=$7, type=$8, expression=$9, xpath=$10, target=$11, modifier=$12, comparator=$13,
-			publisher=$14, date=$15, updated_at=NOW()
+			publisher=$14, date=$15, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		s.ID, s.Status, s.URL, s.Name, s.Description, s.Code,
 		s.Base, s.Type, s.Expression, s.XPath, s.Target, s.Modifier, s.Comparator,
@@ -121,13 +121,16 @@ var searchParameterSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *searchParameterRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*SearchParameter, int, error) {
 	qb := fhir.NewSearchQuery("search_parameter", spCols)
-	qb.ApplyParams(params, searchParameterSearchParams)
+	qb.ApplyParams(ctx, params, searchParameterSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -144,3 +147,9 @@ func (r *searchParameterRepoPG) Search(ctx context.Context, params map[string]st
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("search_parameter", searchParameterSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/SpecimenDefinition/:id/_history", h.HistorySpecimenDefinitionFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("SpecimenDefinition", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateSpecimenDefinition(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetSpecimenDefinitionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateSpecimenDefinitionFHIR(c echo.Context) error {
 	var s SpecimenDefinition
-	if err := c.Bind(&s); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "SpecimenDefinition", "", &s); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateSpecimenDefinition(c.Request().Context(), &s); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateSpecimenDefinitionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateSpecimenDefinitionFHIR(c echo.Context) error {
-	var s SpecimenDefinition
-	if err := c.Bind(&s); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetSpecimenDefinitionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("SpecimenDefinition", c.Param("id")))
 	}
-	s.ID = existing.ID
-	s.FHIRID = existing.FHIRID
+	s := *existing
+	if err := fhir.BindResource(c, "SpecimenDefinition", existing.FHIRID, &s); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateSpecimenDefinition(c.Request().Context(), &s); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("SpecimenDefinition", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["timeAspect"].(string); ok {
-		existing.TimeAspect = &v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateSpecimenDefinition(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -86,6 +86,28 @@ func (s *SpecimenDefinition) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the definition from a FHIR SpecimenDefinition resource,
+// the inverse of ToFHIR. Only the first tested type's handling is read.
+func (s *SpecimenDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("SpecimenDefinition", data)
+	typeCollected := r.CodeableConcept("typeCollected")
+	s.TypeCode, s.TypeDisplay = typeCollected.Code, typeCollected.Display
+	s.PatientPreparation = r.ConceptCode("patientPreparation")
+	s.TimeAspect = r.String("timeAspect")
+	collection := r.CodeableConcept("collection")
+	s.CollectionCode, s.CollectionDisplay = collection.Code, collection.Display
+	low := r.Quantity("typeTested.handling.temperatureRange.low")
+	high := r.Quantity("typeTested.handling.temperatureRange.high")
+	s.HandlingTemperatureLow, s.HandlingTemperatureHigh = low.Value, high.Value
+	s.HandlingTemperatureUnit = low.Unit
+	if s.HandlingTemperatureUnit == nil {
+		s.HandlingTemperatureUnit = high.Unit
+	}
+	s.HandlingMaxDuration = r.String("typeTested.handling.maxDuration.value")
+	s.HandlingInstruction = r.String("typeTested.handling.instruction")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
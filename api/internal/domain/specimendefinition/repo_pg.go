@@ -86,7 +86,7 @@ func (r *specimenDefinitionRepoPG) Update(ctx context.Context, s *SpecimenDefini
 			patient_preparation=$4, time_aspect=$5,
 			collection_code=$6, collection_display=$7,
 			handling_temperature_low=$8, handling_temperature_high=$9, handling_temperature_unit=$10,
-			handling_max_duration=$11, handling_instruction=$12, updated_at=NOW()
+			handling_max_duration=$11, handling_instruction=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		s.ID, s.TypeCode, s.TypeDisplay,
 		s.PatientPreparation, s.TimeAspect,
@@ -128,13 +128,16 @@ var specimenDefinitionSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *specimenDefinitionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*SpecimenDefinition, int, error) {
 	qb := fhir.NewSearchQuery("specimen_definition", sdCols)
-	qb.ApplyParams(params, specimenDefinitionSearchParams)
+	qb.ApplyParams(ctx, params, specimenDefinitionSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -151,3 +154,9 @@ func (r *specimenDefinitionRepoPG) Search(ctx context.Context, params map[string
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("specimen_definition", specimenDefinitionSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/StructureDefinition/:id/_history", h.HistoryStructureDefinitionFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("StructureDefinition", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateStructureDefinition(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetStructureDefinitionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateStructureDefinitionFHIR(c echo.Context) error {
 	var sd StructureDefinition
-	if err := c.Bind(&sd); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "StructureDefinition", "", &sd); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateStructureDefinition(c.Request().Context(), &sd); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateStructureDefinitionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateStructureDefinitionFHIR(c echo.Context) error {
-	var sd StructureDefinition
-	if err := c.Bind(&sd); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetStructureDefinitionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("StructureDefinition", c.Param("id")))
 	}
-	sd.ID = existing.ID
-	sd.FHIRID = existing.FHIRID
+	sd := *existing
+	if err := fhir.BindResource(c, "StructureDefinition", existing.FHIRID, &sd); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateStructureDefinition(c.Request().Context(), &sd); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("StructureDefinition", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateStructureDefinition(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -69,3 +69,22 @@ func (s *StructureDefinition) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the structure definition from a FHIR StructureDefinition
+// resource, the inverse of ToFHIR.
+func (s *StructureDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("StructureDefinition", data)
+	s.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	s.URL = r.RequiredString("url")
+	s.Name = r.RequiredString("name")
+	s.Kind = r.RequiredCode("kind", "primitive-type", "complex-type", "resource", "logical")
+	s.Abstract = r.BoolValue("abstract")
+	s.Type = r.RequiredString("type")
+	s.BaseDefinition = r.String("baseDefinition")
+	s.Derivation = r.Code("derivation", "specialization", "constraint")
+	s.Title = r.String("title")
+	s.Description = r.String("description")
+	s.Publisher = r.String("publisher")
+	s.Date = r.DateTime("date")
+	return r.Err()
+}
@@ -71,7 +71,7 @@ func (r *structureDefinitionRepoPG) GetByFHIRID(ctx context.Context, fhirID stri
 func (r *structureDefinitionRepoPG) Update(ctx context.Context, s *StructureDefinition) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE structure_definition SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
-			kind=$9, abstract=$10, type=$11, base_definition=$12, derivation=$13, context_type=$14, updated_at=NOW()
+			kind=$9, abstract=$10, type=$11, base_definition=$12, derivation=$13, context_type=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		s.ID, s.Status, s.URL, s.Name, s.Title, s.Description, s.Publisher, s.Date,
 		s.Kind, s.Abstract, s.Type, s.BaseDefinition, s.Derivation, s.ContextType)
@@ -115,13 +115,16 @@ var structureDefinitionSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *structureDefinitionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*StructureDefinition, int, error) {
 	qb := fhir.NewSearchQuery("structure_definition", sdCols)
-	qb.ApplyParams(params, structureDefinitionSearchParams)
+	qb.ApplyParams(ctx, params, structureDefinitionSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -138,3 +141,9 @@ func (r *structureDefinitionRepoPG) Search(ctx context.Context, params map[strin
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("structure_definition", structureDefinitionSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/StructureMap/:id/_history", h.HistoryStructureMapFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("StructureMap", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateStructureMap(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetStructureMapFHIR(c echo.Context) error {
 
 func (h *Handler) CreateStructureMapFHIR(c echo.Context) error {
 	var sm StructureMap
-	if err := c.Bind(&sm); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "StructureMap", "", &sm); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateStructureMap(c.Request().Context(), &sm); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateStructureMapFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateStructureMapFHIR(c echo.Context) error {
-	var sm StructureMap
-	if err := c.Bind(&sm); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetStructureMapByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("StructureMap", c.Param("id")))
 	}
-	sm.ID = existing.ID
-	sm.FHIRID = existing.FHIRID
+	sm := *existing
+	if err := fhir.BindResource(c, "StructureMap", existing.FHIRID, &sm); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateStructureMap(c.Request().Context(), &sm); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("StructureMap", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateStructureMap(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -70,6 +70,23 @@ func (sm *StructureMap) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the map from a FHIR StructureMap resource, the inverse
+// of ToFHIR. Only the first structure and import are read.
+func (sm *StructureMap) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("StructureMap", data)
+	sm.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	sm.URL = r.RequiredString("url")
+	sm.Name = r.RequiredString("name")
+	sm.Title = r.String("title")
+	sm.Description = r.String("description")
+	sm.Publisher = r.String("publisher")
+	sm.Date = r.DateTime("date")
+	sm.StructureURL = r.String("structure.url")
+	sm.StructureMode = r.Code("structure.mode", "source", "queried", "target", "produced")
+	sm.ImportURI = r.String("import")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
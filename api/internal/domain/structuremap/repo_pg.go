@@ -71,7 +71,7 @@ func (r *structureMapRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*S
 func (r *structureMapRepoPG) Update(ctx context.Context, sm *StructureMap) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE structure_map SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
-			structure_url=$9, structure_mode=$10, import_uri=$11, updated_at=NOW()
+			structure_url=$9, structure_mode=$10, import_uri=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		sm.ID, sm.Status, sm.URL, sm.Name, sm.Title, sm.Description, sm.Publisher, sm.Date,
 		sm.StructureURL, sm.StructureMode, sm.ImportURI)
@@ -112,13 +112,16 @@ var structureMapSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *structureMapRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*StructureMap, int, error) {
 	qb := fhir.NewSearchQuery("structure_map", smCols)
-	qb.ApplyParams(params, structureMapSearchParams)
+	qb.ApplyParams(ctx, params, structureMapSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -135,3 +138,9 @@ func (r *structureMapRepoPG) Search(ctx context.Context, params map[string]strin
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("structure_map", structureMapSearchParams)
+}
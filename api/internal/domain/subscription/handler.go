@@ -54,6 +54,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Subscription/:id/_history", h.HistorySubscriptionFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Subscription", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST handlers --
 
 func (h *Handler) CreateSubscription(c echo.Context) error {
@@ -156,8 +160,8 @@ func (h *Handler) GetSubscriptionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateSubscriptionFHIR(c echo.Context) error {
 	var sub Subscription
-	if err := c.Bind(&sub); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Subscription", "", &sub); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateSubscription(c.Request().Context(), &sub); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -167,17 +171,14 @@ func (h *Handler) CreateSubscriptionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateSubscriptionFHIR(c echo.Context) error {
-	var sub Subscription
-	if err := c.Bind(&sub); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetSubscriptionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Subscription", c.Param("id")))
 	}
-	sub.ID = existing.ID
-	sub.FHIRID = existing.FHIRID
-	sub.VersionID = existing.VersionID
+	sub := *existing
+	if err := fhir.BindResource(c, "Subscription", existing.FHIRID, &sub); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateSubscription(c.Request().Context(), &sub); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -206,7 +207,7 @@ func (h *Handler) PatchSubscriptionFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Subscription", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -232,8 +233,8 @@ func (h *Handler) PatchSubscriptionFHIR(c echo.Context) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateSubscription(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -60,7 +60,7 @@ func TestGetSubscriptionFHIR_NotFound(t *testing.T) {
 
 func TestCreateSubscriptionFHIR_Success(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"criteria":"Observation?code=1234","channel_endpoint":"https://example.com/webhook"}`
+	body := `{"resourceType":"Subscription","criteria":"Observation?code=1234","channel":{"type":"rest-hook","endpoint":"https://example.com/webhook"}}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -271,7 +271,7 @@ func TestUpdateSubscriptionFHIR_Success(t *testing.T) {
 	h, e := newTestHandler()
 	sub := &Subscription{Criteria: "Observation", ChannelEndpoint: "https://example.com/webhook"}
 	h.svc.CreateSubscription(nil, sub)
-	body := `{"criteria":"Observation","channel_endpoint":"https://example.com/webhook","status":"active"}`
+	body := `{"resourceType":"Subscription","criteria":"Observation","channel":{"type":"rest-hook","endpoint":"https://example.com/webhook"},"status":"active"}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -470,7 +470,7 @@ func TestHistorySubscriptionFHIR_NotFound(t *testing.T) {
 
 func TestCreateSubscriptionFHIR_ValidationError(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"channel_endpoint":"https://example.com/webhook"}`
+	body := `{"resourceType":"Subscription","channel":{"type":"rest-hook","endpoint":"https://example.com/webhook"}}`
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
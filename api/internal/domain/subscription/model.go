@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/ehr/ehr/internal/platform/fhir"
 	"github.com/google/uuid"
 )
 
@@ -66,6 +67,24 @@ func (s *Subscription) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the subscription from a FHIR Subscription resource, the
+// inverse of ToFHIR.
+func (s *Subscription) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Subscription", data)
+	s.Status = r.CodeValue("status", "requested", "active", "error", "off")
+	s.Criteria = r.RequiredString("criteria")
+	s.ChannelType = r.CodeValue("channel.type", "rest-hook", "websocket", "email", "sms", "message")
+	s.ChannelEndpoint = r.RequiredString("channel.endpoint")
+	s.ChannelPayload = r.StringValue("channel.payload")
+	s.ChannelHeaders = nil
+	if headers := r.Strings("channel.header"); len(headers) > 0 {
+		s.ChannelHeaders, _ = json.Marshal(headers)
+	}
+	s.EndTime = r.DateTime("end")
+	s.ErrorText = r.String("error")
+	return r.Err()
+}
+
 // SubscriptionNotification tracks individual webhook delivery attempts.
 type SubscriptionNotification struct {
 	ID             uuid.UUID       `db:"id" json:"id"`
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ehr/ehr/internal/platform/fhir"
 	"github.com/google/uuid"
 )
 
@@ -369,3 +370,62 @@ func TestSubscriptionNotification_JSONRoundTrip(t *testing.T) {
 		t.Errorf("expected event type 'update', got %q", decoded.EventType)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestSubscription_FromFHIR_RoundTrip(t *testing.T) {
+	orig := &Subscription{
+		FHIRID:          "sub-rt",
+		Status:          "active",
+		Criteria:        "Observation?code=1234",
+		ChannelType:     "rest-hook",
+		ChannelEndpoint: "https://example.com/webhook",
+		ChannelPayload:  "application/fhir+json",
+		ChannelHeaders:  json.RawMessage(`["Authorization: Bearer abc"]`),
+	}
+
+	var got Subscription
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Status != "active" || got.Criteria != orig.Criteria {
+		t.Errorf("status/criteria mismatch: %+v", got)
+	}
+	if got.ChannelType != "rest-hook" || got.ChannelEndpoint != orig.ChannelEndpoint || got.ChannelPayload != orig.ChannelPayload {
+		t.Errorf("channel mismatch: %+v", got)
+	}
+	if string(got.ChannelHeaders) != `["Authorization: Bearer abc"]` {
+		t.Errorf("headers mismatch: %s", got.ChannelHeaders)
+	}
+}
+
+func TestSubscription_FromFHIR_InvalidChannelType(t *testing.T) {
+	var sub Subscription
+	err := sub.FromFHIR(map[string]interface{}{
+		"resourceType": "Subscription",
+		"criteria":     "Observation",
+		"channel":      map[string]interface{}{"type": "pigeon", "endpoint": "https://example.com/webhook"},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	if len(pe.Outcome.Issue) != 1 || pe.Outcome.Issue[0].Expression[0] != "Subscription.channel.type" {
+		t.Errorf("unexpected issues: %+v", pe.Outcome.Issue)
+	}
+}
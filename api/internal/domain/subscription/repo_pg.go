@@ -98,13 +98,16 @@ var subscriptionSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *subscriptionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Subscription, int, error) {
 	qb := fhir.NewSearchQuery("subscription", subCols)
-	qb.ApplyParams(params, subscriptionSearchParams)
+	qb.ApplyParams(ctx, params, subscriptionSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -157,7 +160,7 @@ func (r *subscriptionRepoPG) ListExpired(ctx context.Context) ([]*Subscription,
 }
 
 func (r *subscriptionRepoPG) UpdateStatus(ctx context.Context, id uuid.UUID, status string, errorText *string) error {
-	_, err := r.conn(ctx).Exec(ctx, `UPDATE subscription SET status=$2, error_text=$3, updated_at=NOW() WHERE id = $1`, id, status, errorText)
+	_, err := r.conn(ctx).Exec(ctx, `UPDATE subscription SET status=$2, error_text=$3, version_id=version_id+1, updated_at=NOW() WHERE id = $1`, id, status, errorText)
 	return err
 }
 
@@ -245,3 +248,9 @@ func (r *subscriptionRepoPG) DeleteOldNotifications(ctx context.Context, before
 	}
 	return tag.RowsAffected(), nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("subscription", subscriptionSearchParams)
+}
@@ -48,6 +48,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Substance/:id/_history", h.HistorySubstanceFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Substance", fhir.ReadOnlyInteractions()...)
+}
+
 func (h *Handler) CreateSubstance(c echo.Context) error {
 	var s Substance
 	if err := c.Bind(&s); err != nil {
@@ -120,8 +124,8 @@ func (h *Handler) GetSubstanceFHIR(c echo.Context) error {
 
 func (h *Handler) CreateSubstanceFHIR(c echo.Context) error {
 	var s Substance
-	if err := c.Bind(&s); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Substance", "", &s); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateSubstance(c.Request().Context(), &s); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -131,16 +135,14 @@ func (h *Handler) CreateSubstanceFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateSubstanceFHIR(c echo.Context) error {
-	var s Substance
-	if err := c.Bind(&s); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetSubstanceByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Substance", c.Param("id")))
 	}
-	s.ID = existing.ID
-	s.FHIRID = existing.FHIRID
+	s := *existing
+	if err := fhir.BindResource(c, "Substance", existing.FHIRID, &s); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateSubstance(c.Request().Context(), &s); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -196,7 +198,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Substance", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -220,8 +222,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateSubstance(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -57,6 +57,22 @@ func (s *Substance) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the substance from a FHIR Substance resource, the inverse
+// of ToFHIR.
+func (s *Substance) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Substance", data)
+	s.Status = r.CodeValue("status", "active", "inactive", "entered-in-error")
+	code := r.CodeableConcept("code")
+	s.CodeCode = r.RequiredString("code.coding.code")
+	s.CodeDisplay, s.CodeSystem = code.Display, code.System
+	category := r.CodeableConcept("category")
+	s.CategoryCode, s.CategoryDisplay = category.Code, category.Display
+	s.Description = r.String("description")
+	quantity := r.Quantity("quantity")
+	s.QuantityValue, s.QuantityUnit = quantity.Value, quantity.Unit
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
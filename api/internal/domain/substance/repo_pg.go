@@ -77,7 +77,7 @@ func (r *substanceRepoPG) Update(ctx context.Context, s *Substance) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE substance SET status=$2, category_code=$3, category_display=$4,
 			code_code=$5, code_display=$6, code_system=$7, description=$8, expiry=$9,
-			quantity_value=$10, quantity_unit=$11, updated_at=NOW()
+			quantity_value=$10, quantity_unit=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		s.ID, s.Status, s.CategoryCode, s.CategoryDisplay,
 		s.CodeCode, s.CodeDisplay, s.CodeSystem, s.Description, s.Expiry,
@@ -118,13 +118,16 @@ var substanceSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *substanceRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Substance, int, error) {
 	qb := fhir.NewSearchQuery("substance", subCols)
-	qb.ApplyParams(params, substanceSearchParams)
+	qb.ApplyParams(ctx, params, substanceSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -141,3 +144,9 @@ func (r *substanceRepoPG) Search(ctx context.Context, params map[string]string,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("substance", substanceSearchParams)
+}
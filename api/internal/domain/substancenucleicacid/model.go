@@ -42,4 +42,17 @@ func (m *SubstanceNucleicAcid) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the nucleic acid from a FHIR SubstanceNucleicAcid
+// resource, the inverse of ToFHIR.
+func (m *SubstanceNucleicAcid) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("SubstanceNucleicAcid", data)
+	sequenceType := r.CodeableConcept("sequenceType")
+	m.SequenceTypeCode, m.SequenceTypeDisplay = sequenceType.Code, sequenceType.Display
+	m.NumberOfSubunits = r.Int("numberOfSubunits")
+	m.AreaOfHybridisation = r.String("areaOfHybridisation")
+	oligo := r.CodeableConcept("oligoNucleotideType")
+	m.OligoNucleotideTypeCode, m.OligoNucleotideTypeDisplay = oligo.Code, oligo.Display
+	return r.Err()
+}
+
 func strVal(s *string) string { if s == nil { return "" }; return *s }
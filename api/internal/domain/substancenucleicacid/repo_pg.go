@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ehr/ehr/internal/platform/db"
+	"github.com/ehr/ehr/internal/platform/fhir"
 )
 
 type queryable interface {
@@ -59,7 +60,7 @@ func (r *snaRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*SubstanceN
 func (r *snaRepoPG) Update(ctx context.Context, m *SubstanceNucleicAcid) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE substance_nucleic_acid SET sequence_type_code=$2, sequence_type_display=$3, number_of_subunits=$4, area_of_hybridisation=$5,
-			oligo_nucleotide_type_code=$6, oligo_nucleotide_type_display=$7, updated_at=NOW()
+			oligo_nucleotide_type_code=$6, oligo_nucleotide_type_display=$7, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.SequenceTypeCode, m.SequenceTypeDisplay, m.NumberOfSubunits, m.AreaOfHybridisation,
 		m.OligoNucleotideTypeCode, m.OligoNucleotideTypeDisplay)
@@ -82,7 +83,9 @@ func (r *snaRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	query := `SELECT ` + snaCols + ` FROM substance_nucleic_acid WHERE 1=1`
 	countQuery := `SELECT COUNT(*) FROM substance_nucleic_acid WHERE 1=1`
 	var args []interface{}; idx := 1
-	_ = params
+	metaWhere, metaArgs := fhir.MetaSearchSQL("SubstanceNucleicAcid", params, idx)
+	for _, w := range metaWhere { query += " AND " + w; countQuery += " AND " + w }
+	args = append(args, metaArgs...); idx += len(metaArgs)
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, countQuery, args...).Scan(&total); err != nil { return nil, 0, err }
 	query += fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, idx, idx+1); args = append(args, limit, offset)
@@ -43,4 +43,18 @@ func (m *SubstancePolymer) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the polymer from a FHIR SubstancePolymer resource, the
+// inverse of ToFHIR.
+func (m *SubstancePolymer) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("SubstancePolymer", data)
+	class := r.CodeableConcept("class")
+	m.ClassCode, m.ClassDisplay = class.Code, class.Display
+	geometry := r.CodeableConcept("geometry")
+	m.GeometryCode, m.GeometryDisplay = geometry.Code, geometry.Display
+	connectivity := r.CodeableConcept("copolymerConnectivity")
+	m.CopolymerConnectivityCode, m.CopolymerConnectivityDisplay = connectivity.Code, connectivity.Display
+	m.Modification = r.String("modification")
+	return r.Err()
+}
+
 func strVal(s *string) string { if s == nil { return "" }; return *s }
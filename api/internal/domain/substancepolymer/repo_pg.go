@@ -59,7 +59,7 @@ func (r *spRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*SubstancePo
 func (r *spRepoPG) Update(ctx context.Context, m *SubstancePolymer) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE substance_polymer SET class_code=$2, class_display=$3, geometry_code=$4, geometry_display=$5,
-			copolymer_connectivity_code=$6, copolymer_connectivity_display=$7, modification=$8, updated_at=NOW()
+			copolymer_connectivity_code=$6, copolymer_connectivity_display=$7, modification=$8, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.ClassCode, m.ClassDisplay, m.GeometryCode, m.GeometryDisplay,
 		m.CopolymerConnectivityCode, m.CopolymerConnectivityDisplay, m.Modification)
@@ -84,13 +84,16 @@ var spSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *spRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*SubstancePolymer, int, error) {
 	qb := fhir.NewSearchQuery("substance_polymer", spCols)
-	qb.ApplyParams(params, spSearchParams)
+	qb.ApplyParams(ctx, params, spSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -107,3 +110,9 @@ func (r *spRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("substance_polymer", spSearchParams)
+}
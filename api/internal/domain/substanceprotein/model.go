@@ -39,4 +39,15 @@ func (m *SubstanceProtein) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the protein from a FHIR SubstanceProtein resource, the
+// inverse of ToFHIR.
+func (m *SubstanceProtein) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("SubstanceProtein", data)
+	sequenceType := r.CodeableConcept("sequenceType")
+	m.SequenceTypeCode, m.SequenceTypeDisplay = sequenceType.Code, sequenceType.Display
+	m.NumberOfSubunits = r.Int("numberOfSubunits")
+	m.DisulfideLinkage = r.String("disulfideLinkage")
+	return r.Err()
+}
+
 func strVal(s *string) string { if s == nil { return "" }; return *s }
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ehr/ehr/internal/platform/db"
+	"github.com/ehr/ehr/internal/platform/fhir"
 )
 
 type queryable interface {
@@ -54,7 +55,7 @@ func (r *spRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*SubstancePr
 
 func (r *spRepoPG) Update(ctx context.Context, m *SubstanceProtein) error {
 	_, err := r.conn(ctx).Exec(ctx, `
-		UPDATE substance_protein SET sequence_type_code=$2, sequence_type_display=$3, number_of_subunits=$4, disulfide_linkage=$5, updated_at=NOW()
+		UPDATE substance_protein SET sequence_type_code=$2, sequence_type_display=$3, number_of_subunits=$4, disulfide_linkage=$5, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.SequenceTypeCode, m.SequenceTypeDisplay, m.NumberOfSubunits, m.DisulfideLinkage)
 	return err
@@ -76,7 +77,9 @@ func (r *spRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	query := `SELECT ` + spCols + ` FROM substance_protein WHERE 1=1`
 	countQuery := `SELECT COUNT(*) FROM substance_protein WHERE 1=1`
 	var args []interface{}; idx := 1
-	_ = params
+	metaWhere, metaArgs := fhir.MetaSearchSQL("SubstanceProtein", params, idx)
+	for _, w := range metaWhere { query += " AND " + w; countQuery += " AND " + w }
+	args = append(args, metaArgs...); idx += len(metaArgs)
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, countQuery, args...).Scan(&total); err != nil { return nil, 0, err }
 	query += fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, idx, idx+1); args = append(args, limit, offset)
@@ -60,4 +60,21 @@ func (m *SubstanceReferenceInformation) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the reference information from a FHIR
+// SubstanceReferenceInformation resource, the inverse of ToFHIR.
+func (m *SubstanceReferenceInformation) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("SubstanceReferenceInformation", data)
+	m.Comment = r.String("comment")
+	geneType := r.CodeableConcept("geneElement.type")
+	m.GeneElementTypeCode, m.GeneElementTypeDisplay = geneType.Code, geneType.Display
+	m.GeneElementSourceReference = r.Reference("geneElement.source")
+	classification := r.CodeableConcept("classification.classification")
+	m.ClassificationCode, m.ClassificationDisplay = classification.Code, classification.Display
+	domain := r.CodeableConcept("classification.domain")
+	m.ClassificationDomainCode, m.ClassificationDomainDisplay = domain.Code, domain.Display
+	targetType := r.CodeableConcept("target.type")
+	m.TargetTypeCode, m.TargetTypeDisplay = targetType.Code, targetType.Display
+	return r.Err()
+}
+
 func strVal(s *string) string { if s == nil { return "" }; return *s }
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ehr/ehr/internal/platform/db"
+	"github.com/ehr/ehr/internal/platform/fhir"
 )
 
 type queryable interface {
@@ -69,7 +70,7 @@ func (r *sriRepoPG) Update(ctx context.Context, m *SubstanceReferenceInformation
 		UPDATE substance_reference_information SET comment=$2, gene_element_type_code=$3, gene_element_type_display=$4,
 			gene_element_source_reference=$5, classification_code=$6, classification_display=$7,
 			classification_domain_code=$8, classification_domain_display=$9,
-			target_type_code=$10, target_type_display=$11, updated_at=NOW()
+			target_type_code=$10, target_type_display=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.Comment, m.GeneElementTypeCode, m.GeneElementTypeDisplay,
 		m.GeneElementSourceReference, m.ClassificationCode, m.ClassificationDisplay,
@@ -94,7 +95,9 @@ func (r *sriRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	query := `SELECT ` + sriCols + ` FROM substance_reference_information WHERE 1=1`
 	countQuery := `SELECT COUNT(*) FROM substance_reference_information WHERE 1=1`
 	var args []interface{}; idx := 1
-	_ = params
+	metaWhere, metaArgs := fhir.MetaSearchSQL("SubstanceReferenceInformation", params, idx)
+	for _, w := range metaWhere { query += " AND " + w; countQuery += " AND " + w }
+	args = append(args, metaArgs...); idx += len(metaArgs)
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, countQuery, args...).Scan(&total); err != nil { return nil, 0, err }
 	query += fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, idx, idx+1); args = append(args, limit, offset)
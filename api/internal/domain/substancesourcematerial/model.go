@@ -54,4 +54,22 @@ func (m *SubstanceSourceMaterial) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the source material from a FHIR SubstanceSourceMaterial
+// resource, the inverse of ToFHIR.
+func (m *SubstanceSourceMaterial) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("SubstanceSourceMaterial", data)
+	class := r.CodeableConcept("sourceMaterialClass")
+	m.SourceMaterialClassCode, m.SourceMaterialClassDisplay = class.Code, class.Display
+	materialType := r.CodeableConcept("sourceMaterialType")
+	m.SourceMaterialTypeCode, m.SourceMaterialTypeDisplay = materialType.Code, materialType.Display
+	state := r.CodeableConcept("sourceMaterialState")
+	m.SourceMaterialStateCode, m.SourceMaterialStateDisplay = state.Code, state.Display
+	m.OrganismID = r.String("organism.id")
+	m.OrganismName = r.String("organism.name")
+	country := r.CodeableConcept("countryOfOrigin")
+	m.CountryOfOriginCode, m.CountryOfOriginDisplay = country.Code, country.Display
+	m.GeographicalLocation = r.String("geographicalLocation")
+	return r.Err()
+}
+
 func strVal(s *string) string { if s == nil { return "" }; return *s }
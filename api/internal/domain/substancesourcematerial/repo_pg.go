@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ehr/ehr/internal/platform/db"
+	"github.com/ehr/ehr/internal/platform/fhir"
 )
 
 type queryable interface {
@@ -74,7 +75,7 @@ func (r *ssmRepoPG) Update(ctx context.Context, m *SubstanceSourceMaterial) erro
 			source_material_type_code=$4, source_material_type_display=$5,
 			source_material_state_code=$6, source_material_state_display=$7,
 			organism_id=$8, organism_name=$9, country_of_origin_code=$10, country_of_origin_display=$11,
-			geographical_location=$12, updated_at=NOW()
+			geographical_location=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		m.ID, m.SourceMaterialClassCode, m.SourceMaterialClassDisplay,
 		m.SourceMaterialTypeCode, m.SourceMaterialTypeDisplay,
@@ -100,7 +101,9 @@ func (r *ssmRepoPG) Search(ctx context.Context, params map[string]string, limit,
 	query := `SELECT ` + ssmCols + ` FROM substance_source_material WHERE 1=1`
 	countQuery := `SELECT COUNT(*) FROM substance_source_material WHERE 1=1`
 	var args []interface{}; idx := 1
-	_ = params
+	metaWhere, metaArgs := fhir.MetaSearchSQL("SubstanceSourceMaterial", params, idx)
+	for _, w := range metaWhere { query += " AND " + w; countQuery += " AND " + w }
+	args = append(args, metaArgs...); idx += len(metaArgs)
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, countQuery, args...).Scan(&total); err != nil { return nil, 0, err }
 	query += fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, idx, idx+1); args = append(args, limit, offset)
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/SubstanceSpecification/:id/_history", h.HistorySubstanceSpecificationFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("SubstanceSpecification", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateSubstanceSpecification(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetSubstanceSpecificationFHIR(c echo.Context) error {
 
 func (h *Handler) CreateSubstanceSpecificationFHIR(c echo.Context) error {
 	var s SubstanceSpecification
-	if err := c.Bind(&s); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "SubstanceSpecification", "", &s); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateSubstanceSpecification(c.Request().Context(), &s); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateSubstanceSpecificationFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateSubstanceSpecificationFHIR(c echo.Context) error {
-	var s SubstanceSpecification
-	if err := c.Bind(&s); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetSubstanceSpecificationByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("SubstanceSpecification", c.Param("id")))
 	}
-	s.ID = existing.ID
-	s.FHIRID = existing.FHIRID
+	s := *existing
+	if err := fhir.BindResource(c, "SubstanceSpecification", existing.FHIRID, &s); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateSubstanceSpecification(c.Request().Context(), &s); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("SubstanceSpecification", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,11 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["description"].(string); ok {
-		existing.Description = &v
-	}
-	if v, ok := patched["comment"].(string); ok {
-		existing.Comment = &v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateSubstanceSpecification(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
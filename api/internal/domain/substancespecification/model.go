@@ -70,6 +70,23 @@ func (s *SubstanceSpecification) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the specification from a FHIR SubstanceSpecification
+// resource, the inverse of ToFHIR.
+func (s *SubstanceSpecification) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("SubstanceSpecification", data)
+	s.Status = r.ConceptCode("status")
+	specType := r.CodeableConcept("type")
+	s.TypeCode, s.TypeDisplay = specType.Code, specType.Display
+	domain := r.CodeableConcept("domain")
+	s.DomainCode, s.DomainDisplay = domain.Code, domain.Display
+	s.Description = r.String("description")
+	s.SourceReference = r.Reference("source")
+	s.Comment = r.String("comment")
+	weight := r.Quantity("molecularWeight.amount")
+	s.MolecularWeightAmount, s.MolecularWeightUnit = weight.Value, weight.Unit
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
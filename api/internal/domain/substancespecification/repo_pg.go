@@ -71,7 +71,7 @@ func (r *substanceSpecRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*
 func (r *substanceSpecRepoPG) Update(ctx context.Context, s *SubstanceSpecification) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE substance_specification SET status=$2, type_code=$3, type_display=$4, domain_code=$5, domain_display=$6,
-			description=$7, source_reference=$8, comment=$9, molecular_weight_amount=$10, molecular_weight_unit=$11, updated_at=NOW()
+			description=$7, source_reference=$8, comment=$9, molecular_weight_amount=$10, molecular_weight_unit=$11, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		s.ID, s.Status, s.TypeCode, s.TypeDisplay, s.DomainCode, s.DomainDisplay,
 		s.Description, s.SourceReference, s.Comment, s.MolecularWeightAmount, s.MolecularWeightUnit)
@@ -111,13 +111,16 @@ var ssSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *substanceSpecRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*SubstanceSpecification, int, error) {
 	qb := fhir.NewSearchQuery("substance_specification", ssCols)
-	qb.ApplyParams(params, ssSearchParams)
+	qb.ApplyParams(ctx, params, ssSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -134,3 +137,9 @@ func (r *substanceSpecRepoPG) Search(ctx context.Context, params map[string]stri
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("substance_specification", ssSearchParams)
+}
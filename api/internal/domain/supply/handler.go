@@ -64,6 +64,11 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirWrite.PATCH("/SupplyDelivery/:id", h.PatchSupplyDeliveryFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("SupplyRequest", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("SupplyDelivery", fhir.ReadOnlyInteractions()...)
+}
+
 // ---- SupplyRequest REST ----
 
 func (h *Handler) CreateSupplyRequest(c echo.Context) error {
@@ -215,8 +220,8 @@ func (h *Handler) GetSupplyRequestFHIR(c echo.Context) error {
 
 func (h *Handler) CreateSupplyRequestFHIR(c echo.Context) error {
 	var sr SupplyRequest
-	if err := c.Bind(&sr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "SupplyRequest", "", &sr); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateSupplyRequest(c.Request().Context(), &sr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -226,16 +231,14 @@ func (h *Handler) CreateSupplyRequestFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateSupplyRequestFHIR(c echo.Context) error {
-	var sr SupplyRequest
-	if err := c.Bind(&sr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetSupplyRequestByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("SupplyRequest", c.Param("id")))
 	}
-	sr.ID = existing.ID
-	sr.FHIRID = existing.FHIRID
+	sr := *existing
+	if err := fhir.BindResource(c, "SupplyRequest", existing.FHIRID, &sr); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateSupplyRequest(c.Request().Context(), &sr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -292,7 +295,7 @@ func (h *Handler) handlePatchSupplyRequest(c echo.Context, fhirID string) error
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("SupplyRequest", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -318,8 +321,8 @@ func (h *Handler) handlePatchSupplyRequest(c echo.Context, fhirID string) error
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateSupplyRequest(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -354,8 +357,8 @@ func (h *Handler) GetSupplyDeliveryFHIR(c echo.Context) error {
 
 func (h *Handler) CreateSupplyDeliveryFHIR(c echo.Context) error {
 	var sd SupplyDelivery
-	if err := c.Bind(&sd); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "SupplyDelivery", "", &sd); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateSupplyDelivery(c.Request().Context(), &sd); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -365,16 +368,14 @@ func (h *Handler) CreateSupplyDeliveryFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateSupplyDeliveryFHIR(c echo.Context) error {
-	var sd SupplyDelivery
-	if err := c.Bind(&sd); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetSupplyDeliveryByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("SupplyDelivery", c.Param("id")))
 	}
-	sd.ID = existing.ID
-	sd.FHIRID = existing.FHIRID
+	sd := *existing
+	if err := fhir.BindResource(c, "SupplyDelivery", existing.FHIRID, &sd); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateSupplyDelivery(c.Request().Context(), &sd); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -431,7 +432,7 @@ func (h *Handler) handlePatchSupplyDelivery(c echo.Context, fhirID string) error
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("SupplyDelivery", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -457,8 +458,8 @@ func (h *Handler) handlePatchSupplyDelivery(c echo.Context, fhirID string) error
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateSupplyDelivery(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
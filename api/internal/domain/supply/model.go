@@ -95,6 +95,33 @@ func (s *SupplyRequest) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the request from a FHIR SupplyRequest resource, the
+// inverse of ToFHIR.
+func (s *SupplyRequest) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("SupplyRequest", data)
+	s.Status = r.CodeValue("status", "draft", "active", "suspended", "cancelled",
+		"completed", "entered-in-error", "unknown")
+	item := r.CodeableConcept("itemCodeableConcept")
+	s.ItemCode = r.RequiredString("itemCodeableConcept.coding.code")
+	s.ItemDisplay, s.ItemSystem = item.Display, item.System
+	quantity := r.Quantity("quantity")
+	if quantity.Value != nil {
+		s.QuantityValue = *quantity.Value
+	}
+	s.QuantityUnit = quantity.Unit
+	category := r.CodeableConcept("category")
+	s.CategoryCode, s.CategoryDisplay = category.Code, category.Display
+	s.Priority = r.Code("priority", "routine", "urgent", "asap", "stat")
+	s.OccurrenceDate = r.DateTime("occurrenceDateTime")
+	s.AuthoredOn = r.DateTime("authoredOn")
+	s.RequesterID = r.ReferenceID("requester", "Practitioner")
+	s.SupplierOrgID = r.ReferenceID("supplier", "Organization")
+	s.DeliverToLocationID = r.ReferenceID("deliverTo", "Location")
+	reason := r.CodeableConcept("reasonCode")
+	s.ReasonCode, s.ReasonDisplay = reason.Code, reason.Display
+	return r.Err()
+}
+
 // SupplyDelivery maps to the supply_delivery table (FHIR SupplyDelivery resource).
 type SupplyDelivery struct {
 	ID                    uuid.UUID  `db:"id" json:"id"`
@@ -182,6 +209,26 @@ func (s *SupplyDelivery) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the delivery from a FHIR SupplyDelivery resource, the
+// inverse of ToFHIR.
+func (s *SupplyDelivery) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("SupplyDelivery", data)
+	s.Status = r.CodeValue("status", "in-progress", "completed", "abandoned", "entered-in-error")
+	s.BasedOnID = r.ReferenceID("basedOn", "SupplyRequest")
+	s.PatientID = r.ReferenceID("patient", "Patient")
+	deliveryType := r.CodeableConcept("type")
+	s.TypeCode, s.TypeDisplay = deliveryType.Code, deliveryType.Display
+	item := r.CodeableConcept("suppliedItem.itemCodeableConcept")
+	s.SuppliedItemCode, s.SuppliedItemDisplay = item.Code, item.Display
+	quantity := r.Quantity("suppliedItem.quantity")
+	s.SuppliedItemQuantity, s.SuppliedItemUnit = quantity.Value, quantity.Unit
+	s.OccurrenceDate = r.DateTime("occurrenceDateTime")
+	s.SupplierID = r.ReferenceID("supplier", "Organization")
+	s.DestinationLocationID = r.ReferenceID("destination", "Location")
+	s.ReceiverID = r.ReferenceID("receiver", "Practitioner")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
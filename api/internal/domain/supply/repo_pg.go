@@ -88,7 +88,7 @@ func (r *supplyRequestRepoPG) Update(ctx context.Context, s *SupplyRequest) erro
 			priority=$5, item_code=$6, item_display=$7, item_system=$8,
 			quantity_value=$9, quantity_unit=$10, occurrence_date=$11, authored_on=$12,
 			requester_id=$13, supplier_org_id=$14, deliver_to_location_id=$15,
-			reason_code=$16, reason_display=$17, updated_at=NOW()
+			reason_code=$16, reason_display=$17, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		s.ID, s.Status, s.CategoryCode, s.CategoryDisplay,
 		s.Priority, s.ItemCode, s.ItemDisplay, s.ItemSystem,
@@ -131,13 +131,16 @@ var supplyRequestSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *supplyRequestRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*SupplyRequest, int, error) {
 	qb := fhir.NewSearchQuery("supply_request", supplyRequestCols)
-	qb.ApplyParams(params, supplyRequestSearchParams)
+	qb.ApplyParams(ctx, params, supplyRequestSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -220,7 +223,7 @@ func (r *supplyDeliveryRepoPG) Update(ctx context.Context, s *SupplyDelivery) er
 		UPDATE supply_delivery SET status=$2, based_on_id=$3, patient_id=$4,
 			type_code=$5, type_display=$6, supplied_item_code=$7, supplied_item_display=$8,
 			supplied_item_quantity=$9, supplied_item_unit=$10, occurrence_date=$11,
-			supplier_id=$12, destination_location_id=$13, receiver_id=$14, updated_at=NOW()
+			supplier_id=$12, destination_location_id=$13, receiver_id=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		s.ID, s.Status, s.BasedOnID, s.PatientID,
 		s.TypeCode, s.TypeDisplay, s.SuppliedItemCode, s.SuppliedItemDisplay,
@@ -262,13 +265,16 @@ var supplyDeliverySearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *supplyDeliveryRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*SupplyDelivery, int, error) {
 	qb := fhir.NewSearchQuery("supply_delivery", supplyDeliveryCols)
-	qb.ApplyParams(params, supplyDeliverySearchParams)
+	qb.ApplyParams(ctx, params, supplyDeliverySearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -285,3 +291,10 @@ func (r *supplyDeliveryRepoPG) Search(ctx context.Context, params map[string]str
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("supply_request", supplyRequestSearchParams)
+	r.RegisterTable("supply_delivery", supplyDeliverySearchParams)
+}
@@ -59,7 +59,7 @@ func (r *orRoomRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*ORRoom, erro
 func (r *orRoomRepoPG) Update(ctx context.Context, o *ORRoom) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE or_room SET name=$2, status=$3, room_type=$4, equipment=$5, is_active=$6,
-			decontaminated_at=$7, note=$8, updated_at=NOW()
+			decontaminated_at=$7, note=$8, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		o.ID, o.Name, o.Status, o.RoomType, o.Equipment, o.IsActive, o.DecontaminatedAt, o.Note)
 	return err
@@ -98,13 +98,16 @@ var orRoomSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *orRoomRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ORRoom, int, error) {
 	qb := fhir.NewSearchQuery("or_room", orRoomCols)
-	qb.ApplyParams(params, orRoomSearchParams)
+	qb.ApplyParams(ctx, params, orRoomSearchParams)
 	qb.OrderBy("name")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -178,7 +181,7 @@ func (r *surgicalCaseRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*Surgic
 func (r *surgicalCaseRepoPG) Update(ctx context.Context, sc *SurgicalCase) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE surgical_case SET status=$2, or_room_id=$3, actual_start=$4, actual_end=$5,
-			post_op_diagnosis=$6, wound_class=$7, cancel_reason=$8, note=$9, updated_at=NOW()
+			post_op_diagnosis=$6, wound_class=$7, cancel_reason=$8, note=$9, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		sc.ID, sc.Status, sc.ORRoomID, sc.ActualStart, sc.ActualEnd,
 		sc.PostOpDiagnosis, sc.WoundClass, sc.CancelReason, sc.Note)
@@ -241,13 +244,16 @@ var surgicalCaseSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *surgicalCaseRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*SurgicalCase, int, error) {
 	qb := fhir.NewSearchQuery("surgical_case", caseCols)
-	qb.ApplyParams(params, surgicalCaseSearchParams)
+	qb.ApplyParams(ctx, params, surgicalCaseSearchParams)
 	qb.OrderBy("scheduled_date DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -533,13 +539,16 @@ var prefCardSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *preferenceCardRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*SurgicalPreferenceCard, int, error) {
 	qb := fhir.NewSearchQuery("surgical_preference_card", prefCardCols)
-	qb.ApplyParams(params, prefCardSearchParams)
+	qb.ApplyParams(ctx, params, prefCardSearchParams)
 	qb.OrderBy("procedure_display")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -673,13 +682,16 @@ var implantLogSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *implantLogRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ImplantLog, int, error) {
 	qb := fhir.NewSearchQuery("implant_log", implantCols)
-	qb.ApplyParams(params, implantLogSearchParams)
+	qb.ApplyParams(ctx, params, implantLogSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -696,3 +708,12 @@ func (r *implantLogRepoPG) Search(ctx context.Context, params map[string]string,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("or_room", orRoomSearchParams)
+	r.RegisterTable("surgical_case", surgicalCaseSearchParams)
+	r.RegisterTable("surgical_preference_card", prefCardSearchParams)
+	r.RegisterTable("implant_log", implantLogSearchParams)
+}
@@ -50,6 +50,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/Task/:id/_history", h.HistoryTaskFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("Task", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST --
 
 func (h *Handler) CreateTask(c echo.Context) error {
@@ -160,8 +164,8 @@ func (h *Handler) GetTaskFHIR(c echo.Context) error {
 
 func (h *Handler) CreateTaskFHIR(c echo.Context) error {
 	var t Task
-	if err := c.Bind(&t); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "Task", "", &t); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateTask(c.Request().Context(), &t); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -171,16 +175,14 @@ func (h *Handler) CreateTaskFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateTaskFHIR(c echo.Context) error {
-	var t Task
-	if err := c.Bind(&t); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetTaskByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Task", c.Param("id")))
 	}
-	t.ID = existing.ID
-	t.FHIRID = existing.FHIRID
+	t := *existing
+	if err := fhir.BindResource(c, "Task", existing.FHIRID, &t); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateTask(c.Request().Context(), &t); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -209,7 +211,7 @@ func (h *Handler) PatchTaskFHIR(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("Task", c.Param("id")))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -235,8 +237,8 @@ func (h *Handler) PatchTaskFHIR(c echo.Context) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateTask(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -161,7 +161,7 @@ func TestGetTaskFHIR_NotFound(t *testing.T) {
 
 func TestCreateTaskFHIR_Success(t *testing.T) {
 	h, e := newTestHandler()
-	body := `{"for_patient_id":"` + uuid.New().String() + `","intent":"order","status":"requested"}`
+	body := `{"resourceType":"Task","for":{"reference":"Patient/` + uuid.New().String() + `"},"intent":"order","status":"requested"}`
 	req := httptest.NewRequest(http.MethodPost, "/fhir/Task", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -134,6 +134,54 @@ func (t *Task) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the task from a FHIR Task resource, the inverse of
+// ToFHIR. The input and output arrays are kept as raw JSON.
+func (t *Task) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("Task", data)
+	t.Status = r.CodeValue("status", "draft", "requested", "received", "accepted", "rejected", "ready",
+		"cancelled", "in-progress", "on-hold", "failed", "completed", "entered-in-error")
+	t.Intent = r.RequiredCode("intent", "unknown", "proposal", "plan", "order", "original-order",
+		"reflex-order", "filler-order", "instance-order", "option")
+	t.ForPatientID = r.RequiredReferenceID("for", "Patient")
+	t.StatusReason = r.String("statusReason.text")
+	t.Priority = r.Code("priority", "routine", "urgent", "asap", "stat")
+	code := r.CodeableConcept("code")
+	t.CodeValue, t.CodeDisplay, t.CodeSystem = code.Code, code.Display, code.System
+	t.Description = r.String("description")
+	t.FocusResourceType, t.FocusResourceID = r.ReferenceTarget("focus")
+	t.EncounterID = r.ReferenceID("encounter", "Encounter")
+	t.AuthoredOn = r.DateTime("authoredOn")
+	t.LastModified = r.DateTime("lastModified")
+	t.RequesterID = r.ReferenceID("requester", "Practitioner")
+	t.OwnerID = r.ReferenceID("owner", "Practitioner")
+	reason := r.CodeableConcept("reasonCode")
+	t.ReasonCode, t.ReasonDisplay = reason.Code, reason.Display
+	t.Note = r.Annotation("note")
+	t.RestrictionRepetitions = r.Int("restriction.repetitions")
+	t.RestrictionPeriodStart, t.RestrictionPeriodEnd = r.Period("restriction.period")
+	t.InputJSON = rawArray(r, data, "input")
+	t.OutputJSON = rawArray(r, data, "output")
+	return r.Err()
+}
+
+// rawArray returns the top-level array element name of data as raw JSON.
+func rawArray(r *fhir.ResourceReader, data map[string]interface{}, name string) *json.RawMessage {
+	v, ok := data[name]
+	if !ok || v == nil {
+		return nil
+	}
+	if _, isArr := v.([]interface{}); !isArr {
+		r.Invalid(name, fmt.Sprintf("Task.%s must be an array", name))
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	msg := json.RawMessage(raw)
+	return &msg
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ehr/ehr/internal/platform/fhir"
 	"github.com/google/uuid"
 )
 
@@ -235,3 +236,69 @@ func TestTask_ToFHIR_MinimalFields(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// FromFHIR
+// ---------------------------------------------------------------------------
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestTask_FromFHIR_RoundTrip(t *testing.T) {
+	inputs := json.RawMessage(`[{"type":{"text":"order"},"valueString":"CBC"}]`)
+	focusType, focusID := "ServiceRequest", "sr-1"
+	orig := &Task{
+		FHIRID:            "task-rt",
+		Status:            "in-progress",
+		Intent:            "order",
+		ForPatientID:      uuid.New(),
+		FocusResourceType: &focusType,
+		FocusResourceID:   &focusID,
+		InputJSON:         &inputs,
+	}
+
+	var got Task
+	if err := got.FromFHIR(toJSONMap(t, orig.ToFHIR())); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	if got.Status != "in-progress" || got.Intent != "order" || got.ForPatientID != orig.ForPatientID {
+		t.Errorf("status/intent/for mismatch: %+v", got)
+	}
+	if got.FocusResourceType == nil || *got.FocusResourceType != "ServiceRequest" || *got.FocusResourceID != "sr-1" {
+		t.Errorf("focus mismatch: %+v", got)
+	}
+	if got.InputJSON == nil || string(*got.InputJSON) != `[{"type":{"text":"order"},"valueString":"CBC"}]` {
+		t.Errorf("input mismatch: %v", got.InputJSON)
+	}
+}
+
+func TestTask_FromFHIR_InvalidElements(t *testing.T) {
+	var task Task
+	err := task.FromFHIR(map[string]interface{}{
+		"resourceType": "Task",
+		"intent":       "order",
+		"priority":     "whenever",
+		"for":          map[string]interface{}{"reference": "Patient/" + uuid.New().String()},
+		"input":        map[string]interface{}{"valueString": "x"},
+	})
+	pe, ok := err.(*fhir.ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *fhir.ResourceParseError, got %v", err)
+	}
+	if len(pe.Outcome.Issue) != 2 {
+		t.Fatalf("expected 2 issues, got %+v", pe.Outcome.Issue)
+	}
+	if pe.Outcome.Issue[0].Expression[0] != "Task.priority" || pe.Outcome.Issue[1].Expression[0] != "Task.input" {
+		t.Errorf("unexpected issues: %+v", pe.Outcome.Issue)
+	}
+}
@@ -154,13 +154,16 @@ var taskSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *taskRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*Task, int, error) {
 	qb := fhir.NewSearchQuery("task", taskCols)
-	qb.ApplyParams(params, taskSearchParams)
+	qb.ApplyParams(ctx, params, taskSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -177,3 +180,9 @@ func (r *taskRepoPG) Search(ctx context.Context, params map[string]string, limit
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("task", taskSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/TerminologyCapabilities/:id/_history", h.HistoryTerminologyCapabilitiesFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("TerminologyCapabilities", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateTerminologyCapabilities(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetTerminologyCapabilitiesFHIR(c echo.Context) error {
 
 func (h *Handler) CreateTerminologyCapabilitiesFHIR(c echo.Context) error {
 	var tc TerminologyCapabilities
-	if err := c.Bind(&tc); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "TerminologyCapabilities", "", &tc); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateTerminologyCapabilities(c.Request().Context(), &tc); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateTerminologyCapabilitiesFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateTerminologyCapabilitiesFHIR(c echo.Context) error {
-	var tc TerminologyCapabilities
-	if err := c.Bind(&tc); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetTerminologyCapabilitiesByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("TerminologyCapabilities", c.Param("id")))
 	}
-	tc.ID = existing.ID
-	tc.FHIRID = existing.FHIRID
+	tc := *existing
+	if err := fhir.BindResource(c, "TerminologyCapabilities", existing.FHIRID, &tc); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateTerminologyCapabilities(c.Request().Context(), &tc); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("TerminologyCapabilities", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateTerminologyCapabilities(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
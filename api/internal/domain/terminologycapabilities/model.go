@@ -78,6 +78,26 @@ func (tc *TerminologyCapabilities) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the capabilities from a FHIR TerminologyCapabilities
+// resource, the inverse of ToFHIR.
+func (tc *TerminologyCapabilities) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("TerminologyCapabilities", data)
+	tc.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	tc.Kind = r.CodeValue("kind", "instance", "capability", "requirements")
+	tc.Translation = r.BoolValue("translation")
+	tc.Closure = r.BoolValue("closure.translation")
+	tc.URL = r.String("url")
+	tc.Name = r.String("name")
+	tc.Title = r.String("title")
+	tc.Description = r.String("description")
+	tc.Publisher = r.String("publisher")
+	tc.Date = r.DateTime("date")
+	tc.CodeSearch = r.Code("codeSearch", "explicit", "all")
+	tc.SoftwareName = r.String("software.name")
+	tc.SoftwareVersion = r.String("software.version")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
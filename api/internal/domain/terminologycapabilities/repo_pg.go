@@ -71,7 +71,7 @@ func (r *terminologyCapabilitiesRepoPG) GetByFHIRID(ctx context.Context, fhirID
 func (r *terminologyCapabilitiesRepoPG) Update(ctx context.Context, tc *TerminologyCapabilities) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE terminology_capabilities SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
-			kind=$9, code_search=$10, translation=$11, closure=$12, software_name=$13, software_version=$14, updated_at=NOW()
+			kind=$9, code_search=$10, translation=$11, closure=$12, software_name=$13, software_version=$14, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		tc.ID, tc.Status, tc.URL, tc.Name, tc.Title, tc.Description, tc.Publisher, tc.Date,
 		tc.Kind, tc.CodeSearch, tc.Translation, tc.Closure, tc.SoftwareName, tc.SoftwareVersion)
@@ -112,13 +112,16 @@ var tcSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *terminologyCapabilitiesRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*TerminologyCapabilities, int, error) {
 	qb := fhir.NewSearchQuery("terminology_capabilities", tcCols)
-	qb.ApplyParams(params, tcSearchParams)
+	qb.ApplyParams(ctx, params, tcSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -135,3 +138,9 @@ func (r *terminologyCapabilitiesRepoPG) Search(ctx context.Context, params map[s
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("terminology_capabilities", tcSearchParams)
+}
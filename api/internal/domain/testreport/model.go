@@ -68,3 +68,19 @@ func (e *TestReport) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the report from a FHIR TestReport resource, the inverse
+// of ToFHIR. Only the first participant is read.
+func (e *TestReport) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("TestReport", data)
+	e.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	e.Result = r.RequiredCode("result", "pass", "fail", "pending")
+	e.Name = r.String("name")
+	e.TestScriptReference = r.Reference("testScript")
+	e.Score = r.Decimal("score")
+	e.Tester = r.String("tester")
+	e.Issued = r.DateTime("issued")
+	e.ParticipantType = r.Code("participant.type", "test-engine", "client", "server")
+	e.ParticipantURI = r.String("participant.uri")
+	return r.Err()
+}
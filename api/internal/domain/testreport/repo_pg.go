@@ -71,7 +71,7 @@ func (r *testReportRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Tes
 func (r *testReportRepoPG) Update(ctx context.Context, e *TestReport) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE test_report SET status=$2, name=$3, test_script_reference=$4, result=$5, score=$6, tester=$7, issued=$8,
-			participant_type=$9, participant_uri=$10, updated_at=NOW()
+			participant_type=$9, participant_uri=$10, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		e.ID, e.Status, e.Name, e.TestScriptReference, e.Result, e.Score, e.Tester, e.Issued,
 		e.ParticipantType, e.ParticipantURI)
@@ -111,13 +111,16 @@ var trSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *testReportRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*TestReport, int, error) {
 	qb := fhir.NewSearchQuery("test_report", trCols)
-	qb.ApplyParams(params, trSearchParams)
+	qb.ApplyParams(ctx, params, trSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -134,3 +137,9 @@ func (r *testReportRepoPG) Search(ctx context.Context, params map[string]string,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("test_report", trSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/TestScript/:id/_history", h.HistoryTestScriptFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("TestScript", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateTestScript(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetTestScriptFHIR(c echo.Context) error {
 
 func (h *Handler) CreateTestScriptFHIR(c echo.Context) error {
 	var ts TestScript
-	if err := c.Bind(&ts); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "TestScript", "", &ts); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateTestScript(c.Request().Context(), &ts); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateTestScriptFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateTestScriptFHIR(c echo.Context) error {
-	var ts TestScript
-	if err := c.Bind(&ts); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetTestScriptByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("TestScript", c.Param("id")))
 	}
-	ts.ID = existing.ID
-	ts.FHIRID = existing.FHIRID
+	ts := *existing
+	if err := fhir.BindResource(c, "TestScript", existing.FHIRID, &ts); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateTestScript(c.Request().Context(), &ts); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("TestScript", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateTestScript(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -77,6 +77,25 @@ func (ts *TestScript) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the script from a FHIR TestScript resource, the inverse
+// of ToFHIR.
+func (ts *TestScript) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("TestScript", data)
+	ts.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	ts.Name = r.RequiredString("name")
+	ts.URL = r.String("url")
+	ts.Title = r.String("title")
+	ts.Description = r.String("description")
+	ts.Publisher = r.String("publisher")
+	ts.Date = r.DateTime("date")
+	ts.Purpose = r.String("purpose")
+	ts.Copyright = r.String("copyright")
+	ts.ProfileReference = r.Reference("profile")
+	ts.OriginIndex = r.Int("origin.index")
+	ts.DestinationIndex = r.Int("destination.index")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
@@ -71,7 +71,7 @@ func (r *testScriptRepoPG) GetByFHIRID(ctx context.Context, fhirID string) (*Tes
 func (r *testScriptRepoPG) Update(ctx context.Context, ts *TestScript) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE test_script SET status=$2, url=$3, name=$4, title=$5, description=$6, publisher=$7, date=$8,
-			purpose=$9, copyright=$10, profile_reference=$11, origin_index=$12, destination_index=$13, updated_at=NOW()
+			purpose=$9, copyright=$10, profile_reference=$11, origin_index=$12, destination_index=$13, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		ts.ID, ts.Status, ts.URL, ts.Name, ts.Title, ts.Description, ts.Publisher, ts.Date,
 		ts.Purpose, ts.Copyright, ts.ProfileReference, ts.OriginIndex, ts.DestinationIndex)
@@ -112,13 +112,16 @@ var tsSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *testScriptRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*TestScript, int, error) {
 	qb := fhir.NewSearchQuery("test_script", tsCols)
-	qb.ApplyParams(params, tsSearchParams)
+	qb.ApplyParams(ctx, params, tsSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -135,3 +138,9 @@ func (r *testScriptRepoPG) Search(ctx context.Context, params map[string]string,
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("test_script", tsSearchParams)
+}
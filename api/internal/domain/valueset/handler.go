@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/ValueSet/:id/_history", h.HistoryValueSetFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("ValueSet", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateValueSet(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetValueSetFHIR(c echo.Context) error {
 
 func (h *Handler) CreateValueSetFHIR(c echo.Context) error {
 	var vs ValueSet
-	if err := c.Bind(&vs); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ValueSet", "", &vs); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateValueSet(c.Request().Context(), &vs); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateValueSetFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateValueSetFHIR(c echo.Context) error {
-	var vs ValueSet
-	if err := c.Bind(&vs); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetValueSetByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ValueSet", c.Param("id")))
 	}
-	vs.ID = existing.ID
-	vs.FHIRID = existing.FHIRID
+	vs := *existing
+	if err := fhir.BindResource(c, "ValueSet", existing.FHIRID, &vs); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateValueSet(c.Request().Context(), &vs); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ValueSet", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateValueSet(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -95,3 +95,24 @@ func (vs *ValueSet) ToFHIR() map[string]interface{} {
 	}
 	return result
 }
+
+// FromFHIR populates the value set from a FHIR ValueSet resource, the inverse
+// of ToFHIR. Only the first compose include is read.
+func (vs *ValueSet) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ValueSet", data)
+	vs.Status = r.CodeValue("status", "draft", "active", "retired", "unknown")
+	vs.URL = r.String("url")
+	vs.Name = r.String("name")
+	vs.Title = r.String("title")
+	vs.Description = r.String("description")
+	vs.Publisher = r.String("publisher")
+	vs.Date = r.DateTime("date")
+	vs.Immutable = r.BoolValue("immutable")
+	vs.Purpose = r.String("purpose")
+	vs.Copyright = r.String("copyright")
+	vs.ComposeIncludeSystem = r.String("compose.include.system")
+	vs.ComposeIncludeVersion = r.String("compose.include.version")
+	vs.ExpansionIdentifier = r.String("expansion.identifier")
+	vs.ExpansionTimestamp = r.DateTime("expansion.timestamp")
+	return r.Err()
+}
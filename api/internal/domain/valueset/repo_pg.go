@@ -81,7 +81,7 @@ func (r *valueSetRepoPG) Update(ctx context.Context, vs *ValueSet) error {
 		UPDATE value_set SET status=$2, url=$3, name=$4, title=$5, description=$6,
 			publisher=$7, date=$8, immutable=$9, purpose=$10, copyright=$11,
 			compose_include_system=$12, compose_include_version=$13,
-			expansion_identifier=$14, expansion_timestamp=$15, updated_at=NOW()
+			expansion_identifier=$14, expansion_timestamp=$15, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		vs.ID, vs.Status, vs.URL, vs.Name, vs.Title, vs.Description,
 		vs.Publisher, vs.Date, vs.Immutable, vs.Purpose, vs.Copyright,
@@ -125,13 +125,16 @@ var vsSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *valueSetRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ValueSet, int, error) {
 	qb := fhir.NewSearchQuery("value_set", vsCols)
-	qb.ApplyParams(params, vsSearchParams)
+	qb.ApplyParams(ctx, params, vsSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -148,3 +151,9 @@ func (r *valueSetRepoPG) Search(ctx context.Context, params map[string]string, l
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("value_set", vsSearchParams)
+}
@@ -49,6 +49,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/VerificationResult/:id/_history", h.HistoryVerificationResultFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("VerificationResult", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST Endpoints --
 
 func (h *Handler) CreateVerificationResult(c echo.Context) error {
@@ -137,8 +141,8 @@ func (h *Handler) GetVerificationResultFHIR(c echo.Context) error {
 
 func (h *Handler) CreateVerificationResultFHIR(c echo.Context) error {
 	var v VerificationResult
-	if err := c.Bind(&v); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "VerificationResult", "", &v); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateVerificationResult(c.Request().Context(), &v); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -148,16 +152,14 @@ func (h *Handler) CreateVerificationResultFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateVerificationResultFHIR(c echo.Context) error {
-	var v VerificationResult
-	if err := c.Bind(&v); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetVerificationResultByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("VerificationResult", c.Param("id")))
 	}
-	v.ID = existing.ID
-	v.FHIRID = existing.FHIRID
+	v := *existing
+	if err := fhir.BindResource(c, "VerificationResult", existing.FHIRID, &v); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateVerificationResult(c.Request().Context(), &v); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -213,7 +215,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("VerificationResult", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
 		ops, err := fhir.ParseJSONPatch(body)
@@ -237,8 +239,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 		return c.JSON(http.StatusUnsupportedMediaType, fhir.ErrorOutcome(
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateVerificationResult(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
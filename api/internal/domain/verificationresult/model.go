@@ -95,6 +95,28 @@ func (v *VerificationResult) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the result from a FHIR VerificationResult resource, the
+// inverse of ToFHIR. Only the first target is read.
+func (v *VerificationResult) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("VerificationResult", data)
+	v.Status = r.CodeValue("status", "attested", "validated", "in-process", "req-revalid", "val-fail", "reval-fail")
+	v.TargetType, v.TargetReference = r.ReferenceTarget("target")
+	need := r.CodeableConcept("need")
+	v.NeedCode, v.NeedDisplay = need.Code, need.Display
+	v.StatusDate = r.DateTime("statusDate")
+	validationType := r.CodeableConcept("validationType")
+	v.ValidationTypeCode, v.ValidationTypeDisplay = validationType.Code, validationType.Display
+	process := r.CodeableConcept("validationProcess")
+	v.ValidationProcessCode, v.ValidationProcessDisplay = process.Code, process.Display
+	v.FrequencyValue = r.Int("frequency.repeat.frequency")
+	v.FrequencyUnit = r.Code("frequency.repeat.periodUnit", "s", "min", "h", "d", "wk", "mo", "a")
+	v.LastPerformed = r.DateTime("lastPerformed")
+	v.NextScheduled = r.DateTime("nextScheduled")
+	failureAction := r.CodeableConcept("failureAction")
+	v.FailureActionCode, v.FailureActionDisplay = failureAction.Code, failureAction.Display
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
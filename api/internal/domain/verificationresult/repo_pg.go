@@ -91,7 +91,7 @@ func (r *verificationResultRepoPG) Update(ctx context.Context, v *VerificationRe
 			validation_type_code=$8, validation_type_display=$9,
 			validation_process_code=$10, validation_process_display=$11,
 			frequency_value=$12, frequency_unit=$13, last_performed=$14, next_scheduled=$15,
-			failure_action_code=$16, failure_action_display=$17, updated_at=NOW()
+			failure_action_code=$16, failure_action_display=$17, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		v.ID, v.Status, v.TargetType, v.TargetReference,
 		v.NeedCode, v.NeedDisplay, v.StatusDate,
@@ -135,13 +135,16 @@ var vrSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *verificationResultRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*VerificationResult, int, error) {
 	qb := fhir.NewSearchQuery("verification_result", vrCols)
-	qb.ApplyParams(params, vrSearchParams)
+	qb.ApplyParams(ctx, params, vrSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -158,3 +161,9 @@ func (r *verificationResultRepoPG) Search(ctx context.Context, params map[string
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("verification_result", vrSearchParams)
+}
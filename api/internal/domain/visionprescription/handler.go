@@ -52,6 +52,10 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/VisionPrescription/:id/_history", h.HistoryVisionPrescriptionFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("VisionPrescription", fhir.ReadOnlyInteractions()...)
+}
+
 // -- REST --
 
 func (h *Handler) CreateVisionPrescription(c echo.Context) error {
@@ -175,8 +179,8 @@ func (h *Handler) GetVisionPrescriptionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateVisionPrescriptionFHIR(c echo.Context) error {
 	var v VisionPrescription
-	if err := c.Bind(&v); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "VisionPrescription", "", &v); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateVisionPrescription(c.Request().Context(), &v); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -186,16 +190,14 @@ func (h *Handler) CreateVisionPrescriptionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateVisionPrescriptionFHIR(c echo.Context) error {
-	var v VisionPrescription
-	if err := c.Bind(&v); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetVisionPrescriptionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("VisionPrescription", c.Param("id")))
 	}
-	v.ID = existing.ID
-	v.FHIRID = existing.FHIRID
+	v := *existing
+	if err := fhir.BindResource(c, "VisionPrescription", existing.FHIRID, &v); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateVisionPrescription(c.Request().Context(), &v); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -252,7 +254,7 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("VisionPrescription", fhirID))
 	}
-	currentResource := existing.ToFHIR()
+	currentResource := fhir.PatchTarget(c, existing.ToFHIR())
 
 	var patched map[string]interface{}
 	if strings.Contains(contentType, "json-patch+json") {
@@ -278,8 +280,8 @@ func (h *Handler) handlePatch(c echo.Context, fhirID string) error {
 			"PATCH requires Content-Type: application/json-patch+json or application/merge-patch+json"))
 	}
 
-	if v, ok := patched["status"].(string); ok {
-		existing.Status = v
+	if err := fhir.BindPatched(c, patched, existing); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 	}
 	if err := h.svc.UpdateVisionPrescription(c.Request().Context(), existing); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -56,6 +56,20 @@ func (v *VisionPrescription) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the prescription from a FHIR VisionPrescription
+// resource, the inverse of ToFHIR. Lens specifications are managed through
+// their own endpoints.
+func (v *VisionPrescription) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("VisionPrescription", data)
+	v.Status = r.CodeValue("status", "active", "cancelled", "draft", "entered-in-error")
+	v.PatientID = r.RequiredReferenceID("patient", "Patient")
+	v.Created = r.DateTime("created")
+	v.EncounterID = r.ReferenceID("encounter", "Encounter")
+	v.DateWritten = r.DateTime("dateWritten")
+	v.PrescriberID = r.ReferenceID("prescriber", "Practitioner")
+	return r.Err()
+}
+
 // VisionPrescriptionLensSpec maps to the vision_prescription_lens_spec table.
 type VisionPrescriptionLensSpec struct {
 	ID             uuid.UUID  `db:"id" json:"id"`
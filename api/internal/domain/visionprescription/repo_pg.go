@@ -70,7 +70,7 @@ func (r *visionPrescriptionRepoPG) GetByFHIRID(ctx context.Context, fhirID strin
 func (r *visionPrescriptionRepoPG) Update(ctx context.Context, v *VisionPrescription) error {
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE vision_prescription SET status=$2, created=$3, encounter_id=$4,
-			date_written=$5, prescriber_id=$6, updated_at=NOW()
+			date_written=$5, prescriber_id=$6, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		v.ID, v.Status, v.Created, v.EncounterID,
 		v.DateWritten, v.PrescriberID)
@@ -110,13 +110,16 @@ var vpSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *visionPrescriptionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*VisionPrescription, int, error) {
 	qb := fhir.NewSearchQuery("vision_prescription", vpCols)
-	qb.ApplyParams(params, vpSearchParams)
+	qb.ApplyParams(ctx, params, vpSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -183,3 +186,9 @@ func (r *visionPrescriptionRepoPG) GetLensSpecs(ctx context.Context, prescriptio
 	}
 	return items, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("vision_prescription", vpSearchParams)
+}
@@ -86,6 +86,12 @@ func (h *Handler) RegisterRoutes(api *echo.Group, fhirGroup *echo.Group) {
 	fhirRead.GET("/GuidanceResponse/:id/_history", h.HistoryGuidanceResponseFHIR)
 }
 
+func (h *Handler) RegisterResources(r *fhir.ResourceRegistry) {
+	r.RegisterInteractions("ActivityDefinition", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("RequestGroup", fhir.ReadOnlyInteractions()...)
+	r.RegisterInteractions("GuidanceResponse", fhir.ReadOnlyInteractions()...)
+}
+
 // -- ActivityDefinition REST --
 
 func (h *Handler) CreateActivityDefinition(c echo.Context) error {
@@ -324,8 +330,8 @@ func (h *Handler) GetActivityDefinitionFHIR(c echo.Context) error {
 
 func (h *Handler) CreateActivityDefinitionFHIR(c echo.Context) error {
 	var a ActivityDefinition
-	if err := c.Bind(&a); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "ActivityDefinition", "", &a); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateActivityDefinition(c.Request().Context(), &a); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -335,16 +341,14 @@ func (h *Handler) CreateActivityDefinitionFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateActivityDefinitionFHIR(c echo.Context) error {
-	var a ActivityDefinition
-	if err := c.Bind(&a); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetActivityDefinitionByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("ActivityDefinition", c.Param("id")))
 	}
-	a.ID = existing.ID
-	a.FHIRID = existing.FHIRID
+	a := *existing
+	if err := fhir.BindResource(c, "ActivityDefinition", existing.FHIRID, &a); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateActivityDefinition(c.Request().Context(), &a); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -417,8 +421,8 @@ func (h *Handler) GetRequestGroupFHIR(c echo.Context) error {
 
 func (h *Handler) CreateRequestGroupFHIR(c echo.Context) error {
 	var rg RequestGroup
-	if err := c.Bind(&rg); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "RequestGroup", "", &rg); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateRequestGroup(c.Request().Context(), &rg); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -428,16 +432,14 @@ func (h *Handler) CreateRequestGroupFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateRequestGroupFHIR(c echo.Context) error {
-	var rg RequestGroup
-	if err := c.Bind(&rg); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetRequestGroupByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("RequestGroup", c.Param("id")))
 	}
-	rg.ID = existing.ID
-	rg.FHIRID = existing.FHIRID
+	rg := *existing
+	if err := fhir.BindResource(c, "RequestGroup", existing.FHIRID, &rg); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateRequestGroup(c.Request().Context(), &rg); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -510,8 +512,8 @@ func (h *Handler) GetGuidanceResponseFHIR(c echo.Context) error {
 
 func (h *Handler) CreateGuidanceResponseFHIR(c echo.Context) error {
 	var gr GuidanceResponse
-	if err := c.Bind(&gr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
+	if err := fhir.BindResource(c, "GuidanceResponse", "", &gr); err != nil {
+		return fhir.WriteParseError(c, err)
 	}
 	if err := h.svc.CreateGuidanceResponse(c.Request().Context(), &gr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -521,16 +523,14 @@ func (h *Handler) CreateGuidanceResponseFHIR(c echo.Context) error {
 }
 
 func (h *Handler) UpdateGuidanceResponseFHIR(c echo.Context) error {
-	var gr GuidanceResponse
-	if err := c.Bind(&gr); err != nil {
-		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
-	}
 	existing, err := h.svc.GetGuidanceResponseByFHIRID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome("GuidanceResponse", c.Param("id")))
 	}
-	gr.ID = existing.ID
-	gr.FHIRID = existing.FHIRID
+	gr := *existing
+	if err := fhir.BindResource(c, "GuidanceResponse", existing.FHIRID, &gr); err != nil {
+		return fhir.WriteParseError(c, err)
+	}
 	if err := h.svc.UpdateGuidanceResponse(c.Request().Context(), &gr); err != nil {
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
 	}
@@ -592,19 +592,19 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string) error
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "RequestGroup":
 		existing, err := h.svc.GetRequestGroupByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	case "GuidanceResponse":
 		existing, err := h.svc.GetGuidanceResponseByFHIRID(c.Request().Context(), fhirID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, fhir.NotFoundOutcome(resourceType, fhirID))
 		}
-		currentResource = existing.ToFHIR()
+		currentResource = fhir.PatchTarget(c, existing.ToFHIR())
 	default:
 		return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome("unsupported resource type for PATCH"))
 	}
@@ -636,8 +636,8 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string) error
 	switch resourceType {
 	case "ActivityDefinition":
 		existing, _ := h.svc.GetActivityDefinitionByFHIRID(c.Request().Context(), fhirID)
-		if v, ok := patched["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(c, patched, existing); err != nil {
+			return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateActivityDefinition(c.Request().Context(), existing); err != nil {
 			return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -645,8 +645,8 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string) error
 		return c.JSON(http.StatusOK, existing.ToFHIR())
 	case "RequestGroup":
 		existing, _ := h.svc.GetRequestGroupByFHIRID(c.Request().Context(), fhirID)
-		if v, ok := patched["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(c, patched, existing); err != nil {
+			return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateRequestGroup(c.Request().Context(), existing); err != nil {
 			return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
@@ -654,8 +654,8 @@ func (h *Handler) handlePatch(c echo.Context, resourceType, fhirID string) error
 		return c.JSON(http.StatusOK, existing.ToFHIR())
 	case "GuidanceResponse":
 		existing, _ := h.svc.GetGuidanceResponseByFHIRID(c.Request().Context(), fhirID)
-		if v, ok := patched["status"].(string); ok {
-			existing.Status = v
+		if err := fhir.BindPatched(c, patched, existing); err != nil {
+			return c.JSON(http.StatusUnprocessableEntity, fhir.ParseErrorOutcome(err))
 		}
 		if err := h.svc.UpdateGuidanceResponse(c.Request().Context(), existing); err != nil {
 			return c.JSON(http.StatusBadRequest, fhir.ErrorOutcome(err.Error()))
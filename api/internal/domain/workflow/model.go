@@ -122,6 +122,35 @@ func (a *ActivityDefinition) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the definition from a FHIR ActivityDefinition resource,
+// the inverse of ToFHIR.
+func (a *ActivityDefinition) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("ActivityDefinition", data)
+	a.Status = r.RequiredCode("status", "draft", "active", "retired", "unknown")
+	a.DoNotPerform = r.BoolValue("doNotPerform")
+	a.URL = r.String("url")
+	a.Name = r.String("name")
+	a.Title = r.String("title")
+	a.Description = r.String("description")
+	a.Purpose = r.String("purpose")
+	a.Kind = r.String("kind")
+	code := r.CodeableConcept("code")
+	a.CodeCode, a.CodeDisplay, a.CodeSystem = code.Code, code.Display, code.System
+	a.Intent = r.Code("intent", "proposal", "plan", "directive", "order", "original-order",
+		"reflex-order", "filler-order", "instance-order", "option")
+	a.Priority = r.Code("priority", "routine", "urgent", "asap", "stat")
+	a.TimingDescription = r.String("timingString")
+	a.LocationID = r.ReferenceID("location", "Location")
+	quantity := r.Quantity("quantity")
+	a.QuantityValue, a.QuantityUnit = quantity.Value, quantity.Unit
+	a.DosageText = r.String("dosage.text")
+	a.Publisher = r.String("publisher")
+	a.EffectiveStart, a.EffectiveEnd = r.Period("effectivePeriod")
+	a.ApprovalDate = r.DateTime("approvalDate")
+	a.LastReviewDate = r.DateTime("lastReviewDate")
+	return r.Err()
+}
+
 // RequestGroup maps to the request_group table (FHIR RequestGroup resource).
 type RequestGroup struct {
 	ID                uuid.UUID  `db:"id" json:"id"`
@@ -195,6 +224,27 @@ func (rg *RequestGroup) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the request group from a FHIR RequestGroup resource, the
+// inverse of ToFHIR. Actions are managed through their own endpoints.
+func (rg *RequestGroup) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("RequestGroup", data)
+	rg.Status = r.CodeValue("status", "draft", "active", "on-hold", "revoked",
+		"completed", "entered-in-error", "unknown")
+	rg.Intent = r.RequiredCode("intent", "proposal", "plan", "directive", "order", "original-order",
+		"reflex-order", "filler-order", "instance-order", "option")
+	rg.Priority = r.Code("priority", "routine", "urgent", "asap", "stat")
+	code := r.CodeableConcept("code")
+	rg.CodeCode, rg.CodeDisplay = code.Code, code.Display
+	rg.SubjectPatientID = r.ReferenceID("subject", "Patient")
+	rg.EncounterID = r.ReferenceID("encounter", "Encounter")
+	rg.AuthoredOn = r.DateTime("authoredOn")
+	rg.AuthorID = r.ReferenceID("author", "Practitioner")
+	reason := r.CodeableConcept("reasonCode")
+	rg.ReasonCode, rg.ReasonDisplay = reason.Code, reason.Display
+	rg.Note = r.Annotation("note")
+	return r.Err()
+}
+
 // RequestGroupAction maps to the request_group_action table (child of RequestGroup).
 type RequestGroupAction struct {
 	ID                    uuid.UUID `db:"id" json:"id"`
@@ -277,6 +327,25 @@ func (gr *GuidanceResponse) ToFHIR() map[string]interface{} {
 	return result
 }
 
+// FromFHIR populates the response from a FHIR GuidanceResponse resource, the
+// inverse of ToFHIR.
+func (gr *GuidanceResponse) FromFHIR(data map[string]interface{}) error {
+	r := fhir.NewResourceReader("GuidanceResponse", data)
+	gr.ModuleURI = r.RequiredString("moduleUri")
+	gr.Status = r.RequiredCode("status", "success", "data-requested", "data-required",
+		"in-progress", "failure", "entered-in-error")
+	gr.RequestIdentifier = r.String("requestIdentifier.value")
+	gr.SubjectPatientID = r.ReferenceID("subject", "Patient")
+	gr.EncounterID = r.ReferenceID("encounter", "Encounter")
+	gr.OccurrenceDate = r.DateTime("occurrenceDateTime")
+	gr.PerformerID = r.ReferenceID("performer", "Practitioner")
+	reason := r.CodeableConcept("reasonCode")
+	gr.ReasonCode, gr.ReasonDisplay = reason.Code, reason.Display
+	gr.Note = r.Annotation("note")
+	gr.ResultReference = r.Reference("result")
+	return r.Err()
+}
+
 func strVal(s *string) string {
 	if s == nil {
 		return ""
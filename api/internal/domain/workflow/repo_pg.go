@@ -86,7 +86,7 @@ func (r *activityDefinitionRepoPG) Update(ctx context.Context, a *ActivityDefini
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE activity_definition SET status=$2, name=$3, title=$4,
 			description=$5, purpose=$6, kind=$7, intent=$8, priority=$9,
-			do_not_perform=$10, publisher=$11, note=$12, updated_at=NOW()
+			do_not_perform=$10, publisher=$11, note=$12, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		a.ID, a.Status, a.Name, a.Title,
 		a.Description, a.Purpose, a.Kind, a.Intent, a.Priority,
@@ -127,13 +127,16 @@ var adSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *activityDefinitionRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*ActivityDefinition, int, error) {
 	qb := fhir.NewSearchQuery("activity_definition", adCols)
-	qb.ApplyParams(params, adSearchParams)
+	qb.ApplyParams(ctx, params, adSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -211,7 +214,7 @@ func (r *requestGroupRepoPG) Update(ctx context.Context, rg *RequestGroup) error
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE request_group SET status=$2, intent=$3, priority=$4,
 			code_code=$5, code_display=$6, reason_code=$7, reason_display=$8,
-			note=$9, updated_at=NOW()
+			note=$9, version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		rg.ID, rg.Status, rg.Intent, rg.Priority,
 		rg.CodeCode, rg.CodeDisplay, rg.ReasonCode, rg.ReasonDisplay, rg.Note)
@@ -251,13 +254,16 @@ var rgSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *requestGroupRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*RequestGroup, int, error) {
 	qb := fhir.NewSearchQuery("request_group", rgCols)
-	qb.ApplyParams(params, rgSearchParams)
+	qb.ApplyParams(ctx, params, rgSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -372,7 +378,7 @@ func (r *guidanceResponseRepoPG) Update(ctx context.Context, gr *GuidanceRespons
 	_, err := r.conn(ctx).Exec(ctx, `
 		UPDATE guidance_response SET status=$2, module_uri=$3,
 			reason_code=$4, reason_display=$5, note=$6, result_reference=$7,
-			updated_at=NOW()
+			version_id=version_id+1, updated_at=NOW()
 		WHERE id = $1`,
 		gr.ID, gr.Status, gr.ModuleURI,
 		gr.ReasonCode, gr.ReasonDisplay, gr.Note, gr.ResultReference)
@@ -412,13 +418,16 @@ var grSearchParams = map[string]fhir.SearchParamConfig{
 
 func (r *guidanceResponseRepoPG) Search(ctx context.Context, params map[string]string, limit, offset int) ([]*GuidanceResponse, int, error) {
 	qb := fhir.NewSearchQuery("guidance_response", grCols)
-	qb.ApplyParams(params, grSearchParams)
+	qb.ApplyParams(ctx, params, grSearchParams)
 	qb.OrderBy("created_at DESC")
 
 	var total int
 	if err := r.conn(ctx).QueryRow(ctx, qb.CountSQL(), qb.CountArgs()...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if qb.CountOnly() {
+		return nil, total, nil
+	}
 
 	rows, err := r.conn(ctx).Query(ctx, qb.DataSQL(limit, offset), qb.DataArgs(limit, offset)...)
 	if err != nil {
@@ -435,3 +444,11 @@ func (r *guidanceResponseRepoPG) Search(ctx context.Context, params map[string]s
 	}
 	return items, total, nil
 }
+
+// RegisterSearchTables registers the search tables of the package's
+// repositories with r, for chained, _has and _filter searches.
+func RegisterSearchTables(r *fhir.ChainRegistry) {
+	r.RegisterTable("activity_definition", adSearchParams)
+	r.RegisterTable("request_group", rgSearchParams)
+	r.RegisterTable("guidance_response", grSearchParams)
+}
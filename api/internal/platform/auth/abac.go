@@ -297,6 +297,11 @@ func ABACMiddleware(engine *ABACEngine) echo.MiddlewareFunc {
 			if resourceType == "" {
 				return next(c)
 			}
+			// Compartment search (/fhir/Patient/:pid/:resourceType) reads
+			// resources of the type named in the path.
+			if rt := c.Param("resourceType"); rt != "" {
+				resourceType = rt
+			}
 
 			// If SMART scopes are present and already validated by FHIRScopeMiddleware,
 			// the scope check is authoritative — skip role-based ABAC. This allows
@@ -403,15 +408,17 @@ func httpMethodToFHIRAction(method string) string {
 }
 
 // extractPatientID attempts to extract a patient UUID from the echo context.
-// It checks, in order: path param "id" (for routes like /fhir/Patient/:id),
+// It checks, in order: path param "id" or "pid" (for routes like
+// /fhir/Patient/:id and /fhir/Patient/:pid/:resourceType),
 // path param "patient_id", query param "patient", query param "subject".
 func extractPatientID(c echo.Context) (uuid.UUID, bool) {
-	// For Patient resource routes the :id IS the patient ID.
+	// For Patient resource routes the :id IS the patient ID; Patient
+	// compartment searches name it :pid.
 	resourceType := extractABACResourceType(c.Path())
 
 	if resourceType == "Patient" {
-		if idStr := c.Param("id"); idStr != "" {
-			if id, err := uuid.Parse(idStr); err == nil {
+		for _, name := range []string{"id", "pid"} {
+			if id, err := uuid.Parse(c.Param(name)); err == nil {
 				return id, true
 			}
 		}
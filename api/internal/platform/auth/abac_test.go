@@ -1564,6 +1564,23 @@ func TestABAC_ExtractPatientID(t *testing.T) {
 		}
 	})
 
+	t.Run("from patient compartment path param", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/fhir/Patient/"+patientID.String()+"/Observation", nil)
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetPath("/fhir/Patient/:pid/:resourceType")
+		c.SetParamNames("pid", "resourceType")
+		c.SetParamValues(patientID.String(), "Observation")
+
+		got, ok := extractPatientID(c)
+		if !ok {
+			t.Fatal("expected patient ID extraction to succeed")
+		}
+		if got != patientID {
+			t.Errorf("got %v, want %v", got, patientID)
+		}
+	})
+
 	t.Run("no patient ID available", func(t *testing.T) {
 		e := echo.New()
 		req := httptest.NewRequest(http.MethodGet, "/fhir/Condition", nil)
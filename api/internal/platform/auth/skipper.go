@@ -20,6 +20,8 @@ var publicPaths = map[string]bool{
 	"/auth/introspect":                        true,
 	"/auth/register":                          true,
 	"/auth/jwks":                              true,
+	// Signed bulk export downloads; the URL's signature grants access.
+	"/fhir/$export-file/:jobId/:fileName":     true,
 }
 
 // AuthSkipper returns true for requests whose path should skip authentication.
@@ -17,6 +17,7 @@ func TestAuthSkipper_PublicPaths(t *testing.T) {
 		"/metrics",
 		"/.well-known/smart-configuration",
 		"/fhir/metadata",
+		"/fhir/$export-file/:jobId/:fileName",
 	}
 
 	for _, path := range publicPaths {
@@ -124,21 +124,45 @@ func extractFHIRResourceType(path string) string {
 		return ""
 	}
 
+	// Compartment search (/fhir/Patient/123/Observation) reads resources of
+	// the type that follows the compartment id.
+	if len(parts) == 4 && compartmentTypes[candidate] && isResourceTypeSegment(parts[3]) {
+		return parts[3]
+	}
+
 	return candidate
 }
 
+// compartmentTypes lists the compartments that can be searched with
+// /fhir/{Compartment}/{id}/{type}.
+var compartmentTypes = map[string]bool{
+	"Patient":       true,
+	"Encounter":     true,
+	"RelatedPerson": true,
+	"Practitioner":  true,
+	"Device":        true,
+}
+
+// isResourceTypeSegment reports whether a path segment names a resource type
+// rather than an operation, _history or _search.
+func isResourceTypeSegment(seg string) bool {
+	return seg != "" && seg[0] >= 'A' && seg[0] <= 'Z'
+}
+
 // fhirMethodToOperation maps an HTTP method (and request path) to the SMART
 // scope operation ("read" or "write").
 //
 // POST is context-sensitive:
 //   - POST to /fhir/<Resource>/_search is a search -> "read"
 //   - POST to /fhir/<Resource> (no _search) is a create -> "write"
+//   - POST to $graphql is a GraphQL request -> "read"; the GraphQL engine
+//     checks mutations against the write scopes itself
 func fhirMethodToOperation(method, path string) string {
 	switch method {
 	case http.MethodGet, http.MethodHead:
 		return "read"
 	case http.MethodPost:
-		if isSearchPath(path) {
+		if isSearchPath(path) || strings.HasSuffix(path, "/$graphql") {
 			return "read"
 		}
 		return "write"
@@ -93,6 +93,27 @@ func TestFHIRScopeMiddleware_WrongResource(t *testing.T) {
 	}
 }
 
+func TestFHIRScopeMiddleware_CompartmentSearch(t *testing.T) {
+	// GET /fhir/Patient/123/Observation reads Observations, not the Patient.
+	c, rec := newScopeTestContext(http.MethodGet, "/fhir/Patient/123/Observation", []string{"patient"}, []string{"patient/Patient.read"})
+
+	mw := FHIRScopeMiddleware()
+	if err := mw(scopeOkHandler)(c); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", rec.Code)
+	}
+
+	c, rec = newScopeTestContext(http.MethodGet, "/fhir/Patient/123/Observation", []string{"patient"}, []string{"patient/Observation.read"})
+	if err := mw(scopeOkHandler)(c); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+}
+
 func TestFHIRScopeMiddleware_CreateAllowed(t *testing.T) {
 	// POST /fhir/Patient with scope "user/Patient.write" -> allowed (create)
 	c, rec := newScopeTestContext(http.MethodPost, "/fhir/Patient", []string{"physician"}, []string{"user/Patient.write"})
@@ -447,6 +468,11 @@ func TestExtractFHIRResourceType(t *testing.T) {
 		{"/fhir/Patient", "Patient"},
 		{"/fhir/Patient/_search", "Patient"},
 		{"/fhir/Observation/456", "Observation"},
+		{"/fhir/Patient/123/Observation", "Observation"},
+		{"/fhir/Encounter/456/Condition", "Condition"},
+		{"/fhir/Patient/123/_history", "Patient"},
+		{"/fhir/Patient/123/$everything", "Patient"},
+		{"/fhir/Observation/456/Patient", "Observation"},
 		{"/fhir/metadata", ""},
 		{"/fhir/$export", ""},
 		{"/fhir/.well-known/smart-configuration", ""},
@@ -474,6 +500,8 @@ func TestFhirMethodToOperation(t *testing.T) {
 		{http.MethodHead, "/fhir/Patient/123", "read"},
 		{http.MethodPost, "/fhir/Patient", "write"},
 		{http.MethodPost, "/fhir/Patient/_search", "read"},
+		{http.MethodPost, "/fhir/$graphql", "read"},
+		{http.MethodPost, "/fhir/Patient/123/$graphql", "read"},
 		{http.MethodPut, "/fhir/Patient/123", "write"},
 		{http.MethodPatch, "/fhir/Patient/123", "write"},
 		{http.MethodDelete, "/fhir/Patient/123", "write"},
@@ -1,6 +1,7 @@
 // Package blobstore provides document/blob storage for the EHR platform.
 // It defines the BlobStore interface, an in-memory implementation suitable for
-// testing and development, and Echo HTTP handlers for multipart upload,
+// testing and development, a PostgreSQL implementation shared by all
+// replicas (see PGBlobStore), and Echo HTTP handlers for multipart upload,
 // download, metadata retrieval, deletion, and search.
 package blobstore
 
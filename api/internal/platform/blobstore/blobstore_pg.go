@@ -0,0 +1,306 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// pgQuerier is the subset of pgxpool.Pool used by PGBlobStore.
+type pgQuerier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// PGBlobStore is a PostgreSQL-backed BlobStore shared by all replicas, so
+// that a blob uploaded through one replica, or written by a bulk export
+// running on it, can be read through any other and survives restarts.
+// Blobs live in public.blobs with the tenant that stored them, and every
+// operation is scoped to the tenant in the context, when there is one.
+//
+// The content is stored in public.blob_chunks in chunks of pgBlobChunkSize,
+// written as the upload is read and read as the download is consumed, so
+// that no more than a chunk of a blob is held in memory.
+type PGBlobStore struct {
+	db pgQuerier
+}
+
+// NewPGBlobStore creates a PGBlobStore on pool.
+func NewPGBlobStore(pool *pgxpool.Pool) *PGBlobStore {
+	return &PGBlobStore{db: pool}
+}
+
+const pgBlobColumns = `id, file_name, content_type, size, patient_id, encounter_id,
+	category, hash, created_at, created_by, tags`
+
+// pgBlobChunkSize is the size of the chunks blob content is stored in.
+const pgBlobChunkSize = 1 << 20
+
+// tenantScope returns a condition restricting blobs to the tenant in ctx,
+// using argument idx, or an always-true condition when there is none.
+func tenantScope(ctx context.Context, idx int) (string, []interface{}) {
+	tenant := db.TenantFromContext(ctx)
+	if tenant == "" {
+		return "TRUE", nil
+	}
+	return fmt.Sprintf("tenant_id = $%d", idx), []interface{}{tenant}
+}
+
+// Upload validates inputs, stores the content chunk by chunk as it is read
+// while computing its SHA-256 hash, and then records the blob with the
+// tenant in ctx. Until it is recorded the blob cannot be read, and its
+// chunks are removed if the upload fails.
+func (s *PGBlobStore) Upload(ctx context.Context, meta BlobMetadata, content io.Reader) (*BlobMetadata, error) {
+	if meta.FileName == "" {
+		return nil, ErrMissingFileName
+	}
+	meta.ID = uuid.New().String()
+	size, hash, err := s.storeChunks(ctx, meta.ID, content)
+	if err != nil {
+		s.deleteChunks(meta.ID)
+		return nil, err
+	}
+
+	meta.Size = size
+	meta.Hash = hash
+	meta.CreatedAt = time.Now().UTC()
+	if meta.Tags == nil {
+		meta.Tags = make(map[string]string)
+	}
+	tags, err := json.Marshal(meta.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("encode tags: %w", err)
+	}
+
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO public.blobs
+			(id, tenant_id, file_name, content_type, size, patient_id, encounter_id,
+			 category, hash, created_at, created_by, tags, content)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		meta.ID, db.TenantFromContext(ctx), meta.FileName, meta.ContentType, meta.Size,
+		meta.PatientID, meta.EncounterID, meta.Category, meta.Hash, meta.CreatedAt,
+		meta.CreatedBy, tags, []byte{})
+	if err != nil {
+		s.deleteChunks(meta.ID)
+		return nil, fmt.Errorf("store blob: %w", err)
+	}
+	out := meta
+	return &out, nil
+}
+
+// storeChunks writes content to the chunks of blob id and returns its size
+// and hex SHA-256 hash.
+func (s *PGBlobStore) storeChunks(ctx context.Context, id string, content io.Reader) (int64, string, error) {
+	h := sha256.New()
+	buf := make([]byte, pgBlobChunkSize)
+	var size int64
+	for seq := 0; ; seq++ {
+		n, err := io.ReadFull(content, buf)
+		if n > 0 {
+			if size += int64(n); size > MaxFileSize {
+				return 0, "", ErrFileTooLarge
+			}
+			h.Write(buf[:n])
+			if _, err := s.db.Exec(ctx,
+				`INSERT INTO public.blob_chunks (blob_id, seq, data) VALUES ($1, $2, $3)`,
+				id, seq, buf[:n]); err != nil {
+				return 0, "", fmt.Errorf("store blob chunk: %w", err)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return size, fmt.Sprintf("%x", h.Sum(nil)), nil
+		}
+		if err != nil {
+			return 0, "", fmt.Errorf("reading content: %w", err)
+		}
+	}
+}
+
+// deleteChunks removes the chunks of blob id. It runs without the request
+// context, which may be what cancelled the upload.
+func (s *PGBlobStore) deleteChunks(id string) {
+	_, _ = s.db.Exec(context.Background(), `DELETE FROM public.blob_chunks WHERE blob_id = $1`, id)
+}
+
+// Download returns an io.ReadCloser over the blob content and its metadata.
+// The content is read a chunk at a time as the reader is consumed. Blobs
+// stored before content was chunked are held whole in public.blobs.content.
+func (s *PGBlobStore) Download(ctx context.Context, id string) (io.ReadCloser, *BlobMetadata, error) {
+	scope, args := tenantScope(ctx, 2)
+	var data []byte
+	meta, err := scanBlob(s.db.QueryRow(ctx,
+		`SELECT `+pgBlobColumns+`, content FROM public.blobs WHERE id = $1 AND `+scope,
+		append([]interface{}{id}, args...)...), &data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &pgBlobReader{ctx: ctx, db: s.db, id: id, buf: data}, meta, nil
+}
+
+// pgBlobReader reads the chunks of a blob in order.
+type pgBlobReader struct {
+	ctx  context.Context
+	db   pgQuerier
+	id   string
+	seq  int
+	buf  []byte
+	done bool
+}
+
+func (r *pgBlobReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		err := r.db.QueryRow(r.ctx,
+			`SELECT data FROM public.blob_chunks WHERE blob_id = $1 AND seq = $2`,
+			r.id, r.seq).Scan(&r.buf)
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.done = true
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("read blob chunk: %w", err)
+		}
+		r.seq++
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *pgBlobReader) Close() error {
+	r.done, r.buf = true, nil
+	return nil
+}
+
+// Delete removes a blob by ID.
+func (s *PGBlobStore) Delete(ctx context.Context, id string) error {
+	scope, args := tenantScope(ctx, 2)
+	tag, err := s.db.Exec(ctx, `DELETE FROM public.blobs WHERE id = $1 AND `+scope,
+		append([]interface{}{id}, args...)...)
+	if err != nil {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrBlobNotFound
+	}
+	if _, err := s.db.Exec(ctx, `DELETE FROM public.blob_chunks WHERE blob_id = $1`, id); err != nil {
+		return fmt.Errorf("delete blob chunks: %w", err)
+	}
+	return nil
+}
+
+// GetMetadata returns blob metadata without content.
+func (s *PGBlobStore) GetMetadata(ctx context.Context, id string) (*BlobMetadata, error) {
+	scope, args := tenantScope(ctx, 2)
+	return scanBlob(s.db.QueryRow(ctx,
+		`SELECT `+pgBlobColumns+` FROM public.blobs WHERE id = $1 AND `+scope,
+		append([]interface{}{id}, args...)...))
+}
+
+// ListByPatient returns blobs for a given patient, optionally filtered by
+// category. It returns the matching page and the total count.
+func (s *PGBlobStore) ListByPatient(ctx context.Context, patientID, category string, limit, offset int) ([]*BlobMetadata, int, error) {
+	return s.Search(ctx, SearchParams{PatientID: patientID, Category: category, Limit: limit, Offset: offset})
+}
+
+// Search returns blobs matching the given search parameters, oldest first.
+func (s *PGBlobStore) Search(ctx context.Context, params SearchParams) ([]*BlobMetadata, int, error) {
+	scope, args := tenantScope(ctx, 1)
+	conds := []string{scope}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if params.PatientID != "" {
+		add("patient_id = $%d", params.PatientID)
+	}
+	if params.Category != "" {
+		add("category = $%d", params.Category)
+	}
+	if params.ContentType != "" {
+		add("content_type = $%d", params.ContentType)
+	}
+	if params.CreatedAfter != nil {
+		add("created_at >= $%d", *params.CreatedAfter)
+	}
+	if params.CreatedBefore != nil {
+		add("created_at <= $%d", *params.CreatedBefore)
+	}
+	if params.FileName != "" {
+		add("file_name ILIKE $%d", "%"+params.FileName+"%")
+	}
+	if len(params.Tags) > 0 {
+		tags, err := json.Marshal(params.Tags)
+		if err != nil {
+			return nil, 0, fmt.Errorf("encode tags: %w", err)
+		}
+		add("tags @> $%d::jsonb", string(tags))
+	}
+	where := strings.Join(conds, " AND ")
+
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM public.blobs WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count blobs: %w", err)
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	offset := params.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	rows, err := s.db.Query(ctx, fmt.Sprintf(`SELECT `+pgBlobColumns+` FROM public.blobs WHERE %s
+		ORDER BY created_at, id LIMIT %d OFFSET %d`, where, limit, offset), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("search blobs: %w", err)
+	}
+	defer rows.Close()
+	var out []*BlobMetadata
+	for rows.Next() {
+		meta, err := scanBlob(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, meta)
+	}
+	return out, total, rows.Err()
+}
+
+// scanBlob scans the pgBlobColumns of a row, followed by dest.
+func scanBlob(row pgx.Row, dest ...interface{}) (*BlobMetadata, error) {
+	var meta BlobMetadata
+	var tags []byte
+	err := row.Scan(append([]interface{}{&meta.ID, &meta.FileName, &meta.ContentType, &meta.Size,
+		&meta.PatientID, &meta.EncounterID, &meta.Category, &meta.Hash, &meta.CreatedAt,
+		&meta.CreatedBy, &tags}, dest...)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read blob: %w", err)
+	}
+	if len(tags) > 0 {
+		if err := json.Unmarshal(tags, &meta.Tags); err != nil {
+			return nil, fmt.Errorf("decode blob tags: %w", err)
+		}
+	}
+	meta.CreatedAt = meta.CreatedAt.UTC()
+	return &meta, nil
+}
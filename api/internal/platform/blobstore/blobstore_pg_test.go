@@ -0,0 +1,182 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeBlobDB is a pgQuerier keeping public.blobs and public.blob_chunks in
+// memory, for the statements PGBlobStore issues on upload, download and
+// delete.
+type fakeBlobDB struct {
+	mu     sync.Mutex
+	blobs  map[string][]interface{} // id -> scanned columns of a download
+	chunks map[string][][]byte
+}
+
+func newFakeBlobDB() *fakeBlobDB {
+	return &fakeBlobDB{blobs: make(map[string][]interface{}), chunks: make(map[string][][]byte)}
+}
+
+func (f *fakeBlobDB) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case strings.Contains(sql, "INSERT INTO public.blob_chunks"):
+		id, seq := args[0].(string), args[1].(int)
+		if seq != len(f.chunks[id]) {
+			return pgconn.CommandTag{}, fmt.Errorf("chunk %d of %s out of order", seq, id)
+		}
+		f.chunks[id] = append(f.chunks[id], append([]byte(nil), args[2].([]byte)...))
+	case strings.Contains(sql, "INSERT INTO public.blobs"):
+		// id, file_name, content_type, size, patient_id, encounter_id,
+		// category, hash, created_at, created_by, tags, content
+		f.blobs[args[0].(string)] = []interface{}{args[0], args[2], args[3], args[4], args[5], args[6],
+			args[7], args[8], args[9], args[10], args[11], args[12]}
+	case strings.Contains(sql, "DELETE FROM public.blob_chunks"):
+		delete(f.chunks, args[0].(string))
+	case strings.Contains(sql, "DELETE FROM public.blobs"):
+		if _, ok := f.blobs[args[0].(string)]; !ok {
+			return pgconn.NewCommandTag("DELETE 0"), nil
+		}
+		delete(f.blobs, args[0].(string))
+		return pgconn.NewCommandTag("DELETE 1"), nil
+	default:
+		return pgconn.CommandTag{}, fmt.Errorf("unexpected statement: %s", sql)
+	}
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+
+func (f *fakeBlobDB) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeBlobDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if strings.Contains(sql, "FROM public.blob_chunks") {
+		chunks, seq := f.chunks[args[0].(string)], args[1].(int)
+		if seq >= len(chunks) {
+			return fakeBlobRow{err: pgx.ErrNoRows}
+		}
+		return fakeBlobRow{values: []interface{}{chunks[seq]}}
+	}
+	row, ok := f.blobs[args[0].(string)]
+	if !ok {
+		return fakeBlobRow{err: pgx.ErrNoRows}
+	}
+	return fakeBlobRow{values: row}
+}
+
+type fakeBlobRow struct {
+	values []interface{}
+	err    error
+}
+
+func (r fakeBlobRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r.values[i]))
+	}
+	return nil
+}
+
+// failingReader returns n bytes of content and then err.
+type failingReader struct {
+	n   int
+	err error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		return 0, r.err
+	}
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+	r.n -= len(p)
+	return len(p), nil
+}
+
+func TestPGBlobStore_StoresContentInChunks(t *testing.T) {
+	fake := newFakeBlobDB()
+	store := &PGBlobStore{db: fake}
+	ctx := context.Background()
+	content := bytes.Repeat([]byte("0123456789abcdef"), (2*pgBlobChunkSize+pgBlobChunkSize/2)/16)
+
+	meta, err := store.Upload(ctx, BlobMetadata{FileName: "Patient.ndjson", ContentType: "application/fhir+ndjson"}, bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Size != int64(len(content)) || meta.Hash != fmt.Sprintf("%x", sha256.Sum256(content)) {
+		t.Errorf("size %d, hash %s do not describe the content", meta.Size, meta.Hash)
+	}
+	chunks := fake.chunks[meta.ID]
+	if len(chunks) != 3 || len(chunks[0]) != pgBlobChunkSize || len(chunks[2]) != pgBlobChunkSize/2 {
+		t.Errorf("expected 3 chunks of at most %d bytes, got %d", pgBlobChunkSize, len(chunks))
+	}
+
+	rc, got, err := store.Download(ctx, meta.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || !bytes.Equal(data, content) {
+		t.Errorf("downloaded %d bytes (%v), want the %d uploaded", len(data), err, len(content))
+	}
+	if got.FileName != "Patient.ndjson" || got.CreatedAt.After(time.Now()) {
+		t.Errorf("unexpected metadata: %+v", got)
+	}
+
+	if err := store.Delete(ctx, meta.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.chunks) != 0 || len(fake.blobs) != 0 {
+		t.Errorf("expected blob and chunks removed, got %d blobs and %d chunked", len(fake.blobs), len(fake.chunks))
+	}
+}
+
+func TestPGBlobStore_FailedUploadLeavesNothing(t *testing.T) {
+	fake := newFakeBlobDB()
+	store := &PGBlobStore{db: fake}
+	readErr := errors.New("export aborted")
+
+	_, err := store.Upload(context.Background(), BlobMetadata{FileName: "Patient.ndjson"},
+		&failingReader{n: pgBlobChunkSize + 10, err: readErr})
+	if !errors.Is(err, readErr) {
+		t.Fatalf("expected the read error, got %v", err)
+	}
+	if len(fake.chunks) != 0 || len(fake.blobs) != 0 {
+		t.Errorf("expected no blob or chunks left, got %d blobs and %d chunked", len(fake.blobs), len(fake.chunks))
+	}
+}
+
+func TestPGBlobStore_DownloadsUnchunkedContent(t *testing.T) {
+	fake := newFakeBlobDB()
+	store := &PGBlobStore{db: fake}
+	fake.blobs["old"] = []interface{}{"old", "a.txt", "text/plain", int64(5), "", "", "", "", time.Now(), "", []byte("{}"), []byte("hello")}
+
+	rc, _, err := store.Download(context.Background(), "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if data, err := io.ReadAll(rc); err != nil || string(data) != "hello" {
+		t.Errorf("Download = %q, %v; want the stored content", data, err)
+	}
+}
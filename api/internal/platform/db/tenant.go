@@ -104,6 +104,39 @@ func extractTenantID(c echo.Context, defaultTenant string) string {
 	return defaultTenant
 }
 
+// WithTenantConn acquires a connection from pool and scopes it to tenantID
+// and the identity carried by ctx, as TenantMiddleware does for requests. It
+// is used by background work that runs on behalf of a client outside of a
+// request. The caller must release the returned connection.
+func WithTenantConn(ctx context.Context, pool *pgxpool.Pool, tenantID string) (context.Context, *pgxpool.Conn, error) {
+	if !tenantIDPattern.MatchString(tenantID) {
+		return ctx, nil, fmt.Errorf("invalid tenant identifier %q", tenantID)
+	}
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return ctx, nil, fmt.Errorf("acquire connection: %w", err)
+	}
+	stmts := []string{
+		fmt.Sprintf("SET search_path TO tenant_%s, shared, public", tenantID),
+		"SET app.current_tenant_id = " + quoteLiteral(tenantID),
+	}
+	if userID := auth.UserIDFromContext(ctx); userID != "" {
+		stmts = append(stmts, "SET app.current_user_id = "+quoteLiteral(userID))
+	}
+	if roles := auth.RolesFromContext(ctx); len(roles) > 0 {
+		stmts = append(stmts, "SET app.current_user_roles = "+quoteLiteral(strings.Join(roles, ",")))
+	}
+	for _, stmt := range stmts {
+		if _, err := conn.Exec(ctx, stmt); err != nil {
+			conn.Release()
+			return ctx, nil, fmt.Errorf("scope connection to tenant %s: %w", tenantID, err)
+		}
+	}
+	ctx = context.WithValue(ctx, TenantIDKey, tenantID)
+	ctx = context.WithValue(ctx, DBConnKey, conn)
+	return ctx, conn, nil
+}
+
 // ConnFromContext retrieves the tenant-scoped database connection from context.
 func ConnFromContext(ctx context.Context) *pgxpool.Conn {
 	conn, _ := ctx.Value(DBConnKey).(*pgxpool.Conn)
@@ -1,6 +1,7 @@
 package fhir
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -889,25 +890,15 @@ func NewIncludeRegistryResolver(registry *IncludeRegistry) ApplyOpResourceResolv
 
 // ResolveReference resolves a FHIR reference (e.g. "Patient/123") using the IncludeRegistry's fetchers.
 func (r *includeRegistryResolver) ResolveReference(ctx interface{}, reference string) (map[string]interface{}, error) {
-	parts := strings.SplitN(reference, "/", 2)
-	if len(parts) != 2 {
+	if _, _, ok := SplitReference(reference); !ok && !strings.Contains(reference, "://") {
 		return nil, fmt.Errorf("invalid reference format: %s", reference)
 	}
-	resourceType, id := parts[0], parts[1]
-
-	r.registry.mu.RLock()
-	fetcher, ok := r.registry.fetchers[resourceType]
-	r.registry.mu.RUnlock()
-
-	if !ok {
-		return nil, fmt.Errorf("no fetcher registered for resource type: %s", resourceType)
-	}
 
 	// Use a background context if the provided ctx is not a context.Context.
-	ctxVal, ok := ctx.(interface{ Value(interface{}) interface{} })
-	if ok {
-		_ = ctxVal // The fetcher needs context.Context
+	reqCtx, ok := ctx.(context.Context)
+	if !ok || reqCtx == nil {
+		reqCtx = context.Background()
 	}
 
-	return fetcher(nil, id)
+	return r.registry.resolveReference(reqCtx, reference)
 }
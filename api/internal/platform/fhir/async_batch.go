@@ -2,6 +2,8 @@ package fhir
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -41,6 +43,10 @@ type AsyncBatchJob struct {
 	Errors           []AsyncBatchError      `json:"errors,omitempty"`
 	Request          string                 `json:"request"`
 	CreatedAt        time.Time              `json:"createdAt"`
+
+	// Bundle is the submitted bundle. Durable stores keep it so that the job
+	// can be run, or resumed, by any replica.
+	Bundle *TransactionBundle `json:"-"`
 }
 
 // AsyncBatchError describes an error for a specific entry.
@@ -74,6 +80,22 @@ type AsyncBatchStore interface {
 	DeleteJob(ctx interface{}, jobID string) error
 }
 
+// AsyncBatchEntryResult is the outcome of one processed entry of a batch.
+type AsyncBatchEntryResult struct {
+	Index    int                    `json:"index"`
+	Response map[string]interface{} `json:"response"`        // The entry of the response bundle
+	Error    *AsyncBatchError       `json:"error,omitempty"` // Set when the entry failed
+}
+
+// AsyncBatchEntryStore is implemented by AsyncBatchStores that record each
+// processed entry of a batch. The processor then counts progress through
+// SaveEntryResult, and a batch resumed after a restart skips the entries
+// that already ran.
+type AsyncBatchEntryStore interface {
+	SaveEntryResult(ctx context.Context, jobID string, result AsyncBatchEntryResult) error
+	EntryResults(ctx context.Context, jobID string) ([]AsyncBatchEntryResult, error)
+}
+
 // InMemoryAsyncBatchStore is a test/demo implementation of AsyncBatchStore.
 type InMemoryAsyncBatchStore struct {
 	mu   sync.RWMutex
@@ -85,6 +107,7 @@ type AsyncBatchProcessor struct {
 	store     AsyncBatchStore
 	config    AsyncBatchConfig
 	handler   func(method, url string, resource map[string]interface{}) (*BundleEntryResponse, error)
+	tx        *TransactionProcessor
 	cancelFns map[string]context.CancelFunc
 	mu        sync.Mutex
 }
@@ -252,6 +275,13 @@ func ShouldProcessAsync(bundle *TransactionBundle, config *AsyncBatchConfig, pre
 // SubmitAsyncBatch submits a batch/transaction for async processing and returns
 // the job ID. The bundle is validated against the maximum async entry limit.
 func (p *AsyncBatchProcessor) SubmitAsyncBatch(bundle *TransactionBundle, requestURI string) (string, error) {
+	return p.SubmitAsyncBatchContext(context.Background(), bundle, requestURI)
+}
+
+// SubmitAsyncBatchContext is SubmitAsyncBatch for the client in ctx. Durable
+// stores record the client's tenant and identity with the job, so that the
+// job runs on its behalf.
+func (p *AsyncBatchProcessor) SubmitAsyncBatchContext(ctx context.Context, bundle *TransactionBundle, requestURI string) (string, error) {
 	if len(bundle.Entries) > p.config.MaxAsyncEntries {
 		return "", fmt.Errorf("bundle contains %d entries which exceeds maximum of %d",
 			len(bundle.Entries), p.config.MaxAsyncEntries)
@@ -261,9 +291,9 @@ func (p *AsyncBatchProcessor) SubmitAsyncBatch(bundle *TransactionBundle, reques
 		BundleType:   bundle.Type,
 		TotalEntries: len(bundle.Entries),
 		Request:      requestURI,
+		Bundle:       bundle,
 	}
 
-	ctx := context.Background()
 	if err := p.store.CreateJob(ctx, job); err != nil {
 		return "", fmt.Errorf("failed to create async batch job: %w", err)
 	}
@@ -271,20 +301,83 @@ func (p *AsyncBatchProcessor) SubmitAsyncBatch(bundle *TransactionBundle, reques
 	return job.ID, nil
 }
 
-// ProcessBatchAsync processes a batch bundle asynchronously with progress
-// tracking. Each entry is processed independently; failures are captured
-// per-entry and do not affect other entries.
-func (p *AsyncBatchProcessor) ProcessBatchAsync(jobID string, bundle *TransactionBundle) {
-	ctx := context.Background()
+// SetTransactionProcessor makes the processor execute entries through tp,
+// the processor that serves synchronous transaction and batch Bundles,
+// instead of the handler given to NewAsyncBatchProcessor. Transactions then
+// run atomically in one database transaction, and batch entries run one at
+// a time because they share the job's tenant connection. It is meant for
+// jobs run by an AsyncJobRunner, which supplies that connection.
+func (p *AsyncBatchProcessor) SetTransactionProcessor(tp *TransactionProcessor) {
+	p.tx = tp
+}
 
-	// Mark job as processing.
+// RunJob is an AsyncJobFunc that runs a queued job from the bundle stored
+// with it. Register it with an AsyncJobRunner for the "batch" and
+// "transaction" kinds.
+func (p *AsyncBatchProcessor) RunJob(ctx context.Context, jobID string, payload json.RawMessage) error {
+	bundle, err := ParseTransactionBundle(payload)
+	if err != nil {
+		return fmt.Errorf("decode bundle of async batch job %s: %w", jobID, err)
+	}
+	ctx, done := p.jobContext(ctx, jobID)
+	defer done()
+	if bundle.Type == "transaction" {
+		return p.processTransaction(ctx, jobID, bundle)
+	}
+	return p.processBatch(ctx, jobID, bundle)
+}
+
+// jobContext returns a context that CancelJob cancels, and a function to
+// call when the job returns.
+func (p *AsyncBatchProcessor) jobContext(parent context.Context, jobID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	p.mu.Lock()
+	p.cancelFns[jobID] = cancel
+	p.mu.Unlock()
+	return ctx, func() {
+		p.mu.Lock()
+		delete(p.cancelFns, jobID)
+		p.mu.Unlock()
+		cancel()
+	}
+}
+
+// startJob marks a job as processing. It returns nil when the job has
+// already finished or been cancelled, for example when a resumed job had
+// completed before its lease lapsed.
+func (p *AsyncBatchProcessor) startJob(ctx context.Context, jobID string) (*AsyncBatchJob, error) {
 	job, err := p.store.GetJob(ctx, jobID)
 	if err != nil {
-		return
+		return nil, err
+	}
+	switch job.Status {
+	case "completed", "failed", "cancelled":
+		return nil, nil
+	case "queued":
+		job.StartTime = time.Now().UTC()
 	}
 	job.Status = "processing"
-	job.StartTime = time.Now().UTC()
 	_ = p.store.UpdateJob(ctx, job)
+	return job, nil
+}
+
+// ProcessBatchAsync processes a batch bundle asynchronously with progress
+// tracking. Each entry is processed independently; failures are captured
+// per-entry and do not affect other entries.
+func (p *AsyncBatchProcessor) ProcessBatchAsync(jobID string, bundle *TransactionBundle) {
+	ctx, done := p.jobContext(context.Background(), jobID)
+	defer done()
+	_ = p.processBatch(ctx, jobID, bundle)
+}
+
+// processBatch runs a batch job until it completes or ctx is cancelled. When
+// the store is an AsyncBatchEntryStore, entries recorded by an earlier
+// attempt are not run again.
+func (p *AsyncBatchProcessor) processBatch(ctx context.Context, jobID string, bundle *TransactionBundle) error {
+	job, err := p.startJob(ctx, jobID)
+	if job == nil {
+		return err
+	}
 
 	entryCount := len(bundle.Entries)
 	if entryCount == 0 {
@@ -298,32 +391,61 @@ func (p *AsyncBatchProcessor) ProcessBatchAsync(jobID string, bundle *Transactio
 			"entry":        []interface{}{},
 		}
 		_ = p.store.UpdateJob(ctx, job)
-		return
+		return nil
 	}
 
-	// Process entries with worker pool.
-	type entryResult struct {
-		index    int
-		response *BundleEntryResponse
-		err      error
+	results := make([]*AsyncBatchEntryResult, entryCount)
+	entryStore, _ := p.store.(AsyncBatchEntryStore)
+	if entryStore != nil {
+		done, err := entryStore.EntryResults(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		for i := range done {
+			if done[i].Index >= 0 && done[i].Index < entryCount {
+				results[done[i].Index] = &done[i]
+			}
+		}
 	}
 
-	results := make([]entryResult, entryCount)
+	// Process entries with worker pool.
+	workers := p.config.WorkerCount
+	if p.tx != nil {
+		workers = 1
+	}
 	var wg sync.WaitGroup
-	sem := make(chan struct{}, p.config.WorkerCount)
+	sem := make(chan struct{}, workers)
 
 	var mu sync.Mutex
 	processedCount := 0
+	var saveErr error
 
 	for i, entry := range bundle.Entries {
-		wg.Add(1)
+		if results[i] != nil {
+			processedCount++
+			continue
+		}
 		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+		wg.Add(1)
 		go func(idx int, e TransactionEntry) {
 			defer wg.Done()
 			defer func() { <-sem }()
 
-			resp, err := ProcessEntryWithRetry(&e, p.handler, &p.config)
-			results[idx] = entryResult{index: idx, response: resp, err: err}
+			result := p.runEntry(ctx, idx, e)
+			results[idx] = &result
+
+			if entryStore != nil {
+				if err := entryStore.SaveEntryResult(ctx, jobID, result); err != nil {
+					mu.Lock()
+					saveErr = err
+					mu.Unlock()
+				}
+				return
+			}
 
 			mu.Lock()
 			processedCount++
@@ -341,44 +463,30 @@ func (p *AsyncBatchProcessor) ProcessBatchAsync(jobID string, bundle *Transactio
 		}(i, entry)
 	}
 	wg.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if saveErr != nil {
+		return saveErr
+	}
 
 	// Aggregate results.
-	job, _ = p.store.GetJob(ctx, jobID)
+	job, err = p.store.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
 	successCount := 0
 	errorCount := 0
 	var errors []AsyncBatchError
 	responseEntries := make([]interface{}, entryCount)
 
-	for _, r := range results {
-		if r.err != nil {
+	for i, r := range results {
+		responseEntries[i] = r.Response
+		if r.Error != nil {
 			errorCount++
-			errors = append(errors, AsyncBatchError{
-				EntryIndex:  r.index,
-				Method:      bundle.Entries[r.index].Request.Method,
-				URL:         bundle.Entries[r.index].Request.URL,
-				StatusCode:  400,
-				Diagnostics: r.err.Error(),
-				Severity:    "error",
-			})
-			responseEntries[r.index] = map[string]interface{}{
-				"response": map[string]interface{}{
-					"status":  "400 Bad Request",
-					"outcome": map[string]interface{}{"resourceType": "OperationOutcome", "issue": []map[string]interface{}{{"severity": "error", "code": "processing", "diagnostics": r.err.Error()}}},
-				},
-			}
+			errors = append(errors, *r.Error)
 		} else {
 			successCount++
-			entry := map[string]interface{}{
-				"response": map[string]interface{}{
-					"status": r.response.Status,
-				},
-			}
-			if r.response.Location != "" {
-				entry["fullUrl"] = r.response.Location
-				resp := entry["response"].(map[string]interface{})
-				resp["location"] = r.response.Location
-			}
-			responseEntries[r.index] = entry
 		}
 	}
 
@@ -396,22 +504,98 @@ func (p *AsyncBatchProcessor) ProcessBatchAsync(jobID string, bundle *Transactio
 		"entry":        responseEntries,
 	}
 	_ = p.store.UpdateJob(ctx, job)
+	return nil
+}
+
+// runEntry executes one batch entry.
+func (p *AsyncBatchProcessor) runEntry(ctx context.Context, idx int, e TransactionEntry) AsyncBatchEntryResult {
+	if p.tx != nil {
+		respEntry, entryErr := p.tx.executeEntry(ctx, &e, nil, nil)
+		if entryErr != nil {
+			return AsyncBatchEntryResult{
+				Index: idx,
+				Response: map[string]interface{}{
+					"response": map[string]interface{}{
+						"status":  statusLine(entryErr.Status),
+						"outcome": entryErr.Outcome,
+					},
+				},
+				Error: &AsyncBatchError{
+					EntryIndex:  idx,
+					Method:      e.Request.Method,
+					URL:         e.Request.URL,
+					StatusCode:  entryErr.Status,
+					Diagnostics: entryErr.Error(),
+					Severity:    "error",
+				},
+			}
+		}
+		return AsyncBatchEntryResult{Index: idx, Response: bundleEntryMap(respEntry)}
+	}
+
+	resp, err := ProcessEntryWithRetry(&e, p.handler, &p.config)
+	if err != nil {
+		return AsyncBatchEntryResult{
+			Index: idx,
+			Response: map[string]interface{}{
+				"response": map[string]interface{}{
+					"status":  "400 Bad Request",
+					"outcome": map[string]interface{}{"resourceType": "OperationOutcome", "issue": []map[string]interface{}{{"severity": "error", "code": "processing", "diagnostics": err.Error()}}},
+				},
+			},
+			Error: &AsyncBatchError{
+				EntryIndex:  idx,
+				Method:      e.Request.Method,
+				URL:         e.Request.URL,
+				StatusCode:  400,
+				Diagnostics: err.Error(),
+				Severity:    "error",
+			},
+		}
+	}
+	entry := map[string]interface{}{
+		"response": map[string]interface{}{
+			"status": resp.Status,
+		},
+	}
+	if resp.Location != "" {
+		entry["fullUrl"] = resp.Location
+		r := entry["response"].(map[string]interface{})
+		r["location"] = resp.Location
+	}
+	return AsyncBatchEntryResult{Index: idx, Response: entry}
+}
+
+// bundleEntryMap converts a response bundle entry to its JSON object form,
+// as stored in a job's result bundle.
+func bundleEntryMap(entry BundleEntry) map[string]interface{} {
+	var m map[string]interface{}
+	if b, err := json.Marshal(entry); err == nil {
+		_ = json.Unmarshal(b, &m)
+	}
+	return m
 }
 
 // ProcessTransactionAsync processes a transaction bundle asynchronously with
 // atomic semantics. If any entry fails, the entire transaction is marked as
 // failed and errors are recorded.
 func (p *AsyncBatchProcessor) ProcessTransactionAsync(jobID string, bundle *TransactionBundle) {
-	ctx := context.Background()
+	ctx, done := p.jobContext(context.Background(), jobID)
+	defer done()
+	_ = p.processTransaction(ctx, jobID, bundle)
+}
 
-	// Mark job as processing.
-	job, err := p.store.GetJob(ctx, jobID)
-	if err != nil {
-		return
+// processTransaction runs a transaction job until it completes or ctx is
+// cancelled. A transaction interrupted before it commits leaves no changes,
+// so a resumed transaction starts over.
+func (p *AsyncBatchProcessor) processTransaction(ctx context.Context, jobID string, bundle *TransactionBundle) error {
+	job, err := p.startJob(ctx, jobID)
+	if job == nil {
+		return err
+	}
+	if p.tx != nil {
+		return p.executeTransaction(ctx, job, bundle)
 	}
-	job.Status = "processing"
-	job.StartTime = time.Now().UTC()
-	_ = p.store.UpdateJob(ctx, job)
 
 	// Sort entries according to FHIR transaction processing order.
 	sorted := SortTransactionEntries(bundle.Entries)
@@ -422,6 +606,9 @@ func (p *AsyncBatchProcessor) ProcessTransactionAsync(jobID string, bundle *Tran
 	successCount := 0
 
 	for i, entry := range sorted {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		// Resolve urn:uuid references in resources.
 		if entry.Resource != nil && len(idMap) > 0 {
 			resolveRefsInResource(entry.Resource, idMap)
@@ -450,7 +637,7 @@ func (p *AsyncBatchProcessor) ProcessTransactionAsync(jobID string, bundle *Tran
 				},
 			}
 			_ = p.store.UpdateJob(ctx, job)
-			return
+			return nil
 		}
 
 		// Map urn:uuid references.
@@ -496,12 +683,61 @@ func (p *AsyncBatchProcessor) ProcessTransactionAsync(jobID string, bundle *Tran
 		"entry":        responseEntries,
 	}
 	_ = p.store.UpdateJob(ctx, job)
+	return nil
+}
+
+// executeTransaction runs a transaction job through the transaction
+// processor.
+func (p *AsyncBatchProcessor) executeTransaction(ctx context.Context, job *AsyncBatchJob, bundle *TransactionBundle) error {
+	result, err := p.tx.ExecuteTransaction(ctx, bundle, nil)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	now := time.Now().UTC()
+	job.EndTime = &now
+	if err != nil {
+		var entryErr *TransactionEntryError
+		if !errors.As(err, &entryErr) {
+			return err
+		}
+		failure := AsyncBatchError{
+			EntryIndex:  entryErr.Index,
+			StatusCode:  entryErr.Status,
+			Diagnostics: entryErr.Error(),
+			Severity:    "error",
+		}
+		if entryErr.Index >= 0 && entryErr.Index < len(bundle.Entries) {
+			failure.Method = bundle.Entries[entryErr.Index].Request.Method
+			failure.URL = bundle.Entries[entryErr.Index].Request.URL
+		}
+		job.Status = "failed"
+		job.ErrorCount = 1
+		job.Errors = []AsyncBatchError{failure}
+		return p.store.UpdateJob(ctx, job)
+	}
+
+	var resultBundle map[string]interface{}
+	if b, err := json.Marshal(result); err == nil {
+		_ = json.Unmarshal(b, &resultBundle)
+	}
+	job.Status = "completed"
+	job.ProcessedEntries = len(bundle.Entries)
+	job.SuccessCount = len(bundle.Entries)
+	job.Progress = 1.0
+	job.ResultBundle = resultBundle
+	return p.store.UpdateJob(ctx, job)
 }
 
 // CancelJob cancels a running async job. Returns an error if the job is not
 // found or is already in a terminal state (completed, failed).
 func (p *AsyncBatchProcessor) CancelJob(jobID string) error {
-	ctx := context.Background()
+	return p.CancelJobContext(context.Background(), jobID)
+}
+
+// CancelJobContext is CancelJob for the client in ctx. A job running in this
+// process stops at once; durable stores stop a job running on another
+// replica at that replica's next heartbeat.
+func (p *AsyncBatchProcessor) CancelJobContext(ctx context.Context, jobID string) error {
 	job, err := p.store.GetJob(ctx, jobID)
 	if err != nil {
 		return err
@@ -597,8 +833,15 @@ func BuildAsyncBatchResult(job *AsyncBatchJob) map[string]interface{} {
 // It reads the request body, determines whether the bundle should be processed
 // asynchronously, and either submits it for background processing (returning
 // 202 Accepted with Content-Location) or indicates the bundle should be
-// processed synchronously.
+// processed synchronously. A nil config uses the processor's configuration.
+//
+// When the processor's store is an AsyncJobQueue, the job is left to an
+// AsyncJobRunner, which may run it on any replica; otherwise it runs in a
+// goroutine of this process.
 func AsyncBatchHandler(processor *AsyncBatchProcessor, config *AsyncBatchConfig) echo.HandlerFunc {
+	if config == nil {
+		config = &processor.config
+	}
 	return func(c echo.Context) error {
 		body, err := io.ReadAll(c.Request().Body)
 		if err != nil {
@@ -636,7 +879,7 @@ func AsyncBatchHandler(processor *AsyncBatchProcessor, config *AsyncBatchConfig)
 
 		// Submit for async processing.
 		requestURI := c.Request().Method + " " + c.Request().RequestURI
-		jobID, err := processor.SubmitAsyncBatch(bundle, requestURI)
+		jobID, err := processor.SubmitAsyncBatchContext(c.Request().Context(), bundle, requestURI)
 		if err != nil {
 			return c.JSON(http.StatusBadRequest, NewOperationOutcome(
 				IssueSeverityError, IssueTypeProcessing, err.Error(),
@@ -644,23 +887,25 @@ func AsyncBatchHandler(processor *AsyncBatchProcessor, config *AsyncBatchConfig)
 		}
 
 		// Start background processing.
-		go func() {
-			switch bundle.Type {
-			case "batch":
-				processor.ProcessBatchAsync(jobID, bundle)
-			case "transaction":
-				processor.ProcessTransactionAsync(jobID, bundle)
-			}
-		}()
+		if _, queued := processor.store.(AsyncJobQueue); !queued {
+			go func() {
+				switch bundle.Type {
+				case "batch":
+					processor.ProcessBatchAsync(jobID, bundle)
+				case "transaction":
+					processor.ProcessTransactionAsync(jobID, bundle)
+				}
+			}()
+		}
 
 		// Return 202 Accepted with Content-Location for polling.
-		c.Response().Header().Set("Content-Location", fmt.Sprintf("/fhir/_async-batch/%s", jobID))
+		c.Response().Header().Set("Content-Location", fmt.Sprintf("/fhir/$async-batch-status/%s", jobID))
 		return c.NoContent(http.StatusAccepted)
 	}
 }
 
 // AsyncBatchStatusHandler returns a handler for polling job status.
-// GET /fhir/_async-batch/:jobId
+// GET /fhir/$async-batch-status/:jobId
 //
 // Behaviour by job status:
 //   - "queued"/"processing": 202 Accepted with X-Progress header.
@@ -722,7 +967,7 @@ func AsyncBatchStatusHandler(store AsyncBatchStore) echo.HandlerFunc {
 }
 
 // AsyncBatchCancelHandler returns a handler for cancelling async jobs.
-// DELETE /fhir/_async-batch/:jobId
+// DELETE /fhir/$async-batch/:jobId
 func AsyncBatchCancelHandler(processor *AsyncBatchProcessor) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		jobID := c.Param("jobId")
@@ -732,7 +977,7 @@ func AsyncBatchCancelHandler(processor *AsyncBatchProcessor) echo.HandlerFunc {
 			))
 		}
 
-		err := processor.CancelJob(jobID)
+		err := processor.CancelJobContext(c.Request().Context(), jobID)
 		if err != nil {
 			if strings.Contains(err.Error(), "not found") {
 				return c.JSON(http.StatusNotFound, NewOperationOutcome(
@@ -1810,3 +1810,276 @@ func TestAsyncBatchListHandler_WithLimit(t *testing.T) {
 		t.Errorf("expected 2 jobs with limit, got %d", len(jobs))
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Durable jobs
+// ---------------------------------------------------------------------------
+
+// entryRecordingStore is an InMemoryAsyncBatchStore that also records
+// per-entry results, like the PostgreSQL store.
+type entryRecordingStore struct {
+	*InMemoryAsyncBatchStore
+	mu      sync.Mutex
+	results map[string][]AsyncBatchEntryResult
+}
+
+func newEntryRecordingStore() *entryRecordingStore {
+	return &entryRecordingStore{
+		InMemoryAsyncBatchStore: NewInMemoryAsyncBatchStore(),
+		results:                 make(map[string][]AsyncBatchEntryResult),
+	}
+}
+
+func (s *entryRecordingStore) SaveEntryResult(_ context.Context, jobID string, result AsyncBatchEntryResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[jobID] = append(s.results[jobID], result)
+	return nil
+}
+
+func (s *entryRecordingStore) EntryResults(_ context.Context, jobID string) ([]AsyncBatchEntryResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]AsyncBatchEntryResult(nil), s.results[jobID]...), nil
+}
+
+func TestProcessBatchAsync_ResumesFromRecordedEntries(t *testing.T) {
+	store := newEntryRecordingStore()
+	cfg := DefaultAsyncBatchConfig()
+	cfg.RetryMaxAttempts = 1
+	var calls int32
+	handler := func(method, url string, resource map[string]interface{}) (*BundleEntryResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		return &BundleEntryResponse{Status: "200 OK", Location: url}, nil
+	}
+	proc := NewAsyncBatchProcessor(store, cfg, handler)
+
+	bundle := &TransactionBundle{
+		ResourceType: "Bundle",
+		Type:         "batch",
+		Entries: []TransactionEntry{
+			{Request: BundleEntryRequest{Method: "GET", URL: "Patient/1"}},
+			{Request: BundleEntryRequest{Method: "GET", URL: "Patient/2"}},
+			{Request: BundleEntryRequest{Method: "GET", URL: "Patient/3"}},
+		},
+	}
+	jobID, _ := proc.SubmitAsyncBatch(bundle, "POST /fhir")
+
+	// An earlier attempt ran the first entry before its lease lapsed.
+	recorded := map[string]interface{}{"response": map[string]interface{}{"status": "200 OK", "etag": "W/\"1\""}}
+	_ = store.SaveEntryResult(context.Background(), jobID, AsyncBatchEntryResult{Index: 0, Response: recorded})
+
+	payload, _ := json.Marshal(bundle)
+	if err := proc.RunJob(context.Background(), jobID, payload); err != nil {
+		t.Fatalf("RunJob failed: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 entries to run, got %d", calls)
+	}
+	job, _ := store.GetJob(context.Background(), jobID)
+	if job.Status != "completed" || job.SuccessCount != 3 {
+		t.Fatalf("expected completed with 3 successes, got %q with %d", job.Status, job.SuccessCount)
+	}
+	entries := job.ResultBundle["entry"].([]interface{})
+	first := entries[0].(map[string]interface{})["response"].(map[string]interface{})
+	if first["etag"] != "W/\"1\"" {
+		t.Errorf("expected the recorded response for entry 0, got %v", first)
+	}
+}
+
+func TestCancelJob_StopsRunningBatch(t *testing.T) {
+	store := NewInMemoryAsyncBatchStore()
+	cfg := DefaultAsyncBatchConfig()
+	cfg.WorkerCount = 1
+	cfg.RetryMaxAttempts = 1
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls int32
+	handler := func(method, url string, resource map[string]interface{}) (*BundleEntryResponse, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-release
+		}
+		return &BundleEntryResponse{Status: "200 OK"}, nil
+	}
+	proc := NewAsyncBatchProcessor(store, cfg, handler)
+
+	bundle := &TransactionBundle{
+		Type: "batch",
+		Entries: []TransactionEntry{
+			{Request: BundleEntryRequest{Method: "GET", URL: "Patient/1"}},
+			{Request: BundleEntryRequest{Method: "GET", URL: "Patient/2"}},
+			{Request: BundleEntryRequest{Method: "GET", URL: "Patient/3"}},
+		},
+	}
+	jobID, _ := proc.SubmitAsyncBatch(bundle, "POST /fhir")
+
+	done := make(chan struct{})
+	go func() {
+		proc.ProcessBatchAsync(jobID, bundle)
+		close(done)
+	}()
+	<-started
+	if err := proc.CancelJob(jobID); err != nil {
+		t.Fatalf("CancelJob failed: %v", err)
+	}
+	close(release)
+	<-done
+
+	if calls != 1 {
+		t.Errorf("expected processing to stop after the running entry, got %d calls", calls)
+	}
+	job, _ := store.GetJob(context.Background(), jobID)
+	if job.Status != "cancelled" {
+		t.Errorf("expected status 'cancelled', got %q", job.Status)
+	}
+	if job.ResultBundle != nil {
+		t.Error("expected no result bundle for a cancelled job")
+	}
+}
+
+func TestRunJob_BatchThroughTransactionProcessor(t *testing.T) {
+	e, _ := newDispatchTestServer()
+	store := NewInMemoryAsyncBatchStore()
+	proc := NewAsyncBatchProcessor(store, DefaultAsyncBatchConfig(), nil)
+	proc.SetTransactionProcessor(newTestDispatchProcessor(e, &fakeTx{}))
+
+	payload := []byte(`{
+		"resourceType": "Bundle", "type": "batch",
+		"entry": [
+			{"request": {"method": "GET", "url": "Patient/nope"}},
+			{"resource": {"resourceType": "Patient"}, "request": {"method": "POST", "url": "Patient"}}
+		]}`)
+	bundle, _ := ParseTransactionBundle(payload)
+	jobID, _ := proc.SubmitAsyncBatch(bundle, "POST /fhir/$async-batch")
+
+	if err := proc.RunJob(context.Background(), jobID, payload); err != nil {
+		t.Fatalf("RunJob failed: %v", err)
+	}
+
+	job, _ := store.GetJob(context.Background(), jobID)
+	if job.Status != "completed" || job.SuccessCount != 1 || job.ErrorCount != 1 {
+		t.Fatalf("expected completed with 1 success and 1 error, got %q, %d, %d", job.Status, job.SuccessCount, job.ErrorCount)
+	}
+	if job.Errors[0].EntryIndex != 0 || job.Errors[0].StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 for entry 0, got %+v", job.Errors[0])
+	}
+	entries := job.ResultBundle["entry"].([]interface{})
+	created := entries[1].(map[string]interface{})["response"].(map[string]interface{})
+	if created["status"] != "201 Created" {
+		t.Errorf("expected 201 Created for entry 1, got %v", created["status"])
+	}
+}
+
+func TestRunJob_TransactionThroughTransactionProcessor(t *testing.T) {
+	e, store := newDispatchTestServer()
+	jobs := NewInMemoryAsyncBatchStore()
+	tx := &fakeTx{}
+	proc := NewAsyncBatchProcessor(jobs, DefaultAsyncBatchConfig(), nil)
+	proc.SetTransactionProcessor(newTestDispatchProcessor(e, tx))
+
+	payload := []byte(`{
+		"resourceType": "Bundle", "type": "transaction",
+		"entry": [
+			{"fullUrl": "urn:uuid:p", "resource": {"resourceType": "Patient"}, "request": {"method": "POST", "url": "Patient"}},
+			{"resource": {"resourceType": "Observation", "subject": {"reference": "urn:uuid:p"}}, "request": {"method": "POST", "url": "Observation"}}
+		]}`)
+	bundle, _ := ParseTransactionBundle(payload)
+	jobID, _ := proc.SubmitAsyncBatch(bundle, "POST /fhir/$async-batch")
+
+	if err := proc.RunJob(context.Background(), jobID, payload); err != nil {
+		t.Fatalf("RunJob failed: %v", err)
+	}
+
+	job, _ := jobs.GetJob(context.Background(), jobID)
+	if job.Status != "completed" {
+		t.Fatalf("expected status 'completed', got %q (%+v)", job.Status, job.Errors)
+	}
+	if job.ResultBundle["type"] != "transaction-response" {
+		t.Errorf("expected a transaction-response, got %v", job.ResultBundle["type"])
+	}
+	if !tx.committed {
+		t.Error("expected commit")
+	}
+	if len(store) != 2 {
+		t.Errorf("expected 2 stored resources, got %d", len(store))
+	}
+}
+
+func TestRunJob_FailedTransactionRollsBack(t *testing.T) {
+	e, _ := newDispatchTestServer()
+	jobs := NewInMemoryAsyncBatchStore()
+	tx := &fakeTx{}
+	proc := NewAsyncBatchProcessor(jobs, DefaultAsyncBatchConfig(), nil)
+	proc.SetTransactionProcessor(newTestDispatchProcessor(e, tx))
+
+	payload := []byte(`{
+		"resourceType": "Bundle", "type": "transaction",
+		"entry": [
+			{"resource": {"resourceType": "Patient"}, "request": {"method": "POST", "url": "Patient"}},
+			{"request": {"method": "GET", "url": "Patient/missing"}}
+		]}`)
+	bundle, _ := ParseTransactionBundle(payload)
+	jobID, _ := proc.SubmitAsyncBatch(bundle, "POST /fhir/$async-batch")
+
+	if err := proc.RunJob(context.Background(), jobID, payload); err != nil {
+		t.Fatalf("RunJob failed: %v", err)
+	}
+
+	job, _ := jobs.GetJob(context.Background(), jobID)
+	if job.Status != "failed" {
+		t.Fatalf("expected status 'failed', got %q", job.Status)
+	}
+	if len(job.Errors) != 1 || job.Errors[0].URL != "Patient/missing" || job.Errors[0].StatusCode != http.StatusNotFound {
+		t.Errorf("expected the failing entry to be reported, got %+v", job.Errors)
+	}
+	if !tx.rolledBack {
+		t.Error("expected rollback")
+	}
+}
+
+// queuedBatchStore is an AsyncBatchStore whose jobs are run by an
+// AsyncJobRunner.
+type queuedBatchStore struct {
+	*InMemoryAsyncBatchStore
+	*fakeAsyncJobQueue
+}
+
+func TestAsyncBatchHandler_QueuedStoreLeavesJobToRunner(t *testing.T) {
+	store := queuedBatchStore{NewInMemoryAsyncBatchStore(), newFakeAsyncJobQueue()}
+	var calls int32
+	handler := func(method, url string, resource map[string]interface{}) (*BundleEntryResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		return &BundleEntryResponse{Status: "200 OK"}, nil
+	}
+	proc := NewAsyncBatchProcessor(store, DefaultAsyncBatchConfig(), handler)
+
+	e := echo.New()
+	e.POST("/fhir/$async-batch", AsyncBatchHandler(proc, nil))
+	body := `{"resourceType": "Bundle", "type": "batch",
+		"entry": [{"request": {"method": "GET", "url": "Patient/1"}}]}`
+	req := httptest.NewRequest(http.MethodPost, "/fhir/$async-batch", strings.NewReader(body))
+	req.Header.Set("Prefer", "respond-async")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+	loc := rec.Header().Get("Content-Location")
+	if !strings.HasPrefix(loc, "/fhir/$async-batch-status/") {
+		t.Fatalf("expected a status URL, got %q", loc)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if calls != 0 {
+		t.Errorf("expected the job to be left to the runner, got %d calls", calls)
+	}
+	job, err := store.GetJob(context.Background(), strings.TrimPrefix(loc, "/fhir/$async-batch-status/"))
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if job.Status != "queued" || job.Bundle == nil {
+		t.Errorf("expected a queued job carrying its bundle, got %q", job.Status)
+	}
+}
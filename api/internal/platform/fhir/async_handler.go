@@ -20,13 +20,26 @@ type AsyncJob struct {
 	Request       string           `json:"request"`
 	TransactionTS time.Time        `json:"transactionTime"`
 	Output        []AsyncJobOutput `json:"output,omitempty"`
+	ErrorOutput   []AsyncJobOutput `json:"errorOutput,omitempty"` // Files of OperationOutcomes for failed items
 	Error         string           `json:"error,omitempty"`
+
+	// Processed and Failed count the items a running job has processed and
+	// failed so far, reported in the X-Progress header of its status.
+	Processed int `json:"-"`
+	Failed    int `json:"-"`
+
+	// Kind and Payload describe the work of a job run by an AsyncJobRunner,
+	// such as an $import request. Stores that are not an AsyncJobQueue
+	// ignore them.
+	Kind    string          `json:"-"`
+	Payload json.RawMessage `json:"-"`
 }
 
 // AsyncJobOutput describes one output file produced by a completed async job.
 type AsyncJobOutput struct {
-	Type string `json:"type"`
-	URL  string `json:"url"`
+	Type  string `json:"type"`
+	URL   string `json:"url,omitempty"`
+	Count int    `json:"count,omitempty"` // Resources in the file, or processed by the job
 }
 
 // Async job status constants.
@@ -119,8 +132,9 @@ func (s *InMemoryAsyncJobStore) Delete(_ context.Context, jobID string) error {
 // GET /_async/:jobId and reports the current status of an async job.
 //
 // Behaviour by job status:
-//   - "in-progress": 202 Accepted with an X-Progress header.
-//   - "completed":   200 OK with the job output as JSON.
+//   - "in-progress": 202 Accepted with an X-Progress header, counting the
+//     items processed when the job reports them.
+//   - "completed":   200 OK with the job output, and error files, as JSON.
 //   - "error":       500 Internal Server Error with an OperationOutcome.
 //   - not found:     404 Not Found with an OperationOutcome.
 func AsyncStatusHandler(store AsyncJobStore) echo.HandlerFunc {
@@ -141,7 +155,11 @@ func AsyncStatusHandler(store AsyncJobStore) echo.HandlerFunc {
 
 		switch job.Status {
 		case AsyncStatusInProgress:
-			c.Response().Header().Set("X-Progress", "in-progress")
+			progress := "in-progress"
+			if job.Processed > 0 || job.Failed > 0 {
+				progress = fmt.Sprintf("%d processed, %d failed", job.Processed, job.Failed)
+			}
+			c.Response().Header().Set("X-Progress", progress)
 			return c.NoContent(http.StatusAccepted)
 
 		case AsyncStatusCompleted:
@@ -150,6 +168,9 @@ func AsyncStatusHandler(store AsyncJobStore) echo.HandlerFunc {
 				"request":         job.Request,
 				"output":          job.Output,
 			}
+			if len(job.ErrorOutput) > 0 {
+				body["error"] = job.ErrorOutput
+			}
 			return c.JSON(http.StatusOK, body)
 
 		case AsyncStatusError:
@@ -147,6 +147,44 @@ func TestAsyncStatusHandler_InProgress(t *testing.T) {
 	}
 }
 
+func TestAsyncStatusHandler_InProgressCounts(t *testing.T) {
+	store := NewInMemoryAsyncJobStore()
+	job := &AsyncJob{Request: "POST /fhir/$import", Status: AsyncStatusInProgress, Processed: 1200, Failed: 3}
+	_ = store.Create(context.Background(), job)
+
+	c, rec := newAsyncContext(http.MethodGet, "/_async/"+job.ID, job.ID)
+	if err := AsyncStatusHandler(store)(c); err != nil {
+		t.Fatal(err)
+	}
+	if progress := rec.Header().Get("X-Progress"); progress != "1200 processed, 3 failed" {
+		t.Errorf("unexpected X-Progress header %q", progress)
+	}
+}
+
+func TestAsyncStatusHandler_CompletedWithErrors(t *testing.T) {
+	store := NewInMemoryAsyncJobStore()
+	job := &AsyncJob{
+		Request:     "POST /fhir/$import",
+		Status:      AsyncStatusCompleted,
+		Output:      []AsyncJobOutput{{Type: "Patient", URL: "blob://in", Count: 10}},
+		ErrorOutput: []AsyncJobOutput{{Type: "OperationOutcome", URL: "/api/v1/blobs/err", Count: 2}},
+	}
+	_ = store.Create(context.Background(), job)
+
+	c, rec := newAsyncContext(http.MethodGet, "/_async/"+job.ID, job.ID)
+	if err := AsyncStatusHandler(store)(c); err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse body: %v", err)
+	}
+	errs, ok := body["error"].([]interface{})
+	if !ok || len(errs) != 1 || errs[0].(map[string]interface{})["url"] != "/api/v1/blobs/err" {
+		t.Errorf("expected the error file, got %v", body["error"])
+	}
+}
+
 func TestAsyncStatusHandler_Completed(t *testing.T) {
 	store := NewInMemoryAsyncJobStore()
 	ctx := context.Background()
@@ -0,0 +1,623 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ehr/ehr/internal/platform/auth"
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// DefaultAsyncJobRetention is how long finished jobs and their results are
+// kept for polling.
+const DefaultAsyncJobRetention = 24 * time.Hour
+
+// asyncJobSweepInterval is how often PGAsyncJobStore deletes expired jobs.
+const asyncJobSweepInterval = 10 * time.Minute
+
+// asyncBatchKinds are the job kinds stored for $async-batch bundles; the kind
+// is the bundle type. Every other kind is an AsyncJob.
+var asyncBatchKinds = []string{"batch", "transaction"}
+
+// asyncJobRequestKind is the kind of AsyncJobs created without one, such as
+// Prefer: respond-async requests.
+const asyncJobRequestKind = "request"
+
+// PGAsyncJobStore is a PostgreSQL-backed store for $async-batch jobs
+// (AsyncBatchStore) and for $import and Prefer: respond-async jobs
+// (AsyncJobStore), shared by all replicas. Jobs live in public.async_jobs
+// with the tenant and identity of the client that submitted them. Jobs that
+// carry a payload are queued for an AsyncJobRunner (see AsyncJobQueue), and
+// batches record each processed entry (see AsyncBatchEntryStore) so that a
+// resumed batch does not run an entry twice. Finished jobs keep their result
+// for the retention period; a background goroutine deletes them afterwards.
+//
+// Reads and cancellation are scoped to the tenant in the context, when there
+// is one.
+type PGAsyncJobStore struct {
+	db        historyQuerier
+	retention time.Duration
+	wake      chan struct{}
+	stop      chan struct{}
+}
+
+// NewPGAsyncJobStore creates a PGAsyncJobStore that keeps finished jobs for
+// retention and starts its expiry sweeper. If retention is zero or negative,
+// DefaultAsyncJobRetention is used.
+func NewPGAsyncJobStore(pool *pgxpool.Pool, retention time.Duration) *PGAsyncJobStore {
+	s := newPGAsyncJobStore(pool, retention)
+	go s.sweepLoop()
+	return s
+}
+
+func newPGAsyncJobStore(db historyQuerier, retention time.Duration) *PGAsyncJobStore {
+	if retention <= 0 {
+		retention = DefaultAsyncJobRetention
+	}
+	return &PGAsyncJobStore{
+		db:        db,
+		retention: retention,
+		wake:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+	}
+}
+
+// sweepLoop periodically deletes expired jobs.
+func (s *PGAsyncJobStore) sweepLoop() {
+	ticker := time.NewTicker(asyncJobSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = s.Sweep(context.Background())
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Stop terminates the background sweeper.
+func (s *PGAsyncJobStore) Stop() {
+	close(s.stop)
+}
+
+// Sweep deletes the jobs whose retention period has ended, along with their
+// entries.
+func (s *PGAsyncJobStore) Sweep(ctx context.Context) error {
+	if _, err := s.db.Exec(ctx, `DELETE FROM public.async_jobs WHERE expires_at <= NOW()`); err != nil {
+		return fmt.Errorf("sweep async jobs: %w", err)
+	}
+	return nil
+}
+
+// Wakeup implements AsyncJobQueue.
+func (s *PGAsyncJobStore) Wakeup() <-chan struct{} {
+	return s.wake
+}
+
+// notify wakes the local runner after a job was queued.
+func (s *PGAsyncJobStore) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// finish returns the completion and expiry times of a job in status, or nils
+// while the job is still running.
+func (s *PGAsyncJobStore) finish(status string, end *time.Time) (*time.Time, *time.Time) {
+	switch status {
+	case "completed", "failed", "cancelled", AsyncStatusError:
+	default:
+		return nil, nil
+	}
+	completed := time.Now().UTC()
+	if end != nil {
+		completed = *end
+	}
+	expires := completed.Add(s.retention)
+	return &completed, &expires
+}
+
+// newAsyncJobID returns a new job identifier.
+func newAsyncJobID() string {
+	return uuid.New().String()
+}
+
+// asyncContext returns the context.Context passed as an AsyncBatchStore ctx.
+func asyncContext(ctx interface{}) context.Context {
+	if c, ok := ctx.(context.Context); ok && c != nil {
+		return c
+	}
+	return context.Background()
+}
+
+// tenantScope returns a condition restricting jobs to the tenant in ctx,
+// using argument idx, or an always-true condition when there is none.
+func tenantScope(ctx context.Context, idx int) (string, []interface{}) {
+	tenant := db.TenantFromContext(ctx)
+	if tenant == "" {
+		return "TRUE", nil
+	}
+	return fmt.Sprintf("tenant_id = $%d", idx), []interface{}{tenant}
+}
+
+// insert adds a job with the identity of the client in ctx.
+func (s *PGAsyncJobStore) insert(ctx context.Context, id, kind, status, request, resourceType string,
+	payload []byte, total int, createdAt time.Time) error {
+	roles := auth.RolesFromContext(ctx)
+	if roles == nil {
+		roles = []string{}
+	}
+	scopes := auth.ScopesFromContext(ctx)
+	if scopes == nil {
+		scopes = []string{}
+	}
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO public.async_jobs
+			(id, kind, status, tenant_id, user_id, roles, scopes, request, resource_type,
+			 payload, total_entries, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		id, kind, status, db.TenantFromContext(ctx), auth.UserIDFromContext(ctx), roles, scopes,
+		request, resourceType, payload, total, createdAt)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		s.notify()
+	}
+	return nil
+}
+
+// =========== AsyncBatchStore ===========
+
+const asyncBatchColumns = `id, status, kind, total_entries, processed_entries, success_count,
+	error_count, COALESCE(started_at, created_at), completed_at, result, errors, request, created_at`
+
+func scanAsyncBatchJob(row pgx.Row) (*AsyncBatchJob, error) {
+	var job AsyncBatchJob
+	var result, errs []byte
+	if err := row.Scan(&job.ID, &job.Status, &job.BundleType, &job.TotalEntries, &job.ProcessedEntries,
+		&job.SuccessCount, &job.ErrorCount, &job.StartTime, &job.EndTime, &result, &errs,
+		&job.Request, &job.CreatedAt); err != nil {
+		return nil, err
+	}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &job.ResultBundle); err != nil {
+			return nil, fmt.Errorf("decode result bundle: %w", err)
+		}
+	}
+	if len(errs) > 0 {
+		if err := json.Unmarshal(errs, &job.Errors); err != nil {
+			return nil, fmt.Errorf("decode errors: %w", err)
+		}
+	}
+	switch {
+	case job.Status == "completed":
+		job.Progress = 1.0
+	case job.TotalEntries > 0:
+		job.Progress = float64(job.ProcessedEntries) / float64(job.TotalEntries)
+	}
+	return &job, nil
+}
+
+// CreateJob implements AsyncBatchStore. The job's bundle is stored as its
+// payload, which queues the job for an AsyncJobRunner.
+func (s *PGAsyncJobStore) CreateJob(ctx interface{}, job *AsyncBatchJob) error {
+	if job.ID == "" {
+		job.ID = newAsyncJobID()
+	}
+	if job.Status == "" {
+		job.Status = "queued"
+	}
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now().UTC()
+	}
+	if job.StartTime.IsZero() {
+		job.StartTime = job.CreatedAt
+	}
+	var payload []byte
+	if job.Bundle != nil {
+		var err error
+		if payload, err = json.Marshal(job.Bundle); err != nil {
+			return fmt.Errorf("create async batch job: marshal bundle: %w", err)
+		}
+	}
+	if err := s.insert(asyncContext(ctx), job.ID, job.BundleType, job.Status, job.Request, "",
+		payload, job.TotalEntries, job.CreatedAt); err != nil {
+		return fmt.Errorf("create async batch job: %w", err)
+	}
+	return nil
+}
+
+// GetJob implements AsyncBatchStore.
+func (s *PGAsyncJobStore) GetJob(ctx interface{}, jobID string) (*AsyncBatchJob, error) {
+	c := asyncContext(ctx)
+	scope, args := tenantScope(c, 3)
+	row := s.db.QueryRow(c, `SELECT `+asyncBatchColumns+`
+		FROM public.async_jobs
+		WHERE id = $1 AND kind = ANY($2) AND (expires_at IS NULL OR expires_at > NOW()) AND `+scope,
+		append([]interface{}{jobID, asyncBatchKinds}, args...)...)
+	job, err := scanAsyncBatchJob(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("async batch job %s not found", jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get async batch job: %w", err)
+	}
+	return job, nil
+}
+
+// UpdateJob implements AsyncBatchStore. A cancelled job is left unchanged,
+// so that a worker that has not yet noticed the cancellation cannot revive
+// it. The processed entry count never decreases.
+func (s *PGAsyncJobStore) UpdateJob(ctx interface{}, job *AsyncBatchJob) error {
+	c := asyncContext(ctx)
+	var result, errs []byte
+	var err error
+	if job.ResultBundle != nil {
+		if result, err = json.Marshal(job.ResultBundle); err != nil {
+			return fmt.Errorf("update async batch job: marshal result bundle: %w", err)
+		}
+	}
+	if job.Errors != nil {
+		if errs, err = json.Marshal(job.Errors); err != nil {
+			return fmt.Errorf("update async batch job: marshal errors: %w", err)
+		}
+	}
+	completed, expires := s.finish(job.Status, job.EndTime)
+	tag, err := s.db.Exec(c, `
+		UPDATE public.async_jobs SET
+			status = $3,
+			total_entries = $4,
+			processed_entries = GREATEST(processed_entries, $5),
+			success_count = $6,
+			error_count = $7,
+			started_at = $8,
+			result = $9,
+			errors = $10,
+			completed_at = $11,
+			expires_at = $12
+		WHERE id = $1 AND kind = ANY($2) AND status <> 'cancelled'`,
+		job.ID, asyncBatchKinds, job.Status, job.TotalEntries, job.ProcessedEntries, job.SuccessCount,
+		job.ErrorCount, job.StartTime, result, errs, completed, expires)
+	if err != nil {
+		return fmt.Errorf("update async batch job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		// Either the job does not exist or it was cancelled.
+		if _, err := s.GetJob(c, job.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CancelJob implements AsyncBatchStore. The worker running the job notices
+// the cancellation at its next heartbeat.
+func (s *PGAsyncJobStore) CancelJob(ctx interface{}, jobID string) error {
+	c := asyncContext(ctx)
+	scope, args := tenantScope(c, 4)
+	_, expires := s.finish("cancelled", nil)
+	tag, err := s.db.Exec(c, `
+		UPDATE public.async_jobs SET status = 'cancelled', completed_at = NOW(), expires_at = $3
+		WHERE id = $1 AND kind = ANY($2) AND (expires_at IS NULL OR expires_at > NOW()) AND `+scope,
+		append([]interface{}{jobID, asyncBatchKinds, expires}, args...)...)
+	if err != nil {
+		return fmt.Errorf("cancel async batch job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("async batch job %s not found", jobID)
+	}
+	return nil
+}
+
+// ListJobs implements AsyncBatchStore, newest first.
+func (s *PGAsyncJobStore) ListJobs(ctx interface{}, status string, limit int) ([]*AsyncBatchJob, error) {
+	c := asyncContext(ctx)
+	scope, args := tenantScope(c, 4)
+	rows, err := s.db.Query(c, `SELECT `+asyncBatchColumns+`
+		FROM public.async_jobs
+		WHERE kind = ANY($1) AND ($2 = '' OR status = $2) AND (expires_at IS NULL OR expires_at > NOW())
+			AND `+scope+`
+		ORDER BY created_at DESC
+		LIMIT NULLIF($3, 0)`,
+		append([]interface{}{asyncBatchKinds, status, limit}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("list async batch jobs: %w", err)
+	}
+	defer rows.Close()
+	jobs := []*AsyncBatchJob{}
+	for rows.Next() {
+		job, err := scanAsyncBatchJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("list async batch jobs: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// DeleteJob implements AsyncBatchStore.
+func (s *PGAsyncJobStore) DeleteJob(ctx interface{}, jobID string) error {
+	c := asyncContext(ctx)
+	scope, args := tenantScope(c, 3)
+	tag, err := s.db.Exec(c, `DELETE FROM public.async_jobs WHERE id = $1 AND kind = ANY($2) AND `+scope,
+		append([]interface{}{jobID, asyncBatchKinds}, args...)...)
+	if err != nil {
+		return fmt.Errorf("delete async batch job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("async batch job %s not found", jobID)
+	}
+	return nil
+}
+
+// =========== AsyncBatchEntryStore ===========
+
+// SaveEntryResult implements AsyncBatchEntryStore. The job's counts are
+// updated in the same statement, and only the first result of an entry
+// counts.
+func (s *PGAsyncJobStore) SaveEntryResult(ctx context.Context, jobID string, result AsyncBatchEntryResult) error {
+	response, err := json.Marshal(result.Response)
+	if err != nil {
+		return fmt.Errorf("save async batch entry: marshal response: %w", err)
+	}
+	var entryErr interface{}
+	if result.Error != nil {
+		b, err := json.Marshal(result.Error)
+		if err != nil {
+			return fmt.Errorf("save async batch entry: marshal error: %w", err)
+		}
+		entryErr = b
+	}
+	_, err = s.db.Exec(ctx, `
+		WITH ins AS (
+			INSERT INTO public.async_job_entries (job_id, entry_index, response, error)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (job_id, entry_index) DO NOTHING
+			RETURNING error IS NULL AS ok
+		)
+		UPDATE public.async_jobs SET
+			processed_entries = processed_entries + 1,
+			success_count = success_count + (SELECT COUNT(*) FROM ins WHERE ok),
+			error_count = error_count + (SELECT COUNT(*) FROM ins WHERE NOT ok)
+		WHERE id = $1 AND EXISTS (SELECT 1 FROM ins)`,
+		jobID, result.Index, response, entryErr)
+	if err != nil {
+		return fmt.Errorf("save async batch entry: %w", err)
+	}
+	return nil
+}
+
+// EntryResults implements AsyncBatchEntryStore.
+func (s *PGAsyncJobStore) EntryResults(ctx context.Context, jobID string) ([]AsyncBatchEntryResult, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT entry_index, response, error FROM public.async_job_entries
+		WHERE job_id = $1 ORDER BY entry_index`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("list async batch entries: %w", err)
+	}
+	defer rows.Close()
+	var results []AsyncBatchEntryResult
+	for rows.Next() {
+		var r AsyncBatchEntryResult
+		var response, entryErr []byte
+		if err := rows.Scan(&r.Index, &response, &entryErr); err != nil {
+			return nil, fmt.Errorf("list async batch entries: %w", err)
+		}
+		if err := json.Unmarshal(response, &r.Response); err != nil {
+			return nil, fmt.Errorf("decode async batch entry %d: %w", r.Index, err)
+		}
+		if len(entryErr) > 0 {
+			r.Error = &AsyncBatchError{}
+			if err := json.Unmarshal(entryErr, r.Error); err != nil {
+				return nil, fmt.Errorf("decode async batch entry %d: %w", r.Index, err)
+			}
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// =========== AsyncJobStore ===========
+
+// Create implements AsyncJobStore. A job with a payload is queued for an
+// AsyncJobRunner.
+func (s *PGAsyncJobStore) Create(ctx context.Context, job *AsyncJob) error {
+	if job.ID == "" {
+		job.ID = newAsyncJobID()
+	}
+	if job.TransactionTS.IsZero() {
+		job.TransactionTS = time.Now().UTC()
+	}
+	if job.Status == "" {
+		job.Status = AsyncStatusInProgress
+	}
+	kind := job.Kind
+	if kind == "" {
+		kind = asyncJobRequestKind
+	}
+	if err := s.insert(ctx, job.ID, kind, job.Status, job.Request, job.ResourceType,
+		job.Payload, 0, job.TransactionTS); err != nil {
+		return fmt.Errorf("create async job: %w", err)
+	}
+	return nil
+}
+
+// Get implements AsyncJobStore. The job's payload is returned too, so that
+// the state of a job can be restored from it.
+func (s *PGAsyncJobStore) Get(ctx context.Context, jobID string) (*AsyncJob, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	scope, args := tenantScope(ctx, 3)
+	var job AsyncJob
+	var output, errorOutput []byte
+	err := s.db.QueryRow(ctx, `
+		SELECT id, kind, status, resource_type, request, created_at, result, errors, error,
+			processed_entries, error_count, payload
+		FROM public.async_jobs
+		WHERE id = $1 AND NOT kind = ANY($2) AND (expires_at IS NULL OR expires_at > NOW()) AND `+scope,
+		append([]interface{}{jobID, asyncBatchKinds}, args...)...,
+	).Scan(&job.ID, &job.Kind, &job.Status, &job.ResourceType, &job.Request, &job.TransactionTS, &output, &errorOutput,
+		&job.Error, &job.Processed, &job.Failed, &job.Payload)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("async job %s not found", jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get async job: %w", err)
+	}
+	if len(output) > 0 {
+		if err := json.Unmarshal(output, &job.Output); err != nil {
+			return nil, fmt.Errorf("decode async job output: %w", err)
+		}
+	}
+	if len(errorOutput) > 0 {
+		if err := json.Unmarshal(errorOutput, &job.ErrorOutput); err != nil {
+			return nil, fmt.Errorf("decode async job error output: %w", err)
+		}
+	}
+	return &job, nil
+}
+
+// Update implements AsyncJobStore.
+func (s *PGAsyncJobStore) Update(ctx context.Context, job *AsyncJob) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var output, errorOutput []byte
+	if job.Output != nil {
+		var err error
+		if output, err = json.Marshal(job.Output); err != nil {
+			return fmt.Errorf("update async job: marshal output: %w", err)
+		}
+	}
+	if job.ErrorOutput != nil {
+		var err error
+		if errorOutput, err = json.Marshal(job.ErrorOutput); err != nil {
+			return fmt.Errorf("update async job: marshal error output: %w", err)
+		}
+	}
+	completed, expires := s.finish(job.Status, nil)
+	tag, err := s.db.Exec(ctx, `
+		UPDATE public.async_jobs SET status = $3, result = $4, errors = $5, error = $6,
+			processed_entries = $7, error_count = $8, completed_at = $9, expires_at = $10
+		WHERE id = $1 AND NOT kind = ANY($2)`,
+		job.ID, asyncBatchKinds, job.Status, output, errorOutput, job.Error,
+		job.Processed, job.Failed, completed, expires)
+	if err != nil {
+		return fmt.Errorf("update async job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("async job %s not found", job.ID)
+	}
+	return nil
+}
+
+// Delete implements AsyncJobStore. Deleting a running job cancels it: the
+// worker running it loses its lease at the next heartbeat.
+func (s *PGAsyncJobStore) Delete(ctx context.Context, jobID string) error {
+	scope, args := tenantScope(ctx, 3)
+	_, err := s.db.Exec(ctx, `DELETE FROM public.async_jobs WHERE id = $1 AND NOT kind = ANY($2) AND `+scope,
+		append([]interface{}{jobID, asyncBatchKinds}, args...)...)
+	if err != nil {
+		return fmt.Errorf("delete async job: %w", err)
+	}
+	return nil
+}
+
+// =========== AsyncJobQueue ===========
+
+// Claim implements AsyncJobQueue. Concurrent claims skip rows locked by one
+// another, so a job is leased to one worker at a time.
+func (s *PGAsyncJobStore) Claim(ctx context.Context, owner string, kinds []string, lease time.Duration) (*QueuedAsyncJob, error) {
+	var job QueuedAsyncJob
+	err := s.db.QueryRow(ctx, `
+		UPDATE public.async_jobs SET
+			lease_owner = $1,
+			lease_expires_at = NOW() + $3 * INTERVAL '1 millisecond',
+			attempts = attempts + 1,
+			started_at = COALESCE(started_at, NOW())
+		WHERE id = (
+			SELECT id FROM public.async_jobs
+			WHERE completed_at IS NULL AND payload IS NOT NULL AND kind = ANY($2)
+				AND (lease_expires_at IS NULL OR lease_expires_at < NOW())
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, kind, payload, tenant_id, user_id, roles, scopes, attempts`,
+		owner, kinds, lease.Milliseconds(),
+	).Scan(&job.ID, &job.Kind, &job.Payload, &job.TenantID, &job.UserID, &job.Roles, &job.Scopes, &job.Attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("claim async job: %w", err)
+	}
+	return &job, nil
+}
+
+// Heartbeat implements AsyncJobQueue.
+func (s *PGAsyncJobStore) Heartbeat(ctx context.Context, jobID, owner string, lease time.Duration) (bool, error) {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE public.async_jobs SET lease_expires_at = NOW() + $3 * INTERVAL '1 millisecond'
+		WHERE id = $1 AND lease_owner = $2 AND completed_at IS NULL`,
+		jobID, owner, lease.Milliseconds())
+	if err != nil {
+		return false, fmt.Errorf("heartbeat async job: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Release implements AsyncJobQueue.
+func (s *PGAsyncJobStore) Release(ctx context.Context, jobID, owner string, retryAfter time.Duration) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE public.async_jobs SET
+			lease_owner = NULL,
+			lease_expires_at = CASE WHEN $3 > 0 THEN NOW() + $3 * INTERVAL '1 millisecond' END
+		WHERE id = $1 AND lease_owner = $2`,
+		jobID, owner, retryAfter.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("release async job: %w", err)
+	}
+	return nil
+}
+
+// Fail implements AsyncJobQueue. Batches are marked failed with the
+// diagnostics as their error; other jobs are marked as errors.
+func (s *PGAsyncJobStore) Fail(ctx context.Context, jobID, owner, diagnostics string) error {
+	errs, err := json.Marshal([]AsyncBatchError{{
+		EntryIndex:  -1,
+		StatusCode:  500,
+		Diagnostics: diagnostics,
+		Severity:    "error",
+	}})
+	if err != nil {
+		return fmt.Errorf("fail async job: %w", err)
+	}
+	_, expires := s.finish(AsyncStatusError, nil)
+	_, err = s.db.Exec(ctx, `
+		UPDATE public.async_jobs SET
+			status = CASE WHEN kind = ANY($3) THEN 'failed' ELSE 'error' END,
+			errors = CASE WHEN kind = ANY($3) THEN $4::jsonb ELSE errors END,
+			error = $5,
+			completed_at = NOW(),
+			expires_at = $6,
+			lease_owner = NULL,
+			lease_expires_at = NULL
+		WHERE id = $1 AND lease_owner = $2`,
+		jobID, owner, asyncBatchKinds, errs, diagnostics, expires)
+	if err != nil {
+		return fmt.Errorf("fail async job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+
+	"github.com/ehr/ehr/internal/platform/auth"
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// QueuedAsyncJob is a job leased from an AsyncJobQueue, along with the tenant
+// and identity of the client that submitted it.
+type QueuedAsyncJob struct {
+	ID       string
+	Kind     string
+	Payload  json.RawMessage
+	TenantID string
+	UserID   string
+	Roles    []string
+	Scopes   []string
+	Attempts int // including the current one
+}
+
+// AsyncJobQueue is implemented by durable job stores whose jobs are run by an
+// AsyncJobRunner, possibly on another replica, rather than by the request
+// that submitted them. A job is owned by the worker holding its lease until
+// the lease lapses or is released.
+type AsyncJobQueue interface {
+	// Claim leases the oldest runnable job of one of kinds to owner. A job
+	// is runnable when it is unfinished, carries a payload and is not
+	// leased. It returns nil when there is none.
+	Claim(ctx context.Context, owner string, kinds []string, lease time.Duration) (*QueuedAsyncJob, error)
+	// Heartbeat extends the lease of a job. It returns false when owner no
+	// longer holds the lease or the job has finished or been cancelled.
+	Heartbeat(ctx context.Context, jobID, owner string, lease time.Duration) (bool, error)
+	// Release gives up the lease, making the job runnable again after
+	// retryAfter unless it has finished.
+	Release(ctx context.Context, jobID, owner string, retryAfter time.Duration) error
+	// Fail finishes a job that could not be run.
+	Fail(ctx context.Context, jobID, owner, diagnostics string) error
+	// Wakeup returns a channel that receives when a job is queued by this
+	// process, so that the local runner starts it without waiting to poll.
+	Wakeup() <-chan struct{}
+}
+
+// AsyncJobFunc runs a leased job. ctx carries the tenant connection and
+// identity of the client that submitted the job, and is cancelled when the
+// job is cancelled or its lease is lost. The function records the job's
+// progress and result in the store; returning an error makes the runner
+// retry the job.
+type AsyncJobFunc func(ctx context.Context, jobID string, payload json.RawMessage) error
+
+// AsyncJobRunnerConfig controls an AsyncJobRunner.
+type AsyncJobRunnerConfig struct {
+	Workers      int           // Jobs run concurrently by this process
+	Lease        time.Duration // How long a job stays leased without a heartbeat
+	PollInterval time.Duration // How often idle workers look for jobs
+	MaxAttempts  int           // Attempts before a job is failed
+	RetryDelay   time.Duration // Delay before a failed attempt is retried
+}
+
+// DefaultAsyncJobRunnerConfig returns sensible defaults.
+func DefaultAsyncJobRunnerConfig() AsyncJobRunnerConfig {
+	return AsyncJobRunnerConfig{
+		Workers:      4,
+		Lease:        30 * time.Second,
+		PollInterval: 2 * time.Second,
+		MaxAttempts:  3,
+		RetryDelay:   10 * time.Second,
+	}
+}
+
+// AsyncJobRunner leases jobs from an AsyncJobQueue and runs them with the
+// AsyncJobFunc registered for their kind. While a job runs, the runner
+// extends its lease every third of the lease period; if the runner's process
+// dies, the lease lapses and another replica resumes the job.
+type AsyncJobRunner struct {
+	queue    AsyncJobQueue
+	pool     *pgxpool.Pool
+	config   AsyncJobRunnerConfig
+	logger   zerolog.Logger
+	owner    string
+	handlers map[string]AsyncJobFunc
+	search   *SearchServices
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewAsyncJobRunner creates a runner for queue. Jobs submitted by a tenant
+// run on a connection from pool scoped to that tenant; pool may be nil when
+// jobs do not use the database.
+func NewAsyncJobRunner(queue AsyncJobQueue, pool *pgxpool.Pool, config AsyncJobRunnerConfig, logger zerolog.Logger) *AsyncJobRunner {
+	def := DefaultAsyncJobRunnerConfig()
+	if config.Workers <= 0 {
+		config.Workers = def.Workers
+	}
+	if config.Lease <= 0 {
+		config.Lease = def.Lease
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = def.PollInterval
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = def.MaxAttempts
+	}
+	host, _ := os.Hostname()
+	return &AsyncJobRunner{
+		queue:    queue,
+		pool:     pool,
+		config:   config,
+		logger:   logger,
+		owner:    fmt.Sprintf("%s/%s", host, uuid.New().String()[:8]),
+		handlers: make(map[string]AsyncJobFunc),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Handle registers the function that runs jobs of kind. Handlers must be
+// registered before Start.
+func (r *AsyncJobRunner) Handle(kind string, fn AsyncJobFunc) {
+	r.handlers[kind] = fn
+}
+
+// SetSearchServices sets the search services jobs run with, as requests
+// do (see SearchServicesMiddleware).
+func (r *AsyncJobRunner) SetSearchServices(s *SearchServices) {
+	r.search = s
+}
+
+// Start starts the runner's workers.
+func (r *AsyncJobRunner) Start() {
+	for i := 0; i < r.config.Workers; i++ {
+		r.wg.Add(1)
+		go r.work()
+	}
+}
+
+// Stop stops the workers and waits for running jobs to return. Jobs are
+// cancelled and their leases released, so that another replica resumes them.
+func (r *AsyncJobRunner) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+}
+
+// work runs jobs until the runner is stopped.
+func (r *AsyncJobRunner) work() {
+	defer r.wg.Done()
+	kinds := make([]string, 0, len(r.handlers))
+	for kind := range r.handlers {
+		kinds = append(kinds, kind)
+	}
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Drain the queue before waiting.
+		for {
+			select {
+			case <-r.stop:
+				return
+			default:
+			}
+			job, err := r.queue.Claim(context.Background(), r.owner, kinds, r.config.Lease)
+			if err != nil {
+				r.logger.Warn().Err(err).Msg("async job claim failed")
+				break
+			}
+			if job == nil {
+				break
+			}
+			r.run(job)
+		}
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		case <-r.queue.Wakeup():
+		}
+	}
+}
+
+// run runs a leased job and settles its lease.
+func (r *AsyncJobRunner) run(job *QueuedAsyncJob) {
+	ctx, cancel := context.WithCancel(context.Background())
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		r.heartbeat(ctx, cancel, job.ID)
+	}()
+
+	err := r.runInContext(ctx, job)
+	interrupted := ctx.Err() != nil
+	cancel()
+	<-heartbeatDone
+
+	settleCtx := context.Background()
+	switch {
+	case err == nil || interrupted:
+		// Finished, cancelled, lease lost or runner stopping. Releasing a
+		// finished job only drops the lease.
+		err = r.queue.Release(settleCtx, job.ID, r.owner, 0)
+	case job.Attempts >= r.config.MaxAttempts:
+		r.logger.Error().Err(err).Str("job_id", job.ID).Str("kind", job.Kind).Msg("async job failed")
+		err = r.queue.Fail(settleCtx, job.ID, r.owner, err.Error())
+	default:
+		r.logger.Warn().Err(err).Str("job_id", job.ID).Int("attempt", job.Attempts).Msg("async job attempt failed; will retry")
+		err = r.queue.Release(settleCtx, job.ID, r.owner, r.config.RetryDelay)
+	}
+	if err != nil {
+		r.logger.Warn().Err(err).Str("job_id", job.ID).Msg("async job lease release failed")
+	}
+}
+
+// runInContext runs the job's handler in the tenant and identity of the
+// client that submitted it.
+func (r *AsyncJobRunner) runInContext(ctx context.Context, job *QueuedAsyncJob) error {
+	fn, ok := r.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for async job kind %q", job.Kind)
+	}
+	ctx = context.WithValue(ctx, auth.UserIDKey, job.UserID)
+	ctx = context.WithValue(ctx, auth.UserRolesKey, job.Roles)
+	ctx = context.WithValue(ctx, auth.UserScopesKey, job.Scopes)
+	if r.search != nil {
+		ctx = WithSearchServices(ctx, r.search)
+	}
+	if job.TenantID != "" && r.pool != nil {
+		tenantCtx, conn, err := db.WithTenantConn(ctx, r.pool, job.TenantID)
+		if err != nil {
+			return err
+		}
+		defer conn.Release()
+		ctx = tenantCtx
+	}
+	return fn(ctx, job.ID, job.Payload)
+}
+
+// heartbeat extends the job's lease until ctx is done, and cancels the job
+// when the lease is lost or the job is cancelled.
+func (r *AsyncJobRunner) heartbeat(ctx context.Context, cancel context.CancelFunc, jobID string) {
+	ticker := time.NewTicker(r.config.Lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stop:
+			cancel()
+			return
+		case <-ticker.C:
+			alive, err := r.queue.Heartbeat(ctx, jobID, r.owner, r.config.Lease)
+			if err != nil {
+				// Keep running; the lease survives a missed heartbeat.
+				r.logger.Warn().Err(err).Str("job_id", jobID).Msg("async job heartbeat failed")
+				continue
+			}
+			if !alive {
+				cancel()
+				return
+			}
+		}
+	}
+}
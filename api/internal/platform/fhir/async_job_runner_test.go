@@ -0,0 +1,243 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ehr/ehr/internal/platform/auth"
+)
+
+// fakeAsyncJobQueue is an in-memory AsyncJobQueue.
+type fakeAsyncJobQueue struct {
+	mu       sync.Mutex
+	jobs     []*QueuedAsyncJob
+	leased   map[string]string // job ID -> owner
+	retryAt  map[string]time.Time
+	finished map[string]string // job ID -> failure diagnostics, "" when released done
+	alive    bool
+	wake     chan struct{}
+}
+
+func newFakeAsyncJobQueue(jobs ...*QueuedAsyncJob) *fakeAsyncJobQueue {
+	return &fakeAsyncJobQueue{
+		jobs:     jobs,
+		leased:   make(map[string]string),
+		retryAt:  make(map[string]time.Time),
+		finished: make(map[string]string),
+		alive:    true,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+func (q *fakeAsyncJobQueue) Claim(_ context.Context, owner string, kinds []string, _ time.Duration) (*QueuedAsyncJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, job := range q.jobs {
+		if _, done := q.finished[job.ID]; done {
+			continue
+		}
+		if _, leased := q.leased[job.ID]; leased || time.Now().Before(q.retryAt[job.ID]) {
+			continue
+		}
+		for _, k := range kinds {
+			if k == job.Kind {
+				job.Attempts++
+				q.leased[job.ID] = owner
+				cp := *job
+				return &cp, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (q *fakeAsyncJobQueue) Heartbeat(_ context.Context, jobID, owner string, _ time.Duration) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.alive && q.leased[jobID] == owner, nil
+}
+
+func (q *fakeAsyncJobQueue) Release(_ context.Context, jobID, owner string, retryAfter time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.leased[jobID] == owner {
+		delete(q.leased, jobID)
+		q.retryAt[jobID] = time.Now().Add(retryAfter)
+	}
+	return nil
+}
+
+func (q *fakeAsyncJobQueue) Fail(_ context.Context, jobID, owner, diagnostics string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.leased, jobID)
+	q.finished[jobID] = diagnostics
+	return nil
+}
+
+func (q *fakeAsyncJobQueue) Wakeup() <-chan struct{} { return q.wake }
+
+func (q *fakeAsyncJobQueue) markDone(jobID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.finished[jobID] = ""
+}
+
+func (q *fakeAsyncJobQueue) state(jobID string) (leased bool, diagnostics string, finished bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, leased = q.leased[jobID]
+	diagnostics, finished = q.finished[jobID]
+	return leased, diagnostics, finished
+}
+
+func testRunnerConfig() AsyncJobRunnerConfig {
+	return AsyncJobRunnerConfig{
+		Workers:      1,
+		Lease:        30 * time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+		MaxAttempts:  2,
+		RetryDelay:   time.Millisecond,
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAsyncJobRunner_RunsJobAsSubmitter(t *testing.T) {
+	queue := newFakeAsyncJobQueue(&QueuedAsyncJob{
+		ID: "job-1", Kind: "import", Payload: json.RawMessage(`{"n":1}`),
+		UserID: "user-7", Roles: []string{"clinician"}, Scopes: []string{"user/*.read"},
+	})
+	runner := NewAsyncJobRunner(queue, nil, testRunnerConfig(), zerolog.Nop())
+
+	type call struct {
+		jobID, payload, user string
+		roles                []string
+	}
+	calls := make(chan call, 1)
+	runner.Handle("import", func(ctx context.Context, jobID string, payload json.RawMessage) error {
+		calls <- call{jobID, string(payload), auth.UserIDFromContext(ctx), auth.RolesFromContext(ctx)}
+		queue.markDone(jobID)
+		return nil
+	})
+	runner.Start()
+	defer runner.Stop()
+
+	select {
+	case got := <-calls:
+		if got.jobID != "job-1" || got.payload != `{"n":1}` || got.user != "user-7" || len(got.roles) != 1 {
+			t.Errorf("call = %+v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("job was not run")
+	}
+	waitFor(t, "lease release", func() bool {
+		leased, _, _ := queue.state("job-1")
+		return !leased
+	})
+}
+
+func TestAsyncJobRunner_RetriesThenFails(t *testing.T) {
+	queue := newFakeAsyncJobQueue(&QueuedAsyncJob{ID: "job-1", Kind: "batch", Payload: json.RawMessage(`{}`)})
+	runner := NewAsyncJobRunner(queue, nil, testRunnerConfig(), zerolog.Nop())
+
+	var mu sync.Mutex
+	attempts := 0
+	runner.Handle("batch", func(context.Context, string, json.RawMessage) error {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		return errors.New("dispatch failed")
+	})
+	runner.Start()
+	defer runner.Stop()
+
+	waitFor(t, "job failure", func() bool {
+		_, _, finished := queue.state("job-1")
+		return finished
+	})
+	_, diagnostics, _ := queue.state("job-1")
+	if diagnostics != "dispatch failed" {
+		t.Errorf("diagnostics = %q", diagnostics)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestAsyncJobRunner_CancelsWhenLeaseLost(t *testing.T) {
+	queue := newFakeAsyncJobQueue(&QueuedAsyncJob{ID: "job-1", Kind: "batch", Payload: json.RawMessage(`{}`)})
+	runner := NewAsyncJobRunner(queue, nil, testRunnerConfig(), zerolog.Nop())
+
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	runner.Handle("batch", func(ctx context.Context, jobID string, _ json.RawMessage) error {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		queue.markDone(jobID)
+		return ctx.Err()
+	})
+	runner.Start()
+	defer runner.Stop()
+
+	<-started
+	queue.mu.Lock()
+	queue.alive = false // e.g. the job was cancelled on another replica
+	queue.mu.Unlock()
+
+	select {
+	case err := <-stopped:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("ctx.Err() = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("job was not cancelled")
+	}
+	waitFor(t, "lease release", func() bool {
+		leased, _, _ := queue.state("job-1")
+		return !leased
+	})
+	if _, diagnostics, _ := queue.state("job-1"); diagnostics != "" {
+		t.Errorf("cancelled job should not be failed, got %q", diagnostics)
+	}
+}
+
+func TestAsyncJobRunner_StopReleasesRunningJobs(t *testing.T) {
+	queue := newFakeAsyncJobQueue(&QueuedAsyncJob{ID: "job-1", Kind: "batch", Payload: json.RawMessage(`{}`)})
+	cfg := testRunnerConfig()
+	cfg.Lease = time.Minute
+	runner := NewAsyncJobRunner(queue, nil, cfg, zerolog.Nop())
+
+	started := make(chan struct{})
+	runner.Handle("batch", func(ctx context.Context, _ string, _ json.RawMessage) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	runner.Start()
+	<-started
+	runner.Stop()
+
+	leased, _, finished := queue.state("job-1")
+	if leased || finished {
+		t.Errorf("after Stop: leased = %v, finished = %v; want the job released for another replica", leased, finished)
+	}
+}
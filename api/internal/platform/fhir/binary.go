@@ -330,6 +330,11 @@ func (h *BinaryHandler) RegisterRoutes(g *echo.Group) {
 	g.DELETE("/Binary/:id", h.handleDelete)
 }
 
+// RegisterResources registers the Binary read and search routes with r.
+func (h *BinaryHandler) RegisterResources(r *ResourceRegistry) {
+	r.RegisterInteractions("Binary", "read", "search-type")
+}
+
 // handleCreate processes POST /fhir/Binary.
 func (h *BinaryHandler) handleCreate(c echo.Context) error {
 	var fj BinaryFHIRJSON
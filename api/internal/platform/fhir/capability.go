@@ -96,6 +96,11 @@ type resourceEntry struct {
 	profile    string // canonical profile URL
 	versioning string
 	operations []OperationCapability
+
+	// chaining advertises chained and reverse-chained (_has) search
+	chaining bool
+	// filtering advertises the _filter parameter
+	filtering bool
 }
 
 // ---------------------------------------------------------------------------
@@ -160,7 +165,7 @@ func NewCapabilityBuilderFromConfig(cfg CapabilityConfig) *CapabilityBuilder {
 		cfg.FHIRVersion = "4.0.1"
 	}
 	if len(cfg.SupportedFormats) == 0 {
-		cfg.SupportedFormats = []string{"application/fhir+json"}
+		cfg.SupportedFormats = []string{"application/fhir+json", "application/fhir+xml"}
 	}
 	if len(cfg.SupportedVersions) == 0 {
 		cfg.SupportedVersions = []string{"4.0.1"}
@@ -309,6 +314,37 @@ func (b *CapabilityBuilder) AddResourceCapability(cap ResourceCapabilityDef) {
 	}
 }
 
+// SetSearchIncludes sets the _include and _revinclude values advertised for
+// a registered resource type.
+func (b *CapabilityBuilder) SetSearchIncludes(resourceType string, include, revInclude []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if entry, ok := b.resources[resourceType]; ok {
+		entry.searchInclude = include
+		entry.searchRevInclude = revInclude
+	}
+}
+
+// EnableChainedSearch advertises chained and reverse-chained (_has) search
+// for a registered resource type.
+func (b *CapabilityBuilder) EnableChainedSearch(resourceType string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if entry, ok := b.resources[resourceType]; ok {
+		entry.chaining = true
+	}
+}
+
+// EnableFilterSearch advertises the _filter parameter for a registered
+// resource type.
+func (b *CapabilityBuilder) EnableFilterSearch(resourceType string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if entry, ok := b.resources[resourceType]; ok {
+		entry.filtering = true
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Server operations & system interactions
 // ---------------------------------------------------------------------------
@@ -552,6 +588,27 @@ func (b *CapabilityBuilder) buildResourceEntry(entry *resourceEntry, rt string)
 		}
 	}
 
+	// Chained search: reference parameters accept chains and _has is listed
+	if entry.chaining {
+		for i, sp := range allParams {
+			if sp.Type == "reference" && sp.Documentation == "" {
+				allParams[i].Documentation = fmt.Sprintf("Supports chaining, e.g. %s:[type].[parameter]", sp.Name)
+			}
+		}
+		allParams = append(allParams, SearchParam{
+			Name:          "_has",
+			Type:          "special",
+			Documentation: "Reverse chaining: _has:[type]:[reference]:[parameter]",
+		})
+	}
+	if entry.filtering {
+		allParams = append(allParams, SearchParam{
+			Name:          "_filter",
+			Type:          "special",
+			Documentation: "Filter expression over the search parameters, e.g. code eq 1234-5 or status eq final",
+		})
+	}
+
 	if len(allParams) > 0 {
 		params := make([]map[string]string, len(allParams))
 		for i, sp := range allParams {
@@ -809,7 +866,7 @@ func DefaultCapabilityBuilder() *CapabilityBuilder {
 		Publisher:         "Headless EHR",
 		Description:       "Headless EHR FHIR R4 Server",
 		BaseURL:           "http://localhost:8000/fhir",
-		SupportedFormats:  []string{"application/fhir+json"},
+		SupportedFormats:  []string{"application/fhir+json", "application/fhir+xml"},
 		SupportedVersions: []string{"4.0.1"},
 	}
 	b := NewCapabilityBuilderFromConfig(cfg)
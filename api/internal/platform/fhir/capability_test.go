@@ -697,6 +697,59 @@ func TestBuild_SearchIncludeRevIncludeOnly(t *testing.T) {
 	}
 }
 
+func TestBuild_FilterSearch(t *testing.T) {
+	b := NewCapabilityBuilder("http://localhost:8000/fhir", "0.1.0")
+	b.AddResource("Observation", []string{"read", "search-type"}, []SearchParam{
+		{Name: "code", Type: "token"},
+	})
+	b.EnableFilterSearch("Observation")
+
+	params := b.GetResourceEntry("Observation")["searchParam"].([]map[string]string)
+	if len(params) != 2 || params[1]["name"] != "_filter" || params[1]["type"] != "special" {
+		t.Fatalf("expected _filter to be listed, got %v", params)
+	}
+}
+
+func TestBuild_SearchIncludesAndChaining(t *testing.T) {
+	reg := NewIncludeRegistry()
+	reg.RegisterReference("Observation", "subject", "Patient")
+	reg.RegisterReference("Observation", "encounter", "Encounter")
+
+	b := NewCapabilityBuilder("http://localhost:8000/fhir", "0.1.0")
+	b.AddResource("Observation", []string{"read", "search-type"}, []SearchParam{
+		{Name: "subject", Type: "reference"},
+		{Name: "code", Type: "token"},
+	})
+	b.AddResource("Patient", []string{"read", "search-type"}, nil)
+	for _, rt := range []string{"Observation", "Patient"} {
+		b.SetSearchIncludes(rt, reg.SearchIncludes(rt), reg.SearchRevIncludes(rt))
+	}
+	b.EnableChainedSearch("Observation")
+
+	obs := b.GetResourceEntry("Observation")
+	if si := obs["searchInclude"].([]string); strings.Join(si, ",") != "Observation:encounter,Observation:subject,*" {
+		t.Errorf("unexpected searchInclude: %v", si)
+	}
+	params := obs["searchParam"].([]map[string]string)
+	if len(params) != 3 || params[2]["name"] != "_has" || params[2]["type"] != "special" {
+		t.Fatalf("expected _has to be listed, got %v", params)
+	}
+	if !strings.Contains(params[0]["documentation"], "chaining") || params[1]["documentation"] != "" {
+		t.Errorf("expected only the reference parameter to be documented as chainable, got %v", params)
+	}
+
+	patient := b.GetResourceEntry("Patient")
+	if sri := patient["searchRevInclude"].([]string); strings.Join(sri, ",") != "Observation:subject" {
+		t.Errorf("unexpected searchRevInclude: %v", sri)
+	}
+	if _, ok := patient["searchInclude"]; ok {
+		t.Error("Patient has no registered references to include")
+	}
+	if _, ok := patient["searchParam"]; ok {
+		t.Error("chaining was not enabled for Patient")
+	}
+}
+
 // ===========================================================================
 // NEW: CapabilityConfig / ResourceCapability / enhanced builder tests
 // ===========================================================================
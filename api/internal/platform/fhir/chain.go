@@ -44,17 +44,23 @@ type chainRegistryKey struct {
 	targetParam    string
 }
 
-// ChainRegistry maps chain paths to their database configurations.
+// ChainRegistry maps chain paths to their database configurations, and
+// holds the search parameters of the domain tables (see RegisterTable) that
+// chained, _has and _filter parameters are compiled against.
 // It is safe for concurrent reads and writes.
 type ChainRegistry struct {
 	mu      sync.RWMutex
 	configs map[chainRegistryKey]ChainedSearchConfig
+	tables  map[string]map[string]SearchParamConfig // table -> search params
+	byType  map[string]string                       // resource type -> table
 }
 
 // NewChainRegistry creates a new empty ChainRegistry.
 func NewChainRegistry() *ChainRegistry {
 	return &ChainRegistry{
 		configs: make(map[chainRegistryKey]ChainedSearchConfig),
+		tables:  make(map[string]map[string]SearchParamConfig),
+		byType:  make(map[string]string),
 	}
 }
 
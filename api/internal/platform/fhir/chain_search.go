@@ -0,0 +1,234 @@
+package fhir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterTable records the search parameters of a domain table so that
+// searches on other resource types can chain into it, e.g.
+// Observation?subject:Patient.identifier=..., or reverse-chain from it, e.g.
+// Patient?_has:Observation:patient:code=.... Each domain package registers
+// its tables with its RegisterSearchTables function.
+func (r *ChainRegistry) RegisterTable(table string, configs map[string]SearchParamConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tables[table] = configs
+	if rt := ResourceTypeForTable(table); rt != "" {
+		r.byType[rt] = table
+	}
+}
+
+// Table returns the table registered for a resource type. A nil registry
+// has no tables.
+func (r *ChainRegistry) Table(resourceType string) (string, bool) {
+	table, _, ok := r.tableConfigs(resourceType)
+	return table, ok
+}
+
+// tableConfigs returns the table and search parameters registered for a
+// resource type.
+func (r *ChainRegistry) tableConfigs(resourceType string) (string, map[string]SearchParamConfig, bool) {
+	if r == nil {
+		return "", nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	table, ok := r.byType[resourceType]
+	if !ok {
+		return "", nil, false
+	}
+	return table, r.tables[table], true
+}
+
+// searchTables returns the registry of the tables q can chain into.
+func (q *SearchQuery) searchTables() *ChainRegistry {
+	if q.services == nil {
+		return nil
+	}
+	return q.services.Chains
+}
+
+// ErrChainNotSupported is returned by ValidateChains for resource types
+// whose repository has no search table to compile chained and _has
+// parameters against.
+var ErrChainNotSupported = errors.New("chained and _has parameters are not supported")
+
+// ValidateChains reports the first chained or _has parameter of params
+// when resourceType has no search table in the SearchServices of ctx
+// (ErrChainNotSupported): its repository would drop the parameter and
+// return every resource the other parameters match.
+func ValidateChains(ctx context.Context, resourceType string, params url.Values) error {
+	if _, ok := SearchServicesFromContext(ctx).Chains.Table(resourceType); ok {
+		return nil
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, has := ParseHasParam(name)
+		_, chained := ParseChainedParam(name)
+		if has || (chained && !strings.HasPrefix(name, "_")) {
+			return fmt.Errorf("%w for %s: %s", ErrChainNotSupported, resourceType, name)
+		}
+	}
+	return nil
+}
+
+// ChainMiddleware rejects searches with chained or _has parameters that the
+// repository of the searched type cannot answer (see ValidateChains) with
+// 400 Bad Request, as FilterMiddleware does for _filter.
+func ChainMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			resourceType := searchedResourceType(c)
+			if resourceType == "" {
+				return next(c)
+			}
+			if err := ValidateChains(c.Request().Context(), resourceType, c.QueryParams()); err != nil {
+				return c.JSON(http.StatusBadRequest, NewOperationOutcome(
+					IssueSeverityError, IssueTypeNotSupported, err.Error(),
+				))
+			}
+			return next(c)
+		}
+	}
+}
+
+// applyChain adds the clause for a chained parameter such as
+// subject:Patient.identifier or general-practitioner.organization.name. The
+// reference column is matched against the ids of the target resources that
+// satisfy the rest of the chain, which may itself be chained. A chain that
+// cannot be resolved, because the reference or the target parameter is not
+// searchable or the target type is ambiguous, matches nothing.
+func (q *SearchQuery) applyChain(chain *ChainedParam, value string, configs map[string]SearchParamConfig, depth int) {
+	if depth >= MaxChainDepth {
+		q.where += " AND 1=0"
+		return
+	}
+	ref, ok := referenceConfig(configs, chain.SourceParam, chain.TargetType)
+	if !ok {
+		q.where += " AND 1=0"
+		return
+	}
+	targetType := chain.TargetType
+	if targetType == "" {
+		targetType = q.searchTables().referenceTargetType(ref.Column, chain.SourceParam)
+	}
+	table, targetConfigs, ok := q.searchTables().tableConfigs(targetType)
+	if !ok {
+		q.where += " AND 1=0"
+		return
+	}
+
+	sub := q.subquery(table)
+	if !sub.applyNamed(chain.TargetParam, value, targetConfigs, depth+1) {
+		q.where += " AND 1=0"
+		return
+	}
+	column, target := ref.Column, "id"
+	if isTextReferenceColumn(ref.Column) {
+		column, target = referencedFHIRID(ref.Column), "fhir_id"
+	}
+	q.where += fmt.Sprintf(" AND %s IN (SELECT %s FROM %s WHERE 1=1%s)", column, target, table, sub.where)
+	q.args = append(q.args, sub.args...)
+	q.idx = sub.idx
+}
+
+// applyHas adds the clause for a reverse-chained parameter such as
+// _has:Observation:patient:code. It matches the resources referenced,
+// through the given reference parameter, by resources of the named type that
+// satisfy the search parameter, which may itself be a _has or a chain. A
+// _has that cannot be resolved matches nothing.
+func (q *SearchQuery) applyHas(has *HasParam, value string, depth int) {
+	sourceType := ResourceTypeForTable(q.table)
+	if depth >= MaxChainDepth || sourceType == "" {
+		q.where += " AND 1=0"
+		return
+	}
+	table, configs, ok := q.searchTables().tableConfigs(has.TargetType)
+	if !ok {
+		q.where += " AND 1=0"
+		return
+	}
+	ref, ok := referenceConfig(configs, has.TargetParam, sourceType)
+	if !ok {
+		q.where += " AND 1=0"
+		return
+	}
+
+	sub := q.subquery(table)
+	if !sub.applyNamed(has.SearchParam, value, configs, depth+1) {
+		q.where += " AND 1=0"
+		return
+	}
+	column, source := "id", ref.Column
+	if isTextReferenceColumn(ref.Column) {
+		column, source = "fhir_id", referencedFHIRID(ref.Column)
+	}
+	q.where += fmt.Sprintf(" AND %s IN (SELECT %s FROM %s WHERE 1=1%s)", column, source, table, sub.where)
+	q.args = append(q.args, sub.args...)
+	q.idx = sub.idx
+}
+
+// referenceConfig returns the reference search parameter param of configs.
+// Repositories that only map the patient parameter answer subject for
+// Patient targets with it, and the other way round.
+func referenceConfig(configs map[string]SearchParamConfig, param, targetType string) (SearchParamConfig, bool) {
+	if config, ok := configs[param]; ok && config.Type == SearchParamReference {
+		return config, true
+	}
+	if targetType != "" && targetType != "Patient" {
+		return SearchParamConfig{}, false
+	}
+	alias := map[string]string{"subject": "patient", "patient": "subject"}[param]
+	if config, ok := configs[alias]; ok && config.Type == SearchParamReference {
+		return config, true
+	}
+	return SearchParamConfig{}, false
+}
+
+// referenceTargetType infers the resource type a reference column points to
+// when a chain does not name it: the longest registered table that ends the
+// column name ("subject_patient_id" -> patient), else the table named by the
+// parameter ("organization"). It returns "" if neither is registered.
+func (r *ChainRegistry) referenceTargetType(column, param string) string {
+	if r == nil {
+		return ""
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stem := strings.TrimSuffix(strings.TrimSuffix(column, "_id"), "_reference")
+	words := strings.Split(stem, "_")
+	for i := range words {
+		if table := strings.Join(words[i:], "_"); r.tables[table] != nil {
+			return ResourceTypeForTable(table)
+		}
+	}
+	if table := strings.ReplaceAll(param, "-", "_"); r.tables[table] != nil {
+		return ResourceTypeForTable(table)
+	}
+	return ""
+}
+
+// isTextReferenceColumn reports whether a reference column holds the
+// reference text, "Type/id" or the bare FHIR id, rather than the id of the
+// referenced row.
+func isTextReferenceColumn(column string) bool {
+	return strings.HasSuffix(column, "_reference")
+}
+
+// referencedFHIRID is the SQL expression for the FHIR id held by a text
+// reference column: its last path segment.
+func referencedFHIRID(column string) string {
+	return fmt.Sprintf("regexp_replace(%s, '^.*/', '')", column)
+}
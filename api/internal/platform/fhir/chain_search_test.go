@@ -0,0 +1,172 @@
+package fhir
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func chainTestTables() *ChainRegistry {
+	r := NewChainRegistry()
+	r.RegisterTable("patient", map[string]SearchParamConfig{
+		"identifier":           {Type: SearchParamToken, Column: "mrn"},
+		"family":               {Type: SearchParamString, Column: "last_name"},
+		"general-practitioner": {Type: SearchParamReference, Column: "practitioner_id"},
+	})
+	r.RegisterTable("practitioner", map[string]SearchParamConfig{
+		"family":       {Type: SearchParamString, Column: "last_name"},
+		"organization": {Type: SearchParamReference, Column: "managing_org_id"},
+	})
+	r.RegisterTable("organization", map[string]SearchParamConfig{
+		"name": {Type: SearchParamString, Column: "name"},
+	})
+	r.RegisterTable("observation", map[string]SearchParamConfig{
+		"patient": {Type: SearchParamReference, Column: "patient_id"},
+		"code":    {Type: SearchParamToken, Column: "code_value", SysColumn: "code_system"},
+	})
+	r.RegisterTable("document_manifest", map[string]SearchParamConfig{
+		"subject": {Type: SearchParamReference, Column: "subject_reference"},
+	})
+	return r
+}
+
+// chainTestContext returns a context whose searches compile chained, _has
+// and _filter parameters against chainTestTables.
+func chainTestContext() context.Context {
+	return WithSearchServices(context.Background(), &SearchServices{Chains: chainTestTables()})
+}
+
+func chainQuery(table string, params map[string]string) *SearchQuery {
+	configs := chainTestTables().tables[table]
+	q := NewSearchQuery(table, "id")
+	q.ApplyParams(chainTestContext(), params, configs)
+	return q
+}
+
+func TestSearchQuery_Chain(t *testing.T) {
+	q := chainQuery("observation", map[string]string{"subject:Patient.identifier": "12345"})
+	want := "patient_id IN (SELECT id FROM patient WHERE 1=1 AND mrn = $1)"
+	if !strings.Contains(q.CountSQL(), want) {
+		t.Errorf("expected %q in %s", want, q.CountSQL())
+	}
+	if args := q.CountArgs(); len(args) != 1 || args[0] != "12345" {
+		t.Errorf("unexpected args: %v", args)
+	}
+	if q.Idx() != 2 {
+		t.Errorf("Idx() = %d, want 2", q.Idx())
+	}
+
+	// The target type is inferred from the reference column.
+	q = chainQuery("observation", map[string]string{"patient.family": "Chalmers"})
+	if !strings.Contains(q.CountSQL(), "patient_id IN (SELECT id FROM patient WHERE 1=1 AND last_name ILIKE $1)") {
+		t.Errorf("untyped chain: %s", q.CountSQL())
+	}
+}
+
+func TestSearchQuery_MultiLevelChain(t *testing.T) {
+	q := chainQuery("patient", map[string]string{"general-practitioner.organization.name": "Acme"})
+	want := "practitioner_id IN (SELECT id FROM practitioner WHERE 1=1 AND " +
+		"managing_org_id IN (SELECT id FROM organization WHERE 1=1 AND name ILIKE $1))"
+	if !strings.Contains(q.CountSQL(), want) {
+		t.Errorf("expected %q in %s", want, q.CountSQL())
+	}
+	if args := q.CountArgs(); len(args) != 1 || args[0] != "Acme%" {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestSearchQuery_Has(t *testing.T) {
+	q := chainQuery("patient", map[string]string{"_has:Observation:patient:code": "http://loinc.org|1234-5"})
+	want := "id IN (SELECT patient_id FROM observation WHERE 1=1 AND (code_system = $1 AND code_value = $2))"
+	if !strings.Contains(q.CountSQL(), want) {
+		t.Errorf("expected %q in %s", want, q.CountSQL())
+	}
+
+	// _has through a chain on the referencing resource.
+	q = chainQuery("organization", map[string]string{"_has:Practitioner:organization:family": "Careful"})
+	if !strings.Contains(q.CountSQL(), "id IN (SELECT managing_org_id FROM practitioner WHERE 1=1 AND last_name ILIKE $1)") {
+		t.Errorf("unexpected SQL: %s", q.CountSQL())
+	}
+
+	q = chainQuery("patient", map[string]string{"_has:DocumentManifest:subject:_id": "dm1"})
+	if !strings.Contains(q.CountSQL(), "AND 1=0") {
+		t.Errorf("unknown search parameter on the referencing type should match nothing: %s", q.CountSQL())
+	}
+}
+
+func TestSearchQuery_ChainTextReference(t *testing.T) {
+	q := chainQuery("document_manifest", map[string]string{"subject:Patient.identifier": "12345"})
+	want := "regexp_replace(subject_reference, '^.*/', '') IN (SELECT fhir_id FROM patient WHERE 1=1 AND mrn = $1)"
+	if !strings.Contains(q.CountSQL(), want) {
+		t.Errorf("expected %q in %s", want, q.CountSQL())
+	}
+
+	q = chainQuery("patient", map[string]string{"_has:DocumentManifest:subject:subject:Patient.family": "Chalmers"})
+	want = "fhir_id IN (SELECT regexp_replace(subject_reference, '^.*/', '') FROM document_manifest WHERE 1=1 AND " +
+		"regexp_replace(subject_reference, '^.*/', '') IN (SELECT fhir_id FROM patient WHERE 1=1 AND last_name ILIKE $1))"
+	if !strings.Contains(q.CountSQL(), want) {
+		t.Errorf("expected %q in %s", want, q.CountSQL())
+	}
+}
+
+func TestSearchQuery_ChainUnresolvable(t *testing.T) {
+	for name, params := range map[string]map[string]string{
+		"unknown reference":    {"performer:Practitioner.family": "x"},
+		"unknown target param": {"subject:Patient.telecom": "x"},
+		"unknown target type":  {"subject:Group.name": "x"},
+		"too deep":             {"patient.general-practitioner.organization._has:Practitioner:organization:family": "x"},
+	} {
+		q := chainQuery("observation", params)
+		if !strings.Contains(q.CountSQL(), "1=0") || len(q.CountArgs()) != 0 {
+			t.Errorf("%s: expected no matches, got %s %v", name, q.CountSQL(), q.CountArgs())
+		}
+	}
+}
+
+func TestExtractSearchParams_KeepsHas(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Patient?_has:Observation:patient:code=1234&_count=10&subject:Patient.name=x", nil)
+	params := ExtractSearchParams(echo.New().NewContext(req, httptest.NewRecorder()))
+	if params["_has:Observation:patient:code"] != "1234" || params["subject:Patient.name"] != "x" {
+		t.Errorf("unexpected params: %v", params)
+	}
+	if _, ok := params["_count"]; ok {
+		t.Error("_count should not be passed")
+	}
+}
+
+func TestChainMiddleware(t *testing.T) {
+	e := echo.New()
+	g := e.Group("/fhir", SearchServicesMiddleware(&SearchServices{Chains: chainTestTables()}), ChainMiddleware())
+	g.GET("/Observation", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	g.GET("/AuditEvent", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		path  string
+		query string
+		want  int
+	}{
+		{"/fhir/Observation", "?patient.family=Chalmers", http.StatusOK},
+		{"/fhir/Observation", "?_has:Observation:patient:code=1234-5", http.StatusOK},
+		{"/fhir/AuditEvent", "?date=ge2024-01-01&_sort=date", http.StatusOK},
+		// AuditEvent's repository has no search table, so it would drop
+		// these parameters and return every event.
+		{"/fhir/AuditEvent", "?agent.identifier=x", http.StatusBadRequest},
+		{"/fhir/AuditEvent", "?_has:Observation:patient:code=1234-5", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path+tt.query, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s%s: expected %d, got %d (%s)", tt.path, tt.query, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -297,6 +297,12 @@ func (h *CompartmentDefinitionHandler) RegisterRoutes(g *echo.Group) {
 	g.GET("/CompartmentDefinition", h.SearchDefinitions)
 }
 
+// RegisterResources registers the CompartmentDefinition read and search
+// routes with r.
+func (h *CompartmentDefinitionHandler) RegisterResources(r *ResourceRegistry) {
+	r.RegisterInteractions("CompartmentDefinition", "read", "search-type")
+}
+
 // GetDefinition handles GET /fhir/CompartmentDefinition/:id.
 // It returns the compartment definition with the matching ID.
 func (h *CompartmentDefinitionHandler) GetDefinition(c echo.Context) error {
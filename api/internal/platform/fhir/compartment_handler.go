@@ -3,61 +3,181 @@ package fhir
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 
+	"github.com/ehr/ehr/internal/platform/auth"
 	"github.com/labstack/echo/v4"
 )
 
-// CompartmentHandler implements Patient compartment search.
-// It delegates to registered search handlers after injecting the patient filter parameter.
+// searchableCompartments lists the compartments served by CompartmentHandler
+// other than Patient, whose route keeps its historical :pid parameter.
+var searchableCompartments = []string{"Encounter", "RelatedPerson", "Practitioner", "Device"}
+
+// CompartmentHandler implements compartment search for the Patient,
+// Encounter, RelatedPerson, Practitioner and Device compartments. It
+// restricts the search to the members of the compartment and serves it
+// through the search route of the type, GET [base]/{type}, with its
+// dispatcher, so that the route's role checks and the domain handler's own
+// access checks apply as they do to the equivalent type-level search.
 type CompartmentHandler struct {
-	searchHandlers map[string]echo.HandlerFunc
+	dispatcher  EntryDispatcher
+	searchTypes map[string]bool
+	membership  map[string]map[string]string // resource type -> compartment -> parameter
+	searchTable func(resourceType string) (string, bool)
 }
 
-// NewCompartmentHandler creates a new CompartmentHandler.
-func NewCompartmentHandler() *CompartmentHandler {
+// NewCompartmentHandler creates a CompartmentHandler that serves searches
+// through dispatcher, typically an EchoEntryDispatcher on the FHIR routes.
+func NewCompartmentHandler(dispatcher EntryDispatcher) *CompartmentHandler {
 	return &CompartmentHandler{
-		searchHandlers: make(map[string]echo.HandlerFunc),
+		dispatcher:  dispatcher,
+		searchTypes: make(map[string]bool),
+		membership:  make(map[string]map[string]string),
 	}
 }
 
-// RegisterSearchHandler registers a FHIR search handler for a resource type.
-func (h *CompartmentHandler) RegisterSearchHandler(resourceType string, handler echo.HandlerFunc) {
-	h.searchHandlers[resourceType] = handler
+// RegisterSearchType registers a resource type whose search route serves
+// its compartment searches. The route must answer the _compartment
+// parameter (see SetSearchTableLookup) or, in the Patient compartment, the
+// parameter of PatientCompartment for the type, such as patient. Use
+// RegisterMembershipSearchType for routes that answer another membership
+// parameter.
+func (h *CompartmentHandler) RegisterSearchType(resourceType string) {
+	h.searchTypes[resourceType] = true
+}
+
+// RegisterMembershipSearchType registers a resource type whose search route
+// does not answer _compartment, but answers param, a membership parameter
+// of the type in compartment. Searches of the type in other compartments
+// are not supported.
+func (h *CompartmentHandler) RegisterMembershipSearchType(resourceType, compartment, param string) {
+	h.searchTypes[resourceType] = true
+	if h.membership[resourceType] == nil {
+		h.membership[resourceType] = make(map[string]string)
+	}
+	h.membership[resourceType][compartment] = param
+}
+
+// SetSearchTableLookup sets the function that reports whether the
+// repository of a resource type has registered its search table, typically
+// ChainRegistry.Table. The searches of those types are restricted with the
+// _compartment parameter.
+func (h *CompartmentHandler) SetSearchTableLookup(lookup func(resourceType string) (string, bool)) {
+	h.searchTable = lookup
 }
 
 // RegisterRoutes registers compartment search routes.
 func (h *CompartmentHandler) RegisterRoutes(fhirGroup *echo.Group) {
 	// Patient compartment: GET /fhir/Patient/:pid/:resourceType
 	fhirGroup.GET("/Patient/:pid/:resourceType", h.PatientCompartmentSearch)
+	// Other compartments: GET /fhir/Encounter/:id/:resourceType, ...
+	for _, compartment := range searchableCompartments {
+		fhirGroup.GET("/"+compartment+"/:id/:resourceType", h.CompartmentSearch(compartment))
+	}
 }
 
 // PatientCompartmentSearch handles GET /fhir/Patient/:pid/:resourceType.
-// It validates that the resource type belongs to the Patient compartment,
-// injects the patient search parameter, and delegates to the registered search handler.
 func (h *CompartmentHandler) PatientCompartmentSearch(c echo.Context) error {
-	patientID := c.Param("pid")
+	return h.search(c, "Patient", c.Param("pid"))
+}
+
+// CompartmentSearch returns the handler of GET
+// /fhir/{compartment}/:id/:resourceType.
+func (h *CompartmentHandler) CompartmentSearch(compartment string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return h.search(c, compartment, c.Param("id"))
+	}
+}
+
+// search validates that the resource type belongs to the compartment,
+// restricts the query to the compartment's members and serves it through
+// the search route of the type. Repositories that register their search
+// table (see SetSearchTableLookup) answer the _compartment parameter, which
+// matches any of the membership parameters of the CompartmentDefinition;
+// other routes answer the membership parameter they were registered with
+// (see RegisterMembershipSearchType), and searches they cannot answer are
+// not supported rather than widened to every resource of the type. Apps
+// launched in a patient context may only search that patient's
+// compartment.
+func (h *CompartmentHandler) search(c echo.Context, compartment, id string) error {
 	resourceType := c.Param("resourceType")
 
-	if patientID == "" {
-		return c.JSON(http.StatusBadRequest, ErrorOutcome("patient ID is required"))
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorOutcome(
+			fmt.Sprintf("%s ID is required", strings.ToLower(compartment))))
 	}
 
-	// Check if resource type is in patient compartment
-	param := GetCompartmentParam(&PatientCompartment, resourceType)
-	if param == "" {
+	params := CompartmentMembershipParams(compartment, resourceType)
+	if len(params) == 0 {
 		return c.JSON(http.StatusBadRequest, ErrorOutcome(
-			fmt.Sprintf("%s is not in the Patient compartment", resourceType)))
+			fmt.Sprintf("%s is not in the %s compartment", resourceType, compartment)))
 	}
 
-	// Set the patient search parameter on the query
-	q := c.QueryParams()
-	q.Set(param, patientID)
+	if compartment == "Patient" {
+		if launch := auth.SMARTPatientIDFromContext(c.Request().Context()); launch != "" && launch != id {
+			return c.JSON(http.StatusForbidden, NewOperationOutcome(
+				IssueSeverityError, IssueTypeSecurity,
+				fmt.Sprintf("access is limited to the compartment of Patient/%s", launch)))
+		}
+	}
 
-	handler, ok := h.searchHandlers[resourceType]
-	if !ok {
+	if !h.searchTypes[resourceType] {
 		return c.JSON(http.StatusNotFound, ErrorOutcome(
 			fmt.Sprintf("no search handler registered for %s", resourceType)))
 	}
 
-	return handler(c)
+	q := url.Values{}
+	for k, v := range c.QueryParams() {
+		q[k] = v
+	}
+	if h.answersCompartment(resourceType) {
+		q.Set(CompartmentParam, compartment+"/"+id)
+	} else if param := h.membershipParam(compartment, resourceType); param != "" {
+		q.Set(param, id)
+	} else {
+		return c.JSON(http.StatusNotImplemented, NewOperationOutcome(
+			IssueSeverityError, IssueTypeNotSupported,
+			fmt.Sprintf("%s search of the %s compartment is not supported", resourceType, compartment)))
+	}
+
+	result, err := h.dispatcher.Dispatch(c.Request().Context(), &EntryDispatchRequest{
+		Method: http.MethodGet,
+		URL:    resourceType + "?" + q.Encode(),
+		Header: c.Request().Header,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
+	}
+	for k, v := range result.Header {
+		c.Response().Header()[k] = v
+	}
+	c.Response().WriteHeader(result.StatusCode)
+	_, err = c.Response().Write(result.Body)
+	return err
+}
+
+// membershipParam returns the membership parameter answered by the search
+// route of resourceType, which does not answer _compartment: the one it was
+// registered with (see RegisterMembershipSearchType) or, in the Patient
+// compartment, that of PatientCompartment. It returns "" when the route
+// answers none.
+func (h *CompartmentHandler) membershipParam(compartment, resourceType string) string {
+	if param, ok := h.membership[resourceType][compartment]; ok {
+		return param
+	}
+	if len(h.membership[resourceType]) == 0 && compartment == "Patient" {
+		return GetCompartmentParam(&PatientCompartment, resourceType)
+	}
+	return ""
+}
+
+// answersCompartment reports whether the search of resourceType answers
+// the _compartment parameter.
+func (h *CompartmentHandler) answersCompartment(resourceType string) bool {
+	if h.searchTable == nil {
+		return false
+	}
+	_, ok := h.searchTable(resourceType)
+	return ok
 }
@@ -1,23 +1,38 @@
 package fhir
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/ehr/ehr/internal/platform/auth"
 	"github.com/labstack/echo/v4"
 )
 
 // =========== CompartmentHandler Tests ===========
 
-func TestCompartmentHandler_PatientCompartmentSearch_Success(t *testing.T) {
-	h := NewCompartmentHandler()
+// newCompartmentTestHandler returns a CompartmentHandler that dispatches to
+// the routes of the returned Echo instance, as the server's does.
+func newCompartmentTestHandler() (*CompartmentHandler, *echo.Echo) {
 	e := echo.New()
+	return NewCompartmentHandler(NewEchoEntryDispatcher(e, "/fhir")), e
+}
+
+// registerSearchRoute serves handler as the search route of resourceType,
+// behind middleware, and registers the type with h.
+func registerSearchRoute(e *echo.Echo, h *CompartmentHandler, resourceType string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) {
+	e.GET("/fhir/"+resourceType, handler, middleware...)
+	h.RegisterSearchType(resourceType)
+}
+
+func TestCompartmentHandler_PatientCompartmentSearch_Success(t *testing.T) {
+	h, e := newCompartmentTestHandler()
 
 	// Register a mock search handler for Observation
 	var capturedPatientParam string
-	h.RegisterSearchHandler("Observation", func(c echo.Context) error {
+	registerSearchRoute(e, h, "Observation", func(c echo.Context) error {
 		capturedPatientParam = c.QueryParam("patient")
 		return c.JSON(http.StatusOK, map[string]interface{}{
 			"resourceType": "Bundle",
@@ -47,11 +62,10 @@ func TestCompartmentHandler_PatientCompartmentSearch_Success(t *testing.T) {
 }
 
 func TestCompartmentHandler_PatientCompartmentSearch_Condition(t *testing.T) {
-	h := NewCompartmentHandler()
-	e := echo.New()
+	h, e := newCompartmentTestHandler()
 
 	var capturedPatientParam string
-	h.RegisterSearchHandler("Condition", func(c echo.Context) error {
+	registerSearchRoute(e, h, "Condition", func(c echo.Context) error {
 		capturedPatientParam = c.QueryParam("patient")
 		return c.JSON(http.StatusOK, map[string]interface{}{
 			"resourceType": "Bundle",
@@ -78,8 +92,7 @@ func TestCompartmentHandler_PatientCompartmentSearch_Condition(t *testing.T) {
 }
 
 func TestCompartmentHandler_PatientCompartmentSearch_NotInCompartment(t *testing.T) {
-	h := NewCompartmentHandler()
-	e := echo.New()
+	h, e := newCompartmentTestHandler()
 
 	// "Organization" is not in the Patient compartment
 	req := httptest.NewRequest(http.MethodGet, "/fhir/Patient/p1/Organization", nil)
@@ -104,8 +117,7 @@ func TestCompartmentHandler_PatientCompartmentSearch_NotInCompartment(t *testing
 }
 
 func TestCompartmentHandler_PatientCompartmentSearch_NoHandler(t *testing.T) {
-	h := NewCompartmentHandler()
-	e := echo.New()
+	h, e := newCompartmentTestHandler()
 
 	// Encounter is in the compartment, but no handler registered
 	req := httptest.NewRequest(http.MethodGet, "/fhir/Patient/p1/Encounter", nil)
@@ -124,8 +136,7 @@ func TestCompartmentHandler_PatientCompartmentSearch_NoHandler(t *testing.T) {
 }
 
 func TestCompartmentHandler_PatientCompartmentSearch_EmptyPID(t *testing.T) {
-	h := NewCompartmentHandler()
-	e := echo.New()
+	h, e := newCompartmentTestHandler()
 
 	req := httptest.NewRequest(http.MethodGet, "/fhir/Patient//Observation", nil)
 	rec := httptest.NewRecorder()
@@ -143,8 +154,7 @@ func TestCompartmentHandler_PatientCompartmentSearch_EmptyPID(t *testing.T) {
 }
 
 func TestCompartmentHandler_PatientCompartmentSearch_MedicationNoParam(t *testing.T) {
-	h := NewCompartmentHandler()
-	e := echo.New()
+	h, e := newCompartmentTestHandler()
 
 	// Medication is in the compartment definition but has empty linking params
 	req := httptest.NewRequest(http.MethodGet, "/fhir/Patient/p1/Medication", nil)
@@ -163,21 +173,20 @@ func TestCompartmentHandler_PatientCompartmentSearch_MedicationNoParam(t *testin
 	}
 }
 
-func TestCompartmentHandler_RegisterSearchHandler(t *testing.T) {
-	h := NewCompartmentHandler()
+func TestCompartmentHandler_RegisterSearchType(t *testing.T) {
+	h, e := newCompartmentTestHandler()
 
 	handlerCalled := false
-	h.RegisterSearchHandler("Observation", func(c echo.Context) error {
+	registerSearchRoute(e, h, "Observation", func(c echo.Context) error {
 		handlerCalled = true
 		return c.NoContent(http.StatusOK)
 	})
 
-	if _, ok := h.searchHandlers["Observation"]; !ok {
-		t.Error("expected Observation handler to be registered")
+	if !h.searchTypes["Observation"] {
+		t.Error("expected Observation to be registered")
 	}
 
-	// Verify handler can be called
-	e := echo.New()
+	// Verify the search route is called
 	req := httptest.NewRequest(http.MethodGet, "/fhir/Patient/p1/Observation", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
@@ -186,13 +195,12 @@ func TestCompartmentHandler_RegisterSearchHandler(t *testing.T) {
 
 	h.PatientCompartmentSearch(c)
 	if !handlerCalled {
-		t.Error("expected registered handler to be called")
+		t.Error("expected the search route to be called")
 	}
 }
 
 func TestCompartmentHandler_RegisterRoutes(t *testing.T) {
-	h := NewCompartmentHandler()
-	e := echo.New()
+	h, e := newCompartmentTestHandler()
 	fhirGroup := e.Group("/fhir")
 
 	h.RegisterRoutes(fhirGroup)
@@ -211,12 +219,11 @@ func TestCompartmentHandler_RegisterRoutes(t *testing.T) {
 }
 
 func TestCompartmentHandler_PreservesExistingQueryParams(t *testing.T) {
-	h := NewCompartmentHandler()
-	e := echo.New()
+	h, e := newCompartmentTestHandler()
 
 	var capturedStatus string
 	var capturedPatient string
-	h.RegisterSearchHandler("Observation", func(c echo.Context) error {
+	registerSearchRoute(e, h, "Observation", func(c echo.Context) error {
 		capturedStatus = c.QueryParam("status")
 		capturedPatient = c.QueryParam("patient")
 		return c.NoContent(http.StatusOK)
@@ -238,3 +245,204 @@ func TestCompartmentHandler_PreservesExistingQueryParams(t *testing.T) {
 		t.Errorf("expected status 'final', got %q", capturedStatus)
 	}
 }
+
+func TestCompartmentHandler_RegisterRoutes_AllCompartments(t *testing.T) {
+	h, e := newCompartmentTestHandler()
+	h.RegisterRoutes(e.Group("/fhir"))
+
+	paths := make(map[string]bool)
+	for _, r := range e.Routes() {
+		paths[r.Path] = true
+	}
+	for _, compartment := range []string{"Encounter", "RelatedPerson", "Practitioner", "Device"} {
+		if !paths["/fhir/"+compartment+"/:id/:resourceType"] {
+			t.Errorf("expected route GET /fhir/%s/:id/:resourceType", compartment)
+		}
+	}
+}
+
+func TestCompartmentHandler_CompartmentParam(t *testing.T) {
+	h, e := newCompartmentTestHandler()
+	h.SetSearchTableLookup(func(resourceType string) (string, bool) {
+		return "observation", resourceType == "Observation"
+	})
+
+	var captured map[string]string
+	registerSearchRoute(e, h, "Observation", func(c echo.Context) error {
+		captured = ExtractSearchParams(c)
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Patient/p1/Observation?code=1234-5&_compartment=Patient/other", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("pid", "resourceType")
+	c.SetParamValues("p1", "Observation")
+
+	if err := h.PatientCompartmentSearch(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if captured[CompartmentParam] != "Patient/p1" {
+		t.Errorf("expected _compartment 'Patient/p1', got %q", captured[CompartmentParam])
+	}
+	if captured["code"] != "1234-5" {
+		t.Errorf("expected code '1234-5', got %q", captured["code"])
+	}
+	if _, ok := captured["patient"]; ok {
+		t.Errorf("expected no patient param, got %v", captured)
+	}
+}
+
+func TestCompartmentHandler_EncounterCompartment(t *testing.T) {
+	h, e := newCompartmentTestHandler()
+	h.SetSearchTableLookup(func(resourceType string) (string, bool) {
+		return "observation", resourceType == "Observation"
+	})
+
+	var captured map[string]string
+	registerSearchRoute(e, h, "Observation", func(c echo.Context) error {
+		captured = ExtractSearchParams(c)
+		return c.NoContent(http.StatusOK)
+	})
+	registerSearchRoute(e, h, "Procedure", func(c echo.Context) error {
+		t.Error("Procedure answers only the patient parameter and should not be searched")
+		return c.NoContent(http.StatusOK)
+	})
+
+	search := func(resourceType string) int {
+		req := httptest.NewRequest(http.MethodGet, "/fhir/Encounter/e1/"+resourceType, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "resourceType")
+		c.SetParamValues("e1", resourceType)
+		if err := h.CompartmentSearch("Encounter")(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec.Code
+	}
+
+	if code := search("Observation"); code != http.StatusOK {
+		t.Errorf("expected 200, got %d", code)
+	}
+	if captured[CompartmentParam] != "Encounter/e1" {
+		t.Errorf("expected _compartment 'Encounter/e1', got %v", captured)
+	}
+	// The Procedure handler does not answer _compartment.
+	if code := search("Procedure"); code != http.StatusNotImplemented {
+		t.Errorf("expected 501, got %d", code)
+	}
+	// Patient is not in the Encounter compartment.
+	if code := search("Patient"); code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", code)
+	}
+}
+
+func TestCompartmentHandler_MembershipSearchHandler(t *testing.T) {
+	h, e := newCompartmentTestHandler()
+
+	// An AuditEvent store whose search, like its repository, answers the
+	// patient parameter and ignores the others.
+	events := []map[string]interface{}{
+		{"resourceType": "AuditEvent", "id": "a1", "patient": "p1"},
+		{"resourceType": "AuditEvent", "id": "a2", "patient": "p2"},
+		{"resourceType": "AuditEvent", "id": "a3", "patient": "p1"},
+	}
+	h.RegisterMembershipSearchType("AuditEvent", "Patient", "patient")
+	e.GET("/fhir/AuditEvent", func(c echo.Context) error {
+		var matches []interface{}
+		for _, ev := range events {
+			if p := c.QueryParam("patient"); p == "" || ev["patient"] == p {
+				matches = append(matches, ev)
+			}
+		}
+		return c.JSON(http.StatusOK, NewSearchBundle(matches, len(matches), "/fhir/AuditEvent"))
+	})
+
+	search := func(compartment, id string) (int, []string) {
+		req := httptest.NewRequest(http.MethodGet, "/fhir/"+compartment+"/"+id+"/AuditEvent", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "resourceType")
+		c.SetParamValues(id, "AuditEvent")
+		if err := h.CompartmentSearch(compartment)(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var bundle struct {
+			Entry []struct {
+				Resource map[string]interface{} `json:"resource"`
+			} `json:"entry"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &bundle)
+		var ids []string
+		for _, entry := range bundle.Entry {
+			ids = append(ids, entry.Resource["id"].(string))
+		}
+		return rec.Code, ids
+	}
+
+	code, ids := search("Patient", "p1")
+	if code != http.StatusOK || len(ids) != 2 || ids[0] != "a1" || ids[1] != "a3" {
+		t.Errorf("Patient/p1/AuditEvent = %d %v, want a1 and a3", code, ids)
+	}
+	// The AuditEvent search cannot answer the agent parameter of the
+	// Practitioner compartment, so it is refused rather than returning
+	// every event.
+	if code, ids := search("Practitioner", "dr1"); code != http.StatusNotImplemented || len(ids) != 0 {
+		t.Errorf("Practitioner/dr1/AuditEvent = %d %v, want 501", code, ids)
+	}
+}
+
+func TestCompartmentHandler_RouteRoleChecks(t *testing.T) {
+	h, e := newCompartmentTestHandler()
+	h.RegisterMembershipSearchType("AuditEvent", "Patient", "patient")
+	e.GET("/fhir/AuditEvent", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, NewSearchBundle(nil, 0, "/fhir/AuditEvent"))
+	}, auth.RequireRole("admin"))
+	h.RegisterRoutes(e.Group("/fhir"))
+
+	for _, tt := range []struct {
+		role string
+		want int
+	}{
+		{"nurse", http.StatusForbidden},
+		{"physician", http.StatusForbidden},
+		{"admin", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/fhir/Patient/p1/AuditEvent", nil)
+		req = req.WithContext(context.WithValue(req.Context(), auth.UserRolesKey, []string{tt.role}))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d: %s", tt.role, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestCompartmentHandler_SMARTPatientContext(t *testing.T) {
+	h, e := newCompartmentTestHandler()
+	registerSearchRoute(e, h, "Observation", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, tt := range []struct {
+		pid  string
+		want int
+	}{
+		{"p1", http.StatusOK},
+		{"p2", http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/fhir/Patient/"+tt.pid+"/Observation", nil)
+		req = req.WithContext(context.WithValue(req.Context(), auth.SMARTPatientIDKey, "p1"))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("pid", "resourceType")
+		c.SetParamValues(tt.pid, "Observation")
+
+		if err := h.PatientCompartmentSearch(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rec.Code != tt.want {
+			t.Errorf("Patient/%s: expected %d, got %d", tt.pid, tt.want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package fhir
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompartmentParam is the internal search parameter that restricts a search
+// to the members of a compartment, e.g. _compartment=Patient/123. The
+// CompartmentHandler sets it for the searches of repositories that register
+// their search table.
+const CompartmentParam = "_compartment"
+
+// CompartmentMembershipParams returns the search parameters that make
+// resourceType a member of compartment ("Patient", "Encounter", ...), as
+// given by its CompartmentDefinition. Patient compartment types the
+// definition omits keep the parameter of PatientCompartment. It returns nil
+// if the type is not a member.
+func CompartmentMembershipParams(compartment, resourceType string) []string {
+	var params []string
+	if def := GetCompartmentDefinitionByCode(compartment); def != nil {
+		params = CompartmentResourceParams(def, resourceType)
+	}
+	if len(params) == 0 && strings.EqualFold(compartment, "Patient") {
+		if param := GetCompartmentParam(&PatientCompartment, resourceType); param != "" {
+			params = []string{param}
+		}
+	}
+	return params
+}
+
+// applyCompartment adds the clause for _compartment=Type/id: the resource
+// must refer to the compartment's focal resource through any of the
+// parameters that make its type a member of the compartment. A resource type
+// that is not a member, or none of whose membership parameters is
+// searchable, matches nothing.
+func (q *SearchQuery) applyCompartment(value string, configs map[string]SearchParamConfig) {
+	compartment, id, _ := strings.Cut(value, "/")
+	def := GetCompartmentDefinitionByCode(compartment)
+	if def == nil || id == "" {
+		q.where += " AND 1=0"
+		return
+	}
+
+	var clauses []string
+	seen := make(map[string]bool)
+	for _, param := range CompartmentMembershipParams(def.Code, ResourceTypeForTable(q.table)) {
+		var config SearchParamConfig
+		var ok bool
+		if param == "_id" {
+			config, ok = configs["_id"]
+		} else {
+			config, ok = referenceConfig(configs, param, def.Code)
+		}
+		if !ok || seen[config.Column] {
+			continue
+		}
+		seen[config.Column] = true
+
+		var clause string
+		var args []interface{}
+		if param == "_id" {
+			clause, args = fmt.Sprintf("%s = $%d", config.Column, q.idx), []interface{}{id}
+			q.idx++
+		} else {
+			clause, args, q.idx = compartmentReferenceClause(q.searchTables(), config.Column, def.Code, id, q.idx)
+		}
+		clauses = append(clauses, clause)
+		q.args = append(q.args, args...)
+	}
+	if len(clauses) == 0 {
+		q.where += " AND 1=0"
+		return
+	}
+	q.where += " AND (" + strings.Join(clauses, " OR ") + ")"
+}
+
+// compartmentReferenceClause matches a reference column against the
+// compartment's focal resource resourceType/id. Ids that are not row ids are
+// resolved through the fhir_id of the table registered in tables for the
+// type.
+func compartmentReferenceClause(tables *ChainRegistry, column, resourceType, id string, idx int) (string, []interface{}, int) {
+	if isTextReferenceColumn(column) {
+		return fmt.Sprintf("%s = $%d", referencedFHIRID(column), idx), []interface{}{id}, idx + 1
+	}
+	if table, ok := tables.Table(resourceType); ok && !isUUID(id) {
+		clause := fmt.Sprintf("%s = (SELECT id FROM %s WHERE fhir_id = $%d LIMIT 1)", column, table, idx)
+		return clause, []interface{}{id}, idx + 1
+	}
+	return ReferenceSearchClause(column, resourceType+"/"+id, idx)
+}
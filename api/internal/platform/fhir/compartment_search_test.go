@@ -0,0 +1,125 @@
+package fhir
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func compartmentQuery(table, compartment string, configs map[string]SearchParamConfig) *SearchQuery {
+	q := NewSearchQuery(table, "id")
+	q.ApplyParams(chainTestContext(), map[string]string{CompartmentParam: compartment}, configs)
+	return q
+}
+
+func TestSearchQuery_CompartmentMembershipParams(t *testing.T) {
+	// Observation is in the Patient compartment through subject and
+	// performer; only subject is searchable, through the patient alias.
+	q := compartmentQuery("observation", "Patient/p1", map[string]SearchParamConfig{
+		"patient": {Type: SearchParamReference, Column: "patient_id"},
+		"code":    {Type: SearchParamToken, Column: "code_value", SysColumn: "code_system"},
+	})
+	want := " AND (patient_id = (SELECT id FROM patient WHERE fhir_id = $1 LIMIT 1))"
+	if !strings.HasSuffix(q.CountSQL(), want) {
+		t.Errorf("expected %q in %s", want, q.CountSQL())
+	}
+	if args := q.CountArgs(); len(args) != 1 || args[0] != "p1" {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestSearchQuery_CompartmentAnyParam(t *testing.T) {
+	// AllergyIntolerance is in the Patient compartment through patient,
+	// recorder and asserter; any of them makes it a member.
+	q := compartmentQuery("allergy_intolerance", "Patient/550e8400-e29b-41d4-a716-446655440000", map[string]SearchParamConfig{
+		"patient":  {Type: SearchParamReference, Column: "patient_id"},
+		"recorder": {Type: SearchParamReference, Column: "recorder_reference"},
+	})
+	want := " AND (patient_id = $1 OR regexp_replace(recorder_reference, '^.*/', '') = $2)"
+	if !strings.HasSuffix(q.CountSQL(), want) {
+		t.Errorf("expected %q in %s", want, q.CountSQL())
+	}
+	if q.Idx() != 3 {
+		t.Errorf("Idx() = %d, want 3", q.Idx())
+	}
+}
+
+func TestSearchQuery_CompartmentID(t *testing.T) {
+	// An Encounter is in its own compartment through _id.
+	q := compartmentQuery("encounter", "Encounter/e1", map[string]SearchParamConfig{
+		"_id":     {Type: SearchParamToken, Column: "fhir_id"},
+		"patient": {Type: SearchParamReference, Column: "patient_id"},
+	})
+	if !strings.HasSuffix(q.CountSQL(), " AND (fhir_id = $1)") {
+		t.Errorf("unexpected SQL: %s", q.CountSQL())
+	}
+}
+
+func TestSearchQuery_CompartmentCombinesWithOtherParams(t *testing.T) {
+	q := NewSearchQuery("observation", "id")
+	q.ApplyParams(chainTestContext(), map[string]string{CompartmentParam: "Encounter/e1", "code": "1234-5"}, map[string]SearchParamConfig{
+		"encounter": {Type: SearchParamReference, Column: "encounter_id"},
+		"code":      {Type: SearchParamToken, Column: "code_value"},
+	})
+	sql := q.CountSQL()
+	if !strings.Contains(sql, "(encounter_id = ") || !strings.Contains(sql, "code_value = $") {
+		t.Errorf("expected compartment and code clauses in %s", sql)
+	}
+	if len(q.CountArgs()) != 2 {
+		t.Errorf("unexpected args: %v", q.CountArgs())
+	}
+}
+
+func TestSearchQuery_CompartmentMatchesNothing(t *testing.T) {
+	configs := map[string]SearchParamConfig{
+		"patient": {Type: SearchParamReference, Column: "patient_id"},
+	}
+	tests := []struct {
+		name, table, value string
+	}{
+		{"not a member", "organization", "Patient/p1"},
+		{"no searchable param", "observation", "Practitioner/pr1"},
+		{"unknown compartment", "observation", "Group/g1"},
+		{"no id", "observation", "Patient"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := compartmentQuery(tt.table, tt.value, configs)
+			if !strings.HasSuffix(q.CountSQL(), " AND 1=0") {
+				t.Errorf("expected no match, got %s", q.CountSQL())
+			}
+		})
+	}
+}
+
+func TestCompartmentMembershipParams(t *testing.T) {
+	if got := CompartmentMembershipParams("Patient", "Observation"); len(got) != 2 || got[0] != "subject" {
+		t.Errorf("Patient/Observation = %v", got)
+	}
+	if got := CompartmentMembershipParams("encounter", "Observation"); len(got) != 1 || got[0] != "encounter" {
+		t.Errorf("Encounter/Observation = %v", got)
+	}
+	// Composition is only listed by the legacy Patient compartment.
+	if got := CompartmentMembershipParams("Patient", "Composition"); len(got) != 1 || got[0] != "patient" {
+		t.Errorf("Patient/Composition = %v", got)
+	}
+	if got := CompartmentMembershipParams("Patient", "Organization"); got != nil {
+		t.Errorf("Patient/Organization = %v, want nil", got)
+	}
+}
+
+func TestExtractSearchParams_KeepsCompartment(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Observation?_compartment=Patient/p1&_format=json", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+	params := ExtractSearchParams(c)
+	if params[CompartmentParam] != "Patient/p1" {
+		t.Errorf("expected _compartment to be kept, got %v", params)
+	}
+	if _, ok := params["_format"]; ok {
+		t.Errorf("expected _format to be dropped, got %v", params)
+	}
+}
@@ -16,6 +16,11 @@ type CompositeComponent struct {
 	Type      SearchParamType // The underlying search type (token, date, quantity, string, etc.)
 	Column    string          // Primary DB column for this component
 	SysColumn string          // System column (for token components with system|code)
+
+	// Quantity components with a unit; see SearchParamConfig.
+	UnitColumn          string
+	CanonicalColumn     string
+	CanonicalUnitColumn string
 }
 
 // CompositeSearchConfig defines the structure of a composite search parameter.
@@ -73,9 +78,16 @@ func CompositeSearchClause(config CompositeSearchConfig, value string, startIdx
 		case SearchParamDate:
 			clause, args, nextIdx = DateSearchClause(comp.Column, part, idx)
 		case SearchParamNumber, SearchParamQuantity:
-			// Quantity values may contain unit info: number|system|code
-			// Parse the quantity value and use the numeric portion for comparison
-			clause, args, nextIdx = quantityComponentClause(comp.Column, part, idx)
+			// Quantity values may contain unit info: number|system|code.
+			// Components without unit columns compare the numeric portion only.
+			if comp.UnitColumn != "" {
+				clause, args, nextIdx = QuantitySearchClause(QuantityColumns{
+					Value: comp.Column, System: comp.SysColumn, Code: comp.UnitColumn,
+					CanonicalValue: comp.CanonicalColumn, CanonicalCode: comp.CanonicalUnitColumn,
+				}, part, idx)
+			} else {
+				clause, args, nextIdx = quantityComponentClause(comp.Column, part, idx)
+			}
 		case SearchParamString:
 			clause, args, nextIdx = StringSearchClause(comp.Column, part, "", idx)
 		case SearchParamReference:
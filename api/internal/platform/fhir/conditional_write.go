@@ -0,0 +1,363 @@
+package fhir
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// ResourceLocker serializes writes to a resource across requests and
+// replicas. Lock blocks until the lock on key is held and returns the
+// function that releases it.
+type ResourceLocker interface {
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+}
+
+// ConditionalWriteConfig configures ConditionalWriteMiddleware.
+type ConditionalWriteConfig struct {
+	// Dispatcher runs the searches, reads and writes that conditional
+	// interactions resolve to, typically an EchoEntryDispatcher.
+	Dispatcher EntryDispatcher
+	// BasePath is the path the FHIR API is served under, e.g. "/fhir".
+	BasePath string
+	// Locker, when set, holds a lock on the target resource from the
+	// precondition check until the write completes, so that two clients
+	// updating the same version cannot both succeed.
+	Locker ResourceLocker
+	// MaxDeleteCount is the number of resources a conditional delete may
+	// remove at once. Zero or one allows single deletes only, and multiple
+	// matches are rejected with 412.
+	MaxDeleteCount int
+	// BeginTx starts the transaction a conditional delete of several
+	// resources runs in, so that either all matches are deleted or none are.
+	// When nil, DefaultBeginTx is used for requests that hold a database
+	// connection and are not already in a transaction.
+	BeginTx BeginTxFunc
+}
+
+// ConditionalWriteMiddleware implements the FHIR conditional write
+// interactions and optimistic locking for every resource type served under
+// cfg.BasePath:
+//
+//   - POST [type] with If-None-Exist creates the resource only if no resource
+//     matches the criteria, returns the match with 200 OK if one does, and
+//     412 if several do.
+//   - PUT [type]?[criteria] updates the single matching resource, or creates
+//     one if nothing matches; PATCH [type]?[criteria] patches the single
+//     match.
+//   - DELETE [type]?[criteria] deletes the matching resource, or up to
+//     cfg.MaxDeleteCount matching resources.
+//   - PUT, PATCH and DELETE with If-Match: W/"n" fail with 412 unless the
+//     resource is at version n.
+//
+// Searches and writes are dispatched through the domain handlers' routes, so
+// their role checks apply. Install it with Echo.Use after the authentication
+// and tenant middleware: conditional updates and deletes have no route of
+// their own and must be handled before the router's 405 response.
+func ConditionalWriteMiddleware(cfg ConditionalWriteConfig) echo.MiddlewareFunc {
+	prefix := strings.TrimRight(cfg.BasePath, "/") + "/"
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if !strings.HasPrefix(req.URL.Path, prefix) {
+				return next(c)
+			}
+			segs := strings.Split(strings.TrimPrefix(req.URL.Path, prefix), "/")
+			resourceType := segs[0]
+			if !IsValidResourceType(resourceType) {
+				return next(c)
+			}
+
+			switch {
+			case len(segs) == 1 && req.Method == http.MethodPost:
+				if criteria := req.Header.Get("If-None-Exist"); criteria != "" {
+					return cfg.conditionalCreate(c, next, resourceType, criteria)
+				}
+			case len(segs) == 1 && isConditionalWriteMethod(req.Method) && req.URL.RawQuery != "":
+				return cfg.conditionalWrite(c, resourceType, req.URL.RawQuery)
+			case len(segs) == 2 && segs[1] != "" && !strings.HasPrefix(segs[1], "$") && isConditionalWriteMethod(req.Method):
+				return cfg.instanceWrite(c, next, resourceType, segs[1])
+			}
+			return next(c)
+		}
+	}
+}
+
+// isConditionalWriteMethod reports whether method updates or deletes a
+// resource.
+func isConditionalWriteMethod(method string) bool {
+	return method == http.MethodPut || method == http.MethodPatch || method == http.MethodDelete
+}
+
+// lock acquires the lock on key, or does nothing when no Locker is set.
+func (cfg ConditionalWriteConfig) lock(ctx context.Context, key string) (func(), *TransactionEntryError) {
+	if cfg.Locker == nil {
+		return func() {}, nil
+	}
+	unlock, err := cfg.Locker.Lock(ctx, key)
+	if err != nil {
+		return nil, entryError(http.StatusInternalServerError, IssueTypeException,
+			fmt.Sprintf("failed to lock %s: %s", key, err.Error()))
+	}
+	return unlock, nil
+}
+
+// instanceWrite serves PUT, PATCH and DELETE on [type]/[id], checking
+// If-Match against the current version before calling the route handler.
+func (cfg ConditionalWriteConfig) instanceWrite(c echo.Context, next echo.HandlerFunc, resourceType, id string) error {
+	ifMatch := c.Request().Header.Get("If-Match")
+	if cfg.Locker == nil && ifMatch == "" {
+		return next(c)
+	}
+	ctx := c.Request().Context()
+	unlock, lockErr := cfg.lock(ctx, resourceType+"/"+id)
+	if lockErr != nil {
+		return writeEntryError(c, lockErr)
+	}
+	defer unlock()
+
+	if ifMatch != "" {
+		if err := checkIfMatch(ctx, cfg.Dispatcher, resourceType, id, ifMatch, dispatchHeaders(c.Request().Header)); err != nil {
+			return writeEntryError(c, err)
+		}
+	}
+	return next(c)
+}
+
+// conditionalCreate serves POST [type] with If-None-Exist.
+func (cfg ConditionalWriteConfig) conditionalCreate(c echo.Context, next echo.HandlerFunc, resourceType, criteria string) error {
+	ctx := c.Request().Context()
+	criteria = strings.TrimPrefix(criteria, "?")
+	unlock, lockErr := cfg.lock(ctx, resourceType+"?"+criteria)
+	if lockErr != nil {
+		return writeEntryError(c, lockErr)
+	}
+	defer unlock()
+
+	header := dispatchHeaders(c.Request().Header)
+	matches, count, err := searchMatches(ctx, cfg.Dispatcher, resourceType, criteria, 2, header)
+	if err != nil {
+		return writeEntryError(c, err)
+	}
+	switch count {
+	case 0:
+		return next(c)
+	case 1:
+		result, dErr := cfg.Dispatcher.Dispatch(ctx, &EntryDispatchRequest{
+			Method: http.MethodGet,
+			URL:    resourceType + "/" + matches[0],
+			Header: header,
+		})
+		if dErr != nil {
+			return writeEntryError(c, entryError(http.StatusInternalServerError, IssueTypeException, dErr.Error()))
+		}
+		if result.StatusCode < 400 {
+			location := resourceType + "/" + matches[0]
+			if vid := resourceVersionID(result.Resource()); vid != "" {
+				location += "/_history/" + vid
+			}
+			result.Header.Set(echo.HeaderLocation, location)
+		}
+		return writeDispatchResult(c, result)
+	default:
+		return writeEntryError(c, entryError(http.StatusPreconditionFailed, IssueTypeDuplicate,
+			fmt.Sprintf("If-None-Exist criteria %q matched %d resources", criteria, count)))
+	}
+}
+
+// conditionalWrite serves PUT, PATCH and DELETE on [type]?[criteria].
+func (cfg ConditionalWriteConfig) conditionalWrite(c echo.Context, resourceType, criteria string) error {
+	req := c.Request()
+	ctx := req.Context()
+	unlock, lockErr := cfg.lock(ctx, resourceType+"?"+criteria)
+	if lockErr != nil {
+		return writeEntryError(c, lockErr)
+	}
+	defer unlock()
+
+	limit := 2
+	if req.Method == http.MethodDelete && cfg.MaxDeleteCount > 1 {
+		limit = cfg.MaxDeleteCount + 1
+	}
+	header := dispatchHeaders(req.Header)
+	matches, count, err := searchMatches(ctx, cfg.Dispatcher, resourceType, criteria, limit, header)
+	if err != nil {
+		return writeEntryError(c, err)
+	}
+
+	if req.Method == http.MethodDelete {
+		return cfg.conditionalDelete(c, resourceType, criteria, matches, count, header)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		b, readErr := io.ReadAll(req.Body)
+		if readErr != nil {
+			return writeEntryError(c, entryError(http.StatusBadRequest, IssueTypeInvalid,
+				"failed to read request body: "+readErr.Error()))
+		}
+		body = b
+	}
+	dispatch := &EntryDispatchRequest{
+		Method:      req.Method,
+		Body:        body,
+		ContentType: req.Header.Get(echo.HeaderContentType),
+		Header:      header,
+	}
+
+	switch {
+	case count > 1:
+		return writeEntryError(c, entryError(http.StatusPreconditionFailed, IssueTypeMultipleMatches,
+			fmt.Sprintf("conditional %s criteria %q matched %d resources", req.Method, criteria, count)))
+	case count == 0 && req.Method == http.MethodPatch:
+		return writeEntryError(c, entryError(http.StatusNotFound, IssueTypeNotFound,
+			fmt.Sprintf("conditional PATCH criteria %q matched no resources", criteria)))
+	case count == 0:
+		// No match: the conditional update becomes a create.
+		dispatch.Method = http.MethodPost
+		dispatch.URL = resourceType
+	default:
+		id := matches[0]
+		if req.Method == http.MethodPut {
+			if bodyID := resourceIDFromBody(body); bodyID != "" && bodyID != id {
+				return writeEntryError(c, entryError(http.StatusBadRequest, IssueTypeInvalid,
+					fmt.Sprintf("resource id %q does not match %s/%s found by the conditional update", bodyID, resourceType, id)))
+			}
+		}
+		unlockResource, lockErr := cfg.lock(ctx, resourceType+"/"+id)
+		if lockErr != nil {
+			return writeEntryError(c, lockErr)
+		}
+		defer unlockResource()
+		if ifMatch := req.Header.Get("If-Match"); ifMatch != "" {
+			if err := checkIfMatch(ctx, cfg.Dispatcher, resourceType, id, ifMatch, header); err != nil {
+				return writeEntryError(c, err)
+			}
+		}
+		dispatch.URL = resourceType + "/" + id
+	}
+
+	result, dErr := cfg.Dispatcher.Dispatch(ctx, dispatch)
+	if dErr != nil {
+		return writeEntryError(c, entryError(http.StatusInternalServerError, IssueTypeException, dErr.Error()))
+	}
+	return writeDispatchResult(c, result)
+}
+
+// conditionalDelete deletes the resources matched by a conditional delete.
+func (cfg ConditionalWriteConfig) conditionalDelete(c echo.Context, resourceType, criteria string, matches []string, count int, header http.Header) error {
+	if count == 0 {
+		return c.NoContent(http.StatusNoContent)
+	}
+	if count > 1 && (cfg.MaxDeleteCount <= 1 || count > cfg.MaxDeleteCount) {
+		msg := fmt.Sprintf("conditional DELETE criteria %q matched %d resources", criteria, count)
+		if cfg.MaxDeleteCount > 1 {
+			msg += fmt.Sprintf("; at most %d may be deleted at once", cfg.MaxDeleteCount)
+		}
+		return writeEntryError(c, entryError(http.StatusPreconditionFailed, IssueTypeMultipleMatches, msg))
+	}
+
+	ctx := c.Request().Context()
+	ifMatch := c.Request().Header.Get("If-Match")
+	if len(matches) == 1 {
+		if err := cfg.deleteOne(ctx, resourceType, matches[0], ifMatch, header); err != nil {
+			return writeEntryError(c, err)
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	// Several matches are deleted in one transaction, as in a transaction
+	// Bundle: a failure part-way leaves every match in place.
+	begin := cfg.BeginTx
+	if begin == nil && db.TxFromContext(ctx) == nil && db.ConnFromContext(ctx) != nil {
+		begin = DefaultBeginTx
+	}
+	txCtx := ctx
+	var tx TxFinisher
+	if begin != nil {
+		var err error
+		if txCtx, tx, err = begin(ctx); err != nil {
+			return writeEntryError(c, entryError(http.StatusInternalServerError, IssueTypeException,
+				"failed to begin transaction: "+err.Error()))
+		}
+	}
+	for _, id := range matches {
+		if err := cfg.deleteOne(txCtx, resourceType, id, ifMatch, header); err != nil {
+			if tx != nil {
+				_ = tx.Rollback(ctx)
+			}
+			return writeEntryError(c, err)
+		}
+	}
+	if tx != nil {
+		if err := tx.Commit(ctx); err != nil {
+			return writeEntryError(c, entryError(http.StatusInternalServerError, IssueTypeException,
+				"failed to commit transaction: "+err.Error()))
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// deleteOne deletes resourceType/id under its lock.
+func (cfg ConditionalWriteConfig) deleteOne(ctx context.Context, resourceType, id, ifMatch string, header http.Header) *TransactionEntryError {
+	unlock, lockErr := cfg.lock(ctx, resourceType+"/"+id)
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+	if ifMatch != "" {
+		if err := checkIfMatch(ctx, cfg.Dispatcher, resourceType, id, ifMatch, header); err != nil {
+			return err
+		}
+	}
+	result, err := cfg.Dispatcher.Dispatch(ctx, &EntryDispatchRequest{
+		Method: http.MethodDelete,
+		URL:    resourceType + "/" + id,
+		Header: header,
+	})
+	if err != nil {
+		return entryError(http.StatusInternalServerError, IssueTypeException, err.Error())
+	}
+	if result.StatusCode >= 400 && result.StatusCode != http.StatusNotFound && result.StatusCode != http.StatusGone {
+		return &TransactionEntryError{Index: -1, Status: result.StatusCode, Outcome: outcomeFromResult(result)}
+	}
+	return nil
+}
+
+// resourceIDFromBody returns the id of the JSON resource in body, if any.
+func resourceIDFromBody(body []byte) string {
+	res := (&EntryDispatchResult{Body: body}).Resource()
+	id, _ := res["id"].(string)
+	return id
+}
+
+// writeEntryError writes err's OperationOutcome with its status.
+func writeEntryError(c echo.Context, err *TransactionEntryError) error {
+	return c.JSON(err.Status, err.Outcome)
+}
+
+// writeDispatchResult copies a dispatched response to the client.
+func writeDispatchResult(c echo.Context, result *EntryDispatchResult) error {
+	header := c.Response().Header()
+	for k, v := range result.Header {
+		header[k] = v
+	}
+	status := result.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if len(result.Body) == 0 {
+		return c.NoContent(status)
+	}
+	contentType := result.Header.Get(echo.HeaderContentType)
+	if contentType == "" {
+		contentType = echo.MIMEApplicationJSON
+	}
+	return c.Blob(status, contentType, result.Body)
+}
@@ -0,0 +1,286 @@
+package fhir
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// recordingLocker is a ResourceLocker that records the keys it locks and
+// whether each lock was released.
+type recordingLocker struct {
+	mu     sync.Mutex
+	locked []string
+	held   map[string]int
+}
+
+func newRecordingLocker() *recordingLocker {
+	return &recordingLocker{held: make(map[string]int)}
+}
+
+func (l *recordingLocker) Lock(_ context.Context, key string) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.locked = append(l.locked, key)
+	l.held[key]++
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.held[key]--
+	}, nil
+}
+
+func (l *recordingLocker) heldCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for _, c := range l.held {
+		n += c
+	}
+	return n
+}
+
+// newConditionalWriteTestServer returns the dispatch test server with two
+// Patients, a1 at version 3 and a2 at version 1, behind
+// ConditionalWriteMiddleware.
+func newConditionalWriteTestServer(maxDelete int) (*echo.Echo, map[string]map[string]interface{}, *recordingLocker) {
+	e, store := newDispatchTestServer()
+	store["Patient/a1"] = map[string]interface{}{
+		"resourceType": "Patient", "id": "a1", "active": true,
+		"meta": map[string]interface{}{"versionId": "3"},
+	}
+	store["Patient/a2"] = map[string]interface{}{
+		"resourceType": "Patient", "id": "a2", "active": true,
+		"meta": map[string]interface{}{"versionId": "1"},
+	}
+	locker := newRecordingLocker()
+	e.Use(ConditionalWriteMiddleware(ConditionalWriteConfig{
+		Dispatcher:     NewEchoEntryDispatcher(e, "/fhir"),
+		BasePath:       "/fhir",
+		Locker:         locker,
+		MaxDeleteCount: maxDelete,
+	}))
+	return e, store, locker
+}
+
+func serveConditional(e *echo.Echo, method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestConditionalWrite_IfNoneExist(t *testing.T) {
+	e, store, _ := newConditionalWriteTestServer(0)
+	body := `{"resourceType": "Patient", "active": true}`
+
+	rec := serveConditional(e, http.MethodPost, "/fhir/Patient", body, map[string]string{"If-None-Exist": "_id=a1"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("one match: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if loc := rec.Header().Get("Location"); loc != "Patient/a1/_history/3" {
+		t.Errorf("Location = %q", loc)
+	}
+	if !strings.Contains(rec.Body.String(), `"id":"a1"`) {
+		t.Errorf("expected the existing Patient, got %s", rec.Body.String())
+	}
+	if len(store) != 2 {
+		t.Errorf("no resource should have been created, store has %d", len(store))
+	}
+
+	rec = serveConditional(e, http.MethodPost, "/fhir/Patient", body, map[string]string{"If-None-Exist": "_id=missing"})
+	if rec.Code != http.StatusCreated || len(store) != 3 {
+		t.Errorf("no match: expected 201 and a new Patient, got %d with %d stored", rec.Code, len(store))
+	}
+
+	rec = serveConditional(e, http.MethodPost, "/fhir/Patient", body, map[string]string{"If-None-Exist": "active=true"})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("multiple matches: expected 412, got %d", rec.Code)
+	}
+}
+
+func TestConditionalWrite_ConditionalUpdate(t *testing.T) {
+	e, store, locker := newConditionalWriteTestServer(0)
+
+	rec := serveConditional(e, http.MethodPut, "/fhir/Patient?_id=a1", `{"resourceType": "Patient", "active": false}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("one match: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if store["Patient/a1"]["active"] != false {
+		t.Errorf("Patient/a1 was not updated: %v", store["Patient/a1"])
+	}
+	if strings.Join(locker.locked, ",") != "Patient?_id=a1,Patient/a1" || locker.heldCount() != 0 {
+		t.Errorf("locks = %v, held = %d", locker.locked, locker.heldCount())
+	}
+
+	rec = serveConditional(e, http.MethodPut, "/fhir/Patient?_id=new", `{"resourceType": "Patient"}`, nil)
+	if rec.Code != http.StatusCreated || len(store) != 3 {
+		t.Errorf("no match: expected 201 and a new Patient, got %d with %d stored", rec.Code, len(store))
+	}
+
+	rec = serveConditional(e, http.MethodPut, "/fhir/Patient?active=true", `{"resourceType": "Patient"}`, nil)
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("multiple matches: expected 412, got %d", rec.Code)
+	}
+
+	rec = serveConditional(e, http.MethodPut, "/fhir/Patient?_id=a2", `{"resourceType": "Patient", "id": "a1"}`, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("id mismatch: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestConditionalWrite_IfMatch(t *testing.T) {
+	e, store, locker := newConditionalWriteTestServer(0)
+	body := `{"resourceType": "Patient", "active": false}`
+
+	rec := serveConditional(e, http.MethodPut, "/fhir/Patient/a1", body, map[string]string{"If-Match": `W/"2"`})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale version: expected 412, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if store["Patient/a1"]["active"] != true {
+		t.Error("a stale If-Match must not update the resource")
+	}
+
+	rec = serveConditional(e, http.MethodPut, "/fhir/Patient/a1", body, map[string]string{"If-Match": `W/"3"`})
+	if rec.Code != http.StatusOK || store["Patient/a1"]["active"] != false {
+		t.Errorf("current version: expected 200 and an update, got %d", rec.Code)
+	}
+
+	rec = serveConditional(e, http.MethodPut, "/fhir/Patient/a1", body, map[string]string{"If-Match": `W/"3"`})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("second save of version 3: expected 412, got %d", rec.Code)
+	}
+
+	rec = serveConditional(e, http.MethodDelete, "/fhir/Patient/a2", "", map[string]string{"If-Match": `W/"5"`})
+	if _, ok := store["Patient/a2"]; rec.Code != http.StatusPreconditionFailed || !ok {
+		t.Errorf("stale delete: expected 412 and no delete, got %d", rec.Code)
+	}
+
+	rec = serveConditional(e, http.MethodPut, "/fhir/Patient?_id=a2", body, map[string]string{"If-Match": `W/"2"`})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("conditional update with stale If-Match: expected 412, got %d", rec.Code)
+	}
+
+	rec = serveConditional(e, http.MethodPut, "/fhir/Patient/a1", body, map[string]string{"If-Match": `"abc"`})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid If-Match: expected 400, got %d", rec.Code)
+	}
+	if locker.heldCount() != 0 {
+		t.Errorf("%d locks were not released", locker.heldCount())
+	}
+}
+
+func TestConditionalWrite_ConditionalDelete(t *testing.T) {
+	e, store, _ := newConditionalWriteTestServer(0)
+
+	rec := serveConditional(e, http.MethodDelete, "/fhir/Patient?active=true", "", nil)
+	if rec.Code != http.StatusPreconditionFailed || len(store) != 2 {
+		t.Errorf("single mode with two matches: expected 412, got %d with %d stored", rec.Code, len(store))
+	}
+
+	rec = serveConditional(e, http.MethodDelete, "/fhir/Patient?_id=a2", "", nil)
+	if _, ok := store["Patient/a2"]; rec.Code != http.StatusNoContent || ok {
+		t.Errorf("one match: expected 204 and a delete, got %d", rec.Code)
+	}
+
+	rec = serveConditional(e, http.MethodDelete, "/fhir/Patient?_id=missing", "", nil)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("no match: expected 204, got %d", rec.Code)
+	}
+}
+
+func TestConditionalWrite_ConditionalDeleteMultiple(t *testing.T) {
+	e, store, _ := newConditionalWriteTestServer(2)
+	store["Patient/a3"] = map[string]interface{}{"resourceType": "Patient", "id": "a3"}
+	rec := serveConditional(e, http.MethodDelete, "/fhir/Patient?active=true", "", nil)
+	if rec.Code != http.StatusPreconditionFailed || len(store) != 3 {
+		t.Errorf("more matches than MaxDeleteCount: expected 412, got %d with %d stored", rec.Code, len(store))
+	}
+
+	e, store, _ = newConditionalWriteTestServer(10)
+	rec = serveConditional(e, http.MethodDelete, "/fhir/Patient?active=true", "", nil)
+	if rec.Code != http.StatusNoContent || len(store) != 0 {
+		t.Errorf("multiple mode: expected 204 and both deleted, got %d with %d stored", rec.Code, len(store))
+	}
+}
+
+func TestConditionalWrite_ConditionalDeleteMultipleIsAtomic(t *testing.T) {
+	newServer := func(tx *fakeTx) *echo.Echo {
+		e, store := newDispatchTestServer()
+		store["Patient/a1"] = map[string]interface{}{
+			"resourceType": "Patient", "id": "a1", "active": true,
+			"meta": map[string]interface{}{"versionId": "3"},
+		}
+		store["Patient/a2"] = map[string]interface{}{
+			"resourceType": "Patient", "id": "a2", "active": true,
+			"meta": map[string]interface{}{"versionId": "1"},
+		}
+		e.Use(ConditionalWriteMiddleware(ConditionalWriteConfig{
+			Dispatcher:     NewEchoEntryDispatcher(e, "/fhir"),
+			BasePath:       "/fhir",
+			MaxDeleteCount: 10,
+			BeginTx: func(ctx context.Context) (context.Context, TxFinisher, error) {
+				return ctx, tx, nil
+			},
+		}))
+		return e
+	}
+
+	tx := &fakeTx{}
+	rec := serveConditional(newServer(tx), http.MethodDelete, "/fhir/Patient?active=true", "", nil)
+	if rec.Code != http.StatusNoContent || !tx.committed || tx.rolledBack {
+		t.Errorf("expected 204 and a committed transaction, got %d (committed %v, rolled back %v)", rec.Code, tx.committed, tx.rolledBack)
+	}
+
+	// Only a1 is at version 3, so the delete of a2 fails its precondition.
+	tx = &fakeTx{}
+	rec = serveConditional(newServer(tx), http.MethodDelete, "/fhir/Patient?active=true", "", map[string]string{"If-Match": `W/"3"`})
+	if rec.Code != http.StatusPreconditionFailed || tx.committed || !tx.rolledBack {
+		t.Errorf("expected 412 and a rolled back transaction, got %d (committed %v, rolled back %v)", rec.Code, tx.committed, tx.rolledBack)
+	}
+}
+
+func TestConditionalWrite_PassesThroughOtherRequests(t *testing.T) {
+	e, _, locker := newConditionalWriteTestServer(0)
+
+	if rec := serveConditional(e, http.MethodGet, "/fhir/Patient/a1", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("read: expected 200, got %d", rec.Code)
+	}
+	if rec := serveConditional(e, http.MethodPost, "/fhir/Patient", `{"resourceType": "Patient"}`, nil); rec.Code != http.StatusCreated {
+		t.Errorf("create: expected 201, got %d", rec.Code)
+	}
+	if len(locker.locked) != 0 {
+		t.Errorf("reads and plain creates should not lock, got %v", locker.locked)
+	}
+}
+
+func TestExecuteBatch_LocksUpdatedResources(t *testing.T) {
+	e, store := newDispatchTestServer()
+	store["Patient/a1"] = map[string]interface{}{"resourceType": "Patient", "id": "a1"}
+	locker := newRecordingLocker()
+	p := NewDispatchingTransactionProcessor(NewEchoEntryDispatcher(e, "/fhir"))
+	p.Locker = locker
+
+	bundle := &TransactionBundle{ResourceType: "Bundle", Type: "batch", Entries: []TransactionEntry{{
+		Resource: map[string]interface{}{"resourceType": "Patient", "id": "a1"},
+		Request:  BundleEntryRequest{Method: "PUT", URL: "Patient/a1"},
+	}}}
+	resp := p.ExecuteBatch(context.Background(), bundle, nil)
+	if resp.Entry[0].Response.Status != "200 OK" {
+		t.Fatalf("status = %s", resp.Entry[0].Response.Status)
+	}
+	if strings.Join(locker.locked, ",") != "Patient/a1" || locker.heldCount() != 0 {
+		t.Errorf("locks = %v, held = %d", locker.locked, locker.heldCount())
+	}
+}
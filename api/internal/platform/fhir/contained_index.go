@@ -0,0 +1,457 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// ContainedIndexStore persists the contained resources of each stored
+// resource so they can be searched by their own search parameters, e.g. a
+// contained Medication inside a MedicationRequest found by Medication?code=.
+type ContainedIndexStore interface {
+	IndexContained(ctx context.Context, containerType, containerID string, contained []map[string]interface{}) error
+	DeleteContained(ctx context.Context, containerType, containerID string) error
+	SearchContained(ctx context.Context, q *ContainedQuery) ([]ContainedSearchResult, int, error)
+}
+
+// ContainedQuery selects contained resources of one type. Every criterion
+// must match. When Containers is set, one result is returned per matching
+// container and only the container fields of the results are filled in.
+type ContainedQuery struct {
+	ResourceType string
+	Criteria     []ContainedCriterion
+	Containers   bool
+	Limit        int
+	Offset       int
+}
+
+// ContainedCriterion is one search parameter applied to contained resources.
+// It matches when any of Values matches any of the field's paths.
+type ContainedCriterion struct {
+	Param    string
+	Field    ContainedFieldConfig
+	Modifier SearchModifier
+	Values   []string
+}
+
+// =========== JSON path predicates ===========
+
+// JSONPath returns the criterion as a PostgreSQL jsonpath predicate for use
+// with the jsonb @@ operator. Paths are evaluated in lax mode, so arrays along
+// the way are unwrapped. Values are encoded as jsonpath string literals, so
+// the predicate can be bound as a query parameter.
+func (cr ContainedCriterion) JSONPath() string {
+	var alts []string
+	for _, path := range strings.Split(cr.Field.JSONPath, "|") {
+		for _, value := range cr.Values {
+			if filter := cr.jsonPathFilter(path, value); filter != "" {
+				alts = append(alts, fmt.Sprintf("exists($.%s ? (%s))", path, filter))
+			}
+		}
+	}
+	if len(alts) == 0 {
+		return "false"
+	}
+	return strings.Join(alts, " || ")
+}
+
+func (cr ContainedCriterion) jsonPathFilter(path, value string) string {
+	switch cr.Field.SearchType {
+	case "token":
+		system, code := splitContainedTokenValue(value)
+		key := tokenCodeKey(path)
+		var conds []string
+		if code != "" {
+			conds = append(conds, fmt.Sprintf("@.%s == %s", key, jsonPathString(code)))
+		}
+		if strings.Contains(value, "|") && system != "" {
+			conds = append(conds, "@.system == "+jsonPathString(system))
+		}
+		return strings.Join(conds, " && ")
+	case "string":
+		if cr.Modifier == ModifierExact {
+			return "@ == " + jsonPathString(value)
+		}
+		pattern := "^" + regexp.QuoteMeta(value)
+		if cr.Modifier == ModifierContains {
+			pattern = regexp.QuoteMeta(value)
+		}
+		return fmt.Sprintf(`@ like_regex %s flag "i"`, jsonPathString(pattern))
+	case "date":
+		parsed := ParseSearchValue(value)
+		v := jsonPathString(parsed.Value)
+		switch parsed.Prefix {
+		case PrefixGt, PrefixSa:
+			return "@ > " + v
+		case PrefixLt, PrefixEb:
+			return "@ < " + v
+		case PrefixGe:
+			return "@ >= " + v
+		case PrefixLe:
+			return "@ <= " + v
+		case PrefixNe:
+			return "!(@ starts with " + v + ")"
+		default:
+			return "@ starts with " + v
+		}
+	case "reference":
+		pattern := "(^|/)" + regexp.QuoteMeta(value) + "$"
+		return "@ like_regex " + jsonPathString(pattern)
+	default:
+		return "@ == " + jsonPathString(value)
+	}
+}
+
+// tokenCodeKey returns the element holding the code of a token path: the
+// value of an Identifier, the code of a Coding.
+func tokenCodeKey(path string) string {
+	if path == "identifier" || strings.HasSuffix(path, ".identifier") {
+		return "value"
+	}
+	return "code"
+}
+
+// jsonPathString encodes s as a jsonpath string literal. jsonpath accepts
+// the JSON escape sequences, so the JSON encoding of s is used.
+func jsonPathString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// =========== In-process matching ===========
+
+// Matches reports whether the criterion matches resource. It applies the
+// same rules as JSONPath.
+func (cr ContainedCriterion) Matches(resource map[string]interface{}) bool {
+	for _, path := range strings.Split(cr.Field.JSONPath, "|") {
+		items := laxPathValues(resource, strings.Split(path, "."))
+		for _, value := range cr.Values {
+			for _, item := range items {
+				if cr.matchValue(path, item, value) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (cr ContainedCriterion) matchValue(path string, item interface{}, value string) bool {
+	if cr.Field.SearchType == "token" {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		system, code := splitContainedTokenValue(value)
+		if code != "" && fmt.Sprint(m[tokenCodeKey(path)]) != code {
+			return false
+		}
+		if strings.Contains(value, "|") && system != "" && fmt.Sprint(m["system"]) != system {
+			return false
+		}
+		return code != "" || system != ""
+	}
+	s, ok := item.(string)
+	if !ok {
+		return false
+	}
+	switch cr.Field.SearchType {
+	case "string":
+		switch cr.Modifier {
+		case ModifierExact:
+			return s == value
+		case ModifierContains:
+			return strings.Contains(strings.ToLower(s), strings.ToLower(value))
+		}
+		return strings.HasPrefix(strings.ToLower(s), strings.ToLower(value))
+	case "date":
+		parsed := ParseSearchValue(value)
+		switch parsed.Prefix {
+		case PrefixGt, PrefixSa:
+			return s > parsed.Value
+		case PrefixLt, PrefixEb:
+			return s < parsed.Value
+		case PrefixGe:
+			return s >= parsed.Value
+		case PrefixLe:
+			return s <= parsed.Value
+		case PrefixNe:
+			return !strings.HasPrefix(s, parsed.Value)
+		}
+		return strings.HasPrefix(s, parsed.Value)
+	case "reference":
+		return s == value || strings.HasSuffix(s, "/"+value)
+	}
+	return s == value
+}
+
+// laxPathValues returns the values at path in v, unwrapping arrays at every
+// step like a lax-mode jsonpath.
+func laxPathValues(v interface{}, path []string) []interface{} {
+	if arr, ok := v.([]interface{}); ok {
+		var out []interface{}
+		for _, item := range arr {
+			out = append(out, laxPathValues(item, path)...)
+		}
+		return out
+	}
+	if len(path) == 0 {
+		return []interface{}{v}
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	next, ok := m[path[0]]
+	if !ok {
+		return nil
+	}
+	return laxPathValues(next, path[1:])
+}
+
+// =========== In-memory store ===========
+
+// InMemoryContainedIndex is a thread-safe in-memory implementation of
+// ContainedIndexStore.
+type InMemoryContainedIndex struct {
+	mu   sync.RWMutex
+	data map[string][]map[string]interface{} // key: "containerType/containerID"
+}
+
+// NewInMemoryContainedIndex creates a new InMemoryContainedIndex.
+func NewInMemoryContainedIndex() *InMemoryContainedIndex {
+	return &InMemoryContainedIndex{data: make(map[string][]map[string]interface{})}
+}
+
+// IndexContained replaces the indexed contained resources of a container.
+func (s *InMemoryContainedIndex) IndexContained(_ context.Context, containerType, containerID string, contained []map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := containerType + "/" + containerID
+	if len(contained) == 0 {
+		delete(s.data, key)
+		return nil
+	}
+	copies := make([]map[string]interface{}, len(contained))
+	for i, c := range contained {
+		copies[i] = deepCopyMap(c)
+	}
+	s.data[key] = copies
+	return nil
+}
+
+// DeleteContained removes the indexed contained resources of a container.
+func (s *InMemoryContainedIndex) DeleteContained(_ context.Context, containerType, containerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, containerType+"/"+containerID)
+	return nil
+}
+
+// SearchContained returns the contained resources matching q, ordered by
+// container and contained id.
+func (s *InMemoryContainedIndex) SearchContained(_ context.Context, q *ContainedQuery) ([]ContainedSearchResult, int, error) {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.data))
+	for k := range s.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var matches []ContainedSearchResult
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		parts := strings.SplitN(key, "/", 2)
+		for i, c := range s.data[key] {
+			if c["resourceType"] != q.ResourceType || !matchesAll(q.Criteria, c) {
+				continue
+			}
+			if q.Containers {
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				matches = append(matches, ContainedSearchResult{ParentType: parts[0], ParentID: parts[1]})
+				continue
+			}
+			id, _ := c["id"].(string)
+			matches = append(matches, ContainedSearchResult{
+				ParentType:     parts[0],
+				ParentID:       parts[1],
+				ContainedIndex: i,
+				ContainedID:    id,
+				ResourceType:   q.ResourceType,
+				Resource:       deepCopyMap(c),
+			})
+		}
+	}
+	s.mu.RUnlock()
+
+	total := len(matches)
+	if q.Offset >= total {
+		return nil, total, nil
+	}
+	matches = matches[q.Offset:]
+	if q.Limit > 0 && len(matches) > q.Limit {
+		matches = matches[:q.Limit]
+	}
+	return matches, total, nil
+}
+
+func matchesAll(criteria []ContainedCriterion, resource map[string]interface{}) bool {
+	for _, cr := range criteria {
+		if !cr.Matches(resource) {
+			return false
+		}
+	}
+	return true
+}
+
+// =========== PostgreSQL store ===========
+
+// ContainedIndexRepository stores contained resources in the shared
+// resource_contained table, one row per contained resource, and searches them
+// with jsonpath predicates backed by a GIN index.
+type ContainedIndexRepository struct{}
+
+// NewContainedIndexRepository creates a new ContainedIndexRepository.
+func NewContainedIndexRepository() *ContainedIndexRepository {
+	return &ContainedIndexRepository{}
+}
+
+func (r *ContainedIndexRepository) conn(ctx context.Context) historyQuerier {
+	if tx := db.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	if c := db.ConnFromContext(ctx); c != nil {
+		return c
+	}
+	return nil
+}
+
+// IndexContained replaces the indexed contained resources of a container.
+// Contained resources without an id are not indexed.
+func (r *ContainedIndexRepository) IndexContained(ctx context.Context, containerType, containerID string, contained []map[string]interface{}) error {
+	if err := r.DeleteContained(ctx, containerType, containerID); err != nil {
+		return err
+	}
+	q := r.conn(ctx)
+	for i, c := range contained {
+		id, _ := c["id"].(string)
+		rt, _ := c["resourceType"].(string)
+		if id == "" || rt == "" {
+			continue
+		}
+		data, err := json.Marshal(c)
+		if err != nil {
+			return fmt.Errorf("marshal contained resource: %w", err)
+		}
+		_, err = q.Exec(ctx, `
+			INSERT INTO resource_contained (container_type, container_id, contained_id, contained_index,
+				resource_type, resource, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, NOW())
+			ON CONFLICT (container_type, container_id, contained_id)
+			DO UPDATE SET contained_index = EXCLUDED.contained_index, resource_type = EXCLUDED.resource_type,
+				resource = EXCLUDED.resource, updated_at = NOW()`,
+			containerType, containerID, strings.TrimPrefix(id, "#"), i, rt, data)
+		if err != nil {
+			return fmt.Errorf("index contained resource: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteContained removes the indexed contained resources of a container.
+func (r *ContainedIndexRepository) DeleteContained(ctx context.Context, containerType, containerID string) error {
+	q := r.conn(ctx)
+	if q == nil {
+		return fmt.Errorf("no database connection in context")
+	}
+	_, err := q.Exec(ctx, `DELETE FROM resource_contained WHERE container_type = $1 AND container_id = $2`,
+		containerType, containerID)
+	if err != nil {
+		return fmt.Errorf("delete contained resources: %w", err)
+	}
+	return nil
+}
+
+// ContainedSearchSQL returns the WHERE clause and arguments selecting the
+// resource_contained rows that match q.
+func ContainedSearchSQL(q *ContainedQuery) (string, []interface{}) {
+	where := "resource_type = $1"
+	args := []interface{}{q.ResourceType}
+	for _, cr := range q.Criteria {
+		args = append(args, cr.JSONPath())
+		where += fmt.Sprintf(" AND resource @@ $%d::jsonpath", len(args))
+	}
+	return where, args
+}
+
+// SearchContained returns the contained resources matching q, ordered by
+// container and contained id.
+func (r *ContainedIndexRepository) SearchContained(ctx context.Context, q *ContainedQuery) ([]ContainedSearchResult, int, error) {
+	conn := r.conn(ctx)
+	if conn == nil {
+		return nil, 0, fmt.Errorf("no database connection in context")
+	}
+	where, args := ContainedSearchSQL(q)
+	n := len(args)
+	pageArgs := append(append([]interface{}{}, args...), q.Limit, q.Offset)
+
+	var total int
+	var query string
+	if q.Containers {
+		if err := conn.QueryRow(ctx, `
+			SELECT COUNT(DISTINCT (container_type, container_id)) FROM resource_contained WHERE `+where,
+			args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("count contained resources: %w", err)
+		}
+		query = fmt.Sprintf(`
+			SELECT DISTINCT container_type, container_id FROM resource_contained WHERE %s
+			ORDER BY container_type, container_id LIMIT $%d OFFSET $%d`, where, n+1, n+2)
+	} else {
+		if err := conn.QueryRow(ctx, `SELECT COUNT(*) FROM resource_contained WHERE `+where,
+			args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("count contained resources: %w", err)
+		}
+		query = fmt.Sprintf(`
+			SELECT container_type, container_id, contained_id, contained_index, resource
+			FROM resource_contained WHERE %s
+			ORDER BY container_type, container_id, contained_id LIMIT $%d OFFSET $%d`, where, n+1, n+2)
+	}
+
+	rows, err := conn.Query(ctx, query, pageArgs...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("search contained resources: %w", err)
+	}
+	defer rows.Close()
+
+	var results []ContainedSearchResult
+	for rows.Next() {
+		var res ContainedSearchResult
+		if q.Containers {
+			if err := rows.Scan(&res.ParentType, &res.ParentID); err != nil {
+				return nil, 0, fmt.Errorf("scan contained resource: %w", err)
+			}
+			results = append(results, res)
+			continue
+		}
+		var data []byte
+		if err := rows.Scan(&res.ParentType, &res.ParentID, &res.ContainedID, &res.ContainedIndex, &data); err != nil {
+			return nil, 0, fmt.Errorf("scan contained resource: %w", err)
+		}
+		if err := json.Unmarshal(data, &res.Resource); err != nil {
+			return nil, 0, fmt.Errorf("unmarshal contained resource: %w", err)
+		}
+		res.ResourceType = q.ResourceType
+		results = append(results, res)
+	}
+	return results, total, rows.Err()
+}
@@ -0,0 +1,167 @@
+package fhir
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func containedMedicationRequest(id, medID, code string) map[string]interface{} {
+	return map[string]interface{}{
+		"resourceType": "MedicationRequest",
+		"id":           id,
+		"contained": []interface{}{
+			map[string]interface{}{
+				"resourceType": "Medication",
+				"id":           medID,
+				"code": map[string]interface{}{
+					"coding": []interface{}{
+						map[string]interface{}{"system": "http://www.nlm.nih.gov/research/umls/rxnorm", "code": code},
+					},
+				},
+				"batch": map[string]interface{}{"lotNumber": "L-42", "expirationDate": "2027-03-01"},
+			},
+		},
+		"medicationReference": map[string]interface{}{"reference": "#" + medID},
+	}
+}
+
+func TestContainedCriterion_JSONPath(t *testing.T) {
+	engine := NewContainedSearchEngine()
+	q, err := engine.BuildContainedQuery("Medication", url.Values{
+		"code":            {"http://www.nlm.nih.gov/research/umls/rxnorm|197361,313782"},
+		"expiration-date": {"ge2027-01-01"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(q.Criteria) != 2 {
+		t.Fatalf("expected 2 criteria, got %d", len(q.Criteria))
+	}
+
+	code := q.Criteria[0].JSONPath()
+	for _, want := range []string{
+		`exists($.code.coding ? (@.code == "197361" && @.system == "http://www.nlm.nih.gov/research/umls/rxnorm"))`,
+		` || exists($.code.coding ? (@.code == "313782"`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("expected %q in %q", want, code)
+		}
+	}
+	if got := q.Criteria[1].JSONPath(); got != `exists($.batch.expirationDate ? (@ >= "2027-01-01"))` {
+		t.Errorf("unexpected date predicate: %q", got)
+	}
+
+	where, args := ContainedSearchSQL(q)
+	if where != "resource_type = $1 AND resource @@ $2::jsonpath AND resource @@ $3::jsonpath" {
+		t.Errorf("unexpected where clause: %q", where)
+	}
+	if len(args) != 3 || args[0] != "Medication" {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestContainedCriterion_JSONPathEscapesValues(t *testing.T) {
+	cr := ContainedCriterion{
+		Field:  ContainedFieldConfig{JSONPath: "name.family", SearchType: "string"},
+		Values: []string{`O"Brien.*`},
+	}
+	got := cr.JSONPath()
+	if got != `exists($.name.family ? (@ like_regex "^O\"Brien\\.\\*" flag "i"))` {
+		t.Errorf("unexpected predicate: %s", got)
+	}
+}
+
+func TestContainedCriterion_Matches(t *testing.T) {
+	med := ExtractContainedResources(containedMedicationRequest("mr1", "med1", "197361"))[0]
+	engine := NewContainedSearchEngine()
+	tests := []struct {
+		param, value string
+		want         bool
+	}{
+		{"code", "197361", true},
+		{"code", "http://www.nlm.nih.gov/research/umls/rxnorm|197361", true},
+		{"code", "http://snomed.info/sct|197361", false},
+		{"code", "999", false},
+		{"lot-number", "L-42", true},
+		{"expiration-date", "lt2027-01-01", false},
+		{"expiration-date", "2027-03", true},
+		{"_id", "med1", true},
+	}
+	for _, tt := range tests {
+		q, err := engine.BuildContainedQuery("Medication", url.Values{tt.param: {tt.value}})
+		if err != nil {
+			t.Fatalf("%s=%s: unexpected error: %v", tt.param, tt.value, err)
+		}
+		if got := q.Criteria[0].Matches(med); got != tt.want {
+			t.Errorf("%s=%s: Matches = %v, want %v", tt.param, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBuildContainedQuery_Errors(t *testing.T) {
+	engine := NewContainedSearchEngine()
+	if _, err := engine.BuildContainedQuery("Medication", url.Values{"unknown": {"x"}}); err == nil {
+		t.Error("expected error for unsupported parameter")
+	}
+	if _, err := engine.BuildContainedQuery("Medication", url.Values{"code:text": {"x"}}); err == nil {
+		t.Error("expected error for unsupported modifier")
+	}
+	q, err := engine.BuildContainedQuery("Medication", url.Values{"_contained": {"true"}, "_count": {"10"}})
+	if err != nil || len(q.Criteria) != 0 {
+		t.Errorf("expected control parameters to be skipped, got %+v (err=%v)", q, err)
+	}
+}
+
+func TestInMemoryContainedIndex_Search(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryContainedIndex()
+	for _, mr := range []map[string]interface{}{
+		containedMedicationRequest("mr1", "med1", "197361"),
+		containedMedicationRequest("mr2", "med1", "197361"),
+		containedMedicationRequest("mr3", "med1", "313782"),
+	} {
+		if err := store.IndexContained(ctx, "MedicationRequest", mr["id"].(string), ExtractContainedResources(mr)); err != nil {
+			t.Fatalf("IndexContained: %v", err)
+		}
+	}
+
+	q, _ := NewContainedSearchEngine().BuildContainedQuery("Medication", url.Values{"code": {"197361"}})
+	q.Limit = 1
+	results, total, err := store.SearchContained(ctx, q)
+	if err != nil {
+		t.Fatalf("SearchContained: %v", err)
+	}
+	if total != 2 || len(results) != 1 {
+		t.Fatalf("expected 1 of 2 results, got %d of %d", len(results), total)
+	}
+	if results[0].ParentType != "MedicationRequest" || results[0].ParentID != "mr1" || results[0].ContainedID != "med1" {
+		t.Errorf("unexpected result: %+v", results[0])
+	}
+
+	// Re-indexing without contained resources removes them.
+	_ = store.IndexContained(ctx, "MedicationRequest", "mr1", nil)
+	q.Limit = 10
+	if _, total, _ = store.SearchContained(ctx, q); total != 1 {
+		t.Errorf("expected 1 result after re-index, got %d", total)
+	}
+}
+
+func TestVersionTracker_ContainedIndex(t *testing.T) {
+	vt := NewVersionTracker(NewHistoryRepository())
+	store := NewInMemoryContainedIndex()
+	vt.SetContainedIndex(store)
+	ctx := context.Background() // no DB: indexing happens before the history write fails
+
+	_ = vt.RecordCreate(ctx, "MedicationRequest", "mr1", containedMedicationRequest("mr1", "med1", "197361"))
+	q := &ContainedQuery{ResourceType: "Medication", Limit: 10}
+	if _, total, _ := store.SearchContained(ctx, q); total != 1 {
+		t.Fatalf("expected contained Medication to be indexed, got %d", total)
+	}
+
+	_ = vt.RecordDelete(ctx, "MedicationRequest", "mr1", 1)
+	if _, total, _ := store.SearchContained(ctx, q); total != 0 {
+		t.Errorf("expected contained Medication to be removed on delete, got %d", total)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 )
 
@@ -42,6 +43,7 @@ type ContainedFieldConfig struct {
 
 // ContainedSearchResult represents a match within contained resources.
 type ContainedSearchResult struct {
+	ParentType     string                 // Type of the containing resource
 	ParentID       string                 // ID of the containing resource
 	ContainedIndex int                    // Index in the contained array
 	ContainedID    string                 // ID of the contained resource (e.g., "#med1")
@@ -69,8 +71,29 @@ func ParseContainedParam(value string) (ContainedSearchMode, error) {
 	}
 }
 
-// ParseContainedTypeParam parses the _containedType query parameter.
-// Value is a comma-separated list of resource types. Empty returns an empty slice.
+// ContainedResultType controls what is returned for contained matches.
+type ContainedResultType string
+
+const (
+	ContainedResultContained ContainedResultType = "contained" // Return the contained resources (default)
+	ContainedResultContainer ContainedResultType = "container" // Return the resources that contain them
+)
+
+// ParseContainedResultType parses the _containedType query parameter.
+// Valid values: "contained" (default) and "container".
+func ParseContainedResultType(value string) (ContainedResultType, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "contained":
+		return ContainedResultContained, nil
+	case "container":
+		return ContainedResultContainer, nil
+	default:
+		return "", fmt.Errorf("invalid _containedType value: %q (must be container or contained)", value)
+	}
+}
+
+// ParseContainedTypeParam parses a comma-separated list of contained resource
+// types, as accepted by ApplyContainedSearch. Empty returns an empty slice.
 func ParseContainedTypeParam(value string) ([]string, error) {
 	value = strings.TrimSpace(value)
 	if value == "" {
@@ -556,19 +579,158 @@ func DefaultContainedSearchConfigs() map[string]*ContainedSearchConfig {
 	}
 }
 
+// DefaultContainedSearchParams returns the search parameters of the resource
+// types most often sent as contained resources. JSONPath may list several
+// paths separated by "|"; a match on any of them matches the parameter.
+func DefaultContainedSearchParams() map[string]map[string]ContainedFieldConfig {
+	name := ContainedFieldConfig{JSONPath: "name.family|name.given|name.text", SearchType: "string"}
+	identifier := ContainedFieldConfig{JSONPath: "identifier", SearchType: "token"}
+	return map[string]map[string]ContainedFieldConfig{
+		"Medication": {
+			"code":            {FHIRPath: "Medication.code", JSONPath: "code.coding", SearchType: "token"},
+			"form":            {FHIRPath: "Medication.form", JSONPath: "form.coding", SearchType: "token"},
+			"ingredient-code": {FHIRPath: "Medication.ingredient.itemCodeableConcept", JSONPath: "ingredient.itemCodeableConcept.coding", SearchType: "token"},
+			"ingredient":      {FHIRPath: "Medication.ingredient.itemReference", JSONPath: "ingredient.itemReference.reference", SearchType: "reference"},
+			"lot-number":      {FHIRPath: "Medication.batch.lotNumber", JSONPath: "batch.lotNumber", SearchType: "code"},
+			"expiration-date": {FHIRPath: "Medication.batch.expirationDate", JSONPath: "batch.expirationDate", SearchType: "date"},
+			"manufacturer":    {FHIRPath: "Medication.manufacturer", JSONPath: "manufacturer.reference", SearchType: "reference"},
+			"status":          {FHIRPath: "Medication.status", JSONPath: "status", SearchType: "code"},
+			"identifier":      identifier,
+		},
+		"Substance": {
+			"code":       {FHIRPath: "Substance.code", JSONPath: "code.coding", SearchType: "token"},
+			"category":   {FHIRPath: "Substance.category", JSONPath: "category.coding", SearchType: "token"},
+			"status":     {FHIRPath: "Substance.status", JSONPath: "status", SearchType: "code"},
+			"identifier": identifier,
+		},
+		"Device": {
+			"device-name":  {FHIRPath: "Device.deviceName.name", JSONPath: "deviceName.name", SearchType: "string"},
+			"type":         {FHIRPath: "Device.type", JSONPath: "type.coding", SearchType: "token"},
+			"manufacturer": {FHIRPath: "Device.manufacturer", JSONPath: "manufacturer", SearchType: "string"},
+			"model":        {FHIRPath: "Device.modelNumber", JSONPath: "modelNumber", SearchType: "string"},
+			"status":       {FHIRPath: "Device.status", JSONPath: "status", SearchType: "code"},
+			"identifier":   identifier,
+		},
+		"Observation": {
+			"code":     {FHIRPath: "Observation.code", JSONPath: "code.coding", SearchType: "token"},
+			"category": {FHIRPath: "Observation.category", JSONPath: "category.coding", SearchType: "token"},
+			"status":   {FHIRPath: "Observation.status", JSONPath: "status", SearchType: "code"},
+			"date":     {FHIRPath: "Observation.effective", JSONPath: "effectiveDateTime|effectivePeriod.start", SearchType: "date"},
+		},
+		"Specimen": {
+			"type":       {FHIRPath: "Specimen.type", JSONPath: "type.coding", SearchType: "token"},
+			"status":     {FHIRPath: "Specimen.status", JSONPath: "status", SearchType: "code"},
+			"identifier": identifier,
+		},
+		"Patient": {
+			"name":       name,
+			"family":     {FHIRPath: "Patient.name.family", JSONPath: "name.family", SearchType: "string"},
+			"given":      {FHIRPath: "Patient.name.given", JSONPath: "name.given", SearchType: "string"},
+			"birthdate":  {FHIRPath: "Patient.birthDate", JSONPath: "birthDate", SearchType: "date"},
+			"gender":     {FHIRPath: "Patient.gender", JSONPath: "gender", SearchType: "code"},
+			"identifier": identifier,
+		},
+		"Practitioner": {
+			"name":       name,
+			"family":     {FHIRPath: "Practitioner.name.family", JSONPath: "name.family", SearchType: "string"},
+			"given":      {FHIRPath: "Practitioner.name.given", JSONPath: "name.given", SearchType: "string"},
+			"identifier": identifier,
+		},
+		"RelatedPerson": {
+			"name":       name,
+			"identifier": identifier,
+		},
+		"Organization": {
+			"name":       {FHIRPath: "Organization.name", JSONPath: "name|alias", SearchType: "string"},
+			"identifier": identifier,
+		},
+		"Location": {
+			"name":       {FHIRPath: "Location.name", JSONPath: "name|alias", SearchType: "string"},
+			"identifier": identifier,
+		},
+	}
+}
+
 // ---------------------------------------------------------------------------
 // ContainedSearchEngine
 // ---------------------------------------------------------------------------
 
 // ContainedSearchEngine manages contained resource search across resource types.
+// Configs describe searches on a container's column; Params lists, per
+// contained resource type, the search parameters answered from the contained
+// resource index (see ContainedSearchMiddleware).
 type ContainedSearchEngine struct {
 	Configs map[string]*ContainedSearchConfig
+	Params  map[string]map[string]ContainedFieldConfig
 }
 
 // NewContainedSearchEngine creates an engine initialized with default configs.
 func NewContainedSearchEngine() *ContainedSearchEngine {
 	return &ContainedSearchEngine{
 		Configs: DefaultContainedSearchConfigs(),
+		Params:  DefaultContainedSearchParams(),
+	}
+}
+
+// RegisterParam adds or replaces a search parameter for a contained resource type.
+func (e *ContainedSearchEngine) RegisterParam(resourceType, name string, field ContainedFieldConfig) {
+	if e.Params[resourceType] == nil {
+		e.Params[resourceType] = make(map[string]ContainedFieldConfig)
+	}
+	e.Params[resourceType][name] = field
+}
+
+// BuildContainedQuery translates the search parameters of a request for
+// resourceType into a ContainedQuery. Control parameters other than _id are
+// skipped; parameters or modifiers that cannot be applied to contained
+// resources are an error, so that no unfiltered results are returned.
+func (e *ContainedSearchEngine) BuildContainedQuery(resourceType string, params url.Values) (*ContainedQuery, error) {
+	fields := e.Params[resourceType]
+	q := &ContainedQuery{ResourceType: resourceType}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		base, modifier := ParseParamModifier(name)
+		if strings.HasPrefix(base, "_") && base != "_id" {
+			continue
+		}
+		field, ok := fields[base]
+		if base == "_id" {
+			field, ok = ContainedFieldConfig{FHIRPath: resourceType + ".id", JSONPath: "id", SearchType: "code"}, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("search parameter %q is not supported for contained %s resources", base, resourceType)
+		}
+		if modifier != "" && !(field.SearchType == "string" && (modifier == ModifierExact || modifier == ModifierContains)) {
+			return nil, fmt.Errorf("modifier %q is not supported for contained resource search", modifier)
+		}
+		for _, raw := range params[name] {
+			var values []string
+			for _, v := range strings.Split(raw, ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, v)
+				}
+			}
+			if len(values) == 0 {
+				continue
+			}
+			q.Criteria = append(q.Criteria, ContainedCriterion{Param: base, Field: field, Modifier: modifier, Values: values})
+		}
+	}
+	return q, nil
+}
+
+// ContainedSearchParams returns the _contained and _containedType search
+// parameters for advertising in the CapabilityStatement.
+func ContainedSearchParams() []SearchParam {
+	return []SearchParam{
+		{Name: "_contained", Type: "token", Documentation: "Whether to search contained resources: false (default), true or both."},
+		{Name: "_containedType", Type: "token", Documentation: "With _contained, whether to return the matching contained resources (contained, default) or their containers (container)."},
 	}
 }
 
@@ -0,0 +1,211 @@
+package fhir
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/pkg/pagination"
+)
+
+// ContainedSearchMiddleware answers type-level searches that carry the
+// _contained parameter from the contained resource index:
+//
+//   - _contained=false (default) leaves the search untouched;
+//   - _contained=true returns only matching contained resources;
+//   - _contained=both appends the contained matches to the regular results.
+//
+// With _containedType=contained (default) the contained resources themselves
+// are returned, with a fullUrl of the form "<container url>#<id>"; with
+// _containedType=container their containers are returned instead.
+//
+// Contained matches are served only to callers the type's own search route
+// serves: the route's role checks and handler run first, for either mode,
+// and a refusal is returned as is. Each match is then read through its
+// container, with the registry's fetchers, which serve the container's read
+// route; matches whose container the caller may not read, or whose read no
+// longer carries the contained resource, are left out.
+func ContainedSearchMiddleware(engine *ContainedSearchEngine, store ContainedIndexStore, registry *IncludeRegistry) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			params := containedSearchValues(c)
+			if params == nil || params.Get("_contained") == "" {
+				return next(c)
+			}
+			resourceType := searchResourceType(c)
+			if resourceType == "" {
+				return next(c)
+			}
+
+			mode, err := ParseContainedParam(params.Get("_contained"))
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorOutcome(err.Error()))
+			}
+			if mode == ContainedModeNone {
+				return next(c)
+			}
+			resultType, err := ParseContainedResultType(params.Get("_containedType"))
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorOutcome(err.Error()))
+			}
+			query, err := engine.BuildContainedQuery(resourceType, params)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorOutcome(err.Error()))
+			}
+			query.Containers = resultType == ContainedResultContainer
+
+			// Run the regular search through the rest of the route, so that
+			// its role checks and handler decide whether the caller may
+			// search the type at all.
+			origWriter := c.Response().Writer
+			rec := &responseRecorder{
+				ResponseWriter: origWriter,
+				body:           &bytes.Buffer{},
+				statusCode:     http.StatusOK,
+			}
+			c.Response().Writer = rec
+			if err := next(c); err != nil {
+				c.Response().Writer = origWriter
+				return err
+			}
+			c.Response().Writer = origWriter
+
+			var bundle Bundle
+			if rec.statusCode < 200 || rec.statusCode >= 300 ||
+				json.Unmarshal(rec.body.Bytes(), &bundle) != nil || bundle.Type != "searchset" {
+				return flushOriginal(origWriter, rec)
+			}
+
+			pg := pagination.FromContext(c)
+			if mode == ContainedModeTrue {
+				query.Limit, query.Offset = pg.Limit, pg.Offset
+				entries, total, err := containedSearchEntries(c, store, registry, query)
+				if err != nil {
+					return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
+				}
+				bundle := NewSearchBundleWithLinks(nil, SearchBundleParams{
+					ServerBaseURL: ServerBaseURLFromRequest(c),
+					BaseURL:       "/fhir/" + resourceType,
+					QueryStr:      c.QueryString(),
+					Count:         pg.Limit,
+					Offset:        pg.Offset,
+					Total:         total,
+				})
+				bundle.Entry = entries
+				return c.JSON(http.StatusOK, bundle)
+			}
+
+			// _contained=both: add the contained matches of the first page to
+			// the regular results and count them in the total.
+			query.Limit = pg.Limit
+			entries, total, err := containedSearchEntries(c, store, registry, query)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
+			}
+			if pg.Offset == 0 {
+				bundle.Entry = append(bundle.Entry, entries...)
+			}
+			if bundle.Total != nil {
+				t := *bundle.Total + total
+				bundle.Total = &t
+			}
+			return c.JSON(rec.statusCode, bundle)
+		}
+	}
+}
+
+// containedSearchEntries runs query and returns the matches as search
+// entries, read through their containers' read routes; the total leaves out
+// the matches of the page that the caller may not read.
+func containedSearchEntries(c echo.Context, store ContainedIndexStore, registry *IncludeRegistry, query *ContainedQuery) ([]BundleEntry, int, error) {
+	ctx := c.Request().Context()
+	results, total, err := store.SearchContained(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	base := ServerBaseURLFromRequest(c)
+	entries := make([]BundleEntry, 0, len(results))
+	for _, res := range results {
+		var resource map[string]interface{}
+		if fetcher := registry.fetcher(res.ParentType); fetcher != nil {
+			resource, err = fetcher(ctx, res.ParentID)
+		}
+		if err != nil || resource == nil {
+			total--
+			continue
+		}
+		containerURL := res.ParentType + "/" + res.ParentID
+		if base != "" {
+			containerURL = base + "/" + containerURL
+		}
+		fullURL := containerURL
+		if !query.Containers {
+			if resource = containedByID(resource, res.ContainedID); resource == nil {
+				total--
+				continue
+			}
+			fullURL += "#" + res.ContainedID
+		}
+		raw, err := json.Marshal(resource)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, BundleEntry{
+			FullURL:  fullURL,
+			Resource: raw,
+			Search:   &BundleSearch{Mode: "match"},
+		})
+	}
+	return entries, total, nil
+}
+
+// containedByID returns the contained resource of container with the given
+// id, or nil.
+func containedByID(container map[string]interface{}, id string) map[string]interface{} {
+	for _, res := range ExtractContainedResources(container) {
+		if res["id"] == id {
+			return res
+		}
+	}
+	return nil
+}
+
+// containedSearchValues returns the search parameters of a type-level search
+// request: the query string of a GET, plus the form body of a POST _search.
+// It returns nil for other requests.
+func containedSearchValues(c echo.Context) url.Values {
+	switch c.Request().Method {
+	case http.MethodGet:
+		return c.QueryParams()
+	case http.MethodPost:
+		if !strings.HasSuffix(c.Path(), "/_search") {
+			return nil
+		}
+		values := url.Values{}
+		for k, v := range c.QueryParams() {
+			values[k] = v
+		}
+		if form, err := c.FormParams(); err == nil {
+			for k, v := range form {
+				values[k] = append(values[k], v...)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// searchResourceType returns the resource type of a type-level search route
+// such as /fhir/Medication or /fhir/Medication/_search, or "".
+func searchResourceType(c echo.Context) string {
+	path := strings.TrimSuffix(strings.TrimSuffix(c.Path(), "/"), "/_search")
+	rt := path[strings.LastIndex(path, "/")+1:]
+	if !IsValidResourceType(rt) {
+		return ""
+	}
+	return rt
+}
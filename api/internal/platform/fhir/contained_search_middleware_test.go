@@ -0,0 +1,173 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/auth"
+)
+
+func containedSearchFixture(t *testing.T) (*InMemoryContainedIndex, *IncludeRegistry) {
+	t.Helper()
+	store := NewInMemoryContainedIndex()
+	reg := NewIncludeRegistry()
+	requests := map[string]map[string]interface{}{
+		"mr1": containedMedicationRequest("mr1", "med1", "197361"),
+		"mr2": containedMedicationRequest("mr2", "med1", "313782"),
+	}
+	for id, mr := range requests {
+		if err := store.IndexContained(context.Background(), "MedicationRequest", id, ExtractContainedResources(mr)); err != nil {
+			t.Fatalf("IndexContained: %v", err)
+		}
+	}
+	reg.RegisterFetcher("MedicationRequest", func(_ context.Context, id string) (map[string]interface{}, error) {
+		return requests[id], nil
+	})
+	return store, reg
+}
+
+func serveContainedSearch(t *testing.T, target string, next echo.HandlerFunc) (*httptest.ResponseRecorder, Bundle) {
+	t.Helper()
+	store, reg := containedSearchFixture(t)
+	mw := ContainedSearchMiddleware(NewContainedSearchEngine(), store, reg)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/fhir/Medication")
+	if err := mw(next)(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var bundle Bundle
+	_ = json.Unmarshal(rec.Body.Bytes(), &bundle)
+	return rec, bundle
+}
+
+// emptySearch stands for a search route with no regular matches.
+func emptySearch(c echo.Context) error {
+	return c.JSONBlob(http.StatusOK, searchBundleJSON())
+}
+
+func TestContainedSearchMiddleware_Passthrough(t *testing.T) {
+	called := false
+	rec, _ := serveContainedSearch(t, "/fhir/Medication?code=197361&_contained=false", func(c echo.Context) error {
+		called = true
+		return c.NoContent(http.StatusNoContent)
+	})
+	if !called || rec.Code != http.StatusNoContent {
+		t.Errorf("expected handler to run, called=%v status=%d", called, rec.Code)
+	}
+}
+
+func TestContainedSearchMiddleware_ContainedOnly(t *testing.T) {
+	called := false
+	rec, bundle := serveContainedSearch(t, "/fhir/Medication?code=197361&_contained=true", func(c echo.Context) error {
+		called = true
+		return emptySearch(c)
+	})
+	if !called {
+		t.Error("expected the search route to run for _contained=true")
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if bundle.Total == nil || *bundle.Total != 1 || len(bundle.Entry) != 1 {
+		t.Fatalf("expected one match, got %s", rec.Body.String())
+	}
+	if got := bundle.Entry[0].FullURL; got != "http://example.com/fhir/MedicationRequest/mr1#med1" {
+		t.Errorf("unexpected fullUrl %q", got)
+	}
+	var med map[string]interface{}
+	_ = json.Unmarshal(bundle.Entry[0].Resource, &med)
+	if med["resourceType"] != "Medication" || med["id"] != "med1" {
+		t.Errorf("expected the contained Medication, got %v", med)
+	}
+}
+
+func TestContainedSearchMiddleware_Containers(t *testing.T) {
+	rec, bundle := serveContainedSearch(t, "/fhir/Medication?code=313782&_contained=true&_containedType=container", emptySearch)
+	if len(bundle.Entry) != 1 {
+		t.Fatalf("expected one container, got %s", rec.Body.String())
+	}
+	var mr map[string]interface{}
+	_ = json.Unmarshal(bundle.Entry[0].Resource, &mr)
+	if mr["resourceType"] != "MedicationRequest" || mr["id"] != "mr2" {
+		t.Errorf("expected container mr2, got %v", mr)
+	}
+}
+
+func TestContainedSearchMiddleware_Both(t *testing.T) {
+	rec, bundle := serveContainedSearch(t, "/fhir/Medication?code=197361&_contained=both", func(c echo.Context) error {
+		return c.JSONBlob(http.StatusOK, searchBundleJSON(map[string]interface{}{"resourceType": "Medication", "id": "m9"}))
+	})
+	if bundle.Total == nil || *bundle.Total != 2 || len(bundle.Entry) != 2 {
+		t.Fatalf("expected regular and contained matches, got %s", rec.Body.String())
+	}
+}
+
+func TestContainedSearchMiddleware_RouteRefusesCaller(t *testing.T) {
+	store, reg := containedSearchFixture(t)
+	mw := ContainedSearchMiddleware(NewContainedSearchEngine(), store, reg)
+	route := auth.RequireRole("admin", "physician", "pharmacist")(emptySearch)
+
+	for _, mode := range []string{"true", "both"} {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/fhir/Medication?code=197361&_contained="+mode, nil)
+		req = req.WithContext(context.WithValue(req.Context(), auth.UserRolesKey, []string{"registrar"}))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/fhir/Medication")
+		err := mw(route)(c)
+		var he *echo.HTTPError
+		if !errors.As(err, &he) || he.Code != http.StatusForbidden {
+			t.Errorf("_contained=%s: expected 403 from the route, got %v", mode, err)
+		}
+		if rec.Body.Len() != 0 {
+			t.Errorf("_contained=%s: expected no contained matches, got %s", mode, rec.Body.String())
+		}
+	}
+}
+
+func TestContainedSearchMiddleware_UnreadableContainer(t *testing.T) {
+	store, _ := containedSearchFixture(t)
+	reg := NewIncludeRegistry()
+	reg.RegisterFetcher("MedicationRequest", func(_ context.Context, id string) (map[string]interface{}, error) {
+		return nil, fmt.Errorf("GET MedicationRequest/%s: 403 Forbidden", id)
+	})
+	mw := ContainedSearchMiddleware(NewContainedSearchEngine(), store, reg)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Medication?code=197361&_contained=true", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/fhir/Medication")
+	if err := mw(emptySearch)(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var bundle Bundle
+	_ = json.Unmarshal(rec.Body.Bytes(), &bundle)
+	if len(bundle.Entry) != 0 || bundle.Total == nil || *bundle.Total != 0 {
+		t.Errorf("expected matches in unreadable containers to be left out, got %s", rec.Body.String())
+	}
+}
+
+func TestContainedSearchMiddleware_InvalidParams(t *testing.T) {
+	for _, target := range []string{
+		"/fhir/Medication?_contained=sometimes",
+		"/fhir/Medication?_contained=true&_containedType=parent",
+		"/fhir/Medication?_contained=true&unknown=1",
+	} {
+		rec, _ := serveContainedSearch(t, target, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", target, rec.Code)
+		}
+	}
+}
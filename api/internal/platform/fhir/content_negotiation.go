@@ -1,7 +1,12 @@
 package fhir
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -12,41 +17,188 @@ const FHIRContentType = "application/fhir+json; charset=utf-8"
 
 // ContentNegotiationMiddleware handles FHIR content negotiation per the FHIR
 // specification. It checks the _format query parameter first (highest priority),
-// then falls back to the Accept header. All successful responses are served as
-// application/fhir+json. XML formats are rejected with 406 Not Acceptable.
+// then falls back to the Accept header, and finally to the format of the
+// request body. Responses are served as application/fhir+json or, when XML is
+// negotiated, converted to application/fhir+xml. XML request bodies are
+// converted to JSON before the handler sees them.
 func ContentNegotiationMiddleware() echo.MiddlewareFunc {
+	codec := DefaultXMLCodec()
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// _format query parameter takes highest priority per FHIR spec.
-			format := c.QueryParam("_format")
-			if format != "" {
-				if isXMLFormat(format) {
-					return c.JSON(http.StatusNotAcceptable, ErrorOutcome("XML format is not supported. Use application/fhir+json."))
+			req := c.Request()
+			xmlBody := req.Body != nil && (req.Method == http.MethodPost || req.Method == http.MethodPut) &&
+				isXMLMediaType(req.Header.Get(echo.HeaderContentType))
+
+			wantXML := false
+			if format := c.QueryParam("_format"); format != "" {
+				// _format query parameter takes highest priority per FHIR spec.
+				switch {
+				case isXMLFormat(format):
+					wantXML = true
+				case !isJSONFormat(format):
+					// Unknown format value: reject.
+					return c.JSON(http.StatusNotAcceptable, ErrorOutcome("Unsupported _format value: "+format))
 				}
-				if isJSONFormat(format) {
-					c.Response().Header().Set(echo.HeaderContentType, FHIRContentType)
-					return next(c)
+			} else if accept := req.Header.Get("Accept"); accept != "" {
+				// Fall back to Accept header.
+				switch negotiateAccept(accept) {
+				case "xml":
+					wantXML = true
+				case "":
+					// Accept header present but no acceptable type found.
+					return c.JSON(http.StatusNotAcceptable, ErrorOutcome("Accept header does not include a supported FHIR content type. Use application/fhir+json or application/fhir+xml."))
 				}
-				// Unknown format value: reject.
-				return c.JSON(http.StatusNotAcceptable, ErrorOutcome("Unsupported _format value: "+format))
+			} else {
+				// No _format and no Accept header: answer in the format of the
+				// request body, defaulting to FHIR JSON.
+				wantXML = xmlBody
 			}
 
-			// Fall back to Accept header.
-			accept := c.Request().Header.Get("Accept")
-			if accept != "" {
-				if negotiateAccept(accept) {
-					c.Response().Header().Set(echo.HeaderContentType, FHIRContentType)
-					return next(c)
+			handler := next
+			if xmlBody {
+				if err := xmlRequestToJSON(codec, req); errors.Is(err, ErrNoXMLLayout) {
+					handler = func(c echo.Context) error {
+						return c.JSON(http.StatusUnsupportedMediaType, operationOutcome("error", "not-supported", "XML is not supported here: "+err.Error()))
+					}
+				} else if err != nil {
+					handler = func(c echo.Context) error {
+						return c.JSON(http.StatusBadRequest, operationOutcome("error", "structure", "Invalid FHIR XML: "+err.Error()))
+					}
 				}
-				// Accept header present but no acceptable type found.
-				return c.JSON(http.StatusNotAcceptable, ErrorOutcome("Accept header does not include a supported FHIR content type. Use application/fhir+json."))
 			}
 
-			// No _format and no Accept header: default to FHIR JSON.
-			c.Response().Header().Set(echo.HeaderContentType, FHIRContentType)
-			return next(c)
+			if !wantXML {
+				c.Response().Header().Set(echo.HeaderContentType, FHIRContentType)
+				return handler(c)
+			}
+			return serveXML(c, codec, handler)
+		}
+	}
+}
+
+// isXMLMediaType reports whether a Content-Type header names an XML type.
+func isXMLMediaType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	switch mediaType {
+	case "application/fhir+xml", "application/xml", "text/xml":
+		return true
+	}
+	return false
+}
+
+// xmlRequestToJSON replaces an XML request body with its FHIR JSON form.
+func xmlRequestToJSON(codec *XMLCodec, req *http.Request) error {
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return nil
+	}
+	converted, err := codec.XMLToJSON(body)
+	if err != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(converted))
+	req.ContentLength = int64(len(converted))
+	req.Header.Set(echo.HeaderContentType, "application/fhir+json")
+	req.Header.Del(echo.HeaderContentLength)
+	return nil
+}
+
+// serveXML runs the handler and converts its FHIR JSON response to XML.
+// Responses that are not JSON (Binary content, for instance) and responses
+// flushed while streaming pass through unchanged. Handler errors are
+// rendered inside the conversion so error outcomes are XML too. A response
+// holding a resource type without an XML layout is replaced by a 406
+// OperationOutcome.
+func serveXML(c echo.Context, codec *XMLCodec, next echo.HandlerFunc) error {
+	rec := &xmlResponseRecorder{ResponseWriter: c.Response().Writer}
+	c.Response().Writer = rec
+	if err := next(c); err != nil {
+		c.Error(err)
+	}
+	c.Response().Writer = rec.ResponseWriter
+	if rec.passthrough {
+		return nil
+	}
+
+	header := c.Response().Header()
+	body := rec.body.Bytes()
+	if len(body) > 0 && body[0] == '{' {
+		out, err := codec.JSONToXML(body)
+		if errors.Is(err, ErrNoXMLLayout) {
+			rec.status = http.StatusNotAcceptable
+			outcome, _ := json.Marshal(operationOutcome("error", "not-supported",
+				"XML is not supported here: "+err.Error()+"; request application/fhir+json"))
+			out, err = codec.JSONToXML(outcome)
+		}
+		if err == nil {
+			body = out
+			header.Set(echo.HeaderContentType, FHIRXMLContentType)
+		}
+	}
+	header.Del(echo.HeaderContentLength)
+	rec.writeHeader()
+	_, err := rec.ResponseWriter.Write(body)
+	return err
+}
+
+// xmlResponseRecorder buffers JSON response bodies, and holds back the
+// status line, so serveXML can convert them and fix the Content-Type.
+type xmlResponseRecorder struct {
+	http.ResponseWriter
+	body        bytes.Buffer
+	status      int
+	decided     bool
+	passthrough bool
+}
+
+func (r *xmlResponseRecorder) WriteHeader(code int) {
+	if r.passthrough {
+		r.ResponseWriter.WriteHeader(code)
+		return
+	}
+	r.status = code
+}
+
+func (r *xmlResponseRecorder) Write(b []byte) (int, error) {
+	if !r.decided {
+		r.decided = true
+		// JSON may also arrive as text/plain from handlers using c.String.
+		ct := r.Header().Get(echo.HeaderContentType)
+		r.passthrough = !strings.Contains(ct, "json") && !strings.HasPrefix(ct, echo.MIMETextPlain)
+		if r.passthrough {
+			r.writeHeader()
 		}
 	}
+	if r.passthrough {
+		return r.ResponseWriter.Write(b)
+	}
+	return r.body.Write(b)
+}
+
+func (r *xmlResponseRecorder) Flush() {
+	if !r.passthrough {
+		r.decided, r.passthrough = true, true
+		r.writeHeader()
+		_, _ = r.ResponseWriter.Write(r.body.Bytes())
+		r.body.Reset()
+	}
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// writeHeader sends the held-back status code, if any.
+func (r *xmlResponseRecorder) writeHeader() {
+	if r.status != 0 {
+		r.ResponseWriter.WriteHeader(r.status)
+		r.status = 0
+	}
 }
 
 // normalizeFormat normalises a format string by lowercasing, trimming
@@ -77,18 +229,36 @@ func isXMLFormat(format string) bool {
 	return false
 }
 
-// negotiateAccept parses the Accept header and returns true if any of the
-// listed media types are JSON-compatible. Returns false if only XML or
-// unsupported types are present.
-func negotiateAccept(accept string) bool {
+// negotiateAccept picks the response format for an Accept header: "json",
+// "xml", or "" when none of the listed media types is supported. Quality
+// values are honoured; among equal qualities the first listed type wins, and
+// wildcards select JSON.
+func negotiateAccept(accept string) string {
+	best, bestQ := "", 0.0
 	for _, part := range strings.Split(accept, ",") {
-		// Strip quality parameters (e.g., ";q=0.9").
-		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
-		mediaType = strings.ToLower(mediaType)
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		q := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		var format string
 		switch mediaType {
-		case "application/fhir+json", "application/json", "json", "*/*":
-			return true
+		case "application/fhir+json", "application/json", "json", "*/*", "application/*":
+			format = "json"
+		case "application/fhir+xml", "application/xml", "text/xml", "xml":
+			format = "xml"
+		default:
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = format, q
 		}
 	}
-	return false
+	return best
 }
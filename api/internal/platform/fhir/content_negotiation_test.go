@@ -1,6 +1,7 @@
 package fhir
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -58,7 +59,7 @@ func TestContentNegotiation_FormatJSON(t *testing.T) {
 	}
 }
 
-func TestContentNegotiation_FormatXMLReturns406(t *testing.T) {
+func TestContentNegotiation_FormatXML(t *testing.T) {
 	formats := []string{"xml", "application/xml", "application/fhir+xml"}
 	for _, format := range formats {
 		t.Run(format, func(t *testing.T) {
@@ -68,21 +69,21 @@ func TestContentNegotiation_FormatXMLReturns406(t *testing.T) {
 			c := e.NewContext(req, rec)
 
 			handler := ContentNegotiationMiddleware()(func(c echo.Context) error {
-				return c.String(http.StatusOK, `{"resourceType":"Patient"}`)
+				return c.String(http.StatusOK, `{"resourceType":"Patient","id":"p1"}`)
 			})
 
 			if err := handler(c); err != nil {
 				t.Fatal(err)
 			}
-			if rec.Code != http.StatusNotAcceptable {
-				t.Errorf("expected 406, got %d", rec.Code)
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected 200, got %d", rec.Code)
 			}
-			body := rec.Body.String()
-			if !strings.Contains(body, "OperationOutcome") {
-				t.Errorf("expected OperationOutcome in body, got: %s", body)
+			if ct := rec.Header().Get("Content-Type"); ct != FHIRXMLContentType {
+				t.Errorf("expected Content-Type %q, got %q", FHIRXMLContentType, ct)
 			}
-			if !strings.Contains(body, "XML format is not supported") {
-				t.Errorf("expected XML not supported message, got: %s", body)
+			body := rec.Body.String()
+			if !strings.Contains(body, `<Patient xmlns="http://hl7.org/fhir"><id value="p1"/></Patient>`) {
+				t.Errorf("expected Patient XML, got: %s", body)
 			}
 		})
 	}
@@ -111,13 +112,58 @@ func TestContentNegotiation_AcceptFHIRJSON(t *testing.T) {
 	}
 }
 
-func TestContentNegotiation_AcceptFHIRXMLReturns406(t *testing.T) {
+func TestContentNegotiation_AcceptFHIRXML(t *testing.T) {
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/fhir/Patient", nil)
 	req.Header.Set("Accept", "application/fhir+xml")
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
+	handler := ContentNegotiationMiddleware()(func(c echo.Context) error {
+		return c.JSON(http.StatusCreated, map[string]interface{}{"resourceType": "Patient", "active": true})
+	})
+
+	if err := handler(c); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("expected 201, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != FHIRXMLContentType {
+		t.Errorf("expected Content-Type %q, got %q", FHIRXMLContentType, ct)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `<active value="true"/>`) {
+		t.Errorf("expected Patient XML, got: %s", body)
+	}
+}
+
+func TestContentNegotiation_AcceptQualityValues(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Patient", nil)
+	req.Header.Set("Accept", "application/fhir+xml;q=0.5, application/fhir+json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	handler := ContentNegotiationMiddleware()(func(c echo.Context) error {
+		return c.String(http.StatusOK, `{"resourceType":"Patient"}`)
+	})
+
+	if err := handler(c); err != nil {
+		t.Fatal(err)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != FHIRContentType {
+		t.Errorf("expected Content-Type %q, got %q", FHIRContentType, ct)
+	}
+}
+
+func TestContentNegotiation_AcceptUnsupportedReturns406(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Patient", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
 	handler := ContentNegotiationMiddleware()(func(c echo.Context) error {
 		return c.String(http.StatusOK, `{"resourceType":"Patient"}`)
 	})
@@ -134,6 +180,128 @@ func TestContentNegotiation_AcceptFHIRXMLReturns406(t *testing.T) {
 	}
 }
 
+func TestContentNegotiation_XMLRequestBody(t *testing.T) {
+	e := echo.New()
+	body := `<Patient xmlns="http://hl7.org/fhir"><active value="true"/><name><family value="Doe"/></name></Patient>`
+	req := httptest.NewRequest(http.MethodPost, "/fhir/Patient", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/fhir+xml")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	var received map[string]interface{}
+	handler := ContentNegotiationMiddleware()(func(c echo.Context) error {
+		if err := json.NewDecoder(c.Request().Body).Decode(&received); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, received)
+	})
+
+	if err := handler(c); err != nil {
+		t.Fatal(err)
+	}
+	if received["active"] != true {
+		t.Errorf("expected active=true in converted body, got %v", received)
+	}
+	names, _ := received["name"].([]interface{})
+	if len(names) != 1 {
+		t.Fatalf("expected name array with one entry, got %v", received["name"])
+	}
+	// No Accept header: the response follows the request format.
+	if ct := rec.Header().Get("Content-Type"); ct != FHIRXMLContentType {
+		t.Errorf("expected Content-Type %q, got %q", FHIRXMLContentType, ct)
+	}
+	if !strings.Contains(rec.Body.String(), `<family value="Doe"/>`) {
+		t.Errorf("expected XML response, got: %s", rec.Body.String())
+	}
+}
+
+func TestContentNegotiation_InvalidXMLRequestBody(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/fhir/Patient", strings.NewReader(`<Patient xmlns="http://hl7.org/fhir"><active value="yes"/></Patient>`))
+	req.Header.Set("Content-Type", "application/fhir+xml")
+	req.Header.Set("Accept", "application/fhir+json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	called := false
+	handler := ContentNegotiationMiddleware()(func(c echo.Context) error {
+		called = true
+		return c.NoContent(http.StatusOK)
+	})
+
+	if err := handler(c); err != nil {
+		t.Fatal(err)
+	}
+	if called {
+		t.Error("handler should not run for an invalid XML body")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "invalid boolean") {
+		t.Errorf("expected parse error in outcome, got: %s", rec.Body.String())
+	}
+}
+
+func TestContentNegotiation_TypeWithoutXMLLayout(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Widget/1?_format=xml", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	handler := ContentNegotiationMiddleware()(func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]interface{}{"resourceType": "Widget", "id": "1"})
+	})
+	if err := handler(c); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("expected 406, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.HasPrefix(body, "<?xml") || !strings.Contains(body, `<code value="not-supported"/>`) {
+		t.Errorf("expected an XML not-supported OperationOutcome, got: %s", body)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/fhir/Widget", strings.NewReader(`<Widget xmlns="http://hl7.org/fhir"><status value="booked"/></Widget>`))
+	req.Header.Set("Content-Type", "application/fhir+xml")
+	req.Header.Set("Accept", "application/fhir+json")
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	called := false
+	handler = ContentNegotiationMiddleware()(func(c echo.Context) error {
+		called = true
+		return c.NoContent(http.StatusCreated)
+	})
+	if err := handler(c); err != nil {
+		t.Fatal(err)
+	}
+	if called || rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415 without running the handler, got %d (handler ran: %v)", rec.Code, called)
+	}
+}
+
+func TestContentNegotiation_XMLPassesThroughNonJSON(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Binary/b1", nil)
+	req.Header.Set("Accept", "application/fhir+xml")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	handler := ContentNegotiationMiddleware()(func(c echo.Context) error {
+		return c.Blob(http.StatusOK, "image/png", []byte{0x89, 'P', 'N', 'G'})
+	})
+
+	if err := handler(c); err != nil {
+		t.Fatal(err)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("expected Content-Type image/png, got %q", ct)
+	}
+	if rec.Body.Len() != 4 {
+		t.Errorf("expected body to pass through, got %d bytes", rec.Body.Len())
+	}
+}
+
 func TestContentNegotiation_FormatTakesPrecedenceOverAccept(t *testing.T) {
 	e := echo.New()
 	// _format=json should win even though Accept says XML
@@ -173,8 +341,11 @@ func TestContentNegotiation_FormatXMLTakesPrecedenceOverAcceptJSON(t *testing.T)
 	if err := handler(c); err != nil {
 		t.Fatal(err)
 	}
-	if rec.Code != http.StatusNotAcceptable {
-		t.Errorf("expected 406, got %d", rec.Code)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != FHIRXMLContentType {
+		t.Errorf("expected Content-Type %q, got %q", FHIRXMLContentType, ct)
 	}
 }
 
@@ -1,7 +1,9 @@
 package fhir
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 
@@ -51,38 +53,23 @@ func normalizeConvertFormat(f string) string {
 // ConvertHandler creates a handler for POST /fhir/$convert.
 //
 // The handler accepts a FHIR resource in the request body and converts between
-// formats based on _inputFormat and _outputFormat query parameters.
-//
-// Because this server only supports JSON:
-//   - If outputFormat is "xml", return 406 Not Acceptable.
-//   - If inputFormat is "xml", return 415 Unsupported Media Type.
-//   - For JSON->JSON, validate the JSON and return the resource (useful for
-//     format normalisation / pretty-printing).
+// formats based on _inputFormat and _outputFormat query parameters. Both JSON
+// and XML are supported in either direction; JSON->JSON and XML->XML are
+// useful for format normalisation and pretty-printing. The body is parsed
+// according to its content, so a body already converted to JSON by the
+// content negotiation middleware is still accepted with _inputFormat=xml.
 func ConvertHandler() echo.HandlerFunc {
+	codec := DefaultXMLCodec()
 	return func(c echo.Context) error {
 		params := parseConvertParams(c)
 
-		// Reject XML output requests.
-		if params.OutputFormat == "xml" {
-			return c.JSON(http.StatusNotAcceptable,
-				operationOutcome("error", "not-supported",
-					"XML output is not supported; this server only supports application/fhir+json"))
-		}
-
-		// Reject XML input.
-		if params.InputFormat == "xml" {
-			return c.JSON(http.StatusUnsupportedMediaType,
-				operationOutcome("error", "not-supported",
-					"XML input is not supported; this server only supports application/fhir+json"))
-		}
-
 		// Reject unknown formats.
-		if params.InputFormat != "json" {
+		if params.InputFormat != "json" && params.InputFormat != "xml" {
 			return c.JSON(http.StatusUnsupportedMediaType,
 				operationOutcome("error", "not-supported",
 					"Unsupported input format: "+params.InputFormat))
 		}
-		if params.OutputFormat != "json" {
+		if params.OutputFormat != "json" && params.OutputFormat != "xml" {
 			return c.JSON(http.StatusNotAcceptable,
 				operationOutcome("error", "not-supported",
 					"Unsupported output format: "+params.OutputFormat))
@@ -94,16 +81,26 @@ func ConvertHandler() echo.HandlerFunc {
 			return c.JSON(http.StatusBadRequest,
 				operationOutcome("error", "structure", "Failed to read request body"))
 		}
+		body = bytes.TrimSpace(body)
 		if len(body) == 0 {
 			return c.JSON(http.StatusBadRequest,
 				operationOutcome("error", "required", "Request body is empty"))
 		}
 
-		// Validate the JSON by parsing it.
+		// Parse the resource from whichever format the body is in.
 		var resource map[string]interface{}
-		if err := json.Unmarshal(body, &resource); err != nil {
-			return c.JSON(http.StatusBadRequest,
-				operationOutcome("error", "structure", "Invalid JSON: "+err.Error()))
+		if body[0] == '<' {
+			if resource, err = codec.Unmarshal(body); err != nil {
+				return c.JSON(http.StatusBadRequest,
+					operationOutcome("error", "structure", "Invalid XML: "+err.Error()))
+			}
+		} else {
+			dec := json.NewDecoder(bytes.NewReader(body))
+			dec.UseNumber()
+			if err := dec.Decode(&resource); err != nil {
+				return c.JSON(http.StatusBadRequest,
+					operationOutcome("error", "structure", "Invalid JSON: "+err.Error()))
+			}
 		}
 
 		// Validate that resourceType is present.
@@ -112,6 +109,19 @@ func ConvertHandler() echo.HandlerFunc {
 				operationOutcome("error", "structure", "Resource must contain a resourceType field"))
 		}
 
+		if params.OutputFormat == "xml" {
+			out, err := codec.Marshal(resource)
+			if errors.Is(err, ErrNoXMLLayout) {
+				return c.JSON(http.StatusNotAcceptable,
+					operationOutcome("error", "not-supported", "Cannot render resource as XML: "+err.Error()))
+			}
+			if err != nil {
+				return c.JSON(http.StatusUnprocessableEntity,
+					operationOutcome("error", "structure", "Cannot render resource as XML: "+err.Error()))
+			}
+			return c.Blob(http.StatusOK, FHIRXMLContentType, out)
+		}
+
 		// Return the normalised JSON resource with the FHIR content type.
 		c.Response().Header().Set(echo.HeaderContentType, FHIRContentType)
 		return c.JSON(http.StatusOK, resource)
@@ -146,9 +146,9 @@ func TestConvertHandler_JSONtoJSON_ExplicitParams(t *testing.T) {
 	}
 }
 
-func TestConvertHandler_XMLOutput_NotAcceptable(t *testing.T) {
+func TestConvertHandler_JSONtoXML(t *testing.T) {
 	e := echo.New()
-	body := `{"resourceType": "Patient", "id": "p-1"}`
+	body := `{"resourceType": "Patient", "id": "p-1", "active": true}`
 
 	req := httptest.NewRequest(http.MethodPost,
 		"/fhir/$convert?_outputFormat=xml", strings.NewReader(body))
@@ -162,16 +162,15 @@ func TestConvertHandler_XMLOutput_NotAcceptable(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if rec.Code != http.StatusNotAcceptable {
-		t.Errorf("expected 406, got %d", rec.Code)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
 	}
-
-	var outcome map[string]interface{}
-	if err := json.Unmarshal(rec.Body.Bytes(), &outcome); err != nil {
-		t.Fatalf("failed to parse response: %v", err)
+	if ct := rec.Header().Get("Content-Type"); ct != FHIRXMLContentType {
+		t.Errorf("expected Content-Type %q, got %q", FHIRXMLContentType, ct)
 	}
-	if outcome["resourceType"] != "OperationOutcome" {
-		t.Errorf("expected OperationOutcome, got %v", outcome["resourceType"])
+	want := `<Patient xmlns="http://hl7.org/fhir"><id value="p-1"/><active value="true"/></Patient>`
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("expected %s in body, got: %s", want, rec.Body.String())
 	}
 }
 
@@ -191,12 +190,15 @@ func TestConvertHandler_XMLOutput_FHIRMime(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if rec.Code != http.StatusNotAcceptable {
-		t.Errorf("expected 406 for FHIR XML MIME type, got %d", rec.Code)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 for FHIR XML MIME type, got %d", rec.Code)
+	}
+	if !strings.HasPrefix(rec.Body.String(), "<?xml") {
+		t.Errorf("expected an XML document, got: %s", rec.Body.String())
 	}
 }
 
-func TestConvertHandler_XMLInput_UnsupportedMedia(t *testing.T) {
+func TestConvertHandler_XMLtoJSON(t *testing.T) {
 	e := echo.New()
 	body := `<Patient xmlns="http://hl7.org/fhir"><id value="p-1"/></Patient>`
 
@@ -212,8 +214,37 @@ func TestConvertHandler_XMLInput_UnsupportedMedia(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if rec.Code != http.StatusUnsupportedMediaType {
-		t.Errorf("expected 415, got %d", rec.Code)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+
+	var resource map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resource); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resource["resourceType"] != "Patient" || resource["id"] != "p-1" {
+		t.Errorf("unexpected resource: %v", resource)
+	}
+}
+
+func TestConvertHandler_InvalidXML(t *testing.T) {
+	e := echo.New()
+	body := `<Patient xmlns="http://hl7.org/fhir"><id value="p-1"></Patient>`
+
+	req := httptest.NewRequest(http.MethodPost,
+		"/fhir/$convert?_inputFormat=xml", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/fhir+xml")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	handler := ConvertHandler()
+	err := handler(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
 	}
 
 	var outcome map[string]interface{}
@@ -778,6 +778,10 @@ type MeasureEvaluator struct {
 	reports   map[string]*MeasureReport
 	// measureByID maps measure ID -> URL for handler lookups.
 	measureByID map[string]string
+	// elm evaluates measures whose libraries are ELM libraries; resources
+	// is the store that subjects are evaluated against.
+	elm       *ELMEngine
+	resources ELMResourceSearcher
 }
 
 // NewMeasureEvaluator creates a MeasureEvaluator with built-in quality measures.
@@ -826,7 +830,15 @@ func (e *MeasureEvaluator) EvaluateIndividual(
 	patientID, _ := patient["id"].(string)
 	subjectRef := "Patient/" + patientID
 
-	reportGroups := e.evaluateGroups(ctx, measure, patient, resources, period)
+	lib, err := e.measureLibrary(ctx, measure)
+	if err != nil {
+		return nil, err
+	}
+	data := NewBundleDataSource(PatientBundle{Patient: patient, Resources: resources})
+	reportGroups, err := e.evaluateGroups(ctx, measure, lib, patientID, data, patient, resources, period)
+	if err != nil {
+		return nil, err
+	}
 
 	report := &MeasureReport{
 		ID:      uuid.New().String(),
@@ -859,6 +871,11 @@ func (e *MeasureEvaluator) EvaluatePopulation(
 		return nil, fmt.Errorf("measure not found: %s", measureURL)
 	}
 
+	lib, err := e.measureLibrary(ctx, measure)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize aggregate population counts.
 	type popAccumulator struct {
 		count    int
@@ -882,9 +899,12 @@ func (e *MeasureEvaluator) EvaluatePopulation(
 
 	// Evaluate each patient.
 	for _, pb := range patients {
-		patientGroups := e.evaluateGroups(ctx, measure, pb.Patient, pb.Resources, period)
 		patientID, _ := pb.Patient["id"].(string)
 		patientRef := "Patient/" + patientID
+		patientGroups, err := e.evaluateGroups(ctx, measure, lib, patientID, NewBundleDataSource(pb), pb.Patient, pb.Resources, period)
+		if err != nil {
+			return nil, err
+		}
 
 		for gi, grp := range patientGroups {
 			if gi >= len(groupAccs) {
@@ -957,21 +977,37 @@ func (e *MeasureEvaluator) EvaluatePopulation(
 }
 
 // evaluateGroups evaluates all population criteria for a single patient.
+// Measures with an ELM library (lib) take their criteria from its
+// definitions, evaluated against data; others use the built-in and legacy
+// expressions over patient and resources.
 func (e *MeasureEvaluator) evaluateGroups(
 	ctx context.Context,
 	measure *Measure,
+	lib *ELMLibrary,
+	patientID string,
+	data ELMDataSource,
 	patient map[string]interface{},
 	resources map[string][]map[string]interface{},
 	period MeasurePeriod,
-) []MeasureReportGroup {
+) ([]MeasureReportGroup, error) {
 	var groups []MeasureReportGroup
 
+	criteria := e.elmCriteria(ctx, lib, patientID, data, period)
+
 	for _, grp := range measure.Group {
 		rg := MeasureReportGroup{Code: grp.Code}
 		popResults := make(map[string]bool)
 
 		// First pass: evaluate each population expression.
 		for _, pop := range grp.Population {
+			if criteria != nil {
+				result, err := criteria(pop.Expression)
+				if err != nil {
+					return nil, err
+				}
+				popResults[pop.Code] = result
+				continue
+			}
 			result := e.evaluatePopulationExpression(ctx, pop.Expression, patient, resources, period)
 			popResults[pop.Code] = result
 		}
@@ -1023,7 +1059,7 @@ func (e *MeasureEvaluator) evaluateGroups(
 		groups = append(groups, rg)
 	}
 
-	return groups
+	return groups, nil
 }
 
 // evaluatePopulationExpression evaluates a named CQL expression that maps to
@@ -1566,6 +1602,16 @@ func (h *MeasureHandler) RegisterRoutes(fhirGroup *echo.Group) {
 	fhirGroup.GET("/MeasureReport/:id", h.GetReport)
 	fhirGroup.GET("/Library", h.ListLibraries)
 	fhirGroup.POST("/Library", h.CreateLibrary)
+	fhirGroup.POST("/Library/$evaluate", h.EvaluateLibrary)
+	fhirGroup.POST("/Library/:id/$evaluate", h.EvaluateLibrary)
+}
+
+// RegisterResources registers the Measure, MeasureReport and Library
+// routes that read and search them with r.
+func (h *MeasureHandler) RegisterResources(r *ResourceRegistry) {
+	r.RegisterInteractions("Measure", "read", "search-type")
+	r.RegisterInteractions("MeasureReport", "read", "search-type")
+	r.RegisterInteractions("Library", "search-type")
 }
 
 // ListMeasures returns all registered measures as a FHIR Bundle.
@@ -1665,6 +1711,16 @@ func (h *MeasureHandler) EvaluateMeasure(c echo.Context) error {
 		reportType = "individual"
 	}
 
+	// A subject without a body is evaluated against the resource store.
+	if subject := c.QueryParam("subject"); subject != "" && c.Request().ContentLength <= 0 {
+		patientID := strings.TrimPrefix(subject, "Patient/")
+		report, err := h.evaluator.EvaluateSubject(c.Request().Context(), measureURL, patientID, period)
+		if err != nil {
+			return c.JSON(http.StatusUnprocessableEntity, elmErrorOutcome("", err))
+		}
+		return c.JSON(http.StatusOK, report.ToFHIR())
+	}
+
 	// Parse the request body as a FHIR Bundle.
 	var body map[string]interface{}
 	if err := json.NewDecoder(c.Request().Body).Decode(&body); err != nil {
@@ -1678,7 +1734,7 @@ func (h *MeasureHandler) EvaluateMeasure(c echo.Context) error {
 	if reportType == "summary" || reportType == "subject-list" || len(bundles) > 1 {
 		report, err := h.evaluator.EvaluatePopulation(ctx, measureURL, bundles, period)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
+			return c.JSON(http.StatusInternalServerError, elmErrorOutcome("", err))
 		}
 		if reportType == "subject-list" {
 			report.Type = "subject-list"
@@ -1693,7 +1749,7 @@ func (h *MeasureHandler) EvaluateMeasure(c echo.Context) error {
 	pb := bundles[0]
 	report, err := h.evaluator.EvaluateIndividual(ctx, measureURL, pb.Patient, pb.Resources, period)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
+		return c.JSON(http.StatusInternalServerError, elmErrorOutcome("", err))
 	}
 	return c.JSON(http.StatusOK, report.ToFHIR())
 }
@@ -1767,6 +1823,16 @@ func (h *MeasureHandler) CreateLibrary(c echo.Context) error {
 	}
 
 	lib := parseLibraryFromFHIR(body)
+	if h.evaluator.elm != nil && libraryHasContent(body, ELMContentType) {
+		elm, err := ParseELMLibraryResource(body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorOutcome(err.Error()))
+		}
+		if lib.Name == "" {
+			lib.Name, lib.Version = elm.Name, elm.Version
+		}
+		h.evaluator.elm.Libraries().RegisterTenant(c.Request().Context(), elm)
+	}
 	h.evaluator.RegisterLibrary(lib)
 
 	return c.JSON(http.StatusCreated, cqlLibraryToFHIR(lib))
@@ -1819,6 +1885,19 @@ func parseLibraryFromFHIR(body map[string]interface{}) *CQLLibrary {
 	return lib
 }
 
+// libraryHasContent reports whether a FHIR Library has content of the
+// given type.
+func libraryHasContent(body map[string]interface{}, contentType string) bool {
+	contents, _ := body["content"].([]interface{})
+	for _, c := range contents {
+		contentMap, _ := c.(map[string]interface{})
+		if ct, _ := contentMap["contentType"].(string); ct == contentType {
+			return true
+		}
+	}
+	return false
+}
+
 // parseCQLContent does a basic parse of CQL text to extract define statements.
 func parseCQLContent(lib *CQLLibrary, content string) {
 	lines := strings.Split(content, "\n")
@@ -1863,6 +1942,13 @@ func parseMeasureFromFHIR(body map[string]interface{}) *Measure {
 		}
 	}
 
+	libs, _ := body["library"].([]interface{})
+	for _, l := range libs {
+		if canonical, ok := l.(string); ok && canonical != "" {
+			m.Library = append(m.Library, canonical)
+		}
+	}
+
 	// Parse groups.
 	groups, _ := body["group"].([]interface{})
 	for _, g := range groups {
@@ -0,0 +1,437 @@
+package fhir
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// ELMContentType is the content type of ELM libraries in JSON form, as
+// carried in Library.content.
+const ELMContentType = "application/elm+json"
+
+// ErrELMLibraryNotFound is returned when a library, or a library it
+// includes, cannot be found.
+var ErrELMLibraryNotFound = errors.New("ELM library not found")
+
+// ELMLibrary is a CQL library compiled to ELM. Expressions stay in their
+// JSON form and are interpreted by ELMEngine.
+type ELMLibrary struct {
+	Name    string
+	Version string
+	// URL is the canonical URL of the Library resource the library was
+	// loaded from, if any.
+	URL      string
+	Includes []ELMInclude
+
+	parameters  map[string]map[string]interface{}
+	codeSystems map[string]map[string]interface{}
+	valueSets   map[string]map[string]interface{}
+	codes       map[string]map[string]interface{}
+	concepts    map[string]map[string]interface{}
+	expressions map[string]map[string]interface{}
+	functions   map[string][]map[string]interface{}
+	// statements lists the names of the expression definitions in library
+	// order.
+	statements []string
+}
+
+// ELMInclude is a library included by another under a local alias.
+type ELMInclude struct {
+	LocalIdentifier string
+	Path            string
+	Version         string
+}
+
+// ParseELMLibrary parses a library in ELM JSON form.
+func ParseELMLibrary(data []byte) (*ELMLibrary, error) {
+	var doc struct {
+		Library map[string]interface{} `json:"library"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid ELM: %w", err)
+	}
+	if doc.Library == nil {
+		return nil, errors.New("invalid ELM: no library")
+	}
+	l := doc.Library
+	ident := elmNode(l, "identifier")
+	lib := &ELMLibrary{
+		Name:        elmString(ident, "id"),
+		Version:     elmString(ident, "version"),
+		parameters:  elmDefs(l, "parameters"),
+		codeSystems: elmDefs(l, "codeSystems"),
+		valueSets:   elmDefs(l, "valueSets"),
+		codes:       elmDefs(l, "codes"),
+		concepts:    elmDefs(l, "concepts"),
+		expressions: make(map[string]map[string]interface{}),
+		functions:   make(map[string][]map[string]interface{}),
+	}
+	if lib.Name == "" {
+		return nil, errors.New("invalid ELM: library has no identifier")
+	}
+	for _, inc := range elmNodes(elmNode(l, "includes"), "def") {
+		lib.Includes = append(lib.Includes, ELMInclude{
+			LocalIdentifier: elmString(inc, "localIdentifier"),
+			Path:            elmString(inc, "path"),
+			Version:         elmString(inc, "version"),
+		})
+	}
+	for _, def := range elmNodes(elmNode(l, "statements"), "def") {
+		name := elmString(def, "name")
+		if elmString(def, "type") == "FunctionDef" {
+			lib.functions[name] = append(lib.functions[name], def)
+			continue
+		}
+		lib.expressions[name] = def
+		lib.statements = append(lib.statements, name)
+	}
+	return lib, nil
+}
+
+// ParseELMLibraryResource parses the ELM content of a FHIR Library
+// resource. The content data is base64 as FHIR requires, though plain JSON
+// is accepted too.
+func ParseELMLibraryResource(res map[string]interface{}) (*ELMLibrary, error) {
+	contents, _ := res["content"].([]interface{})
+	for _, c := range contents {
+		content, _ := c.(map[string]interface{})
+		if ct, _ := content["contentType"].(string); ct != ELMContentType {
+			continue
+		}
+		data, _ := content["data"].(string)
+		raw := []byte(data)
+		if !strings.HasPrefix(strings.TrimSpace(data), "{") {
+			decoded, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ELM content: %w", err)
+			}
+			raw = decoded
+		}
+		lib, err := ParseELMLibrary(raw)
+		if err != nil {
+			return nil, err
+		}
+		lib.URL, _ = res["url"].(string)
+		return lib, nil
+	}
+	return nil, fmt.Errorf("Library has no %s content", ELMContentType)
+}
+
+// Statements returns the names of the library's expression definitions in
+// the order they are defined.
+func (l *ELMLibrary) Statements() []string {
+	return append([]string(nil), l.statements...)
+}
+
+// Parameters returns the names of the library's parameters.
+func (l *ELMLibrary) Parameters() []string {
+	names := make([]string, 0, len(l.parameters))
+	for name := range l.parameters {
+		names = append(names, name)
+	}
+	return names
+}
+
+// include returns the include with the given local alias.
+func (l *ELMLibrary) include(alias string) (ELMInclude, bool) {
+	for _, inc := range l.Includes {
+		if inc.LocalIdentifier == alias {
+			return inc, true
+		}
+	}
+	return ELMInclude{}, false
+}
+
+// ELMResourceSearcher searches stored resources, for libraries and for
+// retrieves; ResourceRegistry is one.
+type ELMResourceSearcher interface {
+	Search(ctx context.Context, resourceType string, params url.Values) ([]map[string]interface{}, error)
+}
+
+// ELMLibraryManager holds the ELM libraries that can be evaluated or
+// included: those registered with Register, which every tenant shares,
+// those registered for a single tenant with RegisterTenant, and those
+// loaded from the stored Library resources of a tenant, when a store is
+// set. A tenant's libraries take precedence over shared ones of the same
+// name or URL, and no tenant sees another's.
+//
+// The libraries loaded for a tenant are kept until they are older than the
+// refresh interval, so that every replica sees Library resources written
+// through the others; the writes a replica makes itself drop them at once
+// (see OnResourceEvent).
+type ELMLibraryManager struct {
+	mu      sync.RWMutex
+	shared  *elmLibrarySet
+	tenants map[string]*tenantELMLibraries
+	store   ELMResourceSearcher
+	refresh time.Duration
+}
+
+// DefaultELMLibraryRefresh is how long an ELMLibraryManager keeps the
+// libraries it loaded from a tenant's Library resources.
+const DefaultELMLibraryRefresh = time.Minute
+
+// tenantELMLibraries are the libraries of one tenant: those registered for
+// it and those loaded from its Library resources at loaded.
+type tenantELMLibraries struct {
+	registered *elmLibrarySet
+	cached     *elmLibrarySet
+	loaded     time.Time
+}
+
+// elmLibrarySet indexes libraries by name and by canonical URL, each with
+// and without a |version suffix; the unversioned keys hold the library
+// added last.
+type elmLibrarySet struct {
+	libs  map[string]*ELMLibrary
+	byURL map[string]*ELMLibrary
+}
+
+func newELMLibrarySet() *elmLibrarySet {
+	return &elmLibrarySet{
+		libs:  make(map[string]*ELMLibrary),
+		byURL: make(map[string]*ELMLibrary),
+	}
+}
+
+func (s *elmLibrarySet) add(lib *ELMLibrary) {
+	s.libs[lib.Name+"|"+lib.Version] = lib
+	s.libs[lib.Name] = lib
+	if lib.URL != "" {
+		s.byURL[lib.URL] = lib
+		s.byURL[lib.URL+"|"+lib.Version] = lib
+	}
+}
+
+// NewELMLibraryManager creates an empty library manager.
+func NewELMLibraryManager() *ELMLibraryManager {
+	return &ELMLibraryManager{
+		shared:  newELMLibrarySet(),
+		tenants: make(map[string]*tenantELMLibraries),
+		refresh: DefaultELMLibraryRefresh,
+	}
+}
+
+// SetStore enables loading libraries from stored Library resources.
+func (m *ELMLibraryManager) SetStore(store ELMResourceSearcher) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = store
+}
+
+// SetRefresh sets how long libraries loaded from Library resources are
+// kept (DefaultELMLibraryRefresh by default).
+func (m *ELMLibraryManager) SetRefresh(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refresh = d
+}
+
+// Register adds a library shared by every tenant, replacing any with the
+// same name and version.
+func (m *ELMLibraryManager) Register(lib *ELMLibrary) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shared.add(lib)
+}
+
+// RegisterTenant adds a library for the tenant of ctx only, replacing any
+// of the tenant's with the same name and version.
+func (m *ELMLibraryManager) RegisterTenant(ctx context.Context, lib *ELMLibrary) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tenant(db.TenantFromContext(ctx)).registered.add(lib)
+}
+
+// tenant returns the entry of tenantID, creating it if needed, with the
+// loaded libraries dropped once they are older than the refresh interval.
+// The caller holds m.mu for writing.
+func (m *ELMLibraryManager) tenant(tenantID string) *tenantELMLibraries {
+	t, ok := m.tenants[tenantID]
+	if !ok {
+		t = &tenantELMLibraries{registered: newELMLibrarySet(), cached: newELMLibrarySet()}
+		m.tenants[tenantID] = t
+	}
+	if !t.loaded.IsZero() && time.Since(t.loaded) >= m.refresh {
+		t.cached = newELMLibrarySet()
+		t.loaded = time.Time{}
+	}
+	return t
+}
+
+// lookup returns the library under key in the libraries of the tenant of
+// ctx, or else in the shared ones, with the store to load it from when
+// there is none.
+func (m *ELMLibraryManager) lookup(ctx context.Context, key string, index func(*elmLibrarySet) map[string]*ELMLibrary) (*ELMLibrary, ELMResourceSearcher) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.tenant(db.TenantFromContext(ctx))
+	for _, set := range []*elmLibrarySet{t.registered, t.cached, m.shared} {
+		if lib, ok := index(set)[key]; ok {
+			return lib, nil
+		}
+	}
+	return nil, m.store
+}
+
+func elmLibrariesByName(s *elmLibrarySet) map[string]*ELMLibrary { return s.libs }
+func elmLibrariesByURL(s *elmLibrarySet) map[string]*ELMLibrary  { return s.byURL }
+
+// OnResourceEvent drops the libraries loaded for the tenant of ctx when a
+// Library resource is written through the VersionTracker, so that an
+// updated or deleted library is not evaluated from the cache. Register the
+// manager with VersionTracker.AddListener.
+func (m *ELMLibraryManager) OnResourceEvent(ctx context.Context, event ResourceEvent) {
+	if event.ResourceType != "Library" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.tenants[db.TenantFromContext(ctx)]; ok {
+		t.cached = newELMLibrarySet()
+		t.loaded = time.Time{}
+	}
+}
+
+// Library returns the library with the given name and version, or the
+// latest registered version if version is empty.
+func (m *ELMLibraryManager) Library(ctx context.Context, name, version string) (*ELMLibrary, error) {
+	key := name
+	if version != "" {
+		key += "|" + version
+	}
+	lib, store := m.lookup(ctx, key, elmLibrariesByName)
+	if lib != nil {
+		return lib, nil
+	}
+	if store != nil {
+		params := url.Values{"name": {name}}
+		if version != "" {
+			params.Set("version", version)
+		}
+		if lib, err := m.load(ctx, store, params, func(res map[string]interface{}) bool {
+			return cqlGetString(res, "name") == name && (version == "" || cqlGetString(res, "version") == version)
+		}); lib != nil || err != nil {
+			return lib, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrELMLibraryNotFound, key)
+}
+
+// LibraryByURL returns the library with the given canonical URL, which may
+// carry a |version suffix.
+func (m *ELMLibraryManager) LibraryByURL(ctx context.Context, canonical string) (*ELMLibrary, error) {
+	lib, store := m.lookup(ctx, canonical, elmLibrariesByURL)
+	if lib != nil {
+		return lib, nil
+	}
+	if store != nil {
+		u, version, _ := strings.Cut(canonical, "|")
+		params := url.Values{"url": {u}}
+		if version != "" {
+			params.Set("version", version)
+		}
+		if lib, err := m.load(ctx, store, params, func(res map[string]interface{}) bool {
+			return cqlGetString(res, "url") == u && (version == "" || cqlGetString(res, "version") == version)
+		}); lib != nil || err != nil {
+			return lib, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrELMLibraryNotFound, canonical)
+}
+
+// LibraryByID loads the library in the stored Library resource with the
+// given id.
+func (m *ELMLibraryManager) LibraryByID(ctx context.Context, id string) (*ELMLibrary, error) {
+	m.mu.RLock()
+	store := m.store
+	m.mu.RUnlock()
+	if store != nil {
+		if lib, err := m.load(ctx, store, url.Values{"_id": {id}}, func(res map[string]interface{}) bool {
+			return cqlGetString(res, "id") == id
+		}); lib != nil || err != nil {
+			return lib, err
+		}
+	}
+	return nil, fmt.Errorf("%w: Library/%s", ErrELMLibraryNotFound, id)
+}
+
+// load searches the store for a Library with ELM content and keeps it for
+// the tenant of ctx. Stores may ignore search parameters they do not
+// support, so the matches are checked with match.
+func (m *ELMLibraryManager) load(ctx context.Context, store ELMResourceSearcher, params url.Values, match func(map[string]interface{}) bool) (*ELMLibrary, error) {
+	matches, err := store.Search(ctx, "Library", params)
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range matches {
+		if !match(res) {
+			continue
+		}
+		lib, err := ParseELMLibraryResource(res)
+		if err != nil {
+			return nil, err
+		}
+		m.mu.Lock()
+		t := m.tenant(db.TenantFromContext(ctx))
+		if t.loaded.IsZero() {
+			t.loaded = time.Now()
+		}
+		t.cached.add(lib)
+		m.mu.Unlock()
+		return lib, nil
+	}
+	return nil, nil
+}
+
+// ---------------------------------------------------------------------------
+// ELM node helpers
+// ---------------------------------------------------------------------------
+
+// elmNode returns the child node under key.
+func elmNode(n map[string]interface{}, key string) map[string]interface{} {
+	child, _ := n[key].(map[string]interface{})
+	return child
+}
+
+// elmNodes returns the nodes under key, which may hold one node or an
+// array of them.
+func elmNodes(n map[string]interface{}, key string) []map[string]interface{} {
+	switch v := n[key].(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{v}
+	case []interface{}:
+		out := make([]map[string]interface{}, 0, len(v))
+		for _, item := range v {
+			if child, ok := item.(map[string]interface{}); ok {
+				out = append(out, child)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// elmString returns the string under key.
+func elmString(n map[string]interface{}, key string) string {
+	s, _ := n[key].(string)
+	return s
+}
+
+// elmDefs indexes the definitions of a library section by name.
+func elmDefs(l map[string]interface{}, section string) map[string]map[string]interface{} {
+	defs := make(map[string]map[string]interface{})
+	for _, def := range elmNodes(elmNode(l, section), "def") {
+		defs[elmString(def, "name")] = def
+	}
+	return defs
+}
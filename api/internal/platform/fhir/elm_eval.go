@@ -0,0 +1,2411 @@
+package fhir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ELMEngine evaluates ELM libraries against patient data. Libraries and
+// the libraries they include come from the library manager; value sets are
+// expanded by the terminology service.
+type ELMEngine struct {
+	libraries   *ELMLibraryManager
+	terminology ValueSetExpander
+	maxDepth    int
+}
+
+// DefaultELMMaxDepth is how deeply an ELMEngine lets function calls nest
+// before it fails the evaluation with ErrELMDepthExceeded.
+const DefaultELMMaxDepth = 100
+
+// ErrELMDepthExceeded is returned when function calls nest deeper than the
+// engine allows, as a function that calls itself without end does.
+var ErrELMDepthExceeded = errors.New("ELM: function calls nested too deeply")
+
+// NewELMEngine creates an engine over a library manager.
+func NewELMEngine(libraries *ELMLibraryManager) *ELMEngine {
+	return &ELMEngine{libraries: libraries}
+}
+
+// SetTerminology sets the service that expands value sets for retrieves
+// and terminology operators. Without it, value set references fail.
+func (e *ELMEngine) SetTerminology(terminology ValueSetExpander) {
+	e.terminology = terminology
+}
+
+// SetMaxDepth sets how deeply function calls may nest; zero means
+// DefaultELMMaxDepth.
+func (e *ELMEngine) SetMaxDepth(depth int) {
+	e.maxDepth = depth
+}
+
+// Libraries returns the engine's library manager.
+func (e *ELMEngine) Libraries() *ELMLibraryManager {
+	return e.libraries
+}
+
+// ELMRequest is the context of an evaluation.
+type ELMRequest struct {
+	// PatientID is the patient of the Patient context; retrieves in an
+	// Unfiltered context, or without a patient, are not limited to one.
+	PatientID string
+	Data      ELMDataSource
+	// Parameters are the values of library parameters by name; parameters
+	// not given take their defaults. They apply to included libraries too.
+	Parameters map[string]interface{}
+	// Now is the evaluation time, the current time if zero.
+	Now time.Time
+}
+
+// Evaluate evaluates the named expression definitions of lib, or all of
+// them if names is empty, and returns their values by name.
+func (e *ELMEngine) Evaluate(ctx context.Context, lib *ELMLibrary, names []string, req ELMRequest) (map[string]interface{}, error) {
+	if len(names) == 0 {
+		names = lib.statements
+	}
+	r := e.newRun(ctx, req)
+	out := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		v, err := r.expressionRef(lib, name)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = v
+	}
+	return out, nil
+}
+
+// EvaluateExpression evaluates one expression definition of lib.
+func (e *ELMEngine) EvaluateExpression(ctx context.Context, lib *ELMLibrary, name string, req ELMRequest) (interface{}, error) {
+	return e.newRun(ctx, req).expressionRef(lib, name)
+}
+
+func (e *ELMEngine) newRun(ctx context.Context, req ELMRequest) *elmRun {
+	now := req.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return &elmRun{
+		ctx:       ctx,
+		engine:    e,
+		req:       req,
+		now:       CQLDateTime{Time: now, Precision: cqlMillisecond},
+		results:   make(map[string]interface{}),
+		running:   make(map[string]bool),
+		valueSets: make(map[string]map[string]bool),
+		retrieved: make(map[string][]map[string]interface{}),
+	}
+}
+
+// elmRun is the state of one evaluation: definition results are computed
+// once, and value set expansions and retrieves are shared.
+type elmRun struct {
+	ctx       context.Context
+	engine    *ELMEngine
+	req       ELMRequest
+	now       CQLDateTime
+	results   map[string]interface{}
+	running   map[string]bool
+	depth     int                        // function calls in progress
+	valueSets map[string]map[string]bool // url -> system|code
+	retrieved map[string][]map[string]interface{}
+}
+
+// elmFrame is a scope: the library whose definitions are in reach, and the
+// query aliases, lets and function operands defined in it.
+type elmFrame struct {
+	lib    *ELMLibrary
+	vars   map[string]interface{}
+	parent *elmFrame
+}
+
+func (f *elmFrame) with(vars map[string]interface{}) *elmFrame {
+	return &elmFrame{lib: f.lib, vars: vars, parent: f}
+}
+
+func (f *elmFrame) lookup(name string) (interface{}, bool) {
+	for s := f; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// errSingleton is returned by SingletonFrom on a list of several items.
+var errSingleton = errors.New("ELM: list has more than one element")
+
+// library resolves a library alias in lib; the empty alias is lib itself.
+func (r *elmRun) library(lib *ELMLibrary, alias string) (*ELMLibrary, error) {
+	if alias == "" {
+		return lib, nil
+	}
+	inc, ok := lib.include(alias)
+	if !ok {
+		return nil, fmt.Errorf("ELM: library %s has no include %s", lib.Name, alias)
+	}
+	return r.engine.libraries.Library(r.ctx, inc.Path, inc.Version)
+}
+
+// expressionRef returns the value of an expression definition.
+func (r *elmRun) expressionRef(lib *ELMLibrary, name string) (interface{}, error) {
+	key := lib.Name + "|" + lib.Version + "|" + name
+	if v, ok := r.results[key]; ok {
+		return v, nil
+	}
+	def, ok := lib.expressions[name]
+	if !ok {
+		return nil, fmt.Errorf("ELM: library %s has no definition %q", lib.Name, name)
+	}
+	if r.running[key] {
+		return nil, fmt.Errorf("ELM: definition %q refers to itself", name)
+	}
+	r.running[key] = true
+	defer delete(r.running, key)
+	v, err := r.eval(&elmFrame{lib: lib}, elmNode(def, "expression"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	r.results[key] = v
+	return v, nil
+}
+
+// parameterRef returns the value of a parameter: the request's, or the
+// default of its definition.
+func (r *elmRun) parameterRef(lib *ELMLibrary, name string) (interface{}, error) {
+	if v, ok := r.req.Parameters[name]; ok {
+		return v, nil
+	}
+	key := lib.Name + "|" + lib.Version + "|parameter|" + name
+	if v, ok := r.results[key]; ok {
+		return v, nil
+	}
+	def, ok := lib.parameters[name]
+	if !ok {
+		return nil, fmt.Errorf("ELM: library %s has no parameter %q", lib.Name, name)
+	}
+	v, err := r.eval(&elmFrame{lib: lib}, elmNode(def, "default"))
+	if err != nil {
+		return nil, err
+	}
+	r.results[key] = v
+	return v, nil
+}
+
+// eval evaluates an expression node. A missing node is null.
+func (r *elmRun) eval(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	if n == nil {
+		return nil, nil
+	}
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
+	typ := elmString(n, "type")
+	switch typ {
+	case "Null":
+		return nil, nil
+	case "Literal":
+		return elmLiteral(n)
+	case "List":
+		return r.evalList(f, elmNodes(n, "element"))
+	case "Tuple", "Instance":
+		return r.evalInstance(f, n)
+	case "Interval":
+		return r.evalInterval(f, n)
+	case "Quantity":
+		v, _ := cqlNumber(n["value"])
+		return CQLQuantity{Value: v, Unit: elmString(n, "unit")}, nil
+	case "Ratio":
+		num, _ := cqlNumber(elmNode(n, "numerator")["value"])
+		den, _ := cqlNumber(elmNode(n, "denominator")["value"])
+		return CQLRatio{
+			Numerator:   CQLQuantity{Value: num, Unit: elmString(elmNode(n, "numerator"), "unit")},
+			Denominator: CQLQuantity{Value: den, Unit: elmString(elmNode(n, "denominator"), "unit")},
+		}, nil
+	case "Code":
+		return r.code(f.lib, n)
+	case "Concept":
+		return r.concept(f.lib, n)
+	case "CodeRef", "ConceptRef", "CodeSystemRef", "ValueSetRef":
+		return r.terminologyRef(f.lib, n)
+	case "ParameterRef":
+		lib, err := r.library(f.lib, elmString(n, "libraryName"))
+		if err != nil {
+			return nil, err
+		}
+		return r.parameterRef(lib, elmString(n, "name"))
+	case "ExpressionRef":
+		lib, err := r.library(f.lib, elmString(n, "libraryName"))
+		if err != nil {
+			return nil, err
+		}
+		return r.expressionRef(lib, elmString(n, "name"))
+	case "FunctionRef":
+		return r.functionRef(f, n)
+	case "OperandRef", "AliasRef", "QueryLetRef":
+		v, _ := f.lookup(elmString(n, "name"))
+		return v, nil
+	case "IdentifierRef":
+		name := elmString(n, "name")
+		if v, ok := f.lookup(name); ok {
+			return v, nil
+		}
+		// In a sort expression, identifiers are properties of the item.
+		if this, ok := f.lookup("$this"); ok {
+			return elmProperty(this, name), nil
+		}
+		return nil, nil
+	case "Property":
+		return r.evalProperty(f, n)
+	case "As":
+		return r.evalAs(f, n)
+	case "Is":
+		return r.evalIs(f, n)
+	case "Query":
+		return r.evalQuery(f, n)
+	case "Retrieve":
+		return r.evalRetrieve(f, n)
+	case "InValueSet", "AnyInValueSet":
+		return r.evalInValueSet(f, n)
+	case "InCodeSystem", "AnyInCodeSystem":
+		return r.evalInCodeSystem(f, n)
+	case "ExpandValueSet":
+		return r.evalExpandValueSet(f, n)
+	case "If":
+		cond, err := r.eval(f, elmNode(n, "condition"))
+		if err != nil {
+			return nil, err
+		}
+		if cond == true {
+			return r.eval(f, elmNode(n, "then"))
+		}
+		return r.eval(f, elmNode(n, "else"))
+	case "Case":
+		return r.evalCase(f, n)
+	case "And", "Or", "Implies":
+		return r.evalLogical(f, typ, elmNodes(n, "operand"))
+	case "Message":
+		return r.eval(f, elmNode(n, "source"))
+	case "Now":
+		return r.now, nil
+	case "Today":
+		return r.now.asDate(), nil
+	case "Count", "Sum", "Min", "Max", "Avg", "Median", "AllTrue", "AnyTrue", "PopulationVariance", "Variance", "StdDev", "PopulationStdDev":
+		src, err := r.eval(f, elmNode(n, "source"))
+		if err != nil {
+			return nil, err
+		}
+		return elmAggregate(typ, src)
+	case "First", "Last":
+		src, err := r.eval(f, elmNode(n, "source"))
+		if err != nil {
+			return nil, err
+		}
+		list := elmList(src)
+		if len(list) == 0 {
+			return nil, nil
+		}
+		if typ == "First" {
+			return list[0], nil
+		}
+		return list[len(list)-1], nil
+	case "Combine":
+		src, err := r.eval(f, elmNode(n, "source"))
+		if err != nil {
+			return nil, err
+		}
+		sep, err := r.eval(f, elmNode(n, "separator"))
+		if err != nil {
+			return nil, err
+		}
+		return elmCombine(src, sep), nil
+	case "Split":
+		s, err := r.eval(f, elmNode(n, "stringToSplit"))
+		if err != nil {
+			return nil, err
+		}
+		sep, err := r.eval(f, elmNode(n, "separator"))
+		if err != nil || s == nil {
+			return nil, err
+		}
+		str, _ := s.(string)
+		sepStr, _ := sep.(string)
+		var out []interface{}
+		for _, part := range strings.Split(str, sepStr) {
+			out = append(out, part)
+		}
+		return out, nil
+	case "Substring":
+		return r.evalSubstring(f, n)
+	case "PositionOf", "LastPositionOf":
+		pattern, err := r.eval(f, elmNode(n, "pattern"))
+		if err != nil {
+			return nil, err
+		}
+		s, err := r.eval(f, elmNode(n, "string"))
+		if err != nil {
+			return nil, err
+		}
+		ps, ok1 := pattern.(string)
+		ss, ok2 := s.(string)
+		if !ok1 || !ok2 {
+			return nil, nil
+		}
+		if typ == "PositionOf" {
+			return int64(strings.Index(ss, ps)), nil
+		}
+		return int64(strings.LastIndex(ss, ps)), nil
+	case "Slice":
+		return r.evalSlice(f, n)
+	case "IndexOf":
+		src, err := r.eval(f, elmNode(n, "source"))
+		if err != nil {
+			return nil, err
+		}
+		el, err := r.eval(f, elmNode(n, "element"))
+		if err != nil || src == nil || el == nil {
+			return nil, err
+		}
+		for i, item := range elmList(src) {
+			if eq, _ := cqlEqual(item, el); eq {
+				return int64(i), nil
+			}
+		}
+		return int64(-1), nil
+	case "Sort":
+		src, err := r.eval(f, elmNode(n, "source"))
+		if err != nil || src == nil {
+			return nil, err
+		}
+		return r.sortList(f, elmList(src), elmNodes(n, "by"))
+	case "ForEach":
+		src, err := r.eval(f, elmNode(n, "source"))
+		if err != nil || src == nil {
+			return nil, err
+		}
+		var out []interface{}
+		for _, item := range elmList(src) {
+			v, err := r.eval(f.with(map[string]interface{}{"$this": item, elmString(n, "scope"): item}), elmNode(n, "element"))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	case "Total":
+		v, _ := f.lookup("$total")
+		return v, nil
+	case "Date", "DateTime", "Round":
+		// Operands named by field rather than listed.
+		fields := []string{"year", "month", "day", "hour", "minute", "second", "millisecond", "timezoneOffset"}
+		if typ == "Round" {
+			fields = []string{"operand", "precision"}
+		}
+		args := make([]interface{}, len(fields))
+		for i, field := range fields {
+			v, err := r.eval(f, elmNode(n, field))
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		return elmOperators[typ](r, n, args)
+	}
+
+	operands, err := r.evalOperands(f, n)
+	if err != nil {
+		return nil, err
+	}
+	if fn, ok := elmOperators[typ]; ok {
+		return fn(r, n, operands)
+	}
+	return nil, fmt.Errorf("ELM: unsupported expression %s", typ)
+}
+
+// evalOperands evaluates the operand or operands of an operator.
+func (r *elmRun) evalOperands(f *elmFrame, n map[string]interface{}) ([]interface{}, error) {
+	nodes := elmNodes(n, "operand")
+	out := make([]interface{}, len(nodes))
+	for i, op := range nodes {
+		v, err := r.eval(f, op)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func (r *elmRun) evalList(f *elmFrame, elements []map[string]interface{}) (interface{}, error) {
+	out := make([]interface{}, 0, len(elements))
+	for _, el := range elements {
+		v, err := r.eval(f, el)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// evalInstance builds a tuple, or an instance of a class. System Code,
+// Concept and Quantity instances become CQL values; FHIR instances stay
+// maps.
+func (r *elmRun) evalInstance(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	obj := make(map[string]interface{})
+	for _, el := range elmNodes(n, "element") {
+		v, err := r.eval(f, elmNode(el, "value"))
+		if err != nil {
+			return nil, err
+		}
+		obj[elmString(el, "name")] = v
+	}
+	switch elmTypeName(elmString(n, "classType")) {
+	case "System.Code":
+		c := CQLCode{}
+		c.Code, _ = obj["code"].(string)
+		c.System, _ = obj["system"].(string)
+		c.Version, _ = obj["version"].(string)
+		c.Display, _ = obj["display"].(string)
+		return c, nil
+	case "System.Concept":
+		c := CQLConcept{}
+		for _, code := range elmList(obj["codes"]) {
+			if cc, ok := code.(CQLCode); ok {
+				c.Codes = append(c.Codes, cc)
+			}
+		}
+		c.Display, _ = obj["display"].(string)
+		return c, nil
+	case "System.Quantity":
+		v, _ := cqlNumber(obj["value"])
+		unit, _ := obj["unit"].(string)
+		return CQLQuantity{Value: v, Unit: unit}, nil
+	}
+	return obj, nil
+}
+
+func (r *elmRun) evalInterval(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	low, err := r.eval(f, elmNode(n, "low"))
+	if err != nil {
+		return nil, err
+	}
+	high, err := r.eval(f, elmNode(n, "high"))
+	if err != nil {
+		return nil, err
+	}
+	iv := CQLInterval{Low: low, High: high, LowClosed: n["lowClosed"] != false, HighClosed: n["highClosed"] != false}
+	if e := elmNode(n, "lowClosedExpression"); e != nil {
+		v, err := r.eval(f, e)
+		if err != nil {
+			return nil, err
+		}
+		iv.LowClosed = v == true
+	}
+	if e := elmNode(n, "highClosedExpression"); e != nil {
+		v, err := r.eval(f, e)
+		if err != nil {
+			return nil, err
+		}
+		iv.HighClosed = v == true
+	}
+	return iv, nil
+}
+
+// code builds a Code whose system is a code system definition.
+func (r *elmRun) code(lib *ELMLibrary, n map[string]interface{}) (interface{}, error) {
+	c := CQLCode{Code: elmString(n, "code"), Display: elmString(n, "display")}
+	if sys := elmNode(n, "system"); sys != nil {
+		csLib, err := r.library(lib, elmString(sys, "libraryName"))
+		if err != nil {
+			return nil, err
+		}
+		def := csLib.codeSystems[elmString(sys, "name")]
+		c.System, c.Version = elmString(def, "id"), elmString(def, "version")
+	}
+	return c, nil
+}
+
+func (r *elmRun) concept(lib *ELMLibrary, n map[string]interface{}) (interface{}, error) {
+	c := CQLConcept{Display: elmString(n, "display")}
+	for _, code := range elmNodes(n, "code") {
+		v, err := r.code(lib, code)
+		if err != nil {
+			return nil, err
+		}
+		c.Codes = append(c.Codes, v.(CQLCode))
+	}
+	return c, nil
+}
+
+// terminologyRef evaluates a reference to a code, concept, code system or
+// value set definition.
+func (r *elmRun) terminologyRef(lib *ELMLibrary, n map[string]interface{}) (interface{}, error) {
+	lib, err := r.library(lib, elmString(n, "libraryName"))
+	if err != nil {
+		return nil, err
+	}
+	name := elmString(n, "name")
+	switch elmString(n, "type") {
+	case "CodeRef":
+		def, ok := lib.codes[name]
+		if !ok {
+			return nil, fmt.Errorf("ELM: library %s has no code %q", lib.Name, name)
+		}
+		return r.code(lib, map[string]interface{}{"code": def["id"], "display": def["display"], "system": def["codeSystem"]})
+	case "ConceptRef":
+		def, ok := lib.concepts[name]
+		if !ok {
+			return nil, fmt.Errorf("ELM: library %s has no concept %q", lib.Name, name)
+		}
+		c := CQLConcept{Display: elmString(def, "display")}
+		for _, ref := range elmNodes(def, "code") {
+			v, err := r.terminologyRef(lib, map[string]interface{}{"type": "CodeRef", "name": ref["name"], "libraryName": ref["libraryName"]})
+			if err != nil {
+				return nil, err
+			}
+			c.Codes = append(c.Codes, v.(CQLCode))
+		}
+		return c, nil
+	case "CodeSystemRef":
+		def, ok := lib.codeSystems[name]
+		if !ok {
+			return nil, fmt.Errorf("ELM: library %s has no code system %q", lib.Name, name)
+		}
+		return elmString(def, "id"), nil
+	default:
+		def, ok := lib.valueSets[name]
+		if !ok {
+			return nil, fmt.Errorf("ELM: library %s has no value set %q", lib.Name, name)
+		}
+		return cqlValueSet{ID: elmString(def, "id"), Version: elmString(def, "version")}, nil
+	}
+}
+
+// functionRef calls a function: a FHIRHelpers conversion, which the engine
+// implements natively, or a function definition of the library, chosen
+// among its overloads by elmResolveFunction.
+func (r *elmRun) functionRef(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	args, err := r.evalOperands(f, n)
+	if err != nil {
+		return nil, err
+	}
+	name, alias := elmString(n, "name"), elmString(n, "libraryName")
+	if alias != "" {
+		if inc, ok := f.lib.include(alias); ok && inc.Path == "FHIRHelpers" {
+			if v, ok := fhirHelper(name, args); ok {
+				return v, nil
+			}
+		}
+	}
+	lib, err := r.library(f.lib, alias)
+	if err != nil {
+		return nil, err
+	}
+	if def, bound := elmResolveFunction(lib.functions[name], elmNodes(n, "signature"), args); def != nil && def["external"] != true {
+		maxDepth := r.engine.maxDepth
+		if maxDepth <= 0 {
+			maxDepth = DefaultELMMaxDepth
+		}
+		if r.depth >= maxDepth {
+			return nil, fmt.Errorf("%w: %s calls nested beyond %d", ErrELMDepthExceeded, name, maxDepth)
+		}
+		r.depth++
+		defer func() { r.depth-- }()
+		vars := make(map[string]interface{}, len(bound))
+		for i, p := range elmNodes(def, "operand") {
+			vars[elmString(p, "name")] = bound[i]
+		}
+		return r.eval(&elmFrame{lib: lib, vars: vars}, elmNode(def, "expression"))
+	}
+	if v, ok := fhirHelper(name, args); ok && lib.Name == "FHIRHelpers" {
+		return v, nil
+	}
+	return nil, fmt.Errorf("ELM: library %s has no function %s/%d for these arguments", lib.Name, name, len(args))
+}
+
+// elmResolveFunction picks the overload of a function for a call with args:
+// the definition whose operand types are the signature of the call, when
+// the translator recorded one, or else the one whose operand types the
+// arguments match most closely, the first defined on a tie. It returns the
+// definition, or nil if none takes the arguments, and the arguments
+// converted to its operand types, as Integers passed for Decimals.
+func elmResolveFunction(defs, signature []map[string]interface{}, args []interface{}) (map[string]interface{}, []interface{}) {
+	var best map[string]interface{}
+	var bestArgs []interface{}
+	bestScore := -1
+	for _, def := range defs {
+		params := elmNodes(def, "operand")
+		if len(params) != len(args) {
+			continue
+		}
+		bound, score, ok := elmBindOperands(params, args)
+		if len(signature) == len(params) && elmSameSignature(params, signature) {
+			return def, bound
+		}
+		if ok && score > bestScore {
+			best, bestArgs, bestScore = def, bound, score
+		}
+	}
+	return best, bestArgs
+}
+
+// elmBindOperands matches args to the operands params of a definition (see
+// elmOperandMatch), returning the converted arguments, the sum of their
+// scores, and whether every argument matched.
+func elmBindOperands(params []map[string]interface{}, args []interface{}) ([]interface{}, int, bool) {
+	bound := make([]interface{}, len(args))
+	total, all := 0, true
+	for i, p := range params {
+		v, score, ok := elmOperandMatch(elmOperandType(p), args[i])
+		bound[i] = v
+		total += score
+		all = all && ok
+	}
+	return bound, total, all
+}
+
+// elmSameSignature reports whether the operands params are declared with
+// the types of signature.
+func elmSameSignature(params, signature []map[string]interface{}) bool {
+	for i, p := range params {
+		name := elmTypeSpecifierName(elmOperandType(p))
+		if name == "" || name != elmTypeSpecifierName(signature[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// elmOperandType returns the type specifier of an operand definition,
+// which older translators give as a qualified operandType name.
+func elmOperandType(p map[string]interface{}) map[string]interface{} {
+	if spec := elmNode(p, "operandTypeSpecifier"); spec != nil {
+		return spec
+	}
+	if name := elmString(p, "operandType"); name != "" {
+		return map[string]interface{}{"type": "NamedTypeSpecifier", "name": name}
+	}
+	return nil
+}
+
+// elmOperandMatch reports whether arg can be passed for an operand of type
+// spec, with arg converted to the type, and scores the match: 3 when arg
+// is known to be of the type, 2 when it converts implicitly to it, and 1
+// when its type cannot be told, as for null, FHIR elements and operands
+// without a type.
+func elmOperandMatch(spec map[string]interface{}, arg interface{}) (interface{}, int, bool) {
+	if arg == nil {
+		return nil, 1, true
+	}
+	switch elmString(spec, "type") {
+	case "NamedTypeSpecifier":
+		typeName := elmTypeName(elmString(spec, "name"))
+		is, known := elmIsType(arg, typeName)
+		switch {
+		case !known:
+			return arg, 1, true
+		case is:
+			return arg, 3, true
+		}
+		if i, ok := arg.(int64); ok && typeName == "System.Decimal" {
+			return float64(i), 2, true
+		}
+		return arg, 0, false
+	case "ListTypeSpecifier":
+		list, ok := arg.([]interface{})
+		if !ok {
+			return arg, 0, false
+		}
+		score := 3
+		out := make([]interface{}, len(list))
+		for i, item := range list {
+			v, s, ok := elmOperandMatch(elmNode(spec, "elementType"), item)
+			if !ok {
+				return arg, 0, false
+			}
+			out[i] = v
+			if s < score {
+				score = s
+			}
+		}
+		return out, score, true
+	case "IntervalTypeSpecifier":
+		if _, ok := arg.(CQLInterval); !ok {
+			return arg, 0, false
+		}
+		return arg, 3, true
+	}
+	return arg, 1, true
+}
+
+// evalProperty reads a property of its source, or of a query alias
+// (scope).
+func (r *elmRun) evalProperty(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	var src interface{}
+	if scope := elmString(n, "scope"); scope != "" {
+		src, _ = f.lookup(scope)
+	} else if s := elmNode(n, "source"); s != nil {
+		v, err := r.eval(f, s)
+		if err != nil {
+			return nil, err
+		}
+		src = v
+	} else {
+		src, _ = f.lookup("$this")
+	}
+	return elmProperty(src, elmString(n, "path")), nil
+}
+
+// evalAs casts its operand to a type. Over a property of a FHIR choice
+// element, such as Observation.value, it reads the element of that type
+// (valueQuantity).
+func (r *elmRun) evalAs(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	typeName := elmTypeName(elmString(n, "asType"))
+	if typeName == "" {
+		typeName = elmTypeSpecifierName(elmNode(n, "asTypeSpecifier"))
+	}
+	if v, ok, err := r.choiceProperty(f, elmNode(n, "operand"), typeName); ok || err != nil {
+		return v, err
+	}
+	v, err := r.eval(f, elmNode(n, "operand"))
+	if err != nil || v == nil {
+		return nil, err
+	}
+	if is, known := elmIsType(v, typeName); known && !is {
+		if n["strict"] == true {
+			return nil, fmt.Errorf("ELM: cannot cast %T to %s", v, typeName)
+		}
+		return nil, nil
+	}
+	return v, nil
+}
+
+func (r *elmRun) evalIs(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	typeName := elmTypeName(elmString(n, "isType"))
+	if typeName == "" {
+		typeName = elmTypeSpecifierName(elmNode(n, "isTypeSpecifier"))
+	}
+	if v, ok, err := r.choiceProperty(f, elmNode(n, "operand"), typeName); ok || err != nil {
+		return v != nil, err
+	}
+	v, err := r.eval(f, elmNode(n, "operand"))
+	if err != nil || v == nil {
+		return false, err
+	}
+	is, _ := elmIsType(v, typeName)
+	return is, nil
+}
+
+// choiceProperty reads the typed element of a choice property: path
+// "value" and type FHIR.Quantity read valueQuantity. ok is false if the
+// node is not a property of a FHIR element, or the type not a FHIR type.
+func (r *elmRun) choiceProperty(f *elmFrame, n map[string]interface{}, typeName string) (interface{}, bool, error) {
+	if elmString(n, "type") != "Property" || !strings.HasPrefix(typeName, "FHIR.") {
+		return nil, false, nil
+	}
+	suffix := strings.TrimPrefix(typeName, "FHIR.")
+	var src interface{}
+	if scope := elmString(n, "scope"); scope != "" {
+		src, _ = f.lookup(scope)
+	} else {
+		v, err := r.eval(f, elmNode(n, "source"))
+		if err != nil {
+			return nil, true, err
+		}
+		src = v
+	}
+	obj, isMap := src.(map[string]interface{})
+	if !isMap {
+		return nil, false, nil
+	}
+	path := elmString(n, "path")
+	key := path + strings.ToUpper(suffix[:1]) + suffix[1:]
+	if v, ok := obj[key]; ok {
+		return v, true, nil
+	}
+	if _, ok := obj[path]; ok {
+		// Not a choice element; cast the value itself.
+		return nil, false, nil
+	}
+	return nil, true, nil
+}
+
+func (r *elmRun) evalCase(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	var comparand interface{}
+	hasComparand := elmNode(n, "comparand") != nil
+	if hasComparand {
+		v, err := r.eval(f, elmNode(n, "comparand"))
+		if err != nil {
+			return nil, err
+		}
+		comparand = v
+	}
+	for _, item := range elmNodes(n, "caseItem") {
+		when, err := r.eval(f, elmNode(item, "when"))
+		if err != nil {
+			return nil, err
+		}
+		match := when == true
+		if hasComparand {
+			eq, known := cqlEqual(comparand, when)
+			match = known && eq
+		}
+		if match {
+			return r.eval(f, elmNode(item, "then"))
+		}
+	}
+	return r.eval(f, elmNode(n, "else"))
+}
+
+// evalLogical implements three-valued And, Or and Implies, short-circuiting
+// where the result is already known.
+func (r *elmRun) evalLogical(f *elmFrame, op string, operands []map[string]interface{}) (interface{}, error) {
+	if len(operands) != 2 {
+		return nil, fmt.Errorf("ELM: %s needs two operands", op)
+	}
+	a, err := r.eval(f, operands[0])
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case op == "And" && a == false:
+		return false, nil
+	case op == "Or" && a == true:
+		return true, nil
+	case op == "Implies" && a == false:
+		return true, nil
+	}
+	b, err := r.eval(f, operands[1])
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "And":
+		if b == false {
+			return false, nil
+		}
+		if a == true && b == true {
+			return true, nil
+		}
+	case "Or":
+		if b == true {
+			return true, nil
+		}
+		if a == false && b == false {
+			return false, nil
+		}
+	case "Implies":
+		if b == true {
+			return true, nil
+		}
+		if a == true && b == false {
+			return false, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *elmRun) evalSubstring(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	s, err := r.eval(f, elmNode(n, "stringToSub"))
+	if err != nil {
+		return nil, err
+	}
+	start, err := r.eval(f, elmNode(n, "startIndex"))
+	if err != nil {
+		return nil, err
+	}
+	str, ok1 := s.(string)
+	i, ok2 := cqlToInt64(start)
+	if !ok1 || !ok2 || i < 0 || int(i) > len(str) {
+		return nil, nil
+	}
+	str = str[i:]
+	if ln := elmNode(n, "length"); ln != nil {
+		v, err := r.eval(f, ln)
+		if err != nil {
+			return nil, err
+		}
+		if l, ok := cqlToInt64(v); ok && int(l) < len(str) {
+			str = str[:l]
+		}
+	}
+	return str, nil
+}
+
+func (r *elmRun) evalSlice(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	src, err := r.eval(f, elmNode(n, "source"))
+	if err != nil || src == nil {
+		return nil, err
+	}
+	list := elmList(src)
+	start, end := int64(0), int64(len(list))
+	if v, err := r.eval(f, elmNode(n, "startIndex")); err != nil {
+		return nil, err
+	} else if i, ok := cqlToInt64(v); ok {
+		start = i
+	}
+	if v, err := r.eval(f, elmNode(n, "endIndex")); err != nil {
+		return nil, err
+	} else if i, ok := cqlToInt64(v); ok {
+		end = i
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > int64(len(list)) {
+		end = int64(len(list))
+	}
+	if start >= end {
+		return []interface{}{}, nil
+	}
+	return append([]interface{}(nil), list[start:end]...), nil
+}
+
+// ---------------------------------------------------------------------------
+// Values and types
+// ---------------------------------------------------------------------------
+
+// elmLiteral returns the value of a Literal node.
+func elmLiteral(n map[string]interface{}) (interface{}, error) {
+	s := elmString(n, "value")
+	switch t := elmTypeName(elmString(n, "valueType")); t {
+	case "System.Boolean":
+		return s == "true", nil
+	case "System.Integer", "System.Long":
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ELM: invalid %s literal %q", t, s)
+		}
+		return i, nil
+	case "System.Decimal":
+		d, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ELM: invalid Decimal literal %q", s)
+		}
+		return d, nil
+	case "System.Date", "System.DateTime":
+		d, ok := ParseCQLDateTime(s)
+		if !ok {
+			return nil, fmt.Errorf("ELM: invalid %s literal %q", t, s)
+		}
+		d.Date = t == "System.Date"
+		return d, nil
+	}
+	return s, nil
+}
+
+// elmTypeName shortens an ELM qualified type name to Model.Type:
+// "{urn:hl7-org:elm-types:r1}Integer" is System.Integer and
+// "{http://hl7.org/fhir}Quantity" is FHIR.Quantity.
+func elmTypeName(qname string) string {
+	switch {
+	case strings.HasPrefix(qname, "{urn:hl7-org:elm-types:r1}"):
+		return "System." + strings.TrimPrefix(qname, "{urn:hl7-org:elm-types:r1}")
+	case strings.HasPrefix(qname, "{http://hl7.org/fhir}"):
+		return "FHIR." + strings.TrimPrefix(qname, "{http://hl7.org/fhir}")
+	}
+	return qname
+}
+
+// elmTypeSpecifierName returns the type name of a named type specifier,
+// or of a list or interval of one, as List<System.Integer>; it is empty
+// for other specifiers.
+func elmTypeSpecifierName(spec map[string]interface{}) string {
+	switch elmString(spec, "type") {
+	case "NamedTypeSpecifier":
+		return elmTypeName(elmString(spec, "name"))
+	case "ListTypeSpecifier":
+		if inner := elmTypeSpecifierName(elmNode(spec, "elementType")); inner != "" {
+			return "List<" + inner + ">"
+		}
+	case "IntervalTypeSpecifier":
+		if inner := elmTypeSpecifierName(elmNode(spec, "pointType")); inner != "" {
+			return "Interval<" + inner + ">"
+		}
+	}
+	return ""
+}
+
+// elmIsType reports whether v is of the named type. known is false when
+// the value carries no type the check can rely on, as for FHIR elements.
+func elmIsType(v interface{}, typeName string) (is, known bool) {
+	switch typeName {
+	case "System.Boolean":
+		_, ok := v.(bool)
+		return ok, true
+	case "System.Integer", "System.Long":
+		_, ok := v.(int64)
+		return ok, true
+	case "System.Decimal":
+		_, ok := v.(float64)
+		return ok, true
+	case "System.String":
+		_, ok := v.(string)
+		return ok, true
+	case "System.Date", "System.DateTime":
+		d, ok := v.(CQLDateTime)
+		return ok && d.Date == (typeName == "System.Date"), true
+	case "System.Quantity":
+		_, ok := v.(CQLQuantity)
+		return ok, true
+	case "System.Code":
+		_, ok := v.(CQLCode)
+		return ok, true
+	case "System.Concept":
+		_, ok := v.(CQLConcept)
+		return ok, true
+	case "":
+		return true, false
+	}
+	if !strings.HasPrefix(typeName, "FHIR.") {
+		return true, false
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return true, false
+	}
+	if rt, ok := obj["resourceType"].(string); ok {
+		want := strings.TrimPrefix(typeName, "FHIR.")
+		return rt == want || want == "Resource" || want == "DomainResource", true
+	}
+	return true, false
+}
+
+// elmProperty reads path, which may be dotted, from v. Over a list it
+// reads the property of each item and flattens the results.
+func elmProperty(v interface{}, path string) interface{} {
+	if path == "" {
+		return v
+	}
+	if head, rest, dotted := strings.Cut(path, "."); dotted {
+		return elmProperty(elmProperty(v, head), rest)
+	}
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var out []interface{}
+		for _, item := range x {
+			switch pv := elmProperty(item, path).(type) {
+			case nil:
+			case []interface{}:
+				out = append(out, pv...)
+			default:
+				out = append(out, pv)
+			}
+		}
+		return out
+	case map[string]interface{}:
+		if pv, ok := x[path]; ok {
+			return pv
+		}
+		// A choice element read without a type, e.g. Observation.value.
+		for k, pv := range x {
+			if len(k) > len(path) && strings.HasPrefix(k, path) && k[len(path)] >= 'A' && k[len(path)] <= 'Z' {
+				return pv
+			}
+		}
+		return nil
+	case CQLInterval:
+		switch path {
+		case "low":
+			return x.Low
+		case "high":
+			return x.High
+		case "lowClosed":
+			return x.LowClosed
+		case "highClosed":
+			return x.HighClosed
+		}
+	case CQLQuantity:
+		switch path {
+		case "value":
+			return x.Value
+		case "unit":
+			return x.Unit
+		}
+	case CQLCode:
+		switch path {
+		case "code":
+			return x.Code
+		case "system":
+			return x.System
+		case "version":
+			return x.Version
+		case "display":
+			return x.Display
+		}
+	case CQLConcept:
+		switch path {
+		case "codes":
+			out := make([]interface{}, len(x.Codes))
+			for i, c := range x.Codes {
+				out[i] = c
+			}
+			return out
+		case "display":
+			return x.Display
+		}
+	}
+	// FHIR primitives are plain JSON values, their own value.
+	if path == "value" {
+		return v
+	}
+	return nil
+}
+
+// elmList returns v as a list: nil is empty and a single value is a list
+// of one.
+func elmList(v interface{}) []interface{} {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return x
+	case []map[string]interface{}:
+		out := make([]interface{}, len(x))
+		for i, m := range x {
+			out[i] = m
+		}
+		return out
+	}
+	return []interface{}{v}
+}
+
+// ---------------------------------------------------------------------------
+// FHIRHelpers
+// ---------------------------------------------------------------------------
+
+// fhirHelper implements the FHIRHelpers conversions from FHIR elements,
+// kept as JSON, to CQL values.
+func fhirHelper(name string, args []interface{}) (interface{}, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	v := args[0]
+	if obj, ok := v.(map[string]interface{}); ok && name != "ToQuantity" && name != "ToInterval" && name != "ToConcept" && name != "ToCode" && name != "ToRatio" {
+		// A primitive with extensions may arrive as an object.
+		v = obj["value"]
+	}
+	switch name {
+	case "ToString", "ToUri", "ToUrl", "ToCanonical", "ToId", "ToOid", "ToUuid", "ToMarkdown", "ToBase64Binary", "ToXhtml", "ToTime":
+		s, _ := cqlString(v)
+		if v == nil {
+			return nil, true
+		}
+		return s, true
+	case "ToBoolean":
+		b, _ := v.(bool)
+		if v == nil {
+			return nil, true
+		}
+		return b, true
+	case "ToInteger", "ToPositiveInt", "ToUnsignedInt", "ToInteger64":
+		i, ok := cqlToInt64(v)
+		if !ok {
+			return nil, true
+		}
+		return i, true
+	case "ToDecimal":
+		d, ok := cqlNumber(v)
+		if !ok {
+			return nil, true
+		}
+		return d, true
+	case "ToDate", "ToDateTime", "ToInstant":
+		d, ok := cqlDateTimeOf(v)
+		if !ok {
+			return nil, true
+		}
+		if name == "ToDate" {
+			return d.asDate(), true
+		}
+		d.Date = false
+		return d, true
+	case "ToQuantity", "ToAge", "ToDuration", "ToCount", "ToDistance", "ToSimpleQuantity", "ToMoneyQuantity":
+		return fhirQuantity(v), true
+	case "ToRatio":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, true
+		}
+		num, _ := fhirQuantity(obj["numerator"]).(CQLQuantity)
+		den, _ := fhirQuantity(obj["denominator"]).(CQLQuantity)
+		return CQLRatio{Numerator: num, Denominator: den}, true
+	case "ToInterval":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, true
+		}
+		if _, isRange := obj["low"]; isRange || obj["high"] != nil {
+			return CQLInterval{Low: fhirQuantity(obj["low"]), High: fhirQuantity(obj["high"]), LowClosed: true, HighClosed: true}, true
+		}
+		iv := CQLInterval{LowClosed: true, HighClosed: true}
+		if d, ok := cqlDateTimeOf(obj["start"]); ok {
+			d.Date = false
+			iv.Low = d
+		}
+		if d, ok := cqlDateTimeOf(obj["end"]); ok {
+			d.Date = false
+			iv.High = d
+		}
+		return iv, true
+	case "ToCode":
+		return fhirCode(v), true
+	case "ToConcept":
+		return fhirConcept(v), true
+	}
+	return nil, false
+}
+
+// fhirQuantity converts a FHIR Quantity to a CQL Quantity, preferring the
+// UCUM code to the human readable unit.
+func fhirQuantity(v interface{}) interface{} {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	value, ok := cqlNumber(obj["value"])
+	if !ok {
+		return nil
+	}
+	unit, _ := obj["code"].(string)
+	if unit == "" {
+		unit, _ = obj["unit"].(string)
+	}
+	return CQLQuantity{Value: value, Unit: unit}
+}
+
+// fhirCode converts a FHIR Coding to a CQL Code.
+func fhirCode(v interface{}) interface{} {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	c := CQLCode{}
+	c.Code, _ = obj["code"].(string)
+	c.System, _ = obj["system"].(string)
+	c.Version, _ = obj["version"].(string)
+	c.Display, _ = obj["display"].(string)
+	return c
+}
+
+// fhirConcept converts a FHIR CodeableConcept to a CQL Concept.
+func fhirConcept(v interface{}) interface{} {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	c := CQLConcept{}
+	c.Display, _ = obj["text"].(string)
+	for _, coding := range elmList(obj["coding"]) {
+		if code, ok := fhirCode(coding).(CQLCode); ok {
+			c.Codes = append(c.Codes, code)
+		}
+	}
+	return c
+}
+
+// ---------------------------------------------------------------------------
+// Operators
+// ---------------------------------------------------------------------------
+
+// elmOperator implements an operator over its evaluated operands.
+type elmOperator func(r *elmRun, n map[string]interface{}, args []interface{}) (interface{}, error)
+
+// elmOperators maps the ELM operators that evaluate all of their operands
+// to their implementations.
+var elmOperators map[string]elmOperator
+
+func init() {
+	elmOperators = map[string]elmOperator{
+		"Not": elmUnary(func(v interface{}) interface{} {
+			if b, ok := v.(bool); ok {
+				return !b
+			}
+			return nil
+		}),
+		"Xor": elmBinary(func(a, b interface{}) interface{} {
+			x, ok1 := a.(bool)
+			y, ok2 := b.(bool)
+			if !ok1 || !ok2 {
+				return nil
+			}
+			return x != y
+		}),
+		"IsNull": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			return elmArg(args, 0) == nil, nil
+		},
+		"IsTrue": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			return elmArg(args, 0) == true, nil
+		},
+		"IsFalse": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			return elmArg(args, 0) == false, nil
+		},
+		"Coalesce": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			if len(args) == 1 {
+				args = elmList(args[0])
+			}
+			for _, a := range args {
+				if a != nil {
+					return a, nil
+				}
+			}
+			return nil, nil
+		},
+
+		"Equal": elmBinary(func(a, b interface{}) interface{} {
+			eq, known := cqlEqual(a, b)
+			if !known {
+				return nil
+			}
+			return eq
+		}),
+		"NotEqual": elmBinary(func(a, b interface{}) interface{} {
+			eq, known := cqlEqual(a, b)
+			if !known {
+				return nil
+			}
+			return !eq
+		}),
+		"Equivalent": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			return cqlEquivalent(elmArg(args, 0), elmArg(args, 1)), nil
+		},
+		"Less":           elmComparison(func(c int) bool { return c < 0 }),
+		"LessOrEqual":    elmComparison(func(c int) bool { return c <= 0 }),
+		"Greater":        elmComparison(func(c int) bool { return c > 0 }),
+		"GreaterOrEqual": elmComparison(func(c int) bool { return c >= 0 }),
+
+		"Add":      elmBinary(elmAdd),
+		"Subtract": elmBinary(elmSubtract),
+		"Multiply": elmBinary(elmMultiply),
+		"Divide":   elmBinary(elmDivide),
+		"TruncatedDivide": elmBinary(func(a, b interface{}) interface{} {
+			x, ok1 := cqlToInt64(a)
+			y, ok2 := cqlToInt64(b)
+			if !ok1 || !ok2 || y == 0 {
+				return nil
+			}
+			return x / y
+		}),
+		"Modulo": elmBinary(func(a, b interface{}) interface{} {
+			if x, ok := a.(int64); ok {
+				if y, ok := b.(int64); ok && y != 0 {
+					return x % y
+				}
+			}
+			x, ok1 := cqlNumber(a)
+			y, ok2 := cqlNumber(b)
+			if !ok1 || !ok2 || y == 0 {
+				return nil
+			}
+			return math.Mod(x, y)
+		}),
+		"Power": elmBinary(func(a, b interface{}) interface{} {
+			x, ok1 := cqlNumber(a)
+			y, ok2 := cqlNumber(b)
+			if !ok1 || !ok2 {
+				return nil
+			}
+			p := math.Pow(x, y)
+			if _, ok := a.(int64); ok && y >= 0 && p == math.Trunc(p) {
+				return int64(p)
+			}
+			return p
+		}),
+		"Negate": elmUnary(func(v interface{}) interface{} {
+			switch x := v.(type) {
+			case int64:
+				return -x
+			case float64:
+				return -x
+			case CQLQuantity:
+				x.Value = -x.Value
+				return x
+			}
+			return nil
+		}),
+		"Abs": elmUnary(func(v interface{}) interface{} {
+			switch x := v.(type) {
+			case int64:
+				if x < 0 {
+					return -x
+				}
+				return x
+			case float64:
+				return math.Abs(x)
+			case CQLQuantity:
+				x.Value = math.Abs(x.Value)
+				return x
+			}
+			return nil
+		}),
+		"Ceiling":  elmMath(math.Ceil, true),
+		"Floor":    elmMath(math.Floor, true),
+		"Truncate": elmMath(math.Trunc, true),
+		"Ln":       elmMath(math.Log, false),
+		"Exp":      elmMath(math.Exp, false),
+		"Round": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			x, ok := cqlNumber(elmArg(args, 0))
+			if !ok {
+				return nil, nil
+			}
+			places, _ := cqlToInt64(elmArg(args, 1))
+			scale := math.Pow(10, float64(places))
+			return math.Floor(x*scale+0.5) / scale, nil
+		},
+
+		"Concatenate": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			var b strings.Builder
+			for _, a := range args {
+				s, ok := a.(string)
+				if !ok {
+					return nil, nil
+				}
+				b.WriteString(s)
+			}
+			return b.String(), nil
+		},
+		"Length": elmUnary(func(v interface{}) interface{} {
+			switch x := v.(type) {
+			case string:
+				return int64(len([]rune(x)))
+			case []interface{}:
+				return int64(len(x))
+			}
+			return nil
+		}),
+		"Upper":      elmString1(strings.ToUpper),
+		"Lower":      elmString1(strings.ToLower),
+		"StartsWith": elmString2(func(a, b string) interface{} { return strings.HasPrefix(a, b) }),
+		"EndsWith":   elmString2(func(a, b string) interface{} { return strings.HasSuffix(a, b) }),
+		"Matches": elmString2(func(a, b string) interface{} {
+			re, err := regexp.Compile("^(?:" + b + ")$")
+			if err != nil {
+				return nil
+			}
+			return re.MatchString(a)
+		}),
+		"Indexer": elmBinary(func(a, b interface{}) interface{} {
+			i, ok := cqlToInt64(b)
+			if !ok || i < 0 {
+				return nil
+			}
+			switch x := a.(type) {
+			case string:
+				if int(i) < len(x) {
+					return x[i : i+1]
+				}
+			case []interface{}:
+				if int(i) < len(x) {
+					return x[i]
+				}
+			}
+			return nil
+		}),
+		"ToString": elmUnary(func(v interface{}) interface{} {
+			s, _ := cqlString(v)
+			return s
+		}),
+		"ToBoolean": elmUnary(func(v interface{}) interface{} {
+			switch x := v.(type) {
+			case bool:
+				return x
+			case string:
+				switch strings.ToLower(x) {
+				case "true", "t", "yes", "y", "1":
+					return true
+				case "false", "f", "no", "n", "0":
+					return false
+				}
+			}
+			return nil
+		}),
+		"ToInteger": elmUnary(func(v interface{}) interface{} {
+			if s, ok := v.(string); ok {
+				if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+					return i
+				}
+				return nil
+			}
+			if i, ok := cqlToInt64(v); ok {
+				return i
+			}
+			if b, ok := v.(bool); ok {
+				if b {
+					return int64(1)
+				}
+				return int64(0)
+			}
+			return nil
+		}),
+		"ToDecimal": elmUnary(func(v interface{}) interface{} {
+			if s, ok := v.(string); ok {
+				if d, err := strconv.ParseFloat(s, 64); err == nil {
+					return d
+				}
+				return nil
+			}
+			if d, ok := cqlNumber(v); ok {
+				return d
+			}
+			return nil
+		}),
+		"ToDate": elmUnary(func(v interface{}) interface{} {
+			if d, ok := cqlDateTimeOf(v); ok {
+				return d.asDate()
+			}
+			return nil
+		}),
+		"ToDateTime": elmUnary(func(v interface{}) interface{} {
+			if d, ok := cqlDateTimeOf(v); ok {
+				d.Date = false
+				return d
+			}
+			return nil
+		}),
+		"ToQuantity": elmUnary(func(v interface{}) interface{} {
+			switch x := v.(type) {
+			case CQLQuantity:
+				return x
+			case int64, float64:
+				n, _ := cqlNumber(x)
+				return CQLQuantity{Value: n, Unit: "1"}
+			}
+			return nil
+		}),
+		"ToConcept": elmUnary(func(v interface{}) interface{} {
+			switch x := v.(type) {
+			case CQLConcept:
+				return x
+			case CQLCode:
+				return CQLConcept{Codes: []CQLCode{x}}
+			case []interface{}:
+				c := CQLConcept{}
+				for _, item := range x {
+					if code, ok := item.(CQLCode); ok {
+						c.Codes = append(c.Codes, code)
+					}
+				}
+				return c
+			}
+			return nil
+		}),
+		"ToList": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			return append([]interface{}{}, elmList(elmArg(args, 0))...), nil
+		},
+
+		"Exists": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			for _, item := range elmList(elmArg(args, 0)) {
+				if item != nil {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+		"SingletonFrom": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			list := elmList(elmArg(args, 0))
+			switch len(list) {
+			case 0:
+				return nil, nil
+			case 1:
+				return list[0], nil
+			}
+			return nil, errSingleton
+		},
+		"Distinct": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			if elmArg(args, 0) == nil {
+				return nil, nil
+			}
+			return elmDistinct(elmList(args[0])), nil
+		},
+		"Flatten": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			if elmArg(args, 0) == nil {
+				return nil, nil
+			}
+			out := []interface{}{}
+			for _, item := range elmList(args[0]) {
+				if inner, ok := item.([]interface{}); ok {
+					out = append(out, inner...)
+				} else {
+					out = append(out, item)
+				}
+			}
+			return out, nil
+		},
+		"Union": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			a, b := elmArg(args, 0), elmArg(args, 1)
+			if x, ok := a.(CQLInterval); ok {
+				y, ok := b.(CQLInterval)
+				if !ok {
+					return nil, nil
+				}
+				return elmIntervalUnion(x, y), nil
+			}
+			if a == nil && b == nil {
+				return nil, nil
+			}
+			return elmDistinct(append(append([]interface{}{}, elmList(a)...), elmList(b)...)), nil
+		},
+		"Intersect": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			a, b := elmArg(args, 0), elmArg(args, 1)
+			if a == nil || b == nil {
+				return nil, nil
+			}
+			if x, ok := a.(CQLInterval); ok {
+				y, ok := b.(CQLInterval)
+				if !ok {
+					return nil, nil
+				}
+				return elmIntervalIntersect(x, y), nil
+			}
+			var out []interface{}
+			for _, item := range elmList(a) {
+				if elmListContains(elmList(b), item) {
+					out = append(out, item)
+				}
+			}
+			return elmDistinct(out), nil
+		},
+		"Except": func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+			a, b := elmArg(args, 0), elmArg(args, 1)
+			if a == nil {
+				return nil, nil
+			}
+			out := []interface{}{}
+			for _, item := range elmList(a) {
+				if !elmListContains(elmList(b), item) {
+					out = append(out, item)
+				}
+			}
+			return elmDistinct(out), nil
+		},
+
+		"Start":     elmUnary(func(v interface{}) interface{} { return elmClosed(v).low.v }),
+		"End":       elmUnary(func(v interface{}) interface{} { return elmClosed(v).high.v }),
+		"PointFrom": elmUnary(func(v interface{}) interface{} { return elmClosed(v).low.v }),
+		"Width": elmUnary(func(v interface{}) interface{} {
+			iv := elmClosed(v)
+			return elmSubtract(iv.high.v, iv.low.v)
+		}),
+		"Size": elmUnary(func(v interface{}) interface{} {
+			iv := elmClosed(v)
+			w := elmSubtract(iv.high.v, iv.low.v)
+			return elmAdd(w, int64(1))
+		}),
+		"In":               elmIntervalOperator(elmIn),
+		"Contains":         elmIntervalOperator(func(a, b interface{}, p int) interface{} { return elmIn(b, a, p) }),
+		"ProperIn":         elmIntervalOperator(elmProperIn),
+		"ProperContains":   elmIntervalOperator(func(a, b interface{}, p int) interface{} { return elmProperIn(b, a, p) }),
+		"Includes":         elmIntervalOperator(elmIncludes),
+		"IncludedIn":       elmIntervalOperator(func(a, b interface{}, p int) interface{} { return elmIncludes(b, a, p) }),
+		"ProperIncludes":   elmIntervalOperator(elmProperIncludes),
+		"ProperIncludedIn": elmIntervalOperator(func(a, b interface{}, p int) interface{} { return elmProperIncludes(b, a, p) }),
+		"Overlaps":         elmIntervalOperator(elmOverlaps),
+		"OverlapsBefore":   elmIntervalOperator(elmOverlapsBefore),
+		"OverlapsAfter":    elmIntervalOperator(func(a, b interface{}, p int) interface{} { return elmOverlapsBefore(b, a, p) }),
+		"Before":           elmIntervalOperator(elmBefore),
+		"After":            elmIntervalOperator(func(a, b interface{}, p int) interface{} { return elmBefore(b, a, p) }),
+		"Meets": elmIntervalOperator(func(a, b interface{}, p int) interface{} {
+			return elmOr(elmMeetsBefore(a, b, p), elmMeetsBefore(b, a, p))
+		}),
+		"MeetsBefore":  elmIntervalOperator(elmMeetsBefore),
+		"MeetsAfter":   elmIntervalOperator(func(a, b interface{}, p int) interface{} { return elmMeetsBefore(b, a, p) }),
+		"Starts":       elmIntervalOperator(elmStarts),
+		"Ends":         elmIntervalOperator(elmEnds),
+		"SameAs":       elmIntervalOperator(elmSameAs),
+		"SameOrBefore": elmIntervalOperator(func(a, b interface{}, p int) interface{} { return elmSameOr(a, b, p, -1) }),
+		"SameOrAfter":  elmIntervalOperator(func(a, b interface{}, p int) interface{} { return elmSameOr(a, b, p, 1) }),
+
+		"Date":     elmDateConstructor(true),
+		"DateTime": elmDateConstructor(false),
+		"DateFrom": elmUnary(func(v interface{}) interface{} {
+			if d, ok := cqlDateTimeOf(v); ok {
+				return d.asDate()
+			}
+			return nil
+		}),
+		"DateTimeComponentFrom": func(_ *elmRun, n map[string]interface{}, args []interface{}) (interface{}, error) {
+			d, ok := cqlDateTimeOf(elmArg(args, 0))
+			p := cqlPrecision(elmString(n, "precision"))
+			if !ok || p < 0 || p > d.Precision {
+				return nil, nil
+			}
+			return int64(d.components()[p]), nil
+		},
+		"DurationBetween":   elmDateDiff(durationBetween),
+		"DifferenceBetween": elmDateDiff(differenceBetween),
+		"CalculateAgeAt":    elmDateDiff(durationBetween),
+		"CalculateAge": func(r *elmRun, n map[string]interface{}, args []interface{}) (interface{}, error) {
+			birth, ok := cqlDateTimeOf(elmArg(args, 0))
+			if !ok {
+				return nil, nil
+			}
+			now := r.now
+			if birth.Date {
+				now = now.asDate()
+			}
+			if v, ok := durationBetween(birth, now, elmPrecisionOf(n, cqlYear)); ok {
+				return v, nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+// elmArg returns the i-th argument, or null.
+func elmArg(args []interface{}, i int) interface{} {
+	if i < len(args) {
+		return args[i]
+	}
+	return nil
+}
+
+// elmPrecisionOf returns the precision attribute of n, or def.
+func elmPrecisionOf(n map[string]interface{}, def int) int {
+	if p := cqlPrecision(elmString(n, "precision")); p != -1 || elmString(n, "precision") != "" {
+		return p
+	}
+	return def
+}
+
+func elmUnary(fn func(interface{}) interface{}) elmOperator {
+	return func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+		v := elmArg(args, 0)
+		if v == nil {
+			return nil, nil
+		}
+		return fn(v), nil
+	}
+}
+
+// elmBinary wraps an operator that is null when either operand is.
+func elmBinary(fn func(a, b interface{}) interface{}) elmOperator {
+	return func(_ *elmRun, _ map[string]interface{}, args []interface{}) (interface{}, error) {
+		a, b := elmArg(args, 0), elmArg(args, 1)
+		if a == nil || b == nil {
+			return nil, nil
+		}
+		return fn(a, b), nil
+	}
+}
+
+func elmComparison(test func(int) bool) elmOperator {
+	return func(_ *elmRun, n map[string]interface{}, args []interface{}) (interface{}, error) {
+		c, ok := cqlCompare(elmArg(args, 0), elmArg(args, 1), elmPrecisionOf(n, -1))
+		if !ok {
+			return nil, nil
+		}
+		return test(c), nil
+	}
+}
+
+func elmMath(fn func(float64) float64, integral bool) elmOperator {
+	return elmUnary(func(v interface{}) interface{} {
+		x, ok := cqlNumber(v)
+		if !ok {
+			return nil
+		}
+		y := fn(x)
+		if math.IsNaN(y) || math.IsInf(y, 0) {
+			return nil
+		}
+		if integral {
+			return int64(y)
+		}
+		return y
+	})
+}
+
+func elmString1(fn func(string) string) elmOperator {
+	return elmUnary(func(v interface{}) interface{} {
+		s, ok := v.(string)
+		if !ok {
+			return nil
+		}
+		return fn(s)
+	})
+}
+
+func elmString2(fn func(a, b string) interface{}) elmOperator {
+	return elmBinary(func(a, b interface{}) interface{} {
+		x, ok1 := a.(string)
+		y, ok2 := b.(string)
+		if !ok1 || !ok2 {
+			return nil
+		}
+		return fn(x, y)
+	})
+}
+
+// elmAdd adds numbers, quantities, and temporal quantities to dates.
+func elmAdd(a, b interface{}) interface{} {
+	if x, ok := a.(int64); ok {
+		if y, ok := b.(int64); ok {
+			return x + y
+		}
+	}
+	if x, ok := cqlNumber(a); ok {
+		if y, ok := cqlNumber(b); ok {
+			return x + y
+		}
+	}
+	switch x := a.(type) {
+	case CQLQuantity:
+		if y, ok := b.(CQLQuantity); ok {
+			if v, ok := y.convertTo(x.Unit); ok {
+				return CQLQuantity{Value: x.Value + v, Unit: x.Unit}
+			}
+		}
+	case CQLDateTime:
+		if y, ok := b.(CQLQuantity); ok {
+			p := cqlPrecision(y.Unit)
+			if p == -1 {
+				return nil
+			}
+			return x.add(int64(y.Value), p)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return x + y
+		}
+	}
+	return nil
+}
+
+func elmSubtract(a, b interface{}) interface{} {
+	if a == nil || b == nil {
+		return nil
+	}
+	switch y := b.(type) {
+	case int64:
+		if x, ok := a.(int64); ok {
+			return x - y
+		}
+	case CQLQuantity:
+		y.Value = -y.Value
+		return elmAdd(a, y)
+	}
+	if x, ok := cqlNumber(a); ok {
+		if y, ok := cqlNumber(b); ok {
+			return x - y
+		}
+	}
+	return nil
+}
+
+func elmMultiply(a, b interface{}) interface{} {
+	if x, ok := a.(int64); ok {
+		if y, ok := b.(int64); ok {
+			return x * y
+		}
+	}
+	if x, ok := cqlNumber(a); ok {
+		if y, ok := cqlNumber(b); ok {
+			return x * y
+		}
+		if q, ok := b.(CQLQuantity); ok {
+			q.Value *= x
+			return q
+		}
+	}
+	if q, ok := a.(CQLQuantity); ok {
+		if y, ok := cqlNumber(b); ok {
+			q.Value *= y
+			return q
+		}
+	}
+	return nil
+}
+
+func elmDivide(a, b interface{}) interface{} {
+	if x, ok := cqlNumber(a); ok {
+		if y, ok := cqlNumber(b); ok && y != 0 {
+			return x / y
+		}
+		return nil
+	}
+	if q, ok := a.(CQLQuantity); ok {
+		switch y := b.(type) {
+		case CQLQuantity:
+			if v, ok := y.convertTo(q.Unit); ok && v != 0 {
+				return q.Value / v
+			}
+		default:
+			if d, ok := cqlNumber(y); ok && d != 0 {
+				q.Value /= d
+				return q
+			}
+		}
+	}
+	return nil
+}
+
+// elmAggregate implements the aggregate operators, which skip nulls.
+func elmAggregate(op string, src interface{}) (interface{}, error) {
+	var items []interface{}
+	for _, item := range elmList(src) {
+		if item != nil {
+			items = append(items, item)
+		}
+	}
+	switch op {
+	case "Count":
+		return int64(len(items)), nil
+	case "AllTrue":
+		for _, item := range items {
+			if item != true {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "AnyTrue":
+		for _, item := range items {
+			if item == true {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	switch op {
+	case "Sum":
+		sum := items[0]
+		for _, item := range items[1:] {
+			sum = elmAdd(sum, item)
+		}
+		return sum, nil
+	case "Min", "Max":
+		best := items[0]
+		for _, item := range items[1:] {
+			c, ok := cqlCompare(item, best, -1)
+			if ok && ((op == "Min" && c < 0) || (op == "Max" && c > 0)) {
+				best = item
+			}
+		}
+		return best, nil
+	}
+	nums := make([]float64, 0, len(items))
+	for _, item := range items {
+		if x, ok := cqlNumber(item); ok {
+			nums = append(nums, x)
+		} else if q, ok := item.(CQLQuantity); ok {
+			nums = append(nums, q.Value)
+		}
+	}
+	if len(nums) == 0 {
+		return nil, nil
+	}
+	mean := 0.0
+	for _, x := range nums {
+		mean += x
+	}
+	mean /= float64(len(nums))
+	switch op {
+	case "Avg":
+		return mean, nil
+	case "Median":
+		sorted := append([]float64(nil), nums...)
+		for i := 1; i < len(sorted); i++ {
+			for j := i; j > 0 && sorted[j] < sorted[j-1]; j-- {
+				sorted[j], sorted[j-1] = sorted[j-1], sorted[j]
+			}
+		}
+		mid := len(sorted) / 2
+		if len(sorted)%2 == 0 {
+			return (sorted[mid-1] + sorted[mid]) / 2, nil
+		}
+		return sorted[mid], nil
+	}
+	ss := 0.0
+	for _, x := range nums {
+		ss += (x - mean) * (x - mean)
+	}
+	n := float64(len(nums))
+	switch op {
+	case "PopulationVariance":
+		return ss / n, nil
+	case "PopulationStdDev":
+		return math.Sqrt(ss / n), nil
+	}
+	if len(nums) < 2 {
+		return nil, nil
+	}
+	if op == "Variance" {
+		return ss / (n - 1), nil
+	}
+	return math.Sqrt(ss / (n - 1)), nil
+}
+
+func elmCombine(src, sep interface{}) interface{} {
+	if src == nil {
+		return nil
+	}
+	s, _ := sep.(string)
+	var parts []string
+	for _, item := range elmList(src) {
+		if str, ok := item.(string); ok {
+			parts = append(parts, str)
+		}
+	}
+	return strings.Join(parts, s)
+}
+
+// elmDistinct removes duplicates from a list, keeping first occurrences.
+// Nulls are duplicates of each other.
+func elmDistinct(list []interface{}) []interface{} {
+	out := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		if !elmListContains(out, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func elmListContains(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if item == nil && v == nil {
+			return true
+		}
+		if eq, known := cqlEqual(item, v); known && eq {
+			return true
+		}
+	}
+	return false
+}
+
+func elmOr(a, b interface{}) interface{} {
+	if a == true || b == true {
+		return true
+	}
+	if a == false && b == false {
+		return false
+	}
+	return nil
+}
+
+func elmAnd(a, b interface{}) interface{} {
+	if a == false || b == false {
+		return false
+	}
+	if a == true && b == true {
+		return true
+	}
+	return nil
+}
+
+// elmDateConstructor builds a Date or DateTime from its components, to
+// the precision of the last one given.
+func elmDateConstructor(date bool) elmOperator {
+	return func(_ *elmRun, n map[string]interface{}, args []interface{}) (interface{}, error) {
+		parts := [7]int{0, 1, 1, 0, 0, 0, 0}
+		precision := -1
+		for i, key := range []string{"year", "month", "day", "hour", "minute", "second", "millisecond"} {
+			if date && i > cqlDay {
+				break
+			}
+			child := elmNode(n, key)
+			if child == nil {
+				break
+			}
+			v, ok := cqlToInt64(elmArg(args, i))
+			if !ok {
+				break
+			}
+			parts[i] = int(v)
+			precision = i
+		}
+		if precision < 0 {
+			return nil, nil
+		}
+		loc := time.UTC
+		if off, ok := cqlNumber(elmArg(args, 7)); ok && !date {
+			loc = time.FixedZone("", int(off*3600))
+		}
+		t := time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], parts[6]*int(time.Millisecond), loc)
+		return CQLDateTime{Time: t, Precision: precision, Date: date}, nil
+	}
+}
+
+// elmDateDiff wraps DurationBetween and DifferenceBetween.
+func elmDateDiff(fn func(a, b CQLDateTime, precision int) (int64, bool)) elmOperator {
+	return func(_ *elmRun, n map[string]interface{}, args []interface{}) (interface{}, error) {
+		a, ok1 := cqlDateTimeOf(elmArg(args, 0))
+		b, ok2 := cqlDateTimeOf(elmArg(args, 1))
+		if !ok1 || !ok2 {
+			return nil, nil
+		}
+		if v, ok := fn(a, b, elmPrecisionOf(n, cqlYear)); ok {
+			return v, nil
+		}
+		return nil, nil
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Interval operators
+// ---------------------------------------------------------------------------
+
+// elmBound is an interval bound made closed; inf is -1 or 1 for an
+// unbounded low or high.
+type elmBound struct {
+	v   interface{}
+	inf int
+}
+
+// elmClosedInterval is an interval with closed bounds. A point is an
+// interval of itself.
+type elmClosedInterval struct {
+	low, high elmBound
+}
+
+// elmClosed closes the bounds of an interval by stepping past open ones:
+// one for integers, a unit of their precision for dates and times, and
+// the CQL decimal step for decimals and quantities.
+func elmClosed(v interface{}) elmClosedInterval {
+	iv, ok := v.(CQLInterval)
+	if !ok {
+		return elmClosedInterval{low: elmBound{v: v}, high: elmBound{v: v}}
+	}
+	out := elmClosedInterval{low: elmBound{v: iv.Low}, high: elmBound{v: iv.High}}
+	if iv.Low == nil {
+		out.low.inf = -1
+	} else if !iv.LowClosed {
+		out.low.v = elmStep(iv.Low, 1)
+	}
+	if iv.High == nil {
+		out.high.inf = 1
+	} else if !iv.HighClosed {
+		out.high.v = elmStep(iv.High, -1)
+	}
+	return out
+}
+
+// elmStep returns the successor (dir 1) or predecessor (dir -1) of v.
+func elmStep(v interface{}, dir int64) interface{} {
+	switch x := v.(type) {
+	case int64:
+		return x + dir
+	case float64:
+		return x + float64(dir)*1e-8
+	case CQLQuantity:
+		x.Value += float64(dir) * 1e-8
+		return x
+	case CQLDateTime:
+		return x.add(dir, x.Precision)
+	case string:
+		if d, ok := ParseCQLDateTime(x); ok {
+			return d.add(dir, d.Precision)
+		}
+	}
+	return v
+}
+
+// elmCompareBounds compares two bounds; ok is false if the order is
+// unknown.
+func elmCompareBounds(a, b elmBound, precision int) (int, bool) {
+	if a.inf != 0 || b.inf != 0 {
+		if a.inf == b.inf {
+			return 0, true
+		}
+		return compareFloats(float64(a.inf), float64(b.inf)), true
+	}
+	return cqlCompare(a.v, b.v, precision)
+}
+
+// elmBoundTest compares two bounds with test, three-valued.
+func elmBoundTest(a, b elmBound, precision int, test func(int) bool) interface{} {
+	c, ok := elmCompareBounds(a, b, precision)
+	if !ok {
+		return nil
+	}
+	return test(c)
+}
+
+func elmLessEq(c int) bool { return c <= 0 }
+func elmLess(c int) bool   { return c < 0 }
+func elmEq(c int) bool     { return c == 0 }
+
+// elmIntervalOperator wraps an interval operator, which is null when
+// either operand is, with its precision.
+func elmIntervalOperator(fn func(a, b interface{}, precision int) interface{}) elmOperator {
+	return func(_ *elmRun, n map[string]interface{}, args []interface{}) (interface{}, error) {
+		a, b := elmArg(args, 0), elmArg(args, 1)
+		if list, ok := b.([]interface{}); ok {
+			// List membership, not an interval operator.
+			switch elmString(n, "type") {
+			case "In":
+				if a == nil {
+					return nil, nil
+				}
+				return elmListContains(list, a), nil
+			case "IncludedIn":
+				return elmListIncludes(list, elmList(a)), nil
+			}
+		}
+		if list, ok := a.([]interface{}); ok {
+			switch elmString(n, "type") {
+			case "Contains":
+				if b == nil {
+					return nil, nil
+				}
+				return elmListContains(list, b), nil
+			case "Includes":
+				return elmListIncludes(list, elmList(b)), nil
+			}
+		}
+		if a == nil || b == nil {
+			return nil, nil
+		}
+		return fn(a, b, elmPrecisionOf(n, -1)), nil
+	}
+}
+
+func elmListIncludes(list, sub []interface{}) bool {
+	for _, item := range sub {
+		if !elmListContains(list, item) {
+			return false
+		}
+	}
+	return true
+}
+
+// elmIn reports whether point or interval a lies within interval b.
+func elmIn(a, b interface{}, p int) interface{} {
+	if _, ok := a.(CQLInterval); ok {
+		return elmIncludes(b, a, p)
+	}
+	iv := elmClosed(b)
+	pt := elmBound{v: a}
+	return elmAnd(elmBoundTest(iv.low, pt, p, elmLessEq), elmBoundTest(pt, iv.high, p, elmLessEq))
+}
+
+func elmProperIn(a, b interface{}, p int) interface{} {
+	if _, ok := a.(CQLInterval); ok {
+		return elmProperIncludes(b, a, p)
+	}
+	iv := elmClosed(b)
+	pt := elmBound{v: a}
+	return elmAnd(elmIn(a, b, p), elmOr(elmBoundTest(iv.low, pt, p, elmLess), elmBoundTest(pt, iv.high, p, elmLess)))
+}
+
+// elmIncludes reports whether interval a includes interval or point b.
+func elmIncludes(a, b interface{}, p int) interface{} {
+	x, y := elmClosed(a), elmClosed(b)
+	return elmAnd(elmBoundTest(x.low, y.low, p, elmLessEq), elmBoundTest(y.high, x.high, p, elmLessEq))
+}
+
+func elmProperIncludes(a, b interface{}, p int) interface{} {
+	x, y := elmClosed(a), elmClosed(b)
+	return elmAnd(elmIncludes(a, b, p), elmOr(elmBoundTest(x.low, y.low, p, elmLess), elmBoundTest(y.high, x.high, p, elmLess)))
+}
+
+func elmOverlaps(a, b interface{}, p int) interface{} {
+	x, y := elmClosed(a), elmClosed(b)
+	return elmAnd(elmBoundTest(x.low, y.high, p, elmLessEq), elmBoundTest(y.low, x.high, p, elmLessEq))
+}
+
+// elmOverlapsBefore reports whether a starts before b and ends within it.
+func elmOverlapsBefore(a, b interface{}, p int) interface{} {
+	x, y := elmClosed(a), elmClosed(b)
+	return elmAnd(elmBoundTest(x.low, y.low, p, elmLess), elmAnd(elmBoundTest(y.low, x.high, p, elmLessEq), elmBoundTest(x.high, y.high, p, elmLessEq)))
+}
+
+// elmBefore reports whether a ends before b starts.
+func elmBefore(a, b interface{}, p int) interface{} {
+	x, y := elmClosed(a), elmClosed(b)
+	return elmBoundTest(x.high, y.low, p, elmLess)
+}
+
+// elmMeetsBefore reports whether b starts right after a ends.
+func elmMeetsBefore(a, b interface{}, p int) interface{} {
+	x, y := elmClosed(a), elmClosed(b)
+	if x.high.inf != 0 || y.low.inf != 0 {
+		return false
+	}
+	next := x.high.v
+	if d, ok := cqlDateTimeOf(next); ok && p >= 0 {
+		next = d.truncate(p).add(1, p)
+	} else {
+		next = elmStep(next, 1)
+	}
+	return elmBoundTest(elmBound{v: next}, y.low, p, elmEq)
+}
+
+func elmStarts(a, b interface{}, p int) interface{} {
+	x, y := elmClosed(a), elmClosed(b)
+	return elmAnd(elmBoundTest(x.low, y.low, p, elmEq), elmBoundTest(x.high, y.high, p, elmLessEq))
+}
+
+func elmEnds(a, b interface{}, p int) interface{} {
+	x, y := elmClosed(a), elmClosed(b)
+	return elmAnd(elmBoundTest(x.high, y.high, p, elmEq), elmBoundTest(y.low, x.low, p, elmLessEq))
+}
+
+// elmSameAs compares points, or the bounds of intervals, to precision.
+func elmSameAs(a, b interface{}, p int) interface{} {
+	x, y := elmClosed(a), elmClosed(b)
+	return elmAnd(elmBoundTest(x.low, y.low, p, elmEq), elmBoundTest(x.high, y.high, p, elmEq))
+}
+
+// elmSameOr implements SameOrBefore (dir -1) and SameOrAfter (dir 1):
+// for intervals, a ends on or before b starts, or starts on or after b
+// ends.
+func elmSameOr(a, b interface{}, p int, dir int) interface{} {
+	x, y := elmClosed(a), elmClosed(b)
+	if dir < 0 {
+		return elmBoundTest(x.high, y.low, p, elmLessEq)
+	}
+	return elmBoundTest(x.low, y.high, p, func(c int) bool { return c >= 0 })
+}
+
+// elmIntervalUnion returns the union of overlapping or adjacent
+// intervals, or null.
+func elmIntervalUnion(a, b CQLInterval) interface{} {
+	if elmOverlaps(a, b, -1) != true && elmMeetsBefore(a, b, -1) != true && elmMeetsBefore(b, a, -1) != true {
+		return nil
+	}
+	x, y := elmClosed(a), elmClosed(b)
+	out := CQLInterval{LowClosed: true, HighClosed: true}
+	if c, ok := elmCompareBounds(x.low, y.low, -1); ok && c <= 0 {
+		out.Low = x.low.v
+	} else {
+		out.Low = y.low.v
+	}
+	if c, ok := elmCompareBounds(x.high, y.high, -1); ok && c >= 0 {
+		out.High = x.high.v
+	} else {
+		out.High = y.high.v
+	}
+	return out
+}
+
+// elmIntervalIntersect returns the overlap of two intervals, or null.
+func elmIntervalIntersect(a, b CQLInterval) interface{} {
+	if elmOverlaps(a, b, -1) != true {
+		return nil
+	}
+	x, y := elmClosed(a), elmClosed(b)
+	out := CQLInterval{LowClosed: true, HighClosed: true}
+	if c, ok := elmCompareBounds(x.low, y.low, -1); ok && c >= 0 {
+		out.Low = x.low.v
+	} else {
+		out.Low = y.low.v
+	}
+	if c, ok := elmCompareBounds(x.high, y.high, -1); ok && c <= 0 {
+		out.High = x.high.v
+	} else {
+		out.High = y.high.v
+	}
+	return out
+}
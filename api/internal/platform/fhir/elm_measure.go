@@ -0,0 +1,397 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// MeasurementPeriodParameter is the library parameter that receives the
+// period of a measure evaluation.
+const MeasurementPeriodParameter = "Measurement Period"
+
+// SetELMEngine enables measures whose libraries are ELM libraries. Their
+// population criteria are definitions of the library.
+func (e *MeasureEvaluator) SetELMEngine(engine *ELMEngine) {
+	e.elm = engine
+}
+
+// SetResources sets the store that subjects are evaluated against, for
+// $evaluate-measure with a subject and Library $evaluate without data.
+func (e *MeasureEvaluator) SetResources(resources ELMResourceSearcher) {
+	e.resources = resources
+}
+
+// measureLibrary returns the first of the measure's libraries that is an
+// ELM library, or nil if none is.
+func (e *MeasureEvaluator) measureLibrary(ctx context.Context, measure *Measure) (*ELMLibrary, error) {
+	if e.elm == nil {
+		return nil, nil
+	}
+	for _, canonical := range measure.Library {
+		lib, err := e.elm.Libraries().LibraryByURL(ctx, canonical)
+		if errors.Is(err, ErrELMLibraryNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("loading library %s: %w", canonical, err)
+		}
+		return lib, nil
+	}
+	return nil, nil
+}
+
+// elmCriteria returns the function that decides a population criterion for
+// a patient from the definitions of lib, or nil if lib is nil. A criterion
+// holds if its definition is true, or a non-empty list.
+func (e *MeasureEvaluator) elmCriteria(ctx context.Context, lib *ELMLibrary, patientID string, data ELMDataSource, period MeasurePeriod) func(string) (bool, error) {
+	if lib == nil {
+		return nil
+	}
+	r := e.elm.newRun(ctx, ELMRequest{
+		PatientID:  patientID,
+		Data:       data,
+		Parameters: map[string]interface{}{MeasurementPeriodParameter: period.interval()},
+	})
+	return func(name string) (bool, error) {
+		v, err := r.expressionRef(lib, name)
+		if err != nil {
+			return false, fmt.Errorf("Patient/%s: %w", patientID, err)
+		}
+		if list, ok := v.([]interface{}); ok {
+			return len(list) > 0, nil
+		}
+		return v == true, nil
+	}
+}
+
+// interval returns the period as a CQL Interval of DateTimes.
+func (p MeasurePeriod) interval() CQLInterval {
+	return CQLInterval{
+		Low:        CQLDateTime{Time: p.Start, Precision: cqlMillisecond},
+		High:       CQLDateTime{Time: p.End, Precision: cqlMillisecond},
+		LowClosed:  true,
+		HighClosed: true,
+	}
+}
+
+// EvaluateSubject evaluates a measure for a patient whose data is read
+// from the resource store. The measure must have an ELM library.
+func (e *MeasureEvaluator) EvaluateSubject(ctx context.Context, measureURL, patientID string, period MeasurePeriod) (*MeasureReport, error) {
+	e.mu.RLock()
+	measure, ok := e.measures[measureURL]
+	e.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("measure not found: %s", measureURL)
+	}
+	if e.resources == nil {
+		return nil, errors.New("no resource store to evaluate subjects against")
+	}
+	lib, err := e.measureLibrary(ctx, measure)
+	if err != nil {
+		return nil, err
+	}
+	if lib == nil {
+		return nil, fmt.Errorf("measure %s has no ELM library; post the patient's data instead", measureURL)
+	}
+
+	groups, err := e.evaluateGroups(ctx, measure, lib, patientID, NewSearchDataSource(e.resources), nil, nil, period)
+	if err != nil {
+		return nil, err
+	}
+	subjectRef := "Patient/" + patientID
+	report := &MeasureReport{
+		ID:      uuid.New().String(),
+		Status:  "complete",
+		Type:    "individual",
+		Measure: measureURL,
+		Subject: &subjectRef,
+		Period:  period,
+		Group:   groups,
+	}
+
+	e.mu.Lock()
+	e.reports[report.ID] = report
+	e.mu.Unlock()
+
+	return report, nil
+}
+
+// EvaluateLibrary handles POST /fhir/Library/$evaluate and
+// POST /fhir/Library/:id/$evaluate: it evaluates the definitions of an ELM
+// library for a subject and returns their values as Parameters. The body
+// is a Parameters resource with url (for the type-level operation),
+// expression (repeating; all definitions if absent), subject, parameters
+// (a Parameters resource) and data (a Bundle). Without data the subject's
+// stored resources are used.
+func (h *MeasureHandler) EvaluateLibrary(c echo.Context) error {
+	engine := h.evaluator.elm
+	if engine == nil {
+		return c.JSON(http.StatusNotImplemented, ErrorOutcome("ELM engine not configured"))
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(c.Request().Body).Decode(&body); err != nil && err != io.EOF {
+		return c.JSON(http.StatusBadRequest, ErrorOutcome("invalid request body"))
+	}
+	if rt, _ := body["resourceType"].(string); body != nil && rt != "Parameters" {
+		return c.JSON(http.StatusBadRequest, ErrorOutcome("resourceType must be Parameters"))
+	}
+
+	ctx := c.Request().Context()
+	var (
+		canonical, subject string
+		names              []string
+		data               map[string]interface{}
+		params             = make(map[string]interface{})
+	)
+	for _, p := range elmParameterList(body) {
+		switch cqlGetString(p, "name") {
+		case "url":
+			canonical = cqlGetString(p, "valueCanonical") + cqlGetString(p, "valueUri") + cqlGetString(p, "valueUrl")
+		case "expression":
+			names = append(names, cqlGetString(p, "valueString"))
+		case "subject":
+			subject = cqlGetString(p, "valueString")
+			if ref, ok := p["valueReference"].(map[string]interface{}); ok {
+				subject = cqlGetString(ref, "reference")
+			}
+		case "data":
+			data, _ = p["resource"].(map[string]interface{})
+		case "parameters":
+			inner, _ := p["resource"].(map[string]interface{})
+			for _, ip := range elmParameterList(inner) {
+				params[cqlGetString(ip, "name")] = elmParameterValue(ip)
+			}
+		}
+	}
+
+	var lib *ELMLibrary
+	var err error
+	switch {
+	case c.Param("id") != "":
+		lib, err = engine.Libraries().LibraryByID(ctx, c.Param("id"))
+	case canonical != "":
+		lib, err = engine.Libraries().LibraryByURL(ctx, canonical)
+	default:
+		return c.JSON(http.StatusBadRequest, ErrorOutcome("url parameter is required"))
+	}
+	if errors.Is(err, ErrELMLibraryNotFound) {
+		return c.JSON(http.StatusNotFound, ErrorOutcome(err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorOutcome(err.Error()))
+	}
+
+	req := ELMRequest{PatientID: strings.TrimPrefix(subject, "Patient/"), Parameters: params}
+	switch {
+	case data != nil:
+		bundles := parseBundleToPatientBundles(data)
+		for _, pb := range bundles {
+			if id, _ := pb.Patient["id"].(string); req.PatientID == "" || id == req.PatientID {
+				req.Data = NewBundleDataSource(pb)
+				req.PatientID = id
+				break
+			}
+		}
+		if req.Data == nil {
+			return c.JSON(http.StatusBadRequest, ErrorOutcome("no data for the subject in the data Bundle"))
+		}
+	case h.evaluator.resources != nil:
+		req.Data = NewSearchDataSource(h.evaluator.resources)
+	}
+
+	results, err := engine.Evaluate(ctx, lib, names, req)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, elmErrorOutcome("evaluation failed: ", err))
+	}
+	if len(names) == 0 {
+		names = lib.Statements()
+	}
+	out := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		out = append(out, elmResultParameters(name, results[name])...)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"resourceType": "Parameters",
+		"parameter":    out,
+	})
+}
+
+// elmErrorOutcome returns the OperationOutcome of a failed evaluation,
+// with msg before the error: too-costly when function calls nested beyond
+// the engine's limit, processing otherwise.
+func elmErrorOutcome(msg string, err error) *OperationOutcome {
+	if errors.Is(err, ErrELMDepthExceeded) {
+		return NewOperationOutcome(IssueSeverityError, IssueTypeTooCostly, msg+err.Error())
+	}
+	return ErrorOutcome(msg + err.Error())
+}
+
+// elmParameterList returns the parameter entries of a Parameters resource.
+func elmParameterList(params map[string]interface{}) []map[string]interface{} {
+	list, _ := params["parameter"].([]interface{})
+	out := make([]map[string]interface{}, 0, len(list))
+	for _, p := range list {
+		if m, ok := p.(map[string]interface{}); ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// elmParameterValue converts the value of a Parameters entry to a CQL
+// value.
+func elmParameterValue(p map[string]interface{}) interface{} {
+	for key, v := range p {
+		if !strings.HasPrefix(key, "value") {
+			continue
+		}
+		switch strings.TrimPrefix(key, "value") {
+		case "Boolean", "String", "Code", "Uri", "Canonical", "Id", "Markdown":
+			return v
+		case "Integer", "PositiveInt", "UnsignedInt":
+			i, _ := cqlToInt64(v)
+			return i
+		case "Decimal":
+			d, _ := cqlNumber(v)
+			return d
+		case "Date", "DateTime", "Instant":
+			d, ok := cqlDateTimeOf(v)
+			if !ok {
+				return nil
+			}
+			return d
+		case "Period", "Range":
+			iv, _ := fhirHelper("ToInterval", []interface{}{v})
+			return iv
+		case "Quantity":
+			return fhirQuantity(v)
+		case "Coding":
+			return fhirCode(v)
+		case "CodeableConcept":
+			return fhirConcept(v)
+		}
+	}
+	if res, ok := p["resource"]; ok {
+		return res
+	}
+	return nil
+}
+
+// elmResultParameters converts the value of a definition to Parameters
+// entries: one per item of a list, and none for null.
+func elmResultParameters(name string, v interface{}) []interface{} {
+	if list, ok := v.([]interface{}); ok {
+		var out []interface{}
+		for _, item := range list {
+			out = append(out, elmResultParameters(name, item)...)
+		}
+		return out
+	}
+	if v == nil {
+		return nil
+	}
+	p := map[string]interface{}{"name": name}
+	switch x := v.(type) {
+	case bool:
+		p["valueBoolean"] = x
+	case int64:
+		p["valueInteger"] = x
+	case float64:
+		p["valueDecimal"] = x
+	case string:
+		p["valueString"] = x
+	case CQLDateTime:
+		if x.Date {
+			p["valueDate"] = x.String()
+		} else {
+			p["valueDateTime"] = x.String()
+		}
+	case CQLQuantity:
+		p["valueQuantity"] = elmFHIRQuantity(x)
+	case CQLRatio:
+		p["valueRatio"] = map[string]interface{}{"numerator": elmFHIRQuantity(x.Numerator), "denominator": elmFHIRQuantity(x.Denominator)}
+	case CQLCode:
+		p["valueCoding"] = elmFHIRCoding(x)
+	case CQLConcept:
+		codings := make([]interface{}, len(x.Codes))
+		for i, code := range x.Codes {
+			codings[i] = elmFHIRCoding(code)
+		}
+		cc := map[string]interface{}{"coding": codings}
+		if x.Display != "" {
+			cc["text"] = x.Display
+		}
+		p["valueCodeableConcept"] = cc
+	case CQLInterval:
+		if _, ok := x.Low.(CQLQuantity); ok {
+			p["valueRange"] = map[string]interface{}{"low": elmFHIRValue(x.Low), "high": elmFHIRValue(x.High)}
+			break
+		}
+		period := map[string]interface{}{}
+		if x.Low != nil {
+			period["start"] = elmFHIRValue(x.Low)
+		}
+		if x.High != nil {
+			period["end"] = elmFHIRValue(x.High)
+		}
+		p["valuePeriod"] = period
+	case map[string]interface{}:
+		if _, ok := x["resourceType"]; ok {
+			p["resource"] = x
+			break
+		}
+		// A tuple: its elements are parts.
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var parts []interface{}
+		for _, k := range keys {
+			parts = append(parts, elmResultParameters(k, x[k])...)
+		}
+		p["part"] = parts
+	default:
+		s, _ := cqlString(x)
+		p["valueString"] = s
+	}
+	return []interface{}{p}
+}
+
+// elmFHIRValue converts a CQL point value to its FHIR JSON form.
+func elmFHIRValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case CQLDateTime:
+		return x.String()
+	case CQLQuantity:
+		return elmFHIRQuantity(x)
+	}
+	return v
+}
+
+func elmFHIRQuantity(q CQLQuantity) map[string]interface{} {
+	return map[string]interface{}{"value": q.Value, "unit": q.Unit, "system": "http://unitsofmeasure.org", "code": normalizeCQLUnit(q.Unit)}
+}
+
+func elmFHIRCoding(c CQLCode) map[string]interface{} {
+	coding := map[string]interface{}{"code": c.Code}
+	if c.System != "" {
+		coding["system"] = c.System
+	}
+	if c.Version != "" {
+		coding["version"] = c.Version
+	}
+	if c.Display != "" {
+		coding["display"] = c.Display
+	}
+	return coding
+}
@@ -0,0 +1,597 @@
+package fhir
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ELMDataSource provides the resources of retrieves. An empty patientID
+// asks for the resources of every patient.
+type ELMDataSource interface {
+	Retrieve(ctx context.Context, patientID, resourceType string) ([]map[string]interface{}, error)
+}
+
+// bundleDataSource retrieves from the resources of one patient.
+type bundleDataSource struct {
+	bundle PatientBundle
+}
+
+// NewBundleDataSource returns a data source over a patient's resources,
+// such as those posted to $evaluate-measure.
+func NewBundleDataSource(pb PatientBundle) ELMDataSource {
+	return bundleDataSource{bundle: pb}
+}
+
+func (s bundleDataSource) Retrieve(_ context.Context, _ string, resourceType string) ([]map[string]interface{}, error) {
+	if resourceType == "Patient" {
+		if s.bundle.Patient == nil {
+			return nil, nil
+		}
+		return []map[string]interface{}{s.bundle.Patient}, nil
+	}
+	return s.bundle.Resources[resourceType], nil
+}
+
+// elmRetrievePageSize is the page size of searches for retrieves.
+const elmRetrievePageSize = 500
+
+// searchDataSource retrieves through the search layer, limiting the
+// resources to the patient's compartment.
+type searchDataSource struct {
+	resources ELMResourceSearcher
+}
+
+// NewSearchDataSource returns a data source over stored resources.
+func NewSearchDataSource(resources ELMResourceSearcher) ELMDataSource {
+	return searchDataSource{resources: resources}
+}
+
+func (s searchDataSource) Retrieve(ctx context.Context, patientID, resourceType string) ([]map[string]interface{}, error) {
+	scopes := []url.Values{{}}
+	if patientID != "" {
+		scopes = nil
+		if resourceType == "Patient" {
+			scopes = append(scopes, url.Values{"_id": {patientID}})
+		}
+		for _, p := range CompartmentResourceParams(PatientCompartmentDef(), resourceType) {
+			scopes = append(scopes, url.Values{p: {"Patient/" + patientID}})
+		}
+	}
+	var out []map[string]interface{}
+	seen := make(map[string]bool)
+	for _, params := range scopes {
+		for offset := 0; ; {
+			params.Set("_count", strconv.Itoa(elmRetrievePageSize))
+			params.Set("_offset", strconv.Itoa(offset))
+			page, err := s.resources.Search(ctx, resourceType, params)
+			if err != nil {
+				return nil, fmt.Errorf("retrieve %s: %w", resourceType, err)
+			}
+			for _, res := range page {
+				if id, _ := res["id"].(string); id != "" {
+					if seen[id] {
+						continue
+					}
+					seen[id] = true
+				}
+				out = append(out, res)
+			}
+			if len(page) < elmRetrievePageSize {
+				break
+			}
+			offset += len(page)
+		}
+	}
+	return out, nil
+}
+
+// evalRetrieve evaluates a Retrieve: the resources of a type in the
+// patient's context, filtered by codes, a value set or a list of codes, at
+// codeProperty.
+func (r *elmRun) evalRetrieve(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	dataType := elmTypeName(elmString(n, "dataType"))
+	if !strings.HasPrefix(dataType, "FHIR.") {
+		return nil, fmt.Errorf("ELM: cannot retrieve %s", dataType)
+	}
+	resourceType := strings.TrimPrefix(dataType, "FHIR.")
+	if r.req.Data == nil {
+		return nil, fmt.Errorf("ELM: no data source for retrieve of %s", resourceType)
+	}
+	resources, ok := r.retrieved[resourceType]
+	if !ok {
+		var err error
+		resources, err = r.req.Data.Retrieve(r.ctx, r.req.PatientID, resourceType)
+		if err != nil {
+			return nil, err
+		}
+		r.retrieved[resourceType] = resources
+	}
+
+	var filter, dateRange interface{}
+	codesNode := elmNode(n, "codes")
+	if codesNode != nil {
+		v, err := r.eval(f, codesNode)
+		if err != nil {
+			return nil, err
+		}
+		filter = v
+	}
+	if node := elmNode(n, "dateRange"); node != nil {
+		v, err := r.eval(f, node)
+		if err != nil {
+			return nil, err
+		}
+		dateRange = v
+	}
+	codeProperty := elmString(n, "codeProperty")
+	if codeProperty == "" {
+		codeProperty = "code"
+	}
+	out := make([]interface{}, 0, len(resources))
+	for _, res := range resources {
+		if codesNode != nil {
+			match, err := r.codesMatch(elmCodesOf(elmProperty(res, codeProperty)), filter)
+			if err != nil {
+				return nil, err
+			}
+			if !match {
+				continue
+			}
+		}
+		if dateRange != nil && elmInDateRange(elmProperty(res, elmString(n, "dateProperty")), dateRange) != true {
+			continue
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+// elmInDateRange reports whether a FHIR date, dateTime or Period overlaps
+// the date range of a retrieve.
+func elmInDateRange(v, dateRange interface{}) interface{} {
+	if obj, ok := v.(map[string]interface{}); ok {
+		v, _ = fhirHelper("ToInterval", []interface{}{obj})
+	}
+	if v == nil {
+		return nil
+	}
+	return elmOverlaps(v, dateRange, -1)
+}
+
+// elmCodesOf returns the codes of a value: a CodeableConcept, Coding, FHIR
+// code string, CQL Code or Concept, or a list of them.
+func elmCodesOf(v interface{}) []CQLCode {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []CQLCode{{Code: x}}
+	case CQLCode:
+		return []CQLCode{x}
+	case CQLConcept:
+		return x.Codes
+	case []interface{}:
+		var out []CQLCode
+		for _, item := range x {
+			out = append(out, elmCodesOf(item)...)
+		}
+		return out
+	case map[string]interface{}:
+		if _, ok := x["coding"]; ok {
+			return fhirConcept(x).(CQLConcept).Codes
+		}
+		if _, ok := x["code"]; ok {
+			return []CQLCode{fhirCode(x).(CQLCode)}
+		}
+	}
+	return nil
+}
+
+// codesMatch reports whether any of codes is in filter: a value set, a
+// code, a concept, or a list of codes. Codes without a system match on
+// the code alone.
+func (r *elmRun) codesMatch(codes []CQLCode, filter interface{}) (bool, error) {
+	if vs, ok := filter.(cqlValueSet); ok {
+		members, err := r.expand(vs)
+		if err != nil {
+			return false, err
+		}
+		for _, c := range codes {
+			if members[c.System+"|"+c.Code] || (c.System == "" && members["|"+c.Code]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	for _, want := range elmCodesOf(filter) {
+		for _, c := range codes {
+			if c.Code == want.Code && (c.System == "" || want.System == "" || c.System == want.System) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// expand returns the members of a value set as system|code keys, and as
+// |code for matching codes without a system.
+func (r *elmRun) expand(vs cqlValueSet) (map[string]bool, error) {
+	if members, ok := r.valueSets[vs.ID]; ok {
+		return members, nil
+	}
+	if r.engine.terminology == nil {
+		return nil, fmt.Errorf("ELM: no terminology service to expand %s", vs.ID)
+	}
+	members := make(map[string]bool)
+	for offset := 0; ; {
+		expansion, err := r.engine.terminology.ExpandValueSet(vs.ID, "", offset, 1000)
+		if err != nil {
+			return nil, fmt.Errorf("ELM: expanding %s: %w", vs.ID, err)
+		}
+		n := elmAddContains(members, expansion.Contains)
+		offset += len(expansion.Contains)
+		if n == 0 || len(expansion.Contains) == 0 || offset >= expansion.Total {
+			break
+		}
+	}
+	r.valueSets[vs.ID] = members
+	return members, nil
+}
+
+func elmAddContains(members map[string]bool, contains []ValueSetContains) int {
+	n := 0
+	for _, c := range contains {
+		if c.Code != "" {
+			members[c.System+"|"+c.Code] = true
+			members["|"+c.Code] = true
+			n++
+		}
+		n += elmAddContains(members, c.Contains)
+	}
+	return n
+}
+
+// valueSetOperand returns the value set of a terminology operator: a
+// ValueSetRef, or an expression in valuesetExpression.
+func (r *elmRun) valueSetOperand(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	node := elmNode(n, "valueset")
+	if node == nil {
+		node = elmNode(n, "valuesetExpression")
+	}
+	if node != nil && elmString(node, "type") == "" {
+		// ELM before 1.5 gives the reference without a type.
+		node = map[string]interface{}{"type": "ValueSetRef", "name": node["name"], "libraryName": node["libraryName"]}
+	}
+	return r.eval(f, node)
+}
+
+// evalInValueSet implements InValueSet and AnyInValueSet. A string is a
+// code in any system.
+func (r *elmRun) evalInValueSet(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	operand := elmNode(n, "code")
+	if operand == nil {
+		operand = elmNode(n, "codes")
+	}
+	v, err := r.eval(f, operand)
+	if err != nil || v == nil {
+		if elmString(n, "type") == "AnyInValueSet" && err == nil {
+			return false, nil
+		}
+		return nil, err
+	}
+	vs, err := r.valueSetOperand(f, n)
+	if err != nil || vs == nil {
+		return nil, err
+	}
+	return r.codesMatch(elmCodesOf(v), vs)
+}
+
+// evalInCodeSystem implements InCodeSystem, which checks the code's
+// system: code systems are not enumerated.
+func (r *elmRun) evalInCodeSystem(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	operand := elmNode(n, "code")
+	if operand == nil {
+		operand = elmNode(n, "codes")
+	}
+	v, err := r.eval(f, operand)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	csNode := elmNode(n, "codesystem")
+	if csNode == nil {
+		csNode = elmNode(n, "codesystemExpression")
+	}
+	cs, err := r.eval(f, csNode)
+	if err != nil {
+		return nil, err
+	}
+	system, _ := cs.(string)
+	for _, c := range elmCodesOf(v) {
+		if c.System == system {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *elmRun) evalExpandValueSet(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	v, err := r.eval(f, elmNode(n, "operand"))
+	if err != nil || v == nil {
+		return nil, err
+	}
+	vs, ok := v.(cqlValueSet)
+	if !ok {
+		return nil, nil
+	}
+	if r.engine.terminology == nil {
+		return nil, fmt.Errorf("ELM: no terminology service to expand %s", vs.ID)
+	}
+	expansion, err := r.engine.terminology.ExpandValueSet(vs.ID, "", 0, 10000)
+	if err != nil {
+		return nil, fmt.Errorf("ELM: expanding %s: %w", vs.ID, err)
+	}
+	var out []interface{}
+	var add func([]ValueSetContains)
+	add = func(contains []ValueSetContains) {
+		for _, c := range contains {
+			if c.Code != "" {
+				out = append(out, CQLCode{Code: c.Code, System: c.System, Version: c.Version, Display: c.Display})
+			}
+			add(c.Contains)
+		}
+	}
+	add(expansion.Contains)
+	return out, nil
+}
+
+// ---------------------------------------------------------------------------
+// Queries
+// ---------------------------------------------------------------------------
+
+// evalQuery evaluates a Query: the cartesian product of its sources,
+// narrowed by with/without relationships and where, then let, return or
+// aggregate, and sort. A query over a single value, rather than a list,
+// returns a single value.
+func (r *elmRun) evalQuery(f *elmFrame, n map[string]interface{}) (interface{}, error) {
+	sources := elmNodes(n, "source")
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("ELM: query has no source")
+	}
+	aliases := make([]string, len(sources))
+	values := make([][]interface{}, len(sources))
+	singleton := len(sources) == 1
+	for i, src := range sources {
+		v, err := r.eval(f, elmNode(src, "expression"))
+		if err != nil {
+			return nil, err
+		}
+		aliases[i] = elmString(src, "alias")
+		if _, isList := v.([]interface{}); isList {
+			singleton = false
+		}
+		if v == nil && singleton {
+			return nil, nil
+		}
+		values[i] = elmList(v)
+	}
+
+	// Rows of the cartesian product of the sources, by alias.
+	rows := []map[string]interface{}{{}}
+	for i, list := range values {
+		var next []map[string]interface{}
+		for _, row := range rows {
+			for _, item := range list {
+				nr := make(map[string]interface{}, len(row)+1)
+				for k, v := range row {
+					nr[k] = v
+				}
+				nr[aliases[i]] = item
+				next = append(next, nr)
+			}
+		}
+		rows = next
+	}
+
+	ret := elmNode(n, "return")
+	agg := elmNode(n, "aggregate")
+	var out []interface{}
+	var total interface{}
+	if agg != nil {
+		v, err := r.eval(f, elmNode(agg, "starting"))
+		if err != nil {
+			return nil, err
+		}
+		total = v
+	}
+	var kept []map[string]interface{}
+	for _, row := range rows {
+		scope := f.with(row)
+		for _, let := range elmNodes(n, "let") {
+			v, err := r.eval(scope, elmNode(let, "expression"))
+			if err != nil {
+				return nil, err
+			}
+			scope.vars[elmString(let, "identifier")] = v
+		}
+		ok, err := r.relationships(scope, elmNodes(n, "relationship"))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if where := elmNode(n, "where"); where != nil {
+			v, err := r.eval(scope, where)
+			if err != nil {
+				return nil, err
+			}
+			if v != true {
+				continue
+			}
+		}
+		kept = append(kept, scope.vars)
+		switch {
+		case agg != nil:
+		case ret != nil:
+			v, err := r.eval(scope, elmNode(ret, "expression"))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		case len(aliases) == 1:
+			out = append(out, row[aliases[0]])
+		default:
+			tuple := make(map[string]interface{}, len(aliases))
+			for _, a := range aliases {
+				tuple[a] = row[a]
+			}
+			out = append(out, tuple)
+		}
+	}
+
+	if agg != nil {
+		if agg["distinct"] == true {
+			kept = elmDistinctRows(kept, aliases)
+		}
+		identifier := elmString(agg, "identifier")
+		for _, vars := range kept {
+			scope := f.with(vars)
+			scope.vars[identifier] = total
+			v, err := r.eval(scope, elmNode(agg, "expression"))
+			if err != nil {
+				return nil, err
+			}
+			total = v
+		}
+		return total, nil
+	}
+	if ret != nil && ret["distinct"] != false {
+		out = elmDistinct(out)
+	}
+	if s := elmNode(n, "sort"); s != nil {
+		sorted, err := r.sortList(f, out, elmNodes(s, "by"))
+		if err != nil {
+			return nil, err
+		}
+		out = sorted.([]interface{})
+	}
+	if singleton {
+		if len(out) == 0 {
+			return nil, nil
+		}
+		return out[0], nil
+	}
+	if out == nil {
+		out = []interface{}{}
+	}
+	return out, nil
+}
+
+// relationships evaluates the with and without clauses of a query row.
+func (r *elmRun) relationships(scope *elmFrame, rels []map[string]interface{}) (bool, error) {
+	for _, rel := range rels {
+		v, err := r.eval(scope, elmNode(rel, "expression"))
+		if err != nil {
+			return false, err
+		}
+		alias := elmString(rel, "alias")
+		found := false
+		for _, item := range elmList(v) {
+			inner := scope.with(map[string]interface{}{alias: item})
+			match, err := r.eval(inner, elmNode(rel, "suchThat"))
+			if err != nil {
+				return false, err
+			}
+			if match == true {
+				found = true
+				break
+			}
+		}
+		if found != (elmString(rel, "type") == "With") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// elmDistinctRows removes rows whose sources are all equal.
+func elmDistinctRows(rows []map[string]interface{}, aliases []string) []map[string]interface{} {
+	var out []map[string]interface{}
+	var keys []interface{}
+	for _, row := range rows {
+		key := make([]interface{}, len(aliases))
+		for i, a := range aliases {
+			key[i] = row[a]
+		}
+		if elmListContains(keys, []interface{}(key)) {
+			continue
+		}
+		keys = append(keys, []interface{}(key))
+		out = append(out, row)
+	}
+	return out
+}
+
+// sortList sorts items by sort clause items: by the items themselves
+// (ByDirection), by a property (ByColumn), or by an expression over each
+// item (ByExpression). Nulls sort first ascending.
+func (r *elmRun) sortList(f *elmFrame, items []interface{}, by []map[string]interface{}) (interface{}, error) {
+	if len(by) == 0 {
+		by = []map[string]interface{}{{"type": "ByDirection", "direction": "asc"}}
+	}
+	keys := make([][]interface{}, len(items))
+	for i, item := range items {
+		keys[i] = make([]interface{}, len(by))
+		for j, b := range by {
+			switch elmString(b, "type") {
+			case "ByColumn":
+				keys[i][j] = elmProperty(item, elmString(b, "path"))
+			case "ByExpression":
+				v, err := r.eval(f.with(map[string]interface{}{"$this": item}), elmNode(b, "expression"))
+				if err != nil {
+					return nil, err
+				}
+				keys[i][j] = v
+			default:
+				keys[i][j] = item
+			}
+		}
+	}
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(x, y int) bool {
+		for j, b := range by {
+			a, c := keys[idx[x]][j], keys[idx[y]][j]
+			var cmp int
+			switch {
+			case a == nil && c == nil:
+				continue
+			case a == nil:
+				cmp = -1
+			case c == nil:
+				cmp = 1
+			default:
+				cmp, _ = cqlCompare(a, c, -1)
+			}
+			if cmp == 0 {
+				continue
+			}
+			if d := elmString(b, "direction"); d == "desc" || d == "descending" {
+				cmp = -cmp
+			}
+			return cmp < 0
+		}
+		return false
+	})
+	out := make([]interface{}, len(items))
+	for i, j := range idx {
+		out[i] = items[j]
+	}
+	return out, nil
+}
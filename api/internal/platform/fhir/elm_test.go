@@ -0,0 +1,807 @@
+package fhir
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// ---------------------------------------------------------------------------
+// ELM builders
+// ---------------------------------------------------------------------------
+
+type elmN = map[string]interface{}
+
+func eLit(typ, value string) elmN {
+	return elmN{"type": "Literal", "valueType": "{urn:hl7-org:elm-types:r1}" + typ, "value": value}
+}
+
+func eInt(v string) elmN { return eLit("Integer", v) }
+
+func eOp(typ string, operands ...elmN) elmN {
+	if len(operands) == 1 {
+		return elmN{"type": typ, "operand": operands[0]}
+	}
+	list := make([]interface{}, len(operands))
+	for i, o := range operands {
+		list[i] = o
+	}
+	return elmN{"type": typ, "operand": list}
+}
+
+func ePrecision(n elmN, precision string) elmN {
+	n["precision"] = precision
+	return n
+}
+
+func eList(elements ...elmN) elmN {
+	list := make([]interface{}, len(elements))
+	for i, el := range elements {
+		list[i] = el
+	}
+	return elmN{"type": "List", "element": list}
+}
+
+func eRef(name string) elmN { return elmN{"type": "ExpressionRef", "name": name} }
+
+func eProp(source elmN, path string) elmN {
+	return elmN{"type": "Property", "path": path, "source": source}
+}
+
+func eHelper(name string, operand elmN) elmN {
+	return elmN{"type": "FunctionRef", "libraryName": "FHIRHelpers", "name": name, "operand": []interface{}{operand}}
+}
+
+func eRetrieve(resourceType string, codes elmN) elmN {
+	n := elmN{"type": "Retrieve", "dataType": "{http://hl7.org/fhir}" + resourceType}
+	if codes != nil {
+		n["codes"] = codes
+	}
+	return n
+}
+
+func eInterval(low, high elmN, lowClosed, highClosed bool) elmN {
+	return elmN{"type": "Interval", "low": low, "high": high, "lowClosed": lowClosed, "highClosed": highClosed}
+}
+
+// elmTestLibrary builds the ELM JSON of a library with expression and
+// function definitions.
+func elmTestLibrary(name string, sections elmN, statements ...elmN) []byte {
+	lib := elmN{"identifier": elmN{"id": name, "version": "1.0.0"}}
+	for k, v := range sections {
+		lib[k] = v
+	}
+	defs := make([]interface{}, len(statements))
+	for i, s := range statements {
+		defs[i] = s
+	}
+	lib["statements"] = elmN{"def": defs}
+	b, _ := json.Marshal(elmN{"library": lib})
+	return b
+}
+
+func eDef(name string, expr elmN) elmN {
+	return elmN{"name": name, "context": "Patient", "expression": expr}
+}
+
+func mustParseELM(t *testing.T, data []byte) *ELMLibrary {
+	t.Helper()
+	lib, err := ParseELMLibrary(data)
+	if err != nil {
+		t.Fatalf("ParseELMLibrary: %v", err)
+	}
+	return lib
+}
+
+// fakeExpander expands value sets from fixed codes.
+type fakeExpander map[string][]ValueSetContains
+
+func (f fakeExpander) ExpandValueSet(url, _ string, offset, count int) (*ExpandedValueSet, error) {
+	codes := f[url]
+	if offset > len(codes) {
+		offset = len(codes)
+	}
+	end := offset + count
+	if end > len(codes) {
+		end = len(codes)
+	}
+	return &ExpandedValueSet{URL: url, Total: len(codes), Offset: offset, Contains: codes[offset:end]}, nil
+}
+
+// fakeLibraryStore answers searches with fixed resources, keeping those
+// whose fields match the string parameters it knows.
+type fakeLibraryStore struct {
+	resources map[string][]map[string]interface{}
+	searches  []url.Values
+}
+
+func (s *fakeLibraryStore) Search(_ context.Context, resourceType string, params url.Values) ([]map[string]interface{}, error) {
+	s.searches = append(s.searches, params)
+	if params.Get("_offset") != "" && params.Get("_offset") != "0" {
+		return nil, nil
+	}
+	var out []map[string]interface{}
+	for _, res := range s.resources[resourceType] {
+		match := true
+		for _, key := range []string{"name", "url", "_id", "subject", "patient"} {
+			want := params.Get(key)
+			if want == "" {
+				continue
+			}
+			got := cqlGetString(res, strings.TrimPrefix(key, "_"))
+			if key == "subject" || key == "patient" {
+				ref, _ := res["subject"].(map[string]interface{})
+				got = cqlGetString(ref, "reference")
+			}
+			match = match && got == want
+		}
+		if match {
+			out = append(out, res)
+		}
+	}
+	return out, nil
+}
+
+// ---------------------------------------------------------------------------
+// Values
+// ---------------------------------------------------------------------------
+
+func TestParseCQLDateTime(t *testing.T) {
+	d, ok := ParseCQLDateTime("2024-03")
+	if !ok || d.Precision != cqlMonth || !d.Date {
+		t.Errorf("expected a month precision Date, got %+v", d)
+	}
+	d, ok = ParseCQLDateTime("2024-03-05T10:30:00.5-05:00")
+	if !ok || d.Precision != cqlMillisecond || d.Date || d.Time.UTC().Hour() != 15 {
+		t.Errorf("expected a millisecond DateTime at 15:30Z, got %+v", d)
+	}
+	if _, ok := ParseCQLDateTime("2024-02-30"); ok {
+		t.Error("expected an invalid date to be rejected")
+	}
+	if s := d.String(); s != "2024-03-05T10:30:00.500-05:00" {
+		t.Errorf("unexpected text %s", s)
+	}
+}
+
+func TestCQLDateTime_Arithmetic(t *testing.T) {
+	jan31, _ := ParseCQLDateTime("2024-01-31")
+	if got := jan31.add(1, cqlMonth).String(); got != "2024-02-29" {
+		t.Errorf("expected Jan 31 + 1 month to clamp to 2024-02-29, got %s", got)
+	}
+	if got := jan31.add(25, cqlHour).String(); got != "2024-02-01" {
+		t.Errorf("expected 25 hours added to a Date to add one day, got %s", got)
+	}
+
+	birth, _ := ParseCQLDateTime("1970-06-15")
+	before, _ := ParseCQLDateTime("2024-06-14")
+	on, _ := ParseCQLDateTime("2024-06-15")
+	if n, _ := durationBetween(birth, before, cqlYear); n != 53 {
+		t.Errorf("expected 53 whole years the day before the birthday, got %d", n)
+	}
+	if n, _ := durationBetween(birth, on, cqlYear); n != 54 {
+		t.Errorf("expected 54 years on the birthday, got %d", n)
+	}
+	if n, _ := differenceBetween(before, on, cqlYear); n != 0 {
+		t.Errorf("expected no year boundary crossed, got %d", n)
+	}
+	dec31, _ := ParseCQLDateTime("2023-12-31")
+	if n, _ := differenceBetween(dec31, on, cqlYear); n != 1 {
+		t.Errorf("expected one year boundary crossed, got %d", n)
+	}
+
+	year, _ := ParseCQLDateTime("2024")
+	if _, ok := compareDateTimes(year, on, -1); ok {
+		t.Error("expected comparing 2024 with a day in 2024 to be uncertain")
+	}
+	if c, ok := compareDateTimes(year, dec31, -1); !ok || c != 1 {
+		t.Errorf("expected 2024 to be after 2023-12-31, got %d %v", c, ok)
+	}
+	if eq, known := cqlEqual(CQLQuantity{Value: 1, Unit: "g"}, CQLQuantity{Value: 1000, Unit: "mg"}); !eq || !known {
+		t.Error("expected 1 g to equal 1000 mg")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Operators
+// ---------------------------------------------------------------------------
+
+func TestELMEngine_Operators(t *testing.T) {
+	date := func(s string) elmN { return eLit("Date", s) }
+	dateTime := func(s string) elmN { return eLit("DateTime", s) }
+	ivl := eInterval(eInt("1"), eInt("10"), true, false)
+	period := eInterval(dateTime("2024-01-01T00:00:00.000Z"), dateTime("2025-01-01T00:00:00.000Z"), true, false)
+	tests := []struct {
+		name string
+		expr elmN
+		want interface{}
+	}{
+		{"arithmetic", eOp("Add", eOp("Multiply", eInt("3"), eInt("4")), eInt("1")), int64(13)},
+		{"divide", eOp("Divide", eInt("7"), eInt("2")), 3.5},
+		{"null propagates", eOp("Add", eInt("1"), elmN{"type": "Null"}), nil},
+		{"three-valued and", eOp("And", eLit("Boolean", "false"), elmN{"type": "Null"}), false},
+		{"three-valued or", eOp("Or", eLit("Boolean", "false"), elmN{"type": "Null"}), nil},
+		{"string", eOp("Upper", eOp("Concatenate", eLit("String", "a"), eLit("String", "b"))), "AB"},
+		{"equivalent", eOp("Equivalent", eLit("String", "Foo  Bar"), eLit("String", "foo bar")), true},
+		{"quantity compare", eOp("Greater", elmN{"type": "Quantity", "value": 2, "unit": "g"}, elmN{"type": "Quantity", "value": 1500, "unit": "mg"}), true},
+		{"in open interval", eOp("In", eInt("10"), ivl), false},
+		{"in closed bound", eOp("In", eInt("1"), ivl), true},
+		{"interval end", eOp("End", ivl), int64(9)},
+		{"overlaps", eOp("Overlaps", ivl, eInterval(eInt("9"), eInt("20"), true, true)), true},
+		{"before", eOp("Before", ivl, eInterval(eInt("10"), eInt("20"), true, true)), true},
+		{"meets", eOp("Meets", ivl, eInterval(eInt("10"), eInt("20"), true, true)), true},
+		{"date in period", eOp("In", eOp("ToDateTime", date("2024-06-30")), period), true},
+		{"date after period", ePrecision(eOp("After", dateTime("2025-01-01T00:00:00.000Z"), period), "day"), true},
+		{"date arithmetic", eOp("Subtract", date("2024-03-31"), elmN{"type": "Quantity", "value": 1, "unit": "month"}), "2024-02-29"},
+		{"same month", ePrecision(eOp("SameAs", date("2024-03-01"), date("2024-03-31")), "month"), true},
+		{"years between", ePrecision(eOp("DurationBetween", date("2000-02-29"), date("2024-02-28")), "year"), int64(23)},
+		{"age at", ePrecision(eOp("CalculateAgeAt", date("1970-06-15"), date("2024-06-15")), "year"), int64(54)},
+		{"component", ePrecision(eOp("DateTimeComponentFrom", date("2024-03-31")), "month"), int64(3)},
+		{"date constructor", elmN{"type": "Date", "year": eInt("2024"), "month": eInt("2")}, "2024-02"},
+		{"count", elmN{"type": "Count", "source": eList(eInt("1"), elmN{"type": "Null"}, eInt("3"))}, int64(2)},
+		{"max", elmN{"type": "Max", "source": eList(eInt("1"), eInt("7"), eInt("3"))}, int64(7)},
+		{"distinct", elmN{"type": "Count", "source": eOp("Distinct", eList(eInt("1"), eInt("1"), eInt("2")))}, int64(2)},
+		{"union", eOp("Union", eList(eInt("1"), eInt("2")), eList(eInt("2"), eInt("3"))), []interface{}{int64(1), int64(2), int64(3)}},
+		{"except", eOp("Except", eList(eInt("1"), eInt("2")), eList(eInt("2"))), []interface{}{int64(1)}},
+		{"list contains", eOp("Contains", eList(eInt("1"), eInt("2")), eInt("2")), true},
+		{"exists", eOp("Exists", eList()), false},
+		{"case", elmN{"type": "Case", "caseItem": []interface{}{
+			elmN{"when": eOp("Greater", eInt("1"), eInt("2")), "then": eLit("String", "no")},
+			elmN{"when": eOp("Less", eInt("1"), eInt("2")), "then": eLit("String", "yes")},
+		}, "else": eLit("String", "else")}, "yes"},
+		{"coalesce", eOp("Coalesce", elmN{"type": "Null"}, eInt("5")), int64(5)},
+	}
+	var defs []elmN
+	for _, tt := range tests {
+		defs = append(defs, eDef(tt.name, tt.expr))
+	}
+	lib := mustParseELM(t, elmTestLibrary("Ops", nil, defs...))
+	engine := NewELMEngine(NewELMLibraryManager())
+	results, err := engine.Evaluate(context.Background(), lib, nil, ELMRequest{})
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	for _, tt := range tests {
+		got := results[tt.name]
+		if d, ok := got.(CQLDateTime); ok {
+			got = d.String()
+		}
+		gb, _ := json.Marshal(got)
+		wb, _ := json.Marshal(tt.want)
+		if string(gb) != string(wb) {
+			t.Errorf("%s: expected %s, got %s", tt.name, wb, gb)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Libraries, queries and retrieves
+// ---------------------------------------------------------------------------
+
+const (
+	testDiabetesVS = "http://example.org/fhir/ValueSet/diabetes"
+	testLibraryURL = "http://example.org/fhir/Library/DiabetesControl"
+)
+
+// commonELM is an included library with a function and a parameter.
+func commonELM() []byte {
+	return elmTestLibrary("Common", elmN{
+		"parameters": elmN{"def": []interface{}{elmN{"name": "Adult Age", "default": eInt("18")}}},
+	},
+		elmN{"type": "FunctionDef", "name": "IsAdult", "operand": []interface{}{elmN{"name": "age"}},
+			"expression": eOp("GreaterOrEqual", elmN{"type": "OperandRef", "name": "age"}, elmN{"type": "ParameterRef", "name": "Adult Age"})},
+	)
+}
+
+// diabetesELM is a measure library: adults with diabetes in the initial
+// population, and a most recent HbA1c above 9% in the measurement period
+// as the numerator.
+func diabetesELM() []byte {
+	mp := elmN{"type": "ParameterRef", "name": MeasurementPeriodParameter}
+	obsEffective := eHelper("ToDateTime", elmN{"type": "As", "asType": "{http://hl7.org/fhir}dateTime", "operand": elmN{"type": "Property", "path": "effective", "scope": "O"}})
+	return elmTestLibrary("DiabetesControl", elmN{
+		"includes": elmN{"def": []interface{}{
+			elmN{"localIdentifier": "FHIRHelpers", "path": "FHIRHelpers", "version": "4.0.1"},
+			elmN{"localIdentifier": "C", "path": "Common", "version": "1.0.0"},
+		}},
+		"parameters":  elmN{"def": []interface{}{elmN{"name": MeasurementPeriodParameter}}},
+		"codeSystems": elmN{"def": []interface{}{elmN{"name": "LOINC", "id": "http://loinc.org"}}},
+		"valueSets":   elmN{"def": []interface{}{elmN{"name": "Diabetes", "id": testDiabetesVS}}},
+		"codes": elmN{"def": []interface{}{
+			elmN{"name": "HbA1c", "id": "4548-4", "codeSystem": elmN{"name": "LOINC"}},
+		}},
+	},
+		eDef("Patient", eOp("SingletonFrom", eRetrieve("Patient", nil))),
+		eDef("Age", ePrecision(eOp("CalculateAgeAt",
+			eHelper("ToDate", eProp(eRef("Patient"), "birthDate")),
+			eOp("DateFrom", eOp("Start", mp))), "Year")),
+		eDef("Diabetes", eRetrieve("Condition", elmN{"type": "ValueSetRef", "name": "Diabetes"})),
+		eDef("Initial Population", eOp("And",
+			elmN{"type": "FunctionRef", "libraryName": "C", "name": "IsAdult", "operand": []interface{}{eRef("Age")}},
+			eOp("Exists", eRef("Diabetes")))),
+		eDef("Denominator", eRef("Initial Population")),
+		eDef("HbA1c Tests", elmN{"type": "Query",
+			"source": []interface{}{elmN{"alias": "O", "expression": eRetrieve("Observation", eOp("ToList", elmN{"type": "CodeRef", "name": "HbA1c"}))}},
+			"let":    []interface{}{elmN{"identifier": "When", "expression": obsEffective}},
+			"where":  eOp("In", elmN{"type": "QueryLetRef", "name": "When"}, mp),
+			"sort":   elmN{"by": []interface{}{elmN{"type": "ByColumn", "path": "effectiveDateTime", "direction": "asc"}}},
+		}),
+		eDef("Most Recent Value", eHelper("ToQuantity", elmN{"type": "As", "asType": "{http://hl7.org/fhir}Quantity",
+			"operand": eProp(elmN{"type": "Last", "source": eRef("HbA1c Tests")}, "value")})),
+		eDef("Numerator", eOp("Greater", eRef("Most Recent Value"), elmN{"type": "Quantity", "value": 9, "unit": "%"})),
+		eDef("Test Dates", elmN{"type": "Query",
+			"source": []interface{}{elmN{"alias": "O", "expression": eRef("HbA1c Tests")}},
+			"return": elmN{"expression": eHelper("ToDate", elmN{"type": "Property", "path": "effectiveDateTime", "scope": "O"})},
+			"sort":   elmN{"by": []interface{}{elmN{"type": "ByDirection", "direction": "desc"}}},
+		}),
+	)
+}
+
+func newTestELMEngine(t *testing.T) *ELMEngine {
+	t.Helper()
+	libs := NewELMLibraryManager()
+	libs.Register(mustParseELM(t, commonELM()))
+	lib := mustParseELM(t, diabetesELM())
+	lib.URL = testLibraryURL
+	libs.Register(lib)
+	engine := NewELMEngine(libs)
+	engine.SetTerminology(fakeExpander{testDiabetesVS: {
+		{System: "http://snomed.info/sct", Code: "44054006"},
+		{System: "http://hl7.org/fhir/sid/icd-10-cm", Code: "E11.9"},
+	}})
+	return engine
+}
+
+func testMeasurementPeriod() MeasurePeriod {
+	return MeasurePeriod{
+		Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC),
+	}
+}
+
+func TestELMEngine_QueryRetrieveIncludes(t *testing.T) {
+	engine := newTestELMEngine(t)
+	lib, err := engine.Libraries().Library(context.Background(), "DiabetesControl", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb := PatientBundle{Patient: diabeticPatient(), Resources: map[string][]map[string]interface{}{
+		"Condition": {diabetesCondition()},
+		"Observation": {
+			hba1cObservation(10.2, "2024-09-01T08:00:00Z"),
+			hba1cObservation(7.5, "2024-03-01T08:00:00Z"),
+			hba1cObservation(11, "2023-06-01T08:00:00Z"),
+		},
+	}}
+	req := ELMRequest{PatientID: "pt-diab-1", Data: NewBundleDataSource(pb),
+		Parameters: map[string]interface{}{MeasurementPeriodParameter: testMeasurementPeriod().interval()}}
+
+	results, err := engine.Evaluate(context.Background(), lib, nil, req)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if results["Age"] != int64(53) {
+		t.Errorf("expected age 53 at the start of 2024, got %v", results["Age"])
+	}
+	if results["Initial Population"] != true {
+		t.Errorf("expected the patient in the initial population, got %v", results["Initial Population"])
+	}
+	if tests := results["HbA1c Tests"].([]interface{}); len(tests) != 2 {
+		t.Errorf("expected the 2 tests in the period, got %d", len(tests))
+	}
+	if q, ok := results["Most Recent Value"].(CQLQuantity); !ok || q.Value != 10.2 || q.Unit != "%" {
+		t.Errorf("expected the September value 10.2 %%, got %v", results["Most Recent Value"])
+	}
+	if results["Numerator"] != true {
+		t.Errorf("expected the patient in the numerator, got %v", results["Numerator"])
+	}
+	dates := results["Test Dates"].([]interface{})
+	if len(dates) != 2 || dates[0].(CQLDateTime).String() != "2024-09-01" {
+		t.Errorf("expected test dates sorted descending, got %v", dates)
+	}
+
+	// Parameters given apply to included libraries too.
+	req.Parameters["Adult Age"] = int64(60)
+	results, err = engine.Evaluate(context.Background(), lib, []string{"Initial Population"}, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results["Initial Population"] != false {
+		t.Errorf("expected a 53 year old not to be an adult at 60, got %v", results["Initial Population"])
+	}
+}
+
+func TestELMEngine_Errors(t *testing.T) {
+	lib := mustParseELM(t, elmTestLibrary("Bad", nil,
+		eDef("Unknown", elmN{"type": "Frobnicate"}),
+		eDef("Singleton", eOp("SingletonFrom", eList(eInt("1"), eInt("2")))),
+		eDef("Conditions", eRetrieve("Condition", elmN{"type": "ValueSetRef", "name": "Missing"})),
+	))
+	engine := NewELMEngine(NewELMLibraryManager())
+	for _, name := range []string{"Unknown", "Singleton", "Conditions"} {
+		if _, err := engine.EvaluateExpression(context.Background(), lib, name, ELMRequest{}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := ParseELMLibrary([]byte(`{"library":{}}`)); err == nil {
+		t.Error("expected a library without an identifier to be rejected")
+	}
+}
+
+func eNamedType(name string) elmN {
+	return elmN{"type": "NamedTypeSpecifier", "name": "{urn:hl7-org:elm-types:r1}" + name}
+}
+
+// eFunction defines a function of one operand x of type spec.
+func eFunction(name string, spec, expr elmN) elmN {
+	return elmN{"type": "FunctionDef", "name": name, "expression": expr,
+		"operand": []interface{}{elmN{"name": "x", "operandTypeSpecifier": spec}}}
+}
+
+func eCall(name string, operand elmN) elmN {
+	return elmN{"type": "FunctionRef", "name": name, "operand": []interface{}{operand}}
+}
+
+func TestELMEngine_FunctionOverloads(t *testing.T) {
+	x := elmN{"type": "OperandRef", "name": "x"}
+	withSignature := eCall("Describe", elmN{"type": "Null"})
+	withSignature["signature"] = []interface{}{eNamedType("String")}
+	lib := mustParseELM(t, elmTestLibrary("Overloads", nil,
+		eFunction("Describe", eNamedType("Integer"), eLit("String", "integer")),
+		eFunction("Describe", eNamedType("String"), eLit("String", "string")),
+		eFunction("Describe", elmN{"type": "ListTypeSpecifier", "elementType": eNamedType("Integer")}, eLit("String", "list")),
+		eFunction("Half", eNamedType("Decimal"), eOp("Divide", x, eLit("Decimal", "2.0"))),
+		eDef("Integer", eCall("Describe", eInt("1"))),
+		eDef("String", eCall("Describe", eLit("String", "a"))),
+		eDef("List", eCall("Describe", eList(eInt("1"), eInt("2")))),
+		eDef("Null", eCall("Describe", elmN{"type": "Null"})),
+		eDef("Signature", withSignature),
+		eDef("Converted", eCall("Half", eInt("5"))),
+		eDef("NoMatch", eCall("Describe", eLit("Boolean", "true"))),
+	))
+	engine := NewELMEngine(NewELMLibraryManager())
+	results, err := engine.Evaluate(context.Background(), lib,
+		[]string{"Integer", "String", "List", "Null", "Signature", "Converted"}, ELMRequest{})
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	want := map[string]interface{}{
+		"Integer":   "integer",
+		"String":    "string",
+		"List":      "list",
+		"Null":      "integer",
+		"Signature": "string",
+		"Converted": 2.5,
+	}
+	for name, w := range want {
+		if results[name] != w {
+			t.Errorf("%s: expected %v, got %v", name, w, results[name])
+		}
+	}
+	if _, err := engine.EvaluateExpression(context.Background(), lib, "NoMatch", ELMRequest{}); err == nil {
+		t.Error("expected a call no overload takes to fail")
+	}
+}
+
+func TestELMEngine_FunctionDepthLimit(t *testing.T) {
+	x := elmN{"type": "OperandRef", "name": "x"}
+	lib := mustParseELM(t, elmTestLibrary("Recursive", nil,
+		eFunction("Forever", eNamedType("Integer"), eCall("Forever", eOp("Add", x, eInt("1")))),
+		eFunction("Countdown", eNamedType("Integer"), elmN{"type": "If",
+			"condition": eOp("LessOrEqual", x, eInt("0")),
+			"then":      eInt("0"),
+			"else":      eCall("Countdown", eOp("Subtract", x, eInt("1")))}),
+		eDef("Forever", eCall("Forever", eInt("0"))),
+		eDef("Countdown", eCall("Countdown", eInt("5"))),
+	))
+	engine := NewELMEngine(NewELMLibraryManager())
+	if _, err := engine.EvaluateExpression(context.Background(), lib, "Forever", ELMRequest{}); !errors.Is(err, ErrELMDepthExceeded) {
+		t.Errorf("expected ErrELMDepthExceeded, got %v", err)
+	}
+	if v, err := engine.EvaluateExpression(context.Background(), lib, "Countdown", ELMRequest{}); err != nil || v != int64(0) {
+		t.Errorf("expected a bounded recursion to finish, got %v, %v", v, err)
+	}
+	engine.SetMaxDepth(3)
+	if _, err := engine.EvaluateExpression(context.Background(), lib, "Countdown", ELMRequest{}); !errors.Is(err, ErrELMDepthExceeded) {
+		t.Errorf("expected the lowered limit to apply, got %v", err)
+	}
+}
+
+func elmLibraryResource(id, url string, elm []byte) map[string]interface{} {
+	var doc struct {
+		Library struct {
+			Identifier struct{ ID, Version string }
+		}
+	}
+	_ = json.Unmarshal(elm, &doc)
+	return map[string]interface{}{
+		"resourceType": "Library",
+		"id":           id,
+		"url":          url,
+		"name":         doc.Library.Identifier.ID,
+		"version":      doc.Library.Identifier.Version,
+		"content": []interface{}{map[string]interface{}{
+			"contentType": ELMContentType,
+			"data":        base64.StdEncoding.EncodeToString(elm),
+		}},
+	}
+}
+
+func TestELMLibraryManager_LoadsStoredLibraries(t *testing.T) {
+	store := &fakeLibraryStore{resources: map[string][]map[string]interface{}{
+		"Library": {
+			elmLibraryResource("lib-common", "http://example.org/fhir/Library/Common", commonELM()),
+			elmLibraryResource("lib-diabetes", testLibraryURL, diabetesELM()),
+		},
+	}}
+	libs := NewELMLibraryManager()
+	libs.SetStore(store)
+
+	lib, err := libs.LibraryByURL(context.Background(), testLibraryURL)
+	if err != nil {
+		t.Fatalf("LibraryByURL: %v", err)
+	}
+	if lib.Name != "DiabetesControl" || lib.URL != testLibraryURL {
+		t.Errorf("unexpected library %s %s", lib.Name, lib.URL)
+	}
+	// The include is loaded by name on first use.
+	if _, err := libs.Library(context.Background(), "Common", "1.0.0"); err != nil {
+		t.Fatalf("Library: %v", err)
+	}
+	n := len(store.searches)
+	if _, err := libs.Library(context.Background(), "Common", "1.0.0"); err != nil || len(store.searches) != n {
+		t.Error("expected a loaded library to be kept")
+	}
+	if _, err := libs.Library(context.Background(), "Missing", ""); err == nil {
+		t.Error("expected an unknown library to be reported")
+	}
+	if lib, err := libs.LibraryByID(context.Background(), "lib-common"); err != nil || lib.Name != "Common" {
+		t.Errorf("expected Library/lib-common to load, got %v", err)
+	}
+}
+
+// tenantLibraryStore answers searches from the store of the tenant in the
+// context.
+type tenantLibraryStore map[string]*fakeLibraryStore
+
+func (s tenantLibraryStore) Search(ctx context.Context, resourceType string, params url.Values) ([]map[string]interface{}, error) {
+	return s[db.TenantFromContext(ctx)].Search(ctx, resourceType, params)
+}
+
+func TestELMLibraryManager_ScopesLibrariesToTenant(t *testing.T) {
+	ctxA := context.WithValue(context.Background(), db.TenantIDKey, "a")
+	ctxB := context.WithValue(context.Background(), db.TenantIDKey, "b")
+	storeA := &fakeLibraryStore{resources: map[string][]map[string]interface{}{
+		"Library": {elmLibraryResource("lib-diabetes", testLibraryURL, diabetesELM())},
+	}}
+	storeB := &fakeLibraryStore{}
+	libs := NewELMLibraryManager()
+	libs.SetStore(tenantLibraryStore{"a": storeA, "b": storeB})
+
+	if _, err := libs.LibraryByURL(ctxA, testLibraryURL); err != nil {
+		t.Fatalf("LibraryByURL: %v", err)
+	}
+	if _, err := libs.LibraryByURL(ctxB, testLibraryURL); err == nil {
+		t.Error("expected a library loaded for one tenant not to be seen by another")
+	}
+	libs.RegisterTenant(ctxA, mustParseELM(t, commonELM()))
+	if _, err := libs.Library(ctxB, "Common", "1.0.0"); err == nil {
+		t.Error("expected a library registered for one tenant not to be seen by another")
+	}
+	if _, err := libs.Library(ctxA, "Common", "1.0.0"); err != nil {
+		t.Errorf("expected the tenant's registered library, got %v", err)
+	}
+
+	// A Library written by another tenant keeps the cache; one written by
+	// the tenant drops it.
+	n := len(storeA.searches)
+	libs.OnResourceEvent(ctxB, ResourceEvent{ResourceType: "Library", ResourceID: "lib-diabetes", Action: "update"})
+	if _, err := libs.LibraryByURL(ctxA, testLibraryURL); err != nil || len(storeA.searches) != n {
+		t.Error("expected another tenant's write to keep the cache")
+	}
+	libs.OnResourceEvent(ctxA, ResourceEvent{ResourceType: "Library", ResourceID: "lib-diabetes", Action: "delete"})
+	storeA.resources = nil
+	if _, err := libs.LibraryByURL(ctxA, testLibraryURL); err == nil || len(storeA.searches) != n+1 {
+		t.Error("expected a deleted library to be dropped from the cache")
+	}
+
+	// Loaded libraries are reloaded once older than the refresh interval.
+	storeA.resources = map[string][]map[string]interface{}{
+		"Library": {elmLibraryResource("lib-diabetes", testLibraryURL, diabetesELM())},
+	}
+	libs.SetRefresh(0)
+	libs.LibraryByURL(ctxA, testLibraryURL)
+	n = len(storeA.searches)
+	if _, err := libs.LibraryByURL(ctxA, testLibraryURL); err != nil || len(storeA.searches) != n+1 {
+		t.Error("expected an expired library to be reloaded")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Measures and $evaluate
+// ---------------------------------------------------------------------------
+
+func elmTestMeasure() *Measure {
+	return &Measure{
+		ID:      "diabetes-control",
+		URL:     "http://example.org/fhir/Measure/diabetes-control",
+		Status:  "active",
+		Scoring: "proportion",
+		Library: []string{testLibraryURL},
+		Group: []MeasureGroup{{Population: []MeasurePopulation{
+			{Code: "initial-population", Expression: "Initial Population"},
+			{Code: "denominator", Expression: "Denominator"},
+			{Code: "numerator", Expression: "Numerator"},
+		}}},
+	}
+}
+
+func TestMeasureEvaluator_ELMLibrary(t *testing.T) {
+	eval := newMeasureEvaluator()
+	eval.SetELMEngine(newTestELMEngine(t))
+	m := elmTestMeasure()
+	eval.RegisterMeasure(m)
+
+	poor := PatientBundle{Patient: diabeticPatient(), Resources: map[string][]map[string]interface{}{
+		"Condition":   {diabetesCondition()},
+		"Observation": {hba1cObservation(9.8, "2024-05-01")},
+	}}
+	controlled := PatientBundle{Patient: map[string]interface{}{"resourceType": "Patient", "id": "pt-2", "birthDate": "1980-01-01"},
+		Resources: map[string][]map[string]interface{}{
+			"Condition":   {diabetesCondition()},
+			"Observation": {hba1cObservation(6.1, "2024-05-01")},
+		}}
+	healthy := PatientBundle{Patient: map[string]interface{}{"resourceType": "Patient", "id": "pt-3", "birthDate": "1980-01-01"}}
+
+	report, err := eval.EvaluatePopulation(context.Background(), m.URL, []PatientBundle{poor, controlled, healthy}, testMeasurementPeriod())
+	if err != nil {
+		t.Fatalf("EvaluatePopulation: %v", err)
+	}
+	counts := map[string]int{}
+	for _, pop := range report.Group[0].Population {
+		counts[pop.Code] = pop.Count
+	}
+	if counts["initial-population"] != 2 || counts["denominator"] != 2 || counts["numerator"] != 1 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+	if score := report.Group[0].MeasureScore; score == nil || *score != 0.5 {
+		t.Errorf("expected a score of 0.5, got %v", score)
+	}
+
+	// The built-in measures keep their own logic.
+	if _, err := eval.EvaluateIndividual(context.Background(), CMS122URL, diabeticPatient(), poor.Resources, testMeasurementPeriod()); err != nil {
+		t.Errorf("expected CMS122 to evaluate, got %v", err)
+	}
+}
+
+func TestMeasureEvaluator_EvaluateSubject(t *testing.T) {
+	eval := newMeasureEvaluator()
+	eval.SetELMEngine(newTestELMEngine(t))
+	m := elmTestMeasure()
+	eval.RegisterMeasure(m)
+
+	cond := diabetesCondition()
+	obs := hba1cObservation(12, "2024-02-01")
+	obs["subject"] = map[string]interface{}{"reference": "Patient/pt-diab-1"}
+	store := &fakeLibraryStore{resources: map[string][]map[string]interface{}{
+		"Patient":     {diabeticPatient()},
+		"Condition":   {cond},
+		"Observation": {obs},
+	}}
+	if _, err := eval.EvaluateSubject(context.Background(), m.URL, "pt-diab-1", testMeasurementPeriod()); err == nil {
+		t.Error("expected an error without a resource store")
+	}
+	eval.SetResources(store)
+
+	report, err := eval.EvaluateSubject(context.Background(), m.URL, "pt-diab-1", testMeasurementPeriod())
+	if err != nil {
+		t.Fatalf("EvaluateSubject: %v", err)
+	}
+	for _, pop := range report.Group[0].Population {
+		if pop.Count != 1 {
+			t.Errorf("expected the subject in %s, got %d", pop.Code, pop.Count)
+		}
+	}
+	if _, err := eval.EvaluateSubject(context.Background(), CMS122URL, "pt-diab-1", testMeasurementPeriod()); err == nil {
+		t.Error("expected a measure without an ELM library to be rejected")
+	}
+}
+
+func TestMeasureHandler_EvaluateLibrary(t *testing.T) {
+	eval := newMeasureEvaluator()
+	e := echo.New()
+	NewMeasureHandler(eval).RegisterRoutes(e.Group("/fhir"))
+
+	serve := func(target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := serve("/fhir/Library/$evaluate", `{}`); rec.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 without an ELM engine, got %d", rec.Code)
+	}
+	eval.SetELMEngine(newTestELMEngine(t))
+
+	// Libraries posted with ELM content are registered with the engine.
+	res := elmLibraryResource("", "http://example.org/fhir/Library/Greeting", elmTestLibrary("Greeting", elmN{
+		"parameters": elmN{"def": []interface{}{elmN{"name": "Window"}}},
+	},
+		eDef("Hello", eLit("String", "hello")),
+		eDef("Window", elmN{"type": "ParameterRef", "name": "Window"}),
+	))
+	body, _ := json.Marshal(res)
+	if rec := serve("/fhir/Library", string(body)); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := serve("/fhir/Library/$evaluate", `{"resourceType":"Parameters","parameter":[
+		{"name":"url","valueCanonical":"http://example.org/fhir/Library/Greeting"},
+		{"name":"parameters","resource":{"resourceType":"Parameters","parameter":[
+			{"name":"Window","valuePeriod":{"start":"2024-01-01","end":"2024-12-31"}}]}}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var out map[string]interface{}
+	_ = json.Unmarshal(rec.Body.Bytes(), &out)
+	params := elmParameterList(out)
+	if len(params) != 2 || params[0]["valueString"] != "hello" {
+		t.Fatalf("unexpected result %s", rec.Body.String())
+	}
+	if period, _ := params[1]["valuePeriod"].(map[string]interface{}); period["start"] != "2024-01-01" {
+		t.Errorf("expected the Window parameter back as a Period, got %v", params[1])
+	}
+
+	// Measure library definitions against posted data.
+	bundle := map[string]interface{}{"resourceType": "Bundle", "entry": []interface{}{
+		map[string]interface{}{"resource": diabeticPatient()},
+		map[string]interface{}{"resource": diabetesCondition()},
+	}}
+	req, _ := json.Marshal(map[string]interface{}{"resourceType": "Parameters", "parameter": []interface{}{
+		map[string]interface{}{"name": "url", "valueCanonical": testLibraryURL},
+		map[string]interface{}{"name": "expression", "valueString": "Diabetes"},
+		map[string]interface{}{"name": "expression", "valueString": "Age"},
+		map[string]interface{}{"name": "data", "resource": bundle},
+		map[string]interface{}{"name": "parameters", "resource": map[string]interface{}{"resourceType": "Parameters", "parameter": []interface{}{
+			map[string]interface{}{"name": MeasurementPeriodParameter, "valuePeriod": map[string]interface{}{"start": "2024-01-01T00:00:00Z", "end": "2024-12-31T23:59:59Z"}},
+		}}},
+	}})
+	rec = serve("/fhir/Library/$evaluate", string(req))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &out)
+	params = elmParameterList(out)
+	if len(params) != 2 || params[0]["resource"] == nil || params[1]["valueInteger"] != float64(53) {
+		t.Errorf("expected the condition and the age, got %s", rec.Body.String())
+	}
+
+	if rec := serve("/fhir/Library/$evaluate", `{"resourceType":"Parameters","parameter":[{"name":"url","valueCanonical":"http://example.org/none"}]}`); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown library, got %d", rec.Code)
+	}
+
+	// A function that calls itself without end is rejected as too costly.
+	res = elmLibraryResource("", "http://example.org/fhir/Library/Loop", elmTestLibrary("Loop", nil,
+		eFunction("Loop", eNamedType("Integer"), eCall("Loop", elmN{"type": "OperandRef", "name": "x"})),
+		eDef("Loop", eCall("Loop", eInt("1"))),
+	))
+	body, _ = json.Marshal(res)
+	if rec := serve("/fhir/Library", string(body)); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = serve("/fhir/Library/$evaluate", `{"resourceType":"Parameters","parameter":[{"name":"url","valueCanonical":"http://example.org/fhir/Library/Loop"}]}`)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), IssueTypeTooCostly) {
+		t.Errorf("expected a too-costly OperationOutcome, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
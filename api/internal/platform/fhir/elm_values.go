@@ -0,0 +1,717 @@
+package fhir
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CQL runtime values.
+//
+// ELM expressions evaluate to Go values: nil for null, bool, int64 for
+// Integer and Long, float64 for Decimal, string, CQLDateTime for Date and
+// DateTime, CQLQuantity, CQLRatio, CQLInterval, CQLCode, CQLConcept,
+// []interface{} for lists, and map[string]interface{} for tuples and FHIR
+// resources and elements, which keep their JSON form. FHIR primitives are
+// plain JSON values; FHIRHelpers conversions turn them into CQL values.
+
+// Precisions of CQL date and time values, coarsest first.
+const (
+	cqlYear = iota
+	cqlMonth
+	cqlDay
+	cqlHour
+	cqlMinute
+	cqlSecond
+	cqlMillisecond
+)
+
+// cqlWeek is the precision of week durations, which have no date component.
+const cqlWeek = -2
+
+// cqlPrecisions maps ELM precision names, and the units of temporal
+// quantities, to precisions.
+var cqlPrecisions = map[string]int{
+	"year": cqlYear, "years": cqlYear, "a": cqlYear,
+	"month": cqlMonth, "months": cqlMonth, "mo": cqlMonth,
+	"week": cqlWeek, "weeks": cqlWeek, "wk": cqlWeek,
+	"day": cqlDay, "days": cqlDay, "d": cqlDay,
+	"hour": cqlHour, "hours": cqlHour, "h": cqlHour,
+	"minute": cqlMinute, "minutes": cqlMinute, "min": cqlMinute,
+	"second": cqlSecond, "seconds": cqlSecond, "s": cqlSecond,
+	"millisecond": cqlMillisecond, "milliseconds": cqlMillisecond, "ms": cqlMillisecond,
+}
+
+// cqlPrecision returns the precision named by an ELM precision or a
+// temporal unit, or -1 if name is empty or unknown.
+func cqlPrecision(name string) int {
+	if p, ok := cqlPrecisions[strings.ToLower(name)]; ok {
+		return p
+	}
+	return -1
+}
+
+// CQLDateTime is a CQL Date or DateTime: a point in time known to a
+// precision. Dates have no time zone; DateTimes without an offset are taken
+// to be in UTC.
+type CQLDateTime struct {
+	Time      time.Time
+	Precision int
+	// Date marks a Date rather than a DateTime.
+	Date bool
+}
+
+// cqlDateTimePattern matches FHIR date, dateTime and instant values, and
+// the text of CQL date and datetime literals without the @.
+var cqlDateTimePattern = regexp.MustCompile(`^(\d{4})(?:-(\d{2})(?:-(\d{2})(?:T(\d{2})(?::(\d{2})(?::(\d{2})(?:\.(\d+))?)?)?)?)?)?(Z|[+-]\d{2}:\d{2})?$`)
+
+// ParseCQLDateTime parses a FHIR date or dateTime. Values with a time are
+// DateTimes, and so are values with a T and no time; others are Dates.
+func ParseCQLDateTime(s string) (CQLDateTime, bool) {
+	s = strings.TrimPrefix(s, "@")
+	dateTime := strings.HasSuffix(s, "T")
+	s = strings.TrimSuffix(s, "T")
+	m := cqlDateTimePattern.FindStringSubmatch(s)
+	if m == nil {
+		return CQLDateTime{}, false
+	}
+	parts := [6]int{0, 1, 1, 0, 0, 0}
+	precision := cqlYear - 1
+	for i := 0; i < 6; i++ {
+		if m[i+1] == "" {
+			break
+		}
+		parts[i], _ = strconv.Atoi(m[i+1])
+		precision++
+	}
+	nanos := 0
+	if m[7] != "" {
+		ms := (m[7] + "00")[:3]
+		n, _ := strconv.Atoi(ms)
+		nanos = n * int(time.Millisecond)
+		precision = cqlMillisecond
+	}
+	loc := time.UTC
+	if tz := m[8]; tz != "" && tz != "Z" {
+		h, _ := strconv.Atoi(tz[1:3])
+		mi, _ := strconv.Atoi(tz[4:6])
+		offset := h*3600 + mi*60
+		if tz[0] == '-' {
+			offset = -offset
+		}
+		loc = time.FixedZone(tz, offset)
+	}
+	t := time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], nanos, loc)
+	if t.Month() != time.Month(parts[1]) || t.Day() != parts[2] {
+		return CQLDateTime{}, false
+	}
+	return CQLDateTime{Time: t, Precision: precision, Date: precision <= cqlDay && m[8] == "" && !dateTime}, true
+}
+
+// String formats the value as a FHIR date or dateTime to its precision.
+func (d CQLDateTime) String() string {
+	layouts := []string{"2006", "2006-01", "2006-01-02", "2006-01-02T15", "2006-01-02T15:04", "2006-01-02T15:04:05", "2006-01-02T15:04:05.000"}
+	s := d.Time.Format(layouts[d.Precision])
+	if !d.Date && d.Precision >= cqlHour {
+		s += d.Time.Format("Z07:00")
+	}
+	return s
+}
+
+// MarshalJSON encodes the value as its FHIR text.
+func (d CQLDateTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// components returns the year, month, day, hour, minute, second and
+// millisecond of the value, DateTimes in UTC.
+func (d CQLDateTime) components() [7]int {
+	t := d.Time
+	if !d.Date {
+		t = t.UTC()
+	}
+	return [7]int{t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond() / int(time.Millisecond)}
+}
+
+// compareDateTimes compares a and b to precision, or to the finer of their
+// precisions if precision is negative. The comparison is uncertain, and ok
+// false, when the values are equal as far as the coarser one is known but
+// that is not as far as the comparison goes.
+func compareDateTimes(a, b CQLDateTime, precision int) (cmp int, ok bool) {
+	if precision < 0 {
+		precision = a.Precision
+		if b.Precision > precision {
+			precision = b.Precision
+		}
+	}
+	if a.Date != b.Date {
+		// Compare a Date with a DateTime as dates.
+		a, b = a.asDate(), b.asDate()
+		if precision > cqlDay {
+			precision = cqlDay
+		}
+	}
+	ca, cb := a.components(), b.components()
+	for i := cqlYear; i <= precision; i++ {
+		if i > a.Precision || i > b.Precision {
+			return 0, false
+		}
+		if ca[i] != cb[i] {
+			if ca[i] < cb[i] {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// asDate returns the date of a DateTime in UTC.
+func (d CQLDateTime) asDate() CQLDateTime {
+	if d.Date {
+		return d
+	}
+	t := d.Time.UTC()
+	p := d.Precision
+	if p > cqlDay {
+		p = cqlDay
+	}
+	return CQLDateTime{Time: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), Precision: p, Date: true}
+}
+
+// asDateTime returns a Date as a DateTime at the start of its day in UTC.
+func (d CQLDateTime) asDateTime() CQLDateTime {
+	d.Date = false
+	return d
+}
+
+// truncate drops the components of d finer than precision.
+func (d CQLDateTime) truncate(precision int) CQLDateTime {
+	if precision >= d.Precision {
+		return d
+	}
+	c := d.components()
+	for i := precision + 1; i < len(c); i++ {
+		c[i] = 0
+		if i <= cqlDay {
+			c[i] = 1
+		}
+	}
+	loc := time.UTC
+	if d.Date {
+		loc = d.Time.Location()
+	}
+	return CQLDateTime{
+		Time:      time.Date(c[0], time.Month(c[1]), c[2], c[3], c[4], c[5], c[6]*int(time.Millisecond), loc),
+		Precision: precision,
+		Date:      d.Date,
+	}
+}
+
+// add adds n units of precision unit to d. Months and years keep the day of
+// month, clamped to the length of the resulting month; units finer than d
+// are added in whole units of its precision.
+func (d CQLDateTime) add(n int64, unit int) CQLDateTime {
+	if unit == cqlWeek {
+		n, unit = n*7, cqlDay
+	}
+	if unit > d.Precision {
+		// Finer units are converted to the precision of d and truncated:
+		// 25 hours added to a Date is one day.
+		ms := map[int]float64{cqlDay: 86400000, cqlHour: 3600000, cqlMinute: 60000, cqlSecond: 1000, cqlMillisecond: 1}
+		total := float64(n) * ms[unit]
+		switch d.Precision {
+		case cqlYear:
+			n = int64(total / (365 * ms[cqlDay]))
+		case cqlMonth:
+			n = int64(total / (30 * ms[cqlDay]))
+		default:
+			n = int64(total / ms[d.Precision])
+		}
+		unit = d.Precision
+	}
+	t := d.Time
+	switch unit {
+	case cqlYear:
+		t = addMonthsClamped(t, int(n)*12)
+	case cqlMonth:
+		t = addMonthsClamped(t, int(n))
+	case cqlDay:
+		t = t.AddDate(0, 0, int(n))
+	case cqlHour:
+		t = t.Add(time.Duration(n) * time.Hour)
+	case cqlMinute:
+		t = t.Add(time.Duration(n) * time.Minute)
+	case cqlSecond:
+		t = t.Add(time.Duration(n) * time.Second)
+	case cqlMillisecond:
+		t = t.Add(time.Duration(n) * time.Millisecond)
+	}
+	d.Time = t
+	return d
+}
+
+// addMonthsClamped adds months to t, clamping the day to the end of the
+// resulting month (Jan 31 + 1 month is the last day of February).
+func addMonthsClamped(t time.Time, months int) time.Time {
+	y, m, day := t.Date()
+	first := time.Date(y, m, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()).AddDate(0, months, 0)
+	last := first.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// durationBetween returns the number of whole periods of precision from a
+// to b, negative if b is before a, or false if either is not known to that
+// precision.
+func durationBetween(a, b CQLDateTime, precision int) (int64, bool) {
+	unit := precision
+	if unit == cqlWeek {
+		unit = cqlDay
+	}
+	if unit > a.Precision || unit > b.Precision {
+		return 0, false
+	}
+	if a.Date != b.Date {
+		a, b = a.asDateTime(), b.asDateTime()
+	}
+	if cmp, _ := compareDateTimes(a, b, -1); cmp > 0 {
+		n, ok := durationBetween(b, a, precision)
+		return -n, ok
+	}
+	ca, cb := a.components(), b.components()
+	var n int64
+	switch unit {
+	case cqlYear, cqlMonth:
+		months := int64(cb[0]-ca[0])*12 + int64(cb[1]-ca[1])
+		// The last month is not complete if b is earlier in its month.
+		if compareComponents(cb[2:], ca[2:]) < 0 {
+			months--
+		}
+		if unit == cqlYear {
+			n = months / 12
+		} else {
+			n = months
+		}
+	case cqlDay:
+		days := int64(math.Floor(civilDays(cb) - civilDays(ca)))
+		if compareComponents(cb[3:], ca[3:]) < 0 {
+			days--
+		}
+		n = days
+		if precision == cqlWeek {
+			n = days / 7
+		}
+	default:
+		n = durationInUnit(b.Time.Sub(a.Time), unit)
+	}
+	return n, true
+}
+
+// differenceBetween returns the number of boundaries of precision crossed
+// from a to b, or false if either is not known to that precision.
+func differenceBetween(a, b CQLDateTime, precision int) (int64, bool) {
+	unit := precision
+	if unit == cqlWeek {
+		unit = cqlDay
+	}
+	if unit > a.Precision || unit > b.Precision {
+		return 0, false
+	}
+	if a.Date != b.Date {
+		a, b = a.asDateTime(), b.asDateTime()
+	}
+	ta, tb := a.truncate(unit), b.truncate(unit)
+	ca, cb := ta.components(), tb.components()
+	switch unit {
+	case cqlYear:
+		return int64(cb[0] - ca[0]), true
+	case cqlMonth:
+		return int64(cb[0]-ca[0])*12 + int64(cb[1]-ca[1]), true
+	case cqlDay:
+		days := int64(civilDays(cb) - civilDays(ca))
+		if precision == cqlWeek {
+			// Weeks start on Sunday.
+			wa := int64(civilDays(ca)) - int64(ta.Time.Weekday())
+			wb := int64(civilDays(cb)) - int64(tb.Time.Weekday())
+			return (wb - wa) / 7, true
+		}
+		return days, true
+	default:
+		return durationInUnit(tb.Time.Sub(ta.Time), unit), true
+	}
+}
+
+// civilDays returns the day number of the date of c.
+func civilDays(c [7]int) float64 {
+	return float64(time.Date(c[0], time.Month(c[1]), c[2], 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+func compareComponents(a, b []int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func durationInUnit(d time.Duration, unit int) int64 {
+	switch unit {
+	case cqlHour:
+		return int64(d / time.Hour)
+	case cqlMinute:
+		return int64(d / time.Minute)
+	case cqlSecond:
+		return int64(d / time.Second)
+	default:
+		return int64(d / time.Millisecond)
+	}
+}
+
+// CQLQuantity is a CQL Quantity: a decimal value with a UCUM unit, or a
+// calendar duration unit such as "days".
+type CQLQuantity struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+// convertTo returns the value of q in unit, if the units are commensurable.
+func (q CQLQuantity) convertTo(unit string) (float64, bool) {
+	from, to := normalizeCQLUnit(q.Unit), normalizeCQLUnit(unit)
+	if from == to {
+		return q.Value, true
+	}
+	v, err := ConvertUCUM(q.Value, from, to)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// normalizeCQLUnit maps calendar duration units to UCUM and the empty unit
+// to "1".
+func normalizeCQLUnit(unit string) string {
+	switch strings.ToLower(unit) {
+	case "", "1":
+		return "1"
+	case "year", "years":
+		return "a"
+	case "month", "months":
+		return "mo"
+	case "week", "weeks":
+		return "wk"
+	case "day", "days":
+		return "d"
+	case "hour", "hours":
+		return "h"
+	case "minute", "minutes":
+		return "min"
+	case "second", "seconds":
+		return "s"
+	case "millisecond", "milliseconds":
+		return "ms"
+	}
+	return unit
+}
+
+// CQLRatio is a CQL Ratio of two quantities.
+type CQLRatio struct {
+	Numerator   CQLQuantity `json:"numerator"`
+	Denominator CQLQuantity `json:"denominator"`
+}
+
+// CQLInterval is a CQL Interval. A nil bound is unbounded.
+type CQLInterval struct {
+	Low        interface{} `json:"low"`
+	High       interface{} `json:"high"`
+	LowClosed  bool        `json:"lowClosed"`
+	HighClosed bool        `json:"highClosed"`
+}
+
+// CQLCode is a CQL Code.
+type CQLCode struct {
+	Code    string `json:"code"`
+	System  string `json:"system,omitempty"`
+	Version string `json:"version,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+// CQLConcept is a CQL Concept: codes that mean the same thing.
+type CQLConcept struct {
+	Codes   []CQLCode `json:"codes"`
+	Display string    `json:"display,omitempty"`
+}
+
+// cqlValueSet is a reference to a value set, which terminology operators
+// and retrieves expand through the terminology service.
+type cqlValueSet struct {
+	ID      string
+	Version string
+}
+
+// cqlToInt64 returns an Integer or a whole Decimal as an int64.
+func cqlToInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case float64:
+		if n == math.Trunc(n) {
+			return int64(n), true
+		}
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// cqlNumber returns a numeric value as a float64.
+func cqlNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// cqlDateTimeOf returns v as a CQLDateTime, parsing FHIR date and dateTime
+// strings.
+func cqlDateTimeOf(v interface{}) (CQLDateTime, bool) {
+	switch d := v.(type) {
+	case CQLDateTime:
+		return d, true
+	case string:
+		return ParseCQLDateTime(d)
+	}
+	return CQLDateTime{}, false
+}
+
+// cqlCompare orders two values of the same kind: numbers, strings, dates
+// and times, and quantities with commensurable units. ok is false if they
+// cannot be ordered, or the order is uncertain.
+func cqlCompare(a, b interface{}, precision int) (cmp int, ok bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+	if x, ok := cqlNumber(a); ok {
+		if y, ok := cqlNumber(b); ok {
+			return compareFloats(x, y), true
+		}
+	}
+	switch x := a.(type) {
+	case CQLDateTime:
+		if y, ok := cqlDateTimeOf(b); ok {
+			return compareDateTimes(x, y, precision)
+		}
+	case CQLQuantity:
+		if y, ok := b.(CQLQuantity); ok {
+			if v, ok := y.convertTo(x.Unit); ok {
+				return compareFloats(x.Value, v), true
+			}
+		}
+	case string:
+		if y, ok := b.(CQLDateTime); ok {
+			if xd, ok := ParseCQLDateTime(x); ok {
+				return compareDateTimes(xd, y, precision)
+			}
+		}
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	}
+	return 0, false
+}
+
+func compareFloats(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// cqlEqual implements CQL equality. known is false when the result is
+// null: either operand is null, or the comparison is uncertain.
+func cqlEqual(a, b interface{}) (equal, known bool) {
+	if a == nil || b == nil {
+		return false, false
+	}
+	switch x := a.(type) {
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y, true
+	case string:
+		if y, ok := b.(string); ok {
+			return x == y, true
+		}
+	case CQLCode:
+		y, ok := b.(CQLCode)
+		return ok && x.Code == y.Code && x.System == y.System && (x.Version == "" || y.Version == "" || x.Version == y.Version), true
+	case CQLConcept:
+		y, ok := b.(CQLConcept)
+		if !ok || len(x.Codes) != len(y.Codes) {
+			return false, true
+		}
+		for i := range x.Codes {
+			if eq, _ := cqlEqual(x.Codes[i], y.Codes[i]); !eq {
+				return false, true
+			}
+		}
+		return true, true
+	case CQLInterval:
+		y, ok := b.(CQLInterval)
+		if !ok {
+			return false, true
+		}
+		if x.LowClosed != y.LowClosed || x.HighClosed != y.HighClosed {
+			return false, true
+		}
+		lowEq, lowKnown := cqlBoundEqual(x.Low, y.Low)
+		highEq, highKnown := cqlBoundEqual(x.High, y.High)
+		if (lowKnown && !lowEq) || (highKnown && !highEq) {
+			return false, true
+		}
+		return lowEq && highEq, lowKnown && highKnown
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false, true
+		}
+		known := true
+		for i := range x {
+			eq, k := cqlEqual(x[i], y[i])
+			if k && !eq {
+				return false, true
+			}
+			known = known && k
+		}
+		return known, known
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		return ok && reflect.DeepEqual(x, y), true
+	}
+	if cmp, ok := cqlCompare(a, b, -1); ok {
+		return cmp == 0, true
+	}
+	if _, ok := cqlCompare(a, b, -1); !ok {
+		// Uncertain dates, or values of different kinds.
+		if _, isDate := cqlDateTimeOf(a); isDate {
+			if _, isDate := cqlDateTimeOf(b); isDate {
+				return false, false
+			}
+		}
+	}
+	return false, true
+}
+
+// cqlBoundEqual compares interval bounds, two of which may be unbounded.
+func cqlBoundEqual(a, b interface{}) (bool, bool) {
+	if a == nil && b == nil {
+		return true, true
+	}
+	return cqlEqual(a, b)
+}
+
+// cqlEquivalent implements CQL equivalence: nulls are equivalent, strings
+// match ignoring case and runs of white space, codes match on code and
+// system, and a concept matches any of its codes.
+func cqlEquivalent(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.EqualFold(strings.Join(strings.Fields(x), " "), strings.Join(strings.Fields(y), " "))
+		}
+	case CQLCode:
+		switch y := b.(type) {
+		case CQLCode:
+			return x.Code == y.Code && x.System == y.System
+		case CQLConcept:
+			return cqlEquivalent(y, x)
+		}
+	case CQLConcept:
+		for _, c := range x.Codes {
+			switch y := b.(type) {
+			case CQLCode:
+				if cqlEquivalent(c, y) {
+					return true
+				}
+			case CQLConcept:
+				for _, d := range y.Codes {
+					if cqlEquivalent(c, d) {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !cqlEquivalent(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case CQLQuantity:
+		if y, ok := b.(CQLQuantity); ok {
+			if v, ok := y.convertTo(x.Unit); ok {
+				return x.Value == v
+			}
+			return false
+		}
+	}
+	eq, known := cqlEqual(a, b)
+	return known && eq
+}
+
+// cqlString returns the text of a value for ToString and Concatenate.
+func cqlString(v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return x, true
+	case bool:
+		return strconv.FormatBool(x), true
+	case int64:
+		return strconv.FormatInt(x, 10), true
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	case CQLDateTime:
+		return x.String(), true
+	case CQLQuantity:
+		return fmt.Sprintf("%s '%s'", strconv.FormatFloat(x.Value, 'f', -1, 64), x.Unit), true
+	case CQLCode:
+		return x.Code, true
+	}
+	return fmt.Sprintf("%v", v), true
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -15,12 +16,26 @@ import (
 // The patientID is the patient's FHIR ID (UUID string).
 type PatientResourceFetcher func(ctx context.Context, patientID string) ([]map[string]interface{}, error)
 
+// everythingReferencedTypes are the resource types outside the Patient
+// compartment that $everything adds when the patient's resources reference
+// them.
+var everythingReferencedTypes = map[string]bool{
+	"Practitioner":      true,
+	"PractitionerRole":  true,
+	"Organization":      true,
+	"Location":          true,
+	"HealthcareService": true,
+	"Medication":        true,
+	"Substance":         true,
+}
+
 // EverythingHandler implements the FHIR Patient/$everything operation.
 // It aggregates data from all registered fetchers into a single searchset Bundle.
 type EverythingHandler struct {
 	fetchers       map[string]PatientResourceFetcher
 	order          []string
 	patientFetcher func(ctx context.Context, fhirID string) (map[string]interface{}, error)
+	resources      *ResourceRegistry
 }
 
 // NewEverythingHandler creates a new EverythingHandler.
@@ -35,6 +50,14 @@ func (h *EverythingHandler) SetPatientFetcher(fn func(ctx context.Context, fhirI
 	h.patientFetcher = fn
 }
 
+// SetResourceRegistry makes the handler add the practitioners,
+// organizations, locations and medications referenced by the patient's
+// resources, resolved through resources, as include entries. The Patient is
+// also read through resources when no patient fetcher is set.
+func (h *EverythingHandler) SetResourceRegistry(resources *ResourceRegistry) {
+	h.resources = resources
+}
+
 // RegisterFetcher registers a fetcher for the given FHIR resource type.
 // Registration order determines the order of resources in the output Bundle.
 func (h *EverythingHandler) RegisterFetcher(resourceType string, fn PatientResourceFetcher) {
@@ -81,10 +104,16 @@ func (h *EverythingHandler) Handle(c echo.Context) error {
 	ctx := c.Request().Context()
 
 	// Fetch the Patient resource
-	if h.patientFetcher == nil {
+	patientFetcher := h.patientFetcher
+	if patientFetcher == nil && h.resources != nil {
+		patientFetcher = func(ctx context.Context, fhirID string) (map[string]interface{}, error) {
+			return h.resources.Read(ctx, "Patient", fhirID)
+		}
+	}
+	if patientFetcher == nil {
 		return c.JSON(http.StatusInternalServerError, ErrorOutcome("patient fetcher not configured"))
 	}
-	patient, err := h.patientFetcher(ctx, fhirID)
+	patient, err := patientFetcher(ctx, fhirID)
 	if err != nil || patient == nil {
 		return c.JSON(http.StatusNotFound, NotFoundOutcome("Patient", fhirID))
 	}
@@ -132,6 +161,10 @@ func (h *EverythingHandler) Handle(c echo.Context) error {
 		}
 	}
 
+	if h.resources != nil {
+		entries = append(entries, h.referencedEntries(ctx, entries, typeFilter)...)
+	}
+
 	total := len(entries)
 	bundle := &Bundle{
 		ResourceType: "Bundle",
@@ -142,3 +175,50 @@ func (h *EverythingHandler) Handle(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, bundle)
 }
+
+// referencedEntries resolves the references from entries to resources of the
+// everythingReferencedTypes and returns them as include entries.
+func (h *EverythingHandler) referencedEntries(ctx context.Context, entries []BundleEntry, typeFilter map[string]bool) []BundleEntry {
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		seen[e.FullURL] = true
+	}
+	var refs []string
+	for _, e := range entries {
+		var res map[string]interface{}
+		if err := json.Unmarshal(e.Resource, &res); err != nil {
+			continue
+		}
+		for _, ref := range extractReferences(res) {
+			rt, id, ok := SplitReference(ref)
+			if !ok || !everythingReferencedTypes[rt] || (typeFilter != nil && !typeFilter[rt]) {
+				continue
+			}
+			key := rt + "/" + id
+			if !seen[key] {
+				seen[key] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	sort.Strings(refs)
+
+	var included []BundleEntry
+	for _, ref := range refs {
+		res, err := h.resources.ResolveReference(ctx, ref)
+		if err != nil {
+			continue
+		}
+		raw, err := json.Marshal(res)
+		if err != nil {
+			continue
+		}
+		rt, id, _ := SplitReference(ref)
+		included = append(included, BundleEntry{
+			FullURL:  fmt.Sprintf("%s/%s", rt, id),
+			Resource: raw,
+			Search:   &BundleSearch{Mode: "include"},
+		})
+	}
+	return included
+}
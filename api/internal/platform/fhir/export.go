@@ -1,17 +1,26 @@
 package fhir
 
 import (
-	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/blobstore"
 )
 
 // ResourceExporter defines the interface for exporting FHIR resources.
@@ -24,17 +33,57 @@ type ResourceExporter interface {
 	ExportByPatient(ctx context.Context, patientID string, since *time.Time) ([]map[string]interface{}, error)
 }
 
+// StreamingResourceExporter is implemented by exporters that hand resources
+// to the export engine one at a time, so that the resources of a type are
+// never held in memory together. Resources must come in a stable order: a
+// job resumed after a restart skips the resources it had already written.
+type StreamingResourceExporter interface {
+	ResourceExporter
+	// StreamAll calls fn for each resource of this type.
+	StreamAll(ctx context.Context, since *time.Time, fn func(map[string]interface{}) error) error
+	// StreamByPatient calls fn for each resource of a specific patient.
+	StreamByPatient(ctx context.Context, patientID string, since *time.Time, fn func(map[string]interface{}) error) error
+}
+
+// ResumableResourceExporter is implemented by streaming exporters that can
+// start a system-level export after a number of resources, so that a job
+// resumed after a restart does not read again the resources it had already
+// written.
+type ResumableResourceExporter interface {
+	StreamingResourceExporter
+	// StreamAllFrom calls fn for each resource of this type after the first
+	// offset, in the order of StreamAll.
+	StreamAllFrom(ctx context.Context, since *time.Time, offset int, fn func(map[string]interface{}) error) error
+}
+
 // ServiceExporter is a generic adapter that wraps domain service list
 // functions to implement the ResourceExporter interface. Callers supply
 // function values that delegate to the appropriate domain service methods.
+//
+// PageFn, when set, lists the resources a page at a time and is used
+// instead of ListFn, so that a system-level export streams the type.
 type ServiceExporter struct {
 	ResourceType    string
 	ListFn          func(ctx context.Context, since *time.Time) ([]map[string]interface{}, error)
 	ListByPatientFn func(ctx context.Context, patientID string, since *time.Time) ([]map[string]interface{}, error)
+	PageFn          func(ctx context.Context, since *time.Time, limit, offset int) ([]map[string]interface{}, error)
 }
 
-// ExportAll delegates to ListFn if set, otherwise returns an empty slice.
+// exportPageSize is the number of resources read per page when streaming
+// through ServiceExporter.PageFn or the search layer.
+const exportPageSize = 100
+
+// ExportAll delegates to ListFn, or collects the pages of PageFn, if set;
+// otherwise it returns an empty slice.
 func (s *ServiceExporter) ExportAll(ctx context.Context, since *time.Time) ([]map[string]interface{}, error) {
+	if s.PageFn != nil {
+		var out []map[string]interface{}
+		err := s.StreamAll(ctx, since, func(r map[string]interface{}) error {
+			out = append(out, r)
+			return nil
+		})
+		return out, err
+	}
 	if s.ListFn == nil {
 		return nil, nil
 	}
@@ -49,6 +98,80 @@ func (s *ServiceExporter) ExportByPatient(ctx context.Context, patientID string,
 	return s.ListByPatientFn(ctx, patientID, since)
 }
 
+// StreamAll calls fn for each resource of PageFn, a page at a time until a
+// page comes back empty, or for each resource of ListFn.
+func (s *ServiceExporter) StreamAll(ctx context.Context, since *time.Time, fn func(map[string]interface{}) error) error {
+	return s.StreamAllFrom(ctx, since, 0, fn)
+}
+
+// StreamAllFrom is StreamAll starting after the first offset resources.
+// PageFn reads from the offset; the resources of ListFn are skipped.
+func (s *ServiceExporter) StreamAllFrom(ctx context.Context, since *time.Time, offset int, fn func(map[string]interface{}) error) error {
+	if s.PageFn == nil {
+		resources, err := s.ExportAll(ctx, since)
+		if err != nil {
+			return err
+		}
+		if offset >= len(resources) {
+			return nil
+		}
+		return eachExportResource(resources[offset:], fn)
+	}
+	for {
+		page, err := s.PageFn(ctx, since, exportPageSize, offset)
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			return nil
+		}
+		if err := eachExportResource(page, fn); err != nil {
+			return err
+		}
+		offset += len(page)
+	}
+}
+
+// StreamByPatient calls fn for each resource of ListByPatientFn.
+func (s *ServiceExporter) StreamByPatient(ctx context.Context, patientID string, since *time.Time, fn func(map[string]interface{}) error) error {
+	resources, err := s.ExportByPatient(ctx, patientID, since)
+	if err != nil {
+		return err
+	}
+	return eachExportResource(resources, fn)
+}
+
+func eachExportResource(resources []map[string]interface{}, fn func(map[string]interface{}) error) error {
+	for _, r := range resources {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamExport calls fn for each resource of exporter, of the patient when
+// patientID is set, streaming when the exporter supports it.
+func streamExport(ctx context.Context, exporter ResourceExporter, patientID string, since *time.Time, fn func(map[string]interface{}) error) error {
+	if s, ok := exporter.(StreamingResourceExporter); ok {
+		if patientID != "" {
+			return s.StreamByPatient(ctx, patientID, since, fn)
+		}
+		return s.StreamAll(ctx, since, fn)
+	}
+	var resources []map[string]interface{}
+	var err error
+	if patientID != "" {
+		resources, err = exporter.ExportByPatient(ctx, patientID, since)
+	} else {
+		resources, err = exporter.ExportAll(ctx, since)
+	}
+	if err != nil {
+		return err
+	}
+	return eachExportResource(resources, fn)
+}
+
 // GroupMemberResolver resolves the patient IDs belonging to a FHIR Group.
 type GroupMemberResolver func(ctx context.Context, groupID string) ([]string, error)
 
@@ -59,6 +182,37 @@ var validOutputFormats = map[string]bool{
 	"ndjson":                  true,
 }
 
+// ParquetOutputFormat is the _outputFormat of exports written as Parquet
+// files (see parquet.go).
+const ParquetOutputFormat = "application/vnd.apache.parquet"
+
+// parquetOutputFormats lists accepted _outputFormat values that map to Parquet.
+var parquetOutputFormats = map[string]bool{
+	ParquetOutputFormat:   true,
+	"application/parquet": true,
+	"parquet":             true,
+}
+
+// canonicalExportFormat returns the output format an _outputFormat value
+// stands for, defaulting to NDJSON, and whether it is supported.
+func canonicalExportFormat(outputFormat string) (string, bool) {
+	switch {
+	case outputFormat == "" || validOutputFormats[outputFormat]:
+		return "application/fhir+ndjson", true
+	case parquetOutputFormats[outputFormat]:
+		return ParquetOutputFormat, true
+	}
+	return "", false
+}
+
+// exportFileExt returns the file extension of an output format.
+func exportFileExt(outputFormat string) string {
+	if outputFormat == ParquetOutputFormat {
+		return ".parquet"
+	}
+	return ".ndjson"
+}
+
 // defaultExportResourceTypes returns the full set of US Core / g(10) resource
 // types that should be exported when no _type filter is specified.
 func defaultExportResourceTypes() []string {
@@ -109,26 +263,94 @@ type ExportJob struct {
 	// patientIDs is used for group exports; each member is exported separately.
 	patientIDs []string
 
-	// ndjsonData stores the exported NDJSON bytes keyed by resource type.
-	// This field is not serialised to JSON; it is internal storage.
-	ndjsonData map[string][]byte
+	// cancel stops a job run by this manager's own goroutine.
+	cancel context.CancelFunc
 }
 
 // ExportOutputFile represents a single output file from a bulk export job.
+// A type's resources are split across several files once a file reaches
+// the manager's maximum file size; types without resources have a single
+// empty file.
 type ExportOutputFile struct {
 	Type  string `json:"type"`
 	URL   string `json:"url"`
 	Count int    `json:"count,omitempty"`
+
+	// blobID locates the file's content in the manager's blob store.
+	blobID string
+}
+
+// name returns the name of the file in its download URLs: the resource
+// type, followed by "-2", "-3"… for the type's further files.
+func (f ExportOutputFile) name() string {
+	if f.URL == "" {
+		return f.Type
+	}
+	return path.Base(f.URL)
+}
+
+// exportFileName returns the name of the part'th file of a resource type.
+func exportFileName(resourceType string, part int) string {
+	if part <= 1 {
+		return resourceType
+	}
+	return fmt.Sprintf("%s-%d", resourceType, part)
+}
+
+// exportDataURL returns the $export-data URL of a job's output file.
+func exportDataURL(jobID, name string) string {
+	return fmt.Sprintf("/fhir/$export-data/%s/%s", jobID, name)
 }
 
+// DefaultExportMaxFileSize is the size at which an export output file is
+// closed and the output of its type continues in a new file.
+const DefaultExportMaxFileSize = 64 << 20
+
+// DefaultExportURLExpiry is how long signed download URLs stay valid.
+const DefaultExportURLExpiry = time.Hour
+
+// ExportFilePath is the route of signed output file downloads. It must be
+// served without authentication: the URL's signature grants access.
+const ExportFilePath = "/fhir/$export-file/:jobId/:fileName"
+
+// exportJobKind is the AsyncJob kind of persisted export jobs.
+const exportJobKind = "export"
+
 // ExportOptions configures the ExportManager.
 type ExportOptions struct {
 	MaxConcurrentJobs int
 	JobTTL            time.Duration
+
+	// Blobs stores the output files, which are streamed to it as they are
+	// written. Defaults to an in-memory store.
+	Blobs blobstore.BlobStore
+	// MaxFileSize is the size at which an output file is closed; the rest of
+	// its type is written to further files. Defaults to
+	// DefaultExportMaxFileSize.
+	MaxFileSize int64
+	// Jobs persists the state of jobs, and the output files they have
+	// finished, as "export" AsyncJobs. When it is an AsyncJobQueue the jobs
+	// are run by an AsyncJobRunner with ExportJobFunc, on any replica, and
+	// a job interrupted by a restart resumes after its last finished file.
+	Jobs AsyncJobStore
+	// Resources serves _typeFilter queries through the search layer.
+	// Without it, _typeFilter is recorded on the job but not applied.
+	Resources *ResourceRegistry
+	// Extras, when set, holds the elements the domain models do not store,
+	// meta tags, security labels and profiles among them, which are merged
+	// into the exported resources as ExtrasMiddleware does for reads.
+	Extras ExtrasStore
+	// SigningKey enables download URLs signed with HMAC-SHA256 that expire
+	// after URLExpiry (default DefaultExportURLExpiry) and need no access
+	// token. See ExportFilePath.
+	SigningKey []byte
+	URLExpiry  time.Duration
 }
 
-// ExportManager manages export jobs in-memory and dispatches export
-// processing via registered ResourceExporter implementations.
+// ExportManager manages export jobs and dispatches export processing via
+// registered ResourceExporter implementations. Output is streamed into a
+// blob store; running jobs are tracked in memory, and in the job store
+// when one is configured.
 type ExportManager struct {
 	mu            sync.RWMutex
 	jobs          map[string]*ExportJob
@@ -137,6 +359,14 @@ type ExportManager struct {
 
 	maxConcurrentJobs int
 	jobTTL            time.Duration
+
+	blobs       blobstore.BlobStore
+	maxFileSize int64
+	store       AsyncJobStore
+	resources   *ResourceRegistry
+	extras      ExtrasStore
+	signingKey  []byte
+	urlExpiry   time.Duration
 }
 
 // NewExportManager creates a new ExportManager with default settings.
@@ -146,6 +376,9 @@ func NewExportManager() *ExportManager {
 		exporters:         make(map[string]ResourceExporter),
 		maxConcurrentJobs: 10,
 		jobTTL:            time.Hour,
+		blobs:             blobstore.NewInMemoryBlobStore(),
+		maxFileSize:       DefaultExportMaxFileSize,
+		urlExpiry:         DefaultExportURLExpiry,
 	}
 }
 
@@ -158,6 +391,19 @@ func NewExportManagerWithOptions(opts ExportOptions) *ExportManager {
 	if opts.JobTTL > 0 {
 		m.jobTTL = opts.JobTTL
 	}
+	if opts.Blobs != nil {
+		m.blobs = opts.Blobs
+	}
+	if opts.MaxFileSize > 0 {
+		m.maxFileSize = opts.MaxFileSize
+	}
+	if opts.URLExpiry > 0 {
+		m.urlExpiry = opts.URLExpiry
+	}
+	m.store = opts.Jobs
+	m.resources = opts.Resources
+	m.extras = opts.Extras
+	m.signingKey = opts.SigningKey
 	return m
 }
 
@@ -175,6 +421,24 @@ func (m *ExportManager) RegisterExporter(resourceType string, exporter ResourceE
 	m.exporters[resourceType] = exporter
 }
 
+// ExportRequest describes a requested export: its scope, a patient or a
+// Group, and its _type, _since, _outputFormat and _typeFilter parameters.
+type ExportRequest struct {
+	ResourceTypes []string   `json:"resourceTypes,omitempty"`
+	PatientID     string     `json:"patientId,omitempty"`
+	GroupID       string     `json:"groupId,omitempty"`
+	Since         *time.Time `json:"since,omitempty"`
+	OutputFormat  string     `json:"outputFormat,omitempty"`
+	TypeFilter    []string   `json:"typeFilter,omitempty"`
+}
+
+// exportJobPayload is the payload of a persisted export job.
+type exportJobPayload struct {
+	ExportRequest
+	Members     []string  `json:"members,omitempty"`
+	RequestTime time.Time `json:"requestTime"`
+}
+
 // KickOff creates a new system-level export job and starts async processing.
 func (m *ExportManager) KickOff(resourceTypes []string, since *time.Time) (*ExportJob, error) {
 	return m.KickOffWithFormat(resourceTypes, "", since, "", nil)
@@ -189,18 +453,48 @@ func (m *ExportManager) KickOffForPatient(resourceTypes []string, patientID stri
 // and optional type filters. Returns an error if the format is unsupported
 // or the concurrent job limit is reached.
 func (m *ExportManager) KickOffWithFormat(resourceTypes []string, patientID string, since *time.Time, outputFormat string, typeFilter []string) (*ExportJob, error) {
-	// Validate output format
-	if outputFormat != "" {
-		if !validOutputFormats[outputFormat] {
-			return nil, fmt.Errorf("unsupported _outputFormat: %s", outputFormat)
-		}
+	return m.StartExport(context.Background(), ExportRequest{
+		ResourceTypes: resourceTypes,
+		PatientID:     patientID,
+		Since:         since,
+		OutputFormat:  outputFormat,
+		TypeFilter:    typeFilter,
+	})
+}
+
+// KickOffForGroup creates a group-level export job. It resolves group
+// members via the registered GroupMemberResolver and exports per-patient.
+func (m *ExportManager) KickOffForGroup(resourceTypes []string, groupID string, since *time.Time, outputFormat string, typeFilter []string) (*ExportJob, error) {
+	return m.StartExport(context.Background(), ExportRequest{
+		ResourceTypes: resourceTypes,
+		GroupID:       groupID,
+		Since:         since,
+		OutputFormat:  outputFormat,
+		TypeFilter:    typeFilter,
+	})
+}
+
+// StartExport validates req and creates its export job. The job is stored
+// in the job store, if any, with the tenant and identity of ctx; it is left
+// to an AsyncJobRunner when the store is an AsyncJobQueue, and otherwise
+// processed in a background goroutine. Returns an error if the format or a
+// type filter is unsupported, the group cannot be resolved, or the
+// concurrent job limit is reached.
+func (m *ExportManager) StartExport(ctx context.Context, req ExportRequest) (*ExportJob, error) {
+	format, ok := canonicalExportFormat(req.OutputFormat)
+	if !ok {
+		return nil, fmt.Errorf("unsupported _outputFormat: %s", req.OutputFormat)
+	}
+	if err := m.checkTypeFilters(req.TypeFilter); err != nil {
+		return nil, err
 	}
 
 	// If _type is empty but _typeFilter is provided, derive resource types
 	// from the filter prefixes (e.g. "Observation?category=laboratory" → "Observation").
-	if len(resourceTypes) == 0 && len(typeFilter) > 0 {
+	resourceTypes := req.ResourceTypes
+	if len(resourceTypes) == 0 && len(req.TypeFilter) > 0 {
 		seen := make(map[string]bool)
-		for _, tf := range typeFilter {
+		for _, tf := range req.TypeFilter {
 			if prefix, _, ok := strings.Cut(tf, "?"); ok && prefix != "" {
 				if !seen[prefix] {
 					resourceTypes = append(resourceTypes, prefix)
@@ -209,6 +503,28 @@ func (m *ExportManager) KickOffWithFormat(resourceTypes []string, patientID stri
 			}
 		}
 	}
+	// Default resource types if none specified — includes all US Core resource
+	// types required by g(10) / Inferno Bulk Data tests.
+	if len(resourceTypes) == 0 {
+		resourceTypes = defaultExportResourceTypes()
+	}
+
+	var members []string
+	if req.GroupID != "" {
+		m.mu.RLock()
+		resolver := m.groupResolver
+		m.mu.RUnlock()
+
+		if resolver == nil {
+			return nil, fmt.Errorf("Group resource not found: no group resolver configured")
+		}
+
+		// Resolve group members
+		var err error
+		if members, err = resolver(ctx, req.GroupID); err != nil {
+			return nil, err
+		}
+	}
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -224,108 +540,228 @@ func (m *ExportManager) KickOffWithFormat(resourceTypes []string, patientID stri
 		return nil, fmt.Errorf("max concurrent export jobs reached (%d)", m.maxConcurrentJobs)
 	}
 
-	id := uuid.New().String()
 	now := time.Now().UTC()
-
-	// Default resource types if none specified — includes all US Core resource
-	// types required by g(10) / Inferno Bulk Data tests.
-	if len(resourceTypes) == 0 {
-		resourceTypes = defaultExportResourceTypes()
-	}
-
 	job := &ExportJob{
-		ID:            id,
+		ID:            uuid.New().String(),
 		Status:        "in-progress",
 		ResourceTypes: resourceTypes,
-		PatientID:     patientID,
-		Since:         since,
-		OutputFormat:  "application/fhir+ndjson",
+		PatientID:     req.PatientID,
+		GroupID:       req.GroupID,
+		Since:         req.Since,
+		OutputFormat:  format,
 		CreatedAt:     now,
 		RequestTime:   now,
-		TypeFilter:    typeFilter,
+		TypeFilter:    req.TypeFilter,
 		TotalTypes:    len(resourceTypes),
-		ndjsonData:    make(map[string][]byte),
+		patientIDs:    members,
 	}
 
-	m.jobs[id] = job
+	if m.store != nil {
+		req.ResourceTypes = resourceTypes
+		req.OutputFormat = format
+		payload, err := json.Marshal(&exportJobPayload{ExportRequest: req, Members: members, RequestTime: now})
+		if err != nil {
+			return nil, fmt.Errorf("encode export job: %w", err)
+		}
+		if err := m.store.Create(ctx, &AsyncJob{
+			ID:            job.ID,
+			Status:        AsyncStatusInProgress,
+			Request:       exportRequestPath(job),
+			TransactionTS: now,
+			Kind:          exportJobKind,
+			Payload:       payload,
+		}); err != nil {
+			return nil, fmt.Errorf("create export job: %w", err)
+		}
+		if _, queued := m.store.(AsyncJobQueue); queued {
+			return job, nil
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	job.cancel = cancel
+	m.jobs[job.ID] = job
 
 	// Start async export processing in a goroutine
-	go m.processExport(job)
+	go func() {
+		defer cancel()
+		_ = m.run(runCtx, job)
+	}()
 
 	return job, nil
 }
 
-// KickOffForGroup creates a group-level export job. It resolves group
-// members via the registered GroupMemberResolver and exports per-patient.
-func (m *ExportManager) KickOffForGroup(resourceTypes []string, groupID string, since *time.Time, outputFormat string, typeFilter []string) (*ExportJob, error) {
-	// Validate output format
-	if outputFormat != "" {
-		if !validOutputFormats[outputFormat] {
-			return nil, fmt.Errorf("unsupported _outputFormat: %s", outputFormat)
+// checkTypeFilters checks that each _typeFilter is a search of a resource
+// type, "<type>?<search parameters>", that the search layer can run.
+func (m *ExportManager) checkTypeFilters(typeFilter []string) error {
+	for _, tf := range typeFilter {
+		rt, query, ok := strings.Cut(tf, "?")
+		if !ok || rt == "" {
+			return fmt.Errorf("unsupported _typeFilter %q: expected <resource type>?<search parameters>", tf)
+		}
+		if _, err := url.ParseQuery(query); err != nil {
+			return fmt.Errorf("unsupported _typeFilter %q: %v", tf, err)
+		}
+		if m.resources != nil {
+			if _, ok := m.resources.Lookup(rt); !ok {
+				return fmt.Errorf("unsupported _typeFilter %q: %s cannot be searched", tf, rt)
+			}
 		}
 	}
+	return nil
+}
 
-	m.mu.RLock()
-	resolver := m.groupResolver
-	m.mu.RUnlock()
-
-	if resolver == nil {
-		return nil, fmt.Errorf("Group resource not found: no group resolver configured")
+// typeFilterQueries returns the search parameters of the job's _typeFilter
+// queries for resourceType.
+func (job *ExportJob) typeFilterQueries(resourceType string) []url.Values {
+	var queries []url.Values
+	for _, tf := range job.TypeFilter {
+		rt, query, _ := strings.Cut(tf, "?")
+		if rt != resourceType {
+			continue
+		}
+		if params, err := url.ParseQuery(query); err == nil {
+			queries = append(queries, params)
+		}
 	}
+	return queries
+}
 
-	// Resolve group members
-	members, err := resolver(context.Background(), groupID)
-	if err != nil {
-		return nil, err
+// exportRequestPath returns the kick-off path of a job.
+func exportRequestPath(job *ExportJob) string {
+	if job.GroupID != "" {
+		return fmt.Sprintf("/fhir/Group/%s/$export", job.GroupID)
+	}
+	if job.PatientID != "" {
+		return fmt.Sprintf("/fhir/Patient/%s/$export", job.PatientID)
 	}
+	return "/fhir/$export"
+}
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// ExportJobFunc returns an AsyncJobFunc that runs the queued export jobs of
+// m's job store, resuming after the types and files a job had finished when
+// it was interrupted. Within the type it was exporting, a job continues
+// after the resources of its finished files, reading from that offset when
+// the exporter is a ResumableResourceExporter. Register it with an
+// AsyncJobRunner for the "export" kind.
+func ExportJobFunc(m *ExportManager) AsyncJobFunc {
+	return func(ctx context.Context, jobID string, payload json.RawMessage) error {
+		saved, err := m.store.Get(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		job, err := exportJobFromAsync(saved, payload)
+		if err != nil {
+			return err
+		}
+		if job.Status != "in-progress" {
+			return nil
+		}
+		m.dropLostFiles(ctx, job)
+		m.mu.Lock()
+		m.jobs[job.ID] = job
+		m.mu.Unlock()
+		return m.run(ctx, job)
+	}
+}
 
-	// Check concurrent job limit
-	activeCount := 0
-	for _, j := range m.jobs {
-		if j.Status == "in-progress" {
-			activeCount++
+// dropLostFiles rewinds a restored job to the first of its types with a
+// finished file the blob store no longer has, as an in-memory store loses
+// them in a restart, discarding the files of that type and those after it.
+// The types before it are kept.
+func (m *ExportManager) dropLostFiles(ctx context.Context, job *ExportJob) {
+	lost := -1
+	for _, f := range job.OutputFiles {
+		if f.blobID == "" {
+			continue
+		}
+		if _, err := m.blobs.GetMetadata(ctx, f.blobID); err != nil {
+			i := job.typeIndex(f.Type)
+			if i < 0 {
+				i = 0
+			}
+			if lost < 0 || i < lost {
+				lost = i
+			}
 		}
 	}
-	if activeCount >= m.maxConcurrentJobs {
-		return nil, fmt.Errorf("max concurrent export jobs reached (%d)", m.maxConcurrentJobs)
+	if lost < 0 {
+		return
 	}
+	kept := job.OutputFiles[:0]
+	for _, f := range job.OutputFiles {
+		if i := job.typeIndex(f.Type); i >= 0 && i < lost {
+			kept = append(kept, f)
+		} else if f.blobID != "" {
+			_ = m.blobs.Delete(ctx, f.blobID)
+		}
+	}
+	job.OutputFiles = kept
+	if job.ProcessedTypes > lost {
+		job.ProcessedTypes = lost
+	}
+}
 
-	id := uuid.New().String()
-	now := time.Now().UTC()
-
-	if len(resourceTypes) == 0 {
-		resourceTypes = defaultExportResourceTypes()
+// typeIndex returns the position of resourceType among the job's types, or
+// -1.
+func (job *ExportJob) typeIndex(resourceType string) int {
+	for i, rt := range job.ResourceTypes {
+		if rt == resourceType {
+			return i
+		}
 	}
+	return -1
+}
 
+// exportJobFromAsync restores an export job from its persisted state and
+// payload.
+func exportJobFromAsync(saved *AsyncJob, payload json.RawMessage) (*ExportJob, error) {
+	var req exportJobPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, fmt.Errorf("decode export job %s: %w", saved.ID, err)
+	}
 	job := &ExportJob{
-		ID:            id,
-		Status:        "in-progress",
-		ResourceTypes: resourceTypes,
-		GroupID:       groupID,
-		Since:         since,
-		OutputFormat:  "application/fhir+ndjson",
-		CreatedAt:     now,
-		RequestTime:   now,
-		TypeFilter:    typeFilter,
-		TotalTypes:    len(resourceTypes),
-		patientIDs:    members,
-		ndjsonData:    make(map[string][]byte),
+		ID:             saved.ID,
+		Status:         "in-progress",
+		ResourceTypes:  req.ResourceTypes,
+		PatientID:      req.PatientID,
+		GroupID:        req.GroupID,
+		Since:          req.Since,
+		OutputFormat:   req.OutputFormat,
+		CreatedAt:      saved.TransactionTS,
+		ErrorMessage:   saved.Error,
+		TypeFilter:     req.TypeFilter,
+		RequestTime:    req.RequestTime,
+		ProcessedTypes: saved.Processed,
+		TotalTypes:     len(req.ResourceTypes),
+		patientIDs:     req.Members,
+	}
+	switch saved.Status {
+	case AsyncStatusCompleted:
+		job.Status = "complete"
+	case AsyncStatusError:
+		job.Status = "error"
+	}
+	parts := make(map[string]int)
+	for _, out := range saved.Output {
+		f := ExportOutputFile{Type: out.Type, Count: out.Count, blobID: strings.TrimPrefix(out.URL, "blob://")}
+		name := out.Type
+		if f.blobID != "" {
+			parts[out.Type]++
+			name = exportFileName(out.Type, parts[out.Type])
+		}
+		f.URL = exportDataURL(job.ID, name)
+		job.OutputFiles = append(job.OutputFiles, f)
 	}
-
-	m.jobs[id] = job
-
-	go m.processGroupExport(job)
-
 	return job, nil
 }
 
-// processExport runs the export for each resource type in the job.
-func (m *ExportManager) processExport(job *ExportJob) {
-	ctx := context.Background()
-
+// run exports the job's resource types in order, after those it has
+// already processed, and records the outcome. An export failure marks the
+// job as an error. run returns an error, leaving the job in progress, when
+// it is interrupted or its state cannot be saved, so that a queued job is
+// retried.
+func (m *ExportManager) run(ctx context.Context, job *ExportJob) error {
 	// Collect exporters under read lock
 	m.mu.RLock()
 	exportersCopy := make(map[string]ResourceExporter, len(m.exporters))
@@ -334,68 +770,34 @@ func (m *ExportManager) processExport(job *ExportJob) {
 	}
 	m.mu.RUnlock()
 
-	outputFiles := make([]ExportOutputFile, 0, len(job.ResourceTypes))
-	ndjsonData := make(map[string][]byte, len(job.ResourceTypes))
-
-	for _, rt := range job.ResourceTypes {
-		exporter, ok := exportersCopy[rt]
-		if !ok {
-			// No exporter registered for this type; produce empty data
-			ndjsonData[rt] = nil
-			outputFiles = append(outputFiles, ExportOutputFile{
-				Type:  rt,
-				URL:   fmt.Sprintf("/fhir/$export-data/%s/%s", job.ID, rt),
-				Count: 0,
-			})
-			m.mu.Lock()
-			job.ProcessedTypes++
-			m.mu.Unlock()
-			continue
-		}
-
-		var resources []map[string]interface{}
-		var err error
-
-		if job.PatientID != "" {
-			resources, err = exporter.ExportByPatient(ctx, job.PatientID, job.Since)
-		} else {
-			resources, err = exporter.ExportAll(ctx, job.Since)
+	for i := job.ProcessedTypes; i < len(job.ResourceTypes); i++ {
+		rt := job.ResourceTypes[i]
+		err := m.exportType(ctx, job, rt, exportersCopy[rt])
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
-
 		if err != nil {
 			// Mark job as error
 			m.mu.Lock()
 			job.Status = "error"
-			job.ErrorMessage = fmt.Sprintf("export failed for %s: %s", rt, err.Error())
+			job.ErrorMessage = err.Error()
 			m.mu.Unlock()
-			return
+			return m.checkpoint(ctx, job)
 		}
 
-		// Convert resources to NDJSON
-		var buf bytes.Buffer
-		for _, r := range resources {
-			line, err := json.Marshal(r)
-			if err != nil {
-				m.mu.Lock()
-				job.Status = "error"
-				job.ErrorMessage = fmt.Sprintf("json marshal failed for %s: %s", rt, err.Error())
-				m.mu.Unlock()
-				return
-			}
-			buf.Write(line)
-			buf.WriteByte('\n')
-		}
-
-		ndjsonData[rt] = buf.Bytes()
-		outputFiles = append(outputFiles, ExportOutputFile{
-			Type:  rt,
-			URL:   fmt.Sprintf("/fhir/$export-data/%s/%s", job.ID, rt),
-			Count: len(resources),
-		})
-
 		m.mu.Lock()
 		job.ProcessedTypes++
+		if !job.hasOutput(rt) {
+			// No resources (or no exporter) for this type; produce empty data
+			job.OutputFiles = append(job.OutputFiles, ExportOutputFile{
+				Type: rt,
+				URL:  exportDataURL(job.ID, rt),
+			})
+		}
 		m.mu.Unlock()
+		if err := m.checkpoint(ctx, job); err != nil {
+			return err
+		}
 	}
 
 	// Mark job as complete
@@ -403,101 +805,223 @@ func (m *ExportManager) processExport(job *ExportJob) {
 	m.mu.Lock()
 	job.Status = "complete"
 	job.CompletedAt = &now
-	job.OutputFiles = outputFiles
-	job.ndjsonData = ndjsonData
 	m.mu.Unlock()
+	return m.checkpoint(ctx, job)
 }
 
-// processGroupExport exports data for each member of a group.
-func (m *ExportManager) processGroupExport(job *ExportJob) {
-	ctx := context.Background()
+// hasOutput reports whether the job has an output file for resourceType.
+func (job *ExportJob) hasOutput(resourceType string) bool {
+	for _, f := range job.OutputFiles {
+		if f.Type == resourceType {
+			return true
+		}
+	}
+	return false
+}
 
-	m.mu.RLock()
-	exportersCopy := make(map[string]ResourceExporter, len(m.exporters))
-	for k, v := range m.exporters {
-		exportersCopy[k] = v
+// exportType streams the resources of one type into output files, after
+// the resources written to the files the job finished before it was
+// resumed: a ResumableResourceExporter starts reading at their offset, and
+// the resources of other sources are skipped.
+func (m *ExportManager) exportType(ctx context.Context, job *ExportJob, resourceType string, exporter ResourceExporter) error {
+	w := m.newExportFileWriter(ctx, job, resourceType)
+	seen := 0
+	// With an extras store, resources are written a page at a time so that
+	// the extras of a page are read together.
+	var pending []map[string]interface{}
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		if err := MergeStoredExtras(ctx, m.extras, resourceType, pending); err != nil {
+			return fmt.Errorf("export failed for %s: %w", resourceType, err)
+		}
+		for _, r := range pending {
+			if err := w.write(r); err != nil {
+				return err
+			}
+		}
+		pending = pending[:0]
+		return nil
+	}
+	emit := func(r map[string]interface{}) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		seen++
+		if seen <= w.skip {
+			return nil
+		}
+		if m.extras == nil {
+			return w.write(r)
+		}
+		if pending = append(pending, r); len(pending) < exportPageSize {
+			return nil
+		}
+		return flush()
 	}
-	m.mu.RUnlock()
 
-	outputFiles := make([]ExportOutputFile, 0, len(job.ResourceTypes))
-	ndjsonData := make(map[string][]byte, len(job.ResourceTypes))
-
-	for _, rt := range job.ResourceTypes {
-		exporter, ok := exportersCopy[rt]
-		if !ok {
-			ndjsonData[rt] = nil
-			outputFiles = append(outputFiles, ExportOutputFile{
-				Type:  rt,
-				URL:   fmt.Sprintf("/fhir/$export-data/%s/%s", job.ID, rt),
-				Count: 0,
-			})
-			m.mu.Lock()
-			job.ProcessedTypes++
-			m.mu.Unlock()
-			continue
+	var err error
+	switch queries := job.typeFilterQueries(resourceType); {
+	case len(queries) > 0 && m.resources != nil:
+		err = m.searchResources(ctx, job, resourceType, queries, emit)
+	case exporter == nil:
+		// No exporter registered for this type
+	case job.GroupID != "":
+		for _, pid := range job.patientIDs {
+			if err = streamExport(ctx, exporter, pid, job.Since, emit); err != nil {
+				err = fmt.Errorf("export failed for %s (patient %s): %w", resourceType, pid, err)
+				break
+			}
 		}
+	default:
+		if r, ok := exporter.(ResumableResourceExporter); ok && job.PatientID == "" {
+			seen = w.skip
+			err = r.StreamAllFrom(ctx, job.Since, w.skip, emit)
+		} else {
+			err = streamExport(ctx, exporter, job.PatientID, job.Since, emit)
+		}
+		if err != nil {
+			err = fmt.Errorf("export failed for %s: %w", resourceType, err)
+		}
+	}
+	if err == nil {
+		err = flush()
+	}
+	if err == nil {
+		err = w.close()
+	}
+	if err != nil {
+		w.abort()
+	}
+	return err
+}
 
-		var buf bytes.Buffer
-		totalCount := 0
+// searchResources streams the union of the matches of a type's _typeFilter
+// queries through the search layer, limited to the job's patient or group
+// members by the type's patient compartment parameters, and to _since by
+// _lastUpdated. A resource matching several queries is exported once.
+func (m *ExportManager) searchResources(ctx context.Context, job *ExportJob, resourceType string, queries []url.Values, emit func(map[string]interface{}) error) error {
+	patients := job.patientIDs
+	if job.PatientID != "" {
+		patients = []string{job.PatientID}
+	}
+	scopes := []url.Values{{}}
+	if job.PatientID != "" || job.GroupID != "" {
+		scopes = nil
+		params := CompartmentResourceParams(PatientCompartmentDef(), resourceType)
+		for _, pid := range patients {
+			if resourceType == "Patient" {
+				scopes = append(scopes, url.Values{"_id": {pid}})
+			}
+			for _, p := range params {
+				scopes = append(scopes, url.Values{p: {"Patient/" + pid}})
+			}
+		}
+	}
 
-		for _, pid := range job.patientIDs {
-			resources, err := exporter.ExportByPatient(ctx, pid, job.Since)
-			if err != nil {
-				m.mu.Lock()
-				job.Status = "error"
-				job.ErrorMessage = fmt.Sprintf("export failed for %s (patient %s): %s", rt, pid, err.Error())
-				m.mu.Unlock()
-				return
+	seen := make(map[string]bool)
+	for _, query := range queries {
+		for _, scope := range scopes {
+			params := url.Values{}
+			for k, v := range query {
+				params[k] = append([]string(nil), v...)
+			}
+			for k, v := range scope {
+				params[k] = append(params[k], v...)
 			}
-			for _, r := range resources {
-				line, err := json.Marshal(r)
+			if job.Since != nil {
+				params.Add("_lastUpdated", "gt"+job.Since.UTC().Format(time.RFC3339))
+			}
+			for offset := 0; ; {
+				params.Set("_count", strconv.Itoa(exportPageSize))
+				params.Set("_offset", strconv.Itoa(offset))
+				page, err := m.resources.Search(ctx, resourceType, params)
 				if err != nil {
-					m.mu.Lock()
-					job.Status = "error"
-					job.ErrorMessage = fmt.Sprintf("json marshal failed for %s: %s", rt, err.Error())
-					m.mu.Unlock()
-					return
+					return fmt.Errorf("export failed for %s: %w", resourceType, err)
+				}
+				if len(page) == 0 {
+					break
 				}
-				buf.Write(line)
-				buf.WriteByte('\n')
-				totalCount++
+				for _, r := range page {
+					if id, _ := r["id"].(string); id != "" {
+						if seen[id] {
+							continue
+						}
+						seen[id] = true
+					}
+					if err := emit(r); err != nil {
+						return err
+					}
+				}
+				offset += len(page)
 			}
 		}
-
-		ndjsonData[rt] = buf.Bytes()
-		outputFiles = append(outputFiles, ExportOutputFile{
-			Type:  rt,
-			URL:   fmt.Sprintf("/fhir/$export-data/%s/%s", job.ID, rt),
-			Count: totalCount,
-		})
-
-		m.mu.Lock()
-		job.ProcessedTypes++
-		m.mu.Unlock()
 	}
+	return nil
+}
 
-	now := time.Now().UTC()
-	m.mu.Lock()
-	job.Status = "complete"
-	job.CompletedAt = &now
-	job.OutputFiles = outputFiles
-	job.ndjsonData = ndjsonData
-	m.mu.Unlock()
+// checkpoint saves the job's status, progress and finished output files to
+// the job store, so that an interrupted job resumes after them.
+func (m *ExportManager) checkpoint(ctx context.Context, job *ExportJob) error {
+	if m.store == nil {
+		return nil
+	}
+	saved, err := m.store.Get(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	m.mu.RLock()
+	saved.Processed = job.ProcessedTypes
+	saved.Output = make([]AsyncJobOutput, 0, len(job.OutputFiles))
+	for _, f := range job.OutputFiles {
+		out := AsyncJobOutput{Type: f.Type, Count: f.Count}
+		if f.blobID != "" {
+			out.URL = "blob://" + f.blobID
+		}
+		saved.Output = append(saved.Output, out)
+	}
+	switch job.Status {
+	case "complete":
+		saved.Status = AsyncStatusCompleted
+	case "error":
+		saved.Status = AsyncStatusError
+		saved.Error = job.ErrorMessage
+	}
+	m.mu.RUnlock()
+	return m.store.Update(ctx, saved)
 }
 
 // GetStatus retrieves a snapshot of an export job by ID. The returned
 // ExportJob is a copy so callers can inspect it without holding locks.
 func (m *ExportManager) GetStatus(jobID string) (*ExportJob, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	return m.GetStatusContext(context.Background(), jobID)
+}
 
+// GetStatusContext is GetStatus for the tenant of ctx. A job that is not
+// tracked by this manager, because it runs on another replica or ran before
+// a restart, is read from the job store.
+func (m *ExportManager) GetStatusContext(ctx context.Context, jobID string) (*ExportJob, error) {
+	m.mu.RLock()
 	job, ok := m.jobs[jobID]
-	if !ok {
-		return nil, fmt.Errorf("export job not found: %s", jobID)
+	if ok {
+		defer m.mu.RUnlock()
+		return job.snapshot(), nil
 	}
+	m.mu.RUnlock()
 
-	// Return a shallow copy to avoid data races on fields mutated by
-	// the background goroutine after the lock is released.
+	if m.store != nil {
+		if saved, err := m.store.Get(ctx, jobID); err == nil && saved.Kind == exportJobKind {
+			return exportJobFromAsync(saved, saved.Payload)
+		}
+	}
+	return nil, fmt.Errorf("export job not found: %s", jobID)
+}
+
+// snapshot returns a shallow copy of the job, with copies of its slices, to
+// avoid data races on fields mutated by the background goroutine after the
+// lock is released.
+func (job *ExportJob) snapshot() *ExportJob {
 	snapshot := *job
 	// Copy slices to avoid aliasing.
 	if job.OutputFiles != nil {
@@ -512,62 +1036,140 @@ func (m *ExportManager) GetStatus(jobID string) (*ExportJob, error) {
 		snapshot.TypeFilter = make([]string, len(job.TypeFilter))
 		copy(snapshot.TypeFilter, job.TypeFilter)
 	}
-	return &snapshot, nil
+	return &snapshot
 }
 
 // GetJobData returns NDJSON data for a specific resource type in an export job.
 func (m *ExportManager) GetJobData(jobID, fileType string) ([]byte, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	rc, _, err := m.OpenFile(context.Background(), jobID, fileType)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
 
-	job, ok := m.jobs[jobID]
-	if !ok {
-		return nil, fmt.Errorf("export job not found: %s", jobID)
+// OpenFile opens an output file of a completed job, named as in its
+// download URLs with or without the file extension, and returns it with
+// its content type.
+func (m *ExportManager) OpenFile(ctx context.Context, jobID, name string) (io.ReadCloser, string, error) {
+	job, err := m.GetStatusContext(ctx, jobID)
+	if err != nil {
+		return nil, "", err
 	}
 
 	if job.Status != "complete" {
-		return nil, fmt.Errorf("export job is not complete: %s", job.Status)
+		return nil, "", fmt.Errorf("export job is not complete: %s", job.Status)
 	}
 
-	// Verify the file type is part of this job
-	found := false
+	// Verify the file is part of this job
+	name = strings.TrimSuffix(name, exportFileExt(job.OutputFormat))
 	for _, f := range job.OutputFiles {
-		if f.Type == fileType {
-			found = true
-			break
+		if f.name() != name {
+			continue
 		}
+		if f.blobID == "" {
+			// Return an empty file so callers get a valid but empty response
+			return io.NopCloser(strings.NewReader("")), job.OutputFormat, nil
+		}
+		rc, _, err := m.blobs.Download(ctx, f.blobID)
+		if err != nil {
+			return nil, "", fmt.Errorf("export file %s of job %s: %w", name, jobID, err)
+		}
+		return rc, job.OutputFormat, nil
 	}
-	if !found {
-		return nil, fmt.Errorf("file type %s not found in export job %s", fileType, jobID)
+	return nil, "", fmt.Errorf("file type %s not found in export job %s", name, jobID)
+}
+
+// SignsURLs reports whether the manager signs download URLs.
+func (m *ExportManager) SignsURLs() bool {
+	return len(m.signingKey) > 0
+}
+
+// SignedFileURL returns the path and query of a signed download URL for an
+// output file of a job, valid for the manager's URL expiry. It returns ""
+// when the manager does not sign URLs.
+func (m *ExportManager) SignedFileURL(jobID string, f ExportOutputFile, format string) string {
+	if !m.SignsURLs() {
+		return ""
 	}
+	fileName := f.name() + exportFileExt(format)
+	expires := strconv.FormatInt(time.Now().Add(m.urlExpiry).Unix(), 10)
+	return fmt.Sprintf("/fhir/$export-file/%s/%s?expires=%s&signature=%s",
+		jobID, fileName, expires, m.fileSignature(jobID, fileName, expires))
+}
 
-	data := job.ndjsonData[fileType]
-	if data == nil {
-		// Return empty bytes (not nil) so callers get a valid but empty response
-		return []byte{}, nil
+// VerifyFileSignature checks the expires and signature parameters of a
+// signed download URL for fileName of a job.
+func (m *ExportManager) VerifyFileSignature(jobID, fileName, expires, signature string) error {
+	if !m.SignsURLs() {
+		return errors.New("signed export URLs are not enabled")
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errors.New("invalid expires parameter")
+	}
+	if !hmac.Equal([]byte(signature), []byte(m.fileSignature(jobID, fileName, expires))) {
+		return errors.New("invalid signature")
 	}
-	return data, nil
+	if time.Now().Unix() > exp {
+		return errors.New("download URL has expired")
+	}
+	return nil
+}
+
+func (m *ExportManager) fileSignature(jobID, fileName, expires string) string {
+	mac := hmac.New(sha256.New, m.signingKey)
+	mac.Write([]byte(jobID + "/" + fileName + "?" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
 }
 
 // DeleteJob removes an export job and its data.
 func (m *ExportManager) DeleteJob(jobID string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	return m.DeleteJobContext(context.Background(), jobID)
+}
 
-	if _, ok := m.jobs[jobID]; !ok {
-		return fmt.Errorf("export job not found: %s", jobID)
+// DeleteJobContext is DeleteJob for the tenant of ctx. A running job is
+// cancelled, and its output files are deleted from the blob store.
+func (m *ExportManager) DeleteJobContext(ctx context.Context, jobID string) error {
+	job, err := m.GetStatusContext(ctx, jobID)
+	if err != nil {
+		return err
 	}
 
+	m.mu.Lock()
+	if running, ok := m.jobs[jobID]; ok && running.cancel != nil {
+		running.cancel()
+	}
 	delete(m.jobs, jobID)
+	m.mu.Unlock()
+
+	if m.store != nil {
+		if err := m.store.Delete(ctx, jobID); err != nil {
+			return err
+		}
+	}
+	m.deleteFiles(ctx, job)
 	return nil
 }
 
-// CleanupExpiredJobs removes completed/error jobs older than TTL and marks
-// in-progress jobs older than 2x TTL as timed-out errors.
+// deleteFiles deletes the output files of a job from the blob store.
+func (m *ExportManager) deleteFiles(ctx context.Context, job *ExportJob) {
+	for _, f := range job.OutputFiles {
+		if f.blobID != "" {
+			_ = m.blobs.Delete(ctx, f.blobID)
+		}
+	}
+}
+
+// CleanupExpiredJobs removes completed/error jobs older than TTL, with
+// their output files, and marks in-progress jobs older than 2x TTL as
+// timed-out errors.
 func (m *ExportManager) CleanupExpiredJobs() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	ctx := context.Background()
+	var expired []*ExportJob
 
+	m.mu.Lock()
 	now := time.Now().UTC()
 	for id, job := range m.jobs {
 		age := now.Sub(job.CreatedAt)
@@ -575,6 +1177,7 @@ func (m *ExportManager) CleanupExpiredJobs() {
 		case "complete", "error":
 			if age > m.jobTTL {
 				delete(m.jobs, id)
+				expired = append(expired, job)
 			}
 		case "in-progress":
 			if age > 2*m.jobTTL {
@@ -583,6 +1186,14 @@ func (m *ExportManager) CleanupExpiredJobs() {
 			}
 		}
 	}
+	m.mu.Unlock()
+
+	for _, job := range expired {
+		if m.store != nil {
+			_ = m.store.Delete(ctx, job.ID)
+		}
+		m.deleteFiles(ctx, job)
+	}
 }
 
 // StartCleanup runs a background goroutine that periodically removes expired jobs.
@@ -687,7 +1298,13 @@ func (h *ExportHandler) GroupExport(c echo.Context) error {
 		}
 	}
 
-	job, err := h.manager.KickOffForGroup(resourceTypes, groupID, since, outputFormat, typeFilter)
+	job, err := h.manager.StartExport(c.Request().Context(), ExportRequest{
+		ResourceTypes: resourceTypes,
+		GroupID:       groupID,
+		Since:         since,
+		OutputFormat:  outputFormat,
+		TypeFilter:    typeFilter,
+	})
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			return c.JSON(http.StatusNotFound, ErrorOutcome(err.Error()))
@@ -751,7 +1368,13 @@ func (h *ExportHandler) kickOff(c echo.Context, patientID string) error {
 		}
 	}
 
-	job, err := h.manager.KickOffWithFormat(resourceTypes, patientID, since, outputFormat, typeFilter)
+	job, err := h.manager.StartExport(c.Request().Context(), ExportRequest{
+		ResourceTypes: resourceTypes,
+		PatientID:     patientID,
+		Since:         since,
+		OutputFormat:  outputFormat,
+		TypeFilter:    typeFilter,
+	})
 	if err != nil {
 		if strings.Contains(err.Error(), "concurrent") {
 			c.Response().Header().Set("Retry-After", "120")
@@ -767,11 +1390,13 @@ func (h *ExportHandler) kickOff(c echo.Context, patientID string) error {
 	return c.NoContent(http.StatusAccepted)
 }
 
-// ExportStatus handles GET /fhir/$export-status/:id.
+// ExportStatus handles GET /fhir/$export-status/:id. When the manager signs
+// download URLs, the output URLs of a completed job are signed and need no
+// access token.
 func (h *ExportHandler) ExportStatus(c echo.Context) error {
 	jobID := c.Param("id")
 
-	job, err := h.manager.GetStatus(jobID)
+	job, err := h.manager.GetStatusContext(c.Request().Context(), jobID)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, ErrorOutcome(err.Error()))
 	}
@@ -786,11 +1411,19 @@ func (h *ExportHandler) ExportStatus(c echo.Context) error {
 		}
 		return c.NoContent(http.StatusAccepted)
 	case "complete":
+		output := job.OutputFiles
+		if h.manager.SignsURLs() {
+			output = make([]ExportOutputFile, len(job.OutputFiles))
+			for i, f := range job.OutputFiles {
+				output[i] = f
+				output[i].URL = h.manager.SignedFileURL(job.ID, f, job.OutputFormat)
+			}
+		}
 		result := map[string]interface{}{
 			"transactionTime":     job.RequestTime.Format(time.RFC3339),
 			"request":             fmt.Sprintf("/fhir/$export?_type=%s", strings.Join(job.ResourceTypes, ",")),
-			"requiresAccessToken": true,
-			"output":              job.OutputFiles,
+			"requiresAccessToken": !h.manager.SignsURLs(),
+			"output":              output,
 		}
 		return c.JSON(http.StatusOK, result)
 	case "error":
@@ -800,25 +1433,27 @@ func (h *ExportHandler) ExportStatus(c echo.Context) error {
 	}
 }
 
-// ExportData handles GET /fhir/$export-data/:id/:type.
+// ExportData handles GET /fhir/$export-data/:id/:type, streaming the output
+// file from the blob store. The type is the name of the file in the job's
+// output URLs.
 func (h *ExportHandler) ExportData(c echo.Context) error {
 	jobID := c.Param("id")
 	fileType := c.Param("type")
 
-	data, err := h.manager.GetJobData(jobID, fileType)
+	file, contentType, err := h.manager.OpenFile(c.Request().Context(), jobID, fileType)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, ErrorOutcome(err.Error()))
 	}
+	defer file.Close()
 
-	c.Response().Header().Set("Content-Type", "application/fhir+ndjson")
-	return c.Blob(http.StatusOK, "application/fhir+ndjson", data)
+	return c.Stream(http.StatusOK, contentType, file)
 }
 
 // DeleteExport handles DELETE /fhir/$export-status/:id.
 func (h *ExportHandler) DeleteExport(c echo.Context) error {
 	jobID := c.Param("id")
 
-	err := h.manager.DeleteJob(jobID)
+	err := h.manager.DeleteJobContext(c.Request().Context(), jobID)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, ErrorOutcome(err.Error()))
 	}
@@ -79,7 +79,8 @@ func (s *ExportStore) Delete(id string) {
 //	GET  /fhir/Group/:id/$export                Group-level export kick-off
 //	GET  /fhir/$export-poll-status              Export status polling (query param: job=<id>)
 //	DELETE /fhir/$export-poll-status            Cancel/delete an export job
-//	GET  /fhir/$export-output/:jobId/:fileName  Download NDJSON output file
+//	GET  /fhir/$export-output/:jobId/:fileName  Download an output file
+//	GET  /fhir/$export-file/:jobId/:fileName    Download an output file with a signed URL
 type BulkExportHandler struct {
 	manager *ExportManager
 	store   *ExportStore
@@ -116,6 +117,14 @@ func (h *BulkExportHandler) RegisterBulkExportRoutes(fhirGroup *echo.Group) {
 	fhirGroup.GET("/$export-output/:jobId/:fileName", h.ExportOutput)
 }
 
+// RegisterSignedFileRoute registers the signed output file download route,
+// ExportFilePath, on e. The route is outside the FHIR group because its
+// requests carry a signature instead of an access token; the path must be
+// exempt from authentication.
+func (h *BulkExportHandler) RegisterSignedFileRoute(e *echo.Echo) {
+	e.GET(ExportFilePath, h.SignedExportFile)
+}
+
 // SystemExportKickOff handles GET /fhir/$export (system-level export).
 // This is intended for admin users to export all data.
 func (h *BulkExportHandler) SystemExportKickOff(c echo.Context) error {
@@ -148,9 +157,9 @@ func (h *BulkExportHandler) GroupExportKickOff(c echo.Context) error {
 
 	// Validate _outputFormat
 	outputFormat := c.QueryParam("_outputFormat")
-	if outputFormat != "" && !validOutputFormats[outputFormat] {
+	if _, ok := canonicalExportFormat(outputFormat); !ok {
 		return c.JSON(http.StatusBadRequest, ErrorOutcome(
-			fmt.Sprintf("unsupported _outputFormat: %s; only application/fhir+ndjson is supported", outputFormat)))
+			fmt.Sprintf("unsupported _outputFormat: %s; only application/fhir+ndjson and %s are supported", outputFormat, ParquetOutputFormat)))
 	}
 
 	// Parse _type
@@ -188,7 +197,13 @@ func (h *BulkExportHandler) GroupExportKickOff(c echo.Context) error {
 		}
 	}
 
-	job, err := h.manager.KickOffForGroup(resourceTypes, groupID, since, outputFormat, typeFilter)
+	job, err := h.manager.StartExport(c.Request().Context(), ExportRequest{
+		ResourceTypes: resourceTypes,
+		GroupID:       groupID,
+		Since:         since,
+		OutputFormat:  outputFormat,
+		TypeFilter:    typeFilter,
+	})
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			return c.JSON(http.StatusNotFound, ErrorOutcome(err.Error()))
@@ -215,9 +230,9 @@ func (h *BulkExportHandler) GroupExportKickOff(c echo.Context) error {
 func (h *BulkExportHandler) kickOffExport(c echo.Context, patientID string) error {
 	// Validate _outputFormat
 	outputFormat := c.QueryParam("_outputFormat")
-	if outputFormat != "" && !validOutputFormats[outputFormat] {
+	if _, ok := canonicalExportFormat(outputFormat); !ok {
 		return c.JSON(http.StatusBadRequest, ErrorOutcome(
-			fmt.Sprintf("unsupported _outputFormat: %s; only application/fhir+ndjson is supported", outputFormat)))
+			fmt.Sprintf("unsupported _outputFormat: %s; only application/fhir+ndjson and %s are supported", outputFormat, ParquetOutputFormat)))
 	}
 
 	// Parse _type
@@ -243,14 +258,26 @@ func (h *BulkExportHandler) kickOffExport(c echo.Context, patientID string) erro
 		since = &t
 	}
 
-	// Delegate to ExportManager for job creation and async processing
-	var job *ExportJob
-	var err error
-	if patientID != "" {
-		job, err = h.manager.KickOffForPatient(resourceTypes, patientID, since)
-	} else {
-		job, err = h.manager.KickOff(resourceTypes, since)
+	// Parse _typeFilter
+	var typeFilter []string
+	typeFilterParam := c.QueryParam("_typeFilter")
+	if typeFilterParam != "" {
+		for _, tf := range strings.Split(typeFilterParam, ",") {
+			tf = strings.TrimSpace(tf)
+			if tf != "" {
+				typeFilter = append(typeFilter, tf)
+			}
+		}
 	}
+
+	// Delegate to ExportManager for job creation and async processing
+	job, err := h.manager.StartExport(c.Request().Context(), ExportRequest{
+		ResourceTypes: resourceTypes,
+		PatientID:     patientID,
+		Since:         since,
+		OutputFormat:  outputFormat,
+		TypeFilter:    typeFilter,
+	})
 	if err != nil {
 		if strings.Contains(err.Error(), "concurrent") {
 			c.Response().Header().Set("Retry-After", "120")
@@ -275,6 +302,8 @@ func (h *BulkExportHandler) kickOffExport(c echo.Context, patientID string) erro
 //
 // Response when in progress: 202 Accepted with X-Progress and Retry-After: 10.
 // Response when complete: 200 OK with the FHIR Bulk Data status response body.
+// The output URLs are signed, and need no access token, when the manager
+// signs download URLs.
 // Response when failed: 500 with FHIR OperationOutcome.
 func (h *BulkExportHandler) ExportPollStatus(c echo.Context) error {
 	jobID := c.QueryParam("job")
@@ -283,7 +312,7 @@ func (h *BulkExportHandler) ExportPollStatus(c echo.Context) error {
 	}
 
 	// Fetch the latest status from the manager (which owns the async state)
-	job, err := h.manager.GetStatus(jobID)
+	job, err := h.manager.GetStatusContext(c.Request().Context(), jobID)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, ErrorOutcome(err.Error()))
 	}
@@ -300,7 +329,7 @@ func (h *BulkExportHandler) ExportPollStatus(c echo.Context) error {
 		for _, f := range job.OutputFiles {
 			output = append(output, map[string]interface{}{
 				"type":  f.Type,
-				"url":   h.buildOutputURL(job.ID, f.Type),
+				"url":   h.buildOutputURL(job, f),
 				"count": f.Count,
 			})
 		}
@@ -308,7 +337,7 @@ func (h *BulkExportHandler) ExportPollStatus(c echo.Context) error {
 		result := map[string]interface{}{
 			"transactionTime":     job.RequestTime.Format(time.RFC3339),
 			"request":             h.buildRequestURL(job),
-			"requiresAccessToken": true,
+			"requiresAccessToken": !h.manager.SignsURLs(),
 			"output":              output,
 			"error":               []interface{}{},
 		}
@@ -330,7 +359,7 @@ func (h *BulkExportHandler) DeleteExportPollStatus(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorOutcome("job query parameter is required"))
 	}
 
-	err := h.manager.DeleteJob(jobID)
+	err := h.manager.DeleteJobContext(c.Request().Context(), jobID)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, ErrorOutcome(err.Error()))
 	}
@@ -342,31 +371,43 @@ func (h *BulkExportHandler) DeleteExportPollStatus(c echo.Context) error {
 }
 
 // ExportOutput handles GET /fhir/$export-output/:jobId/:fileName.
-// Downloads the NDJSON file for the specified resource type.
-// The fileName is expected to be in the form "<ResourceType>.ndjson".
+// Streams the output file from the blob store. The fileName is expected to
+// be in the form "<ResourceType>.ndjson", or "<ResourceType>-<n>.ndjson"
+// for a type's further files (".parquet" for Parquet exports); the
+// extension may be omitted (backward compat).
 func (h *BulkExportHandler) ExportOutput(c echo.Context) error {
+	return h.serveFile(c, c.Param("jobId"), c.Param("fileName"))
+}
+
+// SignedExportFile handles GET /fhir/$export-file/:jobId/:fileName, the
+// signed download URLs of output files. The expires and signature query
+// parameters take the place of an access token.
+func (h *BulkExportHandler) SignedExportFile(c echo.Context) error {
 	jobID := c.Param("jobId")
 	fileName := c.Param("fileName")
-
-	// Strip .ndjson suffix to get the resource type
-	resourceType := strings.TrimSuffix(fileName, ".ndjson")
-	if resourceType == fileName {
-		// No .ndjson suffix; try using fileName as-is (backward compat)
-		resourceType = fileName
+	if err := h.manager.VerifyFileSignature(jobID, fileName, c.QueryParam("expires"), c.QueryParam("signature")); err != nil {
+		return c.JSON(http.StatusForbidden, ErrorOutcome(err.Error()))
 	}
+	return h.serveFile(c, jobID, fileName)
+}
 
-	data, err := h.manager.GetJobData(jobID, resourceType)
+func (h *BulkExportHandler) serveFile(c echo.Context, jobID, fileName string) error {
+	file, contentType, err := h.manager.OpenFile(c.Request().Context(), jobID, fileName)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, ErrorOutcome(err.Error()))
 	}
+	defer file.Close()
 
-	c.Response().Header().Set("Content-Type", "application/fhir+ndjson")
-	return c.Blob(http.StatusOK, "application/fhir+ndjson", data)
+	return c.Stream(http.StatusOK, contentType, file)
 }
 
-// buildOutputURL constructs the download URL for an export output file.
-func (h *BulkExportHandler) buildOutputURL(jobID, resourceType string) string {
-	return fmt.Sprintf("%s/fhir/$export-output/%s/%s.ndjson", h.baseURL, jobID, resourceType)
+// buildOutputURL constructs the download URL for an export output file,
+// signed when the manager signs download URLs.
+func (h *BulkExportHandler) buildOutputURL(job *ExportJob, f ExportOutputFile) string {
+	if signed := h.manager.SignedFileURL(job.ID, f, job.OutputFormat); signed != "" {
+		return h.baseURL + signed
+	}
+	return fmt.Sprintf("%s/fhir/$export-output/%s/%s%s", h.baseURL, job.ID, f.name(), exportFileExt(job.OutputFormat))
 }
 
 // buildRequestURL reconstructs the original kick-off request URL.
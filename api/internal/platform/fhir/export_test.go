@@ -308,6 +308,40 @@ func TestExportManager_GetJobData_RealData(t *testing.T) {
 	}
 }
 
+func TestExportManager_MergesStoredExtras(t *testing.T) {
+	extras := NewInMemoryExtrasStore()
+	_ = extras.PutExtras(context.Background(), "Observation", "obs-1", map[string]interface{}{
+		"meta": map[string]interface{}{"tag": []interface{}{map[string]interface{}{"system": "urn:queue", "code": "triage"}}},
+	})
+	mgr := NewExportManagerWithOptions(ExportOptions{Extras: extras})
+	mgr.RegisterExporter("Observation", &mockExporter{
+		resources: []map[string]interface{}{
+			{"resourceType": "Observation", "id": "obs-1", "status": "final"},
+			{"resourceType": "Observation", "id": "obs-2", "status": "final"},
+		},
+	})
+
+	job := mustKickOff(t, mgr, []string{"Observation"}, nil)
+	waitForComplete(t, mgr, job.ID, 5*time.Second)
+	data, err := mgr.GetJobData(job.ID, "Observation")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	var first, second map[string]interface{}
+	_ = json.Unmarshal([]byte(lines[0]), &first)
+	_ = json.Unmarshal([]byte(lines[1]), &second)
+	if meta, _ := first["meta"].(map[string]interface{}); meta == nil || meta["tag"] == nil {
+		t.Errorf("expected the stored tag on obs-1, got %v", first)
+	}
+	if second["meta"] != nil {
+		t.Errorf("expected no meta on obs-2, got %v", second["meta"])
+	}
+}
+
 func TestExportManager_PatientExport(t *testing.T) {
 	mgr := NewExportManager()
 	exporter := &mockExporter{
@@ -0,0 +1,224 @@
+package fhir
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ehr/ehr/internal/platform/blobstore"
+)
+
+// errExportFileAborted aborts the upload of an unfinished output file.
+var errExportFileAborted = errors.New("export file aborted")
+
+// exportFileWriter streams the resources of one type of an export job into
+// output files in the manager's blob store. A file is finished once it
+// reaches the manager's maximum file size, so it exceeds it by at most one
+// resource, and the type continues in a new file. Each finished file is
+// added to the job's output files and checkpointed, so that a resumed job
+// continues after it.
+type exportFileWriter struct {
+	m            *ExportManager
+	ctx          context.Context
+	job          *ExportJob
+	resourceType string
+
+	// skip is the number of resources in the files the type already has.
+	skip int
+	// part is the number of files started for the type.
+	part int
+
+	file  exportFile
+	count int
+}
+
+// exportFile is an output file being written.
+type exportFile interface {
+	// write adds a resource, marshalled as line.
+	write(resource map[string]interface{}, line []byte) error
+	// size returns the bytes written so far.
+	size() int64
+	// finish completes the file and returns its blob ID.
+	finish() (string, error)
+	// abort discards the file.
+	abort()
+}
+
+func (m *ExportManager) newExportFileWriter(ctx context.Context, job *ExportJob, resourceType string) *exportFileWriter {
+	w := &exportFileWriter{m: m, ctx: ctx, job: job, resourceType: resourceType}
+	m.mu.RLock()
+	for _, f := range job.OutputFiles {
+		if f.Type == resourceType && f.blobID != "" {
+			w.skip += f.Count
+			w.part++
+		}
+	}
+	m.mu.RUnlock()
+	return w
+}
+
+// write adds a resource to the current file, starting one if needed, and
+// finishes the file when it reaches the maximum size.
+func (w *exportFileWriter) write(resource map[string]interface{}) error {
+	line, err := json.Marshal(resource)
+	if err != nil {
+		return fmt.Errorf("json marshal failed for %s: %w", w.resourceType, err)
+	}
+	if w.file == nil {
+		w.part++
+		w.count = 0
+		meta := blobstore.BlobMetadata{
+			FileName:    fmt.Sprintf("export-%s-%s%s", w.job.ID, exportFileName(w.resourceType, w.part), exportFileExt(w.job.OutputFormat)),
+			ContentType: w.job.OutputFormat,
+			Category:    "other",
+			Tags:        map[string]string{"export_job": w.job.ID},
+		}
+		if w.job.OutputFormat == ParquetOutputFormat {
+			w.file = newParquetExportFile(w.ctx, w.m.blobs, meta)
+		} else {
+			w.file = newNDJSONExportFile(w.ctx, w.m.blobs, meta)
+		}
+	}
+	if err := w.file.write(resource, line); err != nil {
+		return fmt.Errorf("write %s export file: %w", w.resourceType, err)
+	}
+	w.count++
+	if w.file.size() >= w.m.maxFileSize {
+		return w.close()
+	}
+	return nil
+}
+
+// close finishes the current file, if any, and records it on the job.
+func (w *exportFileWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	blobID, err := w.file.finish()
+	w.file = nil
+	if err != nil {
+		return fmt.Errorf("store %s export file: %w", w.resourceType, err)
+	}
+	w.m.mu.Lock()
+	w.job.OutputFiles = append(w.job.OutputFiles, ExportOutputFile{
+		Type:   w.resourceType,
+		URL:    exportDataURL(w.job.ID, exportFileName(w.resourceType, w.part)),
+		Count:  w.count,
+		blobID: blobID,
+	})
+	w.m.mu.Unlock()
+	return w.m.checkpoint(w.ctx, w.job)
+}
+
+// abort discards the current file, if any.
+func (w *exportFileWriter) abort() {
+	if w.file != nil {
+		w.file.abort()
+		w.file = nil
+	}
+}
+
+// ndjsonExportFile streams NDJSON lines to a blob store upload through a
+// pipe. The file is buffered only as far as the store buffers an upload:
+// PGBlobStore stores it in 1 MB chunks as it is read, while
+// blobstore.InMemoryBlobStore holds every file, up to the job's MaxFileSize,
+// in memory.
+type ndjsonExportFile struct {
+	blobs   blobstore.BlobStore
+	pw      *io.PipeWriter
+	result  chan ndjsonUpload
+	written int64
+}
+
+type ndjsonUpload struct {
+	meta *blobstore.BlobMetadata
+	err  error
+}
+
+func newNDJSONExportFile(ctx context.Context, blobs blobstore.BlobStore, meta blobstore.BlobMetadata) *ndjsonExportFile {
+	pr, pw := io.Pipe()
+	f := &ndjsonExportFile{blobs: blobs, pw: pw, result: make(chan ndjsonUpload, 1)}
+	go func() {
+		stored, err := blobs.Upload(ctx, meta, pr)
+		// Unblock the writer if the upload stopped reading early.
+		if err != nil {
+			pr.CloseWithError(err)
+		} else {
+			pr.Close()
+		}
+		f.result <- ndjsonUpload{meta: stored, err: err}
+	}()
+	return f
+}
+
+func (f *ndjsonExportFile) write(_ map[string]interface{}, line []byte) error {
+	n, err := f.pw.Write(append(line, '\n'))
+	f.written += int64(n)
+	return err
+}
+
+func (f *ndjsonExportFile) size() int64 {
+	return f.written
+}
+
+func (f *ndjsonExportFile) finish() (string, error) {
+	f.pw.Close()
+	r := <-f.result
+	if r.err != nil {
+		return "", r.err
+	}
+	return r.meta.ID, nil
+}
+
+func (f *ndjsonExportFile) abort() {
+	f.pw.CloseWithError(errExportFileAborted)
+	if r := <-f.result; r.err == nil && r.meta != nil {
+		_ = f.blobs.Delete(context.Background(), r.meta.ID)
+	}
+}
+
+// parquetExportFile collects the rows of a Parquet file, which are written
+// as one row group when the file is finished. Its size is the size of the
+// column data collected so far.
+type parquetExportFile struct {
+	ctx   context.Context
+	blobs blobstore.BlobStore
+	meta  blobstore.BlobMetadata
+	rows  *parquetRows
+}
+
+func newParquetExportFile(ctx context.Context, blobs blobstore.BlobStore, meta blobstore.BlobMetadata) *parquetExportFile {
+	return &parquetExportFile{ctx: ctx, blobs: blobs, meta: meta, rows: newParquetRows(exportParquetColumns)}
+}
+
+func (f *parquetExportFile) write(resource map[string]interface{}, line []byte) error {
+	id, _ := resource["id"].(string)
+	resourceType, _ := resource["resourceType"].(string)
+	var lastUpdated string
+	if meta, ok := resource["meta"].(map[string]interface{}); ok {
+		lastUpdated, _ = meta["lastUpdated"].(string)
+	}
+	f.rows.add(resourceType, id, lastUpdated, line)
+	return nil
+}
+
+func (f *parquetExportFile) size() int64 {
+	return f.rows.size()
+}
+
+func (f *parquetExportFile) finish() (string, error) {
+	var buf bytes.Buffer
+	if err := f.rows.writeTo(&buf); err != nil {
+		return "", err
+	}
+	stored, err := f.blobs.Upload(f.ctx, f.meta, &buf)
+	if err != nil {
+		return "", err
+	}
+	return stored.ID, nil
+}
+
+func (f *parquetExportFile) abort() {}
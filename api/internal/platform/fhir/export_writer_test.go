@@ -0,0 +1,392 @@
+package fhir
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ehr/ehr/internal/platform/blobstore"
+	"github.com/labstack/echo/v4"
+)
+
+func testPatients(ids ...string) []map[string]interface{} {
+	var out []map[string]interface{}
+	for _, id := range ids {
+		out = append(out, map[string]interface{}{
+			"resourceType": "Patient",
+			"id":           id,
+			"meta":         map[string]interface{}{"lastUpdated": "2024-01-01T00:00:00Z"},
+		})
+	}
+	return out
+}
+
+func TestExportManager_SplitsFilesAtMaxSize(t *testing.T) {
+	mgr := NewExportManagerWithOptions(ExportOptions{MaxFileSize: 1})
+	mgr.RegisterExporter("Patient", &mockExporter{resources: testPatients("p1", "p2", "p3")})
+
+	job, err := mgr.KickOff([]string{"Patient"}, nil)
+	if err != nil {
+		t.Fatalf("KickOff failed: %v", err)
+	}
+	result := waitForComplete(t, mgr, job.ID, 2*time.Second)
+	if result.Status != "complete" {
+		t.Fatalf("expected complete, got %s: %s", result.Status, result.ErrorMessage)
+	}
+
+	if len(result.OutputFiles) != 3 {
+		t.Fatalf("expected 3 output files, got %+v", result.OutputFiles)
+	}
+	for i, name := range []string{"Patient", "Patient-2", "Patient-3"} {
+		f := result.OutputFiles[i]
+		if f.Type != "Patient" || f.Count != 1 || !strings.HasSuffix(f.URL, "/"+name) {
+			t.Errorf("file %d = %+v, want %s with 1 resource", i, f, name)
+		}
+	}
+	data, err := mgr.GetJobData(job.ID, "Patient-2.ndjson")
+	if err != nil {
+		t.Fatalf("GetJobData failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"id":"p2"`) || strings.Count(string(data), "\n") != 1 {
+		t.Errorf("expected Patient-2 to hold p2 only, got %q", data)
+	}
+}
+
+func TestExportManager_ParquetOutput(t *testing.T) {
+	mgr := NewExportManager()
+	mgr.RegisterExporter("Patient", &mockExporter{resources: testPatients("p1", "p2")})
+
+	job, err := mgr.KickOffWithFormat([]string{"Patient"}, "", nil, "parquet", nil)
+	if err != nil {
+		t.Fatalf("KickOffWithFormat failed: %v", err)
+	}
+	if job.OutputFormat != ParquetOutputFormat {
+		t.Errorf("expected format %s, got %s", ParquetOutputFormat, job.OutputFormat)
+	}
+	waitForComplete(t, mgr, job.ID, 2*time.Second)
+
+	rc, contentType, err := mgr.OpenFile(context.Background(), job.ID, "Patient.parquet")
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer rc.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(rc)
+	data := buf.Bytes()
+
+	if contentType != ParquetOutputFormat {
+		t.Errorf("expected content type %s, got %s", ParquetOutputFormat, contentType)
+	}
+	if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatalf("expected the Parquet magic at both ends of the file")
+	}
+	for _, col := range exportParquetColumns {
+		if !bytes.Contains(data, []byte(col.name)) {
+			t.Errorf("expected column %s in the schema", col.name)
+		}
+	}
+	if !bytes.Contains(data, []byte(`{"id":"p2","meta":{"lastUpdated":"2024-01-01T00:00:00Z"},"resourceType":"Patient"}`)) {
+		t.Error("expected the resource JSON in the resource column")
+	}
+}
+
+func TestParquetRows_WriteTo(t *testing.T) {
+	rows := newParquetRows([]parquetColumn{{name: "a", typ: parquetTypeByteArray}})
+	rows.add([]byte("x"))
+	rows.add([]byte("yz"))
+	if rows.size() != 11 {
+		t.Errorf("expected 11 bytes of column data, got %d", rows.size())
+	}
+	var buf bytes.Buffer
+	if err := rows.writeTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// The data page follows the header magic and a page header.
+	if !bytes.Contains(data, []byte("\x01\x00\x00\x00x\x02\x00\x00\x00yz")) {
+		t.Error("expected PLAIN-encoded values in the data page")
+	}
+	footer := int(data[len(data)-8]) | int(data[len(data)-7])<<8
+	if footer <= 0 || footer > len(data)-12 {
+		t.Errorf("invalid footer length %d", footer)
+	}
+}
+
+func TestServiceExporter_StreamAll_Pages(t *testing.T) {
+	var offsets []int
+	exporter := &ServiceExporter{
+		ResourceType: "Patient",
+		PageFn: func(ctx context.Context, since *time.Time, limit, offset int) ([]map[string]interface{}, error) {
+			offsets = append(offsets, offset)
+			if offset >= 250 {
+				return nil, nil
+			}
+			n := limit
+			if offset+n > 250 {
+				n = 250 - offset
+			}
+			return make([]map[string]interface{}, n), nil
+		},
+	}
+
+	count := 0
+	err := exporter.StreamAll(context.Background(), nil, func(map[string]interface{}) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamAll failed: %v", err)
+	}
+	if count != 250 {
+		t.Errorf("expected 250 resources, got %d", count)
+	}
+	if len(offsets) != 4 || offsets[1] != exportPageSize || offsets[3] != 250 {
+		t.Errorf("unexpected page offsets %v", offsets)
+	}
+}
+
+func TestExportManager_TypeFilterSearch(t *testing.T) {
+	var searches []url.Values
+	reg := NewResourceRegistry()
+	reg.Register("Observation", ResourceOps{Search: func(ctx context.Context, params url.Values) ([]map[string]interface{}, error) {
+		searches = append(searches, params)
+		if params.Get("_offset") != "0" {
+			return nil, nil
+		}
+		// Both queries match o2, which is exported once.
+		if params.Get("code") == "a" {
+			return []map[string]interface{}{{"resourceType": "Observation", "id": "o1"}, {"resourceType": "Observation", "id": "o2"}}, nil
+		}
+		return []map[string]interface{}{{"resourceType": "Observation", "id": "o2"}}, nil
+	}})
+	mgr := NewExportManagerWithOptions(ExportOptions{Resources: reg})
+	mgr.RegisterExporter("Observation", &mockExporter{})
+
+	job, err := mgr.KickOffWithFormat(nil, "p1", nil, "", []string{"Observation?code=a", "Observation?code=b"})
+	if err != nil {
+		t.Fatalf("KickOffWithFormat failed: %v", err)
+	}
+	result := waitForComplete(t, mgr, job.ID, 2*time.Second)
+	if result.Status != "complete" || len(result.OutputFiles) != 1 || result.OutputFiles[0].Count != 2 {
+		t.Fatalf("expected o1 and o2 to be exported, got %+v", result)
+	}
+	if len(searches) == 0 || searches[0].Get("subject") != "Patient/p1" && searches[0].Get("patient") != "Patient/p1" {
+		t.Errorf("expected the searches to be limited to the patient, got %v", searches)
+	}
+
+	if _, err := mgr.KickOffWithFormat(nil, "", nil, "", []string{"Unknown?code=a"}); err == nil || !strings.Contains(err.Error(), "unsupported _typeFilter") {
+		t.Errorf("expected an unsupported _typeFilter error, got %v", err)
+	}
+	if _, err := mgr.KickOffWithFormat(nil, "", nil, "", []string{"code=a"}); err == nil {
+		t.Error("expected an error for a _typeFilter without a resource type")
+	}
+}
+
+// savedExportJob stores an in-progress export job of Patient resources as
+// an interrupted job that finished the given output files.
+func savedExportJob(t *testing.T, store AsyncJobStore, outputs ...AsyncJobOutput) (string, json.RawMessage) {
+	t.Helper()
+	payload, _ := json.Marshal(&exportJobPayload{
+		ExportRequest: ExportRequest{ResourceTypes: []string{"Patient"}, OutputFormat: "application/fhir+ndjson"},
+		RequestTime:   time.Now().UTC(),
+	})
+	job := &AsyncJob{
+		ID: "export-1", Status: AsyncStatusInProgress, Kind: exportJobKind,
+		Payload: payload, Output: outputs, TransactionTS: time.Now().UTC(),
+	}
+	if err := store.Create(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	return job.ID, payload
+}
+
+func TestExportJobFunc_ResumesAfterFinishedFiles(t *testing.T) {
+	blobs := blobstore.NewInMemoryBlobStore()
+	store := NewInMemoryAsyncJobStore()
+	mgr := NewExportManagerWithOptions(ExportOptions{Blobs: blobs, Jobs: store})
+	mgr.RegisterExporter("Patient", &mockExporter{resources: testPatients("p1", "p2", "p3")})
+
+	first, err := blobs.Upload(context.Background(), blobstore.BlobMetadata{
+		FileName: "Patient.ndjson", ContentType: "application/fhir+ndjson", Category: "other",
+	}, strings.NewReader(`{"id":"p1","resourceType":"Patient"}`+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobID, payload := savedExportJob(t, store, AsyncJobOutput{Type: "Patient", URL: "blob://" + first.ID, Count: 1})
+
+	if err := ExportJobFunc(mgr)(context.Background(), jobID, payload); err != nil {
+		t.Fatalf("export job failed: %v", err)
+	}
+
+	job, err := mgr.GetStatus(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != "complete" || len(job.OutputFiles) != 2 || job.OutputFiles[1].Count != 2 {
+		t.Fatalf("expected the job to add p2 and p3 in a second file, got %+v", job)
+	}
+	data, err := mgr.GetJobData(jobID, "Patient-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"p1"`) || !strings.Contains(string(data), `"p3"`) {
+		t.Errorf("expected the second file to continue after p1, got %q", data)
+	}
+	saved, _ := store.Get(context.Background(), jobID)
+	if saved.Status != AsyncStatusCompleted || len(saved.Output) != 2 || saved.Processed != 1 {
+		t.Errorf("expected the completed job to be saved, got %+v", saved)
+	}
+}
+
+func TestExportJobFunc_RestartsWhenFilesAreLost(t *testing.T) {
+	store := NewInMemoryAsyncJobStore()
+	mgr := NewExportManagerWithOptions(ExportOptions{Jobs: store})
+	mgr.RegisterExporter("Patient", &mockExporter{resources: testPatients("p1", "p2", "p3")})
+	jobID, payload := savedExportJob(t, store, AsyncJobOutput{Type: "Patient", URL: "blob://missing", Count: 1})
+
+	if err := ExportJobFunc(mgr)(context.Background(), jobID, payload); err != nil {
+		t.Fatalf("export job failed: %v", err)
+	}
+	job, _ := mgr.GetStatus(jobID)
+	if len(job.OutputFiles) != 1 || job.OutputFiles[0].Count != 3 {
+		t.Errorf("expected the job to export all resources again, got %+v", job.OutputFiles)
+	}
+}
+
+func TestExportJobFunc_ResumesAtOffset(t *testing.T) {
+	blobs := blobstore.NewInMemoryBlobStore()
+	store := NewInMemoryAsyncJobStore()
+	mgr := NewExportManagerWithOptions(ExportOptions{Blobs: blobs, Jobs: store})
+	patients := testPatients("p1", "p2", "p3")
+	var offsets []int
+	mgr.RegisterExporter("Patient", &ServiceExporter{
+		ResourceType: "Patient",
+		PageFn: func(ctx context.Context, since *time.Time, limit, offset int) ([]map[string]interface{}, error) {
+			offsets = append(offsets, offset)
+			if offset >= len(patients) {
+				return nil, nil
+			}
+			return patients[offset:], nil
+		},
+	})
+
+	first, err := blobs.Upload(context.Background(), blobstore.BlobMetadata{
+		FileName: "Patient.ndjson", ContentType: "application/fhir+ndjson", Category: "other",
+	}, strings.NewReader(`{"id":"p1","resourceType":"Patient"}`+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobID, payload := savedExportJob(t, store, AsyncJobOutput{Type: "Patient", URL: "blob://" + first.ID, Count: 1})
+
+	if err := ExportJobFunc(mgr)(context.Background(), jobID, payload); err != nil {
+		t.Fatalf("export job failed: %v", err)
+	}
+	if len(offsets) == 0 || offsets[0] != 1 {
+		t.Errorf("expected the resumed job to read from offset 1, got %v", offsets)
+	}
+	job, _ := mgr.GetStatus(jobID)
+	if job.Status != "complete" || len(job.OutputFiles) != 2 || job.OutputFiles[1].Count != 2 {
+		t.Fatalf("expected p2 and p3 in a second file, got %+v", job)
+	}
+}
+
+func TestExportJobFunc_RewindsToTypeWithLostFiles(t *testing.T) {
+	blobs := blobstore.NewInMemoryBlobStore()
+	store := NewInMemoryAsyncJobStore()
+	mgr := NewExportManagerWithOptions(ExportOptions{Blobs: blobs, Jobs: store})
+	mgr.RegisterExporter("Patient", &mockExporter{resources: testPatients("p1")})
+	mgr.RegisterExporter("Observation", &mockExporter{resources: []map[string]interface{}{
+		{"resourceType": "Observation", "id": "o1"}, {"resourceType": "Observation", "id": "o2"},
+	}})
+
+	kept, err := blobs.Upload(context.Background(), blobstore.BlobMetadata{
+		FileName: "Patient.ndjson", ContentType: "application/fhir+ndjson", Category: "other",
+	}, strings.NewReader(`{"id":"p1","resourceType":"Patient"}`+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := json.Marshal(&exportJobPayload{
+		ExportRequest: ExportRequest{ResourceTypes: []string{"Patient", "Observation"}, OutputFormat: "application/fhir+ndjson"},
+		RequestTime:   time.Now().UTC(),
+	})
+	saved := &AsyncJob{
+		ID: "export-2", Status: AsyncStatusInProgress, Kind: exportJobKind, Payload: payload, Processed: 1,
+		Output: []AsyncJobOutput{
+			{Type: "Patient", URL: "blob://" + kept.ID, Count: 1},
+			{Type: "Observation", URL: "blob://missing", Count: 1},
+		},
+		TransactionTS: time.Now().UTC(),
+	}
+	if err := store.Create(context.Background(), saved); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ExportJobFunc(mgr)(context.Background(), saved.ID, payload); err != nil {
+		t.Fatalf("export job failed: %v", err)
+	}
+	job, _ := mgr.GetStatus(saved.ID)
+	if len(job.OutputFiles) != 2 || job.OutputFiles[0].blobID != kept.ID || job.OutputFiles[1].Count != 2 {
+		t.Errorf("expected the Patient file to be kept and Observation exported again, got %+v", job.OutputFiles)
+	}
+}
+
+func TestBulkExportHandler_SignedURLs(t *testing.T) {
+	mgr := NewExportManagerWithOptions(ExportOptions{SigningKey: []byte("secret")})
+	mgr.RegisterExporter("Patient", &mockExporter{resources: testPatients("p1")})
+	h := NewBulkExportHandler(NewExportStore(), mgr, "")
+	e := echo.New()
+	h.RegisterBulkExportRoutes(e.Group("/fhir"))
+	h.RegisterSignedFileRoute(e)
+
+	job, err := mgr.KickOff([]string{"Patient"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForComplete(t, mgr, job.ID, 2*time.Second)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fhir/$export-poll-status?job="+job.ID, nil))
+	var manifest struct {
+		RequiresAccessToken bool `json:"requiresAccessToken"`
+		Output              []struct {
+			URL string `json:"url"`
+		} `json:"output"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &manifest); err != nil || len(manifest.Output) != 1 {
+		t.Fatalf("unexpected manifest %s: %v", rec.Body.String(), err)
+	}
+	if manifest.RequiresAccessToken {
+		t.Error("expected signed URLs not to require an access token")
+	}
+	signed := manifest.Output[0].URL
+	if !strings.HasPrefix(signed, "/fhir/$export-file/"+job.ID+"/Patient.ndjson?expires=") {
+		t.Fatalf("unexpected signed URL %s", signed)
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, signed, nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"p1"`) {
+		t.Errorf("expected the file, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	expired := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	for _, target := range []string{
+		strings.Replace(signed, "signature=", "signature=x", 1),
+		strings.Replace(signed, "Patient.ndjson", "Observation.ndjson", 1),
+		"/fhir/$export-file/" + job.ID + "/Patient.ndjson?expires=" + expired + "&signature=" + mgr.fileSignature(job.ID, "Patient.ndjson", expired),
+		"/fhir/$export-file/" + job.ID + "/Patient.ndjson",
+	} {
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403, got %d", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,728 @@
+package fhir
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// ExtrasStore persists, for each resource, the elements of its FHIR
+// representation that the relational domain model does not store: unknown
+// extensions and modifierExtensions, contained resources, meta tags, security
+// labels and profiles, and any other element the model's ToFHIR does not
+// reproduce. The stored elements are merged back into every read, and into
+// the snapshots recorded in resource_history, so resources round-trip intact.
+type ExtrasStore interface {
+	// GetExtras returns the stored elements for a resource, or nil if none.
+	GetExtras(ctx context.Context, resourceType, resourceID string) (map[string]interface{}, error)
+	// GetExtrasBatch returns the stored elements for several resources of
+	// one type, keyed by resource id. Resources without extras are omitted.
+	GetExtrasBatch(ctx context.Context, resourceType string, resourceIDs []string) (map[string]map[string]interface{}, error)
+	// PutExtras replaces the stored elements for a resource. An empty
+	// elements map removes them.
+	PutExtras(ctx context.Context, resourceType, resourceID string, elements map[string]interface{}) error
+	// DeleteExtras removes the stored elements for a resource.
+	DeleteExtras(ctx context.Context, resourceType, resourceID string) error
+}
+
+// =========== Extraction and merging ===========
+
+// choiceDataTypes lists the FHIR data type names used as suffixes of
+// choice elements (value[x], onset[x], ...).
+var choiceDataTypes = []string{
+	"Base64Binary", "Boolean", "Canonical", "Code", "Date", "DateTime", "Decimal",
+	"Id", "Instant", "Integer", "Markdown", "Oid", "PositiveInt", "String", "Time",
+	"UnsignedInt", "Uri", "Url", "Uuid", "Address", "Age", "Annotation", "Attachment",
+	"CodeableConcept", "Coding", "ContactPoint", "Count", "Distance", "Duration",
+	"HumanName", "Identifier", "Money", "Period", "Quantity", "Range", "Ratio",
+	"Reference", "SampledData", "Signature", "Timing", "ContactDetail", "Contributor",
+	"DataRequirement", "Expression", "ParameterDefinition", "RelatedArtifact",
+	"TriggerDefinition", "UsageContext", "Dosage", "Meta",
+}
+
+// choiceBase returns the element name without its type suffix when name looks
+// like a choice element such as "onsetDateTime", or "" otherwise.
+func choiceBase(name string) string {
+	best := ""
+	for _, suffix := range choiceDataTypes {
+		if len(suffix) > len(best) && len(name) > len(suffix) && strings.HasSuffix(name, suffix) {
+			best = suffix
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return name[:len(name)-len(best)]
+}
+
+// hasChoiceSibling reports whether m contains another type variant of the
+// choice element name, e.g. "onsetPeriod" when name is "onsetDateTime".
+func hasChoiceSibling(m map[string]interface{}, name string) bool {
+	base := choiceBase(name)
+	if base == "" {
+		return false
+	}
+	for k := range m {
+		if k != name && choiceBase(k) == base {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractExtras returns the elements of resource, a FHIR resource as received
+// from a client, that are absent from modeled, the ToFHIR output of the domain
+// model parsed from it. Objects are compared element by element, extension
+// arrays by url and primitive arrays (such as meta.profile) by value. Other
+// arrays are compared item by item as canonical JSON: when the modeled items
+// are the leading items of the received array, only the remaining items are
+// extracted; otherwise the received array is extracted whole, along with the
+// modeled array it replaces (see replacedArray). The resource id and the
+// server-managed meta.versionId and meta.lastUpdated are never extracted.
+func ExtractExtras(resource, modeled map[string]interface{}) map[string]interface{} {
+	in := deepCopyMap(resource)
+	delete(in, "resourceType")
+	delete(in, "id")
+	if meta, ok := in["meta"].(map[string]interface{}); ok {
+		delete(meta, "versionId")
+		delete(meta, "lastUpdated")
+		if len(meta) == 0 {
+			delete(in, "meta")
+		}
+	}
+	return diffElements(in, deepCopyMap(modeled))
+}
+
+func diffElements(in, modeled map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	for k, v := range in {
+		mv, ok := modeled[k]
+		if !ok {
+			if !hasChoiceSibling(modeled, k) {
+				out[k] = v
+			}
+			continue
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			if mm, ok := mv.(map[string]interface{}); ok {
+				if d := diffElements(val, mm); len(d) > 0 {
+					out[k] = d
+				}
+			}
+		case []interface{}:
+			ma, _ := mv.([]interface{})
+			d := diffArray(k, val, ma)
+			switch {
+			case len(d) == 0:
+			case isExtensionElement(k) || isPrimitiveArray(val) || hasPrefixItems(val, ma):
+				out[k] = d
+			default:
+				out[k] = replacedArray(ma, val)
+			}
+		}
+	}
+	return out
+}
+
+// diffArray returns the items of in that are missing from modeled: extensions
+// whose url is not modeled, primitives whose value is not, and other items
+// with no equal modeled item.
+func diffArray(name string, in, modeled []interface{}) []interface{} {
+	var out []interface{}
+	switch {
+	case isExtensionElement(name):
+		urls := make(map[string]bool, len(modeled))
+		for _, item := range modeled {
+			urls[extensionURL(item)] = true
+		}
+		for _, item := range in {
+			if !urls[extensionURL(item)] {
+				out = append(out, item)
+			}
+		}
+	case isPrimitiveArray(in):
+		have := make(map[interface{}]bool, len(modeled))
+		for _, item := range modeled {
+			have[item] = true
+		}
+		for _, item := range in {
+			if !have[item] {
+				out = append(out, item)
+			}
+		}
+	default:
+		have := make(map[string]bool, len(modeled))
+		for _, item := range modeled {
+			have[canonicalJSON(item)] = true
+		}
+		for _, item := range in {
+			if !have[canonicalJSON(item)] {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+// canonicalJSON returns the JSON encoding of v, which for decoded JSON values
+// is canonical because object keys are encoded in sorted order.
+func canonicalJSON(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// hasPrefixItems reports whether the items of modeled are, in order, the
+// leading items of in, so that appending the rest of in restores it.
+func hasPrefixItems(in, modeled []interface{}) bool {
+	if len(modeled) > len(in) {
+		return false
+	}
+	for i, item := range modeled {
+		if canonicalJSON(item) != canonicalJSON(in[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Keys of a replaced-array entry. They are not valid FHIR element names, so
+// the entry cannot be mistaken for a FHIR object.
+const (
+	replacedModeledKey  = "$modeled"
+	replacedOriginalKey = "$original"
+)
+
+// replacedArray returns the extras entry for an array the model does not
+// reproduce item for item, such as a Patient's identifiers when the model
+// keeps a single normalized MRN: the received array, restored on read in
+// place of the modeled one, and the modeled array it was captured against.
+func replacedArray(modeled, original []interface{}) map[string]interface{} {
+	if modeled == nil {
+		modeled = []interface{}{}
+	}
+	return map[string]interface{}{
+		replacedModeledKey:  modeled,
+		replacedOriginalKey: original,
+	}
+}
+
+// restoredArray returns the received array of a replaced-array entry if cur,
+// the array the model emits now, is still the one it was captured against.
+// Once the modeled data has changed, the model's array takes precedence.
+func restoredArray(entry map[string]interface{}, cur interface{}) ([]interface{}, bool) {
+	modeled, ok := entry[replacedModeledKey]
+	if !ok {
+		return nil, false
+	}
+	original, _ := entry[replacedOriginalKey].([]interface{})
+	if canonicalJSON(modeled) != canonicalJSON(cur) {
+		return nil, false
+	}
+	return original, true
+}
+
+func isReplacedArray(v interface{}) bool {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = m[replacedModeledKey]
+	return ok
+}
+
+func isExtensionElement(name string) bool {
+	return name == "extension" || name == "modifierExtension"
+}
+
+func extensionURL(item interface{}) string {
+	if m, ok := item.(map[string]interface{}); ok {
+		if url, ok := m["url"].(string); ok {
+			return url
+		}
+	}
+	return ""
+}
+
+func isPrimitiveArray(items []interface{}) bool {
+	for _, item := range items {
+		switch item.(type) {
+		case string, float64, bool:
+		default:
+			return false
+		}
+	}
+	return len(items) > 0
+}
+
+// MergeExtras merges stored extras into resource in place. Elements produced
+// by the domain model take precedence; extras only fill in what is missing,
+// and a replaced array is restored only while the model still emits the array
+// it was captured against, so merging is idempotent.
+func MergeExtras(resource, extras map[string]interface{}) {
+	for k, v := range extras {
+		cur, ok := resource[k]
+		if !ok {
+			if !hasChoiceSibling(resource, k) && !isReplacedArray(v) {
+				resource[k] = v
+			}
+			continue
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			if isReplacedArray(val) {
+				if original, ok := restoredArray(val, cur); ok {
+					resource[k] = original
+				}
+			} else if cm, ok := cur.(map[string]interface{}); ok {
+				MergeExtras(cm, val)
+			}
+		case []interface{}:
+			if ca, ok := cur.([]interface{}); ok {
+				if missing := diffArray(k, val, ca); len(missing) > 0 {
+					resource[k] = append(ca, missing...)
+				}
+			}
+		}
+	}
+}
+
+// =========== Pending extras ===========
+
+type pendingExtrasKey struct{}
+
+// pendingExtras carries the extras of a resource parsed from a request body
+// until the domain service records the resource with the VersionTracker,
+// which is the first point at which its id is known.
+type pendingExtras struct {
+	mu           sync.Mutex
+	resourceType string
+	resourceID   string // empty on create
+	elements     map[string]interface{}
+	consumed     bool
+}
+
+// withPendingExtras returns a context carrying the extras of a parsed
+// resourceType resource. resourceID is empty for creates.
+func withPendingExtras(ctx context.Context, resourceType, resourceID string, elements map[string]interface{}) context.Context {
+	return context.WithValue(ctx, pendingExtrasKey{}, &pendingExtras{
+		resourceType: resourceType,
+		resourceID:   resourceID,
+		elements:     elements,
+	})
+}
+
+// takePendingExtras returns the pending extras for the given resource, if the
+// request parsed one, marking them consumed so that they are applied once.
+func takePendingExtras(ctx context.Context, resourceType, resourceID string) (map[string]interface{}, bool) {
+	p, ok := ctx.Value(pendingExtrasKey{}).(*pendingExtras)
+	if !ok {
+		return nil, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.consumed || p.resourceType != resourceType || (p.resourceID != "" && p.resourceID != resourceID) {
+		return nil, false
+	}
+	p.consumed = true
+	return p.elements, true
+}
+
+// captureExtras computes the extras of data, which was parsed into dst, and
+// attaches them to the request context for the VersionTracker to persist.
+func captureExtras(c echo.Context, resourceType, resourceID string, data map[string]interface{}, dst ResourceParser) {
+	m, ok := dst.(interface{ ToFHIR() map[string]interface{} })
+	if !ok {
+		return
+	}
+	extras := ExtractExtras(data, m.ToFHIR())
+	ctx := withPendingExtras(c.Request().Context(), resourceType, resourceID, extras)
+	c.SetRequest(c.Request().WithContext(ctx))
+}
+
+// =========== PATCH ===========
+
+type extrasStoreKey struct{}
+
+type patchTargetKey struct{}
+
+// PatchTarget returns current, the ToFHIR output of a stored resource, with
+// the resource's stored extras merged in. It is the document a PATCH request
+// is applied to, so that a patch can address the elements the model does not
+// store and those elements survive it. When the extras cannot be read, current
+// is returned as is and BindPatched leaves the stored extras untouched.
+func PatchTarget(c echo.Context, current map[string]interface{}) map[string]interface{} {
+	target := deepCopyMap(current)
+	ctx := c.Request().Context()
+	store, ok := ctx.Value(extrasStoreKey{}).(ExtrasStore)
+	rt, _ := target["resourceType"].(string)
+	id, _ := target["id"].(string)
+	if !ok || rt == "" || id == "" {
+		return target
+	}
+	extras, err := store.GetExtras(ctx, rt, id)
+	if err != nil {
+		return target
+	}
+	MergeExtras(target, extras)
+	c.SetRequest(c.Request().WithContext(context.WithValue(ctx, patchTargetKey{}, true)))
+	return target
+}
+
+// BindPatched parses patched, the result of applying a PATCH to the document
+// returned by PatchTarget, into dst. As BindResource does for a request body,
+// it attaches the elements dst does not model to the request context so that
+// the VersionTracker persists them as the resource's extras.
+func BindPatched(c echo.Context, patched map[string]interface{}, dst ResourceParser) error {
+	if err := dst.FromFHIR(patched); err != nil {
+		return err
+	}
+	if ok, _ := c.Request().Context().Value(patchTargetKey{}).(bool); ok {
+		rt, _ := patched["resourceType"].(string)
+		id, _ := patched["id"].(string)
+		captureExtras(c, rt, id, patched, dst)
+	}
+	return nil
+}
+
+// =========== Response middleware ===========
+
+// ExtrasMiddleware merges stored extras into the FHIR resources returned by
+// the wrapped handlers, including the entries of searchset and transaction
+// response Bundles. History responses are left untouched because their
+// snapshots already carry the extras that applied to each version. The store
+// is also made available to PatchTarget.
+func ExtrasMiddleware(store ExtrasStore) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.SetRequest(c.Request().WithContext(context.WithValue(c.Request().Context(), extrasStoreKey{}, store)))
+			if strings.Contains(c.Request().URL.Path, "/_history") {
+				return next(c)
+			}
+
+			rec := &extrasResponseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = rec
+			err := next(c)
+			c.Response().Writer = rec.ResponseWriter
+			if err != nil || rec.passthrough {
+				return err
+			}
+
+			body := rec.body.Bytes()
+			if merged, ok := mergeResponseExtras(c.Request().Context(), store, body); ok {
+				body = merged
+			}
+			_, writeErr := c.Response().Writer.Write(body)
+			return writeErr
+		}
+	}
+}
+
+// extrasResponseRecorder buffers JSON response bodies for ExtrasMiddleware.
+// Other content types, and responses that are flushed while being written,
+// pass straight through.
+type extrasResponseRecorder struct {
+	http.ResponseWriter
+	body        bytes.Buffer
+	decided     bool
+	passthrough bool
+}
+
+func (r *extrasResponseRecorder) Write(b []byte) (int, error) {
+	if !r.decided {
+		r.decided = true
+		r.passthrough = !strings.Contains(r.Header().Get(echo.HeaderContentType), "json")
+	}
+	if r.passthrough {
+		return r.ResponseWriter.Write(b)
+	}
+	return r.body.Write(b)
+}
+
+func (r *extrasResponseRecorder) Flush() {
+	if !r.passthrough {
+		r.decided, r.passthrough = true, true
+		_, _ = r.ResponseWriter.Write(r.body.Bytes())
+		r.body.Reset()
+	}
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// mergeResponseExtras merges extras into a JSON response body. It returns
+// false when the body is not a FHIR resource or nothing was merged.
+func mergeResponseExtras(ctx context.Context, store ExtrasStore, body []byte) ([]byte, bool) {
+	var resource map[string]interface{}
+	if len(body) == 0 || body[0] != '{' || json.Unmarshal(body, &resource) != nil {
+		return nil, false
+	}
+
+	// Group the resources by type so each type needs a single lookup.
+	byType := make(map[string]map[string][]map[string]interface{})
+	add := func(res map[string]interface{}) {
+		rt, _ := res["resourceType"].(string)
+		id, _ := res["id"].(string)
+		if rt == "" || id == "" || rt == "OperationOutcome" || rt == "Bundle" {
+			return
+		}
+		if byType[rt] == nil {
+			byType[rt] = make(map[string][]map[string]interface{})
+		}
+		byType[rt][id] = append(byType[rt][id], res)
+	}
+	if resource["resourceType"] == "Bundle" {
+		entries, _ := resource["entry"].([]interface{})
+		for _, entry := range entries {
+			if em, ok := entry.(map[string]interface{}); ok {
+				if res, ok := em["resource"].(map[string]interface{}); ok {
+					add(res)
+				}
+			}
+		}
+	} else {
+		add(resource)
+	}
+	if len(byType) == 0 {
+		return nil, false
+	}
+
+	merged := false
+	for rt, resources := range byType {
+		ids := make([]string, 0, len(resources))
+		for id := range resources {
+			ids = append(ids, id)
+		}
+		extras, err := store.GetExtrasBatch(ctx, rt, ids)
+		if err != nil {
+			continue
+		}
+		for id, elements := range extras {
+			for _, res := range resources[id] {
+				MergeExtras(res, elements)
+				merged = true
+			}
+		}
+	}
+	if !merged {
+		return nil, false
+	}
+	out, err := json.Marshal(resource)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// MergeStoredExtras merges the stored extras of resources, all of type
+// resourceType, into them in place. It is the counterpart of ExtrasMiddleware
+// for resources serialized outside an HTTP response, such as the output files
+// of $export.
+func MergeStoredExtras(ctx context.Context, store ExtrasStore, resourceType string, resources []map[string]interface{}) error {
+	byID := make(map[string][]map[string]interface{}, len(resources))
+	ids := make([]string, 0, len(resources))
+	for _, res := range resources {
+		id, _ := res["id"].(string)
+		if id == "" {
+			continue
+		}
+		if _, ok := byID[id]; !ok {
+			ids = append(ids, id)
+		}
+		byID[id] = append(byID[id], res)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	extras, err := store.GetExtrasBatch(ctx, resourceType, ids)
+	if err != nil {
+		return err
+	}
+	for id, elements := range extras {
+		for _, res := range byID[id] {
+			MergeExtras(res, elements)
+		}
+	}
+	return nil
+}
+
+// =========== In-memory store ===========
+
+// InMemoryExtrasStore is a thread-safe in-memory implementation of ExtrasStore.
+type InMemoryExtrasStore struct {
+	mu   sync.RWMutex
+	data map[string]map[string]interface{} // key: "resourceType/resourceID"
+}
+
+// NewInMemoryExtrasStore creates a new InMemoryExtrasStore.
+func NewInMemoryExtrasStore() *InMemoryExtrasStore {
+	return &InMemoryExtrasStore{data: make(map[string]map[string]interface{})}
+}
+
+// GetExtras returns a copy of the stored elements for a resource.
+func (s *InMemoryExtrasStore) GetExtras(_ context.Context, resourceType, resourceID string) (map[string]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if e, ok := s.data[resourceType+"/"+resourceID]; ok {
+		return deepCopyMap(e), nil
+	}
+	return nil, nil
+}
+
+// GetExtrasBatch returns copies of the stored elements for several resources.
+func (s *InMemoryExtrasStore) GetExtrasBatch(_ context.Context, resourceType string, resourceIDs []string) (map[string]map[string]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]map[string]interface{})
+	for _, id := range resourceIDs {
+		if e, ok := s.data[resourceType+"/"+id]; ok {
+			out[id] = deepCopyMap(e)
+		}
+	}
+	return out, nil
+}
+
+// PutExtras replaces the stored elements for a resource.
+func (s *InMemoryExtrasStore) PutExtras(_ context.Context, resourceType, resourceID string, elements map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := resourceType + "/" + resourceID
+	if len(elements) == 0 {
+		delete(s.data, key)
+		return nil
+	}
+	s.data[key] = deepCopyMap(elements)
+	return nil
+}
+
+// DeleteExtras removes the stored elements for a resource.
+func (s *InMemoryExtrasStore) DeleteExtras(_ context.Context, resourceType, resourceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, resourceType+"/"+resourceID)
+	return nil
+}
+
+// =========== PostgreSQL store ===========
+
+// ExtrasRepository stores extras in the shared resource_extras table, using
+// the tenant connection or transaction carried by the context.
+type ExtrasRepository struct{}
+
+// NewExtrasRepository creates a new ExtrasRepository.
+func NewExtrasRepository() *ExtrasRepository {
+	return &ExtrasRepository{}
+}
+
+func (r *ExtrasRepository) conn(ctx context.Context) historyQuerier {
+	if tx := db.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	if c := db.ConnFromContext(ctx); c != nil {
+		return c
+	}
+	return nil
+}
+
+// GetExtras returns the stored elements for a resource, or nil if none.
+func (r *ExtrasRepository) GetExtras(ctx context.Context, resourceType, resourceID string) (map[string]interface{}, error) {
+	q := r.conn(ctx)
+	if q == nil {
+		return nil, fmt.Errorf("no database connection in context")
+	}
+	var data []byte
+	err := q.QueryRow(ctx, `
+		SELECT elements FROM resource_extras
+		WHERE resource_type = $1 AND resource_id = $2`,
+		resourceType, resourceID).Scan(&data)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get resource extras: %w", err)
+	}
+	var elements map[string]interface{}
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return nil, fmt.Errorf("unmarshal resource extras: %w", err)
+	}
+	return elements, nil
+}
+
+// GetExtrasBatch returns the stored elements for several resources of one type.
+func (r *ExtrasRepository) GetExtrasBatch(ctx context.Context, resourceType string, resourceIDs []string) (map[string]map[string]interface{}, error) {
+	q := r.conn(ctx)
+	if q == nil {
+		return nil, fmt.Errorf("no database connection in context")
+	}
+	rows, err := q.Query(ctx, `
+		SELECT resource_id, elements FROM resource_extras
+		WHERE resource_type = $1 AND resource_id = ANY($2)`,
+		resourceType, resourceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list resource extras: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]map[string]interface{})
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("scan resource extras: %w", err)
+		}
+		var elements map[string]interface{}
+		if err := json.Unmarshal(data, &elements); err != nil {
+			return nil, fmt.Errorf("unmarshal resource extras: %w", err)
+		}
+		out[id] = elements
+	}
+	return out, rows.Err()
+}
+
+// PutExtras replaces the stored elements for a resource.
+func (r *ExtrasRepository) PutExtras(ctx context.Context, resourceType, resourceID string, elements map[string]interface{}) error {
+	if len(elements) == 0 {
+		return r.DeleteExtras(ctx, resourceType, resourceID)
+	}
+	q := r.conn(ctx)
+	if q == nil {
+		return fmt.Errorf("no database connection in context")
+	}
+	data, err := json.Marshal(elements)
+	if err != nil {
+		return fmt.Errorf("marshal resource extras: %w", err)
+	}
+	_, err = q.Exec(ctx, `
+		INSERT INTO resource_extras (resource_type, resource_id, elements, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (resource_type, resource_id)
+		DO UPDATE SET elements = EXCLUDED.elements, updated_at = NOW()`,
+		resourceType, resourceID, data)
+	if err != nil {
+		return fmt.Errorf("save resource extras: %w", err)
+	}
+	return nil
+}
+
+// DeleteExtras removes the stored elements for a resource.
+func (r *ExtrasRepository) DeleteExtras(ctx context.Context, resourceType, resourceID string) error {
+	q := r.conn(ctx)
+	if q == nil {
+		return fmt.Errorf("no database connection in context")
+	}
+	_, err := q.Exec(ctx, `DELETE FROM resource_extras WHERE resource_type = $1 AND resource_id = $2`,
+		resourceType, resourceID)
+	if err != nil {
+		return fmt.Errorf("delete resource extras: %w", err)
+	}
+	return nil
+}
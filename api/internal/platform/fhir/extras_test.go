@@ -0,0 +1,413 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// extrasTestModel is a minimal domain model that only maps status and code.
+type extrasTestModel struct {
+	ID     string
+	Status string
+	Code   string
+}
+
+func (m *extrasTestModel) FromFHIR(data map[string]interface{}) error {
+	r := NewResourceReader("Observation", data)
+	m.Status = r.StringValue("status")
+	m.Code = r.StringValue("code.coding.code")
+	return r.Err()
+}
+
+func (m *extrasTestModel) ToFHIR() map[string]interface{} {
+	return map[string]interface{}{
+		"resourceType": "Observation",
+		"id":           m.ID,
+		"status":       m.Status,
+		"code":         CodeableConcept{Coding: []Coding{{Code: m.Code}}},
+		"meta":         Meta{VersionID: "1", Profile: []string{"http://hl7.org/fhir/StructureDefinition/Observation"}},
+	}
+}
+
+func parseJSONMap(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+const extrasTestResource = `{
+	"resourceType": "Observation",
+	"id": "obs-1",
+	"meta": {
+		"versionId": "7",
+		"profile": ["http://hl7.org/fhir/StructureDefinition/Observation", "http://hl7.org/fhir/us/core/StructureDefinition/us-core-observation-lab"],
+		"tag": [{"system": "urn:ehr:queue", "code": "triage"}]
+	},
+	"extension": [{"url": "http://example.org/ext/source", "valueString": "feed"}],
+	"status": "final",
+	"code": {"coding": [{"code": "1234-5"}], "text": "Glucose"},
+	"contained": [{"resourceType": "Specimen", "id": "s1"}],
+	"valueQuantity": {"value": 5.4, "unit": "mmol/L"}
+}`
+
+func TestExtractExtras(t *testing.T) {
+	in := parseJSONMap(t, extrasTestResource)
+	var m extrasTestModel
+	if err := m.FromFHIR(in); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	extras := ExtractExtras(in, m.ToFHIR())
+
+	for _, key := range []string{"extension", "contained", "valueQuantity"} {
+		if _, ok := extras[key]; !ok {
+			t.Errorf("expected %s to be extracted", key)
+		}
+	}
+	for _, key := range []string{"resourceType", "id", "status"} {
+		if _, ok := extras[key]; ok {
+			t.Errorf("did not expect %s to be extracted", key)
+		}
+	}
+	code, _ := extras["code"].(map[string]interface{})
+	if code["text"] != "Glucose" || code["coding"] != nil {
+		t.Errorf("expected only code.text to be extracted, got %v", code)
+	}
+	meta, _ := extras["meta"].(map[string]interface{})
+	if meta["versionId"] != nil {
+		t.Error("meta.versionId must not be extracted")
+	}
+	profiles, _ := meta["profile"].([]interface{})
+	if len(profiles) != 1 || profiles[0] != "http://hl7.org/fhir/us/core/StructureDefinition/us-core-observation-lab" {
+		t.Errorf("expected only the US Core profile to be extracted, got %v", profiles)
+	}
+	if meta["tag"] == nil {
+		t.Error("expected meta.tag to be extracted")
+	}
+}
+
+func TestExtractExtras_ExtensionsByURL(t *testing.T) {
+	in := map[string]interface{}{
+		"extension": []interface{}{
+			map[string]interface{}{"url": "http://example.org/race", "valueString": "a"},
+			map[string]interface{}{"url": "http://example.org/other", "valueString": "b"},
+		},
+	}
+	modeled := map[string]interface{}{
+		"extension": []interface{}{
+			map[string]interface{}{"url": "http://example.org/race", "valueString": "a"},
+		},
+	}
+	extras := ExtractExtras(in, modeled)
+	exts, _ := extras["extension"].([]interface{})
+	if len(exts) != 1 || extensionURL(exts[0]) != "http://example.org/other" {
+		t.Errorf("expected only the unmodeled extension, got %v", exts)
+	}
+}
+
+func TestExtractExtras_ChoiceVariantModeled(t *testing.T) {
+	in := map[string]interface{}{"onsetPeriod": map[string]interface{}{"start": "2024-01-01"}}
+	modeled := map[string]interface{}{"onsetDateTime": "2024-01-01"}
+	if extras := ExtractExtras(in, modeled); len(extras) != 0 {
+		t.Errorf("expected no extras when another choice variant is modeled, got %v", extras)
+	}
+}
+
+func TestExtractExtras_ComplexArrays(t *testing.T) {
+	// A Patient whose model keeps one normalized MRN identifier and the
+	// official name.
+	in := parseJSONMap(t, `{
+		"resourceType": "Patient",
+		"identifier": [
+			{"type": {"coding": [{"code": "MR"}]}, "value": "MRN-1"},
+			{"system": "http://hl7.org/fhir/sid/us-ssn", "value": "123-45-6789"}
+		],
+		"name": [
+			{"use": "official", "family": "Doe", "given": ["Jane"]},
+			{"use": "maiden", "family": "Roe", "given": ["Jane"]}
+		],
+		"telecom": [{"system": "phone", "value": "555-0100"}]
+	}`)
+	modeled := parseJSONMap(t, `{
+		"resourceType": "Patient",
+		"identifier": [
+			{"use": "usual", "type": {"coding": [{"system": "http://terminology.hl7.org/CodeSystem/v2-0203", "code": "MR"}]}, "value": "MRN-1"}
+		],
+		"name": [
+			{"use": "official", "family": "Doe", "given": ["Jane"]}
+		],
+		"telecom": [{"system": "phone", "value": "555-0100"}]
+	}`)
+	extras := ExtractExtras(in, modeled)
+
+	names, _ := extras["name"].([]interface{})
+	if len(names) != 1 || names[0].(map[string]interface{})["use"] != "maiden" {
+		t.Errorf("expected only the maiden name to be extracted, got %v", extras["name"])
+	}
+	if !isReplacedArray(extras["identifier"]) {
+		t.Errorf("expected the identifiers to be extracted as a replaced array, got %v", extras["identifier"])
+	}
+	if _, ok := extras["telecom"]; ok {
+		t.Errorf("did not expect the modeled telecom to be extracted, got %v", extras["telecom"])
+	}
+
+	out := deepCopyMap(modeled)
+	MergeExtras(out, extras)
+	MergeExtras(out, extras) // idempotent
+	delete(in, "resourceType")
+	delete(out, "resourceType")
+	if got, want := canonicalJSON(out), canonicalJSON(in); got != want {
+		t.Errorf("round trip mismatch:\n got %s\nwant %s", got, want)
+	}
+
+	// Once the modeled identifier changes, the model's array is served.
+	changed := deepCopyMap(modeled)
+	changed["identifier"].([]interface{})[0].(map[string]interface{})["value"] = "MRN-2"
+	want := canonicalJSON(changed["identifier"])
+	MergeExtras(changed, extras)
+	if got := canonicalJSON(changed["identifier"]); got != want {
+		t.Errorf("expected the changed modeled identifiers to take precedence, got %s", got)
+	}
+}
+
+func TestMergeExtras_RoundTrip(t *testing.T) {
+	in := parseJSONMap(t, extrasTestResource)
+	var m extrasTestModel
+	if err := m.FromFHIR(in); err != nil {
+		t.Fatalf("FromFHIR: %v", err)
+	}
+	m.ID = "obs-1"
+	extras := ExtractExtras(in, m.ToFHIR())
+
+	out := deepCopyMap(m.ToFHIR())
+	MergeExtras(out, extras)
+	MergeExtras(out, extras) // idempotent
+
+	want := parseJSONMap(t, extrasTestResource)
+	wantMeta := want["meta"].(map[string]interface{})
+	wantMeta["versionId"] = "1"
+	wantMeta["lastUpdated"] = "0001-01-01T00:00:00Z"
+	got, _ := json.Marshal(out)
+	wantJSON, _ := json.Marshal(want)
+	if string(got) != string(wantJSON) {
+		t.Errorf("round trip mismatch:\n got %s\nwant %s", got, wantJSON)
+	}
+}
+
+func TestMergeExtras_ModelTakesPrecedence(t *testing.T) {
+	res := map[string]interface{}{"status": "final"}
+	MergeExtras(res, map[string]interface{}{"status": "preliminary", "language": "en"})
+	if res["status"] != "final" || res["language"] != "en" {
+		t.Errorf("unexpected merge result: %v", res)
+	}
+}
+
+func TestBindResource_CapturesPendingExtras(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/Observation", strings.NewReader(extrasTestResource))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	var m extrasTestModel
+	if err := BindResource(c, "Observation", "", &m); err != nil {
+		t.Fatalf("BindResource: %v", err)
+	}
+	ctx := c.Request().Context()
+	if _, ok := takePendingExtras(ctx, "Patient", "obs-1"); ok {
+		t.Error("pending extras must not apply to another resource type")
+	}
+	extras, ok := takePendingExtras(ctx, "Observation", "obs-1")
+	if !ok || extras["extension"] == nil {
+		t.Fatalf("expected pending extras, got %v", extras)
+	}
+	if _, ok := takePendingExtras(ctx, "Observation", "obs-2"); ok {
+		t.Error("pending extras must only be applied once")
+	}
+}
+
+func TestBindPatched_KeepsStoredExtras(t *testing.T) {
+	store := NewInMemoryExtrasStore()
+	stored := map[string]interface{}{
+		"extension": []interface{}{map[string]interface{}{"url": "http://example.org/ext/source", "valueString": "feed"}},
+	}
+	if err := store.PutExtras(context.Background(), "Observation", "obs-1", stored); err != nil {
+		t.Fatalf("PutExtras: %v", err)
+	}
+
+	var pending map[string]interface{}
+	var target map[string]interface{}
+	e := echo.New()
+	e.PATCH("/Observation/:id", func(c echo.Context) error {
+		existing := &extrasTestModel{ID: "obs-1", Status: "preliminary", Code: "1234-5"}
+		target = PatchTarget(c, existing.ToFHIR())
+		patched, err := ApplyMergePatch(target, map[string]interface{}{"status": "final", "language": "en"})
+		if err != nil {
+			return err
+		}
+		if err := BindPatched(c, patched, existing); err != nil {
+			return err
+		}
+		pending, _ = takePendingExtras(c.Request().Context(), "Observation", "obs-1")
+		return c.JSON(http.StatusOK, existing.ToFHIR())
+	}, ExtrasMiddleware(store))
+
+	req := httptest.NewRequest(http.MethodPatch, "/Observation/obs-1", nil)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	if target["extension"] == nil {
+		t.Errorf("expected the stored extension in the patch target, got %v", target)
+	}
+	if pending["extension"] == nil || pending["language"] != "en" {
+		t.Errorf("expected the stored extension and the patched language as pending extras, got %v", pending)
+	}
+	if _, ok := pending["status"]; ok {
+		t.Errorf("did not expect the modeled status to be extracted, got %v", pending)
+	}
+}
+
+func TestVersionTracker_ApplyExtras(t *testing.T) {
+	store := NewInMemoryExtrasStore()
+	vt := NewVersionTracker(NewHistoryRepository())
+	vt.SetExtrasStore(store)
+
+	pending := map[string]interface{}{"language": "fr"}
+	ctx := withPendingExtras(context.Background(), "Observation", "", pending)
+	res, err := vt.applyExtras(ctx, "Observation", "obs-1", map[string]interface{}{"resourceType": "Observation", "id": "obs-1"}, false)
+	if err != nil {
+		t.Fatalf("applyExtras: %v", err)
+	}
+	if res.(map[string]interface{})["language"] != "fr" {
+		t.Errorf("expected snapshot to include pending extras, got %v", res)
+	}
+	stored, _ := store.GetExtras(ctx, "Observation", "obs-1")
+	if stored["language"] != "fr" {
+		t.Errorf("expected pending extras to be stored, got %v", stored)
+	}
+
+	// A later update without a parsed body keeps the stored extras.
+	res, err = vt.applyExtras(context.Background(), "Observation", "obs-1", (&extrasTestModel{ID: "obs-1", Status: "amended"}).ToFHIR(), true)
+	if err != nil {
+		t.Fatalf("applyExtras: %v", err)
+	}
+	if m, ok := res.(map[string]interface{}); !ok || m["language"] != "fr" || m["status"] != "amended" {
+		t.Errorf("expected stored extras to be merged, got %v", res)
+	}
+
+	// A replacement without extras clears them.
+	ctx = withPendingExtras(context.Background(), "Observation", "obs-1", map[string]interface{}{})
+	if _, err := vt.applyExtras(ctx, "Observation", "obs-1", map[string]interface{}{}, false); err != nil {
+		t.Fatalf("applyExtras: %v", err)
+	}
+	if stored, _ := store.GetExtras(ctx, "Observation", "obs-1"); stored != nil {
+		t.Errorf("expected extras to be cleared, got %v", stored)
+	}
+}
+
+func TestExtrasMiddleware(t *testing.T) {
+	store := NewInMemoryExtrasStore()
+	ctx := context.Background()
+	_ = store.PutExtras(ctx, "Observation", "obs-1", map[string]interface{}{"language": "fr"})
+	_ = store.PutExtras(ctx, "Observation", "obs-2", map[string]interface{}{"language": "de"})
+
+	e := echo.New()
+	mw := ExtrasMiddleware(store)
+
+	tests := []struct {
+		name    string
+		path    string
+		handler echo.HandlerFunc
+		check   func(t *testing.T, body map[string]interface{})
+	}{
+		{
+			name: "read",
+			path: "/fhir/Observation/obs-1",
+			handler: func(c echo.Context) error {
+				return c.JSON(http.StatusOK, map[string]interface{}{"resourceType": "Observation", "id": "obs-1"})
+			},
+			check: func(t *testing.T, body map[string]interface{}) {
+				if body["language"] != "fr" {
+					t.Errorf("expected extras merged into resource, got %v", body)
+				}
+			},
+		},
+		{
+			name: "search bundle",
+			path: "/fhir/Observation",
+			handler: func(c echo.Context) error {
+				return c.JSON(http.StatusOK, map[string]interface{}{
+					"resourceType": "Bundle",
+					"entry": []map[string]interface{}{
+						{"resource": map[string]interface{}{"resourceType": "Observation", "id": "obs-1"}},
+						{"resource": map[string]interface{}{"resourceType": "Observation", "id": "obs-2"}},
+						{"resource": map[string]interface{}{"resourceType": "Observation", "id": "obs-3"}},
+					},
+				})
+			},
+			check: func(t *testing.T, body map[string]interface{}) {
+				entries := body["entry"].([]interface{})
+				langs := []interface{}{"fr", "de", nil}
+				for i, entry := range entries {
+					res := entry.(map[string]interface{})["resource"].(map[string]interface{})
+					if res["language"] != langs[i] {
+						t.Errorf("entry %d: expected language %v, got %v", i, langs[i], res["language"])
+					}
+				}
+			},
+		},
+		{
+			name: "history untouched",
+			path: "/fhir/Observation/obs-1/_history/1",
+			handler: func(c echo.Context) error {
+				return c.JSON(http.StatusOK, map[string]interface{}{"resourceType": "Observation", "id": "obs-1"})
+			},
+			check: func(t *testing.T, body map[string]interface{}) {
+				if _, ok := body["language"]; ok {
+					t.Error("history responses must not be merged with current extras")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if err := mw(tt.handler)(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", rec.Code)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			tt.check(t, body)
+		})
+	}
+}
+
+func TestExtrasMiddleware_NonJSONPassthrough(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Binary/b1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := ExtrasMiddleware(NewInMemoryExtrasStore())(func(c echo.Context) error {
+		return c.Blob(http.StatusOK, "application/pdf", []byte("%PDF-1.4"))
+	})
+	if err := h(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Body.String() != "%PDF-1.4" {
+		t.Errorf("expected body to pass through, got %q", rec.Body.String())
+	}
+}
@@ -185,7 +185,7 @@ func tokenizeFilter(filter string) ([]filterToken, error) {
 //   andExpr  -> unaryExpr ("and" unaryExpr)*
 //   unaryExpr -> "not" unaryExpr | primary
 //   primary  -> "(" expr ")" | paramExpr
-//   paramExpr -> WORD OPERATOR (VALUE | epsilon for "pr")
+//   paramExpr -> WORD OPERATOR (VALUE | ["true" | "false"] for "pr")
 // ---------------------------------------------------------------------------
 
 type filterParser struct {
@@ -365,13 +365,18 @@ func (p *filterParser) parseParamExpr() (*FilterExprNode, error) {
 		return nil, fmt.Errorf("unknown filter operator %q after parameter %q", operator, paramName)
 	}
 
-	// "pr" (present) is a unary operator with no value.
+	// "pr" (present) takes an optional true or false.
 	if operator == "pr" {
+		value := ""
+		if t := p.peek(); t != nil && t.Type == tokenWord && (t.Value == "true" || t.Value == "false") {
+			p.advance()
+			value = t.Value
+		}
 		return &FilterExprNode{
 			Type:     FilterExprParam,
 			Param:    paramName,
 			Operator: operator,
-			Value:    "",
+			Value:    value,
 		}, nil
 	}
 
@@ -463,7 +468,7 @@ func ValidateFilterExpression(expr *FilterExprNode, ctx *FilterContext) []Valida
 // isOperatorValidForType checks if a filter operator is valid for a given parameter type.
 func isOperatorValidForType(op, paramType string) bool {
 	switch paramType {
-	case "string":
+	case "string", "uri":
 		return validStringOps[op]
 	case "date":
 		return validDateOps[op]
@@ -553,7 +558,7 @@ func compileParamToSQL(param, operator, value string, ctx *FilterContext, startI
 
 	// Handle the "pr" (present) operator.
 	if operator == "pr" {
-		sql := compilePresentFilter(column, false)
+		sql := compilePresentFilter(column, value == "false")
 		return sql, nil, nil
 	}
 
@@ -817,6 +822,9 @@ func FilterExpressionToString(expr *FilterExprNode) string {
 	switch expr.Type {
 	case FilterExprParam:
 		if expr.Operator == "pr" {
+			if expr.Value != "" {
+				return expr.Param + " pr " + expr.Value
+			}
 			return expr.Param + " pr"
 		}
 		return fmt.Sprintf("%s %s %q", expr.Param, expr.Operator, expr.Value)
@@ -294,6 +294,19 @@ func TestParseFilterExpression_PresentOperator(t *testing.T) {
 	}
 }
 
+func TestParseFilterExpression_PresentWithValue(t *testing.T) {
+	expr, err := ParseFilterExpression(`name pr false and gender eq male`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expr.Type != FilterExprAnd || expr.Left.Operator != "pr" || expr.Left.Value != "false" {
+		t.Fatalf("unexpected tree: %s", FilterExpressionToString(expr))
+	}
+	if got := FilterExpressionToString(expr.Left); got != "name pr false" {
+		t.Errorf("FilterExpressionToString = %q, want %q", got, "name pr false")
+	}
+}
+
 func TestParseFilterExpression_TripleAnd(t *testing.T) {
 	expr, err := ParseFilterExpression(`a eq 1 and b eq 2 and c eq 3`)
 	if err != nil {
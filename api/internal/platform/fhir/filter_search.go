@@ -0,0 +1,229 @@
+package fhir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// searchParamTypeNames names the search parameter types as the _filter
+// operator tables do (see isOperatorValidForType).
+var searchParamTypeNames = map[SearchParamType]string{
+	SearchParamToken:     "token",
+	SearchParamDate:      "date",
+	SearchParamString:    "string",
+	SearchParamReference: "reference",
+	SearchParamNumber:    "number",
+	SearchParamQuantity:  "quantity",
+	SearchParamURI:       "uri",
+}
+
+// applyFilter adds the clause for a _filter expression, compiled against
+// the search parameters of the table. An expression that does not compile
+// matches nothing; FilterMiddleware rejects those with 400 before they reach
+// the repositories.
+func (q *SearchQuery) applyFilter(value string, configs map[string]SearchParamConfig) {
+	expr, err := ParseFilterExpression(value)
+	if err == nil {
+		sub := q.subquery(q.table)
+		var clause string
+		if clause, err = sub.filterClause(expr, configs, 0); err == nil {
+			q.where += " AND " + clause
+			q.args = append(q.args, sub.args...)
+			q.idx = sub.idx
+			return
+		}
+	}
+	q.where += " AND 1=0"
+}
+
+// ErrFilterNotSupported is returned by ValidateFilter for resource types
+// whose repository has no registered search table to compile _filter
+// against.
+var ErrFilterNotSupported = errors.New("_filter is not supported")
+
+// ValidateFilter reports why a _filter expression cannot be answered for
+// resourceType: it does not parse, names a parameter or operator the search
+// parameters registered for the type do not support, or the type has no
+// search table in the SearchServices of ctx (ErrFilterNotSupported), so its
+// repository would ignore the expression.
+func ValidateFilter(ctx context.Context, resourceType, filter string) error {
+	expr, err := ParseFilterExpression(filter)
+	if err != nil {
+		return err
+	}
+	services := SearchServicesFromContext(ctx)
+	table, configs, ok := services.Chains.tableConfigs(resourceType)
+	if !ok {
+		return fmt.Errorf("%w for %s", ErrFilterNotSupported, resourceType)
+	}
+	q := &SearchQuery{table: table, idx: 1, services: services}
+	_, err = q.filterClause(expr, configs, 0)
+	return err
+}
+
+// filterClause compiles a _filter expression tree into a SQL condition,
+// binding its arguments to q.
+func (q *SearchQuery) filterClause(expr *FilterExprNode, configs map[string]SearchParamConfig, depth int) (string, error) {
+	switch expr.Type {
+	case FilterExprAnd, FilterExprOr:
+		left, err := q.filterClause(expr.Left, configs, depth)
+		if err != nil {
+			return "", err
+		}
+		right, err := q.filterClause(expr.Right, configs, depth)
+		if err != nil {
+			return "", err
+		}
+		op := "AND"
+		if expr.Type == FilterExprOr {
+			op = "OR"
+		}
+		return fmt.Sprintf("(%s %s %s)", left, op, right), nil
+	case FilterExprNot:
+		child, err := q.filterClause(expr.Child, configs, depth)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("NOT (%s)", child), nil
+	case FilterExprParam:
+		return q.filterParamClause(expr, configs, depth)
+	default:
+		return "", fmt.Errorf("unknown filter expression type %d", expr.Type)
+	}
+}
+
+// filterParamClause compiles a single comparison. The parameter is a search
+// parameter of configs, or a path through reference parameters such as
+// subject.name or subject:Patient.general-practitioner.name, which is
+// answered on the tables registered with ChainRegistry.RegisterTable.
+func (q *SearchQuery) filterParamClause(expr *FilterExprNode, configs map[string]SearchParamConfig, depth int) (string, error) {
+	if chain, ok := ParseChainedParam(expr.Param); ok && !strings.HasPrefix(expr.Param, "_") {
+		return q.filterChainClause(chain, expr, configs, depth)
+	}
+	config, ok := configs[expr.Param]
+	if !ok {
+		return "", fmt.Errorf("unknown search parameter %q", expr.Param)
+	}
+	typeName := searchParamTypeNames[config.Type]
+	if !isOperatorValidForType(expr.Operator, typeName) {
+		return "", fmt.Errorf("operator %q is not supported for %s parameter %q", expr.Operator, typeName, expr.Param)
+	}
+	if expr.Operator == "pr" {
+		return compilePresentFilter(config.Column, expr.Value == "false"), nil
+	}
+
+	var clause string
+	var args []interface{}
+	switch config.Type {
+	case SearchParamDate:
+		clause, args, _ = DateSearchClause(config.Column, expr.Operator+expr.Value, q.idx)
+	case SearchParamNumber:
+		clause, args, _ = NumberSearchClause(config.Column, expr.Operator+expr.Value, q.idx)
+	case SearchParamQuantity:
+		if config.UnitColumn == "" {
+			clause, args, _ = NumberSearchClause(config.Column, expr.Operator+expr.Value, q.idx)
+			break
+		}
+		clause, args, _ = QuantitySearchClause(QuantityColumns{
+			Value: config.Column, System: config.SysColumn, Code: config.UnitColumn,
+			CanonicalValue: config.CanonicalColumn, CanonicalCode: config.CanonicalUnitColumn,
+			Analyte: config.AnalyteColumn,
+		}, expr.Operator+expr.Value, q.idx)
+	case SearchParamToken:
+		value := expr.Value
+		if i := strings.LastIndex(value, "|"); i >= 0 && config.SysColumn == "" {
+			value = value[i+1:]
+		}
+		clause, args = compileTokenFilter(config.Column, config.SysColumn, expr.Operator, value, q.idx)
+	case SearchParamReference:
+		clause, args, _ = ReferenceSearchClause(config.Column, expr.Value, q.idx)
+		if expr.Operator == "ne" {
+			clause = fmt.Sprintf("NOT (%s)", clause)
+		}
+	default:
+		clause, args = compileStringFilter(config.Column, expr.Operator, expr.Value, q.idx)
+	}
+	q.args = append(q.args, args...)
+	q.idx += len(args)
+	return clause, nil
+}
+
+// filterChainClause compiles a comparison on a parameter of the resources a
+// reference parameter points to: the reference column is matched against
+// the ids of the target resources that satisfy the rest of the path.
+func (q *SearchQuery) filterChainClause(chain *ChainedParam, expr *FilterExprNode, configs map[string]SearchParamConfig, depth int) (string, error) {
+	if depth >= MaxChainDepth {
+		return "", fmt.Errorf("parameter path %q is nested too deeply", expr.Param)
+	}
+	ref, ok := referenceConfig(configs, chain.SourceParam, chain.TargetType)
+	if !ok {
+		return "", fmt.Errorf("unknown reference parameter %q", chain.SourceParam)
+	}
+	targetType := chain.TargetType
+	if targetType == "" {
+		targetType = q.searchTables().referenceTargetType(ref.Column, chain.SourceParam)
+	}
+	table, targetConfigs, ok := q.searchTables().tableConfigs(targetType)
+	if !ok {
+		return "", fmt.Errorf("parameter path %q does not lead to a searchable resource type", expr.Param)
+	}
+
+	leaf := *expr
+	leaf.Param = chain.TargetParam
+	sub := q.subquery(table)
+	clause, err := sub.filterParamClause(&leaf, targetConfigs, depth+1)
+	if err != nil {
+		return "", err
+	}
+	column, target := ref.Column, "id"
+	if isTextReferenceColumn(ref.Column) {
+		column, target = referencedFHIRID(ref.Column), "fhir_id"
+	}
+	q.args = append(q.args, sub.args...)
+	q.idx = sub.idx
+	return fmt.Sprintf("%s IN (SELECT %s FROM %s WHERE %s)", column, target, table, clause), nil
+}
+
+// FilterMiddleware rejects searches whose _filter expression cannot be
+// answered with 400 Bad Request and an OperationOutcome naming the problem,
+// since the repositories can only match nothing for them.
+func FilterMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			filter := c.QueryParam("_filter")
+			if filter == "" {
+				return next(c)
+			}
+			resourceType := searchedResourceType(c)
+			if resourceType == "" {
+				return next(c)
+			}
+			if err := ValidateFilter(c.Request().Context(), resourceType, filter); errors.Is(err, ErrFilterNotSupported) {
+				return c.JSON(http.StatusBadRequest, NewOperationOutcome(
+					IssueSeverityError, IssueTypeNotSupported, err.Error(),
+				))
+			} else if err != nil {
+				return c.JSON(http.StatusBadRequest, NewOperationOutcome(
+					IssueSeverityError, IssueTypeInvalid,
+					fmt.Sprintf("invalid _filter: %s", err.Error()),
+				))
+			}
+			return next(c)
+		}
+	}
+}
+
+// searchedResourceType returns the resource type searched by the route: a
+// type-level search (see searchResourceType) or a compartment search. It
+// returns "" for other routes.
+func searchedResourceType(c echo.Context) string {
+	if rt := c.Param("resourceType"); rt != "" {
+		return rt
+	}
+	return searchResourceType(c)
+}
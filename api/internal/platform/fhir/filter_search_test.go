@@ -0,0 +1,143 @@
+package fhir
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+var filterTestConfigs = map[string]SearchParamConfig{
+	"patient": {Type: SearchParamReference, Column: "patient_id"},
+	"code":    {Type: SearchParamToken, Column: "code_value", SysColumn: "code_system"},
+	"status":  {Type: SearchParamToken, Column: "status"},
+	"date":    {Type: SearchParamDate, Column: "effective_datetime"},
+	"value":   {Type: SearchParamNumber, Column: "value_quantity"},
+	"note":    {Type: SearchParamString, Column: "note"},
+}
+
+func filterQuery(params map[string]string) *SearchQuery {
+	q := NewSearchQuery("observation", "id")
+	q.ApplyParams(chainTestContext(), params, filterTestConfigs)
+	return q
+}
+
+func TestSearchQuery_FilterOrAcrossParams(t *testing.T) {
+	q := filterQuery(map[string]string{"_filter": `status eq final or code eq http://loinc.org|1234-5`})
+	want := " AND (status = $1 OR (code_system = $2 AND code_value = $3))"
+	if !strings.HasSuffix(q.CountSQL(), want) {
+		t.Errorf("expected %q in %s", want, q.CountSQL())
+	}
+	args := q.CountArgs()
+	if len(args) != 3 || args[0] != "final" || args[1] != "http://loinc.org" || args[2] != "1234-5" {
+		t.Errorf("unexpected args: %v", args)
+	}
+	if q.Idx() != 4 {
+		t.Errorf("Idx() = %d, want 4", q.Idx())
+	}
+}
+
+func TestSearchQuery_FilterOperators(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{`note co "chest pain"`, "note ILIKE $1"},
+		{`note sw chest`, "note ILIKE $1"},
+		{`note pr false`, "note IS NULL"},
+		{`status ne final`, "status != $1"},
+		{`status in final,amended`, "status IN ($1, $2)"},
+		{`value gt 5.4`, "value_quantity > $1"},
+		{`date ge 2024-01-01`, "effective_datetime >= $1"},
+		{`not (status eq final)`, "NOT (status = $1)"},
+		{`patient ne 550e8400-e29b-41d4-a716-446655440000`, "NOT (patient_id = $1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			q := filterQuery(map[string]string{"_filter": tt.filter})
+			if !strings.Contains(q.CountSQL(), tt.want) {
+				t.Errorf("expected %q in %s", tt.want, q.CountSQL())
+			}
+		})
+	}
+}
+
+func TestSearchQuery_FilterNestedPath(t *testing.T) {
+	q := filterQuery(map[string]string{"_filter": `patient.family eq Chalmers or patient.general-practitioner.family sw Ad`})
+	want := "(patient_id IN (SELECT id FROM patient WHERE last_name = $1) OR " +
+		"patient_id IN (SELECT id FROM patient WHERE practitioner_id IN (SELECT id FROM practitioner WHERE last_name ILIKE $2)))"
+	if !strings.Contains(q.CountSQL(), want) {
+		t.Errorf("expected %q in %s", want, q.CountSQL())
+	}
+}
+
+func TestSearchQuery_FilterCombinesWithParams(t *testing.T) {
+	q := filterQuery(map[string]string{"_filter": `note co pain`, "status": "final"})
+	sql := q.CountSQL()
+	if !strings.Contains(sql, "note ILIKE $") || !strings.Contains(sql, "status = $") {
+		t.Errorf("expected filter and status clauses in %s", sql)
+	}
+	if len(q.CountArgs()) != 2 {
+		t.Errorf("unexpected args: %v", q.CountArgs())
+	}
+}
+
+func TestSearchQuery_FilterInvalidMatchesNothing(t *testing.T) {
+	for _, filter := range []string{`unknown eq 1`, `status gt final`, `status eq`, `patient.unknown eq x`} {
+		q := filterQuery(map[string]string{"_filter": filter})
+		if !strings.HasSuffix(q.CountSQL(), " AND 1=0") || len(q.CountArgs()) != 0 {
+			t.Errorf("%s: expected no match, got %s %v", filter, q.CountSQL(), q.CountArgs())
+		}
+	}
+}
+
+func TestValidateFilter(t *testing.T) {
+	ctx := chainTestContext()
+	if err := ValidateFilter(ctx, "Observation", `code eq 1234-5 or patient.family sw Ch`); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateFilter(ctx, "Observation", `status eq final`); err == nil || !strings.Contains(err.Error(), `"status"`) {
+		t.Errorf("expected unknown parameter error, got %v", err)
+	}
+	if err := ValidateFilter(ctx, "Observation", `code eq (`); err == nil {
+		t.Error("expected syntax error")
+	}
+	// Types without a registered table cannot answer _filter at all.
+	if err := ValidateFilter(ctx, "Basic", `anything eq 1`); !errors.Is(err, ErrFilterNotSupported) {
+		t.Errorf("expected ErrFilterNotSupported, got %v", err)
+	}
+}
+
+func TestFilterMiddleware(t *testing.T) {
+	e := echo.New()
+	g := e.Group("/fhir", SearchServicesMiddleware(&SearchServices{Chains: chainTestTables()}), FilterMiddleware())
+	g.GET("/Observation", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	g.GET("/Basic", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		path  string
+		query string
+		want  int
+	}{
+		{"/fhir/Observation", "", http.StatusOK},
+		{"/fhir/Observation", "?_filter=" + strings.ReplaceAll("code eq 1234-5", " ", "+"), http.StatusOK},
+		{"/fhir/Observation", "?_filter=" + strings.ReplaceAll("bogus eq 1", " ", "+"), http.StatusBadRequest},
+		{"/fhir/Basic", "", http.StatusOK},
+		{"/fhir/Basic", "?_filter=" + strings.ReplaceAll("code eq 1", " ", "+"), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path+tt.query, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%q: expected %d, got %d (%s)", tt.query, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -3,6 +3,7 @@ package fhir
 import (
 	"fmt"
 	"strings"
+	"unicode"
 )
 
 // ---------------------------------------------------------------------------
@@ -570,66 +571,168 @@ func DefaultFullTextConfigs() map[string]*FullTextConfig {
 // ---------------------------------------------------------------------------
 
 // ApplyFullTextSearch adds full-text search to an existing SearchQuery.
-// paramName must be "_text" or "_content".
+// paramName must be "_text" or "_content". The query is matched against the
+// resource_text index maintained by the VersionTracker (see SetTextIndex):
+// _text searches the narrative and _content the whole resource. The table
+// must have a fhir_id column and map to a FHIR resource type.
 func (e *FullTextSearchEngine) ApplyFullTextSearch(query *SearchQuery, paramName string, paramValue string) error {
 	if paramName != "_text" && paramName != "_content" {
 		return fmt.Errorf("unsupported full-text search parameter: %s", paramName)
 	}
 
-	var ftQuery *FullTextQuery
-	var err error
-
-	switch paramName {
-	case "_text":
-		ftQuery, err = ParseTextSearchParam(paramValue)
-	case "_content":
-		ftQuery, err = ParseContentSearchParam(paramValue)
+	resourceType, config := e.configForTable(query.table)
+	if resourceType == "" {
+		return fmt.Errorf("full-text search is not supported on %s", query.table)
+	}
+	language := "english"
+	if config != nil && config.Language != "" {
+		language = config.Language
 	}
+
+	ftQuery, err := ParseFullTextQuery(paramValue, language)
 	if err != nil {
 		return fmt.Errorf("invalid full-text query: %w", err)
 	}
+	text := ftQuery.TSQueryText()
+	if text == "" {
+		return fmt.Errorf("invalid full-text query: full-text search query must contain at least one word")
+	}
+
+	column := "content_tsv"
+	if paramName == "_text" {
+		column = "narrative_tsv"
+	}
+	idx := query.idx
+	tsQuery := fmt.Sprintf("to_tsquery($%d::regconfig, $%d)", idx+1, idx+2)
+	query.Add(fmt.Sprintf(
+		"fhir_id IN (SELECT resource_id FROM resource_text WHERE resource_type = $%d AND %s @@ %s)",
+		idx, column, tsQuery), resourceType, ftQuery.Language, text)
+
+	// The rank is only used for ordering when the request sorts by _score.
+	if ftQuery.UseRanking {
+		query.rank = fmt.Sprintf(
+			"(SELECT ts_rank(%s, %s) FROM resource_text WHERE resource_type = $%d AND resource_id = %s.fhir_id)",
+			column, tsQuery, idx, query.table)
+	}
 
-	// Find the appropriate config. For ApplyFullTextSearch, we attempt to
-	// determine the resource type from the table name. If no specific config
-	// is found, we build a generic one using text_div.
-	var config *FullTextConfig
+	return nil
+}
+
+// configForTable resolves the resource type stored in table and its config.
+func (e *FullTextSearchEngine) configForTable(table string) (string, *FullTextConfig) {
 	for _, cfg := range e.Configs {
-		tableName := strings.ToLower(cfg.ResourceType) + "s"
-		if tableName == query.table {
-			config = cfg
-			break
+		if strings.ToLower(cfg.ResourceType)+"s" == table {
+			return cfg.ResourceType, cfg
 		}
 	}
+	resourceType := ResourceTypeForTable(table)
+	return resourceType, e.Configs[resourceType]
+}
 
-	if config == nil {
-		// Fallback: use a generic config with text_div
-		config = &FullTextConfig{
-			TextColumns: []string{"text_div"},
-			Language:    "english",
+// tableResourceTypes lists the tables whose names do not follow the
+// snake_case resource type convention.
+var tableResourceTypes = map[string]string{
+	"fhir_group":       "Group",
+	"fhir_list":        "List",
+	"procedure_record": "Procedure",
+}
+
+// ResourceTypeForTable returns the FHIR resource type stored in a domain
+// table, such as "MedicationRequest" for medication_request, or "" if the
+// table does not hold a FHIR resource type.
+func ResourceTypeForTable(table string) string {
+	if rt, ok := tableResourceTypes[table]; ok {
+		return rt
+	}
+	name := strings.ReplaceAll(table, "_", "")
+	for rt := range FHIRResourceTypes {
+		if strings.EqualFold(rt, name) {
+			return rt
 		}
 	}
+	return ""
+}
 
-	// For _text, restrict to narrative columns (text_div)
-	if paramName == "_text" {
-		config = &FullTextConfig{
-			TextColumns: []string{"text_div"},
-			WeightMap:   config.WeightMap,
-			Language:    config.Language,
+// TSQueryText translates the raw query into the text search query syntax
+// accepted by to_tsquery, so that the query can be bound as a parameter and
+// normalized with the query's language configuration. Words are ANDed; quoted
+// phrases become <-> sequences, a trailing * a prefix match, a leading - a
+// negation and a|b an alternative. It returns "" if the query has no words.
+func (q *FullTextQuery) TSQueryText() string {
+	var parts []string
+	for _, term := range SplitSearchTerms(q.RawQuery) {
+		term = strings.TrimPrefix(term, "+")
+		negate := false
+		if strings.HasPrefix(term, "-") {
+			negate = true
+			term = term[1:]
+		}
+
+		var part string
+		switch {
+		case strings.ContainsAny(term, " \t"):
+			part = tsQueryJoin(strings.Fields(term), " <-> ")
+		case strings.Contains(term, "|"):
+			part = tsQueryJoin(strings.Split(term, "|"), " | ")
+			if strings.Contains(part, " | ") {
+				part = "(" + part + ")"
+			}
+		default:
+			part = tsQueryLexeme(term)
+		}
+		if part == "" {
+			continue
+		}
+		if negate {
+			part = "!" + part
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " & ")
+}
+
+// tsQueryJoin joins the lexemes of words with op, skipping empty ones.
+func tsQueryJoin(words []string, op string) string {
+	var lexemes []string
+	for _, w := range words {
+		if l := tsQueryLexeme(w); l != "" {
+			lexemes = append(lexemes, l)
 		}
 	}
+	return strings.Join(lexemes, op)
+}
 
-	clause, args := FullTextSearchClause(config, ftQuery, query.idx)
-	query.where += " AND " + clause
-	query.args = append(query.args, args...)
-	query.idx += len(args)
+// tsQueryLexeme quotes word as a tsquery lexeme, with a :* prefix marker for
+// words ending in *. Words without letters or digits yield "".
+func tsQueryLexeme(word string) string {
+	prefix := strings.HasSuffix(word, "*")
+	word = strings.Trim(word, "*")
+	if !strings.ContainsFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+		return ""
+	}
+	lexeme := "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(word) + "'"
+	if prefix {
+		lexeme += ":*"
+	}
+	return lexeme
+}
 
-	// If ranking is requested, set the order by
-	if ftQuery.UseRanking {
-		rankClause := FullTextRankClause(config, ftQuery)
-		if rankClause != "" {
-			query.orderBy = rankClause
+// sortsByScore reports whether a _sort value requests relevance ordering.
+// Results are always ranked best match first, whatever the sort direction.
+func sortsByScore(sortParam string) bool {
+	for _, field := range strings.Split(sortParam, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(field), "-") == "_score" {
+			return true
 		}
 	}
+	return false
+}
 
-	return nil
+// FullTextSearchParams returns the CapabilityStatement entries for the _text
+// and _content search parameters.
+func FullTextSearchParams() []SearchParam {
+	return []SearchParam{
+		{Name: "_text", Type: "string", Documentation: "Full-text search of the resource narrative. Supports phrases, prefix* and -exclusions; use _sort=_score to rank by relevance."},
+		{Name: "_content", Type: "string", Documentation: "Full-text search of the entire resource, including notes and text attachments. Supports phrases, prefix* and -exclusions; use _sort=_score to rank by relevance."},
+	}
 }
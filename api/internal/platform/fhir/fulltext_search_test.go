@@ -1,8 +1,13 @@
 package fhir
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/labstack/echo/v4"
 )
 
 // ---------------------------------------------------------------------------
@@ -1312,3 +1317,142 @@ func TestFullTextSearchEngine_DefaultConfigs(t *testing.T) {
 		t.Errorf("engine should have at least 8 default configs, got %d", len(engine.Configs))
 	}
 }
+
+// ---------------------------------------------------------------------------
+// resource_text integration tests
+// ---------------------------------------------------------------------------
+
+func TestFullTextQuery_TSQueryText(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"pneumonia", "'pneumonia'"},
+		{"lobar pneumonia", "'lobar' & 'pneumonia'"},
+		{`"type 2 diabetes"`, "'type' <-> '2' <-> 'diabetes'"},
+		{"diab*", "'diab':*"},
+		{"+diabetes -juvenile", "'diabetes' & !'juvenile'"},
+		{"flu|influenza", "('flu' | 'influenza')"},
+		{`o'brien back\slash`, `'o''brien' & 'back\\slash'`},
+		{"pneumonia & !", "'pneumonia'"},
+	}
+	for _, tt := range tests {
+		q, err := ParseFullTextQuery(tt.raw, "english")
+		if err != nil {
+			t.Fatalf("ParseFullTextQuery(%q): %v", tt.raw, err)
+		}
+		if got := q.TSQueryText(); got != tt.want {
+			t.Errorf("TSQueryText(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestResourceTypeForTable(t *testing.T) {
+	tests := map[string]string{
+		"medication_request": "MedicationRequest",
+		"document_reference": "DocumentReference",
+		"fhir_group":         "Group",
+		"procedure_record":   "Procedure",
+		"ed_tracking":        "",
+	}
+	for table, want := range tests {
+		if got := ResourceTypeForTable(table); got != want {
+			t.Errorf("ResourceTypeForTable(%q) = %q, want %q", table, got, want)
+		}
+	}
+}
+
+func TestApplyFullTextSearch_ResourceTextIndex(t *testing.T) {
+	q := NewSearchQuery("condition", "id")
+	q.Add("status = $1", "active")
+	if err := NewFullTextSearchEngine().ApplyFullTextSearch(q, "_text", "pneumonia"); err != nil {
+		t.Fatalf("ApplyFullTextSearch error: %v", err)
+	}
+
+	want := "fhir_id IN (SELECT resource_id FROM resource_text WHERE resource_type = $2 AND narrative_tsv @@ to_tsquery($3::regconfig, $4))"
+	if sql := q.CountSQL(); !strings.Contains(sql, want) {
+		t.Errorf("expected %q in SQL, got: %s", want, sql)
+	}
+	args := q.CountArgs()
+	if len(args) != 4 || args[1] != "Condition" || args[2] != "english" || args[3] != "'pneumonia'" {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestSearchQuery_ApplyParamsFullText(t *testing.T) {
+	ctx := WithSearchServices(context.Background(), &SearchServices{FullText: NewFullTextSearchEngine()})
+
+	q := NewSearchQuery("document_reference", "id")
+	q.ApplyParams(WithRelevanceSort(ctx, true), map[string]string{"_content": "pneumonia"}, nil)
+	q.OrderBy("created_at DESC")
+
+	sql := q.DataSQL(10, 0)
+	if !strings.Contains(sql, "content_tsv @@ to_tsquery($2::regconfig, $3)") {
+		t.Errorf("expected _content clause, got: %s", sql)
+	}
+	if !strings.Contains(sql, "ORDER BY (SELECT ts_rank(content_tsv, to_tsquery($2::regconfig, $3)) FROM resource_text WHERE resource_type = $1 AND resource_id = document_reference.fhir_id) DESC, created_at DESC") {
+		t.Errorf("expected relevance ordering, got: %s", sql)
+	}
+	if !strings.Contains(sql, "LIMIT $4 OFFSET $5") {
+		t.Errorf("expected limit after full-text args, got: %s", sql)
+	}
+}
+
+func TestSearchQuery_ApplyParamsFullTextWithoutRanking(t *testing.T) {
+	ctx := WithSearchServices(context.Background(), &SearchServices{FullText: NewFullTextSearchEngine()})
+
+	q := NewSearchQuery("observation", "id")
+	q.ApplyParams(ctx, map[string]string{"_text": "pneumonia"}, nil)
+	q.OrderBy("created_at DESC")
+	if sql := q.DataSQL(10, 0); strings.Contains(sql, "ts_rank") {
+		t.Errorf("expected no relevance ordering without _sort=_score, got: %s", sql)
+	}
+}
+
+func TestSearchQuery_ApplyParamsFullTextUnsupported(t *testing.T) {
+	ctx := WithSearchServices(context.Background(), &SearchServices{FullText: NewFullTextSearchEngine()})
+
+	q := NewSearchQuery("ed_tracking", "id")
+	q.ApplyParams(ctx, map[string]string{"_text": "pneumonia"}, nil)
+	if sql := q.CountSQL(); !strings.Contains(sql, "1=0") {
+		t.Errorf("expected no matches for a table without a resource type, got: %s", sql)
+	}
+
+	q = NewSearchQuery("observation", "id")
+	q.ApplyParams(ctx, map[string]string{"_content": "!!"}, nil)
+	if sql := q.CountSQL(); !strings.Contains(sql, "1=0") {
+		t.Errorf("expected no matches for a query without words, got: %s", sql)
+	}
+}
+
+func TestSearchServicesMiddleware(t *testing.T) {
+	services := &SearchServices{FullText: NewFullTextSearchEngine()}
+	e := echo.New()
+	var got *SearchServices
+	var byScore bool
+	e.GET("/fhir/Observation", func(c echo.Context) error {
+		got = SearchServicesFromContext(c.Request().Context())
+		byScore = relevanceSortFromContext(c.Request().Context())
+		return nil
+	}, SearchServicesMiddleware(services))
+
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Observation?_text=pneumonia&_sort=-_score", nil)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	if got != services || !byScore {
+		t.Errorf("services = %p (want %p), byScore = %v", got, services, byScore)
+	}
+
+	// _sort is not passed to the repositories.
+	c := e.NewContext(req, httptest.NewRecorder())
+	if params := ExtractSearchParams(c); params["_sort"] != "" || params["_text"] != "pneumonia" {
+		t.Errorf("params = %v", params)
+	}
+}
+
+func TestSearchQuery_ApplyParamsFullTextDisabled(t *testing.T) {
+	q := NewSearchQuery("observation", "id")
+	q.ApplyParams(context.Background(), map[string]string{"_text": "pneumonia"}, nil)
+	if sql := q.CountSQL(); strings.Contains(sql, "resource_text") {
+		t.Errorf("expected _text to be ignored without an engine, got: %s", sql)
+	}
+}
@@ -240,24 +240,19 @@ func (t *graphTraverser) traverse(ctx context.Context, resource map[string]inter
 		refs := extractRefsFromPath(resource, link.Path)
 		for _, target := range link.Target {
 			for _, ref := range refs {
-				refType, refID := parseReference(ref)
-				if refType == "" || refID == "" {
-					continue
-				}
-				// Only follow if the reference matches the declared target type.
-				if target.Type != "" && refType != target.Type {
-					continue
+				// Only follow if the reference matches the declared target
+				// type; canonical references name no type.
+				if target.Type != "" {
+					if refType, _, _, ok := parseLiteralReference(ref); ok && refType != target.Type {
+						continue
+					}
 				}
 
-				t.registry.mu.RLock()
-				fetcher, ok := t.registry.fetchers[refType]
-				t.registry.mu.RUnlock()
-				if !ok {
+				fetched, err := t.registry.resolveReference(ctx, ref)
+				if err != nil || fetched == nil {
 					continue
 				}
-
-				fetched, err := fetcher(ctx, refID)
-				if err != nil || fetched == nil {
+				if target.Type != "" && fetched["resourceType"] != target.Type {
 					continue
 				}
 
@@ -409,10 +404,8 @@ func GraphApplyHandler(registry *IncludeRegistry) echo.HandlerFunc {
 		}
 
 		// Fetch the starting resource.
-		registry.mu.RLock()
-		fetcher, ok := registry.fetchers[startType]
-		registry.mu.RUnlock()
-		if !ok {
+		fetcher := registry.fetcher(startType)
+		if fetcher == nil {
 			return c.JSON(http.StatusNotFound,
 				operationOutcome("error", "not-found",
 					fmt.Sprintf("No fetcher registered for resource type '%s'", startType)))
@@ -0,0 +1,896 @@
+package fhir
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ehr/ehr/internal/platform/auth"
+)
+
+// graphqlDefaultCount is the number of resources returned by List and
+// Connection fields without a _count argument.
+const graphqlDefaultCount = 100
+
+// errGraphQLTooCostly marks an operation rejected by the limits of the
+// engine (see GraphQLLimits).
+var errGraphQLTooCostly = errors.New("too costly")
+
+// gqlExecution holds the state of executing one GraphQL operation.
+type gqlExecution struct {
+	engine    *GraphQLEngine
+	ctx       context.Context
+	doc       *gqlDocument
+	variables map[string]interface{}
+	errors    []GraphQLError
+	// container is the innermost resource being selected, against which
+	// contained ("#id") references are resolved.
+	container map[string]interface{}
+	limits    GraphQLLimits
+	// resolutions counts the resolver calls made so far; once it passes
+	// limits.MaxResolutions, tooCostly is set and nothing more is resolved.
+	resolutions int
+	tooCostly   bool
+}
+
+// newGraphQLExecution parses req and prepares the execution of the
+// operation it selects. Variables are taken from req; declared variables
+// that are absent take their default value. Variables that are used without
+// being declared are also looked up in req.Variables.
+func newGraphQLExecution(ctx context.Context, e *GraphQLEngine, req GraphQLRequest) (*gqlExecution, *gqlOperation, error) {
+	doc, err := parseGraphQLDocument(req.Query)
+	if err != nil {
+		return nil, nil, err
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if op.Kind == "subscription" {
+		return nil, nil, fmt.Errorf("subscriptions are not supported")
+	}
+	vars := make(map[string]interface{}, len(req.Variables))
+	for k, v := range req.Variables {
+		vars[k] = v
+	}
+	for _, def := range op.Variables {
+		if v, ok := vars[def.Name]; ok && v != nil {
+			continue
+		}
+		switch {
+		case def.HasDefault:
+			vars[def.Name] = def.Default
+		case strings.HasSuffix(def.Type, "!"):
+			return nil, nil, fmt.Errorf("variable $%s of type %s is required", def.Name, def.Type)
+		}
+	}
+	x := &gqlExecution{engine: e, ctx: ctx, doc: doc, variables: vars, limits: e.limitsOrDefault()}
+	if depth := x.selectionDepth(op.Selections, make(map[string]bool)); depth > x.limits.MaxDepth {
+		return nil, nil, fmt.Errorf("%w: the operation nests %d levels of fields, more than the limit of %d",
+			errGraphQLTooCostly, depth, x.limits.MaxDepth)
+	}
+	return x, op, nil
+}
+
+// selectionDepth returns the number of nested field levels of sels.
+// Fragments add the depth of their selections at the level they are
+// spread; a fragment spread inside itself is not followed again.
+func (x *gqlExecution) selectionDepth(sels []*gqlSelection, spreading map[string]bool) int {
+	depth := 0
+	for _, sel := range sels {
+		d := 0
+		switch {
+		case sel.Spread != "":
+			frag, ok := x.doc.Fragments[sel.Spread]
+			if !ok || spreading[sel.Spread] {
+				continue
+			}
+			spreading[sel.Spread] = true
+			d = x.selectionDepth(frag.Selections, spreading)
+			delete(spreading, sel.Spread)
+		case sel.Inline:
+			d = x.selectionDepth(sel.Selections, spreading)
+		default:
+			d = 1 + x.selectionDepth(sel.Selections, spreading)
+		}
+		depth = max(depth, d)
+	}
+	return depth
+}
+
+// spend accounts for one resolver call. Once the budget of the request is
+// spent it records a too-costly error and returns false, and the caller
+// resolves nothing.
+func (x *gqlExecution) spend(path []string) bool {
+	if x.tooCostly {
+		return false
+	}
+	if x.resolutions++; x.resolutions > x.limits.MaxResolutions {
+		x.tooCostly = true
+		x.fail(path, "the operation needs more than %d resource reads or searches", x.limits.MaxResolutions)
+		return false
+	}
+	return true
+}
+
+// fail records an error for the field at path.
+func (x *gqlExecution) fail(path []string, format string, args ...interface{}) {
+	x.errors = append(x.errors, GraphQLError{Message: fmt.Sprintf(format, args...), Path: path})
+}
+
+// response returns the result of the execution.
+func (x *gqlExecution) response(data interface{}) *GraphQLResponse {
+	if x.tooCostly {
+		return &GraphQLResponse{Errors: x.errors, tooCostly: true}
+	}
+	return &GraphQLResponse{Data: data, Errors: x.errors}
+}
+
+// executeOperation resolves the root fields of a query or mutation.
+// Mutation fields are executed one after the other, in document order.
+func (x *gqlExecution) executeOperation(op *gqlOperation) map[string]interface{} {
+	rootType := "Query"
+	if op.Kind == "mutation" {
+		rootType = "Mutation"
+	}
+	data := make(map[string]interface{})
+	for _, f := range x.collectFields(op.Selections, rootType) {
+		key := f.responseKey()
+		path := []string{key}
+		switch {
+		case f.Name == "__typename":
+			data[key] = rootType
+		case op.Kind == "query" && f.Name == "__schema":
+			data[key] = x.selectValue(x.engine.schema(x.ctx).schemaObject(), f, path)
+		case op.Kind == "query" && f.Name == "__type":
+			name, _ := x.argument(f, "name")
+			if t, ok := x.engine.schema(x.ctx).types[graphqlArgString(name)]; ok {
+				data[key] = x.selectValue(t, f, path)
+			} else {
+				data[key] = nil
+			}
+		case op.Kind == "mutation":
+			data[key] = x.mutate(f, path)
+		default:
+			data[key] = x.query(f, path)
+		}
+	}
+	return data
+}
+
+// query resolves a root query field: Xxx(id: ...) reads a resource, and
+// XxxList(...) and XxxConnection(...) search resources of type Xxx.
+func (x *gqlExecution) query(f *gqlSelection, path []string) interface{} {
+	rt, list, connection := graphqlFieldTarget(f.Name)
+	resolver, ok := x.readResolver(rt, path)
+	if !ok {
+		return nil
+	}
+	params := x.searchParams(f)
+	switch {
+	case connection:
+		return x.connection(resolver, rt, params, f, path)
+	case list:
+		return x.list(resolver, rt, params, f, path)
+	}
+	id := params["id"]
+	if id == "" {
+		x.fail(path, "%s requires an id argument", f.Name)
+		return nil
+	}
+	res, ok := x.read(resolver, rt, id, path)
+	if !ok {
+		return nil
+	}
+	return x.selectObject(res, f.Selections, rt, path)
+}
+
+// readResolver returns the resolver of resourceType after checking that
+// the request may read it.
+func (x *gqlExecution) readResolver(resourceType string, path []string) (GraphQLResourceResolver, bool) {
+	resolver, ok := x.engine.resolver(resourceType)
+	if !ok {
+		x.fail(path, "no resolver registered for resource type %s", resourceType)
+		return nil, false
+	}
+	if err := authorizeGraphQL(x.ctx, resourceType, "read"); err != nil {
+		x.fail(path, "%v", err)
+		return nil, false
+	}
+	return resolver, true
+}
+
+// read resolves resourceType/id.
+func (x *gqlExecution) read(resolver GraphQLResourceResolver, resourceType, id string, path []string) (map[string]interface{}, bool) {
+	if !x.spend(path) {
+		return nil, false
+	}
+	res, err := resolver.ResolveByID(x.ctx, resourceType, id)
+	if err != nil {
+		if errors.Is(err, ErrReferenceNotFound) {
+			x.fail(path, "resource %s/%s not found: %v", resourceType, id, err)
+		} else {
+			x.fail(path, "reading %s/%s failed: %v", resourceType, id, err)
+		}
+		return nil, false
+	}
+	return res, true
+}
+
+// list returns the resources matching params, limited by _count.
+func (x *gqlExecution) list(resolver GraphQLResourceResolver, resourceType string, params map[string]string, f *gqlSelection, path []string) interface{} {
+	count, err := x.count(params)
+	if err != nil {
+		x.fail(path, "%v", err)
+		return nil
+	}
+	if !x.spend(path) {
+		return nil
+	}
+	results, err := resolver.ResolveSearch(x.ctx, resourceType, params, count)
+	if err != nil {
+		x.fail(path, "search for %s failed: %v", resourceType, err)
+		return nil
+	}
+	items := make([]interface{}, 0, len(results))
+	for i, r := range results {
+		items = append(items, x.selectObject(r, f.Selections, resourceType, childPath(path, strconv.Itoa(i))))
+	}
+	return items
+}
+
+// connection returns a page of the resources matching params as an
+// XxxConnection: the page's edges, its size and offset, the total count
+// when the resolver reports it (see GraphQLPageResolver), and the cursors of
+// the first, previous, next and last pages, which are passed back as
+// _cursor.
+func (x *gqlExecution) connection(resolver GraphQLResourceResolver, resourceType string, params map[string]string, f *gqlSelection, path []string) interface{} {
+	count, err := x.count(params)
+	if err != nil {
+		x.fail(path, "%v", err)
+		return nil
+	}
+	offset := 0
+	if v, ok := params["_offset"]; ok {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			x.fail(path, "invalid _offset %q", v)
+			return nil
+		}
+	}
+	if cursor, ok := params["_cursor"]; ok {
+		delete(params, "_cursor")
+		if offset, err = decodeGraphQLCursor(cursor); err != nil {
+			x.fail(path, "%v", err)
+			return nil
+		}
+	}
+	delete(params, "_offset")
+	if offset > 0 {
+		params["_offset"] = strconv.Itoa(offset)
+	}
+	if !x.spend(path) {
+		return nil
+	}
+
+	var results []map[string]interface{}
+	total := -1
+	hasNext := false
+	if pager, ok := resolver.(GraphQLPageResolver); ok {
+		results, total, err = pager.ResolvePage(x.ctx, resourceType, params, count)
+		if total >= 0 {
+			hasNext = offset+len(results) < total
+		} else {
+			hasNext = count > 0 && len(results) == count
+		}
+	} else {
+		results, err = resolver.ResolveSearch(x.ctx, resourceType, params, count+1)
+		if len(results) > count {
+			results, hasNext = results[:count], true
+		}
+	}
+	if err != nil {
+		x.fail(path, "search for %s failed: %v", resourceType, err)
+		return nil
+	}
+
+	conn := map[string]interface{}{
+		"count":    nil,
+		"offset":   offset,
+		"pagesize": count,
+		"first":    encodeGraphQLCursor(0),
+		"previous": nil,
+		"next":     nil,
+		"last":     nil,
+	}
+	if total >= 0 {
+		conn["count"] = total
+		if count > 0 && total > 0 {
+			conn["last"] = encodeGraphQLCursor((total - 1) / count * count)
+		} else {
+			conn["last"] = encodeGraphQLCursor(0)
+		}
+	}
+	if offset > 0 {
+		conn["previous"] = encodeGraphQLCursor(max(offset-count, 0))
+	}
+	if hasNext {
+		conn["next"] = encodeGraphQLCursor(offset + count)
+	}
+	edges := make([]interface{}, 0, len(results))
+	for _, r := range results {
+		edges = append(edges, map[string]interface{}{"mode": "match", "score": nil, "resource": r})
+	}
+	conn["edges"] = edges
+	return x.selectObject(conn, f.Selections, resourceType+"Connection", path)
+}
+
+// mutate resolves a root mutation field: XxxCreate(res: ...),
+// XxxUpdate(id: ..., res: ...) or XxxDelete(id: ...). The resolver of Xxx
+// must implement GraphQLMutationResolver and the request must be allowed to
+// write Xxx. The field returns the created, updated or deleted resource.
+func (x *gqlExecution) mutate(f *gqlSelection, path []string) interface{} {
+	rt, action := graphqlMutationTarget(f.Name)
+	if action == "" {
+		x.fail(path, "unknown mutation %s", f.Name)
+		return nil
+	}
+	resolver, ok := x.engine.resolver(rt)
+	if !ok {
+		x.fail(path, "no resolver registered for resource type %s", rt)
+		return nil
+	}
+	mutator, ok := resolver.(GraphQLMutationResolver)
+	if !ok {
+		x.fail(path, "resource type %s does not support mutations", rt)
+		return nil
+	}
+	if err := authorizeGraphQL(x.ctx, rt, "write"); err != nil {
+		x.fail(path, "%v", err)
+		return nil
+	}
+
+	var id string
+	if action != "Create" {
+		v, _ := x.argument(f, "id")
+		if id = graphqlArgString(v); id == "" {
+			x.fail(path, "%s requires an id argument", f.Name)
+			return nil
+		}
+	}
+	if !x.spend(path) {
+		return nil
+	}
+	var result map[string]interface{}
+	var err error
+	switch action {
+	case "Delete":
+		result, err = mutator.Delete(x.ctx, rt, id)
+	default:
+		var res map[string]interface{}
+		if res, err = x.resourceArgument(f, rt, id); err != nil {
+			x.fail(path, "%v", err)
+			return nil
+		}
+		if action == "Create" {
+			result, err = mutator.Create(x.ctx, rt, res)
+		} else {
+			result, err = mutator.Update(x.ctx, rt, id, res)
+		}
+	}
+	if err != nil {
+		x.fail(path, "%s failed: %v", f.Name, err)
+		return nil
+	}
+	if result == nil {
+		return nil
+	}
+	return x.selectObject(result, f.Selections, rt, path)
+}
+
+// resourceArgument returns the resource passed as the res argument of a
+// create or update mutation, with its resourceType (and id for updates)
+// filled in.
+func (x *gqlExecution) resourceArgument(f *gqlSelection, resourceType, id string) (map[string]interface{}, error) {
+	v, _ := x.argument(f, "res")
+	in, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s requires a res argument holding the %s resource", f.Name, resourceType)
+	}
+	res := make(map[string]interface{}, len(in)+2)
+	for k, v := range in {
+		res[k] = v
+	}
+	if rt, ok := res["resourceType"]; ok && rt != resourceType {
+		return nil, fmt.Errorf("res has resourceType %v, expected %s", rt, resourceType)
+	}
+	res["resourceType"] = resourceType
+	if id != "" {
+		if rid, ok := res["id"]; ok && rid != id {
+			return nil, fmt.Errorf("res has id %v, expected %s", rid, id)
+		}
+		res["id"] = id
+	}
+	return res, nil
+}
+
+// selectObject applies a selection set to an object. Objects without a
+// selection set are returned whole. Elements absent from the object are
+// omitted from the result.
+func (x *gqlExecution) selectObject(obj map[string]interface{}, sels []*gqlSelection, typeName string, path []string) interface{} {
+	if len(sels) == 0 {
+		return obj
+	}
+	if rt, ok := obj["resourceType"].(string); ok && rt != "" {
+		typeName = rt
+		prev := x.container
+		x.container = obj
+		defer func() { x.container = prev }()
+	}
+	out := make(map[string]interface{})
+	for _, f := range x.collectFields(sels, typeName) {
+		key := f.responseKey()
+		if f.Name == "__typename" {
+			out[key] = typeName
+			continue
+		}
+		if value, ok := x.resolveField(obj, f, childPath(path, key)); ok {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// resolveField returns the value of field f of obj. Besides the elements
+// of obj, a Reference has a resource field that resolves the referenced
+// resource, and a resource has XxxList and XxxConnection fields that, given
+// the search parameter in _reference, search the resources of type Xxx that
+// refer to it.
+func (x *gqlExecution) resolveField(obj map[string]interface{}, f *gqlSelection, path []string) (interface{}, bool) {
+	if ref, ok := obj["reference"].(string); ok && f.Name == "resource" {
+		return x.referencedResource(ref, f, path), true
+	}
+	if _, ok := f.argument("_reference"); ok {
+		if rt, list, connection := graphqlFieldTarget(f.Name); list || connection {
+			return x.reverseReferences(obj, rt, connection, f, path), true
+		}
+	}
+	value, ok := obj[f.Name]
+	if !ok {
+		return nil, false
+	}
+	return x.selectValue(value, f, path), true
+}
+
+// selectValue applies the selection set of f to an element value. Lists are
+// filtered by the field's arguments (e.g. name(use: official) selects the
+// official names; _offset and _count page through the list) and @first
+// keeps only the first item.
+func (x *gqlExecution) selectValue(value interface{}, f *gqlSelection, path []string) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		items := x.filterList(v, f)
+		if hasDirective(f, "first") {
+			if len(items) == 0 {
+				return nil
+			}
+			return x.selectItem(items[0], f.Selections, path)
+		}
+		out := make([]interface{}, 0, len(items))
+		for i, item := range items {
+			out = append(out, x.selectItem(item, f.Selections, childPath(path, strconv.Itoa(i))))
+		}
+		return out
+	default:
+		return x.selectItem(value, f.Selections, path)
+	}
+}
+
+func (x *gqlExecution) selectItem(item interface{}, sels []*gqlSelection, path []string) interface{} {
+	if obj, ok := item.(map[string]interface{}); ok {
+		return x.selectObject(obj, sels, "", path)
+	}
+	return item
+}
+
+// filterList keeps the list items whose elements equal the field's
+// arguments, then applies _offset and _count. The includeDeprecated
+// argument of the introspection fields does not filter.
+func (x *gqlExecution) filterList(items []interface{}, f *gqlSelection) []interface{} {
+	offset, count := 0, -1
+	var filtered []interface{}
+	var filters []gqlArgument
+	for _, a := range f.Arguments {
+		v := x.value(a.Value)
+		switch a.Name {
+		case "_offset":
+			offset, _ = strconv.Atoi(graphqlArgString(v))
+		case "_count":
+			count, _ = strconv.Atoi(graphqlArgString(v))
+		case "includeDeprecated":
+		default:
+			filters = append(filters, gqlArgument{Name: a.Name, Value: v})
+		}
+	}
+	if len(filters) == 0 {
+		filtered = items
+	} else {
+		for _, item := range items {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			match := true
+			for _, flt := range filters {
+				if v, ok := obj[flt.Name]; !ok || graphqlArgString(v) != graphqlArgString(flt.Value) {
+					match = false
+					break
+				}
+			}
+			if match {
+				filtered = append(filtered, item)
+			}
+		}
+	}
+	if offset > 0 {
+		if offset >= len(filtered) {
+			return nil
+		}
+		filtered = filtered[offset:]
+	}
+	if count >= 0 && count < len(filtered) {
+		filtered = filtered[:count]
+	}
+	return filtered
+}
+
+// referencedResource resolves the resource field of a Reference. The type
+// argument restricts the reference to a resource type, returning null for
+// references to other types, and optional: true returns null rather than an
+// error for references that cannot be resolved.
+func (x *gqlExecution) referencedResource(ref string, f *gqlSelection, path []string) interface{} {
+	optional := false
+	if v, ok := x.argument(f, "optional"); ok {
+		optional = graphqlArgString(v) == "true"
+	}
+	want := ""
+	if v, ok := x.argument(f, "type"); ok {
+		want = graphqlArgString(v)
+	}
+
+	if strings.HasPrefix(ref, "#") {
+		res := containedResource(x.container, strings.TrimPrefix(ref, "#"))
+		if res == nil {
+			if !optional {
+				x.fail(path, "contained resource %s not found", ref)
+			}
+			return nil
+		}
+		rt, _ := res["resourceType"].(string)
+		if want != "" && rt != want {
+			return nil
+		}
+		return x.selectObject(res, f.Selections, rt, path)
+	}
+
+	rt, id, _, ok := parseLiteralReference(ref)
+	if !ok {
+		if !optional {
+			x.fail(path, "cannot resolve reference %q", ref)
+		}
+		return nil
+	}
+	if want != "" && rt != want {
+		return nil
+	}
+	resolver, ok := x.readResolver(rt, path)
+	if !ok || !x.spend(path) {
+		return nil
+	}
+	res, err := resolver.ResolveByID(x.ctx, rt, id)
+	if err != nil {
+		if !optional {
+			x.fail(path, "reference %s not resolved: %v", ref, err)
+		}
+		return nil
+	}
+	return x.selectObject(res, f.Selections, rt, path)
+}
+
+// containedResource returns the resource contained in container with id.
+func containedResource(container map[string]interface{}, id string) map[string]interface{} {
+	contained, _ := container["contained"].([]interface{})
+	for _, c := range contained {
+		if res, ok := c.(map[string]interface{}); ok && res["id"] == id {
+			return res
+		}
+	}
+	return nil
+}
+
+// reverseReferences searches the resources of type resourceType whose
+// _reference search parameter refers to obj, e.g. on a Patient,
+// ObservationList(_reference: subject).
+func (x *gqlExecution) reverseReferences(obj map[string]interface{}, resourceType string, connection bool, f *gqlSelection, path []string) interface{} {
+	ownerType, _ := obj["resourceType"].(string)
+	ownerID, _ := obj["id"].(string)
+	if ownerType == "" || ownerID == "" {
+		x.fail(path, "%s can only be selected on a resource", f.Name)
+		return nil
+	}
+	resolver, ok := x.readResolver(resourceType, path)
+	if !ok {
+		return nil
+	}
+	params := x.searchParams(f)
+	param := graphqlParamName(params["_reference"])
+	delete(params, "_reference")
+	if param == "" {
+		x.fail(path, "%s requires the search parameter in _reference", f.Name)
+		return nil
+	}
+	params[param] = ownerType + "/" + ownerID
+	if connection {
+		return x.connection(resolver, resourceType, params, f, path)
+	}
+	return x.list(resolver, resourceType, params, f, path)
+}
+
+// collectFields flattens a selection set for an object of typeName: it
+// expands the fragments whose type condition applies, drops selections
+// excluded by @skip or @include, and merges the fields requested more than
+// once under the same response key.
+func (x *gqlExecution) collectFields(sels []*gqlSelection, typeName string) []*gqlSelection {
+	var fields []*gqlSelection
+	x.collect(sels, typeName, &fields, make(map[string]int), make(map[string]bool))
+	return fields
+}
+
+func (x *gqlExecution) collect(sels []*gqlSelection, typeName string, fields *[]*gqlSelection, index map[string]int, visited map[string]bool) {
+	for _, sel := range sels {
+		if !x.included(sel.Directives) {
+			continue
+		}
+		switch {
+		case sel.Spread != "":
+			if visited[sel.Spread] {
+				continue
+			}
+			visited[sel.Spread] = true
+			frag, ok := x.doc.Fragments[sel.Spread]
+			if !ok {
+				x.fail(nil, "unknown fragment %q", sel.Spread)
+				continue
+			}
+			if typeConditionApplies(frag.TypeCondition, typeName) {
+				x.collect(frag.Selections, typeName, fields, index, visited)
+			}
+		case sel.Inline:
+			if typeConditionApplies(sel.TypeCondition, typeName) {
+				x.collect(sel.Selections, typeName, fields, index, visited)
+			}
+		default:
+			key := sel.responseKey()
+			if i, ok := index[key]; ok {
+				merged := *(*fields)[i]
+				merged.Selections = append(append([]*gqlSelection(nil), merged.Selections...), sel.Selections...)
+				(*fields)[i] = &merged
+				continue
+			}
+			index[key] = len(*fields)
+			*fields = append(*fields, sel)
+		}
+	}
+}
+
+// included evaluates the @skip and @include directives of a selection.
+func (x *gqlExecution) included(dirs []gqlDirective) bool {
+	for _, d := range dirs {
+		if d.Name != "skip" && d.Name != "include" {
+			continue
+		}
+		cond := false
+		for _, a := range d.Arguments {
+			if a.Name == "if" {
+				cond, _ = x.value(a.Value).(bool)
+			}
+		}
+		if (d.Name == "skip") == cond {
+			return false
+		}
+	}
+	return true
+}
+
+// typeConditionApplies reports whether a fragment on condition applies to
+// an object of typeName. Objects of unknown type match every condition, and
+// every resource matches Resource.
+func typeConditionApplies(condition, typeName string) bool {
+	if condition == "" || typeName == "" || condition == typeName {
+		return true
+	}
+	return condition == "Resource" && IsValidResourceType(typeName)
+}
+
+func hasDirective(f *gqlSelection, name string) bool {
+	for _, d := range f.Directives {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// argument returns the value of an argument of f with variables
+// substituted. Arguments bound to absent variables are reported as absent.
+func (x *gqlExecution) argument(f *gqlSelection, name string) (interface{}, bool) {
+	v, ok := f.argument(name)
+	if !ok {
+		return nil, false
+	}
+	v = x.value(v)
+	return v, v != nil
+}
+
+// value substitutes variables in an argument value and converts enum values
+// to strings, returning plain JSON data.
+func (x *gqlExecution) value(v interface{}) interface{} {
+	switch t := v.(type) {
+	case gqlVariable:
+		return x.variables[string(t)]
+	case gqlEnum:
+		return string(t)
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = x.value(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, item := range t {
+			out[k] = x.value(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// searchParams converts the arguments of a field into search parameters.
+// GraphQL names cannot contain "-", so underscores after the first letter
+// stand for hyphens (general_practitioner is general-practitioner). Lists
+// are joined with commas, which FHIR search treats as OR.
+func (x *gqlExecution) searchParams(f *gqlSelection) map[string]string {
+	params := make(map[string]string, len(f.Arguments))
+	for _, a := range f.Arguments {
+		if v := x.value(a.Value); v != nil {
+			params[graphqlParamName(a.Name)] = graphqlArgString(v)
+		}
+	}
+	return params
+}
+
+// graphqlParamName returns the search parameter named by a GraphQL
+// argument name.
+func graphqlParamName(name string) string {
+	trimmed := strings.TrimLeft(name, "_")
+	return name[:len(name)-len(trimmed)] + strings.ReplaceAll(trimmed, "_", "-")
+}
+
+// graphqlArgString formats an argument value as a search parameter value.
+func graphqlArgString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case []interface{}:
+		parts := make([]string, len(t))
+		for i, item := range t {
+			parts[i] = graphqlArgString(item)
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// count removes _count from params and returns it, clamped to the
+// engine's MaxCount, or graphqlDefaultCount when it is absent.
+func (x *gqlExecution) count(params map[string]string) (int, error) {
+	n, err := graphqlCount(params)
+	if err != nil {
+		return 0, err
+	}
+	return min(n, x.limits.MaxCount), nil
+}
+
+// graphqlCount removes _count from params and returns it, or
+// graphqlDefaultCount when it is absent.
+func graphqlCount(params map[string]string) (int, error) {
+	v, ok := params["_count"]
+	if !ok {
+		return graphqlDefaultCount, nil
+	}
+	delete(params, "_count")
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid _count %q", v)
+	}
+	return n, nil
+}
+
+// graphqlFieldTarget returns the resource type read by a query field and
+// whether the field is an XxxList or XxxConnection search.
+func graphqlFieldTarget(name string) (resourceType string, list, connection bool) {
+	switch {
+	case strings.HasSuffix(name, "Connection") && len(name) > len("Connection"):
+		return strings.TrimSuffix(name, "Connection"), false, true
+	case strings.HasSuffix(name, "List") && len(name) > len("List"):
+		return strings.TrimSuffix(name, "List"), true, false
+	}
+	return name, false, false
+}
+
+// graphqlMutationTarget splits a mutation field name into the resource type
+// and the action: Create, Update or Delete. The action is empty for names
+// that are not mutations.
+func graphqlMutationTarget(name string) (resourceType, action string) {
+	for _, a := range []string{"Create", "Update", "Delete"} {
+		if strings.HasSuffix(name, a) && len(name) > len(a) {
+			return strings.TrimSuffix(name, a), a
+		}
+	}
+	return "", ""
+}
+
+// encodeGraphQLCursor returns the opaque cursor of the page at offset.
+func encodeGraphQLCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// decodeGraphQLCursor returns the offset of a cursor made by
+// encodeGraphQLCursor.
+func decodeGraphQLCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if v, ok := strings.CutPrefix(string(raw), "offset:"); ok {
+			if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+				return n, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor %q", cursor)
+}
+
+// childPath returns path extended with key, without sharing path's array.
+func childPath(path []string, key string) []string {
+	out := make([]string, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, key)
+}
+
+// authorizeGraphQL checks that the SMART scopes of the request grant
+// operation ("read" or "write") on resourceType. As in
+// auth.FHIRScopeMiddleware, admins and requests without scopes are not
+// restricted.
+func authorizeGraphQL(ctx context.Context, resourceType, operation string) error {
+	for _, r := range auth.RolesFromContext(ctx) {
+		if r == "admin" {
+			return nil
+		}
+	}
+	scopes := auth.ScopesFromContext(ctx)
+	if len(scopes) == 0 {
+		return nil
+	}
+	if auth.ScopeAllows(auth.ParseSMARTScopes(scopes), resourceType, operation) {
+		return nil
+	}
+	return fmt.Errorf("insufficient scope: required %s.%s", resourceType, operation)
+}
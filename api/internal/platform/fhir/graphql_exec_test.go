@@ -0,0 +1,385 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ehr/ehr/internal/platform/auth"
+	"github.com/labstack/echo/v4"
+)
+
+// graphqlData executes query and returns its data, failing on errors.
+func graphqlData(t *testing.T, engine *GraphQLEngine, query string, vars map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	resp := engine.Execute(context.Background(), GraphQLRequest{Query: query, Variables: vars})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", resp.Errors)
+	}
+	return resp.Data.(map[string]interface{})
+}
+
+func TestGraphQL_AliasesAndNestedSelections(t *testing.T) {
+	engine, _ := newTestGraphQLEngine()
+	data := graphqlData(t, engine, `{
+		smith: Patient(id: "123") { __typename name { family } }
+		jones: Patient(id: "789") { ...Born }
+	}
+	fragment Born on Patient { birthDate gender @skip(if: true) }`, nil)
+
+	smith := data["smith"].(map[string]interface{})
+	if smith["__typename"] != "Patient" {
+		t.Errorf("__typename = %v", smith["__typename"])
+	}
+	names := smith["name"].([]interface{})
+	if name := names[0].(map[string]interface{}); name["family"] != "Smith" || name["given"] != nil {
+		t.Errorf("unexpected name selection: %v", name)
+	}
+	jones := data["jones"].(map[string]interface{})
+	if jones["birthDate"] != "2000-03-10" || jones["gender"] != nil {
+		t.Errorf("unexpected fragment selection: %v", jones)
+	}
+}
+
+func TestGraphQL_ListFieldArguments(t *testing.T) {
+	engine, resolver := newTestGraphQLEngine()
+	resolver.AddResource("Patient", map[string]interface{}{
+		"resourceType": "Patient", "id": "p2",
+		"name": []interface{}{
+			map[string]interface{}{"use": "usual", "family": "Bobby"},
+			map[string]interface{}{"use": "official", "family": "Roberts"},
+		},
+	})
+	data := graphqlData(t, engine, `{ Patient(id: "p2") {
+		official: name(use: official) { family }
+		first: name @first { family }
+	} }`, nil)
+	p := data["Patient"].(map[string]interface{})
+	if official := p["official"].([]interface{}); len(official) != 1 || official[0].(map[string]interface{})["family"] != "Roberts" {
+		t.Errorf("official = %v", p["official"])
+	}
+	if first := p["first"].(map[string]interface{}); first["family"] != "Bobby" {
+		t.Errorf("first = %v", p["first"])
+	}
+}
+
+func TestGraphQL_ReferenceResolution(t *testing.T) {
+	engine, _ := newTestGraphQLEngine()
+	data := graphqlData(t, engine, `{ Observation(id: "obs-1") {
+		subject { reference resource { ... on Patient { birthDate } } }
+		practitioner: subject { resource(type: Practitioner) { id } }
+	} }`, nil)
+	obs := data["Observation"].(map[string]interface{})
+	subject := obs["subject"].(map[string]interface{})
+	if subject["reference"] != "Patient/123" {
+		t.Errorf("reference = %v", subject["reference"])
+	}
+	if res := subject["resource"].(map[string]interface{}); res["birthDate"] != "1990-01-15" {
+		t.Errorf("resource = %v", res)
+	}
+	if res := obs["practitioner"].(map[string]interface{})["resource"]; res != nil {
+		t.Errorf("expected no Practitioner, got %v", res)
+	}
+}
+
+func TestGraphQL_ContainedReference(t *testing.T) {
+	engine, resolver := newTestGraphQLEngine()
+	resolver.AddResource("Observation", map[string]interface{}{
+		"resourceType": "Observation", "id": "obs-2",
+		"contained": []interface{}{
+			map[string]interface{}{"resourceType": "Patient", "id": "anon", "gender": "unknown"},
+		},
+		"subject": map[string]interface{}{"reference": "#anon"},
+	})
+	data := graphqlData(t, engine, `{ Observation(id: "obs-2") { subject { resource { gender } } } }`, nil)
+	subject := data["Observation"].(map[string]interface{})["subject"].(map[string]interface{})
+	if res := subject["resource"].(map[string]interface{}); res["gender"] != "unknown" {
+		t.Errorf("resource = %v", res)
+	}
+}
+
+func TestGraphQL_ReverseReferences(t *testing.T) {
+	engine, _ := newTestGraphQLEngine()
+	data := graphqlData(t, engine, `{ Patient(id: "123") {
+		id
+		ObservationList(_reference: subject) { id status }
+		ObservationConnection(_reference: subject) { count edges { resource { id } } }
+	} }`, nil)
+	p := data["Patient"].(map[string]interface{})
+	list := p["ObservationList"].([]interface{})
+	if len(list) != 1 || list[0].(map[string]interface{})["id"] != "obs-1" {
+		t.Errorf("ObservationList = %v", list)
+	}
+	if conn := p["ObservationConnection"].(map[string]interface{}); conn["count"] != 1 {
+		t.Errorf("ObservationConnection = %v", conn)
+	}
+
+	data = graphqlData(t, engine, `{ Patient(id: "456") { ObservationList(_reference: subject) { id } } }`, nil)
+	if list := data["Patient"].(map[string]interface{})["ObservationList"].([]interface{}); len(list) != 0 {
+		t.Errorf("expected no observations for 456, got %v", list)
+	}
+}
+
+func TestGraphQL_ConnectionPaging(t *testing.T) {
+	engine, _ := newTestGraphQLEngine()
+	query := `query ($cursor: String) {
+		PatientConnection(_count: 2, _cursor: $cursor) {
+			count offset pagesize first previous next last
+			edges { mode resource { id } }
+		}
+	}`
+	data := graphqlData(t, engine, query, nil)
+	page := data["PatientConnection"].(map[string]interface{})
+	if page["count"] != 3 || page["offset"] != 0 || page["pagesize"] != 2 || page["previous"] != nil {
+		t.Errorf("unexpected first page: %v", page)
+	}
+	edges := page["edges"].([]interface{})
+	if len(edges) != 2 || edges[0].(map[string]interface{})["mode"] != "match" {
+		t.Fatalf("unexpected edges: %v", edges)
+	}
+	next, _ := page["next"].(string)
+	if next == "" || page["last"] != next {
+		t.Fatalf("expected next and last cursors to match, got %v and %v", page["next"], page["last"])
+	}
+
+	data = graphqlData(t, engine, query, map[string]interface{}{"cursor": next})
+	page = data["PatientConnection"].(map[string]interface{})
+	edges = page["edges"].([]interface{})
+	if len(edges) != 1 || page["offset"] != 2 || page["next"] != nil || page["previous"] != page["first"] {
+		t.Errorf("unexpected second page: %v", page)
+	}
+	if id := edges[0].(map[string]interface{})["resource"].(map[string]interface{})["id"]; id != "789" {
+		t.Errorf("expected Patient 789 on the second page, got %v", id)
+	}
+
+	resp := engine.Execute(context.Background(), GraphQLRequest{Query: query, Variables: map[string]interface{}{"cursor": "bogus"}})
+	if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, "invalid cursor") {
+		t.Errorf("expected an invalid cursor error, got %v", resp.Errors)
+	}
+}
+
+func TestGraphQL_Limits(t *testing.T) {
+	engine, _ := newTestGraphQLEngine()
+	engine.SetLimits(GraphQLLimits{MaxCount: 2, MaxDepth: 3, MaxResolutions: 2})
+
+	data := graphqlData(t, engine, `{ PatientList(_count: 50) { id } }`, nil)
+	if list := data["PatientList"].([]interface{}); len(list) != 2 {
+		t.Errorf("expected _count to be clamped to 2, got %d patients", len(list))
+	}
+
+	tests := []struct {
+		name, query string
+	}{
+		{"depth", `{ Patient(id: "123") { ObservationList(_reference: subject) { code { coding { code } } } } }`},
+		{"depth through a fragment", `{ Patient(id: "123") { ...Obs } }
+		fragment Obs on Patient { ObservationList(_reference: subject) { code { text } } }`},
+		{"resolutions", `{ a: Patient(id: "123") { id } b: Patient(id: "456") { id } c: Patient(id: "789") { id } }`},
+	}
+	for _, tt := range tests {
+		resp := engine.Execute(context.Background(), GraphQLRequest{Query: tt.query})
+		if !resp.TooCostly() || resp.Data != nil || len(resp.Errors) == 0 {
+			t.Errorf("%s: expected a too-costly rejection, got %+v", tt.name, resp)
+		}
+	}
+
+	e := echo.New()
+	NewGraphQLHandler(engine).RegisterRoutes(e.Group("/fhir"))
+	req := httptest.NewRequest(http.MethodGet, "/fhir/$graphql?query="+url.QueryEscape(tests[2].query), nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"too-costly"`) {
+		t.Errorf("expected a 400 too-costly OperationOutcome, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGraphQL_Mutations(t *testing.T) {
+	engine, resolver := newTestGraphQLEngine()
+	data := graphqlData(t, engine, `mutation {
+		created: PatientCreate(res: {name: [{family: "Newman"}], gender: female}) { id gender name { family } }
+	}`, nil)
+	created := data["created"].(map[string]interface{})
+	id, _ := created["id"].(string)
+	if id == "" || created["gender"] != "female" {
+		t.Fatalf("unexpected created resource: %v", created)
+	}
+	if stored, err := resolver.ResolveByID(context.Background(), "Patient", id); err != nil || stored["resourceType"] != "Patient" {
+		t.Errorf("created resource not stored: %v, %v", stored, err)
+	}
+
+	data = graphqlData(t, engine, `mutation Update($id: ID!, $res: ResourceInput!) {
+		PatientUpdate(id: $id, res: $res) { id gender }
+	}`, map[string]interface{}{"id": id, "res": map[string]interface{}{"gender": "other"}})
+	if updated := data["PatientUpdate"].(map[string]interface{}); updated["id"] != id || updated["gender"] != "other" {
+		t.Errorf("unexpected updated resource: %v", updated)
+	}
+
+	data = graphqlData(t, engine, `mutation { PatientDelete(id: "789") { id } }`, nil)
+	if deleted := data["PatientDelete"].(map[string]interface{}); deleted["id"] != "789" {
+		t.Errorf("unexpected deleted resource: %v", deleted)
+	}
+	if _, err := resolver.ResolveByID(context.Background(), "Patient", "789"); err == nil {
+		t.Error("expected Patient/789 to be deleted")
+	}
+
+	for query, want := range map[string]string{
+		`mutation { PatientCreate(res: {resourceType: "Observation"}) { id } }`: "resourceType Observation",
+		`mutation { PatientUpdate(res: {}) { id } }`:                            "requires an id",
+		`mutation { PatientPurge(id: "1") { id } }`:                             "unknown mutation",
+		`mutation { PatientUpdate(id: "nope", res: {}) { id } }`:                "not found",
+	} {
+		resp := engine.Execute(context.Background(), GraphQLRequest{Query: query})
+		if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, want) {
+			t.Errorf("%s: expected error containing %q, got %v", query, want, resp.Errors)
+		}
+	}
+}
+
+func TestGraphQL_ScopesEnforced(t *testing.T) {
+	engine, _ := newTestGraphQLEngine()
+	ctx := context.WithValue(context.Background(), auth.UserScopesKey, []string{"patient/Patient.read"})
+
+	resp := engine.Execute(ctx, GraphQLRequest{Query: `{ Patient(id: "123") { id } }`})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", resp.Errors)
+	}
+
+	resp = engine.Execute(ctx, GraphQLRequest{Query: `{ Patient(id: "123") { id ObservationList(_reference: subject) { id } } }`})
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "insufficient scope: required Observation.read") {
+		t.Errorf("expected an Observation scope error, got %v", resp.Errors)
+	}
+	if p := resp.Data.(map[string]interface{})["Patient"].(map[string]interface{}); p["id"] != "123" || p["ObservationList"] != nil {
+		t.Errorf("expected the permitted fields only, got %v", p)
+	}
+
+	resp = engine.Execute(ctx, GraphQLRequest{Query: `mutation { PatientDelete(id: "123") { id } }`})
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "required Patient.write") {
+		t.Errorf("expected a write scope error, got %v", resp.Errors)
+	}
+
+	admin := context.WithValue(ctx, auth.UserRolesKey, []string{"admin"})
+	resp = engine.Execute(admin, GraphQLRequest{Query: `{ ObservationList { id } }`})
+	if len(resp.Errors) > 0 {
+		t.Errorf("expected admins to bypass scopes, got %v", resp.Errors)
+	}
+}
+
+func TestGraphQL_ExecuteInstance(t *testing.T) {
+	engine, _ := newTestGraphQLEngine()
+	resp := engine.ExecuteInstance(context.Background(), "Patient", "123", GraphQLRequest{
+		Query: `{ id name { given } ObservationList(_reference: subject) { id } }`,
+	})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", resp.Errors)
+	}
+	data := resp.Data.(map[string]interface{})
+	if data["id"] != "123" || len(data["ObservationList"].([]interface{})) != 1 {
+		t.Errorf("unexpected data: %v", data)
+	}
+
+	resp = engine.ExecuteInstance(context.Background(), "Patient", "nope", GraphQLRequest{Query: `{ id }`})
+	if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, "not found") {
+		t.Errorf("expected not found, got %v", resp.Errors)
+	}
+	resp = engine.ExecuteInstance(context.Background(), "Patient", "123", GraphQLRequest{Query: `mutation { PatientDelete(id: "123") { id } }`})
+	if len(resp.Errors) == 0 {
+		t.Error("expected mutations to be rejected at the instance level")
+	}
+}
+
+func TestGraphQLHandler_InstanceRoutes(t *testing.T) {
+	engine, _ := newTestGraphQLEngine()
+	e := echo.New()
+	g := e.Group("/fhir")
+	// The compartment routes share the /Patient/:pid/ prefix.
+	NewCompartmentHandler(NewEchoEntryDispatcher(e, "/fhir")).RegisterRoutes(g)
+	NewGraphQLHandler(engine).RegisterRoutes(g)
+
+	tests := []struct {
+		method, target, body string
+		wantID               string
+	}{
+		{http.MethodGet, "/fhir/Patient/123/$graphql?query=" + url.QueryEscape(`{ id }`), "", "123"},
+		{http.MethodPost, "/fhir/Patient/456/$graphql", `{"query": "{ id }"}`, "456"},
+		{http.MethodGet, "/fhir/Observation/obs-1/$graphql?query=" + url.QueryEscape(`query ($f: Boolean!) { id status @include(if: $f) }`) +
+			"&variables=" + url.QueryEscape(`{"f": true}`), "", "obs-1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s %s: expected 200, got %d: %s", tt.method, tt.target, rec.Code, rec.Body.String())
+			continue
+		}
+		var resp struct {
+			Data   map[string]interface{} `json:"data"`
+			Errors []GraphQLError         `json:"errors"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if resp.Data["id"] != tt.wantID || len(resp.Errors) > 0 {
+			t.Errorf("%s %s: unexpected response %s", tt.method, tt.target, rec.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Bogus/1/$graphql?query=%7Bid%7D", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown type, got %d", rec.Code)
+	}
+}
+
+func TestGraphQL_Introspection(t *testing.T) {
+	engine, _ := newTestGraphQLEngine()
+	data := graphqlData(t, engine, `{
+		__schema { queryType { name } mutationType { name } types { kind name } }
+		patient: __type(name: "Patient") { kind fields { name type { kind name ofType { name } } } }
+		humanName: __type(name: "HumanName") { fields(includeDeprecated: true) { name } }
+		missing: __type(name: "Nope") { name }
+	}`, nil)
+
+	schema := data["__schema"].(map[string]interface{})
+	if schema["queryType"].(map[string]interface{})["name"] != "Query" || schema["mutationType"].(map[string]interface{})["name"] != "Mutation" {
+		t.Errorf("unexpected root types: %v", schema)
+	}
+	types := map[string]string{}
+	for _, typ := range schema["types"].([]interface{}) {
+		m := typ.(map[string]interface{})
+		types[m["name"].(string)] = m["kind"].(string)
+	}
+	for name, kind := range map[string]string{
+		"Patient": "OBJECT", "PatientConnection": "OBJECT", "PatientEdge": "OBJECT", "HumanName": "OBJECT",
+		"ObservationReferenceRange": "OBJECT", "Resource": "UNION", "ResourceInput": "SCALAR", "Mutation": "OBJECT",
+	} {
+		if types[name] != kind {
+			t.Errorf("type %s: kind %q, want %q", name, types[name], kind)
+		}
+	}
+
+	fields := map[string]map[string]interface{}{}
+	for _, f := range data["patient"].(map[string]interface{})["fields"].([]interface{}) {
+		m := f.(map[string]interface{})
+		fields[m["name"].(string)] = m["type"].(map[string]interface{})
+	}
+	if typ := fields["name"]; typ["kind"] != "LIST" || typ["ofType"].(map[string]interface{})["name"] != "HumanName" {
+		t.Errorf("Patient.name type = %v", typ)
+	}
+	for _, name := range []string{"birthDate", "deceasedBoolean", "deceasedDateTime", "contact", "ObservationList", "ObservationConnection"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("expected Patient field %s", name)
+		}
+	}
+	if len(data["humanName"].(map[string]interface{})["fields"].([]interface{})) < 5 {
+		t.Errorf("expected the HumanName fields, got %v", data["humanName"])
+	}
+	if data["missing"] != nil {
+		t.Errorf("expected null for an unknown type, got %v", data["missing"])
+	}
+}
@@ -3,10 +3,11 @@ package fhir
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,22 +16,68 @@ import (
 )
 
 // GraphQLResourceResolver resolves FHIR resources for the GraphQL engine.
-// It supports fetching by ID and searching with parameters.
+// It supports fetching by ID and searching with parameters. Search
+// parameters may include _offset, the number of matches to skip.
 type GraphQLResourceResolver interface {
 	ResolveByID(ctx context.Context, resourceType, id string) (map[string]interface{}, error)
 	ResolveSearch(ctx context.Context, resourceType string, params map[string]string, limit int) ([]map[string]interface{}, error)
 }
 
+// GraphQLPageResolver is implemented by resolvers that report the total
+// number of matches of a search, which XxxConnection fields return as
+// count. A negative total means the total is not known.
+type GraphQLPageResolver interface {
+	ResolvePage(ctx context.Context, resourceType string, params map[string]string, count int) ([]map[string]interface{}, int, error)
+}
+
+// GraphQLMutationResolver is implemented by resolvers that serve the
+// XxxCreate, XxxUpdate and XxxDelete mutations. Each returns the resource
+// created, updated or deleted.
+type GraphQLMutationResolver interface {
+	Create(ctx context.Context, resourceType string, resource map[string]interface{}) (map[string]interface{}, error)
+	Update(ctx context.Context, resourceType, id string, resource map[string]interface{}) (map[string]interface{}, error)
+	Delete(ctx context.Context, resourceType, id string) (map[string]interface{}, error)
+}
+
 // GraphQLRequest represents an incoming GraphQL query request.
 type GraphQLRequest struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables,omitempty"`
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
 }
 
 // GraphQLResponse represents the response from a GraphQL query execution.
 type GraphQLResponse struct {
 	Data   interface{}    `json:"data,omitempty"`
 	Errors []GraphQLError `json:"errors,omitempty"`
+
+	// tooCostly reports that the operation exceeded the engine's limits;
+	// the HTTP handlers reject it with a too-costly OperationOutcome.
+	tooCostly bool
+}
+
+// TooCostly reports whether the operation was rejected for exceeding the
+// limits of the engine (see GraphQLLimits).
+func (r *GraphQLResponse) TooCostly() bool { return r.tooCostly }
+
+// Default limits of a GraphQLEngine.
+const (
+	DefaultGraphQLMaxCount       = 1000
+	DefaultGraphQLMaxDepth       = 15
+	DefaultGraphQLMaxResolutions = 500
+)
+
+// GraphQLLimits bounds the work done to execute one GraphQL operation.
+type GraphQLLimits struct {
+	// MaxCount is the largest _count of a List or Connection field; larger
+	// values are clamped to it.
+	MaxCount int
+	// MaxDepth is the number of nested field levels an operation may
+	// select. Deeper operations are rejected before anything is resolved.
+	MaxDepth int
+	// MaxResolutions is the number of reads and searches, including those
+	// of references and reverse references, one operation may make.
+	MaxResolutions int
 }
 
 // GraphQLError represents a single error from GraphQL execution.
@@ -39,19 +86,36 @@ type GraphQLError struct {
 	Path    []string `json:"path,omitempty"`
 }
 
-// parsedQuery holds the result of parsing a simple GraphQL query string.
+// parsedQuery describes the first root field of a GraphQL query: the
+// resource type it reads, its arguments as search parameters and the names
+// of the fields it selects.
 type parsedQuery struct {
 	ResourceType string
 	ID           string
 	Params       map[string]string
 	Fields       []string
 	IsList       bool
+	IsConnection bool
 }
 
-// GraphQLEngine executes simplified FHIR-compatible GraphQL queries.
+// GraphQLEngine executes FHIR GraphQL queries and mutations
+// (http://hl7.org/fhir/graphql.html) against the registered resolvers:
+//
+//   - Xxx(id: ...) reads a resource; XxxList(...) and XxxConnection(...)
+//     search resources, the latter with cursor paging.
+//   - The resource field of a Reference resolves the referenced resource,
+//     and XxxList(_reference: param, ...) on a resource searches the
+//     resources referring to it.
+//   - XxxCreate, XxxUpdate and XxxDelete mutations are served by resolvers
+//     implementing GraphQLMutationResolver.
+//   - __schema and __type answer introspection queries.
+//
+// Access is limited by the SMART scopes of the request: reads need read
+// access, and mutations write access, to each resource type involved.
 type GraphQLEngine struct {
 	mu        sync.RWMutex
 	resolvers map[string]GraphQLResourceResolver
+	limits    GraphQLLimits
 }
 
 // NewGraphQLEngine creates a new GraphQL engine with no resolvers registered.
@@ -68,212 +132,105 @@ func (e *GraphQLEngine) RegisterResolver(resourceType string, resolver GraphQLRe
 	e.resolvers[resourceType] = resolver
 }
 
-// Execute parses and executes a GraphQL request, returning a response.
-func (e *GraphQLEngine) Execute(ctx context.Context, req GraphQLRequest) *GraphQLResponse {
-	query := req.Query
-
-	// Substitute variables into the query.
-	if len(req.Variables) > 0 {
-		query = substituteVariables(query, req.Variables)
-	}
-
-	parsed, err := parseGraphQLQuery(query)
-	if err != nil {
-		return &GraphQLResponse{
-			Errors: []GraphQLError{{Message: err.Error()}},
-		}
-	}
+// SetLimits sets the limits of the operations executed by the engine. Zero
+// values select DefaultGraphQLMaxCount, DefaultGraphQLMaxDepth and
+// DefaultGraphQLMaxResolutions.
+func (e *GraphQLEngine) SetLimits(limits GraphQLLimits) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.limits = limits
+}
 
+// limitsOrDefault returns the limits of the engine with the defaults
+// filled in.
+func (e *GraphQLEngine) limitsOrDefault() GraphQLLimits {
 	e.mu.RLock()
-	resolver, ok := e.resolvers[parsed.ResourceType]
+	limits := e.limits
 	e.mu.RUnlock()
-
-	if !ok {
-		return &GraphQLResponse{
-			Errors: []GraphQLError{{
-				Message: fmt.Sprintf("no resolver registered for resource type %s", parsed.ResourceType),
-				Path:    []string{parsed.ResourceType},
-			}},
-		}
+	if limits.MaxCount <= 0 {
+		limits.MaxCount = DefaultGraphQLMaxCount
 	}
-
-	if parsed.IsList {
-		return e.executeList(ctx, resolver, parsed)
+	if limits.MaxDepth <= 0 {
+		limits.MaxDepth = DefaultGraphQLMaxDepth
 	}
-	return e.executeSingle(ctx, resolver, parsed)
-}
-
-// executeSingle resolves a single resource by ID and applies field selection.
-func (e *GraphQLEngine) executeSingle(ctx context.Context, resolver GraphQLResourceResolver, pq *parsedQuery) *GraphQLResponse {
-	resource, err := resolver.ResolveByID(ctx, pq.ResourceType, pq.ID)
-	if err != nil {
-		return &GraphQLResponse{
-			Errors: []GraphQLError{{
-				Message: fmt.Sprintf("resource %s/%s not found: %v", pq.ResourceType, pq.ID, err),
-				Path:    []string{pq.ResourceType},
-			}},
-		}
+	if limits.MaxResolutions <= 0 {
+		limits.MaxResolutions = DefaultGraphQLMaxResolutions
 	}
+	return limits
+}
 
-	filtered := applyFieldSelection(resource, pq.Fields)
-	queryName := pq.ResourceType
-	return &GraphQLResponse{
-		Data: map[string]interface{}{
-			queryName: filtered,
-		},
-	}
+// resolver returns the resolver registered for resourceType.
+func (e *GraphQLEngine) resolver(resourceType string) (GraphQLResourceResolver, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	r, ok := e.resolvers[resourceType]
+	return r, ok
 }
 
-// executeList resolves a list of resources via search and applies field selection.
-func (e *GraphQLEngine) executeList(ctx context.Context, resolver GraphQLResourceResolver, pq *parsedQuery) *GraphQLResponse {
-	limit := 100 // default
-	params := make(map[string]string)
-	for k, v := range pq.Params {
-		if k == "_count" {
-			if n, err := strconv.Atoi(v); err == nil {
-				limit = n
-			}
-			continue
-		}
-		params[k] = v
+// Execute parses and executes a GraphQL request, returning a response.
+func (e *GraphQLEngine) Execute(ctx context.Context, req GraphQLRequest) *GraphQLResponse {
+	x, op, err := newGraphQLExecution(ctx, e, req)
+	if err != nil {
+		return executionErrorResponse(err)
 	}
+	return x.response(x.executeOperation(op))
+}
 
-	results, err := resolver.ResolveSearch(ctx, pq.ResourceType, params, limit)
+// ExecuteInstance executes a query against the resource resourceType/id,
+// as for [base]/Patient/123/$graphql: the selection set of the query
+// applies to the resource itself. Mutations are not allowed.
+func (e *GraphQLEngine) ExecuteInstance(ctx context.Context, resourceType, id string, req GraphQLRequest) *GraphQLResponse {
+	x, op, err := newGraphQLExecution(ctx, e, req)
 	if err != nil {
+		return executionErrorResponse(err)
+	}
+	if op.Kind != "query" {
 		return &GraphQLResponse{
-			Errors: []GraphQLError{{
-				Message: fmt.Sprintf("search for %s failed: %v", pq.ResourceType, err),
-				Path:    []string{pq.ResourceType + "List"},
-			}},
+			Errors: []GraphQLError{{Message: "only queries can be executed on a resource instance"}},
 		}
 	}
-
-	filtered := make([]interface{}, 0, len(results))
-	for _, r := range results {
-		filtered = append(filtered, applyFieldSelection(r, pq.Fields))
-	}
-
-	queryName := pq.ResourceType + "List"
-	return &GraphQLResponse{
-		Data: map[string]interface{}{
-			queryName: filtered,
-		},
-	}
-}
-
-// applyFieldSelection filters a resource map to include only the requested
-// fields. Nested object fields are preserved as-is.
-func applyFieldSelection(resource map[string]interface{}, fields []string) map[string]interface{} {
-	if len(fields) == 0 {
-		return resource
+	resolver, ok := x.readResolver(resourceType, nil)
+	if !ok {
+		return x.response(nil)
 	}
-	result := make(map[string]interface{}, len(fields))
-	for _, field := range fields {
-		if val, ok := resource[field]; ok {
-			result[field] = val
-		}
+	res, ok := x.read(resolver, resourceType, id, nil)
+	if !ok {
+		return x.response(nil)
 	}
-	return result
+	return x.response(x.selectObject(res, op.Selections, resourceType, nil))
 }
 
-// substituteVariables replaces $varName references in the query with variable
-// values.
-func substituteVariables(query string, variables map[string]interface{}) string {
-	for name, val := range variables {
-		var strVal string
-		switch v := val.(type) {
-		case string:
-			strVal = `"` + v + `"`
-		default:
-			strVal = fmt.Sprintf("%v", v)
-		}
-		query = strings.ReplaceAll(query, "$"+name, strVal)
+// executionErrorResponse returns the response to an operation that could
+// not be executed.
+func executionErrorResponse(err error) *GraphQLResponse {
+	return &GraphQLResponse{
+		Errors:    []GraphQLError{{Message: err.Error()}},
+		tooCostly: errors.Is(err, errGraphQLTooCostly),
 	}
-	return query
 }
 
-// graphqlQueryPattern matches simple GraphQL queries:
-//
-//	{ TypeName(args) { fields } }
-var graphqlQueryPattern = regexp.MustCompile(
-	`^\s*\{\s*(\w+)\s*(?:\(([^)]*)\))?\s*\{\s*([^}]+)\}\s*\}\s*$`,
-)
-
-// parseGraphQLQuery parses a simplified GraphQL query string into a structured
-// representation.
+// parseGraphQLQuery parses a GraphQL query and describes its first root
+// field.
 func parseGraphQLQuery(query string) (*parsedQuery, error) {
-	query = strings.TrimSpace(query)
-	if query == "" {
-		return nil, fmt.Errorf("empty query")
-	}
-
-	matches := graphqlQueryPattern.FindStringSubmatch(query)
-	if matches == nil {
-		return nil, fmt.Errorf("invalid GraphQL query syntax")
-	}
-
-	typeName := matches[1]
-	argsStr := matches[2]
-	fieldsStr := matches[3]
-
-	pq := &parsedQuery{
-		Params: make(map[string]string),
+	doc, err := parseGraphQLDocument(query)
+	if err != nil {
+		return nil, err
 	}
-
-	// Check for List suffix.
-	if strings.HasSuffix(typeName, "List") {
-		pq.ResourceType = strings.TrimSuffix(typeName, "List")
-		pq.IsList = true
-	} else {
-		pq.ResourceType = typeName
+	x := &gqlExecution{doc: doc, variables: map[string]interface{}{}}
+	fields := x.collectFields(doc.Operations[0].Selections, "Query")
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("the query selects no field")
 	}
-
-	// Parse arguments.
-	if argsStr != "" {
-		if err := parseArguments(argsStr, pq); err != nil {
-			return nil, err
-		}
+	root := fields[0]
+	pq := &parsedQuery{Params: x.searchParams(root)}
+	pq.ResourceType, pq.IsList, pq.IsConnection = graphqlFieldTarget(root.Name)
+	pq.ID = pq.Params["id"]
+	for _, f := range x.collectFields(root.Selections, pq.ResourceType) {
+		pq.Fields = append(pq.Fields, f.Name)
 	}
-
-	// Parse fields.
-	pq.Fields = parseFields(fieldsStr)
-
 	return pq, nil
 }
 
-// argPattern matches key: "value" or key: $var pairs in argument lists.
-var argPattern = regexp.MustCompile(`(\w+)\s*:\s*(?:"([^"]*)"|([\w$]+))`)
-
-// parseArguments extracts key-value pairs from the GraphQL arguments string.
-func parseArguments(argsStr string, pq *parsedQuery) error {
-	matches := argPattern.FindAllStringSubmatch(argsStr, -1)
-	for _, m := range matches {
-		key := m[1]
-		value := m[2]
-		if value == "" {
-			value = m[3]
-		}
-		if key == "id" {
-			pq.ID = value
-		}
-		pq.Params[key] = value
-	}
-	return nil
-}
-
-// parseFields splits a comma-separated field list into individual field names.
-func parseFields(fieldsStr string) []string {
-	parts := strings.Split(fieldsStr, ",")
-	fields := make([]string, 0, len(parts))
-	for _, p := range parts {
-		f := strings.TrimSpace(p)
-		if f != "" {
-			fields = append(fields, f)
-		}
-	}
-	return fields
-}
-
 // =========== HTTP Handler ===========
 
 // GraphQLHandler provides HTTP endpoints for the FHIR $graphql operation.
@@ -286,55 +243,130 @@ func NewGraphQLHandler(engine *GraphQLEngine) *GraphQLHandler {
 	return &GraphQLHandler{engine: engine}
 }
 
-// RegisterRoutes adds $graphql routes to the given FHIR group.
+// RegisterRoutes adds the system-level $graphql routes and the
+// instance-level /:resourceType/:id/$graphql routes to the given FHIR
+// group. The compartment search routes (/Patient/:pid/:resourceType, ...)
+// would take $graphql for a resource type, so the instance routes of the
+// compartment types are registered explicitly.
 func (h *GraphQLHandler) RegisterRoutes(g *echo.Group) {
 	g.POST("/$graphql", h.HandlePost)
 	g.GET("/$graphql", h.HandleGet)
+	g.POST("/:resourceType/:id/$graphql", h.HandleInstancePost)
+	g.GET("/:resourceType/:id/$graphql", h.HandleInstanceGet)
+	for _, rt := range append([]string{"Patient"}, searchableCompartments...) {
+		g.POST("/"+rt+"/:id/$graphql", h.instanceHandler(rt, h.readPost))
+		g.GET("/"+rt+"/:id/$graphql", h.instanceHandler(rt, h.readGet))
+	}
 }
 
 // HandlePost handles POST /fhir/$graphql with a JSON GraphQLRequest body.
 func (h *GraphQLHandler) HandlePost(c echo.Context) error {
+	req, outcome := h.readPost(c)
+	if outcome != nil {
+		return c.JSON(http.StatusBadRequest, outcome)
+	}
+	return h.respond(c, h.engine.Execute(c.Request().Context(), *req))
+}
+
+// HandleGet handles GET /fhir/$graphql with the query in a query parameter.
+func (h *GraphQLHandler) HandleGet(c echo.Context) error {
+	req, outcome := h.readGet(c)
+	if outcome != nil {
+		return c.JSON(http.StatusBadRequest, outcome)
+	}
+	return h.respond(c, h.engine.Execute(c.Request().Context(), *req))
+}
+
+// respond writes the response of an executed operation. Operations that
+// exceeded the engine's limits are rejected with a too-costly
+// OperationOutcome.
+func (h *GraphQLHandler) respond(c echo.Context, resp *GraphQLResponse) error {
+	if resp.TooCostly() {
+		msgs := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			msgs[i] = e.Message
+		}
+		return c.JSON(http.StatusBadRequest, NewOperationOutcome(
+			IssueSeverityError, IssueTypeTooCostly, strings.Join(msgs, "; ")))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// HandleInstancePost handles POST /fhir/:resourceType/:id/$graphql.
+func (h *GraphQLHandler) HandleInstancePost(c echo.Context) error {
+	return h.instanceHandler(c.Param("resourceType"), h.readPost)(c)
+}
+
+// HandleInstanceGet handles GET /fhir/:resourceType/:id/$graphql.
+func (h *GraphQLHandler) HandleInstanceGet(c echo.Context) error {
+	return h.instanceHandler(c.Param("resourceType"), h.readGet)(c)
+}
+
+// instanceHandler returns the handler of the instance-level $graphql
+// operation on resourceType, reading the request with read.
+func (h *GraphQLHandler) instanceHandler(resourceType string, read func(echo.Context) (*GraphQLRequest, map[string]interface{})) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !IsValidResourceType(resourceType) {
+			return c.JSON(http.StatusNotFound, operationOutcome("error", "not-supported", "Unknown resource type: "+resourceType))
+		}
+		req, outcome := read(c)
+		if outcome != nil {
+			return c.JSON(http.StatusBadRequest, outcome)
+		}
+		return h.respond(c, h.engine.ExecuteInstance(c.Request().Context(), resourceType, c.Param("id"), *req))
+	}
+}
+
+// readPost reads a JSON GraphQLRequest body. It returns the
+// OperationOutcome of a 400 response for bodies that do not hold a query.
+func (h *GraphQLHandler) readPost(c echo.Context) (*GraphQLRequest, map[string]interface{}) {
 	body, err := io.ReadAll(c.Request().Body)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, operationOutcome("error", "structure", "Failed to read request body"))
+		return nil, operationOutcome("error", "structure", "Failed to read request body")
 	}
 
 	if len(body) == 0 {
-		return c.JSON(http.StatusBadRequest, operationOutcome("error", "required", "Request body is required"))
+		return nil, operationOutcome("error", "required", "Request body is required")
 	}
 
 	var req GraphQLRequest
 	if err := json.Unmarshal(body, &req); err != nil {
-		return c.JSON(http.StatusBadRequest, operationOutcome("error", "structure", "Invalid JSON: "+err.Error()))
+		return nil, operationOutcome("error", "structure", "Invalid JSON: "+err.Error())
 	}
 
 	if strings.TrimSpace(req.Query) == "" {
-		return c.JSON(http.StatusBadRequest, operationOutcome("error", "required", "Query is required"))
+		return nil, operationOutcome("error", "required", "Query is required")
 	}
-
-	resp := h.engine.Execute(c.Request().Context(), req)
-	return c.JSON(http.StatusOK, resp)
+	return &req, nil
 }
 
-// HandleGet handles GET /fhir/$graphql with the query in a query parameter.
-func (h *GraphQLHandler) HandleGet(c echo.Context) error {
+// readGet reads the query, operationName and variables query parameters.
+// It returns the OperationOutcome of a 400 response when the query is
+// missing or the variables are not a JSON object.
+func (h *GraphQLHandler) readGet(c echo.Context) (*GraphQLRequest, map[string]interface{}) {
 	query := c.QueryParam("query")
 	if strings.TrimSpace(query) == "" {
-		return c.JSON(http.StatusBadRequest, operationOutcome("error", "required", "Query parameter 'query' is required"))
+		return nil, operationOutcome("error", "required", "Query parameter 'query' is required")
 	}
 
-	req := GraphQLRequest{Query: query}
-	resp := h.engine.Execute(c.Request().Context(), req)
-	return c.JSON(http.StatusOK, resp)
+	req := GraphQLRequest{Query: query, OperationName: c.QueryParam("operationName")}
+	if v := c.QueryParam("variables"); v != "" {
+		if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+			return nil, operationOutcome("error", "structure", "Invalid variables: "+err.Error())
+		}
+	}
+	return &req, nil
 }
 
 // =========== In-Memory Test Resolver ===========
 
 // InMemoryResourceResolver is a simple in-memory implementation of
-// GraphQLResourceResolver for testing purposes.
+// GraphQLResourceResolver, GraphQLPageResolver and GraphQLMutationResolver
+// for testing purposes.
 type InMemoryResourceResolver struct {
 	mu        sync.RWMutex
 	resources map[string]map[string]map[string]interface{} // [type][id]resource
+	nextID    int
 }
 
 // NewInMemoryResourceResolver creates a new empty in-memory resolver.
@@ -365,14 +397,9 @@ func (r *InMemoryResourceResolver) ResolveByID(_ context.Context, resourceType,
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	byType, ok := r.resources[resourceType]
+	resource, ok := r.resources[resourceType][id]
 	if !ok {
-		return nil, fmt.Errorf("resource %s/%s not found", resourceType, id)
-	}
-
-	resource, ok := byType[id]
-	if !ok {
-		return nil, fmt.Errorf("resource %s/%s not found", resourceType, id)
+		return nil, fmt.Errorf("%w: %s/%s", ErrReferenceNotFound, resourceType, id)
 	}
 
 	return resource, nil
@@ -380,29 +407,97 @@ func (r *InMemoryResourceResolver) ResolveByID(_ context.Context, resourceType,
 
 // ResolveSearch returns resources matching the given search parameters. It
 // performs simple string matching on top-level fields and nested name fields.
-func (r *InMemoryResourceResolver) ResolveSearch(_ context.Context, resourceType string, params map[string]string, limit int) ([]map[string]interface{}, error) {
+func (r *InMemoryResourceResolver) ResolveSearch(ctx context.Context, resourceType string, params map[string]string, limit int) ([]map[string]interface{}, error) {
+	results, _, err := r.ResolvePage(ctx, resourceType, params, limit)
+	return results, err
+}
+
+// ResolvePage returns up to count resources matching params, skipping the
+// first _offset, and the total number of matches. Resources are ordered by
+// id.
+func (r *InMemoryResourceResolver) ResolvePage(_ context.Context, resourceType string, params map[string]string, count int) ([]map[string]interface{}, int, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	byType, ok := r.resources[resourceType]
-	if !ok {
-		return nil, nil
+	offset := 0
+	match := make(map[string]string, len(params))
+	for k, v := range params {
+		if k == "_offset" {
+			offset, _ = strconv.Atoi(v)
+			continue
+		}
+		match[k] = v
+	}
+
+	byType := r.resources[resourceType]
+	ids := make([]string, 0, len(byType))
+	for id := range byType {
+		ids = append(ids, id)
 	}
+	sort.Strings(ids)
 
 	var results []map[string]interface{}
-	for _, resource := range byType {
-		if matchesParams(resource, params) {
+	total := 0
+	for _, id := range ids {
+		resource := byType[id]
+		if !matchesParams(resource, match) {
+			continue
+		}
+		if total >= offset && len(results) < count {
 			results = append(results, resource)
-			if len(results) >= limit {
-				break
-			}
 		}
+		total++
+	}
+	return results, total, nil
+}
+
+// Create stores resource under a new id.
+func (r *InMemoryResourceResolver) Create(_ context.Context, resourceType string, resource map[string]interface{}) (map[string]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.resources[resourceType] == nil {
+		r.resources[resourceType] = make(map[string]map[string]interface{})
+	}
+	r.nextID++
+	id := fmt.Sprintf("%s-%d", strings.ToLower(resourceType), r.nextID)
+	created := make(map[string]interface{}, len(resource)+1)
+	for k, v := range resource {
+		created[k] = v
+	}
+	created["id"] = id
+	r.resources[resourceType][id] = created
+	return created, nil
+}
+
+// Update replaces the stored resource resourceType/id.
+func (r *InMemoryResourceResolver) Update(_ context.Context, resourceType, id string, resource map[string]interface{}) (map[string]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.resources[resourceType][id]; !ok {
+		return nil, fmt.Errorf("%w: %s/%s", ErrReferenceNotFound, resourceType, id)
 	}
-	return results, nil
+	r.resources[resourceType][id] = resource
+	return resource, nil
+}
+
+// Delete removes resourceType/id and returns it.
+func (r *InMemoryResourceResolver) Delete(_ context.Context, resourceType, id string) (map[string]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	resource, ok := r.resources[resourceType][id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", ErrReferenceNotFound, resourceType, id)
+	}
+	delete(r.resources[resourceType], id)
+	return resource, nil
 }
 
 // matchesParams checks whether a resource matches all the given search
-// parameters using simple string matching.
+// parameters using simple string matching. Reference elements match on
+// their reference.
 func matchesParams(resource map[string]interface{}, params map[string]string) bool {
 	for key, val := range params {
 		if key == "name" {
@@ -415,6 +510,9 @@ func matchesParams(resource map[string]interface{}, params map[string]string) bo
 		if !ok {
 			return false
 		}
+		if ref, ok := fieldVal.(map[string]interface{}); ok && ref["reference"] != nil {
+			fieldVal = ref["reference"]
+		}
 		if fmt.Sprintf("%v", fieldVal) != val {
 			return false
 		}
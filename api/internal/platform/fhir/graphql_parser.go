@@ -0,0 +1,745 @@
+package fhir
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// gqlDocument is a parsed GraphQL document: its operations and the named
+// fragments they spread.
+type gqlDocument struct {
+	Operations []*gqlOperation
+	Fragments  map[string]*gqlFragment
+}
+
+// gqlOperation is a query, mutation or subscription definition. A document
+// written in shorthand form ({ ... }) holds a single anonymous query.
+type gqlOperation struct {
+	Kind       string
+	Name       string
+	Variables  []gqlVariableDef
+	Selections []*gqlSelection
+}
+
+// gqlVariableDef declares an operation variable, e.g. ($id: ID! = "1").
+type gqlVariableDef struct {
+	Name       string
+	Type       string
+	Default    interface{}
+	HasDefault bool
+}
+
+// gqlFragment is a named fragment definition.
+type gqlFragment struct {
+	Name          string
+	TypeCondition string
+	Selections    []*gqlSelection
+}
+
+// gqlSelection is one entry of a selection set: a field, a fragment spread
+// (Spread is set) or an inline fragment (Inline is set).
+type gqlSelection struct {
+	Alias         string
+	Name          string
+	Arguments     []gqlArgument
+	Directives    []gqlDirective
+	Selections    []*gqlSelection
+	Spread        string
+	Inline        bool
+	TypeCondition string
+}
+
+// responseKey returns the key the field's value is returned under.
+func (s *gqlSelection) responseKey() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Name
+}
+
+// argument returns the value of the named argument.
+func (s *gqlSelection) argument(name string) (interface{}, bool) {
+	for _, a := range s.Arguments {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return nil, false
+}
+
+// gqlArgument is a field or directive argument. Values are strings,
+// int64, float64, bool, nil, gqlEnum, gqlVariable, []interface{} or
+// map[string]interface{}.
+type gqlArgument struct {
+	Name  string
+	Value interface{}
+}
+
+// gqlDirective is a directive applied to a selection, e.g. @include(if: $x).
+type gqlDirective struct {
+	Name      string
+	Arguments []gqlArgument
+}
+
+// gqlVariable is a reference to an operation variable in a value.
+type gqlVariable string
+
+// gqlEnum is an enum value, written as a bare name.
+type gqlEnum string
+
+// operation returns the operation to execute: the one named name, or the
+// only operation of the document when name is empty.
+func (d *gqlDocument) operation(name string) (*gqlOperation, error) {
+	if name == "" {
+		if len(d.Operations) != 1 {
+			return nil, fmt.Errorf("operationName is required when the document has %d operations", len(d.Operations))
+		}
+		return d.Operations[0], nil
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// =========== Lexer ===========
+
+type gqlTokenKind int
+
+const (
+	gqlTokenEOF gqlTokenKind = iota
+	gqlTokenPunct
+	gqlTokenName
+	gqlTokenInt
+	gqlTokenFloat
+	gqlTokenString
+)
+
+type gqlToken struct {
+	Kind  gqlTokenKind
+	Value string
+	Pos   int
+}
+
+func (t gqlToken) String() string {
+	switch t.Kind {
+	case gqlTokenEOF:
+		return "end of query"
+	case gqlTokenString:
+		return strconv.Quote(t.Value)
+	default:
+		return fmt.Sprintf("%q", t.Value)
+	}
+}
+
+// lexGraphQL splits a GraphQL document into tokens. Commas, whitespace and
+// comments are insignificant and dropped.
+func lexGraphQL(src string) ([]gqlToken, error) {
+	var tokens []gqlToken
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case strings.HasPrefix(src[i:], "\uFEFF"):
+			i += len("\uFEFF")
+		case c == '#':
+			for i < len(src) && src[i] != '\n' && src[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "..."):
+			tokens = append(tokens, gqlToken{Kind: gqlTokenPunct, Value: "...", Pos: i})
+			i += 3
+		case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+			tokens = append(tokens, gqlToken{Kind: gqlTokenPunct, Value: string(c), Pos: i})
+			i++
+		case c == '_' || isASCIILetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || isASCIILetter(src[i]) || isASCIIDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, gqlToken{Kind: gqlTokenName, Value: src[start:i], Pos: start})
+		case c == '-' || isASCIIDigit(c):
+			tok, n, err := lexGraphQLNumber(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = n
+		case c == '"':
+			tok, n, err := lexGraphQLString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = n
+		default:
+			r, _ := utf8.DecodeRuneInString(src[i:])
+			return nil, fmt.Errorf("syntax error at position %d: unexpected character %q", i, r)
+		}
+	}
+	return append(tokens, gqlToken{Kind: gqlTokenEOF, Pos: len(src)}), nil
+}
+
+// lexGraphQLNumber reads an IntValue or FloatValue starting at i.
+func lexGraphQLNumber(src string, i int) (gqlToken, int, error) {
+	start := i
+	kind := gqlTokenInt
+	if src[i] == '-' {
+		i++
+	}
+	digits := func() bool {
+		n := i
+		for i < len(src) && isASCIIDigit(src[i]) {
+			i++
+		}
+		return i > n
+	}
+	if !digits() {
+		return gqlToken{}, 0, fmt.Errorf("syntax error at position %d: invalid number", start)
+	}
+	if i < len(src) && src[i] == '.' {
+		kind = gqlTokenFloat
+		i++
+		if !digits() {
+			return gqlToken{}, 0, fmt.Errorf("syntax error at position %d: invalid number", start)
+		}
+	}
+	if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+		kind = gqlTokenFloat
+		i++
+		if i < len(src) && (src[i] == '+' || src[i] == '-') {
+			i++
+		}
+		if !digits() {
+			return gqlToken{}, 0, fmt.Errorf("syntax error at position %d: invalid number", start)
+		}
+	}
+	return gqlToken{Kind: kind, Value: src[start:i], Pos: start}, i, nil
+}
+
+// lexGraphQLString reads a string or block string starting at i.
+func lexGraphQLString(src string, i int) (gqlToken, int, error) {
+	start := i
+	if strings.HasPrefix(src[i:], `"""`) {
+		end := strings.Index(src[i+3:], `"""`)
+		for end >= 0 && src[i+3+end-1] == '\\' {
+			next := strings.Index(src[i+3+end+3:], `"""`)
+			if next < 0 {
+				end = -1
+				break
+			}
+			end += 3 + next
+		}
+		if end < 0 {
+			return gqlToken{}, 0, fmt.Errorf("syntax error at position %d: unterminated string", start)
+		}
+		raw := strings.ReplaceAll(src[i+3:i+3+end], `\"""`, `"""`)
+		return gqlToken{Kind: gqlTokenString, Value: blockStringValue(raw), Pos: start}, i + 3 + end + 3, nil
+	}
+
+	var b strings.Builder
+	i++
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '"':
+			return gqlToken{Kind: gqlTokenString, Value: b.String(), Pos: start}, i + 1, nil
+		case c == '\n' || c == '\r':
+			return gqlToken{}, 0, fmt.Errorf("syntax error at position %d: unterminated string", start)
+		case c == '\\':
+			if i+1 >= len(src) {
+				return gqlToken{}, 0, fmt.Errorf("syntax error at position %d: unterminated string", start)
+			}
+			switch esc := src[i+1]; esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+6 > len(src) {
+					return gqlToken{}, 0, fmt.Errorf("syntax error at position %d: invalid unicode escape", i)
+				}
+				code, err := strconv.ParseUint(src[i+2:i+6], 16, 32)
+				if err != nil {
+					return gqlToken{}, 0, fmt.Errorf("syntax error at position %d: invalid unicode escape", i)
+				}
+				b.WriteRune(rune(code))
+				i += 4
+			default:
+				return gqlToken{}, 0, fmt.Errorf("syntax error at position %d: invalid escape \\%c", i, esc)
+			}
+			i += 2
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return gqlToken{}, 0, fmt.Errorf("syntax error at position %d: unterminated string", start)
+}
+
+// blockStringValue removes the common indentation and the leading and
+// trailing blank lines of a block string.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isASCIIDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// =========== Parser ===========
+
+// gqlParser is a recursive-descent parser for GraphQL executable documents.
+type gqlParser struct {
+	tokens []gqlToken
+	pos    int
+}
+
+// parseGraphQLDocument parses the operations and fragments of a GraphQL
+// query document.
+func parseGraphQLDocument(src string) (*gqlDocument, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+	tokens, err := lexGraphQL(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &gqlParser{tokens: tokens}
+	doc := &gqlDocument{Fragments: make(map[string]*gqlFragment)}
+	for p.peek().Kind != gqlTokenEOF {
+		tok := p.peek()
+		switch {
+		case tok.Kind == gqlTokenPunct && tok.Value == "{":
+			sels, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, &gqlOperation{Kind: "query", Selections: sels})
+		case tok.Kind == gqlTokenName && (tok.Value == "query" || tok.Value == "mutation" || tok.Value == "subscription"):
+			op, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case tok.Kind == gqlTokenName && tok.Value == "fragment":
+			frag, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := doc.Fragments[frag.Name]; dup {
+				return nil, fmt.Errorf("fragment %q is defined more than once", frag.Name)
+			}
+			doc.Fragments[frag.Name] = frag
+		default:
+			return nil, p.unexpected("an operation or fragment")
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, fmt.Errorf("the document contains no operation")
+	}
+	return doc, nil
+}
+
+func (p *gqlParser) peek() gqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *gqlParser) next() gqlToken {
+	tok := p.tokens[p.pos]
+	if tok.Kind != gqlTokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *gqlParser) unexpected(expected string) error {
+	tok := p.peek()
+	return fmt.Errorf("syntax error at position %d: expected %s, found %s", tok.Pos, expected, tok)
+}
+
+// isPunct reports whether the next token is the punctuator value.
+func (p *gqlParser) isPunct(value string) bool {
+	tok := p.peek()
+	return tok.Kind == gqlTokenPunct && tok.Value == value
+}
+
+func (p *gqlParser) expectPunct(value string) error {
+	if !p.isPunct(value) {
+		return p.unexpected(strconv.Quote(value))
+	}
+	p.next()
+	return nil
+}
+
+func (p *gqlParser) expectName() (string, error) {
+	if p.peek().Kind != gqlTokenName {
+		return "", p.unexpected("a name")
+	}
+	return p.next().Value, nil
+}
+
+func (p *gqlParser) parseOperation() (*gqlOperation, error) {
+	op := &gqlOperation{Kind: p.next().Value}
+	if p.peek().Kind == gqlTokenName {
+		op.Name = p.next().Value
+	}
+	if p.isPunct("(") {
+		p.next()
+		for !p.isPunct(")") {
+			def, err := p.parseVariableDef()
+			if err != nil {
+				return nil, err
+			}
+			op.Variables = append(op.Variables, def)
+		}
+		p.next()
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	sels, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.Selections = sels
+	return op, nil
+}
+
+func (p *gqlParser) parseVariableDef() (gqlVariableDef, error) {
+	var def gqlVariableDef
+	if err := p.expectPunct("$"); err != nil {
+		return def, err
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return def, err
+	}
+	def.Name = name
+	if err := p.expectPunct(":"); err != nil {
+		return def, err
+	}
+	if def.Type, err = p.parseType(); err != nil {
+		return def, err
+	}
+	if p.isPunct("=") {
+		p.next()
+		if def.Default, err = p.parseValue(true); err != nil {
+			return def, err
+		}
+		def.HasDefault = true
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return def, err
+	}
+	return def, nil
+}
+
+// parseType reads a type reference such as [String!]! and returns it as
+// written.
+func (p *gqlParser) parseType() (string, error) {
+	var t string
+	if p.isPunct("[") {
+		p.next()
+		inner, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expectPunct("]"); err != nil {
+			return "", err
+		}
+		t = "[" + inner + "]"
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return "", err
+		}
+		t = name
+	}
+	if p.isPunct("!") {
+		p.next()
+		t += "!"
+	}
+	return t, nil
+}
+
+func (p *gqlParser) parseFragment() (*gqlFragment, error) {
+	p.next()
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, fmt.Errorf("syntax error: a fragment cannot be named \"on\"")
+	}
+	if tok := p.next(); tok.Kind != gqlTokenName || tok.Value != "on" {
+		p.pos--
+		return nil, p.unexpected(`"on"`)
+	}
+	typeCondition, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	sels, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &gqlFragment{Name: name, TypeCondition: typeCondition, Selections: sels}, nil
+}
+
+func (p *gqlParser) parseSelectionSet() ([]*gqlSelection, error) {
+	if err := p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+	var sels []*gqlSelection
+	for !p.isPunct("}") {
+		sel, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	p.next()
+	if len(sels) == 0 {
+		return nil, fmt.Errorf("syntax error: empty selection set")
+	}
+	return sels, nil
+}
+
+func (p *gqlParser) parseSelection() (*gqlSelection, error) {
+	if p.isPunct("...") {
+		return p.parseFragmentSelection()
+	}
+	sel := &gqlSelection{}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	sel.Name = name
+	if p.isPunct(":") {
+		p.next()
+		sel.Alias = name
+		if sel.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	if p.isPunct("(") {
+		if sel.Arguments, err = p.parseArguments(false); err != nil {
+			return nil, err
+		}
+	}
+	if sel.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if p.isPunct("{") {
+		if sel.Selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return sel, nil
+}
+
+// parseFragmentSelection reads a fragment spread (...Name) or an inline
+// fragment (... on Type { ... } or ... { ... }).
+func (p *gqlParser) parseFragmentSelection() (*gqlSelection, error) {
+	p.next()
+	sel := &gqlSelection{}
+	var err error
+	if tok := p.peek(); tok.Kind == gqlTokenName && tok.Value != "on" {
+		sel.Spread = p.next().Value
+		if sel.Directives, err = p.parseDirectives(); err != nil {
+			return nil, err
+		}
+		return sel, nil
+	}
+	sel.Inline = true
+	if tok := p.peek(); tok.Kind == gqlTokenName && tok.Value == "on" {
+		p.next()
+		if sel.TypeCondition, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	if sel.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if sel.Selections, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return sel, nil
+}
+
+func (p *gqlParser) parseArguments(constant bool) ([]gqlArgument, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	var args []gqlArgument
+	for !p.isPunct(")") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue(constant)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, gqlArgument{Name: name, Value: value})
+	}
+	p.next()
+	if len(args) == 0 {
+		return nil, fmt.Errorf("syntax error: empty argument list")
+	}
+	return args, nil
+}
+
+func (p *gqlParser) parseDirectives() ([]gqlDirective, error) {
+	var dirs []gqlDirective
+	for p.isPunct("@") {
+		p.next()
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		dir := gqlDirective{Name: name}
+		if p.isPunct("(") {
+			if dir.Arguments, err = p.parseArguments(false); err != nil {
+				return nil, err
+			}
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs, nil
+}
+
+// parseValue reads an input value. Variables are not allowed in constant
+// values such as variable defaults.
+func (p *gqlParser) parseValue(constant bool) (interface{}, error) {
+	tok := p.peek()
+	switch tok.Kind {
+	case gqlTokenPunct:
+		switch tok.Value {
+		case "$":
+			if constant {
+				return nil, p.unexpected("a constant value")
+			}
+			p.next()
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			return gqlVariable(name), nil
+		case "[":
+			p.next()
+			list := []interface{}{}
+			for !p.isPunct("]") {
+				if p.peek().Kind == gqlTokenEOF {
+					return nil, p.unexpected(`"]"`)
+				}
+				v, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			p.next()
+			return list, nil
+		case "{":
+			p.next()
+			obj := map[string]interface{}{}
+			for !p.isPunct("}") {
+				name, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expectPunct(":"); err != nil {
+					return nil, err
+				}
+				v, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				obj[name] = v
+			}
+			p.next()
+			return obj, nil
+		}
+	case gqlTokenInt:
+		p.next()
+		n, err := strconv.ParseInt(tok.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("syntax error at position %d: invalid integer %s", tok.Pos, tok.Value)
+		}
+		return n, nil
+	case gqlTokenFloat:
+		p.next()
+		f, err := strconv.ParseFloat(tok.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("syntax error at position %d: invalid number %s", tok.Pos, tok.Value)
+		}
+		return f, nil
+	case gqlTokenString:
+		p.next()
+		return tok.Value, nil
+	case gqlTokenName:
+		p.next()
+		switch tok.Value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return gqlEnum(tok.Value), nil
+	}
+	return nil, p.unexpected("a value")
+}
@@ -0,0 +1,117 @@
+package fhir
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGraphQLDocument_Operation(t *testing.T) {
+	doc, err := parseGraphQLDocument(`
+		# Patients and their names.
+		query Patients($name: String = "Smith", $count: Int!) {
+			people: PatientList(name: $name, _count: $count, gender: [male, female]) @include(if: true) {
+				id
+				...Names
+				... on Patient { birthDate }
+			}
+		}
+		fragment Names on Patient { name { family given } }
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	op, err := doc.operation("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if op.Kind != "query" || op.Name != "Patients" {
+		t.Errorf("operation = %s %s", op.Kind, op.Name)
+	}
+	if len(op.Variables) != 2 || op.Variables[0].Default != "Smith" || !op.Variables[0].HasDefault || op.Variables[1].Type != "Int!" {
+		t.Errorf("unexpected variables: %+v", op.Variables)
+	}
+
+	field := op.Selections[0]
+	if field.Alias != "people" || field.Name != "PatientList" {
+		t.Errorf("field = %s: %s", field.Alias, field.Name)
+	}
+	if v, _ := field.argument("name"); v != gqlVariable("name") {
+		t.Errorf("name argument = %#v", v)
+	}
+	if v, _ := field.argument("gender"); len(v.([]interface{})) != 2 || v.([]interface{})[0] != gqlEnum("male") {
+		t.Errorf("gender argument = %#v", v)
+	}
+	if len(field.Directives) != 1 || field.Directives[0].Name != "include" || field.Directives[0].Arguments[0].Value != true {
+		t.Errorf("unexpected directives: %+v", field.Directives)
+	}
+	if len(field.Selections) != 3 || field.Selections[1].Spread != "Names" || !field.Selections[2].Inline || field.Selections[2].TypeCondition != "Patient" {
+		t.Errorf("unexpected selections: %+v", field.Selections)
+	}
+	if frag := doc.Fragments["Names"]; frag == nil || frag.TypeCondition != "Patient" || len(frag.Selections[0].Selections) != 2 {
+		t.Errorf("unexpected fragment: %+v", doc.Fragments["Names"])
+	}
+}
+
+func TestParseGraphQLDocument_Values(t *testing.T) {
+	doc, err := parseGraphQLDocument(`mutation {
+		PatientCreate(res: {active: true, multipleBirthInteger: -2, weight: 7.5e1, deceased: null,
+			note: "line\n\"quoted\" é", text: """
+				block
+				  text
+			"""}) { id }
+	}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, _ := doc.Operations[0].Selections[0].argument("res")
+	obj := res.(map[string]interface{})
+	want := map[string]interface{}{
+		"active":               true,
+		"multipleBirthInteger": int64(-2),
+		"weight":               75.0,
+		"deceased":             nil,
+		"note":                 "line\n\"quoted\" é",
+		"text":                 "block\n  text",
+	}
+	for k, v := range want {
+		if obj[k] != v {
+			t.Errorf("%s = %#v, want %#v", k, obj[k], v)
+		}
+	}
+}
+
+func TestParseGraphQLDocument_Errors(t *testing.T) {
+	tests := []struct {
+		query, want string
+	}{
+		{``, "empty query"},
+		{`{ Patient(id: "1") { id }`, `expected a name, found end of query`},
+		{`{ Patient(id: "1) { id } }`, "unterminated string"},
+		{`{ Patient { } }`, "empty selection set"},
+		{`{ Patient(id: ) { id } }`, "expected a value"},
+		{`query ($id: ID = $other) { Patient(id: $id) { id } }`, "expected a constant value"},
+		{`fragment F on Patient { id }`, "no operation"},
+		{`{ Patient % }`, "unexpected character"},
+	}
+	for _, tt := range tests {
+		if _, err := parseGraphQLDocument(tt.query); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.query, tt.want, err)
+		}
+	}
+}
+
+func TestGQLDocument_OperationByName(t *testing.T) {
+	doc, err := parseGraphQLDocument(`query A { Patient(id: "1") { id } } query B { Patient(id: "2") { id } }`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := doc.operation(""); err == nil {
+		t.Error("expected an error without operationName")
+	}
+	if op, err := doc.operation("B"); err != nil || op.Name != "B" {
+		t.Errorf("operation(B) = %v, %v", op, err)
+	}
+	if _, err := doc.operation("C"); err == nil {
+		t.Error("expected an error for an unknown operation")
+	}
+}
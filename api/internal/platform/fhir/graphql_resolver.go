@@ -0,0 +1,155 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// RegistryGraphQLResolver resolves the GraphQL engine's reads and searches
+// through a ResourceRegistry and dispatches its mutations as create, update
+// and delete requests through an EntryDispatcher. Either way the request is
+// served by the domain handlers and services, with the caller's context, so
+// their validation and role checks apply as they do over REST.
+type RegistryGraphQLResolver struct {
+	registry   *ResourceRegistry
+	dispatcher EntryDispatcher
+}
+
+// NewRegistryGraphQLResolver creates a resolver reading from registry and
+// writing through dispatcher.
+func NewRegistryGraphQLResolver(registry *ResourceRegistry, dispatcher EntryDispatcher) *RegistryGraphQLResolver {
+	return &RegistryGraphQLResolver{registry: registry, dispatcher: dispatcher}
+}
+
+// ResolveByID reads resourceType/id.
+func (r *RegistryGraphQLResolver) ResolveByID(ctx context.Context, resourceType, id string) (map[string]interface{}, error) {
+	return r.registry.Read(ctx, resourceType, id)
+}
+
+// ResolveSearch runs a search on resourceType, returning up to limit
+// matches.
+func (r *RegistryGraphQLResolver) ResolveSearch(ctx context.Context, resourceType string, params map[string]string, limit int) ([]map[string]interface{}, error) {
+	results, err := r.registry.Search(ctx, resourceType, graphqlSearchValues(params, limit))
+	if err != nil {
+		return nil, err
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// ResolvePage runs a search on resourceType through the search route and
+// returns up to count matches with the total of the search Bundle, or -1
+// when the Bundle does not report it.
+func (r *RegistryGraphQLResolver) ResolvePage(ctx context.Context, resourceType string, params map[string]string, count int) ([]map[string]interface{}, int, error) {
+	target := resourceType + "?" + graphqlSearchValues(params, count).Encode()
+	result, err := dispatchGet(ctx, r.dispatcher, target)
+	if err != nil {
+		return nil, 0, err
+	}
+	var bundle Bundle
+	if err := json.Unmarshal(result.Body, &bundle); err != nil {
+		return nil, 0, fmt.Errorf("search %s did not return a Bundle: %w", resourceType, err)
+	}
+	resources := make([]map[string]interface{}, 0, len(bundle.Entry))
+	for _, e := range bundle.Entry {
+		if e.Search != nil && e.Search.Mode != "" && e.Search.Mode != "match" {
+			continue
+		}
+		var res map[string]interface{}
+		if err := json.Unmarshal(e.Resource, &res); err != nil || res == nil {
+			continue
+		}
+		if len(resources) < count {
+			resources = append(resources, res)
+		}
+	}
+	total := -1
+	if bundle.Total != nil {
+		total = *bundle.Total
+	}
+	return resources, total, nil
+}
+
+// Create posts resource to the type's create route.
+func (r *RegistryGraphQLResolver) Create(ctx context.Context, resourceType string, resource map[string]interface{}) (map[string]interface{}, error) {
+	return r.write(ctx, http.MethodPost, resourceType, resource)
+}
+
+// Update puts resource to the instance's update route.
+func (r *RegistryGraphQLResolver) Update(ctx context.Context, resourceType, id string, resource map[string]interface{}) (map[string]interface{}, error) {
+	return r.write(ctx, http.MethodPut, resourceType+"/"+url.PathEscape(id), resource)
+}
+
+// Delete reads resourceType/id, deletes it through the instance's delete
+// route and returns the resource as it was read.
+func (r *RegistryGraphQLResolver) Delete(ctx context.Context, resourceType, id string) (map[string]interface{}, error) {
+	res, err := r.registry.Read(ctx, resourceType, id)
+	if err != nil {
+		return nil, err
+	}
+	target := resourceType + "/" + url.PathEscape(id)
+	result, err := r.dispatcher.Dispatch(ctx, &EntryDispatchRequest{Method: http.MethodDelete, URL: target})
+	if err != nil {
+		return nil, err
+	}
+	if err := dispatchResultError(http.MethodDelete, target, result); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// write sends resource with method to target and returns the resource the
+// handler responds with. Handlers that respond without a body are followed
+// up with a read of the Location header.
+func (r *RegistryGraphQLResolver) write(ctx context.Context, method, target string, resource map[string]interface{}) (map[string]interface{}, error) {
+	body, err := json.Marshal(resource)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %w", target, err)
+	}
+	result, err := r.dispatcher.Dispatch(ctx, &EntryDispatchRequest{
+		Method: method, URL: target, Body: body, ContentType: "application/fhir+json",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := dispatchResultError(method, target, result); err != nil {
+		return nil, err
+	}
+	if res := result.Resource(); res != nil {
+		return res, nil
+	}
+	if loc := result.Header.Get("Location"); loc != "" {
+		return r.registry.ResolveReference(ctx, loc)
+	}
+	return nil, nil
+}
+
+// dispatchResultError converts an error response to an error carrying the
+// diagnostics of its OperationOutcome.
+func dispatchResultError(method, target string, result *EntryDispatchResult) error {
+	if result.StatusCode < 400 {
+		return nil
+	}
+	msg := http.StatusText(result.StatusCode)
+	if oo := outcomeFromResult(result); len(oo.Issue) > 0 && oo.Issue[0].Diagnostics != "" {
+		msg = oo.Issue[0].Diagnostics
+	}
+	return fmt.Errorf("%s %s: %d %s", method, target, result.StatusCode, msg)
+}
+
+// graphqlSearchValues converts the engine's search parameters to query
+// values with _count set to count.
+func graphqlSearchValues(params map[string]string, count int) url.Values {
+	values := make(url.Values, len(params)+1)
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	values.Set("_count", strconv.Itoa(count))
+	return values
+}
@@ -0,0 +1,95 @@
+package fhir
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func newRegistryGraphQLEngine() (*GraphQLEngine, map[string]map[string]interface{}) {
+	e, store := newDispatchTestServer()
+	dispatcher := NewEchoEntryDispatcher(e, "/fhir")
+	reg := NewResourceRegistry()
+	reg.SetDispatcher(dispatcher)
+	registerDispatchTestResources(reg)
+	resolver := NewRegistryGraphQLResolver(reg, dispatcher)
+	engine := NewGraphQLEngine()
+	for _, rt := range reg.ResourceTypes() {
+		engine.RegisterResolver(rt, resolver)
+	}
+	return engine, store
+}
+
+func TestRegistryGraphQLResolver_Mutations(t *testing.T) {
+	engine, store := newRegistryGraphQLEngine()
+	ctx := context.Background()
+
+	resp := engine.Execute(ctx, GraphQLRequest{Query: `mutation {
+		PatientCreate(res: {gender: female}) { id gender meta { versionId } }
+	}`})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", resp.Errors)
+	}
+	created := resp.Data.(map[string]interface{})["PatientCreate"].(map[string]interface{})
+	id, _ := created["id"].(string)
+	if id == "" || store["Patient/"+id] == nil {
+		t.Fatalf("expected the Patient to be stored, got %v", created)
+	}
+
+	resp = engine.Execute(ctx, GraphQLRequest{
+		Query:     `mutation ($id: ID!) { PatientUpdate(id: $id, res: {gender: male}) { gender meta { versionId } } }`,
+		Variables: map[string]interface{}{"id": id},
+	})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", resp.Errors)
+	}
+	updated := resp.Data.(map[string]interface{})["PatientUpdate"].(map[string]interface{})
+	if updated["gender"] != "male" || updated["meta"].(map[string]interface{})["versionId"] != "2" {
+		t.Errorf("unexpected update: %v", updated)
+	}
+
+	// The handler's validation is reported as a GraphQL error.
+	resp = engine.Execute(ctx, GraphQLRequest{Query: `mutation { ObservationCreate(res: {status: final}) { id } }`})
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "subject must reference a Patient") {
+		t.Errorf("expected the handler's validation error, got %v", resp.Errors)
+	}
+
+	resp = engine.Execute(ctx, GraphQLRequest{Query: `mutation { PatientDelete(id: "` + id + `") { gender } }`})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", resp.Errors)
+	}
+	if _, ok := store["Patient/"+id]; ok {
+		t.Error("expected the Patient to be deleted")
+	}
+}
+
+func TestRegistryGraphQLResolver_Queries(t *testing.T) {
+	engine, store := newRegistryGraphQLEngine()
+	store["Patient/p1"] = map[string]interface{}{"resourceType": "Patient", "id": "p1", "gender": "female"}
+	store["Patient/p2"] = map[string]interface{}{"resourceType": "Patient", "id": "p2", "gender": "male"}
+	store["Observation/o1"] = map[string]interface{}{
+		"resourceType": "Observation", "id": "o1", "subject": map[string]interface{}{"reference": "Patient/p1"},
+	}
+
+	resp := engine.Execute(context.Background(), GraphQLRequest{Query: `{
+		Observation(id: "o1") { subject { resource { ... on Patient { gender } } } }
+		PatientList(_id: "p2") { id }
+		PatientConnection(_count: 5) { count edges { resource { id } } }
+		missing: Patient(id: "nope") { id }
+	}`})
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "not found") {
+		t.Errorf("expected a single not found error, got %v", resp.Errors)
+	}
+	data := resp.Data.(map[string]interface{})
+	subject := data["Observation"].(map[string]interface{})["subject"].(map[string]interface{})
+	if subject["resource"].(map[string]interface{})["gender"] != "female" {
+		t.Errorf("unexpected subject: %v", subject)
+	}
+	if list := data["PatientList"].([]interface{}); len(list) != 1 || list[0].(map[string]interface{})["id"] != "p2" {
+		t.Errorf("unexpected PatientList: %v", list)
+	}
+	conn := data["PatientConnection"].(map[string]interface{})
+	if conn["count"] != 2 || len(conn["edges"].([]interface{})) != 2 {
+		t.Errorf("unexpected PatientConnection: %v", conn)
+	}
+}
@@ -0,0 +1,418 @@
+package fhir
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// gqlSchema holds the introspection types (__Type objects) describing the
+// resource types the engine can resolve. It is built from the structural
+// layouts of the resources and datatypes (see structure_definition_types.go)
+// and the search tables of the request's SearchServices, so that
+// GraphiQL-style tooling can explore and validate queries.
+type gqlSchema struct {
+	types    map[string]map[string]interface{}
+	names    []string
+	mutation bool
+	tables   *ChainRegistry
+}
+
+// gqlScalars are the built-in scalar types, plus ResourceInput, which holds
+// the FHIR JSON of a resource passed to a create or update mutation.
+var gqlScalars = []string{"String", "Int", "Float", "Boolean", "ID", "ResourceInput"}
+
+// gqlNamePattern matches valid GraphQL names.
+var gqlNamePattern = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// schema builds the introspection schema for the registered resolvers.
+func (e *GraphQLEngine) schema(ctx context.Context) *gqlSchema {
+	e.mu.RLock()
+	types := make([]string, 0, len(e.resolvers))
+	mutable := make(map[string]bool)
+	for rt, r := range e.resolvers {
+		types = append(types, rt)
+		if _, ok := r.(GraphQLMutationResolver); ok {
+			mutable[rt] = true
+		}
+	}
+	e.mu.RUnlock()
+	sort.Strings(types)
+	mutation := len(mutable) > 0
+
+	s := &gqlSchema{
+		types:    make(map[string]map[string]interface{}),
+		mutation: mutation,
+		tables:   SearchServicesFromContext(ctx).Chains,
+	}
+	for _, name := range gqlScalars {
+		s.add(gqlTypeObject("SCALAR", name))
+	}
+	union := gqlTypeObject("UNION", "Resource")
+	possible := make([]interface{}, 0, len(types))
+	for _, rt := range types {
+		possible = append(possible, gqlNamedRef("OBJECT", rt))
+	}
+	union["possibleTypes"] = possible
+	s.add(union)
+
+	query := gqlTypeObject("OBJECT", "Query")
+	var queryFields []interface{}
+	for _, rt := range types {
+		s.addResource(rt, types)
+		queryFields = append(queryFields,
+			gqlField(rt, s.ref(rt), gqlInput("id", gqlNonNullRef(s.ref("ID")))),
+			gqlField(rt+"List", gqlListRef(s.ref(rt)), s.searchArgs(rt, "_offset")...),
+			gqlField(rt+"Connection", s.ref(s.addConnection(rt)), s.searchArgs(rt, "_cursor")...),
+		)
+	}
+	query["fields"] = nonNilList(queryFields)
+	s.add(query)
+
+	if mutation {
+		m := gqlTypeObject("OBJECT", "Mutation")
+		var fields []interface{}
+		for _, rt := range types {
+			if !mutable[rt] {
+				continue
+			}
+			res := gqlInput("res", gqlNonNullRef(s.ref("ResourceInput")))
+			id := gqlInput("id", gqlNonNullRef(s.ref("ID")))
+			fields = append(fields,
+				gqlField(rt+"Create", s.ref(rt), res),
+				gqlField(rt+"Update", s.ref(rt), id, res),
+				gqlField(rt+"Delete", s.ref(rt), id),
+			)
+		}
+		m["fields"] = nonNilList(fields)
+		s.add(m)
+	}
+	return s
+}
+
+// schemaObject returns the __Schema object.
+func (s *gqlSchema) schemaObject() map[string]interface{} {
+	types := make([]interface{}, 0, len(s.names))
+	for _, name := range s.names {
+		types = append(types, s.types[name])
+	}
+	var mutationType interface{}
+	if s.mutation {
+		mutationType = s.types["Mutation"]
+	}
+	condition := gqlInput("if", gqlNonNullRef(gqlNamedRef("SCALAR", "Boolean")))
+	return map[string]interface{}{
+		"description":      nil,
+		"queryType":        s.types["Query"],
+		"mutationType":     mutationType,
+		"subscriptionType": nil,
+		"types":            types,
+		"directives": []interface{}{
+			gqlDirectiveObject("skip", condition),
+			gqlDirectiveObject("include", condition),
+		},
+	}
+}
+
+func (s *gqlSchema) add(t map[string]interface{}) {
+	name := t["name"].(string)
+	if _, ok := s.types[name]; ok {
+		return
+	}
+	s.types[name] = t
+	s.names = append(s.names, name)
+}
+
+// ref returns a reference to the named type, whose kind follows from its
+// name: scalars, the Resource union, or objects.
+func (s *gqlSchema) ref(name string) map[string]interface{} {
+	for _, scalar := range gqlScalars {
+		if name == scalar {
+			return gqlNamedRef("SCALAR", name)
+		}
+	}
+	if name == "Resource" {
+		return gqlNamedRef("UNION", name)
+	}
+	return gqlNamedRef("OBJECT", name)
+}
+
+// addResource adds the object type of a resource: the elements every
+// resource has, the elements of its layout and, for the focal resources of
+// compartments, XxxList and XxxConnection fields searching the members of
+// the compartment (the _reference argument names the search parameter).
+func (s *gqlSchema) addResource(resourceType string, types []string) {
+	if _, ok := s.types[resourceType]; ok {
+		return
+	}
+	t := gqlTypeObject("OBJECT", resourceType)
+	s.add(t)
+	fields := []interface{}{
+		gqlField("resourceType", s.ref("String")),
+		gqlField("id", s.ref("ID")),
+		gqlField("meta", s.ref(s.addDataType("Meta"))),
+		gqlField("implicitRules", s.ref("String")),
+		gqlField("language", s.ref("String")),
+		gqlField("text", s.ref(s.addDataType("Narrative"))),
+		gqlField("contained", gqlListRef(s.ref("Resource"))),
+		gqlField("extension", gqlListRef(s.ref(s.addDataType("Extension")))),
+		gqlField("modifierExtension", gqlListRef(s.ref(s.addDataType("Extension")))),
+	}
+	rows, ok := resourceLayouts[resourceType]
+	if !ok {
+		rows = infrastructureLayouts[resourceType]
+	}
+	seen := make(map[string]bool)
+	for _, f := range fields {
+		seen[f.(map[string]interface{})["name"].(string)] = true
+	}
+	for _, f := range s.layoutFields(resourceType, "", rows) {
+		if name := f.(map[string]interface{})["name"].(string); !seen[name] {
+			seen[name] = true
+			fields = append(fields, f)
+		}
+	}
+	if GetCompartmentDefinitionByCode(resourceType) != nil {
+		for _, member := range types {
+			if len(CompartmentMembershipParams(resourceType, member)) == 0 {
+				continue
+			}
+			ref := gqlInput("_reference", gqlNonNullRef(s.ref("String")))
+			fields = append(fields,
+				gqlField(member+"List", gqlListRef(s.ref(member)), append([]interface{}{ref}, s.searchArgs(member, "_offset")...)...),
+				gqlField(member+"Connection", s.ref(s.addConnection(member)), append([]interface{}{ref}, s.searchArgs(member, "_cursor")...)...),
+			)
+		}
+	}
+	t["fields"] = fields
+}
+
+// addDataType adds the object type of a complex datatype and returns its
+// name. Datatypes without a layout only carry id and extension.
+func (s *gqlSchema) addDataType(name string) string {
+	if _, ok := s.types[name]; ok {
+		return name
+	}
+	t := gqlTypeObject("OBJECT", name)
+	s.add(t)
+	fields := []interface{}{
+		gqlField("id", s.ref("String")),
+		gqlField("extension", gqlListRef(s.ref(s.addDataType("Extension")))),
+	}
+	fields = append(fields, s.layoutFields(name, "", dataTypeLayouts[name])...)
+	if name == "Reference" {
+		fields = append(fields, gqlField("resource", s.ref("Resource"),
+			gqlInput("type", s.ref("String")), gqlInput("optional", s.ref("Boolean"))))
+	}
+	t["fields"] = fields
+	return name
+}
+
+// layoutFields returns the fields of the element at prefix in the layout
+// rows of typeName. Backbone elements become object types named after
+// their path (Patient.contact is PatientContact) and choice elements one
+// field per type (deceased[x] is deceasedBoolean and deceasedDateTime).
+func (s *gqlSchema) layoutFields(typeName, prefix string, rows [][3]string) []interface{} {
+	var fields []interface{}
+	for _, row := range rows {
+		rel, ok := strings.CutPrefix(row[0], prefix)
+		if !ok || strings.Contains(rel, ".") {
+			continue
+		}
+		path := row[0]
+		wrap := func(ref map[string]interface{}) map[string]interface{} {
+			if row[1] == "*" {
+				return gqlListRef(ref)
+			}
+			return ref
+		}
+		if strings.HasPrefix(row[2], "#") {
+			target := strings.TrimPrefix(strings.TrimPrefix(row[2], "#"), typeName+".")
+			fields = append(fields, gqlField(rel, wrap(s.ref(backboneTypeName(typeName, target)))))
+			continue
+		}
+		codes := strings.Split(row[2], "|")
+		if base, ok := strings.CutSuffix(rel, "[x]"); ok {
+			for _, code := range codes {
+				fields = append(fields, gqlField(base+strings.ToUpper(code[:1])+code[1:], wrap(s.ref(s.elementType(code, typeName, path, rows)))))
+			}
+			continue
+		}
+		fields = append(fields, gqlField(rel, wrap(s.ref(s.elementType(codes[0], typeName, path, rows)))))
+	}
+	return fields
+}
+
+// elementType returns the GraphQL type of an element of type code.
+func (s *gqlSchema) elementType(code, typeName, path string, rows [][3]string) string {
+	switch code {
+	case "boolean":
+		return "Boolean"
+	case "integer", "positiveInt", "unsignedInt", "integer64":
+		return "Int"
+	case "decimal":
+		return "Float"
+	case "Resource":
+		return "Resource"
+	case "BackboneElement", "Element":
+		name := backboneTypeName(typeName, path)
+		if _, ok := s.types[name]; !ok {
+			t := gqlTypeObject("OBJECT", name)
+			s.add(t)
+			fields := []interface{}{
+				gqlField("id", s.ref("String")),
+				gqlField("extension", gqlListRef(s.ref(s.addDataType("Extension")))),
+			}
+			t["fields"] = append(fields, s.layoutFields(typeName, path+".", rows)...)
+		}
+		return name
+	}
+	if code == "" || code[0] < 'A' || code[0] > 'Z' {
+		return "String"
+	}
+	return s.addDataType(code)
+}
+
+// backboneTypeName names the type of a backbone element: the owning type
+// followed by the capitalised segments of the path.
+func backboneTypeName(owner, path string) string {
+	var b strings.Builder
+	b.WriteString(owner)
+	for _, seg := range strings.Split(path, ".") {
+		if seg != "" {
+			b.WriteString(strings.ToUpper(seg[:1]) + seg[1:])
+		}
+	}
+	return b.String()
+}
+
+// addConnection adds the XxxConnection and XxxEdge types of a resource
+// type and returns the connection type's name.
+func (s *gqlSchema) addConnection(resourceType string) string {
+	name := resourceType + "Connection"
+	if _, ok := s.types[name]; ok {
+		return name
+	}
+	edge := gqlTypeObject("OBJECT", resourceType+"Edge")
+	edge["fields"] = []interface{}{
+		gqlField("mode", s.ref("String")),
+		gqlField("score", s.ref("Float")),
+		gqlField("resource", s.ref(resourceType)),
+	}
+	s.add(edge)
+	conn := gqlTypeObject("OBJECT", name)
+	conn["fields"] = []interface{}{
+		gqlField("count", s.ref("Int")),
+		gqlField("offset", s.ref("Int")),
+		gqlField("pagesize", s.ref("Int")),
+		gqlField("first", s.ref("String")),
+		gqlField("previous", s.ref("String")),
+		gqlField("next", s.ref("String")),
+		gqlField("last", s.ref("String")),
+		gqlField("edges", gqlListRef(s.ref(resourceType+"Edge"))),
+	}
+	s.add(conn)
+	return name
+}
+
+// searchArgs returns the arguments of the List and Connection fields of a
+// resource type: its registered search parameters, _count and _sort, and
+// paging (_offset for lists, _cursor for connections).
+func (s *gqlSchema) searchArgs(resourceType, paging string) []interface{} {
+	args := []interface{}{
+		gqlInput("_count", s.ref("Int")),
+		gqlInput("_sort", s.ref("String")),
+	}
+	if paging == "_offset" {
+		args = append(args, gqlInput(paging, s.ref("Int")))
+	} else {
+		args = append(args, gqlInput(paging, s.ref("String")))
+	}
+	_, configs, _ := s.tables.tableConfigs(resourceType)
+	names := make([]string, 0, len(configs))
+	for param := range configs {
+		if name := strings.ReplaceAll(param, "-", "_"); gqlNamePattern.MatchString(name) && name != "_count" && name != "_sort" && name != paging {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, gqlInput(name, s.ref("String")))
+	}
+	return args
+}
+
+// gqlTypeObject returns a __Type object with every property the
+// introspection query asks for.
+func gqlTypeObject(kind, name string) map[string]interface{} {
+	t := map[string]interface{}{
+		"kind":           kind,
+		"name":           name,
+		"description":    nil,
+		"specifiedByURL": nil,
+		"fields":         nil,
+		"inputFields":    nil,
+		"interfaces":     nil,
+		"enumValues":     nil,
+		"possibleTypes":  nil,
+		"ofType":         nil,
+	}
+	if kind == "OBJECT" {
+		t["interfaces"] = []interface{}{}
+	}
+	return t
+}
+
+func gqlNamedRef(kind, name string) map[string]interface{} {
+	return map[string]interface{}{"kind": kind, "name": name, "ofType": nil}
+}
+
+func gqlListRef(of map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"kind": "LIST", "name": nil, "ofType": of}
+}
+
+func gqlNonNullRef(of map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"kind": "NON_NULL", "name": nil, "ofType": of}
+}
+
+// gqlField returns a __Field object.
+func gqlField(name string, typ map[string]interface{}, args ...interface{}) interface{} {
+	return map[string]interface{}{
+		"name":              name,
+		"description":       nil,
+		"args":              nonNilList(args),
+		"type":              typ,
+		"isDeprecated":      false,
+		"deprecationReason": nil,
+	}
+}
+
+// gqlInput returns an __InputValue object.
+func gqlInput(name string, typ map[string]interface{}) interface{} {
+	return map[string]interface{}{
+		"name":              name,
+		"description":       nil,
+		"type":              typ,
+		"defaultValue":      nil,
+		"isDeprecated":      false,
+		"deprecationReason": nil,
+	}
+}
+
+func gqlDirectiveObject(name string, args ...interface{}) interface{} {
+	return map[string]interface{}{
+		"name":         name,
+		"description":  nil,
+		"isRepeatable": false,
+		"locations":    []interface{}{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		"args":         nonNilList(args),
+	}
+}
+
+func nonNilList(items []interface{}) []interface{} {
+	if items == nil {
+		return []interface{}{}
+	}
+	return items
+}
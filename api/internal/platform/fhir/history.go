@@ -3,6 +3,7 @@ package fhir
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -91,6 +92,41 @@ func (r *HistoryRepository) GetVersion(ctx context.Context, resourceType, resour
 	return &h, nil
 }
 
+// UpdateCurrentMeta replaces the profiles, security labels and tags in the
+// snapshot of the current version of a resource, leaving its other meta
+// elements untouched. It returns ErrMetaResourceNotFound if the resource has
+// no versions or its current version is a deletion.
+func (r *HistoryRepository) UpdateCurrentMeta(ctx context.Context, resourceType, resourceID string, meta *Meta) (*HistoryEntry, error) {
+	q := r.conn(ctx)
+	if q == nil {
+		return nil, fmt.Errorf("no database connection in context")
+	}
+
+	data, err := json.Marshal(metaElements(meta))
+	if err != nil {
+		return nil, fmt.Errorf("marshal meta for history: %w", err)
+	}
+
+	var h HistoryEntry
+	err = q.QueryRow(ctx, `
+		UPDATE resource_history
+		SET resource = jsonb_set(resource, '{meta}',
+			(COALESCE(resource->'meta', '{}'::jsonb) - 'profile' - 'security' - 'tag') || $3::jsonb)
+		WHERE resource_type = $1 AND resource_id = $2 AND action <> 'delete'
+			AND version_id = (SELECT MAX(version_id) FROM resource_history
+				WHERE resource_type = $1 AND resource_id = $2)
+		RETURNING resource_type, resource_id, version_id, resource, action, timestamp`,
+		resourceType, resourceID, data).
+		Scan(&h.ResourceType, &h.ResourceID, &h.VersionID, &h.Resource, &h.Action, &h.Timestamp)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrMetaResourceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("update history meta: %w", err)
+	}
+	return &h, nil
+}
+
 // ListVersions retrieves all versions of a resource, ordered by version descending.
 func (r *HistoryRepository) ListVersions(ctx context.Context, resourceType, resourceID string, limit, offset int) ([]*HistoryEntry, int, error) {
 	q := r.conn(ctx)
@@ -2,11 +2,18 @@ package fhir
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/auth"
+	"github.com/ehr/ehr/internal/platform/db"
 )
 
 // DefaultIdempotencyTTL is the default time-to-live for cached idempotency
@@ -14,18 +21,38 @@ import (
 // of write operations that may fail due to transient network issues.
 const DefaultIdempotencyTTL = 24 * time.Hour
 
+// idempotencyLockTTL bounds how long a key stays locked by a request that
+// never completes, for example because its replica crashed.
+const idempotencyLockTTL = 5 * time.Minute
+
+// idempotencyLockWait is how long a duplicate request waits for the request
+// holding its key to complete before it is answered with 409 Conflict.
+var idempotencyLockWait = 5 * time.Second
+
+// idempotencyPollInterval is how often a waiting duplicate checks the store.
+const idempotencyPollInterval = 100 * time.Millisecond
+
 // IdempotencyKey represents a cached response for an idempotent request.
 // When a client retries a write operation with the same idempotency key,
 // the server returns the cached response instead of re-executing the request.
+//
+// Keys are scoped to a tenant and client. RequestHash is the SHA-256 of the
+// request body, so that reusing a key for a different payload is detected.
+// While the first request carrying a key executes, its entry is InProgress
+// and holds no response.
 type IdempotencyKey struct {
-	Key        string
-	Method     string
-	Path       string
-	StatusCode int
-	Headers    http.Header
-	Body       []byte
-	CreatedAt  time.Time
-	ExpiresAt  time.Time
+	Key         string
+	TenantID    string
+	ClientID    string
+	Method      string
+	Path        string
+	RequestHash string
+	InProgress  bool
+	StatusCode  int
+	Headers     http.Header
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
 }
 
 // IdempotencyStore defines the persistence interface for idempotency key
@@ -40,6 +67,25 @@ type IdempotencyStore interface {
 	Delete(key string)
 }
 
+// IdempotencyLocker is implemented by stores that lock a key while the first
+// request carrying it executes, so that concurrent duplicates, possibly served
+// by other replicas, are not executed twice. Entries are identified by their
+// TenantID, ClientID and Key. IdempotencyMiddleware uses these methods when
+// the store implements them.
+type IdempotencyLocker interface {
+	// Acquire stores entry as in progress unless an unexpired entry exists
+	// for the same tenant, client and key, in which case that entry is
+	// returned and nothing is stored.
+	Acquire(ctx context.Context, entry *IdempotencyKey) (*IdempotencyKey, error)
+	// Lookup returns the unexpired entry for a tenant, client and key, or nil.
+	Lookup(ctx context.Context, tenantID, clientID, key string) (*IdempotencyKey, error)
+	// Complete stores the response of an acquired entry and unlocks it.
+	Complete(ctx context.Context, entry *IdempotencyKey) error
+	// Release removes an acquired entry whose request failed, so that the
+	// client can retry it.
+	Release(ctx context.Context, tenantID, clientID, key string) error
+}
+
 // InMemoryIdempotencyStore is a concurrency-safe, in-memory implementation
 // of IdempotencyStore with TTL-based expiration and background cleanup.
 type InMemoryIdempotencyStore struct {
@@ -149,22 +195,123 @@ func (s *InMemoryIdempotencyStore) Delete(key string) {
 	delete(s.entries, key)
 }
 
+// idempotencyMapKey returns the in-memory key of an entry. Unscoped entries
+// use the bare key, as with Get and Set.
+func idempotencyMapKey(tenantID, clientID, key string) string {
+	if tenantID == "" && clientID == "" {
+		return key
+	}
+	return tenantID + "\x00" + clientID + "\x00" + key
+}
+
+// Acquire implements IdempotencyLocker.
+func (s *InMemoryIdempotencyStore) Acquire(_ context.Context, entry *IdempotencyKey) (*IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := idempotencyMapKey(entry.TenantID, entry.ClientID, entry.Key)
+	now := s.nowFunc()
+	if existing, ok := s.entries[k]; ok && !now.After(existing.ExpiresAt) {
+		cp := *existing
+		cp.Headers = existing.Headers.Clone()
+		cp.Body = append([]byte(nil), existing.Body...)
+		return &cp, nil
+	}
+	cp := *entry
+	cp.InProgress = true
+	cp.CreatedAt = now
+	cp.ExpiresAt = now.Add(idempotencyLockTTL)
+	s.entries[k] = &cp
+	return nil, nil
+}
+
+// Lookup implements IdempotencyLocker.
+func (s *InMemoryIdempotencyStore) Lookup(_ context.Context, tenantID, clientID, key string) (*IdempotencyKey, error) {
+	entry, ok := s.Get(idempotencyMapKey(tenantID, clientID, key))
+	if !ok {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// Complete implements IdempotencyLocker.
+func (s *InMemoryIdempotencyStore) Complete(_ context.Context, entry *IdempotencyKey) error {
+	cp := *entry
+	cp.InProgress = false
+	cp.CreatedAt = s.nowFunc()
+	cp.ExpiresAt = time.Time{}
+	s.Set(idempotencyMapKey(entry.TenantID, entry.ClientID, entry.Key), &cp)
+	return nil
+}
+
+// Release implements IdempotencyLocker.
+func (s *InMemoryIdempotencyStore) Release(_ context.Context, tenantID, clientID, key string) error {
+	s.Delete(idempotencyMapKey(tenantID, clientID, key))
+	return nil
+}
+
+// storeLocker adapts an IdempotencyStore without locking support to the
+// IdempotencyLocker interface. Acquire is not atomic, so concurrent
+// duplicates may both execute.
+type storeLocker struct {
+	store IdempotencyStore
+}
+
+func (l storeLocker) Acquire(_ context.Context, entry *IdempotencyKey) (*IdempotencyKey, error) {
+	k := idempotencyMapKey(entry.TenantID, entry.ClientID, entry.Key)
+	if existing, ok := l.store.Get(k); ok {
+		return existing, nil
+	}
+	cp := *entry
+	cp.InProgress = true
+	l.store.Set(k, &cp)
+	return nil, nil
+}
+
+func (l storeLocker) Lookup(_ context.Context, tenantID, clientID, key string) (*IdempotencyKey, error) {
+	entry, ok := l.store.Get(idempotencyMapKey(tenantID, clientID, key))
+	if !ok {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+func (l storeLocker) Complete(_ context.Context, entry *IdempotencyKey) error {
+	cp := *entry
+	cp.InProgress = false
+	l.store.Set(idempotencyMapKey(entry.TenantID, entry.ClientID, entry.Key), &cp)
+	return nil
+}
+
+func (l storeLocker) Release(_ context.Context, tenantID, clientID, key string) error {
+	l.store.Delete(idempotencyMapKey(tenantID, clientID, key))
+	return nil
+}
+
 // IdempotencyMiddleware returns an Echo middleware that implements idempotency
 // key support for FHIR write operations. It reads the Idempotency-Key header
 // (standard) or X-Idempotency-Key header (legacy) from incoming POST, PUT, and
-// PATCH requests.
+// PATCH requests. Keys are scoped to the request's tenant and client.
 //
 // Behaviour:
 //   - GET and DELETE requests are passed through without inspection.
 //   - If no idempotency key header is present, the request is passed through.
-//   - If a key is present and a cached response exists:
-//   - If the cached method+path do not match, 422 Unprocessable Entity is
-//     returned to prevent key reuse across different operations.
+//   - If a key is present and an entry exists for it:
+//   - If the entry's method+path do not match, 422 Unprocessable Entity is
+//     returned to prevent key reuse across different operations; the same
+//     applies when the request body differs.
+//   - If the first request with the key is still executing, the duplicate
+//     waits for it to complete, and gets 409 Conflict if it does not in time.
 //   - Otherwise the cached response (status, headers, body) is replayed and
 //     the X-Idempotency-Replayed header is set to "true".
-//   - If a key is present but no cache entry exists, the request is executed,
-//     the response is captured and cached, and then returned normally.
+//   - If a key is present but no entry exists, the key is locked, the request
+//     is executed, and the response is captured, cached and returned. If the
+//     handler fails or answers with a server error (5xx), nothing is cached
+//     and the lock is released so the request can be retried.
 func IdempotencyMiddleware(store IdempotencyStore) echo.MiddlewareFunc {
+	locker, ok := store.(IdempotencyLocker)
+	if !ok {
+		locker = storeLocker{store: store}
+	}
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			method := c.Request().Method
@@ -182,32 +329,29 @@ func IdempotencyMiddleware(store IdempotencyStore) echo.MiddlewareFunc {
 				return next(c)
 			}
 
-			path := c.Request().URL.Path
-
-			// Check for a cached response.
-			if cached, ok := store.Get(idempKey); ok {
-				// Verify that the cached entry matches the current method and path.
-				if cached.Method != method || cached.Path != path {
-					return c.JSON(http.StatusUnprocessableEntity, NewOperationOutcome(
-						IssueSeverityError,
-						IssueTypeProcessing,
-						"Idempotency key was already used for a different operation",
-					))
-				}
-				// Replay the cached response.
-				resp := c.Response()
-				for k, vals := range cached.Headers {
-					for _, v := range vals {
-						resp.Header().Set(k, v)
-					}
-				}
-				resp.Header().Set("X-Idempotency-Replayed", "true")
-				resp.WriteHeader(cached.StatusCode)
-				_, err := resp.Write(cached.Body)
-				return err
+			ctx := c.Request().Context()
+			requestHash, err := hashRequestBody(c.Request())
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorOutcome("failed to read request body"))
+			}
+			entry := &IdempotencyKey{
+				Key:         idempKey,
+				TenantID:    db.TenantFromContext(ctx),
+				ClientID:    idempotencyClientID(c),
+				Method:      method,
+				Path:        c.Request().URL.Path,
+				RequestHash: requestHash,
 			}
 
-			// No cached response: execute the handler and capture the response.
+			cached, err := locker.Acquire(ctx, entry)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
+			}
+			if cached != nil {
+				return replayIdempotentResponse(c, locker, entry, cached)
+			}
+
+			// The key is ours: execute the handler and capture the response.
 			origWriter := c.Response().Writer
 			rec := &idempotencyRecorder{
 				ResponseWriter: origWriter,
@@ -219,6 +363,7 @@ func IdempotencyMiddleware(store IdempotencyStore) echo.MiddlewareFunc {
 
 			if err := next(c); err != nil {
 				c.Response().Writer = origWriter
+				_ = locker.Release(context.WithoutCancel(ctx), entry.TenantID, entry.ClientID, entry.Key)
 				return err
 			}
 
@@ -231,30 +376,118 @@ func IdempotencyMiddleware(store IdempotencyStore) echo.MiddlewareFunc {
 				capturedHeaders[k] = vals
 			}
 
-			// Cache the response.
-			entry := &IdempotencyKey{
-				Key:        idempKey,
-				Method:     method,
-				Path:       path,
-				StatusCode: rec.statusCode,
-				Headers:    capturedHeaders,
-				Body:       rec.body.Bytes(),
+			// Cache final responses. Server errors are not final: the key is
+			// released so that a retry executes the request again.
+			entry.StatusCode = rec.statusCode
+			entry.Headers = capturedHeaders
+			entry.Body = rec.body.Bytes()
+			if rec.statusCode >= http.StatusInternalServerError {
+				_ = locker.Release(context.WithoutCancel(ctx), entry.TenantID, entry.ClientID, entry.Key)
+			} else if err := locker.Complete(context.WithoutCancel(ctx), entry); err != nil {
+				_ = locker.Release(context.WithoutCancel(ctx), entry.TenantID, entry.ClientID, entry.Key)
 			}
-			store.Set(idempKey, entry)
 
 			// Write the captured response to the real client.
-			for k, vals := range capturedHeaders {
-				for _, v := range vals {
-					origWriter.Header().Set(k, v)
-				}
-			}
-			origWriter.WriteHeader(rec.statusCode)
-			_, err := origWriter.Write(rec.body.Bytes())
-			return err
+			return writeIdempotentResponse(origWriter, capturedHeaders, rec.statusCode, rec.body.Bytes())
 		}
 	}
 }
 
+// replayIdempotentResponse answers a request whose key already has an entry.
+func replayIdempotentResponse(c echo.Context, locker IdempotencyLocker, entry, cached *IdempotencyKey) error {
+	// Verify that the cached entry matches the current method and path.
+	if cached.Method != entry.Method || cached.Path != entry.Path {
+		return c.JSON(http.StatusUnprocessableEntity, NewOperationOutcome(
+			IssueSeverityError,
+			IssueTypeProcessing,
+			"Idempotency key was already used for a different operation",
+		))
+	}
+	if cached.RequestHash != "" && cached.RequestHash != entry.RequestHash {
+		return c.JSON(http.StatusUnprocessableEntity, NewOperationOutcome(
+			IssueSeverityError,
+			IssueTypeProcessing,
+			"Idempotency key was already used with a different request body",
+		))
+	}
+
+	// Wait for the request holding the key to complete.
+	ctx := c.Request().Context()
+	deadline := time.Now().Add(idempotencyLockWait)
+	for cached.InProgress {
+		if time.Now().After(deadline) {
+			return c.JSON(http.StatusConflict, NewOperationOutcome(
+				IssueSeverityError,
+				IssueTypeConflict,
+				"A request with this idempotency key is still being processed",
+			))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(idempotencyPollInterval):
+		}
+		next, err := locker.Lookup(ctx, entry.TenantID, entry.ClientID, entry.Key)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
+		}
+		if next == nil {
+			// The first request failed and released the key.
+			return c.JSON(http.StatusConflict, NewOperationOutcome(
+				IssueSeverityError,
+				IssueTypeConflict,
+				"The request with this idempotency key failed; retry it",
+			))
+		}
+		cached = next
+	}
+
+	// Replay the cached response.
+	resp := c.Response()
+	resp.Header().Set("X-Idempotency-Replayed", "true")
+	return writeIdempotentResponse(resp, cached.Headers, cached.StatusCode, cached.Body)
+}
+
+// writeIdempotentResponse writes a captured response. The body is only written
+// when there is one, since statuses such as 204 do not allow a body.
+func writeIdempotentResponse(w http.ResponseWriter, headers http.Header, status int, body []byte) error {
+	for k, vals := range headers {
+		for _, v := range vals {
+			w.Header().Set(k, v)
+		}
+	}
+	w.WriteHeader(status)
+	if len(body) == 0 {
+		return nil
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+// hashRequestBody returns the hex SHA-256 of the request body, leaving the
+// body readable by the handler.
+func hashRequestBody(req *http.Request) (string, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return "", err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// idempotencyClientID identifies the client a key belongs to: the API key
+// client when the request was authenticated with one, otherwise the user.
+func idempotencyClientID(c echo.Context) string {
+	if id, ok := c.Get("client_id").(string); ok && id != "" {
+		return id
+	}
+	return auth.UserIDFromContext(c.Request().Context())
+}
+
 // idempotencyRecorder captures an HTTP response for idempotency caching.
 // It implements http.ResponseWriter and buffers the status code, headers,
 // and body written by the downstream handler.
@@ -0,0 +1,229 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// idempotencySweepInterval is how often PGIdempotencyStore deletes expired keys.
+const idempotencySweepInterval = 10 * time.Minute
+
+// PGIdempotencyStore is a PostgreSQL-backed IdempotencyStore shared by all
+// replicas, so that a retry routed to a different replica is still replayed.
+// Keys live in public.idempotency_keys, scoped by tenant and client, and are
+// locked while their first request executes (see IdempotencyLocker). A
+// background goroutine deletes expired keys.
+type PGIdempotencyStore struct {
+	db   historyQuerier
+	ttl  time.Duration
+	stop chan struct{}
+}
+
+// NewPGIdempotencyStore creates a PGIdempotencyStore that keeps responses for
+// ttl and starts its expiry sweeper. If ttl is zero or negative,
+// DefaultIdempotencyTTL is used.
+func NewPGIdempotencyStore(pool *pgxpool.Pool, ttl time.Duration) *PGIdempotencyStore {
+	s := newPGIdempotencyStore(pool, ttl)
+	go s.sweepLoop()
+	return s
+}
+
+func newPGIdempotencyStore(db historyQuerier, ttl time.Duration) *PGIdempotencyStore {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &PGIdempotencyStore{db: db, ttl: ttl, stop: make(chan struct{})}
+}
+
+// sweepLoop periodically deletes expired keys.
+func (s *PGIdempotencyStore) sweepLoop() {
+	ticker := time.NewTicker(idempotencySweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = s.Sweep(context.Background())
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Stop terminates the background sweeper.
+func (s *PGIdempotencyStore) Stop() {
+	close(s.stop)
+}
+
+// Sweep deletes all expired keys, including locks abandoned by requests that
+// never completed.
+func (s *PGIdempotencyStore) Sweep(ctx context.Context) error {
+	if _, err := s.db.Exec(ctx, `DELETE FROM public.idempotency_keys WHERE expires_at <= NOW()`); err != nil {
+		return fmt.Errorf("sweep idempotency keys: %w", err)
+	}
+	return nil
+}
+
+const idempotencyColumns = `idempotency_key, tenant_id, client_id, method, path, request_hash,
+	in_progress, status_code, headers, body, created_at, expires_at`
+
+// Acquire implements IdempotencyLocker. The insert only replaces an existing
+// row once it has expired, so exactly one of several concurrent requests
+// acquires a key.
+func (s *PGIdempotencyStore) Acquire(ctx context.Context, entry *IdempotencyKey) (*IdempotencyKey, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		var key string
+		err := s.db.QueryRow(ctx, `
+			INSERT INTO public.idempotency_keys
+				(tenant_id, client_id, idempotency_key, method, path, request_hash,
+				 in_progress, created_at, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, TRUE, NOW(), NOW() + $7 * INTERVAL '1 second')
+			ON CONFLICT (tenant_id, client_id, idempotency_key) DO UPDATE SET
+				method = EXCLUDED.method,
+				path = EXCLUDED.path,
+				request_hash = EXCLUDED.request_hash,
+				in_progress = TRUE,
+				status_code = 0,
+				headers = NULL,
+				body = NULL,
+				created_at = EXCLUDED.created_at,
+				expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= NOW()
+			RETURNING idempotency_key`,
+			entry.TenantID, entry.ClientID, entry.Key, entry.Method, entry.Path, entry.RequestHash,
+			idempotencyLockTTL.Seconds(),
+		).Scan(&key)
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("acquire idempotency key: %w", err)
+		}
+		// An unexpired entry exists; return it unless it was deleted in the
+		// meantime, in which case try again.
+		existing, err := s.Lookup(ctx, entry.TenantID, entry.ClientID, entry.Key)
+		if err != nil || existing != nil {
+			return existing, err
+		}
+	}
+	return nil, fmt.Errorf("acquire idempotency key: key %q is contended", entry.Key)
+}
+
+// Lookup implements IdempotencyLocker.
+func (s *PGIdempotencyStore) Lookup(ctx context.Context, tenantID, clientID, key string) (*IdempotencyKey, error) {
+	row := s.db.QueryRow(ctx, `SELECT `+idempotencyColumns+`
+		FROM public.idempotency_keys
+		WHERE tenant_id = $1 AND client_id = $2 AND idempotency_key = $3 AND expires_at > NOW()`,
+		tenantID, clientID, key)
+	entry, err := scanIdempotencyKey(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lookup idempotency key: %w", err)
+	}
+	return entry, nil
+}
+
+// Complete implements IdempotencyLocker.
+func (s *PGIdempotencyStore) Complete(ctx context.Context, entry *IdempotencyKey) error {
+	headers, err := json.Marshal(entry.Headers)
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: marshal headers: %w", err)
+	}
+	_, err = s.db.Exec(ctx, `
+		UPDATE public.idempotency_keys SET
+			in_progress = FALSE,
+			status_code = $4,
+			headers = $5,
+			body = $6,
+			expires_at = NOW() + $7 * INTERVAL '1 second'
+		WHERE tenant_id = $1 AND client_id = $2 AND idempotency_key = $3`,
+		entry.TenantID, entry.ClientID, entry.Key, entry.StatusCode, headers, entry.Body, s.ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release implements IdempotencyLocker. Completed entries are left in place.
+func (s *PGIdempotencyStore) Release(ctx context.Context, tenantID, clientID, key string) error {
+	_, err := s.db.Exec(ctx, `
+		DELETE FROM public.idempotency_keys
+		WHERE tenant_id = $1 AND client_id = $2 AND idempotency_key = $3 AND in_progress`,
+		tenantID, clientID, key)
+	if err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Get implements IdempotencyStore for keys without tenant or client scope.
+func (s *PGIdempotencyStore) Get(key string) (*IdempotencyKey, bool) {
+	entry, err := s.Lookup(context.Background(), "", "", key)
+	if err != nil || entry == nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+// Set implements IdempotencyStore for keys without tenant or client scope.
+func (s *PGIdempotencyStore) Set(key string, entry *IdempotencyKey) {
+	headers, err := json.Marshal(entry.Headers)
+	if err != nil {
+		return
+	}
+	expiresAt := entry.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(s.ttl)
+	}
+	_, _ = s.db.Exec(context.Background(), `
+		INSERT INTO public.idempotency_keys
+			(tenant_id, client_id, idempotency_key, method, path, request_hash,
+			 in_progress, status_code, headers, body, created_at, expires_at)
+		VALUES ('', '', $1, $2, $3, $4, $5, $6, $7, $8, NOW(), $9)
+		ON CONFLICT (tenant_id, client_id, idempotency_key) DO UPDATE SET
+			method = EXCLUDED.method,
+			path = EXCLUDED.path,
+			request_hash = EXCLUDED.request_hash,
+			in_progress = EXCLUDED.in_progress,
+			status_code = EXCLUDED.status_code,
+			headers = EXCLUDED.headers,
+			body = EXCLUDED.body,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at`,
+		key, entry.Method, entry.Path, entry.RequestHash, entry.InProgress,
+		entry.StatusCode, headers, entry.Body, expiresAt)
+}
+
+// Delete implements IdempotencyStore for keys without tenant or client scope.
+func (s *PGIdempotencyStore) Delete(key string) {
+	_, _ = s.db.Exec(context.Background(), `
+		DELETE FROM public.idempotency_keys
+		WHERE tenant_id = '' AND client_id = '' AND idempotency_key = $1`, key)
+}
+
+func scanIdempotencyKey(row pgx.Row) (*IdempotencyKey, error) {
+	var (
+		entry   IdempotencyKey
+		headers []byte
+	)
+	if err := row.Scan(&entry.Key, &entry.TenantID, &entry.ClientID, &entry.Method, &entry.Path,
+		&entry.RequestHash, &entry.InProgress, &entry.StatusCode, &headers, &entry.Body,
+		&entry.CreatedAt, &entry.ExpiresAt); err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		entry.Headers = make(http.Header)
+		if err := json.Unmarshal(headers, &entry.Headers); err != nil {
+			return nil, err
+		}
+	}
+	return &entry, nil
+}
@@ -2,6 +2,7 @@ package fhir
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +12,8 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/db"
 )
 
 // ---------------------------------------------------------------------------
@@ -1094,3 +1097,282 @@ func TestIdempotencyRecorder_MultipleWrites(t *testing.T) {
 		t.Errorf("statusCode = %d, want 201", rec.statusCode)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Key locking and scoping
+// ---------------------------------------------------------------------------
+
+func TestInMemoryIdempotencyStore_AcquireCompleteRelease(t *testing.T) {
+	store := NewInMemoryIdempotencyStore(time.Hour)
+	defer store.Stop()
+	ctx := context.Background()
+
+	entry := &IdempotencyKey{Key: "k", TenantID: "t1", ClientID: "c1", Method: http.MethodPost, Path: "/fhir/Patient"}
+	existing, err := store.Acquire(ctx, entry)
+	if err != nil || existing != nil {
+		t.Fatalf("first Acquire = %v, %v; want nil, nil", existing, err)
+	}
+	existing, err = store.Acquire(ctx, entry)
+	if err != nil || existing == nil || !existing.InProgress {
+		t.Fatalf("second Acquire = %+v, %v; want in-progress entry", existing, err)
+	}
+
+	entry.StatusCode = http.StatusCreated
+	entry.Body = []byte("created")
+	if err := store.Complete(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Lookup(ctx, "t1", "c1", "k")
+	if err != nil || got == nil {
+		t.Fatalf("Lookup = %v, %v; want entry", got, err)
+	}
+	if got.InProgress || got.StatusCode != http.StatusCreated || string(got.Body) != "created" {
+		t.Errorf("completed entry = %+v", got)
+	}
+	if got.ExpiresAt.Sub(got.CreatedAt) != time.Hour {
+		t.Errorf("completed entry TTL = %v, want 1h", got.ExpiresAt.Sub(got.CreatedAt))
+	}
+
+	if err := store.Release(ctx, "t1", "c1", "k"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Lookup(ctx, "t1", "c1", "k"); got != nil {
+		t.Error("expected entry to be removed by Release")
+	}
+}
+
+func TestInMemoryIdempotencyStore_ExpiredLockReacquired(t *testing.T) {
+	store := NewInMemoryIdempotencyStore(time.Hour)
+	defer store.Stop()
+	ctx := context.Background()
+
+	now := time.Now()
+	store.nowFunc = func() time.Time { return now }
+	entry := &IdempotencyKey{Key: "k", Method: http.MethodPost, Path: "/fhir/Patient"}
+	if _, err := store.Acquire(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	store.nowFunc = func() time.Time { return now.Add(idempotencyLockTTL + time.Second) }
+	existing, err := store.Acquire(ctx, entry)
+	if err != nil || existing != nil {
+		t.Errorf("Acquire after lock expiry = %v, %v; want nil, nil", existing, err)
+	}
+}
+
+func TestIdempotencyMiddleware_ScopedByTenantAndClient(t *testing.T) {
+	store := NewInMemoryIdempotencyStore(time.Hour)
+	defer store.Stop()
+
+	e := echo.New()
+	callCount := 0
+	handler := IdempotencyMiddleware(store)(func(c echo.Context) error {
+		callCount++
+		return c.String(http.StatusCreated, "created")
+	})
+	do := func(tenant, client string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/fhir/Patient", strings.NewReader("{}"))
+		req.Header.Set("Idempotency-Key", "shared-key")
+		req = req.WithContext(context.WithValue(req.Context(), db.TenantIDKey, tenant))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("client_id", client)
+		if err := handler(c); err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+
+	do("t1", "c1")
+	do("t2", "c1")
+	do("t1", "c2")
+	if callCount != 3 {
+		t.Errorf("handler called %d times, want 3 (one per tenant and client)", callCount)
+	}
+	rec := do("t1", "c1")
+	if callCount != 3 {
+		t.Errorf("handler called %d times after retry, want 3", callCount)
+	}
+	if rec.Header().Get("X-Idempotency-Replayed") != "true" {
+		t.Error("expected retry to be replayed")
+	}
+}
+
+func TestIdempotencyMiddleware_DifferentBodySameKey_422(t *testing.T) {
+	store := NewInMemoryIdempotencyStore(time.Hour)
+	defer store.Stop()
+
+	e := echo.New()
+	callCount := 0
+	handler := IdempotencyMiddleware(store)(func(c echo.Context) error {
+		callCount++
+		return c.String(http.StatusCreated, "created")
+	})
+
+	for i, body := range []string{`{"id":"a"}`, `{"id":"b"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/fhir/Patient", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", "body-key")
+		rec := httptest.NewRecorder()
+		if err := handler(e.NewContext(req, rec)); err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 && rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422 for a different body, got %d", rec.Code)
+		}
+	}
+	if callCount != 1 {
+		t.Errorf("handler called %d times, want 1", callCount)
+	}
+}
+
+func TestIdempotencyMiddleware_HandlerReadsBody(t *testing.T) {
+	store := NewInMemoryIdempotencyStore(time.Hour)
+	defer store.Stop()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/fhir/Patient", strings.NewReader(`{"id":"a"}`))
+	req.Header.Set("Idempotency-Key", "read-key")
+	rec := httptest.NewRecorder()
+
+	var got string
+	handler := IdempotencyMiddleware(store)(func(c echo.Context) error {
+		var buf bytes.Buffer
+		buf.ReadFrom(c.Request().Body)
+		got = buf.String()
+		return c.NoContent(http.StatusCreated)
+	})
+	if err := handler(e.NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+	if got != `{"id":"a"}` {
+		t.Errorf("handler read body %q, want %q", got, `{"id":"a"}`)
+	}
+}
+
+func TestIdempotencyMiddleware_InProgressDuplicate_409(t *testing.T) {
+	orig := idempotencyLockWait
+	idempotencyLockWait = 200 * time.Millisecond
+	defer func() { idempotencyLockWait = orig }()
+
+	store := NewInMemoryIdempotencyStore(time.Hour)
+	defer store.Stop()
+	// Simulate a request holding the key on another replica.
+	if _, err := store.Acquire(context.Background(), &IdempotencyKey{
+		Key: "busy-key", Method: http.MethodPost, Path: "/fhir/Patient", RequestHash: hashOf(""),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/fhir/Patient", nil)
+	req.Header.Set("Idempotency-Key", "busy-key")
+	rec := httptest.NewRecorder()
+	called := false
+	handler := IdempotencyMiddleware(store)(func(c echo.Context) error {
+		called = true
+		return c.NoContent(http.StatusCreated)
+	})
+	if err := handler(e.NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+	if called {
+		t.Error("handler should not run while the key is locked")
+	}
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", rec.Code)
+	}
+}
+
+func TestIdempotencyMiddleware_InProgressDuplicate_WaitsForResponse(t *testing.T) {
+	store := NewInMemoryIdempotencyStore(time.Hour)
+	defer store.Stop()
+	entry := &IdempotencyKey{Key: "slow-key", Method: http.MethodPost, Path: "/fhir/Patient", RequestHash: hashOf("")}
+	if _, err := store.Acquire(context.Background(), entry); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		entry.StatusCode = http.StatusCreated
+		entry.Body = []byte("first")
+		store.Complete(context.Background(), entry)
+	}()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/fhir/Patient", nil)
+	req.Header.Set("Idempotency-Key", "slow-key")
+	rec := httptest.NewRecorder()
+	handler := IdempotencyMiddleware(store)(func(c echo.Context) error {
+		return c.String(http.StatusCreated, "second")
+	})
+	if err := handler(e.NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusCreated || rec.Body.String() != "first" {
+		t.Errorf("got %d %q, want the first request's response", rec.Code, rec.Body.String())
+	}
+}
+
+func TestIdempotencyMiddleware_HandlerErrorReleasesKey(t *testing.T) {
+	store := NewInMemoryIdempotencyStore(time.Hour)
+	defer store.Stop()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/fhir/Patient", nil)
+	req.Header.Set("Idempotency-Key", "fail-key")
+	handler := IdempotencyMiddleware(store)(func(c echo.Context) error {
+		return fmt.Errorf("boom")
+	})
+	if err := handler(e.NewContext(req, httptest.NewRecorder())); err == nil {
+		t.Fatal("expected handler error")
+	}
+	if _, ok := store.Get("fail-key"); ok {
+		t.Error("expected key to be released after handler error")
+	}
+}
+
+func TestIdempotencyMiddleware_ServerErrorResponseNotCached(t *testing.T) {
+	store := NewInMemoryIdempotencyStore(time.Hour)
+	defer store.Stop()
+
+	e := echo.New()
+	calls := 0
+	handler := IdempotencyMiddleware(store)(func(c echo.Context) error {
+		calls++
+		if calls == 1 {
+			return c.JSON(http.StatusServiceUnavailable, ErrorOutcome("database unavailable"))
+		}
+		return c.JSON(http.StatusCreated, map[string]string{"id": "p1"})
+	})
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/fhir/Patient", nil)
+		req.Header.Set("Idempotency-Key", "unavailable-key")
+		rec := httptest.NewRecorder()
+		if err := handler(e.NewContext(req, rec)); err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+
+	if rec := send(); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected the 503 to reach the client, got %d", rec.Code)
+	}
+	if _, ok := store.Get("unavailable-key"); ok {
+		t.Error("expected key to be released after a server error response")
+	}
+	rec := send()
+	if rec.Code != http.StatusCreated || rec.Header().Get("X-Idempotency-Replayed") != "" {
+		t.Errorf("expected the retry to execute and return 201, got %d", rec.Code)
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+	// The final response is cached as usual.
+	if rec := send(); rec.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("expected the 201 to be replayed, got %d after %d calls", rec.Code, calls)
+	}
+}
+
+func hashOf(body string) string {
+	h, _ := hashRequestBody(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	return h
+}
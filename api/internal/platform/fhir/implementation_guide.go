@@ -279,6 +279,12 @@ func (h *ImplementationGuideHandler) RegisterRoutes(g *echo.Group) {
 	g.GET("/ImplementationGuide/:id", h.Read)
 }
 
+// RegisterResources registers the ImplementationGuide read and search
+// routes with r.
+func (h *ImplementationGuideHandler) RegisterResources(r *ResourceRegistry) {
+	r.RegisterInteractions("ImplementationGuide", "read", "search-type")
+}
+
 // List handles GET /fhir/ImplementationGuide and returns a searchset Bundle.
 func (h *ImplementationGuideHandler) List(c echo.Context) error {
 	h.mu.RLock()
@@ -0,0 +1,115 @@
+package fhir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// ImportIDStore records, per input source, the references of the resources
+// that $import created with a server-assigned id in place of the id they
+// had in the source. A retried job, or a later import from the same source,
+// finds them there, so that it updates the resources instead of creating
+// them again and rewrites references to them.
+type ImportIDStore interface {
+	// LookupImportID returns the server reference ("Type/id") recorded for
+	// the source reference of source, or "" if there is none.
+	LookupImportID(ctx context.Context, source, ref string) (string, error)
+	// SaveImportID records the server reference of a source reference,
+	// replacing any earlier one.
+	SaveImportID(ctx context.Context, source, ref, target string) error
+}
+
+// =========== In-memory store ===========
+
+// InMemoryImportIDStore is a thread-safe in-memory implementation of
+// ImportIDStore.
+type InMemoryImportIDStore struct {
+	mu  sync.RWMutex
+	ids map[[2]string]string // key: source, source reference
+}
+
+// NewInMemoryImportIDStore creates a new InMemoryImportIDStore.
+func NewInMemoryImportIDStore() *InMemoryImportIDStore {
+	return &InMemoryImportIDStore{ids: make(map[[2]string]string)}
+}
+
+// LookupImportID implements ImportIDStore.
+func (s *InMemoryImportIDStore) LookupImportID(_ context.Context, source, ref string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ids[[2]string{source, ref}], nil
+}
+
+// SaveImportID implements ImportIDStore.
+func (s *InMemoryImportIDStore) SaveImportID(_ context.Context, source, ref, target string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids[[2]string{source, ref}] = target
+	return nil
+}
+
+// =========== PostgreSQL store ===========
+
+// ImportIDRepository stores the ids in the tenant's import_id_map table,
+// using the tenant connection or transaction carried by the context, so
+// that an id is recorded in the transaction that creates its resource.
+type ImportIDRepository struct{}
+
+// NewImportIDRepository creates a new ImportIDRepository.
+func NewImportIDRepository() *ImportIDRepository {
+	return &ImportIDRepository{}
+}
+
+func (r *ImportIDRepository) conn(ctx context.Context) historyQuerier {
+	if tx := db.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	if c := db.ConnFromContext(ctx); c != nil {
+		return c
+	}
+	return nil
+}
+
+// LookupImportID implements ImportIDStore.
+func (r *ImportIDRepository) LookupImportID(ctx context.Context, source, ref string) (string, error) {
+	q := r.conn(ctx)
+	if q == nil {
+		return "", fmt.Errorf("no database connection in context")
+	}
+	var target string
+	err := q.QueryRow(ctx, `
+		SELECT target_ref FROM import_id_map
+		WHERE input_source = $1 AND source_ref = $2`,
+		source, ref).Scan(&target)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get import id: %w", err)
+	}
+	return target, nil
+}
+
+// SaveImportID implements ImportIDStore.
+func (r *ImportIDRepository) SaveImportID(ctx context.Context, source, ref, target string) error {
+	q := r.conn(ctx)
+	if q == nil {
+		return fmt.Errorf("no database connection in context")
+	}
+	_, err := q.Exec(ctx, `
+		INSERT INTO import_id_map (input_source, source_ref, target_ref)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (input_source, source_ref) DO UPDATE SET
+			target_ref = EXCLUDED.target_ref, created_at = NOW()`,
+		source, ref, target)
+	if err != nil {
+		return fmt.Errorf("save import id: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,494 @@
+package fhir
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ehr/ehr/internal/platform/blobstore"
+)
+
+// defaultImportChunkSize is the number of lines an ImportLoader writes per
+// database transaction.
+const defaultImportChunkSize = 500
+
+// ImportLoader loads the NDJSON inputs of $import jobs into the server.
+//
+// Inputs are read from the blob store, by a blob URL ("blob://<id>") or the
+// blob download path ("<BlobURLPrefix><id>"), or from a file under FileRoot
+// ("file:///<path>"). Each input is streamed a line at a time; every line is
+// decoded, checked against the input's type and validated, then written
+// through the Dispatcher, so that the domain handlers and services persist
+// it exactly as they would a REST create or update.
+//
+// A line with an id updates that resource when it exists and otherwise
+// creates it with a server-assigned id; later references to the original id,
+// in any input of the job, are rewritten to the new one. With IDs set, the
+// new ids are also recorded per input source, in the transaction that
+// creates the resource, so that a retried job or a later import from the
+// same source updates the resource and rewrites references to it instead of
+// creating it again. Lines are written in
+// chunks of ChunkSize, each in one transaction. A chunk in which a write
+// fails is rolled back and retried a line at a time, so that a bad line does
+// not discard its neighbours. Lines that cannot be imported are reported as
+// OperationOutcomes, one per line, in an NDJSON error file per input.
+type ImportLoader struct {
+	Dispatcher EntryDispatcher
+	Validator  *ResourceValidator
+	// Blobs holds blob inputs and receives the error files. Without it blob
+	// inputs are rejected and errors are counted but not written.
+	Blobs blobstore.BlobStore
+	// BlobURLPrefix is the path the blob download route is served at. Error
+	// files are reported at it.
+	BlobURLPrefix string
+	// FileRoot is the directory file inputs must lie in; when empty, file
+	// inputs are rejected.
+	FileRoot string
+	// IDs records the ids assigned in place of source ids. Without it they
+	// are only known for the rest of the job.
+	IDs       ImportIDStore
+	BeginTx   BeginTxFunc
+	ChunkSize int
+}
+
+// NewImportLoader creates a loader writing through dispatcher and storing
+// error files in blobs, served at /api/v1/blobs/.
+func NewImportLoader(dispatcher EntryDispatcher, validator *ResourceValidator, blobs blobstore.BlobStore) *ImportLoader {
+	return &ImportLoader{
+		Dispatcher:    dispatcher,
+		Validator:     validator,
+		Blobs:         blobs,
+		BlobURLPrefix: "/api/v1/blobs/",
+		BeginTx:       DefaultBeginTx,
+		ChunkSize:     defaultImportChunkSize,
+	}
+}
+
+// ImportProgressFunc is called by ImportLoader.Load after every chunk with
+// the number of lines processed and failed so far. Returning an error stops
+// the import.
+type ImportProgressFunc func(processed, failed int) error
+
+// importLine is a line of an input that passed validation.
+type importLine struct {
+	number int
+	raw    json.RawMessage
+}
+
+// importErrors collects the OperationOutcomes of an input's failed lines.
+type importErrors struct {
+	buf   bytes.Buffer
+	w     *NDJSONWriter
+	count int
+}
+
+func (e *importErrors) add(line int, oo *OperationOutcome) {
+	if e.w == nil {
+		e.w = NewNDJSONWriter(&e.buf)
+	}
+	prefix := fmt.Sprintf("line %d: ", line)
+	if line == 0 {
+		prefix = ""
+	}
+	issues := make([]OperationOutcomeIssue, len(oo.Issue))
+	for i, issue := range oo.Issue {
+		issue.Diagnostics = prefix + issue.Diagnostics
+		issues[i] = issue
+	}
+	_ = e.w.WriteResource(&OperationOutcome{ResourceType: "OperationOutcome", Issue: issues})
+	e.count++
+}
+
+// importRun is the state of one Load call.
+type importRun struct {
+	loader   *ImportLoader
+	progress ImportProgressFunc
+	// source is the input source the ids of IDs are recorded for.
+	source string
+	// idMap holds the committed server references of source references,
+	// and unmapped the source references IDs has none for.
+	idMap     map[string]string
+	unmapped  map[string]bool
+	processed int
+	failed    int
+}
+
+// Load imports every input of req. It returns an output per input that could
+// be read, with the number of resources loaded from it, and an error file
+// per input with failed lines. An error is returned only when the import
+// cannot go on, such as when a transaction cannot be started or ctx is done.
+func (l *ImportLoader) Load(ctx context.Context, req *ImportRequest, progress ImportProgressFunc) (outputs, errorFiles []AsyncJobOutput, err error) {
+	run := &importRun{
+		loader: l, progress: progress, source: req.InputSource,
+		idMap: make(map[string]string), unmapped: make(map[string]bool),
+	}
+	for _, in := range req.Input {
+		var errs importErrors
+		loaded, read, err := run.input(ctx, in, &errs)
+		if err != nil {
+			return outputs, errorFiles, fmt.Errorf("import %s: %w", in.URL, err)
+		}
+		if read {
+			outputs = append(outputs, AsyncJobOutput{Type: in.Type, URL: in.URL, Count: loaded})
+		}
+		if errs.count > 0 {
+			file, err := l.saveErrors(ctx, in, &errs)
+			if err != nil {
+				return outputs, errorFiles, err
+			}
+			errorFiles = append(errorFiles, file)
+		}
+	}
+	return outputs, errorFiles, nil
+}
+
+// input imports one input and returns the number of resources loaded and
+// whether the input could be read.
+func (r *importRun) input(ctx context.Context, in ImportInput, errs *importErrors) (int, bool, error) {
+	rc, err := r.loader.open(ctx, in.URL)
+	if err != nil {
+		errs.add(0, NewOperationOutcome(IssueSeverityError, IssueTypeNotFound,
+			fmt.Sprintf("cannot read input %s: %s", in.URL, err.Error())))
+		r.failed++
+		return 0, false, nil
+	}
+	defer rc.Close()
+
+	size := r.loader.ChunkSize
+	if size <= 0 {
+		size = defaultImportChunkSize
+	}
+	loaded := 0
+	chunk := make([]importLine, 0, size)
+	flush := func() error {
+		n, err := r.chunk(ctx, chunk, errs)
+		if err != nil {
+			return err
+		}
+		loaded += n
+		chunk = chunk[:0]
+		if r.progress != nil {
+			return r.progress(r.processed, r.failed)
+		}
+		return nil
+	}
+
+	reader := NewNDJSONReader(rc)
+	for {
+		if err := ctx.Err(); err != nil {
+			return loaded, true, err
+		}
+		raw, number, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			errs.add(number, NewOperationOutcome(IssueSeverityError, IssueTypeException,
+				fmt.Sprintf("reading input failed: %s", err.Error())))
+			r.failed++
+			break
+		}
+		r.processed++
+		if oo := r.loader.check(in.Type, raw); oo != nil {
+			errs.add(number, oo)
+			r.failed++
+			continue
+		}
+		chunk = append(chunk, importLine{number: number, raw: raw})
+		if len(chunk) == size {
+			if err := flush(); err != nil {
+				return loaded, true, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return loaded, true, err
+	}
+	return loaded, true, nil
+}
+
+// chunk writes lines in one transaction, falling back to a transaction per
+// line when a write fails, and returns the number of lines written.
+func (r *importRun) chunk(ctx context.Context, lines []importLine, errs *importErrors) (int, error) {
+	if len(lines) == 0 {
+		return 0, nil
+	}
+	if len(lines) > 1 {
+		txCtx, tx, err := r.loader.BeginTx(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("begin transaction: %w", err)
+		}
+		created := make(map[string]string)
+		ok := true
+		for _, line := range lines {
+			if oo := r.write(txCtx, line, created); oo != nil {
+				ok = false
+				break
+			}
+		}
+		if ok && tx.Commit(ctx) == nil {
+			for k, v := range created {
+				r.idMap[k] = v
+			}
+			return len(lines), nil
+		}
+		_ = tx.Rollback(ctx)
+	}
+
+	written := 0
+	for _, line := range lines {
+		txCtx, tx, err := r.loader.BeginTx(ctx)
+		if err != nil {
+			return written, fmt.Errorf("begin transaction: %w", err)
+		}
+		created := make(map[string]string)
+		oo := r.write(txCtx, line, created)
+		if oo == nil {
+			if err := tx.Commit(ctx); err != nil {
+				oo = NewOperationOutcome(IssueSeverityError, IssueTypeException,
+					fmt.Sprintf("commit failed: %s", err.Error()))
+			}
+		}
+		if oo != nil {
+			_ = tx.Rollback(ctx)
+			errs.add(line.number, oo)
+			r.failed++
+			continue
+		}
+		for k, v := range created {
+			r.idMap[k] = v
+		}
+		written++
+	}
+	return written, nil
+}
+
+// check decodes a line and validates it as a resource of resourceType,
+// returning the OperationOutcome of a line that cannot be imported.
+func (l *ImportLoader) check(resourceType string, raw json.RawMessage) *OperationOutcome {
+	var res map[string]interface{}
+	if err := json.Unmarshal(raw, &res); err != nil || res == nil {
+		return NewOperationOutcome(IssueSeverityError, IssueTypeStructure, "line is not a JSON object")
+	}
+	if rt, _ := res["resourceType"].(string); rt != resourceType {
+		return NewOperationOutcome(IssueSeverityError, IssueTypeInvalid,
+			fmt.Sprintf("resourceType %q does not match the input type %s", rt, resourceType))
+	}
+	if l.Validator == nil {
+		return nil
+	}
+	mode := "create"
+	if id, _ := res["id"].(string); id != "" {
+		mode = ""
+	}
+	if result := l.Validator.ValidateWithMode(res, mode); !result.Valid {
+		return MultiValidationOutcome(result.Issues)
+	}
+	return nil
+}
+
+// write creates or updates the resource of line. References are rewritten
+// through the run's ids and created, and a resource created in place of its
+// id is recorded in created and in the loader's IDs.
+func (r *importRun) write(ctx context.Context, line importLine, created map[string]string) *OperationOutcome {
+	l := r.loader
+	var res map[string]interface{}
+	if err := json.Unmarshal(line.raw, &res); err != nil {
+		return NewOperationOutcome(IssueSeverityError, IssueTypeStructure, err.Error())
+	}
+	if err := r.lookupIDs(ctx, res, created); err != nil {
+		return NewOperationOutcome(IssueSeverityError, IssueTypeException, err.Error())
+	}
+	resolveRefsInResource(res, r.idMap)
+	resolveRefsInResource(res, created)
+
+	rt, _ := res["resourceType"].(string)
+	id, _ := res["id"].(string)
+	method, target := http.MethodPost, rt
+	if id != "" {
+		ref := rt + "/" + id
+		if mapped, ok := created[ref]; ok {
+			ref = mapped
+		} else if mapped, ok := r.idMap[ref]; ok {
+			ref = mapped
+		}
+		_, err := dispatchGet(ctx, l.Dispatcher, ref)
+		switch {
+		case err == nil:
+			method, target = http.MethodPut, ref
+			res["id"] = ref[strings.LastIndex(ref, "/")+1:]
+		case errors.Is(err, ErrReferenceNotFound):
+			delete(res, "id")
+		default:
+			return NewOperationOutcome(IssueSeverityError, IssueTypeException, err.Error())
+		}
+	}
+
+	body, err := json.Marshal(res)
+	if err != nil {
+		return NewOperationOutcome(IssueSeverityError, IssueTypeStructure, err.Error())
+	}
+	result, err := l.Dispatcher.Dispatch(ctx, &EntryDispatchRequest{
+		Method: method, URL: target, Body: body, ContentType: "application/fhir+json",
+	})
+	if err != nil {
+		return NewOperationOutcome(IssueSeverityError, IssueTypeException, err.Error())
+	}
+	if result.StatusCode >= 400 {
+		return outcomeFromResult(result)
+	}
+	if method == http.MethodPost && id != "" {
+		location := normalizeLocation(result.Header.Get("Location"))
+		if location == "" {
+			if newID, _ := result.Resource()["id"].(string); newID != "" {
+				location = rt + "/" + newID
+			}
+		}
+		if location != "" {
+			created[rt+"/"+id] = location
+			if l.IDs != nil {
+				if err := l.IDs.SaveImportID(ctx, r.source, rt+"/"+id, location); err != nil {
+					return NewOperationOutcome(IssueSeverityError, IssueTypeException, err.Error())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// lookupIDs loads from the loader's IDs the server references of res and of
+// the resources it references that the run does not know yet.
+func (r *importRun) lookupIDs(ctx context.Context, res map[string]interface{}, created map[string]string) error {
+	if r.loader.IDs == nil {
+		return nil
+	}
+	refs := importReferences(res)
+	if rt, _ := res["resourceType"].(string); rt != "" {
+		if id, _ := res["id"].(string); id != "" {
+			refs = append(refs, rt+"/"+id)
+		}
+	}
+	for _, ref := range refs {
+		if _, ok := created[ref]; ok {
+			continue
+		}
+		if _, ok := r.idMap[ref]; ok || r.unmapped[ref] {
+			continue
+		}
+		target, err := r.loader.IDs.LookupImportID(ctx, r.source, ref)
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			r.unmapped[ref] = true
+		} else {
+			r.idMap[ref] = target
+		}
+	}
+	return nil
+}
+
+// importReferences returns the relative literal references ("Type/id") of
+// res.
+func importReferences(res map[string]interface{}) []string {
+	var refs []string
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch val := v.(type) {
+		case map[string]interface{}:
+			for k, child := range val {
+				if s, ok := child.(string); ok && k == "reference" {
+					if rt, id, _, ok := parseLiteralReference(s); ok && s == rt+"/"+id {
+						refs = append(refs, s)
+					}
+					continue
+				}
+				walk(child)
+			}
+		case []interface{}:
+			for _, item := range val {
+				walk(item)
+			}
+		}
+	}
+	walk(res)
+	return refs
+}
+
+// open opens the input at rawURL.
+func (l *ImportLoader) open(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case u.Scheme == "blob":
+		return l.openBlob(ctx, strings.Trim(u.Host+u.Path, "/"))
+	case u.Scheme == "file":
+		return l.openFile(u.Path)
+	case u.Scheme == "" && u.Host == "" && l.BlobURLPrefix != "" && strings.HasPrefix(u.Path, l.BlobURLPrefix):
+		return l.openBlob(ctx, strings.TrimPrefix(u.Path, l.BlobURLPrefix))
+	}
+	return nil, fmt.Errorf("unsupported input URL; use a blob or file URL")
+}
+
+func (l *ImportLoader) openBlob(ctx context.Context, id string) (io.ReadCloser, error) {
+	if l.Blobs == nil {
+		return nil, fmt.Errorf("blob inputs are not enabled")
+	}
+	rc, _, err := l.Blobs.Download(ctx, id)
+	return rc, err
+}
+
+// openFile opens path, which must resolve to a file under FileRoot.
+func (l *ImportLoader) openFile(path string) (io.ReadCloser, error) {
+	if l.FileRoot == "" {
+		return nil, fmt.Errorf("file inputs are not enabled")
+	}
+	root, err := filepath.EvalSymlinks(l.FileRoot)
+	if err != nil {
+		return nil, err
+	}
+	if root, err = filepath.Abs(root); err != nil {
+		return nil, err
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(filepath.FromSlash(path)))
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%s is outside the import directory", path)
+	}
+	return os.Open(resolved)
+}
+
+// saveErrors stores the error file of an input and returns its output entry.
+func (l *ImportLoader) saveErrors(ctx context.Context, in ImportInput, errs *importErrors) (AsyncJobOutput, error) {
+	file := AsyncJobOutput{Type: "OperationOutcome", Count: errs.count}
+	if l.Blobs == nil {
+		return file, nil
+	}
+	if err := errs.w.Flush(); err != nil {
+		return file, err
+	}
+	meta, err := l.Blobs.Upload(ctx, blobstore.BlobMetadata{
+		FileName:    in.Type + "-errors.ndjson",
+		ContentType: "application/fhir+ndjson",
+		Category:    "other",
+		Tags:        map[string]string{"import-input": in.URL},
+	}, &errs.buf)
+	if err != nil {
+		return file, fmt.Errorf("store error file of %s: %w", in.URL, err)
+	}
+	file.URL = l.BlobURLPrefix + meta.ID
+	return file, nil
+}
@@ -0,0 +1,288 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ehr/ehr/internal/platform/blobstore"
+)
+
+// newTestImportLoader builds a loader writing to a dispatch test server in
+// fake transactions, with an in-memory blob store.
+func newTestImportLoader() (*ImportLoader, blobstore.BlobStore, map[string]map[string]interface{}) {
+	e, store := newDispatchTestServer()
+	blobs := blobstore.NewInMemoryBlobStore()
+	loader := NewImportLoader(NewEchoEntryDispatcher(e, "/fhir"), NewResourceValidator(), blobs)
+	loader.BeginTx = func(ctx context.Context) (context.Context, TxFinisher, error) {
+		return ctx, &fakeTx{}, nil
+	}
+	return loader, blobs, store
+}
+
+// uploadNDJSON stores lines as an NDJSON blob and returns its blob URL.
+func uploadNDJSON(t *testing.T, blobs blobstore.BlobStore, lines ...string) string {
+	t.Helper()
+	meta, err := blobs.Upload(context.Background(), blobstore.BlobMetadata{
+		FileName: "input.ndjson", ContentType: "application/fhir+ndjson", Category: "other",
+	}, strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	return "blob://" + meta.ID
+}
+
+// readErrorFile returns the OperationOutcomes of an error file.
+func readErrorFile(t *testing.T, blobs blobstore.BlobStore, file AsyncJobOutput) []OperationOutcome {
+	t.Helper()
+	rc, _, err := blobs.Download(context.Background(), strings.TrimPrefix(file.URL, "/api/v1/blobs/"))
+	if err != nil {
+		t.Fatalf("error file %s not stored: %v", file.URL, err)
+	}
+	defer rc.Close()
+	var outcomes []OperationOutcome
+	reader := NewNDJSONReader(rc)
+	for {
+		raw, _, err := reader.Next()
+		if err == io.EOF {
+			return outcomes
+		}
+		var oo OperationOutcome
+		if err != nil || json.Unmarshal(raw, &oo) != nil {
+			t.Fatalf("invalid error file line %s: %v", raw, err)
+		}
+		outcomes = append(outcomes, oo)
+	}
+}
+
+func TestImportLoader_LoadsAndReportsFailedLines(t *testing.T) {
+	loader, blobs, store := newTestImportLoader()
+	req := &ImportRequest{Input: []ImportInput{
+		{Type: "Patient", URL: uploadNDJSON(t, blobs,
+			`{"resourceType":"Patient","id":"legacy-1","name":[{"family":"Smith"}]}`,
+			``,
+			`{"resourceType":"Patient","name":[{"family":"Jones"}]}`,
+			`{not json`,
+			`{"resourceType":"Observation","status":"final"}`,
+			`{"resourceType":"Patient","gender":"female"}`,
+		)},
+		{Type: "Observation", URL: uploadNDJSON(t, blobs,
+			`{"resourceType":"Observation","status":"final","code":{"text":"bp"},"valueString":"ok","subject":{"reference":"Patient/legacy-1"}}`,
+			`{"resourceType":"Observation","status":"final","code":{"text":"bp"},"valueString":"ok","subject":{"reference":"Group/1"}}`,
+		)},
+	}}
+
+	var progress [][2]int
+	outputs, errorFiles, err := loader.Load(context.Background(), req, func(processed, failed int) error {
+		progress = append(progress, [2]int{processed, failed})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(outputs) != 2 || outputs[0].Count != 2 || outputs[1].Count != 1 {
+		t.Fatalf("unexpected outputs: %+v", outputs)
+	}
+	if last := progress[len(progress)-1]; last != [2]int{7, 4} {
+		t.Errorf("final progress = %v, want 7 processed and 4 failed", last)
+	}
+
+	// The Observation's reference to the legacy id points to the created Patient.
+	var obs map[string]interface{}
+	for key, res := range store {
+		if strings.HasPrefix(key, "Observation/") {
+			obs = res
+		}
+	}
+	ref, _ := obs["subject"].(map[string]interface{})["reference"].(string)
+	if patient := store[ref]; patient == nil || patient["name"].([]interface{})[0].(map[string]interface{})["family"] != "Smith" {
+		t.Errorf("expected the subject %q to be the imported Smith", ref)
+	}
+
+	if len(errorFiles) != 2 || errorFiles[0].Type != "OperationOutcome" || errorFiles[0].Count != 3 || errorFiles[1].Count != 1 {
+		t.Fatalf("unexpected error files: %+v", errorFiles)
+	}
+	patientErrors := readErrorFile(t, blobs, errorFiles[0])
+	for i, want := range []string{"line 4: line is not a JSON object", "line 5: resourceType \"Observation\" does not match", "line 6: Required field 'name' is missing"} {
+		if !strings.HasPrefix(patientErrors[i].Issue[0].Diagnostics, want) {
+			t.Errorf("error %d = %q, want prefix %q", i, patientErrors[i].Issue[0].Diagnostics, want)
+		}
+	}
+	if obsErrors := readErrorFile(t, blobs, errorFiles[1]); !strings.Contains(obsErrors[0].Issue[0].Diagnostics, "line 2: subject must reference a Patient") {
+		t.Errorf("expected the handler's error, got %+v", obsErrors[0])
+	}
+}
+
+func TestImportLoader_UpdatesExistingResources(t *testing.T) {
+	loader, blobs, store := newTestImportLoader()
+	store["Patient/p1"] = map[string]interface{}{"resourceType": "Patient", "id": "p1", "gender": "male"}
+
+	req := &ImportRequest{Input: []ImportInput{{Type: "Patient", URL: uploadNDJSON(t, blobs,
+		`{"resourceType":"Patient","id":"p1","gender":"female","name":[{"family":"Smith"}]}`,
+	)}}}
+	if _, _, err := loader.Load(context.Background(), req, nil); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	p := store["Patient/p1"]
+	if p["gender"] != "female" || p["meta"].(map[string]interface{})["versionId"] != "2" {
+		t.Errorf("expected Patient/p1 to be updated, got %v", p)
+	}
+	if len(store) != 1 {
+		t.Errorf("expected no new resources, got %d", len(store))
+	}
+}
+
+func TestImportLoader_RecordsAssignedIDs(t *testing.T) {
+	loader, blobs, store := newTestImportLoader()
+	loader.IDs = NewInMemoryImportIDStore()
+	patients := &ImportRequest{InputSource: "https://lab.example.org", Input: []ImportInput{{Type: "Patient", URL: uploadNDJSON(t, blobs,
+		`{"resourceType":"Patient","id":"legacy-1","gender":"male","name":[{"family":"Smith"}]}`,
+	)}}}
+	if _, _, err := loader.Load(context.Background(), patients, nil); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	target, _ := loader.IDs.LookupImportID(context.Background(), "https://lab.example.org", "Patient/legacy-1")
+	if store[target] == nil {
+		t.Fatalf("expected the assigned id to be recorded, got %q", target)
+	}
+
+	// A retried job updates the resource it created instead of creating it again.
+	patients.Input[0].URL = uploadNDJSON(t, blobs,
+		`{"resourceType":"Patient","id":"legacy-1","gender":"female","name":[{"family":"Smith"}]}`)
+	if _, _, err := loader.Load(context.Background(), patients, nil); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(store) != 1 || store[target]["gender"] != "female" {
+		t.Errorf("expected %s to be updated without duplicates, got %v", target, store)
+	}
+
+	// A later import from the same source references it by its source id.
+	observations := &ImportRequest{InputSource: "https://lab.example.org", Input: []ImportInput{{Type: "Observation", URL: uploadNDJSON(t, blobs,
+		`{"resourceType":"Observation","status":"final","code":{"text":"bp"},"valueString":"ok","subject":{"reference":"Patient/legacy-1"}}`,
+	)}}}
+	if _, errorFiles, err := loader.Load(context.Background(), observations, nil); err != nil || len(errorFiles) != 0 {
+		t.Fatalf("Load failed: %v %+v", err, errorFiles)
+	}
+	for key, res := range store {
+		if strings.HasPrefix(key, "Observation/") {
+			if ref := res["subject"].(map[string]interface{})["reference"]; ref != target {
+				t.Errorf("subject = %v, want %s", ref, target)
+			}
+		}
+	}
+}
+
+func TestImportLoader_RetriesFailedChunkLineByLine(t *testing.T) {
+	loader, blobs, store := newTestImportLoader()
+	var txs []*fakeTx
+	loader.BeginTx = func(ctx context.Context) (context.Context, TxFinisher, error) {
+		tx := &fakeTx{}
+		txs = append(txs, tx)
+		return ctx, tx, nil
+	}
+	loader.ChunkSize = 3
+
+	good := `{"resourceType":"Observation","status":"final","code":{"text":"bp"},"valueString":"ok","subject":{"reference":"Patient/p1"}}`
+	bad := `{"resourceType":"Observation","status":"final","code":{"text":"bp"},"valueString":"ok","subject":{"reference":"Group/1"}}`
+	req := &ImportRequest{Input: []ImportInput{{Type: "Observation", URL: uploadNDJSON(t, blobs, good, bad, good, good)}}}
+
+	outputs, errorFiles, err := loader.Load(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if outputs[0].Count != 3 || len(errorFiles) != 1 || errorFiles[0].Count != 1 {
+		t.Fatalf("unexpected result: %+v, %+v", outputs, errorFiles)
+	}
+
+	// The first chunk is rolled back and retried as three transactions; the
+	// last line is a chunk of its own.
+	if len(txs) != 5 || !txs[0].rolledBack || txs[0].committed {
+		t.Fatalf("expected the failed chunk to be rolled back, got %d transactions", len(txs))
+	}
+	for i, want := range []bool{true, false, true, true} {
+		if txs[i+1].committed != want {
+			t.Errorf("transaction %d committed = %v, want %v", i+1, txs[i+1].committed, want)
+		}
+	}
+	if n := len(store); n < 3 {
+		t.Errorf("expected the good lines to be stored, got %d resources", n)
+	}
+}
+
+func TestImportLoader_Inputs(t *testing.T) {
+	loader, blobs, _ := newTestImportLoader()
+	dir := t.TempDir()
+	loader.FileRoot = dir
+	path := filepath.Join(dir, "Patient.ndjson")
+	if err := os.WriteFile(path, []byte(`{"resourceType":"Patient","name":[{"family":"File"}]}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "Patient.ndjson")
+	if err := os.WriteFile(outside, []byte(`{"resourceType":"Patient"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	blobURL := uploadNDJSON(t, blobs, `{"resourceType":"Patient","name":[{"family":"Blob"}]}`)
+
+	tests := []struct {
+		url     string
+		wantErr string
+	}{
+		{"file://" + filepath.ToSlash(path), ""},
+		{blobURL, ""},
+		{"/api/v1/blobs/" + strings.TrimPrefix(blobURL, "blob://"), ""},
+		{"file://" + filepath.ToSlash(outside), "outside the import directory"},
+		{"file://" + filepath.ToSlash(filepath.Join(dir, "..", filepath.Base(filepath.Dir(outside)), "Patient.ndjson")), "outside the import directory"},
+		{"blob://missing", "blob not found"},
+		{"https://example.com/Patient.ndjson", "unsupported input URL"},
+	}
+	for _, tt := range tests {
+		outputs, errorFiles, err := loader.Load(context.Background(), &ImportRequest{
+			Input: []ImportInput{{Type: "Patient", URL: tt.url}},
+		}, nil)
+		if err != nil {
+			t.Fatalf("%s: Load failed: %v", tt.url, err)
+		}
+		if tt.wantErr == "" {
+			if len(outputs) != 1 || outputs[0].Count != 1 || len(errorFiles) != 0 {
+				t.Errorf("%s: unexpected result %+v, %+v", tt.url, outputs, errorFiles)
+			}
+			continue
+		}
+		if len(outputs) != 0 || len(errorFiles) != 1 {
+			t.Errorf("%s: expected only an error file, got %+v, %+v", tt.url, outputs, errorFiles)
+			continue
+		}
+		if oo := readErrorFile(t, blobs, errorFiles[0]); !strings.Contains(oo[0].Issue[0].Diagnostics, tt.wantErr) {
+			t.Errorf("%s: error %q, want %q", tt.url, oo[0].Issue[0].Diagnostics, tt.wantErr)
+		}
+	}
+
+	loader.FileRoot = ""
+	_, errorFiles, _ := loader.Load(context.Background(), &ImportRequest{
+		Input: []ImportInput{{Type: "Patient", URL: "file://" + filepath.ToSlash(path)}},
+	}, nil)
+	if oo := readErrorFile(t, blobs, errorFiles[0]); !strings.Contains(oo[0].Issue[0].Diagnostics, "file inputs are not enabled") {
+		t.Errorf("expected file inputs to be disabled, got %q", oo[0].Issue[0].Diagnostics)
+	}
+}
+
+func TestImportLoader_StopsWhenProgressFails(t *testing.T) {
+	loader, blobs, _ := newTestImportLoader()
+	loader.ChunkSize = 1
+	line := `{"resourceType":"Patient","name":[{"family":"A"}]}`
+	req := &ImportRequest{Input: []ImportInput{{Type: "Patient", URL: uploadNDJSON(t, blobs, line, line, line)}}}
+
+	calls := 0
+	_, _, err := loader.Load(context.Background(), req, func(processed, failed int) error {
+		calls++
+		return io.ErrClosedPipe
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected the import to stop after the first chunk, got %v after %d calls", err, calls)
+	}
+}
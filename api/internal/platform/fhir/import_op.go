@@ -2,6 +2,7 @@ package fhir
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -189,10 +190,11 @@ func ParseNDJSON(data []byte) ([]json.RawMessage, error) {
 // ImportHandler returns an echo.HandlerFunc that handles POST /fhir/$import.
 //
 // The handler validates the import request, creates an async job via the
-// provided AsyncJobStore, kicks off a background goroutine to process the
-// import, and returns 202 Accepted with a Content-Location header pointing
-// to the async status endpoint.
-func ImportHandler(store AsyncJobStore) echo.HandlerFunc {
+// provided AsyncJobStore, kicks off a background goroutine that loads the
+// inputs with loader, and returns 202 Accepted with a Content-Location header
+// pointing to the async status endpoint. When the store is an AsyncJobQueue,
+// the job is left to an AsyncJobRunner running ImportJobFunc.
+func ImportHandler(store AsyncJobStore, loader *ImportLoader) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		// Only accept POST.
 		if c.Request().Method != http.MethodPost {
@@ -221,11 +223,20 @@ func ImportHandler(store AsyncJobStore) echo.HandlerFunc {
 		jobID := uuid.New().String()
 		now := time.Now().UTC()
 
+		payload, err := json.Marshal(&req)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, NewOperationOutcome(
+				IssueSeverityError, IssueTypeException,
+				fmt.Sprintf("failed to encode import request: %s", err.Error()),
+			))
+		}
 		job := &AsyncJob{
 			ID:            jobID,
 			Status:        AsyncStatusInProgress,
 			Request:       c.Request().RequestURI,
 			TransactionTS: now,
+			Kind:          "import",
+			Payload:       payload,
 		}
 
 		if err := store.Create(c.Request().Context(), job); err != nil {
@@ -235,39 +246,57 @@ func ImportHandler(store AsyncJobStore) echo.HandlerFunc {
 			))
 		}
 
-		// Process the import asynchronously. This is a mock implementation
-		// that immediately marks the job as completed.
-		go processImport(store, job.ID, &req)
+		if _, queued := store.(AsyncJobQueue); !queued {
+			go processImport(context.Background(), store, loader, job.ID, &req)
+		}
 
 		// Return 202 Accepted with Content-Location.
 		return RespondAsync(c, store, jobID)
 	}
 }
 
-// processImport is the background worker for an $import job.
-// This is a mock implementation that simulates processing the import
-// inputs and marks the job as completed.
-func processImport(store AsyncJobStore, jobID string, req *ImportRequest) {
-	// Build mock outcome: one entry per input with a count of 0 (no real data loaded).
-	var outputs []AsyncJobOutput
-	for _, inp := range req.Input {
-		outputs = append(outputs, AsyncJobOutput{
-			Type: inp.Type,
-			URL:  inp.URL,
-		})
+// ImportJobFunc returns an AsyncJobFunc that runs queued $import jobs of
+// store with loader. Register it with an AsyncJobRunner for the "import"
+// kind.
+func ImportJobFunc(store AsyncJobStore, loader *ImportLoader) AsyncJobFunc {
+	return func(ctx context.Context, jobID string, payload json.RawMessage) error {
+		var req ImportRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return fmt.Errorf("decode import request of job %s: %w", jobID, err)
+		}
+		processImport(ctx, store, loader, jobID, &req)
+		return nil
 	}
+}
 
-	// Simulate a small processing delay.
-	time.Sleep(10 * time.Millisecond)
+// processImport is the background worker for an $import job. It records the
+// loader's progress on the job after every chunk and completes the job with
+// an output per input and the error files of failed lines. The import stops
+// when the job is deleted.
+func processImport(ctx context.Context, store AsyncJobStore, loader *ImportLoader, jobID string, req *ImportRequest) {
+	progress := func(processed, failed int) error {
+		job, err := store.Get(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		job.Processed, job.Failed = processed, failed
+		return store.Update(ctx, job)
+	}
+	outputs, errorFiles, failure := loader.Load(ctx, req, progress)
 
-	job, err := store.Get(nil, jobID)
+	job, err := store.Get(ctx, jobID)
 	if err != nil {
 		return
 	}
-
-	job.Status = AsyncStatusCompleted
-	job.Output = outputs
-	_ = store.Update(nil, job)
+	if failure != nil {
+		job.Status = AsyncStatusError
+		job.Error = failure.Error()
+	} else {
+		job.Status = AsyncStatusCompleted
+		job.Output = outputs
+		job.ErrorOutput = errorFiles
+	}
+	_ = store.Update(ctx, job)
 }
 
 // ---------------------------------------------------------------------------
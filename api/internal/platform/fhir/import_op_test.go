@@ -2,6 +2,7 @@ package fhir
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -9,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ehr/ehr/internal/platform/blobstore"
 	"github.com/labstack/echo/v4"
 )
 
@@ -286,16 +288,19 @@ func TestParseNDJSON_SingleLine(t *testing.T) {
 // ImportHandler tests
 // ===========================================================================
 
-// newImportEcho creates an echo instance with the $import route registered.
-func newImportEcho(store AsyncJobStore) *echo.Echo {
+// newImportEcho creates an echo instance with the $import route registered,
+// loading into a fresh dispatch test server. It returns the blob store
+// holding the inputs.
+func newImportEcho(store AsyncJobStore) (*echo.Echo, blobstore.BlobStore) {
+	loader, blobs, _ := newTestImportLoader()
 	e := echo.New()
-	e.POST("/fhir/$import", ImportHandler(store))
-	return e
+	e.POST("/fhir/$import", ImportHandler(store, loader))
+	return e, blobs
 }
 
 func TestImportHandler_Returns202WithContentLocation(t *testing.T) {
 	store := NewInMemoryAsyncJobStore()
-	e := newImportEcho(store)
+	e, _ := newImportEcho(store)
 
 	reqBody := ImportRequest{
 		InputFormat: "application/fhir+ndjson",
@@ -327,7 +332,7 @@ func TestImportHandler_Returns202WithContentLocation(t *testing.T) {
 
 func TestImportHandler_RejectsInvalidRequest_EmptyInput(t *testing.T) {
 	store := NewInMemoryAsyncJobStore()
-	e := newImportEcho(store)
+	e, _ := newImportEcho(store)
 
 	reqBody := ImportRequest{
 		InputFormat: "application/fhir+ndjson",
@@ -356,7 +361,7 @@ func TestImportHandler_RejectsInvalidRequest_EmptyInput(t *testing.T) {
 
 func TestImportHandler_RejectsInvalidRequest_BadFormat(t *testing.T) {
 	store := NewInMemoryAsyncJobStore()
-	e := newImportEcho(store)
+	e, _ := newImportEcho(store)
 
 	reqBody := ImportRequest{
 		InputFormat: "text/csv",
@@ -379,7 +384,7 @@ func TestImportHandler_RejectsInvalidRequest_BadFormat(t *testing.T) {
 
 func TestImportHandler_RejectsInvalidRequest_InvalidResourceType(t *testing.T) {
 	store := NewInMemoryAsyncJobStore()
-	e := newImportEcho(store)
+	e, _ := newImportEcho(store)
 
 	reqBody := ImportRequest{
 		InputFormat: "application/fhir+ndjson",
@@ -402,7 +407,7 @@ func TestImportHandler_RejectsInvalidRequest_InvalidResourceType(t *testing.T) {
 
 func TestImportHandler_RejectsMalformedJSON(t *testing.T) {
 	store := NewInMemoryAsyncJobStore()
-	e := newImportEcho(store)
+	e, _ := newImportEcho(store)
 
 	req := httptest.NewRequest(http.MethodPost, "/fhir/$import", strings.NewReader("{invalid"))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
@@ -417,13 +422,13 @@ func TestImportHandler_RejectsMalformedJSON(t *testing.T) {
 
 func TestImportHandler_CreatesAsyncJob(t *testing.T) {
 	store := NewInMemoryAsyncJobStore()
-	e := newImportEcho(store)
+	e, blobs := newImportEcho(store)
 
 	reqBody := ImportRequest{
 		InputFormat: "application/fhir+ndjson",
 		Input: []ImportInput{
-			{Type: "Patient", URL: "https://example.com/Patient.ndjson"},
-			{Type: "Observation", URL: "https://example.com/Observation.ndjson"},
+			{Type: "Patient", URL: uploadNDJSON(t, blobs, `{"resourceType":"Patient","id":"a","name":[{"family":"A"}]}`)},
+			{Type: "Observation", URL: uploadNDJSON(t, blobs, `{"resourceType":"Observation","status":"final","code":{"text":"x"},"valueString":"ok","subject":{"reference":"Patient/a"}}`)},
 		},
 	}
 	body, _ := json.Marshal(reqBody)
@@ -442,7 +447,7 @@ func TestImportHandler_CreatesAsyncJob(t *testing.T) {
 	contentLocation := rec.Header().Get("Content-Location")
 	jobID := strings.TrimPrefix(contentLocation, "/_async/")
 
-	// Wait briefly for the mock import goroutine to complete.
+	// Wait briefly for the import goroutine to complete.
 	time.Sleep(50 * time.Millisecond)
 
 	// Verify the job exists in the store and has been completed.
@@ -456,19 +461,24 @@ func TestImportHandler_CreatesAsyncJob(t *testing.T) {
 	if len(job.Output) != 2 {
 		t.Errorf("expected 2 output entries, got %d", len(job.Output))
 	}
+	for _, out := range job.Output {
+		if out.Count != 1 {
+			t.Errorf("expected 1 %s loaded, got %d", out.Type, out.Count)
+		}
+	}
 }
 
 func TestImportHandler_MultipleInputTypes(t *testing.T) {
 	store := NewInMemoryAsyncJobStore()
-	e := newImportEcho(store)
+	e, blobs := newImportEcho(store)
 
 	reqBody := ImportRequest{
 		InputFormat: "application/fhir+ndjson",
 		InputSource: "https://source.example.com",
 		Input: []ImportInput{
-			{Type: "Patient", URL: "https://example.com/Patient.ndjson"},
-			{Type: "Condition", URL: "https://example.com/Condition.ndjson"},
-			{Type: "Encounter", URL: "https://example.com/Encounter.ndjson"},
+			{Type: "Patient", URL: uploadNDJSON(t, blobs, `{"resourceType":"Patient","name":[{"family":"A"}]}`)},
+			{Type: "Patient", URL: uploadNDJSON(t, blobs, `{"resourceType":"Patient","name":[{"family":"A"}]}`, `{"resourceType":"Patient","name":[{"family":"A"}]}`)},
+			{Type: "Observation", URL: uploadNDJSON(t, blobs)},
 		},
 		StorageDetail: &StorageDetail{Type: "https"},
 	}
@@ -498,3 +508,33 @@ func TestImportHandler_MultipleInputTypes(t *testing.T) {
 		t.Errorf("expected 3 output entries, got %d", len(job.Output))
 	}
 }
+
+func TestImportJobFunc_CompletesQueuedJob(t *testing.T) {
+	store := NewInMemoryAsyncJobStore()
+	loader, blobs, _ := newTestImportLoader()
+	payload, _ := json.Marshal(ImportRequest{
+		InputFormat: "application/fhir+ndjson",
+		Input:       []ImportInput{{Type: "Patient", URL: uploadNDJSON(t, blobs, `{"resourceType":"Patient","name":[{"family":"A"}]}`)}},
+	})
+	job := &AsyncJob{ID: "import-1", Status: AsyncStatusInProgress, Kind: "import", Payload: payload}
+	_ = store.Create(context.Background(), job)
+
+	if err := ImportJobFunc(store, loader)(context.Background(), job.ID, payload); err != nil {
+		t.Fatalf("ImportJobFunc failed: %v", err)
+	}
+
+	got, _ := store.Get(context.Background(), job.ID)
+	if got.Status != AsyncStatusCompleted {
+		t.Errorf("expected job status %q, got %q", AsyncStatusCompleted, got.Status)
+	}
+	if len(got.Output) != 1 || got.Output[0].Type != "Patient" {
+		t.Errorf("expected one Patient output, got %+v", got.Output)
+	}
+}
+
+func TestImportJobFunc_RejectsMalformedPayload(t *testing.T) {
+	loader, _, _ := newTestImportLoader()
+	if err := ImportJobFunc(NewInMemoryAsyncJobStore(), loader)(context.Background(), "import-1", []byte("{")); err == nil {
+		t.Error("expected an error for a malformed payload")
+	}
+}
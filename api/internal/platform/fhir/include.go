@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -19,6 +20,10 @@ type IncludeRegistry struct {
 	references map[string]map[string]IncludeRef
 	// revRefs maps target ResourceType -> source resource type + param
 	revRefs map[string][]RevIncludeRef
+	// resources serves types without a registered fetcher
+	resources *ResourceRegistry
+	// limits bounds ResolveSearchIncludes
+	limits IncludeLimits
 }
 
 // IncludeRef defines a reference from one resource type to another.
@@ -49,6 +54,51 @@ func (r *IncludeRegistry) RegisterFetcher(resourceType string, fetcher ResourceF
 	r.fetchers[resourceType] = fetcher
 }
 
+// SetResourceRegistry makes the registry fetch resource types that have no
+// fetcher of their own through resources, and resolve versioned and
+// canonical references with it.
+func (r *IncludeRegistry) SetResourceRegistry(resources *ResourceRegistry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resources = resources
+}
+
+// fetcher returns the fetcher registered for resourceType, or nil.
+func (r *IncludeRegistry) fetcher(resourceType string) ResourceFetcher {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.fetcherLocked(resourceType)
+}
+
+// fetcherLocked is fetcher for callers holding r.mu.
+func (r *IncludeRegistry) fetcherLocked(resourceType string) ResourceFetcher {
+	if f, ok := r.fetchers[resourceType]; ok {
+		return f
+	}
+	if r.resources != nil {
+		if ops, ok := r.resources.Lookup(resourceType); ok && ops.Read != nil {
+			return ops.Read
+		}
+	}
+	return nil
+}
+
+// resolveReference returns the resource a reference points to. Without a
+// ResourceRegistry only "Type/id" references are resolved.
+func (r *IncludeRegistry) resolveReference(ctx context.Context, ref string) (map[string]interface{}, error) {
+	r.mu.RLock()
+	resources := r.resources
+	r.mu.RUnlock()
+	refType, refID := parseReference(ref)
+	if f := r.fetcher(refType); f != nil && !strings.Contains(refID, "/") {
+		return f(ctx, refID)
+	}
+	if resources != nil {
+		return resources.ResolveReference(ctx, ref)
+	}
+	return nil, fmt.Errorf("no fetcher registered for reference %s", ref)
+}
+
 // RegisterReference registers a reference from sourceType.searchParam -> targetType.
 func (r *IncludeRegistry) RegisterReference(sourceType, searchParam, targetType string) {
 	r.mu.Lock()
@@ -75,6 +125,40 @@ func (r *IncludeRegistry) GetRevIncludeTargets(resourceType string) []RevInclude
 	return r.revRefs[resourceType]
 }
 
+// SearchIncludes returns the _include values supported for a resource type,
+// "Type:param" for each registered reference and "*", as advertised in the
+// CapabilityStatement.
+func (r *IncludeRegistry) SearchIncludes(resourceType string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var values []string
+	for param := range r.references[resourceType] {
+		values = append(values, resourceType+":"+param)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Strings(values)
+	return append(values, "*")
+}
+
+// SearchRevIncludes returns the _revinclude values supported for a resource
+// type, "Source:param" for each registered reference to it.
+func (r *IncludeRegistry) SearchRevIncludes(resourceType string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	seen := make(map[string]bool)
+	var values []string
+	for _, rev := range r.revRefs[resourceType] {
+		if v := rev.SourceType + ":" + rev.SearchParam; !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
 // ResolveIncludes processes _include parameters and fetches referenced resources.
 // includeParam format: "ResourceType:searchParam" or "ResourceType:searchParam:targetType"
 func (r *IncludeRegistry) ResolveIncludes(ctx context.Context, resources []interface{}, includeParams []string) ([]BundleEntry, error) {
@@ -131,8 +215,8 @@ func (r *IncludeRegistry) ResolveIncludes(ctx context.Context, resources []inter
 	// Fetch referenced resources
 	var entries []BundleEntry
 	for _, req := range toFetch {
-		fetcher, ok := r.fetchers[req.resourceType]
-		if !ok {
+		fetcher := r.fetcherLocked(req.resourceType)
+		if fetcher == nil {
 			continue
 		}
 		resource, err := fetcher(ctx, req.id)
@@ -207,6 +291,8 @@ func searchParamToFields(param string) []string {
 		"practitioner": {"practitioner"},
 		"organization": {"managingOrganization", "organization"},
 		"location":    {"location"},
+		"medication":  {"medicationReference", "medication"},
+		"partof":      {"partOf"},
 	}
 
 	if fields, ok := mappings[param]; ok {
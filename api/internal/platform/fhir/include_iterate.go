@@ -0,0 +1,318 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Default budgets for resolving the includes of one search.
+const (
+	DefaultIncludeMaxDepth     = 3
+	DefaultIncludeMaxResources = 500
+)
+
+// IncludeLimits bounds the work done to resolve the includes of one search.
+type IncludeLimits struct {
+	// MaxDepth is the number of :iterate rounds run after the first round
+	// of includes.
+	MaxDepth int
+	// MaxResources is the number of resources that may be included.
+	MaxResources int
+}
+
+// SetLimits sets the depth and size budget of ResolveSearchIncludes. Zero
+// values select DefaultIncludeMaxDepth and DefaultIncludeMaxResources.
+func (r *IncludeRegistry) SetLimits(limits IncludeLimits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits = limits
+}
+
+// includeSpec is one parsed _include or _revinclude value.
+type includeSpec struct {
+	rev     bool
+	iterate bool
+	source  string // resource type holding the reference, "*" for any
+	param   string // reference search parameter, "*" for every registered one
+	target  string // target resource type, "" for the registered target
+}
+
+// parseIncludeSpecs parses the _include, _revinclude and their :iterate (or
+// STU3 :recurse) parameters. "*" and "Type:*" select every reference
+// registered for the type.
+func parseIncludeSpecs(params url.Values) []includeSpec {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var specs []includeSpec
+	for _, key := range keys {
+		base, modifier, _ := strings.Cut(key, ":")
+		if base != "_include" && base != "_revinclude" {
+			continue
+		}
+		if modifier != "" && modifier != "iterate" && modifier != "recurse" {
+			continue
+		}
+		for _, value := range params[key] {
+			spec := includeSpec{rev: base == "_revinclude", iterate: modifier != ""}
+			if value == "*" {
+				spec.source, spec.param = "*", "*"
+				specs = append(specs, spec)
+				continue
+			}
+			parts := strings.SplitN(value, ":", 3)
+			if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+				continue
+			}
+			spec.source, spec.param = parts[0], parts[1]
+			if len(parts) == 3 {
+				spec.target = parts[2]
+			}
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// ResolveSearchIncludes resolves the _include and _revinclude parameters in
+// params for the matched resources of a search. Plain includes apply to the
+// matches; :iterate includes also apply to the resources they and the other
+// includes bring in, round after round, until nothing new is found or the
+// depth budget is spent. Each resource is included once, so reference
+// cycles end the iteration. Reverse includes search the referencing type
+// through the ResourceRegistry.
+//
+// The returned entries carry search.mode "include". truncated reports that
+// the depth or size budget (see SetLimits) stopped the resolution early.
+func (r *IncludeRegistry) ResolveSearchIncludes(ctx context.Context, matches []interface{}, params url.Values) (entries []BundleEntry, truncated bool) {
+	specs := parseIncludeSpecs(params)
+	if len(specs) == 0 || len(matches) == 0 {
+		return nil, false
+	}
+	iterate := false
+	for _, spec := range specs {
+		iterate = iterate || spec.iterate
+	}
+
+	r.mu.RLock()
+	maxDepth, maxResources := r.limits.MaxDepth, r.limits.MaxResources
+	r.mu.RUnlock()
+	if maxDepth <= 0 {
+		maxDepth = DefaultIncludeMaxDepth
+	}
+	if maxResources <= 0 {
+		maxResources = DefaultIncludeMaxResources
+	}
+
+	seen := make(map[string]bool)
+	var frontier []map[string]interface{}
+	for _, m := range matches {
+		if res, ok := toMap(m); ok {
+			seen[resourceKey(res)] = true
+			frontier = append(frontier, res)
+		}
+	}
+
+	for depth := 0; len(frontier) > 0; depth++ {
+		if depth > 0 && !iterate {
+			break
+		}
+		// Past the depth budget one more round is run only to learn whether
+		// it would have included anything.
+		exhausted := depth > maxDepth
+		var next []map[string]interface{}
+		for _, spec := range specs {
+			if depth > 0 && !spec.iterate {
+				continue
+			}
+			for _, res := range frontier {
+				var found []map[string]interface{}
+				if spec.rev {
+					found = r.revIncluded(ctx, res, spec)
+				} else {
+					found = r.included(ctx, res, spec)
+				}
+				for _, inc := range found {
+					key := resourceKey(inc)
+					if key == "" || seen[key] {
+						continue
+					}
+					if exhausted {
+						return entries, true
+					}
+					if len(entries) >= maxResources {
+						return entries, true
+					}
+					seen[key] = true
+					raw, err := json.Marshal(inc)
+					if err != nil {
+						continue
+					}
+					entries = append(entries, BundleEntry{
+						FullURL:  key,
+						Resource: raw,
+						Search:   &BundleSearch{Mode: "include"},
+					})
+					next = append(next, inc)
+				}
+			}
+		}
+		if exhausted {
+			break
+		}
+		frontier = next
+	}
+	return entries, false
+}
+
+// included returns the resources that res references through the
+// parameters selected by spec. r.mu is held only while the registered
+// references and fetchers are read, not across the fetches.
+func (r *IncludeRegistry) included(ctx context.Context, res map[string]interface{}, spec includeSpec) []map[string]interface{} {
+	resourceType, _ := res["resourceType"].(string)
+	if spec.source != "*" && spec.source != resourceType {
+		return nil
+	}
+	type includeTarget struct {
+		searchParam string
+		targetType  string
+		fetcher     ResourceFetcher
+	}
+	var targets []includeTarget
+	r.mu.RLock()
+	refs := r.references[resourceType]
+	var defs []IncludeRef
+	if spec.param == "*" {
+		for _, def := range refs {
+			defs = append(defs, def)
+		}
+		sort.Slice(defs, func(i, j int) bool { return defs[i].SearchParam < defs[j].SearchParam })
+	} else if def, ok := refs[spec.param]; ok {
+		defs = append(defs, def)
+	}
+	for _, def := range defs {
+		targetType := def.TargetType
+		if spec.target != "" {
+			targetType = spec.target
+		}
+		if fetcher := r.fetcherLocked(targetType); fetcher != nil {
+			targets = append(targets, includeTarget{def.SearchParam, targetType, fetcher})
+		}
+	}
+	r.mu.RUnlock()
+
+	var found []map[string]interface{}
+	for _, target := range targets {
+		for _, id := range includeReferenceIDs(res, target.searchParam, target.targetType) {
+			inc, err := target.fetcher(ctx, id)
+			if err != nil || inc == nil {
+				continue // Skip resources that can't be fetched
+			}
+			found = append(found, inc)
+		}
+	}
+	return found
+}
+
+// revIncluded returns the resources that reference res through the
+// parameters selected by spec. r.mu is held only while the registered
+// reverse references are read, not across the searches.
+func (r *IncludeRegistry) revIncluded(ctx context.Context, res map[string]interface{}, spec includeSpec) []map[string]interface{} {
+	resourceType, _ := res["resourceType"].(string)
+	id, _ := res["id"].(string)
+	if resourceType == "" || id == "" {
+		return nil
+	}
+	if spec.target != "" && spec.target != resourceType {
+		return nil
+	}
+
+	r.mu.RLock()
+	resources := r.resources
+	var sources []RevIncludeRef
+	selected := make(map[RevIncludeRef]bool)
+	for _, rev := range r.revRefs[resourceType] {
+		if selected[rev] {
+			continue
+		}
+		if spec.source == "*" || (rev.SourceType == spec.source && (spec.param == "*" || rev.SearchParam == spec.param)) {
+			selected[rev] = true
+			sources = append(sources, rev)
+		}
+	}
+	r.mu.RUnlock()
+	if resources == nil {
+		return nil
+	}
+
+	var found []map[string]interface{}
+	for _, rev := range sources {
+		matches, err := resources.Search(ctx, rev.SourceType, url.Values{rev.SearchParam: {resourceType + "/" + id}})
+		if err != nil {
+			continue
+		}
+		found = append(found, matches...)
+	}
+	return found
+}
+
+// includeReferenceIDs returns the ids of the targetType resources referenced
+// by the fields behind searchParam, following single references, lists of
+// references and choice-type "Reference" elements such as
+// medicationReference. Relative, absolute and versioned references are
+// accepted.
+func includeReferenceIDs(res map[string]interface{}, searchParam, targetType string) []string {
+	var ids []string
+	add := func(v interface{}) {
+		ref, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		s, _ := ref["reference"].(string)
+		rt, id, _, ok := parseLiteralReference(s)
+		if !ok || (targetType != "" && rt != targetType) {
+			return
+		}
+		for _, existing := range ids {
+			if existing == id {
+				return
+			}
+		}
+		ids = append(ids, id)
+	}
+	for _, field := range searchParamToFields(searchParam) {
+		switch v := res[field].(type) {
+		case []interface{}:
+			for _, item := range v {
+				add(item)
+			}
+		default:
+			add(v)
+		}
+	}
+	return ids
+}
+
+// resourceKey returns "Type/id" for a resource, or "" if either is missing.
+func resourceKey(res map[string]interface{}) string {
+	resourceType, _ := res["resourceType"].(string)
+	id, _ := res["id"].(string)
+	if resourceType == "" || id == "" {
+		return ""
+	}
+	return resourceType + "/" + id
+}
+
+// includeTruncatedEntry is the searchset entry that warns that the includes
+// of a search are incomplete.
+func includeTruncatedEntry() BundleEntry {
+	raw, _ := json.Marshal(NewOperationOutcome(IssueSeverityWarning, IssueTypeTooCostly,
+		"include resolution stopped at the configured depth or size budget; included resources may be incomplete"))
+	return BundleEntry{Resource: raw, Search: &BundleSearch{Mode: "outcome"}}
+}
@@ -0,0 +1,284 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newIterateTestRegistry returns an IncludeRegistry whose resources are read
+// from store through a ResourceRegistry. Searches match a reference search
+// parameter against the field of the same name.
+func newIterateTestRegistry(store map[string]map[string]interface{}) *IncludeRegistry {
+	resources := NewResourceRegistry()
+	for _, rt := range []string{"MedicationRequest", "Medication", "Organization", "Patient", "Observation", "Provenance"} {
+		rt := rt
+		resources.Register(rt, ResourceOps{
+			Read: func(_ context.Context, id string) (map[string]interface{}, error) {
+				res, ok := store[rt+"/"+id]
+				if !ok {
+					return nil, ErrReferenceNotFound
+				}
+				return res, nil
+			},
+			Search: func(_ context.Context, params url.Values) ([]map[string]interface{}, error) {
+				var out []map[string]interface{}
+				for key, res := range store {
+					if !strings.HasPrefix(key, rt+"/") {
+						continue
+					}
+					for param := range params {
+						for _, field := range searchParamToFields(param) {
+							if ref, ok := res[field].(map[string]interface{}); ok && ref["reference"] == params.Get(param) {
+								out = append(out, res)
+							}
+						}
+					}
+				}
+				return out, nil
+			},
+		})
+	}
+	reg := NewIncludeRegistry()
+	reg.SetResourceRegistry(resources)
+	reg.RegisterReference("MedicationRequest", "medication", "Medication")
+	reg.RegisterReference("MedicationRequest", "subject", "Patient")
+	reg.RegisterReference("Medication", "manufacturer", "Organization")
+	reg.RegisterReference("Organization", "partof", "Organization")
+	reg.RegisterReference("Observation", "subject", "Patient")
+	reg.RegisterReference("Provenance", "target", "Observation")
+	return reg
+}
+
+func ref(reference string) map[string]interface{} {
+	return map[string]interface{}{"reference": reference}
+}
+
+func includedKeys(entries []BundleEntry) string {
+	keys := make([]string, len(entries))
+	for i, entry := range entries {
+		keys[i] = entry.FullURL
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestParseIncludeSpecs(t *testing.T) {
+	specs := parseIncludeSpecs(url.Values{
+		"_include":            {"MedicationRequest:medication", "*", "bad"},
+		"_include:iterate":    {"Medication:manufacturer:Organization"},
+		"_revinclude:recurse": {"Provenance:*"},
+		"_include:unknown":    {"Patient:link"},
+	})
+	if len(specs) != 4 {
+		t.Fatalf("expected 4 specs, got %+v", specs)
+	}
+	want := []includeSpec{
+		{source: "MedicationRequest", param: "medication"},
+		{source: "*", param: "*"},
+		{iterate: true, source: "Medication", param: "manufacturer", target: "Organization"},
+		{rev: true, iterate: true, source: "Provenance", param: "*"},
+	}
+	for i, spec := range specs {
+		if spec != want[i] {
+			t.Errorf("spec %d = %+v, want %+v", i, spec, want[i])
+		}
+	}
+}
+
+func TestResolveSearchIncludes_Iterate(t *testing.T) {
+	store := map[string]map[string]interface{}{
+		"Medication/m1":   {"resourceType": "Medication", "id": "m1", "manufacturer": ref("Organization/o1")},
+		"Organization/o1": {"resourceType": "Organization", "id": "o1"},
+	}
+	reg := newIterateTestRegistry(store)
+	matches := []interface{}{map[string]interface{}{
+		"resourceType": "MedicationRequest", "id": "mr1", "medicationReference": ref("Medication/m1"),
+	}}
+
+	entries, truncated := reg.ResolveSearchIncludes(context.Background(), matches, url.Values{
+		"_include":         {"MedicationRequest:medication"},
+		"_include:iterate": {"Medication:manufacturer"},
+	})
+	if truncated || includedKeys(entries) != "Medication/m1,Organization/o1" {
+		t.Fatalf("entries = %s, truncated = %v", includedKeys(entries), truncated)
+	}
+	for _, entry := range entries {
+		if entry.Search == nil || entry.Search.Mode != "include" {
+			t.Errorf("%s: expected search.mode include", entry.FullURL)
+		}
+	}
+
+	// Without :iterate the manufacturer of an included Medication is not followed.
+	entries, _ = reg.ResolveSearchIncludes(context.Background(), matches, url.Values{
+		"_include": {"MedicationRequest:medication", "Medication:manufacturer"},
+	})
+	if includedKeys(entries) != "Medication/m1" {
+		t.Errorf("entries = %s", includedKeys(entries))
+	}
+}
+
+func TestResolveSearchIncludes_Cycle(t *testing.T) {
+	store := map[string]map[string]interface{}{
+		"Organization/o1": {"resourceType": "Organization", "id": "o1", "partOf": ref("Organization/o2")},
+		"Organization/o2": {"resourceType": "Organization", "id": "o2", "partOf": ref("Organization/o1")},
+	}
+	reg := newIterateTestRegistry(store)
+	entries, truncated := reg.ResolveSearchIncludes(context.Background(),
+		[]interface{}{store["Organization/o1"]},
+		url.Values{"_include:iterate": {"Organization:partof"}})
+	if truncated || includedKeys(entries) != "Organization/o2" {
+		t.Errorf("entries = %s, truncated = %v", includedKeys(entries), truncated)
+	}
+}
+
+func TestResolveSearchIncludes_Budgets(t *testing.T) {
+	store := map[string]map[string]interface{}{}
+	for _, id := range []string{"o1", "o2", "o3", "o4", "o5"} {
+		store["Organization/"+id] = map[string]interface{}{"resourceType": "Organization", "id": id}
+	}
+	for i, id := range []string{"o1", "o2", "o3", "o4"} {
+		next := []string{"o2", "o3", "o4", "o5"}[i]
+		store["Organization/"+id]["partOf"] = ref("Organization/" + next)
+	}
+	reg := newIterateTestRegistry(store)
+	params := url.Values{"_include:iterate": {"Organization:partof"}}
+	matches := []interface{}{store["Organization/o1"]}
+
+	reg.SetLimits(IncludeLimits{MaxDepth: 2})
+	entries, truncated := reg.ResolveSearchIncludes(context.Background(), matches, params)
+	if !truncated || includedKeys(entries) != "Organization/o2,Organization/o3,Organization/o4" {
+		t.Errorf("depth budget: entries = %s, truncated = %v", includedKeys(entries), truncated)
+	}
+
+	reg.SetLimits(IncludeLimits{MaxResources: 2})
+	entries, truncated = reg.ResolveSearchIncludes(context.Background(), matches, params)
+	if !truncated || includedKeys(entries) != "Organization/o2,Organization/o3" {
+		t.Errorf("size budget: entries = %s, truncated = %v", includedKeys(entries), truncated)
+	}
+
+	reg.SetLimits(IncludeLimits{})
+	entries, truncated = reg.ResolveSearchIncludes(context.Background(), matches, params)
+	if truncated || len(entries) != 4 {
+		t.Errorf("default budget: entries = %s, truncated = %v", includedKeys(entries), truncated)
+	}
+}
+
+func TestResolveSearchIncludes_FetchesWithoutLock(t *testing.T) {
+	reg := newIterateTestRegistry(map[string]map[string]interface{}{})
+	// A fetcher that needs the registry's write lock deadlocks if the
+	// registry is locked across the fetch.
+	reg.RegisterFetcher("Medication", func(_ context.Context, id string) (map[string]interface{}, error) {
+		reg.RegisterFetcher("Organization", reg.fetcher("Organization"))
+		return map[string]interface{}{"resourceType": "Medication", "id": id}, nil
+	})
+	matches := []interface{}{map[string]interface{}{
+		"resourceType": "MedicationRequest", "id": "mr1", "medicationReference": ref("Medication/m1"),
+	}}
+
+	done := make(chan string)
+	go func() {
+		entries, _ := reg.ResolveSearchIncludes(context.Background(), matches, url.Values{"_include": {"MedicationRequest:medication"}})
+		done <- includedKeys(entries)
+	}()
+	select {
+	case keys := <-done:
+		if keys != "Medication/m1" {
+			t.Errorf("entries = %s", keys)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("include resolution held the registry lock across the fetch")
+	}
+}
+
+func TestResolveSearchIncludes_Wildcards(t *testing.T) {
+	store := map[string]map[string]interface{}{
+		"Medication/m1":   {"resourceType": "Medication", "id": "m1"},
+		"Patient/p1":      {"resourceType": "Patient", "id": "p1"},
+		"Organization/o1": {"resourceType": "Organization", "id": "o1"},
+	}
+	reg := newIterateTestRegistry(store)
+	matches := []interface{}{map[string]interface{}{
+		"resourceType": "MedicationRequest", "id": "mr1",
+		"medicationReference": ref("Medication/m1"), "subject": ref("Patient/p1"),
+	}}
+
+	entries, _ := reg.ResolveSearchIncludes(context.Background(), matches, url.Values{"_include": {"*"}})
+	if includedKeys(entries) != "Medication/m1,Patient/p1" {
+		t.Errorf("*: entries = %s", includedKeys(entries))
+	}
+	entries, _ = reg.ResolveSearchIncludes(context.Background(), matches, url.Values{"_include": {"MedicationRequest:*"}})
+	if includedKeys(entries) != "Medication/m1,Patient/p1" {
+		t.Errorf("MedicationRequest:*: entries = %s", includedKeys(entries))
+	}
+	entries, _ = reg.ResolveSearchIncludes(context.Background(), matches, url.Values{"_include": {"Observation:*"}})
+	if len(entries) != 0 {
+		t.Errorf("Observation:*: entries = %s", includedKeys(entries))
+	}
+}
+
+func TestResolveSearchIncludes_RevIncludeIterate(t *testing.T) {
+	store := map[string]map[string]interface{}{
+		"Observation/obs1": {"resourceType": "Observation", "id": "obs1", "subject": ref("Patient/p1")},
+		"Provenance/prov1": {"resourceType": "Provenance", "id": "prov1", "target": ref("Observation/obs1")},
+	}
+	reg := newIterateTestRegistry(store)
+	matches := []interface{}{map[string]interface{}{"resourceType": "Patient", "id": "p1"}}
+
+	entries, _ := reg.ResolveSearchIncludes(context.Background(), matches, url.Values{
+		"_revinclude":         {"Observation:subject"},
+		"_revinclude:iterate": {"Provenance:target"},
+	})
+	if includedKeys(entries) != "Observation/obs1,Provenance/prov1" {
+		t.Errorf("entries = %s", includedKeys(entries))
+	}
+}
+
+func TestIncludeMiddleware_IterateAndTruncation(t *testing.T) {
+	store := map[string]map[string]interface{}{
+		"Medication/m1":   {"resourceType": "Medication", "id": "m1", "manufacturer": ref("Organization/o1")},
+		"Organization/o1": {"resourceType": "Organization", "id": "o1"},
+	}
+	reg := newIterateTestRegistry(store)
+	bundleData := searchBundleJSON(map[string]interface{}{
+		"resourceType": "MedicationRequest", "id": "mr1", "medicationReference": ref("Medication/m1"),
+	})
+	serve := func() *Bundle {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet,
+			"/fhir/MedicationRequest?_include=MedicationRequest:medication&_include:iterate=Medication:manufacturer", nil)
+		rec := httptest.NewRecorder()
+		handler := IncludeMiddleware(reg)(func(c echo.Context) error {
+			return c.JSONBlob(http.StatusOK, bundleData)
+		})
+		if err := handler(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		var bundle Bundle
+		if err := json.Unmarshal(rec.Body.Bytes(), &bundle); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		return &bundle
+	}
+
+	bundle := serve()
+	if len(bundle.Entry) != 3 || includedKeys(bundle.Entry[1:]) != "Medication/m1,Organization/o1" {
+		t.Errorf("entries = %s", includedKeys(bundle.Entry))
+	}
+
+	reg.SetLimits(IncludeLimits{MaxResources: 1})
+	bundle = serve()
+	if len(bundle.Entry) != 3 {
+		t.Fatalf("expected the match, one include and an outcome, got %d entries", len(bundle.Entry))
+	}
+	last := bundle.Entry[2]
+	if last.Search == nil || last.Search.Mode != "outcome" || !strings.Contains(string(last.Resource), IssueTypeTooCostly) {
+		t.Errorf("expected a too-costly outcome entry, got %s", last.Resource)
+	}
+}
@@ -4,24 +4,26 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
 // IncludeMiddleware creates Echo middleware that processes _include and _revinclude
 // parameters on FHIR search responses. It intercepts search bundle responses and
-// resolves included resources by appending them to the bundle entries.
+// resolves included resources by appending them to the bundle entries. The
+// :iterate forms and wildcards are supported (see ResolveSearchIncludes); when
+// the include budget is exhausted a warning OperationOutcome entry is added.
 //
 // The middleware is a no-op when _include/_revinclude query params are absent,
 // adding zero overhead to normal search requests.
 func IncludeMiddleware(registry *IncludeRegistry) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			includeParams := c.QueryParams()["_include"]
-			revIncludeParams := c.QueryParams()["_revinclude"]
-
-			// Fast path: no include params, pass through with zero overhead.
-			if len(includeParams) == 0 && len(revIncludeParams) == 0 {
+			// Fast path: no include params, or a _summary=count search that
+			// returns no resources, pass through with zero overhead.
+			if !hasIncludeParams(c.QueryParams()) || ProjectionFromContext(c.Request().Context()).CountOnly() {
 				return next(c)
 			}
 
@@ -73,28 +75,25 @@ func IncludeMiddleware(registry *IncludeRegistry) echo.MiddlewareFunc {
 				return flushOriginal(origWriter, rec)
 			}
 
-			ctx := c.Request().Context()
-
-			// Resolve _include params.
-			if len(includeParams) > 0 {
-				included, err := registry.ResolveIncludes(ctx, matchedResources, includeParams)
-				if err == nil && len(included) > 0 {
-					bundle.Entry = append(bundle.Entry, included...)
+			// Resolve _include and _revinclude params. Resources the handler
+			// already added to the bundle (e.g. its own _revinclude results)
+			// are not repeated.
+			included, truncated := registry.ResolveSearchIncludes(c.Request().Context(), matchedResources, c.QueryParams())
+			present := make(map[string]bool, len(bundle.Entry))
+			for _, entry := range bundle.Entry {
+				var res map[string]interface{}
+				if err := json.Unmarshal(entry.Resource, &res); err == nil {
+					present[resourceKey(res)] = true
 				}
-				// On error, we still return the original results (resilient behavior).
 			}
-
-			// Resolve _revinclude params. _revinclude uses the same mechanism
-			// but the semantics differ: the include params reference resources that
-			// point back to the matched resources. We pass them through the same
-			// ResolveIncludes path because the registry already has the reverse
-			// reference definitions registered.
-			if len(revIncludeParams) > 0 {
-				revIncluded, err := registry.ResolveIncludes(ctx, matchedResources, revIncludeParams)
-				if err == nil && len(revIncluded) > 0 {
-					bundle.Entry = append(bundle.Entry, revIncluded...)
+			for _, entry := range included {
+				if !present[entry.FullURL] {
+					bundle.Entry = append(bundle.Entry, entry)
 				}
 			}
+			if truncated {
+				bundle.Entry = append(bundle.Entry, includeTruncatedEntry())
+			}
 
 			// Re-serialize and write the augmented bundle.
 			augmented, err := json.Marshal(bundle)
@@ -110,6 +109,18 @@ func IncludeMiddleware(registry *IncludeRegistry) echo.MiddlewareFunc {
 	}
 }
 
+// hasIncludeParams reports whether params contain _include or _revinclude,
+// with or without a modifier.
+func hasIncludeParams(params url.Values) bool {
+	for k := range params {
+		base, _, _ := strings.Cut(k, ":")
+		if base == "_include" || base == "_revinclude" {
+			return true
+		}
+	}
+	return false
+}
+
 // responseRecorder captures the response body and status code written by
 // downstream handlers so the middleware can inspect and modify the output.
 type responseRecorder struct {
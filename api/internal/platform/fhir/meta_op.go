@@ -3,11 +3,16 @@ package fhir
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/db"
 )
 
 // MetaStore defines persistence operations for resource-level meta management.
@@ -84,6 +89,204 @@ func (s *InMemoryMetaStore) DeleteMeta(_ context.Context, resourceType, resource
 	return cloneMeta(existing), nil
 }
 
+// ErrMetaResourceNotFound is returned by ExtrasMetaStore for a resource that
+// has no current version, either because it does not exist or because it has
+// been deleted.
+var ErrMetaResourceNotFound = errors.New("resource not found")
+
+// MetaHistoryStore is the part of HistoryRepository used by ExtrasMetaStore.
+type MetaHistoryStore interface {
+	ListVersions(ctx context.Context, resourceType, resourceID string, limit, offset int) ([]*HistoryEntry, int, error)
+	UpdateCurrentMeta(ctx context.Context, resourceType, resourceID string, meta *Meta) (*HistoryEntry, error)
+}
+
+// ExtrasMetaStore is a MetaStore that keeps the profiles, security labels and
+// tags of a resource with its other unmodeled elements in the extras sidecar.
+// From there they are merged into every read and snapshot of the resource and
+// matched by the _tag, _security and _profile search parameters. Changes made
+// with $meta-add and $meta-delete amend the snapshot of the current version in
+// resource_history rather than creating a new version.
+//
+// Each change reads, amends and saves the meta in one database transaction
+// holding the lock of the resource in locker, the lock taken by updates of
+// the resource, so that concurrent changes do not overwrite each other.
+type ExtrasMetaStore struct {
+	extras  ExtrasStore
+	history MetaHistoryStore
+	locker  ResourceLocker
+}
+
+// NewExtrasMetaStore creates a new ExtrasMetaStore. locker may be nil when
+// the store is not shared between requests.
+func NewExtrasMetaStore(extras ExtrasStore, history MetaHistoryStore, locker ResourceLocker) *ExtrasMetaStore {
+	return &ExtrasMetaStore{extras: extras, history: history, locker: locker}
+}
+
+// GetMeta returns the meta of the current version of a resource.
+func (s *ExtrasMetaStore) GetMeta(ctx context.Context, resourceType, resourceID string) (*Meta, error) {
+	entries, _, err := s.history.ListVersions(ctx, resourceType, resourceID, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 || entries[0].Action == "delete" {
+		return nil, ErrMetaResourceNotFound
+	}
+	extras, err := s.extras.GetExtras(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return withVersion(metaFromExtras(extras), entries[0]), nil
+}
+
+// AddMeta adds profiles, security labels and tags to a resource.
+func (s *ExtrasMetaStore) AddMeta(ctx context.Context, resourceType, resourceID string, meta *Meta) (*Meta, error) {
+	return s.updateMeta(ctx, resourceType, resourceID, func(m *Meta) {
+		m.Profile = mergeStrings(m.Profile, meta.Profile)
+		m.Security = mergeCodings(m.Security, meta.Security)
+		m.Tag = mergeCodings(m.Tag, meta.Tag)
+	})
+}
+
+// DeleteMeta removes profiles, security labels and tags from a resource.
+func (s *ExtrasMetaStore) DeleteMeta(ctx context.Context, resourceType, resourceID string, meta *Meta) (*Meta, error) {
+	return s.updateMeta(ctx, resourceType, resourceID, func(m *Meta) {
+		m.Profile = removeStrings(m.Profile, meta.Profile)
+		m.Security = removeCodings(m.Security, meta.Security)
+		m.Tag = removeCodings(m.Tag, meta.Tag)
+	})
+}
+
+// updateMeta applies a change to the meta of a resource. Outside a caller's
+// transaction it starts one on the request's tenant connection, so that the
+// advisory lock taken by a PGResourceLocker is held until the change is
+// committed.
+func (s *ExtrasMetaStore) updateMeta(ctx context.Context, resourceType, resourceID string, apply func(*Meta)) (*Meta, error) {
+	var tx pgx.Tx
+	if db.TxFromContext(ctx) == nil && db.ConnFromContext(ctx) != nil {
+		var err error
+		if ctx, tx, err = db.WithTx(ctx); err != nil {
+			return nil, err
+		}
+		defer tx.Rollback(context.Background())
+	}
+	if s.locker != nil {
+		unlock, err := s.locker.Lock(ctx, resourceType+"/"+resourceID)
+		if err != nil {
+			return nil, fmt.Errorf("lock %s/%s: %w", resourceType, resourceID, err)
+		}
+		defer unlock()
+	}
+
+	extras, err := s.extras.GetExtras(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	meta := metaFromExtras(extras)
+	apply(meta)
+
+	entry, err := s.history.UpdateCurrentMeta(ctx, resourceType, resourceID, meta)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.extras.PutExtras(ctx, resourceType, resourceID, setExtrasMeta(extras, meta)); err != nil {
+		return nil, err
+	}
+	if tx != nil {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("commit meta of %s/%s: %w", resourceType, resourceID, err)
+		}
+	}
+	return withVersion(meta, entry), nil
+}
+
+// withVersion sets the versionId and lastUpdated of meta from a history entry.
+func withVersion(meta *Meta, entry *HistoryEntry) *Meta {
+	meta.VersionID = strconv.Itoa(entry.VersionID)
+	meta.LastUpdated = entry.Timestamp
+	return meta
+}
+
+// metaElements returns the profiles, security labels and tags of meta as
+// generic FHIR JSON elements, omitting empty ones.
+func metaElements(meta *Meta) map[string]interface{} {
+	data, _ := json.Marshal(struct {
+		Profile  []string `json:"profile,omitempty"`
+		Security []Coding `json:"security,omitempty"`
+		Tag      []Coding `json:"tag,omitempty"`
+	}{meta.Profile, meta.Security, meta.Tag})
+	out := map[string]interface{}{}
+	_ = json.Unmarshal(data, &out)
+	return out
+}
+
+// metaFromExtras returns the profiles, security labels and tags held in the
+// stored extras of a resource.
+func metaFromExtras(extras map[string]interface{}) *Meta {
+	meta := &Meta{}
+	elements, ok := extras["meta"].(map[string]interface{})
+	if !ok {
+		return meta
+	}
+	data, err := json.Marshal(metaElementsOf(elements))
+	if err == nil {
+		_ = json.Unmarshal(data, meta)
+	}
+	return meta
+}
+
+// metaElementsOf returns the profile, security and tag elements of a meta map.
+func metaElementsOf(elements map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, 3)
+	for _, k := range []string{"profile", "security", "tag"} {
+		if v, ok := elements[k]; ok {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// setExtrasMeta returns a copy of extras whose meta profiles, security labels
+// and tags are those of meta. Other meta elements, such as meta.source, are
+// kept; an empty meta is dropped.
+func setExtrasMeta(extras map[string]interface{}, meta *Meta) map[string]interface{} {
+	out := deepCopyMap(extras)
+	if out == nil {
+		out = map[string]interface{}{}
+	}
+	elements, _ := out["meta"].(map[string]interface{})
+	if elements == nil {
+		elements = map[string]interface{}{}
+	}
+	for _, k := range []string{"profile", "security", "tag"} {
+		delete(elements, k)
+	}
+	for k, v := range metaElements(meta) {
+		elements[k] = v
+	}
+	if len(elements) == 0 {
+		delete(out, "meta")
+	} else {
+		out["meta"] = elements
+	}
+	return out
+}
+
+// retainMeta carries the tags and security labels of the stored extras of a
+// resource over to the extras of its new version, so that an update that
+// omits them does not drop the labels used for routing and access control.
+// They are removed with $meta-delete. Profiles are not retained, since an
+// update may change what the resource conforms to.
+func retainMeta(extras, stored map[string]interface{}) map[string]interface{} {
+	prev := metaFromExtras(stored)
+	if len(prev.Tag) == 0 && len(prev.Security) == 0 {
+		return extras
+	}
+	meta := metaFromExtras(extras)
+	meta.Security = mergeCodings(meta.Security, prev.Security)
+	meta.Tag = mergeCodings(meta.Tag, prev.Tag)
+	return setExtrasMeta(extras, meta)
+}
+
 // mergeStrings adds values from src to dst if not already present.
 func mergeStrings(dst, src []string) []string {
 	set := make(map[string]bool, len(dst))
@@ -196,6 +399,9 @@ func (h *MetaHandler) GetMeta(c echo.Context) error {
 	}
 
 	meta, err := h.store.GetMeta(c.Request().Context(), resourceType, resourceID)
+	if errors.Is(err, ErrMetaResourceNotFound) {
+		return c.JSON(http.StatusNotFound, NotFoundOutcome(resourceType, resourceID))
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
 	}
@@ -218,6 +424,9 @@ func (h *MetaHandler) AddMeta(c echo.Context) error {
 	}
 
 	result, err := h.store.AddMeta(c.Request().Context(), resourceType, resourceID, inputMeta)
+	if errors.Is(err, ErrMetaResourceNotFound) {
+		return c.JSON(http.StatusNotFound, NotFoundOutcome(resourceType, resourceID))
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
 	}
@@ -240,6 +449,9 @@ func (h *MetaHandler) DeleteMeta(c echo.Context) error {
 	}
 
 	result, err := h.store.DeleteMeta(c.Request().Context(), resourceType, resourceID, inputMeta)
+	if errors.Is(err, ErrMetaResourceNotFound) {
+		return c.JSON(http.StatusNotFound, NotFoundOutcome(resourceType, resourceID))
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
 	}
@@ -575,3 +575,157 @@ func TestMetaHandler_AddMeta_SecurityLabels(t *testing.T) {
 		t.Fatalf("expected 1 profile, got %v", valueMeta["profile"])
 	}
 }
+
+// =========== ExtrasMetaStore Tests ===========
+
+// fakeMetaHistory is an in-memory MetaHistoryStore holding the current
+// version of each resource.
+type fakeMetaHistory struct {
+	current map[string]*HistoryEntry
+}
+
+func (f *fakeMetaHistory) ListVersions(_ context.Context, resourceType, resourceID string, _, _ int) ([]*HistoryEntry, int, error) {
+	if h, ok := f.current[resourceType+"/"+resourceID]; ok {
+		return []*HistoryEntry{h}, 1, nil
+	}
+	return nil, 0, nil
+}
+
+func (f *fakeMetaHistory) UpdateCurrentMeta(_ context.Context, resourceType, resourceID string, meta *Meta) (*HistoryEntry, error) {
+	h, ok := f.current[resourceType+"/"+resourceID]
+	if !ok || h.Action == "delete" {
+		return nil, ErrMetaResourceNotFound
+	}
+	var res map[string]interface{}
+	_ = json.Unmarshal(h.Resource, &res)
+	res["meta"] = metaElements(meta)
+	h.Resource, _ = json.Marshal(res)
+	return h, nil
+}
+
+func newTestExtrasMetaStore() (*ExtrasMetaStore, *InMemoryExtrasStore, *fakeMetaHistory) {
+	extras := NewInMemoryExtrasStore()
+	history := &fakeMetaHistory{current: map[string]*HistoryEntry{
+		"Observation/o1": {ResourceType: "Observation", ResourceID: "o1", VersionID: 3, Action: "update",
+			Resource: json.RawMessage(`{"resourceType":"Observation","id":"o1"}`)},
+		"Observation/gone": {ResourceType: "Observation", ResourceID: "gone", VersionID: 2, Action: "delete",
+			Resource: json.RawMessage(`null`)},
+	}}
+	return NewExtrasMetaStore(extras, history, nil), extras, history
+}
+
+func TestExtrasMetaStore_LocksResource(t *testing.T) {
+	_, extras, history := newTestExtrasMetaStore()
+	locker := newRecordingLocker()
+	store := NewExtrasMetaStore(extras, history, locker)
+
+	if _, err := store.AddMeta(context.Background(), "Observation", "o1", &Meta{Tag: []Coding{{System: "urn:queue", Code: "triage"}}}); err != nil {
+		t.Fatalf("AddMeta: %v", err)
+	}
+	if _, err := store.DeleteMeta(context.Background(), "Observation", "o1", &Meta{Tag: []Coding{{System: "urn:queue", Code: "triage"}}}); err != nil {
+		t.Fatalf("DeleteMeta: %v", err)
+	}
+	if len(locker.locked) != 2 || locker.locked[0] != "Observation/o1" || locker.locked[1] != "Observation/o1" {
+		t.Errorf("expected both changes to lock Observation/o1, got %v", locker.locked)
+	}
+	if n := locker.heldCount(); n != 0 {
+		t.Errorf("expected the locks to be released, %d still held", n)
+	}
+}
+
+func TestExtrasMetaStore_AddAndDelete(t *testing.T) {
+	store, extras, history := newTestExtrasMetaStore()
+	ctx := context.Background()
+	_ = extras.PutExtras(ctx, "Observation", "o1", map[string]interface{}{
+		"meta":     map[string]interface{}{"source": "urn:feed"},
+		"language": "fr",
+	})
+
+	meta, err := store.AddMeta(ctx, "Observation", "o1", &Meta{
+		Tag:      []Coding{{System: "urn:queue", Code: "triage"}},
+		Security: []Coding{{System: "http://terminology.hl7.org/CodeSystem/v3-Confidentiality", Code: "R"}},
+	})
+	if err != nil {
+		t.Fatalf("AddMeta: %v", err)
+	}
+	if meta.VersionID != "3" || len(meta.Tag) != 1 || len(meta.Security) != 1 {
+		t.Errorf("unexpected meta: %+v", meta)
+	}
+
+	stored, _ := extras.GetExtras(ctx, "Observation", "o1")
+	storedMeta := stored["meta"].(map[string]interface{})
+	if storedMeta["source"] != "urn:feed" || stored["language"] != "fr" {
+		t.Errorf("expected other extras to be kept, got %v", stored)
+	}
+	if tags, _ := storedMeta["tag"].([]interface{}); len(tags) != 1 {
+		t.Errorf("expected tag in extras, got %v", storedMeta)
+	}
+	if !strings.Contains(string(history.current["Observation/o1"].Resource), `"code":"triage"`) {
+		t.Errorf("expected current version snapshot to carry the tag, got %s", history.current["Observation/o1"].Resource)
+	}
+
+	meta, err = store.DeleteMeta(ctx, "Observation", "o1", &Meta{Tag: []Coding{{System: "urn:queue", Code: "triage"}}})
+	if err != nil {
+		t.Fatalf("DeleteMeta: %v", err)
+	}
+	if len(meta.Tag) != 0 || len(meta.Security) != 1 {
+		t.Errorf("expected tag removed and security kept, got %+v", meta)
+	}
+
+	got, err := store.GetMeta(ctx, "Observation", "o1")
+	if err != nil {
+		t.Fatalf("GetMeta: %v", err)
+	}
+	if len(got.Tag) != 0 || len(got.Security) != 1 || got.VersionID != "3" {
+		t.Errorf("unexpected meta: %+v", got)
+	}
+}
+
+func TestExtrasMetaStore_NotFound(t *testing.T) {
+	store, _, _ := newTestExtrasMetaStore()
+	ctx := context.Background()
+	for _, id := range []string{"missing", "gone"} {
+		if _, err := store.GetMeta(ctx, "Observation", id); err != ErrMetaResourceNotFound {
+			t.Errorf("GetMeta(%s): expected ErrMetaResourceNotFound, got %v", id, err)
+		}
+		if _, err := store.AddMeta(ctx, "Observation", id, &Meta{Tag: []Coding{{Code: "x"}}}); err != ErrMetaResourceNotFound {
+			t.Errorf("AddMeta(%s): expected ErrMetaResourceNotFound, got %v", id, err)
+		}
+	}
+}
+
+func TestMetaHandler_GetMeta_NotFound(t *testing.T) {
+	store, _, _ := newTestExtrasMetaStore()
+	h := NewMetaHandler(store)
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Observation/missing/$meta", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("resourceType", "id")
+	c.SetParamValues("Observation", "missing")
+
+	if err := h.GetMeta(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestRetainMeta(t *testing.T) {
+	stored := map[string]interface{}{"meta": map[string]interface{}{
+		"tag":     []interface{}{map[string]interface{}{"system": "urn:queue", "code": "triage"}},
+		"profile": []interface{}{"http://example.org/old"},
+	}}
+	extras := retainMeta(map[string]interface{}{"language": "fr"}, stored)
+	meta := metaFromExtras(extras)
+	if len(meta.Tag) != 1 || meta.Tag[0].Code != "triage" {
+		t.Errorf("expected tag to be retained, got %+v", meta)
+	}
+	if len(meta.Profile) != 0 {
+		t.Errorf("expected profile not to be retained, got %+v", meta)
+	}
+	if extras["language"] != "fr" {
+		t.Errorf("expected new extras to be kept, got %v", extras)
+	}
+}
@@ -2,6 +2,7 @@ package fhir
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -24,6 +25,16 @@ func IsMetaSearchParam(name string) bool {
 	return false
 }
 
+// MetaSearchParams returns the _tag, _security and _profile search parameters
+// for advertising in the CapabilityStatement.
+func MetaSearchParams() []SearchParam {
+	return []SearchParam{
+		{Name: MetaParamTag, Type: "token", Documentation: "Tags applied to the resource (meta.tag). Supports :not and :missing."},
+		{Name: MetaParamSecurity, Type: "token", Documentation: "Security labels applied to the resource (meta.security). Supports :not and :missing."},
+		{Name: MetaParamProfile, Type: "uri", Documentation: "Profiles the resource claims to conform to (meta.profile). Supports :not and :missing."},
+	}
+}
+
 // ---------------------------------------------------------------------------
 // SQL-level meta search (for repositories that store meta as JSONB)
 // ---------------------------------------------------------------------------
@@ -111,7 +122,7 @@ func metaTokenClause(jsonCol, field, value string, argIdx int) (string, []interf
 // Usage:
 //
 //	qb := fhir.NewSearchQuery("my_resource", cols)
-//	qb.ApplyParams(params, mySearchConfigs)
+//	qb.ApplyParams(ctx, params, mySearchConfigs)
 //	fhir.AddMetaSearchSQL(qb, params, "resource_json")
 func AddMetaSearchSQL(qb *SearchQuery, params map[string]string, jsonCol string) {
 	for _, p := range []string{MetaParamTag, MetaParamSecurity, MetaParamProfile} {
@@ -123,6 +134,111 @@ func AddMetaSearchSQL(qb *SearchQuery, params map[string]string, jsonCol string)
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Sidecar meta search (for the typed domain tables)
+// ---------------------------------------------------------------------------
+
+// MetaSearchSQL returns the WHERE conditions and bind arguments for the _tag,
+// _security and _profile parameters in params, numbered from $idx. The
+// domain tables do not store meta. The tags, security labels and profiles a
+// client supplies are kept in the resource_extras sidecar (see ExtrasStore
+// and ExtrasMetaStore), and those the domain model emits itself, such as the
+// US Core profile of a Patient, in resource_modeled_meta (see
+// ModeledMetaStore). Each condition selects the table's fhir_id from both
+// with a jsonpath predicate backed by the sidecars' GIN indexes.
+//
+// The :not and :missing modifiers are supported. A parameter with any other
+// modifier yields a condition that matches nothing.
+func MetaSearchSQL(resourceType string, params map[string]string, idx int) ([]string, []interface{}) {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if base, _ := ParseParamModifier(name); IsMetaSearchParam(base) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var where []string
+	var args []interface{}
+	for _, name := range names {
+		clause, a := metaSearchCondition(resourceType, name, params[name], idx)
+		where = append(where, clause)
+		args = append(args, a...)
+		idx += len(a)
+	}
+	return where, args
+}
+
+// metaSearchCondition builds the condition for one meta search parameter.
+func metaSearchCondition(resourceType, name, value string, idx int) (string, []interface{}) {
+	base, modifier := ParseParamModifier(name)
+	field := strings.TrimPrefix(base, "_")
+
+	negate := false
+	var extrasPath, modeledPath string
+	switch modifier {
+	case "":
+		extrasPath, modeledPath = metaJSONPath("$.meta", field, value), metaJSONPath("$", field, value)
+	case ModifierNot:
+		negate = true
+		extrasPath, modeledPath = metaJSONPath("$.meta", field, value), metaJSONPath("$", field, value)
+	case ModifierMissing:
+		negate = value == "true"
+		extrasPath, modeledPath = fmt.Sprintf("exists($.meta.%s)", field), fmt.Sprintf("exists($.%s)", field)
+	default:
+		return "1=0", nil
+	}
+	if extrasPath == "" {
+		return "1=0", nil
+	}
+
+	op, join := "IN", "OR"
+	if negate {
+		op, join = "NOT IN", "AND"
+	}
+	return fmt.Sprintf(
+		"(fhir_id %s (SELECT resource_id FROM resource_extras WHERE resource_type = $%d AND elements @@ $%d::jsonpath)"+
+			" %s fhir_id %s (SELECT resource_id FROM resource_modeled_meta WHERE resource_type = $%d AND meta @@ $%d::jsonpath))",
+		op, idx, idx+1, join, op, idx, idx+2), []interface{}{resourceType, extrasPath, modeledPath}
+}
+
+// metaJSONPath returns a jsonpath predicate matching any of the comma
+// separated values of a meta search parameter against <root>.<field>, where
+// root is the path of the meta object, or "" if there is no value. Tokens
+// follow the usual system|code forms; a |code token only matches codings
+// without a system.
+func metaJSONPath(root, field, value string) string {
+	var alts []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		if field == "profile" {
+			alts = append(alts, "@ == "+jsonPathString(v))
+			continue
+		}
+		if !strings.Contains(v, "|") {
+			alts = append(alts, "@.code == "+jsonPathString(v))
+			continue
+		}
+		parts := strings.SplitN(v, "|", 2)
+		switch {
+		case parts[0] == "" && parts[1] == "":
+			continue
+		case parts[0] == "":
+			alts = append(alts, "(@.code == "+jsonPathString(parts[1])+" && !exists(@.system))")
+		case parts[1] == "":
+			alts = append(alts, "@.system == "+jsonPathString(parts[0]))
+		default:
+			alts = append(alts, "(@.system == "+jsonPathString(parts[0])+" && @.code == "+jsonPathString(parts[1])+")")
+		}
+	}
+	if len(alts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("exists(%s.%s ? (%s))", root, field, strings.Join(alts, " || "))
+}
+
 // ---------------------------------------------------------------------------
 // In-memory meta search filter (for repositories using individual columns)
 // ---------------------------------------------------------------------------
@@ -1,6 +1,8 @@
 package fhir
 
 import (
+	"context"
+	"strings"
 	"testing"
 )
 
@@ -641,3 +643,60 @@ func TestCodingMatches_EmptyBoth(t *testing.T) {
 		t.Error("empty CodingMatch should not match any coding")
 	}
 }
+
+func TestMetaSearchSQL(t *testing.T) {
+	where, args := MetaSearchSQL("Observation", map[string]string{
+		"status":        "final",
+		"_tag":          "urn:queue|triage,|local",
+		"_security:not": "R",
+		"_profile":      "http://example.org/fhir/StructureDefinition/vitals",
+	}, 3)
+
+	if len(where) != 3 || len(args) != 9 {
+		t.Fatalf("expected 3 conditions and 9 args, got %v %v", where, args)
+	}
+	if where[0] != "(fhir_id IN (SELECT resource_id FROM resource_extras WHERE resource_type = $3 AND elements @@ $4::jsonpath)"+
+		" OR fhir_id IN (SELECT resource_id FROM resource_modeled_meta WHERE resource_type = $3 AND meta @@ $5::jsonpath))" {
+		t.Errorf("unexpected _profile condition: %s", where[0])
+	}
+	if !strings.HasPrefix(where[1], "(fhir_id NOT IN (") || !strings.Contains(where[1], " AND fhir_id NOT IN (") || !strings.Contains(where[1], "$8") {
+		t.Errorf("unexpected _security:not condition: %s", where[1])
+	}
+	if args[1] != `exists($.meta.profile ? (@ == "http://example.org/fhir/StructureDefinition/vitals"))` {
+		t.Errorf("unexpected _profile path: %v", args[1])
+	}
+	if args[2] != `exists($.profile ? (@ == "http://example.org/fhir/StructureDefinition/vitals"))` {
+		t.Errorf("unexpected modeled _profile path: %v", args[2])
+	}
+	if args[4] != `exists($.meta.security ? (@.code == "R"))` {
+		t.Errorf("unexpected _security path: %v", args[4])
+	}
+	if args[7] != `exists($.meta.tag ? ((@.system == "urn:queue" && @.code == "triage") || (@.code == "local" && !exists(@.system))))` {
+		t.Errorf("unexpected _tag path: %v", args[7])
+	}
+}
+
+func TestMetaSearchSQL_Modifiers(t *testing.T) {
+	where, args := MetaSearchSQL("Patient", map[string]string{"_tag:missing": "true"}, 1)
+	if len(where) != 1 || !strings.HasPrefix(where[0], "(fhir_id NOT IN (") || args[1] != "exists($.meta.tag)" || args[2] != "exists($.tag)" {
+		t.Errorf("unexpected :missing condition: %v %v", where, args)
+	}
+	where, args = MetaSearchSQL("Patient", map[string]string{"_tag:below": "x"}, 1)
+	if len(where) != 1 || where[0] != "1=0" || len(args) != 0 {
+		t.Errorf("expected unsupported modifier to match nothing, got %v %v", where, args)
+	}
+}
+
+func TestSearchQuery_ApplyParams_Meta(t *testing.T) {
+	q := NewSearchQuery("medication_request", "id")
+	q.ApplyParams(context.Background(), map[string]string{"_security": "R"}, nil)
+	if !strings.Contains(q.CountSQL(), "resource_extras WHERE resource_type = $1 AND elements @@ $2::jsonpath") {
+		t.Errorf("unexpected SQL: %s", q.CountSQL())
+	}
+	if !strings.Contains(q.CountSQL(), "resource_modeled_meta WHERE resource_type = $1 AND meta @@ $3::jsonpath") {
+		t.Errorf("modeled meta not searched: %s", q.CountSQL())
+	}
+	if args := q.CountArgs(); len(args) != 3 || args[0] != "MedicationRequest" {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
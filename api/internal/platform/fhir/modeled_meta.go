@@ -0,0 +1,148 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// ModeledMetaStore persists, for each resource, the meta.profile, meta.tag
+// and meta.security elements its domain model emits itself, such as the US
+// Core profile of a Patient or the category profile of a Condition. The
+// extras sidecar holds only the meta a client supplied, so _profile, _tag
+// and _security searches match both (see MetaSearchSQL).
+type ModeledMetaStore interface {
+	// PutModeledMeta replaces the modeled meta of a resource; an empty meta
+	// removes it.
+	PutModeledMeta(ctx context.Context, resourceType, resourceID string, meta map[string]interface{}) error
+	DeleteModeledMeta(ctx context.Context, resourceType, resourceID string) error
+}
+
+// modeledMetaElements returns the profile, tag and security elements of the
+// meta of resource, the ToFHIR output of a domain model.
+func modeledMetaElements(resource interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var m struct {
+		Meta map[string]interface{} `json:"meta"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	out := make(map[string]interface{})
+	for _, field := range []string{"profile", "tag", "security"} {
+		if v, ok := m.Meta[field].([]interface{}); ok && len(v) > 0 {
+			out[field] = v
+		}
+	}
+	return out, nil
+}
+
+// InMemoryModeledMetaStore is a thread-safe in-memory implementation of
+// ModeledMetaStore.
+type InMemoryModeledMetaStore struct {
+	mu   sync.RWMutex
+	data map[string]map[string]interface{} // key: "resourceType/resourceID"
+}
+
+// NewInMemoryModeledMetaStore creates a new InMemoryModeledMetaStore.
+func NewInMemoryModeledMetaStore() *InMemoryModeledMetaStore {
+	return &InMemoryModeledMetaStore{data: make(map[string]map[string]interface{})}
+}
+
+// GetModeledMeta returns a copy of the stored meta of a resource.
+func (s *InMemoryModeledMetaStore) GetModeledMeta(resourceType, resourceID string) map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if m, ok := s.data[resourceType+"/"+resourceID]; ok {
+		return deepCopyMap(m)
+	}
+	return nil
+}
+
+// PutModeledMeta replaces the stored meta of a resource.
+func (s *InMemoryModeledMetaStore) PutModeledMeta(_ context.Context, resourceType, resourceID string, meta map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := resourceType + "/" + resourceID
+	if len(meta) == 0 {
+		delete(s.data, key)
+		return nil
+	}
+	s.data[key] = deepCopyMap(meta)
+	return nil
+}
+
+// DeleteModeledMeta removes the stored meta of a resource.
+func (s *InMemoryModeledMetaStore) DeleteModeledMeta(_ context.Context, resourceType, resourceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, resourceType+"/"+resourceID)
+	return nil
+}
+
+// =========== PostgreSQL store ===========
+
+// ModeledMetaRepository stores modeled meta in the shared
+// resource_modeled_meta table, searched with jsonpath predicates backed by a
+// GIN index.
+type ModeledMetaRepository struct{}
+
+// NewModeledMetaRepository creates a new ModeledMetaRepository.
+func NewModeledMetaRepository() *ModeledMetaRepository {
+	return &ModeledMetaRepository{}
+}
+
+func (r *ModeledMetaRepository) conn(ctx context.Context) historyQuerier {
+	if tx := db.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	if c := db.ConnFromContext(ctx); c != nil {
+		return c
+	}
+	return nil
+}
+
+// PutModeledMeta replaces the modeled meta of a resource.
+func (r *ModeledMetaRepository) PutModeledMeta(ctx context.Context, resourceType, resourceID string, meta map[string]interface{}) error {
+	if len(meta) == 0 {
+		return r.DeleteModeledMeta(ctx, resourceType, resourceID)
+	}
+	q := r.conn(ctx)
+	if q == nil {
+		return fmt.Errorf("no database connection in context")
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("marshal modeled meta: %w", err)
+	}
+	_, err = q.Exec(ctx, `
+		INSERT INTO resource_modeled_meta (resource_type, resource_id, meta, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (resource_type, resource_id)
+		DO UPDATE SET meta = EXCLUDED.meta, updated_at = NOW()`,
+		resourceType, resourceID, data)
+	if err != nil {
+		return fmt.Errorf("save modeled meta: %w", err)
+	}
+	return nil
+}
+
+// DeleteModeledMeta removes the modeled meta of a resource.
+func (r *ModeledMetaRepository) DeleteModeledMeta(ctx context.Context, resourceType, resourceID string) error {
+	q := r.conn(ctx)
+	if q == nil {
+		return fmt.Errorf("no database connection in context")
+	}
+	_, err := q.Exec(ctx, `DELETE FROM resource_modeled_meta WHERE resource_type = $1 AND resource_id = $2`,
+		resourceType, resourceID)
+	if err != nil {
+		return fmt.Errorf("delete modeled meta: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package fhir
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestModeledMetaElements(t *testing.T) {
+	meta, err := modeledMetaElements(map[string]interface{}{
+		"resourceType": "Patient",
+		"meta": Meta{
+			VersionID:   "2",
+			LastUpdated: time.Now(),
+			Profile:     []string{"http://hl7.org/fhir/us/core/StructureDefinition/us-core-patient"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(meta) != 1 {
+		t.Fatalf("expected only the profile, got %v", meta)
+	}
+	if p, _ := meta["profile"].([]interface{}); len(p) != 1 || p[0] != "http://hl7.org/fhir/us/core/StructureDefinition/us-core-patient" {
+		t.Errorf("unexpected profile: %v", meta["profile"])
+	}
+
+	meta, err = modeledMetaElements(map[string]interface{}{"resourceType": "Basic"})
+	if err != nil || len(meta) != 0 {
+		t.Errorf("expected no meta, got %v (%v)", meta, err)
+	}
+}
+
+func TestVersionTracker_ModeledMeta(t *testing.T) {
+	vt := NewVersionTracker(NewHistoryRepository())
+	extras := NewInMemoryExtrasStore()
+	store := NewInMemoryModeledMetaStore()
+	vt.SetExtrasStore(extras)
+	vt.SetModeledMetaStore(store)
+	ctx := withPendingExtras(context.Background(), "Patient", "", map[string]interface{}{
+		"meta": map[string]interface{}{"tag": []interface{}{map[string]interface{}{"code": "vip"}}},
+	})
+
+	// no DB: the meta is saved before the history write fails
+	_ = vt.RecordCreate(ctx, "Patient", "p1", map[string]interface{}{
+		"resourceType": "Patient",
+		"meta":         Meta{Profile: []string{"http://example.org/profile"}},
+	})
+	meta := store.GetModeledMeta("Patient", "p1")
+	if p, _ := meta["profile"].([]interface{}); len(p) != 1 || p[0] != "http://example.org/profile" {
+		t.Fatalf("expected the modeled profile, got %v", meta)
+	}
+	if _, ok := meta["tag"]; ok {
+		t.Errorf("extras tags should not be saved as modeled meta: %v", meta)
+	}
+
+	_ = vt.RecordDelete(ctx, "Patient", "p1", 1)
+	if meta := store.GetModeledMeta("Patient", "p1"); meta != nil {
+		t.Errorf("expected modeled meta to be removed on delete, got %v", meta)
+	}
+}
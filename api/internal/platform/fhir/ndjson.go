@@ -2,6 +2,7 @@ package fhir
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"io"
 )
@@ -42,3 +43,41 @@ func (n *NDJSONWriter) WriteResource(resource interface{}) error {
 func (n *NDJSONWriter) Flush() error {
 	return n.w.Flush()
 }
+
+// NDJSONReader reads NDJSON input one line at a time, so that inputs of any
+// size are read in constant memory. Lines are returned as read; decoding and
+// reporting malformed lines is left to the caller.
+type NDJSONReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// NewNDJSONReader creates a new NDJSONReader that reads from r.
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	return &NDJSONReader{
+		r: bufio.NewReaderSize(r, 64*1024),
+	}
+}
+
+// Next returns the next non-blank line, with surrounding whitespace and a
+// leading byte order mark removed, and its 1-based line number. It returns
+// io.EOF after the last line.
+func (n *NDJSONReader) Next() (json.RawMessage, int, error) {
+	for {
+		data, err := n.r.ReadBytes('\n')
+		if len(data) == 0 && err != nil {
+			return nil, n.line, err
+		}
+		n.line++
+		line := bytes.TrimSpace(data)
+		if n.line == 1 {
+			line = bytes.TrimPrefix(line, []byte("\uFEFF"))
+		}
+		if len(line) > 0 {
+			return json.RawMessage(line), n.line, nil
+		}
+		if err != nil {
+			return nil, n.line, err
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -199,6 +201,33 @@ func TestNDJSONWriter_MarshalError(t *testing.T) {
 	}
 }
 
+func TestNDJSONReader_Lines(t *testing.T) {
+	input := "\uFEFF{\"id\":\"1\"}\r\n\n  \n{\"id\":\"2\"}\n{bad\n{\"id\":\"3\"}"
+	r := NewNDJSONReader(strings.NewReader(input))
+
+	want := []struct {
+		line int
+		data string
+	}{
+		{1, `{"id":"1"}`},
+		{4, `{"id":"2"}`},
+		{5, `{bad`},
+		{6, `{"id":"3"}`},
+	}
+	for _, w := range want {
+		data, line, err := r.Next()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if line != w.line || string(data) != w.data {
+			t.Errorf("got line %d %q, want line %d %q", line, data, w.line, w.data)
+		}
+	}
+	if _, _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
 // scanNDJSON parses NDJSON bytes into a slice of maps.
 func scanNDJSON(t *testing.T, data []byte) []map[string]interface{} {
 	t.Helper()
@@ -12,23 +12,26 @@ const (
 
 // OperationOutcome issue type codes per FHIR R4 spec.
 const (
-	IssueTypeInvalid     = "invalid"
-	IssueTypeStructure   = "structure"
-	IssueTypeRequired    = "required"
-	IssueTypeValue       = "value"
-	IssueTypeNotFound    = "not-found"
-	IssueTypeConflict    = "conflict"
-	IssueTypeProcessing  = "processing"
-	IssueTypeSecurity    = "security"
-	IssueTypeLogin       = "login"
-	IssueTypeThrottled   = "throttled"
-	IssueTypeNotSupported = "not-supported"
-	IssueTypeBusinessRule = "business-rule"
-	IssueTypeException   = "exception"
-	IssueTypeTimeout     = "timeout"
-	IssueTypeDuplicate   = "duplicate"
-	IssueTypeDeleted     = "deleted"
-	IssueTypeCodeInvalid = "code-invalid"
+	IssueTypeInvalid         = "invalid"
+	IssueTypeStructure       = "structure"
+	IssueTypeRequired        = "required"
+	IssueTypeValue           = "value"
+	IssueTypeNotFound        = "not-found"
+	IssueTypeConflict        = "conflict"
+	IssueTypeProcessing      = "processing"
+	IssueTypeSecurity        = "security"
+	IssueTypeLogin           = "login"
+	IssueTypeThrottled       = "throttled"
+	IssueTypeNotSupported    = "not-supported"
+	IssueTypeBusinessRule    = "business-rule"
+	IssueTypeException       = "exception"
+	IssueTypeTimeout         = "timeout"
+	IssueTypeDuplicate       = "duplicate"
+	IssueTypeDeleted         = "deleted"
+	IssueTypeCodeInvalid     = "code-invalid"
+	IssueTypeMultipleMatches = "multiple-matches"
+	IssueTypeInvariant       = "invariant"
+	IssueTypeTooCostly       = "too-costly"
 )
 
 // validSeverities is the set of valid FHIR issue severity values.
@@ -41,23 +44,26 @@ var validSeverities = map[string]bool{
 
 // validIssueTypes is the set of valid FHIR issue type codes.
 var validIssueTypes = map[string]bool{
-	IssueTypeInvalid:      true,
-	IssueTypeStructure:    true,
-	IssueTypeRequired:     true,
-	IssueTypeValue:        true,
-	IssueTypeNotFound:     true,
-	IssueTypeConflict:     true,
-	IssueTypeProcessing:   true,
-	IssueTypeSecurity:     true,
-	IssueTypeLogin:        true,
-	IssueTypeThrottled:    true,
-	IssueTypeNotSupported: true,
-	IssueTypeBusinessRule: true,
-	IssueTypeException:    true,
-	IssueTypeTimeout:      true,
-	IssueTypeDuplicate:    true,
-	IssueTypeDeleted:      true,
-	IssueTypeCodeInvalid:  true,
+	IssueTypeInvalid:         true,
+	IssueTypeStructure:       true,
+	IssueTypeRequired:        true,
+	IssueTypeValue:           true,
+	IssueTypeNotFound:        true,
+	IssueTypeConflict:        true,
+	IssueTypeProcessing:      true,
+	IssueTypeSecurity:        true,
+	IssueTypeLogin:           true,
+	IssueTypeThrottled:       true,
+	IssueTypeNotSupported:    true,
+	IssueTypeBusinessRule:    true,
+	IssueTypeException:       true,
+	IssueTypeTimeout:         true,
+	IssueTypeDuplicate:       true,
+	IssueTypeDeleted:         true,
+	IssueTypeCodeInvalid:     true,
+	IssueTypeTooCostly:       true,
+	IssueTypeMultipleMatches: true,
+	IssueTypeInvariant:       true,
 }
 
 // IsValidSeverity checks whether a severity string is a valid FHIR issue severity.
@@ -266,7 +266,7 @@ func TestIsValidSeverity(t *testing.T) {
 }
 
 func TestIsValidIssueType(t *testing.T) {
-	valid := []string{"invalid", "structure", "required", "value", "not-found", "conflict", "processing"}
+	valid := []string{"invalid", "structure", "required", "value", "not-found", "conflict", "processing", "multiple-matches", "invariant"}
 	for _, c := range valid {
 		if !IsValidIssueType(c) {
 			t.Errorf("expected %q to be valid issue type", c)
@@ -591,6 +591,12 @@ func (h *OperationRegistryHandler) RegisterRoutes(g *echo.Group) {
 	g.GET("/OperationDefinition/:id", h.Read)
 }
 
+// RegisterResources registers the OperationDefinition read and search
+// routes with r.
+func (h *OperationRegistryHandler) RegisterResources(r *ResourceRegistry) {
+	r.RegisterInteractions("OperationDefinition", "read", "search-type")
+}
+
 // Search handles GET /fhir/OperationDefinition, returning a searchset Bundle
 // of all registered operation definitions. The optional query parameters
 // "name", "code", "system", "type", "instance", and "resource" can be used
@@ -0,0 +1,365 @@
+package fhir
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Parquet output of bulk exports and ViewDefinitions.
+//
+// A Parquet file written here is a flat table: bulk export files have one
+// row per resource, with its type, id and meta.lastUpdated and the resource
+// itself as JSON, all as required UTF-8 strings; ViewDefinition files have
+// the view's columns, typed and optional. Each file holds a single row
+// group with one uncompressed, PLAIN-encoded data page per column, which
+// every Parquet reader accepts; the file metadata is written with the
+// Thrift compact protocol, as the format requires.
+
+// parquetColumn describes a column of a Parquet file.
+type parquetColumn struct {
+	name string
+	// typ is the physical type: parquetTypeBoolean, parquetTypeInt64,
+	// parquetTypeDouble or parquetTypeByteArray, which holds UTF-8 strings.
+	typ int32
+	// optional columns may hold nulls.
+	optional bool
+}
+
+// exportParquetColumns are the columns of export Parquet files.
+var exportParquetColumns = []parquetColumn{
+	{name: "resource_type", typ: parquetTypeByteArray},
+	{name: "id", typ: parquetTypeByteArray},
+	{name: "last_updated", typ: parquetTypeByteArray},
+	{name: "resource", typ: parquetTypeByteArray},
+}
+
+// Parquet format constants (parquet.thrift).
+const (
+	parquetMagic = "PAR1"
+
+	parquetTypeBoolean        = 0
+	parquetTypeInt64          = 2
+	parquetTypeDouble         = 5
+	parquetTypeByteArray      = 6
+	parquetRepetitionRequired = 0
+	parquetRepetitionOptional = 1
+	parquetConvertedUTF8      = 0
+	parquetEncodingPlain      = 0
+	parquetEncodingRLE        = 3
+	parquetCodecUncompressed  = 0
+	parquetPageData           = 0
+)
+
+// parquetRows collects the PLAIN-encoded values of the columns of a file,
+// and the definition levels of its optional columns.
+type parquetRows struct {
+	columns []parquetColumn
+	values  []bytes.Buffer
+	// defined holds, for each optional column, whether each row has a value.
+	defined [][]bool
+	// bools holds the values of boolean columns, which are bit-packed.
+	bools [][]bool
+	rows  int
+	bytes int64
+}
+
+func newParquetRows(columns []parquetColumn) *parquetRows {
+	return &parquetRows{
+		columns: columns,
+		values:  make([]bytes.Buffer, len(columns)),
+		defined: make([][]bool, len(columns)),
+		bools:   make([][]bool, len(columns)),
+	}
+}
+
+// add appends a row, with a value for each column: a string or []byte, an
+// int64, a float64 or a bool as the column's type requires, or nil in an
+// optional column. A value of another type is written as its text.
+func (p *parquetRows) add(values ...interface{}) {
+	for i, v := range values {
+		col := p.columns[i]
+		if col.optional {
+			p.defined[i] = append(p.defined[i], v != nil)
+		}
+		if v == nil {
+			if !col.optional {
+				v = ""
+			} else {
+				continue
+			}
+		}
+		buf := &p.values[i]
+		switch col.typ {
+		case parquetTypeBoolean:
+			b, _ := coerceToBool(v).(bool)
+			p.bools[i] = append(p.bools[i], b)
+			p.bytes++
+		case parquetTypeInt64:
+			n, _ := coerceToInt(v).(int64)
+			binary.Write(buf, binary.LittleEndian, n)
+			p.bytes += 8
+		case parquetTypeDouble:
+			f, _ := coerceToFloat(v).(float64)
+			binary.Write(buf, binary.LittleEndian, math.Float64bits(f))
+			p.bytes += 8
+		default:
+			var s []byte
+			switch v := v.(type) {
+			case []byte:
+				s = v
+			case string:
+				s = []byte(v)
+			default:
+				s = []byte(formatCSVValue(v))
+			}
+			var n [4]byte
+			binary.LittleEndian.PutUint32(n[:], uint32(len(s)))
+			buf.Write(n[:])
+			buf.Write(s)
+			p.bytes += int64(len(s)) + 4
+		}
+	}
+	p.rows++
+}
+
+// size returns the size of the collected column data.
+func (p *parquetRows) size() int64 {
+	return p.bytes
+}
+
+// page returns the data of the page of column i: the definition levels of
+// an optional column, then the values.
+func (p *parquetRows) page(i int) []byte {
+	var page bytes.Buffer
+	if p.columns[i].optional {
+		levels := bitPackedRun(p.defined[i])
+		var n [4]byte
+		binary.LittleEndian.PutUint32(n[:], uint32(len(levels)))
+		page.Write(n[:])
+		page.Write(levels)
+	}
+	if p.columns[i].typ == parquetTypeBoolean {
+		page.Write(packBits(p.bools[i]))
+	} else {
+		page.Write(p.values[i].Bytes())
+	}
+	return page.Bytes()
+}
+
+// bitPackedRun encodes bits as a bit-packed run of the RLE/bit-packing
+// hybrid encoding with a bit width of 1.
+func bitPackedRun(bits []bool) []byte {
+	var t thriftCompactWriter
+	t.varint(uint64((len(bits)+7)/8)<<1 | 1)
+	t.buf.Write(packBits(bits))
+	return t.buf.Bytes()
+}
+
+// packBits packs bits, least significant bit first.
+func packBits(bits []bool) []byte {
+	packed := make([]byte, (len(bits)+7)/8)
+	for i, b := range bits {
+		if b {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
+
+// writeTo writes the rows as a Parquet file.
+func (p *parquetRows) writeTo(w io.Writer) error {
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	chunks := make([]func(t *thriftCompactWriter), len(p.columns))
+	var total int64
+	for i := range p.columns {
+		data := p.page(i)
+		var header thriftCompactWriter
+		header.i32(1, parquetPageData)
+		header.i32(2, int32(len(data)))
+		header.i32(3, int32(len(data)))
+		header.beginStruct(5) // DataPageHeader
+		header.i32(1, int32(p.rows))
+		header.i32(2, parquetEncodingPlain)
+		header.i32(3, parquetEncodingRLE)
+		header.i32(4, parquetEncodingRLE)
+		header.endStruct()
+		header.stop()
+
+		offset := int64(file.Len())
+		chunkSize := int64(header.buf.Len() + len(data))
+		file.Write(header.buf.Bytes())
+		file.Write(data)
+		total += chunkSize
+
+		col := p.columns[i]
+		chunks[i] = func(t *thriftCompactWriter) { // ColumnChunk
+			t.i64(2, offset)
+			t.beginStruct(3) // ColumnMetaData
+			t.i32(1, col.typ)
+			t.i32List(2, parquetEncodingPlain, parquetEncodingRLE)
+			t.binaryList(3, col.name)
+			t.i32(4, parquetCodecUncompressed)
+			t.i64(5, int64(p.rows))
+			t.i64(6, chunkSize)
+			t.i64(7, chunkSize)
+			t.i64(9, offset)
+			t.endStruct()
+		}
+	}
+
+	var meta thriftCompactWriter // FileMetaData
+	meta.i32(1, 1)
+	meta.structList(2, 1+len(p.columns), func(t *thriftCompactWriter, i int) { // SchemaElement
+		if i == 0 {
+			t.binary(4, "schema")
+			t.i32(5, int32(len(p.columns)))
+			return
+		}
+		col := p.columns[i-1]
+		t.i32(1, col.typ)
+		if col.optional {
+			t.i32(3, parquetRepetitionOptional)
+		} else {
+			t.i32(3, parquetRepetitionRequired)
+		}
+		t.binary(4, col.name)
+		if col.typ == parquetTypeByteArray {
+			t.i32(6, parquetConvertedUTF8)
+		}
+	})
+	meta.i64(3, int64(p.rows))
+	meta.structList(4, 1, func(t *thriftCompactWriter, _ int) { // RowGroup
+		t.structList(1, len(chunks), func(t *thriftCompactWriter, i int) { chunks[i](t) })
+		t.i64(2, total)
+		t.i64(3, int64(p.rows))
+	})
+	meta.binary(6, "ehr")
+	meta.stop()
+
+	file.Write(meta.buf.Bytes())
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(meta.buf.Len()))
+	file.Write(n[:])
+	file.WriteString(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// thriftCompactWriter encodes Thrift structs with the compact protocol.
+type thriftCompactWriter struct {
+	buf    bytes.Buffer
+	lastID int16
+	stack  []int16
+}
+
+// Thrift compact protocol field types.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+func (t *thriftCompactWriter) field(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(uint64((id << 1) ^ (id >> 15)))
+	}
+	t.lastID = id
+}
+
+func (t *thriftCompactWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	t.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (t *thriftCompactWriter) zigzag32(v int32) {
+	t.varint(uint64(uint32((v << 1) ^ (v >> 31))))
+}
+
+func (t *thriftCompactWriter) listHeader(size int, elem byte) {
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elem)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elem)
+	t.varint(uint64(size))
+}
+
+func (t *thriftCompactWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.zigzag32(v)
+}
+
+func (t *thriftCompactWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thriftCompactWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftCompactWriter) i32List(id int16, values ...int32) {
+	t.field(id, thriftList)
+	t.listHeader(len(values), thriftI32)
+	for _, v := range values {
+		t.zigzag32(v)
+	}
+}
+
+func (t *thriftCompactWriter) binaryList(id int16, values ...string) {
+	t.field(id, thriftList)
+	t.listHeader(len(values), thriftBinary)
+	for _, s := range values {
+		t.varint(uint64(len(s)))
+		t.buf.WriteString(s)
+	}
+}
+
+// structList writes a list of n structs, whose fields elem writes.
+func (t *thriftCompactWriter) structList(id int16, n int, elem func(t *thriftCompactWriter, i int)) {
+	t.field(id, thriftList)
+	t.listHeader(n, thriftStruct)
+	for i := 0; i < n; i++ {
+		t.push()
+		elem(t, i)
+		t.pop()
+	}
+}
+
+func (t *thriftCompactWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.push()
+}
+
+func (t *thriftCompactWriter) endStruct() {
+	t.pop()
+}
+
+func (t *thriftCompactWriter) push() {
+	t.stack = append(t.stack, t.lastID)
+	t.lastID = 0
+}
+
+// pop ends a nested struct.
+func (t *thriftCompactWriter) pop() {
+	t.stop()
+	t.lastID = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// stop ends the top-level struct.
+func (t *thriftCompactWriter) stop() {
+	t.buf.WriteByte(0)
+}
@@ -719,7 +719,8 @@ func (e *PlanDefinitionEngine) registerPreventiveCare() {
 // PlanDefinitionHandler provides FHIR REST endpoints for PlanDefinition,
 // ActivityDefinition, and the $apply operation.
 type PlanDefinitionHandler struct {
-	engine *PlanDefinitionEngine
+	engine   *PlanDefinitionEngine
+	resolver ResourceResolver
 }
 
 // NewPlanDefinitionHandler creates a new handler.
@@ -729,6 +730,12 @@ func NewPlanDefinitionHandler(fhirpath *FHIRPathEngine) *PlanDefinitionHandler {
 	return &PlanDefinitionHandler{engine: engine}
 }
 
+// SetResolver sets the resolver used to load a subject passed to $apply by
+// reference rather than inline.
+func (h *PlanDefinitionHandler) SetResolver(resolver ResourceResolver) {
+	h.resolver = resolver
+}
+
 // RegisterRoutes registers PlanDefinition and ActivityDefinition routes.
 func (h *PlanDefinitionHandler) RegisterRoutes(fhirGroup *echo.Group) {
 	fhirGroup.GET("/PlanDefinition", h.ListPlanDefinitions)
@@ -742,6 +749,13 @@ func (h *PlanDefinitionHandler) RegisterRoutes(fhirGroup *echo.Group) {
 	fhirGroup.POST("/ActivityDefinition", h.CreateActivityDefinition)
 }
 
+// RegisterResources registers the PlanDefinition and ActivityDefinition
+// read and search routes with r.
+func (h *PlanDefinitionHandler) RegisterResources(r *ResourceRegistry) {
+	r.RegisterInteractions("PlanDefinition", "read", "search-type")
+	r.RegisterInteractions("ActivityDefinition", "read", "search-type")
+}
+
 func (h *PlanDefinitionHandler) ListPlanDefinitions(c echo.Context) error {
 	plans := h.engine.ListPlanDefinitions()
 	resources := make([]interface{}, 0, len(plans))
@@ -808,6 +822,7 @@ func (h *PlanDefinitionHandler) ApplyPlanDefinition(c echo.Context) error {
 	}
 
 	var subject map[string]interface{}
+	var subjectRef string
 	var params map[string]interface{}
 
 	if paramList, ok := body["parameter"].([]interface{}); ok {
@@ -821,6 +836,10 @@ func (h *PlanDefinitionHandler) ApplyPlanDefinition(c echo.Context) error {
 			case "subject":
 				if res, ok := param["resource"].(map[string]interface{}); ok {
 					subject = res
+				} else if vs, ok := param["valueString"].(string); ok {
+					subjectRef = vs
+				} else if ref, ok := param["valueReference"].(map[string]interface{}); ok {
+					subjectRef, _ = ref["reference"].(string)
 				}
 			case "parameters":
 				if res, ok := param["resource"].(map[string]interface{}); ok {
@@ -836,6 +855,17 @@ func (h *PlanDefinitionHandler) ApplyPlanDefinition(c echo.Context) error {
 		}
 	}
 
+	if subject == nil && subjectRef != "" {
+		if h.resolver == nil {
+			return c.JSON(http.StatusBadRequest, ErrorOutcome("subject must be passed inline as a resource"))
+		}
+		res, err := h.resolver.ResolveReference(c.Request().Context(), subjectRef)
+		if err != nil || res == nil {
+			return c.JSON(http.StatusNotFound, ErrorOutcome(fmt.Sprintf("subject %s not found", subjectRef)))
+		}
+		subject = res
+	}
+
 	if subject == nil {
 		return c.JSON(http.StatusBadRequest, ErrorOutcome("subject parameter is required"))
 	}
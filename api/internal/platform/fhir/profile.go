@@ -948,6 +948,12 @@ func (h *ProfileHandler) RegisterRoutes(g *echo.Group) {
 	g.POST("/metadata/profiles", h.RegisterCustomProfile)
 }
 
+// RegisterResources registers the StructureDefinition read and search
+// routes of the profile registry with r.
+func (h *ProfileHandler) RegisterResources(r *ResourceRegistry) {
+	r.RegisterInteractions("StructureDefinition", "read", "search-type")
+}
+
 // ListProfiles handles GET /fhir/StructureDefinition — returns all profiles as a Bundle.
 func (h *ProfileHandler) ListProfiles(c echo.Context) error {
 	profiles := h.registry.ListAll()
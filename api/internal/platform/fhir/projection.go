@@ -1,6 +1,7 @@
 package fhir
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 )
@@ -38,7 +39,7 @@ var DefaultSummaryElements = []string{
 }
 
 // ApplyElements filters a FHIR resource map to only include the specified elements
-// plus mandatory elements (resourceType, id, meta).
+// plus mandatory elements (resourceType, id, meta), and marks it SUBSETTED.
 func ApplyElements(resource map[string]interface{}, elements string) map[string]interface{} {
 	if elements == "" {
 		return resource
@@ -62,6 +63,7 @@ func ApplyElements(resource map[string]interface{}, elements string) map[string]
 			result[k] = v
 		}
 	}
+	addSubsettedTag(result)
 	return result
 }
 
@@ -165,17 +167,132 @@ func ApplyProjectionToBundle(bundle *Bundle, elements, summary string) {
 }
 
 // addSubsettedTag adds the SUBSETTED meta tag to indicate partial content.
+// The meta is copied, so the resource the projection was taken from is left
+// untouched, and a resource that is already tagged is not tagged twice.
 func addSubsettedTag(resource map[string]interface{}) {
-	meta, ok := resource["meta"].(map[string]interface{})
-	if !ok {
-		meta = make(map[string]interface{})
-		resource["meta"] = meta
+	meta := make(map[string]interface{})
+	if m, ok := resource["meta"].(map[string]interface{}); ok {
+		for k, v := range m {
+			meta[k] = v
+		}
 	}
+	resource["meta"] = meta
 
 	tags, _ := meta["tag"].([]interface{})
-	tags = append(tags, map[string]interface{}{
+	for _, t := range tags {
+		if tag, ok := t.(map[string]interface{}); ok && tag["code"] == "SUBSETTED" {
+			return
+		}
+	}
+	tags = append(tags[:len(tags):len(tags)], map[string]interface{}{
 		"system": "http://terminology.hl7.org/CodeSystem/v3-ObservationValue",
 		"code":   "SUBSETTED",
 	})
 	meta["tag"] = tags
 }
+
+// =========== Projection hints ===========
+
+// Projection is the _elements and _summary of a read or search.
+// ProjectionMiddleware puts it in the request context so that handlers and
+// repositories can skip loading what the projection will remove. Repositories
+// searching through SearchQuery skip the data query of a _summary=count
+// search (see SearchQuery.CountOnly); the Patient repository leaves the
+// encrypted contact columns undecrypted unless telecom or address is
+// returned, and List and Group reads load their entries and members, the
+// only child rows a FHIR read loads, only when they are returned. Every
+// other resource is read from a single row, loaded whole and then pruned
+// by the middleware; child tables such as observation_component or
+// encounter_participant are not part of those resources' FHIR
+// representation and are never read for it.
+type Projection struct {
+	Elements []string
+	Summary  string
+}
+
+// NewProjection parses the _elements and _summary parameters.
+func NewProjection(elements, summary string) Projection {
+	var p Projection
+	for _, e := range strings.Split(elements, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			p.Elements = append(p.Elements, e)
+		}
+	}
+	if summary != "false" {
+		p.Summary = summary
+	}
+	return p
+}
+
+// IsZero reports that the projection keeps the whole resource.
+func (p Projection) IsZero() bool {
+	return len(p.Elements) == 0 && p.Summary == ""
+}
+
+// CountOnly reports that _summary=count was requested, so no resources are
+// returned.
+func (p Projection) CountOnly() bool {
+	return p.Summary == "count"
+}
+
+// Includes reports whether element of a resourceType resource survives the
+// projection, following the rules of ApplyProjection: _elements takes
+// precedence over _summary and the mandatory elements are always kept.
+func (p Projection) Includes(resourceType, element string) bool {
+	if p.IsZero() || MandatoryElements[element] {
+		return true
+	}
+	if len(p.Elements) > 0 {
+		for _, e := range p.Elements {
+			if e == element {
+				return true
+			}
+		}
+		return false
+	}
+	switch p.Summary {
+	case "true":
+		fields := SummaryElements[resourceType]
+		if fields == nil {
+			fields = DefaultSummaryElements
+		}
+		for _, f := range fields {
+			if f == element {
+				return true
+			}
+		}
+		return false
+	case "text":
+		return element == "text"
+	case "data":
+		return element != "text"
+	case "count":
+		return false
+	default:
+		return true
+	}
+}
+
+// IncludesAny reports whether any of elements survives the projection.
+func (p Projection) IncludesAny(resourceType string, elements ...string) bool {
+	for _, e := range elements {
+		if p.Includes(resourceType, e) {
+			return true
+		}
+	}
+	return false
+}
+
+type projectionKey struct{}
+
+// WithProjection returns a context carrying the projection of a request.
+func WithProjection(ctx context.Context, p Projection) context.Context {
+	return context.WithValue(ctx, projectionKey{}, p)
+}
+
+// ProjectionFromContext returns the projection of the request, or the zero
+// Projection, which keeps everything, if none was requested.
+func ProjectionFromContext(ctx context.Context) Projection {
+	p, _ := ctx.Value(projectionKey{}).(Projection)
+	return p
+}
@@ -4,14 +4,19 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
 // ProjectionMiddleware applies _summary and _elements filtering to FHIR responses.
-// It intercepts the response body, parses it as JSON, applies the projection,
+// For reads and searches it puts the requested Projection in the request
+// context, so that handlers and repositories can avoid loading what the
+// projection removes; writes always load the whole resource. It then
+// intercepts the response body, parses it as JSON, applies the projection,
 // and writes the modified response. For Bundle resources, projection is applied
-// to each entry's resource.
+// to each entry's resource and _summary=count drops the entries. Error
+// responses and OperationOutcomes are passed through unchanged.
 func ProjectionMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -22,72 +27,86 @@ func ProjectionMiddleware() echo.MiddlewareFunc {
 				return next(c)
 			}
 
-			// Create a response recorder
-			rec := &projectionResponseRecorder{
-				ResponseWriter: c.Response().Writer,
+			if req := c.Request(); isProjectedRead(req) {
+				c.SetRequest(req.WithContext(WithProjection(req.Context(), NewProjection(elements, summary))))
+			}
+
+			// Buffer the response, status included, until it is projected
+			origWriter := c.Response().Writer
+			rec := &searchResponseRecorder{
+				ResponseWriter: origWriter,
 				body:           &bytes.Buffer{},
+				statusCode:     http.StatusOK,
 			}
 			c.Response().Writer = rec
 
 			if err := next(c); err != nil {
+				c.Response().Writer = origWriter
 				return err
 			}
+			c.Response().Writer = origWriter
+
+			if rec.statusCode < 200 || rec.statusCode >= 300 {
+				return flushSearchRecorder(origWriter, rec)
+			}
 
 			// Try to apply projection to the response
 			var resource map[string]interface{}
 			if err := json.Unmarshal(rec.body.Bytes(), &resource); err != nil {
 				// Not JSON or not a map, write original
-				c.Response().Writer = rec.ResponseWriter
-				_, writeErr := c.Response().Writer.Write(rec.body.Bytes())
-				return writeErr
+				return flushSearchRecorder(origWriter, rec)
 			}
 
 			// Check if it's a Bundle (apply to entries) or a single resource
-			if resource["resourceType"] == "Bundle" {
-				// Apply to each entry
+			switch resource["resourceType"] {
+			case "Bundle":
+				if summary == "count" {
+					delete(resource, "entry")
+					break
+				}
 				if entries, ok := resource["entry"].([]interface{}); ok {
-					for i, entry := range entries {
-						if entryMap, ok := entry.(map[string]interface{}); ok {
-							if res, ok := entryMap["resource"].(map[string]interface{}); ok {
-								entryMap["resource"] = ApplyProjection(res, elements, summary)
-								entries[i] = entryMap
-							}
+					for _, entry := range entries {
+						entryMap, ok := entry.(map[string]interface{})
+						if !ok {
+							continue
+						}
+						if search, ok := entryMap["search"].(map[string]interface{}); ok && search["mode"] == "outcome" {
+							continue
+						}
+						if res, ok := entryMap["resource"].(map[string]interface{}); ok {
+							entryMap["resource"] = ApplyProjection(res, elements, summary)
 						}
 					}
-					resource["entry"] = entries
 				}
-			} else {
+			case "OperationOutcome":
+				return flushSearchRecorder(origWriter, rec)
+			default:
 				resource = ApplyProjection(resource, elements, summary)
 			}
 
 			result, err := json.Marshal(resource)
 			if err != nil {
 				// If marshal fails, write original
-				c.Response().Writer = rec.ResponseWriter
-				_, writeErr := c.Response().Writer.Write(rec.body.Bytes())
-				return writeErr
+				return flushSearchRecorder(origWriter, rec)
 			}
 
-			c.Response().Writer = rec.ResponseWriter
-			c.Response().Header().Set("Content-Type", "application/fhir+json")
-			_, writeErr := c.Response().Writer.Write(result)
+			origWriter.Header().Set(echo.HeaderContentType, "application/fhir+json")
+			origWriter.WriteHeader(rec.statusCode)
+			_, writeErr := origWriter.Write(result)
 			return writeErr
 		}
 	}
 }
 
-// projectionResponseRecorder captures the response body for post-processing.
-type projectionResponseRecorder struct {
-	http.ResponseWriter
-	body       *bytes.Buffer
-	statusCode int
-}
-
-func (r *projectionResponseRecorder) Write(b []byte) (int, error) {
-	return r.body.Write(b)
-}
-
-func (r *projectionResponseRecorder) WriteHeader(code int) {
-	r.statusCode = code
-	r.ResponseWriter.WriteHeader(code)
+// isProjectedRead reports whether req reads or searches resources, so that
+// what it loads may be limited by its projection. An update loads the
+// current resource to write it back and must see all of it.
+func isProjectedRead(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPost:
+		return strings.HasSuffix(req.URL.Path, "/_search")
+	}
+	return false
 }
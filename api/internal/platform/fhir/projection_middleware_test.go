@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/labstack/echo/v4"
@@ -369,3 +370,110 @@ func TestProjectionMiddleware_ElementsIDStatus(t *testing.T) {
 		t.Error("resourceType should always be present")
 	}
 }
+
+func TestProjectionMiddleware_ContextAndCount(t *testing.T) {
+	var got Projection
+	e := echo.New()
+	e.Use(ProjectionMiddleware())
+	e.GET("/fhir/Patient", func(c echo.Context) error {
+		got = ProjectionFromContext(c.Request().Context())
+		return bundleHandler(c)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Patient?_summary=count", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if !got.CountOnly() {
+		t.Errorf("expected the projection in the request context, got %+v", got)
+	}
+	var bundle map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &bundle); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if _, ok := bundle["entry"]; ok {
+		t.Error("_summary=count should drop the entries")
+	}
+	if bundle["total"] != float64(1) {
+		t.Errorf("expected total 1, got %v", bundle["total"])
+	}
+}
+
+func TestProjectionMiddleware_NoHintForWrites(t *testing.T) {
+	e := echo.New()
+	e.Use(ProjectionMiddleware())
+	var got map[string]Projection
+	record := func(c echo.Context) error {
+		if got == nil {
+			got = make(map[string]Projection)
+		}
+		got[c.Request().Method+" "+c.Request().URL.Path] = ProjectionFromContext(c.Request().Context())
+		return c.JSON(http.StatusOK, map[string]interface{}{"resourceType": "Patient", "id": "1"})
+	}
+	e.GET("/fhir/Patient/:id", record)
+	e.PUT("/fhir/Patient/:id", record)
+	e.POST("/fhir/Patient/_search", record)
+
+	for _, r := range []struct{ method, target string }{
+		{http.MethodGet, "/fhir/Patient/1?_elements=name"},
+		{http.MethodPut, "/fhir/Patient/1?_elements=name"},
+		{http.MethodPost, "/fhir/Patient/_search?_elements=name"},
+	} {
+		req := httptest.NewRequest(r.method, r.target, strings.NewReader(`{"resourceType":"Patient","id":"1"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if p := got["GET /fhir/Patient/1"]; p.IsZero() {
+		t.Error("expected the projection hint on a read")
+	}
+	if p := got["POST /fhir/Patient/_search"]; p.IsZero() {
+		t.Error("expected the projection hint on a POST search")
+	}
+	if p := got["PUT /fhir/Patient/1"]; !p.IsZero() {
+		t.Errorf("expected no projection hint on an update, got %+v", p)
+	}
+}
+
+func TestProjectionMiddleware_ElementsSubsetted(t *testing.T) {
+	e, path := newTestEchoWithMiddleware(singleResourceHandler)
+
+	req := httptest.NewRequest(http.MethodGet, path+"?_elements=name,birthDate", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if _, ok := result["gender"]; ok {
+		t.Error("gender should be removed")
+	}
+	meta, _ := result["meta"].(map[string]interface{})
+	tags, _ := meta["tag"].([]interface{})
+	if len(tags) != 1 || tags[0].(map[string]interface{})["code"] != "SUBSETTED" {
+		t.Errorf("expected a SUBSETTED tag, got %v", meta)
+	}
+}
+
+func TestProjectionMiddleware_ErrorStatusPassthrough(t *testing.T) {
+	e := echo.New()
+	e.Use(ProjectionMiddleware())
+	e.GET("/fhir/Patient/:id", func(c echo.Context) error {
+		return c.JSON(http.StatusNotFound, NotFoundOutcome("Patient", c.Param("id")))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Patient/1?_elements=id", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+	var outcome map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &outcome); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if outcome["issue"] == nil {
+		t.Error("the OperationOutcome should be passed through unchanged")
+	}
+}
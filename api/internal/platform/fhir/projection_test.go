@@ -462,3 +462,57 @@ func TestApplyProjectionToBundle_SummaryTrue(t *testing.T) {
 		t.Error("communication should not be in Patient summary")
 	}
 }
+
+func TestApplyElements_Subsetted(t *testing.T) {
+	meta := map[string]interface{}{"versionId": "1"}
+	resource := map[string]interface{}{"resourceType": "Patient", "id": "1", "meta": meta, "gender": "male"}
+
+	result := ApplyElements(resource, "gender")
+	tags, _ := result["meta"].(map[string]interface{})["tag"].([]interface{})
+	if len(tags) != 1 || tags[0].(map[string]interface{})["code"] != "SUBSETTED" {
+		t.Fatalf("expected a SUBSETTED tag, got %v", result["meta"])
+	}
+	if _, ok := meta["tag"]; ok {
+		t.Error("the source resource's meta should not be modified")
+	}
+
+	// Projecting again does not tag twice.
+	result = ApplySummary(result, "true")
+	if tags, _ := result["meta"].(map[string]interface{})["tag"].([]interface{}); len(tags) != 1 {
+		t.Errorf("expected one SUBSETTED tag, got %v", tags)
+	}
+}
+
+func TestProjection_Includes(t *testing.T) {
+	tests := []struct {
+		name     string
+		p        Projection
+		rt, elem string
+		want     bool
+	}{
+		{"zero keeps everything", NewProjection("", ""), "List", "entry", true},
+		{"summary false keeps everything", NewProjection("", "false"), "List", "entry", true},
+		{"elements", NewProjection("name, birthDate", ""), "Patient", "birthDate", true},
+		{"elements drop others", NewProjection("name,birthDate", ""), "Patient", "telecom", false},
+		{"mandatory", NewProjection("name", ""), "Patient", "meta", true},
+		{"elements take precedence", NewProjection("telecom", "true"), "Patient", "telecom", true},
+		{"summary type elements", NewProjection("", "true"), "Patient", "address", true},
+		{"summary drops others", NewProjection("", "true"), "Patient", "telecom", false},
+		{"summary default elements", NewProjection("", "true"), "List", "entry", false},
+		{"text", NewProjection("", "text"), "List", "text", true},
+		{"data", NewProjection("", "data"), "List", "text", false},
+		{"data keeps others", NewProjection("", "data"), "List", "entry", true},
+		{"count", NewProjection("", "count"), "List", "status", false},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Includes(tt.rt, tt.elem); got != tt.want {
+			t.Errorf("%s: Includes(%s, %s) = %v, want %v", tt.name, tt.rt, tt.elem, got, tt.want)
+		}
+	}
+	if !NewProjection("", "count").CountOnly() || NewProjection("", "true").CountOnly() {
+		t.Error("CountOnly should only be set for _summary=count")
+	}
+	if !NewProjection("name", "").IncludesAny("Patient", "telecom", "name") {
+		t.Error("IncludesAny should match name")
+	}
+}
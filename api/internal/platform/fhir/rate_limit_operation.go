@@ -23,21 +23,26 @@ type OperationRateConfig struct {
 // operations can have independent rate limit configurations, allowing heavy
 // operations like $export to have lower limits than simple reads. Each
 // (operation, clientID) pair is tracked independently using a
-// SlidingWindowLimiter.
+// SlidingWindowLimiter, or a limiter made by the factory set with
+// SetLimiterFactory.
 type OperationRateLimiter struct {
 	mu            sync.RWMutex
 	configs       map[string]OperationRateConfig
-	limiters      map[string]*SlidingWindowLimiter
+	limiters      map[string]RateLimiter
 	defaultConfig OperationRateConfig
+	newLimiter    OperationLimiterFactory
 }
 
+// OperationLimiterFactory creates the RateLimiter of an operation.
+type OperationLimiterFactory func(operation string, maxRequests int, window time.Duration) RateLimiter
+
 // NewOperationRateLimiter creates an OperationRateLimiter with the given
 // default maximum requests and window duration. Operations without an explicit
 // configuration use these defaults.
 func NewOperationRateLimiter(defaultMax int, defaultWindow time.Duration) *OperationRateLimiter {
 	return &OperationRateLimiter{
 		configs:  make(map[string]OperationRateConfig),
-		limiters: make(map[string]*SlidingWindowLimiter),
+		limiters: make(map[string]RateLimiter),
 		defaultConfig: OperationRateConfig{
 			Operation:   "default",
 			MaxRequests: defaultMax,
@@ -62,6 +67,16 @@ func (o *OperationRateLimiter) SetOperationLimit(operation string, maxRequests i
 	delete(o.limiters, operation)
 }
 
+// SetLimiterFactory replaces the per-process SlidingWindowLimiter used for
+// each operation, for example with a RedisSlidingWindowLimiter shared by all
+// replicas. Existing limiters are discarded.
+func (o *OperationRateLimiter) SetLimiterFactory(factory OperationLimiterFactory) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.newLimiter = factory
+	o.limiters = make(map[string]RateLimiter)
+}
+
 // Allow checks whether the request for the given operation and client is
 // permitted under the configured rate limit. It returns true if the request is
 // allowed and false otherwise.
@@ -72,7 +87,7 @@ func (o *OperationRateLimiter) Allow(operation, clientID string) bool {
 	limiter, exists := o.limiters[operation]
 	if !exists {
 		cfg := o.configForLocked(operation)
-		limiter = NewSlidingWindowLimiter(cfg.MaxRequests, cfg.Window)
+		limiter = o.createLocked(operation, cfg)
 		o.limiters[operation] = limiter
 	}
 
@@ -105,17 +120,25 @@ func (o *OperationRateLimiter) configForLocked(operation string) OperationRateCo
 	return o.defaultConfig
 }
 
-// limiterForOperation returns the SlidingWindowLimiter for a given operation,
+// createLocked creates the limiter for an operation. Caller must hold o.mu.
+func (o *OperationRateLimiter) createLocked(operation string, cfg OperationRateConfig) RateLimiter {
+	if o.newLimiter != nil {
+		return o.newLimiter(operation, cfg.MaxRequests, cfg.Window)
+	}
+	return NewSlidingWindowLimiter(cfg.MaxRequests, cfg.Window)
+}
+
+// limiterForOperation returns the RateLimiter for a given operation,
 // along with the applicable config. The returned limiter can be used to obtain
 // remaining-count and reset-time information.
-func (o *OperationRateLimiter) limiterForOperation(operation string) (*SlidingWindowLimiter, OperationRateConfig) {
+func (o *OperationRateLimiter) limiterForOperation(operation string) (RateLimiter, OperationRateConfig) {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
 	cfg := o.configForLocked(operation)
 	limiter, exists := o.limiters[operation]
 	if !exists {
-		limiter = NewSlidingWindowLimiter(cfg.MaxRequests, cfg.Window)
+		limiter = o.createLocked(operation, cfg)
 		o.limiters[operation] = limiter
 	}
 	return limiter, cfg
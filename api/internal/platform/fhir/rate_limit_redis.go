@@ -0,0 +1,67 @@
+package fhir
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/ehr/ehr/internal/platform/redis"
+)
+
+// redisRateLimitTimeout bounds a single rate-limit round trip to Redis.
+const redisRateLimitTimeout = 500 * time.Millisecond
+
+// RedisSlidingWindowLimiter implements RateLimiter with a sliding window kept
+// in Redis, so that all replicas share one quota per client. Each Allow is a
+// single atomic script call (see redis.SlidingWindowScript). If Redis cannot
+// be reached, the limiter falls back to a per-process SlidingWindowLimiter
+// until it can.
+type RedisSlidingWindowLimiter struct {
+	client   *redis.Client
+	prefix   string
+	limit    int
+	window   time.Duration
+	fallback *SlidingWindowLimiter
+}
+
+// NewRedisSlidingWindowLimiter creates a RedisSlidingWindowLimiter that
+// permits at most limit requests per client within window. Client windows are
+// stored under prefix followed by the client key.
+func NewRedisSlidingWindowLimiter(client *redis.Client, prefix string, limit int, window time.Duration) *RedisSlidingWindowLimiter {
+	return &RedisSlidingWindowLimiter{
+		client:   client,
+		prefix:   prefix,
+		limit:    limit,
+		window:   window,
+		fallback: NewSlidingWindowLimiter(limit, window),
+	}
+}
+
+// Limit returns the maximum number of requests per window.
+func (l *RedisSlidingWindowLimiter) Limit() int {
+	return l.limit
+}
+
+// Allow determines whether the request identified by key is permitted.
+func (l *RedisSlidingWindowLimiter) Allow(key string) (allowed bool, remaining int, resetAt time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisRateLimitTimeout)
+	defer cancel()
+	res, err := l.client.SlidingWindow(ctx, l.prefix+key, requestMember(), l.limit, l.window)
+	if err != nil {
+		return l.fallback.Allow(key)
+	}
+	remaining = l.limit - res.Count
+	if !res.Allowed || remaining < 0 {
+		remaining = 0
+	}
+	return res.Allowed, remaining, res.ResetAt
+}
+
+// requestMember returns a random sorted-set member identifying one request,
+// so that simultaneous requests from different replicas are all counted.
+func requestMember() string {
+	var b [12]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
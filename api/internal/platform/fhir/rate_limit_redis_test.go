@@ -0,0 +1,112 @@
+package fhir
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/redis"
+)
+
+func newTestRedis(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	client, err := redis.NewClient("redis://" + srv.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, srv
+}
+
+func TestRedisSlidingWindowLimiter_SharedAcrossReplicas(t *testing.T) {
+	client, _ := newTestRedis(t)
+	// Two limiters on one Redis behave like two replicas.
+	a := NewRedisSlidingWindowLimiter(client, "rl:", 3, time.Minute)
+	b := NewRedisSlidingWindowLimiter(client, "rl:", 3, time.Minute)
+
+	for i, l := range []*RedisSlidingWindowLimiter{a, b, a} {
+		allowed, remaining, _ := l.Allow("client-1")
+		if !allowed {
+			t.Fatalf("request %d denied", i)
+		}
+		if remaining != 2-i {
+			t.Errorf("request %d: remaining = %d, want %d", i, remaining, 2-i)
+		}
+	}
+	if allowed, remaining, _ := b.Allow("client-1"); allowed || remaining != 0 {
+		t.Errorf("fourth request = %v, %d; want denied with 0 remaining", allowed, remaining)
+	}
+	if allowed, _, _ := b.Allow("client-2"); !allowed {
+		t.Error("other client should have its own window")
+	}
+}
+
+func TestRedisSlidingWindowLimiter_WindowExpiry(t *testing.T) {
+	client, srv := newTestRedis(t)
+	l := NewRedisSlidingWindowLimiter(client, "rl:", 1, time.Minute)
+	now := time.Now()
+	srv.SetTime(now)
+
+	if allowed, _, resetAt := l.Allow("c"); !allowed || resetAt.UnixMilli() != now.Add(time.Minute).UnixMilli() {
+		t.Fatalf("first request = %v, reset %v", allowed, resetAt)
+	}
+	if allowed, _, _ := l.Allow("c"); allowed {
+		t.Fatal("second request should be denied")
+	}
+	srv.SetTime(now.Add(time.Minute + time.Millisecond))
+	if allowed, _, _ := l.Allow("c"); !allowed {
+		t.Error("request after the window should be allowed")
+	}
+}
+
+func TestRedisSlidingWindowLimiter_FallsBackWhenUnavailable(t *testing.T) {
+	client, srv := newTestRedis(t)
+	l := NewRedisSlidingWindowLimiter(client, "rl:", 1, time.Minute)
+	srv.Close()
+
+	if allowed, _, _ := l.Allow("c"); !allowed {
+		t.Fatal("first request should be allowed by the fallback limiter")
+	}
+	if allowed, _, _ := l.Allow("c"); allowed {
+		t.Error("fallback limiter should still enforce the limit")
+	}
+}
+
+func TestOperationRateLimitMiddleware_RedisFactory(t *testing.T) {
+	client, srv := newTestRedis(t)
+	newLimiter := func() *OperationRateLimiter {
+		l := NewOperationRateLimiter(50, time.Minute)
+		l.SetOperationLimit("create", 2, time.Minute)
+		l.SetLimiterFactory(func(op string, max int, window time.Duration) RateLimiter {
+			return NewRedisSlidingWindowLimiter(client, "op:"+op+":", max, window)
+		})
+		return l
+	}
+	replicas := []echo.HandlerFunc{
+		OperationRateLimitMiddleware(newLimiter())(func(c echo.Context) error { return c.NoContent(http.StatusCreated) }),
+		OperationRateLimitMiddleware(newLimiter())(func(c echo.Context) error { return c.NoContent(http.StatusCreated) }),
+	}
+
+	e := echo.New()
+	var codes []int
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/fhir/Observation", nil)
+		req.Header.Set("X-API-Key", "key-1")
+		rec := httptest.NewRecorder()
+		if err := replicas[i%2](e.NewContext(req, rec)); err != nil {
+			t.Fatal(err)
+		}
+		codes = append(codes, rec.Code)
+	}
+	if codes[0] != http.StatusCreated || codes[1] != http.StatusCreated || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("status codes = %v, want [201 201 429]", codes)
+	}
+	if keys := srv.Keys(); len(keys) != 1 || keys[0] != "op:create:key-1" {
+		t.Errorf("redis keys = %v, want [op:create:key-1]", keys)
+	}
+}
@@ -0,0 +1,220 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ReindexRequest describes a $reindex job: the resource types whose indexes
+// are rebuilt and whose derived columns are backfilled.
+type ReindexRequest struct {
+	ResourceTypes []string `json:"resourceTypes"`
+}
+
+// reindexPageSize is the number of resources read per search while
+// reindexing.
+const reindexPageSize = 100
+
+// ReindexHandler returns an echo.HandlerFunc that handles POST /fhir/$reindex.
+//
+// The operation rebuilds the indexes the VersionTracker keeps on writes
+// (full text, contained resources and custom search parameters, see
+// VersionTracker.IndexResource) for resources written before an index was
+// kept or a custom parameter defined, and backfills the columns repositories
+// derive from resources (see ResourceOps.Reindex) for rows written before
+// the columns were added. The resource types are taken from the _type query
+// parameter or the "type" parameter of a Parameters body, comma-separated,
+// and default to every type with an index or columns to backfill. The
+// handler creates an async job and returns 202 Accepted with a
+// Content-Location header pointing to the async status endpoint; the job
+// runs in a background goroutine, or is left to an AsyncJobRunner running
+// ReindexJobFunc when the store is an AsyncJobQueue.
+func ReindexHandler(store AsyncJobStore, vt *VersionTracker, resources *ResourceRegistry) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		types := c.QueryParam("_type")
+		if c.Request().ContentLength != 0 {
+			var body map[string]interface{}
+			if err := json.NewDecoder(c.Request().Body).Decode(&body); err == nil && body != nil {
+				params, err := ParseOperationParameters(body)
+				if err != nil {
+					return c.JSON(http.StatusBadRequest, NewOperationOutcome(
+						IssueSeverityError, IssueTypeStructure, err.Error(),
+					))
+				}
+				if t, ok := params["type"].(string); ok && types == "" {
+					types = t
+				}
+			}
+		}
+
+		var req ReindexRequest
+		if types == "" {
+			defaults, err := reindexDefaultTypes(c.Request().Context(), vt, resources)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, NewOperationOutcome(
+					IssueSeverityError, IssueTypeException, err.Error(),
+				))
+			}
+			req.ResourceTypes = defaults
+		} else {
+			for _, rt := range strings.Split(types, ",") {
+				rt = strings.TrimSpace(rt)
+				if _, ok := resources.Lookup(rt); !ok {
+					return c.JSON(http.StatusBadRequest, NewOperationOutcome(
+						IssueSeverityError, IssueTypeNotSupported,
+						fmt.Sprintf("resource type %q cannot be reindexed", rt),
+					))
+				}
+				req.ResourceTypes = append(req.ResourceTypes, rt)
+			}
+		}
+
+		payload, err := json.Marshal(&req)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, NewOperationOutcome(
+				IssueSeverityError, IssueTypeException,
+				fmt.Sprintf("failed to encode reindex request: %s", err.Error()),
+			))
+		}
+		job := &AsyncJob{
+			ID:            uuid.New().String(),
+			Status:        AsyncStatusInProgress,
+			Request:       c.Request().RequestURI,
+			TransactionTS: time.Now().UTC(),
+			Kind:          "reindex",
+			Payload:       payload,
+		}
+		if err := store.Create(c.Request().Context(), job); err != nil {
+			return c.JSON(http.StatusInternalServerError, NewOperationOutcome(
+				IssueSeverityError, IssueTypeException,
+				fmt.Sprintf("failed to create reindex job: %s", err.Error()),
+			))
+		}
+
+		if _, queued := store.(AsyncJobQueue); !queued {
+			go processReindex(context.Background(), store, vt, resources, job.ID, &req)
+		}
+		return RespondAsync(c, store, job.ID)
+	}
+}
+
+// ReindexJobFunc returns an AsyncJobFunc that runs queued $reindex jobs of
+// store. Register it with an AsyncJobRunner for the "reindex" kind.
+func ReindexJobFunc(store AsyncJobStore, vt *VersionTracker, resources *ResourceRegistry) AsyncJobFunc {
+	return func(ctx context.Context, jobID string, payload json.RawMessage) error {
+		var req ReindexRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return fmt.Errorf("decode reindex request of job %s: %w", jobID, err)
+		}
+		processReindex(ctx, store, vt, resources, jobID, &req)
+		return nil
+	}
+}
+
+// reindexDefaultTypes returns the types reindexed when the request names
+// none: every type when vt keeps a full-text or contained index, otherwise
+// those with custom search parameters for the tenant of ctx or columns to
+// backfill.
+func reindexDefaultTypes(ctx context.Context, vt *VersionTracker, resources *ResourceRegistry) ([]string, error) {
+	if vt.text != nil || vt.contained != nil {
+		return resources.ResourceTypes(), nil
+	}
+	var types []string
+	if vt.search != nil {
+		var err error
+		if types, err = vt.search.IndexedTypes(ctx); err != nil {
+			return nil, err
+		}
+	}
+	seen := make(map[string]bool, len(types))
+	for _, rt := range types {
+		seen[rt] = true
+	}
+	for _, rt := range resources.ReindexTypes() {
+		if !seen[rt] {
+			types = append(types, rt)
+		}
+	}
+	return types, nil
+}
+
+// processReindex backfills the derived columns and rebuilds the indexes of
+// every resource of the requested types, a page at a time, and completes
+// the job with the number of resources indexed per type, or of rows
+// backfilled for types without an index. The job fails on the first error.
+func processReindex(ctx context.Context, store AsyncJobStore, vt *VersionTracker, resources *ResourceRegistry, jobID string, req *ReindexRequest) {
+	var outputs []AsyncJobOutput
+	var failure error
+	for _, rt := range req.ResourceTypes {
+		count, err := reindexType(ctx, vt, resources, rt)
+		if err != nil {
+			failure = fmt.Errorf("reindex %s: %w", rt, err)
+			break
+		}
+		outputs = append(outputs, AsyncJobOutput{Type: rt, Count: count})
+	}
+
+	job, err := store.Get(ctx, jobID)
+	if err != nil {
+		return
+	}
+	if failure != nil {
+		job.Status = AsyncStatusError
+		job.Error = failure.Error()
+	} else {
+		job.Status = AsyncStatusCompleted
+		job.Output = outputs
+	}
+	_ = store.Update(ctx, job)
+}
+
+// reindexType backfills the derived columns of resourceType, then indexes
+// every resource of the type when vt keeps an index of it, and returns how
+// many resources were indexed or, without an index, how many rows were
+// backfilled.
+func reindexType(ctx context.Context, vt *VersionTracker, resources *ResourceRegistry, resourceType string) (int, error) {
+	if ops, _ := resources.Lookup(resourceType); ops.Reindex != nil {
+		backfilled, err := ops.Reindex(ctx)
+		if err != nil {
+			return backfilled, err
+		}
+		if indexed, err := vt.indexesType(ctx, resourceType); err != nil || !indexed {
+			return backfilled, err
+		}
+	}
+	count := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		page, err := resources.Search(ctx, resourceType, url.Values{
+			"_count":  {strconv.Itoa(reindexPageSize)},
+			"_offset": {strconv.Itoa(count)},
+		})
+		if err != nil {
+			return count, err
+		}
+		for _, resource := range page {
+			id, _ := resource["id"].(string)
+			if id == "" {
+				continue
+			}
+			if err := vt.IndexResource(ctx, resourceType, id, resource); err != nil {
+				return count, err
+			}
+		}
+		count += len(page)
+		if len(page) < reindexPageSize {
+			return count, nil
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package fhir
+
+import (
+	"context"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// PGResourceLocker is a ResourceLocker backed by PostgreSQL advisory locks,
+// so that writes are serialized across replicas. Keys are scoped to the
+// tenant in the context. Inside a database transaction the lock is a
+// transaction-level lock released on commit or rollback; otherwise it is
+// held on the request's tenant connection until unlock is called. Requests
+// without a database connection are not locked.
+type PGResourceLocker struct{}
+
+// NewPGResourceLocker creates a PGResourceLocker.
+func NewPGResourceLocker() *PGResourceLocker {
+	return &PGResourceLocker{}
+}
+
+// Lock acquires the advisory lock for key.
+func (l *PGResourceLocker) Lock(ctx context.Context, key string) (func(), error) {
+	key = db.TenantFromContext(ctx) + ":" + key
+	if tx := db.TxFromContext(ctx); tx != nil {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, key); err != nil {
+			return nil, err
+		}
+		return func() {}, nil
+	}
+	conn := db.ConnFromContext(ctx)
+	if conn == nil {
+		return func() {}, nil
+	}
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock(hashtextextended($1, 0))`, key); err != nil {
+		return nil, err
+	}
+	return func() {
+		// The request context may already be cancelled; the lock must be
+		// released before the connection returns to the pool regardless, and
+		// a connection that cannot release it is closed instead.
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock(hashtextextended($1, 0))`, key); err != nil {
+			_ = conn.Conn().Close(context.Background())
+		}
+	}, nil
+}
@@ -0,0 +1,676 @@
+package fhir
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ResourceParseError is returned by domain FromFHIR parsers when one or more
+// elements of a FHIR resource are malformed. It carries an OperationOutcome
+// whose issues point at the offending elements with FHIRPath expressions.
+type ResourceParseError struct {
+	Outcome *OperationOutcome
+}
+
+func (e *ResourceParseError) Error() string {
+	msgs := make([]string, 0, len(e.Outcome.Issue))
+	for _, issue := range e.Outcome.Issue {
+		msgs = append(msgs, issue.Diagnostics)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ParseErrorOutcome converts an error returned while parsing a FHIR resource
+// into an OperationOutcome, preserving element-level issues when available.
+func ParseErrorOutcome(err error) *OperationOutcome {
+	if pe, ok := err.(*ResourceParseError); ok {
+		return pe.Outcome
+	}
+	return NewOperationOutcome(IssueSeverityError, IssueTypeInvalid, err.Error())
+}
+
+// ReadResourceBody reads a FHIR JSON request body and checks that it is a
+// resource of the expected type. When id is non-empty (update), the body's id
+// must match it, as required for PUT by the FHIR RESTful API.
+func ReadResourceBody(c echo.Context, resourceType, id string) (map[string]interface{}, error) {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, &ResourceParseError{Outcome: NewOperationOutcome(IssueSeverityError, IssueTypeStructure,
+			"failed to read request body: "+err.Error())}
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, &ResourceParseError{Outcome: NewOperationOutcome(IssueSeverityError, IssueTypeStructure,
+			"request body is not a valid FHIR JSON resource: "+err.Error())}
+	}
+	if rt, _ := data["resourceType"].(string); rt != resourceType {
+		return nil, &ResourceParseError{Outcome: &OperationOutcome{
+			ResourceType: "OperationOutcome",
+			Issue: []OperationOutcomeIssue{{
+				Severity:    IssueSeverityError,
+				Code:        IssueTypeInvalid,
+				Diagnostics: fmt.Sprintf("expected resourceType %q, got %q", resourceType, rt),
+				Expression:  []string{"resourceType"},
+			}},
+		}}
+	}
+	if id != "" {
+		if bodyID, ok := data["id"].(string); ok && bodyID != id {
+			return nil, &ResourceParseError{Outcome: &OperationOutcome{
+				ResourceType: "OperationOutcome",
+				Issue: []OperationOutcomeIssue{{
+					Severity:    IssueSeverityError,
+					Code:        IssueTypeInvalid,
+					Diagnostics: fmt.Sprintf("resource id %q does not match URL id %q", bodyID, id),
+					Expression:  []string{resourceType + ".id"},
+				}},
+			}}
+		}
+	}
+	return data, nil
+}
+
+// WriteParseError writes the 400 response for a ReadResourceBody or FromFHIR error.
+func WriteParseError(c echo.Context, err error) error {
+	return c.JSON(http.StatusBadRequest, ParseErrorOutcome(err))
+}
+
+// ResourceParser is implemented by domain models that can be populated from
+// a FHIR JSON resource, the inverse of ToFHIR.
+type ResourceParser interface {
+	FromFHIR(data map[string]interface{}) error
+}
+
+// BindResource reads the request body as a resourceType resource and parses
+// it into dst. See ReadResourceBody for the meaning of id. The elements dst
+// does not model are attached to the request context so that the
+// VersionTracker can persist them as extras once the resource is saved.
+func BindResource(c echo.Context, resourceType, id string, dst ResourceParser) error {
+	data, err := ReadResourceBody(c, resourceType, id)
+	if err != nil {
+		return err
+	}
+	if err := dst.FromFHIR(data); err != nil {
+		return err
+	}
+	captureExtras(c, resourceType, id, data, dst)
+	return nil
+}
+
+// Concept is the flattened form of a Coding or the first Coding of a
+// CodeableConcept, matching how the relational schema stores coded values.
+type Concept struct {
+	System  *string
+	Code    *string
+	Display *string
+	Text    *string
+}
+
+// CodeValue returns the code, or "" if absent.
+func (c Concept) CodeValue() string { return derefString(c.Code) }
+
+// QuantityValue is the flattened form of a Quantity element.
+type QuantityValue struct {
+	Value  *float64
+	Unit   *string
+	System *string
+	Code   *string
+}
+
+// ResourceReader reads typed values from a FHIR JSON resource map and records
+// an OperationOutcome issue for every element that is present but malformed.
+// Absent elements yield nil (or zero) without an issue unless read through a
+// Required* accessor. Paths use dotted element names with optional indexes,
+// e.g. "class.code" or "type[0].coding[0].display"; an unindexed array yields
+// its first item.
+type ResourceReader struct {
+	resourceType string
+	data         map[string]interface{}
+	issues       []OperationOutcomeIssue
+}
+
+// NewResourceReader creates a reader over data, which must be a resource of
+// the given type.
+func NewResourceReader(resourceType string, data map[string]interface{}) *ResourceReader {
+	r := &ResourceReader{resourceType: resourceType, data: data}
+	if data == nil {
+		r.addIssue(IssueTypeRequired, "", "resource body is required")
+		return r
+	}
+	if rt, ok := data["resourceType"].(string); ok && rt != resourceType {
+		r.addIssue(IssueTypeInvalid, "resourceType", fmt.Sprintf("expected resourceType %q, got %q", resourceType, rt))
+	}
+	return r
+}
+
+// Err returns a *ResourceParseError describing all recorded issues, or nil.
+func (r *ResourceReader) Err() error {
+	if len(r.issues) == 0 {
+		return nil
+	}
+	return &ResourceParseError{Outcome: &OperationOutcome{ResourceType: "OperationOutcome", Issue: r.issues}}
+}
+
+// Invalid records a custom issue against path, for domain-specific rules.
+func (r *ResourceReader) Invalid(path, diagnostics string) {
+	r.addIssue(IssueTypeValue, path, diagnostics)
+}
+
+func (r *ResourceReader) addIssue(code, path, diagnostics string) {
+	issue := OperationOutcomeIssue{Severity: IssueSeverityError, Code: code, Diagnostics: diagnostics}
+	if path != "" {
+		issue.Expression = []string{r.expr(path)}
+	}
+	r.issues = append(r.issues, issue)
+}
+
+func (r *ResourceReader) expr(path string) string {
+	if path == "resourceType" {
+		return path
+	}
+	return r.resourceType + "." + path
+}
+
+// Get returns the raw JSON value at path, or nil when absent.
+func (r *ResourceReader) Get(path string) interface{} {
+	var cur interface{} = r.data
+	for _, seg := range strings.Split(path, ".") {
+		name, idx := seg, -1
+		if open := strings.Index(seg, "["); open >= 0 && strings.HasSuffix(seg, "]") {
+			name = seg[:open]
+			n, err := strconv.Atoi(seg[open+1 : len(seg)-1])
+			if err != nil {
+				return nil
+			}
+			idx = n
+		}
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur, ok = obj[name]
+		if !ok || cur == nil {
+			return nil
+		}
+		if arr, isArr := cur.([]interface{}); isArr {
+			if idx < 0 {
+				idx = 0
+			}
+			if idx >= len(arr) {
+				return nil
+			}
+			cur = arr[idx]
+		} else if idx > 0 {
+			return nil
+		}
+	}
+	return cur
+}
+
+// Has reports whether an element is present at path.
+func (r *ResourceReader) Has(path string) bool {
+	return r.Get(path) != nil
+}
+
+// Len returns the number of items of the repeating element at path. A
+// single (non-array) value counts as one item.
+func (r *ResourceReader) Len(path string) int {
+	var cur interface{} = r.data
+	parent, last := "", path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		parent, last = path[:i], path[i+1:]
+	}
+	if parent != "" {
+		cur = r.Get(parent)
+	}
+	obj, ok := cur.(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	switch v := obj[last].(type) {
+	case nil:
+		return 0
+	case []interface{}:
+		return len(v)
+	default:
+		return 1
+	}
+}
+
+// String reads a string-typed primitive (string, code, uri, id, markdown...).
+func (r *ResourceReader) String(path string) *string {
+	v := r.Get(path)
+	if v == nil {
+		return nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		r.addIssue(IssueTypeStructure, path, fmt.Sprintf("%s must be a string", r.expr(path)))
+		return nil
+	}
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// StringValue reads a string primitive, returning "" when absent.
+func (r *ResourceReader) StringValue(path string) string {
+	return derefString(r.String(path))
+}
+
+// RequiredString reads a mandatory string primitive.
+func (r *ResourceReader) RequiredString(path string) string {
+	s := r.String(path)
+	if s == nil && !r.hasIssueAt(path) {
+		r.addIssue(IssueTypeRequired, path, fmt.Sprintf("%s is required", r.expr(path)))
+		return ""
+	}
+	return derefString(s)
+}
+
+// Strings reads a repeating string primitive such as HumanName.given.
+func (r *ResourceReader) Strings(path string) []string {
+	var out []string
+	for i := 0; i < r.Len(path); i++ {
+		if s := r.String(fmt.Sprintf("%s[%d]", path, i)); s != nil {
+			out = append(out, *s)
+		}
+	}
+	return out
+}
+
+// Code reads a code primitive and, when allowed is non-empty, checks that it
+// belongs to the required value set.
+func (r *ResourceReader) Code(path string, allowed ...string) *string {
+	s := r.String(path)
+	if s == nil || len(allowed) == 0 {
+		return s
+	}
+	for _, a := range allowed {
+		if *s == a {
+			return s
+		}
+	}
+	r.addIssue(IssueTypeCodeInvalid, path, fmt.Sprintf("%s has invalid code %q; expected one of %s",
+		r.expr(path), *s, strings.Join(allowed, ", ")))
+	return nil
+}
+
+// CodeValue reads a code primitive like Code, returning "" when absent.
+func (r *ResourceReader) CodeValue(path string, allowed ...string) string {
+	return derefString(r.Code(path, allowed...))
+}
+
+// RequiredCode reads a mandatory code primitive.
+func (r *ResourceReader) RequiredCode(path string, allowed ...string) string {
+	if !r.Has(path) {
+		r.addIssue(IssueTypeRequired, path, fmt.Sprintf("%s is required", r.expr(path)))
+		return ""
+	}
+	return derefString(r.Code(path, allowed...))
+}
+
+// Bool reads a boolean primitive.
+func (r *ResourceReader) Bool(path string) *bool {
+	v := r.Get(path)
+	if v == nil {
+		return nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		r.addIssue(IssueTypeStructure, path, fmt.Sprintf("%s must be a boolean", r.expr(path)))
+		return nil
+	}
+	return &b
+}
+
+// BoolValue reads a boolean primitive, returning false when absent.
+func (r *ResourceReader) BoolValue(path string) bool {
+	b := r.Bool(path)
+	return b != nil && *b
+}
+
+// Int reads an integer, positiveInt or unsignedInt primitive.
+func (r *ResourceReader) Int(path string) *int {
+	v := r.Get(path)
+	if v == nil {
+		return nil
+	}
+	f, ok := v.(float64)
+	if !ok || f != math.Trunc(f) {
+		r.addIssue(IssueTypeStructure, path, fmt.Sprintf("%s must be an integer", r.expr(path)))
+		return nil
+	}
+	n := int(f)
+	return &n
+}
+
+// Decimal reads a decimal primitive.
+func (r *ResourceReader) Decimal(path string) *float64 {
+	v := r.Get(path)
+	if v == nil {
+		return nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		r.addIssue(IssueTypeStructure, path, fmt.Sprintf("%s must be a number", r.expr(path)))
+		return nil
+	}
+	return &f
+}
+
+// DateTime reads a date, dateTime or instant primitive. Partial dates
+// ("2024", "2024-03") resolve to the start of the period.
+func (r *ResourceReader) DateTime(path string) *time.Time {
+	s := r.String(path)
+	if s == nil {
+		return nil
+	}
+	t, err := ParseFHIRDateTime(*s)
+	if err != nil {
+		r.addIssue(IssueTypeValue, path, fmt.Sprintf("%s has invalid date/time %q", r.expr(path), *s))
+		return nil
+	}
+	return &t
+}
+
+// RequiredDateTime reads a mandatory date, dateTime or instant primitive.
+func (r *ResourceReader) RequiredDateTime(path string) time.Time {
+	if !r.Has(path) {
+		r.addIssue(IssueTypeRequired, path, fmt.Sprintf("%s is required", r.expr(path)))
+		return time.Time{}
+	}
+	if t := r.DateTime(path); t != nil {
+		return *t
+	}
+	return time.Time{}
+}
+
+// Period reads the start and end of a Period element.
+func (r *ResourceReader) Period(path string) (start, end *time.Time) {
+	start = r.DateTime(path + ".start")
+	end = r.DateTime(path + ".end")
+	// Only compare full timestamps; a partial end date covers its whole day.
+	precise := strings.Contains(r.StringValue(path+".start"), "T") && strings.Contains(r.StringValue(path+".end"), "T")
+	if precise && start != nil && end != nil && end.Before(*start) {
+		r.addIssue(IssueTypeInvariant, path, fmt.Sprintf("%s.end must not be before %s.start", r.expr(path), r.expr(path)))
+	}
+	return start, end
+}
+
+// Coding reads a Coding element.
+func (r *ResourceReader) Coding(path string) Concept {
+	if v := r.Get(path); v != nil {
+		if _, ok := v.(map[string]interface{}); !ok {
+			r.addIssue(IssueTypeStructure, path, fmt.Sprintf("%s must be a Coding object", r.expr(path)))
+			return Concept{}
+		}
+	}
+	return Concept{
+		System:  r.String(path + ".system"),
+		Code:    r.String(path + ".code"),
+		Display: r.String(path + ".display"),
+	}
+}
+
+// CodeableConcept reads the first Coding and the text of a CodeableConcept.
+func (r *ResourceReader) CodeableConcept(path string) Concept {
+	if v := r.Get(path); v != nil {
+		if _, ok := v.(map[string]interface{}); !ok {
+			r.addIssue(IssueTypeStructure, path, fmt.Sprintf("%s must be a CodeableConcept object", r.expr(path)))
+			return Concept{}
+		}
+	}
+	c := r.Coding(path + ".coding")
+	c.Text = r.String(path + ".text")
+	if c.Display == nil && c.Code != nil {
+		c.Display = c.Text
+	}
+	return c
+}
+
+// ConceptCode reads the code of the first Coding of a CodeableConcept and,
+// when allowed is non-empty, checks that it belongs to the required value set.
+func (r *ResourceReader) ConceptCode(path string, allowed ...string) *string {
+	if v := r.Get(path); v != nil {
+		if _, ok := v.(map[string]interface{}); !ok {
+			r.addIssue(IssueTypeStructure, path, fmt.Sprintf("%s must be a CodeableConcept object", r.expr(path)))
+			return nil
+		}
+	}
+	return r.Code(path+".coding.code", allowed...)
+}
+
+// Reference reads the literal reference string of a Reference element.
+func (r *ResourceReader) Reference(path string) *string {
+	if v := r.Get(path); v != nil {
+		if _, ok := v.(map[string]interface{}); !ok {
+			r.addIssue(IssueTypeStructure, path, fmt.Sprintf("%s must be a Reference object", r.expr(path)))
+			return nil
+		}
+	}
+	return r.String(path + ".reference")
+}
+
+// ReferenceFHIRID reads a Reference and returns the logical id of the target,
+// checking that the target type is one of types (when given).
+func (r *ResourceReader) ReferenceFHIRID(path string, types ...string) *string {
+	_, id := r.ReferenceTarget(path, types...)
+	return id
+}
+
+// ReferenceTarget reads a Reference and returns the type and logical id of
+// the target, checking that the target type is one of types (when given).
+// It is used for polymorphic references stored as a type/id column pair.
+func (r *ResourceReader) ReferenceTarget(path string, types ...string) (resourceType, id *string) {
+	ref := r.Reference(path)
+	if ref == nil {
+		return nil, nil
+	}
+	rt, fid, ok := SplitReference(*ref)
+	if !ok {
+		r.addIssue(IssueTypeValue, path+".reference", fmt.Sprintf("%s.reference %q is not a literal Type/id reference", r.expr(path), *ref))
+		return nil, nil
+	}
+	if len(types) > 0 {
+		match := false
+		for _, t := range types {
+			if rt == t {
+				match = true
+				break
+			}
+		}
+		if !match {
+			r.addIssue(IssueTypeValue, path+".reference", fmt.Sprintf("%s.reference must refer to %s, got %q",
+				r.expr(path), strings.Join(types, " or "), *ref))
+			return nil, nil
+		}
+	}
+	return &rt, &fid
+}
+
+// RequiredReferenceTarget reads a mandatory Reference like ReferenceTarget,
+// returning empty strings when it is absent or invalid.
+func (r *ResourceReader) RequiredReferenceTarget(path string, types ...string) (resourceType, id string) {
+	if !r.Has(path) {
+		r.addIssue(IssueTypeRequired, path, fmt.Sprintf("%s is required", r.expr(path)))
+		return "", ""
+	}
+	rt, fid := r.ReferenceTarget(path, types...)
+	if rt == nil {
+		if !r.hasIssueAt(path) && !r.hasIssueAt(path+".reference") {
+			r.addIssue(IssueTypeRequired, path+".reference", fmt.Sprintf("%s.reference is required", r.expr(path)))
+		}
+		return "", ""
+	}
+	return *rt, *fid
+}
+
+// ReferenceID reads a Reference whose target id is a server-assigned UUID.
+func (r *ResourceReader) ReferenceID(path string, types ...string) *uuid.UUID {
+	id := r.ReferenceFHIRID(path, types...)
+	if id == nil {
+		return nil
+	}
+	u, err := uuid.Parse(*id)
+	if err != nil {
+		r.addIssue(IssueTypeValue, path+".reference", fmt.Sprintf("%s.reference id %q is not a valid server id", r.expr(path), *id))
+		return nil
+	}
+	return &u
+}
+
+// RequiredReferenceID reads a mandatory Reference to a server-assigned UUID.
+func (r *ResourceReader) RequiredReferenceID(path string, types ...string) uuid.UUID {
+	if !r.Has(path) {
+		r.addIssue(IssueTypeRequired, path, fmt.Sprintf("%s is required", r.expr(path)))
+		return uuid.Nil
+	}
+	if id := r.ReferenceID(path, types...); id != nil {
+		return *id
+	}
+	if !r.hasIssueAt(path) && !r.hasIssueAt(path+".reference") {
+		r.addIssue(IssueTypeRequired, path+".reference", fmt.Sprintf("%s.reference is required", r.expr(path)))
+	}
+	return uuid.Nil
+}
+
+// Quantity reads a Quantity (or SimpleQuantity, Age, Duration...) element.
+func (r *ResourceReader) Quantity(path string) QuantityValue {
+	return QuantityValue{
+		Value:  r.Decimal(path + ".value"),
+		Unit:   r.String(path + ".unit"),
+		System: r.String(path + ".system"),
+		Code:   r.String(path + ".code"),
+	}
+}
+
+// Annotation reads the text of the first Annotation at path (e.g. "note").
+func (r *ResourceReader) Annotation(path string) *string {
+	return r.String(path + ".text")
+}
+
+// IdentifierValue returns the value of the first Identifier at path whose
+// system matches (or the first Identifier when system is empty).
+func (r *ResourceReader) IdentifierValue(path, system string) *string {
+	p, ok := r.Find(path, func(item string) bool { return system == "" || r.StringValue(item+".system") == system })
+	if !ok {
+		return nil
+	}
+	return r.String(p + ".value")
+}
+
+// ContactValue returns the value of the first ContactPoint at path with the
+// given system ("phone", "email"...) and, when use is non-empty, use.
+func (r *ResourceReader) ContactValue(path, system, use string) *string {
+	p, ok := r.Find(path, func(item string) bool {
+		return r.StringValue(item+".system") == system && (use == "" || r.StringValue(item+".use") == use)
+	})
+	if !ok {
+		return nil
+	}
+	return r.String(p + ".value")
+}
+
+// Find returns the indexed path (e.g. "telecom[1]") of the first item of the
+// repeating element at path for which match returns true.
+func (r *ResourceReader) Find(path string, match func(item string) bool) (string, bool) {
+	for i := 0; i < r.Len(path); i++ {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		if match(p) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// FindReference returns the indexed path of the first Reference in the
+// repeating element at path (or at path+"."+sub when sub is non-empty) that
+// targets resourceType. When there is none it returns the unindexed path, so
+// that a following Required* read reports the element as missing or invalid.
+func (r *ResourceReader) FindReference(path, sub, resourceType string) string {
+	ref := func(item string) string {
+		if sub != "" {
+			return item + "." + sub
+		}
+		return item
+	}
+	if item, ok := r.Find(path, func(item string) bool {
+		rt, _, ok := SplitReference(r.StringValue(ref(item) + ".reference"))
+		return ok && rt == resourceType
+	}); ok {
+		return ref(item)
+	}
+	return ref(path)
+}
+
+// Extension returns the indexed path of the extension with the given url on
+// the element at parent, or on the resource itself when parent is "".
+func (r *ResourceReader) Extension(parent, url string) (string, bool) {
+	path := "extension"
+	if parent != "" {
+		path = parent + ".extension"
+	}
+	return r.Find(path, func(item string) bool { return r.StringValue(item+".url") == url })
+}
+
+func (r *ResourceReader) hasIssueAt(path string) bool {
+	e := r.expr(path)
+	for _, issue := range r.issues {
+		for _, ex := range issue.Expression {
+			if ex == e {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SplitReference splits a literal reference such as "Patient/123",
+// "Patient/123/_history/2" or "http://host/fhir/Patient/123" into its
+// resource type and id.
+func SplitReference(ref string) (resourceType, id string, ok bool) {
+	if strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "urn:") {
+		return "", "", false
+	}
+	if idx := strings.Index(ref, "/_history/"); idx >= 0 {
+		ref = ref[:idx]
+	}
+	parts := strings.Split(strings.TrimRight(ref, "/"), "/")
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	resourceType, id = parts[len(parts)-2], parts[len(parts)-1]
+	if resourceType == "" || id == "" {
+		return "", "", false
+	}
+	return resourceType, id, true
+}
+
+// ParseFHIRDateTime parses FHIR date, dateTime and instant values, including
+// the partial forms YYYY and YYYY-MM.
+func ParseFHIRDateTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid FHIR date/time %q", s)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
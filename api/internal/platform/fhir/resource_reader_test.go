@@ -0,0 +1,204 @@
+package fhir
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func TestResourceReader_TypedAccessors(t *testing.T) {
+	pid := uuid.New()
+	data := map[string]interface{}{
+		"resourceType": "Encounter",
+		"status":       "finished",
+		"class":        map[string]interface{}{"system": "http://terminology.hl7.org/CodeSystem/v3-ActCode", "code": "AMB"},
+		"type": []interface{}{
+			map[string]interface{}{"coding": []interface{}{map[string]interface{}{"code": "x"}}, "text": "Checkup"},
+		},
+		"subject": map[string]interface{}{"reference": "Patient/" + pid.String()},
+		"period":  map[string]interface{}{"start": "2024-01-02T10:00:00Z", "end": "2024-01-02"},
+		"length":  map[string]interface{}{"value": 30.0, "unit": "min"},
+		"active":  true,
+		"count":   3.0,
+		"given":   []interface{}{"A", "B"},
+	}
+	r := NewResourceReader("Encounter", data)
+
+	if got := r.RequiredCode("status", "planned", "finished"); got != "finished" {
+		t.Errorf("status = %q", got)
+	}
+	if c := r.Coding("class"); c.CodeValue() != "AMB" || c.System == nil {
+		t.Errorf("unexpected class %+v", c)
+	}
+	if c := r.CodeableConcept("type"); c.CodeValue() != "x" || *c.Text != "Checkup" || *c.Display != "Checkup" {
+		t.Errorf("unexpected type %+v", c)
+	}
+	if id := r.RequiredReferenceID("subject", "Patient"); id != pid {
+		t.Errorf("subject = %s", id)
+	}
+	if start, end := r.Period("period"); start == nil || end == nil {
+		t.Error("expected period start and end")
+	}
+	if q := r.Quantity("length"); *q.Value != 30 || *q.Unit != "min" {
+		t.Errorf("unexpected quantity %+v", q)
+	}
+	if !r.BoolValue("active") || *r.Int("count") != 3 {
+		t.Error("unexpected bool/int")
+	}
+	if got := r.Strings("given"); len(got) != 2 {
+		t.Errorf("given = %v", got)
+	}
+	if r.String("missing") != nil {
+		t.Error("expected nil for missing element")
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestResourceReader_ReportsPreciseIssues(t *testing.T) {
+	data := map[string]interface{}{
+		"resourceType": "Encounter",
+		"status":       "bogus",
+		"class":        "AMB",
+		"subject":      map[string]interface{}{"reference": "Group/1"},
+		"period":       map[string]interface{}{"start": "yesterday"},
+		"count":        1.5,
+	}
+	r := NewResourceReader("Encounter", data)
+	r.Code("status", "planned", "finished")
+	r.Coding("class")
+	r.ReferenceID("subject", "Patient")
+	r.Period("period")
+	r.Int("count")
+	r.RequiredString("serviceType.text")
+
+	err := r.Err()
+	pe, ok := err.(*ResourceParseError)
+	if !ok {
+		t.Fatalf("expected *ResourceParseError, got %v", err)
+	}
+	want := map[string]string{
+		"Encounter.status":            IssueTypeCodeInvalid,
+		"Encounter.class":             IssueTypeStructure,
+		"Encounter.subject.reference": IssueTypeValue,
+		"Encounter.period.start":      IssueTypeValue,
+		"Encounter.count":             IssueTypeStructure,
+		"Encounter.serviceType.text":  IssueTypeRequired,
+	}
+	if len(pe.Outcome.Issue) != len(want) {
+		t.Fatalf("expected %d issues, got %d: %+v", len(want), len(pe.Outcome.Issue), pe.Outcome.Issue)
+	}
+	for _, issue := range pe.Outcome.Issue {
+		if code, ok := want[issue.Expression[0]]; !ok || code != issue.Code {
+			t.Errorf("unexpected issue %s at %v", issue.Code, issue.Expression)
+		}
+	}
+}
+
+func TestResourceReader_WrongResourceType(t *testing.T) {
+	r := NewResourceReader("Patient", map[string]interface{}{"resourceType": "Encounter"})
+	if r.Err() == nil {
+		t.Fatal("expected resourceType mismatch")
+	}
+}
+
+func TestResourceReader_FindReference(t *testing.T) {
+	r := NewResourceReader("Appointment", map[string]interface{}{
+		"resourceType": "Appointment",
+		"participant": []interface{}{
+			map[string]interface{}{"actor": map[string]interface{}{"reference": "Location/loc-1"}},
+			map[string]interface{}{"actor": map[string]interface{}{"reference": "Patient/pat-1"}},
+		},
+	})
+	if got := r.FindReference("participant", "actor", "Patient"); got != "participant[1].actor" {
+		t.Errorf("Patient path = %q", got)
+	}
+	if got := r.FindReference("participant", "actor", "Practitioner"); got != "participant.actor" {
+		t.Errorf("missing Practitioner path = %q", got)
+	}
+}
+
+func TestResourceReader_ReferenceTarget(t *testing.T) {
+	r := NewResourceReader("Basic", map[string]interface{}{
+		"resourceType": "Basic",
+		"subject":      map[string]interface{}{"reference": "https://example.org/fhir/Device/dev-1/_history/3"},
+		"author":       map[string]interface{}{"reference": "Organization/org-1"},
+	})
+	if rt, id := r.ReferenceTarget("subject"); *rt != "Device" || *id != "dev-1" {
+		t.Errorf("subject = %v/%v", *rt, *id)
+	}
+	if rt, id := r.ReferenceTarget("author", "Practitioner", "Patient"); rt != nil || id != nil {
+		t.Errorf("expected author to be rejected, got %v/%v", rt, id)
+	}
+	pe, ok := r.Err().(*ResourceParseError)
+	if !ok || len(pe.Outcome.Issue) != 1 || pe.Outcome.Issue[0].Expression[0] != "Basic.author.reference" {
+		t.Fatalf("unexpected error: %v", r.Err())
+	}
+}
+
+func TestReadResourceBody(t *testing.T) {
+	e := echo.New()
+	tests := []struct {
+		name    string
+		body    string
+		id      string
+		wantErr string
+	}{
+		{"valid", `{"resourceType":"Patient","id":"1"}`, "1", ""},
+		{"bad json", `{`, "", "not a valid FHIR JSON"},
+		{"wrong type", `{"resourceType":"Encounter"}`, "", "expected resourceType"},
+		{"id mismatch", `{"resourceType":"Patient","id":"2"}`, "1", "does not match URL id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/fhir/Patient/1", strings.NewReader(tt.body))
+			c := e.NewContext(req, httptest.NewRecorder())
+			_, err := ReadResourceBody(c, "Patient", tt.id)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSplitReference(t *testing.T) {
+	tests := []struct {
+		ref, rt, id string
+		ok          bool
+	}{
+		{"Patient/123", "Patient", "123", true},
+		{"Patient/123/_history/2", "Patient", "123", true},
+		{"http://example.org/fhir/Observation/abc", "Observation", "abc", true},
+		{"#contained", "", "", false},
+		{"urn:uuid:1234", "", "", false},
+		{"Patient", "", "", false},
+	}
+	for _, tt := range tests {
+		rt, id, ok := SplitReference(tt.ref)
+		if rt != tt.rt || id != tt.id || ok != tt.ok {
+			t.Errorf("SplitReference(%q) = %q, %q, %v", tt.ref, rt, id, ok)
+		}
+	}
+}
+
+func TestParseFHIRDateTime(t *testing.T) {
+	for _, s := range []string{"2024", "2024-03", "2024-03-05", "2024-03-05T10:11:12Z", "2024-03-05T10:11:12.123+05:30"} {
+		if _, err := ParseFHIRDateTime(s); err != nil {
+			t.Errorf("ParseFHIRDateTime(%q) failed: %v", s, err)
+		}
+	}
+	if _, err := ParseFHIRDateTime("03/05/2024"); err == nil {
+		t.Error("expected error for non-FHIR date")
+	}
+}
@@ -0,0 +1,412 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ResourceVreadFunc retrieves a specific version of a resource by its FHIR ID.
+type ResourceVreadFunc func(ctx context.Context, fhirID, versionID string) (map[string]interface{}, error)
+
+// ResourceSearchFunc runs a type-level search and returns the matching
+// resources.
+type ResourceSearchFunc func(ctx context.Context, params url.Values) ([]map[string]interface{}, error)
+
+// ResourceReindexFunc recomputes the columns a repository derives from the
+// resources it stores, for rows written before the columns existed, and
+// returns how many rows it updated. $reindex runs it.
+type ResourceReindexFunc func(ctx context.Context) (int, error)
+
+// ResourceOps holds the functions that serve one resource type. Any of them
+// may be nil when the type does not support the interaction.
+type ResourceOps struct {
+	Read    ResourceFetcher
+	Vread   ResourceVreadFunc
+	Search  ResourceSearchFunc
+	Reindex ResourceReindexFunc
+}
+
+// ErrReferenceNotFound is returned by ResourceRegistry when a reference does
+// not identify a resource held by the server.
+var ErrReferenceNotFound = errors.New("referenced resource not found")
+
+// canonicalResourceTypes lists the R4 resource types that carry a canonical
+// url, in the order they are searched when a canonical reference does not
+// name its type.
+var canonicalResourceTypes = []string{
+	"ActivityDefinition", "CapabilityStatement", "ChargeItemDefinition",
+	"CodeSystem", "CompartmentDefinition", "ConceptMap",
+	"EffectEvidenceSynthesis", "EventDefinition", "Evidence",
+	"EvidenceVariable", "ExampleScenario", "GraphDefinition",
+	"ImplementationGuide", "Library", "Measure", "MessageDefinition",
+	"OperationDefinition", "PlanDefinition", "Questionnaire",
+	"ResearchDefinition", "ResearchElementDefinition",
+	"RiskEvidenceSynthesis", "SearchParameter", "StructureDefinition",
+	"StructureMap", "TerminologyCapabilities", "TestScript", "ValueSet",
+}
+
+// ResourceRegistry maps resource types to the read, vread and search
+// functions of the handlers that serve them. It resolves literal references
+// (including versioned "Type/id/_history/n" references and absolute URLs)
+// and canonical URLs to the full resources, and is shared by the operations
+// that need to follow references: $document, $graph, $apply, $everything
+// and _include.
+type ResourceRegistry struct {
+	mu         sync.RWMutex
+	ops        map[string]ResourceOps
+	dispatcher EntryDispatcher
+}
+
+// NewResourceRegistry creates an empty ResourceRegistry.
+func NewResourceRegistry() *ResourceRegistry {
+	return &ResourceRegistry{ops: make(map[string]ResourceOps)}
+}
+
+// Register sets the functions serving resourceType. Functions left nil in
+// ops keep their current registration.
+func (r *ResourceRegistry) Register(resourceType string, ops ResourceOps) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur := r.ops[resourceType]
+	if ops.Read != nil {
+		cur.Read = ops.Read
+	}
+	if ops.Vread != nil {
+		cur.Vread = ops.Vread
+	}
+	if ops.Search != nil {
+		cur.Search = ops.Search
+	}
+	if ops.Reindex != nil {
+		cur.Reindex = ops.Reindex
+	}
+	r.ops[resourceType] = cur
+}
+
+// SetDispatcher sets the dispatcher that serves the interactions registered
+// with RegisterInteractions, typically an EchoEntryDispatcher on the FHIR
+// routes.
+func (r *ResourceRegistry) SetDispatcher(d EntryDispatcher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dispatcher = d
+}
+
+// RegisterInteractions registers the REST interactions, as
+// CapabilityStatement codes, that a handler's routes serve for
+// resourceType. Domain handlers call it from their RegisterResources
+// method. The read, vread and search-type interactions are served by
+// invoking the routes through the registry's dispatcher, with the caller's
+// context, so the handlers' role checks apply; other interactions are
+// ignored. Functions already set with Register are kept.
+func (r *ResourceRegistry) RegisterInteractions(resourceType string, interactions ...string) {
+	d := registryDispatcher{r}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur, registered := r.ops[resourceType]
+	for _, code := range interactions {
+		switch code {
+		case "read":
+			if cur.Read == nil {
+				cur.Read = dispatchRead(d, resourceType)
+			}
+		case "vread":
+			if cur.Vread == nil {
+				cur.Vread = dispatchVread(d, resourceType)
+			}
+		case "search-type":
+			if cur.Search == nil {
+				cur.Search = dispatchSearch(d, resourceType)
+			}
+		default:
+			continue
+		}
+		registered = true
+	}
+	if registered {
+		r.ops[resourceType] = cur
+	}
+}
+
+// registryDispatcher dispatches through the dispatcher of a registry as it
+// is when the request is made, so that handlers may register their
+// interactions before the dispatcher is set.
+type registryDispatcher struct {
+	r *ResourceRegistry
+}
+
+// Dispatch implements EntryDispatcher.
+func (d registryDispatcher) Dispatch(ctx context.Context, req *EntryDispatchRequest) (*EntryDispatchResult, error) {
+	d.r.mu.RLock()
+	dispatcher := d.r.dispatcher
+	d.r.mu.RUnlock()
+	if dispatcher == nil {
+		return nil, fmt.Errorf("no dispatcher to serve %s %s", req.Method, req.URL)
+	}
+	return dispatcher.Dispatch(ctx, req)
+}
+
+// Lookup returns the functions registered for resourceType.
+func (r *ResourceRegistry) Lookup(resourceType string) (ResourceOps, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ops, ok := r.ops[resourceType]
+	return ops, ok
+}
+
+// ResourceTypes returns the registered resource types in sorted order.
+func (r *ResourceRegistry) ResourceTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.ops))
+	for rt := range r.ops {
+		types = append(types, rt)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// ReindexTypes returns the resource types whose repositories have columns
+// to backfill on $reindex.
+func (r *ResourceRegistry) ReindexTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var types []string
+	for rt, ops := range r.ops {
+		if ops.Reindex != nil {
+			types = append(types, rt)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Read returns the current version of resourceType/id.
+func (r *ResourceRegistry) Read(ctx context.Context, resourceType, id string) (map[string]interface{}, error) {
+	ops, _ := r.Lookup(resourceType)
+	if ops.Read == nil {
+		return nil, fmt.Errorf("no read registered for resource type %s", resourceType)
+	}
+	return ops.Read(ctx, id)
+}
+
+// Vread returns version versionID of resourceType/id. Types without a vread
+// function fall back to reading the current version, which is returned only
+// if it is the requested one.
+func (r *ResourceRegistry) Vread(ctx context.Context, resourceType, id, versionID string) (map[string]interface{}, error) {
+	ops, _ := r.Lookup(resourceType)
+	if ops.Vread != nil {
+		return ops.Vread(ctx, id, versionID)
+	}
+	if ops.Read == nil {
+		return nil, fmt.Errorf("no vread registered for resource type %s", resourceType)
+	}
+	res, err := ops.Read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if v := resourceVersionID(res); v != "" && v != versionID {
+		return nil, fmt.Errorf("%w: %s/%s/_history/%s", ErrReferenceNotFound, resourceType, id, versionID)
+	}
+	return res, nil
+}
+
+// Search runs a search on resourceType.
+func (r *ResourceRegistry) Search(ctx context.Context, resourceType string, params url.Values) ([]map[string]interface{}, error) {
+	ops, _ := r.Lookup(resourceType)
+	if ops.Search == nil {
+		return nil, fmt.Errorf("no search registered for resource type %s", resourceType)
+	}
+	return ops.Search(ctx, params)
+}
+
+// ResolveReference returns the resource a reference points to. It accepts
+// relative literal references ("Patient/123"), versioned references
+// ("Patient/123/_history/2"), absolute URLs ending in either form, and
+// canonical URLs with an optional "|version" suffix. Contained ("#id") and
+// urn: references cannot be resolved outside their resource or Bundle.
+func (r *ResourceRegistry) ResolveReference(ctx context.Context, reference string) (map[string]interface{}, error) {
+	if reference == "" || strings.HasPrefix(reference, "#") || strings.HasPrefix(reference, "urn:") {
+		return nil, fmt.Errorf("cannot resolve reference %q outside its resource", reference)
+	}
+	absolute := strings.Contains(reference, "://")
+	ref, version, _ := strings.Cut(reference, "|")
+	resourceType, id, historyVersion, literal := parseLiteralReference(ref)
+	if literal {
+		if ops, _ := r.Lookup(resourceType); ops.Read == nil {
+			literal = false
+		}
+	}
+
+	// A canonical URL looks like an absolute literal reference; try it as a
+	// canonical first, and as a literal if that finds nothing.
+	if absolute && (!literal || isCanonicalResourceType(resourceType)) {
+		var types []string
+		if literal {
+			types = []string{resourceType}
+		}
+		res, err := r.ResolveCanonical(ctx, ref, version, types...)
+		if err == nil || !literal || !errors.Is(err, ErrReferenceNotFound) {
+			return res, err
+		}
+	}
+	if !literal {
+		return nil, fmt.Errorf("%w: %s", ErrReferenceNotFound, reference)
+	}
+	if historyVersion != "" {
+		return r.Vread(ctx, resourceType, id, historyVersion)
+	}
+	return r.Read(ctx, resourceType, id)
+}
+
+// ResolveCanonical returns the resource whose url is canonical and, when
+// version is not empty, whose version matches. It searches types in order,
+// or every registered canonical resource type when none are given.
+func (r *ResourceRegistry) ResolveCanonical(ctx context.Context, canonical, version string, types ...string) (map[string]interface{}, error) {
+	if len(types) == 0 {
+		types = canonicalResourceTypes
+	}
+	params := url.Values{"url": {canonical}}
+	if version != "" {
+		params.Set("version", version)
+	}
+	for _, rt := range types {
+		ops, _ := r.Lookup(rt)
+		if ops.Search == nil {
+			continue
+		}
+		matches, err := ops.Search(ctx, params)
+		if err != nil {
+			continue
+		}
+		// Handlers may ignore search parameters they do not support, so
+		// check the matches rather than trusting the search.
+		for _, res := range matches {
+			u, _ := res["url"].(string)
+			v, _ := res["version"].(string)
+			if u == canonical && (version == "" || v == version) {
+				return res, nil
+			}
+		}
+	}
+	if version != "" {
+		canonical += "|" + version
+	}
+	return nil, fmt.Errorf("%w: %s", ErrReferenceNotFound, canonical)
+}
+
+// parseLiteralReference splits a relative or absolute literal reference into
+// its resource type, id and, for versioned references, version id.
+func parseLiteralReference(ref string) (resourceType, id, versionID string, ok bool) {
+	if i := strings.Index(ref, "/_history/"); i >= 0 {
+		versionID = strings.Trim(ref[i+len("/_history/"):], "/")
+		if versionID == "" || strings.Contains(versionID, "/") {
+			return "", "", "", false
+		}
+	}
+	resourceType, id, ok = SplitReference(ref)
+	if !ok || !IsValidResourceType(resourceType) {
+		return "", "", "", false
+	}
+	return resourceType, id, versionID, true
+}
+
+// isCanonicalResourceType reports whether resources of resourceType carry a
+// canonical url.
+func isCanonicalResourceType(resourceType string) bool {
+	for _, rt := range canonicalResourceTypes {
+		if rt == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatchRead returns a read function served by the route for
+// GET [base]/resourceType/:id.
+func dispatchRead(d EntryDispatcher, resourceType string) ResourceFetcher {
+	return func(ctx context.Context, id string) (map[string]interface{}, error) {
+		return dispatchResource(ctx, d, resourceType+"/"+url.PathEscape(id))
+	}
+}
+
+// dispatchVread returns a vread function served by the route for
+// GET [base]/resourceType/:id/_history/:vid.
+func dispatchVread(d EntryDispatcher, resourceType string) ResourceVreadFunc {
+	return func(ctx context.Context, id, versionID string) (map[string]interface{}, error) {
+		return dispatchResource(ctx, d, resourceType+"/"+url.PathEscape(id)+"/_history/"+url.PathEscape(versionID))
+	}
+}
+
+// dispatchSearch returns a search function served by the route for
+// GET [base]/resourceType. Only the entries of the first page that are
+// search matches are returned.
+func dispatchSearch(d EntryDispatcher, resourceType string) ResourceSearchFunc {
+	return func(ctx context.Context, params url.Values) ([]map[string]interface{}, error) {
+		target := resourceType
+		if len(params) > 0 {
+			target += "?" + params.Encode()
+		}
+		result, err := dispatchGet(ctx, d, target)
+		if err != nil {
+			return nil, err
+		}
+		var bundle struct {
+			Entry []struct {
+				Resource map[string]interface{} `json:"resource"`
+				Search   *BundleSearch          `json:"search"`
+			} `json:"entry"`
+		}
+		if err := json.Unmarshal(result.Body, &bundle); err != nil {
+			return nil, fmt.Errorf("search %s did not return a Bundle: %w", resourceType, err)
+		}
+		resources := make([]map[string]interface{}, 0, len(bundle.Entry))
+		for _, e := range bundle.Entry {
+			if e.Resource == nil || (e.Search != nil && e.Search.Mode != "" && e.Search.Mode != "match") {
+				continue
+			}
+			resources = append(resources, e.Resource)
+		}
+		return resources, nil
+	}
+}
+
+// dispatchResource reads a single resource through d.
+func dispatchResource(ctx context.Context, d EntryDispatcher, target string) (map[string]interface{}, error) {
+	result, err := dispatchGet(ctx, d, target)
+	if err != nil {
+		return nil, err
+	}
+	res := result.Resource()
+	if res == nil {
+		return nil, fmt.Errorf("%s did not return a resource", target)
+	}
+	return res, nil
+}
+
+// dispatchGet issues GET target through d and converts error responses to
+// errors; 404 and 410 become ErrReferenceNotFound.
+func dispatchGet(ctx context.Context, d EntryDispatcher, target string) (*EntryDispatchResult, error) {
+	result, err := d.Dispatch(ctx, &EntryDispatchRequest{Method: http.MethodGet, URL: target})
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case result.StatusCode == http.StatusNotFound || result.StatusCode == http.StatusGone:
+		return nil, fmt.Errorf("%w: %s", ErrReferenceNotFound, target)
+	case result.StatusCode >= 400:
+		msg := http.StatusText(result.StatusCode)
+		if oo := outcomeFromResult(result); len(oo.Issue) > 0 && oo.Issue[0].Diagnostics != "" {
+			msg = oo.Issue[0].Diagnostics
+		}
+		return nil, fmt.Errorf("GET %s: %d %s", target, result.StatusCode, msg)
+	}
+	return result, nil
+}
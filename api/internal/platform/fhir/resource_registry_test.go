@@ -0,0 +1,219 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newRegistryTestServer extends the dispatch test server with a Questionnaire
+// search that, like many handlers, ignores the url and version parameters.
+func newRegistryTestServer() (*echo.Echo, *ResourceRegistry) {
+	e, store := newDispatchTestServer()
+	store["Patient/p1"] = map[string]interface{}{
+		"resourceType": "Patient", "id": "p1",
+		"name": []interface{}{map[string]interface{}{"family": "Chalmers"}},
+		"meta": map[string]interface{}{"versionId": "3"},
+		"generalPractitioner": []interface{}{
+			map[string]interface{}{"reference": "Practitioner/dr1/_history/1"},
+		},
+	}
+	store["Practitioner/dr1"] = map[string]interface{}{
+		"resourceType": "Practitioner", "id": "dr1",
+		"name": []interface{}{map[string]interface{}{"family": "Careful"}},
+	}
+	questionnaires := []interface{}{
+		map[string]interface{}{"resourceType": "Questionnaire", "id": "q1", "url": "http://example.org/Questionnaire/phq9", "version": "1"},
+		map[string]interface{}{"resourceType": "Questionnaire", "id": "q2", "url": "http://example.org/Questionnaire/phq9", "version": "2"},
+		map[string]interface{}{"resourceType": "Questionnaire", "id": "q3", "url": "http://example.org/Questionnaire/gad7"},
+	}
+	e.GET("/fhir/Questionnaire", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, NewSearchBundle(questionnaires, len(questionnaires), "/fhir/Questionnaire"))
+	})
+	e.GET("/fhir/Practitioner/:id", func(c echo.Context) error {
+		res, ok := store["Practitioner/"+c.Param("id")]
+		if !ok {
+			return c.JSON(http.StatusNotFound, NotFoundOutcome("Practitioner", c.Param("id")))
+		}
+		return c.JSON(http.StatusOK, res)
+	})
+
+	reg := NewResourceRegistry()
+	reg.SetDispatcher(NewEchoEntryDispatcher(e, "/fhir"))
+	registerDispatchTestResources(reg)
+	reg.RegisterInteractions("Practitioner", "read")
+	reg.RegisterInteractions("Questionnaire", "search-type")
+	return e, reg
+}
+
+// registerDispatchTestResources registers the types served by the dispatch
+// test server, as its handlers would.
+func registerDispatchTestResources(reg *ResourceRegistry) {
+	for _, rt := range []string{"Patient", "Observation"} {
+		reg.RegisterInteractions(rt, "read", "search-type", "create", "update", "delete")
+	}
+}
+
+func TestResourceRegistry_RegisterInteractions(t *testing.T) {
+	_, reg := newRegistryTestServer()
+	// Interactions other than read, vread and search-type are not
+	// registered.
+	reg.RegisterInteractions("Secret", "create")
+
+	got := strings.Join(reg.ResourceTypes(), ",")
+	if got != "Observation,Patient,Practitioner,Questionnaire" {
+		t.Errorf("ResourceTypes() = %s", got)
+	}
+	ops, _ := reg.Lookup("Patient")
+	if ops.Read == nil || ops.Search == nil || ops.Vread != nil {
+		t.Errorf("Patient ops: read %v, search %v, vread %v; want read and search only",
+			ops.Read != nil, ops.Search != nil, ops.Vread != nil)
+	}
+	if ops, _ := reg.Lookup("Questionnaire"); ops.Read != nil || ops.Search == nil {
+		t.Error("Questionnaire should only have a search")
+	}
+}
+
+func TestResourceRegistry_RegisterInteractionsKeepsRegisteredOps(t *testing.T) {
+	e, _ := newDispatchTestServer()
+	reg := NewResourceRegistry()
+	reg.Register("Patient", ResourceOps{Read: func(ctx context.Context, id string) (map[string]interface{}, error) {
+		return map[string]interface{}{"resourceType": "Patient", "id": id, "source": "direct"}, nil
+	}})
+	registerDispatchTestResources(reg)
+
+	// The dispatcher may be set after the handlers register.
+	if _, err := reg.Search(context.Background(), "Patient", nil); err == nil {
+		t.Error("expected an error without a dispatcher")
+	}
+	reg.SetDispatcher(NewEchoEntryDispatcher(e, "/fhir"))
+
+	res, err := reg.Read(context.Background(), "Patient", "x")
+	if err != nil || res["source"] != "direct" {
+		t.Errorf("Read() = %v, %v; want the registered read", res, err)
+	}
+	if ops, _ := reg.Lookup("Patient"); ops.Search == nil {
+		t.Error("expected the search route to fill the missing search")
+	}
+}
+
+func TestResourceRegistry_ResolveReference(t *testing.T) {
+	_, reg := newRegistryTestServer()
+	ctx := context.Background()
+
+	tests := []struct {
+		ref    string
+		wantID string
+	}{
+		{"Patient/p1", "p1"},
+		{"Patient/p1/_history/3", "p1"},
+		{"https://ehr.example.org/fhir/Patient/p1", "p1"},
+		{"http://example.org/Questionnaire/phq9|1", "q1"},
+		{"http://example.org/Questionnaire/phq9|2", "q2"},
+		{"http://example.org/Questionnaire/gad7", "q3"},
+	}
+	for _, tt := range tests {
+		res, err := reg.ResolveReference(ctx, tt.ref)
+		if err != nil {
+			t.Errorf("ResolveReference(%q) error: %v", tt.ref, err)
+			continue
+		}
+		if res["id"] != tt.wantID {
+			t.Errorf("ResolveReference(%q) id = %v, want %s", tt.ref, res["id"], tt.wantID)
+		}
+	}
+
+	patient, _ := reg.ResolveReference(ctx, "Patient/p1")
+	if _, ok := patient["name"]; !ok {
+		t.Error("expected the full Patient, not a stub")
+	}
+}
+
+func TestResourceRegistry_ResolveReferenceNotFound(t *testing.T) {
+	_, reg := newRegistryTestServer()
+	ctx := context.Background()
+
+	for _, ref := range []string{
+		"Patient/missing",
+		"Patient/p1/_history/2",
+		"http://example.org/Questionnaire/phq9|9",
+		"http://example.org/ValueSet/unknown",
+	} {
+		if _, err := reg.ResolveReference(ctx, ref); !errors.Is(err, ErrReferenceNotFound) {
+			t.Errorf("ResolveReference(%q) error = %v, want ErrReferenceNotFound", ref, err)
+		}
+	}
+	for _, ref := range []string{"#contained", "urn:uuid:1234", "Encounter/e1"} {
+		if _, err := reg.ResolveReference(ctx, ref); err == nil {
+			t.Errorf("ResolveReference(%q) should fail", ref)
+		}
+	}
+}
+
+func TestResourceRegistry_Search(t *testing.T) {
+	_, reg := newRegistryTestServer()
+
+	res, err := reg.Search(context.Background(), "Patient", url.Values{"_id": {"p1"}})
+	if err != nil {
+		t.Fatalf("Search() error: %v", err)
+	}
+	if len(res) != 1 || res[0]["id"] != "p1" {
+		t.Errorf("Search() = %v, want Patient p1", res)
+	}
+}
+
+func TestResourceRegistry_ResolvesForDocument(t *testing.T) {
+	_, reg := newRegistryTestServer()
+	gen := NewDocumentGenerator(reg)
+
+	bundle, err := gen.GenerateDocument(context.Background(), map[string]interface{}{
+		"resourceType": "Composition", "id": "c1", "status": "final", "title": "Summary",
+		"type":    map[string]interface{}{"text": "summary"},
+		"date":    "2024-01-01",
+		"subject": map[string]interface{}{"reference": "Patient/p1"},
+		"author":  []interface{}{map[string]interface{}{"reference": "Practitioner/dr1"}},
+	}, false)
+	if err != nil {
+		t.Fatalf("GenerateDocument() error: %v", err)
+	}
+
+	entries := bundle["entry"].([]interface{})
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	var patient map[string]interface{}
+	_ = json.Unmarshal(entries[1].(map[string]interface{})["resource"].(json.RawMessage), &patient)
+	if _, ok := patient["name"]; !ok {
+		t.Errorf("expected a fully populated Patient, got %v", patient)
+	}
+}
+
+func TestGraphApply_ResolvesThroughResourceRegistry(t *testing.T) {
+	e, reg := newRegistryTestServer()
+	includes := NewIncludeRegistry()
+	includes.SetResourceRegistry(reg)
+	e.POST("/fhir/$graph", GraphApplyHandler(includes))
+
+	body := `{"resourceType": "Patient", "resourceId": "p1", "graphDefinition": {
+		"resourceType": "GraphDefinition", "name": "patient-gp", "status": "active", "start": "Patient",
+		"link": [{"path": "generalPractitioner", "target": [{"type": "Practitioner"}]}]}}`
+	req := httptest.NewRequest(http.MethodPost, "/fhir/$graph", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var bundle Bundle
+	_ = json.Unmarshal(rec.Body.Bytes(), &bundle)
+	if len(bundle.Entry) != 2 || bundle.Entry[1].FullURL != "Practitioner/dr1" {
+		t.Fatalf("expected the Patient and its versioned practitioner reference, got %+v", bundle.Entry)
+	}
+}
@@ -2,6 +2,8 @@ package fhir
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -121,11 +123,189 @@ func NumberSearchClause(column string, value string, argIdx int) (string, []inte
 		return fmt.Sprintf("%s <= $%d", column, argIdx), []interface{}{parsed.Value}, argIdx + 1
 	case PrefixNe:
 		return fmt.Sprintf("%s != $%d", column, argIdx), []interface{}{parsed.Value}, argIdx + 1
+	case PrefixAp:
+		if low, high, ok := approximateRange(parsed.Value); ok {
+			clause := fmt.Sprintf("(%s >= $%d AND %s <= $%d)", column, argIdx, column, argIdx+1)
+			return clause, []interface{}{low, high}, argIdx + 2
+		}
+		return fmt.Sprintf("%s = $%d", column, argIdx), []interface{}{parsed.Value}, argIdx + 1
 	default:
 		return fmt.Sprintf("%s = $%d", column, argIdx), []interface{}{parsed.Value}, argIdx + 1
 	}
 }
 
+// approximateRange returns the range matched by the ap prefix: the value
+// plus or minus 10%, as the specification recommends, and at least its
+// implicit precision, so that ap0 and ap1 match values that round to them.
+func approximateRange(value string) (float64, float64, bool) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	delta := math.Max(math.Abs(v)*0.1, precisionHalfWidth(value))
+	return v - delta, v + delta, true
+}
+
+// precisionHalfWidth returns half the unit of the last significant digit of
+// a decimal, the implicit precision of a search value: 0.05 for "5.4".
+func precisionHalfWidth(value string) float64 {
+	decimals := 0
+	if i := strings.IndexByte(value, '.'); i >= 0 && !strings.ContainsAny(value, "eE") {
+		decimals = len(value) - i - 1
+	}
+	return 0.5 * math.Pow10(-decimals)
+}
+
+// QuantityColumns are the columns holding a quantity for QuantitySearchClause.
+// CanonicalValue and CanonicalCode hold the quantity converted to its
+// canonical UCUM unit (see CanonicalQuantity) and may be empty when the table
+// does not store it. Analyte, when set, holds the LOINC code of what was
+// measured, so that substance and mass concentrations of the analytes with
+// a known molar mass can be compared (see AnalyteMolarMass).
+type QuantityColumns struct {
+	Value          string // e.g., "value_quantity"
+	System         string // e.g., "value_system"
+	Code           string // e.g., "value_code"
+	CanonicalValue string // e.g., "value_quantity_canonical"
+	CanonicalCode  string // e.g., "value_unit_canonical"
+	Analyte        string // e.g., "code_value"
+}
+
+// QuantitySearchClause generates SQL for a quantity search value of the form
+// [prefix]number|system|code. A bare number compares the value alone. A UCUM
+// unit is converted to its canonical unit and compared with the canonical
+// columns, so that gt5.4|http://unitsofmeasure.org|mmol/L also matches values
+// stored in umol/L; rows without a canonical value, whose unit is not UCUM,
+// are matched on their own system and code. With an Analyte column, values
+// of the analytes of known molar mass also match in the other of substance
+// and mass concentration, so that 5.4 mmol/L matches 97.3 mg/dL of glucose.
+// As the specification requires for quantities compared in canonical units,
+// eq and ne match the range of the value's implicit precision.
+func QuantitySearchClause(cols QuantityColumns, value string, argIdx int) (string, []interface{}, int) {
+	parts := strings.SplitN(value, "|", 3)
+	if len(parts) < 3 {
+		return NumberSearchClause(cols.Value, parts[0], argIdx)
+	}
+	number, system, code := parts[0], parts[1], parts[2]
+
+	var args []interface{}
+	idx := argIdx
+	canonical := ""
+	if cols.CanonicalValue != "" && cols.CanonicalCode != "" && code != "" && (system == "" || system == UCUMSystem) {
+		if unit, err := ParseUCUM(code); err == nil {
+			if clause, cargs, next, ok := canonicalQuantityClause(cols, number, unit, idx); ok {
+				canonical = clause
+				args = append(args, cargs...)
+				idx = next
+				if cols.Analyte != "" {
+					clauses, aargs, next := analyteQuantityClauses(cols, number, unit, idx)
+					for _, c := range clauses {
+						canonical += " OR " + c
+					}
+					args = append(args, aargs...)
+					idx = next
+				}
+			}
+		}
+	}
+
+	numClause, numArgs, next := NumberSearchClause(cols.Value, number, idx)
+	conds := []string{numClause}
+	args = append(args, numArgs...)
+	idx = next
+	if system != "" && cols.System != "" {
+		conds = append(conds, fmt.Sprintf("%s = $%d", cols.System, idx))
+		args = append(args, system)
+		idx++
+	}
+	if code != "" && cols.Code != "" {
+		conds = append(conds, fmt.Sprintf("%s = $%d", cols.Code, idx))
+		args = append(args, code)
+		idx++
+	}
+	raw := "(" + strings.Join(conds, " AND ") + ")"
+	if canonical == "" {
+		return raw, args, idx
+	}
+	return fmt.Sprintf("(%s OR (%s IS NULL AND %s))", canonical, cols.CanonicalCode, raw), args, idx
+}
+
+// canonicalQuantityClause compares the canonical columns of cols with a
+// [prefix]number search value in unit.
+func canonicalQuantityClause(cols QuantityColumns, number string, unit *UCUMUnit, argIdx int) (string, []interface{}, int, bool) {
+	clause, args, next, ok := canonicalNumberClause(cols.CanonicalValue, number, unit, argIdx+1)
+	if !ok {
+		return "", nil, argIdx, false
+	}
+	return fmt.Sprintf("(%s = $%d AND %s)", cols.CanonicalCode, argIdx, clause),
+		append([]interface{}{unit.Canonical()}, args...), next, true
+}
+
+// analyteQuantityClauses returns, for each group of analytes of the same
+// molar mass, the clause matching their values stored in the other of
+// substance and mass concentration than unit. It returns none for units
+// that are neither.
+func analyteQuantityClauses(cols QuantityColumns, number string, unit *UCUMUnit, argIdx int) ([]string, []interface{}, int) {
+	var clauses []string
+	var args []interface{}
+	idx := argIdx
+	for _, analyte := range ucumAnalytes {
+		other, ok := unit.byMolarMass(analyte.molarMass)
+		if !ok {
+			return nil, nil, argIdx
+		}
+		placeholders := make([]string, len(analyte.codes))
+		for i, code := range analyte.codes {
+			placeholders[i] = fmt.Sprintf("$%d", idx+i)
+			args = append(args, code)
+		}
+		clause, cargs, next, ok := canonicalQuantityClause(cols, number, other, idx+len(analyte.codes))
+		if !ok {
+			return nil, nil, argIdx
+		}
+		clauses = append(clauses, fmt.Sprintf("(%s IN (%s) AND %s)", cols.Analyte, strings.Join(placeholders, ", "), clause))
+		args = append(args, cargs...)
+		idx = next
+	}
+	return clauses, args, idx
+}
+
+// canonicalNumberClause compares column, holding canonical values, with a
+// [prefix]number search value in unit.
+func canonicalNumberClause(column, number string, unit *UCUMUnit, argIdx int) (string, []interface{}, int, bool) {
+	parsed := ParseSearchValue(number)
+	v, err := strconv.ParseFloat(parsed.Value, 64)
+	if err != nil {
+		return "", nil, argIdx, false
+	}
+	half := precisionHalfWidth(parsed.Value)
+	between := func(low, high float64, op string) (string, []interface{}, int, bool) {
+		clause := fmt.Sprintf("(%s >= $%d AND %s %s $%d)", column, argIdx, column, op, argIdx+1)
+		return clause, []interface{}{unit.ToCanonical(low), unit.ToCanonical(high)}, argIdx + 2, true
+	}
+	single := func(op string) (string, []interface{}, int, bool) {
+		return fmt.Sprintf("%s %s $%d", column, op, argIdx), []interface{}{unit.ToCanonical(v)}, argIdx + 1, true
+	}
+	switch parsed.Prefix {
+	case PrefixGt, PrefixSa:
+		return single(">")
+	case PrefixLt, PrefixEb:
+		return single("<")
+	case PrefixGe:
+		return single(">=")
+	case PrefixLe:
+		return single("<=")
+	case PrefixAp:
+		low, high, _ := approximateRange(parsed.Value)
+		return between(low, high, "<=")
+	case PrefixNe:
+		clause := fmt.Sprintf("(%s < $%d OR %s >= $%d)", column, argIdx, column, argIdx+1)
+		return clause, []interface{}{unit.ToCanonical(v - half), unit.ToCanonical(v + half)}, argIdx + 2, true
+	default:
+		return between(v-half, v+half, "<")
+	}
+}
+
 // TokenSearchClause handles token search parameters in the format "system|code", "|code", "system|", or just "code".
 func TokenSearchClause(systemCol, codeCol string, value string, argIdx int) (string, []interface{}, int) {
 	if strings.Contains(value, "|") {
@@ -46,6 +46,8 @@ type SearchIndexValue struct {
 	NumberValue    *float64
 	QuantityValue  *float64
 	QuantityUnit   *string
+	QuantitySystem *string
+	QuantityCode   *string
 	ReferenceValue *string
 	URIValue       *string
 }
@@ -463,6 +465,12 @@ func ConvertToSearchValue(paramName, resourceType, resourceID string, value inte
 			if unit, ok := v["unit"].(string); ok {
 				sv.QuantityUnit = &unit
 			}
+			if system, ok := v["system"].(string); ok {
+				sv.QuantitySystem = &system
+			}
+			if code, ok := v["code"].(string); ok {
+				sv.QuantityCode = &code
+			}
 		default:
 			return nil, fmt.Errorf("unexpected quantity value type: %T", value)
 		}
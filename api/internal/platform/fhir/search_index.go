@@ -0,0 +1,659 @@
+package fhir
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// SearchIndexStore persists the values of the custom search parameters of
+// each resource, as extracted by a SearchIndexer, so that searches on those
+// parameters can be answered from the shared index tables.
+type SearchIndexStore interface {
+	// IndexSearchValues replaces the indexed values of a resource.
+	IndexSearchValues(ctx context.Context, resourceType, resourceID string, values []SearchIndexValue) error
+	DeleteSearchValues(ctx context.Context, resourceType, resourceID string) error
+}
+
+// searchIndexTables maps a search parameter type to the shared table holding
+// its values. Types without a table, composite and special, are not indexed.
+var searchIndexTables = map[string]string{
+	"string":    "resource_search_string",
+	"uri":       "resource_search_string",
+	"token":     "resource_search_token",
+	"date":      "resource_search_date",
+	"number":    "resource_search_quantity",
+	"quantity":  "resource_search_quantity",
+	"reference": "resource_search_reference",
+}
+
+// SearchIndexer maintains the index of custom search parameters: the
+// expressions registered with SearchParameter/$expression, which every
+// tenant shares, and those of the active SearchParameter resources of each
+// tenant, which take precedence over shared ones of the same name. On every
+// create and update (see VersionTracker.SetSearchIndexer) it evaluates the
+// expressions that apply to the resource and writes their typed values to
+// the store; SearchQuery.ApplyParams answers the parameters from the same
+// tables when the indexer is the CustomParams of the SearchServices in its
+// context. Parameters mapped to a column by the repository take precedence
+// over custom ones of the same name.
+//
+// A tenant's SearchParameter resources are loaded, with the
+// SearchParameterLoader, when the tenant first searches or writes, and
+// again once they are older than the refresh interval, so that every
+// replica sees SearchParameters written through the others; the writes a
+// replica makes itself reload them at once (see OnResourceEvent).
+type SearchIndexer struct {
+	registry  *SearchExpressionRegistry
+	store     SearchIndexStore
+	evaluator *ExpressionEvaluator
+	load      SearchParameterLoader
+	refresh   time.Duration
+
+	mu      sync.Mutex
+	tenants map[string]*tenantSearchParams
+}
+
+// SearchParameterLoader returns the SearchParameter resources of the tenant
+// of ctx.
+type SearchParameterLoader func(ctx context.Context) ([]map[string]interface{}, error)
+
+// DefaultSearchParameterRefresh is how long a SearchIndexer answers a
+// tenant's searches and indexes its writes by the SearchParameter resources
+// it loaded before loading them again.
+const DefaultSearchParameterRefresh = time.Minute
+
+// tenantSearchParams holds the custom parameters defined by the
+// SearchParameter resources of a tenant.
+type tenantSearchParams struct {
+	mu sync.Mutex // held while loading
+	// params maps a resource type and name to the parameter's expression.
+	params map[string]map[string]*SearchParamExpression
+	// loaded is zero until the resources are loaded, and once a
+	// SearchParameter is written.
+	loaded time.Time
+}
+
+// loadingSearchParamsKey marks the context of a SearchParameterLoader, whose
+// own search of SearchParameter resources sees only the shared parameters.
+type loadingSearchParamsKey struct{}
+
+// NewSearchIndexer creates an indexer for the expressions of registry.
+// Until SetSearchParameterLoader is called it has no SearchParameter
+// definitions.
+func NewSearchIndexer(registry *SearchExpressionRegistry, store SearchIndexStore) *SearchIndexer {
+	return &SearchIndexer{
+		registry:  registry,
+		store:     store,
+		evaluator: NewExpressionEvaluator(),
+		refresh:   DefaultSearchParameterRefresh,
+		tenants:   make(map[string]*tenantSearchParams),
+	}
+}
+
+// SetSearchParameterLoader sets the function that loads the SearchParameter
+// resources of a tenant.
+func (ix *SearchIndexer) SetSearchParameterLoader(load SearchParameterLoader) {
+	ix.load = load
+}
+
+// Lookup returns the custom search parameter name of resourceType for the
+// tenant of ctx.
+func (ix *SearchIndexer) Lookup(ctx context.Context, resourceType, name string) (*SearchParamExpression, bool) {
+	set, _ := ix.params(ctx)
+	return set.lookup(resourceType, name)
+}
+
+// IndexedTypes returns the resource types that have custom search
+// parameters for the tenant of ctx.
+func (ix *SearchIndexer) IndexedTypes(ctx context.Context) ([]string, error) {
+	set, err := ix.params(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var types []string
+	add := func(rt string) {
+		if !seen[rt] && len(set.forType(rt)) > 0 {
+			seen[rt] = true
+			types = append(types, rt)
+		}
+	}
+	for _, expr := range ix.registry.listAll() {
+		for _, rt := range expr.ResourceTypes {
+			add(rt)
+		}
+	}
+	for _, rt := range sortedKeys(set.tenant) {
+		add(rt)
+	}
+	return types, nil
+}
+
+// hasIndexed reports whether resourceType has an indexable custom parameter
+// for the tenant of ctx.
+func (ix *SearchIndexer) hasIndexed(ctx context.Context, resourceType string) (bool, error) {
+	set, err := ix.params(ctx)
+	if err != nil {
+		return false, err
+	}
+	return len(set.forType(resourceType)) > 0, nil
+}
+
+// Index evaluates the custom search parameters of resourceType against
+// resource and replaces its indexed values. An expression that cannot be
+// evaluated for this resource indexes nothing rather than failing the write.
+// Types without custom parameters are left alone.
+func (ix *SearchIndexer) Index(ctx context.Context, resourceType, resourceID string, resource map[string]interface{}) error {
+	set, err := ix.params(ctx)
+	if err != nil {
+		return err
+	}
+	exprs := set.forType(resourceType)
+	if len(exprs) == 0 {
+		return nil
+	}
+	var values []SearchIndexValue
+	for _, expr := range exprs {
+		values = append(values, ix.extract(expr, resourceType, resourceID, resource)...)
+	}
+	return ix.store.IndexSearchValues(ctx, resourceType, resourceID, values)
+}
+
+// Delete removes the indexed values of a resource. The values are removed
+// even if the tenant's SearchParameters cannot be loaded.
+func (ix *SearchIndexer) Delete(ctx context.Context, resourceType, resourceID string) error {
+	if set, err := ix.params(ctx); err == nil && len(set.forType(resourceType)) == 0 {
+		return nil
+	}
+	return ix.store.DeleteSearchValues(ctx, resourceType, resourceID)
+}
+
+// extract returns the values of expr in resource. Composite FHIR types are
+// broken down the way the built-in parameters search them: the codings of a
+// CodeableConcept and the value of an Identifier or ContactPoint are tokens,
+// the strings of a HumanName or Address are strings, and a Period is
+// indexed by its start.
+func (ix *SearchIndexer) extract(expr *SearchParamExpression, resourceType, resourceID string, resource map[string]interface{}) []SearchIndexValue {
+	result, err := ix.evaluator.Evaluate(expr.Expression, resource)
+	if err != nil {
+		return nil
+	}
+	var values []SearchIndexValue
+	for _, v := range result.Values {
+		for _, item := range expandIndexValue(expr.Type, v) {
+			sv, err := ConvertToSearchValue(expr.Name, resourceType, resourceID, item, expr.Type)
+			if err != nil || sv == nil {
+				continue
+			}
+			values = append(values, *sv)
+		}
+	}
+	return values
+}
+
+// expandIndexValue breaks an evaluated value down into the values indexed
+// for a parameter of type paramType.
+func expandIndexValue(paramType string, v interface{}) []interface{} {
+	m, isMap := v.(map[string]interface{})
+	switch paramType {
+	case "token":
+		if b, ok := v.(bool); ok {
+			return []interface{}{strconv.FormatBool(b)}
+		}
+		if !isMap {
+			return []interface{}{v}
+		}
+		if codings, ok := m["coding"].([]interface{}); ok {
+			return codings
+		}
+		if _, ok := m["code"]; !ok {
+			if value, ok := m["value"].(string); ok {
+				system, _ := m["system"].(string)
+				return []interface{}{map[string]interface{}{"system": system, "code": value}}
+			}
+		}
+	case "string":
+		if isMap {
+			var out []string
+			collectText(m, &out)
+			items := make([]interface{}, len(out))
+			for i, s := range out {
+				items[i] = s
+			}
+			return items
+		}
+	case "date":
+		if isMap {
+			if start, ok := m["start"]; ok {
+				return []interface{}{start}
+			}
+			return nil
+		}
+	case "number":
+		if s, ok := v.(string); ok {
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil
+			}
+			return []interface{}{f}
+		}
+	}
+	return []interface{}{v}
+}
+
+// =========== SearchParameter definitions ===========
+
+// SearchParamExpressionFromResource converts a SearchParameter resource to
+// the expression it defines. It reports false for SearchParameters that do
+// not define an indexable custom parameter: those that are not active, have
+// no expression, or are of a type without an index table.
+func SearchParamExpressionFromResource(sp map[string]interface{}) (*SearchParamExpression, bool) {
+	status, _ := sp["status"].(string)
+	code, _ := sp["code"].(string)
+	paramType, _ := sp["type"].(string)
+	expression, _ := sp["expression"].(string)
+	if status != "active" || code == "" || expression == "" || searchIndexTables[paramType] == "" {
+		return nil, false
+	}
+	expr := &SearchParamExpression{
+		Name:          code,
+		Type:          paramType,
+		Expression:    expression,
+		ResourceTypes: stringList(sp["base"]),
+		Target:        stringList(sp["target"]),
+	}
+	expr.Description, _ = sp["description"].(string)
+	if len(expr.ResourceTypes) == 0 {
+		return nil, false
+	}
+	return expr, true
+}
+
+// stringList returns the strings of a JSON array.
+func stringList(v interface{}) []string {
+	if strs, ok := v.([]string); ok {
+		return strs
+	}
+	items, _ := v.([]interface{})
+	var out []string
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// params returns the custom parameters of the tenant of ctx, loading its
+// SearchParameter resources when they were never loaded, are older than the
+// refresh interval or were written since. When they cannot be loaded, the
+// error is returned along with the parameters loaded before, if any, and
+// loading is retried on the next call.
+func (ix *SearchIndexer) params(ctx context.Context) (*searchParamSet, error) {
+	set := &searchParamSet{shared: ix.registry}
+	if ix.load == nil || ctx.Value(loadingSearchParamsKey{}) != nil {
+		return set, nil
+	}
+	t := ix.tenant(db.TenantFromContext(ctx))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.loaded.IsZero() || time.Since(t.loaded) >= ix.refresh {
+		resources, err := ix.load(context.WithValue(ctx, loadingSearchParamsKey{}, true))
+		if err != nil {
+			set.tenant = t.params
+			return set, fmt.Errorf("load search parameters: %w", err)
+		}
+		t.params = searchParamsByType(resources)
+		t.loaded = time.Now()
+	}
+	set.tenant = t.params
+	return set, nil
+}
+
+// tenant returns the entry of tenantID, creating it if needed.
+func (ix *SearchIndexer) tenant(tenantID string) *tenantSearchParams {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	t, ok := ix.tenants[tenantID]
+	if !ok {
+		t = &tenantSearchParams{}
+		ix.tenants[tenantID] = t
+	}
+	return t
+}
+
+// searchParamsByType returns the custom parameters defined by the
+// SearchParameter resources sps (see SearchParamExpressionFromResource), by
+// resource type and name.
+func searchParamsByType(sps []map[string]interface{}) map[string]map[string]*SearchParamExpression {
+	params := make(map[string]map[string]*SearchParamExpression)
+	for _, sp := range sps {
+		expr, ok := SearchParamExpressionFromResource(sp)
+		if !ok {
+			continue
+		}
+		for _, rt := range expr.ResourceTypes {
+			if params[rt] == nil {
+				params[rt] = make(map[string]*SearchParamExpression)
+			}
+			params[rt][expr.Name] = expr
+		}
+	}
+	return params
+}
+
+// OnResourceEvent reloads the SearchParameter resources of the tenant of
+// ctx when one is written through the VersionTracker, so that the replica
+// writing it answers by it at once. Register the indexer with
+// VersionTracker.AddListener.
+func (ix *SearchIndexer) OnResourceEvent(ctx context.Context, event ResourceEvent) {
+	if event.ResourceType != "SearchParameter" {
+		return
+	}
+	t := ix.tenant(db.TenantFromContext(ctx))
+	t.mu.Lock()
+	t.loaded = time.Time{}
+	t.mu.Unlock()
+}
+
+// searchParamSet is the set of custom parameters of a tenant: those of its
+// SearchParameter resources and the shared ones they do not override. A nil
+// set has none.
+type searchParamSet struct {
+	shared *SearchExpressionRegistry
+	tenant map[string]map[string]*SearchParamExpression
+}
+
+// lookup returns the indexable parameter name of resourceType.
+func (s *searchParamSet) lookup(resourceType, name string) (*SearchParamExpression, bool) {
+	if s == nil {
+		return nil, false
+	}
+	expr, ok := s.tenant[resourceType][name]
+	if !ok {
+		expr, ok = s.shared.Get(resourceType, name)
+	}
+	if !ok || searchIndexTables[expr.Type] == "" {
+		return nil, false
+	}
+	return expr, true
+}
+
+// forType returns the indexable parameters of resourceType.
+func (s *searchParamSet) forType(resourceType string) []*SearchParamExpression {
+	own := s.tenant[resourceType]
+	var exprs []*SearchParamExpression
+	for _, expr := range s.shared.ListForResourceType(resourceType) {
+		if _, overridden := own[expr.Name]; !overridden && searchIndexTables[expr.Type] != "" {
+			exprs = append(exprs, expr)
+		}
+	}
+	for _, name := range sortedKeys(own) {
+		exprs = append(exprs, own[name])
+	}
+	return exprs
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// =========== Search ===========
+
+// customParams returns the custom parameters of the tenant of ctx, or nil
+// without a SearchIndexer. When the tenant's SearchParameters cannot be
+// loaded, searches are answered by those loaded before.
+func (s *SearchServices) customParams(ctx context.Context) *searchParamSet {
+	if s.CustomParams == nil {
+		return nil
+	}
+	set, _ := s.CustomParams.params(ctx)
+	return set
+}
+
+// customParam returns the custom search parameter named by name, with its
+// modifier, if the query's resource type has one. Chained names are left to
+// applyChain.
+func (q *SearchQuery) customParam(name string) (*SearchParamExpression, SearchModifier, bool) {
+	if q.custom == nil || strings.Contains(name, ".") {
+		return nil, "", false
+	}
+	resourceType := ResourceTypeForTable(q.table)
+	if resourceType == "" {
+		return nil, "", false
+	}
+	base, modifier := ParseParamModifier(name)
+	expr, ok := q.custom.lookup(resourceType, base)
+	return expr, modifier, ok
+}
+
+// applyCustom adds the clause for a custom search parameter: the table's
+// fhir_id is matched against the resources whose index rows for the
+// parameter satisfy the value. Comma-separated values are ORed, and
+// :missing selects the resources with or without index rows.
+func (q *SearchQuery) applyCustom(expr *SearchParamExpression, modifier SearchModifier, value string) {
+	resourceType := ResourceTypeForTable(q.table)
+	table := searchIndexTables[expr.Type]
+	sub := fmt.Sprintf("SELECT resource_id FROM %s WHERE resource_type = $%d AND param = $%d", table, q.idx, q.idx+1)
+	args := []interface{}{resourceType, expr.Name}
+	idx := q.idx + 2
+
+	if modifier == "missing" {
+		op := "IN"
+		if value == "true" {
+			op = "NOT IN"
+		}
+		q.where += fmt.Sprintf(" AND fhir_id %s (%s)", op, sub)
+		q.args = append(q.args, args...)
+		q.idx = idx
+		return
+	}
+
+	var ors []string
+	for _, v := range strings.Split(value, ",") {
+		if v == "" {
+			continue
+		}
+		clause, vargs, next := customValueClause(expr.Type, modifier, v, idx)
+		ors = append(ors, clause)
+		args = append(args, vargs...)
+		idx = next
+	}
+	if len(ors) == 0 {
+		return
+	}
+	q.where += fmt.Sprintf(" AND fhir_id IN (%s AND (%s))", sub, strings.Join(ors, " OR "))
+	q.args = append(q.args, args...)
+	q.idx = idx
+}
+
+// customValueClause returns the condition on an index row matching one
+// value of a custom parameter of type paramType.
+func customValueClause(paramType string, modifier SearchModifier, value string, idx int) (string, []interface{}, int) {
+	switch paramType {
+	case "string":
+		return StringSearchClause("value", value, modifier, idx)
+	case "uri":
+		return fmt.Sprintf("value = $%d", idx), []interface{}{value}, idx + 1
+	case "token":
+		return TokenSearchClause("system", "code", value, idx)
+	case "date":
+		return DateSearchClause("value", value, idx)
+	case "number":
+		return NumberSearchClause("value", value, idx)
+	case "quantity":
+		return QuantitySearchClause(QuantityColumns{
+			Value: "value", System: "system", Code: "code",
+			CanonicalValue: "canonical_value", CanonicalCode: "canonical_code",
+		}, value, idx)
+	case "reference":
+		if rt, id, _, ok := parseLiteralReference(value); ok {
+			return fmt.Sprintf("(target_type = $%d AND target_id = $%d)", idx, idx+1), []interface{}{rt, id}, idx + 2
+		}
+		return fmt.Sprintf("target_id = $%d", idx), []interface{}{value}, idx + 1
+	}
+	return "FALSE", nil, idx
+}
+
+// =========== In-memory store ===========
+
+// IndexSearchValues implements SearchIndexStore.
+func (idx *SearchExpressionIndex) IndexSearchValues(_ context.Context, resourceType, resourceID string, values []SearchIndexValue) error {
+	idx.Index(resourceType, resourceID, values)
+	return nil
+}
+
+// DeleteSearchValues implements SearchIndexStore.
+func (idx *SearchExpressionIndex) DeleteSearchValues(_ context.Context, resourceType, resourceID string) error {
+	idx.Remove(resourceType, resourceID)
+	return nil
+}
+
+// =========== PostgreSQL store ===========
+
+// SearchIndexRepository stores custom search parameter values in the shared
+// resource_search_* tables, one per parameter type.
+type SearchIndexRepository struct{}
+
+// NewSearchIndexRepository creates a new SearchIndexRepository.
+func NewSearchIndexRepository() *SearchIndexRepository {
+	return &SearchIndexRepository{}
+}
+
+func (r *SearchIndexRepository) conn(ctx context.Context) historyQuerier {
+	if tx := db.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	if c := db.ConnFromContext(ctx); c != nil {
+		return c
+	}
+	return nil
+}
+
+// searchIndexTableNames lists the distinct index tables.
+var searchIndexTableNames = []string{
+	"resource_search_string", "resource_search_token", "resource_search_date",
+	"resource_search_quantity", "resource_search_reference",
+}
+
+// IndexSearchValues replaces the indexed values of a resource.
+func (r *SearchIndexRepository) IndexSearchValues(ctx context.Context, resourceType, resourceID string, values []SearchIndexValue) error {
+	q := r.conn(ctx)
+	if q == nil {
+		return fmt.Errorf("no database connection in context")
+	}
+	if err := r.deleteAll(ctx, q, resourceType, resourceID); err != nil {
+		return err
+	}
+	for _, v := range values {
+		table, cols, vals, ok := searchIndexRow(v)
+		if !ok {
+			continue
+		}
+		placeholders := make([]string, len(vals)+3)
+		for i := range placeholders {
+			placeholders[i] = fmt.Sprintf("$%d", i+1)
+		}
+		args := append([]interface{}{resourceType, resourceID, v.ParamName}, vals...)
+		_, err := q.Exec(ctx, fmt.Sprintf("INSERT INTO %s (resource_type, resource_id, param, %s) VALUES (%s)",
+			table, cols, strings.Join(placeholders, ", ")), args...)
+		if err != nil {
+			return fmt.Errorf("index search parameter %s: %w", v.ParamName, err)
+		}
+	}
+	return nil
+}
+
+// DeleteSearchValues removes the indexed values of a resource.
+func (r *SearchIndexRepository) DeleteSearchValues(ctx context.Context, resourceType, resourceID string) error {
+	q := r.conn(ctx)
+	if q == nil {
+		return fmt.Errorf("no database connection in context")
+	}
+	return r.deleteAll(ctx, q, resourceType, resourceID)
+}
+
+func (r *SearchIndexRepository) deleteAll(ctx context.Context, q historyQuerier, resourceType, resourceID string) error {
+	for _, table := range searchIndexTableNames {
+		_, err := q.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE resource_type = $1 AND resource_id = $2", table),
+			resourceType, resourceID)
+		if err != nil {
+			return fmt.Errorf("delete search index: %w", err)
+		}
+	}
+	return nil
+}
+
+// searchIndexRow returns the table, value columns and values of the index
+// row for v, or false when v holds no value for its type.
+func searchIndexRow(v SearchIndexValue) (table, cols string, vals []interface{}, ok bool) {
+	str := func(p *string) string {
+		if p == nil {
+			return ""
+		}
+		return *p
+	}
+	table = searchIndexTables[v.ValueType]
+	switch v.ValueType {
+	case "string", "uri":
+		s := v.StringValue
+		if s == nil {
+			s = v.URIValue
+		}
+		if s == nil {
+			return "", "", nil, false
+		}
+		return table, "value", []interface{}{*s}, true
+	case "token":
+		if v.TokenCode == nil {
+			return "", "", nil, false
+		}
+		return table, "system, code", []interface{}{str(v.TokenSystem), *v.TokenCode}, true
+	case "date":
+		if v.DateValue == nil {
+			return "", "", nil, false
+		}
+		return table, "value", []interface{}{*v.DateValue}, true
+	case "number", "quantity":
+		n := v.NumberValue
+		if n == nil {
+			n = v.QuantityValue
+		}
+		if n == nil {
+			return "", "", nil, false
+		}
+		code := str(v.QuantityCode)
+		if code == "" {
+			code = str(v.QuantityUnit)
+		}
+		var canonicalValue, canonicalCode interface{}
+		if cv, cc, ok := CanonicalQuantity(*n, str(v.QuantitySystem), code); ok {
+			canonicalValue, canonicalCode = cv, cc
+		}
+		return table, "value, system, code, canonical_value, canonical_code",
+			[]interface{}{*n, str(v.QuantitySystem), code, canonicalValue, canonicalCode}, true
+	case "reference":
+		if v.ReferenceValue == nil {
+			return "", "", nil, false
+		}
+		rt, id, _, ok := parseLiteralReference(*v.ReferenceValue)
+		if !ok {
+			rt, id = "", *v.ReferenceValue
+		}
+		return table, "target_type, target_id", []interface{}{rt, id}, true
+	}
+	return "", "", nil, false
+}
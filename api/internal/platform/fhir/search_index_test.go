@@ -0,0 +1,442 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+func pharmacySearchParameter(status string) map[string]interface{} {
+	return map[string]interface{}{
+		"resourceType": "SearchParameter",
+		"id":           "sp-pharmacy",
+		"status":       status,
+		"code":         "preferred-pharmacy",
+		"base":         []interface{}{"Patient"},
+		"type":         "reference",
+		"expression":   "Patient.extension.where(url='http://example.org/preferred-pharmacy').valueReference",
+	}
+}
+
+func indexedPatient() map[string]interface{} {
+	return map[string]interface{}{
+		"resourceType": "Patient",
+		"id":           "p1",
+		"birthDate":    "1980-05-01",
+		"identifier": []interface{}{
+			map[string]interface{}{"system": "http://example.org/mrn", "value": "12345"},
+		},
+		"extension": []interface{}{
+			map[string]interface{}{
+				"url":            "http://example.org/preferred-pharmacy",
+				"valueReference": map[string]interface{}{"reference": "Organization/pharm1"},
+			},
+			map[string]interface{}{
+				"url":           "http://example.org/weight",
+				"valueQuantity": map[string]interface{}{"value": 72.5, "unit": "kg", "system": "http://unitsofmeasure.org", "code": "kg"},
+			},
+		},
+	}
+}
+
+func newTestSearchIndexer(t *testing.T, exprs ...*SearchParamExpression) (*SearchIndexer, *SearchExpressionIndex) {
+	t.Helper()
+	registry := NewSearchExpressionRegistry()
+	for _, expr := range exprs {
+		if err := registry.Register(expr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := NewSearchExpressionIndex()
+	return NewSearchIndexer(registry, store), store
+}
+
+// withSearchParameters makes sps the SearchParameter resources of every
+// tenant of ix.
+func withSearchParameters(ix *SearchIndexer, sps ...map[string]interface{}) {
+	ix.SetSearchParameterLoader(func(context.Context) ([]map[string]interface{}, error) {
+		return sps, nil
+	})
+}
+
+func TestSearchIndexer_Index(t *testing.T) {
+	ix, store := newTestSearchIndexer(t,
+		&SearchParamExpression{Name: "mrn", Type: "token", Expression: "Patient.identifier", ResourceTypes: []string{"Patient"}},
+		&SearchParamExpression{Name: "born", Type: "date", Expression: "Patient.birthDate", ResourceTypes: []string{"Patient"}},
+		&SearchParamExpression{Name: "weight", Type: "quantity", Expression: "Patient.extension.where(url='http://example.org/weight').valueQuantity", ResourceTypes: []string{"Patient"}},
+	)
+	withSearchParameters(ix, pharmacySearchParameter("active"))
+
+	if err := ix.Index(context.Background(), "Patient", "p1", indexedPatient()); err != nil {
+		t.Fatal(err)
+	}
+	if ids := store.Search("Patient", "mrn", "eq", "http://example.org/mrn|12345"); len(ids) != 1 {
+		t.Errorf("identifier should be indexed as a token, got %v", ids)
+	}
+	if ids := store.Search("Patient", "born", "eq", "1980-05-01"); len(ids) != 1 {
+		t.Errorf("birthDate should be indexed as a date, got %v", ids)
+	}
+	if ids := store.Search("Patient", "preferred-pharmacy", "eq", "Organization/pharm1"); len(ids) != 1 {
+		t.Errorf("extension reference should be indexed, got %v", ids)
+	}
+
+	var quantity *SearchIndexValue
+	for _, v := range store.values["Patient/p1"] {
+		if v.ParamName == "weight" {
+			v := v
+			quantity = &v
+		}
+	}
+	if quantity == nil || *quantity.QuantityValue != 72.5 || *quantity.QuantityCode != "kg" {
+		t.Errorf("unexpected quantity value: %+v", quantity)
+	}
+
+	if err := ix.Delete(context.Background(), "Patient", "p1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.values) != 0 {
+		t.Errorf("values should be removed, got %v", store.values)
+	}
+}
+
+func TestSearchIndexValue_Rows(t *testing.T) {
+	ix, store := newTestSearchIndexer(t,
+		&SearchParamExpression{Name: "weight", Type: "quantity", Expression: "Patient.extension.valueQuantity", ResourceTypes: []string{"Patient"}},
+	)
+	withSearchParameters(ix, pharmacySearchParameter("active"))
+	if err := ix.Index(context.Background(), "Patient", "p1", indexedPatient()); err != nil {
+		t.Fatal(err)
+	}
+	tables := map[string][]interface{}{}
+	for _, v := range store.values["Patient/p1"] {
+		table, _, vals, ok := searchIndexRow(v)
+		if !ok {
+			t.Fatalf("no row for %+v", v)
+		}
+		tables[table] = vals
+	}
+	if got := tables["resource_search_reference"]; len(got) != 2 || got[0] != "Organization" || got[1] != "pharm1" {
+		t.Errorf("unexpected reference row: %v", got)
+	}
+	if got := tables["resource_search_quantity"]; len(got) != 5 || got[0] != 72.5 || got[2] != "kg" || got[3] != 72500.0 || got[4] != "g" {
+		t.Errorf("unexpected quantity row: %v", got)
+	}
+}
+
+func TestSearchIndexer_SearchParameterEvents(t *testing.T) {
+	ix, _ := newTestSearchIndexer(t)
+	ctx := context.Background()
+	stored := []map[string]interface{}{pharmacySearchParameter("draft")}
+	ix.SetSearchParameterLoader(func(context.Context) ([]map[string]interface{}, error) {
+		return stored, nil
+	})
+	written := func(action string) ResourceEvent {
+		return ResourceEvent{ResourceType: "SearchParameter", ResourceID: "sp-pharmacy", Action: action}
+	}
+
+	if _, ok := ix.Lookup(ctx, "Patient", "preferred-pharmacy"); ok {
+		t.Error("draft SearchParameters should not be indexed")
+	}
+
+	stored = []map[string]interface{}{pharmacySearchParameter("active")}
+	ix.OnResourceEvent(ctx, written("update"))
+	if _, ok := ix.Lookup(ctx, "Patient", "preferred-pharmacy"); !ok {
+		t.Fatal("active SearchParameter should be registered")
+	}
+	if types, err := ix.IndexedTypes(ctx); err != nil || len(types) != 1 || types[0] != "Patient" {
+		t.Errorf("IndexedTypes() = %v, %v", types, err)
+	}
+
+	renamed := pharmacySearchParameter("active")
+	renamed["code"] = "pharmacy"
+	stored = []map[string]interface{}{renamed}
+	ix.OnResourceEvent(ctx, written("update"))
+	if _, ok := ix.Lookup(ctx, "Patient", "preferred-pharmacy"); ok {
+		t.Error("previous definition should be removed on update")
+	}
+	if _, ok := ix.Lookup(ctx, "Patient", "pharmacy"); !ok {
+		t.Error("updated definition should be registered")
+	}
+
+	stored = nil
+	ix.OnResourceEvent(ctx, written("delete"))
+	if _, ok := ix.Lookup(ctx, "Patient", "pharmacy"); ok {
+		t.Error("deleted SearchParameter should be removed")
+	}
+}
+
+func TestSearchIndexer_Tenants(t *testing.T) {
+	ix, _ := newTestSearchIndexer(t,
+		&SearchParamExpression{Name: "mrn", Type: "token", Expression: "Patient.identifier", ResourceTypes: []string{"Patient"}},
+	)
+	stored := map[string][]map[string]interface{}{
+		"a": {pharmacySearchParameter("active")},
+	}
+	loads := map[string]int{}
+	ix.SetSearchParameterLoader(func(ctx context.Context) ([]map[string]interface{}, error) {
+		tenant := db.TenantFromContext(ctx)
+		loads[tenant]++
+		// The loader's own search sees only the shared parameters.
+		if _, ok := ix.Lookup(ctx, "Patient", "preferred-pharmacy"); ok {
+			t.Error("loader should not see the tenant's parameters")
+		}
+		return stored[tenant], nil
+	})
+	ctxA := context.WithValue(context.Background(), db.TenantIDKey, "a")
+	ctxB := context.WithValue(context.Background(), db.TenantIDKey, "b")
+
+	if _, ok := ix.Lookup(ctxA, "Patient", "preferred-pharmacy"); !ok {
+		t.Error("tenant a should have its SearchParameter")
+	}
+	if _, ok := ix.Lookup(ctxB, "Patient", "preferred-pharmacy"); ok {
+		t.Error("tenant b should not see tenant a's SearchParameter")
+	}
+	if _, ok := ix.Lookup(ctxB, "Patient", "mrn"); !ok {
+		t.Error("shared parameters apply to every tenant")
+	}
+
+	// A SearchParameter written through another replica is seen once the
+	// definitions are older than the refresh interval.
+	stored["b"] = []map[string]interface{}{pharmacySearchParameter("active")}
+	if _, ok := ix.Lookup(ctxB, "Patient", "preferred-pharmacy"); ok {
+		t.Error("definitions should be reused within the refresh interval")
+	}
+	if loads["a"] != 1 || loads["b"] != 1 {
+		t.Errorf("loads = %v, want one per tenant", loads)
+	}
+	ix.refresh = 0
+	if _, ok := ix.Lookup(ctxB, "Patient", "preferred-pharmacy"); !ok {
+		t.Error("definitions should be reloaded after the refresh interval")
+	}
+
+	// Failed loads keep the definitions loaded before.
+	ix.SetSearchParameterLoader(func(context.Context) ([]map[string]interface{}, error) {
+		return nil, errors.New("connection refused")
+	})
+	if _, ok := ix.Lookup(ctxB, "Patient", "preferred-pharmacy"); !ok {
+		t.Error("previous definitions should be kept when loading fails")
+	}
+	if err := ix.Index(ctxB, "Patient", "p1", indexedPatient()); err == nil {
+		t.Error("indexing should fail when the definitions cannot be loaded")
+	}
+}
+
+func TestSearchQuery_CustomParams(t *testing.T) {
+	ix, _ := newTestSearchIndexer(t,
+		&SearchParamExpression{Name: "mrn", Type: "token", Expression: "Patient.identifier", ResourceTypes: []string{"Patient"}},
+		&SearchParamExpression{Name: "nickname", Type: "string", Expression: "Patient.name.given", ResourceTypes: []string{"Patient"}},
+		&SearchParamExpression{Name: "born", Type: "date", Expression: "Patient.birthDate", ResourceTypes: []string{"Patient"}},
+		&SearchParamExpression{Name: "weight", Type: "quantity", Expression: "Patient.extension.valueQuantity", ResourceTypes: []string{"Patient"}},
+	)
+	withSearchParameters(ix, pharmacySearchParameter("active"))
+	ctx := WithSearchServices(context.Background(), &SearchServices{CustomParams: ix})
+
+	cases := []struct {
+		params map[string]string
+		want   string
+		args   []interface{}
+	}{
+		{
+			map[string]string{"preferred-pharmacy": "Organization/pharm1"},
+			"fhir_id IN (SELECT resource_id FROM resource_search_reference WHERE resource_type = $1 AND param = $2 AND ((target_type = $3 AND target_id = $4)))",
+			[]interface{}{"Patient", "preferred-pharmacy", "Organization", "pharm1"},
+		},
+		{
+			map[string]string{"mrn": "http://example.org/mrn|12345,67890"},
+			"FROM resource_search_token WHERE resource_type = $1 AND param = $2 AND ((system = $3 AND code = $4) OR code = $5))",
+			[]interface{}{"Patient", "mrn", "http://example.org/mrn", "12345", "67890"},
+		},
+		{
+			map[string]string{"nickname:exact": "Jim"},
+			"FROM resource_search_string WHERE resource_type = $1 AND param = $2 AND (value = $3))",
+			[]interface{}{"Patient", "nickname", "Jim"},
+		},
+		{
+			map[string]string{"born": "ge1980-01-01"},
+			"FROM resource_search_date WHERE resource_type = $1 AND param = $2 AND (value >= $3))",
+			nil,
+		},
+		{
+			map[string]string{"weight": "gt70|http://unitsofmeasure.org|kg"},
+			"FROM resource_search_quantity WHERE resource_type = $1 AND param = $2 AND (((canonical_code = $3 AND canonical_value > $4) OR " +
+				"(canonical_code IS NULL AND (value > $5 AND system = $6 AND code = $7)))))",
+			nil,
+		},
+		{
+			map[string]string{"preferred-pharmacy:missing": "true"},
+			"fhir_id NOT IN (SELECT resource_id FROM resource_search_reference WHERE resource_type = $1 AND param = $2)",
+			[]interface{}{"Patient", "preferred-pharmacy"},
+		},
+	}
+	for _, tc := range cases {
+		q := NewSearchQuery("patient", "id")
+		q.ApplyParams(ctx, tc.params, nil)
+		if !strings.Contains(q.CountSQL(), tc.want) {
+			t.Errorf("%v: expected %q in %s", tc.params, tc.want, q.CountSQL())
+		}
+		if tc.args != nil {
+			got := q.CountArgs()
+			if len(got) != len(tc.args) {
+				t.Errorf("%v: args = %v, want %v", tc.params, got, tc.args)
+				continue
+			}
+			for i := range got {
+				if got[i] != tc.args[i] {
+					t.Errorf("%v: args = %v, want %v", tc.params, got, tc.args)
+					break
+				}
+			}
+		}
+		if q.Idx() != len(q.CountArgs())+1 {
+			t.Errorf("%v: Idx() = %d with %d args", tc.params, q.Idx(), len(q.CountArgs()))
+		}
+	}
+
+	// Parameters mapped to a column take precedence.
+	q := NewSearchQuery("patient", "id")
+	q.ApplyParams(ctx, map[string]string{"mrn": "12345"}, map[string]SearchParamConfig{"mrn": {Type: SearchParamToken, Column: "mrn"}})
+	if strings.Contains(q.CountSQL(), "resource_search_token") {
+		t.Errorf("configured parameter should not use the index: %s", q.CountSQL())
+	}
+
+	// Other resource types do not see the parameter.
+	q = NewSearchQuery("observation", "id")
+	q.ApplyParams(ctx, map[string]string{"preferred-pharmacy": "Organization/pharm1"}, nil)
+	if strings.Contains(q.CountSQL(), "resource_search") {
+		t.Errorf("parameter should only apply to its base: %s", q.CountSQL())
+	}
+}
+
+func TestReindexJob(t *testing.T) {
+	ix, store := newTestSearchIndexer(t)
+	withSearchParameters(ix, pharmacySearchParameter("active"))
+
+	var patients []map[string]interface{}
+	for i := 0; i < reindexPageSize+5; i++ {
+		p := indexedPatient()
+		p["id"] = "p" + strings.Repeat("x", i)
+		patients = append(patients, p)
+	}
+	resources := NewResourceRegistry()
+	resources.Register("Patient", ResourceOps{Search: func(_ context.Context, params url.Values) ([]map[string]interface{}, error) {
+		var offset, count int
+		_ = json.Unmarshal([]byte(params.Get("_offset")), &offset)
+		_ = json.Unmarshal([]byte(params.Get("_count")), &count)
+		end := offset + count
+		if end > len(patients) {
+			end = len(patients)
+		}
+		if offset > end {
+			offset = end
+		}
+		return patients[offset:end], nil
+	}})
+
+	jobs := NewInMemoryAsyncJobStore()
+	job := &AsyncJob{ID: "reindex-1", Kind: "reindex"}
+	if err := jobs.Create(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	vt := NewVersionTracker(nil)
+	vt.SetSearchIndexer(ix)
+	types, err := ix.IndexedTypes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := json.Marshal(&ReindexRequest{ResourceTypes: types})
+	if err := ReindexJobFunc(jobs, vt, resources)(context.Background(), job.ID, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	done, _ := jobs.Get(context.Background(), job.ID)
+	if done.Status != AsyncStatusCompleted || len(done.Output) != 1 || done.Output[0].Count != len(patients) {
+		t.Fatalf("unexpected job: %+v", done)
+	}
+	if ids := store.Search("Patient", "preferred-pharmacy", "eq", "Organization/pharm1"); len(ids) != len(patients) {
+		t.Errorf("indexed %d resources, want %d", len(ids), len(patients))
+	}
+
+	// Unknown types fail the job.
+	payload, _ = json.Marshal(&ReindexRequest{ResourceTypes: []string{"Group"}})
+	_ = ReindexJobFunc(jobs, vt, resources)(context.Background(), job.ID, payload)
+	if failed, _ := jobs.Get(context.Background(), job.ID); failed.Status != AsyncStatusError {
+		t.Errorf("status = %s, want error", failed.Status)
+	}
+}
+
+func TestReindexJob_Backfill(t *testing.T) {
+	ix, _ := newTestSearchIndexer(t)
+	resources := NewResourceRegistry()
+	backfills := 0
+	resources.Register("Observation", ResourceOps{
+		Search: func(context.Context, url.Values) ([]map[string]interface{}, error) {
+			t.Error("types without custom parameters should not be searched")
+			return nil, nil
+		},
+		Reindex: func(context.Context) (int, error) {
+			backfills++
+			return 7, nil
+		},
+	})
+	vt := NewVersionTracker(nil)
+	vt.SetSearchIndexer(ix)
+	types, err := reindexDefaultTypes(context.Background(), vt, resources)
+	if err != nil || len(types) != 1 || types[0] != "Observation" {
+		t.Fatalf("default types = %v, %v", types, err)
+	}
+
+	jobs := NewInMemoryAsyncJobStore()
+	job := &AsyncJob{ID: "reindex-2", Kind: "reindex"}
+	if err := jobs.Create(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := json.Marshal(&ReindexRequest{ResourceTypes: types})
+	if err := ReindexJobFunc(jobs, vt, resources)(context.Background(), job.ID, payload); err != nil {
+		t.Fatal(err)
+	}
+	done, _ := jobs.Get(context.Background(), job.ID)
+	if backfills != 1 || done.Status != AsyncStatusCompleted || len(done.Output) != 1 || done.Output[0].Count != 7 {
+		t.Errorf("backfills = %d, job = %+v", backfills, done)
+	}
+}
+
+func TestReindexJob_TextIndex(t *testing.T) {
+	resources := NewResourceRegistry()
+	resources.Register("Condition", ResourceOps{Search: func(_ context.Context, params url.Values) ([]map[string]interface{}, error) {
+		if params.Get("_offset") != "0" {
+			return nil, nil
+		}
+		return []map[string]interface{}{{
+			"resourceType": "Condition",
+			"id":           "c1",
+			"text":         map[string]interface{}{"div": "<div>Community acquired pneumonia</div>"},
+		}}, nil
+	}})
+	text := NewInMemoryTextIndex()
+	vt := NewVersionTracker(nil)
+	vt.SetTextIndex(text)
+
+	// Every type has text, so every type is reindexed by default.
+	types, err := reindexDefaultTypes(context.Background(), vt, resources)
+	if err != nil || len(types) != 1 || types[0] != "Condition" {
+		t.Fatalf("default types = %v, %v", types, err)
+	}
+	jobs := NewInMemoryAsyncJobStore()
+	job := &AsyncJob{ID: "reindex-3", Kind: "reindex"}
+	if err := jobs.Create(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := json.Marshal(&ReindexRequest{ResourceTypes: types})
+	if err := ReindexJobFunc(jobs, vt, resources)(context.Background(), job.ID, payload); err != nil {
+		t.Fatal(err)
+	}
+	doc, ok := text.GetText("Condition", "c1")
+	if !ok || !strings.Contains(doc.Narrative, "pneumonia") {
+		t.Errorf("text index = %+v, %v", doc, ok)
+	}
+}
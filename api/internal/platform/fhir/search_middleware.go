@@ -10,8 +10,8 @@ import (
 )
 
 // SearchMiddleware applies FHIR search parameter processing to responses.
-// It handles _elements, _summary, _count, _total, and _sort post-processing.
-// This middleware wraps search responses to apply projection and pagination.
+// It handles _total post-processing; _elements and _summary are applied by
+// ProjectionMiddleware.
 func SearchMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -20,12 +20,10 @@ func SearchMiddleware() echo.MiddlewareFunc {
 				return next(c)
 			}
 
-			elements := c.QueryParam("_elements")
-			summary := c.QueryParam("_summary")
 			total := c.QueryParam("_total")
 
-			// If no projection params, just pass through
-			if elements == "" && summary == "" && total == "" {
+			// If no post-processing params, just pass through
+			if total == "" {
 				return next(c)
 			}
 
@@ -58,9 +56,6 @@ func SearchMiddleware() echo.MiddlewareFunc {
 				return flushSearchRecorder(origWriter, rec)
 			}
 
-			// Apply projection to bundle entries
-			ApplyProjectionToBundle(&bundle, elements, summary)
-
 			// Handle _total=none (remove total from bundle)
 			if total == "none" {
 				bundle.Total = nil
@@ -684,6 +684,12 @@ func (h *SearchParameterHandler) RegisterRoutes(g *echo.Group) {
 	g.DELETE("/SearchParameter/:id", h.Delete)
 }
 
+// RegisterResources registers the SearchParameter read and search routes
+// with r.
+func (h *SearchParameterHandler) RegisterResources(r *ResourceRegistry) {
+	r.RegisterInteractions("SearchParameter", "read", "search-type")
+}
+
 // Search handles GET /fhir/SearchParameter, returning a searchset Bundle of
 // matching SearchParameter resources. Supported query parameters: name, code,
 // url, status, type, base.
@@ -1,6 +1,7 @@
 package fhir
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -16,7 +17,7 @@ const (
 	SearchParamString                           // String: case-insensitive prefix match, supports :exact, :contains
 	SearchParamReference                        // Reference: handles "ResourceType/uuid" or "uuid"
 	SearchParamNumber                           // Number: supports prefixes (gt, lt, ge, le, eq, etc.)
-	SearchParamQuantity                         // Quantity: number with unit (treated as number on value column)
+	SearchParamQuantity                         // Quantity: number with unit, UCUM-aware when UnitColumn is set
 	SearchParamURI                              // URI: exact match
 )
 
@@ -25,17 +26,29 @@ type SearchParamConfig struct {
 	Type      SearchParamType
 	Column    string // Primary DB column (code column for tokens)
 	SysColumn string // System column for token params (e.g., "code_system")
+
+	// Quantity params with a unit: the unit code column, and the columns of
+	// the value in its canonical UCUM unit (see QuantitySearchClause).
+	UnitColumn          string // e.g., "value_code"
+	CanonicalColumn     string // e.g., "value_quantity_canonical"
+	CanonicalUnitColumn string // e.g., "value_unit_canonical"
+	AnalyteColumn       string // e.g., "code_value"; see QuantityColumns
 }
 
 // SearchQuery builds SQL WHERE clauses from FHIR search parameters.
 // It encapsulates the common search pattern used across all domain repositories.
 type SearchQuery struct {
-	table   string
-	cols    string
-	where   string
-	args    []interface{}
-	idx     int
-	orderBy string
+	table    string
+	cols     string
+	where    string
+	args     []interface{}
+	idx      int
+	orderBy  string
+	rank     string // relevance expression set by full-text search
+	byRank   bool   // _sort=_score was requested
+	count    bool   // _summary=count was requested
+	services *SearchServices
+	custom   *searchParamSet // custom parameters of the searching tenant
 }
 
 // NewSearchQuery creates a new SearchQuery for the given table and columns.
@@ -97,6 +110,14 @@ func (q *SearchQuery) AddNumber(column, value string) {
 	q.idx = nextIdx
 }
 
+// AddQuantity adds a quantity search clause; see QuantitySearchClause.
+func (q *SearchQuery) AddQuantity(cols QuantityColumns, value string) {
+	clause, args, nextIdx := QuantitySearchClause(cols, value, q.idx)
+	q.where += " AND " + clause
+	q.args = append(q.args, args...)
+	q.idx = nextIdx
+}
+
 // ApplyParam applies a single FHIR search parameter using the config.
 func (q *SearchQuery) ApplyParam(config SearchParamConfig, value string) {
 	switch config.Type {
@@ -115,7 +136,17 @@ func (q *SearchQuery) ApplyParam(config SearchParamConfig, value string) {
 		q.AddString(config.Column, value, "")
 	case SearchParamReference:
 		q.AddRef(config.Column, value)
-	case SearchParamNumber, SearchParamQuantity:
+	case SearchParamQuantity:
+		if config.UnitColumn == "" {
+			q.AddNumber(config.Column, value)
+			break
+		}
+		q.AddQuantity(QuantityColumns{
+			Value: config.Column, System: config.SysColumn, Code: config.UnitColumn,
+			CanonicalValue: config.CanonicalColumn, CanonicalCode: config.CanonicalUnitColumn,
+			Analyte: config.AnalyteColumn,
+		}, value)
+	case SearchParamNumber:
 		q.AddNumber(config.Column, value)
 	case SearchParamURI:
 		q.where += fmt.Sprintf(" AND %s = $%d", config.Column, q.idx)
@@ -125,12 +156,86 @@ func (q *SearchQuery) ApplyParam(config SearchParamConfig, value string) {
 }
 
 // ApplyParams applies all matching FHIR search parameters from the given map.
-func (q *SearchQuery) ApplyParams(params map[string]string, configs map[string]SearchParamConfig) {
+// The _text and _content parameters are answered by the full-text search
+// engine of the SearchServices in ctx, if any, and results are ordered by
+// relevance when ctx requests it (see WithRelevanceSort).
+// Chained (subject:Patient.name) and reverse-chained (_has) parameters are
+// compiled into subqueries on the tables registered with the ChainRegistry
+// of the SearchServices.
+// Custom search parameters of the tenant of ctx are answered from the index
+// of the SearchIndexer of the SearchServices, and _compartment restricts the search
+// to the members of a compartment. A _filter expression is compiled against
+// the same search parameters.
+func (q *SearchQuery) ApplyParams(ctx context.Context, params map[string]string, configs map[string]SearchParamConfig) {
+	q.services = SearchServicesFromContext(ctx)
+	q.custom = q.services.customParams(ctx)
 	for name, value := range params {
-		if config, ok := configs[name]; ok {
-			q.ApplyParam(config, value)
-		}
+		q.applyNamed(name, value, configs, 0)
+	}
+	q.byRank = relevanceSortFromContext(ctx)
+	q.count = params["_summary"] == "count"
+}
+
+// subquery returns a query on table whose arguments continue q's, for the
+// subqueries of chains, _has and _filter.
+func (q *SearchQuery) subquery(table string) *SearchQuery {
+	return &SearchQuery{table: table, idx: q.idx, services: q.services, custom: q.custom}
+}
+
+// CountOnly reports that the search asked for _summary=count, so only the
+// count query needs to run: repositories return the total without running
+// DataSQL.
+func (q *SearchQuery) CountOnly() bool { return q.count }
+
+// applyNamed applies the search parameter name and reports whether it is
+// one the query knows how to answer. depth is the number of chain levels
+// the query is nested in.
+func (q *SearchQuery) applyNamed(name, value string, configs map[string]SearchParamConfig, depth int) bool {
+	if config, ok := configs[name]; ok {
+		q.ApplyParam(config, value)
+	} else if expr, modifier, ok := q.customParam(name); ok {
+		q.applyCustom(expr, modifier, value)
+	} else if has, ok := ParseHasParam(name); ok {
+		q.applyHas(has, value, depth)
+	} else if chain, ok := ParseChainedParam(name); ok && !strings.HasPrefix(name, "_") {
+		q.applyChain(chain, value, configs, depth)
+	} else if name == "_text" || name == "_content" {
+		q.applyFullText(name, value)
+	} else if name == CompartmentParam {
+		q.applyCompartment(value, configs)
+	} else if name == "_filter" {
+		q.applyFilter(value, configs)
+	} else if base, _ := ParseParamModifier(name); IsMetaSearchParam(base) {
+		q.applyMeta(name, value)
+	} else {
+		return false
 	}
+	return true
+}
+
+// applyFullText adds a _text or _content clause. A query that cannot be
+// answered, because it has no words or the table holds no FHIR resource,
+// matches nothing.
+func (q *SearchQuery) applyFullText(name, value string) {
+	if q.services == nil || q.services.FullText == nil {
+		return
+	}
+	engine := q.services.FullText
+	if err := engine.ApplyFullTextSearch(q, name, value); err != nil {
+		q.where += " AND 1=0"
+	}
+}
+
+// applyMeta adds a _tag, _security or _profile clause. The meta is looked up
+// by resource type, so a table that holds no FHIR resource matches nothing.
+func (q *SearchQuery) applyMeta(name, value string) {
+	resourceType := ResourceTypeForTable(q.table)
+	if resourceType == "" {
+		q.where += " AND 1=0"
+		return
+	}
+	clause, args := metaSearchCondition(resourceType, name, value, q.idx)
+	q.Add(clause, args...)
 }
 
 // OrderBy sets the ORDER BY clause (without the "ORDER BY" keyword).
@@ -151,8 +256,16 @@ func (q *SearchQuery) CountArgs() []interface{} {
 // DataSQL returns the data query SQL with ORDER BY and LIMIT/OFFSET.
 func (q *SearchQuery) DataSQL(limit, offset int) string {
 	sql := fmt.Sprintf("SELECT %s FROM %s WHERE 1=1%s", q.cols, q.table, q.where)
-	if q.orderBy != "" {
-		sql += " ORDER BY " + q.orderBy
+	orderBy := q.orderBy
+	if q.byRank && q.rank != "" {
+		if orderBy != "" {
+			orderBy = q.rank + " DESC, " + orderBy
+		} else {
+			orderBy = q.rank + " DESC"
+		}
+	}
+	if orderBy != "" {
+		sql += " ORDER BY " + orderBy
 	}
 	sql += fmt.Sprintf(" LIMIT $%d OFFSET $%d", q.idx, q.idx+1)
 	return sql
@@ -200,7 +313,11 @@ func (q *SearchQuery) ApplySort(sortParam, defaultOrder string, configs map[stri
 }
 
 // ExtractSearchParams extracts all FHIR search parameters from the query string,
-// excluding FHIR control parameters (_count, _offset, _sort, _elements, etc.).
+// excluding FHIR control parameters (_count, _offset, _elements, etc.). The
+// full-text parameters _text and _content and the meta parameters _tag,
+// _security and _profile are kept, and so is _summary, which ApplyParams
+// inspects for count. Relevance ordering (_sort=_score) reaches the
+// repositories through the context (see SearchServicesMiddleware).
 // Unknown params are included — the repo's ApplyParams will ignore ones not in its config.
 func ExtractSearchParams(c echo.Context) map[string]string {
 	params := map[string]string{}
@@ -208,8 +325,8 @@ func ExtractSearchParams(c echo.Context) map[string]string {
 		if len(v) == 0 {
 			continue
 		}
-		// Skip FHIR control params except _id and _revinclude
-		if strings.HasPrefix(k, "_") && k != "_id" && k != "_revinclude" {
+		// Skip FHIR control params except _id, _has, _revinclude, the full-text and the meta ones
+		if base, _ := ParseParamModifier(k); strings.HasPrefix(k, "_") && !passedControlParams[base] {
 			continue
 		}
 		params[k] = v[0]
@@ -217,6 +334,21 @@ func ExtractSearchParams(c echo.Context) map[string]string {
 	return params
 }
 
+// passedControlParams lists the underscore parameters ExtractSearchParams keeps.
+var passedControlParams = map[string]bool{
+	"_id":          true,
+	"_compartment": true,
+	"_filter":      true,
+	"_has":         true,
+	"_revinclude":  true,
+	"_text":        true,
+	"_content":     true,
+	"_tag":         true,
+	"_security":    true,
+	"_profile":     true,
+	"_summary":     true,
+}
+
 // ExtractRevIncludes extracts _revinclude parameters from the request.
 // Returns a slice of "ResourceType:searchParam" strings.
 func ExtractRevIncludes(c echo.Context) []string {
@@ -1,6 +1,7 @@
 package fhir
 
 import (
+	"context"
 	"strings"
 	"testing"
 )
@@ -44,7 +45,7 @@ func TestSearchQueryApplyParams(t *testing.T) {
 
 	t.Run("reference param strips ResourceType prefix", func(t *testing.T) {
 		q := NewSearchQuery("observation", "id")
-		q.ApplyParams(map[string]string{"patient": "Patient/abc-123"}, configs)
+		q.ApplyParams(context.Background(), map[string]string{"patient": "Patient/abc-123"}, configs)
 		if len(q.CountArgs()) != 1 || q.CountArgs()[0] != "abc-123" {
 			t.Errorf("reference should strip prefix, got args: %v", q.CountArgs())
 		}
@@ -52,7 +53,7 @@ func TestSearchQueryApplyParams(t *testing.T) {
 
 	t.Run("token param with system|code", func(t *testing.T) {
 		q := NewSearchQuery("observation", "id")
-		q.ApplyParams(map[string]string{"code": "http://loinc.org|1234-5"}, configs)
+		q.ApplyParams(context.Background(), map[string]string{"code": "http://loinc.org|1234-5"}, configs)
 		args := q.CountArgs()
 		if len(args) != 2 {
 			t.Fatalf("expected 2 args for system|code, got %d: %v", len(args), args)
@@ -64,7 +65,7 @@ func TestSearchQueryApplyParams(t *testing.T) {
 
 	t.Run("simple token param", func(t *testing.T) {
 		q := NewSearchQuery("observation", "id")
-		q.ApplyParams(map[string]string{"status": "final"}, configs)
+		q.ApplyParams(context.Background(), map[string]string{"status": "final"}, configs)
 		sql := q.CountSQL()
 		if !strings.Contains(sql, "status = $1") {
 			t.Errorf("expected exact match for simple token: %s", sql)
@@ -73,7 +74,7 @@ func TestSearchQueryApplyParams(t *testing.T) {
 
 	t.Run("date param with prefix", func(t *testing.T) {
 		q := NewSearchQuery("observation", "id")
-		q.ApplyParams(map[string]string{"date": "gt2023-01-01"}, configs)
+		q.ApplyParams(context.Background(), map[string]string{"date": "gt2023-01-01"}, configs)
 		sql := q.CountSQL()
 		if !strings.Contains(sql, "effective_date >") {
 			t.Errorf("expected > for gt prefix: %s", sql)
@@ -82,7 +83,7 @@ func TestSearchQueryApplyParams(t *testing.T) {
 
 	t.Run("string param default prefix match", func(t *testing.T) {
 		q := NewSearchQuery("patient", "id")
-		q.ApplyParams(map[string]string{"name": "Smith"}, configs)
+		q.ApplyParams(context.Background(), map[string]string{"name": "Smith"}, configs)
 		sql := q.CountSQL()
 		if !strings.Contains(sql, "ILIKE") {
 			t.Errorf("expected ILIKE for string search: %s", sql)
@@ -98,7 +99,7 @@ func TestSearchQueryApplyParams(t *testing.T) {
 
 	t.Run("number param with prefix", func(t *testing.T) {
 		q := NewSearchQuery("observation", "id")
-		q.ApplyParams(map[string]string{"value-quantity": "ge100"}, configs)
+		q.ApplyParams(context.Background(), map[string]string{"value-quantity": "ge100"}, configs)
 		sql := q.CountSQL()
 		if !strings.Contains(sql, "value_quantity >=") {
 			t.Errorf("expected >= for ge prefix: %s", sql)
@@ -107,7 +108,7 @@ func TestSearchQueryApplyParams(t *testing.T) {
 
 	t.Run("multiple params combined", func(t *testing.T) {
 		q := NewSearchQuery("observation", "id")
-		q.ApplyParams(map[string]string{
+		q.ApplyParams(context.Background(), map[string]string{
 			"patient": "p1",
 			"status":  "final",
 		}, configs)
@@ -122,7 +123,7 @@ func TestSearchQueryApplyParams(t *testing.T) {
 
 	t.Run("unknown param ignored", func(t *testing.T) {
 		q := NewSearchQuery("observation", "id")
-		q.ApplyParams(map[string]string{"unknown-param": "foo"}, configs)
+		q.ApplyParams(context.Background(), map[string]string{"unknown-param": "foo"}, configs)
 		if len(q.CountArgs()) != 0 {
 			t.Errorf("expected 0 args for unknown param, got %d", len(q.CountArgs()))
 		}
@@ -143,3 +144,21 @@ func TestSearchQueryIdx(t *testing.T) {
 		t.Errorf("idx should be 4 after three args, got %d", q.Idx())
 	}
 }
+
+func TestSearchQueryCountOnly(t *testing.T) {
+	configs := map[string]SearchParamConfig{"status": {Type: SearchParamToken, Column: "status"}}
+	q := NewSearchQuery("test", "id")
+	q.ApplyParams(context.Background(), map[string]string{"status": "active", "_summary": "count"}, configs)
+	if !q.CountOnly() {
+		t.Error("expected CountOnly for _summary=count")
+	}
+	if len(q.CountArgs()) != 1 {
+		t.Errorf("_summary should not add a clause, got args %v", q.CountArgs())
+	}
+
+	q = NewSearchQuery("test", "id")
+	q.ApplyParams(context.Background(), map[string]string{"_summary": "true"}, configs)
+	if q.CountOnly() {
+		t.Error("CountOnly should only be set for _summary=count")
+	}
+}
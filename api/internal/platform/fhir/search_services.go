@@ -0,0 +1,68 @@
+package fhir
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SearchServices are the server-wide services SearchQuery.ApplyParams
+// answers parameters with beyond a repository's own columns. They travel in
+// the request context (see SearchServicesMiddleware) rather than in package
+// state, so that each server, and each test, uses its own.
+type SearchServices struct {
+	// FullText answers _text and _content; while nil they are ignored.
+	FullText *FullTextSearchEngine
+	// Chains holds the search tables that chained, _has and _filter
+	// parameters are compiled against; while nil they match nothing.
+	Chains *ChainRegistry
+	// CustomParams answers the custom search parameters of the tenant of
+	// the context; while nil they are ignored.
+	CustomParams *SearchIndexer
+}
+
+type searchServicesKey struct{}
+
+// WithSearchServices returns a context carrying s. Background jobs that
+// search outside a request, such as the async job runner, run in such a
+// context.
+func WithSearchServices(ctx context.Context, s *SearchServices) context.Context {
+	return context.WithValue(ctx, searchServicesKey{}, s)
+}
+
+// SearchServicesFromContext returns the services carried by ctx, or empty
+// services, which answer no parameter beyond the repositories' columns.
+func SearchServicesFromContext(ctx context.Context) *SearchServices {
+	if s, ok := ctx.Value(searchServicesKey{}).(*SearchServices); ok && s != nil {
+		return s
+	}
+	return &SearchServices{}
+}
+
+type relevanceSortKey struct{}
+
+// WithRelevanceSort returns a context in which searches order their results
+// by full-text relevance, best match first, as _sort=_score requests.
+func WithRelevanceSort(ctx context.Context, byScore bool) context.Context {
+	return context.WithValue(ctx, relevanceSortKey{}, byScore)
+}
+
+// relevanceSortFromContext reports whether ctx requests relevance ordering.
+func relevanceSortFromContext(ctx context.Context) bool {
+	byScore, _ := ctx.Value(relevanceSortKey{}).(bool)
+	return byScore
+}
+
+// SearchServicesMiddleware puts s in the context of each request, along
+// with the relevance ordering requested by its _sort parameter, which
+// ExtractSearchParams leaves out of the repositories' parameters.
+func SearchServicesMiddleware(s *SearchServices) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := WithSearchServices(c.Request().Context(), s)
+			ctx = WithRelevanceSort(ctx, sortsByScore(c.QueryParam("_sort")))
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
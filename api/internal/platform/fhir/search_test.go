@@ -1,6 +1,8 @@
 package fhir
 
 import (
+	"math"
+	"strings"
 	"testing"
 	"time"
 )
@@ -527,3 +529,94 @@ func TestParseSearchValue_UpperCasePrefix(t *testing.T) {
 		t.Errorf("value = %q, want %q", result.Value, "2023-01-01")
 	}
 }
+
+func TestNumberSearchClause_Approximate(t *testing.T) {
+	clause, args, nextIdx := NumberSearchClause("value", "ap100", 1)
+	if clause != "(value >= $1 AND value <= $2)" {
+		t.Errorf("clause = %q", clause)
+	}
+	if len(args) != 2 || args[0] != 90.0 || args[1] != 110.0 {
+		t.Errorf("args = %v, want [90 110]", args)
+	}
+	if nextIdx != 3 {
+		t.Errorf("nextIdx = %d, want 3", nextIdx)
+	}
+
+	// Zero is approximated by its implicit precision.
+	_, args, _ = NumberSearchClause("value", "ap0", 1)
+	if len(args) != 2 || args[0] != -0.5 || args[1] != 0.5 {
+		t.Errorf("args = %v, want [-0.5 0.5]", args)
+	}
+}
+
+func TestQuantitySearchClause(t *testing.T) {
+	cols := QuantityColumns{
+		Value: "value_quantity", System: "value_system", Code: "value_code",
+		CanonicalValue: "value_quantity_canonical", CanonicalCode: "value_unit_canonical",
+	}
+
+	// A bare number compares the value alone.
+	clause, args, nextIdx := QuantitySearchClause(cols, "gt5.4", 1)
+	if clause != "value_quantity > $1" || len(args) != 1 || nextIdx != 2 {
+		t.Errorf("bare number: %q %v %d", clause, args, nextIdx)
+	}
+
+	// UCUM units are compared in canonical units, with a fallback on the
+	// stored unit for rows without a canonical value.
+	clause, args, nextIdx = QuantitySearchClause(cols, "gt5.4|http://unitsofmeasure.org|mg/dL", 1)
+	want := "((value_unit_canonical = $1 AND value_quantity_canonical > $2) OR " +
+		"(value_unit_canonical IS NULL AND (value_quantity > $3 AND value_system = $4 AND value_code = $5)))"
+	if clause != want {
+		t.Errorf("clause = %q, want %q", clause, want)
+	}
+	if len(args) != 5 || args[0] != "g.m-3" || args[1] != 54.0 || args[2] != "5.4" {
+		t.Errorf("args = %v", args)
+	}
+	if nextIdx != 6 {
+		t.Errorf("nextIdx = %d, want 6", nextIdx)
+	}
+
+	// eq matches the implicit precision of the value.
+	clause, args, _ = QuantitySearchClause(cols, "5.4||g/L", 1)
+	if !strings.HasPrefix(clause, "((value_unit_canonical = $1 AND (value_quantity_canonical >= $2 AND value_quantity_canonical < $3))") {
+		t.Errorf("clause = %q", clause)
+	}
+	if args[1] != 5350.0 || args[2] != 5450.0 {
+		t.Errorf("args = %v, want range [5350 5450)", args)
+	}
+	if strings.Contains(clause, "value_system") {
+		t.Errorf("empty system should not be matched: %q", clause)
+	}
+
+	// Non-UCUM units are matched as given.
+	clause, _, _ = QuantitySearchClause(cols, "5|http://example.org/units|tabs", 1)
+	if clause != "(value_quantity = $1 AND value_system = $2 AND value_code = $3)" {
+		t.Errorf("clause = %q", clause)
+	}
+
+	// Tables without canonical columns compare the stored unit.
+	clause, _, _ = QuantitySearchClause(QuantityColumns{Value: "v", Code: "c"}, "5|http://unitsofmeasure.org|mg", 1)
+	if clause != "(v = $1 AND c = $2)" {
+		t.Errorf("clause = %q", clause)
+	}
+
+	// With the analyte column, a substance concentration also matches the
+	// mass concentrations of analytes of known molar mass.
+	cols.Analyte = "code_value"
+	clause, args, nextIdx = QuantitySearchClause(cols, "gt5.4|http://unitsofmeasure.org|mmol/L", 1)
+	glucose := "(code_value IN ($3, $4, $5, $6, $7, $8) AND (value_unit_canonical = $9 AND value_quantity_canonical > $10))"
+	if !strings.Contains(clause, glucose) {
+		t.Fatalf("clause = %q, want the glucose alternative %q", clause, glucose)
+	}
+	if args[0] != "m-3" || args[8] != "g.m-3" || math.Abs(args[9].(float64)-972.8424) > 1e-6 {
+		t.Errorf("args = %v, want 5.4 mmol/L of glucose as 972.8424 g.m-3", args)
+	}
+	if nextIdx != len(args)+1 {
+		t.Errorf("nextIdx = %d with %d args", nextIdx, len(args))
+	}
+	// Units that are neither have no alternatives.
+	clause, _, _ = QuantitySearchClause(cols, "gt5|http://unitsofmeasure.org|10*9/L", 1)
+	if strings.Contains(clause, "code_value") {
+		t.Errorf("count concentration should not be converted: %q", clause)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -29,12 +30,153 @@ type ViewDefinition struct {
 }
 
 // ViewColumn defines a single column in a ViewDefinition output.
+//
+// As in SQL-on-FHIR v2, an entry of a select list may instead be a select
+// element grouping further entries, recognised by having no Path: Column
+// lists its columns and Select nests further elements; ForEach produces a
+// row for each item of its path, which its columns and nested elements are
+// evaluated against, and ForEachOrNull also produces a row of nulls when
+// there are none; UnionAll appends the rows of alternative elements that
+// have the same columns. The rows of the entries of a list are combined as
+// a cross join.
 type ViewColumn struct {
-	Path        string `json:"path"`
-	Name        string `json:"name"`
+	Path        string `json:"path,omitempty"`
+	Name        string `json:"name,omitempty"`
 	Type        string `json:"type,omitempty"`
 	Collection  bool   `json:"collection,omitempty"`
 	Description string `json:"description,omitempty"`
+
+	Column        []ViewColumn `json:"column,omitempty"`
+	Select        []ViewColumn `json:"select,omitempty"`
+	ForEach       string       `json:"forEach,omitempty"`
+	ForEachOrNull string       `json:"forEachOrNull,omitempty"`
+	UnionAll      []ViewColumn `json:"unionAll,omitempty"`
+}
+
+// isSelect reports whether the entry is a select element rather than a
+// column.
+func (c ViewColumn) isSelect() bool {
+	return c.Path == "" && (len(c.Column) > 0 || len(c.Select) > 0 || c.ForEach != "" || c.ForEachOrNull != "" || len(c.UnionAll) > 0)
+}
+
+// forEachPath returns the path of a ForEach or ForEachOrNull element.
+func (c ViewColumn) forEachPath() string {
+	if c.ForEach != "" {
+		return c.ForEach
+	}
+	return c.ForEachOrNull
+}
+
+// viewColumns returns the output columns of a select list, in order: the
+// columns of each element, then those of its nested elements and of its
+// first unionAll alternative.
+func viewColumns(list []ViewColumn) []ViewColumn {
+	var cols []ViewColumn
+	for _, c := range list {
+		if !c.isSelect() {
+			cols = append(cols, c)
+			continue
+		}
+		cols = append(cols, viewColumns(c.Column)...)
+		cols = append(cols, viewColumns(c.Select)...)
+		if len(c.UnionAll) > 0 {
+			cols = append(cols, viewColumns(c.UnionAll[:1])...)
+		}
+	}
+	return cols
+}
+
+// Columns returns the output columns of the view.
+func (v *ViewDefinition) Columns() []ViewColumn {
+	return viewColumns(v.Select)
+}
+
+// Validate checks that the view names its resource and has columns, that
+// column names are unique, and that the alternatives of each unionAll have
+// the same columns.
+func (v *ViewDefinition) Validate() error {
+	if v.Resource == "" {
+		return fmt.Errorf("resource field is required")
+	}
+	cols := v.Columns()
+	if len(cols) == 0 {
+		return fmt.Errorf("at least one column is required")
+	}
+	seen := make(map[string]bool, len(cols))
+	for _, c := range cols {
+		if c.Name == "" {
+			return fmt.Errorf("column %q has no name", c.Path)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("duplicate column name %q", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return validateUnions(v.Select)
+}
+
+func validateUnions(list []ViewColumn) error {
+	for _, c := range list {
+		if !c.isSelect() {
+			continue
+		}
+		if c.ForEach != "" && c.ForEachOrNull != "" {
+			return fmt.Errorf("a select element cannot have both forEach and forEachOrNull")
+		}
+		if len(c.UnionAll) > 0 {
+			want := columnNames(viewColumns(c.UnionAll[:1]))
+			for _, alt := range c.UnionAll[1:] {
+				if got := columnNames(viewColumns([]ViewColumn{alt})); got != want {
+					return fmt.Errorf("unionAll alternatives have different columns: %s and %s", want, got)
+				}
+			}
+		}
+		for _, nested := range [][]ViewColumn{c.Column, c.Select, c.UnionAll} {
+			if err := validateUnions(nested); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func columnNames(cols []ViewColumn) string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// constantPattern matches a reference to a ViewDefinition constant.
+var constantPattern = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_]*)`)
+
+// expandConstants replaces the references to the view's constants in a
+// FHIRPath expression with their values as literals.
+func (v *ViewDefinition) expandConstants(path string) string {
+	if len(v.Constants) == 0 || !strings.Contains(path, "%") {
+		return path
+	}
+	return constantPattern.ReplaceAllStringFunc(path, func(ref string) string {
+		for _, c := range v.Constants {
+			if c.Name == ref[1:] {
+				return fhirPathLiteral(c.Value)
+			}
+		}
+		return ref
+	})
+}
+
+// fhirPathLiteral returns a constant value as a FHIRPath literal: a number
+// or boolean as is, anything else as a string.
+func fhirPathLiteral(value string) string {
+	if value == "true" || value == "false" {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
 }
 
 // ViewWhere defines a filter expression. All where clauses are ANDed.
@@ -67,10 +209,9 @@ func NewViewDefinitionEngine(fhirpath *FHIRPathEngine) *ViewDefinitionEngine {
 // Execute evaluates a ViewDefinition against a set of FHIR resources.
 func (e *ViewDefinitionEngine) Execute(_ context.Context, view *ViewDefinition, resources []map[string]interface{}) (*ViewResult, error) {
 	result := &ViewResult{
-		Columns: make([]ViewColumn, len(view.Select)),
+		Columns: view.Columns(),
 		Rows:    make([][]interface{}, 0),
 	}
-	copy(result.Columns, view.Select)
 
 	if len(resources) == 0 {
 		return result, nil
@@ -84,7 +225,7 @@ func (e *ViewDefinitionEngine) Execute(_ context.Context, view *ViewDefinition,
 
 		include := true
 		for _, w := range view.Where {
-			match, err := e.fhirpath.EvaluateBool(resource, w.Path)
+			match, err := e.fhirpath.EvaluateBool(resource, view.expandConstants(w.Path))
 			if err != nil {
 				include = false
 				break
@@ -98,29 +239,103 @@ func (e *ViewDefinitionEngine) Execute(_ context.Context, view *ViewDefinition,
 			continue
 		}
 
-		row := make([]interface{}, len(view.Select))
-		for i, col := range view.Select {
-			val, err := e.fhirpath.Evaluate(resource, col.Path)
-			if err != nil {
-				row[i] = nil
-				continue
+		result.Rows = append(result.Rows, e.selectRows(view, resource, view.Select)...)
+	}
+
+	return result, nil
+}
+
+// selectRows returns the rows of a select list evaluated against node: the
+// cross join of the rows of its entries.
+func (e *ViewDefinitionEngine) selectRows(view *ViewDefinition, node interface{}, list []ViewColumn) [][]interface{} {
+	rows := [][]interface{}{{}}
+	for _, c := range list {
+		var entryRows [][]interface{}
+		if c.isSelect() {
+			entryRows = e.elementRows(view, node, c)
+		} else {
+			entryRows = [][]interface{}{{e.columnValue(view, node, c)}}
+		}
+		joined := make([][]interface{}, 0, len(rows)*len(entryRows))
+		for _, left := range rows {
+			for _, right := range entryRows {
+				row := make([]interface{}, 0, len(left)+len(right))
+				joined = append(joined, append(append(row, left...), right...))
 			}
-			if len(val) == 0 {
-				row[i] = nil
-			} else if col.Collection {
-				coerced := make([]interface{}, len(val))
-				for j, v := range val {
-					coerced[j] = coerceValue(v, col.Type)
+		}
+		rows = joined
+	}
+	return rows
+}
+
+// elementRows returns the rows of a select element evaluated against node.
+func (e *ViewDefinitionEngine) elementRows(view *ViewDefinition, node interface{}, el ViewColumn) [][]interface{} {
+	nodes := []interface{}{node}
+	if path := el.forEachPath(); path != "" {
+		nodes = e.evaluate(view, node, path)
+		if len(nodes) == 0 && el.ForEachOrNull != "" {
+			return [][]interface{}{make([]interface{}, len(viewColumns([]ViewColumn{el})))}
+		}
+	}
+
+	list := append(append([]ViewColumn(nil), el.Column...), el.Select...)
+	var rows [][]interface{}
+	for _, n := range nodes {
+		nodeRows := e.selectRows(view, n, list)
+		if len(el.UnionAll) > 0 {
+			var union [][]interface{}
+			for _, alt := range el.UnionAll {
+				union = append(union, e.selectRows(view, n, []ViewColumn{alt})...)
+			}
+			var joined [][]interface{}
+			for _, left := range nodeRows {
+				for _, right := range union {
+					row := make([]interface{}, 0, len(left)+len(right))
+					joined = append(joined, append(append(row, left...), right...))
 				}
-				row[i] = coerced
-			} else {
-				row[i] = coerceValue(val[0], col.Type)
 			}
+			nodeRows = joined
 		}
-		result.Rows = append(result.Rows, row)
+		rows = append(rows, nodeRows...)
 	}
+	return rows
+}
 
-	return result, nil
+// evaluate evaluates a FHIRPath expression against node, a resource or an
+// item of a forEach path. $this is the item itself; items that are not
+// elements have no other paths. getResourceKey() is the resource's id.
+func (e *ViewDefinitionEngine) evaluate(view *ViewDefinition, node interface{}, path string) []interface{} {
+	if path == "$this" {
+		return []interface{}{node}
+	}
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if path == "getResourceKey()" {
+		return []interface{}{m["id"]}
+	}
+	val, err := e.fhirpath.Evaluate(m, view.expandConstants(strings.TrimPrefix(path, "$this.")))
+	if err != nil {
+		return nil
+	}
+	return val
+}
+
+// columnValue evaluates a column against node.
+func (e *ViewDefinitionEngine) columnValue(view *ViewDefinition, node interface{}, col ViewColumn) interface{} {
+	val := e.evaluate(view, node, col.Path)
+	if len(val) == 0 {
+		return nil
+	}
+	if col.Collection {
+		coerced := make([]interface{}, len(val))
+		for j, v := range val {
+			coerced[j] = coerceValue(v, col.Type)
+		}
+		return coerced
+	}
+	return coerceValue(val[0], col.Type)
 }
 
 func coerceValue(val interface{}, typ string) interface{} {
@@ -243,7 +458,7 @@ func (e *ViewDefinitionEngine) ToCSV(result *ViewResult) string {
 
 func formatCSVValue(val interface{}) string {
 	switch v := val.(type) {
-	case []interface{}:
+	case []interface{}, map[string]interface{}:
 		b, err := json.Marshal(v)
 		if err != nil {
 			return fmt.Sprintf("%v", v)
@@ -287,8 +502,13 @@ func (e *ViewDefinitionEngine) ToNDJSON(result *ViewResult) string {
 	return buf.String()
 }
 
-// GenerateSQL generates a PostgreSQL CREATE VIEW statement.
+// GenerateSQL generates a PostgreSQL CREATE VIEW statement over the
+// resource store, as compiled by CompileViewSQL. Views that cannot be
+// compiled get a best-effort translation of their top-level columns.
 func (e *ViewDefinitionEngine) GenerateSQL(view *ViewDefinition) string {
+	if cv, err := CompileViewSQL(view); err == nil {
+		return fmt.Sprintf("CREATE OR REPLACE VIEW %s AS\n%s;\n", sanitizeSQLName(view.Name), cv.SQL())
+	}
 	var buf bytes.Buffer
 	buf.WriteString(fmt.Sprintf("CREATE OR REPLACE VIEW %s AS\nSELECT\n", sanitizeSQLName(view.Name)))
 	for i, col := range view.Select {
@@ -558,9 +778,11 @@ func builtinEncountersSummary() ViewDefinition {
 
 // ViewDefinitionHandler provides CRUD and execution endpoints.
 type ViewDefinitionHandler struct {
-	mu     sync.RWMutex
-	views  map[string]*ViewDefinition
-	engine *ViewDefinitionEngine
+	mu         sync.RWMutex
+	views      map[string]*ViewDefinition
+	engine     *ViewDefinitionEngine
+	store      ViewStore
+	dispatcher EntryDispatcher
 }
 
 // NewViewDefinitionHandler creates a new handler.
@@ -590,6 +812,18 @@ func (h *ViewDefinitionHandler) RegisterRoutes(fhirGroup *echo.Group) {
 	fhirGroup.DELETE("/ViewDefinition/:id", h.Delete)
 	fhirGroup.POST("/ViewDefinition/:id/$execute", h.ExecuteView)
 	fhirGroup.GET("/ViewDefinition/:id/$sql", h.SQLView)
+	fhirGroup.GET("/ViewDefinition/:id/$run", h.RunView)
+	fhirGroup.POST("/ViewDefinition/:id/$run", h.RunView)
+	fhirGroup.POST("/ViewDefinition/:id/$materialize", h.MaterializeView)
+	fhirGroup.GET("/ViewDefinition/:id/$materialize", h.GetMaterialization)
+	fhirGroup.DELETE("/ViewDefinition/:id/$materialize", h.DropMaterialization)
+	fhirGroup.POST("/ViewDefinition/:id/$refresh", h.RefreshView)
+}
+
+// RegisterResources registers the ViewDefinition read and search routes
+// with r.
+func (h *ViewDefinitionHandler) RegisterResources(r *ResourceRegistry) {
+	r.RegisterInteractions("ViewDefinition", "read", "search-type")
 }
 
 // List returns all registered view definitions.
@@ -621,11 +855,8 @@ func (h *ViewDefinitionHandler) Create(c echo.Context) error {
 	if err := c.Bind(&view); err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorOutcome("invalid request body: "+err.Error()))
 	}
-	if view.Resource == "" {
-		return c.JSON(http.StatusBadRequest, ErrorOutcome("resource field is required"))
-	}
-	if len(view.Select) == 0 {
-		return c.JSON(http.StatusBadRequest, ErrorOutcome("at least one column is required"))
+	if err := view.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorOutcome(err.Error()))
 	}
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -641,12 +872,19 @@ func (h *ViewDefinitionHandler) Update(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorOutcome("invalid request body: "+err.Error()))
 	}
 	view.ID = id
+	if err := view.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorOutcome(err.Error()))
+	}
 	h.mu.Lock()
-	defer h.mu.Unlock()
 	if _, ok := h.views[id]; !ok {
+		h.mu.Unlock()
 		return c.JSON(http.StatusNotFound, ErrorOutcome("ViewDefinition not found: "+id))
 	}
 	h.views[id] = &view
+	h.mu.Unlock()
+	if err := h.rematerialize(c, &view); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorOutcome("rematerialization failed: "+err.Error()))
+	}
 	return c.JSON(http.StatusOK, view)
 }
 
@@ -654,11 +892,17 @@ func (h *ViewDefinitionHandler) Update(c echo.Context) error {
 func (h *ViewDefinitionHandler) Delete(c echo.Context) error {
 	id := c.Param("id")
 	h.mu.Lock()
-	defer h.mu.Unlock()
 	if _, ok := h.views[id]; !ok {
+		h.mu.Unlock()
 		return c.JSON(http.StatusNotFound, ErrorOutcome("ViewDefinition not found: "+id))
 	}
 	delete(h.views, id)
+	h.mu.Unlock()
+	if h.store != nil {
+		if err := h.store.DropMaterialization(c.Request().Context(), id); err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorOutcome("drop materialization failed: "+err.Error()))
+		}
+	}
 	return c.NoContent(http.StatusNoContent)
 }
 
@@ -702,6 +946,16 @@ func (h *ViewDefinitionHandler) ExecuteView(c echo.Context) error {
 	case "ndjson":
 		ndjson := h.engine.ToNDJSON(result)
 		return c.Blob(http.StatusOK, "application/x-ndjson", []byte(ndjson))
+	case "parquet":
+		rows := newParquetRows(viewParquetColumns(result.Columns))
+		for _, row := range result.Rows {
+			rows.add(row...)
+		}
+		var buf bytes.Buffer
+		if err := rows.writeTo(&buf); err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorOutcome("parquet output failed: "+err.Error()))
+		}
+		return c.Blob(http.StatusOK, ParquetOutputFormat, buf.Bytes())
 	default:
 		return c.JSON(http.StatusOK, h.engine.ToJSON(result))
 	}
@@ -2,6 +2,7 @@ package fhir
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -11,17 +12,20 @@ import (
 // StatsParams holds parameters for the $stats operation.
 type StatsParams struct {
 	Patient   string   // Patient reference
-	Code      string   // Observation code (system|code)
+	Code      string   // Observation code (system|code); comma-separated for several codes
 	System    string   // Code system (optional)
 	Period    string   // Date range (FHIR date format)
-	Statistic []string // Requested statistics: count, min, max, mean, median, stddev, sum, 5-num
+	Statistic []string // Requested statistics: count, min, max, mean, median, stddev, sum, 20-percent, 80-percent
 }
 
-// StatsResult holds computed statistics.
+// StatsResult holds computed statistics. All values share one Unit;
+// observations recorded in units that cannot be converted to it are left
+// out and counted in Excluded.
 type StatsResult struct {
 	Code    string   `json:"code"`
 	Subject string   `json:"subject"`
 	Period  string   `json:"period,omitempty"`
+	Unit    string   `json:"unit,omitempty"`
 	Count   int      `json:"count"`
 	Min     *float64 `json:"min,omitempty"`
 	Max     *float64 `json:"max,omitempty"`
@@ -29,6 +33,17 @@ type StatsResult struct {
 	Median  *float64 `json:"median,omitempty"`
 	StdDev  *float64 `json:"stddev,omitempty"`
 	Sum     *float64 `json:"sum,omitempty"`
+	P20     *float64 `json:"p20,omitempty"`
+	P80     *float64 `json:"p80,omitempty"`
+
+	Excluded []StatsExcludedUnit `json:"excluded,omitempty"`
+}
+
+// StatsExcludedUnit counts the observations of a unit that is not
+// commensurable with the unit of a StatsResult.
+type StatsExcludedUnit struct {
+	Unit  string `json:"unit"`
+	Count int    `json:"count"`
 }
 
 // ParseStatsParams extracts $stats parameters from the request.
@@ -47,11 +62,15 @@ func ParseStatsParams(c echo.Context) StatsParams {
 	return params
 }
 
-// StatsExecutor is a function that executes the actual $stats computation.
+// StatsExecutor is a function that executes the actual $stats computation
+// for a single code.
 type StatsExecutor func(ctx context.Context, params StatsParams) (*StatsResult, error)
 
 // StatsHandler creates a handler for GET/POST /fhir/Observation/$stats.
-// It accepts a function that executes the actual computation.
+// It accepts a function that executes the actual computation. When several
+// codes are requested, the executor runs once per code and the statistics of
+// each code are returned as the parts of a "statistics" parameter. Only the
+// requested statistics are returned, or all of them if none is requested.
 func StatsHandler(executor StatsExecutor) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		params := ParseStatsParams(c)
@@ -68,17 +87,73 @@ func StatsHandler(executor StatsExecutor) echo.HandlerFunc {
 			))
 		}
 
-		result, err := executor(c.Request().Context(), params)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, NewOperationOutcome(
-				IssueSeverityError, IssueTypeProcessing, "stats operation failed: "+err.Error(),
-			))
+		codes := strings.Split(params.Code, ",")
+		results := make([]*StatsResult, 0, len(codes))
+		for _, code := range codes {
+			p := params
+			p.Code = strings.TrimSpace(code)
+			result, err := executor(c.Request().Context(), p)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, NewOperationOutcome(
+					IssueSeverityError, IssueTypeProcessing, "stats operation failed: "+err.Error(),
+				))
+			}
+			if result == nil {
+				result = &StatsResult{Code: p.Code, Subject: params.Patient, Period: params.Period}
+			}
+			results = append(results, filterStatistics(result, params.Statistic))
 		}
 
-		parameters := buildStatsParameters(result)
+		if len(results) == 1 {
+			return c.JSON(http.StatusOK, buildStatsParameters(results[0]))
+		}
+		parts := make([]interface{}, 0, len(results))
+		for _, result := range results {
+			parts = append(parts, map[string]interface{}{
+				"name": "statistics",
+				"part": buildStatsParameters(result)["parameter"],
+			})
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"resourceType": "Parameters",
+			"parameter":    parts,
+		})
+	}
+}
 
-		return c.JSON(http.StatusOK, parameters)
+// statisticAliases maps the statistic names accepted in the statistic
+// parameter, including the observation-statistics codes, to StatsResult fields.
+var statisticAliases = map[string]string{
+	"min": "min", "minimum": "min",
+	"max": "max", "maximum": "max",
+	"mean": "mean", "average": "mean",
+	"median": "median",
+	"stddev": "stddev", "std-dev": "stddev",
+	"sum": "sum",
+	"p20": "p20", "20-percent": "p20",
+	"p80": "p80", "80-percent": "p80",
+}
+
+// filterStatistics returns result with only the requested statistics set.
+// The count is always kept.
+func filterStatistics(result *StatsResult, statistics []string) *StatsResult {
+	if len(statistics) == 0 {
+		return result
+	}
+	want := make(map[string]bool)
+	for _, s := range statistics {
+		want[statisticAliases[strings.ToLower(strings.TrimSpace(s))]] = true
+	}
+	out := *result
+	for name, field := range map[string]**float64{
+		"min": &out.Min, "max": &out.Max, "mean": &out.Mean, "median": &out.Median,
+		"stddev": &out.StdDev, "sum": &out.Sum, "p20": &out.P20, "p80": &out.P80,
+	} {
+		if !want[name] {
+			*field = nil
+		}
 	}
+	return &out
 }
 
 // buildStatsParameters creates a FHIR Parameters resource from the stats result.
@@ -105,6 +180,13 @@ func buildStatsParameters(result *StatsResult) map[string]interface{} {
 		})
 	}
 
+	if result.Unit != "" {
+		params = append(params, map[string]interface{}{
+			"name":        "unit",
+			"valueString": result.Unit,
+		})
+	}
+
 	if result.Min != nil {
 		params = append(params, map[string]interface{}{
 			"name":         "min",
@@ -147,8 +229,41 @@ func buildStatsParameters(result *StatsResult) map[string]interface{} {
 		})
 	}
 
+	if result.P20 != nil {
+		params = append(params, map[string]interface{}{
+			"name":         "20-percent",
+			"valueDecimal": *result.P20,
+		})
+	}
+
+	if result.P80 != nil {
+		params = append(params, map[string]interface{}{
+			"name":         "80-percent",
+			"valueDecimal": *result.P80,
+		})
+	}
+
+	if len(result.Excluded) > 0 {
+		params = append(params, map[string]interface{}{
+			"name":     "outcome",
+			"resource": statsExcludedOutcome(result),
+		})
+	}
+
 	return map[string]interface{}{
 		"resourceType": "Parameters",
 		"parameter":    params,
 	}
 }
+
+// statsExcludedOutcome returns an OperationOutcome with a warning for each
+// unit whose observations were left out of the statistics.
+func statsExcludedOutcome(result *StatsResult) *OperationOutcome {
+	b := NewOutcomeBuilder()
+	for _, ex := range result.Excluded {
+		b.AddIssue(IssueSeverityWarning, IssueTypeProcessing, fmt.Sprintf(
+			"%d observation(s) of %s recorded in %q are not commensurable with %q and were excluded from the statistics",
+			ex.Count, result.Code, ex.Unit, result.Unit))
+	}
+	return b.Build()
+}
@@ -353,3 +353,133 @@ func TestStatsHandler_PartialStats(t *testing.T) {
 		}
 	}
 }
+
+func TestStatsHandler_FiltersRequestedStatistics(t *testing.T) {
+	result := &StatsResult{
+		Code:    "8480-6",
+		Subject: "Patient/123",
+		Unit:    "mm[Hg]",
+		Count:   10,
+		Min:     float64Ptr(100),
+		Max:     float64Ptr(140),
+		Mean:    float64Ptr(120),
+		P20:     float64Ptr(108),
+		P80:     float64Ptr(132),
+	}
+
+	handler := StatsHandler(mockStatsExecutor(result, nil))
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet,
+		"/fhir/Observation/$stats?patient=Patient/123&code=8480-6&statistic=maximum,20-percent,80-percent",
+		nil)
+	rec := httptest.NewRecorder()
+	if err := handler(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var params map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &params); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	got := make(map[string]interface{})
+	for _, p := range params["parameter"].([]interface{}) {
+		pm := p.(map[string]interface{})
+		got[pm["name"].(string)] = pm
+	}
+	for _, name := range []string{"code", "subject", "unit", "count", "max", "20-percent", "80-percent"} {
+		if got[name] == nil {
+			t.Errorf("expected parameter %q", name)
+		}
+	}
+	for _, name := range []string{"min", "mean"} {
+		if got[name] != nil {
+			t.Errorf("expected parameter %q to be omitted", name)
+		}
+	}
+	if len(got) != 7 {
+		t.Errorf("expected 7 parameters, got %d", len(got))
+	}
+}
+
+func TestStatsHandler_ExcludedUnitsWarning(t *testing.T) {
+	result := &StatsResult{
+		Code:     "2345-7",
+		Subject:  "Patient/123",
+		Unit:     "mg/dL",
+		Count:    4,
+		Mean:     float64Ptr(100),
+		Excluded: []StatsExcludedUnit{{Unit: "mmol/L", Count: 2}},
+	}
+
+	handler := StatsHandler(mockStatsExecutor(result, nil))
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/fhir/Observation/$stats?patient=Patient/123&code=2345-7", nil)
+	rec := httptest.NewRecorder()
+	if err := handler(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var params struct {
+		Parameter []struct {
+			Name     string            `json:"name"`
+			Resource *OperationOutcome `json:"resource"`
+		} `json:"parameter"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &params); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	var outcome *OperationOutcome
+	for _, p := range params.Parameter {
+		if p.Name == "outcome" {
+			outcome = p.Resource
+		}
+	}
+	if outcome == nil || len(outcome.Issue) != 1 {
+		t.Fatalf("expected an outcome with one issue, got %s", rec.Body.String())
+	}
+	issue := outcome.Issue[0]
+	if issue.Severity != IssueSeverityWarning || !strings.Contains(issue.Diagnostics, "2 observation(s)") ||
+		!strings.Contains(issue.Diagnostics, "mmol/L") {
+		t.Errorf("issue = %+v", issue)
+	}
+}
+
+func TestStatsHandler_MultipleCodes(t *testing.T) {
+	var calls []string
+	executor := func(_ context.Context, p StatsParams) (*StatsResult, error) {
+		calls = append(calls, p.Code)
+		return &StatsResult{Code: p.Code, Subject: p.Patient, Count: len(calls)}, nil
+	}
+
+	handler := StatsHandler(executor)
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet,
+		"/fhir/Observation/$stats?patient=Patient/123&code=8480-6,8462-4",
+		nil)
+	rec := httptest.NewRecorder()
+	if err := handler(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(calls) != 2 || calls[0] != "8480-6" || calls[1] != "8462-4" {
+		t.Fatalf("executor calls = %v", calls)
+	}
+	var params map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &params); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	paramList := params["parameter"].([]interface{})
+	if len(paramList) != 2 {
+		t.Fatalf("expected 2 statistics parameters, got %d", len(paramList))
+	}
+	for i, p := range paramList {
+		pm := p.(map[string]interface{})
+		if pm["name"] != "statistics" {
+			t.Errorf("parameter %d name = %v, want statistics", i, pm["name"])
+		}
+		code := pm["part"].([]interface{})[0].(map[string]interface{})
+		if code["valueString"] != calls[i] {
+			t.Errorf("parameter %d code = %v, want %s", i, code["valueString"], calls[i])
+		}
+	}
+}
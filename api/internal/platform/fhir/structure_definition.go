@@ -44,15 +44,16 @@ type StructureDifferential struct {
 
 // ElementDefinition describes a single element within a StructureDefinition.
 type ElementDefinition struct {
-	ID          string          `json:"id,omitempty"`
-	Path        string          `json:"path"`
-	Short       string          `json:"short,omitempty"`
-	Definition  string          `json:"definition,omitempty"`
-	Min         *int            `json:"min,omitempty"`
-	Max         string          `json:"max,omitempty"`
-	Type        []ElementType   `json:"type,omitempty"`
-	Binding     *ElementBinding `json:"binding,omitempty"`
-	MustSupport bool            `json:"mustSupport,omitempty"`
+	ID               string          `json:"id,omitempty"`
+	Path             string          `json:"path"`
+	Short            string          `json:"short,omitempty"`
+	Definition       string          `json:"definition,omitempty"`
+	Min              *int            `json:"min,omitempty"`
+	Max              string          `json:"max,omitempty"`
+	Type             []ElementType   `json:"type,omitempty"`
+	ContentReference string          `json:"contentReference,omitempty"`
+	Binding          *ElementBinding `json:"binding,omitempty"`
+	MustSupport      bool            `json:"mustSupport,omitempty"`
 }
 
 // ElementType describes a datatype for an element.
@@ -149,7 +150,8 @@ func intPtr(v int) *int {
 }
 
 // RegisterBaseDefinitions populates the store with base FHIR R4 StructureDefinitions
-// for the 20 most commonly used resource types.
+// for the 20 most commonly used resource types, plus the datatype and
+// infrastructure layouts from RegisterStructuralDefinitions.
 func RegisterBaseDefinitions(store *StructureDefinitionStore) {
 	baseURL := "http://hl7.org/fhir/StructureDefinition/"
 
@@ -471,6 +473,8 @@ func RegisterBaseDefinitions(store *StructureDefinitionStore) {
 		Description: "A provider issued list of professional services for reimbursement.",
 		Snapshot:    &StructureSnapshot{Element: claimElements},
 	})
+
+	RegisterStructuralDefinitions(store)
 }
 
 // ============================================================================
@@ -571,6 +575,12 @@ func (h *StructureDefinitionHandler) RegisterRoutes(fhirGroup *echo.Group) {
 	fhirGroup.GET("/StructureDefinition/$snapshot", h.GenerateSnapshotOp)
 }
 
+// RegisterResources registers the StructureDefinition read and search
+// routes with r.
+func (h *StructureDefinitionHandler) RegisterResources(r *ResourceRegistry) {
+	r.RegisterInteractions("StructureDefinition", "read", "search-type")
+}
+
 // SearchStructureDefinitions handles GET /StructureDefinition with optional query filters.
 func (h *StructureDefinitionHandler) SearchStructureDefinitions(c echo.Context) error {
 	params := make(map[string]string)
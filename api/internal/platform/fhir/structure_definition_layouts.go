@@ -0,0 +1,4657 @@
+package fhir
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resourceLayoutTable lists the elements of every FHIR R4 resource type
+// except the infrastructure resources in infrastructureLayouts, transcribed
+// from the R4 (4.0.1) StructureDefinitions. Each type name is followed by its
+// elements in canonical order, one per indented line: the relative path, the
+// max cardinality and the types, in the notation of the layout rows (see
+// structure_definition_types.go).
+const resourceLayoutTable = `
+Account
+	identifier * Identifier
+	status 1 code
+	type 1 CodeableConcept
+	name 1 string
+	subject * Reference
+	servicePeriod 1 Period
+	coverage * BackboneElement
+	coverage.coverage 1 Reference
+	coverage.priority 1 positiveInt
+	owner 1 Reference
+	description 1 string
+	guarantor * BackboneElement
+	guarantor.party 1 Reference
+	guarantor.onHold 1 boolean
+	guarantor.period 1 Period
+	partOf 1 Reference
+
+ActivityDefinition
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	subtitle 1 string
+	status 1 code
+	experimental 1 boolean
+	subject[x] 1 CodeableConcept|Reference
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	usage 1 string
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	topic * CodeableConcept
+	author * ContactDetail
+	editor * ContactDetail
+	reviewer * ContactDetail
+	endorser * ContactDetail
+	relatedArtifact * RelatedArtifact
+	library * canonical
+	kind 1 code
+	profile 1 canonical
+	code 1 CodeableConcept
+	intent 1 code
+	priority 1 code
+	doNotPerform 1 boolean
+	timing[x] 1 Timing|dateTime|Age|Period|Range|Duration
+	location 1 Reference
+	participant * BackboneElement
+	participant.type 1 code
+	participant.role 1 CodeableConcept
+	product[x] 1 Reference|CodeableConcept
+	quantity 1 Quantity
+	dosage * Dosage
+	bodySite * CodeableConcept
+	specimenRequirement * Reference
+	observationRequirement * Reference
+	observationResultRequirement * Reference
+	transform 1 canonical
+	dynamicValue * BackboneElement
+	dynamicValue.path 1 string
+	dynamicValue.expression 1 Expression
+
+AdverseEvent
+	identifier 1 Identifier
+	actuality 1 code
+	category * CodeableConcept
+	event 1 CodeableConcept
+	subject 1 Reference
+	encounter 1 Reference
+	date 1 dateTime
+	detected 1 dateTime
+	recordedDate 1 dateTime
+	resultingCondition * Reference
+	location 1 Reference
+	seriousness 1 CodeableConcept
+	severity 1 CodeableConcept
+	outcome 1 CodeableConcept
+	recorder 1 Reference
+	contributor * Reference
+	suspectEntity * BackboneElement
+	suspectEntity.instance 1 Reference
+	suspectEntity.causality * BackboneElement
+	suspectEntity.causality.assessment 1 CodeableConcept
+	suspectEntity.causality.productRelatedness 1 string
+	suspectEntity.causality.author 1 Reference
+	suspectEntity.causality.method 1 CodeableConcept
+	subjectMedicalHistory * Reference
+	referenceDocument * Reference
+	study * Reference
+
+AllergyIntolerance
+	identifier * Identifier
+	clinicalStatus 1 CodeableConcept
+	verificationStatus 1 CodeableConcept
+	type 1 code
+	category * code
+	criticality 1 code
+	code 1 CodeableConcept
+	patient 1 Reference
+	encounter 1 Reference
+	onset[x] 1 dateTime|Age|Period|Range|string
+	recordedDate 1 dateTime
+	recorder 1 Reference
+	asserter 1 Reference
+	lastOccurrence 1 dateTime
+	note * Annotation
+	reaction * BackboneElement
+	reaction.substance 1 CodeableConcept
+	reaction.manifestation * CodeableConcept
+	reaction.description 1 string
+	reaction.onset 1 dateTime
+	reaction.severity 1 code
+	reaction.exposureRoute 1 CodeableConcept
+	reaction.note * Annotation
+
+Appointment
+	identifier * Identifier
+	status 1 code
+	cancelationReason 1 CodeableConcept
+	serviceCategory * CodeableConcept
+	serviceType * CodeableConcept
+	specialty * CodeableConcept
+	appointmentType 1 CodeableConcept
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	priority 1 unsignedInt
+	description 1 string
+	supportingInformation * Reference
+	start 1 instant
+	end 1 instant
+	minutesDuration 1 positiveInt
+	slot * Reference
+	created 1 dateTime
+	comment 1 string
+	patientInstruction 1 string
+	basedOn * Reference
+	participant * BackboneElement
+	participant.type * CodeableConcept
+	participant.actor 1 Reference
+	participant.required 1 code
+	participant.status 1 code
+	participant.period 1 Period
+	requestedPeriod * Period
+
+AppointmentResponse
+	identifier * Identifier
+	appointment 1 Reference
+	start 1 instant
+	end 1 instant
+	participantType * CodeableConcept
+	actor 1 Reference
+	participantStatus 1 code
+	comment 1 string
+
+AuditEvent
+	type 1 Coding
+	subtype * Coding
+	action 1 code
+	period 1 Period
+	recorded 1 instant
+	outcome 1 code
+	outcomeDesc 1 string
+	purposeOfEvent * CodeableConcept
+	agent * BackboneElement
+	agent.type 1 CodeableConcept
+	agent.role * CodeableConcept
+	agent.who 1 Reference
+	agent.altId 1 string
+	agent.name 1 string
+	agent.requestor 1 boolean
+	agent.location 1 Reference
+	agent.policy * uri
+	agent.media 1 Coding
+	agent.network 1 BackboneElement
+	agent.network.address 1 string
+	agent.network.type 1 code
+	agent.purposeOfUse * CodeableConcept
+	source 1 BackboneElement
+	source.site 1 string
+	source.observer 1 Reference
+	source.type * Coding
+	entity * BackboneElement
+	entity.what 1 Reference
+	entity.type 1 Coding
+	entity.role 1 Coding
+	entity.lifecycle 1 Coding
+	entity.securityLabel * Coding
+	entity.name 1 string
+	entity.description 1 string
+	entity.query 1 base64Binary
+	entity.detail * BackboneElement
+	entity.detail.type 1 string
+	entity.detail.value[x] 1 string|base64Binary
+
+Basic
+	identifier * Identifier
+	code 1 CodeableConcept
+	subject 1 Reference
+	created 1 date
+	author 1 Reference
+
+BiologicallyDerivedProduct
+	identifier * Identifier
+	productCategory 1 code
+	productCode 1 CodeableConcept
+	status 1 code
+	request * Reference
+	quantity 1 integer
+	parent * Reference
+	collection 1 BackboneElement
+	collection.collector 1 Reference
+	collection.source 1 Reference
+	collection.collected[x] 1 dateTime|Period
+	processing * BackboneElement
+	processing.description 1 string
+	processing.procedure 1 CodeableConcept
+	processing.additive 1 Reference
+	processing.time[x] 1 dateTime|Period
+	manipulation 1 BackboneElement
+	manipulation.description 1 string
+	manipulation.time[x] 1 dateTime|Period
+	storage * BackboneElement
+	storage.description 1 string
+	storage.temperature 1 decimal
+	storage.scale 1 code
+	storage.duration 1 Period
+
+BodyStructure
+	identifier * Identifier
+	active 1 boolean
+	morphology 1 CodeableConcept
+	location 1 CodeableConcept
+	locationQualifier * CodeableConcept
+	description 1 string
+	image * Attachment
+	patient 1 Reference
+
+CarePlan
+	identifier * Identifier
+	instantiatesCanonical * canonical
+	instantiatesUri * uri
+	basedOn * Reference
+	replaces * Reference
+	partOf * Reference
+	status 1 code
+	intent 1 code
+	category * CodeableConcept
+	title 1 string
+	description 1 string
+	subject 1 Reference
+	encounter 1 Reference
+	period 1 Period
+	created 1 dateTime
+	author 1 Reference
+	contributor * Reference
+	careTeam * Reference
+	addresses * Reference
+	supportingInfo * Reference
+	goal * Reference
+	activity * BackboneElement
+	activity.outcomeCodeableConcept * CodeableConcept
+	activity.outcomeReference * Reference
+	activity.progress * Annotation
+	activity.reference 1 Reference
+	activity.detail 1 BackboneElement
+	activity.detail.kind 1 code
+	activity.detail.instantiatesCanonical * canonical
+	activity.detail.instantiatesUri * uri
+	activity.detail.code 1 CodeableConcept
+	activity.detail.reasonCode * CodeableConcept
+	activity.detail.reasonReference * Reference
+	activity.detail.goal * Reference
+	activity.detail.status 1 code
+	activity.detail.statusReason 1 CodeableConcept
+	activity.detail.doNotPerform 1 boolean
+	activity.detail.scheduled[x] 1 Timing|Period|string
+	activity.detail.location 1 Reference
+	activity.detail.performer * Reference
+	activity.detail.product[x] 1 CodeableConcept|Reference
+	activity.detail.dailyAmount 1 Quantity
+	activity.detail.quantity 1 Quantity
+	activity.detail.description 1 string
+	note * Annotation
+
+CareTeam
+	identifier * Identifier
+	status 1 code
+	category * CodeableConcept
+	name 1 string
+	subject 1 Reference
+	encounter 1 Reference
+	period 1 Period
+	participant * BackboneElement
+	participant.role * CodeableConcept
+	participant.member 1 Reference
+	participant.onBehalfOf 1 Reference
+	participant.period 1 Period
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	managingOrganization * Reference
+	telecom * ContactPoint
+	note * Annotation
+
+CatalogEntry
+	identifier * Identifier
+	type 1 CodeableConcept
+	orderable 1 boolean
+	referencedItem 1 Reference
+	additionalIdentifier * Identifier
+	classification * CodeableConcept
+	status 1 code
+	validityPeriod 1 Period
+	validTo 1 dateTime
+	lastUpdated 1 dateTime
+	additionalCharacteristic * CodeableConcept
+	additionalClassification * CodeableConcept
+	relatedEntry * BackboneElement
+	relatedEntry.relationtype 1 code
+	relatedEntry.item 1 Reference
+
+ChargeItem
+	identifier * Identifier
+	definitionUri * uri
+	definitionCanonical * canonical
+	status 1 code
+	partOf * Reference
+	code 1 CodeableConcept
+	subject 1 Reference
+	context 1 Reference
+	occurrence[x] 1 dateTime|Period|Timing
+	performer * BackboneElement
+	performer.function 1 CodeableConcept
+	performer.actor 1 Reference
+	performingOrganization 1 Reference
+	requestingOrganization 1 Reference
+	costCenter 1 Reference
+	quantity 1 Quantity
+	bodysite * CodeableConcept
+	factorOverride 1 decimal
+	priceOverride 1 Money
+	overrideReason 1 string
+	enterer 1 Reference
+	enteredDate 1 dateTime
+	reason * CodeableConcept
+	service * Reference
+	product[x] 1 Reference|CodeableConcept
+	account * Reference
+	note * Annotation
+	supportingInformation * Reference
+
+ChargeItemDefinition
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	title 1 string
+	derivedFromUri * uri
+	partOf * canonical
+	replaces * canonical
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	code 1 CodeableConcept
+	instance * Reference
+	applicability * BackboneElement
+	applicability.description 1 string
+	applicability.language 1 string
+	applicability.expression 1 string
+	propertyGroup * BackboneElement
+	propertyGroup.applicability * #ChargeItemDefinition.applicability
+	propertyGroup.priceComponent * BackboneElement
+	propertyGroup.priceComponent.type 1 code
+	propertyGroup.priceComponent.code 1 CodeableConcept
+	propertyGroup.priceComponent.factor 1 decimal
+	propertyGroup.priceComponent.amount 1 Money
+
+Claim
+	identifier * Identifier
+	status 1 code
+	type 1 CodeableConcept
+	subType 1 CodeableConcept
+	use 1 code
+	patient 1 Reference
+	billablePeriod 1 Period
+	created 1 dateTime
+	enterer 1 Reference
+	insurer 1 Reference
+	provider 1 Reference
+	priority 1 CodeableConcept
+	fundsReserve 1 CodeableConcept
+	related * BackboneElement
+	related.claim 1 Reference
+	related.relationship 1 CodeableConcept
+	related.reference 1 Identifier
+	prescription 1 Reference
+	originalPrescription 1 Reference
+	payee 1 BackboneElement
+	payee.type 1 CodeableConcept
+	payee.party 1 Reference
+	referral 1 Reference
+	facility 1 Reference
+	careTeam * BackboneElement
+	careTeam.sequence 1 positiveInt
+	careTeam.provider 1 Reference
+	careTeam.responsible 1 boolean
+	careTeam.role 1 CodeableConcept
+	careTeam.qualification 1 CodeableConcept
+	supportingInfo * BackboneElement
+	supportingInfo.sequence 1 positiveInt
+	supportingInfo.category 1 CodeableConcept
+	supportingInfo.code 1 CodeableConcept
+	supportingInfo.timing[x] 1 date|Period
+	supportingInfo.value[x] 1 boolean|string|Quantity|Attachment|Reference
+	supportingInfo.reason 1 CodeableConcept
+	diagnosis * BackboneElement
+	diagnosis.sequence 1 positiveInt
+	diagnosis.diagnosis[x] 1 CodeableConcept|Reference
+	diagnosis.type * CodeableConcept
+	diagnosis.onAdmission 1 CodeableConcept
+	diagnosis.packageCode 1 CodeableConcept
+	procedure * BackboneElement
+	procedure.sequence 1 positiveInt
+	procedure.type * CodeableConcept
+	procedure.date 1 dateTime
+	procedure.procedure[x] 1 CodeableConcept|Reference
+	procedure.udi * Reference
+	insurance * BackboneElement
+	insurance.sequence 1 positiveInt
+	insurance.focal 1 boolean
+	insurance.identifier 1 Identifier
+	insurance.coverage 1 Reference
+	insurance.businessArrangement 1 string
+	insurance.preAuthRef * string
+	insurance.claimResponse 1 Reference
+	accident 1 BackboneElement
+	accident.date 1 date
+	accident.type 1 CodeableConcept
+	accident.location[x] 1 Address|Reference
+	item * BackboneElement
+	item.sequence 1 positiveInt
+	item.careTeamSequence * positiveInt
+	item.diagnosisSequence * positiveInt
+	item.procedureSequence * positiveInt
+	item.informationSequence * positiveInt
+	item.revenue 1 CodeableConcept
+	item.category 1 CodeableConcept
+	item.productOrService 1 CodeableConcept
+	item.modifier * CodeableConcept
+	item.programCode * CodeableConcept
+	item.serviced[x] 1 date|Period
+	item.location[x] 1 CodeableConcept|Address|Reference
+	item.quantity 1 Quantity
+	item.unitPrice 1 Money
+	item.factor 1 decimal
+	item.net 1 Money
+	item.udi * Reference
+	item.bodySite 1 CodeableConcept
+	item.subSite * CodeableConcept
+	item.encounter * Reference
+	item.detail * BackboneElement
+	item.detail.sequence 1 positiveInt
+	item.detail.revenue 1 CodeableConcept
+	item.detail.category 1 CodeableConcept
+	item.detail.productOrService 1 CodeableConcept
+	item.detail.modifier * CodeableConcept
+	item.detail.programCode * CodeableConcept
+	item.detail.quantity 1 Quantity
+	item.detail.unitPrice 1 Money
+	item.detail.factor 1 decimal
+	item.detail.net 1 Money
+	item.detail.udi * Reference
+	item.detail.subDetail * BackboneElement
+	item.detail.subDetail.sequence 1 positiveInt
+	item.detail.subDetail.revenue 1 CodeableConcept
+	item.detail.subDetail.category 1 CodeableConcept
+	item.detail.subDetail.productOrService 1 CodeableConcept
+	item.detail.subDetail.modifier * CodeableConcept
+	item.detail.subDetail.programCode * CodeableConcept
+	item.detail.subDetail.quantity 1 Quantity
+	item.detail.subDetail.unitPrice 1 Money
+	item.detail.subDetail.factor 1 decimal
+	item.detail.subDetail.net 1 Money
+	item.detail.subDetail.udi * Reference
+	total 1 Money
+
+ClaimResponse
+	identifier * Identifier
+	status 1 code
+	type 1 CodeableConcept
+	subType 1 CodeableConcept
+	use 1 code
+	patient 1 Reference
+	created 1 dateTime
+	insurer 1 Reference
+	requestor 1 Reference
+	request 1 Reference
+	outcome 1 code
+	disposition 1 string
+	preAuthRef 1 string
+	preAuthPeriod 1 Period
+	payeeType 1 CodeableConcept
+	item * BackboneElement
+	item.itemSequence 1 positiveInt
+	item.noteNumber * positiveInt
+	item.adjudication * BackboneElement
+	item.adjudication.category 1 CodeableConcept
+	item.adjudication.reason 1 CodeableConcept
+	item.adjudication.amount 1 Money
+	item.adjudication.value 1 decimal
+	item.detail * BackboneElement
+	item.detail.detailSequence 1 positiveInt
+	item.detail.noteNumber * positiveInt
+	item.detail.adjudication * #ClaimResponse.item.adjudication
+	item.detail.subDetail * BackboneElement
+	item.detail.subDetail.subDetailSequence 1 positiveInt
+	item.detail.subDetail.noteNumber * positiveInt
+	item.detail.subDetail.adjudication * #ClaimResponse.item.adjudication
+	addItem * BackboneElement
+	addItem.itemSequence * positiveInt
+	addItem.detailSequence * positiveInt
+	addItem.subdetailSequence * positiveInt
+	addItem.provider * Reference
+	addItem.productOrService 1 CodeableConcept
+	addItem.modifier * CodeableConcept
+	addItem.programCode * CodeableConcept
+	addItem.serviced[x] 1 date|Period
+	addItem.location[x] 1 CodeableConcept|Address|Reference
+	addItem.quantity 1 Quantity
+	addItem.unitPrice 1 Money
+	addItem.factor 1 decimal
+	addItem.net 1 Money
+	addItem.bodySite 1 CodeableConcept
+	addItem.subSite * CodeableConcept
+	addItem.noteNumber * positiveInt
+	addItem.adjudication * #ClaimResponse.item.adjudication
+	addItem.detail * BackboneElement
+	addItem.detail.productOrService 1 CodeableConcept
+	addItem.detail.modifier * CodeableConcept
+	addItem.detail.quantity 1 Quantity
+	addItem.detail.unitPrice 1 Money
+	addItem.detail.factor 1 decimal
+	addItem.detail.net 1 Money
+	addItem.detail.noteNumber * positiveInt
+	addItem.detail.adjudication * #ClaimResponse.item.adjudication
+	addItem.detail.subDetail * BackboneElement
+	addItem.detail.subDetail.productOrService 1 CodeableConcept
+	addItem.detail.subDetail.modifier * CodeableConcept
+	addItem.detail.subDetail.quantity 1 Quantity
+	addItem.detail.subDetail.unitPrice 1 Money
+	addItem.detail.subDetail.factor 1 decimal
+	addItem.detail.subDetail.net 1 Money
+	addItem.detail.subDetail.noteNumber * positiveInt
+	addItem.detail.subDetail.adjudication * #ClaimResponse.item.adjudication
+	adjudication * #ClaimResponse.item.adjudication
+	total * BackboneElement
+	total.category 1 CodeableConcept
+	total.amount 1 Money
+	payment 1 BackboneElement
+	payment.type 1 CodeableConcept
+	payment.adjustment 1 Money
+	payment.adjustmentReason 1 CodeableConcept
+	payment.date 1 date
+	payment.amount 1 Money
+	payment.identifier 1 Identifier
+	fundsReserve 1 CodeableConcept
+	formCode 1 CodeableConcept
+	form 1 Attachment
+	processNote * BackboneElement
+	processNote.number 1 positiveInt
+	processNote.type 1 code
+	processNote.text 1 string
+	processNote.language 1 CodeableConcept
+	communicationRequest * Reference
+	insurance * BackboneElement
+	insurance.sequence 1 positiveInt
+	insurance.focal 1 boolean
+	insurance.coverage 1 Reference
+	insurance.businessArrangement 1 string
+	insurance.claimResponse 1 Reference
+	error * BackboneElement
+	error.itemSequence 1 positiveInt
+	error.detailSequence 1 positiveInt
+	error.subDetailSequence 1 positiveInt
+	error.code 1 CodeableConcept
+
+ClinicalImpression
+	identifier * Identifier
+	status 1 code
+	statusReason 1 CodeableConcept
+	code 1 CodeableConcept
+	description 1 string
+	subject 1 Reference
+	encounter 1 Reference
+	effective[x] 1 dateTime|Period
+	date 1 dateTime
+	assessor 1 Reference
+	previous 1 Reference
+	problem * Reference
+	investigation * BackboneElement
+	investigation.code 1 CodeableConcept
+	investigation.item * Reference
+	protocol * uri
+	summary 1 string
+	finding * BackboneElement
+	finding.itemCodeableConcept 1 CodeableConcept
+	finding.itemReference 1 Reference
+	finding.basis 1 string
+	prognosisCodeableConcept * CodeableConcept
+	prognosisReference * Reference
+	supportingInfo * Reference
+	note * Annotation
+
+CodeSystem
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	copyright 1 markdown
+	caseSensitive 1 boolean
+	valueSet 1 canonical
+	hierarchyMeaning 1 code
+	compositional 1 boolean
+	versionNeeded 1 boolean
+	content 1 code
+	supplements 1 canonical
+	count 1 unsignedInt
+	filter * BackboneElement
+	filter.code 1 code
+	filter.description 1 string
+	filter.operator * code
+	filter.value 1 string
+	property * BackboneElement
+	property.code 1 code
+	property.uri 1 uri
+	property.description 1 string
+	property.type 1 code
+	concept * BackboneElement
+	concept.code 1 code
+	concept.display 1 string
+	concept.definition 1 string
+	concept.designation * BackboneElement
+	concept.designation.language 1 code
+	concept.designation.use 1 Coding
+	concept.designation.value 1 string
+	concept.property * BackboneElement
+	concept.property.code 1 code
+	concept.property.value[x] 1 code|Coding|string|integer|boolean|dateTime|decimal
+	concept.concept * #CodeSystem.concept
+
+Communication
+	identifier * Identifier
+	instantiatesCanonical * canonical
+	instantiatesUri * uri
+	basedOn * Reference
+	partOf * Reference
+	inResponseTo * Reference
+	status 1 code
+	statusReason 1 CodeableConcept
+	category * CodeableConcept
+	priority 1 code
+	medium * CodeableConcept
+	subject 1 Reference
+	topic 1 CodeableConcept
+	about * Reference
+	encounter 1 Reference
+	sent 1 dateTime
+	received 1 dateTime
+	recipient * Reference
+	sender 1 Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	payload * BackboneElement
+	payload.content[x] 1 string|Attachment|Reference
+	note * Annotation
+
+CommunicationRequest
+	identifier * Identifier
+	basedOn * Reference
+	replaces * Reference
+	groupIdentifier 1 Identifier
+	status 1 code
+	statusReason 1 CodeableConcept
+	category * CodeableConcept
+	priority 1 code
+	doNotPerform 1 boolean
+	medium * CodeableConcept
+	subject 1 Reference
+	about * Reference
+	encounter 1 Reference
+	payload * BackboneElement
+	payload.content[x] 1 string|Attachment|Reference
+	occurrence[x] 1 dateTime|Period
+	authoredOn 1 dateTime
+	requester 1 Reference
+	recipient * Reference
+	sender 1 Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	note * Annotation
+
+CompartmentDefinition
+	url 1 uri
+	version 1 string
+	name 1 string
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	purpose 1 markdown
+	code 1 code
+	search 1 boolean
+	resource * BackboneElement
+	resource.code 1 code
+	resource.param * string
+	resource.documentation 1 string
+
+Composition
+	identifier 1 Identifier
+	status 1 code
+	type 1 CodeableConcept
+	category * CodeableConcept
+	subject 1 Reference
+	encounter 1 Reference
+	date 1 dateTime
+	author * Reference
+	title 1 string
+	confidentiality 1 code
+	attester * BackboneElement
+	attester.mode 1 code
+	attester.time 1 dateTime
+	attester.party 1 Reference
+	custodian 1 Reference
+	relatesTo * BackboneElement
+	relatesTo.code 1 code
+	relatesTo.target[x] 1 Identifier|Reference
+	event * BackboneElement
+	event.code * CodeableConcept
+	event.period 1 Period
+	event.detail * Reference
+	section * BackboneElement
+	section.title 1 string
+	section.code 1 CodeableConcept
+	section.author * Reference
+	section.focus 1 Reference
+	section.text 1 Narrative
+	section.mode 1 code
+	section.orderedBy 1 CodeableConcept
+	section.entry * Reference
+	section.emptyReason 1 CodeableConcept
+	section.section * #Composition.section
+
+ConceptMap
+	url 1 uri
+	identifier 1 Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	copyright 1 markdown
+	source[x] 1 uri|canonical
+	target[x] 1 uri|canonical
+	group * BackboneElement
+	group.source 1 uri
+	group.sourceVersion 1 string
+	group.target 1 uri
+	group.targetVersion 1 string
+	group.element * BackboneElement
+	group.element.code 1 code
+	group.element.display 1 string
+	group.element.target * BackboneElement
+	group.element.target.code 1 code
+	group.element.target.display 1 string
+	group.element.target.equivalence 1 code
+	group.element.target.comment 1 string
+	group.element.target.dependsOn * BackboneElement
+	group.element.target.dependsOn.property 1 uri
+	group.element.target.dependsOn.system 1 canonical
+	group.element.target.dependsOn.value 1 string
+	group.element.target.dependsOn.display 1 string
+	group.element.target.product * #ConceptMap.group.element.target.dependsOn
+	group.unmapped 1 BackboneElement
+	group.unmapped.mode 1 code
+	group.unmapped.code 1 code
+	group.unmapped.display 1 string
+	group.unmapped.url 1 canonical
+
+Condition
+	identifier * Identifier
+	clinicalStatus 1 CodeableConcept
+	verificationStatus 1 CodeableConcept
+	category * CodeableConcept
+	severity 1 CodeableConcept
+	code 1 CodeableConcept
+	bodySite * CodeableConcept
+	subject 1 Reference
+	encounter 1 Reference
+	onset[x] 1 dateTime|Age|Period|Range|string
+	abatement[x] 1 dateTime|Age|Period|Range|string
+	recordedDate 1 dateTime
+	recorder 1 Reference
+	asserter 1 Reference
+	stage * BackboneElement
+	stage.summary 1 CodeableConcept
+	stage.assessment * Reference
+	stage.type 1 CodeableConcept
+	evidence * BackboneElement
+	evidence.code * CodeableConcept
+	evidence.detail * Reference
+	note * Annotation
+
+Consent
+	identifier * Identifier
+	status 1 code
+	scope 1 CodeableConcept
+	category * CodeableConcept
+	patient 1 Reference
+	dateTime 1 dateTime
+	performer * Reference
+	organization * Reference
+	source[x] 1 Attachment|Reference
+	policy * BackboneElement
+	policy.authority 1 uri
+	policy.uri 1 uri
+	policyRule 1 CodeableConcept
+	verification * BackboneElement
+	verification.verified 1 boolean
+	verification.verifiedWith 1 Reference
+	verification.verificationDate 1 dateTime
+	provision 1 BackboneElement
+	provision.type 1 code
+	provision.period 1 Period
+	provision.actor * BackboneElement
+	provision.actor.role 1 CodeableConcept
+	provision.actor.reference 1 Reference
+	provision.action * CodeableConcept
+	provision.securityLabel * Coding
+	provision.purpose * Coding
+	provision.class * Coding
+	provision.code * CodeableConcept
+	provision.dataPeriod 1 Period
+	provision.data * BackboneElement
+	provision.data.meaning 1 code
+	provision.data.reference 1 Reference
+	provision.provision * #Consent.provision
+
+Contract
+	identifier * Identifier
+	url 1 uri
+	version 1 string
+	status 1 code
+	legalState 1 CodeableConcept
+	instantiatesCanonical 1 Reference
+	instantiatesUri 1 uri
+	contentDerivative 1 CodeableConcept
+	issued 1 dateTime
+	applies 1 Period
+	expirationType 1 CodeableConcept
+	subject * Reference
+	authority * Reference
+	domain * Reference
+	site * Reference
+	name 1 string
+	title 1 string
+	subtitle 1 string
+	alias * string
+	author 1 Reference
+	scope 1 CodeableConcept
+	topic[x] 1 CodeableConcept|Reference
+	type 1 CodeableConcept
+	subType * CodeableConcept
+	contentDefinition 1 BackboneElement
+	contentDefinition.type 1 CodeableConcept
+	contentDefinition.subType 1 CodeableConcept
+	contentDefinition.publisher 1 Reference
+	contentDefinition.publicationDate 1 dateTime
+	contentDefinition.publicationStatus 1 code
+	contentDefinition.copyright 1 markdown
+	term * BackboneElement
+	term.identifier 1 Identifier
+	term.issued 1 dateTime
+	term.applies 1 Period
+	term.topic[x] 1 CodeableConcept|Reference
+	term.type 1 CodeableConcept
+	term.subType 1 CodeableConcept
+	term.text 1 string
+	term.securityLabel * BackboneElement
+	term.securityLabel.number * unsignedInt
+	term.securityLabel.classification 1 Coding
+	term.securityLabel.category * Coding
+	term.securityLabel.control * Coding
+	term.offer 1 BackboneElement
+	term.offer.identifier * Identifier
+	term.offer.party * BackboneElement
+	term.offer.party.reference * Reference
+	term.offer.party.role 1 CodeableConcept
+	term.offer.topic 1 Reference
+	term.offer.type 1 CodeableConcept
+	term.offer.decision 1 CodeableConcept
+	term.offer.decisionMode * CodeableConcept
+	term.offer.answer * BackboneElement
+	term.offer.answer.value[x] 1 boolean|decimal|integer|date|dateTime|time|string|uri|Attachment|Coding|Quantity|Reference
+	term.offer.text 1 string
+	term.offer.linkId * string
+	term.offer.securityLabelNumber * unsignedInt
+	term.asset * BackboneElement
+	term.asset.scope 1 CodeableConcept
+	term.asset.type * CodeableConcept
+	term.asset.typeReference * Reference
+	term.asset.subtype * CodeableConcept
+	term.asset.relationship 1 Coding
+	term.asset.context * BackboneElement
+	term.asset.context.reference 1 Reference
+	term.asset.context.code * CodeableConcept
+	term.asset.context.text 1 string
+	term.asset.condition 1 string
+	term.asset.periodType * CodeableConcept
+	term.asset.period * Period
+	term.asset.usePeriod * Period
+	term.asset.text 1 string
+	term.asset.linkId * string
+	term.asset.answer * #Contract.term.offer.answer
+	term.asset.securityLabelNumber * unsignedInt
+	term.asset.valuedItem * BackboneElement
+	term.asset.valuedItem.entity[x] 1 CodeableConcept|Reference
+	term.asset.valuedItem.identifier 1 Identifier
+	term.asset.valuedItem.effectiveTime 1 dateTime
+	term.asset.valuedItem.quantity 1 Quantity
+	term.asset.valuedItem.unitPrice 1 Money
+	term.asset.valuedItem.factor 1 decimal
+	term.asset.valuedItem.points 1 decimal
+	term.asset.valuedItem.net 1 Money
+	term.asset.valuedItem.payment 1 string
+	term.asset.valuedItem.paymentDate 1 dateTime
+	term.asset.valuedItem.responsible 1 Reference
+	term.asset.valuedItem.recipient 1 Reference
+	term.asset.valuedItem.linkId * string
+	term.asset.valuedItem.securityLabelNumber * unsignedInt
+	term.action * BackboneElement
+	term.action.doNotPerform 1 boolean
+	term.action.type 1 CodeableConcept
+	term.action.subject * BackboneElement
+	term.action.subject.reference * Reference
+	term.action.subject.role 1 CodeableConcept
+	term.action.intent 1 CodeableConcept
+	term.action.linkId * string
+	term.action.status 1 CodeableConcept
+	term.action.context 1 Reference
+	term.action.contextLinkId * string
+	term.action.occurrence[x] 1 dateTime|Period|Timing
+	term.action.requester * Reference
+	term.action.requesterLinkId * string
+	term.action.performerType * CodeableConcept
+	term.action.performerRole 1 CodeableConcept
+	term.action.performer 1 Reference
+	term.action.performerLinkId * string
+	term.action.reasonCode * CodeableConcept
+	term.action.reasonReference * Reference
+	term.action.reason * string
+	term.action.reasonLinkId * string
+	term.action.note * Annotation
+	term.action.securityLabelNumber * unsignedInt
+	term.group * #Contract.term
+	supportingInfo * Reference
+	relevantHistory * Reference
+	signer * BackboneElement
+	signer.type 1 Coding
+	signer.party 1 Reference
+	signer.signature * Signature
+	friendly * BackboneElement
+	friendly.content[x] 1 Attachment|Reference
+	legal * BackboneElement
+	legal.content[x] 1 Attachment|Reference
+	rule * BackboneElement
+	rule.content[x] 1 Attachment|Reference
+	legallyBinding[x] 1 Attachment|Reference
+
+Coverage
+	identifier * Identifier
+	status 1 code
+	type 1 CodeableConcept
+	policyHolder 1 Reference
+	subscriber 1 Reference
+	subscriberId 1 string
+	beneficiary 1 Reference
+	dependent 1 string
+	relationship 1 CodeableConcept
+	period 1 Period
+	payor * Reference
+	class * BackboneElement
+	class.type 1 CodeableConcept
+	class.value 1 string
+	class.name 1 string
+	order 1 positiveInt
+	network 1 string
+	costToBeneficiary * BackboneElement
+	costToBeneficiary.type 1 CodeableConcept
+	costToBeneficiary.value[x] 1 Quantity|Money
+	costToBeneficiary.exception * BackboneElement
+	costToBeneficiary.exception.type 1 CodeableConcept
+	costToBeneficiary.exception.period 1 Period
+	subrogation 1 boolean
+	contract * Reference
+
+CoverageEligibilityRequest
+	identifier * Identifier
+	status 1 code
+	priority 1 CodeableConcept
+	purpose * code
+	patient 1 Reference
+	serviced[x] 1 date|Period
+	created 1 dateTime
+	enterer 1 Reference
+	provider 1 Reference
+	insurer 1 Reference
+	facility 1 Reference
+	supportingInfo * BackboneElement
+	supportingInfo.sequence 1 positiveInt
+	supportingInfo.information 1 Reference
+	supportingInfo.appliesToAll 1 boolean
+	insurance * BackboneElement
+	insurance.focal 1 boolean
+	insurance.coverage 1 Reference
+	insurance.businessArrangement 1 string
+	item * BackboneElement
+	item.supportingInfoSequence * positiveInt
+	item.category 1 CodeableConcept
+	item.productOrService 1 CodeableConcept
+	item.modifier * CodeableConcept
+	item.provider 1 Reference
+	item.quantity 1 Quantity
+	item.unitPrice 1 Money
+	item.facility 1 Reference
+	item.diagnosis * BackboneElement
+	item.diagnosis.diagnosis[x] 1 CodeableConcept|Reference
+	item.detail * Reference
+
+CoverageEligibilityResponse
+	identifier * Identifier
+	status 1 code
+	purpose * code
+	patient 1 Reference
+	serviced[x] 1 date|Period
+	created 1 dateTime
+	requestor 1 Reference
+	request 1 Reference
+	outcome 1 code
+	disposition 1 string
+	insurer 1 Reference
+	insurance * BackboneElement
+	insurance.coverage 1 Reference
+	insurance.inforce 1 boolean
+	insurance.benefitPeriod 1 Period
+	insurance.item * BackboneElement
+	insurance.item.category 1 CodeableConcept
+	insurance.item.productOrService 1 CodeableConcept
+	insurance.item.modifier * CodeableConcept
+	insurance.item.provider 1 Reference
+	insurance.item.excluded 1 boolean
+	insurance.item.name 1 string
+	insurance.item.description 1 string
+	insurance.item.network 1 CodeableConcept
+	insurance.item.unit 1 CodeableConcept
+	insurance.item.term 1 CodeableConcept
+	insurance.item.benefit * BackboneElement
+	insurance.item.benefit.type 1 CodeableConcept
+	insurance.item.benefit.allowed[x] 1 unsignedInt|string|Money
+	insurance.item.benefit.used[x] 1 unsignedInt|string|Money
+	insurance.item.authorizationRequired 1 boolean
+	insurance.item.authorizationSupporting * CodeableConcept
+	insurance.item.authorizationUrl 1 uri
+	preAuthRef 1 string
+	form 1 CodeableConcept
+	error * BackboneElement
+	error.code 1 CodeableConcept
+
+DetectedIssue
+	identifier * Identifier
+	status 1 code
+	code 1 CodeableConcept
+	severity 1 code
+	patient 1 Reference
+	identified[x] 1 dateTime|Period
+	author 1 Reference
+	implicated * Reference
+	evidence * BackboneElement
+	evidence.code * CodeableConcept
+	evidence.detail * Reference
+	detail 1 string
+	reference 1 uri
+	mitigation * BackboneElement
+	mitigation.action 1 CodeableConcept
+	mitigation.date 1 dateTime
+	mitigation.author 1 Reference
+
+Device
+	identifier * Identifier
+	definition 1 Reference
+	udiCarrier * BackboneElement
+	udiCarrier.deviceIdentifier 1 string
+	udiCarrier.issuer 1 uri
+	udiCarrier.jurisdiction 1 uri
+	udiCarrier.carrierAIDC 1 base64Binary
+	udiCarrier.carrierHRF 1 string
+	udiCarrier.entryType 1 code
+	status 1 code
+	statusReason * CodeableConcept
+	distinctIdentifier 1 string
+	manufacturer 1 string
+	manufactureDate 1 dateTime
+	expirationDate 1 dateTime
+	lotNumber 1 string
+	serialNumber 1 string
+	deviceName * BackboneElement
+	deviceName.name 1 string
+	deviceName.type 1 code
+	modelNumber 1 string
+	partNumber 1 string
+	type 1 CodeableConcept
+	specialization * BackboneElement
+	specialization.systemType 1 CodeableConcept
+	specialization.version 1 string
+	version * BackboneElement
+	version.type 1 CodeableConcept
+	version.component 1 Identifier
+	version.value 1 string
+	property * BackboneElement
+	property.type 1 CodeableConcept
+	property.valueQuantity * Quantity
+	property.valueCode * CodeableConcept
+	patient 1 Reference
+	owner 1 Reference
+	contact * ContactPoint
+	location 1 Reference
+	url 1 uri
+	note * Annotation
+	safety * CodeableConcept
+	parent 1 Reference
+
+DeviceDefinition
+	identifier * Identifier
+	udiDeviceIdentifier * BackboneElement
+	udiDeviceIdentifier.deviceIdentifier 1 string
+	udiDeviceIdentifier.issuer 1 uri
+	udiDeviceIdentifier.jurisdiction 1 uri
+	manufacturer[x] 1 string|Reference
+	deviceName * BackboneElement
+	deviceName.name 1 string
+	deviceName.type 1 code
+	modelNumber 1 string
+	type 1 CodeableConcept
+	specialization * BackboneElement
+	specialization.systemType 1 string
+	specialization.version 1 string
+	version * string
+	safety * CodeableConcept
+	shelfLifeStorage * ProductShelfLife
+	physicalCharacteristics 1 ProdCharacteristic
+	languageCode * CodeableConcept
+	capability * BackboneElement
+	capability.type 1 CodeableConcept
+	capability.description * CodeableConcept
+	property * BackboneElement
+	property.type 1 CodeableConcept
+	property.valueQuantity * Quantity
+	property.valueCode * CodeableConcept
+	owner 1 Reference
+	contact * ContactPoint
+	url 1 uri
+	onlineInformation 1 uri
+	note * Annotation
+	quantity 1 Quantity
+	parentDevice 1 Reference
+	material * BackboneElement
+	material.substance 1 CodeableConcept
+	material.alternate 1 boolean
+	material.allergenicIndicator 1 boolean
+
+DeviceMetric
+	identifier * Identifier
+	type 1 CodeableConcept
+	unit 1 CodeableConcept
+	source 1 Reference
+	parent 1 Reference
+	operationalStatus 1 code
+	color 1 code
+	category 1 code
+	measurementPeriod 1 Timing
+	calibration * BackboneElement
+	calibration.type 1 code
+	calibration.state 1 code
+	calibration.time 1 instant
+
+DeviceRequest
+	identifier * Identifier
+	instantiatesCanonical * canonical
+	instantiatesUri * uri
+	basedOn * Reference
+	priorRequest * Reference
+	groupIdentifier 1 Identifier
+	status 1 code
+	intent 1 code
+	priority 1 code
+	code[x] 1 Reference|CodeableConcept
+	parameter * BackboneElement
+	parameter.code 1 CodeableConcept
+	parameter.value[x] 1 CodeableConcept|Quantity|Range|boolean
+	subject 1 Reference
+	encounter 1 Reference
+	occurrence[x] 1 dateTime|Period|Timing
+	authoredOn 1 dateTime
+	requester 1 Reference
+	performerType 1 CodeableConcept
+	performer 1 Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	insurance * Reference
+	supportingInfo * Reference
+	note * Annotation
+	relevantHistory * Reference
+
+DeviceUseStatement
+	identifier * Identifier
+	basedOn * Reference
+	status 1 code
+	subject 1 Reference
+	derivedFrom * Reference
+	timing[x] 1 Timing|Period|dateTime
+	recordedOn 1 dateTime
+	source 1 Reference
+	device 1 Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	bodySite 1 CodeableConcept
+	note * Annotation
+
+DiagnosticReport
+	identifier * Identifier
+	basedOn * Reference
+	status 1 code
+	category * CodeableConcept
+	code 1 CodeableConcept
+	subject 1 Reference
+	encounter 1 Reference
+	effective[x] 1 dateTime|Period
+	issued 1 instant
+	performer * Reference
+	resultsInterpreter * Reference
+	specimen * Reference
+	result * Reference
+	imagingStudy * Reference
+	media * BackboneElement
+	media.comment 1 string
+	media.link 1 Reference
+	conclusion 1 string
+	conclusionCode * CodeableConcept
+	presentedForm * Attachment
+
+DocumentManifest
+	masterIdentifier 1 Identifier
+	identifier * Identifier
+	status 1 code
+	type 1 CodeableConcept
+	subject 1 Reference
+	created 1 dateTime
+	author * Reference
+	recipient * Reference
+	source 1 uri
+	description 1 string
+	content * Reference
+	related * BackboneElement
+	related.identifier 1 Identifier
+	related.ref 1 Reference
+
+DocumentReference
+	masterIdentifier 1 Identifier
+	identifier * Identifier
+	status 1 code
+	docStatus 1 code
+	type 1 CodeableConcept
+	category * CodeableConcept
+	subject 1 Reference
+	date 1 instant
+	author * Reference
+	authenticator 1 Reference
+	custodian 1 Reference
+	relatesTo * BackboneElement
+	relatesTo.code 1 code
+	relatesTo.target 1 Reference
+	description 1 string
+	securityLabel * CodeableConcept
+	content * BackboneElement
+	content.attachment 1 Attachment
+	content.format 1 Coding
+	context 1 BackboneElement
+	context.encounter * Reference
+	context.event * CodeableConcept
+	context.period 1 Period
+	context.facilityType 1 CodeableConcept
+	context.practiceSetting 1 CodeableConcept
+	context.sourcePatientInfo 1 Reference
+	context.related * Reference
+
+EffectEvidenceSynthesis
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	status 1 code
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	note * Annotation
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	topic * CodeableConcept
+	author * ContactDetail
+	editor * ContactDetail
+	reviewer * ContactDetail
+	endorser * ContactDetail
+	relatedArtifact * RelatedArtifact
+	synthesisType 1 CodeableConcept
+	studyType 1 CodeableConcept
+	population 1 Reference
+	exposure 1 Reference
+	exposureAlternative 1 Reference
+	outcome 1 Reference
+	sampleSize 1 BackboneElement
+	sampleSize.description 1 string
+	sampleSize.numberOfStudies 1 integer
+	sampleSize.numberOfParticipants 1 integer
+	resultsByExposure * BackboneElement
+	resultsByExposure.description 1 string
+	resultsByExposure.exposureState 1 code
+	resultsByExposure.variantState 1 CodeableConcept
+	resultsByExposure.riskEvidenceSynthesis 1 Reference
+	effectEstimate * BackboneElement
+	effectEstimate.description 1 string
+	effectEstimate.type 1 CodeableConcept
+	effectEstimate.variantState 1 CodeableConcept
+	effectEstimate.value 1 decimal
+	effectEstimate.unitOfMeasure 1 CodeableConcept
+	effectEstimate.precisionEstimate * BackboneElement
+	effectEstimate.precisionEstimate.type 1 CodeableConcept
+	effectEstimate.precisionEstimate.level 1 decimal
+	effectEstimate.precisionEstimate.from 1 decimal
+	effectEstimate.precisionEstimate.to 1 decimal
+	certainty * BackboneElement
+	certainty.rating * CodeableConcept
+	certainty.note * Annotation
+	certainty.certaintySubcomponent * BackboneElement
+	certainty.certaintySubcomponent.type 1 CodeableConcept
+	certainty.certaintySubcomponent.rating * CodeableConcept
+	certainty.certaintySubcomponent.note * Annotation
+
+Encounter
+	identifier * Identifier
+	status 1 code
+	statusHistory * BackboneElement
+	statusHistory.status 1 code
+	statusHistory.period 1 Period
+	class 1 Coding
+	classHistory * BackboneElement
+	classHistory.class 1 Coding
+	classHistory.period 1 Period
+	type * CodeableConcept
+	serviceType 1 CodeableConcept
+	priority 1 CodeableConcept
+	subject 1 Reference
+	episodeOfCare * Reference
+	basedOn * Reference
+	participant * BackboneElement
+	participant.type * CodeableConcept
+	participant.period 1 Period
+	participant.individual 1 Reference
+	appointment * Reference
+	period 1 Period
+	length 1 Duration
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	diagnosis * BackboneElement
+	diagnosis.condition 1 Reference
+	diagnosis.use 1 CodeableConcept
+	diagnosis.rank 1 positiveInt
+	account * Reference
+	hospitalization 1 BackboneElement
+	hospitalization.preAdmissionIdentifier 1 Identifier
+	hospitalization.origin 1 Reference
+	hospitalization.admitSource 1 CodeableConcept
+	hospitalization.reAdmission 1 CodeableConcept
+	hospitalization.dietPreference * CodeableConcept
+	hospitalization.specialCourtesy * CodeableConcept
+	hospitalization.specialArrangement * CodeableConcept
+	hospitalization.destination 1 Reference
+	hospitalization.dischargeDisposition 1 CodeableConcept
+	location * BackboneElement
+	location.location 1 Reference
+	location.status 1 code
+	location.physicalType 1 CodeableConcept
+	location.period 1 Period
+	serviceProvider 1 Reference
+	partOf 1 Reference
+
+Endpoint
+	identifier * Identifier
+	status 1 code
+	connectionType 1 Coding
+	name 1 string
+	managingOrganization 1 Reference
+	contact * ContactPoint
+	period 1 Period
+	payloadType * CodeableConcept
+	payloadMimeType * code
+	address 1 url
+	header * string
+
+EnrollmentRequest
+	identifier * Identifier
+	status 1 code
+	created 1 dateTime
+	insurer 1 Reference
+	provider 1 Reference
+	candidate 1 Reference
+	coverage 1 Reference
+
+EnrollmentResponse
+	identifier * Identifier
+	status 1 code
+	request 1 Reference
+	outcome 1 code
+	disposition 1 string
+	created 1 dateTime
+	organization 1 Reference
+	requestProvider 1 Reference
+
+EpisodeOfCare
+	identifier * Identifier
+	status 1 code
+	statusHistory * BackboneElement
+	statusHistory.status 1 code
+	statusHistory.period 1 Period
+	type * CodeableConcept
+	diagnosis * BackboneElement
+	diagnosis.condition 1 Reference
+	diagnosis.role 1 CodeableConcept
+	diagnosis.rank 1 positiveInt
+	patient 1 Reference
+	managingOrganization 1 Reference
+	period 1 Period
+	referralRequest * Reference
+	careManager 1 Reference
+	team * Reference
+	account * Reference
+
+EventDefinition
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	subtitle 1 string
+	status 1 code
+	experimental 1 boolean
+	subject[x] 1 CodeableConcept|Reference
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	usage 1 string
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	topic * CodeableConcept
+	author * ContactDetail
+	editor * ContactDetail
+	reviewer * ContactDetail
+	endorser * ContactDetail
+	relatedArtifact * RelatedArtifact
+	trigger * TriggerDefinition
+
+Evidence
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	shortTitle 1 string
+	subtitle 1 string
+	status 1 code
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	note * Annotation
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	topic * CodeableConcept
+	author * ContactDetail
+	editor * ContactDetail
+	reviewer * ContactDetail
+	endorser * ContactDetail
+	relatedArtifact * RelatedArtifact
+	exposureBackground 1 Reference
+	exposureVariant * Reference
+	outcome * Reference
+
+EvidenceVariable
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	shortTitle 1 string
+	subtitle 1 string
+	status 1 code
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	note * Annotation
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	topic * CodeableConcept
+	author * ContactDetail
+	editor * ContactDetail
+	reviewer * ContactDetail
+	endorser * ContactDetail
+	relatedArtifact * RelatedArtifact
+	type 1 code
+	characteristic * BackboneElement
+	characteristic.description 1 string
+	characteristic.definition[x] 1 Reference|canonical|CodeableConcept|Expression|DataRequirement|TriggerDefinition
+	characteristic.usageContext * UsageContext
+	characteristic.exclude 1 boolean
+	characteristic.participantEffective[x] 1 dateTime|Period|Duration|Timing
+	characteristic.timeFromStart 1 Duration
+	characteristic.groupMeasure 1 code
+
+ExampleScenario
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	copyright 1 markdown
+	purpose 1 markdown
+	actor * BackboneElement
+	actor.actorId 1 string
+	actor.type 1 code
+	actor.name 1 string
+	actor.description 1 markdown
+	instance * BackboneElement
+	instance.resourceId 1 string
+	instance.resourceType 1 code
+	instance.name 1 string
+	instance.description 1 markdown
+	instance.version * BackboneElement
+	instance.version.versionId 1 string
+	instance.version.description 1 markdown
+	instance.containedInstance * BackboneElement
+	instance.containedInstance.resourceId 1 string
+	instance.containedInstance.versionId 1 string
+	process * BackboneElement
+	process.title 1 string
+	process.description 1 markdown
+	process.preConditions 1 markdown
+	process.postConditions 1 markdown
+	process.step * BackboneElement
+	process.step.process * #ExampleScenario.process
+	process.step.pause 1 boolean
+	process.step.operation 1 BackboneElement
+	process.step.operation.number 1 string
+	process.step.operation.type 1 string
+	process.step.operation.name 1 string
+	process.step.operation.initiator 1 string
+	process.step.operation.receiver 1 string
+	process.step.operation.description 1 markdown
+	process.step.operation.initiatorActive 1 boolean
+	process.step.operation.receiverActive 1 boolean
+	process.step.operation.request 1 #ExampleScenario.instance.containedInstance
+	process.step.operation.response 1 #ExampleScenario.instance.containedInstance
+	process.step.alternative * BackboneElement
+	process.step.alternative.title 1 string
+	process.step.alternative.description 1 markdown
+	process.step.alternative.step * #ExampleScenario.process.step
+	workflow * canonical
+
+ExplanationOfBenefit
+	identifier * Identifier
+	status 1 code
+	type 1 CodeableConcept
+	subType 1 CodeableConcept
+	use 1 code
+	patient 1 Reference
+	billablePeriod 1 Period
+	created 1 dateTime
+	enterer 1 Reference
+	insurer 1 Reference
+	provider 1 Reference
+	priority 1 CodeableConcept
+	fundsReserveRequested 1 CodeableConcept
+	fundsReserve 1 CodeableConcept
+	related * BackboneElement
+	related.claim 1 Reference
+	related.relationship 1 CodeableConcept
+	related.reference 1 Identifier
+	prescription 1 Reference
+	originalPrescription 1 Reference
+	payee 1 BackboneElement
+	payee.type 1 CodeableConcept
+	payee.party 1 Reference
+	referral 1 Reference
+	facility 1 Reference
+	claim 1 Reference
+	claimResponse 1 Reference
+	outcome 1 code
+	disposition 1 string
+	preAuthRef * string
+	preAuthRefPeriod * Period
+	careTeam * BackboneElement
+	careTeam.sequence 1 positiveInt
+	careTeam.provider 1 Reference
+	careTeam.responsible 1 boolean
+	careTeam.role 1 CodeableConcept
+	careTeam.qualification 1 CodeableConcept
+	supportingInfo * BackboneElement
+	supportingInfo.sequence 1 positiveInt
+	supportingInfo.category 1 CodeableConcept
+	supportingInfo.code 1 CodeableConcept
+	supportingInfo.timing[x] 1 date|Period
+	supportingInfo.value[x] 1 boolean|string|Quantity|Attachment|Reference
+	supportingInfo.reason 1 Coding
+	diagnosis * BackboneElement
+	diagnosis.sequence 1 positiveInt
+	diagnosis.diagnosis[x] 1 CodeableConcept|Reference
+	diagnosis.type * CodeableConcept
+	diagnosis.onAdmission 1 CodeableConcept
+	diagnosis.packageCode 1 CodeableConcept
+	procedure * BackboneElement
+	procedure.sequence 1 positiveInt
+	procedure.type * CodeableConcept
+	procedure.date 1 dateTime
+	procedure.procedure[x] 1 CodeableConcept|Reference
+	procedure.udi * Reference
+	precedence 1 positiveInt
+	insurance * BackboneElement
+	insurance.focal 1 boolean
+	insurance.coverage 1 Reference
+	insurance.preAuthRef * string
+	accident 1 BackboneElement
+	accident.date 1 date
+	accident.type 1 CodeableConcept
+	accident.location[x] 1 Address|Reference
+	item * BackboneElement
+	item.sequence 1 positiveInt
+	item.careTeamSequence * positiveInt
+	item.diagnosisSequence * positiveInt
+	item.procedureSequence * positiveInt
+	item.informationSequence * positiveInt
+	item.revenue 1 CodeableConcept
+	item.category 1 CodeableConcept
+	item.productOrService 1 CodeableConcept
+	item.modifier * CodeableConcept
+	item.programCode * CodeableConcept
+	item.serviced[x] 1 date|Period
+	item.location[x] 1 CodeableConcept|Address|Reference
+	item.quantity 1 Quantity
+	item.unitPrice 1 Money
+	item.factor 1 decimal
+	item.net 1 Money
+	item.udi * Reference
+	item.bodySite 1 CodeableConcept
+	item.subSite * CodeableConcept
+	item.encounter * Reference
+	item.noteNumber * positiveInt
+	item.adjudication * BackboneElement
+	item.adjudication.category 1 CodeableConcept
+	item.adjudication.reason 1 CodeableConcept
+	item.adjudication.amount 1 Money
+	item.adjudication.value 1 decimal
+	item.detail * BackboneElement
+	item.detail.sequence 1 positiveInt
+	item.detail.revenue 1 CodeableConcept
+	item.detail.category 1 CodeableConcept
+	item.detail.productOrService 1 CodeableConcept
+	item.detail.modifier * CodeableConcept
+	item.detail.programCode * CodeableConcept
+	item.detail.quantity 1 Quantity
+	item.detail.unitPrice 1 Money
+	item.detail.factor 1 decimal
+	item.detail.net 1 Money
+	item.detail.udi * Reference
+	item.detail.noteNumber * positiveInt
+	item.detail.adjudication * #ExplanationOfBenefit.item.adjudication
+	item.detail.subDetail * BackboneElement
+	item.detail.subDetail.sequence 1 positiveInt
+	item.detail.subDetail.revenue 1 CodeableConcept
+	item.detail.subDetail.category 1 CodeableConcept
+	item.detail.subDetail.productOrService 1 CodeableConcept
+	item.detail.subDetail.modifier * CodeableConcept
+	item.detail.subDetail.programCode * CodeableConcept
+	item.detail.subDetail.quantity 1 Quantity
+	item.detail.subDetail.unitPrice 1 Money
+	item.detail.subDetail.factor 1 decimal
+	item.detail.subDetail.net 1 Money
+	item.detail.subDetail.udi * Reference
+	item.detail.subDetail.noteNumber * positiveInt
+	item.detail.subDetail.adjudication * #ExplanationOfBenefit.item.adjudication
+	addItem * BackboneElement
+	addItem.itemSequence * positiveInt
+	addItem.detailSequence * positiveInt
+	addItem.subDetailSequence * positiveInt
+	addItem.provider * Reference
+	addItem.productOrService 1 CodeableConcept
+	addItem.modifier * CodeableConcept
+	addItem.programCode * CodeableConcept
+	addItem.serviced[x] 1 date|Period
+	addItem.location[x] 1 CodeableConcept|Address|Reference
+	addItem.quantity 1 Quantity
+	addItem.unitPrice 1 Money
+	addItem.factor 1 decimal
+	addItem.net 1 Money
+	addItem.bodySite 1 CodeableConcept
+	addItem.subSite * CodeableConcept
+	addItem.noteNumber * positiveInt
+	addItem.adjudication * #ExplanationOfBenefit.item.adjudication
+	addItem.detail * BackboneElement
+	addItem.detail.productOrService 1 CodeableConcept
+	addItem.detail.modifier * CodeableConcept
+	addItem.detail.quantity 1 Quantity
+	addItem.detail.unitPrice 1 Money
+	addItem.detail.factor 1 decimal
+	addItem.detail.net 1 Money
+	addItem.detail.noteNumber * positiveInt
+	addItem.detail.adjudication * #ExplanationOfBenefit.item.adjudication
+	addItem.detail.subDetail * BackboneElement
+	addItem.detail.subDetail.productOrService 1 CodeableConcept
+	addItem.detail.subDetail.modifier * CodeableConcept
+	addItem.detail.subDetail.quantity 1 Quantity
+	addItem.detail.subDetail.unitPrice 1 Money
+	addItem.detail.subDetail.factor 1 decimal
+	addItem.detail.subDetail.net 1 Money
+	addItem.detail.subDetail.noteNumber * positiveInt
+	addItem.detail.subDetail.adjudication * #ExplanationOfBenefit.item.adjudication
+	adjudication * #ExplanationOfBenefit.item.adjudication
+	total * BackboneElement
+	total.category 1 CodeableConcept
+	total.amount 1 Money
+	payment 1 BackboneElement
+	payment.type 1 CodeableConcept
+	payment.adjustment 1 Money
+	payment.adjustmentReason 1 CodeableConcept
+	payment.date 1 date
+	payment.amount 1 Money
+	payment.identifier 1 Identifier
+	formCode 1 CodeableConcept
+	form 1 Attachment
+	processNote * BackboneElement
+	processNote.number 1 positiveInt
+	processNote.type 1 code
+	processNote.text 1 string
+	processNote.language 1 CodeableConcept
+	benefitPeriod 1 Period
+	benefitBalance * BackboneElement
+	benefitBalance.category 1 CodeableConcept
+	benefitBalance.excluded 1 boolean
+	benefitBalance.name 1 string
+	benefitBalance.description 1 string
+	benefitBalance.network 1 CodeableConcept
+	benefitBalance.unit 1 CodeableConcept
+	benefitBalance.term 1 CodeableConcept
+	benefitBalance.financial * BackboneElement
+	benefitBalance.financial.type 1 CodeableConcept
+	benefitBalance.financial.allowed[x] 1 unsignedInt|string|Money
+	benefitBalance.financial.used[x] 1 unsignedInt|Money
+
+FamilyMemberHistory
+	identifier * Identifier
+	instantiatesCanonical * canonical
+	instantiatesUri * uri
+	status 1 code
+	dataAbsentReason 1 CodeableConcept
+	patient 1 Reference
+	date 1 dateTime
+	name 1 string
+	relationship 1 CodeableConcept
+	sex 1 CodeableConcept
+	born[x] 1 Period|date|string
+	age[x] 1 Age|Range|string
+	estimatedAge 1 boolean
+	deceased[x] 1 boolean|Age|Range|date|string
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	note * Annotation
+	condition * BackboneElement
+	condition.code 1 CodeableConcept
+	condition.outcome 1 CodeableConcept
+	condition.contributedToDeath 1 boolean
+	condition.onset[x] 1 Age|Range|Period|string
+	condition.note * Annotation
+
+Flag
+	identifier * Identifier
+	status 1 code
+	category * CodeableConcept
+	code 1 CodeableConcept
+	subject 1 Reference
+	period 1 Period
+	encounter 1 Reference
+	author 1 Reference
+
+Goal
+	identifier * Identifier
+	lifecycleStatus 1 code
+	achievementStatus 1 CodeableConcept
+	category * CodeableConcept
+	priority 1 CodeableConcept
+	description 1 CodeableConcept
+	subject 1 Reference
+	start[x] 1 date|CodeableConcept
+	target * BackboneElement
+	target.measure 1 CodeableConcept
+	target.detail[x] 1 Quantity|Range|CodeableConcept|string|boolean|integer|Ratio
+	target.due[x] 1 date|Duration
+	statusDate 1 date
+	statusReason 1 string
+	expressedBy 1 Reference
+	addresses * Reference
+	note * Annotation
+	outcomeCode * CodeableConcept
+	outcomeReference * Reference
+
+GraphDefinition
+	url 1 uri
+	version 1 string
+	name 1 string
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	start 1 code
+	profile 1 canonical
+	link * BackboneElement
+	link.path 1 string
+	link.sliceName 1 string
+	link.min 1 integer
+	link.max 1 string
+	link.description 1 string
+	link.target * BackboneElement
+	link.target.type 1 code
+	link.target.params 1 string
+	link.target.profile 1 canonical
+	link.target.compartment * BackboneElement
+	link.target.compartment.use 1 code
+	link.target.compartment.code 1 code
+	link.target.compartment.rule 1 code
+	link.target.compartment.expression 1 string
+	link.target.compartment.description 1 string
+	link.target.link * #GraphDefinition.link
+
+Group
+	identifier * Identifier
+	active 1 boolean
+	type 1 code
+	actual 1 boolean
+	code 1 CodeableConcept
+	name 1 string
+	quantity 1 unsignedInt
+	managingEntity 1 Reference
+	characteristic * BackboneElement
+	characteristic.code 1 CodeableConcept
+	characteristic.value[x] 1 CodeableConcept|boolean|Quantity|Range|Reference
+	characteristic.exclude 1 boolean
+	characteristic.period 1 Period
+	member * BackboneElement
+	member.entity 1 Reference
+	member.period 1 Period
+	member.inactive 1 boolean
+
+GuidanceResponse
+	requestIdentifier 1 Identifier
+	identifier * Identifier
+	module[x] 1 uri|canonical|CodeableConcept
+	status 1 code
+	subject 1 Reference
+	encounter 1 Reference
+	occurrenceDateTime 1 dateTime
+	performer 1 Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	note * Annotation
+	evaluationMessage * Reference
+	outputParameters 1 Reference
+	result 1 Reference
+	dataRequirement * DataRequirement
+
+HealthcareService
+	identifier * Identifier
+	active 1 boolean
+	providedBy 1 Reference
+	category * CodeableConcept
+	type * CodeableConcept
+	specialty * CodeableConcept
+	location * Reference
+	name 1 string
+	comment 1 string
+	extraDetails 1 markdown
+	photo 1 Attachment
+	telecom * ContactPoint
+	coverageArea * Reference
+	serviceProvisionCode * CodeableConcept
+	eligibility * BackboneElement
+	eligibility.code 1 CodeableConcept
+	eligibility.comment 1 markdown
+	program * CodeableConcept
+	characteristic * CodeableConcept
+	communication * CodeableConcept
+	referralMethod * CodeableConcept
+	appointmentRequired 1 boolean
+	availableTime * BackboneElement
+	availableTime.daysOfWeek * code
+	availableTime.allDay 1 boolean
+	availableTime.availableStartTime 1 time
+	availableTime.availableEndTime 1 time
+	notAvailable * BackboneElement
+	notAvailable.description 1 string
+	notAvailable.during 1 Period
+	availabilityExceptions 1 string
+	endpoint * Reference
+
+ImagingStudy
+	identifier * Identifier
+	status 1 code
+	modality * Coding
+	subject 1 Reference
+	encounter 1 Reference
+	started 1 dateTime
+	basedOn * Reference
+	referrer 1 Reference
+	interpreter * Reference
+	endpoint * Reference
+	numberOfSeries 1 unsignedInt
+	numberOfInstances 1 unsignedInt
+	procedureReference 1 Reference
+	procedureCode * CodeableConcept
+	location 1 Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	note * Annotation
+	description 1 string
+	series * BackboneElement
+	series.uid 1 id
+	series.number 1 unsignedInt
+	series.modality 1 Coding
+	series.description 1 string
+	series.numberOfInstances 1 unsignedInt
+	series.endpoint * Reference
+	series.bodySite 1 Coding
+	series.laterality 1 Coding
+	series.specimen * Reference
+	series.started 1 dateTime
+	series.performer * BackboneElement
+	series.performer.function 1 CodeableConcept
+	series.performer.actor 1 Reference
+	series.instance * BackboneElement
+	series.instance.uid 1 id
+	series.instance.sopClass 1 Coding
+	series.instance.number 1 unsignedInt
+	series.instance.title 1 string
+
+Immunization
+	identifier * Identifier
+	status 1 code
+	statusReason 1 CodeableConcept
+	vaccineCode 1 CodeableConcept
+	patient 1 Reference
+	encounter 1 Reference
+	occurrence[x] 1 dateTime|string
+	recorded 1 dateTime
+	primarySource 1 boolean
+	reportOrigin 1 CodeableConcept
+	location 1 Reference
+	manufacturer 1 Reference
+	lotNumber 1 string
+	expirationDate 1 date
+	site 1 CodeableConcept
+	route 1 CodeableConcept
+	doseQuantity 1 Quantity
+	performer * BackboneElement
+	performer.function 1 CodeableConcept
+	performer.actor 1 Reference
+	note * Annotation
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	isSubpotent 1 boolean
+	subpotentReason * CodeableConcept
+	education * BackboneElement
+	education.documentType 1 string
+	education.reference 1 uri
+	education.publicationDate 1 dateTime
+	education.presentationDate 1 dateTime
+	programEligibility * CodeableConcept
+	fundingSource 1 CodeableConcept
+	reaction * BackboneElement
+	reaction.date 1 dateTime
+	reaction.detail 1 Reference
+	reaction.reported 1 boolean
+	protocolApplied * BackboneElement
+	protocolApplied.series 1 string
+	protocolApplied.authority 1 Reference
+	protocolApplied.targetDisease * CodeableConcept
+	protocolApplied.doseNumber[x] 1 positiveInt|string
+	protocolApplied.seriesDoses[x] 1 positiveInt|string
+
+ImmunizationEvaluation
+	identifier * Identifier
+	status 1 code
+	patient 1 Reference
+	date 1 dateTime
+	authority 1 Reference
+	targetDisease 1 CodeableConcept
+	immunizationEvent 1 Reference
+	doseStatus 1 CodeableConcept
+	doseStatusReason * CodeableConcept
+	description 1 string
+	series 1 string
+	doseNumber[x] 1 positiveInt|string
+	seriesDoses[x] 1 positiveInt|string
+
+ImmunizationRecommendation
+	identifier * Identifier
+	patient 1 Reference
+	date 1 dateTime
+	authority 1 Reference
+	recommendation * BackboneElement
+	recommendation.vaccineCode * CodeableConcept
+	recommendation.targetDisease 1 CodeableConcept
+	recommendation.contraindicatedVaccineCode * CodeableConcept
+	recommendation.forecastStatus 1 CodeableConcept
+	recommendation.forecastReason * CodeableConcept
+	recommendation.dateCriterion * BackboneElement
+	recommendation.dateCriterion.code 1 CodeableConcept
+	recommendation.dateCriterion.value 1 dateTime
+	recommendation.description 1 string
+	recommendation.series 1 string
+	recommendation.doseNumber[x] 1 positiveInt|string
+	recommendation.seriesDoses[x] 1 positiveInt|string
+	recommendation.supportingImmunization * Reference
+	recommendation.supportingPatientInformation * Reference
+
+ImplementationGuide
+	url 1 uri
+	version 1 string
+	name 1 string
+	title 1 string
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	copyright 1 markdown
+	packageId 1 id
+	license 1 code
+	fhirVersion * code
+	dependsOn * BackboneElement
+	dependsOn.uri 1 canonical
+	dependsOn.packageId 1 id
+	dependsOn.version 1 string
+	global * BackboneElement
+	global.type 1 code
+	global.profile 1 canonical
+	definition 1 BackboneElement
+	definition.grouping * BackboneElement
+	definition.grouping.name 1 string
+	definition.grouping.description 1 string
+	definition.resource * BackboneElement
+	definition.resource.reference 1 Reference
+	definition.resource.fhirVersion * code
+	definition.resource.name 1 string
+	definition.resource.description 1 string
+	definition.resource.example[x] 1 boolean|canonical
+	definition.resource.groupingId 1 id
+	definition.page 1 BackboneElement
+	definition.page.name[x] 1 url|Reference
+	definition.page.title 1 string
+	definition.page.generation 1 code
+	definition.page.page * #ImplementationGuide.definition.page
+	definition.parameter * BackboneElement
+	definition.parameter.code 1 code
+	definition.parameter.value 1 string
+	definition.template * BackboneElement
+	definition.template.code 1 code
+	definition.template.source 1 string
+	definition.template.scope 1 string
+	manifest 1 BackboneElement
+	manifest.rendering 1 url
+	manifest.resource * BackboneElement
+	manifest.resource.reference 1 Reference
+	manifest.resource.example[x] 1 boolean|canonical
+	manifest.resource.relativePath 1 url
+	manifest.page * BackboneElement
+	manifest.page.name 1 string
+	manifest.page.title 1 string
+	manifest.page.anchor * string
+	manifest.image * string
+	manifest.other * string
+
+InsurancePlan
+	identifier * Identifier
+	status 1 code
+	type * CodeableConcept
+	name 1 string
+	alias * string
+	period 1 Period
+	ownedBy 1 Reference
+	administeredBy 1 Reference
+	coverageArea * Reference
+	contact * BackboneElement
+	contact.purpose 1 CodeableConcept
+	contact.name 1 HumanName
+	contact.telecom * ContactPoint
+	contact.address 1 Address
+	endpoint * Reference
+	network * Reference
+	coverage * BackboneElement
+	coverage.type 1 CodeableConcept
+	coverage.network * Reference
+	coverage.benefit * BackboneElement
+	coverage.benefit.type 1 CodeableConcept
+	coverage.benefit.requirement 1 string
+	coverage.benefit.limit * BackboneElement
+	coverage.benefit.limit.value 1 Quantity
+	coverage.benefit.limit.code 1 CodeableConcept
+	plan * BackboneElement
+	plan.identifier * Identifier
+	plan.type 1 CodeableConcept
+	plan.coverageArea * Reference
+	plan.network * Reference
+	plan.generalCost * BackboneElement
+	plan.generalCost.type 1 CodeableConcept
+	plan.generalCost.groupSize 1 positiveInt
+	plan.generalCost.cost 1 Money
+	plan.generalCost.comment 1 string
+	plan.specificCost * BackboneElement
+	plan.specificCost.category 1 CodeableConcept
+	plan.specificCost.benefit * BackboneElement
+	plan.specificCost.benefit.type 1 CodeableConcept
+	plan.specificCost.benefit.cost * BackboneElement
+	plan.specificCost.benefit.cost.type 1 CodeableConcept
+	plan.specificCost.benefit.cost.applicability 1 CodeableConcept
+	plan.specificCost.benefit.cost.qualifiers * CodeableConcept
+	plan.specificCost.benefit.cost.value 1 Quantity
+
+Invoice
+	identifier * Identifier
+	status 1 code
+	cancelledReason 1 string
+	type 1 CodeableConcept
+	subject 1 Reference
+	recipient 1 Reference
+	date 1 dateTime
+	participant * BackboneElement
+	participant.role 1 CodeableConcept
+	participant.actor 1 Reference
+	issuer 1 Reference
+	account 1 Reference
+	lineItem * BackboneElement
+	lineItem.sequence 1 positiveInt
+	lineItem.chargeItem[x] 1 Reference|CodeableConcept
+	lineItem.priceComponent * BackboneElement
+	lineItem.priceComponent.type 1 code
+	lineItem.priceComponent.code 1 CodeableConcept
+	lineItem.priceComponent.factor 1 decimal
+	lineItem.priceComponent.amount 1 Money
+	totalPriceComponent * #Invoice.lineItem.priceComponent
+	totalNet 1 Money
+	totalGross 1 Money
+	paymentTerms 1 markdown
+	note * Annotation
+
+Library
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	subtitle 1 string
+	status 1 code
+	experimental 1 boolean
+	type 1 CodeableConcept
+	subject[x] 1 CodeableConcept|Reference
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	usage 1 string
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	topic * CodeableConcept
+	author * ContactDetail
+	editor * ContactDetail
+	reviewer * ContactDetail
+	endorser * ContactDetail
+	relatedArtifact * RelatedArtifact
+	parameter * ParameterDefinition
+	dataRequirement * DataRequirement
+	content * Attachment
+
+Linkage
+	active 1 boolean
+	author 1 Reference
+	item * BackboneElement
+	item.type 1 code
+	item.resource 1 Reference
+
+List
+	identifier * Identifier
+	status 1 code
+	mode 1 code
+	title 1 string
+	code 1 CodeableConcept
+	subject 1 Reference
+	encounter 1 Reference
+	date 1 dateTime
+	source 1 Reference
+	orderedBy 1 CodeableConcept
+	note * Annotation
+	entry * BackboneElement
+	entry.flag 1 CodeableConcept
+	entry.deleted 1 boolean
+	entry.date 1 dateTime
+	entry.item 1 Reference
+	emptyReason 1 CodeableConcept
+
+Location
+	identifier * Identifier
+	status 1 code
+	operationalStatus 1 Coding
+	name 1 string
+	alias * string
+	description 1 string
+	mode 1 code
+	type * CodeableConcept
+	telecom * ContactPoint
+	address 1 Address
+	physicalType 1 CodeableConcept
+	position 1 BackboneElement
+	position.longitude 1 decimal
+	position.latitude 1 decimal
+	position.altitude 1 decimal
+	managingOrganization 1 Reference
+	partOf 1 Reference
+	hoursOfOperation * BackboneElement
+	hoursOfOperation.daysOfWeek * code
+	hoursOfOperation.allDay 1 boolean
+	hoursOfOperation.openingTime 1 time
+	hoursOfOperation.closingTime 1 time
+	availabilityExceptions 1 string
+	endpoint * Reference
+
+Measure
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	subtitle 1 string
+	status 1 code
+	experimental 1 boolean
+	subject[x] 1 CodeableConcept|Reference
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	usage 1 string
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	topic * CodeableConcept
+	author * ContactDetail
+	editor * ContactDetail
+	reviewer * ContactDetail
+	endorser * ContactDetail
+	relatedArtifact * RelatedArtifact
+	library * canonical
+	disclaimer 1 markdown
+	scoring 1 CodeableConcept
+	compositeScoring 1 CodeableConcept
+	type * CodeableConcept
+	riskAdjustment 1 string
+	rateAggregation 1 string
+	rationale 1 markdown
+	clinicalRecommendationStatement 1 markdown
+	improvementNotation 1 CodeableConcept
+	definition * markdown
+	guidance 1 markdown
+	group * BackboneElement
+	group.code 1 CodeableConcept
+	group.description 1 string
+	group.population * BackboneElement
+	group.population.code 1 CodeableConcept
+	group.population.description 1 string
+	group.population.criteria 1 Expression
+	group.stratifier * BackboneElement
+	group.stratifier.code 1 CodeableConcept
+	group.stratifier.description 1 string
+	group.stratifier.criteria 1 Expression
+	group.stratifier.component * BackboneElement
+	group.stratifier.component.code 1 CodeableConcept
+	group.stratifier.component.description 1 string
+	group.stratifier.component.criteria 1 Expression
+	supplementalData * BackboneElement
+	supplementalData.code 1 CodeableConcept
+	supplementalData.usage * CodeableConcept
+	supplementalData.description 1 string
+	supplementalData.criteria 1 Expression
+
+MeasureReport
+	identifier * Identifier
+	status 1 code
+	type 1 code
+	measure 1 canonical
+	subject 1 Reference
+	date 1 dateTime
+	reporter 1 Reference
+	period 1 Period
+	improvementNotation 1 CodeableConcept
+	group * BackboneElement
+	group.code 1 CodeableConcept
+	group.population * BackboneElement
+	group.population.code 1 CodeableConcept
+	group.population.count 1 integer
+	group.population.subjectResults 1 Reference
+	group.measureScore 1 Quantity
+	group.stratifier * BackboneElement
+	group.stratifier.code * CodeableConcept
+	group.stratifier.stratum * BackboneElement
+	group.stratifier.stratum.value 1 CodeableConcept
+	group.stratifier.stratum.component * BackboneElement
+	group.stratifier.stratum.component.code 1 CodeableConcept
+	group.stratifier.stratum.component.value 1 CodeableConcept
+	group.stratifier.stratum.population * BackboneElement
+	group.stratifier.stratum.population.code 1 CodeableConcept
+	group.stratifier.stratum.population.count 1 integer
+	group.stratifier.stratum.population.subjectResults 1 Reference
+	group.stratifier.stratum.measureScore 1 Quantity
+	evaluatedResource * Reference
+
+Media
+	identifier * Identifier
+	basedOn * Reference
+	partOf * Reference
+	status 1 code
+	type 1 CodeableConcept
+	modality 1 CodeableConcept
+	view 1 CodeableConcept
+	subject 1 Reference
+	encounter 1 Reference
+	created[x] 1 dateTime|Period
+	issued 1 instant
+	operator 1 Reference
+	reasonCode * CodeableConcept
+	bodySite 1 CodeableConcept
+	deviceName 1 string
+	device 1 Reference
+	height 1 positiveInt
+	width 1 positiveInt
+	frames 1 positiveInt
+	duration 1 decimal
+	content 1 Attachment
+	note * Annotation
+
+Medication
+	identifier * Identifier
+	code 1 CodeableConcept
+	status 1 code
+	manufacturer 1 Reference
+	form 1 CodeableConcept
+	amount 1 Ratio
+	ingredient * BackboneElement
+	ingredient.item[x] 1 CodeableConcept|Reference
+	ingredient.isActive 1 boolean
+	ingredient.strength 1 Ratio
+	batch 1 BackboneElement
+	batch.lotNumber 1 string
+	batch.expirationDate 1 dateTime
+
+MedicationAdministration
+	identifier * Identifier
+	instantiates * uri
+	partOf * Reference
+	status 1 code
+	statusReason * CodeableConcept
+	category 1 CodeableConcept
+	medication[x] 1 CodeableConcept|Reference
+	subject 1 Reference
+	context 1 Reference
+	supportingInformation * Reference
+	effective[x] 1 dateTime|Period
+	performer * BackboneElement
+	performer.function 1 CodeableConcept
+	performer.actor 1 Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	request 1 Reference
+	device * Reference
+	note * Annotation
+	dosage 1 BackboneElement
+	dosage.text 1 string
+	dosage.site 1 CodeableConcept
+	dosage.route 1 CodeableConcept
+	dosage.method 1 CodeableConcept
+	dosage.dose 1 Quantity
+	dosage.rate[x] 1 Ratio|Quantity
+	eventHistory * Reference
+
+MedicationDispense
+	identifier * Identifier
+	partOf * Reference
+	status 1 code
+	statusReason[x] 1 CodeableConcept|Reference
+	category 1 CodeableConcept
+	medication[x] 1 CodeableConcept|Reference
+	subject 1 Reference
+	context 1 Reference
+	supportingInformation * Reference
+	performer * BackboneElement
+	performer.function 1 CodeableConcept
+	performer.actor 1 Reference
+	location 1 Reference
+	authorizingPrescription * Reference
+	type 1 CodeableConcept
+	quantity 1 Quantity
+	daysSupply 1 Quantity
+	whenPrepared 1 dateTime
+	whenHandedOver 1 dateTime
+	destination 1 Reference
+	receiver * Reference
+	note * Annotation
+	dosageInstruction * Dosage
+	substitution 1 BackboneElement
+	substitution.wasSubstituted 1 boolean
+	substitution.type 1 CodeableConcept
+	substitution.reason * CodeableConcept
+	substitution.responsibleParty * Reference
+	detectedIssue * Reference
+	eventHistory * Reference
+
+MedicationKnowledge
+	code 1 CodeableConcept
+	status 1 code
+	manufacturer 1 Reference
+	doseForm 1 CodeableConcept
+	amount 1 Quantity
+	synonym * string
+	relatedMedicationKnowledge * BackboneElement
+	relatedMedicationKnowledge.type 1 CodeableConcept
+	relatedMedicationKnowledge.reference * Reference
+	associatedMedication * Reference
+	productType * CodeableConcept
+	monograph * BackboneElement
+	monograph.type 1 CodeableConcept
+	monograph.source 1 Reference
+	ingredient * BackboneElement
+	ingredient.item[x] 1 CodeableConcept|Reference
+	ingredient.isActive 1 boolean
+	ingredient.strength 1 Ratio
+	preparationInstruction 1 markdown
+	intendedRoute * CodeableConcept
+	cost * BackboneElement
+	cost.type 1 CodeableConcept
+	cost.source 1 string
+	cost.cost 1 Money
+	monitoringProgram * BackboneElement
+	monitoringProgram.type 1 CodeableConcept
+	monitoringProgram.name 1 string
+	administrationGuidelines * BackboneElement
+	administrationGuidelines.dosage * BackboneElement
+	administrationGuidelines.dosage.type 1 CodeableConcept
+	administrationGuidelines.dosage.dosage * Dosage
+	administrationGuidelines.indication[x] 1 CodeableConcept|Reference
+	administrationGuidelines.patientCharacteristics * BackboneElement
+	administrationGuidelines.patientCharacteristics.characteristic[x] 1 CodeableConcept|Quantity
+	administrationGuidelines.patientCharacteristics.value * string
+	medicineClassification * BackboneElement
+	medicineClassification.type 1 CodeableConcept
+	medicineClassification.classification * CodeableConcept
+	packaging 1 BackboneElement
+	packaging.type 1 CodeableConcept
+	packaging.quantity 1 Quantity
+	drugCharacteristic * BackboneElement
+	drugCharacteristic.type 1 CodeableConcept
+	drugCharacteristic.value[x] 1 CodeableConcept|string|Quantity|base64Binary
+	contraindication * Reference
+	regulatory * BackboneElement
+	regulatory.regulatoryAuthority 1 Reference
+	regulatory.substitution * BackboneElement
+	regulatory.substitution.type 1 CodeableConcept
+	regulatory.substitution.allowed 1 boolean
+	regulatory.schedule * BackboneElement
+	regulatory.schedule.schedule 1 CodeableConcept
+	regulatory.maxDispense 1 BackboneElement
+	regulatory.maxDispense.quantity 1 Quantity
+	regulatory.maxDispense.period 1 Duration
+	kinetics * BackboneElement
+	kinetics.areaUnderCurve * Quantity
+	kinetics.lethalDose50 * Quantity
+	kinetics.halfLifePeriod 1 Duration
+
+MedicationRequest
+	identifier * Identifier
+	status 1 code
+	statusReason 1 CodeableConcept
+	intent 1 code
+	category * CodeableConcept
+	priority 1 code
+	doNotPerform 1 boolean
+	reported[x] 1 boolean|Reference
+	medication[x] 1 CodeableConcept|Reference
+	subject 1 Reference
+	encounter 1 Reference
+	supportingInformation * Reference
+	authoredOn 1 dateTime
+	requester 1 Reference
+	performer 1 Reference
+	performerType 1 CodeableConcept
+	recorder 1 Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	instantiatesCanonical * canonical
+	instantiatesUri * uri
+	basedOn * Reference
+	groupIdentifier 1 Identifier
+	courseOfTherapyType 1 CodeableConcept
+	insurance * Reference
+	note * Annotation
+	dosageInstruction * Dosage
+	dispenseRequest 1 BackboneElement
+	dispenseRequest.initialFill 1 BackboneElement
+	dispenseRequest.initialFill.quantity 1 Quantity
+	dispenseRequest.initialFill.duration 1 Duration
+	dispenseRequest.dispenseInterval 1 Duration
+	dispenseRequest.validityPeriod 1 Period
+	dispenseRequest.numberOfRepeatsAllowed 1 unsignedInt
+	dispenseRequest.quantity 1 Quantity
+	dispenseRequest.expectedSupplyDuration 1 Duration
+	dispenseRequest.performer 1 Reference
+	substitution 1 BackboneElement
+	substitution.allowed[x] 1 boolean|CodeableConcept
+	substitution.reason 1 CodeableConcept
+	priorPrescription 1 Reference
+	detectedIssue * Reference
+	eventHistory * Reference
+
+MedicationStatement
+	identifier * Identifier
+	basedOn * Reference
+	partOf * Reference
+	status 1 code
+	statusReason * CodeableConcept
+	category 1 CodeableConcept
+	medication[x] 1 CodeableConcept|Reference
+	subject 1 Reference
+	context 1 Reference
+	effective[x] 1 dateTime|Period
+	dateAsserted 1 dateTime
+	informationSource 1 Reference
+	derivedFrom * Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	note * Annotation
+	dosage * Dosage
+
+MedicinalProduct
+	identifier * Identifier
+	type 1 CodeableConcept
+	domain 1 Coding
+	combinedPharmaceuticalDoseForm 1 CodeableConcept
+	legalStatusOfSupply 1 CodeableConcept
+	additionalMonitoringIndicator 1 CodeableConcept
+	specialMeasures * string
+	paediatricUseIndicator 1 CodeableConcept
+	productClassification * CodeableConcept
+	marketingStatus * MarketingStatus
+	pharmaceuticalProduct * Reference
+	packagedMedicinalProduct * Reference
+	attachedDocument * Reference
+	masterFile * Reference
+	contact * Reference
+	clinicalTrial * Reference
+	name * BackboneElement
+	name.productName 1 string
+	name.namePart * BackboneElement
+	name.namePart.part 1 string
+	name.namePart.type 1 Coding
+	name.countryLanguage * BackboneElement
+	name.countryLanguage.country 1 CodeableConcept
+	name.countryLanguage.jurisdiction 1 CodeableConcept
+	name.countryLanguage.language 1 CodeableConcept
+	crossReference * Identifier
+	manufacturingBusinessOperation * BackboneElement
+	manufacturingBusinessOperation.operationType 1 CodeableConcept
+	manufacturingBusinessOperation.authorisationReferenceNumber 1 Identifier
+	manufacturingBusinessOperation.effectiveDate 1 dateTime
+	manufacturingBusinessOperation.confidentialityIndicator 1 CodeableConcept
+	manufacturingBusinessOperation.manufacturer * Reference
+	manufacturingBusinessOperation.regulator 1 Reference
+	specialDesignation * BackboneElement
+	specialDesignation.identifier * Identifier
+	specialDesignation.type 1 CodeableConcept
+	specialDesignation.intendedUse 1 CodeableConcept
+	specialDesignation.indication[x] 1 CodeableConcept|Reference
+	specialDesignation.status 1 CodeableConcept
+	specialDesignation.date 1 dateTime
+	specialDesignation.species 1 CodeableConcept
+
+MedicinalProductAuthorization
+	identifier * Identifier
+	subject 1 Reference
+	country * CodeableConcept
+	jurisdiction * CodeableConcept
+	status 1 CodeableConcept
+	statusDate 1 dateTime
+	restoreDate 1 dateTime
+	validityPeriod 1 Period
+	dataExclusivityPeriod 1 Period
+	dateOfFirstAuthorization 1 dateTime
+	internationalBirthDate 1 dateTime
+	legalBasis 1 CodeableConcept
+	jurisdictionalAuthorization * BackboneElement
+	jurisdictionalAuthorization.identifier * Identifier
+	jurisdictionalAuthorization.country 1 CodeableConcept
+	jurisdictionalAuthorization.jurisdiction * CodeableConcept
+	jurisdictionalAuthorization.legalStatusOfSupply 1 CodeableConcept
+	jurisdictionalAuthorization.validityPeriod 1 Period
+	holder 1 Reference
+	regulator 1 Reference
+	procedure 1 BackboneElement
+	procedure.identifier 1 Identifier
+	procedure.type 1 CodeableConcept
+	procedure.date[x] 1 Period|dateTime
+	procedure.application * #MedicinalProductAuthorization.procedure
+
+MedicinalProductContraindication
+	subject * Reference
+	disease 1 CodeableConcept
+	diseaseStatus 1 CodeableConcept
+	comorbidity * CodeableConcept
+	therapeuticIndication * Reference
+	otherTherapy * BackboneElement
+	otherTherapy.therapyRelationshipType 1 CodeableConcept
+	otherTherapy.medication[x] 1 CodeableConcept|Reference
+	population * Population
+
+MedicinalProductIndication
+	subject * Reference
+	diseaseSymptomProcedure 1 CodeableConcept
+	diseaseStatus 1 CodeableConcept
+	comorbidity * CodeableConcept
+	intendedEffect 1 CodeableConcept
+	duration 1 Quantity
+	otherTherapy * BackboneElement
+	otherTherapy.therapyRelationshipType 1 CodeableConcept
+	otherTherapy.medication[x] 1 CodeableConcept|Reference
+	undesirableEffect * Reference
+	population * Population
+
+MedicinalProductIngredient
+	identifier 1 Identifier
+	role 1 CodeableConcept
+	allergenicIndicator 1 boolean
+	manufacturer * Reference
+	specifiedSubstance * BackboneElement
+	specifiedSubstance.code 1 CodeableConcept
+	specifiedSubstance.group 1 CodeableConcept
+	specifiedSubstance.confidentiality 1 CodeableConcept
+	specifiedSubstance.strength * BackboneElement
+	specifiedSubstance.strength.presentation 1 Ratio
+	specifiedSubstance.strength.presentationLowLimit 1 Ratio
+	specifiedSubstance.strength.concentration 1 Ratio
+	specifiedSubstance.strength.concentrationLowLimit 1 Ratio
+	specifiedSubstance.strength.measurementPoint 1 string
+	specifiedSubstance.strength.country * CodeableConcept
+	specifiedSubstance.strength.referenceStrength * BackboneElement
+	specifiedSubstance.strength.referenceStrength.substance 1 CodeableConcept
+	specifiedSubstance.strength.referenceStrength.strength 1 Ratio
+	specifiedSubstance.strength.referenceStrength.strengthLowLimit 1 Ratio
+	specifiedSubstance.strength.referenceStrength.measurementPoint 1 string
+	specifiedSubstance.strength.referenceStrength.country * CodeableConcept
+	substance 1 BackboneElement
+	substance.code 1 CodeableConcept
+	substance.strength * #MedicinalProductIngredient.specifiedSubstance.strength
+
+MedicinalProductInteraction
+	subject * Reference
+	description 1 string
+	interactant * BackboneElement
+	interactant.item[x] 1 Reference|CodeableConcept
+	type 1 CodeableConcept
+	effect 1 CodeableConcept
+	incidence 1 CodeableConcept
+	management 1 CodeableConcept
+
+MedicinalProductManufactured
+	manufacturedDoseForm 1 CodeableConcept
+	unitOfPresentation 1 CodeableConcept
+	quantity 1 Quantity
+	manufacturer * Reference
+	ingredient * Reference
+	physicalCharacteristics 1 ProdCharacteristic
+	otherCharacteristics * CodeableConcept
+
+MedicinalProductPackaged
+	identifier * Identifier
+	subject * Reference
+	description 1 string
+	legalStatusOfSupply 1 CodeableConcept
+	marketingStatus * MarketingStatus
+	marketingAuthorization 1 Reference
+	manufacturer * Reference
+	batchIdentifier * BackboneElement
+	batchIdentifier.outerPackaging 1 Identifier
+	batchIdentifier.immediatePackaging 1 Identifier
+	packageItem * BackboneElement
+	packageItem.identifier * Identifier
+	packageItem.type 1 CodeableConcept
+	packageItem.quantity 1 Quantity
+	packageItem.material * CodeableConcept
+	packageItem.alternateMaterial * CodeableConcept
+	packageItem.device * Reference
+	packageItem.manufacturedItem * Reference
+	packageItem.packageItem * #MedicinalProductPackaged.packageItem
+	packageItem.physicalCharacteristics 1 ProdCharacteristic
+	packageItem.otherCharacteristics * CodeableConcept
+	packageItem.shelfLifeStorage * ProductShelfLife
+	packageItem.manufacturer * Reference
+
+MedicinalProductPharmaceutical
+	identifier * Identifier
+	administrableDoseForm 1 CodeableConcept
+	unitOfPresentation 1 CodeableConcept
+	ingredient * Reference
+	device * Reference
+	characteristics * BackboneElement
+	characteristics.code 1 CodeableConcept
+	characteristics.status 1 CodeableConcept
+	routeOfAdministration * BackboneElement
+	routeOfAdministration.code 1 CodeableConcept
+	routeOfAdministration.firstDose 1 Quantity
+	routeOfAdministration.maxSingleDose 1 Quantity
+	routeOfAdministration.maxDosePerDay 1 Quantity
+	routeOfAdministration.maxDosePerTreatmentPeriod 1 Ratio
+	routeOfAdministration.maxTreatmentPeriod 1 Duration
+	routeOfAdministration.targetSpecies * BackboneElement
+	routeOfAdministration.targetSpecies.code 1 CodeableConcept
+	routeOfAdministration.targetSpecies.withdrawalPeriod * BackboneElement
+	routeOfAdministration.targetSpecies.withdrawalPeriod.tissue 1 CodeableConcept
+	routeOfAdministration.targetSpecies.withdrawalPeriod.value 1 Quantity
+	routeOfAdministration.targetSpecies.withdrawalPeriod.supportingInformation 1 string
+
+MedicinalProductUndesirableEffect
+	subject * Reference
+	symptomConditionEffect 1 CodeableConcept
+	classification 1 CodeableConcept
+	frequencyOfOccurrence 1 CodeableConcept
+	population * Population
+
+MessageDefinition
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	replaces * canonical
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	copyright 1 markdown
+	base 1 canonical
+	parent * canonical
+	event[x] 1 Coding|uri
+	category 1 code
+	focus * BackboneElement
+	focus.code 1 code
+	focus.profile 1 canonical
+	focus.min 1 unsignedInt
+	focus.max 1 string
+	responseRequired 1 code
+	allowedResponse * BackboneElement
+	allowedResponse.message 1 canonical
+	allowedResponse.situation 1 markdown
+	graph * canonical
+
+MessageHeader
+	event[x] 1 Coding|uri
+	destination * BackboneElement
+	destination.name 1 string
+	destination.target 1 Reference
+	destination.endpoint 1 url
+	destination.receiver 1 Reference
+	sender 1 Reference
+	enterer 1 Reference
+	author 1 Reference
+	source 1 BackboneElement
+	source.name 1 string
+	source.software 1 string
+	source.version 1 string
+	source.contact 1 ContactPoint
+	source.endpoint 1 url
+	responsible 1 Reference
+	reason 1 CodeableConcept
+	response 1 BackboneElement
+	response.identifier 1 id
+	response.code 1 code
+	response.details 1 Reference
+	focus * Reference
+	definition 1 canonical
+
+MolecularSequence
+	identifier * Identifier
+	type 1 code
+	coordinateSystem 1 integer
+	patient 1 Reference
+	specimen 1 Reference
+	device 1 Reference
+	performer 1 Reference
+	quantity 1 Quantity
+	referenceSeq 1 BackboneElement
+	referenceSeq.chromosome 1 CodeableConcept
+	referenceSeq.genomeBuild 1 string
+	referenceSeq.orientation 1 code
+	referenceSeq.referenceSeqId 1 CodeableConcept
+	referenceSeq.referenceSeqPointer 1 Reference
+	referenceSeq.referenceSeqString 1 string
+	referenceSeq.strand 1 code
+	referenceSeq.windowStart 1 integer
+	referenceSeq.windowEnd 1 integer
+	variant * BackboneElement
+	variant.start 1 integer
+	variant.end 1 integer
+	variant.observedAllele 1 string
+	variant.referenceAllele 1 string
+	variant.cigar 1 string
+	variant.variantPointer 1 Reference
+	observedSeq 1 string
+	quality * BackboneElement
+	quality.type 1 code
+	quality.standardSequence 1 CodeableConcept
+	quality.start 1 integer
+	quality.end 1 integer
+	quality.score 1 Quantity
+	quality.method 1 CodeableConcept
+	quality.truthTP 1 decimal
+	quality.queryTP 1 decimal
+	quality.truthFN 1 decimal
+	quality.queryFP 1 decimal
+	quality.gtFP 1 decimal
+	quality.precision 1 decimal
+	quality.recall 1 decimal
+	quality.fScore 1 decimal
+	quality.roc 1 BackboneElement
+	quality.roc.score * integer
+	quality.roc.numTP * integer
+	quality.roc.numFP * integer
+	quality.roc.numFN * integer
+	quality.roc.precision * decimal
+	quality.roc.sensitivity * decimal
+	quality.roc.fMeasure * decimal
+	readCoverage 1 integer
+	repository * BackboneElement
+	repository.type 1 code
+	repository.url 1 uri
+	repository.name 1 string
+	repository.datasetId 1 string
+	repository.variantsetId 1 string
+	repository.readsetId 1 string
+	pointer * Reference
+	structureVariant * BackboneElement
+	structureVariant.variantType 1 CodeableConcept
+	structureVariant.exact 1 boolean
+	structureVariant.length 1 integer
+	structureVariant.outer 1 BackboneElement
+	structureVariant.outer.start 1 integer
+	structureVariant.outer.end 1 integer
+	structureVariant.inner 1 BackboneElement
+	structureVariant.inner.start 1 integer
+	structureVariant.inner.end 1 integer
+
+NamingSystem
+	name 1 string
+	status 1 code
+	kind 1 code
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	responsible 1 string
+	type 1 CodeableConcept
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	usage 1 string
+	uniqueId * BackboneElement
+	uniqueId.type 1 code
+	uniqueId.value 1 string
+	uniqueId.preferred 1 boolean
+	uniqueId.comment 1 string
+	uniqueId.period 1 Period
+
+NutritionOrder
+	identifier * Identifier
+	instantiatesCanonical * canonical
+	instantiatesUri * uri
+	instantiates * uri
+	status 1 code
+	intent 1 code
+	patient 1 Reference
+	encounter 1 Reference
+	dateTime 1 dateTime
+	orderer 1 Reference
+	allergyIntolerance * Reference
+	foodPreferenceModifier * CodeableConcept
+	excludeFoodModifier * CodeableConcept
+	oralDiet 1 BackboneElement
+	oralDiet.type * CodeableConcept
+	oralDiet.schedule * Timing
+	oralDiet.nutrient * BackboneElement
+	oralDiet.nutrient.modifier 1 CodeableConcept
+	oralDiet.nutrient.amount 1 Quantity
+	oralDiet.texture * BackboneElement
+	oralDiet.texture.modifier 1 CodeableConcept
+	oralDiet.texture.foodType 1 CodeableConcept
+	oralDiet.fluidConsistencyType * CodeableConcept
+	oralDiet.instruction 1 string
+	supplement * BackboneElement
+	supplement.type 1 CodeableConcept
+	supplement.productName 1 string
+	supplement.schedule * Timing
+	supplement.quantity 1 Quantity
+	supplement.instruction 1 string
+	enteralFormula 1 BackboneElement
+	enteralFormula.baseFormulaType 1 CodeableConcept
+	enteralFormula.baseFormulaProductName 1 string
+	enteralFormula.additiveType 1 CodeableConcept
+	enteralFormula.additiveProductName 1 string
+	enteralFormula.caloricDensity 1 Quantity
+	enteralFormula.routeofAdministration 1 CodeableConcept
+	enteralFormula.administration * BackboneElement
+	enteralFormula.administration.schedule 1 Timing
+	enteralFormula.administration.quantity 1 Quantity
+	enteralFormula.administration.rate[x] 1 Quantity|Ratio
+	enteralFormula.maxVolumeToDeliver 1 Quantity
+	enteralFormula.administrationInstruction 1 string
+	note * Annotation
+
+Observation
+	identifier * Identifier
+	basedOn * Reference
+	partOf * Reference
+	status 1 code
+	category * CodeableConcept
+	code 1 CodeableConcept
+	subject 1 Reference
+	focus * Reference
+	encounter 1 Reference
+	effective[x] 1 dateTime|Period|Timing|instant
+	issued 1 instant
+	performer * Reference
+	value[x] 1 Quantity|CodeableConcept|string|boolean|integer|Range|Ratio|SampledData|time|dateTime|Period
+	dataAbsentReason 1 CodeableConcept
+	interpretation * CodeableConcept
+	note * Annotation
+	bodySite 1 CodeableConcept
+	method 1 CodeableConcept
+	specimen 1 Reference
+	device 1 Reference
+	referenceRange * BackboneElement
+	referenceRange.low 1 Quantity
+	referenceRange.high 1 Quantity
+	referenceRange.type 1 CodeableConcept
+	referenceRange.appliesTo * CodeableConcept
+	referenceRange.age 1 Range
+	referenceRange.text 1 string
+	hasMember * Reference
+	derivedFrom * Reference
+	component * BackboneElement
+	component.code 1 CodeableConcept
+	component.value[x] 1 Quantity|CodeableConcept|string|boolean|integer|Range|Ratio|SampledData|time|dateTime|Period
+	component.dataAbsentReason 1 CodeableConcept
+	component.interpretation * CodeableConcept
+	component.referenceRange * #Observation.referenceRange
+
+ObservationDefinition
+	category * CodeableConcept
+	code 1 CodeableConcept
+	identifier * Identifier
+	permittedDataType * code
+	multipleResultsAllowed 1 boolean
+	method 1 CodeableConcept
+	preferredReportName 1 string
+	quantitativeDetails 1 BackboneElement
+	quantitativeDetails.customaryUnit 1 CodeableConcept
+	quantitativeDetails.unit 1 CodeableConcept
+	quantitativeDetails.conversionFactor 1 decimal
+	quantitativeDetails.decimalPrecision 1 integer
+	qualifiedInterval * BackboneElement
+	qualifiedInterval.category 1 code
+	qualifiedInterval.range 1 Range
+	qualifiedInterval.context 1 CodeableConcept
+	qualifiedInterval.appliesTo * CodeableConcept
+	qualifiedInterval.gender 1 code
+	qualifiedInterval.age 1 Range
+	qualifiedInterval.gestationalAge 1 Range
+	qualifiedInterval.condition 1 string
+	validCodedValueSet 1 Reference
+	normalCodedValueSet 1 Reference
+	abnormalCodedValueSet 1 Reference
+	criticalCodedValueSet 1 Reference
+
+OperationDefinition
+	url 1 uri
+	version 1 string
+	name 1 string
+	title 1 string
+	status 1 code
+	kind 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	affectsState 1 boolean
+	code 1 code
+	comment 1 markdown
+	base 1 canonical
+	resource * code
+	system 1 boolean
+	type 1 boolean
+	instance 1 boolean
+	inputProfile 1 canonical
+	outputProfile 1 canonical
+	parameter * BackboneElement
+	parameter.name 1 code
+	parameter.use 1 code
+	parameter.min 1 integer
+	parameter.max 1 string
+	parameter.documentation 1 string
+	parameter.type 1 code
+	parameter.targetProfile * canonical
+	parameter.searchType 1 code
+	parameter.binding 1 BackboneElement
+	parameter.binding.strength 1 code
+	parameter.binding.valueSet 1 canonical
+	parameter.referencedFrom * BackboneElement
+	parameter.referencedFrom.source 1 string
+	parameter.referencedFrom.sourceId 1 string
+	parameter.part * #OperationDefinition.parameter
+	overload * BackboneElement
+	overload.parameterName * string
+	overload.comment 1 string
+
+Organization
+	identifier * Identifier
+	active 1 boolean
+	type * CodeableConcept
+	name 1 string
+	alias * string
+	telecom * ContactPoint
+	address * Address
+	partOf 1 Reference
+	contact * BackboneElement
+	contact.purpose 1 CodeableConcept
+	contact.name 1 HumanName
+	contact.telecom * ContactPoint
+	contact.address 1 Address
+	endpoint * Reference
+
+OrganizationAffiliation
+	identifier * Identifier
+	active 1 boolean
+	period 1 Period
+	organization 1 Reference
+	participatingOrganization 1 Reference
+	network * Reference
+	code * CodeableConcept
+	specialty * CodeableConcept
+	location * Reference
+	healthcareService * Reference
+	telecom * ContactPoint
+	endpoint * Reference
+
+Patient
+	identifier * Identifier
+	active 1 boolean
+	name * HumanName
+	telecom * ContactPoint
+	gender 1 code
+	birthDate 1 date
+	deceased[x] 1 boolean|dateTime
+	address * Address
+	maritalStatus 1 CodeableConcept
+	multipleBirth[x] 1 boolean|integer
+	photo * Attachment
+	contact * BackboneElement
+	contact.relationship * CodeableConcept
+	contact.name 1 HumanName
+	contact.telecom * ContactPoint
+	contact.address 1 Address
+	contact.gender 1 code
+	contact.organization 1 Reference
+	contact.period 1 Period
+	communication * BackboneElement
+	communication.language 1 CodeableConcept
+	communication.preferred 1 boolean
+	generalPractitioner * Reference
+	managingOrganization 1 Reference
+	link * BackboneElement
+	link.other 1 Reference
+	link.type 1 code
+
+PaymentNotice
+	identifier * Identifier
+	status 1 code
+	request 1 Reference
+	response 1 Reference
+	created 1 dateTime
+	provider 1 Reference
+	payment 1 Reference
+	paymentDate 1 date
+	payee 1 Reference
+	recipient 1 Reference
+	amount 1 Money
+	paymentStatus 1 CodeableConcept
+
+PaymentReconciliation
+	identifier * Identifier
+	status 1 code
+	period 1 Period
+	created 1 dateTime
+	paymentIssuer 1 Reference
+	request 1 Reference
+	requestor 1 Reference
+	outcome 1 code
+	disposition 1 string
+	paymentDate 1 date
+	paymentAmount 1 Money
+	paymentIdentifier 1 Identifier
+	detail * BackboneElement
+	detail.identifier 1 Identifier
+	detail.predecessor 1 Identifier
+	detail.type 1 CodeableConcept
+	detail.request 1 Reference
+	detail.submitter 1 Reference
+	detail.response 1 Reference
+	detail.date 1 date
+	detail.responsible 1 Reference
+	detail.payee 1 Reference
+	detail.amount 1 Money
+	formCode 1 CodeableConcept
+	processNote * BackboneElement
+	processNote.type 1 code
+	processNote.text 1 string
+
+Person
+	identifier * Identifier
+	name * HumanName
+	telecom * ContactPoint
+	gender 1 code
+	birthDate 1 date
+	address * Address
+	photo 1 Attachment
+	managingOrganization 1 Reference
+	active 1 boolean
+	link * BackboneElement
+	link.target 1 Reference
+	link.assurance 1 code
+
+PlanDefinition
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	subtitle 1 string
+	type 1 CodeableConcept
+	status 1 code
+	experimental 1 boolean
+	subject[x] 1 CodeableConcept|Reference
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	usage 1 string
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	topic * CodeableConcept
+	author * ContactDetail
+	editor * ContactDetail
+	reviewer * ContactDetail
+	endorser * ContactDetail
+	relatedArtifact * RelatedArtifact
+	library * canonical
+	goal * BackboneElement
+	goal.category 1 CodeableConcept
+	goal.description 1 CodeableConcept
+	goal.priority 1 CodeableConcept
+	goal.start 1 CodeableConcept
+	goal.addresses * CodeableConcept
+	goal.documentation * RelatedArtifact
+	goal.target * BackboneElement
+	goal.target.measure 1 CodeableConcept
+	goal.target.detail[x] 1 Quantity|Range|CodeableConcept
+	goal.target.due 1 Duration
+	action * BackboneElement
+	action.prefix 1 string
+	action.title 1 string
+	action.description 1 string
+	action.textEquivalent 1 string
+	action.priority 1 code
+	action.code * CodeableConcept
+	action.reason * CodeableConcept
+	action.documentation * RelatedArtifact
+	action.goalId * id
+	action.subject[x] 1 CodeableConcept|Reference
+	action.trigger * TriggerDefinition
+	action.condition * BackboneElement
+	action.condition.kind 1 code
+	action.condition.expression 1 Expression
+	action.input * DataRequirement
+	action.output * DataRequirement
+	action.relatedAction * BackboneElement
+	action.relatedAction.actionId 1 id
+	action.relatedAction.relationship 1 code
+	action.relatedAction.offset[x] 1 Duration|Range
+	action.timing[x] 1 dateTime|Age|Period|Duration|Range|Timing
+	action.participant * BackboneElement
+	action.participant.type 1 code
+	action.participant.role 1 CodeableConcept
+	action.type 1 CodeableConcept
+	action.groupingBehavior 1 code
+	action.selectionBehavior 1 code
+	action.requiredBehavior 1 code
+	action.precheckBehavior 1 code
+	action.cardinalityBehavior 1 code
+	action.definition[x] 1 canonical|uri
+	action.transform 1 canonical
+	action.dynamicValue * BackboneElement
+	action.dynamicValue.path 1 string
+	action.dynamicValue.expression 1 Expression
+	action.action * #PlanDefinition.action
+
+Practitioner
+	identifier * Identifier
+	active 1 boolean
+	name * HumanName
+	telecom * ContactPoint
+	address * Address
+	gender 1 code
+	birthDate 1 date
+	photo * Attachment
+	qualification * BackboneElement
+	qualification.identifier * Identifier
+	qualification.code 1 CodeableConcept
+	qualification.period 1 Period
+	qualification.issuer 1 Reference
+	communication * CodeableConcept
+
+PractitionerRole
+	identifier * Identifier
+	active 1 boolean
+	period 1 Period
+	practitioner 1 Reference
+	organization 1 Reference
+	code * CodeableConcept
+	specialty * CodeableConcept
+	location * Reference
+	healthcareService * Reference
+	telecom * ContactPoint
+	availableTime * BackboneElement
+	availableTime.daysOfWeek * code
+	availableTime.allDay 1 boolean
+	availableTime.availableStartTime 1 time
+	availableTime.availableEndTime 1 time
+	notAvailable * BackboneElement
+	notAvailable.description 1 string
+	notAvailable.during 1 Period
+	availabilityExceptions 1 string
+	endpoint * Reference
+
+Procedure
+	identifier * Identifier
+	instantiatesCanonical * canonical
+	instantiatesUri * uri
+	basedOn * Reference
+	partOf * Reference
+	status 1 code
+	statusReason 1 CodeableConcept
+	category 1 CodeableConcept
+	code 1 CodeableConcept
+	subject 1 Reference
+	encounter 1 Reference
+	performed[x] 1 dateTime|Period|string|Age|Range
+	recorder 1 Reference
+	asserter 1 Reference
+	performer * BackboneElement
+	performer.function 1 CodeableConcept
+	performer.actor 1 Reference
+	performer.onBehalfOf 1 Reference
+	location 1 Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	bodySite * CodeableConcept
+	outcome 1 CodeableConcept
+	report * Reference
+	complication * CodeableConcept
+	complicationDetail * Reference
+	followUp * CodeableConcept
+	note * Annotation
+	focalDevice * BackboneElement
+	focalDevice.action 1 CodeableConcept
+	focalDevice.manipulated 1 Reference
+	usedReference * Reference
+	usedCode * CodeableConcept
+
+Provenance
+	target * Reference
+	occurred[x] 1 Period|dateTime
+	recorded 1 instant
+	policy * uri
+	location 1 Reference
+	reason * CodeableConcept
+	activity 1 CodeableConcept
+	agent * BackboneElement
+	agent.type 1 CodeableConcept
+	agent.role * CodeableConcept
+	agent.who 1 Reference
+	agent.onBehalfOf 1 Reference
+	entity * BackboneElement
+	entity.role 1 code
+	entity.what 1 Reference
+	entity.agent * #Provenance.agent
+	signature * Signature
+
+Questionnaire
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	derivedFrom * canonical
+	status 1 code
+	experimental 1 boolean
+	subjectType * code
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	code * Coding
+	item * BackboneElement
+	item.linkId 1 string
+	item.definition 1 uri
+	item.code * Coding
+	item.prefix 1 string
+	item.text 1 string
+	item.type 1 code
+	item.enableWhen * BackboneElement
+	item.enableWhen.question 1 string
+	item.enableWhen.operator 1 code
+	item.enableWhen.answer[x] 1 boolean|decimal|integer|date|dateTime|time|string|Coding|Quantity|Reference
+	item.enableBehavior 1 code
+	item.required 1 boolean
+	item.repeats 1 boolean
+	item.readOnly 1 boolean
+	item.maxLength 1 integer
+	item.answerValueSet 1 canonical
+	item.answerOption * BackboneElement
+	item.answerOption.value[x] 1 integer|date|time|string|Coding|Reference
+	item.answerOption.initialSelected 1 boolean
+	item.initial * BackboneElement
+	item.initial.value[x] 1 boolean|decimal|integer|date|dateTime|time|string|uri|Attachment|Coding|Quantity|Reference
+	item.item * #Questionnaire.item
+
+QuestionnaireResponse
+	identifier 1 Identifier
+	basedOn * Reference
+	partOf * Reference
+	questionnaire 1 canonical
+	status 1 code
+	subject 1 Reference
+	encounter 1 Reference
+	authored 1 dateTime
+	author 1 Reference
+	source 1 Reference
+	item * BackboneElement
+	item.linkId 1 string
+	item.definition 1 uri
+	item.text 1 string
+	item.answer * BackboneElement
+	item.answer.value[x] 1 boolean|decimal|integer|date|dateTime|time|string|uri|Attachment|Coding|Quantity|Reference
+	item.answer.item * #QuestionnaireResponse.item
+	item.item * #QuestionnaireResponse.item
+
+RelatedPerson
+	identifier * Identifier
+	active 1 boolean
+	patient 1 Reference
+	relationship * CodeableConcept
+	name * HumanName
+	telecom * ContactPoint
+	gender 1 code
+	birthDate 1 date
+	address * Address
+	photo * Attachment
+	period 1 Period
+	communication * BackboneElement
+	communication.language 1 CodeableConcept
+	communication.preferred 1 boolean
+
+RequestGroup
+	identifier * Identifier
+	instantiatesCanonical * canonical
+	instantiatesUri * uri
+	basedOn * Reference
+	replaces * Reference
+	groupIdentifier 1 Identifier
+	status 1 code
+	intent 1 code
+	priority 1 code
+	code 1 CodeableConcept
+	subject 1 Reference
+	encounter 1 Reference
+	authoredOn 1 dateTime
+	author 1 Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	note * Annotation
+	action * BackboneElement
+	action.prefix 1 string
+	action.title 1 string
+	action.description 1 string
+	action.textEquivalent 1 string
+	action.priority 1 code
+	action.code * CodeableConcept
+	action.documentation * RelatedArtifact
+	action.condition * BackboneElement
+	action.condition.kind 1 code
+	action.condition.expression 1 Expression
+	action.relatedAction * BackboneElement
+	action.relatedAction.actionId 1 id
+	action.relatedAction.relationship 1 code
+	action.relatedAction.offset[x] 1 Duration|Range
+	action.timing[x] 1 dateTime|Age|Period|Duration|Range|Timing
+	action.participant * Reference
+	action.type 1 CodeableConcept
+	action.groupingBehavior 1 code
+	action.selectionBehavior 1 code
+	action.requiredBehavior 1 code
+	action.precheckBehavior 1 code
+	action.cardinalityBehavior 1 code
+	action.resource 1 Reference
+	action.action * #RequestGroup.action
+
+ResearchDefinition
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	shortTitle 1 string
+	subtitle 1 string
+	status 1 code
+	experimental 1 boolean
+	subject[x] 1 CodeableConcept|Reference
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	comment * string
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	usage 1 string
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	topic * CodeableConcept
+	author * ContactDetail
+	editor * ContactDetail
+	reviewer * ContactDetail
+	endorser * ContactDetail
+	relatedArtifact * RelatedArtifact
+	library * canonical
+	population 1 Reference
+	exposure 1 Reference
+	exposureAlternative 1 Reference
+	outcome 1 Reference
+
+ResearchElementDefinition
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	shortTitle 1 string
+	subtitle 1 string
+	status 1 code
+	experimental 1 boolean
+	subject[x] 1 CodeableConcept|Reference
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	comment * string
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	usage 1 string
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	topic * CodeableConcept
+	author * ContactDetail
+	editor * ContactDetail
+	reviewer * ContactDetail
+	endorser * ContactDetail
+	relatedArtifact * RelatedArtifact
+	library * canonical
+	type 1 code
+	variableType 1 code
+	characteristic * BackboneElement
+	characteristic.definition[x] 1 CodeableConcept|canonical|Expression|DataRequirement
+	characteristic.usageContext * UsageContext
+	characteristic.exclude 1 boolean
+	characteristic.unitOfMeasure 1 CodeableConcept
+	characteristic.studyEffectiveDescription 1 string
+	characteristic.studyEffective[x] 1 dateTime|Period|Duration|Timing
+	characteristic.studyEffectiveTimeFromStart 1 Duration
+	characteristic.studyEffectiveGroupMeasure 1 code
+	characteristic.participantEffectiveDescription 1 string
+	characteristic.participantEffective[x] 1 dateTime|Period|Duration|Timing
+	characteristic.participantEffectiveTimeFromStart 1 Duration
+	characteristic.participantEffectiveGroupMeasure 1 code
+
+ResearchStudy
+	identifier * Identifier
+	title 1 string
+	protocol * Reference
+	partOf * Reference
+	status 1 code
+	primaryPurposeType 1 CodeableConcept
+	phase 1 CodeableConcept
+	category * CodeableConcept
+	focus * CodeableConcept
+	condition * CodeableConcept
+	contact * ContactDetail
+	relatedArtifact * RelatedArtifact
+	keyword * CodeableConcept
+	location * CodeableConcept
+	description 1 markdown
+	enrollment * Reference
+	period 1 Period
+	sponsor 1 Reference
+	principalInvestigator 1 Reference
+	site * Reference
+	reasonStopped 1 CodeableConcept
+	note * Annotation
+	arm * BackboneElement
+	arm.name 1 string
+	arm.type 1 CodeableConcept
+	arm.description 1 string
+	objective * BackboneElement
+	objective.name 1 string
+	objective.type 1 CodeableConcept
+
+ResearchSubject
+	identifier * Identifier
+	status 1 code
+	period 1 Period
+	study 1 Reference
+	individual 1 Reference
+	assignedArm 1 string
+	actualArm 1 string
+	consent 1 Reference
+
+RiskAssessment
+	identifier * Identifier
+	basedOn 1 Reference
+	parent 1 Reference
+	status 1 code
+	method 1 CodeableConcept
+	code 1 CodeableConcept
+	subject 1 Reference
+	encounter 1 Reference
+	occurrence[x] 1 dateTime|Period
+	condition 1 Reference
+	performer 1 Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	basis * Reference
+	prediction * BackboneElement
+	prediction.outcome 1 CodeableConcept
+	prediction.probability[x] 1 decimal|Range
+	prediction.qualitativeRisk 1 CodeableConcept
+	prediction.relativeRisk 1 decimal
+	prediction.when[x] 1 Period|Range
+	prediction.rationale 1 string
+	mitigation 1 string
+	note * Annotation
+
+RiskEvidenceSynthesis
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	status 1 code
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	note * Annotation
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	copyright 1 markdown
+	approvalDate 1 date
+	lastReviewDate 1 date
+	effectivePeriod 1 Period
+	topic * CodeableConcept
+	author * ContactDetail
+	editor * ContactDetail
+	reviewer * ContactDetail
+	endorser * ContactDetail
+	relatedArtifact * RelatedArtifact
+	synthesisType 1 CodeableConcept
+	studyType 1 CodeableConcept
+	population 1 Reference
+	exposure 1 Reference
+	outcome 1 Reference
+	sampleSize 1 BackboneElement
+	sampleSize.description 1 string
+	sampleSize.numberOfStudies 1 integer
+	sampleSize.numberOfParticipants 1 integer
+	riskEstimate 1 BackboneElement
+	riskEstimate.description 1 string
+	riskEstimate.type 1 CodeableConcept
+	riskEstimate.value 1 decimal
+	riskEstimate.unitOfMeasure 1 CodeableConcept
+	riskEstimate.denominatorCount 1 integer
+	riskEstimate.numeratorCount 1 integer
+	riskEstimate.precisionEstimate * BackboneElement
+	riskEstimate.precisionEstimate.type 1 CodeableConcept
+	riskEstimate.precisionEstimate.level 1 decimal
+	riskEstimate.precisionEstimate.from 1 decimal
+	riskEstimate.precisionEstimate.to 1 decimal
+	certainty * BackboneElement
+	certainty.rating * CodeableConcept
+	certainty.note * Annotation
+	certainty.certaintySubcomponent * BackboneElement
+	certainty.certaintySubcomponent.type 1 CodeableConcept
+	certainty.certaintySubcomponent.rating * CodeableConcept
+	certainty.certaintySubcomponent.note * Annotation
+
+Schedule
+	identifier * Identifier
+	active 1 boolean
+	serviceCategory * CodeableConcept
+	serviceType * CodeableConcept
+	specialty * CodeableConcept
+	actor * Reference
+	planningHorizon 1 Period
+	comment 1 string
+
+SearchParameter
+	url 1 uri
+	version 1 string
+	name 1 string
+	derivedFrom 1 canonical
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	code 1 code
+	base * code
+	type 1 code
+	expression 1 string
+	xpath 1 string
+	xpathUsage 1 code
+	target * code
+	multipleOr 1 boolean
+	multipleAnd 1 boolean
+	comparator * code
+	modifier * code
+	chain * string
+	component * BackboneElement
+	component.definition 1 canonical
+	component.expression 1 string
+
+ServiceRequest
+	identifier * Identifier
+	instantiatesCanonical * canonical
+	instantiatesUri * uri
+	basedOn * Reference
+	replaces * Reference
+	requisition 1 Identifier
+	status 1 code
+	intent 1 code
+	category * CodeableConcept
+	priority 1 code
+	doNotPerform 1 boolean
+	code 1 CodeableConcept
+	orderDetail * CodeableConcept
+	quantity[x] 1 Quantity|Ratio|Range
+	subject 1 Reference
+	encounter 1 Reference
+	occurrence[x] 1 dateTime|Period|Timing
+	asNeeded[x] 1 boolean|CodeableConcept
+	authoredOn 1 dateTime
+	requester 1 Reference
+	performerType 1 CodeableConcept
+	performer * Reference
+	locationCode * CodeableConcept
+	locationReference * Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	insurance * Reference
+	supportingInfo * Reference
+	specimen * Reference
+	bodySite * CodeableConcept
+	note * Annotation
+	patientInstruction 1 string
+	relevantHistory * Reference
+
+Slot
+	identifier * Identifier
+	serviceCategory * CodeableConcept
+	serviceType * CodeableConcept
+	specialty * CodeableConcept
+	appointmentType 1 CodeableConcept
+	schedule 1 Reference
+	status 1 code
+	start 1 instant
+	end 1 instant
+	overbooked 1 boolean
+	comment 1 string
+
+Specimen
+	identifier * Identifier
+	accessionIdentifier 1 Identifier
+	status 1 code
+	type 1 CodeableConcept
+	subject 1 Reference
+	receivedTime 1 dateTime
+	parent * Reference
+	request * Reference
+	collection 1 BackboneElement
+	collection.collector 1 Reference
+	collection.collected[x] 1 dateTime|Period
+	collection.duration 1 Duration
+	collection.quantity 1 Quantity
+	collection.method 1 CodeableConcept
+	collection.bodySite 1 CodeableConcept
+	collection.fastingStatus[x] 1 CodeableConcept|Duration
+	processing * BackboneElement
+	processing.description 1 string
+	processing.procedure 1 CodeableConcept
+	processing.additive * Reference
+	processing.time[x] 1 dateTime|Period
+	container * BackboneElement
+	container.identifier * Identifier
+	container.description 1 string
+	container.type 1 CodeableConcept
+	container.capacity 1 Quantity
+	container.specimenQuantity 1 Quantity
+	container.additive[x] 1 CodeableConcept|Reference
+	condition * CodeableConcept
+	note * Annotation
+
+SpecimenDefinition
+	identifier 1 Identifier
+	typeCollected 1 CodeableConcept
+	patientPreparation * CodeableConcept
+	timeAspect 1 string
+	collection * CodeableConcept
+	typeTested * BackboneElement
+	typeTested.isDerived 1 boolean
+	typeTested.type 1 CodeableConcept
+	typeTested.preference 1 code
+	typeTested.container 1 BackboneElement
+	typeTested.container.material 1 CodeableConcept
+	typeTested.container.type 1 CodeableConcept
+	typeTested.container.cap 1 CodeableConcept
+	typeTested.container.description 1 string
+	typeTested.container.capacity 1 Quantity
+	typeTested.container.minimumVolume[x] 1 Quantity|string
+	typeTested.container.additive * BackboneElement
+	typeTested.container.additive.additive[x] 1 CodeableConcept|Reference
+	typeTested.container.preparation 1 string
+	typeTested.requirement 1 string
+	typeTested.retentionTime 1 Duration
+	typeTested.rejectionCriterion * CodeableConcept
+	typeTested.handling * BackboneElement
+	typeTested.handling.temperatureQualifier 1 CodeableConcept
+	typeTested.handling.temperatureRange 1 Range
+	typeTested.handling.maxDuration 1 Duration
+	typeTested.handling.instruction 1 string
+
+StructureDefinition
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	copyright 1 markdown
+	keyword * Coding
+	fhirVersion 1 code
+	mapping * BackboneElement
+	mapping.identity 1 id
+	mapping.uri 1 uri
+	mapping.name 1 string
+	mapping.comment 1 string
+	kind 1 code
+	abstract 1 boolean
+	context * BackboneElement
+	context.type 1 code
+	context.expression 1 string
+	contextInvariant * string
+	type 1 uri
+	baseDefinition 1 canonical
+	derivation 1 code
+	snapshot 1 BackboneElement
+	snapshot.element * ElementDefinition
+	differential 1 BackboneElement
+	differential.element * ElementDefinition
+
+StructureMap
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	copyright 1 markdown
+	structure * BackboneElement
+	structure.url 1 canonical
+	structure.mode 1 code
+	structure.alias 1 string
+	structure.documentation 1 string
+	import * canonical
+	group * BackboneElement
+	group.name 1 id
+	group.extends 1 id
+	group.typeMode 1 code
+	group.documentation 1 string
+	group.input * BackboneElement
+	group.input.name 1 id
+	group.input.type 1 string
+	group.input.mode 1 code
+	group.input.documentation 1 string
+	group.rule * BackboneElement
+	group.rule.name 1 id
+	group.rule.source * BackboneElement
+	group.rule.source.context 1 id
+	group.rule.source.min 1 integer
+	group.rule.source.max 1 string
+	group.rule.source.type 1 string
+	group.rule.source.defaultValue[x] 1 base64Binary|boolean|canonical|code|date|dateTime|decimal|id|instant|integer|markdown|oid|positiveInt|string|time|unsignedInt|uri|url|uuid|Address|Age|Annotation|Attachment|CodeableConcept|Coding|ContactPoint|Count|Distance|Duration|HumanName|Identifier|Money|Period|Quantity|Range|Ratio|Reference|SampledData|Signature|Timing|ContactDetail|Contributor|DataRequirement|Expression|ParameterDefinition|RelatedArtifact|TriggerDefinition|UsageContext|Dosage|Meta
+	group.rule.source.element 1 string
+	group.rule.source.listMode 1 code
+	group.rule.source.variable 1 id
+	group.rule.source.condition 1 string
+	group.rule.source.check 1 string
+	group.rule.source.logMessage 1 string
+	group.rule.target * BackboneElement
+	group.rule.target.context 1 id
+	group.rule.target.contextType 1 code
+	group.rule.target.element 1 string
+	group.rule.target.variable 1 id
+	group.rule.target.listMode * code
+	group.rule.target.listRuleId 1 id
+	group.rule.target.transform 1 code
+	group.rule.target.parameter * BackboneElement
+	group.rule.target.parameter.value[x] 1 id|string|boolean|integer|decimal
+	group.rule.rule * #StructureMap.group.rule
+	group.rule.dependent * BackboneElement
+	group.rule.dependent.name 1 id
+	group.rule.dependent.variable * string
+	group.rule.documentation 1 string
+
+Subscription
+	status 1 code
+	contact * ContactPoint
+	end 1 instant
+	reason 1 string
+	criteria 1 string
+	error 1 string
+	channel 1 BackboneElement
+	channel.type 1 code
+	channel.endpoint 1 url
+	channel.payload 1 code
+	channel.header * string
+
+Substance
+	identifier * Identifier
+	status 1 code
+	category * CodeableConcept
+	code 1 CodeableConcept
+	description 1 string
+	instance * BackboneElement
+	instance.identifier 1 Identifier
+	instance.expiry 1 dateTime
+	instance.quantity 1 Quantity
+	ingredient * BackboneElement
+	ingredient.quantity 1 Ratio
+	ingredient.substance[x] 1 CodeableConcept|Reference
+
+SubstanceNucleicAcid
+	sequenceType 1 CodeableConcept
+	numberOfSubunits 1 integer
+	areaOfHybridisation 1 string
+	oligoNucleotideType 1 CodeableConcept
+	subunit * BackboneElement
+	subunit.subunit 1 integer
+	subunit.sequence 1 string
+	subunit.length 1 integer
+	subunit.sequenceAttachment 1 Attachment
+	subunit.fivePrime 1 CodeableConcept
+	subunit.threePrime 1 CodeableConcept
+	subunit.linkage * BackboneElement
+	subunit.linkage.connectivity 1 string
+	subunit.linkage.identifier 1 Identifier
+	subunit.linkage.name 1 string
+	subunit.linkage.residueSite 1 string
+	subunit.sugar * BackboneElement
+	subunit.sugar.identifier 1 Identifier
+	subunit.sugar.name 1 string
+	subunit.sugar.residueSite 1 string
+
+SubstancePolymer
+	class 1 CodeableConcept
+	geometry 1 CodeableConcept
+	copolymerConnectivity * CodeableConcept
+	modification * string
+	monomerSet * BackboneElement
+	monomerSet.ratioType 1 CodeableConcept
+	monomerSet.startingMaterial * BackboneElement
+	monomerSet.startingMaterial.material 1 CodeableConcept
+	monomerSet.startingMaterial.type 1 CodeableConcept
+	monomerSet.startingMaterial.isDefining 1 boolean
+	monomerSet.startingMaterial.amount 1 SubstanceAmount
+	repeat * BackboneElement
+	repeat.numberOfUnits 1 integer
+	repeat.averageMolecularFormula 1 string
+	repeat.repeatUnitAmountType 1 CodeableConcept
+	repeat.repeatUnit * BackboneElement
+	repeat.repeatUnit.orientationOfPolymerisation 1 CodeableConcept
+	repeat.repeatUnit.repeatUnit 1 string
+	repeat.repeatUnit.amount 1 SubstanceAmount
+	repeat.repeatUnit.degreeOfPolymerisation * BackboneElement
+	repeat.repeatUnit.degreeOfPolymerisation.degree 1 CodeableConcept
+	repeat.repeatUnit.degreeOfPolymerisation.amount 1 SubstanceAmount
+	repeat.repeatUnit.structuralRepresentation * BackboneElement
+	repeat.repeatUnit.structuralRepresentation.type 1 CodeableConcept
+	repeat.repeatUnit.structuralRepresentation.representation 1 string
+	repeat.repeatUnit.structuralRepresentation.attachment 1 Attachment
+
+SubstanceProtein
+	sequenceType 1 CodeableConcept
+	numberOfSubunits 1 integer
+	disulfideLinkage * string
+	subunit * BackboneElement
+	subunit.subunit 1 integer
+	subunit.sequence 1 string
+	subunit.length 1 integer
+	subunit.sequenceAttachment 1 Attachment
+	subunit.nTerminalModificationId 1 Identifier
+	subunit.nTerminalModification 1 string
+	subunit.cTerminalModificationId 1 Identifier
+	subunit.cTerminalModification 1 string
+
+SubstanceReferenceInformation
+	comment 1 string
+	gene * BackboneElement
+	gene.geneSequenceOrigin 1 CodeableConcept
+	gene.gene 1 CodeableConcept
+	gene.source * Reference
+	geneElement * BackboneElement
+	geneElement.type 1 CodeableConcept
+	geneElement.element 1 Identifier
+	geneElement.source * Reference
+	classification * BackboneElement
+	classification.domain 1 CodeableConcept
+	classification.classification 1 CodeableConcept
+	classification.subtype * CodeableConcept
+	classification.source * Reference
+	target * BackboneElement
+	target.target 1 Identifier
+	target.type 1 CodeableConcept
+	target.interaction 1 CodeableConcept
+	target.organism 1 CodeableConcept
+	target.organismType 1 CodeableConcept
+	target.amount[x] 1 Quantity|Range|string
+	target.amountType 1 CodeableConcept
+	target.source * Reference
+
+SubstanceSourceMaterial
+	sourceMaterialClass 1 CodeableConcept
+	sourceMaterialType 1 CodeableConcept
+	sourceMaterialState 1 CodeableConcept
+	organismId 1 Identifier
+	organismName 1 string
+	parentSubstanceId * Identifier
+	parentSubstanceName * string
+	countryOfOrigin * CodeableConcept
+	geographicalLocation * string
+	developmentStage 1 CodeableConcept
+	fractionDescription * BackboneElement
+	fractionDescription.fraction 1 string
+	fractionDescription.materialType 1 CodeableConcept
+	organism 1 BackboneElement
+	organism.family 1 CodeableConcept
+	organism.genus 1 CodeableConcept
+	organism.species 1 CodeableConcept
+	organism.intraspecificType 1 CodeableConcept
+	organism.intraspecificDescription 1 string
+	organism.author * BackboneElement
+	organism.author.authorType 1 CodeableConcept
+	organism.author.authorDescription 1 string
+	organism.hybrid 1 BackboneElement
+	organism.hybrid.maternalOrganismId 1 string
+	organism.hybrid.maternalOrganismName 1 string
+	organism.hybrid.paternalOrganismId 1 string
+	organism.hybrid.paternalOrganismName 1 string
+	organism.hybrid.hybridType 1 CodeableConcept
+	organism.organismGeneral 1 BackboneElement
+	organism.organismGeneral.kingdom 1 CodeableConcept
+	organism.organismGeneral.phylum 1 CodeableConcept
+	organism.organismGeneral.class 1 CodeableConcept
+	organism.organismGeneral.order 1 CodeableConcept
+	partDescription * BackboneElement
+	partDescription.part 1 CodeableConcept
+	partDescription.partLocation 1 CodeableConcept
+
+SubstanceSpecification
+	identifier 1 Identifier
+	type 1 CodeableConcept
+	status 1 CodeableConcept
+	domain 1 CodeableConcept
+	description 1 string
+	source * Reference
+	comment 1 string
+	moiety * BackboneElement
+	moiety.role 1 CodeableConcept
+	moiety.identifier 1 Identifier
+	moiety.name 1 string
+	moiety.stereochemistry 1 CodeableConcept
+	moiety.opticalActivity 1 CodeableConcept
+	moiety.molecularFormula 1 string
+	moiety.amount[x] 1 Quantity|string
+	property * BackboneElement
+	property.category 1 CodeableConcept
+	property.code 1 CodeableConcept
+	property.parameters 1 string
+	property.definingSubstance[x] 1 Reference|CodeableConcept
+	property.amount[x] 1 Quantity|string
+	referenceInformation 1 Reference
+	structure 1 BackboneElement
+	structure.stereochemistry 1 CodeableConcept
+	structure.opticalActivity 1 CodeableConcept
+	structure.molecularFormula 1 string
+	structure.molecularFormulaByMoiety 1 string
+	structure.isotope * BackboneElement
+	structure.isotope.identifier 1 Identifier
+	structure.isotope.name 1 CodeableConcept
+	structure.isotope.substitution 1 CodeableConcept
+	structure.isotope.halfLife 1 Quantity
+	structure.isotope.molecularWeight 1 BackboneElement
+	structure.isotope.molecularWeight.method 1 CodeableConcept
+	structure.isotope.molecularWeight.type 1 CodeableConcept
+	structure.isotope.molecularWeight.amount 1 Quantity
+	structure.molecularWeight 1 #SubstanceSpecification.structure.isotope.molecularWeight
+	structure.source * Reference
+	structure.representation * BackboneElement
+	structure.representation.type 1 CodeableConcept
+	structure.representation.representation 1 string
+	structure.representation.attachment 1 Attachment
+	code * BackboneElement
+	code.code 1 CodeableConcept
+	code.status 1 CodeableConcept
+	code.statusDate 1 dateTime
+	code.comment 1 string
+	code.source * Reference
+	name * BackboneElement
+	name.name 1 string
+	name.type 1 CodeableConcept
+	name.status 1 CodeableConcept
+	name.preferred 1 boolean
+	name.language * CodeableConcept
+	name.domain * CodeableConcept
+	name.jurisdiction * CodeableConcept
+	name.synonym * #SubstanceSpecification.name
+	name.translation * #SubstanceSpecification.name
+	name.official * BackboneElement
+	name.official.authority 1 CodeableConcept
+	name.official.status 1 CodeableConcept
+	name.official.date 1 dateTime
+	name.source * Reference
+	molecularWeight * #SubstanceSpecification.structure.isotope.molecularWeight
+	relationship * BackboneElement
+	relationship.substance[x] 1 Reference|CodeableConcept
+	relationship.relationship 1 CodeableConcept
+	relationship.isDefining 1 boolean
+	relationship.amount[x] 1 Quantity|Range|Ratio|string
+	relationship.amountRatioLowLimit 1 Ratio
+	relationship.amountType 1 CodeableConcept
+	relationship.source * Reference
+	nucleicAcid 1 Reference
+	polymer 1 Reference
+	protein 1 Reference
+	sourceMaterial 1 Reference
+
+SupplyDelivery
+	identifier * Identifier
+	basedOn * Reference
+	partOf * Reference
+	status 1 code
+	patient 1 Reference
+	type 1 CodeableConcept
+	suppliedItem 1 BackboneElement
+	suppliedItem.quantity 1 Quantity
+	suppliedItem.item[x] 1 CodeableConcept|Reference
+	occurrence[x] 1 dateTime|Period|Timing
+	supplier 1 Reference
+	destination 1 Reference
+	receiver * Reference
+
+SupplyRequest
+	identifier * Identifier
+	status 1 code
+	category 1 CodeableConcept
+	priority 1 code
+	item[x] 1 CodeableConcept|Reference
+	quantity 1 Quantity
+	parameter * BackboneElement
+	parameter.code 1 CodeableConcept
+	parameter.value[x] 1 CodeableConcept|Quantity|Range|boolean
+	occurrence[x] 1 dateTime|Period|Timing
+	authoredOn 1 dateTime
+	requester 1 Reference
+	supplier * Reference
+	reasonCode * CodeableConcept
+	reasonReference * Reference
+	deliverFrom 1 Reference
+	deliverTo 1 Reference
+
+Task
+	identifier * Identifier
+	instantiatesCanonical 1 canonical
+	instantiatesUri 1 uri
+	basedOn * Reference
+	groupIdentifier 1 Identifier
+	partOf * Reference
+	status 1 code
+	statusReason 1 CodeableConcept
+	businessStatus 1 CodeableConcept
+	intent 1 code
+	priority 1 code
+	code 1 CodeableConcept
+	description 1 string
+	focus 1 Reference
+	for 1 Reference
+	encounter 1 Reference
+	executionPeriod 1 Period
+	authoredOn 1 dateTime
+	lastModified 1 dateTime
+	requester 1 Reference
+	performerType * CodeableConcept
+	owner 1 Reference
+	location 1 Reference
+	reasonCode 1 CodeableConcept
+	reasonReference 1 Reference
+	insurance * Reference
+	note * Annotation
+	relevantHistory * Reference
+	restriction 1 BackboneElement
+	restriction.repetitions 1 positiveInt
+	restriction.period 1 Period
+	restriction.recipient * Reference
+	input * BackboneElement
+	input.type 1 CodeableConcept
+	input.value[x] 1 base64Binary|boolean|canonical|code|date|dateTime|decimal|id|instant|integer|markdown|oid|positiveInt|string|time|unsignedInt|uri|url|uuid|Address|Age|Annotation|Attachment|CodeableConcept|Coding|ContactPoint|Count|Distance|Duration|HumanName|Identifier|Money|Period|Quantity|Range|Ratio|Reference|SampledData|Signature|Timing|ContactDetail|Contributor|DataRequirement|Expression|ParameterDefinition|RelatedArtifact|TriggerDefinition|UsageContext|Dosage|Meta
+	output * BackboneElement
+	output.type 1 CodeableConcept
+	output.value[x] 1 base64Binary|boolean|canonical|code|date|dateTime|decimal|id|instant|integer|markdown|oid|positiveInt|string|time|unsignedInt|uri|url|uuid|Address|Age|Annotation|Attachment|CodeableConcept|Coding|ContactPoint|Count|Distance|Duration|HumanName|Identifier|Money|Period|Quantity|Range|Ratio|Reference|SampledData|Signature|Timing|ContactDetail|Contributor|DataRequirement|Expression|ParameterDefinition|RelatedArtifact|TriggerDefinition|UsageContext|Dosage|Meta
+
+TerminologyCapabilities
+	url 1 uri
+	version 1 string
+	name 1 string
+	title 1 string
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	copyright 1 markdown
+	kind 1 code
+	software 1 BackboneElement
+	software.name 1 string
+	software.version 1 string
+	implementation 1 BackboneElement
+	implementation.description 1 string
+	implementation.url 1 url
+	lockedDate 1 boolean
+	codeSystem * BackboneElement
+	codeSystem.uri 1 canonical
+	codeSystem.version * BackboneElement
+	codeSystem.version.code 1 string
+	codeSystem.version.isDefault 1 boolean
+	codeSystem.version.compositional 1 boolean
+	codeSystem.version.language * code
+	codeSystem.version.filter * BackboneElement
+	codeSystem.version.filter.code 1 code
+	codeSystem.version.filter.op * code
+	codeSystem.version.property * code
+	codeSystem.subsumption 1 boolean
+	expansion 1 BackboneElement
+	expansion.hierarchical 1 boolean
+	expansion.paging 1 boolean
+	expansion.incomplete 1 boolean
+	expansion.parameter * BackboneElement
+	expansion.parameter.name 1 code
+	expansion.parameter.documentation 1 string
+	expansion.textFilter 1 markdown
+	codeSearch 1 code
+	validateCode 1 BackboneElement
+	validateCode.translations 1 boolean
+	translation 1 BackboneElement
+	translation.needsMap 1 boolean
+	closure 1 BackboneElement
+	closure.translation 1 boolean
+
+TestReport
+	identifier 1 Identifier
+	name 1 string
+	status 1 code
+	testScript 1 Reference
+	result 1 code
+	score 1 decimal
+	tester 1 string
+	issued 1 dateTime
+	participant * BackboneElement
+	participant.type 1 code
+	participant.uri 1 uri
+	participant.display 1 string
+	setup 1 BackboneElement
+	setup.action * BackboneElement
+	setup.action.operation 1 BackboneElement
+	setup.action.operation.result 1 code
+	setup.action.operation.message 1 markdown
+	setup.action.operation.detail 1 uri
+	setup.action.assert 1 BackboneElement
+	setup.action.assert.result 1 code
+	setup.action.assert.message 1 markdown
+	setup.action.assert.detail 1 string
+	test * BackboneElement
+	test.name 1 string
+	test.description 1 string
+	test.action * BackboneElement
+	test.action.operation 1 #TestReport.setup.action.operation
+	test.action.assert 1 #TestReport.setup.action.assert
+	teardown 1 BackboneElement
+	teardown.action * BackboneElement
+	teardown.action.operation 1 #TestReport.setup.action.operation
+
+TestScript
+	url 1 uri
+	identifier 1 Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	purpose 1 markdown
+	copyright 1 markdown
+	origin * BackboneElement
+	origin.index 1 integer
+	origin.profile 1 Coding
+	destination * BackboneElement
+	destination.index 1 integer
+	destination.profile 1 Coding
+	metadata 1 BackboneElement
+	metadata.link * BackboneElement
+	metadata.link.url 1 uri
+	metadata.link.description 1 string
+	metadata.capability * BackboneElement
+	metadata.capability.required 1 boolean
+	metadata.capability.validated 1 boolean
+	metadata.capability.description 1 string
+	metadata.capability.origin * integer
+	metadata.capability.destination 1 integer
+	metadata.capability.link * uri
+	metadata.capability.capabilities 1 canonical
+	fixture * BackboneElement
+	fixture.autocreate 1 boolean
+	fixture.autodelete 1 boolean
+	fixture.resource 1 Reference
+	profile * Reference
+	variable * BackboneElement
+	variable.name 1 string
+	variable.defaultValue 1 string
+	variable.description 1 string
+	variable.expression 1 string
+	variable.headerField 1 string
+	variable.hint 1 string
+	variable.path 1 string
+	variable.sourceId 1 id
+	setup 1 BackboneElement
+	setup.action * BackboneElement
+	setup.action.operation 1 BackboneElement
+	setup.action.operation.type 1 Coding
+	setup.action.operation.resource 1 code
+	setup.action.operation.label 1 string
+	setup.action.operation.description 1 string
+	setup.action.operation.accept 1 code
+	setup.action.operation.contentType 1 code
+	setup.action.operation.destination 1 integer
+	setup.action.operation.encodeRequestUrl 1 boolean
+	setup.action.operation.method 1 code
+	setup.action.operation.origin 1 integer
+	setup.action.operation.params 1 string
+	setup.action.operation.requestHeader * BackboneElement
+	setup.action.operation.requestHeader.field 1 string
+	setup.action.operation.requestHeader.value 1 string
+	setup.action.operation.requestId 1 id
+	setup.action.operation.responseId 1 id
+	setup.action.operation.sourceId 1 id
+	setup.action.operation.targetId 1 id
+	setup.action.operation.url 1 string
+	setup.action.assert 1 BackboneElement
+	setup.action.assert.label 1 string
+	setup.action.assert.description 1 string
+	setup.action.assert.direction 1 code
+	setup.action.assert.compareToSourceId 1 string
+	setup.action.assert.compareToSourceExpression 1 string
+	setup.action.assert.compareToSourcePath 1 string
+	setup.action.assert.contentType 1 code
+	setup.action.assert.expression 1 string
+	setup.action.assert.headerField 1 string
+	setup.action.assert.minimumId 1 string
+	setup.action.assert.navigationLinks 1 boolean
+	setup.action.assert.operator 1 code
+	setup.action.assert.path 1 string
+	setup.action.assert.requestMethod 1 code
+	setup.action.assert.requestURL 1 string
+	setup.action.assert.resource 1 code
+	setup.action.assert.response 1 code
+	setup.action.assert.responseCode 1 string
+	setup.action.assert.sourceId 1 id
+	setup.action.assert.validateProfileId 1 id
+	setup.action.assert.value 1 string
+	setup.action.assert.warningOnly 1 boolean
+	test * BackboneElement
+	test.name 1 string
+	test.description 1 string
+	test.action * BackboneElement
+	test.action.operation 1 #TestScript.setup.action.operation
+	test.action.assert 1 #TestScript.setup.action.assert
+	teardown 1 BackboneElement
+	teardown.action * BackboneElement
+	teardown.action.operation 1 #TestScript.setup.action.operation
+
+ValueSet
+	url 1 uri
+	identifier * Identifier
+	version 1 string
+	name 1 string
+	title 1 string
+	status 1 code
+	experimental 1 boolean
+	date 1 dateTime
+	publisher 1 string
+	contact * ContactDetail
+	description 1 markdown
+	useContext * UsageContext
+	jurisdiction * CodeableConcept
+	immutable 1 boolean
+	purpose 1 markdown
+	copyright 1 markdown
+	compose 1 BackboneElement
+	compose.lockedDate 1 date
+	compose.inactive 1 boolean
+	compose.include * BackboneElement
+	compose.include.system 1 uri
+	compose.include.version 1 string
+	compose.include.concept * BackboneElement
+	compose.include.concept.code 1 code
+	compose.include.concept.display 1 string
+	compose.include.concept.designation * BackboneElement
+	compose.include.concept.designation.language 1 code
+	compose.include.concept.designation.use 1 Coding
+	compose.include.concept.designation.value 1 string
+	compose.include.filter * BackboneElement
+	compose.include.filter.property 1 code
+	compose.include.filter.op 1 code
+	compose.include.filter.value 1 string
+	compose.include.valueSet * canonical
+	compose.exclude * #ValueSet.compose.include
+	expansion 1 BackboneElement
+	expansion.identifier 1 uri
+	expansion.timestamp 1 dateTime
+	expansion.total 1 integer
+	expansion.offset 1 integer
+	expansion.parameter * BackboneElement
+	expansion.parameter.name 1 string
+	expansion.parameter.value[x] 1 string|boolean|integer|decimal|uri|code|dateTime
+	expansion.contains * BackboneElement
+	expansion.contains.system 1 uri
+	expansion.contains.abstract 1 boolean
+	expansion.contains.inactive 1 boolean
+	expansion.contains.version 1 string
+	expansion.contains.code 1 code
+	expansion.contains.display 1 string
+	expansion.contains.designation * #ValueSet.compose.include.concept.designation
+	expansion.contains.contains * #ValueSet.expansion.contains
+
+VerificationResult
+	target * Reference
+	targetLocation * string
+	need 1 CodeableConcept
+	status 1 code
+	statusDate 1 dateTime
+	validationType 1 CodeableConcept
+	validationProcess * CodeableConcept
+	frequency 1 Timing
+	lastPerformed 1 dateTime
+	nextScheduled 1 date
+	failureAction 1 CodeableConcept
+	primarySource * BackboneElement
+	primarySource.who 1 Reference
+	primarySource.type * CodeableConcept
+	primarySource.communicationMethod * CodeableConcept
+	primarySource.validationStatus 1 CodeableConcept
+	primarySource.validationDate 1 dateTime
+	primarySource.canPushUpdates 1 CodeableConcept
+	primarySource.pushTypeAvailable * CodeableConcept
+	attestation 1 BackboneElement
+	attestation.who 1 Reference
+	attestation.onBehalfOf 1 Reference
+	attestation.communicationMethod 1 CodeableConcept
+	attestation.date 1 date
+	attestation.sourceIdentityCertificate 1 string
+	attestation.proxyIdentityCertificate 1 string
+	attestation.proxySignature 1 Signature
+	attestation.sourceSignature 1 Signature
+	validator * BackboneElement
+	validator.organization 1 Reference
+	validator.identityCertificate 1 string
+	validator.attestationSignature 1 Signature
+
+VisionPrescription
+	identifier * Identifier
+	status 1 code
+	created 1 dateTime
+	patient 1 Reference
+	encounter 1 Reference
+	dateWritten 1 dateTime
+	prescriber 1 Reference
+	lensSpecification * BackboneElement
+	lensSpecification.product 1 CodeableConcept
+	lensSpecification.eye 1 code
+	lensSpecification.sphere 1 decimal
+	lensSpecification.cylinder 1 decimal
+	lensSpecification.axis 1 integer
+	lensSpecification.prism * BackboneElement
+	lensSpecification.prism.amount 1 decimal
+	lensSpecification.prism.base 1 code
+	lensSpecification.add 1 decimal
+	lensSpecification.power 1 decimal
+	lensSpecification.backCurve 1 decimal
+	lensSpecification.diameter 1 decimal
+	lensSpecification.duration 1 Quantity
+	lensSpecification.color 1 string
+	lensSpecification.brand 1 string
+	lensSpecification.note * Annotation
+`
+
+// parseLayoutTable returns the layout rows of a layout table keyed by type
+// name. The table is compiled in, so a malformed line is a programming error
+// and panics.
+func parseLayoutTable(table string) map[string][][3]string {
+	layouts := make(map[string][][3]string)
+	var typeName string
+	for n, line := range strings.Split(table, "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+			typeName = ""
+		case !strings.HasPrefix(line, "\t"):
+			typeName = strings.TrimSpace(line)
+			if _, dup := layouts[typeName]; dup {
+				panic(fmt.Sprintf("layout table line %d: duplicate type %s", n+1, typeName))
+			}
+			layouts[typeName] = nil
+		default:
+			fields := strings.Fields(line)
+			if typeName == "" || len(fields) != 3 {
+				panic(fmt.Sprintf("layout table line %d: malformed element %q", n+1, line))
+			}
+			layouts[typeName] = append(layouts[typeName], [3]string{fields[0], fields[1], fields[2]})
+		}
+	}
+	return layouts
+}
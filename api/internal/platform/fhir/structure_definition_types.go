@@ -0,0 +1,656 @@
+package fhir
+
+import "strings"
+
+// ============================================================================
+// Structural Layouts
+// ============================================================================
+//
+// The definitions registered by RegisterBaseDefinitions describe the elements
+// most useful for validation and documentation. Wire formats such as XML need
+// more than that: every element in its canonical order, the type of every
+// element (including backbone children), and the complex datatypes
+// themselves. The layouts below carry exactly that structural information for
+// the FHIR R4 datatypes, the infrastructure resources the server emits, and
+// every other R4 resource type (see resourceLayoutTable).
+//
+// Each row is {relative path, max cardinality, types}. Types are separated by
+// "|" for choice elements; a leading "#" marks a contentReference to another
+// element of the same definition (e.g. Parameters.parameter.part).
+
+// openTypes lists the types allowed for the open choice elements of R4, such
+// as ElementDefinition.fixed[x].
+const openTypes = "base64Binary|boolean|canonical|code|date|dateTime|decimal|id|instant|integer|markdown|oid|positiveInt|string|time|unsignedInt|uri|url|uuid|Address|Age|Annotation|Attachment|CodeableConcept|Coding|ContactPoint|Count|Distance|Duration|HumanName|Identifier|Money|Period|Quantity|Range|Ratio|Reference|SampledData|Signature|Timing|ContactDetail|Contributor|DataRequirement|Expression|ParameterDefinition|RelatedArtifact|TriggerDefinition|UsageContext|Dosage|Meta"
+
+// quantityLayout is shared by Quantity and its specialisations.
+var quantityLayout = [][3]string{
+	{"value", "1", "decimal"},
+	{"comparator", "1", "code"},
+	{"unit", "1", "string"},
+	{"system", "1", "uri"},
+	{"code", "1", "code"},
+}
+
+// dataTypeLayouts holds the R4 complex datatypes in canonical element order.
+// The id and extension elements every datatype inherits from Element are
+// handled by the wire formats directly and are omitted here.
+var dataTypeLayouts = map[string][][3]string{
+	"Extension": {
+		{"url", "1", "uri"},
+		{"value[x]", "1", "base64Binary|boolean|canonical|code|date|dateTime|decimal|id|instant|integer|markdown|oid|positiveInt|string|time|unsignedInt|uri|url|uuid|Address|Age|Annotation|Attachment|CodeableConcept|Coding|ContactPoint|Count|Distance|Duration|HumanName|Identifier|Money|Period|Quantity|Range|Ratio|Reference|SampledData|Signature|Timing|ContactDetail|Contributor|DataRequirement|Expression|ParameterDefinition|RelatedArtifact|TriggerDefinition|UsageContext|Dosage|Meta"},
+	},
+	"Narrative": {
+		{"status", "1", "code"},
+		{"div", "1", "xhtml"},
+	},
+	"Meta": {
+		{"versionId", "1", "id"},
+		{"lastUpdated", "1", "instant"},
+		{"source", "1", "uri"},
+		{"profile", "*", "canonical"},
+		{"security", "*", "Coding"},
+		{"tag", "*", "Coding"},
+	},
+	"Coding": {
+		{"system", "1", "uri"},
+		{"version", "1", "string"},
+		{"code", "1", "code"},
+		{"display", "1", "string"},
+		{"userSelected", "1", "boolean"},
+	},
+	"CodeableConcept": {
+		{"coding", "*", "Coding"},
+		{"text", "1", "string"},
+	},
+	"Identifier": {
+		{"use", "1", "code"},
+		{"type", "1", "CodeableConcept"},
+		{"system", "1", "uri"},
+		{"value", "1", "string"},
+		{"period", "1", "Period"},
+		{"assigner", "1", "Reference"},
+	},
+	"HumanName": {
+		{"use", "1", "code"},
+		{"text", "1", "string"},
+		{"family", "1", "string"},
+		{"given", "*", "string"},
+		{"prefix", "*", "string"},
+		{"suffix", "*", "string"},
+		{"period", "1", "Period"},
+	},
+	"Address": {
+		{"use", "1", "code"},
+		{"type", "1", "code"},
+		{"text", "1", "string"},
+		{"line", "*", "string"},
+		{"city", "1", "string"},
+		{"district", "1", "string"},
+		{"state", "1", "string"},
+		{"postalCode", "1", "string"},
+		{"country", "1", "string"},
+		{"period", "1", "Period"},
+	},
+	"ContactPoint": {
+		{"system", "1", "code"},
+		{"value", "1", "string"},
+		{"use", "1", "code"},
+		{"rank", "1", "positiveInt"},
+		{"period", "1", "Period"},
+	},
+	"Period": {
+		{"start", "1", "dateTime"},
+		{"end", "1", "dateTime"},
+	},
+	"Quantity":       quantityLayout,
+	"SimpleQuantity": quantityLayout,
+	"MoneyQuantity":  quantityLayout,
+	"Age":            quantityLayout,
+	"Count":          quantityLayout,
+	"Distance":       quantityLayout,
+	"Duration":       quantityLayout,
+	"Money": {
+		{"value", "1", "decimal"},
+		{"currency", "1", "code"},
+	},
+	"Range": {
+		{"low", "1", "Quantity"},
+		{"high", "1", "Quantity"},
+	},
+	"Ratio": {
+		{"numerator", "1", "Quantity"},
+		{"denominator", "1", "Quantity"},
+	},
+	"Reference": {
+		{"reference", "1", "string"},
+		{"type", "1", "uri"},
+		{"identifier", "1", "Identifier"},
+		{"display", "1", "string"},
+	},
+	"Annotation": {
+		{"author[x]", "1", "Reference|string"},
+		{"time", "1", "dateTime"},
+		{"text", "1", "markdown"},
+	},
+	"Attachment": {
+		{"contentType", "1", "code"},
+		{"language", "1", "code"},
+		{"data", "1", "base64Binary"},
+		{"url", "1", "url"},
+		{"size", "1", "unsignedInt"},
+		{"hash", "1", "base64Binary"},
+		{"title", "1", "string"},
+		{"creation", "1", "dateTime"},
+	},
+	"SampledData": {
+		{"origin", "1", "Quantity"},
+		{"period", "1", "decimal"},
+		{"factor", "1", "decimal"},
+		{"lowerLimit", "1", "decimal"},
+		{"upperLimit", "1", "decimal"},
+		{"dimensions", "1", "positiveInt"},
+		{"data", "1", "string"},
+	},
+	"Signature": {
+		{"type", "*", "Coding"},
+		{"when", "1", "instant"},
+		{"who", "1", "Reference"},
+		{"onBehalfOf", "1", "Reference"},
+		{"targetFormat", "1", "code"},
+		{"sigFormat", "1", "code"},
+		{"data", "1", "base64Binary"},
+	},
+	"Timing": {
+		{"event", "*", "dateTime"},
+		{"repeat", "1", "Element"},
+		{"repeat.bounds[x]", "1", "Duration|Range|Period"},
+		{"repeat.count", "1", "positiveInt"},
+		{"repeat.countMax", "1", "positiveInt"},
+		{"repeat.duration", "1", "decimal"},
+		{"repeat.durationMax", "1", "decimal"},
+		{"repeat.durationUnit", "1", "code"},
+		{"repeat.frequency", "1", "positiveInt"},
+		{"repeat.frequencyMax", "1", "positiveInt"},
+		{"repeat.period", "1", "decimal"},
+		{"repeat.periodMax", "1", "decimal"},
+		{"repeat.periodUnit", "1", "code"},
+		{"repeat.dayOfWeek", "*", "code"},
+		{"repeat.timeOfDay", "*", "time"},
+		{"repeat.when", "*", "code"},
+		{"repeat.offset", "1", "unsignedInt"},
+		{"code", "1", "CodeableConcept"},
+	},
+	"Dosage": {
+		{"sequence", "1", "integer"},
+		{"text", "1", "string"},
+		{"additionalInstruction", "*", "CodeableConcept"},
+		{"patientInstruction", "1", "string"},
+		{"timing", "1", "Timing"},
+		{"asNeeded[x]", "1", "boolean|CodeableConcept"},
+		{"site", "1", "CodeableConcept"},
+		{"route", "1", "CodeableConcept"},
+		{"method", "1", "CodeableConcept"},
+		{"doseAndRate", "*", "Element"},
+		{"doseAndRate.type", "1", "CodeableConcept"},
+		{"doseAndRate.dose[x]", "1", "Range|Quantity"},
+		{"doseAndRate.rate[x]", "1", "Ratio|Range|Quantity"},
+		{"maxDosePerPeriod", "1", "Ratio"},
+		{"maxDosePerAdministration", "1", "Quantity"},
+		{"maxDosePerLifetime", "1", "Quantity"},
+	},
+	"ContactDetail": {
+		{"name", "1", "string"},
+		{"telecom", "*", "ContactPoint"},
+	},
+	"UsageContext": {
+		{"code", "1", "Coding"},
+		{"value[x]", "1", "CodeableConcept|Quantity|Range|Reference"},
+	},
+	"RelatedArtifact": {
+		{"type", "1", "code"},
+		{"label", "1", "string"},
+		{"display", "1", "string"},
+		{"citation", "1", "markdown"},
+		{"url", "1", "url"},
+		{"document", "1", "Attachment"},
+		{"resource", "1", "canonical"},
+	},
+	"Expression": {
+		{"description", "1", "string"},
+		{"name", "1", "id"},
+		{"language", "1", "code"},
+		{"expression", "1", "string"},
+		{"reference", "1", "uri"},
+	},
+	"Contributor": {
+		{"type", "1", "code"},
+		{"name", "1", "string"},
+		{"contact", "*", "ContactDetail"},
+	},
+	"DataRequirement": {
+		{"type", "1", "code"},
+		{"profile", "*", "canonical"},
+		{"subject[x]", "1", "CodeableConcept|Reference"},
+		{"mustSupport", "*", "string"},
+		{"codeFilter", "*", "Element"},
+		{"codeFilter.path", "1", "string"},
+		{"codeFilter.searchParam", "1", "string"},
+		{"codeFilter.valueSet", "1", "canonical"},
+		{"codeFilter.code", "*", "Coding"},
+		{"dateFilter", "*", "Element"},
+		{"dateFilter.path", "1", "string"},
+		{"dateFilter.searchParam", "1", "string"},
+		{"dateFilter.value[x]", "1", "dateTime|Period|Duration"},
+		{"limit", "1", "positiveInt"},
+		{"sort", "*", "Element"},
+		{"sort.path", "1", "string"},
+		{"sort.direction", "1", "code"},
+	},
+	"ParameterDefinition": {
+		{"name", "1", "code"},
+		{"use", "1", "code"},
+		{"min", "1", "integer"},
+		{"max", "1", "string"},
+		{"documentation", "1", "string"},
+		{"type", "1", "code"},
+		{"profile", "1", "canonical"},
+	},
+	"TriggerDefinition": {
+		{"type", "1", "code"},
+		{"name", "1", "string"},
+		{"timing[x]", "1", "Timing|Reference|date|dateTime"},
+		{"data", "*", "DataRequirement"},
+		{"condition", "1", "Expression"},
+	},
+	"Population": {
+		{"age[x]", "1", "Range|CodeableConcept"},
+		{"gender", "1", "CodeableConcept"},
+		{"race", "1", "CodeableConcept"},
+		{"physiologicalCondition", "1", "CodeableConcept"},
+	},
+	"ProdCharacteristic": {
+		{"height", "1", "Quantity"},
+		{"width", "1", "Quantity"},
+		{"depth", "1", "Quantity"},
+		{"weight", "1", "Quantity"},
+		{"nominalVolume", "1", "Quantity"},
+		{"externalDiameter", "1", "Quantity"},
+		{"shape", "1", "string"},
+		{"color", "*", "string"},
+		{"imprint", "*", "string"},
+		{"image", "*", "Attachment"},
+		{"scoring", "1", "CodeableConcept"},
+	},
+	"ProductShelfLife": {
+		{"identifier", "1", "Identifier"},
+		{"type", "1", "CodeableConcept"},
+		{"period", "1", "Quantity"},
+		{"specialPrecautionsForStorage", "*", "CodeableConcept"},
+	},
+	"MarketingStatus": {
+		{"country", "1", "CodeableConcept"},
+		{"jurisdiction", "1", "CodeableConcept"},
+		{"status", "1", "CodeableConcept"},
+		{"dateRange", "1", "Period"},
+		{"restoreDate", "1", "dateTime"},
+	},
+	"SubstanceAmount": {
+		{"amount[x]", "1", "Quantity|Range|string"},
+		{"amountType", "1", "CodeableConcept"},
+		{"amountText", "1", "string"},
+		{"referenceRange", "1", "Element"},
+		{"referenceRange.lowLimit", "1", "Quantity"},
+		{"referenceRange.highLimit", "1", "Quantity"},
+	},
+	"ElementDefinition": {
+		{"path", "1", "string"},
+		{"representation", "*", "code"},
+		{"sliceName", "1", "string"},
+		{"sliceIsConstraining", "1", "boolean"},
+		{"label", "1", "string"},
+		{"code", "*", "Coding"},
+		{"slicing", "1", "Element"},
+		{"slicing.discriminator", "*", "Element"},
+		{"slicing.discriminator.type", "1", "code"},
+		{"slicing.discriminator.path", "1", "string"},
+		{"slicing.description", "1", "string"},
+		{"slicing.ordered", "1", "boolean"},
+		{"slicing.rules", "1", "code"},
+		{"short", "1", "string"},
+		{"definition", "1", "markdown"},
+		{"comment", "1", "markdown"},
+		{"requirements", "1", "markdown"},
+		{"alias", "*", "string"},
+		{"min", "1", "unsignedInt"},
+		{"max", "1", "string"},
+		{"base", "1", "Element"},
+		{"base.path", "1", "string"},
+		{"base.min", "1", "unsignedInt"},
+		{"base.max", "1", "string"},
+		{"contentReference", "1", "uri"},
+		{"type", "*", "Element"},
+		{"type.code", "1", "uri"},
+		{"type.profile", "*", "canonical"},
+		{"type.targetProfile", "*", "canonical"},
+		{"type.aggregation", "*", "code"},
+		{"type.versioning", "1", "code"},
+		{"defaultValue[x]", "1", openTypes},
+		{"meaningWhenMissing", "1", "markdown"},
+		{"orderMeaning", "1", "string"},
+		{"fixed[x]", "1", openTypes},
+		{"pattern[x]", "1", openTypes},
+		{"example", "*", "Element"},
+		{"example.label", "1", "string"},
+		{"example.value[x]", "1", openTypes},
+		{"minValue[x]", "1", "date|dateTime|instant|time|decimal|integer|positiveInt|unsignedInt|Quantity"},
+		{"maxValue[x]", "1", "date|dateTime|instant|time|decimal|integer|positiveInt|unsignedInt|Quantity"},
+		{"maxLength", "1", "integer"},
+		{"condition", "*", "id"},
+		{"constraint", "*", "Element"},
+		{"constraint.key", "1", "id"},
+		{"constraint.requirements", "1", "string"},
+		{"constraint.severity", "1", "code"},
+		{"constraint.human", "1", "string"},
+		{"constraint.expression", "1", "string"},
+		{"constraint.xpath", "1", "string"},
+		{"constraint.source", "1", "canonical"},
+		{"mustSupport", "1", "boolean"},
+		{"isModifier", "1", "boolean"},
+		{"isModifierReason", "1", "string"},
+		{"isSummary", "1", "boolean"},
+		{"binding", "1", "Element"},
+		{"binding.strength", "1", "code"},
+		{"binding.description", "1", "string"},
+		{"binding.valueSet", "1", "canonical"},
+		{"mapping", "*", "Element"},
+		{"mapping.identity", "1", "id"},
+		{"mapping.language", "1", "code"},
+		{"mapping.map", "1", "string"},
+		{"mapping.comment", "1", "markdown"},
+	},
+}
+
+// infrastructureLayouts holds the non-clinical resources the server produces
+// itself (search results, errors, operation parameters, metadata).
+var infrastructureLayouts = map[string][][3]string{
+	"Bundle": {
+		{"identifier", "1", "Identifier"},
+		{"type", "1", "code"},
+		{"timestamp", "1", "instant"},
+		{"total", "1", "unsignedInt"},
+		{"link", "*", "BackboneElement"},
+		{"link.relation", "1", "string"},
+		{"link.url", "1", "uri"},
+		{"entry", "*", "BackboneElement"},
+		{"entry.link", "*", "#Bundle.link"},
+		{"entry.fullUrl", "1", "uri"},
+		{"entry.resource", "1", "Resource"},
+		{"entry.search", "1", "BackboneElement"},
+		{"entry.search.mode", "1", "code"},
+		{"entry.search.score", "1", "decimal"},
+		{"entry.request", "1", "BackboneElement"},
+		{"entry.request.method", "1", "code"},
+		{"entry.request.url", "1", "uri"},
+		{"entry.request.ifNoneMatch", "1", "string"},
+		{"entry.request.ifModifiedSince", "1", "instant"},
+		{"entry.request.ifMatch", "1", "string"},
+		{"entry.request.ifNoneExist", "1", "string"},
+		{"entry.response", "1", "BackboneElement"},
+		{"entry.response.status", "1", "string"},
+		{"entry.response.location", "1", "uri"},
+		{"entry.response.etag", "1", "string"},
+		{"entry.response.lastModified", "1", "instant"},
+		{"entry.response.outcome", "1", "Resource"},
+		{"signature", "1", "Signature"},
+	},
+	"OperationOutcome": {
+		{"issue", "*", "BackboneElement"},
+		{"issue.severity", "1", "code"},
+		{"issue.code", "1", "code"},
+		{"issue.details", "1", "CodeableConcept"},
+		{"issue.diagnostics", "1", "string"},
+		{"issue.location", "*", "string"},
+		{"issue.expression", "*", "string"},
+	},
+	"Parameters": {
+		{"parameter", "*", "BackboneElement"},
+		{"parameter.name", "1", "string"},
+		{"parameter.value[x]", "1", "base64Binary|boolean|canonical|code|date|dateTime|decimal|id|instant|integer|markdown|oid|positiveInt|string|time|unsignedInt|uri|url|uuid|Address|Age|Annotation|Attachment|CodeableConcept|Coding|ContactPoint|Count|Distance|Duration|HumanName|Identifier|Money|Period|Quantity|Range|Ratio|Reference|SampledData|Signature|Timing|ContactDetail|Expression|RelatedArtifact|UsageContext|Dosage|Meta"},
+		{"parameter.resource", "1", "Resource"},
+		{"parameter.part", "*", "#Parameters.parameter"},
+	},
+	"Binary": {
+		{"contentType", "1", "code"},
+		{"securityContext", "1", "Reference"},
+		{"data", "1", "base64Binary"},
+	},
+	"CapabilityStatement": {
+		{"url", "1", "uri"},
+		{"version", "1", "string"},
+		{"name", "1", "string"},
+		{"title", "1", "string"},
+		{"status", "1", "code"},
+		{"experimental", "1", "boolean"},
+		{"date", "1", "dateTime"},
+		{"publisher", "1", "string"},
+		{"contact", "*", "ContactDetail"},
+		{"description", "1", "markdown"},
+		{"useContext", "*", "UsageContext"},
+		{"jurisdiction", "*", "CodeableConcept"},
+		{"purpose", "1", "markdown"},
+		{"copyright", "1", "markdown"},
+		{"kind", "1", "code"},
+		{"instantiates", "*", "canonical"},
+		{"imports", "*", "canonical"},
+		{"software", "1", "BackboneElement"},
+		{"software.name", "1", "string"},
+		{"software.version", "1", "string"},
+		{"software.releaseDate", "1", "dateTime"},
+		{"implementation", "1", "BackboneElement"},
+		{"implementation.description", "1", "string"},
+		{"implementation.url", "1", "url"},
+		{"implementation.custodian", "1", "Reference"},
+		{"fhirVersion", "1", "code"},
+		{"format", "*", "code"},
+		{"patchFormat", "*", "code"},
+		{"implementationGuide", "*", "canonical"},
+		{"rest", "*", "BackboneElement"},
+		{"rest.mode", "1", "code"},
+		{"rest.documentation", "1", "markdown"},
+		{"rest.security", "1", "BackboneElement"},
+		{"rest.security.cors", "1", "boolean"},
+		{"rest.security.service", "*", "CodeableConcept"},
+		{"rest.security.description", "1", "markdown"},
+		{"rest.resource", "*", "BackboneElement"},
+		{"rest.resource.type", "1", "code"},
+		{"rest.resource.profile", "1", "canonical"},
+		{"rest.resource.supportedProfile", "*", "canonical"},
+		{"rest.resource.documentation", "1", "markdown"},
+		{"rest.resource.interaction", "*", "BackboneElement"},
+		{"rest.resource.interaction.code", "1", "code"},
+		{"rest.resource.interaction.documentation", "1", "markdown"},
+		{"rest.resource.versioning", "1", "code"},
+		{"rest.resource.readHistory", "1", "boolean"},
+		{"rest.resource.updateCreate", "1", "boolean"},
+		{"rest.resource.conditionalCreate", "1", "boolean"},
+		{"rest.resource.conditionalRead", "1", "code"},
+		{"rest.resource.conditionalUpdate", "1", "boolean"},
+		{"rest.resource.conditionalDelete", "1", "code"},
+		{"rest.resource.referencePolicy", "*", "code"},
+		{"rest.resource.searchInclude", "*", "string"},
+		{"rest.resource.searchRevInclude", "*", "string"},
+		{"rest.resource.searchParam", "*", "BackboneElement"},
+		{"rest.resource.searchParam.name", "1", "string"},
+		{"rest.resource.searchParam.definition", "1", "canonical"},
+		{"rest.resource.searchParam.type", "1", "code"},
+		{"rest.resource.searchParam.documentation", "1", "markdown"},
+		{"rest.resource.operation", "*", "BackboneElement"},
+		{"rest.resource.operation.name", "1", "string"},
+		{"rest.resource.operation.definition", "1", "canonical"},
+		{"rest.resource.operation.documentation", "1", "markdown"},
+		{"rest.interaction", "*", "BackboneElement"},
+		{"rest.interaction.code", "1", "code"},
+		{"rest.interaction.documentation", "1", "markdown"},
+		{"rest.searchParam", "*", "#CapabilityStatement.rest.resource.searchParam"},
+		{"rest.operation", "*", "#CapabilityStatement.rest.resource.operation"},
+		{"rest.compartment", "*", "canonical"},
+		{"document", "*", "BackboneElement"},
+		{"document.mode", "1", "code"},
+		{"document.documentation", "1", "markdown"},
+		{"document.profile", "1", "canonical"},
+	},
+}
+
+// resourceLayouts completes the base definitions registered by
+// RegisterBaseDefinitions with every R4 element in canonical order, and
+// defines the resource types that have no base definition.
+var resourceLayouts = parseLayoutTable(resourceLayoutTable)
+
+// HasStructuralLayout reports whether the canonical element order of
+// resourceType is known, which the XML format needs to write the resource
+// in schema order.
+func HasStructuralLayout(resourceType string) bool {
+	if _, ok := infrastructureLayouts[resourceType]; ok {
+		return true
+	}
+	_, ok := resourceLayouts[resourceType]
+	return ok
+}
+
+// layoutElements expands layout rows into element definitions rooted at
+// typeName.
+func layoutElements(typeName string, rows [][3]string) []ElementDefinition {
+	elements := make([]ElementDefinition, 0, len(rows))
+	for _, row := range rows {
+		path := typeName + "." + row[0]
+		e := ElementDefinition{ID: path, Path: path, Min: intPtr(0), Max: row[1]}
+		if strings.HasPrefix(row[2], "#") {
+			e.ContentReference = row[2]
+		} else {
+			for _, code := range strings.Split(row[2], "|") {
+				e.Type = append(e.Type, ElementType{Code: code})
+			}
+		}
+		elements = append(elements, e)
+	}
+	return elements
+}
+
+// RegisterStructuralDefinitions registers the complex datatypes and
+// infrastructure resources, completes the base resource definitions already
+// in the store with their full element layout, and registers a definition for
+// every other resource type. It is called by RegisterBaseDefinitions; calling
+// it again is harmless.
+func RegisterStructuralDefinitions(store *StructureDefinitionStore) {
+	baseURL := "http://hl7.org/fhir/StructureDefinition/"
+
+	for name, rows := range dataTypeLayouts {
+		elements := append([]ElementDefinition{{ID: name, Path: name, Min: intPtr(0), Max: "*"}}, layoutElements(name, rows)...)
+		store.Register(&StructureDefinitionResource{
+			ResourceType: "StructureDefinition", ID: name, URL: baseURL + name,
+			Name: name, Title: name, Status: "active", Kind: "complex-type",
+			Abstract: false, Type: name, FHIRVersion: "4.0.1",
+			BaseDefinition: baseURL + "Element", Derivation: "specialization",
+			Snapshot: &StructureSnapshot{Element: elements},
+		})
+	}
+
+	for name, rows := range infrastructureLayouts {
+		elements := append([]ElementDefinition{
+			{ID: name, Path: name, Min: intPtr(0), Max: "*"},
+			{ID: name + ".id", Path: name + ".id", Min: intPtr(0), Max: "1", Type: []ElementType{{Code: "id"}}},
+			{ID: name + ".meta", Path: name + ".meta", Min: intPtr(0), Max: "1", Type: []ElementType{{Code: "Meta"}}},
+		}, layoutElements(name, rows)...)
+		base := baseURL + "DomainResource"
+		if name == "Bundle" || name == "Parameters" || name == "Binary" {
+			base = baseURL + "Resource"
+		}
+		store.Register(&StructureDefinitionResource{
+			ResourceType: "StructureDefinition", ID: name, URL: baseURL + name,
+			Name: name, Title: name, Status: "active", Kind: "resource",
+			Abstract: false, Type: name, FHIRVersion: "4.0.1",
+			BaseDefinition: base, Derivation: "specialization",
+			Snapshot: &StructureSnapshot{Element: elements},
+		})
+	}
+
+	for name, rows := range resourceLayouts {
+		sd := store.Get(name)
+		if sd == nil || sd.Snapshot == nil {
+			elements := append([]ElementDefinition{
+				{ID: name, Path: name, Min: intPtr(0), Max: "*"},
+				{ID: name + ".id", Path: name + ".id", Min: intPtr(0), Max: "1", Type: []ElementType{{Code: "id"}}},
+				{ID: name + ".meta", Path: name + ".meta", Min: intPtr(0), Max: "1", Type: []ElementType{{Code: "Meta"}}},
+				{ID: name + ".text", Path: name + ".text", Min: intPtr(0), Max: "1", Type: []ElementType{{Code: "Narrative"}}},
+			}, layoutElements(name, rows)...)
+			store.Register(&StructureDefinitionResource{
+				ResourceType: "StructureDefinition", ID: name, URL: baseURL + name,
+				Name: name, Title: name, Status: "active", Kind: "resource",
+				Abstract: false, Type: name, FHIRVersion: "4.0.1",
+				BaseDefinition: baseURL + "DomainResource", Derivation: "specialization",
+				Snapshot: &StructureSnapshot{Element: elements},
+			})
+			continue
+		}
+		completed := *sd
+		completed.Snapshot = &StructureSnapshot{Element: completeElements(sd.Snapshot.Element, layoutElements(name, rows))}
+		store.Register(&completed)
+	}
+}
+
+// completeElements returns the layout in canonical order, keeping the
+// existing definition (short text, bindings, cardinality) wherever one is
+// present. Types from the layout are added to existing choice elements so
+// every allowed type suffix is recognised. Existing elements that precede the
+// layout (the root, id, meta and text) stay in front.
+func completeElements(existing, layout []ElementDefinition) []ElementDefinition {
+	byPath := make(map[string]int, len(existing))
+	for i, e := range existing {
+		byPath[e.Path] = i
+	}
+	inLayout := make(map[string]bool, len(layout))
+	for _, e := range layout {
+		inLayout[e.Path] = true
+	}
+
+	result := make([]ElementDefinition, 0, len(layout)+4)
+	used := make(map[string]bool, len(existing))
+	for _, e := range existing {
+		if inLayout[e.Path] {
+			break
+		}
+		result = append(result, e)
+		used[e.Path] = true
+	}
+	for _, e := range layout {
+		idx, ok := byPath[e.Path]
+		if !ok {
+			result = append(result, e)
+			continue
+		}
+		merged := existing[idx]
+		merged.Type = append([]ElementType(nil), merged.Type...)
+		for _, t := range e.Type {
+			if !hasTypeCode(merged.Type, t.Code) {
+				merged.Type = append(merged.Type, t)
+			}
+		}
+		result = append(result, merged)
+		used[e.Path] = true
+	}
+	for _, e := range existing {
+		if !used[e.Path] {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func hasTypeCode(types []ElementType, code string) bool {
+	for _, t := range types {
+		if t.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -654,6 +654,12 @@ func (h *TopicHandler) RegisterRoutes(fhirGroup *echo.Group) {
 	fhirGroup.POST("/Subscription/:id/$events", h.ReplayEvents)
 }
 
+// RegisterResources registers the SubscriptionTopic read and search routes
+// with r.
+func (h *TopicHandler) RegisterResources(r *ResourceRegistry) {
+	r.RegisterInteractions("SubscriptionTopic", "read", "search-type")
+}
+
 // ListTopics handles GET /SubscriptionTopic.
 func (h *TopicHandler) ListTopics(c echo.Context) error {
 	topics := h.engine.ListTopics()
@@ -0,0 +1,317 @@
+package fhir
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// TextDocument is the searchable text of a resource. Narrative holds the
+// resource narrative (text.div, plus the section narratives of a Composition)
+// and backs _text; Content holds every other human-readable string in the
+// resource, including the narrative, annotation notes and the decoded data of
+// text attachments, and backs _content.
+type TextDocument struct {
+	Language  string // PostgreSQL text search configuration
+	Narrative string
+	Content   string
+}
+
+// TextIndexStore persists the TextDocument of each resource so that _text and
+// _content searches can be answered with a single indexed lookup.
+type TextIndexStore interface {
+	IndexText(ctx context.Context, resourceType, resourceID string, doc TextDocument) error
+	DeleteText(ctx context.Context, resourceType, resourceID string) error
+}
+
+// maxIndexedTextBytes bounds the text indexed per field. PostgreSQL rejects
+// tsvectors larger than 1MB, and a large attachment should not fail a write.
+const maxIndexedTextBytes = 256 << 10
+
+// textSearchConfigs maps the primary subtag of a resource's language to the
+// PostgreSQL text search configuration used to index it.
+var textSearchConfigs = map[string]string{
+	"da": "danish",
+	"de": "german",
+	"en": "english",
+	"es": "spanish",
+	"fi": "finnish",
+	"fr": "french",
+	"hu": "hungarian",
+	"it": "italian",
+	"nb": "norwegian",
+	"nl": "dutch",
+	"no": "norwegian",
+	"pt": "portuguese",
+	"ro": "romanian",
+	"ru": "russian",
+	"sv": "swedish",
+	"tr": "turkish",
+}
+
+// TextSearchConfig returns the PostgreSQL text search configuration for a
+// BCP-47 language code. Languages without a stemmer use "simple"; an empty
+// code falls back to defaultConfig.
+func TextSearchConfig(language, defaultConfig string) string {
+	if language == "" {
+		return defaultConfig
+	}
+	primary := strings.ToLower(strings.SplitN(language, "-", 2)[0])
+	if cfg, ok := textSearchConfigs[primary]; ok {
+		return cfg
+	}
+	return "simple"
+}
+
+// textSkipElements lists elements whose values are identifiers, URIs or
+// encoded data rather than human-readable text.
+var textSkipElements = map[string]bool{
+	"resourceType": true,
+	"id":           true,
+	"reference":    true,
+	"system":       true,
+	"url":          true,
+	"fullUrl":      true,
+	"profile":      true,
+	"versionId":    true,
+	"lastUpdated":  true,
+	"language":     true,
+	"contentType":  true,
+	"hash":         true,
+	"data":         true,
+	"div":          true,
+}
+
+// ExtractTextDocument builds the TextDocument of a FHIR resource.
+func ExtractTextDocument(resource map[string]interface{}) TextDocument {
+	lang, _ := resource["language"].(string)
+	doc := TextDocument{Language: TextSearchConfig(lang, "english")}
+
+	var narrative []string
+	if div := narrativeDiv(resource); div != "" {
+		narrative = append(narrative, div)
+	}
+	if resource["resourceType"] == "Composition" {
+		narrative = append(narrative, sectionNarratives(resource["section"])...)
+	}
+	doc.Narrative = truncateText(strings.Join(narrative, "\n"))
+
+	content := append([]string(nil), narrative...)
+	collectText(resource, &content)
+	doc.Content = truncateText(strings.Join(content, "\n"))
+	return doc
+}
+
+// narrativeDiv returns the plain text of the text.div of m.
+func narrativeDiv(m map[string]interface{}) string {
+	text, _ := m["text"].(map[string]interface{})
+	div, _ := text["div"].(string)
+	return stripMarkup(div)
+}
+
+// sectionNarratives returns the narratives of Composition sections, including
+// nested sections, in document order.
+func sectionNarratives(v interface{}) []string {
+	sections, _ := v.([]interface{})
+	var out []string
+	for _, s := range sections {
+		sec, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if div := narrativeDiv(sec); div != "" {
+			out = append(out, div)
+		}
+		out = append(out, sectionNarratives(sec["section"])...)
+	}
+	return out
+}
+
+// collectText appends the human-readable strings found in v to out. Object
+// keys are visited in sorted order so the output is deterministic. Contained
+// resources are skipped: they are indexed with neither their container nor on
+// their own.
+func collectText(v interface{}, out *[]string) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if text := attachmentText(val); text != "" {
+			*out = append(*out, text)
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			if !textSkipElements[k] && k != "contained" && !strings.HasPrefix(k, "_") {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			collectText(val[k], out)
+		}
+	case []interface{}:
+		for _, item := range val {
+			collectText(item, out)
+		}
+	case string:
+		if s := strings.TrimSpace(val); s != "" {
+			*out = append(*out, s)
+		}
+	}
+}
+
+// attachmentText returns the decoded data of a text Attachment, or "" when m
+// is not an attachment with a text/* content type.
+func attachmentText(m map[string]interface{}) string {
+	contentType, _ := m["contentType"].(string)
+	data, _ := m["data"].(string)
+	if data == "" || !strings.HasPrefix(strings.ToLower(contentType), "text/") {
+		return ""
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil || !utf8.Valid(raw) {
+		return ""
+	}
+	if strings.HasPrefix(strings.ToLower(contentType), "text/html") {
+		return stripMarkup(string(raw))
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+// stripMarkup returns the text content of an XHTML fragment with tags
+// removed and entities decoded.
+func stripMarkup(s string) string {
+	if s == "" {
+		return ""
+	}
+	var b strings.Builder
+	inTag := false
+	for _, r := range s {
+		switch {
+		case r == '<':
+			inTag = true
+			b.WriteByte(' ')
+		case r == '>' && inTag:
+			inTag = false
+		case !inTag:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(b.String())), " ")
+}
+
+// truncateText cuts s to maxIndexedTextBytes on a rune boundary.
+func truncateText(s string) string {
+	if len(s) <= maxIndexedTextBytes {
+		return s
+	}
+	s = s[:maxIndexedTextBytes]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// =========== In-memory store ===========
+
+// InMemoryTextIndex is a thread-safe in-memory implementation of TextIndexStore.
+type InMemoryTextIndex struct {
+	mu   sync.RWMutex
+	data map[string]TextDocument // key: "resourceType/resourceID"
+}
+
+// NewInMemoryTextIndex creates a new InMemoryTextIndex.
+func NewInMemoryTextIndex() *InMemoryTextIndex {
+	return &InMemoryTextIndex{data: make(map[string]TextDocument)}
+}
+
+// GetText returns the indexed document for a resource.
+func (s *InMemoryTextIndex) GetText(resourceType, resourceID string) (TextDocument, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	doc, ok := s.data[resourceType+"/"+resourceID]
+	return doc, ok
+}
+
+// IndexText replaces the indexed document for a resource.
+func (s *InMemoryTextIndex) IndexText(_ context.Context, resourceType, resourceID string, doc TextDocument) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[resourceType+"/"+resourceID] = doc
+	return nil
+}
+
+// DeleteText removes the indexed document for a resource.
+func (s *InMemoryTextIndex) DeleteText(_ context.Context, resourceType, resourceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, resourceType+"/"+resourceID)
+	return nil
+}
+
+// =========== PostgreSQL store ===========
+
+// TextIndexRepository stores text documents in the shared resource_text
+// table, whose tsvector columns are computed on write with the document's
+// text search configuration.
+type TextIndexRepository struct{}
+
+// NewTextIndexRepository creates a new TextIndexRepository.
+func NewTextIndexRepository() *TextIndexRepository {
+	return &TextIndexRepository{}
+}
+
+func (r *TextIndexRepository) conn(ctx context.Context) historyQuerier {
+	if tx := db.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	if c := db.ConnFromContext(ctx); c != nil {
+		return c
+	}
+	return nil
+}
+
+// IndexText replaces the indexed document for a resource.
+func (r *TextIndexRepository) IndexText(ctx context.Context, resourceType, resourceID string, doc TextDocument) error {
+	q := r.conn(ctx)
+	if q == nil {
+		return fmt.Errorf("no database connection in context")
+	}
+	language := doc.Language
+	if language == "" {
+		language = "english"
+	}
+	_, err := q.Exec(ctx, `
+		INSERT INTO resource_text (resource_type, resource_id, language, narrative, content,
+			narrative_tsv, content_tsv, updated_at)
+		VALUES ($1, $2, $3::regconfig, $4, $5,
+			to_tsvector($3::regconfig, $4), to_tsvector($3::regconfig, $5), NOW())
+		ON CONFLICT (resource_type, resource_id)
+		DO UPDATE SET language = EXCLUDED.language, narrative = EXCLUDED.narrative,
+			content = EXCLUDED.content, narrative_tsv = EXCLUDED.narrative_tsv,
+			content_tsv = EXCLUDED.content_tsv, updated_at = NOW()`,
+		resourceType, resourceID, language, doc.Narrative, doc.Content)
+	if err != nil {
+		return fmt.Errorf("index resource text: %w", err)
+	}
+	return nil
+}
+
+// DeleteText removes the indexed document for a resource.
+func (r *TextIndexRepository) DeleteText(ctx context.Context, resourceType, resourceID string) error {
+	q := r.conn(ctx)
+	if q == nil {
+		return fmt.Errorf("no database connection in context")
+	}
+	_, err := q.Exec(ctx, `DELETE FROM resource_text WHERE resource_type = $1 AND resource_id = $2`,
+		resourceType, resourceID)
+	if err != nil {
+		return fmt.Errorf("delete resource text: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package fhir
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestExtractTextDocument_NarrativeAndNotes(t *testing.T) {
+	doc := ExtractTextDocument(map[string]interface{}{
+		"resourceType": "Condition",
+		"id":           "c1",
+		"text": map[string]interface{}{
+			"status": "generated",
+			"div":    `<div xmlns="http://www.w3.org/1999/xhtml"><p>Community-acquired <b>pneumonia</b> &amp; fever</p></div>`,
+		},
+		"code":    map[string]interface{}{"coding": []interface{}{map[string]interface{}{"system": "http://snomed.info/sct", "code": "385093006", "display": "Community acquired pneumonia"}}},
+		"subject": map[string]interface{}{"reference": "Patient/p1"},
+		"note":    []interface{}{map[string]interface{}{"text": "Started amoxicillin"}},
+	})
+
+	if doc.Narrative != "Community-acquired pneumonia & fever" {
+		t.Errorf("unexpected narrative: %q", doc.Narrative)
+	}
+	if doc.Language != "english" {
+		t.Errorf("expected english config, got %q", doc.Language)
+	}
+	for _, want := range []string{"pneumonia & fever", "Started amoxicillin", "Community acquired pneumonia"} {
+		if !strings.Contains(doc.Content, want) {
+			t.Errorf("expected content to contain %q, got %q", want, doc.Content)
+		}
+	}
+	for _, unwanted := range []string{"Patient/p1", "snomed.info", "Condition"} {
+		if strings.Contains(doc.Content, unwanted) {
+			t.Errorf("expected content to exclude %q, got %q", unwanted, doc.Content)
+		}
+	}
+}
+
+func TestExtractTextDocument_CompositionSections(t *testing.T) {
+	doc := ExtractTextDocument(map[string]interface{}{
+		"resourceType": "Composition",
+		"title":        "Discharge summary",
+		"section": []interface{}{
+			map[string]interface{}{
+				"title": "Hospital course",
+				"text":  map[string]interface{}{"div": "<div>Treated for pneumonia.</div>"},
+				"section": []interface{}{
+					map[string]interface{}{"text": map[string]interface{}{"div": "<div>Afebrile on day 3.</div>"}},
+				},
+			},
+		},
+	})
+
+	if doc.Narrative != "Treated for pneumonia.\nAfebrile on day 3." {
+		t.Errorf("unexpected narrative: %q", doc.Narrative)
+	}
+	if !strings.Contains(doc.Content, "Hospital course") || !strings.Contains(doc.Content, "Discharge summary") {
+		t.Errorf("expected section and document titles in content, got %q", doc.Content)
+	}
+}
+
+func TestExtractTextDocument_TextAttachments(t *testing.T) {
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	doc := ExtractTextDocument(map[string]interface{}{
+		"resourceType": "DocumentReference",
+		"language":     "es-MX",
+		"content": []interface{}{
+			map[string]interface{}{"attachment": map[string]interface{}{"contentType": "text/plain", "data": encode("Neumonía lobar derecha")}},
+			map[string]interface{}{"attachment": map[string]interface{}{"contentType": "text/html", "data": encode("<p>Radiografía <i>anormal</i></p>")}},
+			map[string]interface{}{"attachment": map[string]interface{}{"contentType": "application/pdf", "data": encode("%PDF-1.4 binary")}},
+		},
+	})
+
+	if doc.Language != "spanish" {
+		t.Errorf("expected spanish config, got %q", doc.Language)
+	}
+	if !strings.Contains(doc.Content, "Neumonía lobar derecha") || !strings.Contains(doc.Content, "Radiografía anormal") {
+		t.Errorf("expected decoded text attachments, got %q", doc.Content)
+	}
+	if strings.Contains(doc.Content, "PDF") {
+		t.Errorf("expected binary attachment to be skipped, got %q", doc.Content)
+	}
+}
+
+func TestTextSearchConfig(t *testing.T) {
+	tests := []struct{ lang, want string }{
+		{"", "english"},
+		{"en-GB", "english"},
+		{"DE", "german"},
+		{"nb-NO", "norwegian"},
+		{"zh-CN", "simple"},
+	}
+	for _, tt := range tests {
+		if got := TextSearchConfig(tt.lang, "english"); got != tt.want {
+			t.Errorf("TextSearchConfig(%q) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestVersionTracker_TextIndex(t *testing.T) {
+	vt := NewVersionTracker(NewHistoryRepository())
+	index := NewInMemoryTextIndex()
+	vt.SetTextIndex(index)
+	ctx := context.Background() // no DB: indexing happens before the history write fails
+
+	_ = vt.RecordCreate(ctx, "Observation", "o1", map[string]interface{}{
+		"resourceType": "Observation",
+		"note":         []map[string]interface{}{{"text": "Possible pneumonia"}},
+	})
+	doc, ok := index.GetText("Observation", "o1")
+	if !ok || !strings.Contains(doc.Content, "Possible pneumonia") {
+		t.Fatalf("expected indexed note text, got %+v (found=%v)", doc, ok)
+	}
+
+	_ = vt.RecordDelete(ctx, "Observation", "o1", 1)
+	if _, ok := index.GetText("Observation", "o1"); ok {
+		t.Error("expected text to be removed on delete")
+	}
+}
@@ -291,8 +291,15 @@ func extractReferences(resource map[string]interface{}) []string {
 }
 
 // TransactionProcessor handles the execution of transaction and batch Bundles.
+// When Dispatcher is set, entries are executed through it (see
+// ExecuteTransaction); otherwise ResourceHandler is invoked for each entry.
+// When Locker is set, entries that update or delete a resource hold a lock on
+// it from the ifMatch check until the write completes.
 type TransactionProcessor struct {
 	ResourceHandler func(method, url string, resource map[string]interface{}) (*BundleEntryResponse, error)
+	Dispatcher      EntryDispatcher
+	BeginTx         BeginTxFunc
+	Locker          ResourceLocker
 }
 
 // NewTransactionProcessor creates a new TransactionProcessor with the given
@@ -531,6 +538,24 @@ func TransactionHandler(processor *TransactionProcessor) echo.HandlerFunc {
 			}
 		}
 
+		if processor.Dispatcher != nil {
+			ctx := c.Request().Context()
+			header := dispatchHeaders(c.Request().Header)
+			switch bundle.Type {
+			case "transaction":
+				result, err := processor.ExecuteTransaction(ctx, bundle, header)
+				if err != nil {
+					if entryErr, ok := err.(*TransactionEntryError); ok {
+						return c.JSON(entryErr.Status, entryErr.Outcome)
+					}
+					return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
+				}
+				return c.JSON(http.StatusOK, result)
+			case "batch":
+				return c.JSON(http.StatusOK, processor.ExecuteBatch(ctx, bundle, header))
+			}
+		}
+
 		switch bundle.Type {
 		case "transaction":
 			result, err := processor.ProcessTransaction(bundle)
@@ -0,0 +1,670 @@
+package fhir
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// TxFinisher is the subset of pgx.Tx needed to complete a transaction Bundle.
+type TxFinisher interface {
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// BeginTxFunc starts a database transaction and returns a context carrying it.
+type BeginTxFunc func(ctx context.Context) (context.Context, TxFinisher, error)
+
+// DefaultBeginTx begins a transaction on the tenant-scoped connection held in
+// the context. Domain repositories pick the transaction up via db.TxFromContext.
+func DefaultBeginTx(ctx context.Context) (context.Context, TxFinisher, error) {
+	txCtx, tx, err := db.WithTx(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return txCtx, tx, nil
+}
+
+// EntryDispatchRequest describes a single Bundle entry to execute.
+type EntryDispatchRequest struct {
+	Method      string
+	URL         string // Relative FHIR URL, e.g. "Patient/123" or "Observation?code=x"
+	Body        []byte
+	ContentType string
+	Header      http.Header
+}
+
+// EntryDispatchResult holds the raw HTTP outcome of a dispatched entry.
+type EntryDispatchResult struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Resource decodes the response body as a JSON object. It returns nil when the
+// body is empty or is not a JSON object.
+func (r *EntryDispatchResult) Resource() map[string]interface{} {
+	if len(r.Body) == 0 {
+		return nil
+	}
+	var res map[string]interface{}
+	if err := json.Unmarshal(r.Body, &res); err != nil {
+		return nil
+	}
+	return res
+}
+
+// EntryDispatcher executes a single Bundle entry against the server's
+// resource handlers.
+type EntryDispatcher interface {
+	Dispatch(ctx context.Context, req *EntryDispatchRequest) (*EntryDispatchResult, error)
+}
+
+// EchoEntryDispatcher dispatches Bundle entries through the Echo router so
+// that every entry is served by the same domain handler (including its
+// route-level role checks) as the equivalent standalone REST call. Global
+// middleware is not re-run: the context passed to Dispatch already carries
+// the authenticated identity, tenant connection and active transaction.
+type EchoEntryDispatcher struct {
+	echo     *echo.Echo
+	basePath string
+}
+
+// NewEchoEntryDispatcher creates a dispatcher that routes entries to the
+// handlers registered under basePath (typically "/fhir").
+func NewEchoEntryDispatcher(e *echo.Echo, basePath string) *EchoEntryDispatcher {
+	return &EchoEntryDispatcher{echo: e, basePath: strings.TrimRight(basePath, "/")}
+}
+
+// Dispatch executes the entry and captures the handler's response.
+func (d *EchoEntryDispatcher) Dispatch(ctx context.Context, req *EntryDispatchRequest) (*EntryDispatchResult, error) {
+	target := d.basePath + "/" + strings.TrimLeft(req.URL, "/")
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, target, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, fmt.Errorf("build request for %s %s: %w", req.Method, req.URL, err)
+	}
+	for k, v := range req.Header {
+		httpReq.Header[k] = append([]string(nil), v...)
+	}
+	if len(req.Body) > 0 {
+		ct := req.ContentType
+		if ct == "" {
+			ct = echo.MIMEApplicationJSON
+		}
+		httpReq.Header.Set(echo.HeaderContentType, ct)
+	} else {
+		httpReq.Header.Del(echo.HeaderContentType)
+	}
+	httpReq.Header.Set(echo.HeaderAccept, "application/fhir+json")
+
+	rec := newEntryRecorder()
+	c := d.echo.NewContext(httpReq, rec)
+	d.echo.Router().Find(httpReq.Method, echo.GetPath(httpReq), c)
+	if err := c.Handler()(c); err != nil {
+		d.echo.HTTPErrorHandler(err, c)
+	}
+
+	return &EntryDispatchResult{
+		StatusCode: rec.status,
+		Header:     rec.header,
+		Body:       rec.body.Bytes(),
+	}, nil
+}
+
+// entryRecorder is a minimal http.ResponseWriter that buffers a response.
+type entryRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func newEntryRecorder() *entryRecorder {
+	return &entryRecorder{header: make(http.Header), status: http.StatusOK}
+}
+
+func (r *entryRecorder) Header() http.Header { return r.header }
+
+func (r *entryRecorder) WriteHeader(status int) {
+	if r.wrote {
+		return
+	}
+	r.status = status
+	r.wrote = true
+}
+
+func (r *entryRecorder) Write(b []byte) (int, error) {
+	if !r.wrote {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.body.Write(b)
+}
+
+// TransactionEntryError reports the entry that caused a transaction to fail,
+// along with the HTTP status and OperationOutcome to return to the client.
+type TransactionEntryError struct {
+	Index   int
+	Status  int
+	Outcome *OperationOutcome
+}
+
+func (e *TransactionEntryError) Error() string {
+	if e.Outcome != nil && len(e.Outcome.Issue) > 0 {
+		return e.Outcome.Issue[0].Diagnostics
+	}
+	return http.StatusText(e.Status)
+}
+
+func entryError(status int, code, diagnostics string) *TransactionEntryError {
+	return &TransactionEntryError{
+		Index:   -1,
+		Status:  status,
+		Outcome: NewOperationOutcome(IssueSeverityError, code, diagnostics),
+	}
+}
+
+// NewDispatchingTransactionProcessor creates a TransactionProcessor that
+// executes entries through the given dispatcher. Transactions run inside a
+// single database transaction started with DefaultBeginTx.
+func NewDispatchingTransactionProcessor(dispatcher EntryDispatcher) *TransactionProcessor {
+	return &TransactionProcessor{
+		Dispatcher: dispatcher,
+		BeginTx:    DefaultBeginTx,
+	}
+}
+
+// ExecuteTransaction processes a transaction Bundle atomically using the
+// processor's dispatcher. Entries are executed in FHIR processing order
+// (DELETE, POST, PUT/PATCH, GET/HEAD), urn:uuid references are rewritten to
+// the server-assigned ids, and the first failing entry rolls back every
+// change. The response entries follow the order of the request entries.
+func (p *TransactionProcessor) ExecuteTransaction(ctx context.Context, bundle *TransactionBundle, header http.Header) (*Bundle, error) {
+	begin := p.BeginTx
+	if begin == nil {
+		begin = DefaultBeginTx
+	}
+	txCtx, tx, err := begin(ctx)
+	if err != nil {
+		return nil, entryError(http.StatusInternalServerError, IssueTypeException,
+			"failed to begin transaction: "+err.Error())
+	}
+
+	idMap := make(map[string]string)
+	responses := make([]BundleEntry, len(bundle.Entries))
+
+	for _, i := range transactionEntryOrder(bundle.Entries) {
+		entry := bundle.Entries[i]
+		respEntry, entryErr := p.executeEntry(txCtx, &entry, idMap, header)
+		if entryErr != nil {
+			_ = tx.Rollback(ctx)
+			entryErr.Index = i
+			for j := range entryErr.Outcome.Issue {
+				entryErr.Outcome.Issue[j].Diagnostics = fmt.Sprintf("transaction failed at entry[%d] (%s %s): %s",
+					i, entry.Request.Method, entry.Request.URL, entryErr.Outcome.Issue[j].Diagnostics)
+				entryErr.Outcome.Issue[j].Expression = append(entryErr.Outcome.Issue[j].Expression,
+					fmt.Sprintf("Bundle.entry[%d]", i))
+			}
+			return nil, entryErr
+		}
+		responses[i] = respEntry
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, entryError(http.StatusInternalServerError, IssueTypeException,
+			"failed to commit transaction: "+err.Error())
+	}
+
+	now := time.Now().UTC()
+	return &Bundle{
+		ResourceType: "Bundle",
+		Type:         "transaction-response",
+		Timestamp:    &now,
+		Entry:        responses,
+	}, nil
+}
+
+// ExecuteBatch processes a batch Bundle using the processor's dispatcher.
+// Each entry is independent: failures are reported in that entry's response
+// and do not affect the others.
+func (p *TransactionProcessor) ExecuteBatch(ctx context.Context, bundle *TransactionBundle, header http.Header) *Bundle {
+	responses := make([]BundleEntry, len(bundle.Entries))
+	for i := range bundle.Entries {
+		entry := bundle.Entries[i]
+		respEntry, entryErr := p.executeEntry(ctx, &entry, nil, header)
+		if entryErr != nil {
+			responses[i] = BundleEntry{
+				Response: &BundleResponse{
+					Status:  statusLine(entryErr.Status),
+					Outcome: entryErr.Outcome,
+				},
+			}
+			continue
+		}
+		responses[i] = respEntry
+	}
+
+	now := time.Now().UTC()
+	return &Bundle{
+		ResourceType: "Bundle",
+		Type:         "batch-response",
+		Timestamp:    &now,
+		Entry:        responses,
+	}
+}
+
+// executeEntry runs a single entry, applying urn:uuid resolution and the
+// conditional create/update/delete and If-Match semantics of the entry's
+// request element. idMap may be nil for batch processing.
+func (p *TransactionProcessor) executeEntry(ctx context.Context, entry *TransactionEntry, idMap map[string]string, header http.Header) (BundleEntry, *TransactionEntryError) {
+	method := strings.ToUpper(entry.Request.Method)
+	reqURL := entry.Request.URL
+	ifNoneExist := entry.Request.IfNoneExist
+	if len(idMap) > 0 {
+		if entry.Resource != nil {
+			resolveRefsInResource(entry.Resource, idMap)
+		}
+		reqURL = replaceURNRefs(reqURL, idMap)
+		ifNoneExist = replaceURNRefs(ifNoneExist, idMap)
+	}
+	reqURL = strings.TrimPrefix(reqURL, "/")
+	resourceType, id, isSearch := ParseEntryURL(reqURL)
+
+	switch method {
+	case http.MethodPost:
+		if ifNoneExist != "" {
+			matches, count, err := searchMatches(ctx, p.Dispatcher, resourceType, ifNoneExist, 2, header)
+			if err != nil {
+				return BundleEntry{}, err
+			}
+			switch count {
+			case 0:
+			case 1:
+				location := resourceType + "/" + matches[0]
+				recordFullURL(idMap, entry.FullURL, location)
+				return BundleEntry{
+					FullURL:  location,
+					Response: &BundleResponse{Status: "200 OK", Location: location},
+				}, nil
+			default:
+				return BundleEntry{}, entryError(http.StatusPreconditionFailed, IssueTypeDuplicate,
+					fmt.Sprintf("ifNoneExist criteria %q matched %d resources", ifNoneExist, count))
+			}
+		}
+
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		if isSearch {
+			query := reqURL[strings.Index(reqURL, "?")+1:]
+			matches, count, err := searchMatches(ctx, p.Dispatcher, resourceType, query, 2, header)
+			if err != nil {
+				return BundleEntry{}, err
+			}
+			switch {
+			case count == 1:
+				id = matches[0]
+				reqURL = resourceType + "/" + id
+			case count > 1:
+				return BundleEntry{}, entryError(http.StatusPreconditionFailed, IssueTypeMultipleMatches,
+					fmt.Sprintf("conditional %s criteria %q matched %d resources", method, query, count))
+			case method == http.MethodPut:
+				// No match: the conditional update becomes a create.
+				method = http.MethodPost
+				reqURL = resourceType
+			case method == http.MethodDelete:
+				return BundleEntry{Response: &BundleResponse{Status: "204 No Content"}}, nil
+			default:
+				return BundleEntry{}, entryError(http.StatusNotFound, IssueTypeNotFound,
+					fmt.Sprintf("conditional PATCH criteria %q matched no resources", query))
+			}
+		}
+		if id != "" && p.Locker != nil {
+			unlock, err := p.Locker.Lock(ctx, resourceType+"/"+id)
+			if err != nil {
+				return BundleEntry{}, entryError(http.StatusInternalServerError, IssueTypeException,
+					fmt.Sprintf("failed to lock %s/%s: %s", resourceType, id, err.Error()))
+			}
+			defer unlock()
+		}
+		if entry.Request.IfMatch != "" && id != "" {
+			if err := checkIfMatch(ctx, p.Dispatcher, resourceType, id, entry.Request.IfMatch, header); err != nil {
+				return BundleEntry{}, err
+			}
+		}
+	}
+
+	body, contentType, encErr := entryBody(method, entry.Resource)
+	if encErr != nil {
+		return BundleEntry{}, encErr
+	}
+
+	result, err := p.Dispatcher.Dispatch(ctx, &EntryDispatchRequest{
+		Method:      method,
+		URL:         reqURL,
+		Body:        body,
+		ContentType: contentType,
+		Header:      header,
+	})
+	if err != nil {
+		return BundleEntry{}, entryError(http.StatusInternalServerError, IssueTypeException, err.Error())
+	}
+	if result.StatusCode >= 400 {
+		return BundleEntry{}, &TransactionEntryError{
+			Index:   -1,
+			Status:  result.StatusCode,
+			Outcome: outcomeFromResult(result),
+		}
+	}
+
+	resource := result.Resource()
+	location := normalizeLocation(result.Header.Get("Location"))
+	if location == "" && resource != nil {
+		if rt, _ := resource["resourceType"].(string); rt != "" && rt != "OperationOutcome" && rt != "Bundle" {
+			if rid, _ := resource["id"].(string); rid != "" {
+				location = rt + "/" + rid
+			}
+		}
+	}
+	if method == http.MethodPost || method == http.MethodPut {
+		recordFullURL(idMap, entry.FullURL, location)
+	}
+
+	resp := &BundleResponse{Status: statusLine(result.StatusCode)}
+	if t, err := http.ParseTime(result.Header.Get("Last-Modified")); err == nil {
+		resp.LastModified = &t
+	} else if t, err := time.Parse(time.RFC3339, result.Header.Get("Last-Modified")); err == nil {
+		resp.LastModified = &t
+	}
+	if method != http.MethodDelete && method != http.MethodGet && method != http.MethodHead && location != "" {
+		resp.Location = location
+		if vid := resourceVersionID(resource); vid != "" {
+			resp.Location = location + "/_history/" + vid
+		}
+	}
+
+	respEntry := BundleEntry{Response: resp}
+	if location != "" && method != http.MethodDelete {
+		respEntry.FullURL = location
+	}
+	if method != http.MethodDelete && method != http.MethodHead && len(result.Body) > 0 && resource != nil {
+		respEntry.Resource = json.RawMessage(result.Body)
+	}
+	return respEntry, nil
+}
+
+// searchMatches runs a type-level search through d and returns the ids of up
+// to limit matching resources along with the total number of matches.
+// Entries with a search.mode other than "match" are ignored.
+func searchMatches(ctx context.Context, d EntryDispatcher, resourceType, query string, limit int, header http.Header) ([]string, int, *TransactionEntryError) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, 0, entryError(http.StatusBadRequest, IssueTypeInvalid,
+			fmt.Sprintf("invalid conditional criteria %q: %s", query, err.Error()))
+	}
+	values.Set("_count", strconv.Itoa(limit))
+	result, dErr := d.Dispatch(ctx, &EntryDispatchRequest{
+		Method: http.MethodGet,
+		URL:    resourceType + "?" + values.Encode(),
+		Header: header,
+	})
+	if dErr != nil {
+		return nil, 0, entryError(http.StatusInternalServerError, IssueTypeException, dErr.Error())
+	}
+	if result.StatusCode >= 400 {
+		return nil, 0, &TransactionEntryError{Index: -1, Status: result.StatusCode, Outcome: outcomeFromResult(result)}
+	}
+
+	var bundle struct {
+		Total *int `json:"total"`
+		Entry []struct {
+			Resource struct {
+				ID string `json:"id"`
+			} `json:"resource"`
+			Search *BundleSearch `json:"search"`
+		} `json:"entry"`
+	}
+	if err := json.Unmarshal(result.Body, &bundle); err != nil {
+		return nil, 0, entryError(http.StatusInternalServerError, IssueTypeException,
+			"conditional search did not return a Bundle: "+err.Error())
+	}
+	var ids []string
+	for _, e := range bundle.Entry {
+		if e.Search != nil && e.Search.Mode != "" && e.Search.Mode != "match" {
+			continue
+		}
+		if e.Resource.ID != "" {
+			ids = append(ids, e.Resource.ID)
+		}
+	}
+	// The page is capped at limit entries; prefer the reported total so that
+	// callers can still tell when there are more matches.
+	count := len(ids)
+	if bundle.Total != nil && *bundle.Total > count {
+		count = *bundle.Total
+	}
+	return ids, count, nil
+}
+
+// checkIfMatch reads the current resource through d and compares its
+// meta.versionId with the ifMatch ETag, returning 412 on mismatch.
+func checkIfMatch(ctx context.Context, d EntryDispatcher, resourceType, id, ifMatch string, header http.Header) *TransactionEntryError {
+	expected, err := ParseETag(ifMatch)
+	if err != nil {
+		return entryError(http.StatusBadRequest, IssueTypeInvalid, "invalid If-Match ETag: "+err.Error())
+	}
+	result, dErr := d.Dispatch(ctx, &EntryDispatchRequest{
+		Method: http.MethodGet,
+		URL:    resourceType + "/" + id,
+		Header: header,
+	})
+	if dErr != nil {
+		return entryError(http.StatusInternalServerError, IssueTypeException, dErr.Error())
+	}
+	if result.StatusCode >= 400 {
+		return &TransactionEntryError{Index: -1, Status: result.StatusCode, Outcome: outcomeFromResult(result)}
+	}
+	current := resourceVersionID(result.Resource())
+	if current == "" {
+		if etag := result.Header.Get("ETag"); etag != "" {
+			if v, err := ParseETag(etag); err == nil {
+				current = strconv.Itoa(v)
+			}
+		}
+	}
+	if current != strconv.Itoa(expected) {
+		return entryError(http.StatusPreconditionFailed, IssueTypeConflict,
+			fmt.Sprintf("version conflict: expected version %d but %s/%s is at version %s",
+				expected, resourceType, id, current))
+	}
+	return nil
+}
+
+// entryBody encodes the entry resource as the request body. PATCH entries
+// carrying a Binary resource are sent with the Binary's contentType and
+// decoded data, which is how JSON Patch documents travel in a Bundle.
+func entryBody(method string, resource map[string]interface{}) ([]byte, string, *TransactionEntryError) {
+	if resource == nil || method == http.MethodGet || method == http.MethodHead || method == http.MethodDelete {
+		return nil, "", nil
+	}
+	if method == http.MethodPatch {
+		switch resource["resourceType"] {
+		case "Binary":
+			ct, _ := resource["contentType"].(string)
+			data, _ := resource["data"].(string)
+			decoded, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				return nil, "", entryError(http.StatusBadRequest, IssueTypeInvalid, "invalid Binary.data in PATCH entry: "+err.Error())
+			}
+			return decoded, ct, nil
+		case "Parameters":
+			return nil, "", entryError(http.StatusBadRequest, IssueTypeNotSupported,
+				"FHIRPath Patch (Parameters) is not supported; use a Binary containing a JSON Patch document")
+		}
+		body, err := json.Marshal(resource)
+		if err != nil {
+			return nil, "", entryError(http.StatusBadRequest, IssueTypeInvalid, err.Error())
+		}
+		return body, "application/merge-patch+json", nil
+	}
+	body, err := json.Marshal(resource)
+	if err != nil {
+		return nil, "", entryError(http.StatusBadRequest, IssueTypeInvalid, err.Error())
+	}
+	// The domain handlers bind with Echo, which only accepts application/json.
+	return body, echo.MIMEApplicationJSON, nil
+}
+
+// transactionEntryOrder returns the indices of entries in FHIR processing
+// order. POST entries are additionally ordered so that an entry whose
+// urn:uuid fullUrl is referenced by another POST is created first, allowing
+// the reference to be rewritten to the assigned id.
+func transactionEntryOrder(entries []TransactionEntry) []int {
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return methodSortOrder[strings.ToUpper(entries[order[a]].Request.Method)] <
+			methodSortOrder[strings.ToUpper(entries[order[b]].Request.Method)]
+	})
+
+	var posts []int
+	start := -1
+	for pos, i := range order {
+		if strings.ToUpper(entries[i].Request.Method) == http.MethodPost {
+			if start < 0 {
+				start = pos
+			}
+			posts = append(posts, i)
+		}
+	}
+	if len(posts) < 2 {
+		return order
+	}
+
+	byURL := make(map[string]int)
+	for _, i := range posts {
+		if entries[i].FullURL != "" {
+			byURL[entries[i].FullURL] = i
+		}
+	}
+	visited := make(map[int]bool)
+	sorted := make([]int, 0, len(posts))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		if entries[i].Resource != nil {
+			for _, ref := range extractReferences(entries[i].Resource) {
+				if dep, ok := byURL[ref]; ok && dep != i {
+					visit(dep)
+				}
+			}
+		}
+		sorted = append(sorted, i)
+	}
+	for _, i := range posts {
+		visit(i)
+	}
+	copy(order[start:start+len(sorted)], sorted)
+	return order
+}
+
+// recordFullURL maps a urn:uuid fullUrl to the server-assigned location.
+func recordFullURL(idMap map[string]string, fullURL, location string) {
+	if idMap == nil || location == "" || !strings.HasPrefix(fullURL, "urn:uuid:") {
+		return
+	}
+	idMap[fullURL] = location
+}
+
+// normalizeLocation reduces a Location header such as
+// "http://host/fhir/Patient/123/_history/2" to "Patient/123".
+func normalizeLocation(loc string) string {
+	if loc == "" {
+		return ""
+	}
+	if u, err := url.Parse(loc); err == nil {
+		loc = u.Path
+	}
+	loc = strings.TrimPrefix(loc, "/")
+	if idx := strings.Index(loc, "/_history"); idx >= 0 {
+		loc = loc[:idx]
+	}
+	parts := strings.Split(loc, "/")
+	if len(parts) < 2 {
+		return loc
+	}
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1]
+}
+
+// resourceVersionID returns meta.versionId of a resource, or "" if absent.
+func resourceVersionID(resource map[string]interface{}) string {
+	if resource == nil {
+		return ""
+	}
+	meta, _ := resource["meta"].(map[string]interface{})
+	if meta == nil {
+		return ""
+	}
+	vid, _ := meta["versionId"].(string)
+	return vid
+}
+
+// outcomeFromResult converts an error response into an OperationOutcome,
+// wrapping non-FHIR error bodies such as Echo's {"message": "..."}.
+func outcomeFromResult(result *EntryDispatchResult) *OperationOutcome {
+	var oo OperationOutcome
+	if err := json.Unmarshal(result.Body, &oo); err == nil && oo.ResourceType == "OperationOutcome" && len(oo.Issue) > 0 {
+		return &oo
+	}
+	msg := http.StatusText(result.StatusCode)
+	var echoErr struct {
+		Message interface{} `json:"message"`
+	}
+	if err := json.Unmarshal(result.Body, &echoErr); err == nil && echoErr.Message != nil {
+		msg = fmt.Sprint(echoErr.Message)
+	}
+	code := IssueTypeProcessing
+	switch result.StatusCode {
+	case http.StatusNotFound:
+		code = IssueTypeNotFound
+	case http.StatusUnauthorized:
+		code = IssueTypeLogin
+	case http.StatusForbidden:
+		code = IssueTypeSecurity
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		code = IssueTypeConflict
+	}
+	return NewOperationOutcome(IssueSeverityError, code, msg)
+}
+
+// statusLine formats an HTTP status code as a Bundle response status.
+func statusLine(code int) string {
+	if code == 0 {
+		code = http.StatusOK
+	}
+	return fmt.Sprintf("%d %s", code, http.StatusText(code))
+}
+
+// dispatchHeaders returns the request headers that are forwarded to each
+// dispatched entry. Entity headers of the outer Bundle request are dropped.
+func dispatchHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for _, k := range []string{echo.HeaderContentType, echo.HeaderContentLength, "If-Match", "If-None-Exist", "If-None-Match", "Prefer", "Idempotency-Key"} {
+		out.Del(k)
+	}
+	return out
+}
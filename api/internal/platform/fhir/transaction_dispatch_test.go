@@ -0,0 +1,358 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ---------------------------------------------------------------------------
+// Test helpers
+// ---------------------------------------------------------------------------
+
+type fakeTx struct {
+	committed  bool
+	rolledBack bool
+}
+
+func (t *fakeTx) Commit(ctx context.Context) error   { t.committed = true; return nil }
+func (t *fakeTx) Rollback(ctx context.Context) error { t.rolledBack = true; return nil }
+
+// newDispatchTestServer builds an Echo instance with a tiny in-memory
+// Patient/Observation store exposed through FHIR-style routes.
+func newDispatchTestServer() (*echo.Echo, map[string]map[string]interface{}) {
+	store := make(map[string]map[string]interface{})
+	next := 0
+	e := echo.New()
+	g := e.Group("/fhir")
+
+	create := func(rt string) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var res map[string]interface{}
+			if err := json.NewDecoder(c.Request().Body).Decode(&res); err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorOutcome(err.Error()))
+			}
+			if rt == "Observation" {
+				subj, _ := res["subject"].(map[string]interface{})
+				if ref, _ := subj["reference"].(string); !strings.HasPrefix(ref, "Patient/") {
+					return c.JSON(http.StatusBadRequest, ErrorOutcome("subject must reference a Patient"))
+				}
+			}
+			next++
+			id := rt[:1] + string(rune('0'+next))
+			res["id"] = id
+			res["meta"] = map[string]interface{}{"versionId": "1"}
+			store[rt+"/"+id] = res
+			c.Response().Header().Set("Location", "/fhir/"+rt+"/"+id)
+			return c.JSON(http.StatusCreated, res)
+		}
+	}
+	read := func(rt string) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			res, ok := store[rt+"/"+c.Param("id")]
+			if !ok {
+				return c.JSON(http.StatusNotFound, NotFoundOutcome(rt, c.Param("id")))
+			}
+			return c.JSON(http.StatusOK, res)
+		}
+	}
+	update := func(rt string) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := rt + "/" + c.Param("id")
+			if _, ok := store[key]; !ok {
+				return c.JSON(http.StatusNotFound, NotFoundOutcome(rt, c.Param("id")))
+			}
+			var res map[string]interface{}
+			if err := json.NewDecoder(c.Request().Body).Decode(&res); err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorOutcome(err.Error()))
+			}
+			res["id"] = c.Param("id")
+			res["meta"] = map[string]interface{}{"versionId": "2"}
+			store[key] = res
+			return c.JSON(http.StatusOK, res)
+		}
+	}
+	search := func(rt string) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var resources []interface{}
+			for key, res := range store {
+				if !strings.HasPrefix(key, rt+"/") {
+					continue
+				}
+				if id := c.QueryParam("_id"); id != "" && res["id"] != id {
+					continue
+				}
+				resources = append(resources, res)
+			}
+			return c.JSON(http.StatusOK, NewSearchBundle(resources, len(resources), "/fhir/"+rt))
+		}
+	}
+	for _, rt := range []string{"Patient", "Observation"} {
+		g.POST("/"+rt, create(rt))
+		g.GET("/"+rt, search(rt))
+		g.GET("/"+rt+"/:id", read(rt))
+		g.PUT("/"+rt+"/:id", update(rt))
+		g.DELETE("/"+rt+"/:id", func(c echo.Context) error {
+			delete(store, c.Path()[len("/fhir/"):strings.LastIndex(c.Path(), "/")]+"/"+c.Param("id"))
+			return c.NoContent(http.StatusNoContent)
+		})
+	}
+	g.GET("/Secret", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusForbidden, "required role: admin")
+	})
+	return e, store
+}
+
+func newTestDispatchProcessor(e *echo.Echo, tx *fakeTx) *TransactionProcessor {
+	p := NewDispatchingTransactionProcessor(NewEchoEntryDispatcher(e, "/fhir"))
+	p.BeginTx = func(ctx context.Context) (context.Context, TxFinisher, error) {
+		return ctx, tx, nil
+	}
+	return p
+}
+
+// ---------------------------------------------------------------------------
+// ExecuteTransaction tests
+// ---------------------------------------------------------------------------
+
+func TestExecuteTransaction_ResolvesURNReferencesInDependencyOrder(t *testing.T) {
+	e, store := newDispatchTestServer()
+	tx := &fakeTx{}
+	p := newTestDispatchProcessor(e, tx)
+
+	// The Observation appears first but references the Patient, so the
+	// Patient must be created first for the reference to be rewritten.
+	bundle, err := ParseTransactionBundle([]byte(`{
+		"resourceType": "Bundle", "type": "transaction",
+		"entry": [
+			{"fullUrl": "urn:uuid:obs", "resource": {"resourceType": "Observation", "subject": {"reference": "urn:uuid:pat"}},
+			 "request": {"method": "POST", "url": "Observation"}},
+			{"fullUrl": "urn:uuid:pat", "resource": {"resourceType": "Patient"},
+			 "request": {"method": "POST", "url": "Patient"}}
+		]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := p.ExecuteTransaction(context.Background(), bundle, http.Header{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !tx.committed || tx.rolledBack {
+		t.Errorf("expected commit only, got committed=%v rolledBack=%v", tx.committed, tx.rolledBack)
+	}
+	if result.Type != "transaction-response" || len(result.Entry) != 2 {
+		t.Fatalf("unexpected response bundle: %+v", result)
+	}
+	// Response entries follow request order.
+	if !strings.HasPrefix(result.Entry[0].Response.Location, "Observation/") {
+		t.Errorf("expected entry[0] to be the Observation, got %q", result.Entry[0].Response.Location)
+	}
+	if result.Entry[1].Response.Status != "201 Created" {
+		t.Errorf("expected 201 Created, got %q", result.Entry[1].Response.Status)
+	}
+	if !strings.HasSuffix(result.Entry[1].Response.Location, "/_history/1") {
+		t.Errorf("expected versioned location, got %q", result.Entry[1].Response.Location)
+	}
+
+	obs := store[result.Entry[0].FullURL]
+	subj := obs["subject"].(map[string]interface{})
+	if subj["reference"] != result.Entry[1].FullURL {
+		t.Errorf("expected subject %q, got %v", result.Entry[1].FullURL, subj["reference"])
+	}
+}
+
+func TestExecuteTransaction_RollsBackOnFailure(t *testing.T) {
+	e, _ := newDispatchTestServer()
+	tx := &fakeTx{}
+	p := newTestDispatchProcessor(e, tx)
+
+	bundle, _ := ParseTransactionBundle([]byte(`{
+		"resourceType": "Bundle", "type": "transaction",
+		"entry": [
+			{"fullUrl": "urn:uuid:pat", "resource": {"resourceType": "Patient"}, "request": {"method": "POST", "url": "Patient"}},
+			{"fullUrl": "urn:uuid:obs", "resource": {"resourceType": "Observation", "subject": {"reference": "Group/1"}},
+			 "request": {"method": "POST", "url": "Observation"}}
+		]}`))
+
+	_, err := p.ExecuteTransaction(context.Background(), bundle, http.Header{})
+	entryErr, ok := err.(*TransactionEntryError)
+	if !ok {
+		t.Fatalf("expected *TransactionEntryError, got %v", err)
+	}
+	if entryErr.Index != 1 || entryErr.Status != http.StatusBadRequest {
+		t.Errorf("expected entry 1 / 400, got %d / %d", entryErr.Index, entryErr.Status)
+	}
+	if !tx.rolledBack || tx.committed {
+		t.Errorf("expected rollback only, got committed=%v rolledBack=%v", tx.committed, tx.rolledBack)
+	}
+	if !strings.Contains(entryErr.Outcome.Issue[0].Diagnostics, "subject must reference a Patient") {
+		t.Errorf("expected handler diagnostics, got %q", entryErr.Outcome.Issue[0].Diagnostics)
+	}
+}
+
+func TestExecuteTransaction_IfNoneExistReusesExisting(t *testing.T) {
+	e, store := newDispatchTestServer()
+	store["Patient/p9"] = map[string]interface{}{"resourceType": "Patient", "id": "p9"}
+	p := newTestDispatchProcessor(e, &fakeTx{})
+
+	bundle, _ := ParseTransactionBundle([]byte(`{
+		"resourceType": "Bundle", "type": "transaction",
+		"entry": [
+			{"fullUrl": "urn:uuid:pat", "resource": {"resourceType": "Patient"},
+			 "request": {"method": "POST", "url": "Patient", "ifNoneExist": "_id=p9"}},
+			{"fullUrl": "urn:uuid:obs", "resource": {"resourceType": "Observation", "subject": {"reference": "urn:uuid:pat"}},
+			 "request": {"method": "POST", "url": "Observation"}}
+		]}`))
+
+	result, err := p.ExecuteTransaction(context.Background(), bundle, http.Header{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Entry[0].Response.Status != "200 OK" || result.Entry[0].Response.Location != "Patient/p9" {
+		t.Errorf("expected existing Patient/p9, got %+v", result.Entry[0].Response)
+	}
+	obs := store[result.Entry[1].FullURL]
+	if obs["subject"].(map[string]interface{})["reference"] != "Patient/p9" {
+		t.Errorf("expected reference rewritten to Patient/p9, got %v", obs["subject"])
+	}
+	if len(store) != 2 {
+		t.Errorf("expected no duplicate Patient, store has %d resources", len(store))
+	}
+}
+
+func TestExecuteTransaction_IfMatchConflict(t *testing.T) {
+	e, store := newDispatchTestServer()
+	store["Patient/p1"] = map[string]interface{}{
+		"resourceType": "Patient", "id": "p1",
+		"meta": map[string]interface{}{"versionId": "3"},
+	}
+	tx := &fakeTx{}
+	p := newTestDispatchProcessor(e, tx)
+
+	bundle, _ := ParseTransactionBundle([]byte(`{
+		"resourceType": "Bundle", "type": "transaction",
+		"entry": [
+			{"fullUrl": "Patient/p1", "resource": {"resourceType": "Patient", "id": "p1"},
+			 "request": {"method": "PUT", "url": "Patient/p1", "ifMatch": "W/\"2\""}}
+		]}`))
+
+	_, err := p.ExecuteTransaction(context.Background(), bundle, http.Header{})
+	entryErr, ok := err.(*TransactionEntryError)
+	if !ok || entryErr.Status != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412, got %v", err)
+	}
+	if !tx.rolledBack {
+		t.Error("expected rollback")
+	}
+}
+
+func TestExecuteTransaction_ConditionalUpdateWithoutMatchCreates(t *testing.T) {
+	e, store := newDispatchTestServer()
+	p := newTestDispatchProcessor(e, &fakeTx{})
+
+	bundle, _ := ParseTransactionBundle([]byte(`{
+		"resourceType": "Bundle", "type": "transaction",
+		"entry": [
+			{"fullUrl": "urn:uuid:pat", "resource": {"resourceType": "Patient"},
+			 "request": {"method": "PUT", "url": "Patient?_id=missing"}}
+		]}`))
+
+	result, err := p.ExecuteTransaction(context.Background(), bundle, http.Header{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Entry[0].Response.Status != "201 Created" {
+		t.Errorf("expected 201 Created, got %q", result.Entry[0].Response.Status)
+	}
+	if len(store) != 1 {
+		t.Errorf("expected one created Patient, got %d", len(store))
+	}
+}
+
+func TestExecuteTransaction_RouteErrorsBecomeOutcomes(t *testing.T) {
+	e, _ := newDispatchTestServer()
+	p := newTestDispatchProcessor(e, &fakeTx{})
+
+	bundle, _ := ParseTransactionBundle([]byte(`{
+		"resourceType": "Bundle", "type": "transaction",
+		"entry": [{"fullUrl": "urn:uuid:x", "request": {"method": "GET", "url": "Secret"}}]}`))
+
+	_, err := p.ExecuteTransaction(context.Background(), bundle, http.Header{})
+	entryErr, ok := err.(*TransactionEntryError)
+	if !ok || entryErr.Status != http.StatusForbidden {
+		t.Fatalf("expected 403, got %v", err)
+	}
+	if entryErr.Outcome.Issue[0].Code != IssueTypeSecurity {
+		t.Errorf("expected security issue, got %q", entryErr.Outcome.Issue[0].Code)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ExecuteBatch / handler tests
+// ---------------------------------------------------------------------------
+
+func TestExecuteBatch_IndependentEntries(t *testing.T) {
+	e, _ := newDispatchTestServer()
+	p := newTestDispatchProcessor(e, &fakeTx{})
+
+	bundle, _ := ParseTransactionBundle([]byte(`{
+		"resourceType": "Bundle", "type": "batch",
+		"entry": [
+			{"request": {"method": "GET", "url": "Patient/nope"}},
+			{"resource": {"resourceType": "Patient"}, "request": {"method": "POST", "url": "Patient"}}
+		]}`))
+
+	result := p.ExecuteBatch(context.Background(), bundle, http.Header{})
+	if result.Entry[0].Response.Status != "404 Not Found" {
+		t.Errorf("expected 404, got %q", result.Entry[0].Response.Status)
+	}
+	if result.Entry[1].Response.Status != "201 Created" {
+		t.Errorf("expected 201, got %q", result.Entry[1].Response.Status)
+	}
+}
+
+func TestTransactionHandler_UsesDispatcher(t *testing.T) {
+	e, _ := newDispatchTestServer()
+	tx := &fakeTx{}
+	p := newTestDispatchProcessor(e, tx)
+	e.POST("/fhir", TransactionHandler(p))
+
+	body := `{"resourceType": "Bundle", "type": "transaction",
+		"entry": [{"fullUrl": "urn:uuid:x", "request": {"method": "GET", "url": "Patient/missing"}}]}`
+	req := httptest.NewRequest(http.MethodPost, "/fhir", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, "application/fhir+json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 from failing entry, got %d", rec.Code)
+	}
+	raw, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(raw), "OperationOutcome") {
+		t.Errorf("expected OperationOutcome body, got %s", raw)
+	}
+	if !tx.rolledBack {
+		t.Error("expected rollback")
+	}
+}
+
+func TestNormalizeLocation(t *testing.T) {
+	tests := map[string]string{
+		"/fhir/Patient/123":                       "Patient/123",
+		"http://host/fhir/Patient/123/_history/2": "Patient/123",
+		"Observation/abc":                         "Observation/abc",
+		"":                                        "",
+	}
+	for in, want := range tests {
+		if got := normalizeLocation(in); got != want {
+			t.Errorf("normalizeLocation(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -277,6 +277,11 @@ func (h *TranslateHandler) RegisterRoutes(g *echo.Group) {
 	g.GET("/ConceptMap/:id/$translate", h.TranslateByMap)
 }
 
+// RegisterResources registers the ConceptMap search route with r.
+func (h *TranslateHandler) RegisterResources(r *ResourceRegistry) {
+	r.RegisterInteractions("ConceptMap", "search-type")
+}
+
 // ListConceptMaps handles GET /fhir/ConceptMap — returns a Bundle of available maps.
 func (h *TranslateHandler) ListConceptMaps(c echo.Context) error {
 	maps := h.translator.ListConceptMaps()
@@ -0,0 +1,556 @@
+package fhir
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// UCUMSystem is the code system of UCUM units.
+const UCUMSystem = "http://unitsofmeasure.org"
+
+// UCUMUnit is a parsed UCUM unit expression such as "mg/dL" or "10*9/L":
+// its magnitude and dimension in terms of the UCUM base units. Two units are
+// commensurable, and quantities in them comparable, when they have the same
+// canonical unit.
+type UCUMUnit struct {
+	factor float64        // magnitude of one unit in canonical units
+	offset float64        // for Cel and [degF], added after factor
+	dim    map[string]int // exponent of each base or arbitrary unit
+	amount int            // exponent of mol, for conversions by molar mass
+}
+
+// ucumBaseUnits are the UCUM base units, in the order they appear in
+// canonical units. Arbitrary units, such as [iU], are their own base and
+// follow in alphabetical order.
+var ucumBaseUnits = []string{"g", "m", "s", "rad", "K", "C", "cd"}
+
+// ucumPrefixes are the UCUM metric prefixes.
+var ucumPrefixes = map[string]float64{
+	"Y": 1e24, "Z": 1e21, "E": 1e18, "P": 1e15, "T": 1e12, "G": 1e9, "M": 1e6,
+	"k": 1e3, "h": 1e2, "da": 1e1, "d": 1e-1, "c": 1e-2, "m": 1e-3, "u": 1e-6,
+	"n": 1e-9, "p": 1e-12, "f": 1e-15, "a": 1e-18, "z": 1e-21, "y": 1e-24,
+}
+
+// ucumAtom defines a UCUM unit atom as a multiple of a unit expression.
+// Metric atoms take prefixes; arbitrary atoms are only commensurable with
+// themselves.
+type ucumAtom struct {
+	value     float64
+	unit      string
+	metric    bool
+	arbitrary bool
+}
+
+// ucumAtoms holds the unit atoms in common clinical use: the SI units, and
+// the customary, clinical and arbitrary units of the UCUM tables. Cel and
+// [degF] are handled by parseUCUMSpecial.
+var ucumAtoms = map[string]ucumAtom{
+	// Dimensionless
+	"10*":    {value: 10, unit: "1"},
+	"10^":    {value: 10, unit: "1"},
+	"[pi]":   {value: math.Pi, unit: "1"},
+	"%":      {value: 1, unit: "10*-2"},
+	"[ppth]": {value: 1, unit: "10*-3"},
+	"[ppm]":  {value: 1, unit: "10*-6"},
+	"[ppb]":  {value: 1, unit: "10*-9"},
+	"[pptr]": {value: 1, unit: "10*-12"},
+	"[HPF]":  {value: 1, unit: "1"},
+	"[LPF]":  {value: 100, unit: "1"},
+	"mol":    {value: 6.0221367, unit: "10*23", metric: true},
+	"sr":     {value: 1, unit: "rad2", metric: true},
+	"deg":    {value: 2, unit: "[pi].rad/360"},
+
+	// SI derived units
+	"Hz":  {value: 1, unit: "s-1", metric: true},
+	"N":   {value: 1, unit: "kg.m/s2", metric: true},
+	"Pa":  {value: 1, unit: "N/m2", metric: true},
+	"J":   {value: 1, unit: "N.m", metric: true},
+	"W":   {value: 1, unit: "J/s", metric: true},
+	"A":   {value: 1, unit: "C/s", metric: true},
+	"V":   {value: 1, unit: "J/C", metric: true},
+	"F":   {value: 1, unit: "C/V", metric: true},
+	"Ohm": {value: 1, unit: "V/A", metric: true},
+	"S":   {value: 1, unit: "Ohm-1", metric: true},
+	"Wb":  {value: 1, unit: "V.s", metric: true},
+	"T":   {value: 1, unit: "Wb/m2", metric: true},
+	"H":   {value: 1, unit: "Wb/A", metric: true},
+	"lm":  {value: 1, unit: "cd.sr", metric: true},
+	"lx":  {value: 1, unit: "lm/m2", metric: true},
+	"Bq":  {value: 1, unit: "s-1", metric: true},
+	"Gy":  {value: 1, unit: "J/kg", metric: true},
+	"Sv":  {value: 1, unit: "J/kg", metric: true},
+	"kat": {value: 1, unit: "mol/s", metric: true},
+	"U":   {value: 1, unit: "umol/min", metric: true},
+
+	// Time
+	"min":  {value: 60, unit: "s"},
+	"h":    {value: 60, unit: "min"},
+	"d":    {value: 24, unit: "h"},
+	"wk":   {value: 7, unit: "d"},
+	"a_j":  {value: 365.25, unit: "d"},
+	"a_g":  {value: 365.2425, unit: "d"},
+	"a":    {value: 1, unit: "a_j"},
+	"mo_j": {value: 1, unit: "a_j/12"},
+	"mo_g": {value: 1, unit: "a_g/12"},
+	"mo":   {value: 1, unit: "mo_j"},
+
+	// Volume, area and mass
+	"L":   {value: 1, unit: "dm3", metric: true},
+	"l":   {value: 1, unit: "dm3", metric: true},
+	"ar":  {value: 100, unit: "m2", metric: true},
+	"t":   {value: 1e3, unit: "kg", metric: true},
+	"u":   {value: 1.6605402e-24, unit: "g", metric: true},
+	"g%":  {value: 1, unit: "g/dL", metric: true},
+	"eq":  {value: 1, unit: "mol", metric: true},
+	"osm": {value: 1, unit: "mol", metric: true},
+
+	// Pressure and energy
+	"bar":    {value: 1e5, unit: "Pa", metric: true},
+	"atm":    {value: 101325, unit: "Pa"},
+	"m[Hg]":  {value: 133.322, unit: "kPa", metric: true},
+	"m[H2O]": {value: 9.80665, unit: "kPa", metric: true},
+	"eV":     {value: 1.60217733e-19, unit: "J", metric: true},
+	"cal":    {value: 4.184, unit: "J", metric: true},
+	"[Cal]":  {value: 1, unit: "kcal"},
+
+	// Customary units
+	"[in_i]":    {value: 2.54, unit: "cm"},
+	"[ft_i]":    {value: 12, unit: "[in_i]"},
+	"[yd_i]":    {value: 3, unit: "[ft_i]"},
+	"[mi_i]":    {value: 5280, unit: "[ft_i]"},
+	"[in_i'Hg]": {value: 1, unit: "m[Hg].[in_i]/m"},
+	"[gr]":      {value: 64.79891, unit: "mg"},
+	"[lb_av]":   {value: 7000, unit: "[gr]"},
+	"[oz_av]":   {value: 1.0 / 16, unit: "[lb_av]"},
+	"[gal_us]":  {value: 231, unit: "[in_i]3"},
+	"[qt_us]":   {value: 1.0 / 4, unit: "[gal_us]"},
+	"[pt_us]":   {value: 1.0 / 2, unit: "[qt_us]"},
+	"[foz_us]":  {value: 1.0 / 16, unit: "[pt_us]"},
+	"[cup_us]":  {value: 8, unit: "[foz_us]"},
+	"[tbs_us]":  {value: 1.0 / 2, unit: "[foz_us]"},
+	"[tsp_us]":  {value: 1.0 / 3, unit: "[tbs_us]"},
+	"[drp]":     {value: 1, unit: "mL/20"},
+
+	// Arbitrary units
+	"[iU]":    {arbitrary: true, metric: true},
+	"[IU]":    {value: 1, unit: "[iU]", metric: true},
+	"[arb'U]": {arbitrary: true},
+	"[USP'U]": {arbitrary: true},
+	"[CFU]":   {arbitrary: true, metric: true},
+}
+
+// ucumAvogadro is the number of particles in a mol, as UCUM defines it.
+const ucumAvogadro = 6.0221367e23
+
+// ucumAnalytes are the analytes that laboratories report in both substance
+// and mass concentration, such as glucose in mmol/L and mg/dL, by LOINC
+// code, with their molar mass in g/mol.
+var ucumAnalytes = []struct {
+	molarMass float64
+	codes     []string
+}{
+	{180.156, []string{"2339-0", "2345-7", "41653-7", "15074-8", "14749-6", "14743-9"}}, // Glucose
+	{113.118, []string{"2160-0", "14682-9"}},                                            // Creatinine
+	{386.654, []string{"2093-3", "14647-2"}},                                            // Cholesterol
+	{885.7, []string{"2571-8", "14927-8"}},                                              // Triglyceride, as triolein
+	{584.662, []string{"1975-2", "14631-6"}},                                            // Bilirubin
+	{40.078, []string{"17861-6", "2000-8"}},                                             // Calcium
+}
+
+// AnalyteMolarMass returns the molar mass, in g/mol, of the analyte
+// measured by an observation with the LOINC code, for the analytes of
+// ucumAnalytes.
+func AnalyteMolarMass(code string) (float64, bool) {
+	for _, analyte := range ucumAnalytes {
+		for _, c := range analyte.codes {
+			if c == code {
+				return analyte.molarMass, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// ucumCache memoizes parsed unit expressions.
+var ucumCache sync.Map // string -> *UCUMUnit
+
+// ParseUCUM parses a UCUM unit expression in case-sensitive UCUM syntax:
+// unit atoms with optional metric prefixes and integer exponents, combined
+// with "." and "/", parentheses, integer factors and {annotations}, which
+// do not affect the unit.
+func ParseUCUM(expr string) (*UCUMUnit, error) {
+	if cached, ok := ucumCache.Load(expr); ok {
+		return cached.(*UCUMUnit), nil
+	}
+	u, err := parseUCUMSpecial(expr)
+	if u == nil && err == nil {
+		p := &ucumParser{s: expr}
+		u, err = p.parse()
+	}
+	if err != nil {
+		return nil, err
+	}
+	ucumCache.Store(expr, u)
+	return u, nil
+}
+
+// parseUCUMSpecial returns the units whose conversion is not a plain
+// multiple, degrees Celsius and Fahrenheit, or nil for any other unit. They
+// cannot be combined with other units.
+func parseUCUMSpecial(expr string) (*UCUMUnit, error) {
+	switch expr {
+	case "Cel":
+		return &UCUMUnit{factor: 1, offset: 273.15, dim: map[string]int{"K": 1}}, nil
+	case "[degF]":
+		return &UCUMUnit{factor: 5.0 / 9, offset: 459.67 * 5 / 9, dim: map[string]int{"K": 1}}, nil
+	}
+	if strings.Contains(expr, "Cel") || strings.Contains(expr, "[degF]") {
+		return nil, fmt.Errorf("unit %q: temperatures cannot be combined with other units", expr)
+	}
+	return nil, nil
+}
+
+// Canonical returns the canonical unit of u: the base units it is made of,
+// such as "g.m-3" for mg/dL, or "1" for a dimensionless unit.
+func (u *UCUMUnit) Canonical() string {
+	var names []string
+	for _, base := range ucumBaseUnits {
+		if u.dim[base] != 0 {
+			names = append(names, base)
+		}
+	}
+	var arbitrary []string
+	for name, exp := range u.dim {
+		if exp != 0 && strings.HasPrefix(name, "[") {
+			arbitrary = append(arbitrary, name)
+		}
+	}
+	sort.Strings(arbitrary)
+	names = append(names, arbitrary...)
+	if len(names) == 0 {
+		return "1"
+	}
+	terms := make([]string, len(names))
+	for i, name := range names {
+		terms[i] = name
+		if exp := u.dim[name]; exp != 1 {
+			terms[i] += strconv.Itoa(exp)
+		}
+	}
+	return strings.Join(terms, ".")
+}
+
+// ToCanonical converts value in u to the canonical unit. The result is
+// rounded to 12 significant digits, so that the same quantity reached
+// through different units compares equal.
+func (u *UCUMUnit) ToCanonical(value float64) float64 {
+	return roundSignificant(value*u.factor+u.offset, 12)
+}
+
+// ConvertUCUM converts value from one UCUM unit to another. It fails when
+// either unit is invalid or they are not commensurable, such as mmol/L and
+// mg/dL, which differ by the molar mass of the substance.
+func ConvertUCUM(value float64, from, to string) (float64, error) {
+	src, err := ParseUCUM(from)
+	if err != nil {
+		return 0, err
+	}
+	dst, err := ParseUCUM(to)
+	if err != nil {
+		return 0, err
+	}
+	if src.Canonical() != dst.Canonical() {
+		return 0, fmt.Errorf("units %q and %q are not commensurable", from, to)
+	}
+	return roundSignificant((value*src.factor+src.offset-dst.offset)/dst.factor, 12), nil
+}
+
+// ConvertUCUMAnalyte converts value from one UCUM unit to another like
+// ConvertUCUM, and also between substance and mass concentrations, such as
+// mmol/L and mg/dL, of the analyte of the LOINC code, by its molar mass
+// (see AnalyteMolarMass).
+func ConvertUCUMAnalyte(value float64, from, to, analyte string) (float64, error) {
+	src, err := ParseUCUM(from)
+	if err != nil {
+		return 0, err
+	}
+	dst, err := ParseUCUM(to)
+	if err != nil {
+		return 0, err
+	}
+	if src.Canonical() != dst.Canonical() {
+		molarMass, ok := AnalyteMolarMass(analyte)
+		if !ok {
+			return 0, fmt.Errorf("units %q and %q are not commensurable and the molar mass of %q is not known", from, to, analyte)
+		}
+		if src, ok = src.byMolarMass(molarMass); !ok || src.Canonical() != dst.Canonical() {
+			return 0, fmt.Errorf("units %q and %q are not commensurable", from, to)
+		}
+	}
+	return roundSignificant((value*src.factor+src.offset-dst.offset)/dst.factor, 12), nil
+}
+
+// byMolarMass returns the unit of the same quantity of a substance of
+// molarMass g/mol in the other of amount of substance and mass: mg/dL for
+// a unit such as mmol/L, and mmol/L for mg/dL. It reports false for units
+// that are neither, and for units of both, such as mol/g.
+func (u *UCUMUnit) byMolarMass(molarMass float64) (*UCUMUnit, bool) {
+	r := u.pow(1)
+	switch {
+	case u.amount == 1 && u.dim["g"] == 0:
+		r.amount = 0
+		r.dim["g"] = 1
+		r.factor *= molarMass / ucumAvogadro
+	case u.amount == 0 && u.dim["g"] == 1:
+		r.amount = 1
+		delete(r.dim, "g")
+		r.factor *= ucumAvogadro / molarMass
+	default:
+		return nil, false
+	}
+	return r, true
+}
+
+// CanonicalQuantity returns a quantity in its canonical UCUM unit, as stored
+// alongside the quantity so that searches in any commensurable unit match
+// it. It reports false for quantities of another unit system and codes that
+// are not valid UCUM. A quantity without a system is taken to be UCUM when
+// its code parses.
+func CanonicalQuantity(value float64, system, code string) (float64, string, bool) {
+	if code == "" || (system != "" && system != UCUMSystem) {
+		return 0, "", false
+	}
+	u, err := ParseUCUM(code)
+	if err != nil {
+		return 0, "", false
+	}
+	return u.ToCanonical(value), u.Canonical(), true
+}
+
+func roundSignificant(v float64, digits int) float64 {
+	if v == 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return v
+	}
+	r, err := strconv.ParseFloat(strconv.FormatFloat(v, 'g', digits, 64), 64)
+	if err != nil {
+		return v
+	}
+	return r
+}
+
+func (u *UCUMUnit) mul(o *UCUMUnit, sign int) *UCUMUnit {
+	r := &UCUMUnit{factor: u.factor, dim: make(map[string]int), amount: u.amount + sign*o.amount}
+	for k, v := range u.dim {
+		r.dim[k] = v
+	}
+	if sign > 0 {
+		r.factor *= o.factor
+	} else {
+		r.factor /= o.factor
+	}
+	for k, v := range o.dim {
+		r.dim[k] += sign * v
+	}
+	return r
+}
+
+func (u *UCUMUnit) pow(exp int) *UCUMUnit {
+	r := &UCUMUnit{factor: math.Pow(u.factor, float64(exp)), dim: make(map[string]int), amount: u.amount * exp}
+	for k, v := range u.dim {
+		r.dim[k] = v * exp
+	}
+	return r
+}
+
+// ucumParser parses the UCUM grammar:
+//
+//	term      = ["/"] component {("." | "/") component}
+//	component = annotatable [annotation] | annotation | factor | "(" term ")"
+type ucumParser struct {
+	s   string
+	pos int
+}
+
+func (p *ucumParser) parse() (*UCUMUnit, error) {
+	if p.s == "" {
+		return nil, fmt.Errorf("empty unit")
+	}
+	u, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.s) {
+		return nil, fmt.Errorf("unit %q: unexpected %q at %d", p.s, p.s[p.pos], p.pos)
+	}
+	return u, nil
+}
+
+func (p *ucumParser) term() (*UCUMUnit, error) {
+	u := &UCUMUnit{factor: 1, dim: map[string]int{}}
+	sign := 1
+	if p.pos < len(p.s) && p.s[p.pos] == '/' {
+		sign = -1
+		p.pos++
+	}
+	for {
+		c, err := p.component()
+		if err != nil {
+			return nil, err
+		}
+		u = u.mul(c, sign)
+		if p.pos >= len(p.s) {
+			return u, nil
+		}
+		switch p.s[p.pos] {
+		case '.':
+			sign = 1
+		case '/':
+			sign = -1
+		default:
+			return u, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *ucumParser) component() (*UCUMUnit, error) {
+	if p.pos >= len(p.s) {
+		return nil, fmt.Errorf("unit %q: missing unit at end", p.s)
+	}
+	switch p.s[p.pos] {
+	case '(':
+		p.pos++
+		u, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return nil, fmt.Errorf("unit %q: unbalanced parentheses", p.s)
+		}
+		p.pos++
+		return u, p.annotation()
+	case '{':
+		return &UCUMUnit{factor: 1, dim: map[string]int{}}, p.annotation()
+	}
+
+	start, depth := p.pos, 0
+	for p.pos < len(p.s) {
+		ch := p.s[p.pos]
+		if ch == '[' {
+			depth++
+		} else if ch == ']' {
+			depth--
+		} else if depth == 0 && strings.IndexByte("./(){", ch) >= 0 {
+			break
+		}
+		p.pos++
+	}
+	u, err := parseUCUMSimple(p.s[start:p.pos])
+	if err != nil {
+		return nil, fmt.Errorf("unit %q: %w", p.s, err)
+	}
+	return u, p.annotation()
+}
+
+// annotation skips a {annotation} at the current position, if any.
+func (p *ucumParser) annotation() error {
+	if p.pos >= len(p.s) || p.s[p.pos] != '{' {
+		return nil
+	}
+	end := strings.IndexByte(p.s[p.pos:], '}')
+	if end < 0 {
+		return fmt.Errorf("unit %q: unterminated annotation", p.s)
+	}
+	p.pos += end + 1
+	return nil
+}
+
+// parseUCUMSimple parses a unit atom with optional prefix and exponent, such
+// as "mg", "m2" or "10*3", or an integer factor.
+func parseUCUMSimple(s string) (*UCUMUnit, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing unit")
+	}
+	// Split off a trailing exponent, which may not start inside brackets.
+	atomEnd := len(s)
+	for atomEnd > 0 && s[atomEnd-1] >= '0' && s[atomEnd-1] <= '9' {
+		atomEnd--
+	}
+	if atomEnd > 0 && atomEnd < len(s) && (s[atomEnd-1] == '-' || s[atomEnd-1] == '+') {
+		atomEnd--
+	}
+	atom, exp := s[:atomEnd], 1
+	if atom == "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid factor %q", s)
+		}
+		return &UCUMUnit{factor: float64(n), dim: map[string]int{}}, nil
+	}
+	if atomEnd < len(s) {
+		var err error
+		if exp, err = strconv.Atoi(s[atomEnd:]); err != nil {
+			return nil, fmt.Errorf("invalid exponent in %q", s)
+		}
+	}
+	u, err := ucumAtomUnit(atom)
+	if err != nil {
+		return nil, err
+	}
+	return u.pow(exp), nil
+}
+
+// ucumAtomUnit resolves a unit atom, with an optional metric prefix.
+func ucumAtomUnit(atom string) (*UCUMUnit, error) {
+	if u, ok := ucumResolve(atom); ok {
+		return u, nil
+	}
+	for _, n := range []int{2, 1} {
+		if len(atom) <= n {
+			continue
+		}
+		scale, ok := ucumPrefixes[atom[:n]]
+		if !ok {
+			continue
+		}
+		if def, ok := ucumAtoms[atom[n:]]; ok && !def.metric {
+			continue
+		}
+		if u, ok := ucumResolve(atom[n:]); ok {
+			r := u.pow(1)
+			r.factor *= scale
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown unit %q", atom)
+}
+
+// ucumResolve returns the unit of an atom without prefix.
+func ucumResolve(atom string) (*UCUMUnit, bool) {
+	for _, base := range ucumBaseUnits {
+		if atom == base {
+			return &UCUMUnit{factor: 1, dim: map[string]int{base: 1}}, true
+		}
+	}
+	def, ok := ucumAtoms[atom]
+	if !ok {
+		return nil, false
+	}
+	if def.arbitrary {
+		return &UCUMUnit{factor: 1, dim: map[string]int{atom: 1}}, true
+	}
+	p := &ucumParser{s: def.unit}
+	u, err := p.parse()
+	if err != nil {
+		return nil, false
+	}
+	u.factor *= def.value
+	if atom == "mol" {
+		u.amount = 1
+	}
+	return u, true
+}
@@ -0,0 +1,120 @@
+package fhir
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseUCUM_Canonical(t *testing.T) {
+	tests := []struct {
+		unit      string
+		canonical string
+		factor    float64
+	}{
+		{"mg/dL", "g.m-3", 10},
+		{"g/L", "g.m-3", 1000},
+		{"mmol/L", "m-3", 6.0221367e23},
+		{"10*9/L", "m-3", 1e12},
+		{"mm[Hg]", "g.m-1.s-2", 133322},
+		{"kg/m2", "g.m-2", 1000},
+		{"%", "1", 0.01},
+		{"/min", "s-1", 1.0 / 60},
+		{"{beats}/min", "s-1", 1.0 / 60},
+		{"[lb_av]", "g", 453.59237},
+		{"[in_i]", "m", 0.0254},
+		{"m[iU]/mL", "m-3.[iU]", 1000},
+		{"U/L", "m-3.s-1", 6.0221367e23 * 1e-6 / 60 / 1e-3},
+		{"mL/min/{1.73_m2}", "m3.s-1", 1e-6 / 60},
+		{"mL/(min.m2)", "m.s-1", 1e-6 / 60},
+		{"10*3/uL", "m-3", 1e12},
+		{"ug", "g", 1e-6},
+	}
+	for _, tt := range tests {
+		u, err := ParseUCUM(tt.unit)
+		if err != nil {
+			t.Errorf("ParseUCUM(%q): %v", tt.unit, err)
+			continue
+		}
+		if got := u.Canonical(); got != tt.canonical {
+			t.Errorf("ParseUCUM(%q).Canonical() = %q, want %q", tt.unit, got, tt.canonical)
+		}
+		if got := u.ToCanonical(1); math.Abs(got-tt.factor) > math.Abs(tt.factor)*1e-9 {
+			t.Errorf("ParseUCUM(%q).ToCanonical(1) = %v, want %v", tt.unit, got, tt.factor)
+		}
+	}
+}
+
+func TestParseUCUM_Invalid(t *testing.T) {
+	for _, unit := range []string{"", "mcg", "foo/L", "mg/", "(mg", "{cells", "Cel/h", "mg//dL"} {
+		if _, err := ParseUCUM(unit); err == nil {
+			t.Errorf("ParseUCUM(%q) should fail", unit)
+		}
+	}
+}
+
+func TestConvertUCUM(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{97.3, "mg/dL", "g/L", 0.973},
+		{5.4, "mmol/L", "umol/L", 5400},
+		{1, "[lb_av]", "kg", 0.45359237},
+		{37, "Cel", "[degF]", 98.6},
+		{0, "Cel", "K", 273.15},
+		{120, "mm[Hg]", "kPa", 15.99864},
+		{2, "h", "min", 120},
+	}
+	for _, tt := range tests {
+		got, err := ConvertUCUM(tt.value, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("ConvertUCUM(%v, %q, %q): %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9*math.Max(1, math.Abs(tt.want)) {
+			t.Errorf("ConvertUCUM(%v, %q, %q) = %v, want %v", tt.value, tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	// Molar and mass concentrations differ by the molar mass.
+	if _, err := ConvertUCUM(5.4, "mmol/L", "mg/dL"); err == nil {
+		t.Error("mmol/L and mg/dL should not be commensurable")
+	}
+}
+
+func TestConvertUCUMAnalyte(t *testing.T) {
+	// Glucose, 180.156 g/mol: 5.4 mmol/L is 97.28 mg/dL.
+	got, err := ConvertUCUMAnalyte(5.4, "mmol/L", "mg/dL", "2345-7")
+	if err != nil || math.Abs(got-97.28424) > 1e-6 {
+		t.Errorf("glucose 5.4 mmol/L = %v mg/dL, %v; want 97.28424", got, err)
+	}
+	got, err = ConvertUCUMAnalyte(1.2, "mg/dL", "umol/L", "2160-0")
+	if err != nil || math.Abs(got-106.0839) > 1e-3 {
+		t.Errorf("creatinine 1.2 mg/dL = %v umol/L, %v; want 106.08", got, err)
+	}
+	// Commensurable units need no molar mass.
+	if got, err := ConvertUCUMAnalyte(97.3, "mg/dL", "g/L", ""); err != nil || got != 0.973 {
+		t.Errorf("mg/dL to g/L = %v, %v", got, err)
+	}
+	if _, err := ConvertUCUMAnalyte(5.4, "mmol/L", "mg/dL", "8480-6"); err == nil {
+		t.Error("an analyte without a known molar mass should not convert")
+	}
+	if _, err := ConvertUCUMAnalyte(5.4, "mmol/L", "mg", "2345-7"); err == nil {
+		t.Error("a concentration should not convert to a mass")
+	}
+}
+
+func TestCanonicalQuantity(t *testing.T) {
+	v1, u1, ok1 := CanonicalQuantity(97.3, UCUMSystem, "mg/dL")
+	v2, u2, ok2 := CanonicalQuantity(0.973, "", "g/L")
+	if !ok1 || !ok2 || u1 != u2 || v1 != v2 {
+		t.Errorf("97.3 mg/dL = %v %s, 0.973 g/L = %v %s; want equal", v1, u1, v2, u2)
+	}
+	if _, _, ok := CanonicalQuantity(5, "http://example.org/units", "mg"); ok {
+		t.Error("non-UCUM systems should not be canonicalized")
+	}
+	if _, _, ok := CanonicalQuantity(5, UCUMSystem, "tablets"); ok {
+		t.Error("invalid UCUM codes should not be canonicalized")
+	}
+}
@@ -25,6 +25,11 @@ type ResourceEventListener interface {
 // that domain services call during create/update/delete operations.
 type VersionTracker struct {
 	repo      *HistoryRepository
+	extras    ExtrasStore
+	meta      ModeledMetaStore
+	text      TextIndexStore
+	contained ContainedIndexStore
+	search    *SearchIndexer
 	mu        sync.RWMutex
 	listeners []ResourceEventListener
 }
@@ -34,6 +39,42 @@ func NewVersionTracker(repo *HistoryRepository) *VersionTracker {
 	return &VersionTracker{repo: repo}
 }
 
+// SetExtrasStore enables extras persistence. Extras parsed from the request
+// (see BindResource) are saved when a resource is created or updated, and the
+// stored extras are merged into every snapshot recorded in history.
+func (vt *VersionTracker) SetExtrasStore(s ExtrasStore) {
+	vt.extras = s
+}
+
+// SetModeledMetaStore enables modeled meta persistence. The profiles, tags
+// and security labels each created or updated resource's domain model emits
+// are saved for the _profile, _tag and _security searches, and removed when
+// it is deleted.
+func (vt *VersionTracker) SetModeledMetaStore(s ModeledMetaStore) {
+	vt.meta = s
+}
+
+// SetTextIndex enables full-text indexing. The text of each created or
+// updated resource, extras included, is written to the index that backs the
+// _text and _content search parameters, and removed when it is deleted.
+func (vt *VersionTracker) SetTextIndex(s TextIndexStore) {
+	vt.text = s
+}
+
+// SetContainedIndex enables contained-resource indexing. The contained
+// resources of each created or updated resource are written to the index that
+// backs the _contained search parameter, and removed when it is deleted.
+func (vt *VersionTracker) SetContainedIndex(s ContainedIndexStore) {
+	vt.contained = s
+}
+
+// SetSearchIndexer enables custom search parameter indexing. The values of
+// the custom search parameters of each created or updated resource are
+// written to the index, and removed when it is deleted.
+func (vt *VersionTracker) SetSearchIndexer(ix *SearchIndexer) {
+	vt.search = ix
+}
+
 // AddListener registers a listener that will be notified on resource events.
 func (vt *VersionTracker) AddListener(l ResourceEventListener) {
 	vt.mu.Lock()
@@ -52,10 +93,20 @@ func (vt *VersionTracker) fireEvent(ctx context.Context, event ResourceEvent) {
 
 // RecordCreate saves version 1 of a resource after creation.
 func (vt *VersionTracker) RecordCreate(ctx context.Context, resourceType, resourceID string, resource interface{}) error {
+	if err := vt.saveModeledMeta(ctx, resourceType, resourceID, resource); err != nil {
+		return err
+	}
+	resource, err := vt.applyExtras(ctx, resourceType, resourceID, resource, false)
+	if err != nil {
+		return err
+	}
 	data, err := json.Marshal(resource)
 	if err != nil {
 		return fmt.Errorf("version tracker: marshal resource: %w", err)
 	}
+	if err := vt.indexResource(ctx, resourceType, resourceID, data); err != nil {
+		return err
+	}
 	if err := vt.repo.SaveVersion(ctx, resourceType, resourceID, 1, json.RawMessage(data), "create"); err != nil {
 		return err
 	}
@@ -73,10 +124,20 @@ func (vt *VersionTracker) RecordCreate(ctx context.Context, resourceType, resour
 // Returns the new version number.
 func (vt *VersionTracker) RecordUpdate(ctx context.Context, resourceType, resourceID string, currentVersion int, resource interface{}) (int, error) {
 	newVersion := currentVersion + 1
+	if err := vt.saveModeledMeta(ctx, resourceType, resourceID, resource); err != nil {
+		return 0, err
+	}
+	resource, err := vt.applyExtras(ctx, resourceType, resourceID, resource, true)
+	if err != nil {
+		return 0, err
+	}
 	data, err := json.Marshal(resource)
 	if err != nil {
 		return 0, fmt.Errorf("version tracker: marshal resource: %w", err)
 	}
+	if err := vt.indexResource(ctx, resourceType, resourceID, data); err != nil {
+		return 0, err
+	}
 	if err := vt.repo.SaveVersion(ctx, resourceType, resourceID, newVersion, json.RawMessage(data), "update"); err != nil {
 		return 0, err
 	}
@@ -92,6 +153,31 @@ func (vt *VersionTracker) RecordUpdate(ctx context.Context, resourceType, resour
 
 // RecordDelete saves a deletion marker at the next version.
 func (vt *VersionTracker) RecordDelete(ctx context.Context, resourceType, resourceID string, currentVersion int) error {
+	if vt.extras != nil {
+		if err := vt.extras.DeleteExtras(ctx, resourceType, resourceID); err != nil {
+			return err
+		}
+	}
+	if vt.meta != nil {
+		if err := vt.meta.DeleteModeledMeta(ctx, resourceType, resourceID); err != nil {
+			return err
+		}
+	}
+	if vt.text != nil {
+		if err := vt.text.DeleteText(ctx, resourceType, resourceID); err != nil {
+			return err
+		}
+	}
+	if vt.contained != nil {
+		if err := vt.contained.DeleteContained(ctx, resourceType, resourceID); err != nil {
+			return err
+		}
+	}
+	if vt.search != nil {
+		if err := vt.search.Delete(ctx, resourceType, resourceID); err != nil {
+			return err
+		}
+	}
 	if err := vt.repo.SaveVersion(ctx, resourceType, resourceID, currentVersion+1, json.RawMessage("null"), "delete"); err != nil {
 		return err
 	}
@@ -105,6 +191,109 @@ func (vt *VersionTracker) RecordDelete(ctx context.Context, resourceType, resour
 	return nil
 }
 
+// saveModeledMeta saves the meta elements of resource, the ToFHIR output of
+// the domain model, before any extras are merged into it.
+func (vt *VersionTracker) saveModeledMeta(ctx context.Context, resourceType, resourceID string, resource interface{}) error {
+	if vt.meta == nil {
+		return nil
+	}
+	meta, err := modeledMetaElements(resource)
+	if err != nil {
+		return fmt.Errorf("version tracker: marshal resource: %w", err)
+	}
+	return vt.meta.PutModeledMeta(ctx, resourceType, resourceID, meta)
+}
+
+// applyExtras saves the extras parsed from the current request, if any, and
+// returns resource with the resource's extras merged in. On update, the tags
+// and security labels of the previous version are retained.
+func (vt *VersionTracker) applyExtras(ctx context.Context, resourceType, resourceID string, resource interface{}, update bool) (interface{}, error) {
+	if vt.extras == nil {
+		return resource, nil
+	}
+	extras, ok := takePendingExtras(ctx, resourceType, resourceID)
+	if ok {
+		if update {
+			stored, err := vt.extras.GetExtras(ctx, resourceType, resourceID)
+			if err != nil {
+				return nil, err
+			}
+			extras = retainMeta(extras, stored)
+		}
+		if err := vt.extras.PutExtras(ctx, resourceType, resourceID, extras); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if extras, err = vt.extras.GetExtras(ctx, resourceType, resourceID); err != nil {
+			return nil, err
+		}
+	}
+	if len(extras) == 0 {
+		return resource, nil
+	}
+	// Round-trip through JSON rather than toMap: ToFHIR output holds typed
+	// elements, such as fhir.Meta, that extras can only be merged into as maps.
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return resource, nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil || m == nil {
+		return resource, nil
+	}
+	MergeExtras(m, extras)
+	return m, nil
+}
+
+// indexResource writes the marshaled resource to the text, contained and
+// search parameter indexes that are set.
+func (vt *VersionTracker) indexResource(ctx context.Context, resourceType, resourceID string, data []byte) error {
+	if vt.text == nil && vt.contained == nil && vt.search == nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil || m == nil {
+		return nil
+	}
+	return vt.IndexResource(ctx, resourceType, resourceID, m)
+}
+
+// IndexResource writes resource to the text, contained and search parameter
+// indexes that are set, as a create or update does. $reindex uses it to
+// index resources stored before an index was kept.
+func (vt *VersionTracker) IndexResource(ctx context.Context, resourceType, resourceID string, m map[string]interface{}) error {
+	if vt.text != nil {
+		if err := vt.text.IndexText(ctx, resourceType, resourceID, ExtractTextDocument(m)); err != nil {
+			return err
+		}
+	}
+	if vt.contained != nil {
+		if err := vt.contained.IndexContained(ctx, resourceType, resourceID, ExtractContainedResources(m)); err != nil {
+			return err
+		}
+	}
+	if vt.search != nil {
+		if err := vt.search.Index(ctx, resourceType, resourceID, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexesType reports whether vt keeps an index of resources of
+// resourceType: every type has text and contained resources, and only some
+// have custom search parameters for the tenant of ctx.
+func (vt *VersionTracker) indexesType(ctx context.Context, resourceType string) (bool, error) {
+	if vt.text != nil || vt.contained != nil {
+		return true, nil
+	}
+	if vt.search == nil {
+		return false, nil
+	}
+	return vt.search.hasIndexed(ctx, resourceType)
+}
+
 // GetVersion retrieves a specific version of a resource from history.
 func (vt *VersionTracker) GetVersion(ctx context.Context, resourceType, resourceID string, versionID int) (*HistoryEntry, error) {
 	return vt.repo.GetVersion(ctx, resourceType, resourceID, versionID)
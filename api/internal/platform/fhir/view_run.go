@@ -0,0 +1,395 @@
+package fhir
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetStore enables running views in the database and materializing them.
+// Without a store, $run, $materialize and $refresh answer 501.
+func (h *ViewDefinitionHandler) SetStore(store ViewStore) {
+	h.store = store
+}
+
+// SetDispatcher sets the dispatcher through which $run, $materialize and
+// $refresh check that the caller may read the view's resource type, by
+// asking the search route of the type, typically an EchoEntryDispatcher on
+// the FHIR routes. Without one, the operations are refused.
+func (h *ViewDefinitionHandler) SetDispatcher(d EntryDispatcher) {
+	h.dispatcher = d
+}
+
+// authorize checks that the caller may read resources of resourceType: a
+// view reads every resource of the type, so the caller must be served by
+// the type's search route, GET [base]/{type}, with its role checks. It
+// returns false, having written the refusal, when the caller may not.
+func (h *ViewDefinitionHandler) authorize(c echo.Context, resourceType string) (bool, error) {
+	if h.dispatcher == nil {
+		return false, c.JSON(http.StatusForbidden, NewOperationOutcome(IssueSeverityError, IssueTypeSecurity,
+			"access to "+resourceType+" cannot be checked"))
+	}
+	result, err := h.dispatcher.Dispatch(c.Request().Context(), &EntryDispatchRequest{
+		Method: http.MethodGet,
+		URL:    resourceType + "?_count=1",
+		Header: c.Request().Header,
+	})
+	if err != nil {
+		return false, c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
+	}
+	switch {
+	case result.StatusCode == http.StatusUnauthorized || result.StatusCode == http.StatusForbidden:
+		return false, c.Blob(result.StatusCode, echo.MIMEApplicationJSON, result.Body)
+	case result.StatusCode >= 400:
+		return false, c.JSON(http.StatusForbidden, NewOperationOutcome(IssueSeverityError, IssueTypeSecurity,
+			fmt.Sprintf("access to %s cannot be checked: its search answered %d", resourceType, result.StatusCode)))
+	}
+	return true, nil
+}
+
+// compiled returns the view with the given ID compiled to SQL, once the
+// caller is authorized to read its resource type, or writes the error
+// response and returns nil.
+func (h *ViewDefinitionHandler) compiled(c echo.Context) (*CompiledView, error) {
+	if h.store == nil {
+		return nil, c.JSON(http.StatusNotImplemented, ErrorOutcome("view store not configured"))
+	}
+	id := c.Param("id")
+	h.mu.RLock()
+	view, ok := h.views[id]
+	h.mu.RUnlock()
+	if !ok {
+		return nil, c.JSON(http.StatusNotFound, ErrorOutcome("ViewDefinition not found: "+id))
+	}
+	cv, err := CompileViewSQL(view)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, ErrorOutcome("view cannot run in the database: "+err.Error()))
+	}
+	if ok, err := h.authorize(c, view.Resource); !ok {
+		return nil, err
+	}
+	return cv, nil
+}
+
+// RunView runs a ViewDefinition against the resource store, or reads its
+// materialization, and streams the rows as json, csv, ndjson or parquet
+// (_format), up to _count rows.
+func (h *ViewDefinitionHandler) RunView(c echo.Context) error {
+	cv, err := h.compiled(c)
+	if cv == nil {
+		return err
+	}
+	format := c.QueryParam("_format")
+	if format == "" {
+		format = "json"
+	}
+	limit := 0
+	if n, err := strconv.Atoi(c.QueryParam("_count")); err == nil && n > 0 {
+		limit = n
+	}
+	out := &lazyResponseWriter{c: c}
+	w, ok := newViewRowWriter(format, cv.Columns, out)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ErrorOutcome("unsupported _format: "+format))
+	}
+	out.contentType = w.contentType()
+
+	err = h.store.Query(c.Request().Context(), cv, limit, w.writeRow)
+	if err == nil {
+		err = w.close()
+	}
+	if err != nil {
+		if out.started {
+			// The status is sent; the truncated body is all that is left.
+			return nil
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorOutcome("view execution failed: "+err.Error()))
+	}
+	return nil
+}
+
+// MaterializeView stores the rows of a view in the tenant's schema, as a
+// table or a materialized view (kind).
+func (h *ViewDefinitionHandler) MaterializeView(c echo.Context) error {
+	cv, err := h.compiled(c)
+	if cv == nil {
+		return err
+	}
+	kind := c.QueryParam("kind")
+	if kind != "" && kind != ViewMaterializeTable && kind != ViewMaterializeView {
+		return c.JSON(http.StatusBadRequest, ErrorOutcome("kind must be table or materialized-view"))
+	}
+	m, err := h.store.Materialize(c.Request().Context(), cv, kind)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorOutcome("materialization failed: "+err.Error()))
+	}
+	return c.JSON(http.StatusOK, m)
+}
+
+// GetMaterialization returns the materialization of a view.
+func (h *ViewDefinitionHandler) GetMaterialization(c echo.Context) error {
+	if h.store == nil {
+		return c.JSON(http.StatusNotImplemented, ErrorOutcome("view store not configured"))
+	}
+	m, err := h.store.GetMaterialization(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, ErrViewNotMaterialized) {
+		return c.JSON(http.StatusNotFound, ErrorOutcome("ViewDefinition is not materialized: "+c.Param("id")))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
+	}
+	return c.JSON(http.StatusOK, m)
+}
+
+// DropMaterialization removes the materialization of a view.
+func (h *ViewDefinitionHandler) DropMaterialization(c echo.Context) error {
+	if h.store == nil {
+		return c.JSON(http.StatusNotImplemented, ErrorOutcome("view store not configured"))
+	}
+	if err := h.store.DropMaterialization(c.Request().Context(), c.Param("id")); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorOutcome(err.Error()))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RefreshView recomputes the rows of a materialized view.
+func (h *ViewDefinitionHandler) RefreshView(c echo.Context) error {
+	if h.store == nil {
+		return c.JSON(http.StatusNotImplemented, ErrorOutcome("view store not configured"))
+	}
+	m, err := h.store.GetMaterialization(c.Request().Context(), c.Param("id"))
+	if err == nil {
+		var ok bool
+		if ok, err = h.authorize(c, m.ResourceType); !ok {
+			return err
+		}
+		m, err = h.store.Refresh(c.Request().Context(), c.Param("id"))
+	}
+	if errors.Is(err, ErrViewNotMaterialized) {
+		return c.JSON(http.StatusNotFound, ErrorOutcome("ViewDefinition is not materialized: "+c.Param("id")))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorOutcome("refresh failed: "+err.Error()))
+	}
+	return c.JSON(http.StatusOK, m)
+}
+
+// rematerialize replaces the materialization of a changed view, if it has
+// one. Views that no longer compile lose their materialization.
+func (h *ViewDefinitionHandler) rematerialize(c echo.Context, view *ViewDefinition) error {
+	if h.store == nil {
+		return nil
+	}
+	ctx := c.Request().Context()
+	m, err := h.store.GetMaterialization(ctx, view.ID)
+	if err != nil {
+		if errors.Is(err, ErrViewNotMaterialized) {
+			return nil
+		}
+		return err
+	}
+	cv, err := CompileViewSQL(view)
+	if err != nil {
+		return h.store.DropMaterialization(ctx, view.ID)
+	}
+	_, err = h.store.Materialize(ctx, cv, m.Kind)
+	return err
+}
+
+// lazyResponseWriter sends the response status and headers on the first
+// write, so that a view failing before its first row can still answer with
+// an error.
+type lazyResponseWriter struct {
+	c           echo.Context
+	contentType string
+	started     bool
+}
+
+func (w *lazyResponseWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.c.Response().Header().Set(echo.HeaderContentType, w.contentType)
+		w.c.Response().WriteHeader(http.StatusOK)
+	}
+	return w.c.Response().Write(p)
+}
+
+// viewRowWriter writes the rows of a view in an output format.
+type viewRowWriter interface {
+	contentType() string
+	writeRow(row []interface{}) error
+	// close writes whatever the format needs after the last row.
+	close() error
+}
+
+// newViewRowWriter returns the writer of format: json, csv, ndjson or
+// parquet.
+func newViewRowWriter(format string, cols []ViewColumn, w io.Writer) (viewRowWriter, bool) {
+	switch format {
+	case "json":
+		return &viewJSONWriter{cols: cols, w: w}, true
+	case "ndjson":
+		return &viewJSONWriter{cols: cols, w: w, lines: true}, true
+	case "csv":
+		return &viewCSVWriter{cols: cols, w: csv.NewWriter(w)}, true
+	case "parquet":
+		return &viewParquetWriter{w: w, rows: newParquetRows(viewParquetColumns(cols))}, true
+	}
+	return nil, false
+}
+
+// viewJSONWriter writes rows as a JSON array of objects, or as NDJSON.
+type viewJSONWriter struct {
+	cols  []ViewColumn
+	w     io.Writer
+	lines bool
+	rows  int
+}
+
+func (j *viewJSONWriter) contentType() string {
+	if j.lines {
+		return "application/x-ndjson"
+	}
+	return echo.MIMEApplicationJSON
+}
+
+func (j *viewJSONWriter) writeRow(row []interface{}) error {
+	obj := make(map[string]interface{}, len(j.cols))
+	for i, col := range j.cols {
+		obj[col.Name] = row[i]
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	switch {
+	case j.lines:
+		b = append(b, '\n')
+	case j.rows == 0:
+		b = append([]byte("["), b...)
+	default:
+		b = append([]byte(","), b...)
+	}
+	j.rows++
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *viewJSONWriter) close() error {
+	switch {
+	case j.lines:
+		return nil
+	case j.rows == 0:
+		_, err := j.w.Write([]byte("[]"))
+		return err
+	default:
+		_, err := j.w.Write([]byte("]"))
+		return err
+	}
+}
+
+// viewCSVWriter writes rows as CSV with a header line, flushing every 100
+// rows.
+type viewCSVWriter struct {
+	cols   []ViewColumn
+	w      *csv.Writer
+	header bool
+	rows   int
+}
+
+func (v *viewCSVWriter) contentType() string {
+	return "text/csv"
+}
+
+func (v *viewCSVWriter) writeHeader() error {
+	if v.header {
+		return nil
+	}
+	v.header = true
+	header := make([]string, len(v.cols))
+	for i, col := range v.cols {
+		header[i] = col.Name
+	}
+	return v.w.Write(header)
+}
+
+func (v *viewCSVWriter) writeRow(row []interface{}) error {
+	if err := v.writeHeader(); err != nil {
+		return err
+	}
+	record := make([]string, len(row))
+	for i, val := range row {
+		if val != nil {
+			record[i] = formatCSVValue(val)
+		}
+	}
+	if err := v.w.Write(record); err != nil {
+		return err
+	}
+	if v.rows++; v.rows%100 == 0 {
+		v.w.Flush()
+	}
+	return v.w.Error()
+}
+
+func (v *viewCSVWriter) close() error {
+	if err := v.writeHeader(); err != nil {
+		return err
+	}
+	v.w.Flush()
+	return v.w.Error()
+}
+
+// viewParquetWriter collects the rows of a Parquet file, which is written
+// as a whole once the last row is in.
+type viewParquetWriter struct {
+	w    io.Writer
+	rows *parquetRows
+}
+
+func (p *viewParquetWriter) contentType() string {
+	return ParquetOutputFormat
+}
+
+func (p *viewParquetWriter) writeRow(row []interface{}) error {
+	p.rows.add(row...)
+	return nil
+}
+
+func (p *viewParquetWriter) close() error {
+	var buf bytes.Buffer
+	if err := p.rows.writeTo(&buf); err != nil {
+		return err
+	}
+	_, err := p.w.Write(buf.Bytes())
+	return err
+}
+
+// viewParquetColumns returns the Parquet columns of a view's columns, all
+// optional: integers as INT64, decimals as DOUBLE, booleans as BOOLEAN, and
+// everything else, collections included, as UTF-8 strings.
+func viewParquetColumns(cols []ViewColumn) []parquetColumn {
+	out := make([]parquetColumn, len(cols))
+	for i, col := range cols {
+		typ := int32(parquetTypeByteArray)
+		if !col.Collection {
+			switch col.Type {
+			case "integer", "positiveInt", "unsignedInt", "integer64":
+				typ = parquetTypeInt64
+			case "decimal":
+				typ = parquetTypeDouble
+			case "boolean":
+				typ = parquetTypeBoolean
+			}
+		}
+		out[i] = parquetColumn{name: col.Name, typ: typ, optional: true}
+	}
+	return out
+}
@@ -0,0 +1,256 @@
+package fhir
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/auth"
+)
+
+// fakeViewStore answers queries with fixed rows and records
+// materializations in memory.
+type fakeViewStore struct {
+	rows        [][]interface{}
+	limit       int
+	materialize map[string]*ViewMaterialization
+}
+
+func newFakeViewStore(rows ...[]interface{}) *fakeViewStore {
+	return &fakeViewStore{rows: rows, materialize: make(map[string]*ViewMaterialization)}
+}
+
+func (s *fakeViewStore) Query(_ context.Context, cv *CompiledView, limit int, fn func(row []interface{}) error) error {
+	s.limit = limit
+	for i, row := range s.rows {
+		if limit > 0 && i == limit {
+			break
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fakeViewStore) Materialize(_ context.Context, cv *CompiledView, kind string) (*ViewMaterialization, error) {
+	if kind == "" {
+		kind = ViewMaterializeTable
+	}
+	m := &ViewMaterialization{ViewID: cv.View.ID, Table: viewTableName(cv.View.ID), Kind: kind, ResourceType: cv.View.Resource, Definition: cv.View}
+	s.materialize[cv.View.ID] = m
+	return m, nil
+}
+
+func (s *fakeViewStore) Refresh(_ context.Context, viewID string) (*ViewMaterialization, error) {
+	m, ok := s.materialize[viewID]
+	if !ok {
+		return nil, ErrViewNotMaterialized
+	}
+	m.Stale = false
+	return m, nil
+}
+
+func (s *fakeViewStore) GetMaterialization(_ context.Context, viewID string) (*ViewMaterialization, error) {
+	m, ok := s.materialize[viewID]
+	if !ok {
+		return nil, ErrViewNotMaterialized
+	}
+	return m, nil
+}
+
+func (s *fakeViewStore) DropMaterialization(_ context.Context, viewID string) error {
+	delete(s.materialize, viewID)
+	return nil
+}
+
+// newTestViewRunHandler serves the view routes, and search routes for the
+// view resource types, behind middleware, which check access to them.
+func newTestViewRunHandler(store ViewStore, middleware ...echo.MiddlewareFunc) *echo.Echo {
+	h := newTestViewHandler()
+	h.SetStore(store)
+	e := echo.New()
+	h.SetDispatcher(NewEchoEntryDispatcher(e, "/fhir"))
+	h.RegisterRoutes(e.Group("/fhir"))
+	for _, rt := range []string{"Patient", "Condition"} {
+		e.GET("/fhir/"+rt, func(c echo.Context) error {
+			return c.JSONBlob(http.StatusOK, searchBundleJSON())
+		}, middleware...)
+	}
+	return e
+}
+
+func vdServe(e *echo.Echo, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestViewDefinition_Handler_Run_NoStore(t *testing.T) {
+	h := newTestViewHandler()
+	e := echo.New()
+	h.RegisterRoutes(e.Group("/fhir"))
+
+	for _, target := range []string{
+		"/fhir/ViewDefinition/patient_demographics/$run",
+		"/fhir/ViewDefinition/patient_demographics/$materialize",
+		"/fhir/ViewDefinition/patient_demographics/$refresh",
+	} {
+		if rec := vdServe(e, http.MethodPost, target, ""); rec.Code != http.StatusNotImplemented {
+			t.Errorf("%s: expected 501, got %d", target, rec.Code)
+		}
+	}
+}
+
+func TestViewDefinition_Handler_Run_Formats(t *testing.T) {
+	store := newFakeViewStore(
+		[]interface{}{"pt-1", "Doe", "Jane", "1985-07-23", "female", "MRN1", nil, nil, true},
+		[]interface{}{"pt-2", nil, nil, nil, "male", nil, nil, nil, false},
+	)
+	e := newTestViewRunHandler(store)
+
+	rec := vdServe(e, http.MethodGet, "/fhir/ViewDefinition/patient_demographics/$run?_format=csv&_count=1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if store.limit != 1 {
+		t.Errorf("expected _count to limit the query, got %d", store.limit)
+	}
+	if ct := rec.Header().Get(echo.HeaderContentType); ct != "text/csv" {
+		t.Errorf("expected text/csv, got %s", ct)
+	}
+	if want := "id,family_name,given_name,birth_date,gender,mrn,phone,email,active\npt-1,Doe,Jane,1985-07-23,female,MRN1,,,true\n"; rec.Body.String() != want {
+		t.Errorf("expected CSV %q, got %q", want, rec.Body.String())
+	}
+
+	rec = vdServe(e, http.MethodGet, "/fhir/ViewDefinition/patient_demographics/$run?_format=ndjson", "")
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 NDJSON lines, got %q", rec.Body.String())
+	}
+	var row map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &row); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if row["id"] != "pt-2" || row["family_name"] != nil || row["active"] != false {
+		t.Errorf("unexpected NDJSON row %v", row)
+	}
+
+	rec = vdServe(e, http.MethodPost, "/fhir/ViewDefinition/patient_demographics/$run", "")
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &rows); err != nil || len(rows) != 2 {
+		t.Errorf("expected a JSON array of 2 rows, got %s", rec.Body.String())
+	}
+
+	rec = vdServe(e, http.MethodGet, "/fhir/ViewDefinition/patient_demographics/$run?_format=parquet", "")
+	if ct := rec.Header().Get(echo.HeaderContentType); ct != ParquetOutputFormat {
+		t.Errorf("expected %s, got %s", ParquetOutputFormat, ct)
+	}
+	if data := rec.Body.Bytes(); !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.Contains(data, []byte("family_name")) {
+		t.Error("expected a Parquet file with the view's columns")
+	}
+
+	rec = vdServe(e, http.MethodGet, "/fhir/ViewDefinition/patient_demographics/$run?_format=xml", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown format, got %d", rec.Code)
+	}
+}
+
+func TestViewDefinition_Handler_Materialize(t *testing.T) {
+	store := newFakeViewStore()
+	e := newTestViewRunHandler(store)
+
+	rec := vdServe(e, http.MethodGet, "/fhir/ViewDefinition/active_conditions/$materialize", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 before materializing, got %d", rec.Code)
+	}
+
+	rec = vdServe(e, http.MethodPost, "/fhir/ViewDefinition/active_conditions/$materialize?kind=materialized-view", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var m ViewMaterialization
+	if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if m.Table != "view_active_conditions" || m.Kind != ViewMaterializeView || m.ResourceType != "Condition" {
+		t.Errorf("unexpected materialization %+v", m)
+	}
+
+	// Changing the view rematerializes it with the same kind.
+	view := store.materialize["active_conditions"].Definition
+	changed := *view
+	changed.Select = append(append([]ViewColumn(nil), view.Select...), ViewColumn{Path: "onsetDateTime", Name: "onset"})
+	body, _ := json.Marshal(changed)
+	if rec := vdServe(e, http.MethodPut, "/fhir/ViewDefinition/active_conditions", string(body)); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if m := store.materialize["active_conditions"]; m.Kind != ViewMaterializeView || len(m.Definition.Select) != len(changed.Select) {
+		t.Errorf("expected the changed view to be rematerialized, got %+v", m)
+	}
+
+	if rec := vdServe(e, http.MethodPost, "/fhir/ViewDefinition/active_conditions/$refresh", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 from $refresh, got %d", rec.Code)
+	}
+	if rec := vdServe(e, http.MethodDelete, "/fhir/ViewDefinition/active_conditions", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", rec.Code)
+	}
+	if _, ok := store.materialize["active_conditions"]; ok {
+		t.Error("expected deleting the view to drop its materialization")
+	}
+}
+
+func TestViewDefinition_Handler_ChecksResourceAccess(t *testing.T) {
+	store := newFakeViewStore()
+	e := newTestViewRunHandler(store, auth.RequireRole("admin"))
+	serve := func(method, target, role string) int {
+		req := httptest.NewRequest(method, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), auth.UserRolesKey, []string{role}))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, target := range []string{
+		"/fhir/ViewDefinition/patient_demographics/$run",
+		"/fhir/ViewDefinition/active_conditions/$materialize",
+	} {
+		if code := serve(http.MethodPost, target, "physician"); code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 for a role the search route refuses, got %d", target, code)
+		}
+		if code := serve(http.MethodPost, target, "admin"); code != http.StatusOK {
+			t.Errorf("%s: expected 200 for admin, got %d", target, code)
+		}
+	}
+	if code := serve(http.MethodPost, "/fhir/ViewDefinition/active_conditions/$refresh", "physician"); code != http.StatusForbidden {
+		t.Errorf("$refresh: expected 403, got %d", code)
+	}
+
+	// Without a dispatcher, access cannot be checked and views do not run.
+	h := newTestViewHandler()
+	h.SetStore(store)
+	e = echo.New()
+	h.RegisterRoutes(e.Group("/fhir"))
+	if code := serve(http.MethodPost, "/fhir/ViewDefinition/patient_demographics/$run", "admin"); code != http.StatusForbidden {
+		t.Errorf("expected 403 without a dispatcher, got %d", code)
+	}
+}
+
+func TestViewDefinition_Handler_Create_Invalid(t *testing.T) {
+	e := newTestViewRunHandler(newFakeViewStore())
+	body := `{"id":"dup","name":"dup","resource":"Patient","select":[{"path":"id","name":"id"},{"column":[{"path":"gender","name":"id"}]}]}`
+	rec := vdServe(e, http.MethodPost, "/fhir/ViewDefinition", body)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "duplicate column") {
+		t.Errorf("expected 400 for duplicate columns, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,510 @@
+package fhir
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ViewDefinitions in PostgreSQL.
+//
+// A ViewDefinition compiles to a single SELECT over the resource store: the
+// latest version of each resource of the view's type recorded in
+// resource_history, whose snapshots hold the resources as served. FHIRPath
+// expressions are translated to SQL/JSON path (jsonpath) expressions, whose
+// lax mode flattens arrays along a path as FHIRPath navigation does:
+// name.given becomes $."name"[*]."given"[*]. forEach becomes a lateral
+// join on jsonb_path_query, forEachOrNull a lateral subquery with a row of
+// nulls for an empty path, and unionAll a lateral UNION ALL subquery, so
+// the database produces the rows of SQL-on-FHIR v2.
+//
+// The translation covers the FHIRPath subset that views use: member
+// navigation, indexers, first(), where(), ofType(), exists(), empty(),
+// not(), the comparison and boolean operators, contains(), startsWith(),
+// endsWith() and matches(), and getResourceKey() and getReferenceKey() as
+// whole column expressions. An indexer, or first(), selects the first item
+// of each parent's list rather than of the whole collection, which differs
+// only below repeating elements. Other expressions are reported as errors.
+
+// CompiledView is a ViewDefinition compiled to SQL.
+type CompiledView struct {
+	View    *ViewDefinition
+	Columns []ViewColumn
+
+	selects []string
+	joins   []string
+	where   []string
+}
+
+// CompileViewSQL compiles a ViewDefinition to a query over the resource
+// store.
+func CompileViewSQL(view *ViewDefinition) (*CompiledView, error) {
+	if err := view.Validate(); err != nil {
+		return nil, err
+	}
+	c := &viewCompiler{view: view, aliases: new(int)}
+	selects, err := c.list(view.Select, "r.resource")
+	if err != nil {
+		return nil, err
+	}
+	cv := &CompiledView{View: view, Columns: view.Columns(), selects: selects, joins: c.joins}
+	for _, w := range view.Where {
+		node, err := parseViewPath(view, w.Path)
+		if err != nil {
+			return nil, err
+		}
+		pred, err := c.predicate(node, "@")
+		if err != nil {
+			return nil, fmt.Errorf("where %q: %w", w.Path, err)
+		}
+		cv.where = append(cv.where, fmt.Sprintf("jsonb_path_exists(r.resource, %s)", jsonPathSQL("$ ? ("+pred+")")))
+	}
+	return cv, nil
+}
+
+// SQL returns the SELECT statement of the view.
+func (cv *CompiledView) SQL() string {
+	return cv.query(false, false)
+}
+
+// query returns the SELECT statement of the view. With key, the rows lead
+// with the _resource_id of their resource, as materializations store them;
+// with one, only the resource whose id is $1 is selected.
+func (cv *CompiledView) query(key, one bool) string {
+	selects := cv.selects
+	if key {
+		selects = append([]string{`r.resource_id AS "_resource_id"`}, selects...)
+	}
+	filter := ""
+	if one {
+		filter = " AND h.resource_id = $1"
+	}
+	var b strings.Builder
+	b.WriteString("SELECT " + strings.Join(selects, ",\n       "))
+	fmt.Fprintf(&b, "\nFROM (SELECT DISTINCT ON (h.resource_id) h.resource_id, h.resource, h.action"+
+		"\n      FROM resource_history h"+
+		"\n      WHERE h.resource_type = %s%s"+
+		"\n      ORDER BY h.resource_id, h.version_id DESC) AS r", sqlLiteral(cv.View.Resource), filter)
+	for _, j := range cv.joins {
+		b.WriteString("\n" + j)
+	}
+	b.WriteString("\nWHERE r.action <> 'delete'")
+	for _, w := range cv.where {
+		b.WriteString("\n  AND " + w)
+	}
+	return b.String()
+}
+
+// viewCompiler compiles the select lists of a view.
+type viewCompiler struct {
+	view  *ViewDefinition
+	joins []string
+	// aliases numbers the lateral joins, across nested compilers.
+	aliases *int
+}
+
+func (c *viewCompiler) alias(prefix string) string {
+	*c.aliases++
+	return fmt.Sprintf("%s%d", prefix, *c.aliases)
+}
+
+// list compiles a select list evaluated against the jsonb expression ctx,
+// adding its joins, and returns its select expressions.
+func (c *viewCompiler) list(list []ViewColumn, ctx string) ([]string, error) {
+	var selects []string
+	for _, col := range list {
+		if col.isSelect() {
+			elSelects, err := c.element(col, ctx)
+			if err != nil {
+				return nil, err
+			}
+			selects = append(selects, elSelects...)
+			continue
+		}
+		expr, err := c.column(col, ctx)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", col.Name, err)
+		}
+		selects = append(selects, expr+" AS "+sqlIdent(col.Name))
+	}
+	return selects, nil
+}
+
+// element compiles a select element.
+func (c *viewCompiler) element(el ViewColumn, ctx string) ([]string, error) {
+	if path := el.forEachPath(); path != "" {
+		node, err := parseViewPath(c.view, path)
+		if err != nil {
+			return nil, err
+		}
+		jp, err := c.path(node, "$")
+		if err != nil {
+			return nil, fmt.Errorf("forEach %q: %w", path, err)
+		}
+		if el.ForEachOrNull != "" {
+			return c.orNull(el, ctx, jp)
+		}
+		alias := c.alias("f")
+		c.joins = append(c.joins, fmt.Sprintf("CROSS JOIN LATERAL jsonb_path_query(%s, %s) AS %s(node)", ctx, jsonPathSQL(jp), alias))
+		ctx = alias + ".node"
+	}
+
+	selects, err := c.list(append(append([]ViewColumn(nil), el.Column...), el.Select...), ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(el.UnionAll) == 0 {
+		return selects, nil
+	}
+
+	alias := c.alias("u")
+	branches := make([]string, 0, len(el.UnionAll))
+	for _, alt := range el.UnionAll {
+		sub := &viewCompiler{view: c.view, aliases: c.aliases}
+		altSelects, err := sub.list([]ViewColumn{alt}, ctx)
+		if err != nil {
+			return nil, err
+		}
+		branch := fmt.Sprintf("SELECT %s FROM (VALUES (1)) AS %s(x)", strings.Join(altSelects, ", "), c.alias("b"))
+		for _, j := range sub.joins {
+			branch += " " + j
+		}
+		branches = append(branches, branch)
+	}
+	c.joins = append(c.joins, fmt.Sprintf("CROSS JOIN LATERAL (%s) AS %s", strings.Join(branches, " UNION ALL "), alias))
+	for _, col := range viewColumns(el.UnionAll[:1]) {
+		selects = append(selects, alias+"."+sqlIdent(col.Name)+" AS "+sqlIdent(col.Name))
+	}
+	return selects, nil
+}
+
+// orNull compiles a forEachOrNull element as a lateral subquery: the rows
+// of the element as a forEach, or a row of nulls for all of its columns
+// when its path jp has no items.
+func (c *viewCompiler) orNull(el ViewColumn, ctx, jp string) ([]string, error) {
+	inner := el
+	inner.ForEach, inner.ForEachOrNull = el.ForEachOrNull, ""
+	sub := &viewCompiler{view: c.view, aliases: c.aliases}
+	innerSelects, err := sub.element(inner, ctx)
+	if err != nil {
+		return nil, err
+	}
+	branch := fmt.Sprintf("SELECT %s FROM (VALUES (1)) AS %s(x)", strings.Join(innerSelects, ", "), c.alias("b"))
+	for _, j := range sub.joins {
+		branch += " " + j
+	}
+	cols := viewColumns([]ViewColumn{el})
+	nulls := make([]string, len(cols))
+	selects := make([]string, len(cols))
+	alias := c.alias("o")
+	for i, col := range cols {
+		nulls[i] = "NULL AS " + sqlIdent(col.Name)
+		selects[i] = alias + "." + sqlIdent(col.Name) + " AS " + sqlIdent(col.Name)
+	}
+	c.joins = append(c.joins, fmt.Sprintf("CROSS JOIN LATERAL (%s UNION ALL SELECT %s WHERE NOT jsonb_path_exists(%s, %s)) AS %s",
+		branch, strings.Join(nulls, ", "), ctx, jsonPathSQL(jp), alias))
+	return selects, nil
+}
+
+// column compiles the value of a column evaluated against ctx: the first
+// item of its path, cast to the column's type, or a jsonb array of all
+// items for a collection column. Columns without a type are text.
+func (c *viewCompiler) column(col ViewColumn, ctx string) (string, error) {
+	node, err := parseViewPath(c.view, col.Path)
+	if err != nil {
+		return "", err
+	}
+	if node.kind == ndFunction && node.value == "getResourceKey" && len(node.children) == 0 {
+		return "r.resource_id", nil
+	}
+	if node.kind == ndFunction && node.value == "getReferenceKey" && len(node.children) > 0 {
+		return c.referenceKey(node, ctx)
+	}
+	// The first item is taken anyway.
+	if !col.Collection && node.kind == ndFunction && node.value == "first" && len(node.children) == 1 {
+		node = node.children[0]
+	}
+	jp, err := c.path(node, "$")
+	if err != nil {
+		return "", err
+	}
+	if col.Collection {
+		return fmt.Sprintf("(SELECT jsonb_agg(v) FROM jsonb_path_query(%s, %s) AS v)", ctx, jsonPathSQL(jp)), nil
+	}
+	switch col.Type {
+	case "integer", "positiveInt", "unsignedInt", "integer64":
+		return fmt.Sprintf("round((jsonb_path_query_first(%s, %s) #>> '{}')::numeric)::bigint",
+			ctx, jsonPathSQL(jp+` ? (@.type() == "number")`)), nil
+	case "decimal":
+		return fmt.Sprintf("(jsonb_path_query_first(%s, %s) #>> '{}')::float8",
+			ctx, jsonPathSQL(jp+` ? (@.type() == "number")`)), nil
+	case "boolean":
+		return fmt.Sprintf("(jsonb_path_query_first(%s, %s) #>> '{}')::boolean",
+			ctx, jsonPathSQL(jp+` ? (@.type() == "boolean")`)), nil
+	default:
+		return fmt.Sprintf("jsonb_path_query_first(%s, %s) #>> '{}'", ctx, jsonPathSQL(jp)), nil
+	}
+}
+
+// referenceKey compiles getReferenceKey([type]): the id of the first
+// reference of the receiver, of the given type if any.
+func (c *viewCompiler) referenceKey(node *astNode, ctx string) (string, error) {
+	jp, err := c.path(node.children[0], "$")
+	if err != nil {
+		return "", err
+	}
+	jp += `."reference"[*]`
+	if len(node.children) > 1 {
+		rt, ok := identArg(node.children[1])
+		if !ok {
+			return "", fmt.Errorf("getReferenceKey() takes a resource type")
+		}
+		jp += fmt.Sprintf(" ? (@ like_regex %s)", jsonPathString("(^|/)"+regexp.QuoteMeta(rt)+"/[^/]+(/_history/[^/]+)?$"))
+	}
+	return fmt.Sprintf("substring(jsonb_path_query_first(%s, %s) #>> '{}' from '([^/]+)(/_history/[^/]+)?$')",
+		ctx, jsonPathSQL(jp)), nil
+}
+
+// path translates a FHIRPath path to a jsonpath expression whose items are
+// the items of the path, evaluated from root: "$", or "@" in a filter.
+func (c *viewCompiler) path(node *astNode, root string) (string, error) {
+	switch node.kind {
+	case ndPath:
+		name := node.value.(string)
+		if name == "$this" || name == c.view.Resource && root == "$" {
+			return root, nil
+		}
+		return root + "." + jsonPathString(name) + "[*]", nil
+
+	case ndDot:
+		left, err := c.path(node.children[0], root)
+		if err != nil {
+			return "", err
+		}
+		return left + "." + jsonPathString(node.children[1].value.(string)) + "[*]", nil
+
+	case ndIndex:
+		left, err := c.path(node.children[0], root)
+		if err != nil {
+			return "", err
+		}
+		return indexPath(left, node.value.(int64))
+
+	case ndFunction:
+		name := node.value.(string)
+		if len(node.children) == 0 {
+			return "", fmt.Errorf("%s() cannot be translated to SQL", name)
+		}
+		left, err := c.path(node.children[0], root)
+		if err != nil {
+			return "", err
+		}
+		args := node.children[1:]
+		switch {
+		case name == "first" && len(args) == 0:
+			return indexPath(left, 0)
+		case name == "where" && len(args) == 1:
+			pred, err := c.predicate(args[0], "@")
+			if err != nil {
+				return "", err
+			}
+			return left + " ? (" + pred + ")", nil
+		case name == "ofType" && len(args) == 1:
+			typ, ok := identArg(args[0])
+			if !ok || !strings.HasSuffix(left, `"[*]`) {
+				return "", fmt.Errorf("ofType() is only supported on a choice element")
+			}
+			// value.ofType(Quantity) is the element valueQuantity.
+			r := []rune(typ)
+			r[0] = unicode.ToUpper(r[0])
+			return strings.TrimSuffix(left, `"[*]`) + string(r) + `"[*]`, nil
+		}
+		return "", fmt.Errorf("%s() cannot be translated to SQL", name)
+	}
+	return "", fmt.Errorf("expression cannot be translated to SQL")
+}
+
+// indexPath selects the item at index of the list of the last member of a
+// path.
+func indexPath(path string, index int64) (string, error) {
+	if !strings.HasSuffix(path, "[*]") {
+		return "", fmt.Errorf("an indexer or first() is only supported after an element")
+	}
+	return fmt.Sprintf("%s[%d]", strings.TrimSuffix(path, "[*]"), index), nil
+}
+
+// predicate translates a FHIRPath boolean expression to a jsonpath
+// predicate evaluated from root.
+func (c *viewCompiler) predicate(node *astNode, root string) (string, error) {
+	switch node.kind {
+	case ndAnd, ndOr, ndImplies:
+		left, err := c.predicate(node.children[0], root)
+		if err != nil {
+			return "", err
+		}
+		right, err := c.predicate(node.children[1], root)
+		if err != nil {
+			return "", err
+		}
+		switch node.kind {
+		case ndAnd:
+			return "(" + left + " && " + right + ")", nil
+		case ndOr:
+			return "(" + left + " || " + right + ")", nil
+		default:
+			return "(!(" + left + ") || " + right + ")", nil
+		}
+
+	case ndCompare:
+		left, err := c.operand(node.children[0], root)
+		if err != nil {
+			return "", err
+		}
+		right, err := c.operand(node.children[1], root)
+		if err != nil {
+			return "", err
+		}
+		op := node.value.(string)
+		if op == "=" {
+			op = "=="
+		}
+		return left + " " + op + " " + right, nil
+
+	case ndLiteral:
+		if b, ok := node.value.(bool); ok {
+			return fmt.Sprintf("(%t == true)", b), nil
+		}
+
+	case ndFunction:
+		name := node.value.(string)
+		if len(node.children) == 0 {
+			break
+		}
+		args := node.children[1:]
+		switch name {
+		case "exists", "empty":
+			p, err := c.path(node.children[0], root)
+			if err != nil {
+				return "", err
+			}
+			if len(args) == 1 {
+				pred, err := c.predicate(args[0], "@")
+				if err != nil {
+					return "", err
+				}
+				p += " ? (" + pred + ")"
+			}
+			if name == "empty" {
+				return "!exists(" + p + ")", nil
+			}
+			return "exists(" + p + ")", nil
+		case "not":
+			pred, err := c.predicate(node.children[0], root)
+			if err != nil {
+				return "", err
+			}
+			return "!(" + pred + ")", nil
+		case "contains", "startsWith", "endsWith", "matches":
+			s, ok := stringArg(args)
+			if !ok {
+				return "", fmt.Errorf("%s() takes a string", name)
+			}
+			p, err := c.path(node.children[0], root)
+			if err != nil {
+				return "", err
+			}
+			switch name {
+			case "contains":
+				return p + " like_regex " + jsonPathString(regexp.QuoteMeta(s)), nil
+			case "startsWith":
+				return p + " starts with " + jsonPathString(s), nil
+			case "endsWith":
+				return p + " like_regex " + jsonPathString(regexp.QuoteMeta(s)+"$"), nil
+			default:
+				return p + " like_regex " + jsonPathString(s), nil
+			}
+		}
+	}
+
+	// Any other expression is true when it has an item other than false.
+	p, err := c.path(node, root)
+	if err != nil {
+		return "", err
+	}
+	return "exists(" + p + ` ? (@ == true || @.type() != "boolean"))`, nil
+}
+
+// operand translates a comparison operand: a literal or a path.
+func (c *viewCompiler) operand(node *astNode, root string) (string, error) {
+	if node.kind != ndLiteral {
+		return c.path(node, root)
+	}
+	switch v := node.value.(type) {
+	case string:
+		return jsonPathString(v), nil
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return jsonPathString(v.Format("2006-01-02")), nil
+		}
+		return jsonPathString(v.Format(time.RFC3339)), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// parseViewPath parses a FHIRPath expression of a view, with its constants
+// expanded. $this is the context item, as a whole expression or a prefix.
+func parseViewPath(view *ViewDefinition, path string) (*astNode, error) {
+	expr := strings.TrimSpace(view.expandConstants(path))
+	if expr == "$this" {
+		return &astNode{kind: ndPath, value: "$this"}, nil
+	}
+	tokens, err := tokenize(strings.TrimPrefix(expr, "$this."))
+	if err != nil {
+		return nil, fmt.Errorf("fhirpath %q: %w", path, err)
+	}
+	p := &parser{tokens: tokens}
+	node, err := p.parseExpression(0)
+	if err != nil {
+		return nil, fmt.Errorf("fhirpath %q: %w", path, err)
+	}
+	if tok := p.peek(); tok.kind != tkEOF {
+		return nil, fmt.Errorf("fhirpath %q: unexpected token %q at position %d", path, tok.value, tok.pos)
+	}
+	return node, nil
+}
+
+// identArg returns the name of a type argument, such as Quantity.
+func identArg(node *astNode) (string, bool) {
+	if node.kind != ndPath {
+		return "", false
+	}
+	name, _ := node.value.(string)
+	return name, name != ""
+}
+
+// stringArg returns the value of a single string literal argument.
+func stringArg(args []*astNode) (string, bool) {
+	if len(args) != 1 || args[0].kind != ndLiteral {
+		return "", false
+	}
+	s, ok := args[0].value.(string)
+	return s, ok
+}
+
+// jsonPathSQL returns a jsonpath expression as an SQL literal.
+func jsonPathSQL(jp string) string {
+	return sqlLiteral(jp) + "::jsonpath"
+}
+
+// sqlLiteral quotes s as an SQL string literal.
+func sqlLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// sqlIdent quotes name as an SQL identifier.
+func sqlIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,245 @@
+package fhir
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func vdNestedView() *ViewDefinition {
+	return &ViewDefinition{
+		ID:       "patient_names",
+		Name:     "patient_names",
+		Resource: "Patient",
+		Select: []ViewColumn{
+			{Column: []ViewColumn{{Path: "getResourceKey()", Name: "id"}}},
+			{ForEachOrNull: "name", Column: []ViewColumn{{Path: "family", Name: "family"}},
+				Select: []ViewColumn{{ForEach: "given", Column: []ViewColumn{{Path: "$this", Name: "given"}}}}},
+		},
+	}
+}
+
+func TestCompileViewSQL_ForEach(t *testing.T) {
+	cv, err := CompileViewSQL(vdNestedView())
+	if err != nil {
+		t.Fatalf("CompileViewSQL failed: %v", err)
+	}
+	sql := cv.SQL()
+	for _, want := range []string{
+		`r.resource_id AS "id"`,
+		`CROSS JOIN LATERAL jsonb_path_query(r.resource, '$."name"[*]'::jsonpath) AS f1(node)`,
+		`CROSS JOIN LATERAL jsonb_path_query(f1.node, '$."given"[*]'::jsonpath) AS f2(node)`,
+		`jsonb_path_query_first(f2.node, '$'::jsonpath) #>> '{}' AS "given"`,
+		`UNION ALL SELECT NULL AS "family", NULL AS "given" WHERE NOT jsonb_path_exists(r.resource, '$."name"[*]'::jsonpath)) AS o4`,
+		`o4."given" AS "given"`,
+		`WHERE h.resource_type = 'Patient'`,
+		`WHERE r.action <> 'delete'`,
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in:\n%s", want, sql)
+		}
+	}
+	if names := columnNames(cv.Columns); names != "id, family, given" {
+		t.Errorf("expected columns id, family, given, got %s", names)
+	}
+}
+
+func TestCompileViewSQL_UnionAllWhereAndReferences(t *testing.T) {
+	view := &ViewDefinition{
+		ID:        "contacts",
+		Name:      "contacts",
+		Resource:  "Patient",
+		Constants: []ViewConstant{{Name: "sys", Value: "phone"}},
+		Where:     []ViewWhere{{Path: "active = true"}},
+		Select: []ViewColumn{
+			{Path: "managingOrganization.getReferenceKey(Organization)", Name: "org"},
+			{UnionAll: []ViewColumn{
+				{ForEach: "telecom.where(system = %sys)", Column: []ViewColumn{{Path: "value", Name: "tel"}}},
+				{ForEach: "contact.telecom", Column: []ViewColumn{{Path: "value", Name: "tel"}}},
+			}},
+		},
+	}
+	cv, err := CompileViewSQL(view)
+	if err != nil {
+		t.Fatalf("CompileViewSQL failed: %v", err)
+	}
+	sql := cv.SQL()
+	for _, want := range []string{
+		`jsonb_path_exists(r.resource, '$ ? (@."active"[*] == true)'::jsonpath)`,
+		`$."telecom"[*] ? (@."system"[*] == "phone")`,
+		` UNION ALL `,
+		`$."contact"[*]."telecom"[*]`,
+		`(^|/)Organization/[^/]+(/_history/[^/]+)?$`,
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in:\n%s", want, sql)
+		}
+	}
+
+	one := cv.query(true, true)
+	if !strings.HasPrefix(one, `SELECT r.resource_id AS "_resource_id"`) || !strings.Contains(one, "h.resource_id = $1") {
+		t.Errorf("expected a keyed query of one resource, got:\n%s", one)
+	}
+}
+
+func TestCompileViewSQL_TypedColumns(t *testing.T) {
+	view := &ViewDefinition{
+		ID:       "obs",
+		Name:     "obs",
+		Resource: "Observation",
+		Select: []ViewColumn{
+			{Path: "valueQuantity.value", Name: "value", Type: "decimal"},
+			{Path: "component.count()", Name: "n", Type: "integer"},
+		},
+	}
+	if _, err := CompileViewSQL(view); err == nil {
+		t.Fatal("expected count() to be rejected")
+	}
+
+	view.Select = append(view.Select[:1],
+		ViewColumn{Path: "code.coding.code", Name: "codes", Collection: true},
+		ViewColumn{Path: "extension.where(url = 'x').valueBoolean", Name: "flag", Type: "boolean"})
+	cv, err := CompileViewSQL(view)
+	if err != nil {
+		t.Fatalf("CompileViewSQL failed: %v", err)
+	}
+	sql := cv.SQL()
+	for _, want := range []string{
+		`::float8 AS "value"`,
+		`(SELECT jsonb_agg(v) FROM jsonb_path_query(r.resource, '$."code"[*]."coding"[*]."code"[*]'::jsonpath) AS v) AS "codes"`,
+		`::boolean AS "flag"`,
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in:\n%s", want, sql)
+		}
+	}
+}
+
+func TestCompileViewSQL_BuiltIns(t *testing.T) {
+	for _, v := range BuiltInViewDefinitions() {
+		view := v
+		if _, err := CompileViewSQL(&view); err != nil {
+			t.Errorf("built-in view %s does not compile: %v", view.ID, err)
+		}
+	}
+}
+
+func TestViewDefinition_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		view ViewDefinition
+		want string
+	}{
+		{"no resource", ViewDefinition{Select: []ViewColumn{{Path: "id", Name: "id"}}}, "resource"},
+		{"no columns", ViewDefinition{Resource: "Patient"}, "column"},
+		{"duplicate", ViewDefinition{Resource: "Patient", Select: []ViewColumn{
+			{Path: "id", Name: "id"}, {Column: []ViewColumn{{Path: "id", Name: "id"}}},
+		}}, "id"},
+		{"union mismatch", ViewDefinition{Resource: "Patient", Select: []ViewColumn{
+			{UnionAll: []ViewColumn{
+				{Column: []ViewColumn{{Path: "id", Name: "a"}}},
+				{Column: []ViewColumn{{Path: "id", Name: "b"}}},
+			}},
+		}}, "unionAll"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.view.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestViewDefinition_Execute_ForEach(t *testing.T) {
+	engine := newTestViewEngine()
+	patients := []map[string]interface{}{
+		vdPatient(),
+		{"resourceType": "Patient", "id": "pt-200"},
+	}
+	result, err := engine.Execute(context.Background(), vdNestedView(), patients)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	// pt-100 has one name with two given names; pt-200 has no name, which
+	// forEachOrNull keeps as a row of nulls.
+	if len(result.Rows) != 3 {
+		t.Fatalf("expected 2 rows, got %d: %v", len(result.Rows), result.Rows)
+	}
+	for i, given := range []string{"Jane", "Marie"} {
+		row := result.Rows[i]
+		if row[0] != "pt-100" || row[1] != "Doe" || row[2] != given {
+			t.Errorf("row %d: expected [pt-100 Doe %s], got %v", i, given, row)
+		}
+	}
+
+	if row := result.Rows[2]; row[0] != "pt-200" || row[1] != nil || row[2] != nil {
+		t.Errorf("expected a null row for pt-200, got %v", row)
+	}
+}
+
+func TestViewDefinition_Execute_UnionAll(t *testing.T) {
+	engine := newTestViewEngine()
+	view := &ViewDefinition{
+		ID:       "ids",
+		Name:     "ids",
+		Resource: "Patient",
+		Select: []ViewColumn{
+			{Path: "id", Name: "id"},
+			{UnionAll: []ViewColumn{
+				{ForEach: "name", Column: []ViewColumn{{Path: "family", Name: "label"}}},
+				{Column: []ViewColumn{{Path: "gender", Name: "label"}}},
+			}},
+		},
+	}
+	result, err := engine.Execute(context.Background(), view, []map[string]interface{}{vdPatient()})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	var labels []string
+	for _, row := range result.Rows {
+		labels = append(labels, formatCSVValue(row[1]))
+	}
+	if strings.Join(labels, ",") != "Doe,female" {
+		t.Errorf("expected labels Doe,female, got %v", labels)
+	}
+}
+
+func TestViewDefinition_GenerateSQL_Compiled(t *testing.T) {
+	engine := newTestViewEngine()
+	sql := engine.GenerateSQL(vdNestedView())
+	if !strings.HasPrefix(sql, "CREATE OR REPLACE VIEW patient_names AS\nSELECT ") {
+		t.Errorf("expected a CREATE VIEW statement, got:\n%s", sql)
+	}
+	if !strings.Contains(sql, "FROM resource_history h") {
+		t.Errorf("expected the view to read the resource store, got:\n%s", sql)
+	}
+}
+
+func TestParquetRows_OptionalTyped(t *testing.T) {
+	rows := newParquetRows(viewParquetColumns([]ViewColumn{
+		{Name: "n", Type: "integer"},
+		{Name: "ok", Type: "boolean"},
+		{Name: "s"},
+	}))
+	rows.add(int64(7), true, "a")
+	rows.add(nil, false, nil)
+	rows.add(float64(2), nil, "b")
+
+	// Definition levels: a 4-byte length, then one bit-packed group.
+	if got := rows.page(0); !bytes.Equal(got, []byte{2, 0, 0, 0, 3, 0x05, 7, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0}) {
+		t.Errorf("unexpected INT64 page % x", got)
+	}
+	if got := rows.page(1); !bytes.Equal(got, []byte{2, 0, 0, 0, 3, 0x03, 0x01}) {
+		t.Errorf("unexpected BOOLEAN page % x", got)
+	}
+	var buf bytes.Buffer
+	if err := rows.writeTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("PAR1")) || !bytes.HasSuffix(buf.Bytes(), []byte("PAR1")) {
+		t.Error("expected the Parquet magic at both ends of the file")
+	}
+}
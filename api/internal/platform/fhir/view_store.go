@@ -0,0 +1,356 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ehr/ehr/internal/platform/db"
+)
+
+// ErrViewNotMaterialized is returned for a view without a materialization.
+var ErrViewNotMaterialized = errors.New("view is not materialized")
+
+// Kinds of view materialization.
+const (
+	// ViewMaterializeTable stores the rows in a table, which is kept up to
+	// date row by row as the view's resources change.
+	ViewMaterializeTable = "table"
+	// ViewMaterializeView stores the rows in a materialized view, which is
+	// marked stale when the view's resources change and refreshed as a whole
+	// before it is next read.
+	ViewMaterializeView = "materialized-view"
+)
+
+// ViewMaterialization describes the stored rows of a ViewDefinition.
+type ViewMaterialization struct {
+	ViewID       string          `json:"viewId"`
+	Table        string          `json:"table"`
+	Kind         string          `json:"kind"`
+	ResourceType string          `json:"resourceType"`
+	Stale        bool            `json:"stale"`
+	RefreshedAt  *time.Time      `json:"refreshedAt,omitempty"`
+	Definition   *ViewDefinition `json:"-"`
+}
+
+// ViewStore runs ViewDefinitions against the resource store and keeps their
+// materializations.
+type ViewStore interface {
+	// Query calls fn with each row of the view, in column order, up to limit
+	// rows if limit is positive. The rows are read from the view's
+	// materialization if it has one, refreshed first if stale, and computed
+	// from the resource store otherwise.
+	Query(ctx context.Context, cv *CompiledView, limit int, fn func(row []interface{}) error) error
+	// Materialize stores the rows of the view as a table or materialized
+	// view, replacing any previous materialization.
+	Materialize(ctx context.Context, cv *CompiledView, kind string) (*ViewMaterialization, error)
+	// Refresh recomputes the rows of a materialized view.
+	Refresh(ctx context.Context, viewID string) (*ViewMaterialization, error)
+	// GetMaterialization returns ErrViewNotMaterialized if the view has no
+	// materialization.
+	GetMaterialization(ctx context.Context, viewID string) (*ViewMaterialization, error)
+	// DropMaterialization removes the view's materialization, if any.
+	DropMaterialization(ctx context.Context, viewID string) error
+}
+
+// viewTableName returns the name of the table holding the materialization
+// of a view, in the tenant's schema.
+func viewTableName(viewID string) string {
+	return "view_" + strings.ToLower(sanitizeSQLName(viewID))
+}
+
+// ViewRepository is the PostgreSQL ViewStore. Materializations live in the
+// tenant's schema, next to the resource_history they are computed from, and
+// are recorded in view_materialization.
+//
+// Register the repository with VersionTracker.AddListener to keep them up to
+// date: a table has the rows of the changed resource replaced within the
+// writer's transaction, and a materialized view is marked stale.
+type ViewRepository struct{}
+
+// NewViewRepository creates a new ViewRepository.
+func NewViewRepository() *ViewRepository {
+	return &ViewRepository{}
+}
+
+func (r *ViewRepository) conn(ctx context.Context) historyQuerier {
+	if tx := db.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	if c := db.ConnFromContext(ctx); c != nil {
+		return c
+	}
+	return nil
+}
+
+// inTx runs fn in a transaction: a savepoint of the context's transaction,
+// or a transaction on its connection.
+func (r *ViewRepository) inTx(ctx context.Context, fn func(q pgx.Tx) error) error {
+	var (
+		tx  pgx.Tx
+		err error
+	)
+	if outer := db.TxFromContext(ctx); outer != nil {
+		tx, err = outer.Begin(ctx)
+	} else if c := db.ConnFromContext(ctx); c != nil {
+		tx, err = c.Begin(ctx)
+	} else {
+		return fmt.Errorf("no database connection in context")
+	}
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Query implements ViewStore.
+func (r *ViewRepository) Query(ctx context.Context, cv *CompiledView, limit int, fn func(row []interface{}) error) error {
+	q := r.conn(ctx)
+	if q == nil {
+		return fmt.Errorf("no database connection in context")
+	}
+	sql := cv.SQL()
+	m, err := r.GetMaterialization(ctx, cv.View.ID)
+	switch {
+	case err == nil:
+		if m.Stale {
+			if m, err = r.Refresh(ctx, cv.View.ID); err != nil {
+				return err
+			}
+		}
+		cols := make([]string, len(cv.Columns))
+		for i, col := range cv.Columns {
+			cols[i] = sqlIdent(col.Name)
+		}
+		sql = fmt.Sprintf("SELECT %s FROM %s", strings.Join(cols, ", "), m.Table)
+	case !errors.Is(err, ErrViewNotMaterialized):
+		return err
+	}
+	if limit > 0 {
+		sql += fmt.Sprintf("\nLIMIT %d", limit)
+	}
+
+	rows, err := q.Query(ctx, sql)
+	if err != nil {
+		return fmt.Errorf("run view %s: %w", cv.View.ID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return fmt.Errorf("scan view %s row: %w", cv.View.ID, err)
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Materialize implements ViewStore.
+func (r *ViewRepository) Materialize(ctx context.Context, cv *CompiledView, kind string) (*ViewMaterialization, error) {
+	if kind == "" {
+		kind = ViewMaterializeTable
+	}
+	if kind != ViewMaterializeTable && kind != ViewMaterializeView {
+		return nil, fmt.Errorf("unknown materialization kind %q", kind)
+	}
+	def, err := json.Marshal(cv.View)
+	if err != nil {
+		return nil, fmt.Errorf("marshal view %s: %w", cv.View.ID, err)
+	}
+	table := viewTableName(cv.View.ID)
+	now := time.Now().UTC()
+	err = r.inTx(ctx, func(q pgx.Tx) error {
+		if err := r.drop(ctx, q, cv.View.ID); err != nil {
+			return err
+		}
+		create := "CREATE TABLE"
+		if kind == ViewMaterializeView {
+			create = "CREATE MATERIALIZED VIEW"
+		}
+		if _, err := q.Exec(ctx, fmt.Sprintf("%s %s AS\n%s", create, table, cv.query(true, false))); err != nil {
+			return fmt.Errorf("materialize view %s: %w", cv.View.ID, err)
+		}
+		if _, err := q.Exec(ctx, fmt.Sprintf(`CREATE INDEX %s ON %s ("_resource_id")`, table+"_resource_id", table)); err != nil {
+			return fmt.Errorf("index view %s: %w", cv.View.ID, err)
+		}
+		_, err := q.Exec(ctx, `INSERT INTO view_materialization (view_id, resource_type, table_name, kind, definition, stale, refreshed_at)
+			VALUES ($1, $2, $3, $4, $5, false, $6)`,
+			cv.View.ID, cv.View.Resource, table, kind, def, now)
+		if err != nil {
+			return fmt.Errorf("record materialization of view %s: %w", cv.View.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ViewMaterialization{
+		ViewID: cv.View.ID, Table: table, Kind: kind, ResourceType: cv.View.Resource,
+		RefreshedAt: &now, Definition: cv.View,
+	}, nil
+}
+
+// Refresh implements ViewStore.
+func (r *ViewRepository) Refresh(ctx context.Context, viewID string) (*ViewMaterialization, error) {
+	m, err := r.GetMaterialization(ctx, viewID)
+	if err != nil {
+		return nil, err
+	}
+	cv, err := CompileViewSQL(m.Definition)
+	if err != nil {
+		return nil, fmt.Errorf("compile view %s: %w", viewID, err)
+	}
+	now := time.Now().UTC()
+	err = r.inTx(ctx, func(q pgx.Tx) error {
+		if m.Kind == ViewMaterializeView {
+			if _, err := q.Exec(ctx, "REFRESH MATERIALIZED VIEW "+m.Table); err != nil {
+				return fmt.Errorf("refresh view %s: %w", viewID, err)
+			}
+		} else {
+			if _, err := q.Exec(ctx, "DELETE FROM "+m.Table); err != nil {
+				return fmt.Errorf("refresh view %s: %w", viewID, err)
+			}
+			if _, err := q.Exec(ctx, fmt.Sprintf("INSERT INTO %s\n%s", m.Table, cv.query(true, false))); err != nil {
+				return fmt.Errorf("refresh view %s: %w", viewID, err)
+			}
+		}
+		_, err := q.Exec(ctx, `UPDATE view_materialization SET stale = false, refreshed_at = $2 WHERE view_id = $1`, viewID, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.Stale = false
+	m.RefreshedAt = &now
+	return m, nil
+}
+
+// GetMaterialization implements ViewStore.
+func (r *ViewRepository) GetMaterialization(ctx context.Context, viewID string) (*ViewMaterialization, error) {
+	q := r.conn(ctx)
+	if q == nil {
+		return nil, fmt.Errorf("no database connection in context")
+	}
+	m := &ViewMaterialization{ViewID: viewID}
+	var def []byte
+	err := q.QueryRow(ctx, `SELECT resource_type, table_name, kind, definition, stale, refreshed_at
+		FROM view_materialization WHERE view_id = $1`, viewID).
+		Scan(&m.ResourceType, &m.Table, &m.Kind, &def, &m.Stale, &m.RefreshedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrViewNotMaterialized
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get materialization of view %s: %w", viewID, err)
+	}
+	if err := json.Unmarshal(def, &m.Definition); err != nil {
+		return nil, fmt.Errorf("decode view %s: %w", viewID, err)
+	}
+	return m, nil
+}
+
+// DropMaterialization implements ViewStore.
+func (r *ViewRepository) DropMaterialization(ctx context.Context, viewID string) error {
+	return r.inTx(ctx, func(q pgx.Tx) error {
+		return r.drop(ctx, q, viewID)
+	})
+}
+
+func (r *ViewRepository) drop(ctx context.Context, q historyQuerier, viewID string) error {
+	var table, kind string
+	err := q.QueryRow(ctx, `DELETE FROM view_materialization WHERE view_id = $1 RETURNING table_name, kind`, viewID).
+		Scan(&table, &kind)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("drop materialization of view %s: %w", viewID, err)
+	}
+	drop := "DROP TABLE IF EXISTS "
+	if kind == ViewMaterializeView {
+		drop = "DROP MATERIALIZED VIEW IF EXISTS "
+	}
+	if _, err := q.Exec(ctx, drop+table); err != nil {
+		return fmt.Errorf("drop materialization of view %s: %w", viewID, err)
+	}
+	return nil
+}
+
+// OnResourceEvent keeps the materializations of the event's resource type
+// up to date. Register the repository with VersionTracker.AddListener.
+func (r *ViewRepository) OnResourceEvent(ctx context.Context, event ResourceEvent) {
+	_ = r.ApplyResourceChange(ctx, event.ResourceType, event.ResourceID)
+}
+
+// ApplyResourceChange brings the materializations of resourceType up to
+// date with the latest version of a resource. The rows of tables are
+// replaced; a table whose rows cannot be recomputed, like a materialized
+// view, is marked stale and refreshed before it is next read. Failures
+// never affect the caller's transaction.
+func (r *ViewRepository) ApplyResourceChange(ctx context.Context, resourceType, resourceID string) error {
+	return r.inTx(ctx, func(q pgx.Tx) error {
+		rows, err := q.Query(ctx, `SELECT view_id, table_name, kind, definition
+			FROM view_materialization WHERE resource_type = $1 AND NOT stale`, resourceType)
+		if err != nil {
+			return err
+		}
+		var views []ViewMaterialization
+		for rows.Next() {
+			var m ViewMaterialization
+			var def []byte
+			if err := rows.Scan(&m.ViewID, &m.Table, &m.Kind, &def); err != nil {
+				rows.Close()
+				return err
+			}
+			if json.Unmarshal(def, &m.Definition) == nil {
+				views = append(views, m)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, m := range views {
+			if m.Kind == ViewMaterializeTable && r.replaceRows(ctx, q, m, resourceID) == nil {
+				continue
+			}
+			if _, err := q.Exec(ctx, `UPDATE view_materialization SET stale = true WHERE view_id = $1`, m.ViewID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// replaceRows replaces the rows of a resource in a materialized table, in a
+// savepoint of tx.
+func (r *ViewRepository) replaceRows(ctx context.Context, tx pgx.Tx, m ViewMaterialization, resourceID string) error {
+	cv, err := CompileViewSQL(m.Definition)
+	if err != nil {
+		return err
+	}
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := sp.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE "_resource_id" = $1`, m.Table), resourceID); err != nil {
+		_ = sp.Rollback(ctx)
+		return err
+	}
+	if _, err := sp.Exec(ctx, fmt.Sprintf("INSERT INTO %s\n%s", m.Table, cv.query(true, true)), resourceID); err != nil {
+		_ = sp.Rollback(ctx)
+		return err
+	}
+	return sp.Commit(ctx)
+}
@@ -0,0 +1,891 @@
+package fhir
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// FHIRXMLContentType is the FHIR XML content type with charset.
+const FHIRXMLContentType = "application/fhir+xml; charset=utf-8"
+
+const (
+	fhirXMLNamespace  = "http://hl7.org/fhir"
+	xhtmlXMLNamespace = "http://www.w3.org/1999/xhtml"
+)
+
+// resourceElementOrder is the order of the elements every resource inherits
+// from Resource and DomainResource.
+var resourceElementOrder = []string{
+	"id", "meta", "implicitRules", "language", "text", "contained", "extension", "modifierExtension",
+}
+
+// xmlRepeatingElements lists element names that repeat wherever they occur.
+// It is consulted only when no definition describes the element, so that a
+// single occurrence in XML still becomes a JSON array.
+var xmlRepeatingElements = map[string]bool{
+	"extension": true, "modifierExtension": true, "contained": true, "identifier": true,
+	"coding": true, "telecom": true, "note": true, "basedOn": true, "partOf": true,
+	"reasonCode": true, "reasonReference": true, "instantiatesCanonical": true,
+	"instantiatesUri": true, "supportingInfo": true, "insurance": true, "payor": true,
+	"contact": true, "interpretation": true, "referenceRange": true, "component": true,
+	"hasMember": true, "derivedFrom": true, "dosageInstruction": true, "item": true,
+	"entry": true, "participant": true, "diagnosis": true, "useContext": true,
+	"jurisdiction": true, "link": true, "issue": true, "parameter": true, "part": true,
+	"given": true, "prefix": true, "suffix": true, "line": true, "profile": true,
+	"tag": true, "security": true, "category": true, "performer": true, "member": true,
+}
+
+// xmlNumericElements lists element names whose primitive values are numbers.
+// Like xmlRepeatingElements it only applies when no definition is available.
+var xmlNumericElements = map[string]bool{
+	"latitude": true, "longitude": true, "altitude": true, "factor": true, "rank": true,
+	"sequence": true, "size": true, "count": true, "countMax": true, "frequency": true,
+	"frequencyMax": true, "duration": true, "durationMax": true, "periodMax": true,
+	"offset": true, "total": true, "score": true, "numberOfSeries": true,
+	"numberOfInstances": true, "minutesDuration": true, "dimensions": true, "lowerLimit": true, "upperLimit": true,
+}
+
+// XMLCodec converts FHIR resources between their JSON object form and the
+// FHIR XML wire format. Element order, cardinality and primitive types come
+// from the StructureDefinitions in the store; elements without a definition,
+// such as extensions' content, are handled with conservative heuristics so
+// unknown content still round-trips. Resource types without a structural
+// layout are refused with ErrNoXMLLayout.
+type XMLCodec struct {
+	store *StructureDefinitionStore
+
+	mu       sync.RWMutex
+	children map[string][]ElementDefinition
+}
+
+// ErrNoXMLLayout is returned by XMLCodec for a resource type whose element
+// order is not known (see HasStructuralLayout). Its XML could not be written
+// in schema order, so it is refused rather than approximated.
+var ErrNoXMLLayout = errors.New("no XML element layout")
+
+// NewXMLCodec creates a codec backed by the given StructureDefinition store.
+func NewXMLCodec(store *StructureDefinitionStore) *XMLCodec {
+	return &XMLCodec{store: store, children: make(map[string][]ElementDefinition)}
+}
+
+var (
+	defaultXMLCodec     *XMLCodec
+	defaultXMLCodecOnce sync.Once
+)
+
+// DefaultXMLCodec returns a shared codec backed by the base definitions.
+func DefaultXMLCodec() *XMLCodec {
+	defaultXMLCodecOnce.Do(func() {
+		store := NewStructureDefinitionStore()
+		RegisterBaseDefinitions(store)
+		defaultXMLCodec = NewXMLCodec(store)
+	})
+	return defaultXMLCodec
+}
+
+// xmlContext identifies the definition describing an element's children:
+// the StructureDefinition and the element path within it. The zero value
+// means the structure is unknown.
+type xmlContext struct {
+	sd   string
+	path string
+}
+
+func (ctx xmlContext) isExtension() bool {
+	return ctx.sd == "Extension" && ctx.path == "Extension"
+}
+
+// childDefinitions returns the direct children of the element at ctx.
+func (x *XMLCodec) childDefinitions(ctx xmlContext) []ElementDefinition {
+	if ctx.sd == "" {
+		return nil
+	}
+	key := ctx.sd + "|" + ctx.path
+	x.mu.RLock()
+	kids, ok := x.children[key]
+	x.mu.RUnlock()
+	if ok {
+		return kids
+	}
+
+	if sd := x.store.Get(ctx.sd); sd != nil {
+		if sd = GenerateSnapshot(x.store, sd); sd.Snapshot != nil {
+			prefix := ctx.path + "."
+			for _, e := range sd.Snapshot.Element {
+				if strings.HasPrefix(e.Path, prefix) && !strings.Contains(e.Path[len(prefix):], ".") {
+					kids = append(kids, e)
+				}
+			}
+		}
+	}
+	x.mu.Lock()
+	x.children[key] = kids
+	x.mu.Unlock()
+	return kids
+}
+
+// hasDefinition reports whether the store describes the named type.
+func (x *XMLCodec) hasDefinition(typeName string) bool {
+	return typeName != "" && x.store.Get(typeName) != nil
+}
+
+// lookup finds the definition of the child element name at ctx, resolving
+// choice elements by their type suffix. It returns the element definition
+// (nil when unknown) and the element's type code ("" when undetermined).
+func (x *XMLCodec) lookup(ctx xmlContext, name string) (*ElementDefinition, string) {
+	kids := x.childDefinitions(ctx)
+	for i := range kids {
+		d := &kids[i]
+		elem := d.Path[len(ctx.path)+1:]
+		if elem == name {
+			if len(d.Type) == 1 {
+				return d, d.Type[0].Code
+			}
+			return d, ""
+		}
+		if base := strings.TrimSuffix(elem, "[x]"); base != elem && strings.HasPrefix(name, base) {
+			suffix := name[len(base):]
+			for _, t := range d.Type {
+				if upperFirst(t.Code) == suffix {
+					return d, t.Code
+				}
+			}
+		}
+	}
+	// Undefined choice elements still announce their type in the suffix.
+	if base := choiceBase(name); base != "" {
+		suffix := name[len(base):]
+		if x.hasDefinition(suffix) {
+			return nil, suffix
+		}
+		return nil, lowerFirst(suffix)
+	}
+	return nil, ""
+}
+
+// childContext returns the context describing the children of a complex
+// element. childNames are the names of the element's own children, used to
+// recognise common datatypes when no definition applies.
+func (x *XMLCodec) childContext(ctx xmlContext, name string, def *ElementDefinition, typeCode string, childNames []string) xmlContext {
+	if name == "extension" || name == "modifierExtension" {
+		return xmlContext{sd: "Extension", path: "Extension"}
+	}
+	if def != nil && def.ContentReference != "" {
+		path := strings.TrimPrefix(def.ContentReference, "#")
+		return xmlContext{sd: strings.SplitN(path, ".", 2)[0], path: path}
+	}
+	if def != nil && (typeCode == "BackboneElement" || typeCode == "Element") {
+		return xmlContext{sd: ctx.sd, path: def.Path}
+	}
+	if def == nil && !x.hasDefinition(typeCode) {
+		typeCode = guessComplexType(name, childNames)
+	}
+	if x.hasDefinition(typeCode) {
+		return xmlContext{sd: typeCode, path: typeCode}
+	}
+	return xmlContext{}
+}
+
+// guessComplexType recognises the common datatypes from an element's name
+// and the names of its children. It returns "" when nothing matches.
+func guessComplexType(name string, childNames []string) string {
+	has := make(map[string]bool, len(childNames))
+	for _, n := range childNames {
+		has[n] = true
+	}
+	switch {
+	case name == "meta":
+		return "Meta"
+	case name == "coding":
+		return "Coding"
+	case has["coding"]:
+		return "CodeableConcept"
+	case name == "identifier":
+		return "Identifier"
+	case name == "telecom":
+		return "ContactPoint"
+	case name == "address":
+		return "Address"
+	case name == "note":
+		return "Annotation"
+	case name == "period":
+		return "Period"
+	case name == "text" && has["div"]:
+		return "Narrative"
+	case has["reference"]:
+		return "Reference"
+	case has["family"] || has["given"]:
+		return "HumanName"
+	case has["line"] || has["city"] || has["postalCode"] || has["country"]:
+		return "Address"
+	case has["numerator"] || has["denominator"]:
+		return "Ratio"
+	case has["low"] || has["high"]:
+		return "Range"
+	case has["value"] && has["currency"]:
+		return "Money"
+	case has["value"] && (has["unit"] || has["comparator"] || (has["system"] && has["code"])):
+		return "Quantity"
+	case has["contentType"]:
+		return "Attachment"
+	case (has["start"] || has["end"]) && len(has) <= 2:
+		return "Period"
+	}
+	return ""
+}
+
+// isPrimitiveTypeCode reports whether a type code names a FHIR primitive.
+func isPrimitiveTypeCode(code string) bool {
+	return code != "" && unicode.IsLower(rune(code[0]))
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// ============================================================================
+// Serialization
+// ============================================================================
+
+// Marshal renders a resource, as decoded from FHIR JSON, in FHIR XML.
+// Numbers may be float64 or json.Number; the latter preserves the exact
+// decimal representation.
+func (x *XMLCodec) Marshal(resource map[string]interface{}) ([]byte, error) {
+	enc := &xmlEncoder{codec: x}
+	enc.buf.WriteString(xml.Header)
+	if err := enc.resource(resource, true); err != nil {
+		return nil, err
+	}
+	return enc.buf.Bytes(), nil
+}
+
+// JSONToXML converts a FHIR JSON document to FHIR XML.
+func (x *XMLCodec) JSONToXML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var resource map[string]interface{}
+	if err := dec.Decode(&resource); err != nil {
+		return nil, err
+	}
+	return x.Marshal(resource)
+}
+
+type xmlEncoder struct {
+	codec *XMLCodec
+	buf   bytes.Buffer
+}
+
+func (e *xmlEncoder) resource(m map[string]interface{}, root bool) error {
+	rt, _ := m["resourceType"].(string)
+	if rt == "" || !isXMLName(rt) {
+		return fmt.Errorf("resource has no valid resourceType")
+	}
+	if !HasStructuralLayout(rt) {
+		return fmt.Errorf("%w for resource type %s", ErrNoXMLLayout, rt)
+	}
+	e.buf.WriteString("<" + rt)
+	if root {
+		e.buf.WriteString(` xmlns="` + fhirXMLNamespace + `"`)
+	}
+	e.buf.WriteString(">")
+	if err := e.children(xmlContext{sd: rt, path: rt}, m, true); err != nil {
+		return err
+	}
+	e.buf.WriteString("</" + rt + ">")
+	return nil
+}
+
+// children writes the child elements of m in canonical order.
+func (e *xmlEncoder) children(ctx xmlContext, m map[string]interface{}, isResource bool) error {
+	for _, name := range e.codec.orderedNames(ctx, m, isResource) {
+		value, hasValue := m[name]
+		if err := e.element(ctx, name, value, hasValue, m["_"+name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderedNames returns the names of the child elements of m in the order
+// the XML format requires: inherited elements first, then the definition's
+// element order, then any undefined elements alphabetically. Properties
+// rendered as XML attributes are excluded, and "_name" companions are folded
+// into their element.
+func (x *XMLCodec) orderedNames(ctx xmlContext, m map[string]interface{}, isResource bool) []string {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		switch {
+		case k == "resourceType":
+		case !isResource && k == "id":
+		case ctx.isExtension() && k == "url":
+		case strings.HasPrefix(k, "_"):
+			if _, ok := m[k[1:]]; !ok && k != "_" {
+				names = append(names, k[1:])
+			}
+		default:
+			names = append(names, k)
+		}
+	}
+
+	var kids []ElementDefinition
+	if ctx.sd == "" {
+		// Unknown structure: order by the recognised datatype, if any.
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		if t := guessComplexType("", keys); t != "" {
+			kids = x.childDefinitions(xmlContext{sd: t, path: t})
+			ctx = xmlContext{sd: t, path: t}
+		}
+	} else {
+		kids = x.childDefinitions(ctx)
+	}
+
+	rank := func(name string) int {
+		if isResource {
+			for i, n := range resourceElementOrder {
+				if n == name {
+					return i
+				}
+			}
+		} else if name == "extension" {
+			return 0
+		} else if name == "modifierExtension" {
+			return 1
+		}
+		for i, d := range kids {
+			elem := d.Path[len(ctx.path)+1:]
+			if elem == name {
+				return 100 + i
+			}
+			if base := strings.TrimSuffix(elem, "[x]"); base != elem && strings.HasPrefix(name, base) {
+				return 100 + i
+			}
+		}
+		return 1 << 20
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		ri, rj := rank(names[i]), rank(names[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// element writes one JSON property (possibly repeating) as XML elements.
+// ext is the property's "_name" companion, if any.
+func (e *xmlEncoder) element(ctx xmlContext, name string, value interface{}, hasValue bool, ext interface{}) error {
+	if !isXMLName(name) {
+		return fmt.Errorf("invalid element name %q", name)
+	}
+	def, typeCode := e.codec.lookup(ctx, name)
+
+	items, isArray := value.([]interface{})
+	if !hasValue {
+		items, isArray = ext.([]interface{})
+		if isArray {
+			items = make([]interface{}, len(items))
+		}
+	}
+	if !isArray {
+		return e.single(ctx, name, def, typeCode, value, ext)
+	}
+	exts, _ := ext.([]interface{})
+	for i, item := range items {
+		var itemExt interface{}
+		if i < len(exts) {
+			itemExt = exts[i]
+		}
+		if err := e.single(ctx, name, def, typeCode, item, itemExt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *xmlEncoder) single(ctx xmlContext, name string, def *ElementDefinition, typeCode string, value, ext interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if rt, _ := v["resourceType"].(string); rt != "" && (typeCode == "Resource" || typeCode == "") {
+			e.buf.WriteString("<" + name + ">")
+			if err := e.resource(v, false); err != nil {
+				return err
+			}
+			e.buf.WriteString("</" + name + ">")
+			return nil
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		child := e.codec.childContext(ctx, name, def, typeCode, keys)
+		e.buf.WriteString("<" + name)
+		if id, ok := v["id"].(string); ok {
+			e.attr("id", id)
+		}
+		if url, ok := v["url"].(string); ok && child.isExtension() {
+			e.attr("url", url)
+		}
+		if len(e.codec.orderedNames(child, v, false)) == 0 {
+			e.buf.WriteString("/>")
+			return nil
+		}
+		e.buf.WriteString(">")
+		if err := e.children(child, v, false); err != nil {
+			return err
+		}
+		e.buf.WriteString("</" + name + ">")
+		return nil
+	case []interface{}:
+		return fmt.Errorf("element %s: nested arrays are not valid FHIR", name)
+	case string:
+		if name == "div" && (typeCode == "xhtml" || ctx.sd == "Narrative" || ctx.sd == "") && strings.HasPrefix(strings.TrimSpace(v), "<") {
+			e.xhtml(v)
+			return nil
+		}
+	}
+	return e.primitive(name, value, ext)
+}
+
+// primitive writes a primitive element: the value attribute, plus the id and
+// extensions carried by its "_name" companion.
+func (e *xmlEncoder) primitive(name string, value, ext interface{}) error {
+	extMap, _ := ext.(map[string]interface{})
+	e.buf.WriteString("<" + name)
+	if id, ok := extMap["id"].(string); ok {
+		e.attr("id", id)
+	}
+	if value != nil {
+		e.attr("value", formatXMLPrimitive(value))
+	}
+	extensions, _ := extMap["extension"].([]interface{})
+	if len(extensions) == 0 {
+		e.buf.WriteString("/>")
+		return nil
+	}
+	e.buf.WriteString(">")
+	if err := e.element(xmlContext{}, "extension", extensions, true, nil); err != nil {
+		return err
+	}
+	e.buf.WriteString("</" + name + ">")
+	return nil
+}
+
+func (e *xmlEncoder) attr(name, value string) {
+	e.buf.WriteString(" " + name + `="`)
+	_ = xml.EscapeText(&e.buf, []byte(value))
+	e.buf.WriteString(`"`)
+}
+
+// xhtml writes narrative XHTML verbatim. A div without the XHTML namespace
+// gets one; content that is not well-formed is escaped into an empty div so
+// the document stays valid.
+func (e *xmlEncoder) xhtml(div string) {
+	div = strings.TrimSpace(div)
+	if strings.HasPrefix(div, "<div") && !strings.Contains(div[:strings.Index(div, ">")+1], "xmlns") {
+		div = `<div xmlns="` + xhtmlXMLNamespace + `"` + div[len("<div"):]
+	}
+	if !isWellFormedXML(div) {
+		e.buf.WriteString(`<div xmlns="` + xhtmlXMLNamespace + `">`)
+		_ = xml.EscapeText(&e.buf, []byte(div))
+		e.buf.WriteString("</div>")
+		return
+	}
+	e.buf.WriteString(div)
+}
+
+func isWellFormedXML(s string) bool {
+	if !strings.HasPrefix(s, "<div") || !strings.Contains(s, ">") {
+		return false
+	}
+	dec := xml.NewDecoder(strings.NewReader(s))
+	for {
+		if _, err := dec.Token(); err != nil {
+			return err == io.EOF
+		}
+	}
+}
+
+// formatXMLPrimitive renders a JSON primitive as an XML attribute value.
+func formatXMLPrimitive(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case json.Number:
+		return val.String()
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func isXMLName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !(unicode.IsLetter(r) || r == '_' || (i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'))) {
+			return false
+		}
+	}
+	return true
+}
+
+// ============================================================================
+// Parsing
+// ============================================================================
+
+// xmlNode is a parsed FHIR XML element. Narrative XHTML is kept verbatim.
+type xmlNode struct {
+	name     string
+	attrs    map[string]string
+	children []*xmlNode
+	xhtml    string
+}
+
+// Unmarshal parses a FHIR XML document into the resource's JSON object form.
+func (x *XMLCodec) Unmarshal(data []byte) (map[string]interface{}, error) {
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return nil, err
+	}
+	if !isResourceElement(root.name) {
+		return nil, fmt.Errorf("root element %q is not a FHIR resource", root.name)
+	}
+	return x.object(root, xmlContext{sd: root.name, path: root.name}, true)
+}
+
+// XMLToJSON converts a FHIR XML document to FHIR JSON.
+func (x *XMLCodec) XMLToJSON(data []byte) ([]byte, error) {
+	resource, err := x.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(resource)
+}
+
+// isResourceElement reports whether an element name is a resource type.
+// FHIR element names are lowerCamelCase, so a leading capital identifies a
+// resource wrapped by contained, Bundle.entry.resource and the like.
+func isResourceElement(name string) bool {
+	return name != "" && unicode.IsUpper(rune(name[0]))
+}
+
+func parseXMLTree(data []byte) (*xmlNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var n *xmlNode
+			if t.Name.Space == xhtmlXMLNamespace && t.Name.Local == "div" {
+				div, err := captureXHTML(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				n = &xmlNode{name: "div", xhtml: div}
+			} else {
+				if t.Name.Space != fhirXMLNamespace && t.Name.Space != "" {
+					return nil, fmt.Errorf("element %s is not in the FHIR namespace", t.Name.Local)
+				}
+				n = &xmlNode{name: t.Name.Local, attrs: make(map[string]string)}
+				for _, a := range t.Attr {
+					if a.Name.Space == "" && a.Name.Local != "xmlns" {
+						n.attrs[a.Name.Local] = a.Value
+					}
+				}
+			}
+			switch {
+			case len(stack) > 0:
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			case root == nil:
+				root = n
+			default:
+				return nil, fmt.Errorf("document has more than one root element")
+			}
+			if n.xhtml == "" {
+				stack = append(stack, n)
+			}
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("document has no root element")
+	}
+	return root, nil
+}
+
+// captureXHTML re-serializes an XHTML div (whose start tag has just been
+// read) with the namespace declared once on the div itself.
+func captureXHTML(dec *xml.Decoder, start xml.StartElement) (string, error) {
+	var b bytes.Buffer
+	open := false // a start tag has been written without its closing '>'
+	writeStart := func(t xml.StartElement, root bool) {
+		b.WriteString("<" + t.Name.Local)
+		if root {
+			b.WriteString(` xmlns="` + xhtmlXMLNamespace + `"`)
+		}
+		for _, a := range t.Attr {
+			if a.Name.Local == "xmlns" || a.Name.Space == "xmlns" {
+				continue
+			}
+			name := a.Name.Local
+			if a.Name.Space == "http://www.w3.org/XML/1998/namespace" {
+				name = "xml:" + name
+			}
+			b.WriteString(" " + name + `="`)
+			_ = xml.EscapeText(&b, []byte(a.Value))
+			b.WriteString(`"`)
+		}
+		open = true
+	}
+	closeOpen := func() {
+		if open {
+			b.WriteString(">")
+			open = false
+		}
+	}
+
+	writeStart(start, true)
+	depth := 1
+	for depth > 0 {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", fmt.Errorf("narrative: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			closeOpen()
+			writeStart(t, false)
+			depth++
+		case xml.EndElement:
+			if open {
+				b.WriteString("/>")
+				open = false
+			} else {
+				b.WriteString("</" + t.Name.Local + ">")
+			}
+			depth--
+		case xml.CharData:
+			closeOpen()
+			_ = xml.EscapeText(&b, t)
+		}
+	}
+	return b.String(), nil
+}
+
+// object converts an XML element into a JSON object.
+func (x *XMLCodec) object(n *xmlNode, ctx xmlContext, isResource bool) (map[string]interface{}, error) {
+	out := make(map[string]interface{})
+	if isResource {
+		if !HasStructuralLayout(n.name) {
+			return nil, fmt.Errorf("%w for resource type %s", ErrNoXMLLayout, n.name)
+		}
+		out["resourceType"] = n.name
+	} else {
+		if id, ok := n.attrs["id"]; ok {
+			out["id"] = id
+		}
+		if url, ok := n.attrs["url"]; ok {
+			out["url"] = url
+		}
+	}
+
+	// Group the children by name, keeping document order.
+	var order []string
+	groups := make(map[string][]*xmlNode)
+	for _, ch := range n.children {
+		if _, seen := groups[ch.name]; !seen {
+			order = append(order, ch.name)
+		}
+		groups[ch.name] = append(groups[ch.name], ch)
+	}
+
+	for _, name := range order {
+		nodes := groups[name]
+		def, typeCode := x.lookup(ctx, name)
+		repeating := len(nodes) > 1 || xmlRepeatingElements[name]
+		if def != nil {
+			repeating = def.Max != "1" && def.Max != "0"
+			if !repeating && len(nodes) > 1 {
+				return nil, fmt.Errorf("element %s.%s occurs %d times but allows at most one", ctx.path, name, len(nodes))
+			}
+		}
+
+		values := make([]interface{}, len(nodes))
+		exts := make([]interface{}, len(nodes))
+		hasValue, hasExt := false, false
+		for i, node := range nodes {
+			v, ext, err := x.nodeValue(ctx, name, def, typeCode, node)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				values[i] = v
+				hasValue = true
+			}
+			if ext != nil {
+				exts[i] = ext
+				hasExt = true
+			}
+		}
+		if repeating {
+			if hasValue {
+				out[name] = values
+			}
+			if hasExt {
+				out["_"+name] = exts
+			}
+		} else {
+			if hasValue {
+				out[name] = values[0]
+			}
+			if hasExt {
+				out["_"+name] = exts[0]
+			}
+		}
+	}
+	return out, nil
+}
+
+// nodeValue converts one XML element to its JSON value. For primitives the
+// second result is the "_name" companion holding the element's id and
+// extensions, or nil.
+func (x *XMLCodec) nodeValue(ctx xmlContext, name string, def *ElementDefinition, typeCode string, n *xmlNode) (interface{}, map[string]interface{}, error) {
+	if n.xhtml != "" {
+		return n.xhtml, nil, nil
+	}
+
+	// Wrapped resource: contained, Bundle.entry.resource, Parameters.parameter.resource.
+	if len(n.children) == 1 && isResourceElement(n.children[0].name) && (typeCode == "Resource" || typeCode == "") {
+		inner := n.children[0]
+		m, err := x.object(inner, xmlContext{sd: inner.name, path: inner.name}, true)
+		return m, nil, err
+	}
+
+	// A type guessed from a choice-like suffix ("vaccineCode") is only
+	// trusted for primitives when the element looks like one.
+	raw, hasValue := n.attrs["value"]
+	if hasValue || (isPrimitiveTypeCode(typeCode) && (def != nil || onlyExtensions(n))) {
+		var value interface{}
+		if hasValue {
+			primitiveType := typeCode
+			if !isPrimitiveTypeCode(primitiveType) {
+				primitiveType = ""
+			}
+			v, err := parseXMLPrimitive(raw, primitiveType, name)
+			if err != nil {
+				return nil, nil, fmt.Errorf("element %s.%s: %w", ctx.path, name, err)
+			}
+			value = v
+		}
+		ext := make(map[string]interface{})
+		if id, ok := n.attrs["id"]; ok {
+			ext["id"] = id
+		}
+		var extensions []interface{}
+		for _, ch := range n.children {
+			if ch.name != "extension" {
+				return nil, nil, fmt.Errorf("primitive element %s.%s has unexpected child %s", ctx.path, name, ch.name)
+			}
+			m, err := x.object(ch, xmlContext{sd: "Extension", path: "Extension"}, false)
+			if err != nil {
+				return nil, nil, err
+			}
+			extensions = append(extensions, m)
+		}
+		if len(extensions) > 0 {
+			ext["extension"] = extensions
+		}
+		if len(ext) == 0 {
+			ext = nil
+		}
+		return value, ext, nil
+	}
+
+	names := make([]string, len(n.children))
+	for i, ch := range n.children {
+		names[i] = ch.name
+	}
+	m, err := x.object(n, x.childContext(ctx, name, def, typeCode, names), false)
+	if err != nil || len(m) == 0 {
+		return nil, nil, err
+	}
+	return m, nil, nil
+}
+
+func onlyExtensions(n *xmlNode) bool {
+	for _, ch := range n.children {
+		if ch.name != "extension" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseXMLPrimitive converts a value attribute to its JSON type.
+func parseXMLPrimitive(raw, typeCode, name string) (interface{}, error) {
+	switch typeCode {
+	case "boolean":
+		switch raw {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean %q", raw)
+	case "integer", "positiveInt", "unsignedInt", "decimal":
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", typeCode, raw)
+		}
+		return f, nil
+	case "":
+		if raw == "true" || raw == "false" {
+			return raw == "true", nil
+		}
+		if xmlNumericElements[name] {
+			if f, err := strconv.ParseFloat(raw, 64); err == nil {
+				return f, nil
+			}
+		}
+	}
+	return raw, nil
+}
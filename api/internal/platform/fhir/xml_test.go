@@ -0,0 +1,247 @@
+package fhir
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// xmlRoundTrip converts FHIR JSON to XML and back, failing the test on error.
+func xmlRoundTrip(t *testing.T, src string) (string, map[string]interface{}) {
+	t.Helper()
+	codec := DefaultXMLCodec()
+	out, err := codec.JSONToXML([]byte(src))
+	if err != nil {
+		t.Fatalf("JSONToXML: %v", err)
+	}
+	back, err := codec.Unmarshal(out)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, out)
+	}
+	return string(out), back
+}
+
+func assertSameJSON(t *testing.T, want string, got map[string]interface{}) {
+	t.Helper()
+	var w map[string]interface{}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("bad expected JSON: %v", err)
+	}
+	data, _ := json.Marshal(got)
+	var g map[string]interface{}
+	_ = json.Unmarshal(data, &g)
+	if !reflect.DeepEqual(w, g) {
+		t.Errorf("round trip mismatch\nwant: %s\ngot:  %s", want, data)
+	}
+}
+
+func TestXMLCodec_MarshalElementOrder(t *testing.T) {
+	src := `{"resourceType":"Patient","gender":"female","name":[{"given":["Ann"],"family":"Lee"}],
+		"extension":[{"valueString":"x","url":"http://example.org/ext"}],"id":"p1",
+		"meta":{"versionId":"2"},"birthDate":"1970-01-01","active":true}`
+	out, back := xmlRoundTrip(t, src)
+
+	want := `<Patient xmlns="http://hl7.org/fhir"><id value="p1"/><meta><versionId value="2"/></meta>` +
+		`<extension url="http://example.org/ext"><valueString value="x"/></extension>` +
+		`<active value="true"/><name><family value="Lee"/><given value="Ann"/></name>` +
+		`<gender value="female"/><birthDate value="1970-01-01"/></Patient>`
+	if !strings.Contains(out, want) {
+		t.Errorf("unexpected XML\nwant: %s\ngot:  %s", want, out)
+	}
+	if !strings.HasPrefix(out, "<?xml") {
+		t.Errorf("expected XML declaration, got: %s", out)
+	}
+	assertSameJSON(t, src, back)
+}
+
+func TestXMLCodec_PrimitiveIDAndExtensions(t *testing.T) {
+	src := `{"resourceType":"Patient","birthDate":"1970-03-30",
+		"_birthDate":{"id":"bd","extension":[{"url":"http://hl7.org/fhir/StructureDefinition/patient-birthTime","valueDateTime":"1970-03-30T14:35:45-05:00"}]},
+		"name":[{"given":["Jim",null],"_given":[null,{"extension":[{"url":"http://example.org/nick","valueBoolean":true}]}]}]}`
+	out, back := xmlRoundTrip(t, src)
+
+	if !strings.Contains(out, `<birthDate id="bd" value="1970-03-30"><extension url="http://hl7.org/fhir/StructureDefinition/patient-birthTime">`) {
+		t.Errorf("expected birthDate with id and extension, got: %s", out)
+	}
+	if !strings.Contains(out, `<given value="Jim"/><given><extension url="http://example.org/nick"><valueBoolean value="true"/></extension></given>`) {
+		t.Errorf("expected value-less given with extension, got: %s", out)
+	}
+	assertSameJSON(t, src, back)
+}
+
+func TestXMLCodec_Narrative(t *testing.T) {
+	src := `{"resourceType":"Patient","id":"p1","text":{"status":"generated",
+		"div":"<div xmlns=\"http://www.w3.org/1999/xhtml\"><p class=\"name\">Ann &amp; Bob<br/>Lee</p></div>"}}`
+	out, back := xmlRoundTrip(t, src)
+
+	if !strings.Contains(out, `<text><status value="generated"/><div xmlns="http://www.w3.org/1999/xhtml"><p class="name">Ann &amp; Bob<br/>Lee</p></div></text>`) {
+		t.Errorf("expected verbatim XHTML narrative, got: %s", out)
+	}
+	assertSameJSON(t, src, back)
+}
+
+func TestXMLCodec_NarrativeWithoutNamespace(t *testing.T) {
+	out, back := xmlRoundTrip(t, `{"resourceType":"Patient","text":{"status":"generated","div":"<div>Hi</div>"}}`)
+	if !strings.Contains(out, `<div xmlns="http://www.w3.org/1999/xhtml">Hi</div>`) {
+		t.Errorf("expected XHTML namespace to be added, got: %s", out)
+	}
+	text, _ := back["text"].(map[string]interface{})
+	if text["div"] != `<div xmlns="http://www.w3.org/1999/xhtml">Hi</div>` {
+		t.Errorf("unexpected div after round trip: %v", text["div"])
+	}
+}
+
+func TestXMLCodec_BundleWithContainedResources(t *testing.T) {
+	src := `{"resourceType":"Bundle","type":"searchset","total":1,
+		"link":[{"relation":"self","url":"http://example.org/fhir/Observation"}],
+		"entry":[{"fullUrl":"http://example.org/fhir/Observation/o1",
+			"resource":{"resourceType":"Observation","id":"o1","status":"final",
+				"contained":[{"resourceType":"Patient","id":"pat","active":false}],
+				"code":{"coding":[{"system":"http://loinc.org","code":"8867-4"}]},
+				"subject":{"reference":"#pat"},
+				"valueQuantity":{"value":72.5,"unit":"beats/min"},
+				"component":[{"code":{"text":"x"},"valueInteger":3}]},
+			"search":{"mode":"match","score":1}}]}`
+	out, back := xmlRoundTrip(t, src)
+
+	if !strings.Contains(out, `<contained><Patient><id value="pat"/><active value="false"/></Patient></contained>`) {
+		t.Errorf("expected wrapped contained resource, got: %s", out)
+	}
+	if !strings.Contains(out, `<resource><Observation><id value="o1"/>`) {
+		t.Errorf("expected wrapped entry resource, got: %s", out)
+	}
+	if !strings.Contains(out, `<valueQuantity><value value="72.5"/><unit value="beats/min"/></valueQuantity>`) {
+		t.Errorf("expected Quantity in element order, got: %s", out)
+	}
+	assertSameJSON(t, src, back)
+}
+
+func TestXMLCodec_ParametersWithParts(t *testing.T) {
+	src := `{"resourceType":"Parameters","parameter":[{"name":"outer","part":[{"name":"inner","valueDecimal":1.25},
+		{"name":"res","resource":{"resourceType":"Patient","id":"p"}}]}]}`
+	_, back := xmlRoundTrip(t, src)
+	assertSameJSON(t, src, back)
+}
+
+func TestXMLCodec_UnmarshalTypes(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<Observation xmlns="http://hl7.org/fhir">
+  <!-- comments are ignored -->
+  <status value="final"/>
+  <category><coding><code value="vital-signs"/></coding></category>
+  <code><text value="Heart rate"/></code>
+  <valueInteger value="72"/>
+  <interpretation><text value="normal"/></interpretation>
+</Observation>`
+	obs, err := DefaultXMLCodec().Unmarshal([]byte(doc))
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if obs["valueInteger"] != float64(72) {
+		t.Errorf("expected valueInteger 72 as a number, got %#v", obs["valueInteger"])
+	}
+	if cats, ok := obs["category"].([]interface{}); !ok || len(cats) != 1 {
+		t.Errorf("expected category to be a one-element array, got %#v", obs["category"])
+	}
+	if _, ok := obs["code"].(map[string]interface{}); !ok {
+		t.Errorf("expected code to be an object, got %#v", obs["code"])
+	}
+}
+
+func TestXMLCodec_RejectsTypesWithoutLayout(t *testing.T) {
+	// Widget is not a FHIR resource type, so it has no structural layout.
+	codec := DefaultXMLCodec()
+	if _, err := codec.JSONToXML([]byte(`{"resourceType":"Widget","status":"booked"}`)); !errors.Is(err, ErrNoXMLLayout) {
+		t.Errorf("expected ErrNoXMLLayout writing Widget, got %v", err)
+	}
+	bundle := `{"resourceType":"Bundle","type":"searchset","entry":[{"resource":{"resourceType":"Widget","status":"booked"}}]}`
+	if _, err := codec.JSONToXML([]byte(bundle)); !errors.Is(err, ErrNoXMLLayout) {
+		t.Errorf("expected ErrNoXMLLayout writing a Bundle of Widgets, got %v", err)
+	}
+	doc := `<Widget xmlns="http://hl7.org/fhir"><status value="booked"/></Widget>`
+	if _, err := codec.Unmarshal([]byte(doc)); !errors.Is(err, ErrNoXMLLayout) {
+		t.Errorf("expected ErrNoXMLLayout reading Widget, got %v", err)
+	}
+}
+
+func TestXMLCodec_LayoutForEveryResourceType(t *testing.T) {
+	for rt := range FHIRResourceTypes {
+		if !HasStructuralLayout(rt) {
+			t.Errorf("no XML layout for %s", rt)
+		}
+	}
+}
+
+func TestXMLCodec_AppointmentRoundTrip(t *testing.T) {
+	codec := DefaultXMLCodec()
+	in := `{"resourceType":"Appointment","id":"a1","status":"booked","serviceType":[{"text":"Checkup"}],"start":"2024-05-01T09:00:00Z","participant":[{"actor":{"reference":"Patient/p1"},"status":"accepted"},{"actor":{"reference":"Practitioner/d1"},"required":"required","status":"accepted"}]}`
+	xmlOut, err := codec.JSONToXML([]byte(in))
+	if err != nil {
+		t.Fatalf("JSONToXML: %v", err)
+	}
+	if !strings.Contains(string(xmlOut), `<participant><actor><reference value="Patient/p1"/></actor><status value="accepted"/></participant>`) {
+		t.Errorf("participant not written in element order: %s", xmlOut)
+	}
+	back, err := codec.XMLToJSON(xmlOut)
+	if err != nil {
+		t.Fatalf("XMLToJSON: %v", err)
+	}
+	var got, want map[string]interface{}
+	if err := json.Unmarshal(back, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(in), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestXMLCodec_UnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"malformed", `<Patient xmlns="http://hl7.org/fhir"><id value="1"></Patient>`, "syntax error"},
+		{"not a resource", `<patient xmlns="http://hl7.org/fhir"/>`, "not a FHIR resource"},
+		{"wrong namespace", `<Patient xmlns="urn:other"/>`, "FHIR namespace"},
+		{"cardinality", `<Patient xmlns="http://hl7.org/fhir"><gender value="male"/><gender value="female"/></Patient>`, "at most one"},
+		{"bad boolean", `<Patient xmlns="http://hl7.org/fhir"><active value="1"/></Patient>`, "invalid boolean"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DefaultXMLCodec().Unmarshal([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRegisterStructuralDefinitions_CompletesBaseDefinitions(t *testing.T) {
+	store := NewStructureDefinitionStore()
+	RegisterBaseDefinitions(store)
+
+	if sd := store.Get("HumanName"); sd == nil || sd.Kind != "complex-type" {
+		t.Fatalf("expected HumanName complex-type definition, got %+v", sd)
+	}
+	sd := store.Get("Patient")
+	var paths []string
+	for _, e := range sd.Snapshot.Element {
+		paths = append(paths, e.Path)
+		if e.Path == "Patient.gender" && (e.Binding == nil || e.Binding.Strength != "required") {
+			t.Error("expected existing Patient.gender binding to be kept")
+		}
+	}
+	joined := strings.Join(paths, ",")
+	if !strings.Contains(joined, "Patient.name,Patient.telecom,Patient.gender,Patient.birthDate,Patient.deceased[x]") {
+		t.Errorf("expected canonical Patient element order, got %s", joined)
+	}
+	if !strings.Contains(joined, "Patient.contact.relationship") {
+		t.Errorf("expected backbone elements, got %s", joined)
+	}
+}
@@ -335,15 +335,23 @@ func ConditionalRequestMiddleware() echo.MiddlewareFunc {
 // ---------------------------------------------------------------------------
 
 // ResponseCacheMiddleware returns Echo middleware that caches GET responses
-// by URL + Accept header. Requests with an Authorization header skip the cache
-// to protect private data.
+// by URL (path and query) + Accept header. Requests with an Authorization
+// header skip the cache to protect private data.
 func ResponseCacheMiddleware(store CacheStore, ttl time.Duration) echo.MiddlewareFunc {
+	return ResponseCacheMiddlewareWithSkipper(store, ttl, nil)
+}
+
+// ResponseCacheMiddlewareWithSkipper is ResponseCacheMiddleware for the
+// requests skipper does not skip. Servers whose unauthenticated requests may
+// carry private data (development auth) restrict the cache to their public
+// endpoints this way.
+func ResponseCacheMiddlewareWithSkipper(store CacheStore, ttl time.Duration, skipper func(echo.Context) bool) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			req := c.Request()
 
 			// Only cache GET requests.
-			if req.Method != http.MethodGet {
+			if req.Method != http.MethodGet || (skipper != nil && skipper(c)) {
 				return next(c)
 			}
 
@@ -353,13 +361,17 @@ func ResponseCacheMiddleware(store CacheStore, ttl time.Duration) echo.Middlewar
 				return next(c)
 			}
 
-			key := cacheKey(req.Method, req.URL.Path, req.Header.Get("Accept"))
+			key := cacheKey(req.Method, req.URL.RequestURI(), req.Header.Get("Accept"))
 
 			// Check cache.
 			if data, ok := store.Get(key); ok {
+				contentType, body := decodeCachedResponse(data)
+				if contentType != "" {
+					c.Response().Header().Set(echo.HeaderContentType, contentType)
+				}
 				c.Response().Header().Set("X-Cache", "HIT")
 				c.Response().Writer.WriteHeader(http.StatusOK)
-				_, err := c.Response().Writer.Write(data)
+				_, err := c.Response().Writer.Write(body)
 				return err
 			}
 
@@ -378,7 +390,7 @@ func ResponseCacheMiddleware(store CacheStore, ttl time.Duration) echo.Middlewar
 
 			// Only cache successful responses.
 			if buf.statusCode < 400 {
-				store.Set(key, buf.buf.Bytes(), ttl)
+				store.Set(key, encodeCachedResponse(res.Header().Get(echo.HeaderContentType), buf.buf.Bytes()), ttl)
 			}
 
 			res.Header().Set("X-Cache", "MISS")
@@ -397,9 +409,25 @@ func computeETag(body []byte) string {
 	return fmt.Sprintf(`W/"%x"`, hash)
 }
 
-// cacheKey builds a cache key from the HTTP method, path, and Accept header.
-func cacheKey(method, path, accept string) string {
-	return method + ":" + path + ":" + accept
+// cacheKey builds a cache key from the HTTP method, request URI, and Accept
+// header.
+func cacheKey(method, uri, accept string) string {
+	return method + ":" + uri + ":" + accept
+}
+
+// encodeCachedResponse packs a response's content type and body into a
+// cache value.
+func encodeCachedResponse(contentType string, body []byte) []byte {
+	return append([]byte(contentType+"\n"), body...)
+}
+
+// decodeCachedResponse unpacks a value stored by encodeCachedResponse.
+func decodeCachedResponse(data []byte) (string, []byte) {
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return "", data
+	}
+	return string(data[:i]), data[i+1:]
 }
 
 // shouldSkip returns true if the path matches any of the excluded paths.
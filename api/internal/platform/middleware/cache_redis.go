@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/ehr/ehr/internal/platform/redis"
+)
+
+// redisCacheTimeout bounds a single cache round trip to Redis.
+const redisCacheTimeout = time.Second
+
+// RedisCacheStore is a CacheStore kept in Redis, so that all replicas share
+// one response cache. Entries are stored under a key prefix and expire through
+// Redis TTLs. Redis errors are treated as cache misses.
+type RedisCacheStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisCacheStore creates a RedisCacheStore storing entries under prefix.
+func NewRedisCacheStore(client *redis.Client, prefix string) *RedisCacheStore {
+	return &RedisCacheStore{client: client, prefix: prefix}
+}
+
+// Get retrieves a value from the cache.
+func (s *RedisCacheStore) Get(key string) ([]byte, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisCacheTimeout)
+	defer cancel()
+	data, err := s.client.Get(ctx, s.prefix+key)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Set stores a value in the cache with the given TTL.
+func (s *RedisCacheStore) Set(key string, value []byte, ttl time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisCacheTimeout)
+	defer cancel()
+	_ = s.client.Set(ctx, s.prefix+key, value, ttl)
+}
+
+// Delete removes a single entry from the cache.
+func (s *RedisCacheStore) Delete(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisCacheTimeout)
+	defer cancel()
+	_ = s.client.Del(ctx, s.prefix+key)
+}
+
+// Clear removes all entries under the store's prefix.
+func (s *RedisCacheStore) Clear() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*redisCacheTimeout)
+	defer cancel()
+	keys, err := s.client.Scan(ctx, s.prefix+"*")
+	if err != nil {
+		return
+	}
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > 500 {
+			n = 500
+		}
+		if err := s.client.Del(ctx, keys[:n]...); err != nil {
+			return
+		}
+		keys = keys[n:]
+	}
+}
@@ -565,6 +565,58 @@ func TestResponseCache_Expiration(t *testing.T) {
 	}
 }
 
+func TestResponseCache_KeysOnQueryAndKeepsContentType(t *testing.T) {
+	e := echo.New()
+	store := NewInMemoryCacheStore()
+	handler := ResponseCacheMiddleware(store, 5*time.Minute)(func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"format": c.QueryParam("_format")})
+	})
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		if err := handler(e.NewContext(httptest.NewRequest(http.MethodGet, target, nil), rec)); err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+	get("/fhir/metadata?_format=json")
+	rec := get("/fhir/metadata?_format=xml")
+	if rec.Header().Get("X-Cache") != "MISS" || !containsSubstring(rec.Body.String(), "xml") {
+		t.Errorf("expected a different query to miss, got %s %s", rec.Header().Get("X-Cache"), rec.Body.String())
+	}
+	rec = get("/fhir/metadata?_format=xml")
+	if rec.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("expected HIT, got %q", rec.Header().Get("X-Cache"))
+	}
+	if ct := rec.Header().Get(echo.HeaderContentType); !containsSubstring(ct, "application/json") {
+		t.Errorf("expected the cached content type, got %q", ct)
+	}
+	if !containsSubstring(rec.Body.String(), `"format":"xml"`) {
+		t.Errorf("unexpected cached body %s", rec.Body.String())
+	}
+}
+
+func TestResponseCache_Skipper(t *testing.T) {
+	e := echo.New()
+	store := NewInMemoryCacheStore()
+	calls := 0
+	skipper := func(c echo.Context) bool { return c.Request().URL.Path != "/fhir/metadata" }
+	handler := ResponseCacheMiddlewareWithSkipper(store, 5*time.Minute, skipper)(func(c echo.Context) error {
+		calls++
+		return c.String(http.StatusOK, "data")
+	})
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		_ = handler(e.NewContext(httptest.NewRequest(http.MethodGet, "/fhir/Patient", nil), rec))
+		if rec.Header().Get("X-Cache") != "" {
+			t.Errorf("expected skipped request to bypass the cache, got %q", rec.Header().Get("X-Cache"))
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected skipped requests to execute, called %d times", calls)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Cleanup goroutine test
 // ---------------------------------------------------------------------------
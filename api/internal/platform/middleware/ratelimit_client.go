@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/redis"
 )
 
 // ---------------------------------------------------------------------------
@@ -33,6 +35,8 @@ type RateLimitInfo struct {
 	Limit      int    `json:"limit"`
 	RetryAfter int    `json:"retry_after"`
 	Plan       string `json:"plan"`
+	// ResetAt is when the per-minute window resets, if known.
+	ResetAt time.Time `json:"-"`
 }
 
 // ClientUsage exposes the current usage counters for a client.
@@ -63,12 +67,15 @@ type clientCounter struct {
 }
 
 // ClientRateLimiter provides thread-safe per-client rate limiting with
-// multiple time windows and concurrent request tracking.
+// multiple time windows and concurrent request tracking. Counters are kept
+// in-process, or in Redis when created with NewRedisClientRateLimiter.
 type ClientRateLimiter struct {
 	plans       map[string]*RatePlan
 	clientPlans map[string]string
 	counters    map[string]*clientCounter
 	mu          sync.RWMutex
+	redis       *redis.Client
+	redisPrefix string
 }
 
 // ---------------------------------------------------------------------------
@@ -230,6 +237,11 @@ func maybeResetWindows(c *clientCounter) {
 // The effective per-minute limit is RequestsPerMinute + BurstSize.
 func (rl *ClientRateLimiter) Allow(clientID string) (bool, *RateLimitInfo) {
 	plan := rl.GetPlan(clientID)
+	if rl.redis != nil {
+		if allowed, info, err := rl.allowRedis(clientID, plan); err == nil {
+			return allowed, info
+		}
+	}
 	counter := rl.getOrCreateCounter(clientID)
 
 	// Reset expired windows under lock
@@ -305,6 +317,9 @@ func (rl *ClientRateLimiter) Allow(clientID string) (bool, *RateLimitInfo) {
 // Release decrements the concurrent request counter for clientID. It is safe
 // to call even if Allow was never called (the counter will not go below zero).
 func (rl *ClientRateLimiter) Release(clientID string) {
+	if rl.redis != nil && rl.releaseRedis(clientID) == nil {
+		return
+	}
 	counter := rl.getOrCreateCounter(clientID)
 	for {
 		cur := atomic.LoadInt64(&counter.concurrent)
@@ -324,6 +339,11 @@ func (rl *ClientRateLimiter) Release(clientID string) {
 // GetUsage returns a snapshot of the current counters for clientID.
 func (rl *ClientRateLimiter) GetUsage(clientID string) *ClientUsage {
 	plan := rl.GetPlan(clientID)
+	if rl.redis != nil {
+		if usage, err := rl.usageRedis(clientID, plan); err == nil {
+			return usage
+		}
+	}
 	counter := rl.getOrCreateCounter(clientID)
 
 	counter.mu.Lock()
@@ -347,6 +367,9 @@ func (rl *ClientRateLimiter) GetUsage(clientID string) *ClientUsage {
 // ResetCounters zeroes all rate-limit counters for clientID and resets the
 // time windows.
 func (rl *ClientRateLimiter) ResetCounters(clientID string) {
+	if rl.redis != nil {
+		_ = rl.resetRedis(clientID)
+	}
 	counter := rl.getOrCreateCounter(clientID)
 	counter.mu.Lock()
 	defer counter.mu.Unlock()
@@ -416,7 +439,9 @@ func ClientRateLimitMiddleware(limiter *ClientRateLimiter) echo.MiddlewareFunc {
 			limiter.mu.RLock()
 			counter, ok := limiter.counters[clientID]
 			limiter.mu.RUnlock()
-			if ok {
+			if !info.ResetAt.IsZero() {
+				c.Response().Header().Set("X-RateLimit-Reset", strconv.FormatInt(info.ResetAt.Unix(), 10))
+			} else if ok {
 				counter.mu.Lock()
 				resetEpoch := counter.minuteReset.Unix()
 				counter.mu.Unlock()
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ehr/ehr/internal/platform/redis"
+)
+
+// redisRateLimitTimeout bounds a single rate-limit round trip to Redis.
+const redisRateLimitTimeout = 500 * time.Millisecond
+
+// concurrentLease is how long a concurrency slot survives without activity,
+// so that slots held by a crashed replica are eventually reclaimed.
+const concurrentLease = 5 * time.Minute
+
+// NewRedisClientRateLimiter creates a ClientRateLimiter whose counters live
+// in Redis under prefix, so that quotas hold across all replicas. Plans and
+// plan assignments stay per-process. If Redis cannot be reached, the limiter
+// falls back to per-process counters.
+func NewRedisClientRateLimiter(client *redis.Client, prefix string) *ClientRateLimiter {
+	rl := NewClientRateLimiter()
+	rl.redis = client
+	rl.redisPrefix = prefix
+	return rl
+}
+
+// redisCounters returns the Redis counters of clientID under plan, in the
+// order Allow checks them: concurrent, minute, hour, day.
+func (rl *ClientRateLimiter) redisCounters(clientID string, plan *RatePlan) []redis.Counter {
+	concurrent := plan.ConcurrentRequests
+	if concurrent <= 0 {
+		concurrent = -1 // unlimited
+	}
+	key := rl.redisPrefix + clientID
+	return []redis.Counter{
+		{Key: key + ":concurrent", Limit: concurrent, TTL: concurrentLease, Refresh: true},
+		{Key: key + ":minute", Limit: plan.RequestsPerMinute + plan.BurstSize, TTL: time.Minute},
+		{Key: key + ":hour", Limit: plan.RequestsPerHour, TTL: time.Hour},
+		{Key: key + ":day", Limit: plan.RequestsPerDay, TTL: 24 * time.Hour},
+	}
+}
+
+// allowRedis is Allow backed by Redis.
+func (rl *ClientRateLimiter) allowRedis(clientID string, plan *RatePlan) (bool, *RateLimitInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisRateLimitTimeout)
+	defer cancel()
+	res, err := rl.redis.Quota(ctx, rl.redisCounters(clientID, plan))
+	if err != nil {
+		return false, nil, err
+	}
+	limit := plan.RequestsPerMinute + plan.BurstSize
+	info := &RateLimitInfo{
+		Allowed: res.Allowed,
+		Plan:    plan.Name,
+		Limit:   limit,
+		ResetAt: time.Now().Add(res.TTLs[1]),
+	}
+	if res.TTLs[1] < 0 {
+		info.ResetAt = time.Now().Add(time.Minute)
+	}
+	switch res.Exhausted {
+	case -1:
+		info.Remaining = limit - res.Counts[1]
+		if info.Remaining < 0 {
+			info.Remaining = 0
+		}
+	case 0:
+		info.RetryAfter = 1 // retry quickly for concurrent
+	default:
+		info.RetryAfter = secondsUntil(time.Now().Add(res.TTLs[res.Exhausted]))
+	}
+	return res.Allowed, info, nil
+}
+
+// releaseRedis is Release backed by Redis.
+func (rl *ClientRateLimiter) releaseRedis(clientID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisRateLimitTimeout)
+	defer cancel()
+	_, err := rl.redis.Release(ctx, rl.redisPrefix+clientID+":concurrent")
+	return err
+}
+
+// usageRedis is GetUsage backed by Redis.
+func (rl *ClientRateLimiter) usageRedis(clientID string, plan *RatePlan) (*ClientUsage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisRateLimitTimeout)
+	defer cancel()
+	counters := rl.redisCounters(clientID, plan)
+	counts := make([]int, len(counters))
+	for i, ctr := range counters {
+		data, err := rl.redis.Get(ctx, ctr.Key)
+		if errors.Is(err, redis.ErrNil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if counts[i], err = redis.Int(data); err != nil {
+			return nil, err
+		}
+	}
+	return &ClientUsage{
+		ClientID:        clientID,
+		Plan:            plan.Name,
+		ConcurrentUsed:  counts[0],
+		ConcurrentLimit: plan.ConcurrentRequests,
+		MinuteUsed:      counts[1],
+		MinuteLimit:     plan.RequestsPerMinute + plan.BurstSize,
+		HourUsed:        counts[2],
+		HourLimit:       plan.RequestsPerHour,
+		DayUsed:         counts[3],
+		DayLimit:        plan.RequestsPerDay,
+	}, nil
+}
+
+// resetRedis is ResetCounters backed by Redis.
+func (rl *ClientRateLimiter) resetRedis(clientID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisRateLimitTimeout)
+	defer cancel()
+	key := rl.redisPrefix + clientID
+	return rl.redis.Del(ctx, key+":concurrent", key+":minute", key+":hour", key+":day")
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+
+	"github.com/ehr/ehr/internal/platform/redis"
+)
+
+func newTestRedis(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	client, err := redis.NewClient("redis://" + srv.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, srv
+}
+
+func TestRedisClientRateLimiter_SharedAcrossReplicas(t *testing.T) {
+	client, _ := newTestRedis(t)
+	plan := RatePlan{Name: "tiny", RequestsPerMinute: 2, BurstSize: 1, RequestsPerHour: 100, RequestsPerDay: 1000}
+	var replicas []*ClientRateLimiter
+	for i := 0; i < 2; i++ {
+		rl := NewRedisClientRateLimiter(client, "rl:")
+		rl.RegisterPlan(plan)
+		if err := rl.AssignPlan("c1", "tiny"); err != nil {
+			t.Fatal(err)
+		}
+		replicas = append(replicas, rl)
+	}
+
+	for i := 0; i < 3; i++ {
+		rl := replicas[i%2]
+		allowed, info := rl.Allow("c1")
+		if !allowed {
+			t.Fatalf("request %d denied", i)
+		}
+		if info.Remaining != 2-i {
+			t.Errorf("request %d: remaining = %d, want %d", i, info.Remaining, 2-i)
+		}
+		rl.Release("c1")
+	}
+	allowed, info := replicas[1].Allow("c1")
+	if allowed {
+		t.Fatal("expected the fourth request across replicas to be denied")
+	}
+	if info.RetryAfter < 1 || info.RetryAfter > 60 {
+		t.Errorf("RetryAfter = %d, want within the minute", info.RetryAfter)
+	}
+
+	usage := replicas[0].GetUsage("c1")
+	if usage.MinuteUsed != 3 || usage.HourUsed != 3 || usage.ConcurrentUsed != 0 {
+		t.Errorf("usage = %+v", usage)
+	}
+}
+
+func TestRedisClientRateLimiter_Concurrent(t *testing.T) {
+	client, _ := newTestRedis(t)
+	rl := NewRedisClientRateLimiter(client, "rl:")
+	rl.RegisterPlan(RatePlan{Name: "one", RequestsPerMinute: 100, RequestsPerHour: 100, RequestsPerDay: 100, ConcurrentRequests: 1})
+	rl.AssignPlan("c1", "one")
+
+	if allowed, _ := rl.Allow("c1"); !allowed {
+		t.Fatal("first request denied")
+	}
+	allowed, info := rl.Allow("c1")
+	if allowed || info.RetryAfter != 1 {
+		t.Fatalf("second concurrent request = %v, %+v; want denied with RetryAfter 1", allowed, info)
+	}
+	rl.Release("c1")
+	if allowed, _ := rl.Allow("c1"); !allowed {
+		t.Error("request after release denied")
+	}
+}
+
+func TestRedisClientRateLimiter_ResetCounters(t *testing.T) {
+	client, srv := newTestRedis(t)
+	rl := NewRedisClientRateLimiter(client, "rl:")
+	rl.Allow("c1")
+	rl.ResetCounters("c1")
+	if keys := srv.Keys(); len(keys) != 0 {
+		t.Errorf("keys after reset = %v, want none", keys)
+	}
+}
+
+func TestRedisClientRateLimiter_FallsBackWhenUnavailable(t *testing.T) {
+	client, srv := newTestRedis(t)
+	rl := NewRedisClientRateLimiter(client, "rl:")
+	rl.RegisterPlan(RatePlan{Name: "one", RequestsPerMinute: 1, RequestsPerHour: 100, RequestsPerDay: 100})
+	rl.AssignPlan("c1", "one")
+	srv.Close()
+
+	if allowed, _ := rl.Allow("c1"); !allowed {
+		t.Fatal("first request should be allowed by in-process counters")
+	}
+	if allowed, _ := rl.Allow("c1"); allowed {
+		t.Error("in-process counters should still enforce the limit")
+	}
+}
+
+func TestClientRateLimitMiddleware_RedisResetHeader(t *testing.T) {
+	client, _ := newTestRedis(t)
+	rl := NewRedisClientRateLimiter(client, "rl:")
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Client-ID", "c1")
+	rec := httptest.NewRecorder()
+	h := ClientRateLimitMiddleware(rl)(func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	if err := h(e.NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Header().Get("X-RateLimit-Reset") == "" {
+		t.Error("expected X-RateLimit-Reset header")
+	}
+	if rec.Header().Get("X-RateLimit-Remaining") != "69" {
+		t.Errorf("X-RateLimit-Remaining = %q, want 69", rec.Header().Get("X-RateLimit-Remaining"))
+	}
+}
+
+func TestRedisCacheStore(t *testing.T) {
+	client, srv := newTestRedis(t)
+	store := NewRedisCacheStore(client, "cache:")
+	other := NewRedisCacheStore(client, "other:")
+
+	if _, ok := store.Get("k"); ok {
+		t.Fatal("expected miss")
+	}
+	store.Set("k", []byte("v"), time.Minute)
+	store.Set("k2", []byte("v2"), time.Minute)
+	other.Set("k", []byte("o"), time.Minute)
+	if got, ok := store.Get("k"); !ok || string(got) != "v" {
+		t.Fatalf("Get = %q, %v", got, ok)
+	}
+
+	store.Delete("k")
+	if _, ok := store.Get("k"); ok {
+		t.Error("expected miss after Delete")
+	}
+	store.Clear()
+	if _, ok := store.Get("k2"); ok {
+		t.Error("expected miss after Clear")
+	}
+	if got, ok := other.Get("k"); !ok || string(got) != "o" {
+		t.Error("Clear should only remove the store's own prefix")
+	}
+
+	store.Set("short", []byte("v"), 20*time.Millisecond)
+	srv.FastForward(40 * time.Millisecond)
+	if _, ok := store.Get("short"); ok {
+		t.Error("expected entry to expire")
+	}
+
+	srv.Close()
+	if _, ok := other.Get("k"); ok {
+		t.Error("expected miss when Redis is unavailable")
+	}
+}
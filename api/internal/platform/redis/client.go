@@ -0,0 +1,268 @@
+// Package redis is a minimal client for servers speaking the Redis
+// serialization protocol (RESP2), used to share rate-limit and cache state
+// between replicas. It supports plain commands and Lua scripts, which is all
+// the platform needs.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNil is returned by helpers when the server replies with a null value,
+// for example GET on a missing key.
+var ErrNil = errors.New("redis: nil reply")
+
+// Error is an error reply sent by the server, such as "NOSCRIPT ...".
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+const (
+	defaultDialTimeout = 5 * time.Second
+	defaultOpTimeout   = 5 * time.Second
+	defaultPoolSize    = 16
+)
+
+// Client is a pooled connection to a Redis server. It is safe for concurrent
+// use. Connections are dialed lazily, so NewClient does not contact the
+// server; use Ping to check connectivity.
+type Client struct {
+	addr        string
+	username    string
+	password    string
+	db          int
+	tlsConfig   *tls.Config
+	dialTimeout time.Duration
+	opTimeout   time.Duration
+	pool        chan *conn
+}
+
+// conn is a single server connection.
+type conn struct {
+	nc net.Conn
+	br *bufio.Reader
+	bw *bufio.Writer
+}
+
+// NewClient creates a Client for a URL of the form
+// redis://[[user]:password@]host[:port][/db]. The rediss scheme connects over
+// TLS.
+func NewClient(rawURL string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse redis url: %w", err)
+	}
+	c := &Client{
+		dialTimeout: defaultDialTimeout,
+		opTimeout:   defaultOpTimeout,
+		pool:        make(chan *conn, defaultPoolSize),
+	}
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		c.tlsConfig = &tls.Config{ServerName: u.Hostname()}
+	default:
+		return nil, fmt.Errorf("parse redis url: unsupported scheme %q", u.Scheme)
+	}
+	host := u.Host
+	if host == "" {
+		return nil, fmt.Errorf("parse redis url: missing host")
+	}
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	c.addr = host
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		if c.db, err = strconv.Atoi(path); err != nil {
+			return nil, fmt.Errorf("parse redis url: invalid database %q", path)
+		}
+	}
+	return c, nil
+}
+
+// Close closes the idle connections of the pool. Connections in use are
+// closed when they are returned.
+func (c *Client) Close() error {
+	for {
+		select {
+		case cn := <-c.pool:
+			cn.nc.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// Ping checks that the server is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Do sends a command and returns its reply, which is one of string (simple
+// string), int64, []byte (bulk string), nil (null), []interface{} (array).
+// Error replies are returned as an Error.
+func (c *Client) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := cn.do(ctx, c.opTimeout, args)
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection is in an unknown state.
+		cn.nc.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Get returns the value of key, or ErrNil if it does not exist.
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	reply, err := c.Do(ctx, "GET", key)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrNil
+	}
+	b, ok := reply.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+	return b, nil
+}
+
+// Set stores value under key. A positive ttl sets an expiry.
+func (c *Client) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []interface{}{"SET", key, value}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+	_, err := c.Do(ctx, args...)
+	return err
+}
+
+// Del deletes keys.
+func (c *Client) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, k := range keys {
+		args = append(args, k)
+	}
+	_, err := c.Do(ctx, args...)
+	return err
+}
+
+// Scan returns all keys matching a glob pattern, using SCAN so as not to
+// block the server.
+func (c *Client) Scan(ctx context.Context, match string) ([]string, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := c.Do(ctx, "SCAN", cursor, "MATCH", match, "COUNT", 500)
+		if err != nil {
+			return nil, err
+		}
+		arr, ok := reply.([]interface{})
+		if !ok || len(arr) != 2 {
+			return nil, fmt.Errorf("redis: unexpected SCAN reply %T", reply)
+		}
+		next, _ := arr[0].([]byte)
+		batch, _ := arr[1].([]interface{})
+		for _, k := range batch {
+			if b, ok := k.([]byte); ok {
+				keys = append(keys, string(b))
+			}
+		}
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return keys, nil
+		}
+	}
+}
+
+// get takes an idle connection from the pool or dials a new one.
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.pool:
+		return cn, nil
+	default:
+	}
+	d := net.Dialer{Timeout: c.dialTimeout}
+	var (
+		nc  net.Conn
+		err error
+	)
+	if c.tlsConfig != nil {
+		nc, err = (&tls.Dialer{NetDialer: &d, Config: c.tlsConfig}).DialContext(ctx, "tcp", c.addr)
+	} else {
+		nc, err = d.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis: dial %s: %w", c.addr, err)
+	}
+	cn := &conn{nc: nc, br: bufio.NewReader(nc), bw: bufio.NewWriter(nc)}
+	if c.password != "" {
+		args := []interface{}{"AUTH", c.password}
+		if c.username != "" {
+			args = []interface{}{"AUTH", c.username, c.password}
+		}
+		if _, err := cn.do(ctx, c.opTimeout, args); err != nil {
+			nc.Close()
+			return nil, err
+		}
+	}
+	if c.db != 0 {
+		if _, err := cn.do(ctx, c.opTimeout, []interface{}{"SELECT", c.db}); err != nil {
+			nc.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// put returns a connection to the pool, closing it if the pool is full.
+func (c *Client) put(cn *conn) {
+	select {
+	case c.pool <- cn:
+	default:
+		cn.nc.Close()
+	}
+}
+
+// do writes a command and reads its reply. The deadline is the earlier of
+// the context deadline and timeout from now.
+func (cn *conn) do(ctx context.Context, timeout time.Duration, args []interface{}) (interface{}, error) {
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := cn.nc.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if err := WriteCommand(cn.bw, args...); err != nil {
+		return nil, err
+	}
+	if err := cn.bw.Flush(); err != nil {
+		return nil, err
+	}
+	return ReadReply(cn.br)
+}
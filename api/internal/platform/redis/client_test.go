@@ -0,0 +1,142 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/ehr/ehr/internal/platform/redis"
+)
+
+// newTestClient returns a client of a miniredis server, which runs the Lua
+// source of the scripts. If password is not empty, the server requires it.
+func newTestClient(t *testing.T, password string) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	url := "redis://" + srv.Addr()
+	if password != "" {
+		srv.RequireAuth(password)
+		url = "redis://:" + password + "@" + srv.Addr()
+	}
+	client, err := redis.NewClient(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, srv
+}
+
+func TestNewClient_ParsesURL(t *testing.T) {
+	for _, u := range []string{"redis://localhost", "redis://:secret@localhost:6380/2", "rediss://user:pw@cache.internal:6379"} {
+		if _, err := redis.NewClient(u); err != nil {
+			t.Errorf("NewClient(%q): %v", u, err)
+		}
+	}
+	for _, u := range []string{"http://localhost", "redis://", "redis://localhost/db"} {
+		if _, err := redis.NewClient(u); err == nil {
+			t.Errorf("NewClient(%q): expected error", u)
+		}
+	}
+}
+
+func TestClient_GetSetDel(t *testing.T) {
+	client, _ := newTestClient(t, "")
+	ctx := context.Background()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(ctx, "k"); !errors.Is(err, redis.ErrNil) {
+		t.Fatalf("Get missing key: err = %v, want ErrNil", err)
+	}
+	if err := client.Set(ctx, "k", []byte("v"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.Get(ctx, "k")
+	if err != nil || string(got) != "v" {
+		t.Fatalf("Get = %q, %v; want v", got, err)
+	}
+	if err := client.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(ctx, "k"); !errors.Is(err, redis.ErrNil) {
+		t.Errorf("Get after Del: err = %v, want ErrNil", err)
+	}
+}
+
+func TestClient_SetExpires(t *testing.T) {
+	client, srv := newTestClient(t, "")
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "k", []byte("v"), 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	srv.FastForward(40 * time.Millisecond)
+	if _, err := client.Get(ctx, "k"); !errors.Is(err, redis.ErrNil) {
+		t.Errorf("Get after expiry: err = %v, want ErrNil", err)
+	}
+}
+
+func TestClient_Scan(t *testing.T) {
+	client, _ := newTestClient(t, "")
+	ctx := context.Background()
+	for _, k := range []string{"cache:a", "cache:b", "other"} {
+		if err := client.Set(ctx, k, []byte("1"), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keys, err := client.Scan(ctx, "cache:*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] != "cache:a" || keys[1] != "cache:b" {
+		t.Errorf("Scan = %v, want [cache:a cache:b]", keys)
+	}
+}
+
+func TestClient_Auth(t *testing.T) {
+	client, srv := newTestClient(t, "secret")
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping with password: %v", err)
+	}
+
+	anon, err := redis.NewClient("redis://" + srv.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer anon.Close()
+	var replyErr redis.Error
+	if err := anon.Ping(context.Background()); !errors.As(err, &replyErr) {
+		t.Errorf("Ping without password: err = %v, want NOAUTH", err)
+	}
+}
+
+func TestClient_ErrorReplyKeepsConnection(t *testing.T) {
+	client, _ := newTestClient(t, "")
+	ctx := context.Background()
+	if _, err := client.Do(ctx, "NOSUCHCOMMAND"); err == nil {
+		t.Fatal("expected error reply")
+	}
+	if err := client.Ping(ctx); err != nil {
+		t.Errorf("Ping after error reply: %v", err)
+	}
+}
+
+func TestScript_FallsBackToEval(t *testing.T) {
+	client, _ := newTestClient(t, "")
+	ctx := context.Background()
+
+	if _, err := client.Do(ctx, "SCRIPT", "FLUSH"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Release(ctx, "k"); err != nil {
+		t.Fatalf("Release with uncached script: %v", err)
+	}
+	// Now cached: EVALSHA succeeds directly.
+	if _, err := client.Do(ctx, "EVALSHA", redis.ReleaseScript.SHA(), 1, "k"); err != nil {
+		t.Errorf("EVALSHA after EVAL: %v", err)
+	}
+}
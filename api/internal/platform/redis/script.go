@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// Script is a Lua script run atomically on the server. It is sent by SHA1
+// digest, falling back to the full source when the server has not cached it.
+type Script struct {
+	src string
+	sha string
+}
+
+// NewScript creates a Script from Lua source.
+func NewScript(src string) *Script {
+	sum := sha1.Sum([]byte(src))
+	return &Script{src: src, sha: hex.EncodeToString(sum[:])}
+}
+
+// Source returns the script's Lua source.
+func (s *Script) Source() string { return s.src }
+
+// SHA returns the hex SHA1 digest the server identifies the script by.
+func (s *Script) SHA() string { return s.sha }
+
+// Run executes the script with EVALSHA, or with EVAL if the server replies
+// NOSCRIPT.
+func (s *Script) Run(ctx context.Context, c *Client, keys []string, args ...interface{}) (interface{}, error) {
+	reply, err := c.Do(ctx, s.command("EVALSHA", s.sha, keys, args)...)
+	var replyErr Error
+	if errors.As(err, &replyErr) && strings.HasPrefix(string(replyErr), "NOSCRIPT") {
+		return c.Do(ctx, s.command("EVAL", s.src, keys, args)...)
+	}
+	return reply, err
+}
+
+func (s *Script) command(name, script string, keys []string, args []interface{}) []interface{} {
+	cmd := make([]interface{}, 0, 3+len(keys)+len(args))
+	cmd = append(cmd, name, script, len(keys))
+	for _, k := range keys {
+		cmd = append(cmd, k)
+	}
+	return append(cmd, args...)
+}
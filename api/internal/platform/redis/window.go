@@ -0,0 +1,197 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SlidingWindowScript records a request in a sliding window kept as a sorted
+// set of request timestamps (in milliseconds), unless the window already
+// holds limit requests.
+//
+// KEYS[1] is the window; ARGV is the window length, limit and a member
+// unique to the request. The current time is read from the Redis server
+// with TIME rather than passed by the caller, so that replicas whose clocks
+// disagree still share one consistent window. It returns {allowed (0|1),
+// count, reset}, where reset is when the oldest request in the window
+// expires.
+var SlidingWindowScript = NewScript(`
+-- TIME is non-deterministic: replicate the script's effects, not the
+-- script (the default from Redis 5).
+redis.replicate_commands()
+local key = KEYS[1]
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', key, now, ARGV[3])
+  count = count + 1
+  allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+local reset = now + window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+  reset = tonumber(oldest[2]) + window
+end
+return {allowed, count, reset}
+`)
+
+// QuotaScript checks a set of counters against their limits and, only if
+// none is exhausted, increments all of them.
+//
+// KEYS are the counters; ARGV holds a (limit, ttl, refresh) triple per key. A
+// negative limit is unlimited. The ttl, in milliseconds, is set when the
+// counter is created, or on every increment when refresh is 1. It returns
+// {allowed (0|1), exhausted key index (1-based, 0 if allowed), count_1,
+// ttl_1, count_2, ttl_2, ...} with the counts after the increment.
+var QuotaScript = NewScript(`
+local exhausted = 0
+for i, key in ipairs(KEYS) do
+  local limit = tonumber(ARGV[3 * i - 2])
+  local count = tonumber(redis.call('GET', key) or '0')
+  if limit >= 0 and count >= limit then
+    exhausted = i
+    break
+  end
+end
+local result = {exhausted == 0 and 1 or 0, exhausted}
+for i, key in ipairs(KEYS) do
+  local count
+  if exhausted == 0 then
+    count = redis.call('INCR', key)
+    if count == 1 or ARGV[3 * i] == '1' then
+      redis.call('PEXPIRE', key, ARGV[3 * i - 1])
+    end
+  else
+    count = tonumber(redis.call('GET', key) or '0')
+  end
+  table.insert(result, count)
+  table.insert(result, redis.call('PTTL', key))
+end
+return result
+`)
+
+// ReleaseScript decrements a counter, deleting it when it reaches zero so it
+// never goes negative. KEYS[1] is the counter; it returns the new count.
+var ReleaseScript = NewScript(`
+local count = tonumber(redis.call('GET', KEYS[1]) or '0')
+if count <= 1 then
+  redis.call('DEL', KEYS[1])
+  return 0
+end
+return redis.call('DECR', KEYS[1])
+`)
+
+// WindowResult is the outcome of SlidingWindow.
+type WindowResult struct {
+	Allowed bool
+	Count   int
+	ResetAt time.Time
+}
+
+// SlidingWindow records a request identified by member in the sliding window
+// stored at key, unless it already holds limit requests within window. The
+// window is measured on the Redis server's clock.
+func (c *Client) SlidingWindow(ctx context.Context, key, member string, limit int, window time.Duration) (WindowResult, error) {
+	reply, err := SlidingWindowScript.Run(ctx, c, []string{key},
+		window.Milliseconds(), limit, member)
+	if err != nil {
+		return WindowResult{}, err
+	}
+	vals, err := ints(reply, 3)
+	if err != nil {
+		return WindowResult{}, err
+	}
+	return WindowResult{
+		Allowed: vals[0] == 1,
+		Count:   vals[1],
+		ResetAt: time.UnixMilli(int64(vals[2])),
+	}, nil
+}
+
+// Counter is one counter checked by Quota.
+type Counter struct {
+	Key string
+	// Limit is the count at which the counter is exhausted; negative is
+	// unlimited.
+	Limit int
+	// TTL is the counter's lifetime, i.e. its fixed window.
+	TTL time.Duration
+	// Refresh extends the TTL on every increment, for counters that are
+	// decremented explicitly and only expire when abandoned.
+	Refresh bool
+}
+
+// QuotaResult is the outcome of Quota.
+type QuotaResult struct {
+	Allowed bool
+	// Exhausted is the index of the first exhausted counter, or -1.
+	Exhausted int
+	Counts    []int
+	TTLs      []time.Duration
+}
+
+// Quota atomically increments all counters unless one of them has reached
+// its limit.
+func (c *Client) Quota(ctx context.Context, counters []Counter) (QuotaResult, error) {
+	keys := make([]string, len(counters))
+	args := make([]interface{}, 0, 3*len(counters))
+	for i, ctr := range counters {
+		keys[i] = ctr.Key
+		refresh := 0
+		if ctr.Refresh {
+			refresh = 1
+		}
+		args = append(args, ctr.Limit, ctr.TTL.Milliseconds(), refresh)
+	}
+	reply, err := QuotaScript.Run(ctx, c, keys, args...)
+	if err != nil {
+		return QuotaResult{}, err
+	}
+	vals, err := ints(reply, 2+2*len(counters))
+	if err != nil {
+		return QuotaResult{}, err
+	}
+	res := QuotaResult{
+		Allowed:   vals[0] == 1,
+		Exhausted: vals[1] - 1,
+		Counts:    make([]int, len(counters)),
+		TTLs:      make([]time.Duration, len(counters)),
+	}
+	for i := range counters {
+		res.Counts[i] = vals[2+2*i]
+		res.TTLs[i] = time.Duration(vals[3+2*i]) * time.Millisecond
+	}
+	return res, nil
+}
+
+// Release decrements the counter at key without going below zero.
+func (c *Client) Release(ctx context.Context, key string) (int, error) {
+	reply, err := ReleaseScript.Run(ctx, c, []string{key})
+	if err != nil {
+		return 0, err
+	}
+	return Int(reply)
+}
+
+// ints converts an array reply of n integers.
+func ints(reply interface{}, n int) ([]int, error) {
+	arr, ok := reply.([]interface{})
+	if !ok || len(arr) != n {
+		return nil, fmt.Errorf("redis: unexpected script reply %v", reply)
+	}
+	vals := make([]int, n)
+	for i, v := range arr {
+		var err error
+		if vals[i], err = Int(v); err != nil {
+			return nil, err
+		}
+	}
+	return vals, nil
+}
@@ -0,0 +1,87 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ehr/ehr/internal/platform/redis"
+)
+
+func TestSlidingWindowScript_Lua(t *testing.T) {
+	client, m := newTestClient(t, "")
+	ctx := context.Background()
+	// The server clock, not the caller's, defines the window.
+	now := time.UnixMilli(1_700_000_000_123)
+
+	for i := 0; i < 3; i++ {
+		m.SetTime(now.Add(time.Duration(i) * time.Second))
+		res, err := client.SlidingWindow(ctx, "w", string(rune('a'+i)), 3, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Count != i+1 {
+			t.Fatalf("request %d: %+v, want allowed with count %d", i, res, i+1)
+		}
+	}
+	m.SetTime(now.Add(3 * time.Second))
+	res, err := client.SlidingWindow(ctx, "w", "d", 3, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.Count != 3 {
+		t.Errorf("fourth request = %+v, want denied", res)
+	}
+	if !res.ResetAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("ResetAt = %v, want %v", res.ResetAt, now.Add(time.Minute))
+	}
+
+	m.SetTime(now.Add(time.Minute + time.Millisecond))
+	res, err = client.SlidingWindow(ctx, "w", "e", 3, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Allowed || res.Count != 3 {
+		t.Errorf("request after the oldest expired = %+v, want allowed", res)
+	}
+}
+
+func TestQuotaAndReleaseScripts_Lua(t *testing.T) {
+	client, _ := newTestClient(t, "")
+	ctx := context.Background()
+	counters := []redis.Counter{
+		{Key: "conc", Limit: 1, TTL: time.Minute, Refresh: true},
+		{Key: "min", Limit: 5, TTL: time.Minute},
+		{Key: "unlimited", Limit: -1, TTL: time.Minute},
+	}
+
+	res, err := client.Quota(ctx, counters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Allowed || res.Exhausted != -1 || res.Counts[0] != 1 || res.Counts[1] != 1 || res.Counts[2] != 1 {
+		t.Fatalf("first Quota = %+v", res)
+	}
+	if res.TTLs[1] != time.Minute {
+		t.Errorf("counter TTL = %v, want %v", res.TTLs[1], time.Minute)
+	}
+
+	res, err = client.Quota(ctx, counters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.Exhausted != 0 || res.Counts[1] != 1 {
+		t.Fatalf("second Quota = %+v, want denied on the first counter without increments", res)
+	}
+
+	if n, err := client.Release(ctx, "conc"); err != nil || n != 0 {
+		t.Fatalf("Release = %d, %v", n, err)
+	}
+	if n, err := client.Release(ctx, "conc"); err != nil || n != 0 {
+		t.Fatalf("Release below zero = %d, %v", n, err)
+	}
+	res, err = client.Quota(ctx, counters)
+	if err != nil || !res.Allowed || res.Counts[1] != 2 {
+		t.Errorf("Quota after Release = %+v, %v", res, err)
+	}
+}
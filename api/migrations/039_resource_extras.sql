@@ -0,0 +1,15 @@
+-- 039: Resource extras sidecar
+-- Stores, per resource, the FHIR elements that the typed domain tables do not
+-- model (extensions, modifierExtension, contained, meta.tag, ...). The server
+-- merges them back into reads and history snapshots so resources round-trip.
+
+CREATE TABLE IF NOT EXISTS resource_extras (
+    resource_type   VARCHAR(64) NOT NULL,
+    resource_id     VARCHAR(64) NOT NULL,
+    elements        JSONB NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (resource_type, resource_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_extras_elements
+    ON resource_extras USING GIN (elements jsonb_path_ops);
@@ -0,0 +1,25 @@
+-- 040: Full-text search index
+-- Stores, per resource, the text searched by the _text (narrative) and
+-- _content (whole resource) parameters: narratives, Composition sections,
+-- annotation notes and the decoded data of text attachments. The tsvectors
+-- are computed on write with the text search configuration matching the
+-- resource language. Resources written before this migration are indexed
+-- by POST /fhir/$reindex.
+
+CREATE TABLE IF NOT EXISTS resource_text (
+    resource_type   VARCHAR(64) NOT NULL,
+    resource_id     VARCHAR(64) NOT NULL,
+    language        REGCONFIG NOT NULL DEFAULT 'english',
+    narrative       TEXT NOT NULL DEFAULT '',
+    content         TEXT NOT NULL DEFAULT '',
+    narrative_tsv   TSVECTOR NOT NULL,
+    content_tsv     TSVECTOR NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (resource_type, resource_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_text_narrative
+    ON resource_text USING GIN (narrative_tsv);
+
+CREATE INDEX IF NOT EXISTS idx_resource_text_content
+    ON resource_text USING GIN (content_tsv);
@@ -0,0 +1,22 @@
+-- 041: Contained resource index
+-- Stores, per container, a copy of each contained resource so that searches
+-- with _contained=true|both can find them by their own search parameters
+-- (e.g. an inline Medication of a MedicationRequest by Medication?code=).
+-- Rows are rewritten whenever the container is created or updated.
+
+CREATE TABLE IF NOT EXISTS resource_contained (
+    container_type  VARCHAR(64) NOT NULL,
+    container_id    VARCHAR(64) NOT NULL,
+    contained_id    VARCHAR(64) NOT NULL,
+    contained_index INTEGER NOT NULL DEFAULT 0,
+    resource_type   VARCHAR(64) NOT NULL,
+    resource        JSONB NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (container_type, container_id, contained_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_contained_type
+    ON resource_contained (resource_type, container_type, container_id);
+
+CREATE INDEX IF NOT EXISTS idx_resource_contained_resource
+    ON resource_contained USING GIN (resource jsonb_path_ops);
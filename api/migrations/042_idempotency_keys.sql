@@ -0,0 +1,26 @@
+-- 042: Idempotency keys
+-- Responses to write requests carrying an Idempotency-Key header, shared by
+-- all replicas so that a retry is replayed wherever it lands. The table lives
+-- in the public schema and is scoped by a tenant_id column, because keys are
+-- written outside the request's tenant transaction so that a lock is visible
+-- to other replicas at once. A row is in_progress while
+-- its first request executes; expired rows are swept by the server.
+
+CREATE TABLE IF NOT EXISTS public.idempotency_keys (
+    tenant_id       VARCHAR(64) NOT NULL DEFAULT '',
+    client_id       VARCHAR(255) NOT NULL DEFAULT '',
+    idempotency_key VARCHAR(255) NOT NULL,
+    method          VARCHAR(10) NOT NULL,
+    path            TEXT NOT NULL,
+    request_hash    VARCHAR(64) NOT NULL DEFAULT '',
+    in_progress     BOOLEAN NOT NULL DEFAULT TRUE,
+    status_code     INTEGER NOT NULL DEFAULT 0,
+    headers         JSONB,
+    body            BYTEA,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at      TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (tenant_id, client_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires
+    ON public.idempotency_keys (expires_at);
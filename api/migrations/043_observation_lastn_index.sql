@@ -0,0 +1,6 @@
+-- 043: Observation $lastn / $stats index
+-- $lastn ranks a patient's observations per code by effective time, and
+-- $stats aggregates them per code over a period; both scan this index.
+
+CREATE INDEX IF NOT EXISTS idx_observation_patient_code_effective
+    ON observation (patient_id, code_system, code_value, effective_datetime DESC NULLS LAST);
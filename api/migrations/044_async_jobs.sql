@@ -0,0 +1,54 @@
+-- 044: Asynchronous jobs
+-- $async-batch bundles, $import requests and Prefer: respond-async requests,
+-- shared by all replicas. Like idempotency keys, jobs live in the public
+-- schema and record the tenant and identity of the client that submitted
+-- them, so that any replica can lease a job and run it in that client's
+-- context. A leased job is owned by one worker until lease_expires_at; the
+-- worker extends the lease while it runs, and a job whose lease lapses (for
+-- example because its replica was restarted) is picked up again. Finished
+-- jobs keep their result until expires_at and are then swept by the server.
+
+CREATE TABLE IF NOT EXISTS public.async_jobs (
+    id                VARCHAR(64) PRIMARY KEY,
+    kind              VARCHAR(32) NOT NULL,
+    status            VARCHAR(32) NOT NULL,
+    tenant_id         VARCHAR(64) NOT NULL DEFAULT '',
+    user_id           VARCHAR(255) NOT NULL DEFAULT '',
+    roles             TEXT[] NOT NULL DEFAULT '{}',
+    scopes            TEXT[] NOT NULL DEFAULT '{}',
+    request           TEXT NOT NULL DEFAULT '',
+    resource_type     VARCHAR(64) NOT NULL DEFAULT '',
+    payload           JSONB,
+    total_entries     INTEGER NOT NULL DEFAULT 0,
+    processed_entries INTEGER NOT NULL DEFAULT 0,
+    success_count     INTEGER NOT NULL DEFAULT 0,
+    error_count       INTEGER NOT NULL DEFAULT 0,
+    result            JSONB,
+    errors            JSONB,
+    error             TEXT NOT NULL DEFAULT '',
+    attempts          INTEGER NOT NULL DEFAULT 0,
+    lease_owner       VARCHAR(128),
+    lease_expires_at  TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at        TIMESTAMPTZ,
+    completed_at      TIMESTAMPTZ,
+    expires_at        TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_async_jobs_runnable
+    ON public.async_jobs (created_at)
+    WHERE completed_at IS NULL AND payload IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_async_jobs_expires
+    ON public.async_jobs (expires_at)
+    WHERE expires_at IS NOT NULL;
+
+-- The outcome of each processed entry of a batch, so that a batch resumed
+-- after its lease lapsed skips the entries that already ran.
+CREATE TABLE IF NOT EXISTS public.async_job_entries (
+    job_id      VARCHAR(64) NOT NULL REFERENCES public.async_jobs (id) ON DELETE CASCADE,
+    entry_index INTEGER NOT NULL,
+    response    JSONB NOT NULL,
+    error       JSONB,
+    PRIMARY KEY (job_id, entry_index)
+);
@@ -0,0 +1,77 @@
+-- 045: Custom search parameter index
+-- Stores, per resource, the values of the custom search parameters defined
+-- by active SearchParameter resources with a FHIRPath expression (e.g.
+-- Patient?preferred-pharmacy=), so that site-specific parameters can be
+-- searched without adding columns. Rows are rewritten whenever the resource
+-- is created or updated, and backfilled for existing resources by $reindex.
+-- Each table holds one search parameter type; number and quantity share a
+-- table, and so do string and uri.
+
+CREATE TABLE IF NOT EXISTS resource_search_string (
+    resource_type   VARCHAR(64) NOT NULL,
+    resource_id     VARCHAR(64) NOT NULL,
+    param           VARCHAR(128) NOT NULL,
+    value           TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_search_string_value
+    ON resource_search_string (resource_type, param, value);
+
+CREATE INDEX IF NOT EXISTS idx_resource_search_string_resource
+    ON resource_search_string (resource_type, resource_id);
+
+CREATE TABLE IF NOT EXISTS resource_search_token (
+    resource_type   VARCHAR(64) NOT NULL,
+    resource_id     VARCHAR(64) NOT NULL,
+    param           VARCHAR(128) NOT NULL,
+    system          TEXT NOT NULL DEFAULT '',
+    code            TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_search_token_value
+    ON resource_search_token (resource_type, param, code, system);
+
+CREATE INDEX IF NOT EXISTS idx_resource_search_token_resource
+    ON resource_search_token (resource_type, resource_id);
+
+CREATE TABLE IF NOT EXISTS resource_search_date (
+    resource_type   VARCHAR(64) NOT NULL,
+    resource_id     VARCHAR(64) NOT NULL,
+    param           VARCHAR(128) NOT NULL,
+    value           TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_search_date_value
+    ON resource_search_date (resource_type, param, value);
+
+CREATE INDEX IF NOT EXISTS idx_resource_search_date_resource
+    ON resource_search_date (resource_type, resource_id);
+
+CREATE TABLE IF NOT EXISTS resource_search_quantity (
+    resource_type   VARCHAR(64) NOT NULL,
+    resource_id     VARCHAR(64) NOT NULL,
+    param           VARCHAR(128) NOT NULL,
+    value           NUMERIC NOT NULL,
+    system          TEXT NOT NULL DEFAULT '',
+    code            TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_search_quantity_value
+    ON resource_search_quantity (resource_type, param, value);
+
+CREATE INDEX IF NOT EXISTS idx_resource_search_quantity_resource
+    ON resource_search_quantity (resource_type, resource_id);
+
+CREATE TABLE IF NOT EXISTS resource_search_reference (
+    resource_type   VARCHAR(64) NOT NULL,
+    resource_id     VARCHAR(64) NOT NULL,
+    param           VARCHAR(128) NOT NULL,
+    target_type     VARCHAR(64) NOT NULL DEFAULT '',
+    target_id       VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_search_reference_value
+    ON resource_search_reference (resource_type, param, target_id, target_type);
+
+CREATE INDEX IF NOT EXISTS idx_resource_search_reference_resource
+    ON resource_search_reference (resource_type, resource_id);
@@ -0,0 +1,20 @@
+-- 046: Canonical UCUM quantities
+-- Quantities are stored alongside their value converted to the canonical
+-- UCUM unit (e.g. mg/dL and g/L both as g.m-3), so that quantity searches
+-- match values recorded in any commensurable unit. Quantities whose unit is
+-- not UCUM leave them NULL and are matched on their own unit. The conversion
+-- needs the UCUM parser, so observations written before this migration are
+-- filled in by POST /fhir/$reindex?_type=Observation rather than here.
+
+ALTER TABLE observation ADD COLUMN IF NOT EXISTS value_quantity_canonical NUMERIC;
+ALTER TABLE observation ADD COLUMN IF NOT EXISTS value_unit_canonical VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_observation_value_canonical
+    ON observation (value_unit_canonical, value_quantity_canonical)
+    WHERE value_unit_canonical IS NOT NULL;
+
+ALTER TABLE resource_search_quantity ADD COLUMN IF NOT EXISTS canonical_value NUMERIC;
+ALTER TABLE resource_search_quantity ADD COLUMN IF NOT EXISTS canonical_code VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_resource_search_quantity_canonical
+    ON resource_search_quantity (resource_type, param, canonical_code, canonical_value);
@@ -0,0 +1,19 @@
+-- 047: ViewDefinition materializations
+-- Records the ViewDefinitions whose rows are stored in the tenant's schema,
+-- as a table (view_<id>) kept up to date as resources change, or as a
+-- materialized view refreshed on demand. The definition is kept so that
+-- rows can be recomputed without the handler that materialized the view;
+-- stale marks materializations that must be refreshed before they are read.
+
+CREATE TABLE IF NOT EXISTS view_materialization (
+    view_id         VARCHAR(128) PRIMARY KEY,
+    resource_type   VARCHAR(64) NOT NULL,
+    table_name      VARCHAR(140) NOT NULL,
+    kind            VARCHAR(32) NOT NULL DEFAULT 'table',
+    definition      JSONB NOT NULL,
+    stale           BOOLEAN NOT NULL DEFAULT false,
+    refreshed_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_view_materialization_resource_type
+    ON view_materialization (resource_type);
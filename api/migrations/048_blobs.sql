@@ -0,0 +1,28 @@
+-- 048: Blob storage
+-- Documents uploaded through /api/v1/blobs, $import inputs and error files,
+-- and $export output files, shared by all replicas so that a file written
+-- on one replica can be downloaded through any other and survives restarts.
+-- Like async jobs, blobs live in the public schema and record the tenant
+-- that stored them; reads are scoped to the requesting tenant.
+
+CREATE TABLE IF NOT EXISTS public.blobs (
+    id           VARCHAR(64) PRIMARY KEY,
+    tenant_id    VARCHAR(64) NOT NULL DEFAULT '',
+    file_name    TEXT NOT NULL,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    size         BIGINT NOT NULL DEFAULT 0,
+    patient_id   VARCHAR(64) NOT NULL DEFAULT '',
+    encounter_id VARCHAR(64) NOT NULL DEFAULT '',
+    category     VARCHAR(64) NOT NULL DEFAULT '',
+    hash         VARCHAR(64) NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by   VARCHAR(255) NOT NULL DEFAULT '',
+    tags         JSONB NOT NULL DEFAULT '{}',
+    content      BYTEA NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_blobs_patient
+    ON public.blobs (tenant_id, patient_id, category, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_blobs_tags
+    ON public.blobs USING GIN (tags);
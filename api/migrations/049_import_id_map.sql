@@ -0,0 +1,15 @@
+-- 049: $import id map
+-- Records, per input source, the resources $import created with a
+-- server-assigned id in place of the id they had in the source file, so
+-- that a retried import job, or a later import from the same source,
+-- updates those resources instead of creating duplicates, and rewrites
+-- references to the source ids. Rows are written in the transaction that
+-- creates the resource.
+
+CREATE TABLE IF NOT EXISTS import_id_map (
+    input_source    TEXT NOT NULL DEFAULT '',
+    source_ref      VARCHAR(255) NOT NULL,
+    target_ref      VARCHAR(255) NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (input_source, source_ref)
+);
@@ -0,0 +1,39 @@
+-- 050: Modeled meta
+-- Stores, per resource, the meta.profile, meta.tag and meta.security
+-- elements the domain model emits itself (e.g. the US Core profile of every
+-- Patient), which resource_extras does not hold because they are not extras.
+-- The _profile, _tag and _security search parameters match both tables.
+-- Rows are rewritten whenever the resource is created or updated.
+
+CREATE TABLE IF NOT EXISTS resource_modeled_meta (
+    resource_type   VARCHAR(64) NOT NULL,
+    resource_id     VARCHAR(64) NOT NULL,
+    meta            JSONB NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (resource_type, resource_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_modeled_meta_meta
+    ON resource_modeled_meta USING GIN (meta jsonb_path_ops);
+
+-- Backfill from the latest history snapshot of each resource. Snapshots hold
+-- the extras merged in, so the profiles kept in resource_extras are left out;
+-- the domain models emit no tags or security labels.
+INSERT INTO resource_modeled_meta (resource_type, resource_id, meta)
+SELECT latest.resource_type, latest.resource_id, jsonb_build_object('profile', latest.profile)
+FROM (
+    SELECT h.resource_type, h.resource_id,
+        (SELECT jsonb_agg(p)
+         FROM jsonb_array_elements(h.resource->'meta'->'profile') p
+         WHERE NOT COALESCE(e.elements->'meta'->'profile', '[]'::jsonb) @> jsonb_build_array(p)) AS profile
+    FROM (
+        SELECT DISTINCT ON (resource_type, resource_id) resource_type, resource_id, resource, action
+        FROM resource_history
+        ORDER BY resource_type, resource_id, version_id DESC
+    ) h
+    LEFT JOIN resource_extras e
+        ON e.resource_type = h.resource_type AND e.resource_id = h.resource_id
+    WHERE h.action <> 'delete' AND jsonb_typeof(h.resource->'meta'->'profile') = 'array'
+) latest
+WHERE latest.profile IS NOT NULL
+ON CONFLICT (resource_type, resource_id) DO NOTHING;
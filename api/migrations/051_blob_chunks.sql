@@ -0,0 +1,16 @@
+-- 051: Chunked blob content
+-- Stores blob content in chunks, written as an upload is read and read as a
+-- download is consumed, so that neither holds a whole blob (an export file
+-- may be tens of megabytes) in memory. public.blobs.content keeps the
+-- content of blobs stored before this migration and is empty for new ones.
+-- A blob's chunks are written before its public.blobs row, which makes it
+-- readable once they are complete.
+
+CREATE TABLE IF NOT EXISTS public.blob_chunks (
+    blob_id      VARCHAR(64) NOT NULL,
+    seq          INTEGER NOT NULL,
+    data         BYTEA NOT NULL,
+    PRIMARY KEY (blob_id, seq)
+);
+
+ALTER TABLE public.blobs ALTER COLUMN content SET DEFAULT ''::bytea;